@@ -76,6 +76,8 @@ func (s *Server) isViewAllowed(sr *store.SessionRecord, page, tab string) bool {
 			return s.policy.Allowed(roles, rbac.Permission("software.view"))
 		case "findings":
 			return s.policy.Allowed(roles, rbac.Permission("findings.view"))
+		case "risks":
+			return s.policy.Allowed(roles, rbac.Permission("risks.view"))
 		default:
 			return true
 		}
@@ -88,7 +90,7 @@ func (s *Server) viewAction(page, tab string) string {
 	switch page {
 	case "registry":
 		switch tab {
-		case "overview", "controls", "checks", "violations", "frameworks", "assets", "software", "findings":
+		case "overview", "controls", "checks", "violations", "frameworks", "assets", "software", "findings", "risks":
 			return "registry.tab." + tab + ".view"
 		default:
 			return ""
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"berkut-scc/api/handlers"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
@@ -41,6 +42,14 @@ func newBackgroundManager(sessions store.SessionStore, revokeOnStartup bool, log
 	}
 }
 
+// BackgroundWorkers returns the workers that run on the server's own handlers.
+func (s *Server) BackgroundWorkers() []BackgroundWorker {
+	if s == nil || s.cfg == nil {
+		return nil
+	}
+	return []BackgroundWorker{handlers.NewReportScheduler(s.cfg.Scheduler, s.reports, s.logger)}
+}
+
 func BuildBackgroundController(sessions store.SessionStore, revokeOnStartup bool, logger *utils.Logger, workers ...BackgroundWorker) BackgroundController {
 	return newBackgroundManager(sessions, revokeOnStartup, logger, workers...)
 }
@@ -19,6 +19,10 @@ type planPayload struct {
 	RetentionDays       int    `json:"retention_days"`
 	KeepLastSuccessful  int    `json:"keep_last_successful"`
 	IncludeFiles        bool   `json:"include_files"`
+	ScheduleTimezone    string `json:"schedule_timezone"`
+	ScheduleRRule       string `json:"schedule_rrule"`
+	ScheduleShift       string `json:"schedule_shift"`
+	ScheduleCalendarID  int64  `json:"schedule_calendar_id"`
 }
 
 func (h *Handler) GetPlan(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +54,10 @@ func (h *Handler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
 		RetentionDays:       payload.RetentionDays,
 		KeepLastSuccessful:  payload.KeepLastSuccessful,
 		IncludeFiles:        payload.IncludeFiles,
+		ScheduleTimezone:    payload.ScheduleTimezone,
+		ScheduleRRule:       payload.ScheduleRRule,
+		ScheduleShift:       payload.ScheduleShift,
+		ScheduleCalendarID:  payload.ScheduleCalendarID,
 	}, session.Username)
 	if err != nil {
 		if de, ok := corebackups.AsDomainError(err); ok {
@@ -2144,11 +2144,11 @@ func defaultRoleTemplates() []roleTemplate {
 		{ID: "doc_admin", Name: "Doc Admin", Description: "Manage documents, approvals and templates", Permissions: []string{"app.view", "dashboard.view", "docs.view", "docs.manage", "docs.classification.set", "docs.export", "docs.versions.view", "docs.versions.restore", "docs.approval.start", "docs.approval.view", "docs.approval.approve", "folders.manage", "templates.manage", "incidents.view", "incidents.create", "incidents.edit", "logs.view"}},
 		{ID: "auditor", Name: "Auditor", Description: "Read-only access with audit visibility", Permissions: []string{"app.view", "dashboard.view", "docs.view", "docs.versions.view", "docs.export", "incidents.view", "reports.view", "reports.export", "logs.view"}},
 		{ID: "security_officer", Name: "Security Officer", Description: "Security oversight for documents", Permissions: []string{"app.view", "dashboard.view", "docs.classification.set", "docs.manage", "docs.view", "docs.export", "docs.versions.view", "folders.manage", "incidents.view", "incidents.create", "incidents.edit", "logs.view"}},
-		{ID: "analyst", Name: "Analyst", Description: "Controls, findings and monitoring visibility", Permissions: []string{"app.view", "dashboard.view", "docs.view", "controls.view", "controls.checks.view", "controls.violations.view", "controls.frameworks.view", "monitoring.view", "monitoring.events.view", "tasks.view", "tasks.create", "tasks.edit", "tasks.comment", "findings.view", "incidents.view"}},
+		{ID: "analyst", Name: "Analyst", Description: "Controls, findings and monitoring visibility", Permissions: []string{"app.view", "dashboard.view", "docs.view", "controls.view", "controls.checks.view", "controls.violations.view", "controls.frameworks.view", "monitoring.view", "monitoring.events.view", "tasks.view", "tasks.create", "tasks.edit", "tasks.comment", "findings.view", "risks.view", "incidents.view"}},
 		{ID: "soc_viewer", Name: "SOC Viewer", Description: "Read-only SOC access to monitoring, incidents and logs", Permissions: []string{"app.view", "dashboard.view", "monitoring.view", "monitoring.events.view", "monitoring.certs.view", "monitoring.maintenance.view", "monitoring.notifications.view", "incidents.view", "reports.view", "logs.view"}},
 		{ID: "soc_operator", Name: "SOC Operator", Description: "Operate monitoring and incident response workflows", Permissions: []string{"app.view", "dashboard.view", "monitoring.view", "monitoring.manage", "monitoring.events.view", "monitoring.certs.view", "monitoring.certs.manage", "monitoring.maintenance.view", "monitoring.maintenance.manage", "monitoring.notifications.view", "monitoring.notifications.manage", "monitoring.incidents.link", "tasks.view", "tasks.create", "tasks.edit", "tasks.assign", "tasks.comment", "incidents.view", "incidents.create", "incidents.edit", "logs.view"}},
 		{ID: "backup_operator", Name: "Backup Operator", Description: "Manage backup and restore operations", Permissions: []string{"app.view", "dashboard.view", "backups.read", "backups.create", "backups.import", "backups.plan.update", "backups.download", "backups.restore", "logs.view"}},
-		{ID: "compliance_manager", Name: "Compliance Manager", Description: "Compliance reporting and evidence visibility", Permissions: []string{"app.view", "dashboard.view", "docs.view", "docs.export", "docs.versions.view", "controls.view", "controls.checks.view", "controls.violations.view", "controls.frameworks.view", "assets.view", "software.view", "findings.view", "incidents.view", "reports.view", "reports.create", "reports.edit", "reports.export", "logs.view"}},
+		{ID: "compliance_manager", Name: "Compliance Manager", Description: "Compliance reporting and evidence visibility", Permissions: []string{"app.view", "dashboard.view", "docs.view", "docs.export", "docs.versions.view", "controls.view", "controls.checks.view", "controls.violations.view", "controls.frameworks.view", "assets.view", "software.view", "findings.view", "risks.view", "incidents.view", "reports.view", "reports.create", "reports.edit", "reports.export", "logs.view"}},
 	}
 }
 
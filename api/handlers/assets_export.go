@@ -32,6 +32,21 @@ func (h *AssetsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	if canManage && (q.Get("include_deleted") == "1" || strings.ToLower(q.Get("include_deleted")) == "true") {
 		filter.IncludeDeleted = true
 	}
+	conditions, err := h.fields.Conditions(r.Context(), store.CustomFieldEntityAsset, roles, q)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	filter.CustomFields = conditions
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityAsset, q, user.ID, roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	if filter.Limit <= 0 || filter.Limit > 5000 {
 		filter.Limit = 5000
 	}
@@ -41,6 +56,11 @@ func (h *AssetsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	ids := make([]int64, 0, len(items))
+	for _, a := range items {
+		ids = append(ids, a.ID)
+	}
+	fields, values := customFieldExport(r.Context(), h.fields, store.CustomFieldEntityAsset, roles, ids)
 
 	now := time.Now().UTC()
 	filename := fmt.Sprintf("assets_%s.csv", now.Format("20060102_150405"))
@@ -51,10 +71,10 @@ func (h *AssetsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	h.logAudit(r.Context(), user.Username, "assets.export.csv", strconv.Itoa(len(items)))
 
 	writer := csv.NewWriter(w)
-	_ = writer.Write([]string{
+	_ = writer.Write(append([]string{
 		"id", "name", "type", "description", "commissioned_at", "ip_addresses", "criticality", "owner", "administrator",
 		"env", "status", "tags", "created_at", "updated_at", "deleted_at",
-	})
+	}, customFieldHeaders(fields)...))
 	for _, a := range items {
 		commissioned := ""
 		if a.CommissionedAt != nil {
@@ -64,7 +84,7 @@ func (h *AssetsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		if a.DeletedAt != nil {
 			deleted = a.DeletedAt.UTC().Format(time.RFC3339)
 		}
-		_ = writer.Write([]string{
+		_ = writer.Write(append([]string{
 			strconv.FormatInt(a.ID, 10),
 			a.Name,
 			a.Type,
@@ -80,7 +100,7 @@ func (h *AssetsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 			a.CreatedAt.UTC().Format(time.RFC3339),
 			a.UpdatedAt.UTC().Format(time.RFC3339),
 			deleted,
-		})
+		}, customFieldRow(fields, values[a.ID])...))
 	}
 	writer.Flush()
 }
@@ -0,0 +1,579 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/docs"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/store"
+)
+
+const (
+	assetGraphDefaultDepth = 2
+	assetGraphMaxDepth     = 5
+	assetGraphMaxNodes     = 300
+)
+
+// SetGraph enables asset relations, the dependency graph and blast-radius queries.
+func (h *AssetsHandler) SetGraph(cfg *config.AppConfig, relations store.AssetRelationsStore, monitoring store.MonitoringStore, incidentsStore store.IncidentsStore, incidentsSvc *incidents.Service, links store.EntityLinksStore, controls store.ControlsStore) {
+	if h == nil {
+		return
+	}
+	h.cfg = cfg
+	h.relations = relations
+	h.monitoring = monitoring
+	h.incidents = incidentsStore
+	h.incidentsSvc = incidentsSvc
+	h.links = links
+	h.controls = controls
+}
+
+func (h *AssetsHandler) ListRelations(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := h.currentUser(r); err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.relations == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.relations.ListAssetRelations(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.AssetRelation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "types": store.AssetRelationTypes})
+}
+
+func (h *AssetsHandler) AddRelation(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.relations == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	var payload struct {
+		TargetID     int64  `json:"target_id"`
+		RelationType string `json:"relation_type"`
+		Description  string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "assets.error.badRequest", http.StatusBadRequest)
+		return
+	}
+	relType := strings.ToLower(strings.TrimSpace(payload.RelationType))
+	if !isAssetRelationType(relType) {
+		http.Error(w, "assets.relations.typeInvalid", http.StatusBadRequest)
+		return
+	}
+	if payload.TargetID <= 0 {
+		http.Error(w, "assets.relations.targetRequired", http.StatusBadRequest)
+		return
+	}
+	if payload.TargetID == id {
+		http.Error(w, "assets.relations.self", http.StatusBadRequest)
+		return
+	}
+	if len([]rune(strings.TrimSpace(payload.Description))) > 500 {
+		http.Error(w, "assets.relations.descriptionTooLong", http.StatusBadRequest)
+		return
+	}
+	target, err := h.store.GetAsset(r.Context(), payload.TargetID)
+	if err != nil || target == nil || target.DeletedAt != nil {
+		http.Error(w, "assets.relations.targetNotFound", http.StatusBadRequest)
+		return
+	}
+	rel := &store.AssetRelation{
+		SourceID:     id,
+		TargetID:     target.ID,
+		TargetName:   target.Name,
+		RelationType: relType,
+		Description:  payload.Description,
+		CreatedBy:    &user.ID,
+	}
+	if _, err := h.relations.AddAssetRelation(r.Context(), rel); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			http.Error(w, "assets.relations.duplicate", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	created, err := h.relations.GetAssetRelation(r.Context(), rel.ID)
+	if err == nil && created != nil {
+		rel = created
+	}
+	h.logAudit(r.Context(), user.Username, "assets.relation.add", fmt.Sprintf("%d|%s|%d", rel.SourceID, rel.RelationType, rel.TargetID))
+	writeJSON(w, http.StatusCreated, rel)
+}
+
+func (h *AssetsHandler) DeleteRelation(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.relations == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	relID := parseInt64Default(pathParams(r)["relation_id"], 0)
+	rel, err := h.relations.GetAssetRelation(r.Context(), relID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if rel == nil || (rel.SourceID != id && rel.TargetID != id) {
+		http.Error(w, "assets.relations.notFound", http.StatusNotFound)
+		return
+	}
+	if err := h.relations.DeleteAssetRelation(r.Context(), rel.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.logAudit(r.Context(), user.Username, "assets.relation.delete", fmt.Sprintf("%d|%s|%d", rel.SourceID, rel.RelationType, rel.TargetID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type assetGraphNode struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Criticality string `json:"criticality"`
+	Env         string `json:"env"`
+	Status      string `json:"status"`
+	Depth       int    `json:"depth"`
+	Direction   string `json:"direction"`
+}
+
+type assetGraph struct {
+	Nodes     []assetGraphNode      `json:"nodes"`
+	Edges     []store.AssetRelation `json:"edges"`
+	Truncated bool                  `json:"truncated"`
+}
+
+// Graph returns the dependencies (upstream) and dependents (downstream) of an asset
+// up to the requested depth.
+func (h *AssetsHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := h.currentUser(r); err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.relations == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	depth, ok := parseAssetGraphDepth(r.URL.Query().Get("depth"))
+	if !ok {
+		http.Error(w, "assets.graph.depthInvalid", http.StatusBadRequest)
+		return
+	}
+	upstream, downstream := true, true
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("direction"))) {
+	case "", "both":
+	case "upstream":
+		downstream = false
+	case "downstream":
+		upstream = false
+	default:
+		http.Error(w, "assets.graph.directionInvalid", http.StatusBadRequest)
+		return
+	}
+	graph, err := h.walkAssetGraph(r.Context(), []int64{id}, depth, upstream, downstream)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"asset_id": id, "depth": depth, "nodes": graph.Nodes, "edges": graph.Edges, "truncated": graph.Truncated})
+}
+
+type blastRadiusAsset struct {
+	assetGraphNode
+	Software []string `json:"software"`
+}
+
+type blastRadiusIncident struct {
+	ID       int64   `json:"id"`
+	RegNo    string  `json:"reg_no"`
+	Title    string  `json:"title"`
+	Severity string  `json:"severity"`
+	Status   string  `json:"status"`
+	AssetIDs []int64 `json:"asset_ids"`
+}
+
+type blastRadiusControl struct {
+	ID       int64   `json:"id"`
+	Code     string  `json:"code"`
+	Title    string  `json:"title"`
+	Status   string  `json:"status"`
+	AssetIDs []int64 `json:"asset_ids"`
+}
+
+// BlastRadius lists the assets affected by an outage of an asset or of the assets
+// linked to a monitor: the starting assets and everything that depends on them,
+// with their software, open incidents and covering controls.
+func (h *AssetsHandler) BlastRadius(w http.ResponseWriter, r *http.Request) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.relations == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	depth, ok := parseAssetGraphDepth(q.Get("depth"))
+	if !ok {
+		http.Error(w, "assets.graph.depthInvalid", http.StatusBadRequest)
+		return
+	}
+	assetID := parseInt64Default(q.Get("asset_id"), 0)
+	monitorID := parseInt64Default(q.Get("monitor_id"), 0)
+	resp := map[string]any{"depth": depth}
+	var roots []int64
+	switch {
+	case assetID > 0:
+		item, err := h.store.GetAsset(r.Context(), assetID)
+		if err != nil || item == nil || item.DeletedAt != nil {
+			http.Error(w, "assets.error.notFound", http.StatusNotFound)
+			return
+		}
+		roots = []int64{item.ID}
+		resp["asset_id"] = item.ID
+	case monitorID > 0:
+		if h.monitoring == nil || h.policy == nil || !h.policy.Allowed(roles, "monitoring.view") {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		mon, err := h.monitoring.GetMonitor(r.Context(), monitorID)
+		if err != nil || mon == nil {
+			http.Error(w, "monitoring.notFound", http.StatusNotFound)
+			return
+		}
+		linked, err := h.monitoring.ListMonitorAssets(r.Context(), mon.ID)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		for _, a := range linked {
+			roots = append(roots, a.ID)
+		}
+		resp["monitor_id"] = mon.ID
+		resp["monitor_name"] = mon.Name
+		if state, err := h.monitoring.GetMonitorState(r.Context(), mon.ID); err == nil && state != nil {
+			resp["monitor_status"] = state.Status
+		}
+	default:
+		http.Error(w, "assets.graph.sourceRequired", http.StatusBadRequest)
+		return
+	}
+
+	graph, err := h.walkAssetGraph(r.Context(), roots, depth, false, true)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	affected := make([]blastRadiusAsset, 0, len(graph.Nodes))
+	ids := make([]int64, 0, len(graph.Nodes))
+	names := make([]string, 0, len(graph.Nodes))
+	canViewSoftware := h.policy != nil && h.policy.Allowed(roles, "software.view")
+	for _, node := range graph.Nodes {
+		item := blastRadiusAsset{assetGraphNode: node, Software: []string{}}
+		if h.sw != nil && canViewSoftware {
+			installs, err := h.sw.ListAssetSoftware(r.Context(), node.ID, false)
+			if err != nil {
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			for _, inst := range installs {
+				label := strings.TrimSpace(inst.ProductName + " " + inst.VersionText)
+				if label != "" {
+					item.Software = append(item.Software, label)
+				}
+			}
+		}
+		affected = append(affected, item)
+		ids = append(ids, node.ID)
+		names = append(names, node.Name)
+	}
+	incidentsList, err := h.blastRadiusIncidents(r.Context(), user, roles, ids)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	controlsList, err := h.blastRadiusControls(r.Context(), roles, ids)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	resp["assets"] = affected
+	resp["edges"] = graph.Edges
+	resp["incidents"] = incidentsList
+	resp["controls"] = controlsList
+	resp["truncated"] = graph.Truncated
+	resp["affected_systems"] = strings.Join(names, ", ")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// walkAssetGraph expands the relations of the root assets level by level. Upstream
+// follows relations from source to target (what the asset depends on), downstream
+// from target to source (what depends on the asset). Roots are returned at depth 0.
+func (h *AssetsHandler) walkAssetGraph(ctx context.Context, roots []int64, depth int, upstream, downstream bool) (*assetGraph, error) {
+	graph := &assetGraph{Nodes: []assetGraphNode{}, Edges: []store.AssetRelation{}}
+	index := map[int64]int{}
+	addNode := func(id int64, level int, direction string) (bool, error) {
+		if pos, ok := index[id]; ok {
+			if graph.Nodes[pos].Direction != direction && graph.Nodes[pos].Direction != "root" {
+				graph.Nodes[pos].Direction = "both"
+			}
+			return false, nil
+		}
+		if len(graph.Nodes) >= assetGraphMaxNodes {
+			graph.Truncated = true
+			return false, nil
+		}
+		item, err := h.store.GetAsset(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if item == nil || item.DeletedAt != nil {
+			return false, nil
+		}
+		index[id] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, assetGraphNode{
+			ID:          item.ID,
+			Name:        item.Name,
+			Type:        item.Type,
+			Criticality: item.Criticality,
+			Env:         item.Env,
+			Status:      item.Status,
+			Depth:       level,
+			Direction:   direction,
+		})
+		return true, nil
+	}
+	var start []int64
+	for _, id := range roots {
+		if _, err := addNode(id, 0, "root"); err != nil {
+			return nil, err
+		}
+		if _, ok := index[id]; ok {
+			start = append(start, id)
+		}
+	}
+	edges := map[int64]bool{}
+	walk := func(direction string) error {
+		visited := map[int64]bool{}
+		frontier := append([]int64(nil), start...)
+		for _, id := range frontier {
+			visited[id] = true
+		}
+		for level := 1; level <= depth && len(frontier) > 0; level++ {
+			rels, err := h.relations.ListAssetRelationsFor(ctx, frontier)
+			if err != nil {
+				return err
+			}
+			inFrontier := map[int64]bool{}
+			for _, id := range frontier {
+				inFrontier[id] = true
+			}
+			var next []int64
+			for _, rel := range rels {
+				from, to := rel.TargetID, rel.SourceID
+				if direction == "upstream" {
+					from, to = rel.SourceID, rel.TargetID
+				}
+				if !inFrontier[from] {
+					continue
+				}
+				if !visited[to] {
+					visited[to] = true
+					if _, err := addNode(to, level, direction); err != nil {
+						return err
+					}
+					if _, ok := index[to]; ok {
+						next = append(next, to)
+					}
+				}
+				if _, ok := index[to]; ok && !edges[rel.ID] {
+					edges[rel.ID] = true
+					graph.Edges = append(graph.Edges, rel)
+				}
+			}
+			frontier = next
+		}
+		return nil
+	}
+	if upstream {
+		if err := walk("upstream"); err != nil {
+			return nil, err
+		}
+	}
+	if downstream {
+		if err := walk("downstream"); err != nil {
+			return nil, err
+		}
+	}
+	return graph, nil
+}
+
+// blastRadiusIncidents returns open incidents linked to the assets that the user is
+// allowed to see.
+func (h *AssetsHandler) blastRadiusIncidents(ctx context.Context, user *store.User, roles []string, assetIDs []int64) ([]blastRadiusIncident, error) {
+	out := []blastRadiusIncident{}
+	if h.incidents == nil || h.incidentsSvc == nil || len(assetIDs) == 0 {
+		return out, nil
+	}
+	if h.policy == nil || !h.policy.Allowed(roles, "incidents.view") {
+		return out, nil
+	}
+	var groups []store.Group
+	if h.users != nil {
+		groups, _ = h.users.UserGroups(ctx, user.ID)
+	}
+	eff := auth.CalculateEffectiveAccess(user, roles, groups, h.policy)
+	if !allowedByMenuPermissions(eff.MenuPermissions, "incidents") {
+		return out, nil
+	}
+	refs := make([]string, 0, len(assetIDs))
+	for _, id := range assetIDs {
+		refs = append(refs, strconv.FormatInt(id, 10))
+	}
+	items, err := h.incidents.ListOpenIncidentsByLink(ctx, "asset", refs)
+	if err != nil {
+		return nil, err
+	}
+	affected := map[string]bool{}
+	for _, ref := range refs {
+		affected[ref] = true
+	}
+	for _, inc := range items {
+		acl, _ := h.incidents.GetIncidentACL(ctx, inc.ID)
+		if !h.incidentsSvc.CheckACL(user, eff.Roles, acl, "view") {
+			continue
+		}
+		if !h.canViewClassified(eff, inc.ClassificationLevel, inc.ClassificationTags) {
+			continue
+		}
+		item := blastRadiusIncident{ID: inc.ID, RegNo: inc.RegNo, Title: inc.Title, Severity: inc.Severity, Status: inc.Status, AssetIDs: []int64{}}
+		links, _ := h.incidents.ListIncidentLinks(ctx, inc.ID)
+		seen := map[int64]bool{}
+		for _, l := range links {
+			if strings.ToLower(strings.TrimSpace(l.EntityType)) != "asset" || !affected[l.EntityID] {
+				continue
+			}
+			if id := parseInt64Default(l.EntityID, 0); id > 0 && !seen[id] {
+				seen[id] = true
+				item.AssetIDs = append(item.AssetIDs, id)
+			}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// blastRadiusControls returns controls linked to the assets, except links recording
+// a violation.
+func (h *AssetsHandler) blastRadiusControls(ctx context.Context, roles []string, assetIDs []int64) ([]blastRadiusControl, error) {
+	out := []blastRadiusControl{}
+	if h.links == nil || h.controls == nil {
+		return out, nil
+	}
+	if h.policy == nil || !h.policy.Allowed(roles, "controls.view") {
+		return out, nil
+	}
+	index := map[int64]int{}
+	for _, assetID := range assetIDs {
+		links, err := h.links.ListByTarget(ctx, "asset", strconv.FormatInt(assetID, 10))
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range links {
+			if l.SourceType != "control" || l.RelationType == "violates" {
+				continue
+			}
+			controlID := parseInt64Default(l.SourceID, 0)
+			if controlID <= 0 {
+				continue
+			}
+			pos, ok := index[controlID]
+			if !ok {
+				control, err := h.controls.GetControl(ctx, controlID)
+				if err != nil {
+					return nil, err
+				}
+				if control == nil {
+					continue
+				}
+				pos = len(out)
+				index[controlID] = pos
+				out = append(out, blastRadiusControl{ID: control.ID, Code: control.Code, Title: control.Title, Status: control.Status, AssetIDs: []int64{}})
+			}
+			if ids := out[pos].AssetIDs; len(ids) == 0 || ids[len(ids)-1] != assetID {
+				out[pos].AssetIDs = append(ids, assetID)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
+
+func (h *AssetsHandler) canViewClassified(eff store.EffectiveAccess, level int, tags []string) bool {
+	if h.cfg != nil && h.cfg.Security.TagsSubsetEnforced {
+		return docs.HasClearance(docs.ClassificationLevel(eff.ClearanceLevel), eff.ClearanceTags, docs.ClassificationLevel(level), tags)
+	}
+	return eff.ClearanceLevel >= level
+}
+
+func parseAssetGraphDepth(raw string) (int, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return assetGraphDefaultDepth, true
+	}
+	depth, err := strconv.Atoi(raw)
+	if err != nil || depth < 1 || depth > assetGraphMaxDepth {
+		return 0, false
+	}
+	return depth, true
+}
+
+func isAssetRelationType(val string) bool {
+	for _, t := range store.AssetRelationTypes {
+		if t == val {
+			return true
+		}
+	}
+	return false
+}
@@ -11,9 +11,14 @@ import (
 	"strings"
 	"time"
 
+	"berkut-scc/config"
 	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
+	"berkut-scc/core/vulns"
 )
 
 type AssetsHandler struct {
@@ -22,10 +27,26 @@ type AssetsHandler struct {
 	users  store.UsersStore
 	audits store.AuditStore
 	policy *rbac.Policy
+
+	observables store.ObservablesStore
+	vulns       *vulns.Service
+	imports     *userImportManager
+
+	cfg          *config.AppConfig
+	relations    store.AssetRelationsStore
+	monitoring   store.MonitoringStore
+	incidents    store.IncidentsStore
+	incidentsSvc *incidents.Service
+	links        store.EntityLinksStore
+	controls     store.ControlsStore
+
+	history store.AssetHistoryStore
+	fields  *customfields.Service
+	queries *query.Service
 }
 
-func NewAssetsHandler(as store.AssetsStore, sw store.SoftwareStore, us store.UsersStore, audits store.AuditStore, policy *rbac.Policy) *AssetsHandler {
-	return &AssetsHandler{store: as, sw: sw, users: us, audits: audits, policy: policy}
+func NewAssetsHandler(as store.AssetsStore, sw store.SoftwareStore, observables store.ObservablesStore, vulnsSvc *vulns.Service, us store.UsersStore, audits store.AuditStore, policy *rbac.Policy) *AssetsHandler {
+	return &AssetsHandler{store: as, sw: sw, observables: observables, vulns: vulnsSvc, users: us, audits: audits, policy: policy, imports: newUserImportManager()}
 }
 
 var validAssetTypes = map[string]struct{}{
@@ -77,11 +98,27 @@ func (h *AssetsHandler) List(w http.ResponseWriter, r *http.Request) {
 	if canManage && (q.Get("include_deleted") == "1" || strings.ToLower(q.Get("include_deleted")) == "true") {
 		filter.IncludeDeleted = true
 	}
+	conditions, err := h.fields.Conditions(r.Context(), store.CustomFieldEntityAsset, roles, q)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	filter.CustomFields = conditions
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityAsset, q, user.ID, roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	items, err := h.store.ListAssets(r.Context(), filter)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	h.attachCustomFields(r, roles, items)
 	writeJSON(w, http.StatusOK, map[string]any{"items": items})
 }
 
@@ -100,7 +137,8 @@ func (h *AssetsHandler) ListLite(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AssetsHandler) Get(w http.ResponseWriter, r *http.Request) {
-	if _, _, err := h.currentUser(r); err != nil {
+	_, roles, err := h.currentUser(r)
+	if err != nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -114,11 +152,13 @@ func (h *AssetsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "assets.error.notFound", http.StatusNotFound)
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	items := []store.Asset{*item}
+	h.attachCustomFields(r, roles, items)
+	writeJSON(w, http.StatusOK, items[0])
 }
 
 func (h *AssetsHandler) Create(w http.ResponseWriter, r *http.Request) {
-	user, _, err := h.currentUser(r)
+	user, roles, err := h.currentUser(r)
 	if err != nil || user == nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
@@ -133,18 +173,31 @@ func (h *AssetsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityAsset, roles, payload.CustomFields, true)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	a.CreatedBy = &user.ID
 	a.UpdatedBy = &user.ID
 	if _, err := h.store.CreateAsset(r.Context(), a); err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityAsset, a.ID, values, user.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	h.logAudit(r.Context(), user.Username, "assets.create", strconv.FormatInt(a.ID, 10))
-	writeJSON(w, http.StatusCreated, a)
+	items := []store.Asset{*a}
+	h.attachCustomFields(r, roles, items)
+	writeJSON(w, http.StatusCreated, items[0])
 }
 
 func (h *AssetsHandler) Update(w http.ResponseWriter, r *http.Request) {
-	user, _, err := h.currentUser(r)
+	user, roles, err := h.currentUser(r)
 	if err != nil || user == nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
@@ -173,6 +226,13 @@ func (h *AssetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityAsset, roles, payload.CustomFields, false)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	next.ID = existing.ID
 	next.CreatedAt = existing.CreatedAt
 	next.CreatedBy = existing.CreatedBy
@@ -186,8 +246,14 @@ func (h *AssetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityAsset, next.ID, values, user.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	h.logAudit(r.Context(), user.Username, "assets.update", strconv.FormatInt(next.ID, 10))
-	writeJSON(w, http.StatusOK, next)
+	items := []store.Asset{*next}
+	h.attachCustomFields(r, roles, items)
+	writeJSON(w, http.StatusOK, items[0])
 }
 
 func (h *AssetsHandler) Archive(w http.ResponseWriter, r *http.Request) {
@@ -229,17 +295,52 @@ func (h *AssetsHandler) Restore(w http.ResponseWriter, r *http.Request) {
 }
 
 type assetPayload struct {
-	Name           string   `json:"name"`
-	Type           string   `json:"type"`
-	Description    string   `json:"description"`
-	CommissionedAt string   `json:"commissioned_at"`
-	IPAddresses    []string `json:"ip_addresses"`
-	Criticality    string   `json:"criticality"`
-	Owner          string   `json:"owner"`
-	Administrator  string   `json:"administrator"`
-	Env            string   `json:"env"`
-	Status         string   `json:"status"`
-	Tags           []string `json:"tags"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	Description    string         `json:"description"`
+	CommissionedAt string         `json:"commissioned_at"`
+	IPAddresses    []string       `json:"ip_addresses"`
+	Criticality    string         `json:"criticality"`
+	Owner          string         `json:"owner"`
+	Administrator  string         `json:"administrator"`
+	Env            string         `json:"env"`
+	Status         string         `json:"status"`
+	Tags           []string       `json:"tags"`
+	CustomFields   map[string]any `json:"custom_fields"`
+}
+
+// SetCustomFields enables admin-defined fields on assets.
+func (h *AssetsHandler) SetCustomFields(svc *customfields.Service) {
+	if h == nil {
+		return
+	}
+	h.fields = svc
+}
+
+// SetQueries enables the query and saved_search filters of the assets list.
+func (h *AssetsHandler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
+// attachCustomFields fills the custom field values visible to the roles.
+func (h *AssetsHandler) attachCustomFields(r *http.Request, roles []string, items []store.Asset) {
+	if h.fields == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	values, err := h.fields.Values(r.Context(), store.CustomFieldEntityAsset, roles, ids)
+	if err != nil {
+		return
+	}
+	for i := range items {
+		items[i].CustomFields = values[items[i].ID]
+	}
 }
 
 func (p assetPayload) toAsset() (*store.Asset, error) {
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const assetHistoryDefaultLimit = 100
+
+// SetHistory enables the change history and the point-in-time view of assets.
+func (h *AssetsHandler) SetHistory(history store.AssetHistoryStore) {
+	if h == nil {
+		return
+	}
+	h.history = history
+}
+
+type assetHistoryItem struct {
+	store.AssetChange
+	ActorName   string `json:"actor_name,omitempty"`
+	ProductName string `json:"product_name,omitempty"`
+}
+
+func (h *AssetsHandler) History(w http.ResponseWriter, r *http.Request) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.history == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	limit := int(parseInt64Default(query.Get("limit"), assetHistoryDefaultLimit))
+	if limit <= 0 || limit > 500 {
+		limit = assetHistoryDefaultLimit
+	}
+	offset := int(parseInt64Default(query.Get("offset"), 0))
+	if offset < 0 {
+		offset = 0
+	}
+	changes, err := h.history.ListAssetHistory(r.Context(), id, store.AssetHistoryFilter{Limit: limit, Offset: offset})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	showSoftware := h.policy != nil && h.policy.Allowed(roles, "software.view")
+	products := map[int64]string{}
+	if showSoftware && h.sw != nil {
+		installs, _ := h.sw.ListAssetSoftware(r.Context(), id, true)
+		for _, inst := range installs {
+			products[inst.ID] = inst.ProductName
+		}
+	}
+	actors := map[int64]string{}
+	items := make([]assetHistoryItem, 0, len(changes))
+	for _, change := range changes {
+		if change.EntityType == store.AssetHistoryEntitySoftware && !showSoftware {
+			continue
+		}
+		item := assetHistoryItem{AssetChange: change}
+		if change.EntityType == store.AssetHistoryEntitySoftware {
+			item.ProductName = products[change.EntityID]
+		}
+		if change.ActorID != nil {
+			name, ok := actors[*change.ActorID]
+			if !ok {
+				if u, _, err := h.users.Get(r.Context(), *change.ActorID); err == nil && u != nil {
+					name = u.Username
+				}
+				actors[*change.ActorID] = name
+			}
+			item.ActorName = name
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+// AsOf returns the asset and its software as they were at the "at" query parameter,
+// an RFC 3339 timestamp or a date.
+func (h *AssetsHandler) AsOf(w http.ResponseWriter, r *http.Request) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if h.history == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	at, ok := parseAssetAsOf(r.URL.Query().Get("at"))
+	if !ok {
+		http.Error(w, "assets.history.badTime", http.StatusBadRequest)
+		return
+	}
+	asset, software, err := h.history.AssetAsOf(r.Context(), id, at)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if asset == nil {
+		http.Error(w, "assets.history.notExisted", http.StatusNotFound)
+		return
+	}
+	if h.policy == nil || !h.policy.Allowed(roles, "software.view") {
+		software = nil
+	}
+	if software == nil {
+		software = []store.AssetSoftwareInstallation{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"at": at, "asset": asset, "software": software})
+}
+
+// parseAssetAsOf accepts RFC 3339 timestamps, datetime-local values (treated as UTC)
+// and plain dates, which mean the end of that day.
+func parseAssetAsOf(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), true
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), true
+		}
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond).UTC(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/core/store"
+)
+
+func (h *AssetsHandler) ImportUpload(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	session, ok := readTableUpload(w, r, h.imports)
+	if !ok {
+		return
+	}
+	h.logAudit(r.Context(), user.Username, "assets.import.start", fmt.Sprintf("%s|%d", session.ID, len(session.Rows)))
+	writeTableUpload(w, session)
+}
+
+// ImportCommit creates or updates assets from an uploaded table. Rows are matched to
+// existing assets by name, then by any of their IP addresses; mapped non-empty cells
+// overwrite the asset fields, IP addresses and tags are merged.
+func (h *AssetsHandler) ImportCommit(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, session, ok := loadTableImport(w, r, h.imports, "name")
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	idx, err := importer.LoadAssetIndex(ctx, h.store)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	val := session.fieldReader(payload.Mapping)
+	report := tableImportReport{importReport: importReport{TotalRows: len(session.Rows)}, DryRun: payload.DryRun}
+	for i, row := range session.Rows {
+		rowNum := i + 2 // account for header row
+		name := val(row, "name")
+		if name == "" {
+			report.fail(rowNum, "invalid_value", "name")
+			continue
+		}
+		ips := importer.SplitList(val(row, "ip_addresses"))
+		if err := validateIPList(ips); err != nil {
+			report.fail(rowNum, "invalid_value", "ip_addresses")
+			continue
+		}
+		existing := idx.Match(name, ips)
+		p := assetPayload{Name: name}
+		if existing != nil {
+			p = assetPayloadFrom(existing)
+		}
+		setIfMapped(&p.Type, val(row, "type"))
+		setIfMapped(&p.Description, val(row, "description"))
+		setIfMapped(&p.Criticality, val(row, "criticality"))
+		setIfMapped(&p.Owner, val(row, "owner"))
+		setIfMapped(&p.Administrator, val(row, "administrator"))
+		setIfMapped(&p.Env, val(row, "env"))
+		setIfMapped(&p.Status, val(row, "status"))
+		p.IPAddresses = importer.MergeIPs(p.IPAddresses, ips)
+		p.Tags = mergeTags(p.Tags, importer.SplitList(val(row, "tags")))
+		if v := val(row, "commissioned_at"); v != "" {
+			t, err := importer.ParseDate(v)
+			if err != nil {
+				report.fail(rowNum, "invalid_value", "commissioned_at")
+				continue
+			}
+			p.CommissionedAt = t.Format("2006-01-02")
+		}
+		next, err := p.toAsset()
+		if err != nil {
+			report.fail(rowNum, "invalid_value", err.Error())
+			continue
+		}
+		next.UpdatedBy = &user.ID
+		if existing != nil {
+			next.ID = existing.ID
+			next.CreatedAt = existing.CreatedAt
+			next.CreatedBy = existing.CreatedBy
+			if !payload.DryRun {
+				if err := h.store.UpdateAsset(ctx, next); err != nil {
+					report.fail(rowNum, "invalid_value", "update")
+					continue
+				}
+			}
+			report.UpdatedCount++
+			idx.Put(next)
+			continue
+		}
+		next.CreatedBy = &user.ID
+		if !payload.DryRun {
+			id, err := h.store.CreateAsset(ctx, next)
+			if err != nil {
+				report.fail(rowNum, "invalid_value", "create")
+				continue
+			}
+			next.ID = id
+		}
+		report.CreatedCount++
+		idx.Put(next)
+	}
+	report.FailedCount = len(report.Failures)
+	if !payload.DryRun {
+		h.logAudit(ctx, user.Username, "assets.import.commit", fmt.Sprintf("%d|%d|%d|%d", report.TotalRows, report.CreatedCount, report.UpdatedCount, report.FailedCount))
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ImportNmap creates or updates host assets from an Nmap XML report (multipart field
+// "file") and records their open ports in the description. With preview=1 only the
+// plan is returned.
+func (h *AssetsHandler) ImportNmap(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	data, ok := readScanUpload(w, r)
+	if !ok {
+		return
+	}
+	hosts, err := importer.ParseNmap(data)
+	if err != nil {
+		http.Error(w, "import.scan.formatInvalid", http.StatusBadRequest)
+		return
+	}
+	plan, err := importer.BuildHostPlan(r.Context(), h.store, hosts)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if parseBool(r.FormValue("preview")) {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+	if err := importer.ApplyHostPlan(r.Context(), h.store, plan, user.ID, time.Now().UTC()); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.logAudit(r.Context(), user.Username, "assets.import.nmap", fmt.Sprintf("hosts=%d added=%d updated=%d", plan.Hosts, plan.Added, plan.Updated))
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// readScanUpload returns the scanner report of a multipart upload (field "file").
+func readScanUpload(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if err := parseMultipartFormLimited(w, r, 64<<20); err != nil {
+		return nil, false
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "import.fileRequired", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 64<<20))
+	if err != nil {
+		http.Error(w, "import.scan.formatInvalid", http.StatusBadRequest)
+		return nil, false
+	}
+	return data, true
+}
+
+func assetPayloadFrom(a *store.Asset) assetPayload {
+	p := assetPayload{
+		Name:          a.Name,
+		Type:          a.Type,
+		Description:   a.Description,
+		IPAddresses:   a.IPAddresses,
+		Criticality:   a.Criticality,
+		Owner:         a.Owner,
+		Administrator: a.Administrator,
+		Env:           a.Env,
+		Status:        a.Status,
+		Tags:          a.Tags,
+	}
+	if a.CommissionedAt != nil {
+		p.CommissionedAt = a.CommissionedAt.UTC().Format("2006-01-02")
+	}
+	return p
+}
+
+func setIfMapped(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func mergeTags(base, extra []string) []string {
+	out := append([]string{}, base...)
+	for _, t := range extra {
+		dup := false
+		for _, existing := range out {
+			if strings.EqualFold(existing, t) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out = append(out, t)
+		}
+	}
+	return out
+}
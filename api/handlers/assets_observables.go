@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (h *AssetsHandler) ListObservables(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := h.currentUser(r); err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	listEntityObservables(w, r, h.observables, "asset", id)
+}
+
+func (h *AssetsHandler) AddObservable(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	if item, ok := addEntityObservable(w, r, h.observables, "asset", id, user.ID); ok {
+		h.logAudit(r.Context(), user.Username, "assets.observable.add", fmt.Sprintf("%d|%s:%s", id, item.Type, item.Value))
+	}
+}
+
+func (h *AssetsHandler) DeleteObservable(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := h.existingAssetID(w, r)
+	if !ok {
+		return
+	}
+	if item, ok := deleteEntityObservable(w, r, h.observables, "asset", id); ok {
+		h.logAudit(r.Context(), user.Username, "assets.observable.remove", fmt.Sprintf("%d|%s:%s", id, item.Type, item.Value))
+	}
+}
+
+func (h *AssetsHandler) existingAssetID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "assets.error.badRequest", http.StatusBadRequest)
+		return 0, false
+	}
+	item, err := h.store.GetAsset(r.Context(), id)
+	if err != nil || item == nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"berkut-scc/core/sbom"
+)
+
+// ImportSBOM loads a CycloneDX or SPDX JSON SBOM (multipart field "file") into the asset
+// software inventory. With preview=1 the computed diff is returned and nothing is changed.
+func (h *AssetsHandler) ImportSBOM(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.requireSoftwareManage(w, r)
+	if !ok {
+		return
+	}
+	assetID := parseInt64Default(pathParams(r)["id"], 0)
+	if assetID <= 0 {
+		http.Error(w, "assets.error.badRequest", http.StatusBadRequest)
+		return
+	}
+	asset, err := h.store.GetAsset(r.Context(), assetID)
+	if err != nil || asset == nil || asset.DeletedAt != nil {
+		http.Error(w, "assets.error.notFound", http.StatusNotFound)
+		return
+	}
+	if err := parseMultipartFormLimited(w, r, 32<<20); err != nil {
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "assets.sbom.fileRequired", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 32<<20))
+	if err != nil {
+		http.Error(w, "assets.sbom.formatInvalid", http.StatusBadRequest)
+		return
+	}
+	doc, err := sbom.Parse(data)
+	if err != nil {
+		if errors.Is(err, sbom.ErrFormatInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	plan, err := sbom.BuildPlan(r.Context(), h.sw, assetID, doc)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if parseBool(r.FormValue("preview")) {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+	if err := sbom.Apply(r.Context(), h.sw, assetID, plan, user.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.logAudit(r.Context(), user.Username, "assets.software.sbom.import", fmt.Sprintf("%d|%s|added=%d updated=%d unchanged=%d archived=%d products=%d versions=%d",
+		assetID, plan.Format, plan.Added, plan.Updated, plan.Unchanged, plan.Archived, plan.NewProducts, plan.NewVersions))
+	h.rescanVulnerabilities(r, user)
+	writeJSON(w, http.StatusOK, plan)
+}
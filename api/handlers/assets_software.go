@@ -118,6 +118,7 @@ func (h *AssetsHandler) AddSoftware(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "assets.software.add", strconv.FormatInt(id, 10))
+	h.rescanVulnerabilities(r, user)
 	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
 }
 
@@ -164,6 +165,7 @@ func (h *AssetsHandler) UpdateSoftware(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "assets.software.update", strconv.FormatInt(instID, 10))
+	h.rescanVulnerabilities(r, user)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
@@ -182,6 +184,7 @@ func (h *AssetsHandler) ArchiveSoftware(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "assets.software.archive", strconv.FormatInt(instID, 10))
+	h.rescanVulnerabilities(r, user)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
@@ -200,9 +203,23 @@ func (h *AssetsHandler) RestoreSoftware(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "assets.software.restore", strconv.FormatInt(instID, 10))
+	h.rescanVulnerabilities(r, user)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
+// rescanVulnerabilities refreshes vulnerability matches of the asset after its software changed,
+// so upgraded or removed versions resolve their findings. Failures are not fatal.
+func (h *AssetsHandler) rescanVulnerabilities(r *http.Request, user *store.User) {
+	if h.vulns == nil {
+		return
+	}
+	assetID := parseInt64Default(pathParams(r)["id"], 0)
+	if assetID <= 0 {
+		return
+	}
+	_, _ = h.vulns.Scan(r.Context(), assetID, user.Username, user.ID)
+}
+
 func (h *AssetsHandler) requireSoftwareView(w http.ResponseWriter, r *http.Request) (*store.User, []string, bool) {
 	return h.requireSoftwarePerm(w, r, "software.view")
 }
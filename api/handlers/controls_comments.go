@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"berkut-scc/core/notify"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
@@ -105,6 +106,7 @@ func (h *ControlsHandler) AddControlComment(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "control.comment.add", fmt.Sprintf("%d", control.ID))
+	h.recordCommentMentions(r.Context(), control, comment, user)
 	prepareControlCommentAttachments(controlID, comment)
 	writeJSON(w, http.StatusCreated, comment)
 }
@@ -157,6 +159,7 @@ func (h *ControlsHandler) UpdateControlComment(w http.ResponseWriter, r *http.Re
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "control.comment.update", fmt.Sprintf("%d|%d", control.ID, comment.ID))
+	h.recordCommentMentions(r.Context(), control, comment, user)
 	prepareControlCommentAttachments(controlID, comment)
 	writeJSON(w, http.StatusOK, comment)
 }
@@ -201,6 +204,7 @@ func (h *ControlsHandler) DeleteControlComment(w http.ResponseWriter, r *http.Re
 		return
 	}
 	h.logAudit(r.Context(), user.Username, "control.comment.delete", fmt.Sprintf("%d|%d", control.ID, comment.ID))
+	h.notifier.ClearMentions(r.Context(), notify.SourceControlComment, fmt.Sprintf("%d", comment.ID))
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -305,6 +309,7 @@ func (h *ControlsHandler) DeleteControlCommentFile(w http.ResponseWriter, r *htt
 			return
 		}
 		h.logAudit(r.Context(), user.Username, "control.comment.delete", fmt.Sprintf("%d|%d", control.ID, comment.ID))
+		h.notifier.ClearMentions(r.Context(), notify.SourceControlComment, fmt.Sprintf("%d", comment.ID))
 		writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 		return
 	}
@@ -11,6 +11,7 @@ import (
 
 	"berkut-scc/core/auth"
 	"berkut-scc/core/controls"
+	"berkut-scc/core/notify"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
@@ -29,6 +30,7 @@ type ControlsHandler struct {
 	audits    store.AuditStore
 	policy    *rbac.Policy
 	logger    *utils.Logger
+	notifier  *notify.Service
 }
 
 func NewControlsHandler(cs store.ControlsStore, links store.EntityLinksStore, us store.UsersStore, ds store.DocsStore, is store.IncidentsStore, ts tasks.Store, assets store.AssetsStore, software store.SoftwareStore, audits store.AuditStore, policy *rbac.Policy, logger *utils.Logger) *ControlsHandler {
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+)
+
+func (h *ControlsHandler) SetNotifier(n *notify.Service) {
+	h.notifier = n
+}
+
+// recordCommentMentions stores @mentions of a control comment for users allowed to view controls.
+func (h *ControlsHandler) recordCommentMentions(ctx context.Context, control *store.Control, comment *store.ControlComment, author *store.User) {
+	if h.notifier == nil || control == nil || comment == nil {
+		return
+	}
+	title := strings.TrimSpace(control.Code + " " + control.Title)
+	src := notify.MentionSource{
+		SourceType: notify.SourceControlComment,
+		SourceID:   fmt.Sprintf("%d", comment.ID),
+		EntityType: "control",
+		EntityID:   fmt.Sprintf("%d", control.ID),
+		Title:      title,
+		Link:       fmt.Sprintf("/registry/controls?control=%d", control.ID),
+	}
+	h.notifier.RecordMentions(ctx, src, author, comment.Content, func(ctx context.Context, u *store.User, roles []string) bool {
+		groups, _ := h.users.UserGroups(ctx, u.ID)
+		eff := auth.CalculateEffectiveAccess(u, roles, groups, h.policy)
+		return h.policy.Allowed(eff.Roles, "controls.view")
+	})
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+)
+
+const (
+	customFieldAuditCreate  = "custom_fields.create"
+	customFieldAuditUpdate  = "custom_fields.update"
+	customFieldAuditArchive = "custom_fields.archive"
+)
+
+// CustomFieldsHandler manages admin-defined fields of incidents, assets, findings and
+// tasks. Values are read and written through the entity handlers.
+type CustomFieldsHandler struct {
+	store  store.CustomFieldsStore
+	audits store.AuditStore
+	policy *rbac.Policy
+}
+
+func NewCustomFieldsHandler(cf store.CustomFieldsStore, audits store.AuditStore, policy *rbac.Policy) *CustomFieldsHandler {
+	return &CustomFieldsHandler{store: cf, audits: audits, policy: policy}
+}
+
+type customFieldView struct {
+	store.CustomField
+	CanEdit bool `json:"can_edit"`
+}
+
+// List returns the active fields of an entity type that the current user may see.
+func (h *CustomFieldsHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	entityType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("entity_type")))
+	if entityType != "" && !customfields.ValidEntityType(entityType) {
+		http.Error(w, "customFields.error.entityType", http.StatusBadRequest)
+		return
+	}
+	fields, err := h.store.ListCustomFields(r.Context(), entityType, false)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	items := make([]customFieldView, 0, len(fields))
+	for _, f := range fields {
+		if !customfields.CanView(f, sess.Roles) {
+			continue
+		}
+		items = append(items, customFieldView{CustomField: f, CanEdit: customfields.CanEdit(f, sess.Roles)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// ListAll returns every field definition, archived ones included, for the settings page.
+func (h *CustomFieldsHandler) ListAll(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "settings.custom_fields"); !ok {
+		return
+	}
+	fields, err := h.store.ListCustomFields(r.Context(), "", true)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if fields == nil {
+		fields = []store.CustomField{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": fields})
+}
+
+func (h *CustomFieldsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "settings.custom_fields")
+	if !ok {
+		return
+	}
+	var payload store.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := customfields.NormalizeDefinition(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload.CreatedBy = &sess.UserID
+	id, err := h.store.CreateCustomField(r.Context(), &payload)
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			http.Error(w, "customFields.error.duplicateKey", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, customFieldAuditCreate, payload.EntityType+"."+payload.Key)
+	created, err := h.store.GetCustomField(r.Context(), id)
+	if err != nil || created == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// Update changes a field definition. The entity type, key and value type cannot be
+// changed because stored values depend on them.
+func (h *CustomFieldsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "settings.custom_fields"); !ok {
+		return
+	}
+	existing, ok := h.existingField(w, r)
+	if !ok {
+		return
+	}
+	var payload store.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	payload.ID = existing.ID
+	payload.EntityType = existing.EntityType
+	payload.Key = existing.Key
+	payload.FieldType = existing.FieldType
+	if err := customfields.NormalizeDefinition(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.UpdateCustomField(r.Context(), &payload); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "customFields.error.notFound", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, customFieldAuditUpdate, existing.EntityType+"."+existing.Key)
+	updated, err := h.store.GetCustomField(r.Context(), existing.ID)
+	if err != nil || updated == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Archive hides the field from forms, lists and exports; stored values are kept.
+func (h *CustomFieldsHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "settings.custom_fields"); !ok {
+		return
+	}
+	existing, ok := h.existingField(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.ArchiveCustomField(r.Context(), existing.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "customFields.error.notFound", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, customFieldAuditArchive, existing.EntityType+"."+existing.Key)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *CustomFieldsHandler) existingField(w http.ResponseWriter, r *http.Request) (*store.CustomField, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	f, err := h.store.GetCustomField(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if f == nil || f.DeletedAt != nil {
+		http.Error(w, "customFields.error.notFound", http.StatusNotFound)
+		return nil, false
+	}
+	return f, true
+}
+
+func (h *CustomFieldsHandler) session(w http.ResponseWriter, r *http.Request) (*store.SessionRecord, bool) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return val.(*store.SessionRecord), true
+}
+
+func (h *CustomFieldsHandler) requirePermission(w http.ResponseWriter, r *http.Request, perm rbac.Permission) (*store.SessionRecord, bool) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return nil, false
+	}
+	if h.policy != nil && !h.policy.Allowed(sess.Roles, perm) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return sess, true
+}
+
+func (h *CustomFieldsHandler) audit(r *http.Request, action, details string) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), currentUsername(r), action, details)
+}
+
+// customFieldExport returns the fields visible to the roles and their printable values
+// for CSV exports. Errors only drop the extra columns.
+func customFieldExport(ctx context.Context, svc *customfields.Service, entityType string, roles []string, ids []int64) ([]store.CustomField, map[int64]map[string]string) {
+	fields, err := svc.Fields(ctx, entityType, roles)
+	if err != nil || len(fields) == 0 {
+		return nil, nil
+	}
+	values, err := svc.DisplayValues(ctx, entityType, fields, ids)
+	if err != nil {
+		return nil, nil
+	}
+	return fields, values
+}
+
+// customFieldHeaders names the export columns of custom fields as cf.<key>.
+func customFieldHeaders(fields []store.CustomField) []string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, customfields.FilterPrefix+f.Key)
+	}
+	return out
+}
+
+func customFieldRow(fields []store.CustomField, values map[string]string) []string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, values[f.Key])
+	}
+	return out
+}
+
+// customFieldError writes an invalid custom field error and reports whether err was one.
+func customFieldError(w http.ResponseWriter, err error) bool {
+	var fieldErr *customfields.FieldError
+	if errors.As(err, &fieldErr) {
+		http.Error(w, fieldErr.Error(), http.StatusBadRequest)
+		return true
+	}
+	return false
+}
@@ -32,6 +32,7 @@ type DashboardHandler struct {
 	audits         store.AuditStore
 	policy         *rbac.Policy
 	logger         *utils.Logger
+	searches       store.SavedSearchesStore
 }
 
 type DashboardLayout struct {
@@ -72,7 +73,7 @@ func (h *DashboardHandler) Data(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	perms := permissionsMap(eff.Permissions)
-	allowedFrames := h.allowedFrames(perms)
+	allowedFrames := h.allowedFrames(r.Context(), user.ID, perms)
 
 	defaultLayout := defaultDashboardLayout(eff.Roles, perms, allowedFrames)
 	layout, err := h.loadLayout(r.Context(), user.ID, eff.Roles, perms, allowedFrames)
@@ -108,7 +109,7 @@ func (h *DashboardHandler) SaveLayout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	perms := permissionsMap(eff.Permissions)
-	allowedFrames := h.allowedFrames(perms)
+	allowedFrames := h.allowedFrames(r.Context(), user.ID, perms)
 	layout := sanitizeLayout(payload.Layout, allowedFrames)
 	layout = ensureDefaultSettings(layout)
 	raw, err := json.Marshal(layout)
@@ -499,7 +500,7 @@ func (h *DashboardHandler) canViewByClassification(eff store.EffectiveAccess, le
 	return eff.ClearanceLevel >= level
 }
 
-func (h *DashboardHandler) allowedFrames(perms map[string]bool) []map[string]string {
+func (h *DashboardHandler) allowedFrames(ctx context.Context, userID int64, perms map[string]bool) []map[string]string {
 	frames := []struct {
 		ID    string
 		Title string
@@ -520,7 +521,7 @@ func (h *DashboardHandler) allowedFrames(perms map[string]bool) []map[string]str
 		}
 		allowed = append(allowed, map[string]string{"id": f.ID, "title": f.Title})
 	}
-	return allowed
+	return append(allowed, h.savedSearchFrames(ctx, userID, perms)...)
 }
 
 func permissionsMap(perms []string) map[string]bool {
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"berkut-scc/core/store"
+)
+
+// savedSearchFramePrefix starts the frame id of a saved search: saved_search.<id>.
+const savedSearchFramePrefix = "saved_search."
+
+// SetSavedSearches enables dashboard frames for saved searches marked for the dashboard.
+func (h *DashboardHandler) SetSavedSearches(ss store.SavedSearchesStore) {
+	if h == nil {
+		return
+	}
+	h.searches = ss
+}
+
+// savedSearchFrames returns a frame for every visible saved search marked for the
+// dashboard whose entity the user may view. The frame lists the first results of
+// the search through the entity list endpoint.
+func (h *DashboardHandler) savedSearchFrames(ctx context.Context, userID int64, perms map[string]bool) []map[string]string {
+	if h.searches == nil {
+		return nil
+	}
+	items, err := h.searches.ListSavedSearches(ctx, userID, "")
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Errorf("dashboard saved searches: %v", err)
+		}
+		return nil
+	}
+	var out []map[string]string
+	for _, item := range items {
+		perm, ok := savedSearchPermissions[item.EntityType]
+		if !item.Dashboard || !ok || !perms[string(perm)] {
+			continue
+		}
+		out = append(out, map[string]string{
+			"id":          savedSearchFramePrefix + strconv.FormatInt(item.ID, 10),
+			"title":       item.Name,
+			"entity_type": item.EntityType,
+			"search_id":   strconv.FormatInt(item.ID, 10),
+		})
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+)
+
+// notifyApprovers asks the approvers of the given stage to review the document.
+func (h *DocsHandler) notifyApprovers(ctx context.Context, approvalID int64, doc *store.Document, parts []store.ApprovalParticipant, stage int, actor *store.User) {
+	n := h.svc.Notifier()
+	if n == nil || doc == nil {
+		return
+	}
+	var ids []int64
+	stageName := ""
+	for _, p := range parts {
+		if p.Role == "approver" && p.Stage == stage {
+			ids = append(ids, p.UserID)
+			stageName = p.StageName
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	title := strings.TrimSpace(doc.Title)
+	if reg := strings.TrimSpace(doc.RegNumber); reg != "" {
+		title = reg + " " + title
+	}
+	item := store.Notification{
+		EventType:  notify.EventApprovalRequested,
+		Title:      title,
+		Body:       stageName,
+		EntityType: "approval",
+		EntityID:   fmt.Sprintf("%d", approvalID),
+		Link:       fmt.Sprintf("/approvals/%d", approvalID),
+	}
+	if actor != nil {
+		item.ActorID = &actor.ID
+	}
+	n.Notify(ctx, item, ids...)
+}
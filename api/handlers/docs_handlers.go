@@ -17,6 +17,7 @@ import (
 	"berkut-scc/config"
 	"berkut-scc/core/auth"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
@@ -36,6 +37,7 @@ type DocsHandler struct {
 	logger      *utils.Logger
 	uploads     map[string]uploadItem
 	officeSaves map[string]onlyOfficePendingSave
+	queries     *query.Service
 	mu          sync.Mutex
 }
 
@@ -67,6 +69,14 @@ func NewDocsHandler(cfg *config.AppConfig, ds store.DocsStore, links store.Entit
 	}
 }
 
+// SetQueries enables the query and saved_search filters of the document list.
+func (h *DocsHandler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
 func (h *DocsHandler) List(w http.ResponseWriter, r *http.Request) {
 	user, roles, err := h.currentUser(r)
 	if err != nil || user == nil {
@@ -109,6 +119,13 @@ func (h *DocsHandler) List(w http.ResponseWriter, r *http.Request) {
 	if q := r.URL.Query().Get("status_in"); q != "" {
 		filter.StatusIn = strings.Split(q, ",")
 	}
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityDoc, r.URL.Query(), user.ID, roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	docsList, err := h.store.ListDocuments(r.Context(), filter)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
@@ -951,6 +968,7 @@ func (h *DocsHandler) StartApproval(w http.ResponseWriter, r *http.Request) {
 	doc.Status = docs.StatusReview
 	_ = h.store.UpdateDocument(r.Context(), doc)
 	h.svc.Log(r.Context(), user.Username, "approval.start", doc.RegNumber)
+	h.notifyApprovers(r.Context(), approvalID, doc, participants, 1, user)
 	writeJSON(w, http.StatusOK, map[string]any{"approval_id": approvalID})
 }
 
@@ -1165,6 +1183,9 @@ func (h *DocsHandler) ApprovalDecision(w http.ResponseWriter, r *http.Request) {
 		action = "approval.reject"
 	}
 	h.svc.Log(r.Context(), user.Username, action, fmt.Sprintf("%d", ap.ID))
+	if nextStage != currentStage && newStatus == docs.StatusReview {
+		h.notifyApprovers(r.Context(), ap.ID, doc, parts, nextStage, user)
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"status": newStatus})
 }
 
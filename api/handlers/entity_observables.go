@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"berkut-scc/core/store"
+)
+
+// Findings and assets share the incident observable model but only need plain list/add/delete;
+// correlation, TLP-aware export and bulk import stay on the incident side.
+
+func listEntityObservables(w http.ResponseWriter, r *http.Request, obs store.ObservablesStore, entityType string, entityID int64) {
+	if obs == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"items": []store.Observable{}})
+		return
+	}
+	items, err := obs.ListObservables(r.Context(), entityType, entityID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.Observable{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func addEntityObservable(w http.ResponseWriter, r *http.Request, obs store.ObservablesStore, entityType string, entityID int64, userID int64) (*store.Observable, bool) {
+	if obs == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	var payload observablePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	item, err := observableFromPayload(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	item.EntityType = entityType
+	item.EntityID = entityID
+	item.Source = "manual"
+	if userID > 0 {
+		item.CreatedBy = &userID
+		item.UpdatedBy = &userID
+	}
+	if _, err := obs.UpsertObservable(r.Context(), item); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	writeJSON(w, http.StatusCreated, item)
+	return item, true
+}
+
+func deleteEntityObservable(w http.ResponseWriter, r *http.Request, obs store.ObservablesStore, entityType string, entityID int64) (*store.Observable, bool) {
+	if obs == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return nil, false
+	}
+	obsID := parseInt64Default(pathParams(r)["obs_id"], 0)
+	item, err := obs.GetObservable(r.Context(), obsID)
+	if err != nil || item == nil || item.EntityType != entityType || item.EntityID != entityID {
+		http.Error(w, "not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err := obs.DeleteObservable(r.Context(), item.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	return item, true
+}
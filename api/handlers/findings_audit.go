@@ -11,6 +11,12 @@ const (
 	findingAuditRestore = "finding.restore"
 	findingAuditLinkAdd = "finding.link.add"
 	findingAuditLinkDel = "finding.link.remove"
+	findingAuditObsAdd  = "finding.observable.add"
+	findingAuditObsDel  = "finding.observable.remove"
+
+	findingAuditImportStart  = "finding.import.start"
+	findingAuditImportCommit = "finding.import.commit"
+	findingAuditImportScan   = "finding.import.scan"
 )
 
 func (h *FindingsHandler) audit(r *http.Request, action, details string) {
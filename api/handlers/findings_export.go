@@ -30,6 +30,21 @@ func (h *FindingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	if canManage && parseBool(q.Get("include_deleted")) {
 		filter.IncludeDeleted = true
 	}
+	conditions, err := h.fields.Conditions(r.Context(), store.CustomFieldEntityFinding, sess.Roles, q)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	filter.CustomFields = conditions
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityFinding, q, sess.UserID, sess.Roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	if filter.Limit <= 0 || filter.Limit > 5000 {
 		filter.Limit = 5000
 	}
@@ -39,6 +54,11 @@ func (h *FindingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	ids := make([]int64, 0, len(items))
+	for _, f := range items {
+		ids = append(ids, f.ID)
+	}
+	fields, values := customFieldExport(r.Context(), h.fields, store.CustomFieldEntityFinding, sess.Roles, ids)
 
 	now := time.Now().UTC()
 	filename := fmt.Sprintf("findings_%s.csv", now.Format("20060102_150405"))
@@ -49,10 +69,10 @@ func (h *FindingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	h.audit(r, "finding.export.csv", strconv.Itoa(len(items)))
 
 	writer := csv.NewWriter(w)
-	_ = writer.Write([]string{
+	_ = writer.Write(append([]string{
 		"id", "title", "description_md", "status", "severity", "type", "owner", "due_at", "tags",
 		"created_at", "updated_at", "deleted_at",
-	})
+	}, customFieldHeaders(fields)...))
 	for _, f := range items {
 		due := ""
 		if f.DueAt != nil {
@@ -62,7 +82,7 @@ func (h *FindingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		if f.DeletedAt != nil {
 			deleted = f.DeletedAt.UTC().Format(time.RFC3339)
 		}
-		_ = writer.Write([]string{
+		_ = writer.Write(append([]string{
 			strconv.FormatInt(f.ID, 10),
 			f.Title,
 			f.DescriptionMD,
@@ -75,7 +95,7 @@ func (h *FindingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 			f.CreatedAt.UTC().Format(time.RFC3339),
 			f.UpdatedAt.UTC().Format(time.RFC3339),
 			deleted,
-		})
+		}, customFieldRow(fields, values[f.ID])...))
 	}
 	writer.Flush()
 }
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/findings"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 )
@@ -21,10 +24,17 @@ type FindingsHandler struct {
 	software store.SoftwareStore
 	policy   *rbac.Policy
 	audits   store.AuditStore
+
+	observables store.ObservablesStore
+	slaSvc      *findings.Service
+	sla         store.FindingSLAStore
+	imports     *userImportManager
+	fields      *customfields.Service
+	queries     *query.Service
 }
 
-func NewFindingsHandler(fs store.FindingsStore, links store.EntityLinksStore, us store.UsersStore, assets store.AssetsStore, ctrls store.ControlsStore, software store.SoftwareStore, audits store.AuditStore, policy *rbac.Policy) *FindingsHandler {
-	return &FindingsHandler{store: fs, links: links, users: us, assets: assets, ctrls: ctrls, software: software, audits: audits, policy: policy}
+func NewFindingsHandler(fs store.FindingsStore, links store.EntityLinksStore, us store.UsersStore, assets store.AssetsStore, ctrls store.ControlsStore, software store.SoftwareStore, observables store.ObservablesStore, audits store.AuditStore, policy *rbac.Policy) *FindingsHandler {
+	return &FindingsHandler{store: fs, links: links, users: us, assets: assets, ctrls: ctrls, software: software, observables: observables, audits: audits, policy: policy, imports: newUserImportManager()}
 }
 
 var validFindingStatus = map[string]struct{}{
@@ -43,15 +53,18 @@ var validFindingSeverity = map[string]struct{}{
 }
 
 var validFindingType = map[string]struct{}{
-	"technical":  {},
-	"config":     {},
-	"process":    {},
-	"compliance": {},
-	"other":      {},
+	"technical":     {},
+	"config":        {},
+	"process":       {},
+	"compliance":    {},
+	"vulnerability": {},
+	"eol":           {},
+	"other":         {},
 }
 
 func (h *FindingsHandler) List(w http.ResponseWriter, r *http.Request) {
-	if _, ok := h.requirePermission(w, r, "findings.view"); !ok {
+	sess, ok := h.requirePermission(w, r, "findings.view")
+	if !ok {
 		return
 	}
 	q := r.URL.Query()
@@ -61,15 +74,32 @@ func (h *FindingsHandler) List(w http.ResponseWriter, r *http.Request) {
 		Severity:       strings.ToLower(strings.TrimSpace(q.Get("severity"))),
 		Type:           strings.ToLower(strings.TrimSpace(q.Get("type"))),
 		Tag:            q.Get("tag"),
+		Overdue:        parseBool(q.Get("overdue")),
 		IncludeDeleted: parseBool(q.Get("include_deleted")),
 		Limit:          parseIntDefault(q.Get("limit"), 0),
 		Offset:         parseIntDefault(q.Get("offset"), 0),
 	}
+	conditions, err := h.fields.Conditions(r.Context(), store.CustomFieldEntityFinding, sess.Roles, q)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	filter.CustomFields = conditions
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityFinding, q, sess.UserID, sess.Roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	items, err := h.store.ListFindings(r.Context(), filter)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	h.attachCustomFields(r, sess.Roles, items)
 	writeJSON(w, http.StatusOK, map[string]any{"items": items})
 }
 
@@ -87,7 +117,8 @@ func (h *FindingsHandler) ListLite(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *FindingsHandler) Get(w http.ResponseWriter, r *http.Request) {
-	if _, ok := h.requirePermission(w, r, "findings.view"); !ok {
+	sess, ok := h.requirePermission(w, r, "findings.view")
+	if !ok {
 		return
 	}
 	id := parseInt64Default(pathParams(r)["id"], 0)
@@ -104,7 +135,7 @@ func (h *FindingsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	h.writeFinding(w, r, sess.Roles, http.StatusOK, item)
 }
 
 func (h *FindingsHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -146,6 +177,13 @@ func (h *FindingsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "findings.typeInvalid", http.StatusBadRequest)
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityFinding, sess.Roles, payload.CustomFields, true)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	now := time.Now().UTC()
 	item := &store.Finding{
 		Title:         title,
@@ -162,14 +200,19 @@ func (h *FindingsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:     now,
 		Version:       1,
 	}
+	h.defaultDueAt(r, item)
 	id, err := h.store.CreateFinding(r.Context(), item)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
 	item.ID = id
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityFinding, id, values, sess.UserID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	h.audit(r, findingAuditCreate, strconv.FormatInt(id, 10))
-	writeJSON(w, http.StatusCreated, item)
+	h.writeFinding(w, r, sess.Roles, http.StatusCreated, item)
 }
 
 func (h *FindingsHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -230,6 +273,13 @@ func (h *FindingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "findings.versionRequired", http.StatusBadRequest)
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityFinding, sess.Roles, payload.CustomFields, false)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	updated := *existing
 	updated.Title = title
 	updated.DescriptionMD = strings.TrimSpace(payload.DescriptionMD)
@@ -241,6 +291,7 @@ func (h *FindingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	updated.Tags = payload.Tags
 	updated.UpdatedBy = &sess.UserID
 	updated.Version = expectedVersion
+	h.defaultDueAt(r, &updated)
 	if err := h.store.UpdateFinding(r.Context(), &updated); err != nil {
 		if err == store.ErrConflict {
 			http.Error(w, "findings.conflict", http.StatusConflict)
@@ -249,9 +300,57 @@ func (h *FindingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityFinding, id, values, sess.UserID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	h.audit(r, findingAuditUpdate, strconv.FormatInt(id, 10))
-	item, _ := h.store.GetFinding(r.Context(), id)
-	writeJSON(w, http.StatusOK, item)
+	item, err := h.store.GetFinding(r.Context(), id)
+	if err != nil || item == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeFinding(w, r, sess.Roles, http.StatusOK, item)
+}
+
+// SetCustomFields enables admin-defined fields on findings.
+func (h *FindingsHandler) SetCustomFields(svc *customfields.Service) {
+	if h == nil {
+		return
+	}
+	h.fields = svc
+}
+
+// SetQueries enables the query and saved_search filters of the findings list.
+func (h *FindingsHandler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
+// attachCustomFields fills the custom field values visible to the roles.
+func (h *FindingsHandler) attachCustomFields(r *http.Request, roles []string, items []store.Finding) {
+	if h.fields == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	values, err := h.fields.Values(r.Context(), store.CustomFieldEntityFinding, roles, ids)
+	if err != nil {
+		return
+	}
+	for i := range items {
+		items[i].CustomFields = values[items[i].ID]
+	}
+}
+
+func (h *FindingsHandler) writeFinding(w http.ResponseWriter, r *http.Request, roles []string, code int, item *store.Finding) {
+	items := []store.Finding{*item}
+	h.attachCustomFields(r, roles, items)
+	writeJSON(w, code, items[0])
 }
 
 func (h *FindingsHandler) Archive(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/core/store"
+)
+
+func (h *FindingsHandler) ImportUpload(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "findings.manage"); !ok {
+		return
+	}
+	session, ok := readTableUpload(w, r, h.imports)
+	if !ok {
+		return
+	}
+	h.audit(r, findingAuditImportStart, fmt.Sprintf("%s|%d", session.ID, len(session.Rows)))
+	writeTableUpload(w, session)
+}
+
+// ImportCommit creates or updates findings from an uploaded table. The optional asset
+// column holds an asset name or IP address; a row matches the finding with the same
+// title linked to that asset, or any finding with that title when no asset is given.
+// New findings are linked to their asset.
+func (h *FindingsHandler) ImportCommit(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.assets == nil || h.links == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	payload, session, ok := loadTableImport(w, r, h.imports, "title")
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	st := importer.FindingStores{Assets: h.assets, Findings: h.store, Links: h.links}
+	assets, err := importer.LoadAssetIndex(ctx, h.assets)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	all, err := listAllFindings(ctx, h.store, store.FindingFilter{})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	byTitle := map[string]*store.Finding{}
+	for i := range all {
+		key := strings.ToLower(strings.TrimSpace(all[i].Title))
+		if _, ok := byTitle[key]; !ok {
+			byTitle[key] = &all[i]
+		}
+	}
+	byAsset := map[int64][]store.Finding{}
+
+	val := session.fieldReader(payload.Mapping)
+	report := tableImportReport{importReport: importReport{TotalRows: len(session.Rows)}, DryRun: payload.DryRun}
+	now := time.Now().UTC()
+	for i, row := range session.Rows {
+		rowNum := i + 2 // account for header row
+		title := val(row, "title")
+		if title == "" {
+			report.fail(rowNum, "invalid_value", "title")
+			continue
+		}
+		var asset *store.Asset
+		if ref := val(row, "asset"); ref != "" {
+			if asset = assets.Match(ref, []string{ref}); asset == nil {
+				report.fail(rowNum, "asset_not_found", ref)
+				continue
+			}
+		}
+		var existing *store.Finding
+		if asset != nil {
+			linked, ok := byAsset[asset.ID]
+			if !ok {
+				if linked, err = importer.LinkedFindings(ctx, st, asset.ID); err != nil {
+					report.fail(rowNum, "invalid_value", "asset")
+					continue
+				}
+				byAsset[asset.ID] = linked
+			}
+			for j := range linked {
+				if strings.EqualFold(strings.TrimSpace(linked[j].Title), title) {
+					existing = &linked[j]
+					break
+				}
+			}
+		} else {
+			existing = byTitle[strings.ToLower(title)]
+		}
+
+		item := store.Finding{Title: title, Status: "open", Severity: "medium", FindingType: "other"}
+		if existing != nil {
+			item = *existing
+		}
+		setIfMapped(&item.DescriptionMD, val(row, "description_md"))
+		setIfMapped(&item.Owner, val(row, "owner"))
+		if v := strings.ToLower(val(row, "status")); v != "" {
+			item.Status = v
+		}
+		if v := strings.ToLower(val(row, "severity")); v != "" {
+			item.Severity = v
+		}
+		if v := strings.ToLower(val(row, "type")); v != "" {
+			item.FindingType = v
+		}
+		if _, ok := validFindingStatus[item.Status]; !ok {
+			report.fail(rowNum, "invalid_value", "status")
+			continue
+		}
+		if _, ok := validFindingSeverity[item.Severity]; !ok {
+			report.fail(rowNum, "invalid_value", "severity")
+			continue
+		}
+		if _, ok := validFindingType[item.FindingType]; !ok {
+			report.fail(rowNum, "invalid_value", "type")
+			continue
+		}
+		if v := val(row, "due_at"); v != "" {
+			due, err := importer.ParseDate(v)
+			if err != nil {
+				report.fail(rowNum, "invalid_value", "due_at")
+				continue
+			}
+			item.DueAt = &due
+		}
+		item.Tags = mergeTags(item.Tags, importer.SplitList(val(row, "tags")))
+		item.UpdatedBy = &sess.UserID
+
+		if existing != nil {
+			if !payload.DryRun {
+				h.defaultDueAt(r, &item)
+				if err := h.store.UpdateFinding(ctx, &item); err != nil {
+					report.fail(rowNum, "invalid_value", "update")
+					continue
+				}
+				item.Version++
+				*existing = item
+			}
+			report.UpdatedCount++
+			continue
+		}
+		item.CreatedBy = &sess.UserID
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		item.Version = 1
+		if !payload.DryRun {
+			h.defaultDueAt(r, &item)
+			id, err := h.store.CreateFinding(ctx, &item)
+			if err != nil {
+				report.fail(rowNum, "invalid_value", "create")
+				continue
+			}
+			item.ID = id
+			if asset != nil {
+				link := &store.EntityLink{
+					SourceType:   "finding",
+					SourceID:     strconv.FormatInt(id, 10),
+					TargetType:   "asset",
+					TargetID:     strconv.FormatInt(asset.ID, 10),
+					RelationType: "affects",
+				}
+				if _, err := h.links.Add(ctx, link); err != nil {
+					report.fail(rowNum, "invalid_value", "asset")
+					continue
+				}
+			}
+		}
+		report.CreatedCount++
+		created := item
+		if asset != nil {
+			byAsset[asset.ID] = append(byAsset[asset.ID], created)
+		} else if _, ok := byTitle[strings.ToLower(title)]; !ok {
+			byTitle[strings.ToLower(title)] = &created
+		}
+	}
+	report.FailedCount = len(report.Failures)
+	if !payload.DryRun {
+		h.audit(r, findingAuditImportCommit, fmt.Sprintf("%d|%d|%d|%d", report.TotalRows, report.CreatedCount, report.UpdatedCount, report.FailedCount))
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ImportScan turns a Nessus or OpenVAS XML report (multipart field "file") into
+// vulnerability findings linked to the scanned hosts; hosts without an asset get one.
+// With preview=1 only the plan is returned.
+func (h *FindingsHandler) ImportScan(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.assets == nil || h.links == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	data, ok := readScanUpload(w, r)
+	if !ok {
+		return
+	}
+	scan, err := importer.ParseVulnReport(data)
+	if err != nil {
+		http.Error(w, "import.scan.formatInvalid", http.StatusBadRequest)
+		return
+	}
+	st := importer.FindingStores{Assets: h.assets, Findings: h.store, Links: h.links}
+	plan, err := importer.BuildFindingPlan(r.Context(), st, scan)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if parseBool(r.FormValue("preview")) {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+	var dueAt func(string, time.Time) *time.Time
+	if h.slaSvc != nil {
+		dueAt = func(severity string, from time.Time) *time.Time {
+			return h.slaSvc.DefaultDueAt(r.Context(), severity, from)
+		}
+	}
+	if err := importer.ApplyFindingPlan(r.Context(), st, plan, sess.UserID, time.Now().UTC(), dueAt); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, findingAuditImportScan, fmt.Sprintf("%s|added=%d updated=%d unchanged=%d new_assets=%d",
+		plan.Format, plan.Added, plan.Updated, plan.Unchanged, plan.NewAssets))
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (h *FindingsHandler) ListObservables(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "findings.view"); !ok {
+		return
+	}
+	id, ok := h.existingFindingID(w, r)
+	if !ok {
+		return
+	}
+	listEntityObservables(w, r, h.observables, "finding", id)
+}
+
+func (h *FindingsHandler) AddObservable(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	id, ok := h.existingFindingID(w, r)
+	if !ok {
+		return
+	}
+	item, ok := addEntityObservable(w, r, h.observables, "finding", id, sess.UserID)
+	if ok {
+		h.audit(r, findingAuditObsAdd, fmt.Sprintf("%d|%s:%s", id, item.Type, item.Value))
+	}
+}
+
+func (h *FindingsHandler) DeleteObservable(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "findings.manage"); !ok {
+		return
+	}
+	id, ok := h.existingFindingID(w, r)
+	if !ok {
+		return
+	}
+	item, ok := deleteEntityObservable(w, r, h.observables, "finding", id)
+	if ok {
+		h.audit(r, findingAuditObsDel, fmt.Sprintf("%d|%s:%s", id, item.Type, item.Value))
+	}
+}
+
+func (h *FindingsHandler) existingFindingID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return 0, false
+	}
+	f, err := h.store.GetFinding(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if f == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
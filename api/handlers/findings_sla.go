@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/findings"
+	"berkut-scc/core/store"
+)
+
+// SetSLA enables severity based due dates and the risk acceptance workflow.
+func (h *FindingsHandler) SetSLA(svc *findings.Service, sla store.FindingSLAStore) {
+	if h == nil {
+		return
+	}
+	h.slaSvc = svc
+	h.sla = sla
+}
+
+func (h *FindingsHandler) GetSLAPolicy(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "findings.view"); !ok {
+		return
+	}
+	if h.slaSvc == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	policy, err := h.slaSvc.Policy(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy)
+}
+
+func (h *FindingsHandler) UpdateSLAPolicy(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.slaSvc == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var payload store.FindingSLAPolicy
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	days := map[string]int{}
+	for sev, n := range payload.Days {
+		days[strings.ToLower(strings.TrimSpace(sev))] = n
+	}
+	payload.Days = days
+	if err := h.slaSvc.SavePolicy(r.Context(), &payload, sess.Username); err != nil {
+		if errors.Is(err, store.ErrInvalidFindingSLAPolicy) {
+			http.Error(w, "findings.sla.policyInvalid", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// EvaluateSLA runs the daily overdue evaluation on demand.
+func (h *FindingsHandler) EvaluateSLA(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.slaSvc == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	res, err := h.slaSvc.Evaluate(r.Context(), time.Now().UTC(), sess.Username)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *FindingsHandler) ListExceptions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "findings.view"); !ok {
+		return
+	}
+	if h.sla == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	findingID, ok := h.existingFindingID(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.sla.ListFindingExceptions(r.Context(), findingID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.FindingException{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// ListPendingExceptions returns the exceptions waiting for the current user's decision.
+func (h *FindingsHandler) ListPendingExceptions(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.view")
+	if !ok {
+		return
+	}
+	if h.sla == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	items, err := h.sla.ListPendingFindingExceptions(r.Context(), sess.UserID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.FindingException{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *FindingsHandler) RequestException(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.slaSvc == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	finding, err := h.store.GetFinding(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if finding == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var payload struct {
+		ApproverID    int64  `json:"approver_id"`
+		Justification string `json:"justification"`
+		ExpiresAt     string `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	expires, err := parseExceptionExpiry(payload.ExpiresAt)
+	if err != nil {
+		http.Error(w, "findings.exceptions.expiryInvalid", http.StatusBadRequest)
+		return
+	}
+	actor, err := h.userFromSession(r.Context(), sess)
+	if err != nil || actor == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	item := &store.FindingException{Justification: payload.Justification, ExpiresAt: expires}
+	if payload.ApproverID > 0 {
+		item.ApproverID = &payload.ApproverID
+	}
+	if err := h.slaSvc.RequestException(r.Context(), finding, item, actor, time.Now().UTC()); err != nil {
+		h.writeExceptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, item)
+}
+
+func (h *FindingsHandler) ApproveException(w http.ResponseWriter, r *http.Request) {
+	h.decideException(w, r, true)
+}
+
+func (h *FindingsHandler) RejectException(w http.ResponseWriter, r *http.Request) {
+	h.decideException(w, r, false)
+}
+
+// decideException is limited to the approver designated on the request.
+func (h *FindingsHandler) decideException(w http.ResponseWriter, r *http.Request, approve bool) {
+	sess, ok := h.requirePermission(w, r, "findings.view")
+	if !ok {
+		return
+	}
+	if h.slaSvc == nil || h.sla == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	item, ok := h.loadException(w, r)
+	if !ok {
+		return
+	}
+	if item.ApproverID == nil || *item.ApproverID != sess.UserID {
+		http.Error(w, "findings.exceptions.notApprover", http.StatusForbidden)
+		return
+	}
+	var payload struct {
+		Comment string `json:"comment"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	actor, err := h.userFromSession(r.Context(), sess)
+	if err != nil || actor == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.slaSvc.DecideException(r.Context(), item, approve, payload.Comment, actor, time.Now().UTC()); err != nil {
+		h.writeExceptionError(w, err)
+		return
+	}
+	item, _ = h.sla.GetFindingException(r.Context(), item.ID)
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *FindingsHandler) RevokeException(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "findings.manage")
+	if !ok {
+		return
+	}
+	if h.slaSvc == nil || h.sla == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	item, ok := h.loadException(w, r)
+	if !ok {
+		return
+	}
+	if item.FindingID != parseInt64Default(pathParams(r)["id"], 0) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	actor, err := h.userFromSession(r.Context(), sess)
+	if err != nil || actor == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.slaSvc.RevokeException(r.Context(), item, actor, time.Now().UTC()); err != nil {
+		h.writeExceptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *FindingsHandler) loadException(w http.ResponseWriter, r *http.Request) (*store.FindingException, bool) {
+	id := parseInt64Default(pathParams(r)["exception_id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	item, err := h.sla.GetFindingException(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if item == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return nil, false
+	}
+	return item, true
+}
+
+func (h *FindingsHandler) writeExceptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, findings.ErrJustificationRequired):
+		http.Error(w, "findings.exceptions.justificationRequired", http.StatusBadRequest)
+	case errors.Is(err, findings.ErrApproverInvalid):
+		http.Error(w, "findings.exceptions.approverInvalid", http.StatusBadRequest)
+	case errors.Is(err, findings.ErrExpiryInvalid):
+		http.Error(w, "findings.exceptions.expiryInvalid", http.StatusBadRequest)
+	case errors.Is(err, findings.ErrExceptionActive):
+		http.Error(w, "findings.exceptions.active", http.StatusConflict)
+	case errors.Is(err, findings.ErrFindingClosed):
+		http.Error(w, "findings.exceptions.findingClosed", http.StatusConflict)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, "findings.exceptions.decided", http.StatusConflict)
+	default:
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
+
+// defaultDueAt fills a missing due date from the SLA policy.
+func (h *FindingsHandler) defaultDueAt(r *http.Request, f *store.Finding) {
+	if f.DueAt != nil || h.slaSvc == nil || store.FindingStatusClosed(f.Status) {
+		return
+	}
+	from := f.CreatedAt
+	if from.IsZero() {
+		from = time.Now().UTC()
+	}
+	f.DueAt = h.slaSvc.DefaultDueAt(r.Context(), f.Severity, from)
+}
+
+// parseExceptionExpiry accepts a date (end of that day, UTC) or an RFC 3339 timestamp.
+func parseExceptionExpiry(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad expiry: %w", err)
+	}
+	return t.Add(24*time.Hour - time.Second).UTC(), nil
+}
@@ -16,31 +16,37 @@ import (
 
 	"berkut-scc/config"
 	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
 	"berkut-scc/core/docs"
 	"berkut-scc/core/incidents"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
 
 type IncidentsHandler struct {
-	cfg       *config.AppConfig
-	store     store.IncidentsStore
-	links     store.EntityLinksStore
-	controls  store.ControlsStore
-	assets    store.AssetsStore
-	software  store.SoftwareStore
-	users     store.UsersStore
-	docsStore store.DocsStore
-	policy    *rbac.Policy
-	svc       *incidents.Service
-	docsSvc   *docs.Service
-	audits    store.AuditStore
-	logger    *utils.Logger
-}
-
-func NewIncidentsHandler(cfg *config.AppConfig, is store.IncidentsStore, links store.EntityLinksStore, controls store.ControlsStore, assets store.AssetsStore, software store.SoftwareStore, us store.UsersStore, ds store.DocsStore, policy *rbac.Policy, svc *incidents.Service, docsSvc *docs.Service, audits store.AuditStore, logger *utils.Logger) *IncidentsHandler {
-	return &IncidentsHandler{cfg: cfg, store: is, links: links, controls: controls, assets: assets, software: software, users: us, docsStore: ds, policy: policy, svc: svc, docsSvc: docsSvc, audits: audits, logger: logger}
+	cfg         *config.AppConfig
+	store       store.IncidentsStore
+	links       store.EntityLinksStore
+	controls    store.ControlsStore
+	assets      store.AssetsStore
+	software    store.SoftwareStore
+	findings    store.FindingsStore
+	observables store.ObservablesStore
+	users       store.UsersStore
+	docsStore   store.DocsStore
+	policy      *rbac.Policy
+	svc         *incidents.Service
+	docsSvc     *docs.Service
+	audits      store.AuditStore
+	logger      *utils.Logger
+	fields      *customfields.Service
+	queries     *query.Service
+}
+
+func NewIncidentsHandler(cfg *config.AppConfig, is store.IncidentsStore, links store.EntityLinksStore, controls store.ControlsStore, assets store.AssetsStore, software store.SoftwareStore, findings store.FindingsStore, observables store.ObservablesStore, us store.UsersStore, ds store.DocsStore, policy *rbac.Policy, svc *incidents.Service, docsSvc *docs.Service, audits store.AuditStore, logger *utils.Logger) *IncidentsHandler {
+	return &IncidentsHandler{cfg: cfg, store: is, links: links, controls: controls, assets: assets, software: software, findings: findings, observables: observables, users: us, docsStore: ds, policy: policy, svc: svc, docsSvc: docsSvc, audits: audits, logger: logger}
 }
 
 var validIncidentSeverity = map[string]struct{}{
@@ -108,6 +114,20 @@ func (h *IncidentsHandler) List(w http.ResponseWriter, r *http.Request) {
 	if canManage && (r.URL.Query().Get("include_deleted") == "1" || strings.ToLower(r.URL.Query().Get("include_deleted")) == "true") {
 		filter.IncludeDeleted = true
 	}
+	filter.CustomFields, err = h.fields.Conditions(r.Context(), store.CustomFieldEntityIncident, roles, r.URL.Query())
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	filter.Query, err = h.queries.FromRequest(r.Context(), store.SearchEntityIncident, r.URL.Query(), user.ID, roles)
+	if err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	items, err := h.store.ListIncidents(r.Context(), filter)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
@@ -150,6 +170,7 @@ func (h *IncidentsHandler) List(w http.ResponseWriter, r *http.Request) {
 			CaseSLA:      buildIncidentCaseSLA(inc),
 		})
 	}
+	h.attachCustomFields(r, roles, result)
 	writeJSON(w, http.StatusOK, map[string]any{"items": result})
 }
 
@@ -252,7 +273,7 @@ func (h *IncidentsHandler) ListDocsLite(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *IncidentsHandler) Create(w http.ResponseWriter, r *http.Request) {
-	user, _, _, err := h.currentUser(r)
+	user, roles, _, err := h.currentUser(r)
 	if err != nil || user == nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
@@ -268,6 +289,7 @@ func (h *IncidentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Assignee       string             `json:"assignee"`
 		Participants   []string           `json:"participants"`
 		Meta           store.IncidentMeta `json:"meta"`
+		CustomFields   map[string]any     `json:"custom_fields"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		http.Error(w, "bad request", http.StatusBadRequest)
@@ -327,6 +349,13 @@ func (h *IncidentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "incidents.userNotFound", http.StatusBadRequest)
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityIncident, roles, payload.CustomFields, true)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	incident := &store.Incident{
 		Title:               title,
 		Description:         strings.TrimSpace(payload.Description),
@@ -347,6 +376,10 @@ func (h *IncidentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "incidents.regNoFailed", http.StatusInternalServerError)
 		return
 	}
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityIncident, incident.ID, values, user.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
 	created, err := h.store.GetIncident(r.Context(), incident.ID)
 	if err != nil || created == nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
@@ -354,12 +387,19 @@ func (h *IncidentsHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	h.svc.Log(r.Context(), user.Username, "incident.create", created.RegNo)
 	h.addTimeline(r.Context(), created.ID, "incident.create", "incident created", user.ID)
-	writeJSON(w, http.StatusCreated, incidentDTO{
+	notifyIDs := []int64{created.OwnerUserID}
+	if created.AssigneeUserID != nil {
+		notifyIDs = append(notifyIDs, *created.AssigneeUserID)
+	}
+	h.notifyIncidentAssigned(r.Context(), created, user, notifyIDs...)
+	dto := []incidentDTO{{
 		Incident:     *created,
 		OwnerName:    displayName(ownerUser),
 		AssigneeName: displayName(assigneeUser),
 		CaseSLA:      buildIncidentCaseSLA(*created),
-	})
+	}}
+	h.attachCustomFields(r, roles, dto)
+	writeJSON(w, http.StatusCreated, dto[0])
 }
 
 func (h *IncidentsHandler) Get(w http.ResponseWriter, r *http.Request) {
@@ -397,13 +437,15 @@ func (h *IncidentsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		assignee, _, _ = h.users.Get(r.Context(), *incident.AssigneeUserID)
 	}
 	h.svc.Log(r.Context(), user.Username, "incident.view", incident.RegNo)
+	dto := []incidentDTO{{
+		Incident:     *incident,
+		OwnerName:    displayName(owner),
+		AssigneeName: displayName(assignee),
+		CaseSLA:      buildIncidentCaseSLA(*incident),
+	}}
+	h.attachCustomFields(r, roles, dto)
 	writeJSON(w, http.StatusOK, map[string]any{
-		"incident": incidentDTO{
-			Incident:     *incident,
-			OwnerName:    displayName(owner),
-			AssigneeName: displayName(assignee),
-			CaseSLA:      buildIncidentCaseSLA(*incident),
-		},
+		"incident":     dto[0],
 		"participants": parts,
 	})
 }
@@ -431,6 +473,7 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Assignee            *string             `json:"assignee"`
 		Participants        []string            `json:"participants"`
 		Meta                *store.IncidentMeta `json:"meta"`
+		CustomFields        map[string]any      `json:"custom_fields"`
 		Version             int                 `json:"version"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -552,6 +595,13 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 			updated.AssigneeUserID = &assigneeUser.ID
 		}
 	}
+	values, err := h.fields.Prepare(r.Context(), store.CustomFieldEntityIncident, roles, payload.CustomFields, false)
+	if err != nil {
+		if !customFieldError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return
+	}
 	updated.UpdatedBy = user.ID
 	if err := h.store.UpdateIncident(r.Context(), &updated, expectedVersion); err != nil {
 		if errors.Is(err, store.ErrConflict) {
@@ -561,6 +611,13 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	if err := h.fields.Save(r.Context(), store.CustomFieldEntityIncident, incident.ID, values, user.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if len(values) > 0 {
+		h.addTimeline(r.Context(), incident.ID, "custom_fields.change", "custom fields updated", user.ID)
+	}
 	statusChanged := payload.Status != nil && incident.Status != updated.Status
 	severityChanged := payload.Severity != nil && incident.Severity != updated.Severity
 	assigneeChanged := (payload.AssigneeUserID != nil || payload.Assignee != nil) && !sameAssignee(incident.AssigneeUserID, updated.AssigneeUserID)
@@ -568,12 +625,16 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	classChanged := (payload.ClassificationLevel != nil || payload.ClassificationTags != nil) && (incident.ClassificationLevel != updated.ClassificationLevel || !sameTags(incident.ClassificationTags, updated.ClassificationTags))
 	if statusChanged {
 		h.addTimeline(r.Context(), incident.ID, "status.change", fmt.Sprintf("%s -> %s", incident.Status, updated.Status), user.ID)
+		h.stampLifecycle(r, incident.ID, updated.Status)
 	}
 	if severityChanged {
 		h.addTimeline(r.Context(), incident.ID, "severity.change", fmt.Sprintf("%s -> %s", incident.Severity, updated.Severity), user.ID)
 	}
 	if assigneeChanged {
 		h.addTimeline(r.Context(), incident.ID, "assignee.change", "assignee updated", user.ID)
+		if updated.AssigneeUserID != nil {
+			_ = h.store.StampIncidentMilestone(r.Context(), incident.ID, store.IncidentMilestoneAcknowledged, time.Now().UTC())
+		}
 	}
 	if classChanged {
 		h.addTimeline(r.Context(), incident.ID, "classification.change", "classification updated", user.ID)
@@ -601,6 +662,14 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if ownerChanged {
 		h.svc.Log(r.Context(), user.Username, "incident.owner.change", incident.RegNo)
 	}
+	var notifyIDs []int64
+	if assigneeChanged && updated.AssigneeUserID != nil {
+		notifyIDs = append(notifyIDs, *updated.AssigneeUserID)
+	}
+	if ownerChanged {
+		notifyIDs = append(notifyIDs, updated.OwnerUserID)
+	}
+	h.notifyIncidentAssigned(r.Context(), &updated, user, notifyIDs...)
 	if statusChanged {
 		h.svc.Log(r.Context(), user.Username, "incident.status.change", incident.RegNo)
 	}
@@ -620,12 +689,14 @@ func (h *IncidentsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if updated.AssigneeUserID != nil {
 		assigneeUser, _ = h.lookupUserByID(r.Context(), *updated.AssigneeUserID)
 	}
-	writeJSON(w, http.StatusOK, incidentDTO{
+	dto := []incidentDTO{{
 		Incident:     updated,
 		OwnerName:    displayName(owner),
 		AssigneeName: displayName(assigneeUser),
 		CaseSLA:      buildIncidentCaseSLA(updated),
-	})
+	}}
+	h.attachCustomFields(r, roles, dto)
+	writeJSON(w, http.StatusOK, dto[0])
 }
 
 func (h *IncidentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -887,6 +958,7 @@ func (h *IncidentsHandler) CloseIncident(w http.ResponseWriter, r *http.Request)
 	}
 	h.svc.Log(r.Context(), user.Username, "incidents.closed", updated.RegNo)
 	h.addTimeline(r.Context(), incident.ID, "incident.closed", "incident closed", user.ID)
+	h.stampLifecycle(r, incident.ID, "closed")
 	writeJSON(w, http.StatusOK, map[string]any{"incident": updated})
 }
 
@@ -2153,17 +2225,19 @@ func (h *IncidentsHandler) AddTimeline(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	if _, err := h.store.AddIncidentTimeline(r.Context(), &store.IncidentTimelineEvent{
+	eventID, err := h.store.AddIncidentTimeline(r.Context(), &store.IncidentTimelineEvent{
 		IncidentID: incident.ID,
 		EventType:  eventType,
 		Message:    msg,
 		CreatedBy:  user.ID,
 		EventAt:    eventAt,
-	}); err != nil {
+	})
+	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
 	h.svc.Log(r.Context(), user.Username, "incident.note.add", incident.RegNo)
+	h.recordTimelineMentions(r.Context(), incident, eventID, user, msg)
 	writeJSON(w, http.StatusCreated, map[string]string{"status": "ok"})
 }
 
@@ -2233,7 +2307,7 @@ func (h *IncidentsHandler) Export(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad request", http.StatusBadRequest)
 		return
 	}
-	md, err := h.buildIncidentReportMarkdown(r.Context(), incident, eff)
+	md, err := h.buildIncidentReportMarkdown(r.Context(), incident, roles, eff)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
@@ -2319,7 +2393,7 @@ func (h *IncidentsHandler) CreateReportDoc(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	md, err := h.buildIncidentReportMarkdown(r.Context(), incident, eff)
+	md, err := h.buildIncidentReportMarkdown(r.Context(), incident, roles, eff)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
@@ -2667,7 +2741,7 @@ func safeFileName(name string) string {
 	return replacer.Replace(name)
 }
 
-func (h *IncidentsHandler) buildIncidentReportMarkdown(ctx context.Context, incident *store.Incident, eff store.EffectiveAccess) ([]byte, error) {
+func (h *IncidentsHandler) buildIncidentReportMarkdown(ctx context.Context, incident *store.Incident, roles []string, eff store.EffectiveAccess) ([]byte, error) {
 	if incident == nil {
 		return nil, errors.New("missing incident")
 	}
@@ -2703,6 +2777,14 @@ func (h *IncidentsHandler) buildIncidentReportMarkdown(ctx context.Context, inci
 	if len(incident.ClassificationTags) > 0 {
 		b.WriteString(fmt.Sprintf("- Tags: %s\n", strings.Join(docs.NormalizeTags(incident.ClassificationTags), ", ")))
 	}
+	if fields, _ := h.fields.Fields(ctx, store.CustomFieldEntityIncident, roles); len(fields) > 0 {
+		values, _ := h.fields.DisplayValues(ctx, store.CustomFieldEntityIncident, fields, []int64{incident.ID})
+		for _, f := range fields {
+			if v := values[incident.ID][f.Key]; v != "" {
+				b.WriteString(fmt.Sprintf("- %s: %s\n", f.Label, v))
+			}
+		}
+	}
 	if strings.TrimSpace(incident.Description) != "" {
 		b.WriteString("\n## Summary\n")
 		b.WriteString(incident.Description)
@@ -2781,6 +2863,41 @@ type incidentDTO struct {
 	OwnerName    string          `json:"owner_name"`
 	AssigneeName string          `json:"assignee_name,omitempty"`
 	CaseSLA      incidentCaseSLA `json:"case_sla"`
+	CustomFields map[string]any  `json:"custom_fields,omitempty"`
+}
+
+// SetCustomFields enables admin-defined fields on incidents.
+func (h *IncidentsHandler) SetCustomFields(svc *customfields.Service) {
+	if h == nil {
+		return
+	}
+	h.fields = svc
+}
+
+// SetQueries enables the query and saved_search filters of the incidents list.
+func (h *IncidentsHandler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
+// attachCustomFields fills the custom field values visible to the roles.
+func (h *IncidentsHandler) attachCustomFields(r *http.Request, roles []string, items []incidentDTO) {
+	if h.fields == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	values, err := h.fields.Values(r.Context(), store.CustomFieldEntityIncident, roles, ids)
+	if err != nil {
+		return
+	}
+	for i := range items {
+		items[i].CustomFields = values[items[i].ID]
+	}
 }
 
 type incidentCaseSLA struct {
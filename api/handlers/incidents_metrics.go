@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/store"
+)
+
+type incidentImpactPayload struct {
+	StartedAt       *time.Time `json:"started_at"`
+	DetectedAt      *time.Time `json:"detected_at"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at"`
+	ContainedAt     *time.Time `json:"contained_at"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+	DowntimeMinutes int        `json:"downtime_minutes"`
+	AffectedUsers   int        `json:"affected_users"`
+	EstimatedCost   float64    `json:"estimated_cost"`
+	CostCurrency    string     `json:"cost_currency"`
+}
+
+func (h *IncidentsHandler) GetImpact(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "view")
+	if !ok {
+		return
+	}
+	records, err := incidents.BuildMetricsRecords(r.Context(), h.store, []store.Incident{*incident})
+	if err != nil || len(records) == 0 {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"impact":    records[0].Impact,
+		"lifecycle": records[0].Lifecycle,
+	})
+}
+
+// UpdateImpact replaces the impact record. Milestones left empty fall back to values derived
+// from the timeline, so clearing a field re-enables derivation.
+func (h *IncidentsHandler) UpdateImpact(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "edit")
+	if !ok {
+		return
+	}
+	var payload incidentImpactPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if payload.DowntimeMinutes < 0 || payload.AffectedUsers < 0 || payload.EstimatedCost < 0 {
+		http.Error(w, incidents.ErrImpactNegative.Error(), http.StatusBadRequest)
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(payload.CostCurrency))
+	if len(currency) > 8 {
+		http.Error(w, "incidents.impact.currencyInvalid", http.StatusBadRequest)
+		return
+	}
+	impact := &store.IncidentImpact{
+		IncidentID:      incident.ID,
+		StartedAt:       utcTimePtr(payload.StartedAt),
+		DetectedAt:      utcTimePtr(payload.DetectedAt),
+		AcknowledgedAt:  utcTimePtr(payload.AcknowledgedAt),
+		ContainedAt:     utcTimePtr(payload.ContainedAt),
+		ResolvedAt:      utcTimePtr(payload.ResolvedAt),
+		DowntimeMinutes: payload.DowntimeMinutes,
+		AffectedUsers:   payload.AffectedUsers,
+		EstimatedCost:   payload.EstimatedCost,
+		CostCurrency:    currency,
+		UpdatedBy:       &user.ID,
+	}
+	events, _ := h.store.ListIncidentTimelineByTypes(r.Context(), []int64{incident.ID}, incidents.LifecycleEventTypes)
+	lifecycle := incidents.DeriveLifecycle(*incident, impact, events)
+	if err := incidents.ValidateLifecycle(lifecycle); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.SaveIncidentImpact(r.Context(), impact); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.svc.Log(r.Context(), user.Username, "incident.impact.update", fmt.Sprintf("%s|downtime=%d users=%d cost=%.2f %s", incident.RegNo, impact.DowntimeMinutes, impact.AffectedUsers, impact.EstimatedCost, impact.CostCurrency))
+	h.addTimeline(r.Context(), incident.ID, "impact.update", "impact updated", user.ID)
+	writeJSON(w, http.StatusOK, map[string]any{"impact": impact, "lifecycle": lifecycle})
+}
+
+// Metrics returns MTTD/MTTA/MTTC/MTTR and impact totals over the incidents visible to the caller.
+// Query: from/to (YYYY-MM-DD, by detection time), group_by=severity|type|period, period=week|month,
+// optional severity and type filters.
+func (h *IncidentsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	from, err := parseDateStrict(q.Get("from"))
+	if err != nil {
+		http.Error(w, "incidents.metrics.periodInvalid", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateStrict(q.Get("to"))
+	if err != nil {
+		http.Error(w, "incidents.metrics.periodInvalid", http.StatusBadRequest)
+		return
+	}
+	if to != nil {
+		end := to.Add(24*time.Hour - time.Nanosecond)
+		to = &end
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		http.Error(w, "incidents.metrics.periodInvalid", http.StatusBadRequest)
+		return
+	}
+	incidentType := strings.TrimSpace(q.Get("type"))
+	items, err := h.store.ListIncidents(r.Context(), store.IncidentFilter{Severity: strings.ToLower(strings.TrimSpace(q.Get("severity")))})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	canManage := h.policy.Allowed(roles, "incidents.manage")
+	var visible []store.Incident
+	for _, inc := range items {
+		if incidentType != "" && !strings.EqualFold(inc.Meta.IncidentType, incidentType) {
+			continue
+		}
+		acl, _ := h.store.GetIncidentACL(r.Context(), inc.ID)
+		if !canManage && !h.svc.CheckACL(user, roles, acl, "view") {
+			continue
+		}
+		if !h.canViewByClassification(eff, inc.ClassificationLevel, inc.ClassificationTags) {
+			continue
+		}
+		visible = append(visible, inc)
+	}
+	records, err := incidents.BuildMetricsRecords(r.Context(), h.store, visible)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if from != nil || to != nil {
+		filtered := records[:0]
+		for _, rec := range records {
+			at := rec.Incident.CreatedAt
+			if rec.Lifecycle.DetectedAt != nil {
+				at = *rec.Lifecycle.DetectedAt
+			}
+			if from != nil && at.Before(*from) {
+				continue
+			}
+			if to != nil && at.After(*to) {
+				continue
+			}
+			filtered = append(filtered, rec)
+		}
+		records = filtered
+	}
+	writeJSON(w, http.StatusOK, incidents.ComputeMetrics(records, q.Get("group_by"), q.Get("period")))
+}
+
+// stampLifecycle records the milestone implied by a status transition; failures are not fatal.
+func (h *IncidentsHandler) stampLifecycle(r *http.Request, incidentID int64, status string) {
+	milestone := incidents.MilestoneForStatus(status)
+	if milestone == "" {
+		return
+	}
+	now := time.Now().UTC()
+	if milestone == store.IncidentMilestoneContained || milestone == store.IncidentMilestoneResolved {
+		_ = h.store.StampIncidentMilestone(r.Context(), incidentID, store.IncidentMilestoneAcknowledged, now)
+	}
+	_ = h.store.StampIncidentMilestone(r.Context(), incidentID, milestone, now)
+}
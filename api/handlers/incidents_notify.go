@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+)
+
+// notifyIncidentAssigned tells users who just became the assignee or owner of the incident.
+func (h *IncidentsHandler) notifyIncidentAssigned(ctx context.Context, inc *store.Incident, actor *store.User, userIDs ...int64) {
+	n := h.svc.Notifier()
+	if n == nil || inc == nil || len(userIDs) == 0 {
+		return
+	}
+	title := strings.TrimSpace(inc.Title)
+	if reg := strings.TrimSpace(inc.RegNo); reg != "" {
+		title = reg + " " + title
+	}
+	item := store.Notification{
+		EventType:  notify.EventIncidentAssigned,
+		Title:      title,
+		Body:       "Severity: " + inc.Severity,
+		BodyKey:    "notifications.incident.severity",
+		Params:     map[string]string{"severity": inc.Severity},
+		EntityType: "incident",
+		EntityID:   fmt.Sprintf("%d", inc.ID),
+		Link:       fmt.Sprintf("/incidents?incident=%d", inc.ID),
+	}
+	if actor != nil {
+		item.ActorID = &actor.ID
+	}
+	n.Notify(ctx, item, userIDs...)
+}
+
+// recordTimelineMentions stores @mentions of a timeline note for users who pass the incident
+// permission, ACL and classification checks.
+func (h *IncidentsHandler) recordTimelineMentions(ctx context.Context, inc *store.Incident, eventID int64, author *store.User, text string) {
+	n := h.svc.Notifier()
+	if n == nil || inc == nil {
+		return
+	}
+	acl, _ := h.store.GetIncidentACL(ctx, inc.ID)
+	title := strings.TrimSpace(inc.Title)
+	if reg := strings.TrimSpace(inc.RegNo); reg != "" {
+		title = reg + " " + title
+	}
+	src := notify.MentionSource{
+		SourceType: notify.SourceIncidentTimeline,
+		SourceID:   fmt.Sprintf("%d", eventID),
+		EntityType: "incident",
+		EntityID:   fmt.Sprintf("%d", inc.ID),
+		Title:      title,
+		Link:       fmt.Sprintf("/incidents?incident=%d", inc.ID),
+	}
+	n.RecordMentions(ctx, src, author, text, func(ctx context.Context, u *store.User, roles []string) bool {
+		groups, _ := h.users.UserGroups(ctx, u.ID)
+		eff := auth.CalculateEffectiveAccess(u, roles, groups, h.policy)
+		if !h.policy.Allowed(eff.Roles, "incidents.view") {
+			return false
+		}
+		if !h.policy.Allowed(eff.Roles, "incidents.manage") && !h.svc.CheckACL(u, eff.Roles, acl, "view") {
+			return false
+		}
+		return h.canViewByClassification(eff, inc.ClassificationLevel, inc.ClassificationTags)
+	})
+}
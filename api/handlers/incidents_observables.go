@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/store"
+)
+
+type observablePayload struct {
+	Type        string     `json:"type"`
+	Value       string     `json:"value"`
+	TLP         string     `json:"tlp"`
+	Description string     `json:"description"`
+	FirstSeenAt *time.Time `json:"first_seen_at"`
+	LastSeenAt  *time.Time `json:"last_seen_at"`
+}
+
+type correlatedIncident struct {
+	ID       int64  `json:"id"`
+	RegNo    string `json:"reg_no"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Status   string `json:"status"`
+}
+
+type correlatedFinding struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Status   string `json:"status"`
+}
+
+type correlatedAsset struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Criticality string `json:"criticality"`
+	MatchedBy   string `json:"matched_by"`
+}
+
+type observableCorrelation struct {
+	Observable store.Observable     `json:"observable"`
+	Incidents  []correlatedIncident `json:"incidents"`
+	Findings   []correlatedFinding  `json:"findings"`
+	Assets     []correlatedAsset    `json:"assets"`
+}
+
+func (h *IncidentsHandler) ListObservables(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "view")
+	if !ok {
+		return
+	}
+	items, err := h.observables.ListObservables(r.Context(), "incident", incident.ID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "types": incidents.ObservableTypes, "tlp_levels": incidents.TLPLevels})
+}
+
+func (h *IncidentsHandler) AddObservable(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "edit")
+	if !ok {
+		return
+	}
+	var payload observablePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	item, err := observableFromPayload(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.EntityType = "incident"
+	item.EntityID = incident.ID
+	item.Source = "manual"
+	item.CreatedBy = &user.ID
+	item.UpdatedBy = &user.ID
+	if _, err := h.observables.UpsertObservable(r.Context(), item); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.svc.Log(r.Context(), user.Username, "incident.observable.add", fmt.Sprintf("%s|%s:%s", incident.RegNo, item.Type, item.Value))
+	h.addTimeline(r.Context(), incident.ID, "observable.add", fmt.Sprintf("%s:%s", item.Type, item.Value), user.ID)
+	writeJSON(w, http.StatusCreated, item)
+}
+
+func (h *IncidentsHandler) UpdateObservable(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "edit")
+	if !ok {
+		return
+	}
+	existing, ok := h.incidentObservable(w, r, incident.ID)
+	if !ok {
+		return
+	}
+	var payload observablePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	tlp, err := incidents.NormalizeTLP(payload.TLP)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if payload.FirstSeenAt != nil && payload.LastSeenAt != nil && payload.LastSeenAt.Before(*payload.FirstSeenAt) {
+		http.Error(w, "incidents.observables.seenRangeInvalid", http.StatusBadRequest)
+		return
+	}
+	existing.TLP = tlp
+	existing.Description = strings.TrimSpace(payload.Description)
+	existing.FirstSeenAt = utcTimePtr(payload.FirstSeenAt)
+	existing.LastSeenAt = utcTimePtr(payload.LastSeenAt)
+	existing.UpdatedBy = &user.ID
+	if err := h.observables.UpdateObservable(r.Context(), existing); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.svc.Log(r.Context(), user.Username, "incident.observable.update", fmt.Sprintf("%s|%d", incident.RegNo, existing.ID))
+	writeJSON(w, http.StatusOK, existing)
+}
+
+func (h *IncidentsHandler) DeleteObservable(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "edit")
+	if !ok {
+		return
+	}
+	existing, ok := h.incidentObservable(w, r, incident.ID)
+	if !ok {
+		return
+	}
+	if err := h.observables.DeleteObservable(r.Context(), existing.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.svc.Log(r.Context(), user.Username, "incident.observable.remove", fmt.Sprintf("%s|%s:%s", incident.RegNo, existing.Type, existing.Value))
+	h.addTimeline(r.Context(), incident.ID, "observable.remove", fmt.Sprintf("%s:%s", existing.Type, existing.Value), user.ID)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// CorrelateObservables lists every other incident, finding and asset sharing an observable
+// with this incident. Entities the caller cannot see are dropped silently.
+func (h *IncidentsHandler) CorrelateObservables(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "view")
+	if !ok {
+		return
+	}
+	own, err := h.observables.ListObservables(r.Context(), "incident", incident.ID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	keys := make([]store.ObservableKey, 0, len(own))
+	for _, o := range own {
+		keys = append(keys, store.ObservableKey{Type: o.Type, Value: o.Value})
+	}
+	matches, err := h.observables.FindObservablesByKeys(r.Context(), keys)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	byKey := map[store.ObservableKey][]store.Observable{}
+	for _, m := range matches {
+		if m.EntityType == "incident" && m.EntityID == incident.ID {
+			continue
+		}
+		k := store.ObservableKey{Type: m.Type, Value: m.Value}
+		byKey[k] = append(byKey[k], m)
+	}
+	canViewFindings := h.findings != nil && h.policy.Allowed(roles, "findings.view") && allowedByMenuPermissions(eff.MenuPermissions, "findings")
+	canViewAssets := h.assets != nil && h.policy.Allowed(roles, "assets.view") && allowedByMenuPermissions(eff.MenuPermissions, "assets")
+
+	incidentCache := map[int64]*correlatedIncident{}
+	visibleIncident := func(id int64) *correlatedIncident {
+		if v, ok := incidentCache[id]; ok {
+			return v
+		}
+		incidentCache[id] = nil
+		inc, err := h.store.GetIncident(r.Context(), id)
+		if err != nil || inc == nil || inc.DeletedAt != nil {
+			return nil
+		}
+		acl, _ := h.store.GetIncidentACL(r.Context(), inc.ID)
+		if !h.policy.Allowed(roles, "incidents.manage") && !h.svc.CheckACL(user, roles, acl, "view") {
+			return nil
+		}
+		if !h.canViewByClassification(eff, inc.ClassificationLevel, inc.ClassificationTags) {
+			return nil
+		}
+		v := &correlatedIncident{ID: inc.ID, RegNo: inc.RegNo, Title: inc.Title, Severity: inc.Severity, Status: inc.Status}
+		incidentCache[id] = v
+		return v
+	}
+	findingCache := map[int64]*correlatedFinding{}
+	visibleFinding := func(id int64) *correlatedFinding {
+		if !canViewFindings {
+			return nil
+		}
+		if v, ok := findingCache[id]; ok {
+			return v
+		}
+		findingCache[id] = nil
+		f, err := h.findings.GetFinding(r.Context(), id)
+		if err != nil || f == nil || f.DeletedAt != nil {
+			return nil
+		}
+		v := &correlatedFinding{ID: f.ID, Title: f.Title, Severity: f.Severity, Status: f.Status}
+		findingCache[id] = v
+		return v
+	}
+	assetCache := map[int64]*store.Asset{}
+	visibleAsset := func(id int64) *store.Asset {
+		if !canViewAssets {
+			return nil
+		}
+		if v, ok := assetCache[id]; ok {
+			return v
+		}
+		assetCache[id] = nil
+		a, err := h.assets.GetAsset(r.Context(), id)
+		if err != nil || a == nil || a.DeletedAt != nil {
+			return nil
+		}
+		assetCache[id] = a
+		return a
+	}
+
+	items := make([]observableCorrelation, 0, len(own))
+	for _, o := range own {
+		entry := observableCorrelation{Observable: o, Incidents: []correlatedIncident{}, Findings: []correlatedFinding{}, Assets: []correlatedAsset{}}
+		seenAssets := map[int64]struct{}{}
+		for _, m := range byKey[store.ObservableKey{Type: o.Type, Value: o.Value}] {
+			switch m.EntityType {
+			case "incident":
+				if v := visibleIncident(m.EntityID); v != nil {
+					entry.Incidents = append(entry.Incidents, *v)
+				}
+			case "finding":
+				if v := visibleFinding(m.EntityID); v != nil {
+					entry.Findings = append(entry.Findings, *v)
+				}
+			case "asset":
+				if a := visibleAsset(m.EntityID); a != nil {
+					seenAssets[a.ID] = struct{}{}
+					entry.Assets = append(entry.Assets, correlatedAsset{ID: a.ID, Name: a.Name, Type: a.Type, Criticality: a.Criticality, MatchedBy: "observable"})
+				}
+			}
+		}
+		if o.Type == incidents.ObservableIP && canViewAssets {
+			ids, _ := h.observables.FindAssetIDsByIP(r.Context(), o.Value)
+			for _, id := range ids {
+				if _, dup := seenAssets[id]; dup {
+					continue
+				}
+				if a := visibleAsset(id); a != nil {
+					entry.Assets = append(entry.Assets, correlatedAsset{ID: a.ID, Name: a.Name, Type: a.Type, Criticality: a.Criticality, MatchedBy: "ip_address"})
+				}
+			}
+		}
+		items = append(items, entry)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *IncidentsHandler) ExportObservables(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "view")
+	if !ok {
+		return
+	}
+	items, err := h.observables.ListObservables(r.Context(), "incident", incident.ID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	now := time.Now().UTC()
+	base := fmt.Sprintf("%s_observables_%s", safeFileName(incident.RegNo), now.Format("20060102_150405"))
+	switch format {
+	case "", "csv":
+		var buf bytes.Buffer
+		if err := incidents.WriteObservablesCSV(&buf, items); err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		h.svc.Log(r.Context(), user.Username, "incident.observables.export", fmt.Sprintf("%s|csv|%d", incident.RegNo, len(items)))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", attachmentDisposition(base+".csv"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	case "stix":
+		raw, err := incidents.ExportSTIXBundle(items, now)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		h.svc.Log(r.Context(), user.Username, "incident.observables.export", fmt.Sprintf("%s|stix|%d", incident.RegNo, len(items)))
+		w.Header().Set("Content-Type", "application/stix+json;version=2.1")
+		w.Header().Set("Content-Disposition", attachmentDisposition(base+".stix.json"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(raw)
+	default:
+		http.Error(w, "incidents.observables.formatInvalid", http.StatusBadRequest)
+	}
+}
+
+// ImportObservables accepts a CSV file or a STIX 2.1 bundle (multipart field "file").
+// Existing observables are merged, widening their first/last seen window.
+func (h *IncidentsHandler) ImportObservables(w http.ResponseWriter, r *http.Request) {
+	user, roles, eff, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	incident, ok := h.getIncidentWithACL(w, r, user, roles, eff, "edit")
+	if !ok {
+		return
+	}
+	if err := parseMultipartFormLimited(w, r, 20<<20); err != nil {
+		return
+	}
+	file, hdr, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 16<<20))
+	if err != nil {
+		http.Error(w, "invalid file", http.StatusBadRequest)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.FormValue("format")))
+	if format == "" {
+		format = "csv"
+		ext := strings.ToLower(filepath.Ext(hdr.Filename))
+		if ext == ".json" || bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			format = "stix"
+		}
+	}
+	var parsed []store.Observable
+	var failures []incidents.ObservableImportError
+	switch format {
+	case "csv":
+		parsed, failures, err = incidents.ParseObservablesCSV(data)
+	case "stix":
+		parsed, failures, err = incidents.ParseSTIXBundle(data)
+	default:
+		http.Error(w, "incidents.observables.formatInvalid", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "incidents.observables.fileInvalid", http.StatusBadRequest)
+		return
+	}
+	created, updated := 0, 0
+	for i := range parsed {
+		item := parsed[i]
+		item.EntityType = "incident"
+		item.EntityID = incident.ID
+		item.Source = "import:" + format
+		item.CreatedBy = &user.ID
+		item.UpdatedBy = &user.ID
+		isNew, err := h.observables.UpsertObservable(r.Context(), &item)
+		if err != nil {
+			failures = append(failures, incidents.ObservableImportError{Value: item.Value, Reason: "server error"})
+			continue
+		}
+		if isNew {
+			created++
+		} else {
+			updated++
+		}
+	}
+	if failures == nil {
+		failures = []incidents.ObservableImportError{}
+	}
+	h.svc.Log(r.Context(), user.Username, "incident.observables.import", fmt.Sprintf("%s|%s|created=%d updated=%d failed=%d", incident.RegNo, format, created, updated, len(failures)))
+	if created+updated > 0 {
+		h.addTimeline(r.Context(), incident.ID, "observable.import", fmt.Sprintf("%s: +%d ~%d", format, created, updated), user.ID)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"format":        format,
+		"created_count": created,
+		"updated_count": updated,
+		"failed_count":  len(failures),
+		"failures":      failures,
+	})
+}
+
+func (h *IncidentsHandler) incidentObservable(w http.ResponseWriter, r *http.Request, incidentID int64) (*store.Observable, bool) {
+	obsID, _ := strconv.ParseInt(pathParams(r)["obs_id"], 10, 64)
+	item, err := h.observables.GetObservable(r.Context(), obsID)
+	if err != nil || item == nil || item.EntityType != "incident" || item.EntityID != incidentID {
+		http.Error(w, "not found", http.StatusNotFound)
+		return nil, false
+	}
+	return item, true
+}
+
+func observableFromPayload(payload observablePayload) (*store.Observable, error) {
+	obsType, value, err := incidents.NormalizeObservable(payload.Type, payload.Value)
+	if err != nil {
+		return nil, err
+	}
+	tlp, err := incidents.NormalizeTLP(payload.TLP)
+	if err != nil {
+		return nil, err
+	}
+	if payload.FirstSeenAt != nil && payload.LastSeenAt != nil && payload.LastSeenAt.Before(*payload.FirstSeenAt) {
+		return nil, fmt.Errorf("incidents.observables.seenRangeInvalid")
+	}
+	return &store.Observable{
+		Type:        obsType,
+		Value:       value,
+		TLP:         tlp,
+		Description: strings.TrimSpace(payload.Description),
+		FirstSeenAt: utcTimePtr(payload.FirstSeenAt),
+		LastSeenAt:  utcTimePtr(payload.LastSeenAt),
+	}, nil
+}
+
+func utcTimePtr(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
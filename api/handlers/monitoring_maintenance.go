@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"berkut-scc/core/schedule"
 	"berkut-scc/core/store"
 	"github.com/robfig/cron/v3"
 )
@@ -23,6 +24,9 @@ type maintenanceSchedulePayload struct {
 	WindowEnd      string    `json:"window_end"`
 	ActiveFrom     time.Time `json:"active_from"`
 	ActiveUntil    time.Time `json:"active_until"`
+	ExDates        []string  `json:"exdates"`
+	Shift          string    `json:"shift"`
+	CalendarID     int64     `json:"calendar_id"`
 }
 
 type maintenancePayload struct {
@@ -294,9 +298,18 @@ func validateMaintenance(item *store.MonitorMaintenance) error {
 			return errors.New("monitoring.error.invalidWindow")
 		}
 		if item.IsRecurring {
-			if item.RRuleText == "" {
+			rr, err := schedule.ParseRRule(item.RRuleText)
+			if err != nil {
 				return errors.New("monitoring.error.invalidRRule")
 			}
+			item.RRuleText = rr.String()
+			spec, err := schedule.Spec{Start: "2000-01-01", Timezone: item.Timezone, ExDates: item.Schedule.ExDates, Shift: item.Schedule.Shift, CalendarID: item.Schedule.CalendarID}.Normalize()
+			if err != nil {
+				return err
+			}
+			item.Schedule.ExDates = spec.ExDates
+			item.Schedule.Shift = spec.Shift
+			item.Schedule.CalendarID = spec.CalendarID
 		}
 	default:
 		return errors.New("monitoring.maintenance.error.invalidStrategy")
@@ -317,6 +330,9 @@ func payloadToMaintenanceSchedule(in maintenanceSchedulePayload) store.Maintenan
 		UseLastDay:     in.UseLastDay,
 		WindowStart:    strings.TrimSpace(in.WindowStart),
 		WindowEnd:      strings.TrimSpace(in.WindowEnd),
+		ExDates:        in.ExDates,
+		Shift:          strings.TrimSpace(in.Shift),
+		CalendarID:     in.CalendarID,
 	}
 	if !in.ActiveFrom.IsZero() {
 		val := in.ActiveFrom.UTC()
@@ -330,7 +346,7 @@ func payloadToMaintenanceSchedule(in maintenanceSchedulePayload) store.Maintenan
 }
 
 func scheduleChanged(in maintenanceSchedulePayload) bool {
-	return strings.TrimSpace(in.CronExpression) != "" || in.DurationMin != 0 || in.IntervalDays != 0 || len(in.Weekdays) > 0 || len(in.MonthDays) > 0 || in.UseLastDay || strings.TrimSpace(in.WindowStart) != "" || strings.TrimSpace(in.WindowEnd) != "" || !in.ActiveFrom.IsZero() || !in.ActiveUntil.IsZero()
+	return strings.TrimSpace(in.CronExpression) != "" || in.DurationMin != 0 || in.IntervalDays != 0 || len(in.Weekdays) > 0 || len(in.MonthDays) > 0 || in.UseLastDay || strings.TrimSpace(in.WindowStart) != "" || strings.TrimSpace(in.WindowEnd) != "" || !in.ActiveFrom.IsZero() || !in.ActiveUntil.IsZero() || len(in.ExDates) > 0 || strings.TrimSpace(in.Shift) != "" || in.CalendarID != 0
 }
 
 func defaultMaintenanceStrategy(item *store.MonitorMaintenance) string {
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/notify"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+)
+
+const (
+	notificationsHeartbeat = 25 * time.Second
+	// notificationsStreamTTL closes long-lived streams so the client reconnects and the session is re-validated.
+	notificationsStreamTTL = 10 * time.Minute
+)
+
+const maxMentionCandidates = 10
+
+type NotificationsHandler struct {
+	svc        *notify.Service
+	monitoring store.MonitoringStore
+	users      store.UsersStore
+	audits     store.AuditStore
+	policy     *rbac.Policy
+}
+
+func NewNotificationsHandler(svc *notify.Service, monitoring store.MonitoringStore, users store.UsersStore, audits store.AuditStore, policy *rbac.Policy) *NotificationsHandler {
+	return &NotificationsHandler{svc: svc, monitoring: monitoring, users: users, audits: audits, policy: policy}
+}
+
+type notificationIDsPayload struct {
+	IDs []int64 `json:"ids"`
+}
+
+type mentionCandidate struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+type notificationChannelOption struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// List returns the current user's inbox; ?unread=1 limits it to unread items.
+func (h *NotificationsHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	filter := store.NotificationFilter{
+		UnreadOnly: parseBool(q.Get("unread")),
+		EventType:  strings.TrimSpace(q.Get("event_type")),
+		Limit:      parseIntDefault(q.Get("limit"), 50),
+		Offset:     parseIntDefault(q.Get("offset"), 0),
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	items, err := h.svc.Store().ListNotifications(r.Context(), sess.UserID, filter)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	unread, err := h.svc.Store().CountUnreadNotifications(r.Context(), sess.UserID)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.Notification{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "unread": unread})
+}
+
+// Stream delivers new notifications as Server-Sent Events. A reconnecting client passes
+// Last-Event-ID (or ?after=) and receives what it missed before the live events.
+func (h *NotificationsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	events, cancel := h.svc.Subscribe(sess.UserID)
+	defer cancel()
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/event-stream")
+	hdr.Set("Cache-Control", "no-cache")
+	hdr.Set("Connection", "keep-alive")
+	hdr.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	after := parseInt64Default(r.Header.Get("Last-Event-ID"), 0)
+	if after <= 0 {
+		after = parseInt64Default(r.URL.Query().Get("after"), 0)
+	}
+	unread, _ := h.svc.Store().CountUnreadNotifications(ctx, sess.UserID)
+	if after > 0 {
+		missed, _ := h.svc.Store().ListNotifications(ctx, sess.UserID, store.NotificationFilter{AfterID: after, Limit: 200})
+		for i := len(missed) - 1; i >= 0; i-- {
+			n := missed[i]
+			if err := writeNotificationEvent(w, notify.Event{Kind: notify.KindNotification, Notification: &n, Unread: unread}); err != nil {
+				return
+			}
+		}
+	}
+	if err := writeNotificationEvent(w, notify.Event{Kind: notify.KindSync, Unread: unread}); err != nil {
+		return
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(notificationsHeartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(notificationsStreamTTL)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeNotificationEvent(w, ev); err != nil {
+				return
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeNotificationEvent(w http.ResponseWriter, ev notify.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if ev.Notification != nil {
+		b.WriteString("id: " + strconv.FormatInt(ev.Notification.ID, 10) + "\n")
+	}
+	b.WriteString("event: " + ev.Kind + "\n")
+	b.WriteString("data: " + string(data) + "\n\n")
+	_, err = w.Write([]byte(b.String()))
+	return err
+}
+
+// MarkRead marks the given notifications (all of them when ids is empty) as read.
+func (h *NotificationsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	h.updateState(w, r, false)
+}
+
+// Dismiss hides the given notifications (all of them when ids is empty) from the inbox.
+func (h *NotificationsHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	h.updateState(w, r, true)
+}
+
+func (h *NotificationsHandler) updateState(w http.ResponseWriter, r *http.Request, dismiss bool) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload notificationIDsPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	ns := h.svc.Store()
+	apply := ns.MarkNotificationsRead
+	if dismiss {
+		apply = ns.DismissNotifications
+	}
+	changed, err := apply(r.Context(), sess.UserID, payload.IDs, time.Now().UTC())
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if changed > 0 {
+		h.svc.Sync(r.Context(), sess.UserID)
+	}
+	unread, _ := ns.CountUnreadNotifications(r.Context(), sess.UserID)
+	writeJSON(w, http.StatusOK, map[string]any{"changed": changed, "unread": unread})
+}
+
+// Mentions lists comments and notes that mention the current user, newest first.
+func (h *NotificationsHandler) Mentions(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	ms := h.svc.Mentions()
+	if ms == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"items": []store.Mention{}})
+		return
+	}
+	q := r.URL.Query()
+	items, err := ms.ListUserMentions(r.Context(), sess.UserID, parseIntDefault(q.Get("limit"), 50), parseIntDefault(q.Get("offset"), 0))
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.Mention{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// MentionCandidates backs @username autocomplete with active users matching ?q=.
+func (h *NotificationsHandler) MentionCandidates(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.users == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	query := strings.TrimPrefix(strings.TrimSpace(r.URL.Query().Get("q")), "@")
+	out := []mentionCandidate{}
+	if query == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"items": out})
+		return
+	}
+	users, err := h.users.ListFiltered(r.Context(), store.UserFilter{Status: "active", Query: query})
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	for _, u := range users {
+		if len(out) >= maxMentionCandidates {
+			break
+		}
+		out = append(out, mentionCandidate{Username: u.Username, FullName: u.FullName})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+// GetPreferences returns the user's delivery settings together with the channels they may pick.
+func (h *NotificationsHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	prefs, err := h.svc.Store().ListNotificationPreferences(r.Context(), sess.UserID)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	channels, err := h.channelOptions(r)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if prefs == nil {
+		prefs = []store.NotificationPreference{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"event_types": notify.EventTypes,
+		"preferences": prefs,
+		"channels":    channels,
+	})
+}
+
+// UpdatePreferences replaces the user's delivery settings.
+func (h *NotificationsHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromCtx(r)
+	if sess == nil || h.svc == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		Preferences []store.NotificationPreference `json:"preferences"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	channels, err := h.channelOptions(r)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	known := map[int64]bool{}
+	for _, ch := range channels {
+		known[ch.ID] = true
+	}
+	seen := map[string]bool{}
+	var prefs []store.NotificationPreference
+	for _, p := range payload.Preferences {
+		p.EventType = strings.ToLower(strings.TrimSpace(p.EventType))
+		if !notify.IsEventType(p.EventType) {
+			http.Error(w, "notifications.errors.eventTypeInvalid", http.StatusBadRequest)
+			return
+		}
+		if seen[p.EventType] {
+			continue
+		}
+		seen[p.EventType] = true
+		if p.External && (p.ChannelID == nil || !known[*p.ChannelID]) {
+			http.Error(w, "notifications.errors.channelInvalid", http.StatusBadRequest)
+			return
+		}
+		if !p.External {
+			p.ChannelID = nil
+		}
+		prefs = append(prefs, p)
+	}
+	if err := h.svc.Store().SaveNotificationPreferences(r.Context(), sess.UserID, prefs); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if h.audits != nil {
+		_ = h.audits.Log(r.Context(), sess.Username, "notifications.preferences.update", fmt.Sprintf("external=%d", countExternal(prefs)))
+	}
+	if prefs == nil {
+		prefs = []store.NotificationPreference{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"preferences": prefs})
+}
+
+// channelOptions lists active Telegram channels by name only; tokens and chat ids stay with the monitoring settings.
+// The channels are shared chats, so only users who manage monitoring notifications may route their inbox there.
+func (h *NotificationsHandler) channelOptions(r *http.Request) ([]notificationChannelOption, error) {
+	out := []notificationChannelOption{}
+	if h.monitoring == nil || !hasPermission(r, h.policy, "monitoring.notifications.manage") {
+		return out, nil
+	}
+	items, err := h.monitoring.ListNotificationChannels(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range items {
+		if !ch.IsActive || ch.Type != "telegram" {
+			continue
+		}
+		out = append(out, notificationChannelOption{ID: ch.ID, Name: ch.Name})
+	}
+	return out, nil
+}
+
+func countExternal(prefs []store.NotificationPreference) int {
+	n := 0
+	for _, p := range prefs {
+		if p.External {
+			n++
+		}
+	}
+	return n
+}
@@ -22,6 +22,7 @@ var pageFiles = map[string]string{
 	"backups":    "backups.html",
 	"tasks":      "tasks.html",
 	"findings":   "findings.html",
+	"risks":      "risks.html",
 	"incidents":  "incidents.html",
 	"reports":    "reports.html",
 	"monitoring": "monitoring.html",
@@ -70,6 +71,8 @@ func RequiredPermission(name string) rbac.Permission {
 		return "tasks.view"
 	case "findings":
 		return "findings.view"
+	case "risks":
+		return "risks.view"
 	case "incidents":
 		return "incidents.view"
 	case "reports":
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +14,8 @@ import (
 	"berkut-scc/core/store"
 )
 
+var errReportPeriodRequired = errors.New("reports.error.periodRequired")
+
 type reportBuildPayload struct {
 	Reason     string `json:"reason"`
 	Mode       string `json:"mode"`
@@ -63,46 +67,60 @@ func (h *ReportsHandler) Build(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	sections, err := h.reports.ListReportSections(r.Context(), doc.ID)
+	v, snapshot, err := h.buildReport(r.Context(), doc, meta, user, roles, groups, eff, mode, payload.Reason)
 	if err != nil {
+		if errors.Is(err, errReportPeriodRequired) {
+			http.Error(w, localized(preferredLang(r), err.Error()), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"version":  v.Version,
+		"snapshot": snapshot.ID,
+	})
+}
+
+// buildReport regenerates the sections of a report as user, saves the result as a
+// new version and records a snapshot of the data it was built from.
+func (h *ReportsHandler) buildReport(ctx context.Context, doc *store.Document, meta *store.ReportMeta, user *store.User, roles []string, groups []store.Group, eff store.EffectiveAccess, mode, reason string) (*store.DocVersion, *store.ReportSnapshot, error) {
+	sections, err := h.reports.ListReportSections(ctx, doc.ID)
+	if err != nil {
+		return nil, nil, err
+	}
 	if len(sections) == 0 {
 		sections = defaultReportSections()
 	}
 	if (meta == nil || (meta.PeriodFrom == nil && meta.PeriodTo == nil)) && requiresReportPeriod(sections) {
-		http.Error(w, localized(preferredLang(r), "reports.error.periodRequired"), http.StatusBadRequest)
-		return
+		return nil, nil, errReportPeriodRequired
 	}
-	sectionResults, snapshotItems, snapshotPayload, err := h.buildReportSections(r.Context(), doc, meta, sections, user, roles, groups, eff)
+	sectionResults, snapshotItems, snapshotPayload, err := h.buildReportSections(ctx, doc, meta, sections, user, roles, groups, eff)
 	if err != nil {
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
+		return nil, nil, err
 	}
 	var baseContent string
 	if mode == "markers" {
-		if ver, err := h.docs.GetVersion(r.Context(), doc.ID, doc.CurrentVersion); err == nil && ver != nil {
-			if content, err := h.svc.LoadContent(r.Context(), ver); err == nil {
+		if ver, err := h.docs.GetVersion(ctx, doc.ID, doc.CurrentVersion); err == nil && ver != nil {
+			if content, err := h.svc.LoadContent(ctx, ver); err == nil {
 				baseContent = string(content)
 			}
 		}
 	}
 	nextContent := applySectionContent(mode, baseContent, sectionResults)
-	v, err := h.svc.SaveVersion(r.Context(), docs.SaveRequest{
+	v, err := h.svc.SaveVersion(ctx, docs.SaveRequest{
 		Doc:      doc,
 		Author:   user,
 		Format:   docs.FormatMarkdown,
 		Content:  []byte(nextContent),
-		Reason:   payload.Reason,
+		Reason:   reason,
 		IndexFTS: true,
 	})
 	if err != nil {
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
+		return nil, nil, err
 	}
 	doc.CurrentVersion = v.Version
-	_ = h.docs.UpdateDocument(r.Context(), doc)
+	_ = h.docs.UpdateDocument(ctx, doc)
 
 	payloadBytes, _ := json.Marshal(snapshotPayload)
 	sum := sha256.Sum256(payloadBytes)
@@ -110,21 +128,17 @@ func (h *ReportsHandler) Build(w http.ResponseWriter, r *http.Request) {
 		ReportID:     doc.ID,
 		CreatedAt:    time.Now().UTC(),
 		CreatedBy:    user.ID,
-		Reason:       payload.Reason,
+		Reason:       reason,
 		Snapshot:     snapshotPayload,
 		SnapshotJSON: string(payloadBytes),
 		Sha256:       hex.EncodeToString(sum[:]),
 	}
-	if _, err := h.reports.CreateReportSnapshot(r.Context(), snapshot, snapshotItems); err != nil {
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
+	if _, err := h.reports.CreateReportSnapshot(ctx, snapshot, snapshotItems); err != nil {
+		return nil, nil, err
 	}
-	h.log(r.Context(), user.Username, "report.build", doc.RegNumber)
-	h.log(r.Context(), user.Username, "report.snapshot.create", doc.RegNumber)
-	writeJSON(w, http.StatusOK, map[string]any{
-		"version":  v.Version,
-		"snapshot": snapshot.ID,
-	})
+	h.log(ctx, user.Username, "report.build", doc.RegNumber)
+	h.log(ctx, user.Username, "report.snapshot.create", doc.RegNumber)
+	return v, snapshot, nil
 }
 
 func (h *ReportsHandler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
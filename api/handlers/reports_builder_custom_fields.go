@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/store"
+)
+
+// SetCustomFields lets incident, finding and task sections show and filter by
+// admin-defined fields.
+func (h *ReportsHandler) SetCustomFields(svc *customfields.Service) {
+	if h == nil {
+		return
+	}
+	h.fields = svc
+}
+
+// sectionCustomFields holds the custom_fields columns and custom_filters conditions
+// of a section, limited to fields visible to the report author.
+type sectionCustomFields struct {
+	entityType string
+	columns    []store.CustomField
+	conditions []store.CustomFieldCondition
+	values     map[int64]map[string]string
+}
+
+func (h *ReportsHandler) sectionCustomFields(ctx context.Context, sec store.ReportSection, entityType string, roles []string) (*sectionCustomFields, error) {
+	out := &sectionCustomFields{entityType: entityType}
+	keys := configStrings(sec.Config, "custom_fields")
+	filters := configStringMap(sec.Config, "custom_filters")
+	if h.fields == nil || (len(keys) == 0 && len(filters) == 0) {
+		return out, nil
+	}
+	conditions, err := h.fields.ConditionsFor(ctx, entityType, roles, filters)
+	if err != nil {
+		return nil, err
+	}
+	out.conditions = conditions
+	if len(keys) == 0 {
+		return out, nil
+	}
+	visible, err := h.fields.Fields(ctx, entityType, roles)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]store.CustomField{}
+	for _, f := range visible {
+		byKey[f.Key] = f
+	}
+	for _, key := range keys {
+		if f, ok := byKey[strings.ToLower(key)]; ok {
+			out.columns = append(out.columns, f)
+		}
+	}
+	return out, nil
+}
+
+// load reads the column values of the rows that made it into the section.
+func (c *sectionCustomFields) load(ctx context.Context, svc *customfields.Service, ids []int64) {
+	if len(c.columns) == 0 {
+		return
+	}
+	c.values, _ = svc.DisplayValues(ctx, c.entityType, c.columns, ids)
+}
+
+func (c *sectionCustomFields) header() string {
+	var b strings.Builder
+	for _, f := range c.columns {
+		b.WriteString(" " + escapePipes(f.Label) + " |")
+	}
+	return b.String()
+}
+
+func (c *sectionCustomFields) separator() string {
+	return strings.Repeat("---|", len(c.columns))
+}
+
+func (c *sectionCustomFields) cells(id int64) string {
+	var b strings.Builder
+	for _, f := range c.columns {
+		v := c.values[id][f.Key]
+		if v == "" {
+			v = "-"
+		}
+		b.WriteString(" " + escapePipes(v) + " |")
+	}
+	return b.String()
+}
+
+// snapshot adds the column values to a snapshot entity under custom_fields.
+func (c *sectionCustomFields) snapshot(entity map[string]any, id int64) {
+	if len(c.columns) == 0 {
+		return
+	}
+	values := map[string]string{}
+	for _, f := range c.columns {
+		values[f.Key] = c.values[id][f.Key]
+	}
+	entity["custom_fields"] = values
+}
+
+func configStringMap(cfg map[string]any, key string) map[string]string {
+	if cfg == nil {
+		return nil
+	}
+	raw, ok := cfg[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := map[string]string{}
+	for k, v := range raw {
+		s := strings.TrimSpace(fmt.Sprintf("%v", v))
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && s != "" {
+			out[k] = s
+		}
+	}
+	return out
+}
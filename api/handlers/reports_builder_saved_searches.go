@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/query"
+	"berkut-scc/core/store"
+)
+
+// SetQueries lets incident, finding, task and document sections narrow their rows
+// with a query or a saved search of the report author.
+func (h *ReportsHandler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
+// sectionQuery compiles the saved_search and query settings of a section. The saved
+// search must be visible to the report author.
+func (h *ReportsHandler) sectionQuery(ctx context.Context, sec store.ReportSection, entityType string, user *store.User, roles []string) (*store.QueryClause, error) {
+	params := url.Values{}
+	if id := configInt(sec.Config, "saved_search", 0); id > 0 {
+		params.Set("saved_search", strconv.Itoa(id))
+	}
+	if text := strings.TrimSpace(configString(sec.Config, "query")); text != "" {
+		params.Set("query", text)
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	if h.queries == nil {
+		return nil, query.ErrSearchNotFound
+	}
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	return h.queries.FromRequest(ctx, entityType, params, userID, roles)
+}
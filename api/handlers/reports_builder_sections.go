@@ -54,6 +54,22 @@ func (h *ReportsHandler) buildReportSections(ctx context.Context, doc *store.Doc
 			res = h.buildMonitoringSection(ctx, sec, user, roles, periodFrom, periodTo, totals)
 		case "sla_summary":
 			res = h.buildSLASummarySection(ctx, sec, user, roles, periodFrom, periodTo, totals)
+		case "software_eol":
+			res = h.buildSoftwareEOLSection(ctx, sec, user, roles, totals)
+		case "risks":
+			res = h.buildRisksSection(ctx, sec, user, roles, totals)
+		case "findings":
+			res = h.buildFindingsSection(ctx, sec, user, roles, totals)
+		case "assets":
+			res = h.buildAssetsSection(ctx, sec, user, roles, totals)
+		case "software":
+			res = h.buildSoftwareSection(ctx, sec, user, roles, totals)
+		case "framework_coverage":
+			res = h.buildFrameworkCoverageSection(ctx, sec, user, roles, totals)
+		case "effort":
+			res = h.buildEffortSection(ctx, sec, user, roles, groups, periodFrom, periodTo, totals)
+		case "task_flow":
+			res = h.buildTaskFlowSection(ctx, sec, user, roles, groups, periodFrom, periodTo, totals)
 		case "audit":
 			res = h.buildAuditSection(ctx, sec, user, roles, periodFrom, periodTo, totals)
 		case "custom_md":
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// SetInventory lets the assets and software sections read the registries.
+func (h *ReportsHandler) SetInventory(assets store.AssetsStore, software store.SoftwareStore) {
+	if h == nil {
+		return
+	}
+	h.assets = assets
+	h.software = software
+}
+
+// buildAssetsSection counts assets by criticality, environment and type and lists
+// the most critical ones. Config "criticality", "env", "type", "status" and "tag"
+// narrow the assets.
+func (h *ReportsHandler) buildAssetsSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "assets.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Assets"))
+		return res
+	}
+	if h.assets == nil {
+		res.Error = "assets unavailable"
+		return res
+	}
+	custom, err := h.sectionCustomFields(ctx, sec, store.CustomFieldEntityAsset, roles)
+	if err != nil {
+		res.Error = "custom field filter invalid"
+		return res
+	}
+	clause, err := h.sectionQuery(ctx, sec, store.SearchEntityAsset, user, roles)
+	if err != nil {
+		res.Error = "query invalid"
+		return res
+	}
+	all, err := listAllAssets(ctx, h.assets, store.AssetFilter{
+		Criticality:  configString(sec.Config, "criticality"),
+		Env:          configString(sec.Config, "env"),
+		Type:         configString(sec.Config, "type"),
+		Status:       configString(sec.Config, "status"),
+		Tag:          configString(sec.Config, "tag"),
+		CustomFields: custom.conditions,
+		Query:        clause,
+	})
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	limit := configInt(sec.Config, "limit", 20)
+	byCriticality := map[string]int{}
+	byEnv := map[string]int{}
+	byType := map[string]int{}
+	ids := make([]int64, 0, len(all))
+	for _, a := range all {
+		byCriticality[a.Criticality]++
+		byEnv[a.Env]++
+		byType[a.Type]++
+		ids = append(ids, a.ID)
+	}
+	custom.load(ctx, h.fields, ids)
+	for _, a := range all {
+		item := assetSnapshotItem(a)
+		custom.snapshot(item.Entity, a.ID)
+		res.Items = append(res.Items, item)
+	}
+	listed := append([]store.Asset(nil), all...)
+	sort.SliceStable(listed, func(i, j int) bool {
+		return assetCriticalityRank(listed[i].Criticality) > assetCriticalityRank(listed[j].Criticality)
+	})
+	if limit > 0 && len(listed) > limit {
+		listed = listed[:limit]
+	}
+	res.ItemCount = len(listed)
+	res.Summary = map[string]any{
+		"assets":          len(all),
+		"assets_critical": byCriticality["critical"],
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Assets")))
+	b.WriteString(fmt.Sprintf("- Total: %d\n", len(all)))
+	b.WriteString(fmt.Sprintf("- By criticality: critical %d, high %d, medium %d, low %d\n",
+		byCriticality["critical"], byCriticality["high"], byCriticality["medium"], byCriticality["low"]))
+	b.WriteString(fmt.Sprintf("- By environment: %s\n", formatCounts(byEnv)))
+	b.WriteString(fmt.Sprintf("- By type: %s\n", formatCounts(byType)))
+	if len(listed) == 0 {
+		b.WriteString("\n_No assets for selected filters._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("\n| Name | Type | Criticality | Environment | Owner | Status |" + custom.header() + "\n|---|---|---|---|---|---|" + custom.separator() + "\n")
+	for _, a := range listed {
+		owner := a.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |%s\n",
+			escapePipes(a.Name),
+			a.Type,
+			a.Criticality,
+			a.Env,
+			escapePipes(owner),
+			a.Status,
+			custom.cells(a.ID),
+		))
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func assetSnapshotItem(a store.Asset) store.ReportSnapshotItem {
+	return store.ReportSnapshotItem{
+		EntityType: "asset",
+		EntityID:   fmt.Sprintf("%d", a.ID),
+		Entity: map[string]any{
+			"name":        a.Name,
+			"type":        a.Type,
+			"criticality": a.Criticality,
+			"env":         a.Env,
+			"owner":       a.Owner,
+			"status":      a.Status,
+			"created_at":  a.CreatedAt.UTC().Format(time.RFC3339),
+			"updated_at":  a.UpdatedAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+func assetCriticalityRank(val string) int {
+	switch strings.ToLower(val) {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+// formatCounts renders counts as "key n" pairs, largest first.
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] == counts[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return counts[keys[i]] > counts[keys[j]]
+	})
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		label := k
+		if label == "" {
+			label = "unknown"
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", label, counts[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func listAllAssets(ctx context.Context, as store.AssetsStore, filter store.AssetFilter) ([]store.Asset, error) {
+	const page = 500
+	var out []store.Asset
+	filter.Limit = page
+	for offset := 0; ; offset += page {
+		filter.Offset = offset
+		items, err := as.ListAssets(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
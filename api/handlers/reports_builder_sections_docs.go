@@ -19,9 +19,15 @@ func (h *ReportsHandler) buildDocsSection(ctx context.Context, sec store.ReportS
 	}
 	from, to := periodOverride(sec.Config, fallbackFrom, fallbackTo)
 	limit := configInt(sec.Config, "limit", 20)
+	clause, err := h.sectionQuery(ctx, sec, store.SearchEntityDoc, user, roles)
+	if err != nil {
+		res.Error = "query invalid"
+		return res
+	}
 	filter := store.DocumentFilter{
 		Status:  configString(sec.Config, "status"),
 		Tags:    configStrings(sec.Config, "tags"),
+		Query:   clause,
 		Limit:   limit * 5,
 		DocType: "document",
 	}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+func (h *ReportsHandler) buildEffortSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, groups []store.Group, fallbackFrom, fallbackTo *time.Time, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !tasks.Allowed(h.policy, roles, tasks.PermView) {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Effort"))
+		return res
+	}
+	if h.tasksSvc == nil {
+		res.Error = "tasks unavailable"
+		return res
+	}
+	groupBy := strings.ToLower(configString(sec.Config, "group_by"))
+	if !tasks.IsEffortGroup(groupBy) {
+		groupBy = tasks.EffortByUser
+	}
+	from, to := periodOverride(sec.Config, fallbackFrom, fallbackTo)
+	filter := tasks.WorklogFilter{
+		Customer: configString(sec.Config, "customer"),
+		From:     from,
+		To:       to,
+	}
+	if v := configInt(sec.Config, "board_id", 0); v > 0 {
+		filter.BoardID = int64(v)
+	}
+	if v := configInt(sec.Config, "space_id", 0); v > 0 {
+		filter.SpaceID = int64(v)
+	}
+	entries, err := h.tasksSvc.Store().ListWorklogs(ctx, filter)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	allowed := map[int64]bool{}
+	var visible []tasks.WorklogEntry
+	for _, e := range entries {
+		ok, seen := allowed[e.BoardID]
+		if !seen {
+			var spaceACL []tasks.ACLRule
+			if e.SpaceID > 0 {
+				spaceACL, _ = h.tasksSvc.Store().GetSpaceACL(ctx, e.SpaceID)
+			}
+			boardACL, _ := h.tasksSvc.Store().GetBoardACL(ctx, e.BoardID)
+			ok = taskBoardAllowed(user, roles, groups, spaceACL, boardACL, "view")
+			allowed[e.BoardID] = ok
+		}
+		if ok {
+			visible = append(visible, e)
+		}
+	}
+	userCache := map[int64]string{}
+	rows := tasks.AggregateWorklogs(visible, groupBy, func(id int64) string { return h.cachedUserName(userCache, id) })
+	total := 0
+	for _, row := range rows {
+		total += row.Minutes
+	}
+	limit := configInt(sec.Config, "limit", 20)
+	if len(rows) > limit && limit > 0 {
+		rows = rows[:limit]
+	}
+	res.ItemCount = len(rows)
+	res.Summary = map[string]any{
+		"effort_minutes": total,
+		"worklogs":       len(visible),
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Effort")))
+	b.WriteString(fmt.Sprintf("- Hours logged: %s\n", formatEffortHours(total)))
+	b.WriteString(fmt.Sprintf("- Worklog entries: %d\n", len(visible)))
+	if len(rows) == 0 {
+		b.WriteString("\n_No time logged for selected period._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString(fmt.Sprintf("\n| %s | Hours | Entries |\n|---|---|---|\n", effortGroupHeader(groupBy)))
+	for _, row := range rows {
+		label := row.Label
+		if strings.TrimSpace(label) == "" {
+			label = "-"
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %d |\n", escapePipes(label), formatEffortHours(row.Minutes), row.Entries))
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "effort",
+			EntityID:   groupBy + ":" + row.Key,
+			Entity: map[string]any{
+				"group_by": groupBy,
+				"key":      row.Key,
+				"label":    label,
+				"minutes":  row.Minutes,
+				"entries":  row.Entries,
+			},
+		})
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func effortGroupHeader(groupBy string) string {
+	switch groupBy {
+	case tasks.EffortByBoard:
+		return "Board"
+	case tasks.EffortBySpace:
+		return "Space"
+	case tasks.EffortByCustomer:
+		return "Business customer"
+	default:
+		return "User"
+	}
+}
+
+func formatEffortHours(minutes int) string {
+	return fmt.Sprintf("%.1f", float64(minutes)/60)
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// findingsChartWindowDays covers the longest burn-down window, so findings
+// resolved within it stay in the snapshot.
+const findingsChartWindowDays = 31
+
+// buildFindingsSection summarises open findings by severity and SLA state and
+// lists the overdue ones first, then those closest to their due date. Config
+// "severity" and "status" (lists) and "min_age_days" narrow the findings.
+func (h *ReportsHandler) buildFindingsSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "findings.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Findings"))
+		return res
+	}
+	if h.findings == nil {
+		res.Error = "findings unavailable"
+		return res
+	}
+	custom, err := h.sectionCustomFields(ctx, sec, store.CustomFieldEntityFinding, roles)
+	if err != nil {
+		res.Error = "custom field filter invalid"
+		return res
+	}
+	clause, err := h.sectionQuery(ctx, sec, store.SearchEntityFinding, user, roles)
+	if err != nil {
+		res.Error = "query invalid"
+		return res
+	}
+	all, err := listAllFindings(ctx, h.findings, store.FindingFilter{CustomFields: custom.conditions, Query: clause})
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	limit := configInt(sec.Config, "limit", 20)
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -findingsChartWindowDays)
+	all = filterFindings(all, configStrings(sec.Config, "severity"), configStrings(sec.Config, "status"), configInt(sec.Config, "min_age_days", 0), now)
+
+	bySeverity := map[string]int{}
+	var open []store.Finding
+	overdue, dueSoon, accepted := 0, 0, 0
+	for _, f := range all {
+		resolvedAt := findingResolvedAt(f)
+		if resolvedAt != nil {
+			if resolvedAt.After(since) {
+				res.Items = append(res.Items, findingSnapshotItem(f, resolvedAt, now))
+			}
+			continue
+		}
+		res.Items = append(res.Items, findingSnapshotItem(f, nil, now))
+		if f.Status == "accepted_risk" {
+			accepted++
+			continue
+		}
+		bySeverity[f.Severity]++
+		open = append(open, f)
+		switch {
+		case findingOverdue(f, now):
+			overdue++
+		case f.DueAt != nil && f.DueAt.Sub(now) <= 7*24*time.Hour:
+			dueSoon++
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		a, b := open[i].DueAt, open[j].DueAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+	openCount := len(open)
+	if limit > 0 && len(open) > limit {
+		open = open[:limit]
+	}
+	res.ItemCount = len(open)
+	ids := make([]int64, 0, len(all))
+	for _, f := range all {
+		ids = append(ids, f.ID)
+	}
+	custom.load(ctx, h.fields, ids)
+	for i := range res.Items {
+		custom.snapshot(res.Items[i].Entity, parseInt64Default(res.Items[i].EntityID, 0))
+	}
+	res.Summary = map[string]any{
+		"findings_open":    openCount,
+		"findings_overdue": overdue,
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Findings")))
+	b.WriteString(fmt.Sprintf("- Open: %d (critical %d, high %d, medium %d, low %d)\n",
+		openCount, bySeverity["critical"], bySeverity["high"], bySeverity["medium"], bySeverity["low"]))
+	b.WriteString(fmt.Sprintf("- Overdue: %d\n", overdue))
+	b.WriteString(fmt.Sprintf("- Due within 7 days: %d\n", dueSoon))
+	b.WriteString(fmt.Sprintf("- Accepted risk: %d\n", accepted))
+	if len(open) == 0 {
+		b.WriteString("\n_No open findings._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("\n| ID | Title | Severity | Owner | Due | Age (days) |" + custom.header() + "\n|---|---|---|---|---|---|" + custom.separator() + "\n")
+	for _, f := range open {
+		due := "-"
+		if f.DueAt != nil {
+			due = f.DueAt.Format("2006-01-02")
+			if findingOverdue(f, now) {
+				due += " (overdue)"
+			}
+		}
+		owner := f.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s | %d |%s\n",
+			f.ID,
+			escapePipes(f.Title),
+			f.Severity,
+			escapePipes(owner),
+			due,
+			int(now.Sub(f.CreatedAt).Hours()/24),
+			custom.cells(f.ID),
+		))
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+// filterFindings keeps findings matching any of the severities and statuses
+// (empty lists match all) that were created at least minAgeDays ago.
+func filterFindings(items []store.Finding, severities, statuses []string, minAgeDays int, now time.Time) []store.Finding {
+	sev := lowerSet(severities)
+	st := lowerSet(statuses)
+	out := items[:0]
+	for _, f := range items {
+		if len(sev) > 0 {
+			if _, ok := sev[strings.ToLower(f.Severity)]; !ok {
+				continue
+			}
+		}
+		if len(st) > 0 {
+			if _, ok := st[strings.ToLower(f.Status)]; !ok {
+				continue
+			}
+		}
+		if minAgeDays > 0 && now.Sub(f.CreatedAt) < time.Duration(minAgeDays)*24*time.Hour {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func lowerSet(values []string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out[v] = struct{}{}
+		}
+	}
+	return out
+}
+
+func findingSnapshotItem(f store.Finding, resolvedAt *time.Time, now time.Time) store.ReportSnapshotItem {
+	entity := map[string]any{
+		"title":      f.Title,
+		"severity":   f.Severity,
+		"status":     f.Status,
+		"owner":      f.Owner,
+		"created_at": f.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at": f.UpdatedAt.UTC().Format(time.RFC3339),
+		"overdue":    findingOverdue(f, now),
+	}
+	if resolvedAt != nil {
+		entity["resolved_at"] = resolvedAt.UTC().Format(time.RFC3339)
+	}
+	if f.DueAt != nil {
+		entity["due_at"] = f.DueAt.UTC().Format(time.RFC3339)
+	}
+	return store.ReportSnapshotItem{
+		EntityType: "finding",
+		EntityID:   fmt.Sprintf("%d", f.ID),
+		Entity:     entity,
+	}
+}
+
+// findingResolvedAt falls back to updated_at for findings closed before
+// resolution times were recorded.
+func findingResolvedAt(f store.Finding) *time.Time {
+	if !store.FindingStatusClosed(f.Status) {
+		return nil
+	}
+	if f.ResolvedAt != nil {
+		return f.ResolvedAt
+	}
+	t := f.UpdatedAt
+	return &t
+}
+
+func findingOverdue(f store.Finding, now time.Time) bool {
+	if f.SLAPausedAt != nil || f.DueAt == nil || (f.Status != "open" && f.Status != "in_progress") {
+		return false
+	}
+	return !now.Before(*f.DueAt)
+}
+
+func listAllFindings(ctx context.Context, fs store.FindingsStore, filter store.FindingFilter) ([]store.Finding, error) {
+	const page = 500
+	var out []store.Finding
+	filter.Limit = page
+	for offset := 0; ; offset += page {
+		filter.Offset = offset
+		items, err := fs.ListFindings(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+func (h *ReportsHandler) buildTaskFlowSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, groups []store.Group, fallbackFrom, fallbackTo *time.Time, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !tasks.Allowed(h.policy, roles, tasks.PermView) {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Task flow"))
+		return res
+	}
+	if h.tasksSvc == nil {
+		res.Error = "tasks unavailable"
+		return res
+	}
+	boardID := int64(configInt(sec.Config, "board_id", 0))
+	if boardID <= 0 {
+		res.Error = "board required"
+		return res
+	}
+	board, err := h.tasksSvc.Store().GetBoard(ctx, boardID)
+	if err != nil || board == nil {
+		res.Error = "board not found"
+		return res
+	}
+	var spaceACL []tasks.ACLRule
+	if board.SpaceID > 0 {
+		spaceACL, _ = h.tasksSvc.Store().GetSpaceACL(ctx, board.SpaceID)
+	}
+	boardACL, _ := h.tasksSvc.Store().GetBoardACL(ctx, board.ID)
+	if !taskBoardAllowed(user, roles, groups, spaceACL, boardACL, "view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Task flow"))
+		return res
+	}
+	now := time.Now().UTC()
+	from, to := periodOverride(sec.Config, fallbackFrom, fallbackTo)
+	end := tasks.WorkDay(now)
+	if to != nil {
+		end = tasks.WorkDay(*to)
+	}
+	start := end.AddDate(0, 0, -29)
+	if from != nil {
+		start = tasks.WorkDay(*from)
+	}
+	if earliest := end.AddDate(0, 0, -(tasks.MaxFlowDays - 1)); start.Before(earliest) {
+		start = earliest
+	}
+	flow, err := tasks.LoadBoardFlow(ctx, h.tasksSvc.Store(), board.ID, start, end, now)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+
+	columnNames := map[int64]string{}
+	for _, c := range flow.Columns {
+		columnNames[c.ID] = c.Name
+	}
+	boardRef := strconv.FormatInt(board.ID, 10)
+	for _, day := range flow.Cumulative {
+		date := day.Date.Format("2006-01-02")
+		for _, c := range flow.Columns {
+			res.Items = append(res.Items, store.ReportSnapshotItem{
+				EntityType: "task_flow_day",
+				EntityID:   fmt.Sprintf("%s:%s:%d", boardRef, date, c.ID),
+				Entity: map[string]any{
+					"board_id": board.ID,
+					"date":     date,
+					"column":   c.Name,
+					"position": c.Position,
+					"count":    day.Counts[c.ID],
+				},
+			})
+		}
+	}
+	for _, done := range flow.Completed {
+		entity := map[string]any{
+			"board_id":          board.ID,
+			"task_id":           done.TaskID,
+			"title":             done.Title,
+			"done_at":           done.DoneAt.Format(time.RFC3339),
+			"lead_time_seconds": done.LeadTimeSeconds,
+		}
+		if done.CycleTimeSeconds != nil {
+			entity["cycle_time_seconds"] = *done.CycleTimeSeconds
+		}
+		res.Items = append(res.Items, store.ReportSnapshotItem{EntityType: "task_flow_done", EntityID: strconv.FormatInt(done.TaskID, 10), Entity: entity})
+	}
+	for _, v := range flow.WIPViolations {
+		date := v.Date.Format("2006-01-02")
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "task_flow_wip",
+			EntityID:   fmt.Sprintf("%s:%s:%d", boardRef, date, v.ColumnID),
+			Entity: map[string]any{
+				"board_id": board.ID,
+				"date":     date,
+				"column":   columnNames[v.ColumnID],
+				"count":    v.Count,
+				"limit":    v.Limit,
+			},
+		})
+	}
+	for _, a := range flow.Ageing {
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "task_flow_ageing",
+			EntityID:   strconv.FormatInt(a.TaskID, 10),
+			Entity: map[string]any{
+				"board_id":    board.ID,
+				"task_id":     a.TaskID,
+				"title":       a.Title,
+				"column":      columnNames[a.ColumnID],
+				"since":       a.Since.Format(time.RFC3339),
+				"age_seconds": a.AgeSeconds,
+			},
+		})
+	}
+	res.ItemCount = len(flow.Completed) + len(flow.Ageing)
+	res.Summary = map[string]any{
+		"flow_done":           len(flow.Completed),
+		"flow_wip_violations": len(flow.WIPViolations),
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Task flow")))
+	b.WriteString(fmt.Sprintf("- Board: %s\n", escapePipes(board.Name)))
+	b.WriteString(fmt.Sprintf("- Period: %s - %s\n", flow.From.Format("2006-01-02"), flow.To.Format("2006-01-02")))
+	b.WriteString(fmt.Sprintf("- Tasks done: %d\n", len(flow.Completed)))
+	b.WriteString(fmt.Sprintf("- Lead time (days, p50 / p85 / p95): %s\n", formatFlowStats(flow.LeadTime)))
+	b.WriteString(fmt.Sprintf("- Cycle time (days, p50 / p85 / p95): %s\n", formatFlowStats(flow.CycleTime)))
+	b.WriteString(fmt.Sprintf("- WIP limit breaches (column-days): %d\n", len(flow.WIPViolations)))
+
+	if len(flow.Cumulative) > 0 {
+		last := flow.Cumulative[len(flow.Cumulative)-1]
+		breaches := map[int64]int{}
+		for _, v := range flow.WIPViolations {
+			breaches[v.ColumnID]++
+		}
+		b.WriteString("\n| Column | Tasks | WIP limit | Days over limit |\n|---|---|---|---|\n")
+		for _, c := range flow.Columns {
+			limit := "-"
+			if c.WIPLimit != nil && *c.WIPLimit > 0 {
+				limit = strconv.Itoa(*c.WIPLimit)
+			}
+			b.WriteString(fmt.Sprintf("| %s | %d | %s | %d |\n", escapePipes(c.Name), last.Counts[c.ID], limit, breaches[c.ID]))
+		}
+	}
+
+	ageing := flow.Ageing
+	if limit := configInt(sec.Config, "limit", 10); limit > 0 && len(ageing) > limit {
+		ageing = ageing[:limit]
+	}
+	if len(ageing) == 0 {
+		b.WriteString("\n_No open tasks._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("\n| Task | Column | Days in column |\n|---|---|---|\n")
+	for _, a := range ageing {
+		b.WriteString(fmt.Sprintf("| #%d %s | %s | %s |\n", a.TaskID, escapePipes(a.Title), escapePipes(columnNames[a.ColumnID]), formatFlowDays(a.AgeSeconds)))
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func formatFlowStats(stats tasks.DurationStats) string {
+	if stats.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s / %s", formatFlowDays(stats.P50), formatFlowDays(stats.P85), formatFlowDays(stats.P95))
+}
+
+func formatFlowDays(seconds int64) string {
+	return fmt.Sprintf("%.1f", float64(seconds)/86400)
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"berkut-scc/core/controls"
+	"berkut-scc/core/store"
+)
+
+// Coverage states of a framework requirement, from its best mapped control.
+const (
+	coverageCovered       = "covered"
+	coveragePartial       = "partial"
+	coverageNotCovered    = "not_covered"
+	coverageUnmapped      = "unmapped"
+	coverageNotApplicable = "not_applicable"
+)
+
+type frameworkCoverage struct {
+	framework store.ControlFramework
+	counts    map[string]int
+	gaps      []frameworkRequirement
+}
+
+type frameworkRequirement struct {
+	item     store.ControlFrameworkItem
+	state    string
+	controls []string
+}
+
+// pct is the share of applicable requirements covered by an implemented control.
+func (c frameworkCoverage) pct() float64 {
+	total := 0
+	for state, n := range c.counts {
+		if state != coverageNotApplicable {
+			total += n
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(c.counts[coverageCovered])*1000/float64(total)) / 10
+}
+
+func (c frameworkCoverage) label() string {
+	if strings.TrimSpace(c.framework.Version) == "" {
+		return c.framework.Name
+	}
+	return c.framework.Name + " " + c.framework.Version
+}
+
+// buildFrameworkCoverageSection reports, per framework, how many requirements are
+// covered by implemented active controls and lists the gaps. Config "framework_id"
+// limits the section to one framework, "include_inactive" adds inactive ones.
+func (h *ReportsHandler) buildFrameworkCoverageSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "controls.frameworks.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Framework coverage"))
+		return res
+	}
+	if h.controls == nil {
+		res.Error = "controls unavailable"
+		return res
+	}
+	frameworks, err := h.controls.ListFrameworks(ctx)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	allControls, err := h.controls.ListControls(ctx, store.ControlFilter{})
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	byID := map[int64]store.Control{}
+	for _, c := range allControls {
+		if c.IsActive {
+			byID[c.ID] = c
+		}
+	}
+	onlyID := int64(configInt(sec.Config, "framework_id", 0))
+	includeInactive := configBool(sec.Config, "include_inactive")
+	var report []frameworkCoverage
+	for _, fw := range frameworks {
+		if (onlyID > 0 && fw.ID != onlyID) || (onlyID == 0 && !fw.IsActive && !includeInactive) {
+			continue
+		}
+		cov, err := h.frameworkCoverage(ctx, fw, byID)
+		if err != nil {
+			res.Error = "load failed"
+			return res
+		}
+		report = append(report, cov)
+	}
+	limit := configInt(sec.Config, "limit", 20)
+	gaps := 0
+	for _, cov := range report {
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "framework",
+			EntityID:   fmt.Sprintf("%d", cov.framework.ID),
+			Entity: map[string]any{
+				"name":           cov.framework.Name,
+				"version":        cov.framework.Version,
+				"covered":        cov.counts[coverageCovered],
+				"partial":        cov.counts[coveragePartial],
+				"not_covered":    cov.counts[coverageNotCovered],
+				"unmapped":       cov.counts[coverageUnmapped],
+				"not_applicable": cov.counts[coverageNotApplicable],
+				"coverage_pct":   cov.pct(),
+			},
+		})
+		for _, gap := range cov.gaps {
+			res.Items = append(res.Items, store.ReportSnapshotItem{
+				EntityType: "framework_gap",
+				EntityID:   fmt.Sprintf("%d", gap.item.ID),
+				Entity: map[string]any{
+					"framework_id": cov.framework.ID,
+					"code":         gap.item.Code,
+					"title":        gap.item.Title,
+					"state":        gap.state,
+					"controls":     gap.controls,
+				},
+			})
+		}
+		gaps += len(cov.gaps)
+	}
+	res.ItemCount = len(report)
+	res.Summary = map[string]any{
+		"frameworks":     len(report),
+		"framework_gaps": gaps,
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Framework coverage")))
+	if len(report) == 0 {
+		b.WriteString("_No frameworks for selected filters._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("| Framework | Covered | Partial | Not covered | Unmapped | N/A | Coverage |\n|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, cov := range report {
+		b.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %.1f%% |\n",
+			escapePipes(cov.label()),
+			cov.counts[coverageCovered],
+			cov.counts[coveragePartial],
+			cov.counts[coverageNotCovered],
+			cov.counts[coverageUnmapped],
+			cov.counts[coverageNotApplicable],
+			cov.pct(),
+		))
+	}
+	for _, cov := range report {
+		if len(cov.gaps) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n### %s gaps\n\n", escapePipes(cov.label())))
+		b.WriteString("| Requirement | Title | State | Controls |\n|---|---|---|---|\n")
+		listed := cov.gaps
+		if limit > 0 && len(listed) > limit {
+			listed = listed[:limit]
+		}
+		for _, gap := range listed {
+			mapped := strings.Join(gap.controls, ", ")
+			if mapped == "" {
+				mapped = "-"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				escapePipes(gap.item.Code),
+				escapePipes(gap.item.Title),
+				gap.state,
+				escapePipes(mapped),
+			))
+		}
+		if len(cov.gaps) > len(listed) {
+			b.WriteString(fmt.Sprintf("\n_%d more not shown._\n", len(cov.gaps)-len(listed)))
+		}
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+// frameworkCoverage rates every requirement by the best status among its mapped
+// active controls; requirements not covered by an implemented control are gaps.
+func (h *ReportsHandler) frameworkCoverage(ctx context.Context, fw store.ControlFramework, byID map[int64]store.Control) (frameworkCoverage, error) {
+	cov := frameworkCoverage{framework: fw, counts: map[string]int{}}
+	items, err := h.controls.ListFrameworkItems(ctx, fw.ID)
+	if err != nil {
+		return cov, err
+	}
+	mappings, err := h.controls.ListFrameworkMap(ctx, fw.ID)
+	if err != nil {
+		return cov, err
+	}
+	mapped := map[int64][]store.Control{}
+	for _, m := range mappings {
+		if c, ok := byID[m.ControlID]; ok {
+			mapped[m.FrameworkItemID] = append(mapped[m.FrameworkItemID], c)
+		}
+	}
+	for _, item := range items {
+		linked := mapped[item.ID]
+		state := requirementCoverage(linked)
+		cov.counts[state]++
+		if state == coverageCovered || state == coverageNotApplicable {
+			continue
+		}
+		codes := make([]string, 0, len(linked))
+		for _, c := range linked {
+			codes = append(codes, c.Code)
+		}
+		sort.Strings(codes)
+		cov.gaps = append(cov.gaps, frameworkRequirement{item: item, state: state, controls: codes})
+	}
+	return cov, nil
+}
+
+func requirementCoverage(linked []store.Control) string {
+	if len(linked) == 0 {
+		return coverageUnmapped
+	}
+	best := coverageNotApplicable
+	for _, c := range linked {
+		switch strings.ToLower(c.Status) {
+		case controls.StatusImplemented:
+			return coverageCovered
+		case controls.StatusPartial:
+			best = coveragePartial
+		case controls.StatusNotApplicable:
+		default:
+			if best != coveragePartial {
+				best = coverageNotCovered
+			}
+		}
+	}
+	return best
+}
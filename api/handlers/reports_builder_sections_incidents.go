@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"berkut-scc/core/incidents"
 	"berkut-scc/core/store"
 )
 
@@ -18,10 +19,22 @@ func (h *ReportsHandler) buildIncidentsSection(ctx context.Context, sec store.Re
 	}
 	from, to := periodOverride(sec.Config, fallbackFrom, fallbackTo)
 	limit := configInt(sec.Config, "limit", 20)
+	custom, err := h.sectionCustomFields(ctx, sec, store.CustomFieldEntityIncident, roles)
+	if err != nil {
+		res.Error = "custom field filter invalid"
+		return res
+	}
+	clause, err := h.sectionQuery(ctx, sec, store.SearchEntityIncident, user, roles)
+	if err != nil {
+		res.Error = "query invalid"
+		return res
+	}
 	filter := store.IncidentFilter{
-		Status:   configString(sec.Config, "status"),
-		Severity: configString(sec.Config, "severity"),
-		Limit:    limit * 5,
+		Status:       configString(sec.Config, "status"),
+		Severity:     configString(sec.Config, "severity"),
+		CustomFields: custom.conditions,
+		Query:        clause,
+		Limit:        limit * 5,
 	}
 	items, err := h.incidents.ListIncidents(ctx, filter)
 	if err != nil {
@@ -80,11 +93,19 @@ func (h *ReportsHandler) buildIncidentsSection(ctx context.Context, sec store.Re
 		statusCounts[strings.ToLower(inc.Status)]++
 		severityCounts[strings.ToLower(inc.Severity)]++
 	}
+	lifecycle := map[int64]incidents.MetricsRecord{}
+	records, _ := incidents.BuildMetricsRecords(ctx, h.incidents, rows)
+	for _, rec := range records {
+		lifecycle[rec.Incident.ID] = rec
+	}
+	overall := incidents.ComputeMetrics(records, incidents.MetricsGroupSeverity, "").Overall
 	res.ItemCount = len(rows)
 	res.Summary = map[string]any{
 		"incidents":          len(rows),
 		"incidents_critical": severityCounts["critical"],
 		"incidents_high":     severityCounts["high"],
+		"mtta_minutes":       overall.MTTA.Mean,
+		"mttr_minutes":       overall.MTTR.Mean,
 	}
 	totals["incidents"] += len(rows)
 	var b strings.Builder
@@ -96,44 +117,85 @@ func (h *ReportsHandler) buildIncidentsSection(ctx context.Context, sec store.Re
 		}
 		b.WriteString(fmt.Sprintf("- %s: %d\n", strings.Title(key), count))
 	}
+	if overall.MTTA.Count > 0 {
+		b.WriteString(fmt.Sprintf("- MTTA: %s\n", formatMinutes(overall.MTTA.Mean)))
+	}
+	if overall.MTTR.Count > 0 {
+		b.WriteString(fmt.Sprintf("- MTTR: %s\n", formatMinutes(overall.MTTR.Mean)))
+	}
+	if overall.DowntimeMinutes > 0 {
+		b.WriteString(fmt.Sprintf("- Downtime: %s\n", formatMinutes(float64(overall.DowntimeMinutes))))
+	}
 	if len(rows) == 0 {
 		b.WriteString("\n_No incidents for selected period._\n")
 		res.Markdown = b.String()
 		return res
 	}
-	b.WriteString("\n| ID | Title | Severity | Status | Owner | Created |\n|---|---|---|---|---|---|\n")
+	ids := make([]int64, 0, len(rows))
+	for _, inc := range rows {
+		ids = append(ids, inc.ID)
+	}
+	custom.load(ctx, h.fields, ids)
+	b.WriteString("\n| ID | Title | Severity | Status | Owner | Created |" + custom.header() + "\n|---|---|---|---|---|---|" + custom.separator() + "\n")
 	for _, inc := range rows {
 		ownerName := h.cachedUserName(ownerCache, inc.OwnerUserID)
 		idLabel := inc.RegNo
 		if strings.TrimSpace(idLabel) == "" {
 			idLabel = fmt.Sprintf("%d", inc.ID)
 		}
-		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |%s\n",
 			escapePipes(idLabel),
 			escapePipes(inc.Title),
 			escapePipes(inc.Severity),
 			escapePipes(inc.Status),
 			escapePipes(ownerName),
 			inc.CreatedAt.UTC().Format("2006-01-02"),
+			custom.cells(inc.ID),
 		))
+		entity := map[string]any{
+			"id":                   inc.ID,
+			"reg_no":               inc.RegNo,
+			"title":                inc.Title,
+			"severity":             inc.Severity,
+			"status":               inc.Status,
+			"owner_user_id":        inc.OwnerUserID,
+			"assignee_user_id":     inc.AssigneeUserID,
+			"created_at":           inc.CreatedAt.UTC().Format(time.RFC3339),
+			"updated_at":           inc.UpdatedAt.UTC().Format(time.RFC3339),
+			"classification_level": inc.ClassificationLevel,
+			"classification_tags":  inc.ClassificationTags,
+			"incident_type":        inc.Meta.IncidentType,
+			"started_at":           snapshotTime(lifecycle[inc.ID].Lifecycle.StartedAt),
+			"detected_at":          snapshotTime(lifecycle[inc.ID].Lifecycle.DetectedAt),
+			"acknowledged_at":      snapshotTime(lifecycle[inc.ID].Lifecycle.AcknowledgedAt),
+			"contained_at":         snapshotTime(lifecycle[inc.ID].Lifecycle.ContainedAt),
+			"resolved_at":          snapshotTime(lifecycle[inc.ID].Lifecycle.ResolvedAt),
+			"downtime_minutes":     lifecycle[inc.ID].Impact.DowntimeMinutes,
+			"affected_users":       lifecycle[inc.ID].Impact.AffectedUsers,
+			"estimated_cost":       lifecycle[inc.ID].Impact.EstimatedCost,
+			"cost_currency":        lifecycle[inc.ID].Impact.CostCurrency,
+		}
+		custom.snapshot(entity, inc.ID)
 		res.Items = append(res.Items, store.ReportSnapshotItem{
 			EntityType: "incident",
 			EntityID:   fmt.Sprintf("%d", inc.ID),
-			Entity: map[string]any{
-				"id":                   inc.ID,
-				"reg_no":               inc.RegNo,
-				"title":                inc.Title,
-				"severity":             inc.Severity,
-				"status":               inc.Status,
-				"owner_user_id":        inc.OwnerUserID,
-				"assignee_user_id":     inc.AssigneeUserID,
-				"created_at":           inc.CreatedAt.UTC().Format(time.RFC3339),
-				"updated_at":           inc.UpdatedAt.UTC().Format(time.RFC3339),
-				"classification_level": inc.ClassificationLevel,
-				"classification_tags":  inc.ClassificationTags,
-			},
+			Entity:     entity,
 		})
 	}
 	res.Markdown = b.String()
 	return res
 }
+
+func snapshotTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatMinutes(v float64) string {
+	if v < 120 {
+		return fmt.Sprintf("%.0f min", v)
+	}
+	return fmt.Sprintf("%.1f h", v/60)
+}
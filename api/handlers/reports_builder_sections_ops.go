@@ -363,6 +363,24 @@ func (h *ReportsHandler) buildSummarySection(sec store.ReportSection, totals map
 	if v := totals["monitors"]; v > 0 {
 		b.WriteString(fmt.Sprintf("- Monitors tracked: %d\n", v))
 	}
+	if v := totals["eol_expired"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Installations past end of life: %d\n", v))
+	}
+	if v := totals["risks_open"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Open risks: %d\n", v))
+	}
+	if v := totals["risks_acceptance_expired"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Risk acceptances expired: %d\n", v))
+	}
+	if v := totals["findings_open"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Open findings: %d\n", v))
+	}
+	if v := totals["findings_overdue"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Overdue findings: %d\n", v))
+	}
+	if v := totals["effort_minutes"]; v > 0 {
+		b.WriteString(fmt.Sprintf("- Hours logged on tasks: %s\n", formatEffortHours(v)))
+	}
 	if v := totals["audit_events"]; v > 0 {
 		b.WriteString(fmt.Sprintf("- Audit events: %d\n", v))
 	}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// buildRisksSection renders the residual (or inherent, via config "score")
+// heat map followed by the top open risks ordered by score.
+func (h *ReportsHandler) buildRisksSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "risks.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Risk register"))
+		return res
+	}
+	if h.risks == nil {
+		res.Error = "risks unavailable"
+		return res
+	}
+	matrix, err := h.risks.GetRiskMatrix(ctx)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	all, err := listAllRisks(ctx, h.risks)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	inherent := strings.EqualFold(configString(sec.Config, "score"), "inherent")
+	includeClosed := configBool(sec.Config, "include_closed")
+	limit := configInt(sec.Config, "limit", 20)
+	now := time.Now().UTC()
+
+	var items []store.Risk
+	expired := 0
+	for _, it := range all {
+		if it.Status == "closed" && !includeClosed {
+			continue
+		}
+		if it.AcceptanceExpired(now) {
+			expired++
+		}
+		items = append(items, it)
+	}
+	grid := newRiskGrid(matrix)
+	for _, it := range items {
+		l, i := riskAxes(it, inherent)
+		if matrix.InRange(l, i) {
+			grid[l-1][i-1]++
+		}
+	}
+	scoreKind := "residual"
+	if inherent {
+		scoreKind = "inherent"
+	}
+	// The whole grid is snapshotted so that the heat map chart covers every
+	// risk, not only the listed top ones.
+	res.Items = append(res.Items, store.ReportSnapshotItem{
+		EntityType: "risk_matrix",
+		EntityID:   scoreKind,
+		Entity: map[string]any{
+			"score":      scoreKind,
+			"likelihood": matrix.Likelihood,
+			"impact":     matrix.Impact,
+			"counts":     grid,
+		},
+	})
+	open := len(items)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	res.ItemCount = len(items)
+	res.Summary = map[string]any{
+		"risks_open":               open,
+		"risks_acceptance_expired": expired,
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Risk register")))
+	kind := "Residual"
+	if inherent {
+		kind = "Inherent"
+	}
+	b.WriteString(fmt.Sprintf("- Risks: %d\n", open))
+	b.WriteString(fmt.Sprintf("- Expired acceptances: %d\n", expired))
+	b.WriteString(fmt.Sprintf("\n### %s risk heat map\n\n", kind))
+	b.WriteString("| Likelihood \\ Impact |")
+	for _, label := range matrix.Impact {
+		b.WriteString(" " + escapePipes(label) + " |")
+	}
+	b.WriteString("\n|---|" + strings.Repeat("---|", len(matrix.Impact)) + "\n")
+	for l := len(matrix.Likelihood); l >= 1; l-- {
+		b.WriteString("| " + escapePipes(matrix.Likelihood[l-1]) + " |")
+		for i := 1; i <= len(matrix.Impact); i++ {
+			b.WriteString(fmt.Sprintf(" %d (%s) |", grid[l-1][i-1], matrix.Level(l, i).Key))
+		}
+		b.WriteString("\n")
+	}
+	if len(items) == 0 {
+		b.WriteString("\n_No risks._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	cache := map[int64]string{}
+	b.WriteString("\n### Top risks\n\n")
+	b.WriteString("| ID | Title | Owner | Inherent | Residual | Treatment | Status | Acceptance |\n|---|---|---|---|---|---|---|---|\n")
+	for _, it := range items {
+		view := newRiskView(it, matrix, now)
+		owner := "-"
+		if it.OwnerID != nil {
+			owner = h.cachedUserName(cache, *it.OwnerID)
+		}
+		acceptance := "-"
+		if it.AcceptanceExpiresAt != nil {
+			acceptance = "until " + it.AcceptanceExpiresAt.Format("2006-01-02")
+			if view.AcceptanceExpired {
+				acceptance = "expired " + it.AcceptanceExpiresAt.Format("2006-01-02")
+			}
+		}
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %d (%s) | %d (%s) | %s | %s | %s |\n",
+			it.ID,
+			escapePipes(it.Title),
+			escapePipes(owner),
+			view.InherentScore, view.InherentLevel,
+			view.ResidualScore, view.ResidualLevel,
+			it.Treatment,
+			it.Status,
+			acceptance,
+		))
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "risk",
+			EntityID:   fmt.Sprintf("%d", it.ID),
+			Entity: map[string]any{
+				"title":              it.Title,
+				"owner":              owner,
+				"status":             it.Status,
+				"treatment":          it.Treatment,
+				"inherent_score":     view.InherentScore,
+				"inherent_level":     view.InherentLevel,
+				"residual_score":     view.ResidualScore,
+				"residual_level":     view.ResidualLevel,
+				"acceptance_expired": view.AcceptanceExpired,
+			},
+		})
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func riskAxes(r store.Risk, inherent bool) (int, int) {
+	if inherent {
+		return r.InherentLikelihood, r.InherentImpact
+	}
+	return r.ResidualLikelihood, r.ResidualImpact
+}
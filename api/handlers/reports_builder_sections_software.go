@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"berkut-scc/core/eol"
+	"berkut-scc/core/store"
+)
+
+func (h *ReportsHandler) buildSoftwareEOLSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "software.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "EOL exposure"))
+		return res
+	}
+	if h.eol == nil {
+		res.Error = "eol unavailable"
+		return res
+	}
+	horizon := configInt(sec.Config, "horizon_days", h.eol.HorizonDays())
+	limit := configInt(sec.Config, "limit", 50)
+	items, err := h.eol.ListExposure(ctx, time.Now().UTC(), horizon)
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	if configBool(sec.Config, "only_expired") {
+		filtered := items[:0]
+		for _, it := range items {
+			if it.State == store.EOLStateExpired {
+				filtered = append(filtered, it)
+			}
+		}
+		items = filtered
+	}
+	expired := 0
+	for _, it := range items {
+		if it.State == store.EOLStateExpired {
+			expired++
+		}
+	}
+	approaching := len(items) - expired
+	if len(items) > limit && limit > 0 {
+		items = items[:limit]
+	}
+	res.ItemCount = len(items)
+	res.Summary = map[string]any{
+		"eol_expired":     expired,
+		"eol_approaching": approaching,
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "EOL exposure")))
+	b.WriteString(fmt.Sprintf("- Past end of life: %d\n", expired))
+	b.WriteString(fmt.Sprintf("- Reaching end of life within %d days: %d\n", horizon, approaching))
+	if len(items) == 0 {
+		b.WriteString("\n_No installations past or near end of life._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("\n| Asset | Owner | Software | Version | EOL date | State |\n|---|---|---|---|---|---|\n")
+	for _, it := range items {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			escapePipes(it.AssetName),
+			escapePipes(it.AssetOwner),
+			escapePipes(eolProductLabel(it)),
+			escapePipes(it.Version),
+			it.EOLDate.Format("2006-01-02"),
+			it.State,
+		))
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "software_eol",
+			EntityID:   fmt.Sprintf("%d", it.InstallationID),
+			Entity: map[string]any{
+				"asset_id":   it.AssetID,
+				"asset":      it.AssetName,
+				"owner":      it.AssetOwner,
+				"product_id": it.ProductID,
+				"product":    eolProductLabel(it),
+				"version":    it.Version,
+				"eol_date":   it.EOLDate.Format("2006-01-02"),
+				"days_left":  it.DaysLeft,
+				"state":      it.State,
+			},
+		})
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func eolProductLabel(it eol.Exposure) string {
+	if strings.TrimSpace(it.ProductVendor) == "" {
+		return it.ProductName
+	}
+	return it.ProductVendor + " " + it.ProductName
+}
+
+// softwareInventoryRow is a product with its active installations and the number
+// of them past or near end of life.
+type softwareInventoryRow struct {
+	product       store.SoftwareProduct
+	installations int
+	assets        int
+	expired       int
+	approaching   int
+}
+
+func (r softwareInventoryRow) label() string {
+	if strings.TrimSpace(r.product.Vendor) == "" {
+		return r.product.Name
+	}
+	return r.product.Vendor + " " + r.product.Name
+}
+
+func (r softwareInventoryRow) eolState() string {
+	switch {
+	case r.expired > 0:
+		return store.EOLStateExpired
+	case r.approaching > 0:
+		return store.EOLStateApproaching
+	}
+	return "supported"
+}
+
+// buildSoftwareSection lists software products by the number of installations and
+// flags those with installations past or near end of life. Config "vendor" and
+// "tag" narrow the products, "only_eol" keeps the flagged ones.
+func (h *ReportsHandler) buildSoftwareSection(ctx context.Context, sec store.ReportSection, user *store.User, roles []string, totals map[string]int) reportSectionResult {
+	res := reportSectionResult{Section: sec}
+	if !h.policy.Allowed(roles, "software.view") {
+		res.Denied = true
+		res.Markdown = fmt.Sprintf("## %s\n\n_No access._", sectionTitle(sec, "Software inventory"))
+		return res
+	}
+	if h.software == nil {
+		res.Error = "software unavailable"
+		return res
+	}
+	products, err := listAllSoftware(ctx, h.software, store.SoftwareFilter{
+		Vendor: configString(sec.Config, "vendor"),
+		Tag:    configString(sec.Config, "tag"),
+	})
+	if err != nil {
+		res.Error = "load failed"
+		return res
+	}
+	horizon := 0
+	exposure := map[int64][]eol.Exposure{}
+	if h.eol != nil {
+		horizon = configInt(sec.Config, "horizon_days", h.eol.HorizonDays())
+		items, err := h.eol.ListExposure(ctx, time.Now().UTC(), horizon)
+		if err != nil {
+			res.Error = "load failed"
+			return res
+		}
+		for _, it := range items {
+			exposure[it.ProductID] = append(exposure[it.ProductID], it)
+		}
+	}
+	onlyEOL := configBool(sec.Config, "only_eol")
+	var rows []softwareInventoryRow
+	installations := 0
+	for _, p := range products {
+		insts, err := h.software.ListProductAssets(ctx, p.ID, false)
+		if err != nil {
+			res.Error = "load failed"
+			return res
+		}
+		row := softwareInventoryRow{product: p, installations: len(insts)}
+		assets := map[int64]struct{}{}
+		for _, inst := range insts {
+			assets[inst.AssetID] = struct{}{}
+		}
+		row.assets = len(assets)
+		for _, it := range exposure[p.ID] {
+			if it.State == store.EOLStateExpired {
+				row.expired++
+			} else {
+				row.approaching++
+			}
+		}
+		if onlyEOL && row.expired+row.approaching == 0 {
+			continue
+		}
+		installations += row.installations
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].installations > rows[j].installations
+	})
+	flagged := 0
+	for _, row := range rows {
+		if row.expired+row.approaching > 0 {
+			flagged++
+		}
+		entity := map[string]any{
+			"name":          row.product.Name,
+			"vendor":        row.product.Vendor,
+			"installations": row.installations,
+			"assets":        row.assets,
+		}
+		if h.eol != nil {
+			entity["eol_expired"] = row.expired
+			entity["eol_approaching"] = row.approaching
+			entity["eol_state"] = row.eolState()
+		}
+		res.Items = append(res.Items, store.ReportSnapshotItem{
+			EntityType: "software",
+			EntityID:   fmt.Sprintf("%d", row.product.ID),
+			Entity:     entity,
+		})
+	}
+	listed := rows
+	limit := configInt(sec.Config, "limit", 20)
+	if limit > 0 && len(listed) > limit {
+		listed = listed[:limit]
+	}
+	res.ItemCount = len(listed)
+	res.Summary = map[string]any{
+		"software_products":      len(rows),
+		"software_installations": installations,
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitle(sec, "Software inventory")))
+	b.WriteString(fmt.Sprintf("- Products: %d\n", len(rows)))
+	b.WriteString(fmt.Sprintf("- Installations: %d\n", installations))
+	if h.eol != nil {
+		b.WriteString(fmt.Sprintf("- Products past or within %d days of end of life: %d\n", horizon, flagged))
+	}
+	if len(listed) == 0 {
+		b.WriteString("\n_No software for selected filters._\n")
+		res.Markdown = b.String()
+		return res
+	}
+	b.WriteString("\n| Software | Installations | Assets | EOL |\n|---|---:|---:|---|\n")
+	for _, row := range listed {
+		flag := "-"
+		if h.eol != nil {
+			switch row.eolState() {
+			case store.EOLStateExpired:
+				flag = fmt.Sprintf("expired (%d)", row.expired)
+				if row.approaching > 0 {
+					flag += fmt.Sprintf(", approaching (%d)", row.approaching)
+				}
+			case store.EOLStateApproaching:
+				flag = fmt.Sprintf("approaching (%d)", row.approaching)
+			}
+		}
+		b.WriteString(fmt.Sprintf("| %s | %d | %d | %s |\n",
+			escapePipes(row.label()),
+			row.installations,
+			row.assets,
+			flag,
+		))
+	}
+	res.Markdown = b.String()
+	return res
+}
+
+func listAllSoftware(ctx context.Context, ss store.SoftwareStore, filter store.SoftwareFilter) ([]store.SoftwareProduct, error) {
+	const page = 500
+	var out []store.SoftwareProduct
+	filter.Limit = page
+	for offset := 0; ; offset += page {
+		filter.Offset = offset
+		items, err := ss.ListProducts(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
@@ -23,9 +23,21 @@ func (h *ReportsHandler) buildTasksSection(ctx context.Context, sec store.Report
 	}
 	from, to := periodOverride(sec.Config, fallbackFrom, fallbackTo)
 	limit := configInt(sec.Config, "limit", 20)
+	custom, err := h.sectionCustomFields(ctx, sec, store.CustomFieldEntityTask, roles)
+	if err != nil {
+		res.Error = "custom field filter invalid"
+		return res
+	}
+	clause, err := h.sectionQuery(ctx, sec, store.SearchEntityTask, user, roles)
+	if err != nil {
+		res.Error = "query invalid"
+		return res
+	}
 	filter := tasks.TaskFilter{
-		Status: strings.TrimSpace(configString(sec.Config, "status")),
-		Limit:  limit * 5,
+		Status:       strings.TrimSpace(configString(sec.Config, "status")),
+		CustomFields: custom.conditions,
+		Query:        clause,
+		Limit:        limit * 5,
 	}
 	if v := configInt(sec.Config, "board_id", 0); v > 0 {
 		filter.BoardID = int64(v)
@@ -144,7 +156,12 @@ func (h *ReportsHandler) buildTasksSection(ctx context.Context, sec store.Report
 		res.Markdown = b.String()
 		return res
 	}
-	b.WriteString("\n| ID | Title | Status | Assignees | Due |\n|---|---|---|---|---|\n")
+	rowIDs := make([]int64, 0, len(rows))
+	for _, t := range rows {
+		rowIDs = append(rowIDs, t.ID)
+	}
+	custom.load(ctx, h.fields, rowIDs)
+	b.WriteString("\n| ID | Title | Status | Assignees | Due |" + custom.header() + "\n|---|---|---|---|---|" + custom.separator() + "\n")
 	userCache := map[int64]string{}
 	for _, t := range rows {
 		assignees := taskAssignees(assignments[t.ID], userCache, h)
@@ -156,28 +173,31 @@ func (h *ReportsHandler) buildTasksSection(ctx context.Context, sec store.Report
 		if t.ClosedAt != nil {
 			closedAt = t.ClosedAt.UTC().Format(time.RFC3339)
 		}
-		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n",
+		b.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |%s\n",
 			t.ID,
 			escapePipes(t.Title),
 			escapePipes(t.Status),
 			escapePipes(assignees),
 			due,
+			custom.cells(t.ID),
 		))
+		entity := map[string]any{
+			"id":          t.ID,
+			"title":       t.Title,
+			"status":      t.Status,
+			"board_id":    t.BoardID,
+			"assigned_to": assignmentIDs(assignments[t.ID]),
+			"due_date":    due,
+			"created_at":  t.CreatedAt.UTC().Format(time.RFC3339),
+			"updated_at":  t.UpdatedAt.UTC().Format(time.RFC3339),
+			"closed_at":   closedAt,
+			"is_archived": t.IsArchived,
+		}
+		custom.snapshot(entity, t.ID)
 		res.Items = append(res.Items, store.ReportSnapshotItem{
 			EntityType: "task",
 			EntityID:   fmt.Sprintf("%d", t.ID),
-			Entity: map[string]any{
-				"id":          t.ID,
-				"title":       t.Title,
-				"status":      t.Status,
-				"board_id":    t.BoardID,
-				"assigned_to": assignmentIDs(assignments[t.ID]),
-				"due_date":    due,
-				"created_at":  t.CreatedAt.UTC().Format(time.RFC3339),
-				"updated_at":  t.UpdatedAt.UTC().Format(time.RFC3339),
-				"closed_at":   closedAt,
-				"is_archived": t.IsArchived,
-			},
+			Entity:     entity,
 		})
 	}
 	res.Markdown = b.String()
@@ -121,13 +121,17 @@ func (h *ReportsHandler) RenderChart(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, localized(preferredLang(r), "reports.error.badRequest"), http.StatusBadRequest)
 		return
 	}
-	svg, err := charts.RenderSVG(chartData)
+	render, contentType := charts.RenderSVG, "image/svg+xml"
+	if strings.EqualFold(r.URL.Query().Get("format"), "png") {
+		render, contentType = charts.RenderPNG, "image/png"
+	}
+	out, err := render(chartData)
 	if err != nil {
 		http.Error(w, "server error", http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(svg)
+	_, _ = w.Write(out)
 	h.log(r.Context(), user.Username, "report.charts.render", doc.RegNumber)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -245,17 +246,23 @@ func (h *ReportsHandler) saveReport(r *http.Request, user *store.User, doc *stor
 	if len(payload.ACLRoles) > 0 || len(payload.ACLUsers) > 0 {
 		acl = append(acl, buildACLFor(payload.ACLRoles, payload.ACLUsers)...)
 	}
-	docID, err := h.docs.CreateDocument(r.Context(), doc, acl, h.cfg.Docs.RegTemplate, h.cfg.Docs.PerFolderSequence)
+	return h.createReport(r.Context(), user, doc, meta, content, acl)
+}
+
+// createReport stores a new report document with its meta, the default charts and
+// the initial content as the first version.
+func (h *ReportsHandler) createReport(ctx context.Context, user *store.User, doc *store.Document, meta *store.ReportMeta, content []byte, acl []store.ACLRule) error {
+	docID, err := h.docs.CreateDocument(ctx, doc, acl, h.cfg.Docs.RegTemplate, h.cfg.Docs.PerFolderSequence)
 	if err != nil {
 		return err
 	}
 	doc.ID = docID
 	meta.DocID = docID
-	if err := h.reports.UpsertReportMeta(r.Context(), meta); err != nil {
+	if err := h.reports.UpsertReportMeta(ctx, meta); err != nil {
 		return err
 	}
-	_ = h.reports.ReplaceReportCharts(r.Context(), docID, charts.DefaultCharts())
-	_, err = h.svc.SaveVersion(r.Context(), docs.SaveRequest{
+	_ = h.reports.ReplaceReportCharts(ctx, docID, charts.DefaultCharts())
+	_, err = h.svc.SaveVersion(ctx, docs.SaveRequest{
 		Doc:      doc,
 		Author:   user,
 		Format:   docs.FormatMarkdown,
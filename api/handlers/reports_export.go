@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
@@ -18,6 +19,13 @@ import (
 	"berkut-scc/gui"
 )
 
+var (
+	errReportContentMissing    = errors.New("reports.error.contentMissing")
+	errReportChartsUnavailable = errors.New("reports.error.exportChartsUnavailable")
+	errReportSnapshotRequired  = errors.New("reports.error.snapshotRequired")
+	errReportConverterMissing  = errors.New("reports.error.exportConverterMissing")
+)
+
 func (h *ReportsHandler) Export(w http.ResponseWriter, r *http.Request) {
 	doc, meta, user, _, ok := h.loadReportForAccess(w, r, "export")
 	if !ok {
@@ -28,61 +36,73 @@ func (h *ReportsHandler) Export(w http.ResponseWriter, r *http.Request) {
 		format = docs.FormatMarkdown
 	}
 	normalizedFormat := strings.ToLower(strings.TrimPrefix(format, "."))
-	ver, err := h.docs.GetVersion(r.Context(), doc.ID, doc.CurrentVersion)
-	if err != nil || ver == nil {
-		http.Error(w, "not found", http.StatusNotFound)
+	data, ct, withCharts, err := h.renderReport(r.Context(), doc, meta, user.Username, format, preferredLang(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, errReportContentMissing):
+			http.Error(w, "not found", http.StatusNotFound)
+		case errors.Is(err, errReportChartsUnavailable), errors.Is(err, errReportSnapshotRequired), errors.Is(err, errReportConverterMissing):
+			http.Error(w, localized(preferredLang(r), err.Error()), http.StatusBadRequest)
+		default:
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
 		return
 	}
-	content, err := h.svc.LoadContent(r.Context(), ver)
+	filename := fmt.Sprintf("%s.%s", safeFileName(doc.RegNumber), normalizedFormat)
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+	h.log(r.Context(), user.Username, "report.export", doc.RegNumber)
+	if withCharts {
+		h.log(r.Context(), user.Username, "report.export.with_charts", doc.RegNumber)
+	}
+}
+
+// renderReport converts the current version of a report with its header and
+// enabled charts to format, watermarked for username. It also reports whether
+// charts were included.
+func (h *ReportsHandler) renderReport(ctx context.Context, doc *store.Document, meta *store.ReportMeta, username, format, lang string) ([]byte, string, bool, error) {
+	normalizedFormat := strings.ToLower(strings.TrimPrefix(format, "."))
+	ver, err := h.docs.GetVersion(ctx, doc.ID, doc.CurrentVersion)
+	if err != nil || ver == nil {
+		return nil, "", false, errReportContentMissing
+	}
+	content, err := h.svc.LoadContent(ctx, ver)
 	if err != nil {
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
+		return nil, "", false, err
 	}
-	settings, _ := h.reports.GetReportSettings(r.Context())
+	settings, _ := h.reports.GetReportSettings(ctx)
 	header, _ := h.buildReportHeader(settings, doc, meta)
 	md := append(header, content...)
 	md = append(md, '\n', '\n')
-	chartList, _ := h.reports.ListReportCharts(r.Context(), doc.ID)
+	chartList, _ := h.reports.ListReportCharts(ctx, doc.ID)
 	chartList = filterEnabledCharts(chartList)
-	cleanup := func() {}
 	if len(chartList) > 0 {
 		if (normalizedFormat == docs.FormatDocx || normalizedFormat == docs.FormatPDF) && !h.svc.ConvertersStatus().PandocAvailable {
-			http.Error(w, localized(preferredLang(r), "reports.error.exportChartsUnavailable"), http.StatusBadRequest)
-			return
+			return nil, "", false, errReportChartsUnavailable
 		}
-		snaps, _ := h.reports.ListReportSnapshots(r.Context(), doc.ID)
+		snaps, _ := h.reports.ListReportSnapshots(ctx, doc.ID)
 		if len(snaps) == 0 {
-			http.Error(w, localized(preferredLang(r), "reports.error.snapshotRequired"), http.StatusBadRequest)
-			return
+			return nil, "", false, errReportSnapshotRequired
 		}
-		snapshot, items, err := h.reports.GetReportSnapshot(r.Context(), snaps[0].ID)
+		snapshot, items, err := h.reports.GetReportSnapshot(ctx, snaps[0].ID)
 		if err != nil || snapshot == nil {
-			http.Error(w, "server error", http.StatusInternalServerError)
-			return
+			return nil, "", false, errors.New("snapshot not found")
 		}
-		var errAppend error
-		md, cleanup, errAppend = h.appendChartsToMarkdown(r.Context(), md, chartList, snapshot, items, preferredLang(r), normalizedFormat)
-		if errAppend != nil {
-			http.Error(w, "server error", http.StatusInternalServerError)
-			return
+		var cleanup func()
+		md, cleanup, err = h.appendChartsToMarkdown(ctx, md, chartList, snapshot, items, lang, normalizedFormat)
+		if err != nil {
+			return nil, "", false, err
 		}
 		defer cleanup()
 	}
-	wm, _ := h.svc.WatermarkFor(doc, user.Username)
-	data, ct, err := h.svc.ConvertMarkdown(r.Context(), format, md, wm)
+	wm, _ := h.svc.WatermarkFor(doc, username)
+	data, ct, err := h.svc.ConvertMarkdown(ctx, format, md, wm)
 	if err != nil {
-		http.Error(w, localized(preferredLang(r), "reports.error.exportConverterMissing"), http.StatusBadRequest)
-		return
-	}
-	filename := fmt.Sprintf("%s.%s", safeFileName(doc.RegNumber), strings.ToLower(strings.TrimPrefix(format, ".")))
-	w.Header().Set("Content-Type", ct)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(data)
-	h.log(r.Context(), user.Username, "report.export", doc.RegNumber)
-	if len(chartList) > 0 {
-		h.log(r.Context(), user.Username, "report.export.with_charts", doc.RegNumber)
+		return nil, "", false, errReportConverterMissing
 	}
+	return data, ct, len(chartList) > 0, nil
 }
 
 func filterEnabledCharts(items []store.ReportChart) []store.ReportChart {
@@ -123,19 +143,24 @@ func (h *ReportsHandler) appendChartsToMarkdown(ctx context.Context, base []byte
 		if err != nil {
 			continue
 		}
-		svg, err := charts.RenderSVG(data)
-		if err != nil {
-			continue
-		}
 		label := ch.Title
 		path := ""
 		if useTemp {
-			filename := fmt.Sprintf("chart_%d.svg", i+1)
+			// DOCX and PDF converters cannot embed SVG, so they get a raster copy.
+			img, err := charts.RenderPNG(data)
+			if err != nil {
+				continue
+			}
+			filename := fmt.Sprintf("chart_%d.png", i+1)
 			path = filepath.Join(tempDir, filename)
-			if err := os.WriteFile(path, svg, 0o600); err != nil {
+			if err := os.WriteFile(path, img, 0o600); err != nil {
 				continue
 			}
 		} else {
+			svg, err := charts.RenderSVG(data)
+			if err != nil {
+				continue
+			}
 			path = "data:image/svg+xml;utf8," + url.QueryEscape(string(svg))
 		}
 		b.WriteString(fmt.Sprintf("![%s](%s)\n\n", label, path))
@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"berkut-scc/config"
 	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/eol"
 	"berkut-scc/core/incidents"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
@@ -31,11 +35,19 @@ type ReportsHandler struct {
 	controls     store.ControlsStore
 	monitoring   store.MonitoringStore
 	tasksSvc     *tasks.Service
+	eol          *eol.Service
+	risks        store.RisksStore
+	findings     store.FindingsStore
+	assets       store.AssetsStore
+	software     store.SoftwareStore
 	audits       store.AuditStore
 	logger       *utils.Logger
+	fields       *customfields.Service
+	queries      *query.Service
+	scheduleMu   sync.Mutex
 }
 
-func NewReportsHandler(cfg *config.AppConfig, ds store.DocsStore, rs store.ReportsStore, us store.UsersStore, policy *rbac.Policy, svc *docs.Service, incidents store.IncidentsStore, incidentsSvc *incidents.Service, controls store.ControlsStore, monitoring store.MonitoringStore, tasksSvc *tasks.Service, audits store.AuditStore, logger *utils.Logger) *ReportsHandler {
+func NewReportsHandler(cfg *config.AppConfig, ds store.DocsStore, rs store.ReportsStore, us store.UsersStore, policy *rbac.Policy, svc *docs.Service, incidents store.IncidentsStore, incidentsSvc *incidents.Service, controls store.ControlsStore, monitoring store.MonitoringStore, tasksSvc *tasks.Service, eolSvc *eol.Service, risks store.RisksStore, findings store.FindingsStore, audits store.AuditStore, logger *utils.Logger) *ReportsHandler {
 	return &ReportsHandler{
 		cfg:          cfg,
 		docs:         ds,
@@ -48,6 +60,9 @@ func NewReportsHandler(cfg *config.AppConfig, ds store.DocsStore, rs store.Repor
 		controls:     controls,
 		monitoring:   monitoring,
 		tasksSvc:     tasksSvc,
+		eol:          eolSvc,
+		risks:        risks,
+		findings:     findings,
 		audits:       audits,
 		logger:       logger,
 	}
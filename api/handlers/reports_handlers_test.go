@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -76,3 +78,28 @@ func TestAppendChartsToMarkdownEmbedsImages(t *testing.T) {
 		t.Fatalf("expected embedded svg in markdown")
 	}
 }
+
+func TestAppendChartsToMarkdownUsesPNGForDocx(t *testing.T) {
+	h := &ReportsHandler{}
+	chartList := []store.ReportChart{
+		{ChartType: "controls_status_donut", Title: "Controls", IsEnabled: true},
+	}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "control", Entity: map[string]any{"status": "implemented"}},
+	}
+	out, cleanup, err := h.appendChartsToMarkdown(context.Background(), []byte("base"), chartList, &store.ReportSnapshot{}, items, "en", docs.FormatDocx)
+	if err != nil {
+		t.Fatalf("append charts: %v", err)
+	}
+	defer cleanup()
+	text := string(out)
+	start := strings.Index(text, "](")
+	end := strings.LastIndex(text, ")")
+	if start < 0 || end <= start || !strings.HasSuffix(text[start+2:end], ".png") {
+		t.Fatalf("expected png chart file in markdown: %s", text)
+	}
+	data, err := os.ReadFile(text[start+2 : end])
+	if err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("expected png file: %v", err)
+	}
+}
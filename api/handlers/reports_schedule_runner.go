@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/docs"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+// Run triggers recorded in the schedule history.
+const (
+	reportRunScheduled = "schedule"
+	reportRunManual    = "manual"
+)
+
+var errReportScheduleOwner = errors.New("reports.schedules.error.owner")
+
+// runReportSchedule builds the report of a schedule for its rolling period as the
+// schedule owner, renders it into the output document and notifies the recipients.
+// The outcome is recorded as a run and in the schedule state; runs are serialized.
+func (h *ReportsHandler) runReportSchedule(ctx context.Context, sch *store.ReportSchedule, trigger, triggeredBy string, scheduledFor *time.Time, now time.Time) *store.ReportScheduleRun {
+	h.scheduleMu.Lock()
+	defer h.scheduleMu.Unlock()
+	run := &store.ReportScheduleRun{
+		ScheduleID:   sch.ID,
+		Trigger:      trigger,
+		TriggeredBy:  triggeredBy,
+		ScheduledFor: scheduledFor,
+		StartedAt:    now,
+	}
+	run.Status = "success"
+	if err := h.executeReportSchedule(ctx, sch, run); err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+		if h.logger != nil {
+			h.logger.Errorf("report schedule %d: %v", sch.ID, err)
+		}
+	}
+	run.FinishedAt = time.Now().UTC()
+	sch.LastRunAt = &now
+	sch.LastStatus = run.Status
+	if _, err := h.reports.CreateReportScheduleRun(ctx, run); err != nil && h.logger != nil {
+		h.logger.Errorf("report schedule %d run: %v", sch.ID, err)
+	}
+	if err := h.reports.UpdateReportScheduleState(ctx, sch); err != nil && h.logger != nil {
+		h.logger.Errorf("report schedule %d state: %v", sch.ID, err)
+	}
+	h.log(ctx, triggeredBy, "report.schedule.run", fmt.Sprintf("%d|%s|%s", sch.ID, trigger, run.Status))
+	return run
+}
+
+func (h *ReportsHandler) executeReportSchedule(ctx context.Context, sch *store.ReportSchedule, run *store.ReportScheduleRun) error {
+	owner, roles, err := h.users.Get(ctx, sch.OwnerID)
+	if err != nil || owner == nil || !owner.Active {
+		return errReportScheduleOwner
+	}
+	groups, _ := h.users.UserGroups(ctx, owner.ID)
+	eff := auth.CalculateEffectiveAccess(owner, roles, groups, h.policy)
+	owner.ClearanceLevel = eff.ClearanceLevel
+	owner.ClearanceTags = eff.ClearanceTags
+	roles = eff.Roles
+
+	loc, err := time.LoadLocation(sch.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	ref := run.StartedAt
+	if run.ScheduledFor != nil {
+		ref = *run.ScheduledFor
+	}
+	from, to := reportSchedulePeriod(sch.Period, ref.In(loc))
+	run.PeriodFrom, run.PeriodTo = &from, &to
+
+	doc, meta, err := h.scheduledReport(ctx, sch, owner, roles)
+	if err != nil {
+		return err
+	}
+	run.ReportID = &doc.ID
+	meta.PeriodFrom, meta.PeriodTo = &from, &to
+	if err := h.reports.UpsertReportMeta(ctx, meta); err != nil {
+		return err
+	}
+	_, snapshot, err := h.buildReport(ctx, doc, meta, owner, roles, groups, eff, "markers", "Scheduled run: "+sch.Name)
+	if err != nil {
+		return err
+	}
+	run.SnapshotID = &snapshot.ID
+	lang := sch.Language
+	if lang == "" {
+		lang = "en"
+	}
+	data, _, _, err := h.renderReport(ctx, doc, meta, owner.Username, sch.Format, lang)
+	if err != nil {
+		return err
+	}
+
+	recipients := h.scheduleRecipients(ctx, sch)
+	out, err := h.scheduleOutputDoc(ctx, sch, doc, owner, recipients)
+	if err != nil {
+		return err
+	}
+	v, err := h.svc.SaveVersion(ctx, docs.SaveRequest{
+		Doc:     out,
+		Author:  owner,
+		Format:  sch.Format,
+		Content: data,
+		Reason:  fmt.Sprintf("Scheduled run: %s (%s - %s)", sch.Name, from.Format("2006-01-02"), to.Format("2006-01-02")),
+	})
+	if err != nil {
+		return err
+	}
+	out.CurrentVersion = v.Version
+	_ = h.docs.UpdateDocument(ctx, out)
+	sch.OutputDocID = &out.ID
+	run.OutputDocID = &out.ID
+	run.OutputVersion = v.Version
+	run.Delivered = h.notifyScheduleRecipients(ctx, sch, out, owner, recipients)
+	return nil
+}
+
+// scheduledReport returns the report a schedule builds. Template schedules create
+// the report on their first run and keep using it afterwards.
+func (h *ReportsHandler) scheduledReport(ctx context.Context, sch *store.ReportSchedule, owner *store.User, roles []string) (*store.Document, *store.ReportMeta, error) {
+	if sch.ReportID != nil {
+		doc, err := h.docs.GetDocument(ctx, *sch.ReportID)
+		if err != nil || doc == nil || !h.isReport(doc) || doc.DeletedAt != nil {
+			return nil, nil, errReportScheduleReport
+		}
+		docACL, _ := h.docs.GetDocACL(ctx, doc.ID)
+		if !h.svc.CheckACL(owner, roles, doc, docACL, nil, "edit") {
+			return nil, nil, errReportScheduleReport
+		}
+		meta, _ := h.reports.GetReportMeta(ctx, doc.ID)
+		if meta == nil {
+			meta = &store.ReportMeta{DocID: doc.ID, Status: "draft"}
+		}
+		return doc, meta, nil
+	}
+	if sch.TemplateID == nil {
+		return nil, nil, errReportScheduleTarget
+	}
+	tpl, err := h.reports.GetReportTemplate(ctx, *sch.TemplateID)
+	if err != nil || tpl == nil {
+		return nil, nil, errReportScheduleTemplate
+	}
+	settings, _ := h.reports.GetReportSettings(ctx)
+	level := docs.ClassificationInternal
+	if settings != nil {
+		if parsed, err := docs.ParseLevel(settings.DefaultClassification); err == nil {
+			level = parsed
+		}
+	}
+	if !hasPrivRole(roles) && !docs.HasClearance(docs.ClassificationLevel(owner.ClearanceLevel), owner.ClearanceTags, level, nil) {
+		return nil, nil, errReportScheduleForbidden
+	}
+	doc := &store.Document{
+		Title:                 sch.Name,
+		Status:                docs.StatusDraft,
+		ClassificationLevel:   int(level),
+		DocType:               "report",
+		InheritClassification: true,
+		CreatedBy:             owner.ID,
+	}
+	meta := &store.ReportMeta{Status: "draft", TemplateID: sch.TemplateID}
+	content := applyTemplate(tpl.TemplateMarkdown, h.templateVars(doc.Title, meta, settings))
+	if err := h.createReport(ctx, owner, doc, meta, []byte(content), buildBaseACLFor(owner)); err != nil {
+		return nil, nil, err
+	}
+	h.log(ctx, owner.Username, "report.template.use", fmt.Sprintf("%d", tpl.ID))
+	sch.ReportID = &doc.ID
+	return doc, meta, nil
+}
+
+// scheduleOutputDoc returns the document that receives the rendered versions of a
+// schedule and grants the recipients view and export access to it. Access the
+// schedule granted to users who are no longer recipients is revoked; rules added
+// by hand are kept. The granted rules are recorded in sch.GrantedACL.
+func (h *ReportsHandler) scheduleOutputDoc(ctx context.Context, sch *store.ReportSchedule, report *store.Document, owner *store.User, recipients []store.User) (*store.Document, error) {
+	var wanted []store.ACLRule
+	for _, u := range recipients {
+		if u.ID == owner.ID {
+			continue
+		}
+		for _, p := range []string{"view", "export"} {
+			wanted = append(wanted, store.ACLRule{SubjectType: "user", SubjectID: u.Username, Permission: p})
+		}
+	}
+	if sch.OutputDocID != nil {
+		doc, err := h.docs.GetDocument(ctx, *sch.OutputDocID)
+		if err == nil && doc != nil && doc.DeletedAt == nil {
+			current, err := h.docs.GetDocACL(ctx, doc.ID)
+			if err != nil {
+				return nil, err
+			}
+			// Rules the schedule granted to former recipients are revoked; rules added
+			// by hand are kept, including those that match a current recipient.
+			merged := make([]store.ACLRule, 0, len(current)+len(wanted))
+			changed := false
+			for _, rule := range current {
+				if containsACLRule(sch.GrantedACL, rule) && !containsACLRule(wanted, rule) {
+					changed = true
+					continue
+				}
+				merged = append(merged, rule)
+			}
+			var granted []store.ACLRule
+			for _, rule := range append(buildBaseACLFor(owner), wanted...) {
+				isRecipient := containsACLRule(wanted, rule)
+				if containsACLRule(merged, rule) {
+					if isRecipient && containsACLRule(sch.GrantedACL, rule) {
+						granted = append(granted, rule)
+					}
+					continue
+				}
+				merged = append(merged, rule)
+				changed = true
+				if isRecipient {
+					granted = append(granted, rule)
+				}
+			}
+			if changed {
+				if err := h.docs.SetDocACL(ctx, doc.ID, merged); err != nil {
+					return nil, err
+				}
+			}
+			sch.GrantedACL = granted
+			return doc, nil
+		}
+	}
+	doc := &store.Document{
+		Title:                 sch.Name,
+		Status:                docs.StatusDraft,
+		FolderID:              report.FolderID,
+		ClassificationLevel:   report.ClassificationLevel,
+		ClassificationTags:    report.ClassificationTags,
+		DocType:               "document",
+		InheritClassification: true,
+		CreatedBy:             owner.ID,
+	}
+	acl := append(buildBaseACLFor(owner), wanted...)
+	id, err := h.docs.CreateDocument(ctx, doc, acl, h.cfg.Docs.RegTemplate, h.cfg.Docs.PerFolderSequence)
+	if err != nil {
+		return nil, err
+	}
+	doc.ID = id
+	sch.GrantedACL = wanted
+	return doc, nil
+}
+
+func containsACLRule(acl []store.ACLRule, rule store.ACLRule) bool {
+	for _, r := range acl {
+		if strings.EqualFold(r.SubjectType, rule.SubjectType) && r.SubjectID == rule.SubjectID && strings.EqualFold(r.Permission, rule.Permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleRecipients resolves the recipient users and groups of a schedule to the
+// distinct active users.
+func (h *ReportsHandler) scheduleRecipients(ctx context.Context, sch *store.ReportSchedule) []store.User {
+	var out []store.User
+	seen := map[int64]bool{}
+	add := func(u *store.User) {
+		if u == nil || !u.Active || seen[u.ID] {
+			return
+		}
+		seen[u.ID] = true
+		out = append(out, *u)
+	}
+	for _, id := range sch.RecipientUsers {
+		u, _, err := h.users.Get(ctx, id)
+		if err == nil {
+			add(u)
+		}
+	}
+	for _, gid := range sch.RecipientGroups {
+		members, err := h.users.ListFiltered(ctx, store.UserFilter{GroupID: gid})
+		if err != nil {
+			continue
+		}
+		for i := range members {
+			add(&members[i].User)
+		}
+	}
+	return out
+}
+
+// notifyScheduleRecipients notifies the recipients that can open the output
+// document and returns how many were notified.
+func (h *ReportsHandler) notifyScheduleRecipients(ctx context.Context, sch *store.ReportSchedule, out *store.Document, owner *store.User, recipients []store.User) int {
+	notifier := h.svc.Notifier()
+	if notifier == nil || len(recipients) == 0 {
+		return 0
+	}
+	docACL, _ := h.docs.GetDocACL(ctx, out.ID)
+	var ids []int64
+	for i := range recipients {
+		u := recipients[i]
+		_, roles, err := h.users.Get(ctx, u.ID)
+		if err != nil {
+			continue
+		}
+		groups, _ := h.users.UserGroups(ctx, u.ID)
+		eff := auth.CalculateEffectiveAccess(&u, roles, groups, h.policy)
+		u.ClearanceLevel = eff.ClearanceLevel
+		u.ClearanceTags = eff.ClearanceTags
+		if h.svc.CheckACL(&u, eff.Roles, out, docACL, nil, "view") {
+			ids = append(ids, u.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+	return notifier.Notify(ctx, store.Notification{
+		EventType:  notify.EventReportDelivered,
+		Title:      "Scheduled report: " + sch.Name,
+		Body:       fmt.Sprintf("Version %d is available.", out.CurrentVersion),
+		TitleKey:   "notifications.report.delivered",
+		BodyKey:    "notifications.report.version",
+		Params:     map[string]string{"name": sch.Name, "version": strconv.Itoa(out.CurrentVersion)},
+		EntityType: "doc",
+		EntityID:   strconv.FormatInt(out.ID, 10),
+		Link:       fmt.Sprintf("/docs/%d", out.ID),
+		ActorID:    &owner.ID,
+	}, ids...)
+}
+
+// ReportScheduler runs the report schedules that are due.
+type ReportScheduler struct {
+	cfg     config.SchedulerConfig
+	reports *ReportsHandler
+	logger  *utils.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewReportScheduler(cfg config.SchedulerConfig, reports *ReportsHandler, logger *utils.Logger) *ReportScheduler {
+	return &ReportScheduler{cfg: cfg, reports: reports, logger: logger}
+}
+
+func (s *ReportScheduler) StartWithContext(ctx context.Context) {
+	if s == nil || s.reports == nil || !s.cfg.Enabled {
+		return
+	}
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.running = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	interval := time.Duration(s.cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer s.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.RunOnce(runCtx, time.Now().UTC()); err != nil && s.logger != nil {
+					s.logger.Errorf("scheduler reports.schedules: %v", err)
+				}
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *ReportScheduler) StopWithContext(ctx context.Context) error {
+	if s == nil || !s.cfg.Enabled {
+		return nil
+	}
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	wasRunning := s.running
+	s.mu.Unlock()
+	if !wasRunning || cancel == nil {
+		return nil
+	}
+	cancel()
+	waitDone := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce runs the schedules due at now. The next run is planned after now first,
+// so occurrences missed while the service was down are skipped rather than replayed.
+func (s *ReportScheduler) RunOnce(ctx context.Context, now time.Time) error {
+	if s == nil || s.reports == nil {
+		return nil
+	}
+	limit := s.cfg.MaxJobsPerTick
+	if limit <= 0 {
+		limit = 20
+	}
+	due, err := s.reports.reports.ListDueReportSchedules(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		sch := &due[i]
+		scheduledFor := sch.NextRunAt
+		sch.NextRunAt = nextReportScheduleRun(sch, now)
+		s.reports.runReportSchedule(ctx, sch, reportRunScheduled, "scheduler", scheduledFor, now)
+	}
+	return nil
+}
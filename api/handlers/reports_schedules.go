@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/docs"
+	"berkut-scc/core/schedule"
+	"berkut-scc/core/store"
+)
+
+// Rolling periods a scheduled report covers, relative to the run date.
+const (
+	reportPeriodLastWeek    = "last_week"
+	reportPeriodLastMonth   = "last_month"
+	reportPeriodLastQuarter = "last_quarter"
+)
+
+const (
+	maxReportScheduleName       = 200
+	maxReportScheduleRecipients = 200
+)
+
+var (
+	errReportScheduleName       = errors.New("reports.schedules.error.name")
+	errReportScheduleTarget     = errors.New("reports.schedules.error.target")
+	errReportSchedulePeriod     = errors.New("reports.schedules.error.period")
+	errReportScheduleRecurrence = errors.New("reports.schedules.error.recurrence")
+	errReportScheduleTime       = errors.New("reports.schedules.error.time")
+	errReportScheduleFormat     = errors.New("reports.schedules.error.format")
+	errReportScheduleRecipients = errors.New("reports.schedules.error.recipients")
+	errReportScheduleReport     = errors.New("reports.schedules.error.reportNotFound")
+	errReportScheduleTemplate   = errors.New("reports.schedules.error.templateNotFound")
+	errReportScheduleForbidden  = errors.New("reports.error.forbidden")
+)
+
+type reportSchedulePayload struct {
+	Name            string  `json:"name"`
+	ReportID        *int64  `json:"report_id"`
+	TemplateID      *int64  `json:"template_id"`
+	Period          string  `json:"period"`
+	Cron            string  `json:"cron"`
+	RRule           string  `json:"rrule"`
+	TimeOfDay       string  `json:"time_of_day"`
+	Timezone        string  `json:"timezone"`
+	Format          string  `json:"format"`
+	RecipientUsers  []int64 `json:"recipient_users"`
+	RecipientGroups []int64 `json:"recipient_groups"`
+	Enabled         *bool   `json:"enabled"`
+}
+
+func (h *ReportsHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.reports.ListReportSchedules(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	out := []store.ReportSchedule{}
+	for _, sch := range items {
+		if sch.OwnerID == user.ID || hasPrivRole(roles) {
+			out = append(out, sch)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+func (h *ReportsHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload reportSchedulePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, localized(preferredLang(r), "reports.error.badRequest"), http.StatusBadRequest)
+		return
+	}
+	// Scheduled runs have no request to take the language from, so the one of
+	// the creator is kept for rendering.
+	sch := &store.ReportSchedule{OwnerID: user.ID, Enabled: true, Language: preferredLang(r)}
+	if err := h.applySchedulePayload(r, user, roles, sch, payload); err != nil {
+		h.scheduleError(w, r, err)
+		return
+	}
+	if _, err := h.reports.CreateReportSchedule(r.Context(), sch); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.log(r.Context(), user.Username, "report.schedule.create", fmt.Sprintf("%d|%s", sch.ID, sch.Name))
+	writeJSON(w, http.StatusCreated, sch)
+}
+
+func (h *ReportsHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, user, roles, ok := h.loadScheduleForAccess(w, r)
+	if !ok {
+		return
+	}
+	var payload reportSchedulePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, localized(preferredLang(r), "reports.error.badRequest"), http.StatusBadRequest)
+		return
+	}
+	if err := h.applySchedulePayload(r, user, roles, sch, payload); err != nil {
+		h.scheduleError(w, r, err)
+		return
+	}
+	if err := h.reports.UpdateReportSchedule(r.Context(), sch); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.log(r.Context(), user.Username, "report.schedule.update", fmt.Sprintf("%d|%s", sch.ID, sch.Name))
+	writeJSON(w, http.StatusOK, sch)
+}
+
+func (h *ReportsHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, user, _, ok := h.loadScheduleForAccess(w, r)
+	if !ok {
+		return
+	}
+	if err := h.reports.DeleteReportSchedule(r.Context(), sch.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.log(r.Context(), user.Username, "report.schedule.delete", fmt.Sprintf("%d|%s", sch.ID, sch.Name))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// RunSchedule runs a schedule right away; the planned next run is kept.
+func (h *ReportsHandler) RunSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, user, _, ok := h.loadScheduleForAccess(w, r)
+	if !ok {
+		return
+	}
+	run := h.runReportSchedule(r.Context(), sch, reportRunManual, user.Username, nil, time.Now().UTC())
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (h *ReportsHandler) ListScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	sch, _, _, ok := h.loadScheduleForAccess(w, r)
+	if !ok {
+		return
+	}
+	runs, err := h.reports.ListReportScheduleRuns(r.Context(), sch.ID, parseIntDefault(r.URL.Query().Get("limit"), 50))
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		runs = []store.ReportScheduleRun{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": runs})
+}
+
+// loadScheduleForAccess returns the schedule in the URL when the user owns it or is
+// an administrator.
+func (h *ReportsHandler) loadScheduleForAccess(w http.ResponseWriter, r *http.Request) (*store.ReportSchedule, *store.User, []string, bool) {
+	user, roles, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, nil, nil, false
+	}
+	id, _ := strconv.ParseInt(pathParams(r)["id"], 10, 64)
+	sch, err := h.reports.GetReportSchedule(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if sch == nil || (sch.OwnerID != user.ID && !hasPrivRole(roles)) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+	return sch, user, roles, true
+}
+
+// applySchedulePayload validates the payload into sch and plans its next run.
+func (h *ReportsHandler) applySchedulePayload(r *http.Request, user *store.User, roles []string, sch *store.ReportSchedule, payload reportSchedulePayload) error {
+	sch.Name = strings.TrimSpace(payload.Name)
+	if sch.Name == "" || len([]rune(sch.Name)) > maxReportScheduleName {
+		return errReportScheduleName
+	}
+	hasReport := payload.ReportID != nil && *payload.ReportID > 0
+	hasTemplate := payload.TemplateID != nil && *payload.TemplateID > 0
+	prevReport, prevTemplate := sch.ReportID, sch.TemplateID
+	if hasReport && hasTemplate && prevTemplate != nil && *prevTemplate == *payload.TemplateID {
+		// A template schedule echoes back the report it produced.
+		hasReport = false
+	}
+	if hasReport == hasTemplate {
+		return errReportScheduleTarget
+	}
+	sch.ReportID, sch.TemplateID = nil, nil
+	if hasReport {
+		doc, err := h.docs.GetDocument(r.Context(), *payload.ReportID)
+		if err != nil || doc == nil || !h.isReport(doc) || doc.DeletedAt != nil {
+			return errReportScheduleReport
+		}
+		docACL, _ := h.docs.GetDocACL(r.Context(), doc.ID)
+		if !h.svc.CheckACL(user, roles, doc, docACL, nil, "edit") {
+			return errReportScheduleReport
+		}
+		sch.ReportID = &doc.ID
+	} else {
+		tpl, err := h.reports.GetReportTemplate(r.Context(), *payload.TemplateID)
+		if err != nil || tpl == nil {
+			return errReportScheduleTemplate
+		}
+		if h.policy != nil && !h.policy.Allowed(roles, "reports.create") {
+			return errReportScheduleForbidden
+		}
+		sch.TemplateID = &tpl.ID
+		// Keep the report the template produced on earlier runs.
+		if prevTemplate != nil && *prevTemplate == tpl.ID {
+			sch.ReportID = prevReport
+		}
+	}
+	sch.Period = strings.ToLower(strings.TrimSpace(payload.Period))
+	switch sch.Period {
+	case reportPeriodLastWeek, reportPeriodLastMonth, reportPeriodLastQuarter:
+	default:
+		return errReportSchedulePeriod
+	}
+	sch.Cron = strings.Join(strings.Fields(payload.Cron), " ")
+	sch.RRule = strings.TrimSpace(payload.RRule)
+	switch {
+	case sch.Cron != "" && sch.RRule != "":
+		return errReportScheduleRecurrence
+	case sch.Cron != "":
+		rrule, err := schedule.CronToRRule(sch.Cron)
+		if err != nil {
+			return err
+		}
+		sch.RRule = rrule
+	case sch.RRule == "":
+		return errReportScheduleRecurrence
+	}
+	sch.TimeOfDay = strings.TrimSpace(payload.TimeOfDay)
+	if sch.TimeOfDay != "" {
+		if _, err := time.Parse("15:04", sch.TimeOfDay); err != nil {
+			return errReportScheduleTime
+		}
+	}
+	sch.Timezone = strings.TrimSpace(payload.Timezone)
+	spec, err := reportScheduleSpec(sch).Normalize()
+	if err != nil {
+		return err
+	}
+	sch.RRule = spec.RRule
+	sch.Format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(payload.Format), "."))
+	if sch.Format == "" {
+		sch.Format = docs.FormatPDF
+	}
+	switch sch.Format {
+	case docs.FormatPDF, docs.FormatDocx, docs.FormatMarkdown:
+	default:
+		return errReportScheduleFormat
+	}
+	users, okUsers := uniquePositiveIDs(payload.RecipientUsers)
+	groups, okGroups := uniquePositiveIDs(payload.RecipientGroups)
+	if !okUsers || !okGroups || len(users)+len(groups) > maxReportScheduleRecipients {
+		return errReportScheduleRecipients
+	}
+	for _, id := range users {
+		if u, _, err := h.users.Get(r.Context(), id); err != nil || u == nil {
+			return errReportScheduleRecipients
+		}
+	}
+	sch.RecipientUsers, sch.RecipientGroups = users, groups
+	if payload.Enabled != nil {
+		sch.Enabled = *payload.Enabled
+	}
+	sch.NextRunAt = nil
+	if sch.Enabled {
+		sch.NextRunAt = nextReportScheduleRun(sch, time.Now().UTC())
+	}
+	return nil
+}
+
+func (h *ReportsHandler) scheduleError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, errReportScheduleReport) || errors.Is(err, errReportScheduleTemplate) {
+		status = http.StatusNotFound
+	}
+	if errors.Is(err, errReportScheduleForbidden) {
+		status = http.StatusForbidden
+	}
+	http.Error(w, localized(preferredLang(r), err.Error()), status)
+}
+
+// reportScheduleSpec is the recurrence of a schedule in the shared schedule form; the
+// time of day anchors rules that do not set BYHOUR and BYMINUTE.
+func reportScheduleSpec(sch *store.ReportSchedule) schedule.Spec {
+	start := "2000-01-01"
+	if sch.TimeOfDay != "" {
+		start += "T" + sch.TimeOfDay
+	}
+	return schedule.Spec{Start: start, RRule: sch.RRule, Timezone: sch.Timezone}
+}
+
+// nextReportScheduleRun returns the first run after ref in UTC, or nil when the rule
+// is invalid or exhausted.
+func nextReportScheduleRun(sch *store.ReportSchedule, ref time.Time) *time.Time {
+	rule, err := reportScheduleSpec(sch).Compile(nil)
+	if err != nil {
+		return nil
+	}
+	next, ok := rule.Next(ref)
+	if !ok {
+		return nil
+	}
+	next = next.UTC()
+	return &next
+}
+
+// reportSchedulePeriod returns the first and last day of the rolling period that
+// precedes the day of ref.
+func reportSchedulePeriod(period string, ref time.Time) (time.Time, time.Time) {
+	day := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC)
+	var start time.Time
+	switch period {
+	case reportPeriodLastWeek:
+		start = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start.AddDate(0, 0, -7), start.AddDate(0, 0, -1)
+	case reportPeriodLastQuarter:
+		start = time.Date(day.Year(), ((day.Month()-1)/3)*3+1, 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(0, -3, 0), start.AddDate(0, 0, -1)
+	default:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(0, -1, 0), start.AddDate(0, 0, -1)
+	}
+}
+
+func uniquePositiveIDs(ids []int64) ([]int64, bool) {
+	out := []int64{}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, true
+}
@@ -9,15 +9,23 @@ import (
 )
 
 var reportSectionTypes = map[string]struct{}{
-	"summary":     {},
-	"incidents":   {},
-	"tasks":       {},
-	"docs":        {},
-	"controls":    {},
-	"monitoring":  {},
-	"sla_summary": {},
-	"audit":       {},
-	"custom_md":   {},
+	"summary":            {},
+	"incidents":          {},
+	"tasks":              {},
+	"docs":               {},
+	"controls":           {},
+	"monitoring":         {},
+	"sla_summary":        {},
+	"software_eol":       {},
+	"risks":              {},
+	"findings":           {},
+	"assets":             {},
+	"software":           {},
+	"framework_coverage": {},
+	"effort":             {},
+	"task_flow":          {},
+	"audit":              {},
+	"custom_md":          {},
 }
 
 func defaultReportSections() []store.ReportSection {
@@ -29,6 +37,10 @@ func defaultReportSections() []store.ReportSection {
 		{SectionType: "controls", Title: "Controls", IsEnabled: true},
 		{SectionType: "monitoring", Title: "Monitoring", IsEnabled: true},
 		{SectionType: "sla_summary", Title: "SLA executive summary", IsEnabled: true},
+		{SectionType: "software_eol", Title: "EOL exposure", IsEnabled: true},
+		{SectionType: "risks", Title: "Risk register", IsEnabled: true},
+		{SectionType: "findings", Title: "Findings", IsEnabled: true},
+		{SectionType: "effort", Title: "Effort", IsEnabled: true},
 		{SectionType: "audit", Title: "Audit events", IsEnabled: true},
 	}
 }
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+const (
+	riskAuditCreate       = "risk.create"
+	riskAuditUpdate       = "risk.update"
+	riskAuditArchive      = "risk.archive"
+	riskAuditRestore      = "risk.restore"
+	riskAuditAccept       = "risk.accept"
+	riskAuditAcceptRevoke = "risk.accept.revoke"
+	riskAuditMatrixUpdate = "risk.matrix.update"
+	riskAuditLinkAdd      = "risk.link.add"
+	riskAuditLinkDel      = "risk.link.remove"
+	riskAuditTaskCreate   = "risk.task.create"
+)
+
+func (h *RisksHandler) audit(r *http.Request, action, details string) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), currentUsername(r), action, details)
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+type RisksHandler struct {
+	store    store.RisksStore
+	links    store.EntityLinksStore
+	users    store.UsersStore
+	assets   store.AssetsStore
+	ctrls    store.ControlsStore
+	findings store.FindingsStore
+	vulns    store.VulnsStore
+	tasks    tasks.Store
+	policy   *rbac.Policy
+	audits   store.AuditStore
+}
+
+func NewRisksHandler(rs store.RisksStore, links store.EntityLinksStore, us store.UsersStore, assets store.AssetsStore, ctrls store.ControlsStore, findings store.FindingsStore, vulns store.VulnsStore, taskStore tasks.Store, audits store.AuditStore, policy *rbac.Policy) *RisksHandler {
+	return &RisksHandler{store: rs, links: links, users: us, assets: assets, ctrls: ctrls, findings: findings, vulns: vulns, tasks: taskStore, audits: audits, policy: policy}
+}
+
+var validRiskStatus = map[string]struct{}{
+	"identified": {},
+	"assessed":   {},
+	"treating":   {},
+	"accepted":   {},
+	"closed":     {},
+}
+
+var validRiskTreatment = map[string]struct{}{
+	"mitigate": {},
+	"accept":   {},
+	"transfer": {},
+	"avoid":    {},
+}
+
+// riskView decorates a stored risk with scores and levels computed against
+// the current matrix, so clients never have to duplicate the banding rules.
+type riskView struct {
+	store.Risk
+	InherentScore     int    `json:"inherent_score"`
+	InherentLevel     string `json:"inherent_level"`
+	ResidualScore     int    `json:"residual_score"`
+	ResidualLevel     string `json:"residual_level"`
+	AcceptanceExpired bool   `json:"acceptance_expired"`
+}
+
+func newRiskView(r store.Risk, m *store.RiskMatrix, now time.Time) riskView {
+	return riskView{
+		Risk:              r,
+		InherentScore:     store.RiskScore(r.InherentLikelihood, r.InherentImpact),
+		InherentLevel:     m.Level(r.InherentLikelihood, r.InherentImpact).Key,
+		ResidualScore:     store.RiskScore(r.ResidualLikelihood, r.ResidualImpact),
+		ResidualLevel:     m.Level(r.ResidualLikelihood, r.ResidualImpact).Key,
+		AcceptanceExpired: r.AcceptanceExpired(now),
+	}
+}
+
+func (h *RisksHandler) List(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.view"); !ok {
+		return
+	}
+	q := r.URL.Query()
+	filter := store.RiskFilter{
+		Search:         q.Get("q"),
+		Status:         strings.ToLower(strings.TrimSpace(q.Get("status"))),
+		Treatment:      strings.ToLower(strings.TrimSpace(q.Get("treatment"))),
+		Category:       q.Get("category"),
+		OwnerID:        parseInt64Default(q.Get("owner_id"), 0),
+		Tag:            q.Get("tag"),
+		IncludeDeleted: parseBool(q.Get("include_deleted")),
+		Limit:          parseIntDefault(q.Get("limit"), 0),
+		Offset:         parseIntDefault(q.Get("offset"), 0),
+	}
+	matrix, err := h.store.GetRiskMatrix(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	items, err := h.store.ListRisks(r.Context(), filter)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	level := strings.ToLower(strings.TrimSpace(q.Get("level")))
+	now := time.Now().UTC()
+	out := make([]riskView, 0, len(items))
+	for _, item := range items {
+		view := newRiskView(item, matrix, now)
+		if level != "" && view.ResidualLevel != level {
+			continue
+		}
+		out = append(out, view)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+func (h *RisksHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.view"); !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	item, err := h.store.GetRisk(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	h.writeRisk(w, r, http.StatusOK, item)
+}
+
+func (h *RisksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	var payload store.Risk
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	item, errKey := h.normalizePayload(r.Context(), payload)
+	if errKey != "" {
+		http.Error(w, errKey, http.StatusBadRequest)
+		return
+	}
+	item.CreatedBy = &sess.UserID
+	item.UpdatedBy = &sess.UserID
+	id, err := h.store.CreateRisk(r.Context(), item)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditCreate, strconv.FormatInt(id, 10))
+	created, err := h.store.GetRisk(r.Context(), id)
+	if err != nil || created == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeRisk(w, r, http.StatusCreated, created)
+}
+
+func (h *RisksHandler) Update(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	existing, err := h.store.GetRisk(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if existing == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var payload store.Risk
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if payload.Version == 0 {
+		http.Error(w, "risks.versionRequired", http.StatusBadRequest)
+		return
+	}
+	item, errKey := h.normalizePayload(r.Context(), payload)
+	if errKey != "" {
+		http.Error(w, errKey, http.StatusBadRequest)
+		return
+	}
+	item.ID = id
+	item.Version = payload.Version
+	item.UpdatedBy = &sess.UserID
+	if err := h.store.UpdateRisk(r.Context(), item); err != nil {
+		if err == store.ErrConflict {
+			http.Error(w, "risks.conflict", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditUpdate, strconv.FormatInt(id, 10))
+	updated, _ := h.store.GetRisk(r.Context(), id)
+	h.writeRisk(w, r, http.StatusOK, updated)
+}
+
+func (h *RisksHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.ArchiveRisk(r.Context(), id, sess.UserID); err != nil {
+		if err == store.ErrConflict {
+			http.Error(w, "risks.conflict", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditArchive, strconv.FormatInt(id, 10))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *RisksHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.RestoreRisk(r.Context(), id, sess.UserID); err != nil {
+		if err == store.ErrConflict {
+			http.Error(w, "risks.conflict", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditRestore, strconv.FormatInt(id, 10))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Accept records a formal sign-off of the residual risk. The sign-off must
+// carry an expiry so accepted risks come back for review.
+func (h *RisksHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.accept")
+	if !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var payload struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+		Note      string     `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if payload.ExpiresAt == nil {
+		http.Error(w, "risks.acceptance.expiryRequired", http.StatusBadRequest)
+		return
+	}
+	if !payload.ExpiresAt.After(time.Now().UTC()) {
+		http.Error(w, "risks.acceptance.expiryPast", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.AcceptRisk(r.Context(), id, sess.UserID, payload.ExpiresAt, payload.Note); err != nil {
+		if err == store.ErrConflict {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditAccept, strconv.FormatInt(id, 10)+"|"+payload.ExpiresAt.UTC().Format("2006-01-02"))
+	item, _ := h.store.GetRisk(r.Context(), id)
+	h.writeRisk(w, r, http.StatusOK, item)
+}
+
+func (h *RisksHandler) RevokeAcceptance(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.accept")
+	if !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.RevokeRiskAcceptance(r.Context(), id, sess.UserID); err != nil {
+		if err == store.ErrConflict {
+			http.Error(w, "risks.acceptance.notAccepted", http.StatusConflict)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditAcceptRevoke, strconv.FormatInt(id, 10))
+	item, _ := h.store.GetRisk(r.Context(), id)
+	h.writeRisk(w, r, http.StatusOK, item)
+}
+
+func (h *RisksHandler) normalizePayload(ctx context.Context, payload store.Risk) (*store.Risk, string) {
+	title := strings.TrimSpace(payload.Title)
+	if title == "" {
+		return nil, "risks.titleRequired"
+	}
+	status := strings.ToLower(strings.TrimSpace(payload.Status))
+	if status == "" {
+		status = "identified"
+	}
+	if _, ok := validRiskStatus[status]; !ok {
+		return nil, "risks.statusInvalid"
+	}
+	treatment := strings.ToLower(strings.TrimSpace(payload.Treatment))
+	if treatment == "" {
+		treatment = "mitigate"
+	}
+	if _, ok := validRiskTreatment[treatment]; !ok {
+		return nil, "risks.treatmentInvalid"
+	}
+	matrix, err := h.store.GetRiskMatrix(ctx)
+	if err != nil {
+		return nil, "server error"
+	}
+	if !matrix.InRange(payload.InherentLikelihood, payload.InherentImpact) {
+		return nil, "risks.scoreInvalid"
+	}
+	residualL, residualI := payload.ResidualLikelihood, payload.ResidualImpact
+	if residualL == 0 && residualI == 0 {
+		residualL, residualI = payload.InherentLikelihood, payload.InherentImpact
+	}
+	if !matrix.InRange(residualL, residualI) {
+		return nil, "risks.scoreInvalid"
+	}
+	if payload.OwnerID != nil && *payload.OwnerID > 0 {
+		u, _, err := h.users.Get(ctx, *payload.OwnerID)
+		if err != nil || u == nil {
+			return nil, "risks.ownerNotFound"
+		}
+	} else {
+		payload.OwnerID = nil
+	}
+	return &store.Risk{
+		Title:              title,
+		DescriptionMD:      strings.TrimSpace(payload.DescriptionMD),
+		Category:           strings.TrimSpace(payload.Category),
+		Status:             status,
+		OwnerID:            payload.OwnerID,
+		Threats:            payload.Threats,
+		Vulnerabilities:    payload.Vulnerabilities,
+		InherentLikelihood: payload.InherentLikelihood,
+		InherentImpact:     payload.InherentImpact,
+		ResidualLikelihood: residualL,
+		ResidualImpact:     residualI,
+		Treatment:          treatment,
+		TreatmentPlanMD:    strings.TrimSpace(payload.TreatmentPlanMD),
+		TreatmentDueAt:     payload.TreatmentDueAt,
+		ReviewAt:           payload.ReviewAt,
+		Tags:               payload.Tags,
+	}, ""
+}
+
+func (h *RisksHandler) writeRisk(w http.ResponseWriter, r *http.Request, code int, item *store.Risk) {
+	if item == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	matrix, err := h.store.GetRiskMatrix(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, code, newRiskView(*item, matrix, time.Now().UTC()))
+}
+
+func (h *RisksHandler) requirePermission(w http.ResponseWriter, r *http.Request, perm rbac.Permission) (*store.SessionRecord, bool) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	sess := val.(*store.SessionRecord)
+	if h.policy != nil && !h.policy.Allowed(sess.Roles, perm) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return sess, true
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+// defaultRiskRelation picks the relation implied by the target type when the
+// client does not send one: controls mitigate, tasks treat, the rest affect.
+func defaultRiskRelation(targetType string) string {
+	switch targetType {
+	case "control":
+		return "mitigates"
+	case "task":
+		return "treats"
+	case "asset":
+		return "affects"
+	default:
+		return "related"
+	}
+}
+
+func isAllowedRiskRelation(val string) bool {
+	switch val {
+	case "related", "mitigates", "treats", "affects", "exploits":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *RisksHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.view")
+	if !ok {
+		return
+	}
+	user, err := h.userFromSession(r.Context(), sess)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	risk, err := h.store.GetRisk(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if risk == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	links, err := h.links.ListBySource(r.Context(), "risk", strconv.FormatInt(id, 10))
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	type linkView struct {
+		ID           int64  `json:"id"`
+		TargetType   string `json:"target_type"`
+		TargetID     string `json:"target_id"`
+		RelationType string `json:"relation_type"`
+		TargetTitle  string `json:"target_title,omitempty"`
+	}
+	menu := h.effectiveMenu(r.Context(), user, sess.Roles)
+	items := make([]linkView, 0, len(links))
+	for _, l := range links {
+		view := linkView{ID: l.ID, TargetType: l.TargetType, TargetID: l.TargetID, RelationType: l.RelationType}
+		if h.canViewTarget(sess.Roles, menu, l.TargetType) {
+			view.TargetTitle = h.resolveTargetTitle(r.Context(), l.TargetType, l.TargetID)
+		}
+		items = append(items, view)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *RisksHandler) AddLink(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	user, err := h.userFromSession(r.Context(), sess)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	risk, err := h.store.GetRisk(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if risk == nil || risk.DeletedAt != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var payload struct {
+		TargetType   string `json:"target_type"`
+		TargetID     string `json:"target_id"`
+		RelationType string `json:"relation_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	targetType := strings.ToLower(strings.TrimSpace(payload.TargetType))
+	targetID := strings.TrimSpace(payload.TargetID)
+	if targetType == "" || targetID == "" {
+		http.Error(w, "risks.links.required", http.StatusBadRequest)
+		return
+	}
+	relationType := strings.ToLower(strings.TrimSpace(payload.RelationType))
+	if relationType == "" {
+		relationType = defaultRiskRelation(targetType)
+	}
+	if !isAllowedRiskRelation(relationType) {
+		http.Error(w, "risks.links.relationInvalid", http.StatusBadRequest)
+		return
+	}
+	menu := h.effectiveMenu(r.Context(), user, sess.Roles)
+	if err := h.validateLinkTarget(r.Context(), sess.Roles, menu, targetType, targetID); err != nil {
+		code := http.StatusBadRequest
+		if err.Error() == "forbidden" {
+			code = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	existing, _ := h.links.ListBySource(r.Context(), "risk", strconv.FormatInt(id, 10))
+	for _, l := range existing {
+		if l.TargetType == targetType && l.TargetID == targetID {
+			http.Error(w, "risks.links.duplicate", http.StatusConflict)
+			return
+		}
+	}
+	link := &store.EntityLink{
+		SourceType:   "risk",
+		SourceID:     strconv.FormatInt(id, 10),
+		TargetType:   targetType,
+		TargetID:     targetID,
+		RelationType: relationType,
+	}
+	if _, err := h.links.Add(r.Context(), link); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditLinkAdd, strconv.FormatInt(id, 10)+"|"+targetType+"|"+targetID)
+	writeJSON(w, http.StatusCreated, link)
+}
+
+func (h *RisksHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.manage"); !ok {
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	linkID := parseInt64Default(pathParams(r)["link_id"], 0)
+	if id <= 0 || linkID <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	links, err := h.links.ListBySource(r.Context(), "risk", strconv.FormatInt(id, 10))
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	var target *store.EntityLink
+	for i := range links {
+		if links[i].ID == linkID {
+			target = &links[i]
+			break
+		}
+	}
+	if target == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err := h.links.Delete(r.Context(), linkID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditLinkDel, strconv.FormatInt(id, 10)+"|"+target.TargetType+"|"+target.TargetID)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// CreateTask opens a treatment task on a board the caller may manage and
+// links it to the risk in both directions.
+func (h *RisksHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requirePermission(w, r, "risks.manage")
+	if !ok {
+		return
+	}
+	if h.tasks == nil {
+		http.Error(w, "risks.tasks.unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if h.policy == nil || !h.policy.Allowed(sess.Roles, tasks.PermCreate) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	user, err := h.userFromSession(r.Context(), sess)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	risk, err := h.store.GetRisk(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if risk == nil || risk.DeletedAt != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var payload struct {
+		ColumnID    int64      `json:"column_id"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Priority    string     `json:"priority"`
+		DueDate     *time.Time `json:"due_date"`
+		AssignOwner bool       `json:"assign_owner"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if payload.ColumnID <= 0 {
+		http.Error(w, "tasks.columnRequired", http.StatusBadRequest)
+		return
+	}
+	column, err := h.tasks.GetColumn(r.Context(), payload.ColumnID)
+	if err != nil || column == nil || !column.IsActive {
+		http.Error(w, "tasks.columnNotFound", http.StatusBadRequest)
+		return
+	}
+	board, _ := h.tasks.GetBoard(r.Context(), column.BoardID)
+	spaceACL := []tasks.ACLRule{}
+	if board != nil && board.SpaceID > 0 {
+		spaceACL, _ = h.tasks.GetSpaceACL(r.Context(), board.SpaceID)
+	}
+	boardACL, _ := h.tasks.GetBoardACL(r.Context(), column.BoardID)
+	groups, _ := h.users.UserGroups(r.Context(), user.ID)
+	if !taskBoardAllowed(user, sess.Roles, groups, spaceACL, boardACL, "manage") {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	priority := tasks.PriorityMedium
+	switch strings.ToLower(strings.TrimSpace(payload.Priority)) {
+	case "":
+	case tasks.PriorityLow, tasks.PriorityMedium, tasks.PriorityHigh, tasks.PriorityCritical:
+		priority = strings.ToLower(strings.TrimSpace(payload.Priority))
+	default:
+		http.Error(w, "tasks.priorityInvalid", http.StatusBadRequest)
+		return
+	}
+	title := strings.TrimSpace(payload.Title)
+	if title == "" {
+		title = risk.Title
+	}
+	description := strings.TrimSpace(payload.Description)
+	if description == "" {
+		description = risk.TreatmentPlanMD
+	}
+	due := payload.DueDate
+	if due == nil {
+		due = risk.TreatmentDueAt
+	}
+	var assignees []int64
+	if payload.AssignOwner && risk.OwnerID != nil {
+		assignees = append(assignees, *risk.OwnerID)
+	}
+	task := &tasks.Task{
+		BoardID:     column.BoardID,
+		ColumnID:    column.ID,
+		Title:       title,
+		Description: description,
+		Priority:    priority,
+		DueDate:     due,
+		CreatedBy:   &user.ID,
+	}
+	riskID := strconv.FormatInt(id, 10)
+	taskID, err := h.tasks.CreateTaskWithLinks(r.Context(), task, assignees, []tasks.Link{{TargetType: "risk", TargetID: riskID}})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	_, _ = h.links.Add(r.Context(), &store.EntityLink{
+		SourceType:   "risk",
+		SourceID:     riskID,
+		TargetType:   "task",
+		TargetID:     strconv.FormatInt(taskID, 10),
+		RelationType: "treats",
+	})
+	h.audit(r, riskAuditTaskCreate, riskID+"|"+strconv.FormatInt(taskID, 10))
+	writeJSON(w, http.StatusCreated, map[string]any{"task_id": taskID, "board_id": column.BoardID})
+}
+
+func (h *RisksHandler) validateLinkTarget(ctx context.Context, roles []string, menu []string, targetType, targetID string) error {
+	if !h.canViewTarget(roles, menu, targetType) {
+		if _, known := riskLinkModules[targetType]; !known {
+			return errors.New("risks.links.typeInvalid")
+		}
+		return errors.New("forbidden")
+	}
+	id, err := strconv.ParseInt(targetID, 10, 64)
+	if err != nil || id <= 0 {
+		return errors.New("risks.links.targetInvalid")
+	}
+	found := false
+	switch targetType {
+	case "asset":
+		if h.assets != nil {
+			a, err := h.assets.GetAsset(ctx, id)
+			found = err == nil && a != nil && a.DeletedAt == nil
+		}
+	case "control":
+		if h.ctrls != nil {
+			c, err := h.ctrls.GetControl(ctx, id)
+			found = err == nil && c != nil
+		}
+	case "finding":
+		if h.findings != nil {
+			f, err := h.findings.GetFinding(ctx, id)
+			found = err == nil && f != nil && f.DeletedAt == nil
+		}
+	case "vulnerability":
+		if h.vulns != nil {
+			v, err := h.vulns.GetVulnerability(ctx, id)
+			found = err == nil && v != nil
+		}
+	case "task":
+		if h.tasks != nil {
+			t, err := h.tasks.GetTask(ctx, id)
+			found = err == nil && t != nil
+		}
+	}
+	if !found {
+		return errors.New("risks.links.targetNotFound")
+	}
+	return nil
+}
+
+// riskLinkModules maps link target types to the permission and menu entry
+// that gate them.
+var riskLinkModules = map[string]struct {
+	perm rbac.Permission
+	menu string
+}{
+	"asset":         {perm: "assets.view", menu: "assets"},
+	"control":       {perm: "controls.view", menu: "controls"},
+	"finding":       {perm: "findings.view", menu: "findings"},
+	"vulnerability": {perm: "findings.view", menu: "findings"},
+	"task":          {perm: "tasks.view", menu: "tasks"},
+}
+
+func (h *RisksHandler) canViewTarget(roles []string, menu []string, targetType string) bool {
+	mod, ok := riskLinkModules[strings.ToLower(strings.TrimSpace(targetType))]
+	if !ok || h.policy == nil {
+		return false
+	}
+	return h.policy.Allowed(roles, mod.perm) && allowedByMenuPermissions(menu, mod.menu)
+}
+
+func (h *RisksHandler) resolveTargetTitle(ctx context.Context, targetType, targetID string) string {
+	id, err := strconv.ParseInt(strings.TrimSpace(targetID), 10, 64)
+	if err != nil || id <= 0 {
+		return ""
+	}
+	switch strings.ToLower(strings.TrimSpace(targetType)) {
+	case "asset":
+		if h.assets == nil {
+			return ""
+		}
+		if a, err := h.assets.GetAsset(ctx, id); err == nil && a != nil && a.DeletedAt == nil {
+			return a.Name
+		}
+	case "control":
+		if h.ctrls == nil {
+			return ""
+		}
+		if c, err := h.ctrls.GetControl(ctx, id); err == nil && c != nil {
+			return strings.TrimSpace(c.Code + " " + c.Title)
+		}
+	case "finding":
+		if h.findings == nil {
+			return ""
+		}
+		if f, err := h.findings.GetFinding(ctx, id); err == nil && f != nil {
+			return f.Title
+		}
+	case "vulnerability":
+		if h.vulns == nil {
+			return ""
+		}
+		if v, err := h.vulns.GetVulnerability(ctx, id); err == nil && v != nil {
+			return strings.TrimSpace(v.ExternalID + " " + v.Title)
+		}
+	case "task":
+		if h.tasks == nil {
+			return ""
+		}
+		if t, err := h.tasks.GetTask(ctx, id); err == nil && t != nil {
+			return t.Title
+		}
+	}
+	return ""
+}
+
+func (h *RisksHandler) effectiveMenu(ctx context.Context, user *store.User, roles []string) []string {
+	if h == nil || h.users == nil || user == nil {
+		return nil
+	}
+	groups, _ := h.users.UserGroups(ctx, user.ID)
+	eff := auth.CalculateEffectiveAccess(user, roles, groups, h.policy)
+	return eff.MenuPermissions
+}
+
+func (h *RisksHandler) userFromSession(ctx context.Context, sess *store.SessionRecord) (*store.User, error) {
+	if sess == nil {
+		return nil, errors.New("no session")
+	}
+	u, _, err := h.users.FindByUsername(ctx, sess.Username)
+	return u, err
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+func (h *RisksHandler) GetMatrix(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.view"); !ok {
+		return
+	}
+	matrix, err := h.store.GetRiskMatrix(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, matrix)
+}
+
+// UpdateMatrix replaces the scoring matrix. Shrinking an axis is refused while
+// active risks still sit on the rows or columns that would disappear.
+func (h *RisksHandler) UpdateMatrix(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.manage"); !ok {
+		return
+	}
+	var payload store.RiskMatrix
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	for i := range payload.Likelihood {
+		payload.Likelihood[i] = strings.TrimSpace(payload.Likelihood[i])
+	}
+	for i := range payload.Impact {
+		payload.Impact[i] = strings.TrimSpace(payload.Impact[i])
+	}
+	for i := range payload.Levels {
+		payload.Levels[i].Key = strings.ToLower(strings.TrimSpace(payload.Levels[i].Key))
+		payload.Levels[i].Color = strings.TrimSpace(payload.Levels[i].Color)
+	}
+	if err := payload.Validate(); err != nil {
+		http.Error(w, "risks.matrix.invalid", http.StatusBadRequest)
+		return
+	}
+	items, err := listAllRisks(r.Context(), h.store)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	for _, item := range items {
+		if !payload.InRange(item.InherentLikelihood, item.InherentImpact) || !payload.InRange(item.ResidualLikelihood, item.ResidualImpact) {
+			http.Error(w, "risks.matrix.inUse", http.StatusConflict)
+			return
+		}
+	}
+	if err := h.store.SaveRiskMatrix(r.Context(), &payload); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, riskAuditMatrixUpdate, "")
+	writeJSON(w, http.StatusOK, payload)
+}
+
+type riskHeatmap struct {
+	Matrix   *store.RiskMatrix `json:"matrix"`
+	Inherent [][]int           `json:"inherent"`
+	Residual [][]int           `json:"residual"`
+	Total    int               `json:"total"`
+}
+
+// Heatmap counts open risks per matrix cell; rows are likelihood and
+// columns are impact, both zero-based.
+func (h *RisksHandler) Heatmap(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requirePermission(w, r, "risks.view"); !ok {
+		return
+	}
+	matrix, err := h.store.GetRiskMatrix(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	items, err := listAllRisks(r.Context(), h.store)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, buildRiskHeatmap(matrix, items))
+}
+
+func buildRiskHeatmap(matrix *store.RiskMatrix, items []store.Risk) riskHeatmap {
+	out := riskHeatmap{Matrix: matrix, Inherent: newRiskGrid(matrix), Residual: newRiskGrid(matrix)}
+	for _, item := range items {
+		if item.DeletedAt != nil || item.Status == "closed" {
+			continue
+		}
+		out.Total++
+		if matrix.InRange(item.InherentLikelihood, item.InherentImpact) {
+			out.Inherent[item.InherentLikelihood-1][item.InherentImpact-1]++
+		}
+		if matrix.InRange(item.ResidualLikelihood, item.ResidualImpact) {
+			out.Residual[item.ResidualLikelihood-1][item.ResidualImpact-1]++
+		}
+	}
+	return out
+}
+
+func newRiskGrid(matrix *store.RiskMatrix) [][]int {
+	grid := make([][]int, len(matrix.Likelihood))
+	for i := range grid {
+		grid[i] = make([]int, len(matrix.Impact))
+	}
+	return grid
+}
+
+func listAllRisks(ctx context.Context, rs store.RisksStore) ([]store.Risk, error) {
+	const page = 500
+	var out []store.Risk
+	for offset := 0; ; offset += page {
+		items, err := rs.ListRisks(ctx, store.RiskFilter{Limit: page, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
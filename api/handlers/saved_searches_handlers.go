@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/query"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+)
+
+const (
+	savedSearchAuditCreate = "saved_searches.create"
+	savedSearchAuditUpdate = "saved_searches.update"
+	savedSearchAuditDelete = "saved_searches.delete"
+)
+
+const maxSavedSearchName = 200
+
+// savedSearchPermissions maps entity types to the permission needed to run their queries.
+var savedSearchPermissions = map[string]rbac.Permission{
+	store.SearchEntityIncident: "incidents.view",
+	store.SearchEntityFinding:  "findings.view",
+	store.SearchEntityAsset:    "assets.view",
+	store.SearchEntityTask:     "tasks.view",
+	store.SearchEntityDoc:      "docs.view",
+}
+
+// SavedSearchesHandler manages named list queries. Searches are private to their
+// owner unless shared with groups the owner belongs to.
+type SavedSearchesHandler struct {
+	store   store.SavedSearchesStore
+	queries *query.Service
+	users   store.UsersStore
+	audits  store.AuditStore
+	policy  *rbac.Policy
+}
+
+func NewSavedSearchesHandler(ss store.SavedSearchesStore, queries *query.Service, us store.UsersStore, audits store.AuditStore, policy *rbac.Policy) *SavedSearchesHandler {
+	return &SavedSearchesHandler{store: ss, queries: queries, users: us, audits: audits, policy: policy}
+}
+
+type savedSearchView struct {
+	store.SavedSearch
+	CanEdit bool `json:"can_edit"`
+}
+
+// List returns the searches visible to the user for the entity types the user may view.
+func (h *SavedSearchesHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	entityType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("entity_type")))
+	if entityType != "" && !query.ValidEntityType(entityType) {
+		http.Error(w, "savedSearches.error.entityType", http.StatusBadRequest)
+		return
+	}
+	items, err := h.store.ListSavedSearches(r.Context(), sess.UserID, entityType)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	out := make([]savedSearchView, 0, len(items))
+	for _, item := range items {
+		if !h.canUse(sess.Roles, item.EntityType) {
+			continue
+		}
+		out = append(out, savedSearchView{SavedSearch: item, CanEdit: item.OwnerID == sess.UserID})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+// Groups returns the groups of the user that searches can be shared with.
+func (h *SavedSearchesHandler) Groups(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	groups, err := h.users.UserGroups(r.Context(), sess.UserID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	items := make([]map[string]any, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, map[string]any{"id": g.ID, "name": g.Name})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type savedSearchPayload struct {
+	EntityType string  `json:"entity_type"`
+	Name       string  `json:"name"`
+	Query      string  `json:"query"`
+	GroupIDs   []int64 `json:"group_ids"`
+	Dashboard  bool    `json:"dashboard"`
+}
+
+func (h *SavedSearchesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	var payload savedSearchPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	payload.EntityType = strings.ToLower(strings.TrimSpace(payload.EntityType))
+	if !query.ValidEntityType(payload.EntityType) {
+		http.Error(w, "savedSearches.error.entityType", http.StatusBadRequest)
+		return
+	}
+	if !h.canUse(sess.Roles, payload.EntityType) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	item := store.SavedSearch{OwnerID: sess.UserID, EntityType: payload.EntityType}
+	if !h.apply(w, r, sess, &item, payload) {
+		return
+	}
+	if _, err := h.store.CreateSavedSearch(r.Context(), &item); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, savedSearchAuditCreate, item)
+	writeJSON(w, http.StatusCreated, savedSearchView{SavedSearch: item, CanEdit: true})
+}
+
+// Update changes the name, query, sharing and dashboard flag; only the owner may do it.
+func (h *SavedSearchesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	item, ok := h.owned(w, r, sess)
+	if !ok {
+		return
+	}
+	var payload savedSearchPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !h.apply(w, r, sess, item, payload) {
+		return
+	}
+	if err := h.store.UpdateSavedSearch(r.Context(), item); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "savedSearches.error.notFound", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, savedSearchAuditUpdate, *item)
+	writeJSON(w, http.StatusOK, savedSearchView{SavedSearch: *item, CanEdit: true})
+}
+
+func (h *SavedSearchesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	item, ok := h.owned(w, r, sess)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteSavedSearch(r.Context(), item.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "savedSearches.error.notFound", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, savedSearchAuditDelete, *item)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// apply validates the payload and copies it into item.
+func (h *SavedSearchesHandler) apply(w http.ResponseWriter, r *http.Request, sess *store.SessionRecord, item *store.SavedSearch, payload savedSearchPayload) bool {
+	name := strings.TrimSpace(payload.Name)
+	if name == "" || len([]rune(name)) > maxSavedSearchName {
+		http.Error(w, "savedSearches.error.name", http.StatusBadRequest)
+		return false
+	}
+	text := strings.TrimSpace(payload.Query)
+	if text == "" {
+		http.Error(w, "savedSearches.error.query", http.StatusBadRequest)
+		return false
+	}
+	if _, err := h.queries.Compile(r.Context(), item.EntityType, text, sess.UserID, sess.Roles); err != nil {
+		if !queryError(w, err) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+		}
+		return false
+	}
+	if len(payload.GroupIDs) > 0 && (h.policy == nil || !h.policy.Allowed(sess.Roles, "groups.manage")) {
+		groups, err := h.users.UserGroups(r.Context(), sess.UserID)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return false
+		}
+		member := map[int64]bool{}
+		for _, g := range groups {
+			member[g.ID] = true
+		}
+		for _, id := range payload.GroupIDs {
+			if !member[id] {
+				http.Error(w, "savedSearches.error.group", http.StatusBadRequest)
+				return false
+			}
+		}
+	}
+	item.Name = name
+	item.Query = text
+	item.GroupIDs = payload.GroupIDs
+	item.Dashboard = payload.Dashboard
+	return true
+}
+
+func (h *SavedSearchesHandler) owned(w http.ResponseWriter, r *http.Request, sess *store.SessionRecord) (*store.SavedSearch, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	item, err := h.store.GetSavedSearch(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if item == nil {
+		http.Error(w, "savedSearches.error.notFound", http.StatusNotFound)
+		return nil, false
+	}
+	if item.OwnerID != sess.UserID {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return nil, false
+	}
+	return item, true
+}
+
+func (h *SavedSearchesHandler) canUse(roles []string, entityType string) bool {
+	perm, ok := savedSearchPermissions[entityType]
+	return ok && (h.policy == nil || h.policy.Allowed(roles, perm))
+}
+
+func (h *SavedSearchesHandler) session(w http.ResponseWriter, r *http.Request) (*store.SessionRecord, bool) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return val.(*store.SessionRecord), true
+}
+
+func (h *SavedSearchesHandler) audit(r *http.Request, action string, item store.SavedSearch) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), currentUsername(r), action, item.EntityType+":"+item.Name)
+}
+
+// queryError writes an invalid query or unknown saved search error and reports
+// whether err was one.
+func queryError(w http.ResponseWriter, err error) bool {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		http.Error(w, queryErr.Error(), http.StatusBadRequest)
+		return true
+	}
+	if errors.Is(err, query.ErrSearchNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return true
+	}
+	var fieldErr *customfields.FieldError
+	if errors.As(err, &fieldErr) {
+		http.Error(w, fieldErr.Error(), http.StatusBadRequest)
+		return true
+	}
+	return false
+}
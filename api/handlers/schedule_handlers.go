@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/schedule"
+	"berkut-scc/core/store"
+)
+
+const (
+	scheduleAuditCalendarCreate = "schedule.calendar.create"
+	scheduleAuditCalendarUpdate = "schedule.calendar.update"
+	scheduleAuditCalendarDelete = "schedule.calendar.delete"
+	scheduleAuditCalendarImport = "schedule.calendar.import"
+
+	maxCalendarNameLen = 200
+	maxCalendarDays    = 5000
+	// icsImportYears is how far ahead recurring events of an imported .ics are expanded.
+	icsImportYears = 5
+)
+
+// ScheduleHandler manages business calendars and previews schedules of the shared
+// scheduling engine used by recurring tasks, backup plans, maintenance windows and SLAs.
+type ScheduleHandler struct {
+	store  store.ScheduleCalendarsStore
+	audits store.AuditStore
+}
+
+func NewScheduleHandler(sc store.ScheduleCalendarsStore, audits store.AuditStore) *ScheduleHandler {
+	return &ScheduleHandler{store: sc, audits: audits}
+}
+
+type scheduleCalendarView struct {
+	store.ScheduleCalendar
+	HolidayCount int `json:"holiday_count"`
+}
+
+// ListCalendars returns the calendars without holidays, for pickers and the settings list.
+func (h *ScheduleHandler) ListCalendars(w http.ResponseWriter, r *http.Request) {
+	items, err := h.store.ListScheduleCalendars(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	out := make([]scheduleCalendarView, 0, len(items))
+	for _, item := range items {
+		full, err := h.store.GetScheduleCalendar(r.Context(), item.ID)
+		if err != nil || full == nil {
+			continue
+		}
+		item.Holidays = nil
+		out = append(out, scheduleCalendarView{ScheduleCalendar: item, HolidayCount: len(full.Holidays)})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": out})
+}
+
+func (h *ScheduleHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	item, ok := h.existingCalendar(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *ScheduleHandler) CreateCalendar(w http.ResponseWriter, r *http.Request) {
+	var payload store.ScheduleCalendar
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := normalizeScheduleCalendar(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sess := scheduleSession(r); sess != nil {
+		payload.CreatedBy = sess.UserID
+	}
+	id, err := h.store.CreateScheduleCalendar(r.Context(), &payload)
+	if err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	h.audit(r, scheduleAuditCalendarCreate, strconv.FormatInt(id, 10)+"|"+payload.Name)
+	h.writeCalendar(w, r, id, http.StatusCreated)
+}
+
+func (h *ScheduleHandler) UpdateCalendar(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.existingCalendar(w, r)
+	if !ok {
+		return
+	}
+	var payload store.ScheduleCalendar
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	payload.ID = existing.ID
+	if payload.Holidays == nil {
+		payload.Holidays = existing.Holidays
+	}
+	if err := normalizeScheduleCalendar(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.UpdateScheduleCalendar(r.Context(), &payload); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	h.audit(r, scheduleAuditCalendarUpdate, strconv.FormatInt(existing.ID, 10)+"|"+payload.Name)
+	h.writeCalendar(w, r, existing.ID, http.StatusOK)
+}
+
+func (h *ScheduleHandler) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.existingCalendar(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteScheduleCalendar(r.Context(), existing.ID); err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, scheduleAuditCalendarDelete, strconv.FormatInt(existing.ID, 10)+"|"+existing.Name)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ImportCalendar adds the events of an iCalendar file (multipart field "file") as
+// holidays. With replace=1 the existing holidays are dropped first.
+func (h *ScheduleHandler) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.existingCalendar(w, r)
+	if !ok {
+		return
+	}
+	if err := parseMultipartFormLimited(w, r, 4<<20); err != nil {
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "import.fileRequired", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	imported, err := schedule.ParseICS(file, time.Now().UTC().AddDate(icsImportYears, 0, 0))
+	if err != nil {
+		http.Error(w, schedule.ErrInvalidICS.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("replace") == "1" {
+		existing.Holidays = nil
+	}
+	existing.Holidays = mergeHolidays(existing.Holidays, imported)
+	if err := normalizeScheduleCalendar(existing); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.UpdateScheduleCalendar(r.Context(), existing); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	h.audit(r, scheduleAuditCalendarImport, strconv.FormatInt(existing.ID, 10)+"|"+strconv.Itoa(len(imported)))
+	h.writeCalendar(w, r, existing.ID, http.StatusOK)
+}
+
+type schedulePreviewPayload struct {
+	schedule.Spec
+	Count int    `json:"count"`
+	After string `json:"after"`
+}
+
+type scheduleOccurrence struct {
+	At      time.Time `json:"at"`
+	Local   string    `json:"local"`
+	Holiday string    `json:"holiday,omitempty"`
+}
+
+// Preview returns the next occurrences of a schedule spec after now or after "after".
+func (h *ScheduleHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var payload schedulePreviewPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	spec, err := payload.Spec.Normalize()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var cal *schedule.Calendar
+	if spec.CalendarID > 0 {
+		if cal, err = h.store.BusinessCalendar(r.Context(), spec.CalendarID); err != nil {
+			if errors.Is(err, schedule.ErrCalendarNotFound) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	rule, err := spec.Compile(cal)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	count := payload.Count
+	if count <= 0 {
+		count = 10
+	}
+	count = min(count, schedule.MaxPreview)
+	after := time.Now().UTC()
+	if strings.TrimSpace(payload.After) != "" {
+		loc, _ := schedule.LoadLocation(spec.Timezone)
+		if after, _, err = schedule.ParseLocalTime(payload.After, loc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	items := []scheduleOccurrence{}
+	for _, at := range rule.Occurrences(after, count) {
+		item := scheduleOccurrence{At: at.UTC(), Local: at.Format("2006-01-02 15:04 Mon")}
+		item.Holiday, _ = cal.Holiday(at)
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"spec": spec, "items": items})
+}
+
+func (h *ScheduleHandler) existingCalendar(w http.ResponseWriter, r *http.Request) (*store.ScheduleCalendar, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	item, err := h.store.GetScheduleCalendar(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if item == nil {
+		http.Error(w, schedule.ErrCalendarNotFound.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return item, true
+}
+
+func (h *ScheduleHandler) writeCalendar(w http.ResponseWriter, r *http.Request, id int64, status int) {
+	item, err := h.store.GetScheduleCalendar(r.Context(), id)
+	if err != nil || item == nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, item)
+}
+
+func (h *ScheduleHandler) writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, "schedule.error.calendarDuplicate", http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, schedule.ErrCalendarNotFound.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *ScheduleHandler) audit(r *http.Request, action, details string) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), currentUsername(r), action, details)
+}
+
+func scheduleSession(r *http.Request) *store.SessionRecord {
+	sess, _ := r.Context().Value(auth.SessionContextKey).(*store.SessionRecord)
+	return sess
+}
+
+// normalizeScheduleCalendar trims the calendar, deduplicates weekend days and holidays
+// and checks the holiday dates.
+func normalizeScheduleCalendar(c *store.ScheduleCalendar) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	if c.Name == "" || len([]rune(c.Name)) > maxCalendarNameLen {
+		return errors.New("schedule.error.calendarName")
+	}
+	seen := map[int]bool{}
+	weekend := []int{}
+	for _, d := range c.Weekend {
+		if d < 0 || d > 6 {
+			return errors.New("schedule.error.weekend")
+		}
+		if !seen[d] {
+			seen[d] = true
+			weekend = append(weekend, d)
+		}
+	}
+	if len(weekend) == 7 {
+		return errors.New("schedule.error.weekend")
+	}
+	sort.Ints(weekend)
+	c.Weekend = weekend
+	holidays := mergeHolidays(nil, c.Holidays)
+	for i := range holidays {
+		if _, err := time.Parse(schedule.DateLayout, holidays[i].Date); err != nil {
+			return errors.New("schedule.error.holidayDate")
+		}
+	}
+	if len(holidays) > maxCalendarDays {
+		return errors.New("schedule.error.tooManyHolidays")
+	}
+	c.Holidays = holidays
+	return nil
+}
+
+// mergeHolidays adds extra to base; a date keeps its first name.
+func mergeHolidays(base, extra []schedule.Holiday) []schedule.Holiday {
+	byDate := map[string]string{}
+	var order []string
+	for _, h := range append(append([]schedule.Holiday{}, base...), extra...) {
+		date := strings.TrimSpace(h.Date)
+		if _, ok := byDate[date]; ok {
+			continue
+		}
+		byDate[date] = strings.TrimSpace(h.Name)
+		order = append(order, date)
+	}
+	sort.Strings(order)
+	out := make([]schedule.Holiday, 0, len(order))
+	for _, date := range order {
+		out = append(out, schedule.Holiday{Date: date, Name: byDate[date]})
+	}
+	return out
+}
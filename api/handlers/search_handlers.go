@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/docs"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+const (
+	minSearchQuery     = 2
+	maxSearchQuery     = 200
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// searchOverfetch is how many candidates per requested result are fetched for
+	// types with per-record access checks, so that hidden records do not starve the page.
+	searchOverfetch = 5
+)
+
+// searchPermissions maps global search entity types to the permission needed to see them.
+var searchPermissions = map[string]rbac.Permission{
+	store.SearchEntityDoc:      "docs.view",
+	store.SearchEntityIncident: "incidents.view",
+	store.SearchEntityTask:     "tasks.view",
+	store.SearchEntityFinding:  "findings.view",
+	store.SearchEntityAsset:    "assets.view",
+	store.SearchEntitySoftware: "software.view",
+	store.SearchEntityControl:  "controls.view",
+	store.SearchEntityMonitor:  "monitoring.view",
+}
+
+// SearchHandler runs the global search across modules. Documents, incidents and tasks
+// are filtered by their ACL and classification, other types by module permission only.
+type SearchHandler struct {
+	cfg            *config.AppConfig
+	store          store.SearchStore
+	users          store.UsersStore
+	docsStore      store.DocsStore
+	docsSvc        *docs.Service
+	incidentsStore store.IncidentsStore
+	incidentsSvc   *incidents.Service
+	tasksStore     tasks.Store
+	policy         *rbac.Policy
+}
+
+func NewSearchHandler(cfg *config.AppConfig, ss store.SearchStore, users store.UsersStore, docsStore store.DocsStore, docsSvc *docs.Service, incidentsStore store.IncidentsStore, incidentsSvc *incidents.Service, tasksStore tasks.Store, policy *rbac.Policy) *SearchHandler {
+	return &SearchHandler{
+		cfg:            cfg,
+		store:          ss,
+		users:          users,
+		docsStore:      docsStore,
+		docsSvc:        docsSvc,
+		incidentsStore: incidentsStore,
+		incidentsSvc:   incidentsSvc,
+		tasksStore:     tasksStore,
+		policy:         policy,
+	}
+}
+
+type searchViewer struct {
+	user   *store.User
+	roles  []string
+	groups []store.Group
+	eff    store.EffectiveAccess
+	boards map[int64]bool
+}
+
+// Search returns the best matches of q among the requested types the user may see.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	viewer, err := h.currentViewer(r)
+	if err != nil || viewer.user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if n := len([]rune(text)); n < minSearchQuery || n > maxSearchQuery {
+		http.Error(w, "search.error.query", http.StatusBadRequest)
+		return
+	}
+	limit := parseIntDefault(r.URL.Query().Get("limit"), defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	types, ok := searchTypes(r.URL.Query().Get("types"))
+	if !ok {
+		http.Error(w, "search.error.type", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	items := []store.SearchResult{}
+	for _, entityType := range types {
+		if h.policy != nil && !h.policy.Allowed(viewer.roles, searchPermissions[entityType]) {
+			continue
+		}
+		fetch := limit
+		if h.recordChecked(entityType) {
+			fetch = limit * searchOverfetch
+		}
+		found, err := h.store.Search(ctx, entityType, text, fetch)
+		if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		kept := 0
+		for _, item := range found {
+			if kept == limit {
+				break
+			}
+			if !h.canView(ctx, viewer, item) {
+				continue
+			}
+			items = append(items, item)
+			kept++
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Rank != items[j].Rank {
+			return items[i].Rank > items[j].Rank
+		}
+		return items[i].UpdatedAt.After(items[j].UpdatedAt)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// searchTypes parses the comma separated type filter; empty means all types.
+func searchTypes(raw string) ([]string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return store.GlobalSearchTypes, true
+	}
+	requested := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if _, ok := searchPermissions[part]; !ok {
+			return nil, false
+		}
+		requested[part] = true
+	}
+	var out []string
+	for _, entityType := range store.GlobalSearchTypes {
+		if requested[entityType] {
+			out = append(out, entityType)
+		}
+	}
+	return out, true
+}
+
+func (h *SearchHandler) recordChecked(entityType string) bool {
+	switch entityType {
+	case store.SearchEntityDoc, store.SearchEntityIncident, store.SearchEntityTask:
+		return true
+	}
+	return false
+}
+
+func (h *SearchHandler) canView(ctx context.Context, viewer *searchViewer, item store.SearchResult) bool {
+	switch item.EntityType {
+	case store.SearchEntityDoc:
+		return h.canViewDoc(ctx, viewer, item.EntityID)
+	case store.SearchEntityIncident:
+		return h.canViewIncident(ctx, viewer, item.EntityID)
+	case store.SearchEntityTask:
+		return h.canViewTask(ctx, viewer, item.EntityID)
+	}
+	return true
+}
+
+func (h *SearchHandler) canViewDoc(ctx context.Context, viewer *searchViewer, id int64) bool {
+	if h.docsStore == nil || h.docsSvc == nil {
+		return false
+	}
+	d, err := h.docsStore.GetDocument(ctx, id)
+	if err != nil || d == nil {
+		return false
+	}
+	docACL, _ := h.docsStore.GetDocACL(ctx, d.ID)
+	var folderACL []store.ACLRule
+	if d.FolderID != nil {
+		folderACL, _ = h.docsStore.GetFolderACL(ctx, *d.FolderID)
+	}
+	if !h.docsSvc.CheckACL(viewer.user, viewer.roles, d, docACL, folderACL, "view") {
+		return false
+	}
+	if !h.canViewByClassification(viewer.eff, d.ClassificationLevel, d.ClassificationTags) {
+		return false
+	}
+	if d.Status == docs.StatusReview {
+		ap, parts, _ := h.docsStore.GetActiveApproval(ctx, d.ID)
+		if ap != nil && !isApprovalParticipant(parts, viewer.user.ID) && !hasRole(viewer.roles, "doc_admin") && !hasRole(viewer.roles, "admin") {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *SearchHandler) canViewIncident(ctx context.Context, viewer *searchViewer, id int64) bool {
+	if h.incidentsStore == nil || h.incidentsSvc == nil {
+		return false
+	}
+	inc, err := h.incidentsStore.GetIncident(ctx, id)
+	if err != nil || inc == nil {
+		return false
+	}
+	acl, _ := h.incidentsStore.GetIncidentACL(ctx, inc.ID)
+	if !h.incidentsSvc.CheckACL(viewer.user, viewer.roles, acl, "view") {
+		return false
+	}
+	return h.canViewByClassification(viewer.eff, inc.ClassificationLevel, inc.ClassificationTags)
+}
+
+func (h *SearchHandler) canViewTask(ctx context.Context, viewer *searchViewer, id int64) bool {
+	if h.tasksStore == nil {
+		return false
+	}
+	task, err := h.tasksStore.GetTask(ctx, id)
+	if err != nil || task == nil {
+		return false
+	}
+	if allowed, ok := viewer.boards[task.BoardID]; ok {
+		return allowed
+	}
+	allowed := false
+	board, _ := h.tasksStore.GetBoard(ctx, task.BoardID)
+	if board != nil && board.IsActive {
+		boardACL, _ := h.tasksStore.GetBoardACL(ctx, board.ID)
+		var spaceACL []tasks.ACLRule
+		if board.SpaceID > 0 {
+			spaceACL, _ = h.tasksStore.GetSpaceACL(ctx, board.SpaceID)
+		}
+		allowed = taskBoardAllowed(viewer.user, viewer.roles, viewer.groups, spaceACL, boardACL, "view")
+	}
+	viewer.boards[task.BoardID] = allowed
+	return allowed
+}
+
+func (h *SearchHandler) currentViewer(r *http.Request) (*searchViewer, error) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		return nil, errors.New("no session")
+	}
+	sess := val.(*store.SessionRecord)
+	u, roles, err := h.users.FindByUsername(r.Context(), sess.Username)
+	if err != nil || u == nil {
+		return &searchViewer{user: u, roles: roles}, err
+	}
+	groups, _ := h.users.UserGroups(r.Context(), u.ID)
+	eff := auth.CalculateEffectiveAccess(u, roles, groups, h.policy)
+	return &searchViewer{user: u, roles: eff.Roles, groups: groups, eff: eff, boards: map[int64]bool{}}, nil
+}
+
+func (h *SearchHandler) canViewByClassification(eff store.EffectiveAccess, level int, tags []string) bool {
+	if h.cfg != nil && h.cfg.Security.TagsSubsetEnforced {
+		return docs.HasClearance(docs.ClassificationLevel(eff.ClearanceLevel), eff.ClearanceTags, docs.ClassificationLevel(level), tags)
+	}
+	return eff.ClearanceLevel >= level
+}
@@ -16,6 +16,8 @@ const (
 	softwareAuditInstallArchive = "software.install.archive"
 	softwareAuditInstallRestore = "software.install.restore"
 	softwareAuditExportCSV      = "software.export.csv"
+	softwareAuditImportStart    = "software.import.start"
+	softwareAuditImportCommit   = "software.import.commit"
 )
 
 func (h *SoftwareHandler) audit(r *http.Request, action, details string) {
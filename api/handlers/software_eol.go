@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/eol"
+	"berkut-scc/core/store"
+)
+
+type SoftwareEOLHandler struct {
+	svc      *eol.Service
+	software store.SoftwareStore
+	users    store.UsersStore
+}
+
+func NewSoftwareEOLHandler(svc *eol.Service, software store.SoftwareStore, us store.UsersStore) *SoftwareEOLHandler {
+	return &SoftwareEOLHandler{svc: svc, software: software, users: us}
+}
+
+// List returns installations past or approaching end of life; ?horizon_days= overrides the configured horizon.
+func (h *SoftwareEOLHandler) List(w http.ResponseWriter, r *http.Request) {
+	horizon := parseIntDefault(r.URL.Query().Get("horizon_days"), 0)
+	if horizon < 0 || horizon > 3650 {
+		http.Error(w, "software.eol.horizonInvalid", http.StatusBadRequest)
+		return
+	}
+	items, err := h.svc.ListExposure(r.Context(), time.Now().UTC(), horizon)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if horizon == 0 {
+		horizon = h.svc.HorizonDays()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "horizon_days": horizon})
+}
+
+// Check runs the daily end-of-life check immediately.
+func (h *SoftwareEOLHandler) Check(w http.ResponseWriter, r *http.Request) {
+	user, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	res, err := h.svc.Check(r.Context(), time.Now().UTC(), user.Username, user.ID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Import applies an endoflife.date style JSON file (multipart field "file" or the raw request body)
+// to the product versions; create_versions=1 adds versions for unmatched cycles.
+func (h *SoftwareEOLHandler) Import(w http.ResponseWriter, r *http.Request) {
+	user, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	productID := parseInt64Default(pathParams(r)["id"], 0)
+	if productID <= 0 {
+		http.Error(w, "software.error.badRequest", http.StatusBadRequest)
+		return
+	}
+	p, err := h.software.GetProduct(r.Context(), productID)
+	if err != nil || p == nil || p.DeletedAt != nil {
+		http.Error(w, "software.error.notFound", http.StatusNotFound)
+		return
+	}
+	var data []byte
+	createVersions := parseBool(r.URL.Query().Get("create_versions"))
+	if strings.HasPrefix(strings.ToLower(r.Header.Get("Content-Type")), "multipart/") {
+		if err := parseMultipartFormLimited(w, r, 8<<20); err != nil {
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "software.eol.fileRequired", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(io.LimitReader(file, 8<<20))
+		if err != nil {
+			http.Error(w, "software.eol.feedInvalid", http.StatusBadRequest)
+			return
+		}
+		createVersions = createVersions || parseBool(r.FormValue("create_versions"))
+	} else {
+		data, err = io.ReadAll(io.LimitReader(r.Body, 8<<20))
+		if err != nil {
+			http.Error(w, "software.eol.feedInvalid", http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := h.svc.ImportEndOfLife(r.Context(), productID, data, createVersions, time.Now().UTC(), user.Username, user.ID)
+	if err != nil {
+		if errors.Is(err, eol.ErrFeedInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *SoftwareEOLHandler) currentUser(r *http.Request) (*store.User, error) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		return nil, errors.New("no session")
+	}
+	sess := val.(*store.SessionRecord)
+	u, _, err := h.users.FindByUsername(r.Context(), sess.Username)
+	return u, err
+}
@@ -20,10 +20,12 @@ type SoftwareHandler struct {
 	assets store.AssetsStore
 	audits store.AuditStore
 	policy *rbac.Policy
+
+	imports *userImportManager
 }
 
 func NewSoftwareHandler(ss store.SoftwareStore, us store.UsersStore, assets store.AssetsStore, audits store.AuditStore, policy *rbac.Policy) *SoftwareHandler {
-	return &SoftwareHandler{store: ss, users: us, assets: assets, audits: audits, policy: policy}
+	return &SoftwareHandler{store: ss, users: us, assets: assets, audits: audits, policy: policy, imports: newUserImportManager()}
 }
 
 type softwareProductPayload struct {
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/core/sbom"
+	"berkut-scc/core/store"
+)
+
+func (h *SoftwareHandler) ImportUpload(w http.ResponseWriter, r *http.Request) {
+	session, ok := readTableUpload(w, r, h.imports)
+	if !ok {
+		return
+	}
+	h.audit(r, softwareAuditImportStart, fmt.Sprintf("%s|%d", session.ID, len(session.Rows)))
+	writeTableUpload(w, session)
+}
+
+// ImportCommit creates or updates software products from an uploaded table. Products
+// are matched by name and normalized vendor, or by name alone when the row has no
+// vendor. The optional versions column lists versions to add to the product.
+func (h *SoftwareHandler) ImportCommit(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, session, ok := loadTableImport(w, r, h.imports, "name")
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	products, err := listAllSoftwareProducts(ctx, h.store)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	byKey := map[string]*store.SoftwareProduct{}
+	byName := map[string]*store.SoftwareProduct{}
+	index := func(p *store.SoftwareProduct) {
+		byKey[softwareImportKey(p.Name, p.Vendor)] = p
+		if _, ok := byName[softwareImportKey(p.Name, "")]; !ok {
+			byName[softwareImportKey(p.Name, "")] = p
+		}
+	}
+	for i := range products {
+		index(&products[i])
+	}
+
+	val := session.fieldReader(payload.Mapping)
+	report := tableImportReport{importReport: importReport{TotalRows: len(session.Rows)}, DryRun: payload.DryRun}
+	for i, row := range session.Rows {
+		rowNum := i + 2 // account for header row
+		name, vendor := val(row, "name"), val(row, "vendor")
+		var existing *store.SoftwareProduct
+		if vendor != "" {
+			existing = byKey[softwareImportKey(name, vendor)]
+		} else {
+			existing = byName[softwareImportKey(name, "")]
+		}
+		p := softwareProductPayload{Name: name, Vendor: vendor}
+		if existing != nil {
+			p = softwareProductPayload{Name: existing.Name, Vendor: existing.Vendor, Description: existing.Description, Tags: existing.Tags, Version: existing.Version}
+		}
+		setIfMapped(&p.Description, val(row, "description"))
+		p.Tags = mergeTags(p.Tags, importer.SplitList(val(row, "tags")))
+		next, err := p.toProduct()
+		if err != nil {
+			report.fail(rowNum, "invalid_value", err.Error())
+			continue
+		}
+		versions := importer.SplitList(val(row, "versions"))
+		next.UpdatedBy = &user.ID
+		if existing != nil {
+			next.ID = existing.ID
+			if !payload.DryRun {
+				if err := h.store.UpdateProduct(ctx, next); err != nil {
+					report.fail(rowNum, "invalid_value", "update")
+					continue
+				}
+				next.Version++
+				if err := h.ensureVersions(ctx, next.ID, versions, user.ID); err != nil {
+					report.fail(rowNum, "invalid_value", "versions")
+					continue
+				}
+			}
+			*existing = *next
+			report.UpdatedCount++
+			continue
+		}
+		next.CreatedBy = &user.ID
+		if !payload.DryRun {
+			id, err := h.store.CreateProduct(ctx, next)
+			if err != nil {
+				report.fail(rowNum, "invalid_value", "create")
+				continue
+			}
+			next.ID = id
+			next.Version = 1
+			if err := h.ensureVersions(ctx, id, versions, user.ID); err != nil {
+				report.fail(rowNum, "invalid_value", "versions")
+				continue
+			}
+		}
+		index(next)
+		report.CreatedCount++
+	}
+	report.FailedCount = len(report.Failures)
+	if !payload.DryRun {
+		h.audit(r, softwareAuditImportCommit, fmt.Sprintf("%d|%d|%d|%d", report.TotalRows, report.CreatedCount, report.UpdatedCount, report.FailedCount))
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ensureVersions adds the versions the product does not have yet.
+func (h *SoftwareHandler) ensureVersions(ctx context.Context, productID int64, versions []string, userID int64) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	existing, err := h.store.ListVersions(ctx, productID, false)
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, v := range existing {
+		known[strings.ToLower(strings.TrimSpace(v.Version))] = true
+	}
+	for _, v := range versions {
+		if known[strings.ToLower(v)] {
+			continue
+		}
+		if _, err := h.store.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: v, CreatedBy: &userID, UpdatedBy: &userID}); err != nil {
+			return err
+		}
+		known[strings.ToLower(v)] = true
+	}
+	return nil
+}
+
+func softwareImportKey(name, vendor string) string {
+	return sbom.NormalizeVendor(vendor) + "|" + strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func listAllSoftwareProducts(ctx context.Context, ss store.SoftwareStore) ([]store.SoftwareProduct, error) {
+	const page = 500
+	var out []store.SoftwareProduct
+	for offset := 0; ; offset += page {
+		items, err := ss.ListProducts(ctx, store.SoftwareFilter{Limit: page, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/core/utils"
+)
+
+// Registry imports (assets, findings, software) follow the accounts import: the upload
+// is parsed into a short-lived session and returned as a preview, the commit applies
+// a column mapping to it. A commit with dry_run validates and counts without writing
+// and keeps the session for the real commit.
+
+type tableImportCommitPayload struct {
+	ImportID string            `json:"import_id"`
+	Mapping  map[string]string `json:"mapping"`
+	DryRun   bool              `json:"dry_run"`
+}
+
+type tableImportReport struct {
+	importReport
+	DryRun bool `json:"dry_run"`
+}
+
+func (r *tableImportReport) fail(row int, reason, detail string) {
+	r.Failures = append(r.Failures, importFailure{RowNumber: row, Reason: reason, Detail: detail})
+}
+
+// readTableUpload parses the CSV or XLSX file of a multipart upload into an import session.
+func readTableUpload(w http.ResponseWriter, r *http.Request, imports *userImportManager) (*userImportSession, bool) {
+	if err := parseMultipartFormLimited(w, r, 20<<20); err != nil {
+		return nil, false
+	}
+	file, hdr, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "import.fileRequired", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 16<<20))
+	if err != nil {
+		http.Error(w, "import.formatInvalid", http.StatusBadRequest)
+		return nil, false
+	}
+	table, err := importer.ReadTable(hdr.Filename, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	id, _ := utils.RandString(12)
+	session := &userImportSession{
+		ID:         id,
+		Headers:    table.Headers,
+		Normalized: normalizeHeaders(table.Headers),
+		Rows:       table.Rows,
+		CreatedAt:  time.Now().UTC(),
+		Filename:   hdr.Filename,
+	}
+	imports.save(session)
+	return session, true
+}
+
+func writeTableUpload(w http.ResponseWriter, session *userImportSession) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"import_id":        session.ID,
+		"detected_headers": session.Headers,
+		"preview_rows":     session.preview(10),
+		"total_rows":       len(session.Rows),
+	})
+}
+
+// loadTableImport decodes a commit request and checks that the required fields are mapped.
+// The session is removed unless the request is a dry run.
+func loadTableImport(w http.ResponseWriter, r *http.Request, imports *userImportManager, required ...string) (*tableImportCommitPayload, *userImportSession, bool) {
+	var payload tableImportCommitPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	if payload.ImportID == "" {
+		http.Error(w, "import_id required", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	session, ok := imports.get(payload.ImportID)
+	if !ok || session == nil {
+		http.Error(w, "import.notFound", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if payload.Mapping == nil {
+		http.Error(w, "import.mappingRequired", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	for _, field := range required {
+		if strings.TrimSpace(payload.Mapping[field]) == "" {
+			http.Error(w, "import.mappingRequired", http.StatusBadRequest)
+			return nil, nil, false
+		}
+	}
+	if !payload.DryRun {
+		imports.delete(payload.ImportID)
+	}
+	return &payload, session, true
+}
+
+// fieldReader returns the mapped, trimmed value of a field in a row.
+func (s *userImportSession) fieldReader(mapping map[string]string) func(row []string, field string) string {
+	index := s.headerIndex()
+	return func(row []string, field string) string {
+		header := strings.ToLower(strings.TrimSpace(mapping[field]))
+		if header == "" {
+			return ""
+		}
+		idx, ok := index[header]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/vulns"
+)
+
+type VulnsHandler struct {
+	store    store.VulnsStore
+	software store.SoftwareStore
+	svc      *vulns.Service
+	users    store.UsersStore
+	audits   store.AuditStore
+	policy   *rbac.Policy
+}
+
+func NewVulnsHandler(vs store.VulnsStore, software store.SoftwareStore, svc *vulns.Service, us store.UsersStore, audits store.AuditStore, policy *rbac.Policy) *VulnsHandler {
+	return &VulnsHandler{store: vs, software: software, svc: svc, users: us, audits: audits, policy: policy}
+}
+
+func (h *VulnsHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	items, err := h.store.ListVulnerabilities(r.Context(), store.VulnerabilityFilter{
+		Search:   q.Get("q"),
+		Severity: q.Get("severity"),
+		Source:   q.Get("source"),
+		Limit:    parseIntDefault(q.Get("limit"), 0),
+		Offset:   parseIntDefault(q.Get("offset"), 0),
+	})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *VulnsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	item, err := h.store.GetVulnerability(r.Context(), id)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	matches, err := h.store.ListMatches(r.Context(), store.VulnerabilityMatchFilter{VulnerabilityID: id, Status: r.URL.Query().Get("status")})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"vulnerability": item, "matches": matches})
+}
+
+// Import accepts an offline feed file (multipart field "file"; optional "format": nvd, osv or bdu,
+// detected from the content when omitted). Gzip-compressed files and OSV zip dumps are accepted.
+func (h *VulnsHandler) Import(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := parseMultipartFormLimited(w, r, 128<<20); err != nil {
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "vulns.import.fileRequired", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, 128<<20))
+	if err != nil {
+		http.Error(w, "vulns.import.feedInvalid", http.StatusBadRequest)
+		return
+	}
+	res, err := h.svc.Import(r.Context(), strings.ToLower(strings.TrimSpace(r.FormValue("format"))), data, user.Username, user.ID)
+	if err != nil {
+		if errors.Is(err, vulns.ErrFormatInvalid) || errors.Is(err, vulns.ErrFeedInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Scan rematches installed software against the catalog; ?asset_id= limits it to one asset.
+func (h *VulnsHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	res, err := h.svc.Scan(r.Context(), parseInt64Default(r.URL.Query().Get("asset_id"), 0), user.Username, user.ID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *VulnsHandler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	items, err := h.store.ListVulnerableAssets(r.Context())
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *VulnsHandler) ListAssetMatches(w http.ResponseWriter, r *http.Request) {
+	assetID := parseInt64Default(pathParams(r)["id"], 0)
+	if assetID <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = store.VulnMatchOpen
+	} else if status == "all" {
+		status = ""
+	}
+	items, err := h.store.ListMatches(r.Context(), store.VulnerabilityMatchFilter{AssetID: assetID, Status: status})
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *VulnsHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	productID, ok := h.existingProductID(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.store.ListProductAliases(r.Context(), productID)
+	if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+type productAliasPayload struct {
+	Vendor  string `json:"vendor"`
+	Product string `json:"product"`
+	CPE     string `json:"cpe"`
+}
+
+// AddAlias maps a product to feed naming, either as vendor/product or as a CPE name.
+func (h *VulnsHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	user, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	productID, ok := h.existingProductID(w, r)
+	if !ok {
+		return
+	}
+	var payload productAliasPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	alias := &store.SoftwareProductAlias{
+		ProductID: productID,
+		Vendor:    vulns.NormalizeName(payload.Vendor),
+		Product:   vulns.NormalizeName(payload.Product),
+		CreatedBy: &user.ID,
+	}
+	if cpeRaw := strings.TrimSpace(payload.CPE); cpeRaw != "" {
+		cpe, ok := vulns.ParseCPE(cpeRaw)
+		if !ok {
+			http.Error(w, "vulns.alias.cpeInvalid", http.StatusBadRequest)
+			return
+		}
+		alias.CPE = cpeRaw
+		alias.Vendor = vulns.NormalizeName(cpe.Vendor)
+		alias.Product = vulns.NormalizeName(cpe.Product)
+	}
+	if alias.Product == "" || len(alias.Product) > 200 || len(alias.Vendor) > 200 {
+		http.Error(w, "vulns.alias.productRequired", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.store.AddProductAlias(r.Context(), alias); err != nil {
+		http.Error(w, "vulns.alias.duplicate", http.StatusConflict)
+		return
+	}
+	h.audit(r, vulnsAuditAliasAdd, fmt.Sprintf("%d|%s:%s", productID, alias.Vendor, alias.Product))
+	writeJSON(w, http.StatusCreated, alias)
+}
+
+func (h *VulnsHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	productID, ok := h.existingProductID(w, r)
+	if !ok {
+		return
+	}
+	aliasID := parseInt64Default(pathParams(r)["alias_id"], 0)
+	if aliasID <= 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.DeleteProductAlias(r.Context(), productID, aliasID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, vulnsAuditAliasDelete, fmt.Sprintf("%d|%d", productID, aliasID))
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *VulnsHandler) existingProductID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id := parseInt64Default(pathParams(r)["id"], 0)
+	if id <= 0 {
+		http.Error(w, "software.error.badRequest", http.StatusBadRequest)
+		return 0, false
+	}
+	p, err := h.software.GetProduct(r.Context(), id)
+	if err != nil || p == nil || p.DeletedAt != nil {
+		http.Error(w, "software.error.notFound", http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+const (
+	vulnsAuditAliasAdd    = "vulns.alias.add"
+	vulnsAuditAliasDelete = "vulns.alias.delete"
+)
+
+func (h *VulnsHandler) audit(r *http.Request, action, details string) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), currentUsername(r), action, details)
+}
+
+func (h *VulnsHandler) currentUser(r *http.Request) (*store.User, []string, error) {
+	val := r.Context().Value(auth.SessionContextKey)
+	if val == nil {
+		return nil, nil, errors.New("no session")
+	}
+	sess := val.(*store.SessionRecord)
+	u, _, err := h.users.FindByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, sess.Roles, nil
+}
@@ -240,6 +240,11 @@ func (r *statusRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach Flush and SetWriteDeadline for streaming responses.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func (s *Server) withSession(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		cookie, err := r.Cookie(sessionCookie)
@@ -596,6 +601,9 @@ func (s *Server) requiredMenuKeys(path string) []string {
 	case strings.HasPrefix(p, "/findings") || strings.HasPrefix(p, "/api/findings"):
 		// Findings/Observations registry is part of "Registries" UX (controls tab), but can also be granted directly.
 		return []string{"controls", "findings"}
+	case strings.HasPrefix(p, "/risks") || strings.HasPrefix(p, "/api/risks"):
+		// Risk register lives in the "Registries" UX as well and can be granted directly.
+		return []string{"controls", "risks"}
 	case strings.HasPrefix(p, "/accounts") || strings.HasPrefix(p, "/api/accounts"):
 		return []string{"accounts"}
 	case strings.HasPrefix(p, "/settings") || strings.HasPrefix(p, "/api/settings"):
@@ -11,14 +11,31 @@ func RegisterAssets(apiRouter chi.Router, g Guards, assets *handlers.AssetsHandl
 		assetsRouter.MethodFunc("GET", "/list", g.SessionPerm("assets.view", assets.ListLite))
 		assetsRouter.MethodFunc("GET", "/export.csv", g.SessionPerm("assets.view", assets.ExportCSV))
 		assetsRouter.MethodFunc("GET", "/autocomplete", g.SessionPerm("assets.view", assets.Autocomplete))
+		assetsRouter.MethodFunc("GET", "/blast-radius", g.SessionPerm("assets.view", assets.BlastRadius))
 		assetsRouter.MethodFunc("POST", "/", g.SessionPerm("assets.manage", assets.Create))
+		assetsRouter.MethodFunc("POST", "/import/upload", g.SessionPerm("assets.manage", assets.ImportUpload))
+		assetsRouter.MethodFunc("POST", "/import/commit", g.SessionPerm("assets.manage", assets.ImportCommit))
+		assetsRouter.MethodFunc("POST", "/import/nmap", g.SessionPerm("assets.manage", assets.ImportNmap))
 		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}", g.SessionPerm("assets.view", assets.Get))
 		assetsRouter.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionPerm("assets.manage", assets.Update))
 		assetsRouter.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionPerm("assets.manage", assets.Archive))
 		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/restore", g.SessionPerm("assets.manage", assets.Restore))
 
+		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/observables", g.SessionPerm("assets.view", assets.ListObservables))
+		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/observables", g.SessionPerm("assets.manage", assets.AddObservable))
+		assetsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/observables/{obs_id:[0-9]+}", g.SessionPerm("assets.manage", assets.DeleteObservable))
+
+		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/relations", g.SessionPerm("assets.view", assets.ListRelations))
+		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/relations", g.SessionPerm("assets.manage", assets.AddRelation))
+		assetsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/relations/{relation_id:[0-9]+}", g.SessionPerm("assets.manage", assets.DeleteRelation))
+		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/graph", g.SessionPerm("assets.view", assets.Graph))
+
+		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/history", g.SessionPerm("assets.view", assets.History))
+		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/as-of", g.SessionPerm("assets.view", assets.AsOf))
+
 		assetsRouter.MethodFunc("GET", "/{id:[0-9]+}/software", g.SessionPerm("assets.view", assets.ListSoftware))
 		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/software", g.SessionPerm("assets.manage", assets.AddSoftware))
+		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/software/sbom", g.SessionPerm("assets.manage", assets.ImportSBOM))
 		assetsRouter.MethodFunc("PUT", "/{id:[0-9]+}/software/{inst_id:[0-9]+}", g.SessionPerm("assets.manage", assets.UpdateSoftware))
 		assetsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/software/{inst_id:[0-9]+}", g.SessionPerm("assets.manage", assets.ArchiveSoftware))
 		assetsRouter.MethodFunc("POST", "/{id:[0-9]+}/software/{inst_id:[0-9]+}/restore", g.SessionPerm("assets.manage", assets.RestoreSoftware))
@@ -0,0 +1,16 @@
+package routegroups
+
+import (
+	"berkut-scc/api/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterCustomFields(apiRouter chi.Router, g Guards, fields *handlers.CustomFieldsHandler) {
+	apiRouter.Route("/custom-fields", func(fieldsRouter chi.Router) {
+		fieldsRouter.MethodFunc("GET", "/", g.SessionAnyPerm([]string{"incidents.view", "assets.view", "findings.view", "tasks.view"}, fields.List))
+		fieldsRouter.MethodFunc("GET", "/all", g.SessionPerm("settings.custom_fields", fields.ListAll))
+		fieldsRouter.MethodFunc("POST", "/", g.SessionPerm("settings.custom_fields", fields.Create))
+		fieldsRouter.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionPerm("settings.custom_fields", fields.Update))
+		fieldsRouter.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionPerm("settings.custom_fields", fields.Archive))
+	})
+}
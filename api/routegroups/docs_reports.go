@@ -75,6 +75,12 @@ func RegisterReports(apiRouter chi.Router, g Guards, reports *handlers.ReportsHa
 		reportsRouter.MethodFunc("DELETE", "/templates/{id:[0-9]+}", g.SessionPerm("reports.templates.manage", reports.DeleteTemplate))
 		reportsRouter.MethodFunc("POST", "/from-template", g.SessionPerm("reports.create", reports.CreateFromTemplate))
 		reportsRouter.MethodFunc("POST", "/from-incident", g.SessionPerm("reports.create", reports.CreateFromIncident))
+		reportsRouter.MethodFunc("GET", "/schedules", g.SessionPerm("reports.view", reports.ListSchedules))
+		reportsRouter.MethodFunc("POST", "/schedules", g.SessionPerm("reports.edit", reports.CreateSchedule))
+		reportsRouter.MethodFunc("PUT", "/schedules/{id:[0-9]+}", g.SessionPerm("reports.edit", reports.UpdateSchedule))
+		reportsRouter.MethodFunc("DELETE", "/schedules/{id:[0-9]+}", g.SessionPerm("reports.edit", reports.DeleteSchedule))
+		reportsRouter.MethodFunc("POST", "/schedules/{id:[0-9]+}/run", g.SessionPerm("reports.edit", reports.RunSchedule))
+		reportsRouter.MethodFunc("GET", "/schedules/{id:[0-9]+}/runs", g.SessionPerm("reports.view", reports.ListScheduleRuns))
 		reportsRouter.MethodFunc("GET", "/settings", g.SessionPerm("reports.templates.manage", reports.GetSettings))
 		reportsRouter.MethodFunc("PUT", "/settings", g.SessionPerm("reports.templates.manage", reports.UpdateSettings))
 	})
@@ -5,20 +5,43 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func RegisterFindings(apiRouter chi.Router, g Guards, findings *handlers.FindingsHandler) {
+func RegisterFindings(apiRouter chi.Router, g Guards, findings *handlers.FindingsHandler, vulns *handlers.VulnsHandler) {
 	apiRouter.Route("/findings", func(findingsRouter chi.Router) {
 		findingsRouter.MethodFunc("GET", "/", g.SessionPerm("findings.view", findings.List))
 		findingsRouter.MethodFunc("GET", "/list", g.SessionPerm("findings.view", findings.ListLite))
 		findingsRouter.MethodFunc("GET", "/export.csv", g.SessionPerm("findings.view", findings.ExportCSV))
 		findingsRouter.MethodFunc("GET", "/autocomplete", g.SessionPerm("findings.view", findings.Autocomplete))
 		findingsRouter.MethodFunc("POST", "/", g.SessionPerm("findings.manage", findings.Create))
+		findingsRouter.MethodFunc("POST", "/import/upload", g.SessionPerm("findings.manage", findings.ImportUpload))
+		findingsRouter.MethodFunc("POST", "/import/commit", g.SessionPerm("findings.manage", findings.ImportCommit))
+		findingsRouter.MethodFunc("POST", "/import/scan", g.SessionPerm("findings.manage", findings.ImportScan))
+		findingsRouter.MethodFunc("GET", "/sla/policy", g.SessionPerm("findings.view", findings.GetSLAPolicy))
+		findingsRouter.MethodFunc("PUT", "/sla/policy", g.SessionPerm("findings.manage", findings.UpdateSLAPolicy))
+		findingsRouter.MethodFunc("POST", "/sla/evaluate", g.SessionPerm("findings.manage", findings.EvaluateSLA))
+		findingsRouter.MethodFunc("GET", "/exceptions/pending", g.SessionPerm("findings.view", findings.ListPendingExceptions))
+		findingsRouter.MethodFunc("POST", "/exceptions/{exception_id:[0-9]+}/approve", g.SessionPerm("findings.view", findings.ApproveException))
+		findingsRouter.MethodFunc("POST", "/exceptions/{exception_id:[0-9]+}/reject", g.SessionPerm("findings.view", findings.RejectException))
 		findingsRouter.MethodFunc("GET", "/{id:[0-9]+}", g.SessionPerm("findings.view", findings.Get))
 		findingsRouter.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionPerm("findings.manage", findings.Update))
 		findingsRouter.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionPerm("findings.manage", findings.Archive))
 		findingsRouter.MethodFunc("POST", "/{id:[0-9]+}/restore", g.SessionPerm("findings.manage", findings.Restore))
 
+		findingsRouter.MethodFunc("GET", "/{id:[0-9]+}/observables", g.SessionPerm("findings.view", findings.ListObservables))
+		findingsRouter.MethodFunc("POST", "/{id:[0-9]+}/observables", g.SessionPerm("findings.manage", findings.AddObservable))
+		findingsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/observables/{obs_id:[0-9]+}", g.SessionPerm("findings.manage", findings.DeleteObservable))
 		findingsRouter.MethodFunc("GET", "/{id:[0-9]+}/links", g.SessionPerm("findings.view", findings.ListLinks))
 		findingsRouter.MethodFunc("POST", "/{id:[0-9]+}/links", g.SessionPerm("findings.manage", findings.AddLink))
 		findingsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/links/{link_id:[0-9]+}", g.SessionPerm("findings.manage", findings.DeleteLink))
+		findingsRouter.MethodFunc("GET", "/{id:[0-9]+}/exceptions", g.SessionPerm("findings.view", findings.ListExceptions))
+		findingsRouter.MethodFunc("POST", "/{id:[0-9]+}/exceptions", g.SessionPerm("findings.manage", findings.RequestException))
+		findingsRouter.MethodFunc("DELETE", "/{id:[0-9]+}/exceptions/{exception_id:[0-9]+}", g.SessionPerm("findings.manage", findings.RevokeException))
+	})
+	apiRouter.Route("/vulnerabilities", func(r chi.Router) {
+		r.MethodFunc("GET", "/", g.SessionPerm("findings.view", vulns.List))
+		r.MethodFunc("GET", "/assets", g.SessionPerm("findings.view", vulns.ListAssets))
+		r.MethodFunc("GET", "/assets/{id:[0-9]+}", g.SessionPerm("findings.view", vulns.ListAssetMatches))
+		r.MethodFunc("POST", "/import", g.SessionPerm("findings.manage", vulns.Import))
+		r.MethodFunc("POST", "/scan", g.SessionPerm("findings.manage", vulns.Scan))
+		r.MethodFunc("GET", "/{id:[0-9]+}", g.SessionPerm("findings.view", vulns.Get))
 	})
 }
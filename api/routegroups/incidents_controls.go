@@ -9,6 +9,7 @@ func RegisterIncidents(apiRouter chi.Router, g Guards, incidents *handlers.Incid
 	apiRouter.Route("/incidents", func(incidentsRouter chi.Router) {
 		incidentsRouter.MethodFunc("GET", "/dashboard", g.SessionPerm("incidents.view", incidents.Dashboard))
 		incidentsRouter.MethodFunc("GET", "/list", g.SessionPerm("incidents.view", incidents.ListIncidentsLite))
+		incidentsRouter.MethodFunc("GET", "/metrics", g.SessionPerm("incidents.view", incidents.Metrics))
 		incidentsRouter.MethodFunc("GET", "/", g.SessionPerm("incidents.view", incidents.List))
 		incidentsRouter.MethodFunc("POST", "/cleanup", g.SessionPerm("settings.advanced", incidents.Cleanup))
 		incidentsRouter.MethodFunc("POST", "/", g.SessionPerm("incidents.create", incidents.Create))
@@ -19,6 +20,15 @@ func RegisterIncidents(apiRouter chi.Router, g Guards, incidents *handlers.Incid
 		incidentsRouter.MethodFunc("GET", "/{id}/links", g.SessionPerm("incidents.view", incidents.ListLinks))
 		incidentsRouter.MethodFunc("POST", "/{id}/links", g.SessionPerm("incidents.edit", incidents.AddLink))
 		incidentsRouter.MethodFunc("DELETE", "/{id}/links/{link_id}", g.SessionPerm("incidents.edit", incidents.DeleteLink))
+		incidentsRouter.MethodFunc("GET", "/{id}/impact", g.SessionPerm("incidents.view", incidents.GetImpact))
+		incidentsRouter.MethodFunc("PUT", "/{id}/impact", g.SessionPerm("incidents.edit", incidents.UpdateImpact))
+		incidentsRouter.MethodFunc("GET", "/{id}/observables", g.SessionPerm("incidents.view", incidents.ListObservables))
+		incidentsRouter.MethodFunc("POST", "/{id}/observables", g.SessionPerm("incidents.edit", incidents.AddObservable))
+		incidentsRouter.MethodFunc("GET", "/{id}/observables/correlations", g.SessionPerm("incidents.view", incidents.CorrelateObservables))
+		incidentsRouter.MethodFunc("GET", "/{id}/observables/export", g.SessionPerm("incidents.export", incidents.ExportObservables))
+		incidentsRouter.MethodFunc("POST", "/{id}/observables/import", g.SessionPerm("incidents.edit", incidents.ImportObservables))
+		incidentsRouter.MethodFunc("PUT", "/{id}/observables/{obs_id:[0-9]+}", g.SessionPerm("incidents.edit", incidents.UpdateObservable))
+		incidentsRouter.MethodFunc("DELETE", "/{id}/observables/{obs_id:[0-9]+}", g.SessionPerm("incidents.edit", incidents.DeleteObservable))
 		incidentsRouter.MethodFunc("GET", "/{id}/control-links", g.SessionPerm("incidents.view", incidents.ListControlLinks))
 		incidentsRouter.MethodFunc("GET", "/{id}/attachments", g.SessionPerm("incidents.view", incidents.ListAttachments))
 		incidentsRouter.MethodFunc("POST", "/{id}/attachments/upload", g.SessionPerm("incidents.edit", incidents.UploadAttachment))
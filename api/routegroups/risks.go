@@ -0,0 +1,27 @@
+package routegroups
+
+import (
+	"berkut-scc/api/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterRisks(apiRouter chi.Router, g Guards, risks *handlers.RisksHandler) {
+	apiRouter.Route("/risks", func(risksRouter chi.Router) {
+		risksRouter.MethodFunc("GET", "/", g.SessionPerm("risks.view", risks.List))
+		risksRouter.MethodFunc("POST", "/", g.SessionPerm("risks.manage", risks.Create))
+		risksRouter.MethodFunc("GET", "/matrix", g.SessionPerm("risks.view", risks.GetMatrix))
+		risksRouter.MethodFunc("PUT", "/matrix", g.SessionPerm("risks.manage", risks.UpdateMatrix))
+		risksRouter.MethodFunc("GET", "/heatmap", g.SessionPerm("risks.view", risks.Heatmap))
+		risksRouter.MethodFunc("GET", "/{id:[0-9]+}", g.SessionPerm("risks.view", risks.Get))
+		risksRouter.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionPerm("risks.manage", risks.Update))
+		risksRouter.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionPerm("risks.manage", risks.Archive))
+		risksRouter.MethodFunc("POST", "/{id:[0-9]+}/restore", g.SessionPerm("risks.manage", risks.Restore))
+		risksRouter.MethodFunc("POST", "/{id:[0-9]+}/accept", g.SessionPerm("risks.accept", risks.Accept))
+		risksRouter.MethodFunc("DELETE", "/{id:[0-9]+}/accept", g.SessionPerm("risks.accept", risks.RevokeAcceptance))
+
+		risksRouter.MethodFunc("GET", "/{id:[0-9]+}/links", g.SessionPerm("risks.view", risks.ListLinks))
+		risksRouter.MethodFunc("POST", "/{id:[0-9]+}/links", g.SessionPerm("risks.manage", risks.AddLink))
+		risksRouter.MethodFunc("DELETE", "/{id:[0-9]+}/links/{link_id:[0-9]+}", g.SessionPerm("risks.manage", risks.DeleteLink))
+		risksRouter.MethodFunc("POST", "/{id:[0-9]+}/tasks", g.SessionPerm("risks.manage", risks.CreateTask))
+	})
+}
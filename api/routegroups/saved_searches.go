@@ -0,0 +1,17 @@
+package routegroups
+
+import (
+	"berkut-scc/api/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterSavedSearches(apiRouter chi.Router, g Guards, searches *handlers.SavedSearchesHandler) {
+	perms := []string{"incidents.view", "findings.view", "assets.view", "tasks.view", "docs.view"}
+	apiRouter.Route("/saved-searches", func(searchesRouter chi.Router) {
+		searchesRouter.MethodFunc("GET", "/", g.SessionAnyPerm(perms, searches.List))
+		searchesRouter.MethodFunc("GET", "/groups", g.SessionAnyPerm(perms, searches.Groups))
+		searchesRouter.MethodFunc("POST", "/", g.SessionAnyPerm(perms, searches.Create))
+		searchesRouter.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionAnyPerm(perms, searches.Update))
+		searchesRouter.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionAnyPerm(perms, searches.Delete))
+	})
+}
@@ -0,0 +1,19 @@
+package routegroups
+
+import (
+	"berkut-scc/api/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterSchedule(apiRouter chi.Router, g Guards, sched *handlers.ScheduleHandler) {
+	consumers := []string{"settings.calendars", "tasks.recurring.view", "backups.read", "monitoring.maintenance.view", "findings.view"}
+	apiRouter.Route("/schedule", func(scheduleRouter chi.Router) {
+		scheduleRouter.MethodFunc("POST", "/preview", g.SessionAnyPerm(consumers, sched.Preview))
+		scheduleRouter.MethodFunc("GET", "/calendars", g.SessionAnyPerm(consumers, sched.ListCalendars))
+		scheduleRouter.MethodFunc("POST", "/calendars", g.SessionPerm("settings.calendars", sched.CreateCalendar))
+		scheduleRouter.MethodFunc("GET", "/calendars/{id:[0-9]+}", g.SessionPerm("settings.calendars", sched.GetCalendar))
+		scheduleRouter.MethodFunc("PUT", "/calendars/{id:[0-9]+}", g.SessionPerm("settings.calendars", sched.UpdateCalendar))
+		scheduleRouter.MethodFunc("DELETE", "/calendars/{id:[0-9]+}", g.SessionPerm("settings.calendars", sched.DeleteCalendar))
+		scheduleRouter.MethodFunc("POST", "/calendars/{id:[0-9]+}/import", g.SessionPerm("settings.calendars", sched.ImportCalendar))
+	})
+}
@@ -0,0 +1,11 @@
+package routegroups
+
+import (
+	"berkut-scc/api/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+func RegisterSearch(apiRouter chi.Router, g Guards, search *handlers.SearchHandler) {
+	perms := []string{"docs.view", "incidents.view", "tasks.view", "findings.view", "assets.view", "software.view", "controls.view", "monitoring.view"}
+	apiRouter.MethodFunc("GET", "/search", g.SessionAnyPerm(perms, search.Search))
+}
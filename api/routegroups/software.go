@@ -5,13 +5,17 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func RegisterSoftware(apiRouter chi.Router, g Guards, software *handlers.SoftwareHandler) {
+func RegisterSoftware(apiRouter chi.Router, g Guards, software *handlers.SoftwareHandler, vulns *handlers.VulnsHandler, eol *handlers.SoftwareEOLHandler) {
 	apiRouter.Route("/software", func(r chi.Router) {
 		r.MethodFunc("GET", "/", g.SessionPerm("software.view", software.List))
 		r.MethodFunc("GET", "/list", g.SessionPerm("software.view", software.ListLite))
 		r.MethodFunc("GET", "/export.csv", g.SessionPerm("software.view", software.ExportCSV))
 		r.MethodFunc("GET", "/autocomplete", g.SessionPerm("software.view", software.Autocomplete))
+		r.MethodFunc("GET", "/eol", g.SessionPerm("software.view", eol.List))
+		r.MethodFunc("POST", "/eol/check", g.SessionPerm("software.manage", eol.Check))
 		r.MethodFunc("POST", "/", g.SessionPerm("software.manage", software.Create))
+		r.MethodFunc("POST", "/import/upload", g.SessionPerm("software.manage", software.ImportUpload))
+		r.MethodFunc("POST", "/import/commit", g.SessionPerm("software.manage", software.ImportCommit))
 		r.MethodFunc("GET", "/{id:[0-9]+}", g.SessionPerm("software.view", software.Get))
 		r.MethodFunc("PUT", "/{id:[0-9]+}", g.SessionPerm("software.manage", software.Update))
 		r.MethodFunc("DELETE", "/{id:[0-9]+}", g.SessionPerm("software.manage", software.Archive))
@@ -24,5 +28,10 @@ func RegisterSoftware(apiRouter chi.Router, g Guards, software *handlers.Softwar
 		r.MethodFunc("POST", "/{id:[0-9]+}/versions/{version_id:[0-9]+}/restore", g.SessionPerm("software.manage", software.RestoreVersion))
 
 		r.MethodFunc("GET", "/{id:[0-9]+}/assets", g.SessionPerm("software.view", software.ListProductAssets))
+		r.MethodFunc("POST", "/{id:[0-9]+}/eol/import", g.SessionPerm("software.manage", eol.Import))
+
+		r.MethodFunc("GET", "/{id:[0-9]+}/aliases", g.SessionPerm("software.view", vulns.ListAliases))
+		r.MethodFunc("POST", "/{id:[0-9]+}/aliases", g.SessionPerm("software.manage", vulns.AddAlias))
+		r.MethodFunc("DELETE", "/{id:[0-9]+}/aliases/{alias_id:[0-9]+}", g.SessionPerm("software.manage", vulns.DeleteAlias))
 	})
 }
@@ -37,6 +37,11 @@ func (s *Server) registerRoutes() {
 	s.registerIncidentsRoutes(apiRouter, h)
 	s.registerControlsRoutes(apiRouter, h)
 	s.registerFindingsRoutes(apiRouter, h)
+	s.registerRisksRoutes(apiRouter, h)
+	s.registerCustomFieldsRoutes(apiRouter, h)
+	s.registerSavedSearchesRoutes(apiRouter, h)
+	s.registerSearchRoutes(apiRouter, h)
+	s.registerScheduleRoutes(apiRouter, h)
 	s.registerAssetsRoutes(apiRouter, h)
 	s.registerSoftwareRoutes(apiRouter, h)
 	s.registerMonitoringRoutes(apiRouter, h)
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"berkut-scc/api/routegroups"
+	"berkut-scc/core/rbac"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *Server) registerCustomFieldsRoutes(apiRouter chi.Router, h routeHandlers) {
+	routegroups.RegisterCustomFields(apiRouter, routegroups.Guards{
+		WithSession:       s.withSession,
+		RequirePermission: func(p string) func(http.HandlerFunc) http.HandlerFunc { return s.requirePermission(rbac.Permission(p)) },
+	}, h.fields)
+}
@@ -12,5 +12,5 @@ func (s *Server) registerFindingsRoutes(apiRouter chi.Router, h routeHandlers) {
 	routegroups.RegisterFindings(apiRouter, routegroups.Guards{
 		WithSession:       s.withSession,
 		RequirePermission: func(p string) func(http.HandlerFunc) http.HandlerFunc { return s.requirePermission(rbac.Permission(p)) },
-	}, h.findings)
+	}, h.findings, h.vulns)
 }
@@ -23,15 +23,23 @@ type routeHandlers struct {
 	controls    *handlers.ControlsHandler
 	assets      *handlers.AssetsHandler
 	findings    *handlers.FindingsHandler
+	risks       *handlers.RisksHandler
+	fields      *handlers.CustomFieldsHandler
+	searches    *handlers.SavedSearchesHandler
+	search      *handlers.SearchHandler
+	schedule    *handlers.ScheduleHandler
 	software    *handlers.SoftwareHandler
+	vulns       *handlers.VulnsHandler
+	eol         *handlers.SoftwareEOLHandler
 	logs        *handlers.LogsHandler
 	monitoring  *handlers.MonitoringHandler
+	notify      *handlers.NotificationsHandler
 }
 
 func (s *Server) newRouteHandlers() routeHandlers {
 	twoFA := store.NewAuth2FAStore(s.db)
 	passkeys := store.NewPasskeysStore(s.db)
-	return routeHandlers{
+	hs := routeHandlers{
 		auth:        handlers.NewAuthHandler(s.cfg, s.users, s.sessions, s.incidentsStore, twoFA, passkeys, s.sessionManager, s.policy, s.audits, s.logger),
 		accounts:    handlers.NewAccountsHandler(s.users, s.groups, s.roles, s.sessions, twoFA, s.policy, s.sessionManager, s.cfg, s.audits, s.logger, s.refreshPolicy),
 		dashboard:   handlers.NewDashboardHandler(s.cfg, s.dashboardStore, s.users, s.docsStore, s.incidentsStore, s.docsSvc, s.incidentsSvc, s.tasksStore, s.audits, s.policy, s.logger),
@@ -44,13 +52,44 @@ func (s *Server) newRouteHandlers() routeHandlers {
 		jobs:        handlers.NewAppJobsHandler(s.appJobs, s.policy),
 		hardening:   handlers.NewHardeningHandler(s.cfg, s.appHTTPSStore, s.appRuntimeStore, s.behaviorRiskStore, s.users, s.audits),
 		docs:        handlers.NewDocsHandler(s.cfg, s.docsStore, s.entityLinksStore, s.controlsStore, s.assetsStore, s.softwareStore, s.users, s.policy, s.docsSvc, s.audits, s.logger),
-		reports:     handlers.NewReportsHandler(s.cfg, s.docsStore, s.reportsStore, s.users, s.policy, s.docsSvc, s.incidentsStore, s.incidentsSvc, s.controlsStore, s.monitoringStore, s.tasksSvc, s.audits, s.logger),
-		incidents:   handlers.NewIncidentsHandler(s.cfg, s.incidentsStore, s.entityLinksStore, s.controlsStore, s.assetsStore, s.softwareStore, s.users, s.docsStore, s.policy, s.incidentsSvc, s.docsSvc, s.audits, s.logger),
+		reports:     s.reports,
+		incidents:   handlers.NewIncidentsHandler(s.cfg, s.incidentsStore, s.entityLinksStore, s.controlsStore, s.assetsStore, s.softwareStore, s.findingsStore, s.observablesStore, s.users, s.docsStore, s.policy, s.incidentsSvc, s.docsSvc, s.audits, s.logger),
 		controls:    handlers.NewControlsHandler(s.controlsStore, s.entityLinksStore, s.users, s.docsStore, s.incidentsStore, s.tasksStore, s.assetsStore, s.softwareStore, s.audits, s.policy, s.logger),
-		assets:      handlers.NewAssetsHandler(s.assetsStore, s.softwareStore, s.users, s.audits, s.policy),
-		findings:    handlers.NewFindingsHandler(s.findingsStore, s.entityLinksStore, s.users, s.assetsStore, s.controlsStore, s.softwareStore, s.audits, s.policy),
+		assets:      handlers.NewAssetsHandler(s.assetsStore, s.softwareStore, s.observablesStore, s.vulnsSvc, s.users, s.audits, s.policy),
+		findings:    handlers.NewFindingsHandler(s.findingsStore, s.entityLinksStore, s.users, s.assetsStore, s.controlsStore, s.softwareStore, s.observablesStore, s.audits, s.policy),
+		risks:       handlers.NewRisksHandler(s.risksStore, s.entityLinksStore, s.users, s.assetsStore, s.controlsStore, s.findingsStore, s.vulnsStore, s.tasksStore, s.audits, s.policy),
+		fields:      handlers.NewCustomFieldsHandler(store.NewCustomFieldsStore(s.db), s.audits, s.policy),
+		searches:    handlers.NewSavedSearchesHandler(s.savedSearches, s.queriesSvc, s.users, s.audits, s.policy),
+		search:      handlers.NewSearchHandler(s.cfg, s.searchStore, s.users, s.docsStore, s.docsSvc, s.incidentsStore, s.incidentsSvc, s.tasksStore, s.policy),
+		schedule:    handlers.NewScheduleHandler(s.scheduleCalendars, s.audits),
 		software:    handlers.NewSoftwareHandler(s.softwareStore, s.users, s.assetsStore, s.audits, s.policy),
+		vulns:       handlers.NewVulnsHandler(s.vulnsStore, s.softwareStore, s.vulnsSvc, s.users, s.audits, s.policy),
+		eol:         handlers.NewSoftwareEOLHandler(s.eolSvc, s.softwareStore, s.users),
 		logs:        handlers.NewLogsHandler(s.audits),
 		monitoring:  handlers.NewMonitoringHandler(s.monitoringStore, s.users, s.audits, s.monitoringEngine, s.policy, s.incidentsSvc.Encryptor()),
+		notify:      handlers.NewNotificationsHandler(s.notifySvc, s.monitoringStore, s.users, s.audits, s.policy),
 	}
+	hs.controls.SetNotifier(s.notifySvc)
+	hs.findings.SetSLA(s.findingsSvc, s.findingSLAStore)
+	hs.assets.SetGraph(s.cfg, store.NewAssetRelationsStore(s.db), s.monitoringStore, s.incidentsStore, s.incidentsSvc, s.entityLinksStore, s.controlsStore)
+	hs.assets.SetHistory(store.NewAssetHistoryStore(s.db))
+	hs.assets.SetCustomFields(s.customFieldsSvc)
+	hs.findings.SetCustomFields(s.customFieldsSvc)
+	hs.incidents.SetCustomFields(s.customFieldsSvc)
+	hs.assets.SetQueries(s.queriesSvc)
+	hs.findings.SetQueries(s.queriesSvc)
+	hs.incidents.SetQueries(s.queriesSvc)
+	hs.docs.SetQueries(s.queriesSvc)
+	hs.dashboard.SetSavedSearches(s.savedSearches)
+	return hs
+}
+
+// newReportsHandler builds the reports handler shared by the routes and the report
+// scheduler.
+func (s *Server) newReportsHandler() *handlers.ReportsHandler {
+	h := handlers.NewReportsHandler(s.cfg, s.docsStore, s.reportsStore, s.users, s.policy, s.docsSvc, s.incidentsStore, s.incidentsSvc, s.controlsStore, s.monitoringStore, s.tasksSvc, s.eolSvc, s.risksStore, s.findingsStore, s.audits, s.logger)
+	h.SetCustomFields(s.customFieldsSvc)
+	h.SetQueries(s.queriesSvc)
+	h.SetInventory(s.assetsStore, s.softwareStore)
+	return h
 }
@@ -53,6 +53,14 @@ func (s *Server) registerCoreAPIRoutes(apiRouter chi.Router, h routeHandlers) {
 	apiRouter.MethodFunc("GET", "/app/jobs", s.withSession(s.requirePermission("app.compat.view")(h.jobs.List)))
 	apiRouter.MethodFunc("GET", "/app/jobs/{id}", s.withSession(s.requirePermission("app.compat.view")(h.jobs.Get)))
 	apiRouter.MethodFunc("POST", "/app/jobs/{id}/cancel", s.withSession(s.requirePermission("app.compat.manage.partial")(h.jobs.Cancel)))
+	apiRouter.MethodFunc("GET", "/notifications", s.withSession(s.requirePermission("app.view")(h.notify.List)))
+	apiRouter.MethodFunc("GET", "/notifications/stream", s.withSession(s.requirePermission("app.view")(h.notify.Stream)))
+	apiRouter.MethodFunc("POST", "/notifications/read", s.withSession(s.requirePermission("app.view")(h.notify.MarkRead)))
+	apiRouter.MethodFunc("POST", "/notifications/dismiss", s.withSession(s.requirePermission("app.view")(h.notify.Dismiss)))
+	apiRouter.MethodFunc("GET", "/notifications/mentions", s.withSession(s.requirePermission("app.view")(h.notify.Mentions)))
+	apiRouter.MethodFunc("GET", "/notifications/mentions/users", s.withSession(s.requirePermission("app.view")(h.notify.MentionCandidates)))
+	apiRouter.MethodFunc("GET", "/notifications/preferences", s.withSession(s.requirePermission("app.view")(h.notify.GetPreferences)))
+	apiRouter.MethodFunc("PUT", "/notifications/preferences", s.withSession(s.requirePermission("app.view")(h.notify.UpdatePreferences)))
 	apiRouter.MethodFunc("GET", "/dashboard", s.withSession(s.requirePermission("dashboard.view")(h.dashboard.Data)))
 	apiRouter.MethodFunc("POST", "/dashboard/layout", s.withSession(s.requirePermission("dashboard.view")(h.dashboard.SaveLayout)))
 }
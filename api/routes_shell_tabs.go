@@ -29,6 +29,7 @@ func (s *Server) registerShellTabRoutes(appShell http.HandlerFunc) {
 	s.router.MethodFunc("GET", "/backups", appShell)
 	s.router.MethodFunc("GET", "/backups/*", appShell)
 	s.router.MethodFunc("GET", "/findings", appShell)
+	s.router.MethodFunc("GET", "/risks", appShell)
 	s.router.MethodFunc("GET", "/accounts", appShell)
 	s.router.MethodFunc("GET", "/accounts/*", appShell)
 	s.router.MethodFunc("GET", "/settings", appShell)
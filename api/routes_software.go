@@ -12,5 +12,5 @@ func (s *Server) registerSoftwareRoutes(apiRouter chi.Router, h routeHandlers) {
 	routegroups.RegisterSoftware(apiRouter, routegroups.Guards{
 		WithSession:       s.withSession,
 		RequirePermission: func(p string) func(http.HandlerFunc) http.HandlerFunc { return s.requirePermission(rbac.Permission(p)) },
-	}, h.software)
+	}, h.software, h.vulns, h.eol)
 }
@@ -9,17 +9,24 @@ import (
 	"path/filepath"
 	"time"
 
+	"berkut-scc/api/handlers"
 	"berkut-scc/config"
 	"berkut-scc/core/appjobs"
 	"berkut-scc/core/appmeta"
 	"berkut-scc/core/auth"
 	"berkut-scc/core/backups"
+	"berkut-scc/core/customfields"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/eol"
+	"berkut-scc/core/findings"
 	"berkut-scc/core/incidents"
 	"berkut-scc/core/monitoring"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
+	"berkut-scc/core/vulns"
 	"berkut-scc/gui"
 	"berkut-scc/tasks"
 	"github.com/go-chi/chi/v5"
@@ -52,6 +59,14 @@ type Server struct {
 	findingsStore     store.FindingsStore
 	softwareStore     store.SoftwareStore
 	entityLinksStore  store.EntityLinksStore
+	observablesStore  store.ObservablesStore
+	vulnsStore        store.VulnsStore
+	risksStore        store.RisksStore
+	vulnsSvc          *vulns.Service
+	eolSvc            *eol.Service
+	findingsSvc       *findings.Service
+	findingSLAStore   store.FindingSLAStore
+	notifySvc         *notify.Service
 	monitoringStore   store.MonitoringStore
 	appModules        store.AppModuleStateStore
 	appJobs           store.AppJobsStore
@@ -64,6 +79,12 @@ type Server struct {
 	backupsScheduler  *backups.Scheduler
 	tasksScheduler    *tasks.RecurringScheduler
 	activityTracker   *sessionActivity
+	customFieldsSvc   *customfields.Service
+	savedSearches     store.SavedSearchesStore
+	queriesSvc        *query.Service
+	searchStore       store.SearchStore
+	scheduleCalendars store.ScheduleCalendarsStore
+	reports           *handlers.ReportsHandler
 }
 
 func NewServer(cfg *config.AppConfig, logger *utils.Logger, deps ServerDeps) *Server {
@@ -90,6 +111,14 @@ func NewServer(cfg *config.AppConfig, logger *utils.Logger, deps ServerDeps) *Se
 		findingsStore:     deps.FindingsStore,
 		softwareStore:     deps.SoftwareStore,
 		entityLinksStore:  deps.EntityLinksStore,
+		observablesStore:  deps.ObservablesStore,
+		vulnsStore:        deps.VulnsStore,
+		risksStore:        deps.RisksStore,
+		vulnsSvc:          deps.VulnsSvc,
+		eolSvc:            deps.EOLSvc,
+		findingsSvc:       deps.FindingsSvc,
+		findingSLAStore:   deps.FindingSLAStore,
+		notifySvc:         deps.NotifySvc,
 		monitoringStore:   deps.MonitoringStore,
 		appModules:        deps.AppModules,
 		appJobs:           deps.AppJobs,
@@ -106,7 +135,13 @@ func NewServer(cfg *config.AppConfig, logger *utils.Logger, deps ServerDeps) *Se
 		dashboardStore:    deps.DashboardStore,
 		backupsSvc:        deps.BackupsSvc,
 		activityTracker:   newSessionActivity(),
+		customFieldsSvc:   customfields.NewService(store.NewCustomFieldsStore(deps.DB), deps.Users, deps.AssetsStore),
 	}
+	s.savedSearches = store.NewSavedSearchesStore(deps.DB)
+	s.queriesSvc = query.NewService(s.savedSearches, s.customFieldsSvc)
+	s.searchStore = store.NewSearchStore(deps.DB)
+	s.scheduleCalendars = store.NewScheduleCalendarsStore(deps.DB)
+	s.reports = s.newReportsHandler()
 	if err := s.bootstrapRoles(context.Background()); err != nil && logger != nil {
 		logger.Errorf("bootstrap roles: %v", err)
 	}
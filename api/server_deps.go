@@ -7,9 +7,13 @@ import (
 	"berkut-scc/core/appmeta"
 	"berkut-scc/core/backups"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/eol"
+	"berkut-scc/core/findings"
 	"berkut-scc/core/incidents"
 	"berkut-scc/core/monitoring"
+	"berkut-scc/core/notify"
 	"berkut-scc/core/store"
+	"berkut-scc/core/vulns"
 	"berkut-scc/tasks"
 )
 
@@ -28,6 +32,9 @@ type ServerDeps struct {
 	FindingsStore     store.FindingsStore
 	SoftwareStore     store.SoftwareStore
 	EntityLinksStore  store.EntityLinksStore
+	ObservablesStore  store.ObservablesStore
+	VulnsStore        store.VulnsStore
+	RisksStore        store.RisksStore
 	MonitoringStore   store.MonitoringStore
 	AppModules        store.AppModuleStateStore
 	AppJobs           store.AppJobsStore
@@ -39,6 +46,11 @@ type ServerDeps struct {
 	BackupsSvc        *backups.Service
 	DocsSvc           *docs.Service
 	IncidentsSvc      *incidents.Service
+	VulnsSvc          *vulns.Service
+	EOLSvc            *eol.Service
+	FindingsSvc       *findings.Service
+	FindingSLAStore   store.FindingSLAStore
+	NotifySvc         *notify.Service
 	TasksStore        tasks.Store
 	TasksSvc          *tasks.Service
 	MonitoringEngine  *monitoring.Engine
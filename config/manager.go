@@ -125,6 +125,21 @@ func normalizeConfig(cfg *AppConfig) {
 	for i := range cfg.Security.WebAuthn.Origins {
 		cfg.Security.WebAuthn.Origins[i] = strings.TrimSpace(cfg.Security.WebAuthn.Origins[i])
 	}
+	cfg.Software.EOLAlertMode = strings.ToLower(strings.TrimSpace(cfg.Software.EOLAlertMode))
+	switch cfg.Software.EOLAlertMode {
+	case "finding", "task", "both":
+	default:
+		cfg.Software.EOLAlertMode = "finding"
+	}
+	if cfg.Software.EOLHorizonDays <= 0 {
+		cfg.Software.EOLHorizonDays = 90
+	}
+	if cfg.Software.EOLTaskBoardID < 0 {
+		cfg.Software.EOLTaskBoardID = 0
+	}
+	if cfg.Software.EOLTaskColumnID < 0 {
+		cfg.Software.EOLTaskColumnID = 0
+	}
 	if cfg.Backups.PGDumpBin == "" {
 		cfg.Backups.PGDumpBin = "pg_dump"
 	}
@@ -25,6 +25,7 @@ type AppConfig struct {
 	Security        SecurityConfig      `yaml:"security"`
 	Incidents       IncidentsConfig     `yaml:"incidents"`
 	Backups         BackupsConfig       `yaml:"backups"`
+	Software        SoftwareConfig      `yaml:"software"`
 }
 
 func (c *AppConfig) IsHomeMode() bool {
@@ -148,6 +149,17 @@ type BackupsConfig struct {
 	RestoreTestIntervalHours int    `yaml:"restore_test_interval_hours" env:"BERKUT_BACKUP_RESTORE_TEST_INTERVAL_HOURS" env-default:"168"`
 }
 
+type SoftwareConfig struct {
+	// EOLHorizonDays is how far ahead installations are flagged as approaching end of life.
+	EOLHorizonDays int `yaml:"eol_horizon_days" env:"BERKUT_SOFTWARE_EOL_HORIZON_DAYS" env-default:"90"`
+	// EOLAlertMode selects what the daily end-of-life check raises: finding, task or both.
+	EOLAlertMode string `yaml:"eol_alert_mode" env:"BERKUT_SOFTWARE_EOL_ALERT_MODE" env-default:"finding"`
+	// EOLTaskBoardID and EOLTaskColumnID pick where end-of-life tasks are created; 0 means the
+	// first active board and its first active column.
+	EOLTaskBoardID  int64 `yaml:"eol_task_board_id" env:"BERKUT_SOFTWARE_EOL_TASK_BOARD_ID" env-default:"0"`
+	EOLTaskColumnID int64 `yaml:"eol_task_column_id" env:"BERKUT_SOFTWARE_EOL_TASK_COLUMN_ID" env-default:"0"`
+}
+
 const maxUserSessionTTL = 3 * time.Hour
 
 func (c *AppConfig) EffectiveSessionTTL() time.Duration {
@@ -11,10 +11,14 @@ import (
 	"berkut-scc/core/backups"
 	backupsstore "berkut-scc/core/backups/store"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/eol"
+	"berkut-scc/core/findings"
 	"berkut-scc/core/incidents"
 	"berkut-scc/core/monitoring"
+	"berkut-scc/core/notify"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
+	"berkut-scc/core/vulns"
 	"berkut-scc/tasks"
 	taskstore "berkut-scc/tasks/store"
 )
@@ -43,6 +47,10 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 	findingsStore := store.NewFindingsStore(db)
 	softwareStore := store.NewSoftwareStore(db)
 	entityLinks := store.NewEntityLinksStore(db)
+	observablesStore := store.NewObservablesStore(db)
+	vulnsStore := store.NewVulnsStore(db)
+	risksStore := store.NewRisksStore(db)
+	vulnsSvc := vulns.NewService(vulnsStore, findingsStore, entityLinks, audits)
 	monitoringStore := store.NewMonitoringStore(db)
 	appModules := store.NewAppModuleStateStore(db)
 	appJobs := store.NewAppJobsStore(db)
@@ -56,6 +64,12 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 	backupsScheduler := backups.NewScheduler(cfg.Scheduler, backupsSvc)
 	tasksStore := taskstore.NewStore(db)
 	tasksSvc := tasks.NewService(tasksStore)
+	eolSvc := eol.NewService(cfg.Software, store.NewSoftwareEOLStore(db), softwareStore, findingsStore, entityLinks, users, audits)
+	eolSvc.SetTaskStore(tasksStore)
+	eolScheduler := eol.NewScheduler(cfg.Scheduler, eolSvc, logger)
+	findingSLAStore := store.NewFindingSLAStore(db)
+	findingsSvc := findings.NewService(findingsStore, findingSLAStore, users, audits)
+	findingsScheduler := findings.NewScheduler(cfg.Scheduler, findingsSvc, logger)
 
 	docsSvc, err := docs.NewService(cfg, docsStore, users, audits, logger)
 	if err != nil {
@@ -66,6 +80,7 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 		return nil, err
 	}
 	tasksScheduler := tasks.NewRecurringScheduler(cfg.Scheduler, tasksSvc.Store(), audits, logger)
+	tasksAutomation := tasks.NewAutomationWorker(cfg.Scheduler, tasksSvc.Store(), incidentsStore, audits, logger)
 	monitoringEngine := monitoring.NewEngineWithDeps(
 		monitoringStore,
 		incidentsStore,
@@ -81,6 +96,18 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 		StatsLogInterval: time.Duration(cfg.Monitoring.StatsLogIntervalSeconds) * time.Second,
 	})
 	monitoringEngine.SetTaskStore(tasksStore)
+	notifySvc := notify.NewService(store.NewNotificationsStore(db), logger)
+	notifySvc.SetExternalSender(monitoringEngine)
+	notifySvc.SetMentionStore(store.NewMentionsStore(db), users)
+	monitoringEngine.SetOwnerNotifier(assetsStore, users, notifySvc)
+	tasksSvc.SetNotifier(notifySvc)
+	docsSvc.SetNotifier(notifySvc)
+	incidentsSvc.SetNotifier(notifySvc)
+	findingsSvc.SetNotifier(notifySvc)
+	calendars := store.NewScheduleCalendarsStore(db)
+	tasksScheduler.SetCalendars(calendars)
+	backupsSvc.SetCalendars(calendars)
+	findingsSvc.SetCalendars(calendars)
 	appJobsWorker := appjobs.NewWorker(cfg, db, appJobs, appModules, audits, logger)
 
 	return &runtimeComposition{
@@ -99,6 +126,9 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 			FindingsStore:     findingsStore,
 			SoftwareStore:     softwareStore,
 			EntityLinksStore:  entityLinks,
+			ObservablesStore:  observablesStore,
+			VulnsStore:        vulnsStore,
+			RisksStore:        risksStore,
 			MonitoringStore:   monitoringStore,
 			AppModules:        appModules,
 			AppJobs:           appJobs,
@@ -110,6 +140,11 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 			BackupsSvc:        backupsSvc,
 			DocsSvc:           docsSvc,
 			IncidentsSvc:      incidentsSvc,
+			VulnsSvc:          vulnsSvc,
+			EOLSvc:            eolSvc,
+			FindingsSvc:       findingsSvc,
+			FindingSLAStore:   findingSLAStore,
+			NotifySvc:         notifySvc,
 			TasksStore:        tasksStore,
 			TasksSvc:          tasksSvc,
 			MonitoringEngine:  monitoringEngine,
@@ -118,6 +153,6 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 			TasksScheduler:    tasksScheduler,
 		},
 		sessions: sessions,
-		workers:  []api.BackgroundWorker{tasksScheduler, monitoringEngine, backupsScheduler, appJobsWorker},
+		workers:  []api.BackgroundWorker{tasksScheduler, tasksAutomation, monitoringEngine, backupsScheduler, eolScheduler, findingsScheduler, appJobsWorker},
 	}, nil
 }
@@ -40,7 +40,7 @@ func InitRuntime(ctx context.Context, cfg *config.AppConfig, logger *utils.Logge
 	return &Runtime{
 		DB:         db,
 		Server:     srv,
-		background: api.BuildBackgroundController(composition.sessions, cfg != nil && cfg.RunMode == "all", logger, composition.workers...),
+		background: api.BuildBackgroundController(composition.sessions, cfg != nil && cfg.RunMode == "all", logger, append(composition.workers, srv.BackgroundWorkers()...)...),
 	}, nil
 }
 
@@ -25,14 +25,23 @@ func moduleIncidents(spec appcompat.ModuleSpec) Module {
 					"incident_acl",
 					"incident_stage_entries",
 					"incident_stages",
+					"incident_tags",
 					"incident_participants",
 					"incident_reg_counters",
+					"incident_impact",
 					"incidents",
 				}
 				counts, err := deleteTablesInOrder(ctx, tx, tables)
 				if err != nil {
 					return ModuleResult{}, err
 				}
+				if exists, _ := tableExists(ctx, tx, "observables"); exists {
+					n, err := deleteWhere(ctx, tx, "observables", "entity_type=?", "incident")
+					if err != nil {
+						return ModuleResult{}, err
+					}
+					counts["observables"] = n
+				}
 				return ModuleResult{Counts: counts}, nil
 			})
 			if err != nil {
@@ -18,12 +18,28 @@ func moduleRegistryAssets(spec appcompat.ModuleSpec) Module {
 				tables := []string{
 					"asset_software",
 					"monitor_assets",
+					"asset_relations",
+					"asset_history",
 					"assets",
 				}
 				counts, err := deleteTablesInOrder(ctx, tx, tables)
 				if err != nil {
 					return ModuleResult{}, err
 				}
+				if exists, _ := tableExists(ctx, tx, "custom_field_values"); exists {
+					n, err := deleteWhere(ctx, tx, "custom_field_values", "entity_type=?", "asset")
+					if err != nil {
+						return ModuleResult{}, err
+					}
+					counts["custom_field_values"] = n
+				}
+				if exists, _ := tableExists(ctx, tx, "observables"); exists {
+					n, err := deleteWhere(ctx, tx, "observables", "entity_type=?", "asset")
+					if err != nil {
+						return ModuleResult{}, err
+					}
+					counts["observables"] = n
+				}
 				return ModuleResult{Counts: counts}, nil
 			})
 			if err != nil {
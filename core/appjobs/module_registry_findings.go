@@ -19,6 +19,20 @@ func moduleRegistryFindings(spec appcompat.ModuleSpec) Module {
 				if err != nil {
 					return ModuleResult{}, err
 				}
+				if exists, _ := tableExists(ctx, tx, "custom_field_values"); exists {
+					n, err := deleteWhere(ctx, tx, "custom_field_values", "entity_type=?", "finding")
+					if err != nil {
+						return ModuleResult{}, err
+					}
+					counts["custom_field_values"] = n
+				}
+				if exists, _ := tableExists(ctx, tx, "observables"); exists {
+					n, err := deleteWhere(ctx, tx, "observables", "entity_type=?", "finding")
+					if err != nil {
+						return ModuleResult{}, err
+					}
+					counts["observables"] = n
+				}
 				return ModuleResult{Counts: counts}, nil
 			})
 			if err != nil {
@@ -18,6 +18,17 @@ func moduleReports(spec appcompat.ModuleSpec) Module {
 			res, err := withTx(ctx, deps.DB, func(tx *sql.Tx) (ModuleResult, error) {
 				counts := map[string]int64{}
 
+				// Delete report schedules and their run log.
+				for _, table := range []string{"report_schedule_runs", "report_schedules"} {
+					if exists, _ := tableExists(ctx, tx, table); exists {
+						n, err := deleteAll(ctx, tx, table)
+						if err != nil {
+							return ModuleResult{}, err
+						}
+						counts[table] = n
+					}
+				}
+
 				// Delete report-specific derived tables.
 				r1, err := tx.ExecContext(ctx, `
 					DELETE FROM report_snapshot_items
@@ -23,6 +23,14 @@ func moduleTasks(spec appcompat.ModuleSpec) Module {
 					"task_comments",
 					"task_assignments",
 					"task_archive_entries",
+					"task_automation_runs",
+					"task_automation_events",
+					"task_automation_rules",
+					"task_time_estimates",
+					"task_timers",
+					"task_worklogs",
+					"task_activity",
+					"task_dependencies",
 					"tasks",
 					"task_subcolumns",
 					"task_columns",
@@ -1,8 +1,12 @@
 package backups
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
+
+	"berkut-scc/core/schedule"
 )
 
 const (
@@ -10,48 +14,49 @@ const (
 	ScheduleWeekly       = "weekly"
 	ScheduleMonthlyStart = "monthly_start"
 	ScheduleMonthlyEnd   = "monthly_end"
+	ScheduleRRule        = "rrule"
 	MonthAnchorStart     = "start"
 	MonthAnchorEnd       = "end"
 )
 
-func nextRunAfter(plan BackupPlan, ref time.Time) time.Time {
-	loc := ref.Location()
-	if loc == nil {
-		loc = time.UTC
-	}
+// planSpec converts the plan schedule to the shared schedule form; the legacy types
+// become RRULEs anchored at the plan time in the plan timezone.
+func planSpec(plan BackupPlan) schedule.Spec {
 	hour := clamp(plan.ScheduleHour, 0, 23)
 	minute := clamp(plan.ScheduleMinute, 0, 59)
-	base := ref.In(loc)
-
+	spec := schedule.Spec{
+		Start:      fmt.Sprintf("2000-01-01T%02d:%02d", hour, minute),
+		Timezone:   plan.ScheduleTimezone,
+		Shift:      plan.ScheduleShift,
+		CalendarID: plan.ScheduleCalendarID,
+	}
 	switch plan.ScheduleType {
 	case ScheduleWeekly:
-		weekday := time.Weekday(clamp(plan.ScheduleWeekday, 0, 6))
-		candidate := time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, loc)
-		delta := (int(weekday) - int(candidate.Weekday()) + 7) % 7
-		candidate = candidate.AddDate(0, 0, delta)
-		if !candidate.After(base) {
-			candidate = candidate.AddDate(0, 0, 7)
-		}
-		return candidate.UTC()
+		spec.RRule = "FREQ=WEEKLY;BYDAY=" + strings.ToUpper(time.Weekday(clamp(plan.ScheduleWeekday, 0, 6)).String()[:2])
 	case ScheduleMonthlyStart:
-		candidate := time.Date(base.Year(), base.Month(), 1, hour, minute, 0, 0, loc)
-		if !candidate.After(base) {
-			candidate = time.Date(base.Year(), base.Month()+1, 1, hour, minute, 0, 0, loc)
-		}
-		return candidate.UTC()
+		spec.RRule = "FREQ=MONTHLY;BYMONTHDAY=1"
 	case ScheduleMonthlyEnd:
-		candidate := time.Date(base.Year(), base.Month()+1, 0, hour, minute, 0, 0, loc)
-		if !candidate.After(base) {
-			candidate = time.Date(base.Year(), base.Month()+2, 0, hour, minute, 0, 0, loc)
-		}
-		return candidate.UTC()
+		spec.RRule = "FREQ=MONTHLY;BYMONTHDAY=-1"
+	case ScheduleRRule:
+		spec.RRule = plan.ScheduleRRule
 	default:
-		candidate := time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, loc)
-		if !candidate.After(base) {
-			candidate = candidate.AddDate(0, 0, 1)
-		}
-		return candidate.UTC()
+		spec.RRule = "FREQ=DAILY"
 	}
+	return spec
+}
+
+// nextRunAfter returns the next planned run after ref in UTC, or zero when the plan
+// schedule is invalid or exhausted.
+func nextRunAfter(plan BackupPlan, ref time.Time, cal *schedule.Calendar) time.Time {
+	rule, err := planSpec(plan).Compile(cal)
+	if err != nil {
+		return time.Time{}
+	}
+	next, ok := rule.Next(ref)
+	if !ok {
+		return time.Time{}
+	}
+	return next.UTC()
 }
 
 func scheduleCronExpression(plan BackupPlan) string {
@@ -64,26 +69,25 @@ func scheduleCronExpression(plan BackupPlan) string {
 		return itoa(minute) + " " + itoa(hour) + " 1 * *"
 	case ScheduleMonthlyEnd:
 		return itoa(minute) + " " + itoa(hour) + " 28-31 * *"
+	case ScheduleRRule:
+		return "RRULE:" + plan.ScheduleRRule
 	default:
 		return itoa(minute) + " " + itoa(hour) + " * * *"
 	}
 }
 
 func shouldRunByPlan(plan BackupPlan, lastRun *time.Time, now time.Time) bool {
+	return shouldRunOnCalendar(plan, nil, lastRun, now)
+}
+
+// shouldRunOnCalendar reports whether a planned run is due, shifting runs by cal.
+func shouldRunOnCalendar(plan BackupPlan, cal *schedule.Calendar, lastRun *time.Time, now time.Time) bool {
 	reference := now.UTC()
 	if lastRun != nil {
 		reference = lastRun.UTC()
 	}
-	next := nextRunAfter(plan, reference)
-	if !next.After(now.UTC()) {
-		if plan.ScheduleType == ScheduleMonthlyEnd {
-			n := now.UTC()
-			lastDay := time.Date(n.Year(), n.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
-			return n.Day() == lastDay
-		}
-		return true
-	}
-	return false
+	next := nextRunAfter(plan, reference, cal)
+	return !next.IsZero() && !next.After(now.UTC())
 }
 
 func clamp(v, minV, maxV int) int {
@@ -116,7 +116,11 @@ func (s *Scheduler) RunOnce(ctx context.Context, now time.Time) error {
 		seed := now.UTC()
 		lastRun = &seed
 	}
-	if !shouldRunByPlan(*plan, lastRun, now.UTC()) {
+	cal, err := s.svc.planCalendar(ctx, *plan)
+	if err != nil {
+		return err
+	}
+	if !shouldRunOnCalendar(*plan, cal, lastRun, now.UTC()) {
 		return nil
 	}
 	return s.svc.RunAutoBackup(ctx)
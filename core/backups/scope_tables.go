@@ -24,6 +24,16 @@ var scopeTables = map[string][]string{
 		"task_comments",
 		"task_assignments",
 		"task_archive_entries",
+		"task_automation_runs",
+		"task_automation_events",
+		"task_automation_rules",
+		"task_time_estimates",
+		"task_timers",
+		"task_worklogs",
+		"task_activity",
+		"task_dependencies",
+		"custom_field_values",
+		"custom_fields",
 		"tasks",
 		"task_subcolumns",
 		"task_columns",
@@ -36,6 +46,8 @@ var scopeTables = map[string][]string{
 		"task_space_acl",
 		"task_spaces",
 		"task_tags",
+		"schedule_calendar_days",
+		"schedule_calendars",
 	},
 	"incidents": {
 		"incident_artifact_files",
@@ -45,11 +57,18 @@ var scopeTables = map[string][]string{
 		"incident_acl",
 		"incident_stage_entries",
 		"incident_stages",
+		"incident_tags",
 		"incident_participants",
 		"incident_reg_counters",
+		"incident_impact",
+		"observables",
+		"custom_field_values",
+		"custom_fields",
 		"incidents",
 	},
 	"reports": {
+		"report_schedule_runs",
+		"report_schedules",
 		"report_snapshot_items",
 		"report_snapshots",
 		"report_charts",
@@ -78,6 +97,8 @@ var scopeTables = map[string][]string{
 		"monitor_sla_policies",
 		"monitors",
 		"monitoring_settings",
+		"schedule_calendar_days",
+		"schedule_calendars",
 	},
 	"controls": {
 		"control_framework_map",
@@ -89,6 +110,21 @@ var scopeTables = map[string][]string{
 		"controls",
 		"control_types",
 	},
+	"assets": {
+		"vulnerability_matches",
+		"vulnerability_affected",
+		"vulnerabilities",
+		"software_product_aliases",
+		"software_eol_alerts",
+		"asset_relations",
+		"asset_history",
+		"asset_software",
+		"software_versions",
+		"software_products",
+		"custom_field_values",
+		"custom_fields",
+		"assets",
+	},
 	"approvals": {
 		"approval_comments",
 		"approval_participants",
@@ -101,10 +137,28 @@ var scopeTables = map[string][]string{
 		"group_role_links",
 		"role_permissions",
 		"sessions",
+		"user_notification_preferences",
+		"user_notifications",
+		"mentions",
+		"saved_search_groups",
+		"saved_searches",
 		"users",
 		"groups",
 		"roles",
 	},
+	"risks": {
+		"risk_settings",
+		"risks",
+	},
+	"findings": {
+		"finding_exceptions",
+		"finding_sla_settings",
+		"custom_field_values",
+		"custom_fields",
+		"findings",
+		"schedule_calendar_days",
+		"schedule_calendars",
+	},
 }
 
 func backupScopeIsAll(scope []string) bool {
@@ -20,6 +20,7 @@ import (
 	"berkut-scc/core/backups/format"
 	"berkut-scc/core/backups/pgdump"
 	"berkut-scc/core/backups/pgrestore"
+	"berkut-scc/core/schedule"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
@@ -40,6 +41,7 @@ type Service struct {
 	downloads map[int64]int
 	restoreMu sync.RWMutex
 	restores  map[int64]RestoreRun
+	calendars schedule.CalendarSource
 }
 
 func NewService(cfg *config.AppConfig, db *sql.DB, repo Repository, audits store.AuditStore, logger *utils.Logger) *Service {
@@ -526,6 +528,9 @@ func normalizedBackupScope(in []string) []string {
 		"controls":   {},
 		"accounts":   {},
 		"approvals":  {},
+		"assets":     {},
+		"risks":      {},
+		"findings":   {},
 	}
 	seen := map[string]struct{}{}
 	out := make([]string, 0, len(in))
@@ -4,8 +4,26 @@ import (
 	"context"
 	"strings"
 	"time"
+
+	"berkut-scc/core/schedule"
 )
 
+// SetCalendars sets the business calendars used to shift planned runs.
+func (s *Service) SetCalendars(calendars schedule.CalendarSource) {
+	if s == nil {
+		return
+	}
+	s.calendars = calendars
+}
+
+// planCalendar loads the business calendar of the plan; nil means weekends only.
+func (s *Service) planCalendar(ctx context.Context, plan BackupPlan) (*schedule.Calendar, error) {
+	if plan.ScheduleCalendarID <= 0 || s.calendars == nil {
+		return nil, nil
+	}
+	return s.calendars.BusinessCalendar(ctx, plan.ScheduleCalendarID)
+}
+
 func (s *Service) GetPlan(ctx context.Context) (*BackupPlan, error) {
 	if s == nil || s.repo == nil {
 		return nil, NewDomainError(ErrorCodeInternal, "common.serverError")
@@ -55,7 +73,7 @@ func (s *Service) DisablePlan(ctx context.Context, requestedBy string) (*BackupP
 func (s *Service) normalizeAndValidatePlan(in BackupPlan) (*BackupPlan, error) {
 	plan := normalizePlan(&in)
 	switch plan.ScheduleType {
-	case ScheduleDaily, ScheduleWeekly, ScheduleMonthlyStart, ScheduleMonthlyEnd:
+	case ScheduleDaily, ScheduleWeekly, ScheduleMonthlyStart, ScheduleMonthlyEnd, ScheduleRRule:
 	default:
 		return nil, NewDomainError(ErrorCodeInvalidPlan, ErrorKeyInvalidPlan)
 	}
@@ -73,6 +91,21 @@ func (s *Service) normalizeAndValidatePlan(in BackupPlan) (*BackupPlan, error) {
 	if plan.ScheduleHour < 0 || plan.ScheduleHour > 23 || plan.ScheduleMinute < 0 || plan.ScheduleMinute > 59 {
 		return nil, NewDomainError(ErrorCodeInvalidPlan, ErrorKeyInvalidPlan)
 	}
+	if plan.ScheduleType != ScheduleRRule {
+		plan.ScheduleRRule = ""
+	} else if plan.ScheduleRRule == "" {
+		return nil, NewDomainError(ErrorCodeInvalidPlan, ErrorKeyInvalidPlan)
+	}
+	spec, err := planSpec(*plan).Normalize()
+	if err != nil {
+		return nil, NewDomainError(ErrorCodeInvalidPlan, err.Error())
+	}
+	plan.ScheduleTimezone = spec.Timezone
+	plan.ScheduleShift = spec.Shift
+	plan.ScheduleCalendarID = spec.CalendarID
+	if plan.ScheduleType == ScheduleRRule {
+		plan.ScheduleRRule = spec.RRule
+	}
 	plan.CronExpression = scheduleCronExpression(*plan)
 	return plan, nil
 }
@@ -107,6 +140,12 @@ func normalizePlan(in *BackupPlan) *BackupPlan {
 	if out.ScheduleMinute < 0 || out.ScheduleMinute > 59 {
 		out.ScheduleMinute = 0
 	}
+	out.ScheduleTimezone = strings.TrimSpace(out.ScheduleTimezone)
+	out.ScheduleRRule = strings.TrimSpace(out.ScheduleRRule)
+	out.ScheduleShift = strings.TrimSpace(out.ScheduleShift)
+	if out.ScheduleCalendarID < 0 {
+		out.ScheduleCalendarID = 0
+	}
 	if strings.TrimSpace(out.ScheduleMonthAnchor) == "" {
 		out.ScheduleMonthAnchor = MonthAnchorStart
 	}
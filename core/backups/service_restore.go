@@ -669,6 +669,9 @@ var scopedValidationRules = []scopedValidationRule{
 	{Scope: "accounts", EntityKey: "accounts.users", Table: "users"},
 	{Scope: "accounts", EntityKey: "accounts.groups", Table: "groups"},
 	{Scope: "approvals", EntityKey: "approvals.approvals", Table: "approvals"},
+	{Scope: "assets", EntityKey: "assets.assets", Table: "assets"},
+	{Scope: "risks", EntityKey: "risks.risks", Table: "risks"},
+	{Scope: "findings", EntityKey: "findings.findings", Table: "findings"},
 }
 
 func (s *Service) validateScopedRestore(artifact *BackupArtifact, scope []string, before, after map[string]int64, meta *restore.Meta) error {
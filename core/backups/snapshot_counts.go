@@ -38,6 +38,16 @@ func (s *Service) snapshotEntityCounts(ctx context.Context, scope []string) map[
 	if scopeIncludes(scope, "approvals") {
 		s.addCount(ctx, out, "approvals.approvals", "SELECT COUNT(*) FROM approvals")
 	}
+	if scopeIncludes(scope, "assets") {
+		s.addCount(ctx, out, "assets.assets", "SELECT COUNT(*) FROM assets")
+		s.addCount(ctx, out, "assets.vulnerabilities", "SELECT COUNT(*) FROM vulnerabilities")
+	}
+	if scopeIncludes(scope, "risks") {
+		s.addCount(ctx, out, "risks.risks", "SELECT COUNT(*) FROM risks")
+	}
+	if scopeIncludes(scope, "findings") {
+		s.addCount(ctx, out, "findings.findings", "SELECT COUNT(*) FROM findings")
+	}
 	return out
 }
 
@@ -11,7 +11,8 @@ import (
 
 func (r *Repository) GetBackupPlan(ctx context.Context) (*backups.BackupPlan, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, enabled, cron_expression, schedule_type, schedule_weekday, schedule_month_anchor, schedule_hour, schedule_minute, retention_days, keep_last_successful, include_files, created_at, updated_at, last_auto_run_at
+		SELECT id, enabled, cron_expression, schedule_type, schedule_weekday, schedule_month_anchor, schedule_hour, schedule_minute,
+			schedule_timezone, schedule_rrule, schedule_shift, schedule_calendar_id, retention_days, keep_last_successful, include_files, created_at, updated_at, last_auto_run_at
 		FROM backup_plans
 		WHERE id=1
 	`)
@@ -26,6 +27,10 @@ func (r *Repository) GetBackupPlan(ctx context.Context) (*backups.BackupPlan, er
 		&item.ScheduleMonthAnchor,
 		&item.ScheduleHour,
 		&item.ScheduleMinute,
+		&item.ScheduleTimezone,
+		&item.ScheduleRRule,
+		&item.ScheduleShift,
+		&item.ScheduleCalendarID,
 		&item.RetentionDays,
 		&item.KeepLastSuccessful,
 		&item.IncludeFiles,
@@ -70,8 +75,9 @@ func (r *Repository) UpsertBackupPlan(ctx context.Context, plan *backups.BackupP
 		cronExpr = "0 2 * * *"
 	}
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO backup_plans(id, enabled, cron_expression, schedule_type, schedule_weekday, schedule_month_anchor, schedule_hour, schedule_minute, retention_days, keep_last_successful, include_files, created_at, updated_at)
-		VALUES(1,?,?,?,?,?,?,?,?,?,?,?,?)
+		INSERT INTO backup_plans(id, enabled, cron_expression, schedule_type, schedule_weekday, schedule_month_anchor, schedule_hour, schedule_minute,
+			schedule_timezone, schedule_rrule, schedule_shift, schedule_calendar_id, retention_days, keep_last_successful, include_files, created_at, updated_at)
+		VALUES(1,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 		ON CONFLICT (id) DO UPDATE
 		SET enabled=EXCLUDED.enabled,
 			cron_expression=EXCLUDED.cron_expression,
@@ -80,11 +86,16 @@ func (r *Repository) UpsertBackupPlan(ctx context.Context, plan *backups.BackupP
 			schedule_month_anchor=EXCLUDED.schedule_month_anchor,
 			schedule_hour=EXCLUDED.schedule_hour,
 			schedule_minute=EXCLUDED.schedule_minute,
+			schedule_timezone=EXCLUDED.schedule_timezone,
+			schedule_rrule=EXCLUDED.schedule_rrule,
+			schedule_shift=EXCLUDED.schedule_shift,
+			schedule_calendar_id=EXCLUDED.schedule_calendar_id,
 			retention_days=EXCLUDED.retention_days,
 			keep_last_successful=EXCLUDED.keep_last_successful,
 			include_files=EXCLUDED.include_files,
 			updated_at=EXCLUDED.updated_at
-	`, plan.Enabled, cronExpr, plan.ScheduleType, plan.ScheduleWeekday, plan.ScheduleMonthAnchor, plan.ScheduleHour, plan.ScheduleMinute, plan.RetentionDays, plan.KeepLastSuccessful, plan.IncludeFiles, now, now)
+	`, plan.Enabled, cronExpr, plan.ScheduleType, plan.ScheduleWeekday, plan.ScheduleMonthAnchor, plan.ScheduleHour, plan.ScheduleMinute,
+		plan.ScheduleTimezone, plan.ScheduleRRule, plan.ScheduleShift, plan.ScheduleCalendarID, plan.RetentionDays, plan.KeepLastSuccessful, plan.IncludeFiles, now, now)
 	if err != nil {
 		return nil, err
 	}
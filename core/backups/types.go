@@ -91,6 +91,10 @@ type BackupPlan struct {
 	ScheduleMonthAnchor string     `json:"schedule_month_anchor,omitempty"`
 	ScheduleHour        int        `json:"schedule_hour,omitempty"`
 	ScheduleMinute      int        `json:"schedule_minute,omitempty"`
+	ScheduleTimezone    string     `json:"schedule_timezone,omitempty"`
+	ScheduleRRule       string     `json:"schedule_rrule,omitempty"`
+	ScheduleShift       string     `json:"schedule_shift,omitempty"`
+	ScheduleCalendarID  int64      `json:"schedule_calendar_id,omitempty"`
 	RetentionDays       int        `json:"retention_days"`
 	KeepLastSuccessful  int        `json:"keep_last_successful"`
 	IncludeFiles        bool       `json:"include_files"`
@@ -0,0 +1,509 @@
+package customfields
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// FilterPrefix marks custom field filters in list query strings: cf.<key>=<value>.
+const FilterPrefix = "cf."
+
+const maxTextLength = 4000
+
+// FieldError reports an invalid custom field value. Error returns an i18n key.
+type FieldError struct {
+	Key  string
+	Code string
+}
+
+func (e *FieldError) Error() string {
+	return "customFields.error." + e.Code
+}
+
+type Service struct {
+	store  store.CustomFieldsStore
+	users  store.UsersStore
+	assets store.AssetsStore
+}
+
+func NewService(cf store.CustomFieldsStore, users store.UsersStore, assets store.AssetsStore) *Service {
+	return &Service{store: cf, users: users, assets: assets}
+}
+
+func ValidEntityType(entityType string) bool {
+	for _, item := range store.CustomFieldEntityTypes {
+		if item == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidFieldType(fieldType string) bool {
+	for _, item := range store.CustomFieldTypes {
+		if item == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// CanView reports whether one of the roles may see the field.
+func CanView(f store.CustomField, roles []string) bool {
+	return rolesAllowed(f.ViewRoles, roles)
+}
+
+// CanEdit reports whether one of the roles may change the field. Editing implies viewing.
+func CanEdit(f store.CustomField, roles []string) bool {
+	return CanView(f, roles) && rolesAllowed(f.EditRoles, roles)
+}
+
+func rolesAllowed(allowed, roles []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if role == "superadmin" {
+			return true
+		}
+		for _, a := range allowed {
+			if strings.EqualFold(a, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Fields returns the active fields of an entity type visible to the roles.
+func (s *Service) Fields(ctx context.Context, entityType string, roles []string) ([]store.CustomField, error) {
+	if s == nil {
+		return nil, nil
+	}
+	all, err := s.store.ListCustomFields(ctx, entityType, false)
+	if err != nil {
+		return nil, err
+	}
+	var out []store.CustomField
+	for _, f := range all {
+		if CanView(f, roles) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// Prepare validates the submitted values against the field definitions and returns
+// them in storage form. Only the submitted keys are changed; when creating, every
+// required field the user may edit must be filled.
+func (s *Service) Prepare(ctx context.Context, entityType string, roles []string, raw map[string]any, creating bool) (map[int64]*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	fields, err := s.store.ListCustomFields(ctx, entityType, false)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]store.CustomField{}
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	out := map[int64]*string{}
+	for key, value := range raw {
+		f, ok := byKey[key]
+		if !ok || !CanView(f, roles) {
+			return nil, &FieldError{Key: key, Code: "unknown"}
+		}
+		if !CanEdit(f, roles) {
+			return nil, &FieldError{Key: key, Code: "forbidden"}
+		}
+		normalized, err := s.normalize(ctx, f, value)
+		if err != nil {
+			return nil, err
+		}
+		if normalized == nil && f.Required {
+			return nil, &FieldError{Key: key, Code: "required"}
+		}
+		out[f.ID] = normalized
+	}
+	if creating {
+		for _, f := range fields {
+			if !f.Required || !CanEdit(f, roles) {
+				continue
+			}
+			if out[f.ID] == nil {
+				return nil, &FieldError{Key: f.Key, Code: "required"}
+			}
+		}
+	}
+	return out, nil
+}
+
+// Save stores values returned by Prepare.
+func (s *Service) Save(ctx context.Context, entityType string, entityID int64, values map[int64]*string, userID int64) error {
+	if s == nil || len(values) == 0 {
+		return nil
+	}
+	return s.store.SetCustomFieldValues(ctx, entityType, entityID, values, userID)
+}
+
+// Values returns the typed values of the fields visible to the roles, by entity id and
+// field key.
+func (s *Service) Values(ctx context.Context, entityType string, roles []string, entityIDs []int64) (map[int64]map[string]any, error) {
+	out := map[int64]map[string]any{}
+	if s == nil || len(entityIDs) == 0 {
+		return out, nil
+	}
+	fields, err := s.Fields(ctx, entityType, roles)
+	if err != nil || len(fields) == 0 {
+		return out, err
+	}
+	stored, err := s.store.ListCustomFieldValues(ctx, entityType, entityIDs)
+	if err != nil {
+		return nil, err
+	}
+	for entityID, values := range stored {
+		item := map[string]any{}
+		for _, f := range fields {
+			if raw, ok := values[f.ID]; ok {
+				item[f.Key] = typedValue(f, raw)
+			}
+		}
+		if len(item) > 0 {
+			out[entityID] = item
+		}
+	}
+	return out, nil
+}
+
+// DisplayValues returns printable values for exports and reports: users and assets are
+// shown by name, multi-select options are joined with "; ".
+func (s *Service) DisplayValues(ctx context.Context, entityType string, fields []store.CustomField, entityIDs []int64) (map[int64]map[string]string, error) {
+	out := map[int64]map[string]string{}
+	if s == nil || len(fields) == 0 || len(entityIDs) == 0 {
+		return out, nil
+	}
+	stored, err := s.store.ListCustomFieldValues(ctx, entityType, entityIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for entityID, values := range stored {
+		item := map[string]string{}
+		for _, f := range fields {
+			raw, ok := values[f.ID]
+			if !ok {
+				continue
+			}
+			item[f.Key] = s.display(ctx, f, raw, names)
+		}
+		out[entityID] = item
+	}
+	return out, nil
+}
+
+// Conditions turns cf.<key> query parameters into list conditions. Keys of unknown or
+// hidden fields are rejected.
+func (s *Service) Conditions(ctx context.Context, entityType string, roles []string, query url.Values) ([]store.CustomFieldCondition, error) {
+	filters := map[string]string{}
+	for name, values := range query {
+		if !strings.HasPrefix(name, FilterPrefix) || len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		filters[strings.TrimPrefix(name, FilterPrefix)] = values[0]
+	}
+	return s.ConditionsFor(ctx, entityType, roles, filters)
+}
+
+// ConditionsFor builds list conditions from field keys and raw values.
+func (s *Service) ConditionsFor(ctx context.Context, entityType string, roles []string, filters map[string]string) ([]store.CustomFieldCondition, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	if s == nil {
+		return nil, &FieldError{Code: "unknown"}
+	}
+	fields, err := s.Fields(ctx, entityType, roles)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]store.CustomField{}
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var out []store.CustomFieldCondition
+	for _, key := range keys {
+		f, ok := byKey[key]
+		if !ok {
+			return nil, &FieldError{Key: key, Code: "unknown"}
+		}
+		cond := store.CustomFieldCondition{FieldID: f.ID}
+		if f.FieldType == store.CustomFieldTypeMultiSelect {
+			cond.Multi = true
+			cond.Value = strings.TrimSpace(filters[key])
+		} else {
+			single := f
+			if single.FieldType == store.CustomFieldTypeSelect {
+				single.Options = nil
+			}
+			value, err := s.normalize(ctx, single, filters[key])
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				continue
+			}
+			cond.Value = *value
+		}
+		out = append(out, cond)
+	}
+	return out, nil
+}
+
+// NormalizeDefinition validates a field definition submitted by an administrator.
+func NormalizeDefinition(f *store.CustomField) error {
+	f.EntityType = strings.ToLower(strings.TrimSpace(f.EntityType))
+	f.Key = strings.ToLower(strings.TrimSpace(f.Key))
+	f.Label = strings.TrimSpace(f.Label)
+	f.FieldType = strings.ToLower(strings.TrimSpace(f.FieldType))
+	if !ValidEntityType(f.EntityType) {
+		return &FieldError{Code: "entityType"}
+	}
+	if !validKey(f.Key) {
+		return &FieldError{Key: f.Key, Code: "key"}
+	}
+	if f.Label == "" || len([]rune(f.Label)) > 200 {
+		return &FieldError{Key: f.Key, Code: "label"}
+	}
+	if !ValidFieldType(f.FieldType) {
+		return &FieldError{Key: f.Key, Code: "fieldType"}
+	}
+	f.Options = cleanList(f.Options)
+	if f.FieldType == store.CustomFieldTypeSelect || f.FieldType == store.CustomFieldTypeMultiSelect {
+		if len(f.Options) == 0 {
+			return &FieldError{Key: f.Key, Code: "options"}
+		}
+	} else {
+		f.Options = []string{}
+	}
+	f.ViewRoles = cleanList(f.ViewRoles)
+	f.EditRoles = cleanList(f.EditRoles)
+	return nil
+}
+
+func validKey(key string) bool {
+	if key == "" || len(key) > 64 {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z':
+		case (r >= '0' && r <= '9') || r == '_':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func cleanList(items []string) []string {
+	out := []string{}
+	seen := map[string]struct{}{}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		out = append(out, item)
+	}
+	return out
+}
+
+// normalize converts a submitted value to its storage form; nil means empty.
+func (s *Service) normalize(ctx context.Context, f store.CustomField, value any) (*string, error) {
+	invalid := &FieldError{Key: f.Key, Code: "invalid"}
+	if value == nil {
+		return nil, nil
+	}
+	if f.FieldType == store.CustomFieldTypeMultiSelect {
+		var items []string
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				str, ok := item.(string)
+				if !ok {
+					return nil, invalid
+				}
+				items = append(items, str)
+			}
+		case []string:
+			items = v
+		case string:
+			items = strings.Split(v, ";")
+		default:
+			return nil, invalid
+		}
+		items = cleanList(items)
+		if len(items) == 0 {
+			return nil, nil
+		}
+		for _, item := range items {
+			if !containsString(f.Options, item) {
+				return nil, invalid
+			}
+		}
+		raw, _ := json.Marshal(items)
+		out := string(raw)
+		return &out, nil
+	}
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case int:
+		text = strconv.Itoa(v)
+	case bool:
+		return nil, invalid
+	default:
+		return nil, invalid
+	}
+	if text == "" {
+		return nil, nil
+	}
+	switch f.FieldType {
+	case store.CustomFieldTypeText:
+		if len([]rune(text)) > maxTextLength {
+			return nil, invalid
+		}
+	case store.CustomFieldTypeNumber:
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		text = strconv.FormatFloat(num, 'f', -1, 64)
+	case store.CustomFieldTypeDate:
+		if len(text) > 10 {
+			text = text[:10]
+		}
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return nil, invalid
+		}
+	case store.CustomFieldTypeSelect:
+		if len(f.Options) > 0 && !containsString(f.Options, text) {
+			return nil, invalid
+		}
+	case store.CustomFieldTypeUser:
+		id, err := s.resolveUser(ctx, text)
+		if err != nil {
+			return nil, invalid
+		}
+		text = strconv.FormatInt(id, 10)
+	case store.CustomFieldTypeAsset:
+		id, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || id <= 0 || s.assets == nil {
+			return nil, invalid
+		}
+		asset, err := s.assets.GetAsset(ctx, id)
+		if err != nil || asset == nil || asset.DeletedAt != nil {
+			return nil, invalid
+		}
+	}
+	return &text, nil
+}
+
+func (s *Service) resolveUser(ctx context.Context, token string) (int64, error) {
+	if s.users == nil {
+		return 0, fmt.Errorf("users unavailable")
+	}
+	if id, err := strconv.ParseInt(token, 10, 64); err == nil {
+		u, _, err := s.users.Get(ctx, id)
+		if err != nil || u == nil {
+			return 0, fmt.Errorf("user not found")
+		}
+		return u.ID, nil
+	}
+	u, _, err := s.users.FindByUsername(ctx, token)
+	if err != nil || u == nil {
+		return 0, fmt.Errorf("user not found")
+	}
+	return u.ID, nil
+}
+
+func (s *Service) display(ctx context.Context, f store.CustomField, raw string, names map[string]string) string {
+	switch f.FieldType {
+	case store.CustomFieldTypeMultiSelect:
+		var items []string
+		_ = json.Unmarshal([]byte(raw), &items)
+		return strings.Join(items, "; ")
+	case store.CustomFieldTypeUser, store.CustomFieldTypeAsset:
+		cacheKey := f.FieldType + ":" + raw
+		if name, ok := names[cacheKey]; ok {
+			return name
+		}
+		name := raw
+		id, _ := strconv.ParseInt(raw, 10, 64)
+		if f.FieldType == store.CustomFieldTypeUser && s.users != nil {
+			if u, _, err := s.users.Get(ctx, id); err == nil && u != nil {
+				name = u.Username
+			}
+		} else if s.assets != nil {
+			if a, err := s.assets.GetAsset(ctx, id); err == nil && a != nil {
+				name = a.Name
+			}
+		}
+		names[cacheKey] = name
+		return name
+	}
+	return raw
+}
+
+func typedValue(f store.CustomField, raw string) any {
+	switch f.FieldType {
+	case store.CustomFieldTypeNumber:
+		if num, err := strconv.ParseFloat(raw, 64); err == nil {
+			return num
+		}
+	case store.CustomFieldTypeMultiSelect:
+		items := []string{}
+		_ = json.Unmarshal([]byte(raw), &items)
+		return items
+	case store.CustomFieldTypeUser, store.CustomFieldTypeAsset:
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return id
+		}
+	}
+	return raw
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"berkut-scc/config"
+	"berkut-scc/core/notify"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
@@ -35,6 +36,7 @@ type Service struct {
 	logger     *utils.Logger
 	converters ConverterStatus
 	tempDir    string
+	notifier   *notify.Service
 }
 
 type SaveRequest struct {
@@ -546,6 +548,17 @@ func (s *Service) Log(ctx context.Context, username, action, details string) {
 	}
 }
 
+func (s *Service) SetNotifier(n *notify.Service) {
+	s.notifier = n
+}
+
+func (s *Service) Notifier() *notify.Service {
+	if s == nil {
+		return nil
+	}
+	return s.notifier
+}
+
 func (s *Service) StreamFile(ctx context.Context, w io.Writer, v *store.DocVersion) error {
 	content, err := s.LoadContent(ctx, v)
 	if err != nil {
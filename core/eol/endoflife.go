@@ -0,0 +1,103 @@
+package eol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrFeedInvalid = errors.New("software.eol.feedInvalid")
+
+// Cycle is one release cycle of an endoflife.date product file, e.g.
+// {"cycle": "3.11", "releaseDate": "2022-10-24", "eol": "2027-10-24", "latest": "3.11.9"}.
+// "eol" may also be a boolean: true means the cycle is already unsupported, false means no date yet.
+type Cycle struct {
+	Cycle       string
+	ReleaseDate *time.Time
+	EOL         *time.Time
+	EOLReached  bool
+	Latest      string
+}
+
+// ParseEndOfLife decodes an endoflife.date style JSON array of release cycles.
+func ParseEndOfLife(data []byte) ([]Cycle, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &raw); err != nil {
+		return nil, ErrFeedInvalid
+	}
+	out := make([]Cycle, 0, len(raw))
+	for _, item := range raw {
+		c := Cycle{Cycle: rawString(item["cycle"]), Latest: rawString(item["latest"])}
+		if c.Cycle == "" {
+			continue
+		}
+		c.ReleaseDate = rawDate(item["releaseDate"])
+		switch v := strings.TrimSpace(string(item["eol"])); v {
+		case "true":
+			c.EOLReached = true
+		case "", "false", "null":
+		default:
+			c.EOL = rawDate(item["eol"])
+		}
+		out = append(out, c)
+	}
+	if len(out) == 0 {
+		return nil, ErrFeedInvalid
+	}
+	return out, nil
+}
+
+// MatchCycle returns the cycle a version belongs to: an exact match or the longest cycle
+// that prefixes the version at a "." / "-" / "+" boundary ("3.11.4" belongs to "3.11", not "3.1").
+func MatchCycle(cycles []Cycle, version string) (Cycle, bool) {
+	v := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	best, bestLen := -1, 0
+	for i, c := range cycles {
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(c.Cycle), "v"))
+		if name == "" {
+			continue
+		}
+		if v != name {
+			if !strings.HasPrefix(v, name) || !strings.ContainsRune(".-+", rune(v[len(name)])) {
+				continue
+			}
+		}
+		if best < 0 || len(name) > bestLen {
+			best, bestLen = i, len(name)
+		}
+	}
+	if best < 0 {
+		return Cycle{}, false
+	}
+	return cycles[best], true
+}
+
+func rawString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+func rawDate(raw json.RawMessage) *time.Time {
+	v := rawString(raw)
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,26 @@
+package eol
+
+import (
+	"context"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/schedule"
+	"berkut-scc/core/utils"
+)
+
+// Scheduler runs the end-of-life check once per UTC day.
+type Scheduler struct {
+	*schedule.Daily
+}
+
+func NewScheduler(cfg config.SchedulerConfig, svc *Service, logger *utils.Logger) *Scheduler {
+	var run func(context.Context, time.Time) error
+	if svc != nil {
+		run = func(ctx context.Context, now time.Time) error {
+			_, err := svc.Check(ctx, now, "scheduler", 0)
+			return err
+		}
+	}
+	return &Scheduler{Daily: schedule.NewDaily("eol.check", cfg, run, logger)}
+}
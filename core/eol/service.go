@@ -0,0 +1,491 @@
+package eol
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+// FindingType is the finding_type assigned to findings raised by the end-of-life check.
+const FindingType = "eol"
+
+// Alert modes.
+const (
+	ModeFinding = "finding"
+	ModeTask    = "task"
+	ModeBoth    = "both"
+)
+
+type Service struct {
+	cfg      config.SoftwareConfig
+	store    store.SoftwareEOLStore
+	software store.SoftwareStore
+	findings store.FindingsStore
+	links    store.EntityLinksStore
+	users    store.UsersStore
+	tasks    tasks.Store
+	audits   store.AuditStore
+}
+
+func NewService(cfg config.SoftwareConfig, es store.SoftwareEOLStore, software store.SoftwareStore, findings store.FindingsStore, links store.EntityLinksStore, users store.UsersStore, audits store.AuditStore) *Service {
+	return &Service{cfg: cfg, store: es, software: software, findings: findings, links: links, users: users, audits: audits}
+}
+
+// SetTaskStore enables task alerts for the "task" and "both" modes.
+func (s *Service) SetTaskStore(ts tasks.Store) {
+	if s == nil {
+		return
+	}
+	s.tasks = ts
+}
+
+// HorizonDays is the configured look-ahead for approaching end of life.
+func (s *Service) HorizonDays() int {
+	if s == nil || s.cfg.EOLHorizonDays <= 0 {
+		return 90
+	}
+	return s.cfg.EOLHorizonDays
+}
+
+// Exposure is an installation past or approaching end of life.
+type Exposure struct {
+	store.SoftwareEOLExposure
+	State    string `json:"state"`
+	DaysLeft int    `json:"days_left"`
+}
+
+// ListExposure returns installations whose version reaches end of life within horizonDays of now
+// (the configured horizon when horizonDays is 0), expired ones first.
+func (s *Service) ListExposure(ctx context.Context, now time.Time, horizonDays int) ([]Exposure, error) {
+	if horizonDays <= 0 {
+		horizonDays = s.HorizonDays()
+	}
+	today := truncateDay(now)
+	items, err := s.store.ListEOLExposure(ctx, today.AddDate(0, 0, horizonDays))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Exposure, 0, len(items))
+	for _, item := range items {
+		out = append(out, classify(item, today))
+	}
+	return out, nil
+}
+
+type CheckResult struct {
+	Exposed      int `json:"exposed"`
+	Expired      int `json:"expired"`
+	Approaching  int `json:"approaching"`
+	Raised       int `json:"raised"`
+	Escalated    int `json:"escalated"`
+	Reopened     int `json:"reopened"`
+	Resolved     int `json:"resolved"`
+	TasksCreated int `json:"tasks_created"`
+}
+
+// Check raises findings and/or tasks for installations past or approaching end of life, escalates
+// approaching alerts once the date passes and resolves alerts of upgraded or removed installations.
+func (s *Service) Check(ctx context.Context, now time.Time, username string, userID int64) (*CheckResult, error) {
+	exposures, err := s.ListExposure(ctx, now, 0)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := s.store.ListEOLAlerts(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	byInstallation := map[int64]store.SoftwareEOLAlert{}
+	for _, a := range alerts {
+		byInstallation[a.InstallationID] = a
+	}
+	res := &CheckResult{Exposed: len(exposures)}
+	current := map[int64]bool{}
+	for _, e := range exposures {
+		current[e.InstallationID] = true
+		if e.State == store.EOLStateExpired {
+			res.Expired++
+		} else {
+			res.Approaching++
+		}
+		alert, known := byInstallation[e.InstallationID]
+		switch {
+		case !known:
+			alert = store.SoftwareEOLAlert{InstallationID: e.InstallationID}
+			res.Raised++
+		case alert.ResolvedAt != nil:
+			res.Reopened++
+			if alert.FindingID != nil {
+				s.updateFinding(ctx, *alert.FindingID, e, "resolved", "open", userID)
+			}
+			if alert.TaskID != nil && !s.taskOpen(ctx, *alert.TaskID) {
+				alert.TaskID = nil
+			}
+		case alert.State != e.State || !alert.EOLDate.Equal(e.EOLDate) || alert.VersionText != e.Version:
+			if alert.State != e.State {
+				res.Escalated++
+			}
+			if alert.FindingID != nil {
+				s.updateFinding(ctx, *alert.FindingID, e, "", "", userID)
+			}
+		default:
+			continue
+		}
+		alert.AssetID = e.AssetID
+		alert.ProductID = e.ProductID
+		alert.VersionText = e.Version
+		alert.EOLDate = e.EOLDate
+		alert.State = e.State
+		if alert.FindingID == nil && s.mode() != ModeTask {
+			id, err := s.createFinding(ctx, e, userID)
+			if err != nil {
+				return nil, err
+			}
+			alert.FindingID = &id
+		}
+		if alert.TaskID == nil && s.mode() != ModeFinding && s.tasks != nil {
+			if id := s.createTask(ctx, e, userID); id > 0 {
+				alert.TaskID = &id
+				res.TasksCreated++
+			}
+		}
+		if err := s.store.SaveEOLAlert(ctx, &alert); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range alerts {
+		if a.ResolvedAt != nil || current[a.InstallationID] {
+			continue
+		}
+		if err := s.store.ResolveEOLAlert(ctx, a.ID, now); err != nil {
+			return nil, err
+		}
+		res.Resolved++
+		if a.FindingID != nil {
+			s.resolveFinding(ctx, *a.FindingID, userID)
+		}
+		if a.TaskID != nil {
+			s.closeTask(ctx, *a.TaskID, userID)
+		}
+	}
+	s.log(ctx, username, "software.eol.check", fmt.Sprintf("exposed=%d expired=%d approaching=%d raised=%d escalated=%d reopened=%d resolved=%d tasks=%d",
+		res.Exposed, res.Expired, res.Approaching, res.Raised, res.Escalated, res.Reopened, res.Resolved, res.TasksCreated))
+	return res, nil
+}
+
+type ImportResult struct {
+	Cycles          int `json:"cycles"`
+	Matched         int `json:"matched"`
+	Updated         int `json:"updated"`
+	VersionsCreated int `json:"versions_created"`
+	Unmatched       int `json:"unmatched"`
+}
+
+// ImportEndOfLife sets end-of-life (and missing release) dates on the product versions from an
+// endoflife.date style file. With createVersions every cycle without a matching version is added.
+func (s *Service) ImportEndOfLife(ctx context.Context, productID int64, data []byte, createVersions bool, now time.Time, username string, userID int64) (*ImportResult, error) {
+	cycles, err := ParseEndOfLife(data)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.software.ListVersions(ctx, productID, false)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{Cycles: len(cycles)}
+	used := map[string]bool{}
+	for i := range versions {
+		v := versions[i]
+		c, ok := MatchCycle(cycles, v.Version)
+		if !ok {
+			res.Unmatched++
+			continue
+		}
+		res.Matched++
+		used[c.Cycle] = true
+		eolDate := cycleEOL(c, now)
+		if c.EOL == nil && v.EOLDate != nil && !v.EOLDate.After(now) {
+			eolDate = v.EOLDate
+		}
+		changed := false
+		if eolDate != nil && !sameDate(v.EOLDate, eolDate) {
+			v.EOLDate = eolDate
+			changed = true
+		}
+		if v.ReleaseDate == nil && c.ReleaseDate != nil && strings.EqualFold(v.Version, c.Cycle) {
+			v.ReleaseDate = c.ReleaseDate
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if userID > 0 {
+			v.UpdatedBy = &userID
+		}
+		if err := s.software.UpdateVersion(ctx, &v); err != nil {
+			return nil, err
+		}
+		res.Updated++
+	}
+	if createVersions {
+		for _, c := range cycles {
+			if used[c.Cycle] {
+				continue
+			}
+			v := &store.SoftwareVersion{ProductID: productID, Version: c.Cycle, ReleaseDate: c.ReleaseDate, EOLDate: cycleEOL(c, now)}
+			if userID > 0 {
+				v.CreatedBy = &userID
+				v.UpdatedBy = &userID
+			}
+			if _, err := s.software.CreateVersion(ctx, v); err != nil {
+				return nil, err
+			}
+			res.VersionsCreated++
+		}
+	}
+	s.log(ctx, username, "software.eol.import", fmt.Sprintf("%d|cycles=%d matched=%d updated=%d created=%d", productID, res.Cycles, res.Matched, res.Updated, res.VersionsCreated))
+	return res, nil
+}
+
+func (s *Service) mode() string {
+	switch s.cfg.EOLAlertMode {
+	case ModeTask, ModeBoth:
+		return s.cfg.EOLAlertMode
+	}
+	return ModeFinding
+}
+
+func (s *Service) createFinding(ctx context.Context, e Exposure, userID int64) (int64, error) {
+	f := &store.Finding{Status: "open", FindingType: FindingType, Tags: []string{"EOL"}}
+	fillFinding(f, e)
+	if userID > 0 {
+		f.CreatedBy = &userID
+		f.UpdatedBy = &userID
+	}
+	id, err := s.findings.CreateFinding(ctx, f)
+	if err != nil {
+		return 0, err
+	}
+	if s.links != nil {
+		source := strconv.FormatInt(id, 10)
+		_, _ = s.links.Add(ctx, &store.EntityLink{SourceType: "finding", SourceID: source, TargetType: "asset", TargetID: strconv.FormatInt(e.AssetID, 10), RelationType: "affects"})
+		_, _ = s.links.Add(ctx, &store.EntityLink{SourceType: "finding", SourceID: source, TargetType: "software", TargetID: strconv.FormatInt(e.ProductID, 10), RelationType: "affects"})
+	}
+	return id, nil
+}
+
+// updateFinding refreshes an EOL finding after the state or date changed. When from is set the
+// status is only moved from -> to; accepted risks and closed findings are never touched.
+func (s *Service) updateFinding(ctx context.Context, id int64, e Exposure, from, to string, userID int64) {
+	f, err := s.findings.GetFinding(ctx, id)
+	if err != nil || f == nil || f.DeletedAt != nil || f.FindingType != FindingType {
+		return
+	}
+	if from != "" {
+		if f.Status != from {
+			return
+		}
+		f.Status = to
+	} else if f.Status != "open" && f.Status != "in_progress" {
+		return
+	}
+	fillFinding(f, e)
+	if userID > 0 {
+		f.UpdatedBy = &userID
+	}
+	_ = s.findings.UpdateFinding(ctx, f)
+}
+
+func (s *Service) resolveFinding(ctx context.Context, id int64, userID int64) {
+	f, err := s.findings.GetFinding(ctx, id)
+	if err != nil || f == nil || f.DeletedAt != nil || f.FindingType != FindingType {
+		return
+	}
+	if f.Status != "open" && f.Status != "in_progress" {
+		return
+	}
+	f.Status = "resolved"
+	if userID > 0 {
+		f.UpdatedBy = &userID
+	}
+	_ = s.findings.UpdateFinding(ctx, f)
+}
+
+// createTask opens a task for the asset owner (assigned when the owner is a known username)
+// in the configured board and column.
+func (s *Service) createTask(ctx context.Context, e Exposure, userID int64) int64 {
+	boardID, columnID := s.taskDestination(ctx)
+	if boardID == 0 || columnID == 0 {
+		return 0
+	}
+	var assignees []int64
+	if owner := strings.TrimSpace(e.AssetOwner); owner != "" && s.users != nil {
+		if u, _, err := s.users.FindByUsername(ctx, owner); err == nil && u != nil && u.Active {
+			assignees = append(assignees, u.ID)
+		}
+	}
+	priority := tasks.PriorityMedium
+	if e.State == store.EOLStateExpired {
+		priority = tasks.PriorityHigh
+	}
+	due := e.EOLDate
+	task := &tasks.Task{
+		BoardID:     boardID,
+		ColumnID:    columnID,
+		Title:       alertTitle(e),
+		Description: alertDescription(e),
+		Priority:    priority,
+		DueDate:     &due,
+	}
+	if userID > 0 {
+		task.CreatedBy = &userID
+	}
+	id, err := s.tasks.CreateTask(ctx, task, assignees)
+	if err != nil {
+		return 0
+	}
+	_, _ = s.tasks.AddEntityLink(ctx, &tasks.Link{SourceType: "task", SourceID: strconv.FormatInt(id, 10), TargetType: "asset", TargetID: strconv.FormatInt(e.AssetID, 10)})
+	return id
+}
+
+// taskDestination resolves software.eol_task_board_id / eol_task_column_id. Without a board the
+// first board with an open column is used; without a column the first open column of the board.
+// A configured board or column that is missing, inactive or final yields no destination.
+func (s *Service) taskDestination(ctx context.Context) (int64, int64) {
+	var boardIDs []int64
+	if s.cfg.EOLTaskBoardID > 0 {
+		board, err := s.tasks.GetBoard(ctx, s.cfg.EOLTaskBoardID)
+		if err != nil || board == nil || !board.IsActive {
+			return 0, 0
+		}
+		boardIDs = append(boardIDs, board.ID)
+	} else {
+		boards, err := s.tasks.ListBoards(ctx, tasks.BoardFilter{})
+		if err != nil {
+			return 0, 0
+		}
+		for _, board := range boards {
+			boardIDs = append(boardIDs, board.ID)
+		}
+	}
+	for _, boardID := range boardIDs {
+		columns, err := s.tasks.ListColumns(ctx, boardID, false)
+		if err != nil {
+			continue
+		}
+		for _, col := range columns {
+			if !col.IsActive || col.IsFinal {
+				continue
+			}
+			if s.cfg.EOLTaskColumnID > 0 && col.ID != s.cfg.EOLTaskColumnID {
+				continue
+			}
+			return boardID, col.ID
+		}
+		if s.cfg.EOLTaskColumnID > 0 {
+			return 0, 0
+		}
+	}
+	return 0, 0
+}
+
+func (s *Service) taskOpen(ctx context.Context, id int64) bool {
+	if s.tasks == nil {
+		return false
+	}
+	task, err := s.tasks.GetTask(ctx, id)
+	return err == nil && task != nil && task.ClosedAt == nil && !task.IsArchived
+}
+
+// closeTask closes the task of a resolved alert; tasks already closed or archived are left alone.
+func (s *Service) closeTask(ctx context.Context, id int64, userID int64) {
+	if !s.taskOpen(ctx, id) {
+		return
+	}
+	_, _ = s.tasks.CloseTask(ctx, id, userID)
+}
+
+func (s *Service) log(ctx context.Context, username, action, details string) {
+	if s.audits != nil {
+		_ = s.audits.Log(ctx, username, action, details)
+	}
+}
+
+func fillFinding(f *store.Finding, e Exposure) {
+	f.Title = alertTitle(e)
+	f.DescriptionMD = alertDescription(e)
+	f.Owner = e.AssetOwner
+	f.Severity = "medium"
+	if e.State == store.EOLStateExpired {
+		f.Severity = "high"
+	}
+	due := e.EOLDate
+	f.DueAt = &due
+}
+
+func alertTitle(e Exposure) string {
+	return truncate(fmt.Sprintf("End of life: %s %s on %s", e.ProductName, e.Version, e.AssetName), 200)
+}
+
+func alertDescription(e Exposure) string {
+	var b strings.Builder
+	if e.State == store.EOLStateExpired {
+		fmt.Fprintf(&b, "Version reached end of life on %s.\n\n", e.EOLDate.Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(&b, "Version reaches end of life on %s (in %d days).\n\n", e.EOLDate.Format("2006-01-02"), e.DaysLeft)
+	}
+	fmt.Fprintf(&b, "- Asset: %s\n", e.AssetName)
+	if e.AssetOwner != "" {
+		fmt.Fprintf(&b, "- Owner: %s\n", e.AssetOwner)
+	}
+	fmt.Fprintf(&b, "- Software: %s %s\n", strings.TrimSpace(e.ProductVendor+" "+e.ProductName), e.Version)
+	return strings.TrimSpace(b.String())
+}
+
+func classify(item store.SoftwareEOLExposure, today time.Time) Exposure {
+	days := int(truncateDay(item.EOLDate).Sub(today).Hours() / 24)
+	state := store.EOLStateApproaching
+	if days <= 0 {
+		state = store.EOLStateExpired
+	}
+	return Exposure{SoftwareEOLExposure: item, State: state, DaysLeft: days}
+}
+
+// cycleEOL returns the end-of-life date of a cycle; cycles marked "eol": true without a date
+// are treated as ended on the import day.
+func cycleEOL(c Cycle, now time.Time) *time.Time {
+	if c.EOL != nil {
+		return c.EOL
+	}
+	if c.EOLReached {
+		t := truncateDay(now)
+		return &t
+	}
+	return nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return truncateDay(*a).Equal(truncateDay(*b))
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,26 @@
+package findings
+
+import (
+	"context"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/schedule"
+	"berkut-scc/core/utils"
+)
+
+// Scheduler runs the SLA evaluation once per UTC day.
+type Scheduler struct {
+	*schedule.Daily
+}
+
+func NewScheduler(cfg config.SchedulerConfig, svc *Service, logger *utils.Logger) *Scheduler {
+	var run func(context.Context, time.Time) error
+	if svc != nil {
+		run = func(ctx context.Context, now time.Time) error {
+			_, err := svc.Evaluate(ctx, now, "scheduler")
+			return err
+		}
+	}
+	return &Scheduler{Daily: schedule.NewDaily("findings.sla", cfg, run, logger)}
+}
@@ -0,0 +1,430 @@
+package findings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/notify"
+	"berkut-scc/core/schedule"
+	"berkut-scc/core/store"
+)
+
+// SLA states reported for open findings.
+const (
+	StateNone    = "none"
+	StateOnTrack = "on_track"
+	StateDueSoon = "due_soon"
+	StateOverdue = "overdue"
+	StatePaused  = "paused"
+)
+
+var (
+	ErrJustificationRequired = errors.New("justification required")
+	ErrApproverInvalid       = errors.New("approver invalid")
+	ErrExpiryInvalid         = errors.New("expiry invalid")
+	ErrExceptionActive       = errors.New("exception already active")
+	ErrFindingClosed         = errors.New("finding closed")
+)
+
+type Service struct {
+	store    store.FindingsStore
+	sla      store.FindingSLAStore
+	users    store.UsersStore
+	audits   store.AuditStore
+	notifier *notify.Service
+	// calendars resolves the business calendar of the SLA policy.
+	calendars schedule.CalendarSource
+}
+
+func NewService(fs store.FindingsStore, sla store.FindingSLAStore, users store.UsersStore, audits store.AuditStore) *Service {
+	return &Service{store: fs, sla: sla, users: users, audits: audits}
+}
+
+func (s *Service) SetNotifier(n *notify.Service) {
+	if s == nil {
+		return
+	}
+	s.notifier = n
+}
+
+// SetCalendars sets the business calendars SLA days may be counted in.
+func (s *Service) SetCalendars(calendars schedule.CalendarSource) {
+	if s == nil {
+		return
+	}
+	s.calendars = calendars
+}
+
+func (s *Service) Policy(ctx context.Context) (*store.FindingSLAPolicy, error) {
+	return s.sla.GetSLAPolicy(ctx)
+}
+
+func (s *Service) SavePolicy(ctx context.Context, p *store.FindingSLAPolicy, username string) error {
+	if err := s.sla.SaveSLAPolicy(ctx, p); err != nil {
+		return err
+	}
+	s.log(ctx, username, "finding.sla.policy.update", formatDays(p.Days))
+	return nil
+}
+
+// DefaultDueAt returns the policy due date for a finding of severity opened at from.
+func (s *Service) DefaultDueAt(ctx context.Context, severity string, from time.Time) *time.Time {
+	if s == nil {
+		return nil
+	}
+	p, err := s.sla.GetSLAPolicy(ctx)
+	if err != nil {
+		return nil
+	}
+	return s.dueAt(ctx, p, severity, from)
+}
+
+// dueAt applies the policy in calendar days, or in business days when the policy has a
+// calendar. A calendar that cannot be loaded falls back to weekends only.
+func (s *Service) dueAt(ctx context.Context, p *store.FindingSLAPolicy, severity string, from time.Time) *time.Time {
+	if p.CalendarID <= 0 {
+		return p.DueAt(severity, from)
+	}
+	var cal *schedule.Calendar
+	if s.calendars != nil {
+		cal, _ = s.calendars.BusinessCalendar(ctx, p.CalendarID)
+	}
+	return p.BusinessDueAt(severity, from, cal)
+}
+
+// State classifies a finding against the policy at now.
+func State(f store.Finding, p *store.FindingSLAPolicy, now time.Time) string {
+	if f.SLAPausedAt != nil {
+		return StatePaused
+	}
+	if f.DueAt == nil || (f.Status != "open" && f.Status != "in_progress") {
+		return StateNone
+	}
+	if !now.Before(*f.DueAt) {
+		return StateOverdue
+	}
+	if p != nil && p.DueSoonDays > 0 && f.DueAt.Sub(now) <= time.Duration(p.DueSoonDays)*24*time.Hour {
+		return StateDueSoon
+	}
+	return StateOnTrack
+}
+
+type EvaluateResult struct {
+	Open              int `json:"open"`
+	DueDatesSet       int `json:"due_dates_set"`
+	Overdue           int `json:"overdue"`
+	NewlyOverdue      int `json:"newly_overdue"`
+	Escalated         int `json:"escalated"`
+	Cleared           int `json:"cleared"`
+	ExceptionsExpired int `json:"exceptions_expired"`
+}
+
+// Evaluate expires risk acceptances, fills missing due dates from the policy, marks open
+// findings past their due date as overdue (notifying the owner once) and escalates findings
+// that stay overdue longer than the escalation period.
+func (s *Service) Evaluate(ctx context.Context, now time.Time, username string) (*EvaluateResult, error) {
+	now = now.UTC()
+	policy, err := s.sla.GetSLAPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &EvaluateResult{}
+	expired, err := s.sla.ListExpiredFindingExceptions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		if err := s.closeException(ctx, &expired[i], store.FindingExceptionExpired, now, nil); err != nil {
+			if errors.Is(err, store.ErrConflict) {
+				continue
+			}
+			return nil, err
+		}
+		res.ExceptionsExpired++
+		s.log(ctx, username, "finding.exception.expire", fmt.Sprintf("finding=%d exception=%d", expired[i].FindingID, expired[i].ID))
+	}
+	cleared, err := s.sla.ClearClosedFindingOverdue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res.Cleared = int(cleared)
+	items, err := s.sla.ListOpenFindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range items {
+		if f.SLAPausedAt != nil {
+			continue
+		}
+		res.Open++
+		if f.DueAt == nil {
+			if due := s.dueAt(ctx, policy, f.Severity, f.CreatedAt); due != nil {
+				if err := s.sla.SetFindingDueAt(ctx, f.ID, *due); err != nil {
+					return nil, err
+				}
+				f.DueAt = due
+				res.DueDatesSet++
+			}
+		}
+		if State(f, policy, now) != StateOverdue {
+			if f.OverdueAt != nil {
+				if err := s.sla.ClearFindingOverdue(ctx, f.ID); err != nil {
+					return nil, err
+				}
+				res.Cleared++
+			}
+			continue
+		}
+		res.Overdue++
+		if f.OverdueAt == nil {
+			if err := s.sla.MarkFindingOverdue(ctx, f.ID, now); err != nil {
+				return nil, err
+			}
+			res.NewlyOverdue++
+			s.notify(ctx, f, notify.EventFindingOverdue, "notifications.finding.overdue", "notifications.finding.due",
+				map[string]string{"date": f.DueAt.Format("2006-01-02"), "severity": f.Severity}, nil, s.ownerIDs(ctx, f)...)
+			s.log(ctx, username, "finding.sla.overdue", fmt.Sprintf("finding=%d due=%s", f.ID, f.DueAt.Format("2006-01-02")))
+		}
+		if policy.EscalationDays > 0 && f.EscalatedAt == nil && !now.Before(f.DueAt.AddDate(0, 0, policy.EscalationDays)) {
+			if err := s.sla.MarkFindingEscalated(ctx, f.ID, now); err != nil {
+				return nil, err
+			}
+			res.Escalated++
+			s.notify(ctx, f, notify.EventFindingOverdue, "notifications.finding.escalated", "notifications.finding.overdueSince",
+				map[string]string{"date": f.DueAt.Format("2006-01-02"), "severity": f.Severity}, nil, s.escalationIDs(policy, f)...)
+			s.log(ctx, username, "finding.sla.escalate", fmt.Sprintf("finding=%d", f.ID))
+		}
+	}
+	s.log(ctx, username, "finding.sla.evaluate", fmt.Sprintf("open=%d overdue=%d new=%d escalated=%d cleared=%d due_set=%d expired=%d",
+		res.Open, res.Overdue, res.NewlyOverdue, res.Escalated, res.Cleared, res.DueDatesSet, res.ExceptionsExpired))
+	return res, nil
+}
+
+// RequestException files a pending risk acceptance for f with the designated approver.
+func (s *Service) RequestException(ctx context.Context, f *store.Finding, e *store.FindingException, actor *store.User, now time.Time) error {
+	if f == nil || e == nil || actor == nil {
+		return errors.New("bad request")
+	}
+	if f.DeletedAt != nil || store.FindingStatusClosed(f.Status) {
+		return ErrFindingClosed
+	}
+	e.Justification = strings.TrimSpace(e.Justification)
+	if e.Justification == "" {
+		return ErrJustificationRequired
+	}
+	if e.ApproverID == nil || *e.ApproverID == actor.ID {
+		return ErrApproverInvalid
+	}
+	approver, _, err := s.users.Get(ctx, *e.ApproverID)
+	if err != nil {
+		return err
+	}
+	if approver == nil || !approver.Active {
+		return ErrApproverInvalid
+	}
+	policy, err := s.sla.GetSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	if !e.ExpiresAt.After(now) || e.ExpiresAt.After(now.AddDate(0, 0, policy.MaxExceptionDays)) {
+		return ErrExpiryInvalid
+	}
+	active, err := s.sla.ActiveFindingException(ctx, f.ID)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return ErrExceptionActive
+	}
+	e.FindingID = f.ID
+	e.RequestedBy = &actor.ID
+	if _, err := s.sla.CreateFindingException(ctx, e); err != nil {
+		return err
+	}
+	s.notify(ctx, *f, notify.EventFindingException, "notifications.finding.exceptionRequested", "notifications.finding.exceptionUntil",
+		map[string]string{"user": actor.Username, "date": e.ExpiresAt.Format("2006-01-02"), "justification": e.Justification}, &actor.ID, approver.ID)
+	s.log(ctx, actor.Username, "finding.exception.request", fmt.Sprintf("finding=%d exception=%d approver=%d", f.ID, e.ID, approver.ID))
+	return nil
+}
+
+// DecideException approves (pausing the SLA of the finding) or rejects a pending exception.
+func (s *Service) DecideException(ctx context.Context, e *store.FindingException, approve bool, comment string, actor *store.User, now time.Time) error {
+	f, err := s.store.GetFinding(ctx, e.FindingID)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return ErrFindingClosed
+	}
+	status := store.FindingExceptionRejected
+	prev := ""
+	if approve {
+		status = store.FindingExceptionApproved
+		prev = f.Status
+	}
+	if err := s.sla.DecideFindingException(ctx, e.ID, status, prev, comment, now); err != nil {
+		return err
+	}
+	if approve {
+		if err := s.sla.PauseFindingSLA(ctx, f.ID, now); err != nil {
+			return err
+		}
+	}
+	e.Status = status
+	title, action := "notifications.finding.exceptionRejected", "finding.exception.reject"
+	if approve {
+		title, action = "notifications.finding.exceptionApproved", "finding.exception.approve"
+	}
+	body := ""
+	if comment = strings.TrimSpace(comment); comment != "" {
+		body = "notifications.finding.comment"
+	}
+	recipients := s.ownerIDs(ctx, *f)
+	if e.RequestedBy != nil {
+		recipients = append(recipients, *e.RequestedBy)
+	}
+	s.notify(ctx, *f, notify.EventFindingException, title, body, map[string]string{"comment": comment}, &actor.ID, recipients...)
+	s.log(ctx, actor.Username, action, fmt.Sprintf("finding=%d exception=%d", f.ID, e.ID))
+	return nil
+}
+
+// RevokeException withdraws a pending or approved exception; an approved one resumes the SLA.
+func (s *Service) RevokeException(ctx context.Context, e *store.FindingException, actor *store.User, now time.Time) error {
+	if err := s.closeException(ctx, e, store.FindingExceptionRevoked, now, &actor.ID); err != nil {
+		return err
+	}
+	s.log(ctx, actor.Username, "finding.exception.revoke", fmt.Sprintf("finding=%d exception=%d", e.FindingID, e.ID))
+	return nil
+}
+
+// closeException ends e and, when it was approved, restores the previous status and shifts the
+// due date by the paused period so the remaining time is preserved.
+func (s *Service) closeException(ctx context.Context, e *store.FindingException, status string, now time.Time, actorID *int64) error {
+	wasApproved := e.Status == store.FindingExceptionApproved
+	if err := s.sla.CloseFindingException(ctx, e.ID, status, now); err != nil {
+		return err
+	}
+	e.Status = status
+	if !wasApproved {
+		return nil
+	}
+	f, err := s.store.GetFinding(ctx, e.FindingID)
+	if err != nil || f == nil || f.SLAPausedAt == nil {
+		return err
+	}
+	var due *time.Time
+	if f.DueAt != nil {
+		remaining := f.DueAt.Sub(*f.SLAPausedAt)
+		if remaining < 0 {
+			remaining = 0
+		}
+		d := now.Add(remaining)
+		due = &d
+	}
+	prev := e.PrevStatus
+	if prev == "" || prev == "accepted_risk" {
+		prev = "open"
+	}
+	if err := s.sla.ResumeFindingSLA(ctx, f.ID, prev, due); err != nil {
+		return err
+	}
+	title := "notifications.finding.exceptionExpired"
+	if status == store.FindingExceptionRevoked {
+		title = "notifications.finding.exceptionRevoked"
+	}
+	recipients := s.ownerIDs(ctx, *f)
+	if e.RequestedBy != nil {
+		recipients = append(recipients, *e.RequestedBy)
+	}
+	s.notify(ctx, *f, notify.EventFindingException, title, "notifications.finding.slaResumed", nil, actorID, recipients...)
+	return nil
+}
+
+func (s *Service) ownerIDs(ctx context.Context, f store.Finding) []int64 {
+	owner := strings.TrimSpace(f.Owner)
+	if owner == "" || s.users == nil {
+		return nil
+	}
+	u, _, err := s.users.FindByUsername(ctx, owner)
+	if err != nil || u == nil || !u.Active {
+		return nil
+	}
+	return []int64{u.ID}
+}
+
+func (s *Service) escalationIDs(p *store.FindingSLAPolicy, f store.Finding) []int64 {
+	if len(p.EscalateTo) > 0 {
+		return p.EscalateTo
+	}
+	if f.CreatedBy != nil {
+		return []int64{*f.CreatedBy}
+	}
+	return nil
+}
+
+// notificationTexts are the English texts of the notification keys; the GUI renders the keys in
+// the reader's language, external channels get these.
+var notificationTexts = map[string]string{
+	"notifications.finding.overdue":            "Finding is overdue: {title}",
+	"notifications.finding.escalated":          "Overdue finding escalated: {title}",
+	"notifications.finding.exceptionRequested": "Risk acceptance requested: {title}",
+	"notifications.finding.exceptionApproved":  "Risk acceptance approved: {title}",
+	"notifications.finding.exceptionRejected":  "Risk acceptance rejected: {title}",
+	"notifications.finding.exceptionExpired":   "Risk acceptance expired: {title}",
+	"notifications.finding.exceptionRevoked":   "Risk acceptance revoked: {title}",
+	"notifications.finding.due":                "Due {date} ({severity})",
+	"notifications.finding.overdueSince":       "Overdue since {date} ({severity})",
+	"notifications.finding.exceptionUntil":     "{user} until {date}: {justification}",
+	"notifications.finding.comment":            "{comment}",
+	"notifications.finding.slaResumed":         "SLA resumed",
+}
+
+func notificationText(key string, params map[string]string) string {
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(notificationTexts[key])
+}
+
+// notify sends a finding notification; titleKey and bodyKey are notificationTexts keys, params
+// fill their placeholders ({title} is the finding title).
+func (s *Service) notify(ctx context.Context, f store.Finding, event, titleKey, bodyKey string, params map[string]string, actorID *int64, userIDs ...int64) {
+	if s.notifier == nil || len(userIDs) == 0 {
+		return
+	}
+	all := map[string]string{"title": f.Title}
+	for name, value := range params {
+		all[name] = value
+	}
+	s.notifier.Notify(ctx, store.Notification{
+		EventType:  event,
+		Title:      notificationText(titleKey, all),
+		Body:       notificationText(bodyKey, all),
+		TitleKey:   titleKey,
+		BodyKey:    bodyKey,
+		Params:     all,
+		EntityType: "finding",
+		EntityID:   strconv.FormatInt(f.ID, 10),
+		Link:       fmt.Sprintf("/registry/findings?finding=%d", f.ID),
+		ActorID:    actorID,
+	}, userIDs...)
+}
+
+func (s *Service) log(ctx context.Context, username, action, details string) {
+	if s.audits != nil {
+		_ = s.audits.Log(ctx, username, action, details)
+	}
+}
+
+func formatDays(days map[string]int) string {
+	var parts []string
+	for _, sev := range []string{"critical", "high", "medium", "low"} {
+		parts = append(parts, fmt.Sprintf("%s=%d", sev, days[sev]))
+	}
+	return strings.Join(parts, " ")
+}
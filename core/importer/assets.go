@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+// AssetIndex matches imported records to assets: by name first, then by any shared
+// IP address.
+type AssetIndex struct {
+	byName map[string]*store.Asset
+	byIP   map[string]*store.Asset
+}
+
+func NewAssetIndex(assets []store.Asset) *AssetIndex {
+	idx := &AssetIndex{byName: map[string]*store.Asset{}, byIP: map[string]*store.Asset{}}
+	for i := range assets {
+		idx.Put(&assets[i])
+	}
+	return idx
+}
+
+// LoadAssetIndex indexes every active asset.
+func LoadAssetIndex(ctx context.Context, as store.AssetsStore) (*AssetIndex, error) {
+	const page = 500
+	var all []store.Asset
+	for offset := 0; ; offset += page {
+		items, err := as.ListAssets(ctx, store.AssetFilter{Limit: page, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < page {
+			break
+		}
+	}
+	return NewAssetIndex(all), nil
+}
+
+func (x *AssetIndex) Match(name string, ips []string) *store.Asset {
+	if a, ok := x.byName[normalizeAssetName(name)]; ok {
+		return a
+	}
+	for _, ip := range ips {
+		if a, ok := x.byIP[normalizeIP(ip)]; ok {
+			return a
+		}
+	}
+	return nil
+}
+
+// Put adds or re-indexes an asset, so later rows of the same import match it.
+func (x *AssetIndex) Put(a *store.Asset) {
+	if key := normalizeAssetName(a.Name); key != "" {
+		x.byName[key] = a
+	}
+	for _, ip := range a.IPAddresses {
+		if key := normalizeIP(ip); key != "" {
+			x.byIP[key] = a
+		}
+	}
+}
+
+func normalizeAssetName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func normalizeIP(raw string) string {
+	ip := net.ParseIP(strings.TrimSpace(raw))
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// MergeIPs appends the addresses from extra that are not in base yet.
+func MergeIPs(base, extra []string) []string {
+	out := append([]string{}, base...)
+	seen := map[string]bool{}
+	for _, ip := range base {
+		seen[normalizeIP(ip)] = true
+	}
+	for _, ip := range extra {
+		key := normalizeIP(ip)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, key)
+	}
+	return out
+}
+
+// portNotesHeader opens the block of open ports maintained by Nmap imports in the
+// asset description; the block runs until the next blank line.
+const portNotesHeader = "Open ports (nmap"
+
+// MergePortNotes replaces the previous port block of the description with a new one
+// and leaves the rest of the text untouched.
+func MergePortNotes(description, notes string) string {
+	lines := strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n")
+	var kept []string
+	skipping := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, portNotesHeader) {
+			skipping = true
+			continue
+		}
+		if skipping {
+			if strings.TrimSpace(line) == "" {
+				skipping = false
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	rest := strings.TrimSpace(strings.Join(kept, "\n"))
+	if notes == "" {
+		return rest
+	}
+	if rest == "" {
+		return notes
+	}
+	return rest + "\n\n" + notes
+}
@@ -0,0 +1,296 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// FindingStores groups the stores a vulnerability report import writes to.
+type FindingStores struct {
+	Assets   store.AssetsStore
+	Findings store.FindingsStore
+	Links    store.EntityLinksStore
+}
+
+type FindingPlanItem struct {
+	Action    string   `json:"action"`
+	FindingID int64    `json:"finding_id,omitempty"`
+	Title     string   `json:"title"`
+	Severity  string   `json:"severity"`
+	Host      string   `json:"host"`
+	AssetID   int64    `json:"asset_id,omitempty"`
+	AssetName string   `json:"asset_name"`
+	NewAsset  bool     `json:"new_asset"`
+	Ports     []string `json:"ports,omitempty"`
+	CVEs      []string `json:"cves,omitempty"`
+	Reopened  bool     `json:"reopened,omitempty"`
+
+	issue ScanIssue
+}
+
+// FindingPlan lists the findings a Nessus or OpenVAS report creates or updates.
+type FindingPlan struct {
+	Format        string            `json:"format"`
+	Issues        int               `json:"issues"`
+	Informational int               `json:"informational"`
+	Added         int               `json:"added"`
+	Updated       int               `json:"updated"`
+	Unchanged     int               `json:"unchanged"`
+	NewAssets     int               `json:"new_assets"`
+	Applied       bool              `json:"applied"`
+	Items         []FindingPlanItem `json:"items"`
+}
+
+// BuildFindingPlan groups the report by host and vulnerability, resolves hosts to
+// assets by hostname or IP and matches each vulnerability to a finding with the same
+// title already linked to that asset.
+func BuildFindingPlan(ctx context.Context, st FindingStores, report *VulnReport) (*FindingPlan, error) {
+	idx, err := LoadAssetIndex(ctx, st.Assets)
+	if err != nil {
+		return nil, err
+	}
+	plan := &FindingPlan{Format: report.Format, Issues: len(report.Issues), Informational: report.Informational, Items: []FindingPlanItem{}}
+	byKey := map[string]int{}
+	linked := map[int64][]store.Finding{}
+	newAssets := map[string]bool{}
+	for _, issue := range report.Issues {
+		key := issue.Host + "|" + strings.ToLower(issue.Title)
+		if i, ok := byKey[key]; ok {
+			mergeIssue(&plan.Items[i], issue)
+			continue
+		}
+		item := FindingPlanItem{Title: issue.Title, Severity: issue.Severity, Host: issue.Host, issue: issue}
+		item.Ports = appendPort(nil, issue)
+		item.CVEs = issue.CVEs
+		asset := idx.Match(issue.Hostname, []string{issue.Host})
+		if asset != nil {
+			item.AssetID = asset.ID
+			item.AssetName = asset.Name
+		} else {
+			item.NewAsset = true
+			item.AssetName = issue.Host
+			if issue.Hostname != "" {
+				item.AssetName = issue.Hostname
+			}
+			if !newAssets[issue.Host] {
+				newAssets[issue.Host] = true
+				plan.NewAssets++
+			}
+		}
+		item.Action = ActionAdd
+		if item.AssetID > 0 {
+			findings, ok := linked[item.AssetID]
+			if !ok {
+				findings, err = LinkedFindings(ctx, st, item.AssetID)
+				if err != nil {
+					return nil, err
+				}
+				linked[item.AssetID] = findings
+			}
+			for _, f := range findings {
+				if strings.EqualFold(strings.TrimSpace(f.Title), item.Title) {
+					item.FindingID = f.ID
+					item.Action = ActionUpdate
+					item.Reopened = f.Status == "resolved"
+					break
+				}
+			}
+		}
+		byKey[key] = len(plan.Items)
+		plan.Items = append(plan.Items, item)
+	}
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		if item.Action == ActionUpdate {
+			f, err := st.Findings.GetFinding(ctx, item.FindingID)
+			if err != nil {
+				return nil, err
+			}
+			if f != nil && !item.Reopened && f.Severity == item.Severity && f.DescriptionMD == issueDescription(*item) {
+				item.Action = ActionUnchanged
+			}
+		}
+		switch item.Action {
+		case ActionAdd:
+			plan.Added++
+		case ActionUpdate:
+			plan.Updated++
+		case ActionUnchanged:
+			plan.Unchanged++
+		}
+	}
+	return plan, nil
+}
+
+// LinkedFindings returns the active findings linked to an asset.
+func LinkedFindings(ctx context.Context, st FindingStores, assetID int64) ([]store.Finding, error) {
+	links, err := st.Links.ListByTarget(ctx, "asset", strconv.FormatInt(assetID, 10))
+	if err != nil {
+		return nil, err
+	}
+	var out []store.Finding
+	for _, l := range links {
+		if l.SourceType != "finding" {
+			continue
+		}
+		id, err := strconv.ParseInt(l.SourceID, 10, 64)
+		if err != nil {
+			continue
+		}
+		f, err := st.Findings.GetFinding(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil && f.DeletedAt == nil {
+			out = append(out, *f)
+		}
+	}
+	return out, nil
+}
+
+func mergeIssue(item *FindingPlanItem, issue ScanIssue) {
+	item.Ports = appendPort(item.Ports, issue)
+	item.CVEs = normalizeCVEs(append(item.CVEs, issue.CVEs...))
+	if severityRank[issue.Severity] > severityRank[item.Severity] {
+		item.Severity = issue.Severity
+	}
+	if issue.CVSS > item.issue.CVSS {
+		item.issue.CVSS = issue.CVSS
+	}
+}
+
+var severityRank = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+func appendPort(ports []string, issue ScanIssue) []string {
+	if issue.Port <= 0 {
+		return ports
+	}
+	p := fmt.Sprintf("%d/%s", issue.Port, issue.Protocol)
+	for _, existing := range ports {
+		if existing == p {
+			return ports
+		}
+	}
+	return append(ports, p)
+}
+
+func issueDescription(item FindingPlanItem) string {
+	var b strings.Builder
+	host := item.Host
+	if item.issue.Hostname != "" {
+		host += " (" + item.issue.Hostname + ")"
+	}
+	fmt.Fprintf(&b, "**Host:** %s\n", host)
+	if len(item.Ports) > 0 {
+		fmt.Fprintf(&b, "**Ports:** %s\n", strings.Join(item.Ports, ", "))
+	}
+	if item.issue.PluginID != "" {
+		fmt.Fprintf(&b, "**Plugin:** %s\n", item.issue.PluginID)
+	}
+	if len(item.CVEs) > 0 {
+		fmt.Fprintf(&b, "**CVE:** %s\n", strings.Join(item.CVEs, ", "))
+	}
+	if item.issue.CVSS > 0 {
+		fmt.Fprintf(&b, "**CVSS:** %.1f\n", item.issue.CVSS)
+	}
+	if d := strings.TrimSpace(item.issue.Description); d != "" {
+		b.WriteString("\n" + d + "\n")
+	}
+	if s := strings.TrimSpace(item.issue.Solution); s != "" {
+		b.WriteString("\n### Solution\n\n" + s + "\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// ApplyFindingPlan creates missing assets and findings, links each new finding to its
+// asset and refreshes matched findings. Resolved findings that were reported again
+// are reopened; accepted risks and false positives keep their status. dueAt, when
+// set, supplies the due date of new findings.
+func ApplyFindingPlan(ctx context.Context, st FindingStores, plan *FindingPlan, userID int64, now time.Time, dueAt func(severity string, from time.Time) *time.Time) error {
+	created := map[string]int64{}
+	tag := strings.ToUpper(plan.Format)
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		if item.NewAsset {
+			id, ok := created[item.Host]
+			if !ok {
+				var err error
+				id, err = st.Assets.CreateAsset(ctx, &store.Asset{
+					Name:        item.AssetName,
+					Type:        "host",
+					IPAddresses: []string{item.Host},
+					Criticality: "medium",
+					Env:         "other",
+					Status:      "active",
+					CreatedBy:   &userID,
+					UpdatedBy:   &userID,
+				})
+				if err != nil {
+					return err
+				}
+				created[item.Host] = id
+			}
+			item.AssetID = id
+		}
+		desc := issueDescription(*item)
+		switch item.Action {
+		case ActionUnchanged:
+			continue
+		case ActionUpdate:
+			f, err := st.Findings.GetFinding(ctx, item.FindingID)
+			if err != nil {
+				return err
+			}
+			if f == nil {
+				continue
+			}
+			f.Severity = item.Severity
+			f.DescriptionMD = desc
+			if item.Reopened {
+				f.Status = "open"
+			}
+			f.UpdatedBy = &userID
+			if err := st.Findings.UpdateFinding(ctx, f); err != nil {
+				return err
+			}
+			continue
+		}
+		f := &store.Finding{
+			Title:         item.Title,
+			DescriptionMD: desc,
+			Status:        "open",
+			Severity:      item.Severity,
+			FindingType:   "vulnerability",
+			Tags:          []string{tag},
+			CreatedBy:     &userID,
+			UpdatedBy:     &userID,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Version:       1,
+		}
+		if dueAt != nil {
+			f.DueAt = dueAt(f.Severity, now)
+		}
+		id, err := st.Findings.CreateFinding(ctx, f)
+		if err != nil {
+			return err
+		}
+		item.FindingID = id
+		if _, err := st.Links.Add(ctx, &store.EntityLink{
+			SourceType:   "finding",
+			SourceID:     strconv.FormatInt(id, 10),
+			TargetType:   "asset",
+			TargetID:     strconv.FormatInt(item.AssetID, 10),
+			RelationType: "affects",
+		}); err != nil {
+			return err
+		}
+	}
+	plan.Applied = true
+	return nil
+}
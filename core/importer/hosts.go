@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Plan actions.
+const (
+	ActionAdd       = "add"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+type HostPlanItem struct {
+	Action       string     `json:"action"`
+	AssetID      int64      `json:"asset_id,omitempty"`
+	Name         string     `json:"name"`
+	Addresses    []string   `json:"addresses"`
+	NewAddresses []string   `json:"new_addresses,omitempty"`
+	OS           string     `json:"os,omitempty"`
+	Ports        []ScanPort `json:"ports"`
+}
+
+// HostPlan lists the assets an Nmap report creates or updates.
+type HostPlan struct {
+	Hosts   int            `json:"hosts"`
+	Added   int            `json:"added"`
+	Updated int            `json:"updated"`
+	Applied bool           `json:"applied"`
+	Items   []HostPlanItem `json:"items"`
+}
+
+// BuildHostPlan matches scanned hosts to assets by hostname or IP address. Hosts
+// that resolve to the same asset are merged.
+func BuildHostPlan(ctx context.Context, as store.AssetsStore, hosts []ScanHost) (*HostPlan, error) {
+	idx, err := LoadAssetIndex(ctx, as)
+	if err != nil {
+		return nil, err
+	}
+	plan := &HostPlan{Hosts: len(hosts), Items: []HostPlanItem{}}
+	byAsset := map[int64]int{}
+	pending := NewAssetIndex(nil)
+	pendingItem := map[*store.Asset]int{}
+	for _, h := range hosts {
+		var match *store.Asset
+		for _, name := range append([]string{h.Name}, h.Hostnames...) {
+			if match = idx.Match(name, nil); match != nil {
+				break
+			}
+		}
+		if match == nil {
+			match = idx.Match("", h.Addresses)
+		}
+		if match != nil {
+			if i, ok := byAsset[match.ID]; ok {
+				mergeHostItem(&plan.Items[i], h)
+				continue
+			}
+			item := HostPlanItem{
+				Action:    ActionUpdate,
+				AssetID:   match.ID,
+				Name:      match.Name,
+				Addresses: match.IPAddresses,
+				OS:        h.OS,
+			}
+			mergeHostItem(&item, h)
+			byAsset[match.ID] = len(plan.Items)
+			plan.Items = append(plan.Items, item)
+			continue
+		}
+		if p := pending.Match(h.Name, h.Addresses); p != nil {
+			i := pendingItem[p]
+			mergeHostItem(&plan.Items[i], h)
+			p.IPAddresses = plan.Items[i].Addresses
+			pending.Put(p)
+			continue
+		}
+		item := HostPlanItem{Action: ActionAdd, Name: h.Name, OS: h.OS}
+		mergeHostItem(&item, h)
+		p := &store.Asset{Name: item.Name, IPAddresses: item.Addresses}
+		pending.Put(p)
+		pendingItem[p] = len(plan.Items)
+		plan.Items = append(plan.Items, item)
+	}
+	for _, item := range plan.Items {
+		if item.Action == ActionAdd {
+			plan.Added++
+		} else {
+			plan.Updated++
+		}
+	}
+	return plan, nil
+}
+
+func mergeHostItem(item *HostPlanItem, h ScanHost) {
+	before := len(item.Addresses)
+	item.Addresses = MergeIPs(item.Addresses, h.Addresses)
+	if item.Action == ActionUpdate {
+		item.NewAddresses = append(item.NewAddresses, item.Addresses[before:]...)
+	}
+	if item.OS == "" {
+		item.OS = h.OS
+	}
+	seen := map[string]bool{}
+	for _, p := range item.Ports {
+		seen[fmt.Sprintf("%d/%s", p.Port, p.Protocol)] = true
+	}
+	for _, p := range h.Ports {
+		if key := fmt.Sprintf("%d/%s", p.Port, p.Protocol); !seen[key] {
+			seen[key] = true
+			item.Ports = append(item.Ports, p)
+		}
+	}
+}
+
+// ApplyHostPlan creates the new assets and refreshes addresses and port notes of the
+// matched ones.
+func ApplyHostPlan(ctx context.Context, as store.AssetsStore, plan *HostPlan, userID int64, now time.Time) error {
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		notes := portNotes(*item, now)
+		if item.Action == ActionAdd {
+			id, err := as.CreateAsset(ctx, &store.Asset{
+				Name:        item.Name,
+				Type:        "host",
+				Description: notes,
+				IPAddresses: item.Addresses,
+				Criticality: "medium",
+				Env:         "other",
+				Status:      "active",
+				CreatedBy:   &userID,
+				UpdatedBy:   &userID,
+			})
+			if err != nil {
+				return err
+			}
+			item.AssetID = id
+			continue
+		}
+		a, err := as.GetAsset(ctx, item.AssetID)
+		if err != nil {
+			return err
+		}
+		if a == nil || a.DeletedAt != nil {
+			continue
+		}
+		a.IPAddresses = MergeIPs(a.IPAddresses, item.Addresses)
+		a.Description = MergePortNotes(a.Description, notes)
+		a.UpdatedBy = &userID
+		if err := as.UpdateAsset(ctx, a); err != nil {
+			return err
+		}
+	}
+	plan.Applied = true
+	return nil
+}
+
+func portNotes(item HostPlanItem, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s):", portNotesHeader, now.UTC().Format("2006-01-02"))
+	if item.OS != "" {
+		fmt.Fprintf(&b, "\nOS: %s", item.OS)
+	}
+	if len(item.Ports) == 0 {
+		b.WriteString("\n- none")
+	}
+	for _, p := range item.Ports {
+		b.WriteString("\n- " + p.String())
+	}
+	return b.String()
+}
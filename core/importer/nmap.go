@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ScanHost is a live host reported by a network scanner.
+type ScanHost struct {
+	Name      string     `json:"name"`
+	Addresses []string   `json:"addresses"`
+	Hostnames []string   `json:"hostnames,omitempty"`
+	OS        string     `json:"os,omitempty"`
+	Ports     []ScanPort `json:"ports,omitempty"`
+}
+
+type ScanPort struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Service  string `json:"service,omitempty"`
+	Product  string `json:"product,omitempty"`
+}
+
+func (p ScanPort) String() string {
+	s := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+	if p.Service != "" {
+		s += " " + p.Service
+	}
+	if p.Product != "" {
+		s += " (" + p.Product + ")"
+	}
+	return s
+}
+
+type nmapRun struct {
+	XMLName xml.Name `xml:"nmaprun"`
+	Hosts   []struct {
+		Status struct {
+			State string `xml:"state,attr"`
+		} `xml:"status"`
+		Addresses []struct {
+			Addr     string `xml:"addr,attr"`
+			AddrType string `xml:"addrtype,attr"`
+		} `xml:"address"`
+		Hostnames []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"hostnames>hostname"`
+		Ports []struct {
+			Protocol string `xml:"protocol,attr"`
+			PortID   int    `xml:"portid,attr"`
+			State    struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+			Service struct {
+				Name      string `xml:"name,attr"`
+				Product   string `xml:"product,attr"`
+				Version   string `xml:"version,attr"`
+				ExtraInfo string `xml:"extrainfo,attr"`
+			} `xml:"service"`
+		} `xml:"ports>port"`
+		OSMatches []struct {
+			Name     string `xml:"name,attr"`
+			Accuracy int    `xml:"accuracy,attr"`
+		} `xml:"os>osmatch"`
+	} `xml:"host"`
+}
+
+// ParseNmap reads an Nmap XML report (-oX). Hosts that were not up or have no IP
+// address are skipped; only open ports are kept.
+func ParseNmap(data []byte) ([]ScanHost, error) {
+	var run nmapRun
+	if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &run); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	hosts := []ScanHost{}
+	for _, h := range run.Hosts {
+		if h.Status.State != "" && h.Status.State != "up" {
+			continue
+		}
+		var host ScanHost
+		for _, a := range h.Addresses {
+			if a.AddrType != "ipv4" && a.AddrType != "ipv6" {
+				continue
+			}
+			if ip := net.ParseIP(strings.TrimSpace(a.Addr)); ip != nil {
+				host.Addresses = append(host.Addresses, ip.String())
+			}
+		}
+		if len(host.Addresses) == 0 {
+			continue
+		}
+		for _, hn := range h.Hostnames {
+			if name := strings.TrimSpace(hn.Name); name != "" && !containsFold(host.Hostnames, name) {
+				host.Hostnames = append(host.Hostnames, name)
+			}
+		}
+		best := 0
+		for _, m := range h.OSMatches {
+			if m.Accuracy > best {
+				best, host.OS = m.Accuracy, strings.TrimSpace(m.Name)
+			}
+		}
+		for _, p := range h.Ports {
+			if p.State.State != "open" {
+				continue
+			}
+			product := strings.TrimSpace(strings.Join(strings.Fields(p.Service.Product+" "+p.Service.Version), " "))
+			if extra := strings.TrimSpace(p.Service.ExtraInfo); extra != "" && product != "" {
+				product += ", " + extra
+			}
+			host.Ports = append(host.Ports, ScanPort{
+				Port:     p.PortID,
+				Protocol: strings.ToLower(p.Protocol),
+				Service:  strings.TrimSpace(p.Service.Name),
+				Product:  product,
+			})
+		}
+		host.Name = host.Addresses[0]
+		if len(host.Hostnames) > 0 {
+			host.Name = host.Hostnames[0]
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+func containsFold(list []string, v string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFormatInvalid = errors.New("import.formatInvalid")
+	ErrEmpty         = errors.New("import.empty")
+)
+
+// Table is a spreadsheet read from a CSV or XLSX upload. Every row has exactly
+// len(Headers) cells.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// ReadTable decodes a CSV or XLSX upload. XLSX is detected by the zip signature or
+// the file extension; anything else is read as CSV with the delimiter guessed from
+// the header line.
+func ReadTable(filename string, data []byte) (*Table, error) {
+	var rows [][]string
+	var err error
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || strings.EqualFold(filepath.Ext(filename), ".xlsx") {
+		rows, err = readXLSX(data)
+	} else {
+		rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(rows) > 0 && blankRow(rows[0]) {
+		rows = rows[1:]
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+	headers := make([]string, len(rows[0]))
+	for i, h := range rows[0] {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			h = fmt.Sprintf("col_%d", i+1)
+		}
+		headers[i] = h
+	}
+	t := &Table{Headers: headers, Rows: make([][]string, 0, len(rows)-1)}
+	for _, raw := range rows[1:] {
+		if blankRow(raw) {
+			continue
+		}
+		row := make([]string, len(headers))
+		for i := range row {
+			if i < len(raw) {
+				row[i] = strings.TrimSpace(raw[i])
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if len(t.Rows) == 0 {
+		return nil, ErrEmpty
+	}
+	return t, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, ErrFormatInvalid
+	}
+	return rows, nil
+}
+
+// sniffDelimiter picks the most frequent of comma, semicolon and tab in the first
+// line; spreadsheets saved with a non-English locale use semicolons.
+func sniffDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', bytes.Count(line, []byte{','})
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func blankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitList splits a multi-value cell on ';', ',', '|' or line breaks.
+func SplitList(v string) []string {
+	parts := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ';' || r == ',' || r == '|' || r == '\n' || r == '\r'
+	})
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "02.01.2006", "2006/01/02"}
+
+// ParseDate accepts ISO dates, dd.mm.yyyy and the serial day numbers XLSX stores for
+// date cells.
+func ParseDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil && serial > 0 && serial < 2958466 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
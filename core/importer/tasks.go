@@ -0,0 +1,535 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Task tracker export formats.
+const (
+	FormatJiraCSV     = "jira_csv"
+	FormatJiraXML     = "jira_xml"
+	FormatYouTrackCSV = "youtrack_csv"
+	FormatTrello      = "trello"
+	FormatTaskCSV     = "csv"
+)
+
+// taskSystems names the tracker behind each format; it prefixes external keys so a
+// Jira issue is the same task whether it came from the CSV or the XML export.
+var taskSystems = map[string]string{
+	FormatJiraCSV:     "jira",
+	FormatJiraXML:     "jira",
+	FormatYouTrackCSV: "youtrack",
+	FormatTrello:      "trello",
+	FormatTaskCSV:     "csv",
+}
+
+// taskTableColumns maps task fields to the lower-cased headers of each table format.
+// Jira repeats the Labels, Comment and Attachment columns once per value.
+var taskTableColumns = map[string]map[string][]string{
+	FormatJiraCSV: {
+		"id":          {"issue key"},
+		"title":       {"summary"},
+		"description": {"description"},
+		"status":      {"status"},
+		"priority":    {"priority"},
+		"assignee":    {"assignee"},
+		"labels":      {"labels"},
+		"comment":     {"comment"},
+		"attachment":  {"attachment"},
+		"due":         {"due date"},
+	},
+	FormatYouTrackCSV: {
+		"id":          {"issue id"},
+		"title":       {"summary"},
+		"description": {"description"},
+		"status":      {"state"},
+		"priority":    {"priority"},
+		"assignee":    {"assignee"},
+		"labels":      {"tags"},
+		"due":         {"due date"},
+	},
+	FormatTaskCSV: {
+		"id":          {"external_id", "id", "key"},
+		"title":       {"title", "summary", "name"},
+		"description": {"description"},
+		"status":      {"status", "column", "state", "list"},
+		"priority":    {"priority"},
+		"assignee":    {"assignee", "assignees", "assigned_to"},
+		"labels":      {"labels", "tags"},
+		"checklist":   {"checklist"},
+		"comment":     {"comment", "comments"},
+		"attachment":  {"attachment", "attachments"},
+		"due":         {"due_date", "due"},
+	},
+}
+
+type ExternalCheckItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+type ExternalComment struct {
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type ExternalAttachment struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// ExternalTask is a task read from another tracker's export. Users are kept as the
+// export names them (login, e-mail or display name).
+type ExternalTask struct {
+	ExternalID  string               `json:"external_id"`
+	Title       string               `json:"title"`
+	Description string               `json:"description,omitempty"`
+	Status      string               `json:"status,omitempty"`
+	Priority    string               `json:"priority,omitempty"`
+	Assignees   []string             `json:"assignees,omitempty"`
+	Labels      []string             `json:"labels,omitempty"`
+	Checklist   []ExternalCheckItem  `json:"checklist,omitempty"`
+	Comments    []ExternalComment    `json:"comments,omitempty"`
+	Attachments []ExternalAttachment `json:"attachments,omitempty"`
+	DueDate     *time.Time           `json:"due_date,omitempty"`
+	// Closed marks cards archived in the source (Trello).
+	Closed bool `json:"closed,omitempty"`
+}
+
+type TaskExport struct {
+	Format string         `json:"format"`
+	System string         `json:"system"`
+	Tasks  []ExternalTask `json:"tasks"`
+}
+
+// ExternalKey identifies a task of the export across imports.
+func (e *TaskExport) ExternalKey(t ExternalTask) string {
+	if strings.TrimSpace(t.ExternalID) == "" {
+		return ""
+	}
+	return e.System + ":" + strings.TrimSpace(t.ExternalID)
+}
+
+// DetectTaskFormat guesses the export format: JSON is a Trello board, XML a Jira RSS
+// export and tables are told apart by their key column.
+func DetectTaskFormat(filename string, data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatTrello
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatJiraXML
+	}
+	if t, err := ReadTable(filename, data); err == nil {
+		for _, h := range t.Headers {
+			switch strings.ToLower(h) {
+			case "issue key":
+				return FormatJiraCSV
+			case "issue id":
+				return FormatYouTrackCSV
+			}
+		}
+	}
+	return FormatTaskCSV
+}
+
+// ParseTaskExport reads a Jira CSV or XML, YouTrack CSV, Trello JSON or generic CSV
+// export; an empty format is detected from the content.
+func ParseTaskExport(format, filename string, data []byte) (*TaskExport, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, ErrEmpty
+	}
+	if format == "" {
+		format = DetectTaskFormat(filename, data)
+	}
+	var export *TaskExport
+	var err error
+	switch format {
+	case FormatTrello:
+		export, err = parseTrello(data)
+	case FormatJiraXML:
+		export, err = parseJiraXML(data)
+	case FormatJiraCSV, FormatYouTrackCSV, FormatTaskCSV:
+		export, err = parseTaskTable(format, filename, data)
+	default:
+		return nil, ErrFormatInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	export.Format = format
+	export.System = taskSystems[format]
+	if len(export.Tasks) == 0 {
+		return nil, ErrEmpty
+	}
+	return export, nil
+}
+
+func parseTaskTable(format, filename string, data []byte) (*TaskExport, error) {
+	t, err := ReadTable(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	cols := map[string][]int{}
+	for i, h := range t.Headers {
+		name := strings.ToLower(h)
+		for field, aliases := range taskTableColumns[format] {
+			if slices.Contains(aliases, name) {
+				cols[field] = append(cols[field], i)
+			}
+		}
+	}
+	if len(cols["title"]) == 0 {
+		return nil, ErrFormatInvalid
+	}
+	export := &TaskExport{Tasks: make([]ExternalTask, 0, len(t.Rows))}
+	for _, row := range t.Rows {
+		values := func(field string) []string {
+			var out []string
+			for _, i := range cols[field] {
+				if v := strings.TrimSpace(row[i]); v != "" {
+					out = append(out, v)
+				}
+			}
+			return out
+		}
+		first := func(field string) string {
+			if v := values(field); len(v) > 0 {
+				return v[0]
+			}
+			return ""
+		}
+		task := ExternalTask{
+			ExternalID:  first("id"),
+			Title:       first("title"),
+			Description: first("description"),
+			Status:      first("status"),
+			Priority:    first("priority"),
+		}
+		for _, v := range values("assignee") {
+			// Jira and YouTrack hold one display name that may contain commas.
+			if format == FormatTaskCSV {
+				task.Assignees = append(task.Assignees, SplitList(v)...)
+			} else {
+				task.Assignees = append(task.Assignees, v)
+			}
+		}
+		for _, v := range values("labels") {
+			task.Labels = append(task.Labels, SplitList(v)...)
+		}
+		for _, v := range values("checklist") {
+			task.Checklist = append(task.Checklist, parseChecklistCell(v)...)
+		}
+		for _, v := range values("comment") {
+			task.Comments = append(task.Comments, parseCommentCell(v))
+		}
+		for _, v := range values("attachment") {
+			task.Attachments = append(task.Attachments, parseAttachmentCell(v))
+		}
+		if raw := first("due"); raw != "" {
+			if due, err := parseExportDate(raw); err == nil {
+				task.DueDate = &due
+			}
+		}
+		export.Tasks = append(export.Tasks, task)
+	}
+	return export, nil
+}
+
+// parseChecklistCell reads one item per line (or ';'); "[x] text" marks done items.
+func parseChecklistCell(v string) []ExternalCheckItem {
+	var out []ExternalCheckItem
+	for _, line := range strings.FieldsFunc(v, func(r rune) bool { return r == '\n' || r == '\r' || r == ';' }) {
+		text := strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		done := false
+		lower := strings.ToLower(text)
+		switch {
+		case strings.HasPrefix(lower, "[x]"):
+			done = true
+			text = text[3:]
+		case strings.HasPrefix(lower, "[ ]"):
+			text = text[3:]
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			out = append(out, ExternalCheckItem{Text: text, Done: done})
+		}
+	}
+	return out
+}
+
+// parseCommentCell reads Jira's "date;author;body" comment cells; anything else is
+// taken as the comment body.
+func parseCommentCell(v string) ExternalComment {
+	parts := strings.SplitN(v, ";", 3)
+	if len(parts) == 3 {
+		if at, err := parseExportDate(parts[0]); err == nil {
+			return ExternalComment{Author: strings.TrimSpace(parts[1]), Body: strings.TrimSpace(parts[2]), CreatedAt: &at}
+		}
+	}
+	return ExternalComment{Body: v}
+}
+
+// parseAttachmentCell reads Jira's "date;author;name;url" attachment cells, bare URLs
+// and bare file names.
+func parseAttachmentCell(v string) ExternalAttachment {
+	parts := strings.SplitN(v, ";", 4)
+	if len(parts) == 4 {
+		if _, err := parseExportDate(parts[0]); err == nil {
+			return ExternalAttachment{Name: strings.TrimSpace(parts[2]), URL: strings.TrimSpace(parts[3])}
+		}
+	}
+	if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+		return ExternalAttachment{Name: path.Base(strings.SplitN(v, "?", 2)[0]), URL: v}
+	}
+	return ExternalAttachment{Name: v}
+}
+
+var exportDateLayouts = []string{
+	"02/Jan/06 3:04 PM",
+	"2/Jan/06 3:04 PM",
+	"02/Jan/06 15:04",
+	"02/Jan/06",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseExportDate accepts the Jira, YouTrack and Trello date formats besides the
+// ones ParseDate knows.
+func parseExportDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range exportDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return ParseDate(raw)
+}
+
+type jiraRSS struct {
+	Items []struct {
+		Key         string `xml:"key"`
+		Summary     string `xml:"summary"`
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Status      string `xml:"status"`
+		Priority    string `xml:"priority"`
+		Assignee    struct {
+			Username string `xml:"username,attr"`
+			Name     string `xml:",chardata"`
+		} `xml:"assignee"`
+		Due      string   `xml:"due"`
+		Labels   []string `xml:"labels>label"`
+		Comments []struct {
+			Author  string `xml:"author,attr"`
+			Created string `xml:"created,attr"`
+			Body    string `xml:",chardata"`
+		} `xml:"comments>comment"`
+		Attachments []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"attachments>attachment"`
+	} `xml:"channel>item"`
+}
+
+func parseJiraXML(data []byte) (*TaskExport, error) {
+	var doc jiraRSS
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	export := &TaskExport{Tasks: make([]ExternalTask, 0, len(doc.Items))}
+	for _, item := range doc.Items {
+		task := ExternalTask{
+			ExternalID:  strings.TrimSpace(item.Key),
+			Title:       strings.TrimSpace(item.Summary),
+			Description: htmlText(item.Description),
+			Status:      strings.TrimSpace(item.Status),
+			Priority:    strings.TrimSpace(item.Priority),
+			Labels:      item.Labels,
+		}
+		if task.Title == "" {
+			task.Title = strings.TrimSpace(strings.TrimPrefix(item.Title, "["+task.ExternalID+"]"))
+		}
+		assignee := strings.TrimSpace(item.Assignee.Username)
+		if assignee == "" || assignee == "-1" {
+			assignee = strings.TrimSpace(item.Assignee.Name)
+		}
+		if assignee != "" && !strings.EqualFold(assignee, "unassigned") {
+			task.Assignees = []string{assignee}
+		}
+		if due, err := parseExportDate(item.Due); err == nil {
+			task.DueDate = &due
+		}
+		for _, c := range item.Comments {
+			comment := ExternalComment{Author: strings.TrimSpace(c.Author), Body: htmlText(c.Body)}
+			if at, err := parseExportDate(c.Created); err == nil {
+				comment.CreatedAt = &at
+			}
+			task.Comments = append(task.Comments, comment)
+		}
+		for _, a := range item.Attachments {
+			task.Attachments = append(task.Attachments, ExternalAttachment{Name: strings.TrimSpace(a.Name)})
+		}
+		export.Tasks = append(export.Tasks, task)
+	}
+	return export, nil
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</div>|</h[1-6]>`)
+	htmlTags   = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlText turns the rendered HTML of Jira descriptions and comments into plain text.
+func htmlText(v string) string {
+	v = htmlBreaks.ReplaceAllString(v, "\n")
+	v = html.UnescapeString(htmlTags.ReplaceAllString(v, ""))
+	lines := strings.Split(strings.ReplaceAll(v, "\r", ""), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Desc   string  `json:"desc"`
+		IDList string  `json:"idList"`
+		Closed bool    `json:"closed"`
+		Due    *string `json:"due"`
+		Labels []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+		IDMembers   []string `json:"idMembers"`
+		Attachments []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"attachments"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Actions []struct {
+		Type string `json:"type"`
+		Date string `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+// parseTrello reads a board exported as JSON. The list of a card is its status;
+// cards of archived lists count as archived. Comments come from commentCard actions,
+// which Trello lists newest first.
+func parseTrello(data []byte) (*TaskExport, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil || board.Cards == nil {
+		return nil, ErrFormatInvalid
+	}
+	lists := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, l := range board.Lists {
+		lists[l.ID] = strings.TrimSpace(l.Name)
+		closedLists[l.ID] = l.Closed
+	}
+	members := map[string]string{}
+	for _, m := range board.Members {
+		members[m.ID] = m.Username
+	}
+	sort.SliceStable(board.Checklists, func(i, j int) bool { return board.Checklists[i].Pos < board.Checklists[j].Pos })
+	checklists := map[string][]ExternalCheckItem{}
+	for _, cl := range board.Checklists {
+		items := cl.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		for _, it := range items {
+			if text := strings.TrimSpace(it.Name); text != "" {
+				checklists[cl.IDCard] = append(checklists[cl.IDCard], ExternalCheckItem{Text: text, Done: it.State == "complete"})
+			}
+		}
+	}
+	comments := map[string][]ExternalComment{}
+	for i := len(board.Actions) - 1; i >= 0; i-- {
+		a := board.Actions[i]
+		if a.Type != "commentCard" || strings.TrimSpace(a.Data.Text) == "" {
+			continue
+		}
+		comment := ExternalComment{Author: a.MemberCreator.Username, Body: strings.TrimSpace(a.Data.Text)}
+		if at, err := parseExportDate(a.Date); err == nil {
+			comment.CreatedAt = &at
+		}
+		comments[a.Data.Card.ID] = append(comments[a.Data.Card.ID], comment)
+	}
+	export := &TaskExport{Tasks: make([]ExternalTask, 0, len(board.Cards))}
+	for _, card := range board.Cards {
+		task := ExternalTask{
+			ExternalID:  card.ID,
+			Title:       strings.TrimSpace(card.Name),
+			Description: strings.TrimSpace(card.Desc),
+			Status:      lists[card.IDList],
+			Checklist:   checklists[card.ID],
+			Comments:    comments[card.ID],
+			Closed:      card.Closed || closedLists[card.IDList],
+		}
+		for _, l := range card.Labels {
+			name := strings.TrimSpace(l.Name)
+			if name == "" {
+				name = l.Color
+			}
+			if name != "" {
+				task.Labels = append(task.Labels, name)
+			}
+		}
+		for _, id := range card.IDMembers {
+			if username := members[id]; username != "" {
+				task.Assignees = append(task.Assignees, username)
+			}
+		}
+		for _, a := range card.Attachments {
+			task.Attachments = append(task.Attachments, ExternalAttachment{Name: strings.TrimSpace(a.Name), URL: a.URL})
+		}
+		if card.Due != nil {
+			if due, err := parseExportDate(*card.Due); err == nil {
+				task.DueDate = &due
+			}
+		}
+		export.Tasks = append(export.Tasks, task)
+	}
+	return export, nil
+}
@@ -0,0 +1,318 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported vulnerability scanner formats.
+const (
+	FormatNessus  = "nessus"
+	FormatOpenVAS = "openvas"
+)
+
+// ScanIssue is a single vulnerability reported for a host and port.
+type ScanIssue struct {
+	Host        string   `json:"host"`
+	Hostname    string   `json:"hostname,omitempty"`
+	Port        int      `json:"port,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
+	Service     string   `json:"service,omitempty"`
+	PluginID    string   `json:"plugin_id,omitempty"`
+	Title       string   `json:"title"`
+	Severity    string   `json:"severity"`
+	CVSS        float64  `json:"cvss,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Solution    string   `json:"solution,omitempty"`
+	CVEs        []string `json:"cves,omitempty"`
+}
+
+type VulnReport struct {
+	Format string      `json:"format"`
+	Issues []ScanIssue `json:"issues"`
+	// Informational counts results that carry no risk (Nessus severity 0, OpenVAS "Log").
+	Informational int `json:"informational"`
+}
+
+// ParseVulnReport reads a Nessus (.nessus v2) or OpenVAS/GVM XML report; the format
+// is detected from the root element.
+func ParseVulnReport(data []byte) (*VulnReport, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, ErrFormatInvalid
+	}
+	switch root {
+	case "NessusClientData_v2":
+		return parseNessus(data)
+	case "report", "get_reports_response":
+		return parseOpenVAS(data)
+	}
+	return nil, ErrFormatInvalid
+}
+
+func rootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local, nil
+		}
+	}
+}
+
+type nessusData struct {
+	Hosts []struct {
+		Name       string `xml:"name,attr"`
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"HostProperties>tag"`
+		Items []struct {
+			Port        int      `xml:"port,attr"`
+			Service     string   `xml:"svc_name,attr"`
+			Protocol    string   `xml:"protocol,attr"`
+			Severity    int      `xml:"severity,attr"`
+			PluginID    string   `xml:"pluginID,attr"`
+			PluginName  string   `xml:"pluginName,attr"`
+			Synopsis    string   `xml:"synopsis"`
+			Description string   `xml:"description"`
+			Solution    string   `xml:"solution"`
+			RiskFactor  string   `xml:"risk_factor"`
+			CVSS3       string   `xml:"cvss3_base_score"`
+			CVSS2       string   `xml:"cvss_base_score"`
+			CVEs        []string `xml:"cve"`
+		} `xml:"ReportItem"`
+	} `xml:"Report>ReportHost"`
+}
+
+var nessusSeverity = map[int]string{1: "low", 2: "medium", 3: "high", 4: "critical"}
+
+func parseNessus(data []byte) (*VulnReport, error) {
+	var doc nessusData
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	report := &VulnReport{Format: FormatNessus, Issues: []ScanIssue{}}
+	for _, h := range doc.Hosts {
+		props := map[string]string{}
+		for _, p := range h.Properties {
+			props[p.Name] = strings.TrimSpace(p.Value)
+		}
+		host := props["host-ip"]
+		if net.ParseIP(host) == nil {
+			host = strings.TrimSpace(h.Name)
+		}
+		hostname := props["host-fqdn"]
+		if hostname == "" {
+			hostname = props["netbios-name"]
+		}
+		if hostname == "" && net.ParseIP(h.Name) == nil {
+			hostname = strings.TrimSpace(h.Name)
+		}
+		for _, item := range h.Items {
+			sev, ok := nessusSeverity[item.Severity]
+			if !ok {
+				report.Informational++
+				continue
+			}
+			desc := strings.TrimSpace(item.Synopsis)
+			if d := strings.TrimSpace(item.Description); d != "" {
+				if desc != "" {
+					desc += "\n\n"
+				}
+				desc += d
+			}
+			cvss := parseScore(item.CVSS3)
+			if cvss == 0 {
+				cvss = parseScore(item.CVSS2)
+			}
+			report.Issues = append(report.Issues, ScanIssue{
+				Host:        host,
+				Hostname:    hostname,
+				Port:        item.Port,
+				Protocol:    strings.ToLower(item.Protocol),
+				Service:     item.Service,
+				PluginID:    item.PluginID,
+				Title:       strings.TrimSpace(item.PluginName),
+				Severity:    sev,
+				CVSS:        cvss,
+				Description: desc,
+				Solution:    strings.TrimSpace(item.Solution),
+				CVEs:        normalizeCVEs(item.CVEs),
+			})
+		}
+	}
+	return report, nil
+}
+
+type openVASResult struct {
+	Name string `xml:"name"`
+	Host struct {
+		Address  string `xml:",chardata"`
+		Hostname string `xml:"hostname"`
+	} `xml:"host"`
+	Port string `xml:"port"`
+	NVT  struct {
+		OID      string `xml:"oid,attr"`
+		Name     string `xml:"name"`
+		CVSSBase string `xml:"cvss_base"`
+		Tags     string `xml:"tags"`
+		Solution string `xml:"solution"`
+		CVE      string `xml:"cve"`
+		Refs     []struct {
+			Type string `xml:"type,attr"`
+			ID   string `xml:"id,attr"`
+		} `xml:"refs>ref"`
+	} `xml:"nvt"`
+	Threat      string `xml:"threat"`
+	Severity    string `xml:"severity"`
+	Description string `xml:"description"`
+}
+
+// parseOpenVAS walks every <result> element, so both a bare report export and a
+// get_reports response are accepted.
+func parseOpenVAS(data []byte) (*VulnReport, error) {
+	report := &VulnReport{Format: FormatOpenVAS, Issues: []ScanIssue{}}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrFormatInvalid
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "result" {
+			continue
+		}
+		var res openVASResult
+		if err := dec.DecodeElement(&res, &se); err != nil {
+			return nil, ErrFormatInvalid
+		}
+		host := strings.TrimSpace(res.Host.Address)
+		if net.ParseIP(host) == nil {
+			continue
+		}
+		score := parseScore(res.Severity)
+		if score == 0 {
+			score = parseScore(res.NVT.CVSSBase)
+		}
+		sev := severityFromScore(score)
+		switch strings.ToLower(strings.TrimSpace(res.Threat)) {
+		case "log", "debug", "false positive":
+			sev = ""
+		}
+		if sev == "" {
+			report.Informational++
+			continue
+		}
+		tags := parseOpenVASTags(res.NVT.Tags)
+		solution := strings.TrimSpace(res.NVT.Solution)
+		if solution == "" {
+			solution = tags["solution"]
+		}
+		desc := tags["summary"]
+		if d := strings.TrimSpace(res.Description); d != "" {
+			if desc != "" {
+				desc += "\n\n"
+			}
+			desc += d
+		}
+		var cves []string
+		for _, ref := range res.NVT.Refs {
+			if strings.EqualFold(ref.Type, "cve") {
+				cves = append(cves, ref.ID)
+			}
+		}
+		if len(cves) == 0 && res.NVT.CVE != "" && !strings.EqualFold(res.NVT.CVE, "NOCVE") {
+			cves = strings.Split(res.NVT.CVE, ",")
+		}
+		port, proto := parseOpenVASPort(res.Port)
+		title := strings.TrimSpace(res.NVT.Name)
+		if title == "" {
+			title = strings.TrimSpace(res.Name)
+		}
+		report.Issues = append(report.Issues, ScanIssue{
+			Host:        host,
+			Hostname:    strings.TrimSpace(res.Host.Hostname),
+			Port:        port,
+			Protocol:    proto,
+			PluginID:    res.NVT.OID,
+			Title:       title,
+			Severity:    sev,
+			CVSS:        score,
+			Description: desc,
+			Solution:    solution,
+			CVEs:        normalizeCVEs(cves),
+		})
+	}
+	return report, nil
+}
+
+// parseOpenVASTags splits the NVT tag string "summary=...|solution=...".
+func parseOpenVASTags(raw string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(raw, "|") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok {
+			out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return out
+}
+
+// parseOpenVASPort reads "443/tcp"; "general/tcp" and similar yield port 0.
+func parseOpenVASPort(raw string) (int, string) {
+	num, proto, _ := strings.Cut(strings.TrimSpace(raw), "/")
+	port, _ := strconv.Atoi(num)
+	return port, strings.ToLower(strings.TrimSpace(proto))
+}
+
+func parseScore(raw string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// severityFromScore maps a CVSS score to the finding severity scale.
+func severityFromScore(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	}
+	return ""
+}
+
+func normalizeCVEs(list []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range list {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if !strings.HasPrefix(c, "CVE-") || seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxMaxPartSize = 64 << 20
+	xlsxMaxColumns  = 1024
+)
+
+// readXLSX returns the cells of the first worksheet. Only what a data export needs
+// is supported: shared and inline strings, numbers and booleans; formulas yield
+// their cached value.
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrFormatInvalid
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	sheetPath := firstSheetPath(files)
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrFormatInvalid
+	}
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, ErrFormatInvalid
+		}
+	}
+	raw, err := readZipPart(sheet)
+	if err != nil {
+		return nil, ErrFormatInvalid
+	}
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(raw, &ws); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	out := make([][]string, 0, len(ws.Rows))
+	for _, row := range ws.Rows {
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			if col < 0 || col >= xlsxMaxColumns {
+				continue
+			}
+			var val string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(c.Value))
+				if err == nil && idx >= 0 && idx < len(shared) {
+					val = shared[idx]
+				}
+			case "inlineStr":
+				val = c.Inline.String()
+			case "b":
+				if strings.TrimSpace(c.Value) == "1" {
+					val = "true"
+				} else {
+					val = "false"
+				}
+			default:
+				val = c.Value
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = val
+		}
+		out = append(out, cells)
+	}
+	return out, nil
+}
+
+// firstSheetPath resolves the first sheet of the workbook through its relationships,
+// falling back to the conventional part name.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok || !ok2 {
+		return fallback
+	}
+	wbRaw, err := readZipPart(wbFile)
+	if err != nil {
+		return fallback
+	}
+	var wb struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(wbRaw, &wb); err != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	relsRaw, err := readZipPart(relsFile)
+	if err != nil {
+		return fallback
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(relsRaw, &rels); err != nil {
+		return fallback
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != wb.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	b.WriteString(t.Text)
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	raw, err := readZipPart(f)
+	if err != nil {
+		return nil, err
+	}
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := xml.Unmarshal(raw, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		out[i] = item.String()
+	}
+	return out, nil
+}
+
+func readZipPart(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, ErrFormatInvalid
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, xlsxMaxPartSize))
+}
+
+// columnIndex converts the letters of a cell reference ("AB12") to a zero-based column.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return -1
+	}
+	return col - 1
+}
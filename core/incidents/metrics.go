@@ -0,0 +1,365 @@
+package incidents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Lifecycle holds the effective milestone timestamps of an incident. Explicit values from
+// incident_impact win; missing ones are derived from the incident record and its timeline.
+type Lifecycle struct {
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	DetectedAt     *time.Time `json:"detected_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ContainedAt    *time.Time `json:"contained_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+var (
+	ErrImpactOrder    = errors.New("incidents.impact.orderInvalid")
+	ErrImpactNegative = errors.New("incidents.impact.negative")
+)
+
+// LifecycleEventTypes are the timeline events DeriveLifecycle looks at.
+var LifecycleEventTypes = []string{"status.change", "assignee.change", "incident.closed"}
+
+// MilestoneForStatus maps an incident status onto the lifecycle milestone it implies, if any.
+func MilestoneForStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "contained":
+		return store.IncidentMilestoneContained
+	case "resolved", "closed":
+		return store.IncidentMilestoneResolved
+	case "", "draft", "open":
+		return ""
+	default:
+		return store.IncidentMilestoneAcknowledged
+	}
+}
+
+// DeriveLifecycle resolves milestones for one incident. events must belong to the incident and
+// be ordered oldest first.
+func DeriveLifecycle(inc store.Incident, impact *store.IncidentImpact, events []store.IncidentTimelineEvent) Lifecycle {
+	var lc Lifecycle
+	if impact != nil {
+		lc = Lifecycle{
+			StartedAt:      impact.StartedAt,
+			DetectedAt:     impact.DetectedAt,
+			AcknowledgedAt: impact.AcknowledgedAt,
+			ContainedAt:    impact.ContainedAt,
+			ResolvedAt:     impact.ResolvedAt,
+		}
+	}
+	if lc.DetectedAt == nil {
+		if t, ok := parseMetaTime(inc.Meta.DetectedAt); ok {
+			lc.DetectedAt = &t
+		} else {
+			created := inc.CreatedAt.UTC()
+			lc.DetectedAt = &created
+		}
+	}
+	for _, ev := range events {
+		at := ev.EventAt.UTC()
+		switch ev.EventType {
+		case "assignee.change":
+			if lc.AcknowledgedAt == nil {
+				lc.AcknowledgedAt = &at
+			}
+		case "incident.closed":
+			if lc.ResolvedAt == nil {
+				lc.ResolvedAt = &at
+			}
+		case "status.change":
+			parts := strings.SplitN(ev.Message, "->", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			switch MilestoneForStatus(parts[1]) {
+			case store.IncidentMilestoneResolved:
+				if lc.ResolvedAt == nil {
+					lc.ResolvedAt = &at
+				}
+				fallthrough
+			case store.IncidentMilestoneContained:
+				if lc.ContainedAt == nil && strings.EqualFold(strings.TrimSpace(parts[1]), "contained") {
+					lc.ContainedAt = &at
+				}
+				fallthrough
+			case store.IncidentMilestoneAcknowledged:
+				if lc.AcknowledgedAt == nil {
+					lc.AcknowledgedAt = &at
+				}
+			}
+		}
+	}
+	if lc.ResolvedAt == nil && inc.ClosedAt != nil {
+		closed := inc.ClosedAt.UTC()
+		lc.ResolvedAt = &closed
+	}
+	return lc
+}
+
+// ValidateLifecycle checks that milestones, where set, do not go back in time.
+func ValidateLifecycle(lc Lifecycle) error {
+	ordered := []*time.Time{lc.StartedAt, lc.DetectedAt, lc.AcknowledgedAt, lc.ResolvedAt}
+	var prev *time.Time
+	for _, t := range ordered {
+		if t == nil {
+			continue
+		}
+		if prev != nil && t.Before(*prev) {
+			return ErrImpactOrder
+		}
+		prev = t
+	}
+	if lc.ContainedAt != nil {
+		if lc.DetectedAt != nil && lc.ContainedAt.Before(*lc.DetectedAt) {
+			return ErrImpactOrder
+		}
+		if lc.ResolvedAt != nil && lc.ResolvedAt.Before(*lc.ContainedAt) {
+			return ErrImpactOrder
+		}
+	}
+	return nil
+}
+
+// MetricsRecord is one incident as fed into ComputeMetrics.
+type MetricsRecord struct {
+	Incident  store.Incident
+	Impact    store.IncidentImpact
+	Lifecycle Lifecycle
+}
+
+// DurationStat summarises one interval in minutes.
+type DurationStat struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean_minutes"`
+	Median float64 `json:"median_minutes"`
+	P90    float64 `json:"p90_minutes"`
+}
+
+type MetricsBucket struct {
+	Key             string             `json:"key"`
+	Incidents       int                `json:"incidents"`
+	MTTD            DurationStat       `json:"mttd"`
+	MTTA            DurationStat       `json:"mtta"`
+	MTTC            DurationStat       `json:"mttc"`
+	MTTR            DurationStat       `json:"mttr"`
+	DowntimeMinutes int                `json:"downtime_minutes"`
+	AffectedUsers   int                `json:"affected_users"`
+	EstimatedCost   map[string]float64 `json:"estimated_cost"`
+}
+
+type MetricsReport struct {
+	GroupBy string          `json:"group_by"`
+	Period  string          `json:"period,omitempty"`
+	Overall MetricsBucket   `json:"overall"`
+	Groups  []MetricsBucket `json:"groups"`
+}
+
+const (
+	MetricsGroupSeverity = "severity"
+	MetricsGroupType     = "type"
+	MetricsGroupPeriod   = "period"
+)
+
+var severityOrder = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+// ComputeMetrics aggregates MTTD (started→detected), MTTA (detected→acknowledged),
+// MTTC (detected→contained) and MTTR (detected→resolved) plus impact totals.
+// period is "week" or "month" and only matters for MetricsGroupPeriod.
+func ComputeMetrics(records []MetricsRecord, groupBy, period string) MetricsReport {
+	groupBy = strings.ToLower(strings.TrimSpace(groupBy))
+	switch groupBy {
+	case MetricsGroupSeverity, MetricsGroupType, MetricsGroupPeriod:
+	default:
+		groupBy = MetricsGroupSeverity
+	}
+	period = strings.ToLower(strings.TrimSpace(period))
+	if period != "month" {
+		period = "week"
+	}
+	report := MetricsReport{GroupBy: groupBy, Overall: buildBucket("all", records), Groups: []MetricsBucket{}}
+	if groupBy == MetricsGroupPeriod {
+		report.Period = period
+	}
+	grouped := map[string][]MetricsRecord{}
+	for _, rec := range records {
+		key := metricsGroupKey(rec, groupBy, period)
+		grouped[key] = append(grouped[key], rec)
+	}
+	keys := make([]string, 0, len(grouped))
+	for k := range grouped {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if groupBy == MetricsGroupSeverity {
+			oi, okI := severityOrder[keys[i]]
+			oj, okJ := severityOrder[keys[j]]
+			if !okI {
+				oi = len(severityOrder)
+			}
+			if !okJ {
+				oj = len(severityOrder)
+			}
+			if oi != oj {
+				return oi < oj
+			}
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		report.Groups = append(report.Groups, buildBucket(k, grouped[k]))
+	}
+	return report
+}
+
+// PeriodKey formats t as an ISO week ("2024-W05") or a month ("2024-01").
+func PeriodKey(t time.Time, period string) string {
+	t = t.UTC()
+	if period == "month" {
+		return t.Format("2006-01")
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+func metricsGroupKey(rec MetricsRecord, groupBy, period string) string {
+	switch groupBy {
+	case MetricsGroupType:
+		if v := strings.TrimSpace(rec.Incident.Meta.IncidentType); v != "" {
+			return v
+		}
+		return "unknown"
+	case MetricsGroupPeriod:
+		at := rec.Incident.CreatedAt
+		if rec.Lifecycle.DetectedAt != nil {
+			at = *rec.Lifecycle.DetectedAt
+		}
+		return PeriodKey(at, period)
+	default:
+		if v := strings.ToLower(strings.TrimSpace(rec.Incident.Severity)); v != "" {
+			return v
+		}
+		return "unknown"
+	}
+}
+
+func buildBucket(key string, records []MetricsRecord) MetricsBucket {
+	b := MetricsBucket{Key: key, Incidents: len(records), EstimatedCost: map[string]float64{}}
+	var mttd, mtta, mttc, mttr []float64
+	for _, rec := range records {
+		lc := rec.Lifecycle
+		mttd = appendInterval(mttd, lc.StartedAt, lc.DetectedAt)
+		mtta = appendInterval(mtta, lc.DetectedAt, lc.AcknowledgedAt)
+		mttc = appendInterval(mttc, lc.DetectedAt, lc.ContainedAt)
+		mttr = appendInterval(mttr, lc.DetectedAt, lc.ResolvedAt)
+		b.DowntimeMinutes += rec.Impact.DowntimeMinutes
+		b.AffectedUsers += rec.Impact.AffectedUsers
+		if rec.Impact.EstimatedCost > 0 {
+			b.EstimatedCost[strings.ToUpper(strings.TrimSpace(rec.Impact.CostCurrency))] += rec.Impact.EstimatedCost
+		}
+	}
+	b.MTTD = summarize(mttd)
+	b.MTTA = summarize(mtta)
+	b.MTTC = summarize(mttc)
+	b.MTTR = summarize(mttr)
+	return b
+}
+
+func appendInterval(list []float64, from, to *time.Time) []float64 {
+	if from == nil || to == nil || to.Before(*from) {
+		return list
+	}
+	return append(list, to.Sub(*from).Minutes())
+}
+
+func summarize(values []float64) DurationStat {
+	if len(values) == 0 {
+		return DurationStat{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return DurationStat{
+		Count:  len(sorted),
+		Mean:   round2(sum / float64(len(sorted))),
+		Median: round2(percentile(sorted, 0.5)),
+		P90:    round2(percentile(sorted, 0.9)),
+	}
+}
+
+// percentile uses linear interpolation between closest ranks on sorted input.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + (sorted[lo+1]-sorted[lo])*frac
+}
+
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
+
+func parseMetaTime(raw string) (time.Time, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// BuildMetricsRecords loads impact rows and lifecycle timeline events for the given incidents
+// in bulk and resolves their lifecycles.
+func BuildMetricsRecords(ctx context.Context, is store.IncidentsStore, items []store.Incident) ([]MetricsRecord, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, 0, len(items))
+	for _, inc := range items {
+		ids = append(ids, inc.ID)
+	}
+	impacts, err := is.ListIncidentImpacts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	events, err := is.ListIncidentTimelineByTypes(ctx, ids, LifecycleEventTypes)
+	if err != nil {
+		return nil, err
+	}
+	byIncident := map[int64][]store.IncidentTimelineEvent{}
+	for _, ev := range events {
+		byIncident[ev.IncidentID] = append(byIncident[ev.IncidentID], ev)
+	}
+	out := make([]MetricsRecord, 0, len(items))
+	for _, inc := range items {
+		impact := impacts[inc.ID]
+		impact.IncidentID = inc.ID
+		out = append(out, MetricsRecord{
+			Incident:  inc,
+			Impact:    impact,
+			Lifecycle: DeriveLifecycle(inc, &impact, byIncident[inc.ID]),
+		})
+	}
+	return out, nil
+}
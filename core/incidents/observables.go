@@ -0,0 +1,139 @@
+package incidents
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	ObservableIP       = "ip"
+	ObservableDomain   = "domain"
+	ObservableURL      = "url"
+	ObservableMD5      = "md5"
+	ObservableSHA1     = "sha1"
+	ObservableSHA256   = "sha256"
+	ObservableSHA512   = "sha512"
+	ObservableEmail    = "email"
+	ObservableUsername = "username"
+	ObservableCVE      = "cve"
+)
+
+var ObservableTypes = []string{
+	ObservableIP, ObservableDomain, ObservableURL,
+	ObservableMD5, ObservableSHA1, ObservableSHA256, ObservableSHA512,
+	ObservableEmail, ObservableUsername, ObservableCVE,
+}
+
+// TLP 2.0 levels, from least to most restrictive.
+var TLPLevels = []string{"clear", "green", "amber", "amber+strict", "red"}
+
+const DefaultTLP = "amber"
+
+var (
+	ErrObservableType  = errors.New("incidents.observables.typeInvalid")
+	ErrObservableValue = errors.New("incidents.observables.valueInvalid")
+	ErrObservableTLP   = errors.New("incidents.observables.tlpInvalid")
+)
+
+var (
+	reDomain   = regexp.MustCompile(`^(?i)([a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,61}[a-z0-9]$`)
+	reCVE      = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+	reHex      = regexp.MustCompile(`^[0-9a-f]+$`)
+	reUsername = regexp.MustCompile(`^[^\s]{1,256}$`)
+	hashLength = map[string]int{ObservableMD5: 32, ObservableSHA1: 40, ObservableSHA256: 64, ObservableSHA512: 128}
+)
+
+// NormalizeObservable validates value against its type and returns the canonical form used
+// for storage and cross-entity correlation.
+func NormalizeObservable(obsType, value string) (string, string, error) {
+	t := strings.ToLower(strings.TrimSpace(obsType))
+	v := strings.TrimSpace(value)
+	if v == "" {
+		return t, "", ErrObservableValue
+	}
+	switch t {
+	case ObservableIP:
+		ip := net.ParseIP(refang(v))
+		if ip == nil {
+			return t, "", ErrObservableValue
+		}
+		return t, ip.String(), nil
+	case ObservableDomain:
+		d := strings.TrimSuffix(strings.ToLower(refang(v)), ".")
+		if len(d) > 253 || !reDomain.MatchString(d) {
+			return t, "", ErrObservableValue
+		}
+		return t, d, nil
+	case ObservableURL:
+		u, err := url.Parse(refang(v))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return t, "", ErrObservableValue
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		return t, u.String(), nil
+	case ObservableMD5, ObservableSHA1, ObservableSHA256, ObservableSHA512:
+		h := strings.ToLower(v)
+		if len(h) != hashLength[t] || !reHex.MatchString(h) {
+			return t, "", ErrObservableValue
+		}
+		return t, h, nil
+	case ObservableEmail:
+		addr, err := mail.ParseAddress(refang(v))
+		if err != nil || addr.Address != refang(v) {
+			return t, "", ErrObservableValue
+		}
+		return t, strings.ToLower(addr.Address), nil
+	case ObservableUsername:
+		if !reUsername.MatchString(v) {
+			return t, "", ErrObservableValue
+		}
+		return t, strings.ToLower(v), nil
+	case ObservableCVE:
+		c := strings.ToUpper(v)
+		if !reCVE.MatchString(c) {
+			return t, "", ErrObservableValue
+		}
+		return t, c, nil
+	default:
+		return t, "", ErrObservableType
+	}
+}
+
+// NormalizeTLP maps user input (with or without the "TLP:" prefix, including the legacy
+// WHITE level) onto a TLP 2.0 level. Empty input yields DefaultTLP.
+func NormalizeTLP(raw string) (string, error) {
+	v := strings.ToLower(strings.TrimSpace(raw))
+	v = strings.TrimPrefix(v, "tlp:")
+	v = strings.ReplaceAll(v, " ", "")
+	switch v {
+	case "":
+		return DefaultTLP, nil
+	case "white":
+		return "clear", nil
+	}
+	for _, lvl := range TLPLevels {
+		if v == lvl {
+			return v, nil
+		}
+	}
+	return "", ErrObservableTLP
+}
+
+// refang undoes common defanging used when sharing indicators ("hxxp", "[.]", "(at)").
+func refang(v string) string {
+	r := strings.NewReplacer("[.]", ".", "(.)", ".", "[:]", ":", "[@]", "@", "(at)", "@", "[at]", "@")
+	out := r.Replace(strings.TrimSpace(v))
+	lower := strings.ToLower(out)
+	switch {
+	case strings.HasPrefix(lower, "hxxps://"):
+		out = "https://" + out[len("hxxps://"):]
+	case strings.HasPrefix(lower, "hxxp://"):
+		out = "http://" + out[len("hxxp://"):]
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package incidents
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// ObservableImportError describes a rejected row (CSV) or object (STIX) of a bulk import.
+type ObservableImportError struct {
+	Row    int    `json:"row,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+var observablesCSVHeader = []string{"type", "value", "tlp", "description", "first_seen", "last_seen"}
+
+func WriteObservablesCSV(w io.Writer, items []store.Observable) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(observablesCSVHeader); err != nil {
+		return err
+	}
+	for _, o := range items {
+		if err := writer.Write([]string{
+			o.Type,
+			o.Value,
+			o.TLP,
+			o.Description,
+			formatObservableTime(o.FirstSeenAt),
+			formatObservableTime(o.LastSeenAt),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ParseObservablesCSV reads a CSV with a header row. Only "type" and "value" columns are
+// required; the rest of observablesCSVHeader is optional and may come in any order.
+func ParseObservablesCSV(data []byte) ([]store.Observable, []ObservableImportError, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.New("empty csv")
+	}
+	index := map[string]int{}
+	for i, h := range rows[0] {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := index["type"]; !ok {
+		return nil, nil, errors.New("type column required")
+	}
+	if _, ok := index["value"]; !ok {
+		return nil, nil, errors.New("value column required")
+	}
+	col := func(row []string, name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	var out []store.Observable
+	var failures []ObservableImportError
+	for n, row := range rows[1:] {
+		rowNum := n + 2
+		if len(strings.TrimSpace(strings.Join(row, ""))) == 0 {
+			continue
+		}
+		item, err := buildImportedObservable(col(row, "type"), col(row, "value"), col(row, "tlp"), col(row, "description"), col(row, "first_seen"), col(row, "last_seen"))
+		if err != nil {
+			failures = append(failures, ObservableImportError{Row: rowNum, Value: col(row, "value"), Reason: err.Error()})
+			continue
+		}
+		out = append(out, *item)
+	}
+	return out, failures, nil
+}
+
+func buildImportedObservable(obsType, value, tlp, description, firstSeen, lastSeen string) (*store.Observable, error) {
+	t, v, err := NormalizeObservable(obsType, value)
+	if err != nil {
+		return nil, err
+	}
+	level, err := NormalizeTLP(tlp)
+	if err != nil {
+		return nil, err
+	}
+	first, err := parseObservableTime(firstSeen)
+	if err != nil {
+		return nil, errors.New("incidents.observables.timeInvalid")
+	}
+	last, err := parseObservableTime(lastSeen)
+	if err != nil {
+		return nil, errors.New("incidents.observables.timeInvalid")
+	}
+	if first != nil && last != nil && last.Before(*first) {
+		return nil, errors.New("incidents.observables.seenRangeInvalid")
+	}
+	return &store.Observable{
+		Type:        t,
+		Value:       v,
+		TLP:         level,
+		Description: description,
+		FirstSeenAt: first,
+		LastSeenAt:  last,
+	}, nil
+}
+
+func formatObservableTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseObservableTime(raw string) (*time.Time, error) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, errors.New("bad time")
+}
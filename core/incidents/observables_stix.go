@@ -0,0 +1,338 @@
+package incidents
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+	"github.com/gofrs/uuid/v5"
+)
+
+// STIX 2.1 namespace for deterministic SCO identifiers (section 2.9 of the spec).
+var stixSCONamespace = uuid.Must(uuid.FromString("00abedb4-aa42-466c-9c01-fed23315a9b7"))
+
+// Predefined TLP 1.0 marking definitions from the STIX 2.1 specification. They need not be
+// shipped inside the bundle. AMBER+STRICT has no 1.0 equivalent and is exported as AMBER
+// with the exact level kept in x_scc_tlp.
+var stixTLPMarkings = map[string]string{
+	"clear": "marking-definition--613f2e26-407d-48c7-9eca-b8e91df99dc9",
+	"green": "marking-definition--34098fce-860f-48ae-8e50-ebd3cc5e41da",
+	"amber": "marking-definition--f88d31f6-486f-44da-b317-01333bde0b82",
+	"red":   "marking-definition--5e57c739-391a-4eb3-b6be-7d15ca92d5ed",
+}
+
+// TLP 2.0 marking definitions published with the OASIS TLP 2.0 extension, accepted on import.
+var stixTLP2Markings = map[string]string{
+	"marking-definition--94868c89-83c2-464b-929b-a1a8aa3c8487": "clear",
+	"marking-definition--bab4a63c-aed9-4cf5-a766-dfca5abac2bb": "green",
+	"marking-definition--55d920b0-5e8b-4f79-9ee9-91f868d9b421": "amber",
+	"marking-definition--939a9414-2ddd-4d32-a0cd-375ea402b003": "amber+strict",
+	"marking-definition--e828b379-4e03-4974-9ac4-e53a884c97c1": "red",
+}
+
+var stixHashKeys = map[string]string{
+	ObservableMD5:    "MD5",
+	ObservableSHA1:   "SHA-1",
+	ObservableSHA256: "SHA-256",
+	ObservableSHA512: "SHA-512",
+}
+
+// ExportSTIXBundle renders observables as a STIX 2.1 bundle of cyber-observable objects.
+// CVE identifiers become vulnerability SDOs since STIX has no SCO for them.
+func ExportSTIXBundle(items []store.Observable, now time.Time) ([]byte, error) {
+	ts := now.UTC().Format("2006-01-02T15:04:05.000Z")
+	objects := make([]map[string]any, 0, len(items))
+	for _, o := range items {
+		obj, err := observableToSTIX(o, ts)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	bundleID, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(map[string]any{
+		"type":    "bundle",
+		"id":      "bundle--" + bundleID.String(),
+		"objects": objects,
+	}, "", "  ")
+}
+
+func observableToSTIX(o store.Observable, ts string) (map[string]any, error) {
+	obj := map[string]any{"spec_version": "2.1"}
+	var idContrib map[string]any
+	switch o.Type {
+	case ObservableIP:
+		obj["type"] = "ipv4-addr"
+		if ip := net.ParseIP(o.Value); ip != nil && ip.To4() == nil {
+			obj["type"] = "ipv6-addr"
+		}
+		obj["value"] = o.Value
+		idContrib = map[string]any{"value": o.Value}
+	case ObservableDomain:
+		obj["type"] = "domain-name"
+		obj["value"] = o.Value
+		idContrib = map[string]any{"value": o.Value}
+	case ObservableURL:
+		obj["type"] = "url"
+		obj["value"] = o.Value
+		idContrib = map[string]any{"value": o.Value}
+	case ObservableEmail:
+		obj["type"] = "email-addr"
+		obj["value"] = o.Value
+		idContrib = map[string]any{"value": o.Value}
+	case ObservableUsername:
+		obj["type"] = "user-account"
+		obj["account_login"] = o.Value
+		idContrib = map[string]any{"account_login": o.Value}
+	case ObservableMD5, ObservableSHA1, ObservableSHA256, ObservableSHA512:
+		hashes := map[string]any{stixHashKeys[o.Type]: o.Value}
+		obj["type"] = "file"
+		obj["hashes"] = hashes
+		idContrib = map[string]any{"hashes": hashes}
+	case ObservableCVE:
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		obj["type"] = "vulnerability"
+		obj["id"] = "vulnerability--" + id.String()
+		obj["created"] = ts
+		obj["modified"] = ts
+		obj["name"] = o.Value
+		obj["external_references"] = []map[string]any{{"source_name": "cve", "external_id": o.Value}}
+		if o.Description != "" {
+			obj["description"] = o.Description
+		}
+	default:
+		return nil, ErrObservableType
+	}
+	if idContrib != nil {
+		raw, _ := json.Marshal(idContrib)
+		obj["id"] = obj["type"].(string) + "--" + uuid.NewV5(stixSCONamespace, string(raw)).String()
+		if o.Description != "" {
+			obj["x_scc_description"] = o.Description
+		}
+	}
+	tlp := o.TLP
+	if tlp == "" {
+		tlp = DefaultTLP
+	}
+	marking := stixTLPMarkings[tlp]
+	if tlp == "amber+strict" {
+		marking = stixTLPMarkings["amber"]
+	}
+	obj["object_marking_refs"] = []string{marking}
+	obj["x_scc_tlp"] = tlp
+	if o.FirstSeenAt != nil {
+		obj["x_scc_first_seen"] = o.FirstSeenAt.UTC().Format(time.RFC3339)
+	}
+	if o.LastSeenAt != nil {
+		obj["x_scc_last_seen"] = o.LastSeenAt.UTC().Format(time.RFC3339)
+	}
+	return obj, nil
+}
+
+type stixObject struct {
+	Type               string            `json:"type"`
+	ID                 string            `json:"id"`
+	Value              string            `json:"value"`
+	AccountLogin       string            `json:"account_login"`
+	UserID             string            `json:"user_id"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Hashes             map[string]string `json:"hashes"`
+	Pattern            string            `json:"pattern"`
+	PatternType        string            `json:"pattern_type"`
+	ValidFrom          string            `json:"valid_from"`
+	FirstObserved      string            `json:"first_observed"`
+	LastObserved       string            `json:"last_observed"`
+	ObjectMarkingRefs  []string          `json:"object_marking_refs"`
+	ObjectRefs         []string          `json:"object_refs"`
+	ExternalReferences []struct {
+		SourceName string `json:"source_name"`
+		ExternalID string `json:"external_id"`
+	} `json:"external_references"`
+	Definition struct {
+		TLP string `json:"tlp"`
+	} `json:"definition"`
+	DefinitionType string `json:"definition_type"`
+	XSCCTLP        string `json:"x_scc_tlp"`
+	XSCCDesc       string `json:"x_scc_description"`
+	XSCCFirstSeen  string `json:"x_scc_first_seen"`
+	XSCCLastSeen   string `json:"x_scc_last_seen"`
+}
+
+// Only simple equality comparisons are understood, e.g. [ipv4-addr:value = '203.0.113.7'].
+var reSTIXComparison = regexp.MustCompile(`([a-z0-9-]+):([a-z_]+(?:\.'?[A-Za-z0-9-]+'?)?)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+// ParseSTIXBundle extracts observables from a STIX 2.1 bundle. SCOs, vulnerability SDOs with
+// CVE references and indicators with STIX patterns are supported; other objects are ignored.
+func ParseSTIXBundle(data []byte) ([]store.Observable, []ObservableImportError, error) {
+	var bundle struct {
+		Type    string            `json:"type"`
+		Objects []json.RawMessage `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, nil, err
+	}
+	if bundle.Type != "bundle" {
+		return nil, nil, errors.New("not a stix bundle")
+	}
+	objects := make([]stixObject, 0, len(bundle.Objects))
+	markings := map[string]string{}
+	for k, v := range stixTLP2Markings {
+		markings[k] = v
+	}
+	for k, v := range stixTLPMarkings {
+		markings[v] = k
+	}
+	for _, raw := range bundle.Objects {
+		var obj stixObject
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			continue
+		}
+		if obj.Type == "marking-definition" {
+			if lvl, err := NormalizeTLP(firstNonEmpty(obj.Definition.TLP, obj.Name)); err == nil && (obj.Definition.TLP != "" || strings.HasPrefix(strings.ToUpper(obj.Name), "TLP:")) {
+				markings[obj.ID] = lvl
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	var out []store.Observable
+	var failures []ObservableImportError
+	add := func(obj stixObject, obsType, value string, first, last string) {
+		tlp := obj.XSCCTLP
+		if tlp == "" {
+			for _, ref := range obj.ObjectMarkingRefs {
+				if lvl, ok := markings[ref]; ok {
+					tlp = lvl
+					break
+				}
+			}
+		}
+		item, err := buildImportedObservable(obsType, value, tlp, firstNonEmpty(obj.XSCCDesc, obj.Description), first, last)
+		if err != nil {
+			failures = append(failures, ObservableImportError{Ref: obj.ID, Value: value, Reason: err.Error()})
+			return
+		}
+		out = append(out, *item)
+	}
+	for _, obj := range objects {
+		first := firstNonEmpty(obj.XSCCFirstSeen, obj.FirstObserved, obj.ValidFrom)
+		last := firstNonEmpty(obj.XSCCLastSeen, obj.LastObserved)
+		switch obj.Type {
+		case "ipv4-addr", "ipv6-addr":
+			add(obj, ObservableIP, obj.Value, first, last)
+		case "domain-name":
+			add(obj, ObservableDomain, obj.Value, first, last)
+		case "url":
+			add(obj, ObservableURL, obj.Value, first, last)
+		case "email-addr":
+			add(obj, ObservableEmail, obj.Value, first, last)
+		case "user-account":
+			add(obj, ObservableUsername, firstNonEmpty(obj.AccountLogin, obj.UserID), first, last)
+		case "file":
+			for _, t := range sortedHashTypes() {
+				if v := lookupHash(obj.Hashes, stixHashKeys[t]); v != "" {
+					add(obj, t, v, first, last)
+				}
+			}
+		case "vulnerability":
+			cve := ""
+			for _, ref := range obj.ExternalReferences {
+				if strings.EqualFold(ref.SourceName, "cve") {
+					cve = ref.ExternalID
+					break
+				}
+			}
+			if cve == "" && reCVE.MatchString(strings.ToUpper(strings.TrimSpace(obj.Name))) {
+				cve = obj.Name
+			}
+			if cve != "" {
+				add(obj, ObservableCVE, cve, first, last)
+			}
+		case "indicator":
+			if obj.PatternType != "" && obj.PatternType != "stix" {
+				continue
+			}
+			for _, m := range reSTIXComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+				obsType := stixPatternType(m[1], m[2])
+				if obsType == "" {
+					failures = append(failures, ObservableImportError{Ref: obj.ID, Value: m[3], Reason: ErrObservableType.Error()})
+					continue
+				}
+				add(obj, obsType, strings.ReplaceAll(m[3], `\'`, `'`), first, last)
+			}
+		}
+	}
+	return out, failures, nil
+}
+
+func stixPatternType(objType, path string) string {
+	switch objType {
+	case "ipv4-addr", "ipv6-addr":
+		if path == "value" {
+			return ObservableIP
+		}
+	case "domain-name":
+		if path == "value" {
+			return ObservableDomain
+		}
+	case "url":
+		if path == "value" {
+			return ObservableURL
+		}
+	case "email-addr":
+		if path == "value" {
+			return ObservableEmail
+		}
+	case "user-account":
+		if path == "account_login" || path == "user_id" {
+			return ObservableUsername
+		}
+	case "file":
+		key := strings.Trim(strings.TrimPrefix(path, "hashes."), "'")
+		for t, k := range stixHashKeys {
+			if strings.EqualFold(key, k) || strings.EqualFold(key, strings.ReplaceAll(k, "-", "")) {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+func lookupHash(hashes map[string]string, key string) string {
+	for k, v := range hashes {
+		if strings.EqualFold(k, key) || strings.EqualFold(k, strings.ReplaceAll(key, "-", "")) {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedHashTypes() []string {
+	out := make([]string, 0, len(stixHashKeys))
+	for t := range stixHashKeys {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"berkut-scc/config"
+	"berkut-scc/core/notify"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
@@ -16,6 +17,7 @@ type Service struct {
 	audits store.AuditStore
 	encryptor *utils.Encryptor
 	storageDir string
+	notifier *notify.Service
 }
 
 func NewService(cfg *config.AppConfig, audits store.AuditStore) (*Service, error) {
@@ -39,6 +41,17 @@ func (s *Service) Log(ctx context.Context, username, action, details string) {
 	}
 }
 
+func (s *Service) SetNotifier(n *notify.Service) {
+	s.notifier = n
+}
+
+func (s *Service) Notifier() *notify.Service {
+	if s == nil {
+		return nil
+	}
+	return s.notifier
+}
+
 func (s *Service) StorageDir() string {
 	return s.storageDir
 }
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"berkut-scc/core/notify"
 	"berkut-scc/core/store"
 	"berkut-scc/core/utils"
 	"berkut-scc/tasks"
@@ -22,6 +23,9 @@ type Engine struct {
 	sender            TelegramSender
 	incidentRegFormat string
 	taskStore         tasks.Store
+	assets            store.AssetsStore
+	users             store.UsersStore
+	notifier          *notify.Service
 	logger            *utils.Logger
 	tuning            Tuning
 	obs               *engineObservability
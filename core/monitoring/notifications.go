@@ -77,6 +77,22 @@ func (e *Engine) TestTelegram(ctx context.Context, msg TelegramMessage) error {
 	return e.sender.Send(ctx, msg)
 }
 
+// SendChannelMessage delivers text through one notification channel; used for user notifications
+// that their owners route to an external channel.
+func (e *Engine) SendChannelMessage(ctx context.Context, channelID int64, eventType, text string) error {
+	if e == nil || e.sender == nil || e.encryptor == nil || e.store == nil {
+		return errors.New("telegram sender unavailable")
+	}
+	ch, err := e.store.GetNotificationChannel(ctx, channelID)
+	if err != nil || ch == nil {
+		return errors.New("common.notFound")
+	}
+	if !e.dispatchNotification(ctx, []store.NotificationChannel{*ch}, TelegramMessage{Text: text}, eventType, nil) {
+		return errors.New("monitoring.notifications.testFailed")
+	}
+	return nil
+}
+
 func (e *Engine) TestTLSNotification(ctx context.Context, monitorID int64) error {
 	if e == nil || e.sender == nil || e.encryptor == nil {
 		return errors.New("telegram sender unavailable")
@@ -157,6 +173,7 @@ func (e *Engine) handleAutomation(ctx context.Context, m store.Monitor, prev, ne
 	}
 	e.handleNotifications(ctx, m, prev, next, effectiveStatus, now, st, tlsRecord, result, settings)
 	e.handleAutoTaskOnDown(ctx, m, prev, next, now)
+	e.handleOwnerNotifications(ctx, m, prev, next)
 	e.handleAutoTLSIncident(ctx, m, prev, next, tlsRecord, now, settings)
 	e.handleAutoIncident(ctx, m, prev, next, effectiveStatus, now, st, settings)
 	_ = e.store.UpsertNotificationState(ctx, st)
@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+)
+
+// SetOwnerNotifier enables inbox notifications to the owners of assets linked to a failing monitor.
+func (e *Engine) SetOwnerNotifier(assets store.AssetsStore, users store.UsersStore, notifier *notify.Service) {
+	if e == nil {
+		return
+	}
+	e.assets = assets
+	e.users = users
+	e.notifier = notifier
+}
+
+func (e *Engine) handleOwnerNotifications(ctx context.Context, m store.Monitor, prev, next *store.MonitorState) {
+	if e.notifier == nil || e.assets == nil || e.users == nil {
+		return
+	}
+	if next == nil || m.IsPaused || next.MaintenanceActive {
+		return
+	}
+	// Same transition rule as auto tasks: only a confirmed DOWN after retries.
+	if monitorRawStatus(next) != "down" || next.RetryAt != nil {
+		return
+	}
+	if monitorRawStatus(prev) == "down" && prev != nil && prev.RetryAt == nil {
+		return
+	}
+	owners := e.assetOwnerIDs(ctx, m.ID)
+	if len(owners) == 0 {
+		return
+	}
+	body := strings.ToUpper(strings.TrimSpace(m.Type))
+	if host := monitorTargetHost(m); host != "" {
+		body = fmt.Sprintf("%s: %s", body, host)
+	}
+	if msg := strings.TrimSpace(next.LastError); msg != "" {
+		body += "\n" + msg
+	}
+	e.notifier.Notify(ctx, store.Notification{
+		EventType:  notify.EventMonitorDown,
+		Title:      automationMonitorDisplayName(m),
+		Body:       body,
+		EntityType: "monitor",
+		EntityID:   strconv.FormatInt(m.ID, 10),
+		Link:       "/monitoring",
+	}, owners...)
+}
+
+// assetOwnerIDs resolves active owners of the assets linked to the monitor.
+func (e *Engine) assetOwnerIDs(ctx context.Context, monitorID int64) []int64 {
+	linked, err := e.store.ListMonitorAssets(ctx, monitorID)
+	if err != nil {
+		return nil
+	}
+	seen := map[int64]struct{}{}
+	var ids []int64
+	for _, item := range linked {
+		a, err := e.assets.GetAsset(ctx, item.ID)
+		if err != nil || a == nil || a.DeletedAt != nil || strings.TrimSpace(a.Owner) == "" {
+			continue
+		}
+		u, _, err := e.users.FindByUsername(ctx, strings.TrimSpace(a.Owner))
+		if err != nil || u == nil || !u.Active {
+			continue
+		}
+		if _, ok := seen[u.ID]; ok {
+			continue
+		}
+		seen[u.ID] = struct{}{}
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func monitorTargetHost(m store.Monitor) string {
+	host := strings.TrimSpace(m.Host)
+	if host == "" && strings.TrimSpace(m.URL) != "" {
+		if u, err := url.Parse(strings.TrimSpace(m.URL)); err == nil {
+			host = strings.TrimSpace(u.Hostname())
+		}
+	}
+	if parsed, _ := splitHostPort(host); parsed != "" {
+		host = parsed
+	}
+	return strings.ToLower(host)
+}
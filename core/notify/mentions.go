@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	SourceTaskComment      = "task_comment"
+	SourceIncidentTimeline = "incident_timeline"
+	SourceControlComment   = "control_comment"
+
+	maxMentionsPerText = 20
+	mentionExcerptLen  = 200
+)
+
+// mentionPattern matches @username that is not part of an e-mail address or another word.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]{0,63})`)
+
+// MentionSource describes the text that mentions users and the entity the mention links to.
+type MentionSource struct {
+	SourceType string
+	SourceID   string
+	EntityType string
+	EntityID   string
+	Title      string
+	Link       string
+}
+
+// MentionVisibility reports whether the mentioned user can see the entity; roles are the user's
+// direct roles as returned by UsersStore.
+type MentionVisibility func(ctx context.Context, u *store.User, roles []string) bool
+
+// ParseMentions returns the distinct lower-cased usernames mentioned in text, in order of appearance.
+func ParseMentions(text string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], ".-"))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+		if len(out) >= maxMentionsPerText {
+			break
+		}
+	}
+	return out
+}
+
+func (s *Service) SetMentionStore(ms store.MentionsStore, users store.UsersStore) {
+	if s == nil {
+		return
+	}
+	s.mentions = ms
+	s.users = users
+}
+
+func (s *Service) Mentions() store.MentionsStore {
+	if s == nil {
+		return nil
+	}
+	return s.mentions
+}
+
+// RecordMentions resolves @usernames in text, stores them as references of src and notifies users
+// mentioned for the first time. Users that cannot see the entity are neither stored nor notified,
+// so the mention does not leak the entity. Editing the text to drop a mention removes its reference.
+func (s *Service) RecordMentions(ctx context.Context, src MentionSource, author *store.User, text string, canSee MentionVisibility) []store.Mention {
+	if s == nil || s.mentions == nil || s.users == nil {
+		return nil
+	}
+	var items []store.Mention
+	for _, name := range ParseMentions(text) {
+		u, roles, err := s.users.FindByUsername(ctx, name)
+		if err != nil || u == nil || !u.Active {
+			continue
+		}
+		if author != nil && u.ID == author.ID {
+			continue
+		}
+		if canSee != nil && !canSee(ctx, u, roles) {
+			continue
+		}
+		m := store.Mention{
+			UserID:     u.ID,
+			Username:   u.Username,
+			EntityType: src.EntityType,
+			EntityID:   src.EntityID,
+			Title:      src.Title,
+			Excerpt:    mentionExcerpt(text),
+			Link:       src.Link,
+		}
+		if author != nil {
+			m.AuthorID = &author.ID
+		}
+		items = append(items, m)
+	}
+	added, err := s.mentions.SaveMentions(ctx, src.SourceType, src.SourceID, items)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Errorf("mentions %s/%s: %v", src.SourceType, src.SourceID, err)
+		}
+		return nil
+	}
+	for _, m := range added {
+		n := store.Notification{
+			EventType:  EventMention,
+			Title:      src.Title,
+			Body:       m.Excerpt,
+			EntityType: src.EntityType,
+			EntityID:   src.EntityID,
+			Link:       src.Link,
+		}
+		if author != nil {
+			n.ActorID = &author.ID
+			n.Body = author.Username + ": " + m.Excerpt
+		}
+		s.Notify(ctx, n, m.UserID)
+	}
+	return items
+}
+
+// ClearMentions drops the references of a deleted comment or note.
+func (s *Service) ClearMentions(ctx context.Context, sourceType, sourceID string) {
+	if s == nil || s.mentions == nil {
+		return
+	}
+	_ = s.mentions.DeleteMentions(ctx, sourceType, sourceID)
+}
+
+func mentionExcerpt(text string) string {
+	clean := strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(clean) <= mentionExcerptLen {
+		return clean
+	}
+	runes := []rune(clean)
+	return string(runes[:mentionExcerptLen]) + "…"
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+const (
+	EventTaskAssigned      = "task.assigned"
+	EventApprovalRequested = "approval.requested"
+	EventIncidentAssigned  = "incident.assigned"
+	EventMention           = "mention"
+	EventMonitorDown       = "monitor.down"
+	EventFindingOverdue    = "finding.overdue"
+	EventFindingException  = "finding.exception"
+	EventReportDelivered   = "report.delivered"
+)
+
+// EventTypes lists the events users can receive, in the order the preferences UI shows them.
+var EventTypes = []string{EventTaskAssigned, EventApprovalRequested, EventIncidentAssigned, EventMention, EventMonitorDown, EventFindingOverdue, EventFindingException, EventReportDelivered}
+
+const (
+	// KindNotification carries a new notification; KindSync tells the other sessions of the user
+	// that read/dismiss state changed and the unread counter must be refreshed.
+	KindNotification = "notification"
+	KindSync         = "sync"
+
+	subscriberBuffer = 16
+	externalTimeout  = 15 * time.Second
+)
+
+// ExternalSender delivers a notification text through a configured notification channel.
+type ExternalSender interface {
+	SendChannelMessage(ctx context.Context, channelID int64, eventType, text string) error
+}
+
+type Event struct {
+	Kind         string              `json:"kind"`
+	Notification *store.Notification `json:"notification,omitempty"`
+	Unread       int                 `json:"unread"`
+}
+
+type Service struct {
+	store    store.NotificationsStore
+	mentions store.MentionsStore
+	users    store.UsersStore
+	logger   *utils.Logger
+	external ExternalSender
+	mu       sync.Mutex
+	subs     map[int64]map[chan Event]struct{}
+	wg       sync.WaitGroup
+}
+
+func NewService(ns store.NotificationsStore, logger *utils.Logger) *Service {
+	return &Service{store: ns, logger: logger, subs: map[int64]map[chan Event]struct{}{}}
+}
+
+func (s *Service) SetExternalSender(sender ExternalSender) {
+	if s == nil {
+		return
+	}
+	s.external = sender
+}
+
+func (s *Service) Store() store.NotificationsStore {
+	if s == nil {
+		return nil
+	}
+	return s.store
+}
+
+func IsEventType(v string) bool {
+	for _, t := range EventTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify stores a copy of n for every recipient (the actor never notifies themselves), pushes it
+// to their open streams and forwards it to the external channel chosen in their preferences.
+// It is safe to call on a nil service.
+func (s *Service) Notify(ctx context.Context, n store.Notification, userIDs ...int64) int {
+	if s == nil || s.store == nil {
+		return 0
+	}
+	seen := map[int64]bool{}
+	sent := 0
+	for _, uid := range userIDs {
+		if uid <= 0 || seen[uid] || (n.ActorID != nil && *n.ActorID == uid) {
+			continue
+		}
+		seen[uid] = true
+		item := n
+		item.ID = 0
+		item.UserID = uid
+		if _, err := s.store.CreateNotification(ctx, &item); err != nil {
+			if s.logger != nil {
+				s.logger.Errorf("notify %s user=%d: %v", n.EventType, uid, err)
+			}
+			continue
+		}
+		sent++
+		unread, _ := s.store.CountUnreadNotifications(ctx, uid)
+		s.publish(uid, Event{Kind: KindNotification, Notification: &item, Unread: unread})
+		s.forward(ctx, item)
+	}
+	return sent
+}
+
+// Sync broadcasts the current unread counter to the user's streams after read/dismiss changes.
+func (s *Service) Sync(ctx context.Context, userID int64) {
+	if s == nil || s.store == nil {
+		return
+	}
+	unread, _ := s.store.CountUnreadNotifications(ctx, userID)
+	s.publish(userID, Event{Kind: KindSync, Unread: unread})
+}
+
+// Subscribe registers a stream for userID. Slow consumers lose events rather than block producers;
+// the client reloads the inbox on reconnect anyway.
+func (s *Service) Subscribe(userID int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	s.mu.Lock()
+	if s.subs[userID] == nil {
+		s.subs[userID] = map[chan Event]struct{}{}
+	}
+	s.subs[userID][ch] = struct{}{}
+	s.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs[userID], ch)
+			if len(s.subs[userID]) == 0 {
+				delete(s.subs, userID)
+			}
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Wait blocks until pending external deliveries are finished.
+func (s *Service) Wait() {
+	if s == nil {
+		return
+	}
+	s.wg.Wait()
+}
+
+func (s *Service) publish(userID int64, ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs[userID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (s *Service) forward(ctx context.Context, n store.Notification) {
+	if s.external == nil {
+		return
+	}
+	prefs, err := s.store.ListNotificationPreferences(ctx, n.UserID)
+	if err != nil {
+		return
+	}
+	for _, p := range prefs {
+		if p.EventType != n.EventType || !p.External || p.ChannelID == nil {
+			continue
+		}
+		channelID := *p.ChannelID
+		text := externalText(n)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			sendCtx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+			defer cancel()
+			if err := s.external.SendChannelMessage(sendCtx, channelID, "user."+n.EventType, text); err != nil && s.logger != nil {
+				s.logger.Errorf("notify external channel=%d user=%d: %v", channelID, n.UserID, err)
+			}
+		}()
+		return
+	}
+}
+
+func externalText(n store.Notification) string {
+	parts := []string{strings.TrimSpace(n.Title)}
+	if body := strings.TrimSpace(n.Body); body != "" {
+		parts = append(parts, body)
+	}
+	return strings.Join(parts, "\n")
+}
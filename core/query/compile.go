@@ -0,0 +1,366 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// CustomFieldPrefix marks custom field conditions: cf.<key>:<value>.
+const CustomFieldPrefix = "cf."
+
+// likeEscaped is the LIKE operator for patterns built with store.EscapeLike, so
+// that % and _ in query values match literally.
+const likeEscaped = `LIKE ? ESCAPE '\'`
+
+// Env is the context a query is compiled in.
+type Env struct {
+	// UserID resolves "me" and "mine".
+	UserID int64
+	// Now is the reference time of relative dates; zero means the current time.
+	Now time.Time
+	// CustomField returns the condition matching entities whose custom field key has
+	// the value. Without it cf.* fields are rejected.
+	CustomField func(key, value string) (string, []any, error)
+}
+
+// Compile turns a parsed query into a WHERE condition over the entity table.
+// A nil node compiles to a nil clause.
+func Compile(entityType string, node Node, env Env) (*store.QueryClause, error) {
+	schema, ok := schemas[entityType]
+	if !ok {
+		return nil, &Error{Code: errField}
+	}
+	if node == nil {
+		return nil, nil
+	}
+	if env.Now.IsZero() {
+		env.Now = time.Now()
+	}
+	c := &compiler{schema: schema, env: env}
+	sql, err := c.node(node)
+	if err != nil {
+		return nil, err
+	}
+	return &store.QueryClause{SQL: sql, Args: c.args}, nil
+}
+
+type compiler struct {
+	schema map[string]field
+	env    Env
+	args   []any
+}
+
+func (c *compiler) node(n Node) (string, error) {
+	switch n := n.(type) {
+	case And:
+		return c.binary(n.Left, n.Right, "AND")
+	case Or:
+		return c.binary(n.Left, n.Right, "OR")
+	case Not:
+		inner, err := c.node(n.Node)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case Term:
+		sql, args, err := c.term(n)
+		if err != nil {
+			return "", err
+		}
+		c.args = append(c.args, args...)
+		return "(" + sql + ")", nil
+	}
+	return "", &Error{Code: errSyntax}
+}
+
+func (c *compiler) binary(left, right Node, op string) (string, error) {
+	l, err := c.node(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.node(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + op + " " + r + ")", nil
+}
+
+func (c *compiler) term(t Term) (string, []any, error) {
+	if strings.HasPrefix(t.Field, CustomFieldPrefix) {
+		return c.customField(t)
+	}
+	f, ok := c.schema[t.Field]
+	if !ok {
+		return "", nil, &Error{Code: errField, Field: t.Field}
+	}
+	if len(t.Values) == 1 && strings.EqualFold(t.Values[0], "null") {
+		return c.null(t, f)
+	}
+	op := t.Op
+	if op == OpNe {
+		op = OpEq
+	}
+	sql, args, err := c.condition(t.Field, f, op, t.Values)
+	if err != nil {
+		return "", nil, err
+	}
+	if f.wrap != "" {
+		sql = fmt.Sprintf(f.wrap, sql)
+	}
+	if t.Op != OpNe {
+		return sql, args, nil
+	}
+	if f.wrap != "" || f.kind == kindBool || f.kind == kindTags || f.kind == kindList {
+		return "NOT (" + sql + ")", args, nil
+	}
+	// Plain columns may be NULL; such rows differ from any value.
+	return f.column + " IS NULL OR NOT (" + sql + ")", args, nil
+}
+
+func (c *compiler) null(t Term, f field) (string, []any, error) {
+	if t.Op != OpEq && t.Op != OpNe {
+		return "", nil, &Error{Code: errOperator, Field: t.Field}
+	}
+	var sql string
+	switch {
+	case f.wrap != "":
+		return "", nil, &Error{Code: errValue, Field: t.Field}
+	case f.kind == kindString || f.kind == kindText:
+		sql = f.column + " IS NULL OR " + f.column + "=''"
+	case f.kind == kindNumber || f.kind == kindDate || f.kind == kindUser:
+		sql = f.column + " IS NULL"
+	default:
+		return "", nil, &Error{Code: errValue, Field: t.Field}
+	}
+	if t.Op == OpNe {
+		sql = "NOT (" + sql + ")"
+	}
+	return sql, nil, nil
+}
+
+func (c *compiler) customField(t Term) (string, []any, error) {
+	key := strings.TrimPrefix(t.Field, CustomFieldPrefix)
+	if c.env.CustomField == nil || key == "" {
+		return "", nil, &Error{Code: errField, Field: t.Field}
+	}
+	if t.Op != OpEq && t.Op != OpNe {
+		return "", nil, &Error{Code: errOperator, Field: t.Field}
+	}
+	var parts []string
+	var args []any
+	for _, value := range t.Values {
+		sql, valueArgs, err := c.env.CustomField(key, value)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, sql)
+		args = append(args, valueArgs...)
+	}
+	sql := strings.Join(parts, " OR ")
+	if t.Op == OpNe {
+		sql = "NOT (" + sql + ")"
+	}
+	return sql, args, nil
+}
+
+// condition builds the positive condition of a term; op is never OpNe here.
+func (c *compiler) condition(name string, f field, op string, values []string) (string, []any, error) {
+	opError := &Error{Code: errOperator, Field: name}
+	switch f.kind {
+	case kindString:
+		switch op {
+		case OpEq:
+			args := make([]any, 0, len(values))
+			for _, v := range values {
+				args = append(args, strings.ToLower(v))
+			}
+			return "LOWER(" + f.column + ") IN (" + placeholders(len(args)) + ")", args, nil
+		case OpContains:
+			return c.each(values, func(v string) (string, []any, error) {
+				return "LOWER(" + f.column + ") " + likeEscaped, []any{"%" + store.EscapeLike(strings.ToLower(v)) + "%"}, nil
+			})
+		}
+		return "", nil, opError
+	case kindText:
+		if op != OpEq && op != OpContains {
+			return "", nil, opError
+		}
+		return c.each(values, func(v string) (string, []any, error) {
+			return "LOWER(" + f.column + ") " + likeEscaped, []any{"%" + store.EscapeLike(strings.ToLower(v)) + "%"}, nil
+		})
+	case kindNumber:
+		if op == OpContains {
+			return "", nil, opError
+		}
+		args := make([]any, 0, len(values))
+		for _, v := range values {
+			num, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", nil, &Error{Code: errValue, Field: name}
+			}
+			args = append(args, num)
+		}
+		if op == OpEq {
+			return f.column + " IN (" + placeholders(len(args)) + ")", args, nil
+		}
+		return f.column + op + "?", args, nil
+	case kindDate:
+		if op == OpContains {
+			return "", nil, opError
+		}
+		return c.each(values, func(v string) (string, []any, error) {
+			at, day, err := parseDate(v, c.env.Now)
+			if err != nil {
+				return "", nil, &Error{Code: errValue, Field: name}
+			}
+			if !day {
+				return f.column + op + "?", []any{at}, nil
+			}
+			next := at.AddDate(0, 0, 1)
+			switch op {
+			case OpEq:
+				return f.column + ">=? AND " + f.column + "<?", []any{at, next}, nil
+			case OpGt:
+				return f.column + ">=?", []any{next}, nil
+			case OpLe:
+				return f.column + "<?", []any{next}, nil
+			}
+			return f.column + op + "?", []any{at}, nil
+		})
+	case kindBool:
+		if op != OpEq || len(values) != 1 {
+			return "", nil, opError
+		}
+		value, err := strconv.ParseBool(strings.ToLower(values[0]))
+		if err != nil {
+			switch strings.ToLower(values[0]) {
+			case "yes":
+				value = true
+			case "no":
+				value = false
+			default:
+				return "", nil, &Error{Code: errValue, Field: name}
+			}
+		}
+		if value {
+			return f.column, nil, nil
+		}
+		return "NOT (" + f.column + ")", nil, nil
+	case kindTags, kindList:
+		if op != OpEq && op != OpContains {
+			return "", nil, opError
+		}
+		return c.each(values, func(v string) (string, []any, error) {
+			if f.kind == kindTags {
+				v = strings.ToUpper(v)
+			}
+			if op == OpContains {
+				return f.column + " " + likeEscaped, []any{"%" + store.EscapeLike(v) + "%"}, nil
+			}
+			raw, _ := json.Marshal(v)
+			return f.column + " " + likeEscaped, []any{"%" + store.EscapeLike(string(raw)) + "%"}, nil
+		})
+	case kindUser:
+		if op != OpEq {
+			return "", nil, opError
+		}
+		return c.each(values, func(v string) (string, []any, error) {
+			if strings.EqualFold(v, "me") {
+				return f.column + "=?", []any{c.env.UserID}, nil
+			}
+			if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return f.column + "=?", []any{id}, nil
+			}
+			return f.column + " IN (SELECT id FROM users WHERE LOWER(username)=?)", []any{strings.ToLower(v)}, nil
+		})
+	case kindGroup:
+		if op != OpEq {
+			return "", nil, opError
+		}
+		return c.each(values, func(v string) (string, []any, error) {
+			if strings.EqualFold(v, "mine") {
+				return f.column + " IN (SELECT user_id FROM user_groups WHERE group_id IN (SELECT group_id FROM user_groups WHERE user_id=?))", []any{c.env.UserID}, nil
+			}
+			if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return f.column + " IN (SELECT user_id FROM user_groups WHERE group_id=?)", []any{id}, nil
+			}
+			return f.column + " IN (SELECT ug.user_id FROM user_groups ug JOIN groups gr ON gr.id=ug.group_id WHERE LOWER(gr.name)=?)", []any{strings.ToLower(v)}, nil
+		})
+	}
+	return "", nil, opError
+}
+
+// each joins the conditions built for every value with OR.
+func (c *compiler) each(values []string, build func(string) (string, []any, error)) (string, []any, error) {
+	parts := make([]string, 0, len(values))
+	var args []any
+	for _, v := range values {
+		sql, valueArgs, err := build(v)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, sql)
+		args = append(args, valueArgs...)
+	}
+	if len(parts) == 1 {
+		return parts[0], args, nil
+	}
+	return "(" + strings.Join(parts, ") OR (") + ")", args, nil
+}
+
+var relativeDate = regexp.MustCompile(`^(now|today)(?:([+-])(\d+)([hdwMy]))?$`)
+
+// parseDate resolves a date value. day reports that the value denotes a whole day,
+// so that equality matches the entire day.
+func parseDate(value string, now time.Time) (time.Time, bool, error) {
+	now = now.UTC()
+	if m := relativeDate.FindStringSubmatch(value); m != nil {
+		at := now
+		day := m[1] == "today"
+		if day {
+			at = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		if m[2] == "" {
+			return at, day, nil
+		}
+		n, err := strconv.Atoi(m[3])
+		if err != nil || n > 100000 {
+			return time.Time{}, false, fmt.Errorf("invalid offset")
+		}
+		if m[2] == "-" {
+			n = -n
+		}
+		switch m[4] {
+		case "h":
+			at = at.Add(time.Duration(n) * time.Hour)
+			day = false
+		case "d":
+			at = at.AddDate(0, 0, n)
+		case "w":
+			at = at.AddDate(0, 0, 7*n)
+		case "M":
+			at = at.AddDate(0, n, 0)
+		case "y":
+			at = at.AddDate(n, 0, 0)
+		}
+		return at, day, nil
+	}
+	if at, err := time.Parse("2006-01-02", value); err == nil {
+		return at.UTC(), true, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return at.UTC(), false, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
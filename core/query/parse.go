@@ -0,0 +1,327 @@
+// Package query implements the filter language of list endpoints and saved searches.
+//
+// A query is a list of conditions combined with AND, OR, NOT and parentheses;
+// adjacent conditions are joined with AND:
+//
+//	severity:critical assignee_group:mine created>=now-7d tag:ransomware
+//	(status:open OR status:in_progress) AND NOT tag:test
+//	status:(open,contained) title~"vpn gateway"
+//
+// Operators are ":" and "=" (equals; a list in parentheses matches any value),
+// "!=", "~" (contains), ">", ">=", "<" and "<=". Date values are YYYY-MM-DD,
+// RFC 3339 times, "now" or "today" with an optional offset such as now-7d or
+// today+1w (units h, d, w, M, y).
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Operators of a condition.
+const (
+	OpEq       = "="
+	OpNe       = "!="
+	OpContains = "~"
+	OpGt       = ">"
+	OpGe       = ">="
+	OpLt       = "<"
+	OpLe       = "<="
+)
+
+// MaxLength limits the size of a query.
+const MaxLength = 2000
+
+// Node is an element of a parsed query.
+type Node interface {
+	node()
+}
+
+// And matches when both sides match.
+type And struct {
+	Left, Right Node
+}
+
+// Or matches when either side matches.
+type Or struct {
+	Left, Right Node
+}
+
+// Not negates a node.
+type Not struct {
+	Node Node
+}
+
+// Term is a single condition; several values are only allowed with OpEq and OpNe.
+type Term struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+func (And) node()  {}
+func (Or) node()   {}
+func (Not) node()  {}
+func (Term) node() {}
+
+// Error describes an invalid query. Code is an i18n key; Field and Pos point at the
+// offending condition when known.
+type Error struct {
+	Code  string
+	Field string
+	Pos   int
+}
+
+func (e *Error) Error() string {
+	return e.Code
+}
+
+const (
+	errSyntax   = "query.error.syntax"
+	errField    = "query.error.field"
+	errOperator = "query.error.operator"
+	errValue    = "query.error.value"
+	errTooLong  = "query.error.tooLong"
+)
+
+// Parse parses a query. An empty query returns a nil node.
+func Parse(input string) (Node, error) {
+	if len(input) > MaxLength {
+		return nil, &Error{Code: errTooLong}
+	}
+	p := &parser{src: []rune(input)}
+	p.skipSpace()
+	if p.eof() {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.syntaxError()
+	}
+	return node, nil
+}
+
+type parser struct {
+	src   []rune
+	pos   int
+	depth int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) syntaxError() error {
+	return &Error{Code: errSyntax, Pos: p.pos}
+}
+
+// keyword consumes AND, OR or NOT (any case) when it is followed by a separator.
+func (p *parser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.src) || !strings.EqualFold(string(p.src[p.pos:end]), word) {
+		return false
+	}
+	if end < len(p.src) && !unicode.IsSpace(p.src[end]) && p.src[end] != '(' {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			return left, nil
+		}
+		start := p.pos
+		if p.keyword("OR") {
+			p.pos = start
+			return left, nil
+		}
+		p.keyword("AND")
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.keyword("NOT") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: inner}, nil
+	}
+	p.skipSpace()
+	if p.peek() == '(' {
+		p.depth++
+		if p.depth > 32 {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		p.depth--
+		return inner, nil
+	}
+	return p.parseTerm()
+}
+
+func isFieldRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	start := p.pos
+	for !p.eof() && isFieldRune(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.syntaxError()
+	}
+	field := strings.ToLower(string(p.src[start:p.pos]))
+	op, ok := p.parseOperator()
+	if !ok {
+		return nil, &Error{Code: errOperator, Field: field, Pos: p.pos}
+	}
+	var values []string
+	if p.peek() == '(' {
+		if op != OpEq && op != OpNe {
+			return nil, &Error{Code: errOperator, Field: field, Pos: p.pos}
+		}
+		p.pos++
+		for {
+			p.skipSpace()
+			value, err := p.parseValue(field)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+				continue
+			}
+			if p.peek() == ')' {
+				p.pos++
+				break
+			}
+			return nil, p.syntaxError()
+		}
+	} else {
+		value, err := p.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		values = []string{value}
+	}
+	return Term{Field: field, Op: op, Values: values}, nil
+}
+
+func (p *parser) parseOperator() (string, bool) {
+	two := ""
+	if p.pos+1 < len(p.src) {
+		two = string(p.src[p.pos : p.pos+2])
+	}
+	switch two {
+	case "!=", ">=", "<=":
+		p.pos += 2
+		return two, true
+	}
+	switch p.peek() {
+	case ':', '=':
+		p.pos++
+		return OpEq, true
+	case '~':
+		p.pos++
+		return OpContains, true
+	case '>':
+		p.pos++
+		return OpGt, true
+	case '<':
+		p.pos++
+		return OpLt, true
+	}
+	return "", false
+}
+
+func (p *parser) parseValue(field string) (string, error) {
+	if p.peek() == '"' {
+		p.pos++
+		var b strings.Builder
+		for !p.eof() {
+			r := p.src[p.pos]
+			p.pos++
+			switch {
+			case r == '\\' && !p.eof():
+				b.WriteRune(p.src[p.pos])
+				p.pos++
+			case r == '"':
+				return b.String(), nil
+			default:
+				b.WriteRune(r)
+			}
+		}
+		return "", p.syntaxError()
+	}
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == ',' || r == '"' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", &Error{Code: errValue, Field: field, Pos: p.pos}
+	}
+	return string(p.src[start:p.pos]), nil
+}
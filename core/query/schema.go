@@ -0,0 +1,144 @@
+package query
+
+import (
+	"sort"
+
+	"berkut-scc/core/store"
+)
+
+type kind int
+
+const (
+	// kindString is compared case-insensitively; ~ matches a substring.
+	kindString kind = iota
+	// kindText is free text: both : and ~ match a substring.
+	kindText
+	kindNumber
+	kindDate
+	// kindBool matches when the condition in column holds.
+	kindBool
+	// kindTags is a JSON array of tags stored upper case.
+	kindTags
+	// kindList is a JSON array of values stored as entered.
+	kindList
+	// kindUser is a user id column; values are ids, logins or "me".
+	kindUser
+	// kindGroup is a user id column matched through group membership; values are
+	// group ids, names or "mine".
+	kindGroup
+)
+
+// field describes a query field. When wrap is set the condition on column is
+// placed into it, e.g. to match rows of a link table.
+type field struct {
+	column string
+	kind   kind
+	wrap   string
+}
+
+const (
+	taskAssignees = "id IN (SELECT task_id FROM task_assignments WHERE %s)"
+	taskTags      = "id IN (SELECT l.task_id FROM task_tag_links l JOIN task_tags g ON g.id=l.tag_id WHERE %s)"
+	incidentTags  = "id IN (SELECT incident_id FROM incident_tags WHERE %s)"
+)
+
+var schemas = map[string]map[string]field{
+	store.SearchEntityIncident: {
+		"reg_no":         {column: "reg_no", kind: kindString},
+		"title":          {column: "title", kind: kindText},
+		"description":    {column: "description", kind: kindText},
+		"severity":       {column: "severity", kind: kindString},
+		"status":         {column: "status", kind: kindString},
+		"source":         {column: "source", kind: kindString},
+		"owner":          {column: "owner_user_id", kind: kindUser},
+		"assignee":       {column: "assignee_user_id", kind: kindUser},
+		"created_by":     {column: "created_by", kind: kindUser},
+		"participant":    {column: "user_id", kind: kindUser, wrap: "id IN (SELECT incident_id FROM incident_participants WHERE %s)"},
+		"owner_group":    {column: "owner_user_id", kind: kindGroup},
+		"assignee_group": {column: "assignee_user_id", kind: kindGroup},
+		"tag":            {column: "tag", kind: kindString, wrap: incidentTags},
+		"level":          {column: "classification_level", kind: kindNumber},
+		"created":        {column: "created_at", kind: kindDate},
+		"updated":        {column: "updated_at", kind: kindDate},
+		"closed":         {column: "closed_at", kind: kindDate},
+	},
+	store.SearchEntityFinding: {
+		"title":       {column: "title", kind: kindText},
+		"description": {column: "description_md", kind: kindText},
+		"status":      {column: "status", kind: kindString},
+		"severity":    {column: "severity", kind: kindString},
+		"type":        {column: "finding_type", kind: kindString},
+		"owner":       {column: "owner", kind: kindString},
+		"tag":         {column: "tags_json", kind: kindTags},
+		"due":         {column: "due_at", kind: kindDate},
+		"overdue":     {column: "overdue_at IS NOT NULL", kind: kindBool},
+		"created_by":  {column: "created_by", kind: kindUser},
+		"created":     {column: "created_at", kind: kindDate},
+		"updated":     {column: "updated_at", kind: kindDate},
+		"resolved":    {column: "resolved_at", kind: kindDate},
+	},
+	store.SearchEntityAsset: {
+		"name":          {column: "name", kind: kindString},
+		"description":   {column: "description", kind: kindText},
+		"type":          {column: "type", kind: kindString},
+		"criticality":   {column: "criticality", kind: kindString},
+		"env":           {column: "env", kind: kindString},
+		"status":        {column: "status", kind: kindString},
+		"owner":         {column: "owner", kind: kindString},
+		"administrator": {column: "administrator", kind: kindString},
+		"ip":            {column: "ip_addresses_json", kind: kindList},
+		"tag":           {column: "tags_json", kind: kindTags},
+		"commissioned":  {column: "commissioned_at", kind: kindDate},
+		"created_by":    {column: "created_by", kind: kindUser},
+		"created":       {column: "created_at", kind: kindDate},
+		"updated":       {column: "updated_at", kind: kindDate},
+	},
+	store.SearchEntityTask: {
+		"title":          {column: "title", kind: kindText},
+		"description":    {column: "description", kind: kindText},
+		"result":         {column: "result", kind: kindText},
+		"status":         {column: "status", kind: kindString},
+		"priority":       {column: "priority", kind: kindString},
+		"board":          {column: "board_id", kind: kindNumber},
+		"column":         {column: "column_id", kind: kindNumber},
+		"size":           {column: "size_estimate", kind: kindNumber},
+		"assignee":       {column: "user_id", kind: kindUser, wrap: taskAssignees},
+		"assignee_group": {column: "user_id", kind: kindGroup, wrap: taskAssignees},
+		"created_by":     {column: "created_by", kind: kindUser},
+		"tag":            {column: "g.name", kind: kindString, wrap: taskTags},
+		"due":            {column: "due_date", kind: kindDate},
+		"created":        {column: "created_at", kind: kindDate},
+		"updated":        {column: "updated_at", kind: kindDate},
+		"closed":         {column: "closed_at", kind: kindDate},
+		"archived":       {column: "is_archived=1", kind: kindBool},
+	},
+	store.SearchEntityDoc: {
+		"title":      {column: "title", kind: kindText},
+		"status":     {column: "status", kind: kindString},
+		"type":       {column: "doc_type", kind: kindString},
+		"reg_number": {column: "reg_number", kind: kindString},
+		"level":      {column: "classification_level", kind: kindNumber},
+		"tag":        {column: "classification_tags", kind: kindTags},
+		"folder":     {column: "folder_id", kind: kindNumber},
+		"created_by": {column: "created_by", kind: kindUser},
+		"created":    {column: "created_at", kind: kindDate},
+		"updated":    {column: "updated_at", kind: kindDate},
+	},
+}
+
+// ValidEntityType reports whether the entity supports queries.
+func ValidEntityType(entityType string) bool {
+	_, ok := schemas[entityType]
+	return ok
+}
+
+// Fields returns the built-in query fields of the entity in alphabetical order.
+func Fields(entityType string) []string {
+	schema := schemas[entityType]
+	out := make([]string, 0, len(schema))
+	for name := range schema {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/store"
+)
+
+// ErrSearchNotFound is returned for saved searches that do not exist, are not visible
+// to the user or belong to another entity.
+var ErrSearchNotFound = errors.New("savedSearches.error.notFound")
+
+// Service compiles queries and saved searches of a user.
+type Service struct {
+	searches store.SavedSearchesStore
+	fields   *customfields.Service
+}
+
+func NewService(searches store.SavedSearchesStore, fields *customfields.Service) *Service {
+	return &Service{searches: searches, fields: fields}
+}
+
+// Compile parses and compiles a query for the user; roles limit the custom fields
+// that may be referenced.
+func (s *Service) Compile(ctx context.Context, entityType, input string, userID int64, roles []string) (*store.QueryClause, error) {
+	node, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	env := Env{UserID: userID}
+	if s != nil && s.fields != nil && customfields.ValidEntityType(entityType) {
+		env.CustomField = func(key, value string) (string, []any, error) {
+			conds, err := s.fields.ConditionsFor(ctx, entityType, roles, map[string]string{key: value})
+			if err != nil {
+				return "", nil, err
+			}
+			if len(conds) == 0 {
+				return "", nil, &Error{Code: errValue, Field: CustomFieldPrefix + key}
+			}
+			sql, args := store.CustomFieldClause(entityType, "id", conds[0])
+			return sql, args, nil
+		}
+	}
+	return Compile(entityType, node, env)
+}
+
+// FromRequest compiles the "query" parameter and the saved search referenced by
+// "saved_search" of a list request; both are combined with AND.
+func (s *Service) FromRequest(ctx context.Context, entityType string, params url.Values, userID int64, roles []string) (*store.QueryClause, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var parts []*store.QueryClause
+	if raw := strings.TrimSpace(params.Get("saved_search")); raw != "" {
+		clause, err := s.SavedSearch(ctx, entityType, raw, userID, roles)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, clause)
+	}
+	if raw := strings.TrimSpace(params.Get("query")); raw != "" {
+		clause, err := s.Compile(ctx, entityType, raw, userID, roles)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, clause)
+	}
+	return combine(parts), nil
+}
+
+// SavedSearch compiles a saved search visible to the user.
+func (s *Service) SavedSearch(ctx context.Context, entityType, rawID string, userID int64, roles []string) (*store.QueryClause, error) {
+	if s == nil || s.searches == nil {
+		return nil, ErrSearchNotFound
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, ErrSearchNotFound
+	}
+	search, err := s.searches.GetVisibleSavedSearch(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if search == nil || search.EntityType != entityType {
+		return nil, ErrSearchNotFound
+	}
+	return s.Compile(ctx, entityType, search.Query, userID, roles)
+}
+
+func combine(parts []*store.QueryClause) *store.QueryClause {
+	var sql []string
+	var args []any
+	for _, part := range parts {
+		if part == nil {
+			continue
+		}
+		sql = append(sql, part.SQL)
+		args = append(args, part.Args...)
+	}
+	if len(sql) == 0 {
+		return nil
+	}
+	return &store.QueryClause{SQL: strings.Join(sql, " AND "), Args: args}
+}
@@ -1,6 +1,9 @@
 package charts
 
 import (
+	"bytes"
+	"encoding/json"
+	"image/png"
 	"strings"
 	"testing"
 
@@ -46,3 +49,309 @@ func TestBuildChartUsesSnapshotItems(t *testing.T) {
 		t.Fatalf("expected sum 2, got %.0f", sum)
 	}
 }
+
+func TestBuildChartIncidentsMTTRBySeverity(t *testing.T) {
+	ch := store.ReportChart{ChartType: "incidents_mttr_severity_bar"}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "incident", Entity: map[string]any{"severity": "high", "detected_at": "2024-01-01T00:00:00Z", "resolved_at": "2024-01-01T02:00:00Z"}},
+		{EntityType: "incident", Entity: map[string]any{"severity": "high", "detected_at": "2024-01-02T00:00:00Z", "resolved_at": "2024-01-02T04:00:00Z"}},
+		{EntityType: "incident", Entity: map[string]any{"severity": "low", "detected_at": "2024-01-02T00:00:00Z"}},
+	}
+	data, err := BuildChart(ch, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build chart: %v", err)
+	}
+	if len(data.Labels) != 4 || data.Labels[1] != "High" || data.Values[1] != 3 || data.Values[3] != 0 {
+		t.Fatalf("unexpected mttr chart: %+v", data)
+	}
+}
+
+func TestBuildChartSoftwareEOLBuckets(t *testing.T) {
+	ch := store.ReportChart{ChartType: "software_eol_bar"}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "software_eol", Entity: map[string]any{"days_left": -10}},
+		{EntityType: "software_eol", Entity: map[string]any{"days_left": float64(12)}},
+		{EntityType: "software_eol", Entity: map[string]any{"days_left": 45}},
+		{EntityType: "software_eol", Entity: map[string]any{"days_left": 120}},
+		{EntityType: "monitor", Entity: map[string]any{"days_left": 1}},
+	}
+	data, err := BuildChart(ch, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build chart: %v", err)
+	}
+	if len(data.Values) != 4 || data.Labels[0] != "Expired" {
+		t.Fatalf("unexpected eol chart: %+v", data)
+	}
+	for i, v := range data.Values {
+		if v != 1 {
+			t.Fatalf("bucket %d: expected 1, got %.0f", i, v)
+		}
+	}
+}
+
+func TestBuildChartRisksLevel(t *testing.T) {
+	ch := store.ReportChart{ChartType: "risks_level_bar"}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "risk", Entity: map[string]any{"residual_level": "high"}},
+		{EntityType: "risk", Entity: map[string]any{"residual_level": "high"}},
+		{EntityType: "risk", Entity: map[string]any{"residual_level": "low"}},
+		{EntityType: "risk", Entity: map[string]any{"residual_level": "extreme"}},
+		{EntityType: "incident", Entity: map[string]any{"residual_level": "low"}},
+	}
+	data, err := BuildChart(ch, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build chart: %v", err)
+	}
+	if len(data.Values) != 5 || data.Labels[0] != "Low" || data.Labels[4] != "extreme" {
+		t.Fatalf("unexpected risk chart: %+v", data)
+	}
+	if data.Values[0] != 1 || data.Values[2] != 2 || data.Values[4] != 1 {
+		t.Fatalf("unexpected risk counts: %+v", data.Values)
+	}
+}
+
+func TestBuildChartFindingsAgeingAndBurndown(t *testing.T) {
+	snap := &store.ReportSnapshot{Snapshot: map[string]any{"generated_at": "2026-03-31T12:00:00Z"}}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "finding", Entity: map[string]any{"status": "open", "created_at": "2026-03-29T10:00:00Z"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "in_progress", "created_at": "2026-03-10T10:00:00Z"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "open", "created_at": "2025-11-01T10:00:00Z"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "resolved", "created_at": "2026-03-20T10:00:00Z", "resolved_at": "2026-03-30T10:00:00Z"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "false_positive", "created_at": "2026-01-01T10:00:00Z", "updated_at": "2026-03-25T08:00:00Z"}},
+	}
+	ageing, err := BuildChart(store.ReportChart{ChartType: "findings_ageing_bar"}, snap, items, "en")
+	if err != nil {
+		t.Fatalf("build ageing: %v", err)
+	}
+	if len(ageing.Values) != 4 || ageing.Values[0] != 1 || ageing.Values[1] != 1 || ageing.Values[2] != 0 || ageing.Values[3] != 1 {
+		t.Fatalf("unexpected ageing: %+v", ageing)
+	}
+	burn, err := BuildChart(store.ReportChart{ChartType: "findings_burndown_line", Config: map[string]any{"days": 7}}, snap, items, "en")
+	if err != nil {
+		t.Fatalf("build burndown: %v", err)
+	}
+	if len(burn.Values) != 7 || burn.Labels[0] != "2026-03-25" || burn.Labels[6] != "2026-03-31" {
+		t.Fatalf("unexpected burndown labels: %+v", burn.Labels)
+	}
+	// The false positive closes on 03-25, one finding opens on 03-29 and one is resolved on 03-30.
+	want := []float64{3, 3, 3, 3, 4, 3, 3}
+	for i, v := range want {
+		if burn.Values[i] != v {
+			t.Fatalf("unexpected burndown values: %+v", burn.Values)
+		}
+	}
+}
+
+func TestBuildChartFindingsSeverityAndSoftwareInventory(t *testing.T) {
+	items := []store.ReportSnapshotItem{
+		{EntityType: "finding", Entity: map[string]any{"status": "open", "severity": "critical"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "in_progress", "severity": "low"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "accepted_risk", "severity": "critical"}},
+		{EntityType: "finding", Entity: map[string]any{"status": "resolved", "severity": "high", "resolved_at": "2026-03-30T10:00:00Z"}},
+		{EntityType: "software", Entity: map[string]any{"name": "nginx", "vendor": "F5", "installations": 4}},
+		{EntityType: "software", Entity: map[string]any{"name": "OpenSSL", "installations": float64(9)}},
+		{EntityType: "software", Entity: map[string]any{"name": "curl", "installations": 1}},
+		{EntityType: "software", Entity: map[string]any{"name": "bash", "installations": 1}},
+	}
+	sev, err := BuildChart(store.ReportChart{ChartType: "findings_severity_bar"}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build severity: %v", err)
+	}
+	if len(sev.Values) != 4 || sev.Labels[0] != "Critical" || sev.Values[0] != 1 || sev.Values[2] != 0 || sev.Values[3] != 1 {
+		t.Fatalf("unexpected severity chart: %+v", sev)
+	}
+	inv, err := BuildChart(store.ReportChart{ChartType: "software_inventory_bar", Config: map[string]any{"top_n": 3}}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build inventory: %v", err)
+	}
+	if len(inv.Values) != 3 || inv.Labels[0] != "OpenSSL" || inv.Labels[1] != "F5 nginx" || inv.Labels[2] != "bash" || inv.Values[0] != 9 {
+		t.Fatalf("unexpected inventory chart: %+v", inv)
+	}
+}
+
+func TestBuildChartEffortHours(t *testing.T) {
+	ch := store.ReportChart{ChartType: "effort_bar", Config: map[string]any{"top_n": 3}}
+	items := []store.ReportSnapshotItem{
+		{EntityType: "effort", Entity: map[string]any{"label": "Alice", "minutes": 90}},
+		{EntityType: "effort", Entity: map[string]any{"label": "Bob", "minutes": float64(300)}},
+		{EntityType: "effort", Entity: map[string]any{"label": "Carol", "minutes": 30}},
+		{EntityType: "effort", Entity: map[string]any{"label": "Dave", "minutes": 15}},
+		{EntityType: "task", Entity: map[string]any{"label": "Task", "minutes": 1000}},
+	}
+	data, err := BuildChart(ch, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build chart: %v", err)
+	}
+	if len(data.Values) != 3 || data.Labels[0] != "Bob" || data.Values[0] != 5 || data.Values[1] != 1.5 {
+		t.Fatalf("unexpected effort chart: %+v", data)
+	}
+}
+
+func TestBuildChartTaskFlow(t *testing.T) {
+	items := []store.ReportSnapshotItem{
+		{EntityType: "task_flow_day", Entity: map[string]any{"date": "2026-03-02", "column": "Todo", "position": 1, "count": 3}},
+		{EntityType: "task_flow_day", Entity: map[string]any{"date": "2026-03-02", "column": "Done", "position": 2, "count": 1}},
+		{EntityType: "task_flow_day", Entity: map[string]any{"date": "2026-03-01", "column": "Todo", "position": 1, "count": 4}},
+		{EntityType: "task_flow_day", Entity: map[string]any{"date": "2026-03-01", "column": "Done", "position": 2, "count": 0}},
+		{EntityType: "task_flow_done", Entity: map[string]any{"done_at": "2026-03-02T10:00:00Z", "lead_time_seconds": float64(4 * 86400), "cycle_time_seconds": 86400}},
+		{EntityType: "task_flow_done", Entity: map[string]any{"done_at": "2026-03-02T12:00:00Z", "lead_time_seconds": int64(2 * 86400)}},
+		{EntityType: "task_flow_ageing", Entity: map[string]any{"age_seconds": 3600}},
+		{EntityType: "task_flow_ageing", Entity: map[string]any{"age_seconds": int64(20 * 86400)}},
+	}
+	cfd, err := BuildChart(store.ReportChart{ChartType: "tasks_flow_cfd_area"}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build cfd: %v", err)
+	}
+	if len(cfd.Labels) != 2 || cfd.Labels[0] != "2026-03-01" || len(cfd.Series) != 2 || cfd.Series[0].Name != "Done" {
+		t.Fatalf("unexpected cfd: %+v", cfd)
+	}
+	if cfd.Values[0] != 4 || cfd.Values[1] != 4 || cfd.Series[1].Values[0] != 4 {
+		t.Fatalf("unexpected cfd totals: %+v", cfd)
+	}
+	svg, err := RenderSVG(cfd)
+	if err != nil || !strings.Contains(string(svg), "Todo") {
+		t.Fatalf("expected legend in area svg: %v", err)
+	}
+
+	lead, err := BuildChart(store.ReportChart{ChartType: "tasks_flow_lead_bar"}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build lead: %v", err)
+	}
+	if lead.Values[0] != 2 || lead.Values[3] != 4 {
+		t.Fatalf("unexpected lead percentiles: %+v", lead.Values)
+	}
+	cycle, _ := BuildChart(store.ReportChart{ChartType: "tasks_flow_cycle_bar"}, &store.ReportSnapshot{}, items, "en")
+	if cycle.Values[0] != 1 || cycle.Values[3] != 1 {
+		t.Fatalf("tasks without cycle time must be skipped: %+v", cycle.Values)
+	}
+	ageing, _ := BuildChart(store.ReportChart{ChartType: "tasks_flow_ageing_bar"}, &store.ReportSnapshot{}, items, "en")
+	if ageing.Values[0] != 1 || ageing.Values[4] != 1 {
+		t.Fatalf("unexpected ageing buckets: %+v", ageing.Values)
+	}
+}
+
+func TestBuildChartIncidentSeverityStackedAndHeatmap(t *testing.T) {
+	snap := &store.ReportSnapshot{Snapshot: map[string]any{"period_from": "2026-03-02T00:00:00Z", "period_to": "2026-03-15T00:00:00Z"}}
+	items := []store.ReportSnapshotItem{
+		// 2026-03-02 is a Monday.
+		{EntityType: "incident", Entity: map[string]any{"severity": "critical", "created_at": "2026-03-02T09:15:00Z"}},
+		{EntityType: "incident", Entity: map[string]any{"severity": "low", "created_at": "2026-03-03T09:30:00Z", "detected_at": "2026-03-02T09:05:00Z"}},
+		{EntityType: "incident", Entity: map[string]any{"severity": "high", "created_at": "2026-03-10T23:00:00Z"}},
+		{EntityType: "incident", Entity: map[string]any{"severity": "critical", "created_at": "2026-03-14T12:00:00Z"}},
+	}
+	stacked, err := BuildChart(store.ReportChart{ChartType: "incidents_severity_weekly_stacked"}, snap, items, "en")
+	if err != nil {
+		t.Fatalf("build stacked: %v", err)
+	}
+	if len(stacked.Labels) != 2 || len(stacked.Series) != 4 || stacked.Series[0].Name != "Critical" {
+		t.Fatalf("unexpected stacked chart: %+v", stacked)
+	}
+	if stacked.Series[0].Values[0] != 1 || stacked.Series[0].Values[1] != 1 || stacked.Series[1].Values[1] != 1 || stacked.Values[0] != 2 {
+		t.Fatalf("unexpected stacked values: %+v", stacked)
+	}
+	if len(stacked.Palette) == 0 || stacked.Palette[0] != palettes["severity"][0] {
+		t.Fatalf("expected severity palette: %v", stacked.Palette)
+	}
+	heat, err := BuildChart(store.ReportChart{ChartType: "incidents_weekday_hour_heatmap"}, snap, items, "en")
+	if err != nil {
+		t.Fatalf("build heatmap: %v", err)
+	}
+	if len(heat.Labels) != 24 || len(heat.Series) != 7 || heat.Series[0].Name != "Mon" {
+		t.Fatalf("unexpected heatmap: %+v", heat)
+	}
+	if heat.Series[0].Values[9] != 2 || heat.Series[1].Values[23] != 1 || heat.Series[5].Values[12] != 1 {
+		t.Fatalf("unexpected heatmap cells: %+v", heat.Series)
+	}
+	line, _ := BuildChart(store.ReportChart{ChartType: "incidents_severity_weekly_line", Config: map[string]any{"palette": "mono"}}, snap, items, "en")
+	if line.Kind != KindLine || len(line.Series) != 4 || line.Palette[0] != palettes["mono"][0] {
+		t.Fatalf("expected multi-series line with mono palette: %+v", line)
+	}
+	pie, _ := BuildChart(store.ReportChart{ChartType: "incidents_severity_pie"}, snap, items, "en")
+	if len(pie.Labels) != 4 || pie.Values[0] != 2 || pie.Values[3] != 1 {
+		t.Fatalf("unexpected pie: %+v", pie)
+	}
+}
+
+func TestBuildChartControlsDonutAndRiskMatrix(t *testing.T) {
+	matrix := store.ReportSnapshotItem{EntityType: "risk_matrix", Entity: map[string]any{
+		"likelihood": []string{"Rare", "Likely", "Certain"},
+		"impact":     []string{"Minor", "Major"},
+		"counts":     [][]int{{1, 0}, {0, 2}, {3, 0}},
+	}}
+	// Items are read back from JSON in reports, so the matrix arrives as []any.
+	raw, _ := json.Marshal(matrix)
+	var decoded store.ReportSnapshotItem
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	items := []store.ReportSnapshotItem{
+		decoded,
+		{EntityType: "control", Entity: map[string]any{"status": "implemented"}},
+		{EntityType: "control", Entity: map[string]any{"status": "implemented"}},
+		{EntityType: "control", Entity: map[string]any{"status": "not_implemented"}},
+		{EntityType: "control", Entity: map[string]any{"status": "retired"}},
+	}
+	risks, err := BuildChart(store.ReportChart{ChartType: "risks_matrix_heatmap"}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build matrix: %v", err)
+	}
+	if len(risks.Labels) != 2 || len(risks.Series) != 3 || risks.Series[0].Name != "Certain" || risks.Series[0].Values[0] != 3 || risks.Series[1].Values[1] != 2 {
+		t.Fatalf("unexpected risk matrix: %+v", risks)
+	}
+	donut, err := BuildChart(store.ReportChart{ChartType: "controls_status_donut"}, &store.ReportSnapshot{}, items, "en")
+	if err != nil {
+		t.Fatalf("build donut: %v", err)
+	}
+	if len(donut.Labels) != 5 || donut.Labels[0] != "Implemented" || donut.Values[0] != 2 || donut.Values[2] != 1 || donut.Labels[4] != "retired" {
+		t.Fatalf("unexpected donut: %+v", donut)
+	}
+}
+
+func TestRenderChartKinds(t *testing.T) {
+	series := []Series{{Name: "Critical", Values: []float64{1, 3}}, {Name: "Low", Values: []float64{2, 0}}}
+	charts := []ChartData{
+		{Title: "Bars", Kind: KindBar, Labels: []string{"A", "B"}, Values: []float64{2, 5}},
+		{Title: "Stacked", Kind: KindStackedBar, Labels: []string{"W1", "W2"}, Values: []float64{3, 3}, Series: series},
+		{Title: "Lines", Kind: KindLine, Labels: []string{"W1", "W2"}, Series: series},
+		{Title: "Donut", Kind: KindDonut, Labels: []string{"Critical", "Low"}, Values: []float64{3, 1}, Palette: palettes["severity"]},
+		{Title: "Pie", Kind: KindPie, Labels: []string{"Empty"}, Values: []float64{0}},
+		{Title: "Матрица", Kind: KindHeatmap, Labels: []string{"Minor", "Major"}, Series: series},
+	}
+	for _, data := range charts {
+		svg, err := RenderSVG(data)
+		if err != nil || !strings.Contains(string(svg), "</svg>") {
+			t.Fatalf("%s: render svg: %v", data.Title, err)
+		}
+		if len(data.Series) > 0 && !strings.Contains(string(svg), "Critical") {
+			t.Fatalf("%s: expected series names in svg", data.Title)
+		}
+		out, err := RenderPNG(data)
+		if err != nil {
+			t.Fatalf("%s: render png: %v", data.Title, err)
+		}
+		img, err := png.Decode(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("%s: decode png: %v", data.Title, err)
+		}
+		if b := img.Bounds(); b.Dx() != chartWidth*pngScale || b.Dy() != chartHeight*pngScale {
+			t.Fatalf("%s: unexpected png size %v", data.Title, b)
+		}
+	}
+	// The first donut slice is painted in the first palette colour at 12 o'clock.
+	out, _ := RenderPNG(charts[3])
+	img, _ := png.Decode(bytes.NewReader(out))
+	radius := float64(chartHeight-paddingTop-30) / 2
+	x := int((float64(paddingLeft) + radius + 2) * pngScale)
+	y := int((float64(paddingTop+10) + 4) * pngScale)
+	red, green, blue, _ := img.At(x, y).RGBA()
+	wr, wg, wb := parseHexColor(palettes["severity"][0])
+	if uint8(red>>8) != wr || uint8(green>>8) != wg || uint8(blue>>8) != wb {
+		t.Fatalf("unexpected donut colour at %d,%d: %d %d %d", x, y, red>>8, green>>8, blue>>8)
+	}
+}
+
+func TestFontTextTransliterates(t *testing.T) {
+	if got := fontText("Риск — Ёж №1"); got != "Risk - Ezh No1" {
+		t.Fatalf("unexpected transliteration: %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package charts
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"time"
@@ -14,11 +15,34 @@ type ChartData struct {
 	Kind    Kind
 	Labels  []string
 	Values  []float64
+	Series  []Series
 	XLabel  string
 	YLabel  string
+	// Palette overrides the default series colours; heat maps use it as gradient stops.
+	Palette []string
+}
+
+// Series is one named layer of a stacked chart; Values align with Labels.
+type Series struct {
+	Name   string
+	Values []float64
 }
 
 func BuildChart(chart store.ReportChart, snapshot *store.ReportSnapshot, items []store.ReportSnapshotItem, lang string) (ChartData, error) {
+	data, err := buildChartData(chart, snapshot, items, lang)
+	if err != nil {
+		return ChartData{}, err
+	}
+	def, _ := DefinitionFor(chart.ChartType)
+	palette := def.Palette
+	if name, ok := NormalizeConfig(chart.ChartType, chart.Config)["palette"].(string); ok {
+		palette = name
+	}
+	data.Palette = paletteFor(palette)
+	return data, nil
+}
+
+func buildChartData(chart store.ReportChart, snapshot *store.ReportSnapshot, items []store.ReportSnapshotItem, lang string) (ChartData, error) {
 	def, ok := DefinitionFor(chart.ChartType)
 	if !ok {
 		return ChartData{}, fmt.Errorf("unknown chart type")
@@ -44,6 +68,24 @@ func BuildChart(chart store.ReportChart, snapshot *store.ReportSnapshot, items [
 		dates := datesFromItems(items, "incident", "created_at")
 		labels, values := weeklyBuckets(dates, from, to, cfg["weeks"].(int))
 		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.week"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "incidents_severity_weekly_stacked", "incidents_severity_weekly_line":
+		labels, series := incidentsSeverityWeekly(items, from, to, cfg["weeks"].(int), lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: stackedTotals(len(labels), series), Series: series, YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "incidents_severity_pie":
+		labels, values := incidentsSeverityOrdered(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values}, nil
+	case "incidents_weekday_hour_heatmap":
+		labels, series := incidentsWeekdayHour(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Series: series, XLabel: Localized(lang, "chart.axis.hour"), YLabel: Localized(lang, "chart.axis.weekday")}, nil
+	case "incidents_response_bar":
+		labels, values := incidentsResponseMeans(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, YLabel: Localized(lang, "chart.axis.hours")}, nil
+	case "incidents_mttr_severity_bar":
+		labels, values := incidentsMTTRBySeverity(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.severity"), YLabel: Localized(lang, "chart.axis.hours")}, nil
+	case "incidents_mttr_weekly_line":
+		labels, values := incidentsMTTRWeekly(items, from, to, cfg["weeks"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.week"), YLabel: Localized(lang, "chart.axis.hours")}, nil
 	case "tasks_status_bar":
 		done, overdue, inProgress := taskStatusCounts(items, now)
 		labels := []string{
@@ -71,6 +113,9 @@ func BuildChart(chart store.ReportChart, snapshot *store.ReportSnapshot, items [
 		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.week"), YLabel: Localized(lang, "chart.axis.count")}, nil
 	case "controls_status_bar":
 		return barFromCounts(title, def.Kind, controlsStatusCounts(items, lang), Localized(lang, "chart.axis.count"))
+	case "controls_status_donut":
+		labels, values := controlsStatusShare(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values}, nil
 	case "controls_domains_bar":
 		labels, values := controlsDomainCounts(items, cfg["top_n"].(int))
 		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.domain"), YLabel: Localized(lang, "chart.axis.count")}, nil
@@ -84,6 +129,62 @@ func BuildChart(chart store.ReportChart, snapshot *store.ReportSnapshot, items [
 	case "monitoring_tls_bar":
 		labels, values := monitoringTLSCounts(items, lang)
 		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "software_eol_bar":
+		labels, values := softwareEOLCounts(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "risks_level_bar":
+		labels, values := riskLevelCounts(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.risk_level"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "risks_matrix_heatmap":
+		labels, series := riskMatrixGrid(items)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Series: series, XLabel: Localized(lang, "chart.axis.impact"), YLabel: Localized(lang, "chart.axis.likelihood")}, nil
+	case "findings_ageing_bar":
+		labels, values := findingsAgeing(items, now, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.age"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "findings_severity_bar":
+		labels, values := findingsSeverityCounts(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.severity"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "findings_status_bar":
+		return barFromCounts(title, def.Kind, entityCounts(items, "finding", "status"), Localized(lang, "chart.axis.count"))
+	case "findings_burndown_line":
+		labels, values := findingsBurndown(items, now, cfg["days"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.day"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "assets_criticality_bar":
+		labels, values := assetsCriticalityCounts(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.criticality"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "assets_env_bar":
+		return barFromCounts(title, def.Kind, entityCounts(items, "asset", "env"), Localized(lang, "chart.axis.count"))
+	case "assets_type_bar":
+		return barFromCounts(title, def.Kind, entityCounts(items, "asset", "type"), Localized(lang, "chart.axis.count"))
+	case "software_inventory_bar":
+		labels, values := softwareInstallations(items, cfg["top_n"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, YLabel: Localized(lang, "chart.axis.installations")}, nil
+	case "framework_coverage_bar":
+		labels, values := frameworkCoveragePct(items)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.framework"), YLabel: Localized(lang, "chart.axis.coverage")}, nil
+	case "effort_bar":
+		labels, values := effortHours(items, cfg["top_n"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, YLabel: Localized(lang, "chart.axis.hours")}, nil
+	case "tasks_flow_cfd_area":
+		labels, series := flowCumulative(items)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: stackedTotals(len(labels), series), Series: series, YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "tasks_flow_cycle_bar":
+		labels, values := flowPercentiles(items, "cycle_time_seconds")
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.percentile"), YLabel: Localized(lang, "chart.axis.days")}, nil
+	case "tasks_flow_lead_bar":
+		labels, values := flowPercentiles(items, "lead_time_seconds")
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.percentile"), YLabel: Localized(lang, "chart.axis.days")}, nil
+	case "tasks_flow_throughput_line":
+		dates := datesFromItems(items, "task_flow_done", "done_at")
+		labels, values := weeklyBuckets(dates, from, to, cfg["weeks"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.week"), YLabel: Localized(lang, "chart.axis.count")}, nil
+	case "tasks_flow_wip_line":
+		dates := datesFromItems(items, "task_flow_wip", "date")
+		labels, values := weeklyBuckets(dates, from, to, cfg["weeks"].(int))
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.week"), YLabel: Localized(lang, "chart.axis.column_days")}, nil
+	case "tasks_flow_ageing_bar":
+		labels, values := flowAgeing(items, lang)
+		return ChartData{Title: title, Kind: def.Kind, Labels: labels, Values: values, XLabel: Localized(lang, "chart.axis.age"), YLabel: Localized(lang, "chart.axis.count")}, nil
 	}
 	return ChartData{}, fmt.Errorf("unsupported chart type")
 }
@@ -185,6 +286,158 @@ func controlsStatusCounts(items []store.ReportSnapshotItem, lang string) map[str
 	return counts
 }
 
+// severityOrder is the series and slice order of severity charts; it matches
+// the "severity" palette.
+var severityOrder = []string{"critical", "high", "medium", "low"}
+
+func severityIndex(item store.ReportSnapshotItem) int {
+	sev := strings.ToLower(strings.TrimSpace(getString(item.Entity, "severity")))
+	for i, key := range severityOrder {
+		if key == sev {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+func severityLabels(lang string) []string {
+	labels := make([]string, 0, len(severityOrder)+1)
+	for _, key := range severityOrder {
+		labels = append(labels, Localized(lang, "chart.severity."+key))
+	}
+	return append(labels, Localized(lang, "chart.label.unknown"))
+}
+
+// incidentsSeverityOrdered counts incidents by severity, critical first; the
+// unknown slice is dropped when empty.
+func incidentsSeverityOrdered(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	labels := severityLabels(lang)
+	values := make([]float64, len(labels))
+	for _, item := range items {
+		if item.EntityType == "incident" {
+			values[severityIndex(item)]++
+		}
+	}
+	if values[len(values)-1] == 0 {
+		return labels[:len(labels)-1], values[:len(values)-1]
+	}
+	return labels, values
+}
+
+// incidentsSeverityWeekly buckets created incidents per week with one series per
+// severity; the unknown series is kept only when it has incidents.
+func incidentsSeverityWeekly(items []store.ReportSnapshotItem, from, to *time.Time, weeks int, lang string) ([]string, []Series) {
+	names := severityLabels(lang)
+	dates := make([][]time.Time, len(names))
+	for _, item := range items {
+		if item.EntityType != "incident" {
+			continue
+		}
+		if dt, ok := getTime(item.Entity, "created_at"); ok {
+			idx := severityIndex(item)
+			dates[idx] = append(dates[idx], dt)
+		}
+	}
+	var labels []string
+	series := make([]Series, 0, len(names))
+	for i, name := range names {
+		l, values := weeklyBuckets(dates[i], from, to, weeks)
+		labels = l
+		if i == len(names)-1 && maxValue(values) == 0 {
+			continue
+		}
+		series = append(series, Series{Name: name, Values: values})
+	}
+	return labels, series
+}
+
+// incidentsWeekdayHour counts incidents by weekday (rows, Monday first) and UTC
+// hour of detection, falling back to creation time.
+func incidentsWeekdayHour(items []store.ReportSnapshotItem, lang string) ([]string, []Series) {
+	labels := make([]string, 24)
+	for h := range labels {
+		labels[h] = fmt.Sprintf("%02d", h)
+	}
+	series := make([]Series, 7)
+	for d := range series {
+		series[d] = Series{Name: Localized(lang, fmt.Sprintf("chart.weekday.%d", d+1)), Values: make([]float64, 24)}
+	}
+	for _, item := range items {
+		if item.EntityType != "incident" {
+			continue
+		}
+		dt, ok := getTime(item.Entity, "detected_at")
+		if !ok {
+			dt, ok = getTime(item.Entity, "created_at")
+		}
+		if !ok {
+			continue
+		}
+		day := (int(dt.Weekday()) + 6) % 7
+		series[day].Values[dt.Hour()]++
+	}
+	return labels, series
+}
+
+// controlsStatusShare counts controls by implementation status in the order of
+// the "traffic" palette; other statuses follow alphabetically.
+func controlsStatusShare(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	order := []string{"implemented", "partial", "not_implemented", "not_applicable"}
+	counts := entityCounts(items, "control", "status")
+	var extra []string
+	for key := range counts {
+		known := false
+		for _, o := range order {
+			if strings.EqualFold(o, key) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	labels := make([]string, 0, len(order)+len(extra))
+	values := make([]float64, 0, len(order)+len(extra))
+	for _, key := range order {
+		labels = append(labels, Localized(lang, "chart.control."+key))
+		values = append(values, float64(counts[key]))
+	}
+	for _, key := range extra {
+		labels = append(labels, key)
+		values = append(values, float64(counts[key]))
+	}
+	return labels, values
+}
+
+// riskMatrixGrid turns the snapshotted risk matrix into heat map rows, highest
+// likelihood first, with impact levels as columns.
+func riskMatrixGrid(items []store.ReportSnapshotItem) ([]string, []Series) {
+	for _, item := range items {
+		if item.EntityType != "risk_matrix" {
+			continue
+		}
+		likelihood := stringList(item.Entity["likelihood"])
+		impact := stringList(item.Entity["impact"])
+		rows := anyList(item.Entity["counts"])
+		series := make([]Series, 0, len(likelihood))
+		for l := len(likelihood) - 1; l >= 0; l-- {
+			values := make([]float64, len(impact))
+			if l < len(rows) {
+				for i, cell := range anyList(rows[l]) {
+					if i < len(values) {
+						values[i] = floatValue(cell)
+					}
+				}
+			}
+			series = append(series, Series{Name: likelihood[l], Values: values})
+		}
+		return impact, series
+	}
+	return nil, nil
+}
+
 func controlsDomainCounts(items []store.ReportSnapshotItem, topN int) ([]string, []float64) {
 	counts := map[string]int{}
 	for _, item := range items {
@@ -311,6 +564,268 @@ func monitoringTLSCounts(items []store.ReportSnapshotItem, lang string) ([]strin
 	return labels, values
 }
 
+func softwareEOLCounts(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	expired, lt30, lt90, later := 0, 0, 0, 0
+	for _, item := range items {
+		if item.EntityType != "software_eol" {
+			continue
+		}
+		val := getInt(item.Entity, "days_left")
+		if val == nil {
+			continue
+		}
+		switch {
+		case *val < 0:
+			expired++
+		case *val < 30:
+			lt30++
+		case *val < 90:
+			lt90++
+		default:
+			later++
+		}
+	}
+	labels := []string{
+		Localized(lang, "chart.label.eol_expired"),
+		Localized(lang, "chart.label.lt30"),
+		Localized(lang, "chart.label.lt90"),
+		Localized(lang, "chart.label.eol_later"),
+	}
+	values := []float64{float64(expired), float64(lt30), float64(lt90), float64(later)}
+	return labels, values
+}
+
+// riskLevelCounts groups risks by residual level. Built-in levels keep their
+// severity order; custom matrix levels follow alphabetically.
+func riskLevelCounts(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	order := []string{"low", "medium", "high", "critical"}
+	counts := map[string]int{}
+	for _, item := range items {
+		if item.EntityType != "risk" {
+			continue
+		}
+		level := strings.ToLower(strings.TrimSpace(getString(item.Entity, "residual_level")))
+		if level == "" {
+			continue
+		}
+		counts[level]++
+	}
+	var extra []string
+	for key := range counts {
+		known := false
+		for _, o := range order {
+			if o == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	keys := append(append([]string{}, order...), extra...)
+	labels := make([]string, 0, len(keys))
+	values := make([]float64, 0, len(keys))
+	for _, key := range keys {
+		label := Localized(lang, "chart.risk_level."+key)
+		if label == "chart.risk_level."+key {
+			label = key
+		}
+		labels = append(labels, label)
+		values = append(values, float64(counts[key]))
+	}
+	return labels, values
+}
+
+// findingsAgeing buckets unresolved findings by days since creation.
+func findingsAgeing(items []store.ReportSnapshotItem, now time.Time, lang string) ([]string, []float64) {
+	values := make([]float64, 4)
+	for _, item := range items {
+		if item.EntityType != "finding" {
+			continue
+		}
+		created, ok := getTime(item.Entity, "created_at")
+		if !ok {
+			continue
+		}
+		if _, resolved := findingResolvedAt(item); resolved {
+			continue
+		}
+		age := now.Sub(created).Hours() / 24
+		switch {
+		case age < 7:
+			values[0]++
+		case age < 30:
+			values[1]++
+		case age < 90:
+			values[2]++
+		default:
+			values[3]++
+		}
+	}
+	labels := []string{
+		Localized(lang, "chart.label.age_lt7"),
+		Localized(lang, "chart.label.age_7_30"),
+		Localized(lang, "chart.label.age_30_90"),
+		Localized(lang, "chart.label.age_gt90"),
+	}
+	return labels, values
+}
+
+// findingsBurndown counts findings still unresolved at the end of each of the last days.
+func findingsBurndown(items []store.ReportSnapshotItem, now time.Time, days int) ([]string, []float64) {
+	if days <= 0 {
+		days = 30
+	}
+	start := truncateDay(now.AddDate(0, 0, -(days-1)))
+	labels := make([]string, days)
+	values := make([]float64, days)
+	for i := 0; i < days; i++ {
+		labels[i] = start.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	for _, item := range items {
+		if item.EntityType != "finding" {
+			continue
+		}
+		created, ok := getTime(item.Entity, "created_at")
+		if !ok {
+			continue
+		}
+		resolvedAt, resolved := findingResolvedAt(item)
+		for i := 0; i < days; i++ {
+			end := start.AddDate(0, 0, i+1)
+			if !created.Before(end) {
+				continue
+			}
+			if resolved && resolvedAt.Before(end) {
+				continue
+			}
+			values[i]++
+		}
+	}
+	return labels, values
+}
+
+// findingResolvedAt falls back to updated_at for findings closed before resolution
+// times were recorded.
+func findingResolvedAt(item store.ReportSnapshotItem) (time.Time, bool) {
+	if t, ok := getTime(item.Entity, "resolved_at"); ok {
+		return t, true
+	}
+	switch strings.ToLower(getString(item.Entity, "status")) {
+	case "resolved", "false_positive":
+		return getTime(item.Entity, "updated_at")
+	}
+	return time.Time{}, false
+}
+
+// findingsSeverityCounts counts unresolved findings other than accepted risks by severity.
+func findingsSeverityCounts(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	order := []string{"critical", "high", "medium", "low"}
+	values := make([]float64, len(order))
+	for _, item := range items {
+		if item.EntityType != "finding" {
+			continue
+		}
+		if _, resolved := findingResolvedAt(item); resolved || strings.EqualFold(getString(item.Entity, "status"), "accepted_risk") {
+			continue
+		}
+		sev := strings.ToLower(strings.TrimSpace(getString(item.Entity, "severity")))
+		for i, key := range order {
+			if key == sev {
+				values[i]++
+			}
+		}
+	}
+	labels := make([]string, len(order))
+	for i, key := range order {
+		labels[i] = Localized(lang, "chart.severity."+key)
+	}
+	return labels, values
+}
+
+// entityCounts counts snapshot items of entityType by the raw value of field.
+func entityCounts(items []store.ReportSnapshotItem, entityType, field string) map[string]int {
+	counts := map[string]int{}
+	for _, item := range items {
+		if item.EntityType != entityType {
+			continue
+		}
+		val := strings.TrimSpace(getString(item.Entity, field))
+		if val == "" {
+			val = "unknown"
+		}
+		counts[val]++
+	}
+	return counts
+}
+
+func assetsCriticalityCounts(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	order := []string{"critical", "high", "medium", "low"}
+	counts := entityCounts(items, "asset", "criticality")
+	labels := make([]string, len(order))
+	values := make([]float64, len(order))
+	for i, key := range order {
+		labels[i] = Localized(lang, "chart.severity."+key)
+		values[i] = float64(counts[key])
+	}
+	return labels, values
+}
+
+// softwareInstallations returns the products with the most installations.
+func softwareInstallations(items []store.ReportSnapshotItem, topN int) ([]string, []float64) {
+	type pair struct {
+		Name  string
+		Value float64
+	}
+	var pairs []pair
+	for _, item := range items {
+		if item.EntityType != "software" {
+			continue
+		}
+		name := strings.TrimSpace(getString(item.Entity, "name"))
+		if vendor := strings.TrimSpace(getString(item.Entity, "vendor")); vendor != "" {
+			name = vendor + " " + name
+		}
+		pairs = append(pairs, pair{Name: name, Value: getFloat(item.Entity, "installations")})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Value == pairs[j].Value {
+			return pairs[i].Name < pairs[j].Name
+		}
+		return pairs[i].Value > pairs[j].Value
+	})
+	if topN > 0 && len(pairs) > topN {
+		pairs = pairs[:topN]
+	}
+	labels := make([]string, 0, len(pairs))
+	values := make([]float64, 0, len(pairs))
+	for _, p := range pairs {
+		labels = append(labels, p.Name)
+		values = append(values, p.Value)
+	}
+	return labels, values
+}
+
+// frameworkCoveragePct returns the coverage of each framework in snapshot order.
+func frameworkCoveragePct(items []store.ReportSnapshotItem) ([]string, []float64) {
+	var labels []string
+	var values []float64
+	for _, item := range items {
+		if item.EntityType != "framework" {
+			continue
+		}
+		name := strings.TrimSpace(getString(item.Entity, "name"))
+		if version := strings.TrimSpace(getString(item.Entity, "version")); version != "" {
+			name += " " + version
+		}
+		labels = append(labels, name)
+		values = append(values, getFloat(item.Entity, "coverage_pct"))
+	}
+	return labels, values
+}
+
 func approvalsCounts(items []store.ReportSnapshotItem) map[string]int {
 	counts := map[string]int{"approved": 0, "returned": 0, "review": 0}
 	for _, item := range items {
@@ -502,3 +1017,271 @@ func startOfWeek(t time.Time) time.Time {
 func truncateDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
+
+// incidentInterval returns the hours between two lifecycle fields of an incident snapshot item.
+func incidentInterval(item store.ReportSnapshotItem, fromField, toField string) (float64, bool) {
+	from, ok := getTime(item.Entity, fromField)
+	if !ok {
+		return 0, false
+	}
+	to, ok := getTime(item.Entity, toField)
+	if !ok || to.Before(from) {
+		return 0, false
+	}
+	return to.Sub(from).Hours(), true
+}
+
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return math.Round(sum/float64(len(values))*100) / 100
+}
+
+func incidentsResponseMeans(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	intervals := []struct {
+		label    string
+		from, to string
+	}{
+		{"chart.label.mttd", "started_at", "detected_at"},
+		{"chart.label.mtta", "detected_at", "acknowledged_at"},
+		{"chart.label.mttc", "detected_at", "contained_at"},
+		{"chart.label.mttr", "detected_at", "resolved_at"},
+	}
+	labels := make([]string, 0, len(intervals))
+	values := make([]float64, 0, len(intervals))
+	for _, iv := range intervals {
+		var hours []float64
+		for _, item := range items {
+			if item.EntityType != "incident" {
+				continue
+			}
+			if v, ok := incidentInterval(item, iv.from, iv.to); ok {
+				hours = append(hours, v)
+			}
+		}
+		labels = append(labels, Localized(lang, iv.label))
+		values = append(values, meanOf(hours))
+	}
+	return labels, values
+}
+
+func incidentsMTTRBySeverity(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	order := []string{"critical", "high", "medium", "low"}
+	hours := map[string][]float64{}
+	for _, item := range items {
+		if item.EntityType != "incident" {
+			continue
+		}
+		if v, ok := incidentInterval(item, "detected_at", "resolved_at"); ok {
+			sev := strings.ToLower(strings.TrimSpace(getString(item.Entity, "severity")))
+			hours[sev] = append(hours[sev], v)
+		}
+	}
+	labels := make([]string, 0, len(order))
+	values := make([]float64, 0, len(order))
+	for _, sev := range order {
+		labels = append(labels, Localized(lang, "chart.severity."+sev))
+		values = append(values, meanOf(hours[sev]))
+	}
+	return labels, values
+}
+
+func incidentsMTTRWeekly(items []store.ReportSnapshotItem, from, to *time.Time, weeks int) ([]string, []float64) {
+	labels, _ := weeklyBuckets(nil, from, to, weeks)
+	hours := make([][]float64, len(labels))
+	starts := make([]time.Time, len(labels))
+	for i, l := range labels {
+		starts[i], _ = time.Parse("2006-01-02", l)
+	}
+	for _, item := range items {
+		if item.EntityType != "incident" {
+			continue
+		}
+		v, ok := incidentInterval(item, "detected_at", "resolved_at")
+		if !ok {
+			continue
+		}
+		detected, _ := getTime(item.Entity, "detected_at")
+		for i, start := range starts {
+			if !detected.Before(start) && detected.Before(start.AddDate(0, 0, 7)) {
+				hours[i] = append(hours[i], v)
+				break
+			}
+		}
+	}
+	values := make([]float64, len(labels))
+	for i := range labels {
+		values[i] = meanOf(hours[i])
+	}
+	return labels, values
+}
+
+func effortHours(items []store.ReportSnapshotItem, topN int) ([]string, []float64) {
+	type pair struct {
+		Name  string
+		Value float64
+	}
+	var pairs []pair
+	for _, item := range items {
+		if item.EntityType != "effort" {
+			continue
+		}
+		name := strings.TrimSpace(getString(item.Entity, "label"))
+		if name == "" {
+			name = "-"
+		}
+		hours := math.Round(getFloat(item.Entity, "minutes")/60*10) / 10
+		pairs = append(pairs, pair{Name: name, Value: hours})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Value > pairs[j].Value
+	})
+	if topN > 0 && len(pairs) > topN {
+		pairs = pairs[:topN]
+	}
+	labels := make([]string, 0, len(pairs))
+	values := make([]float64, 0, len(pairs))
+	for _, p := range pairs {
+		labels = append(labels, p.Name)
+		values = append(values, p.Value)
+	}
+	return labels, values
+}
+
+// flowCumulative builds the cumulative flow diagram from per-day column counts.
+// Columns are stacked from the last board column up, so done work stays at the
+// bottom; columns of different boards with the same name are merged.
+func flowCumulative(items []store.ReportSnapshotItem) ([]string, []Series) {
+	type column struct {
+		name     string
+		position int
+		counts   map[string]float64
+	}
+	columns := map[string]*column{}
+	days := map[string]struct{}{}
+	for _, item := range items {
+		if item.EntityType != "task_flow_day" {
+			continue
+		}
+		date := getString(item.Entity, "date")
+		if date == "" {
+			continue
+		}
+		name := strings.TrimSpace(getString(item.Entity, "column"))
+		if name == "" {
+			name = "-"
+		}
+		position := 0
+		if p := getInt(item.Entity, "position"); p != nil {
+			position = *p
+		}
+		col, ok := columns[name]
+		if !ok {
+			col = &column{name: name, position: position, counts: map[string]float64{}}
+			columns[name] = col
+		} else if position < col.position {
+			col.position = position
+		}
+		col.counts[date] += getFloat(item.Entity, "count")
+		days[date] = struct{}{}
+	}
+	labels := make([]string, 0, len(days))
+	for d := range days {
+		labels = append(labels, d)
+	}
+	sort.Strings(labels)
+	ordered := make([]*column, 0, len(columns))
+	for _, col := range columns {
+		ordered = append(ordered, col)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].position != ordered[j].position {
+			return ordered[i].position > ordered[j].position
+		}
+		return ordered[i].name < ordered[j].name
+	})
+	series := make([]Series, 0, len(ordered))
+	for _, col := range ordered {
+		values := make([]float64, len(labels))
+		for i, d := range labels {
+			values[i] = col.counts[d]
+		}
+		series = append(series, Series{Name: col.name, Values: values})
+	}
+	return labels, series
+}
+
+func stackedTotals(n int, series []Series) []float64 {
+	totals := make([]float64, n)
+	for _, s := range series {
+		for i := 0; i < n && i < len(s.Values); i++ {
+			totals[i] += s.Values[i]
+		}
+	}
+	return totals
+}
+
+// flowPercentiles returns nearest-rank percentiles, in days, of a duration field
+// of completed tasks.
+func flowPercentiles(items []store.ReportSnapshotItem, field string) ([]string, []float64) {
+	var values []float64
+	for _, item := range items {
+		if item.EntityType != "task_flow_done" {
+			continue
+		}
+		if _, ok := item.Entity[field]; !ok {
+			continue
+		}
+		values = append(values, getFloat(item.Entity, field)/86400)
+	}
+	labels := []string{"p50", "p75", "p85", "p95"}
+	out := make([]float64, len(labels))
+	if len(values) == 0 {
+		return labels, out
+	}
+	sort.Float64s(values)
+	for i, p := range []int{50, 75, 85, 95} {
+		rank := (p*len(values) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		out[i] = math.Round(values[rank-1]*10) / 10
+	}
+	return labels, out
+}
+
+// flowAgeing buckets open tasks by the time spent in their current column.
+func flowAgeing(items []store.ReportSnapshotItem, lang string) ([]string, []float64) {
+	values := make([]float64, 5)
+	for _, item := range items {
+		if item.EntityType != "task_flow_ageing" {
+			continue
+		}
+		days := getFloat(item.Entity, "age_seconds") / 86400
+		switch {
+		case days < 1:
+			values[0]++
+		case days < 3:
+			values[1]++
+		case days < 7:
+			values[2]++
+		case days < 14:
+			values[3]++
+		default:
+			values[4]++
+		}
+	}
+	labels := []string{
+		Localized(lang, "chart.label.age_lt1"),
+		Localized(lang, "chart.label.age_1_3"),
+		Localized(lang, "chart.label.age_3_7"),
+		Localized(lang, "chart.label.age_7_14"),
+		Localized(lang, "chart.label.age_gt14"),
+	}
+	return labels, values
+}
@@ -1,12 +1,21 @@
 package charts
 
-import "berkut-scc/core/store"
+import (
+	"strings"
+
+	"berkut-scc/core/store"
+)
 
 type Kind string
 
 const (
 	KindBar  Kind = "bar"
 	KindLine Kind = "line"
+	KindArea Kind = "area"
+	KindStackedBar Kind = "stacked_bar"
+	KindPie        Kind = "pie"
+	KindDonut      Kind = "donut"
+	KindHeatmap    Kind = "heatmap"
 )
 
 type Definition struct {
@@ -15,6 +24,8 @@ type Definition struct {
 	SectionType   string
 	Kind          Kind
 	DefaultConfig map[string]any
+	// Palette names the default colour set; config "palette" overrides it.
+	Palette string
 }
 
 var definitions = map[string]Definition{
@@ -37,6 +48,55 @@ var definitions = map[string]Definition{
 		Kind:        KindLine,
 		DefaultConfig: map[string]any{"weeks": 8},
 	},
+	"incidents_response_bar": {
+		Type:        "incidents_response_bar",
+		TitleKey:    "chart.title.incidents_response",
+		SectionType: "incidents",
+		Kind:        KindBar,
+	},
+	"incidents_mttr_severity_bar": {
+		Type:        "incidents_mttr_severity_bar",
+		TitleKey:    "chart.title.mttr_severity",
+		SectionType: "incidents",
+		Kind:        KindBar,
+	},
+	"incidents_mttr_weekly_line": {
+		Type:        "incidents_mttr_weekly_line",
+		TitleKey:    "chart.title.mttr_weekly",
+		SectionType: "incidents",
+		Kind:        KindLine,
+		DefaultConfig: map[string]any{"weeks": 8},
+	},
+	"incidents_severity_weekly_stacked": {
+		Type:        "incidents_severity_weekly_stacked",
+		TitleKey:    "chart.title.severity_weekly",
+		SectionType: "incidents",
+		Kind:        KindStackedBar,
+		DefaultConfig: map[string]any{"weeks": 8},
+		Palette:     "severity",
+	},
+	"incidents_severity_weekly_line": {
+		Type:        "incidents_severity_weekly_line",
+		TitleKey:    "chart.title.severity_trend",
+		SectionType: "incidents",
+		Kind:        KindLine,
+		DefaultConfig: map[string]any{"weeks": 8},
+		Palette:     "severity",
+	},
+	"incidents_severity_pie": {
+		Type:        "incidents_severity_pie",
+		TitleKey:    "chart.title.severity_share",
+		SectionType: "incidents",
+		Kind:        KindPie,
+		Palette:     "severity",
+	},
+	"incidents_weekday_hour_heatmap": {
+		Type:        "incidents_weekday_hour_heatmap",
+		TitleKey:    "chart.title.weekday_hour",
+		SectionType: "incidents",
+		Kind:        KindHeatmap,
+		Palette:     "heat",
+	},
 	"tasks_status_bar": {
 		Type:        "tasks_status_bar",
 		TitleKey:    "chart.title.tasks_status",
@@ -76,6 +136,13 @@ var definitions = map[string]Definition{
 		Kind:        KindBar,
 		DefaultConfig: map[string]any{"top_n": 6},
 	},
+	"controls_status_donut": {
+		Type:        "controls_status_donut",
+		TitleKey:    "chart.title.controls_share",
+		SectionType: "controls",
+		Kind:        KindDonut,
+		Palette:     "traffic",
+	},
 	"monitoring_uptime_bar": {
 		Type:        "monitoring_uptime_bar",
 		TitleKey:    "chart.title.monitoring_uptime",
@@ -96,6 +163,126 @@ var definitions = map[string]Definition{
 		SectionType: "monitoring",
 		Kind:        KindBar,
 	},
+	"software_eol_bar": {
+		Type:        "software_eol_bar",
+		TitleKey:    "chart.title.software_eol",
+		SectionType: "software_eol",
+		Kind:        KindBar,
+	},
+	"risks_level_bar": {
+		Type:        "risks_level_bar",
+		TitleKey:    "chart.title.risks_level",
+		SectionType: "risks",
+		Kind:        KindBar,
+	},
+	"risks_matrix_heatmap": {
+		Type:        "risks_matrix_heatmap",
+		TitleKey:    "chart.title.risks_matrix",
+		SectionType: "risks",
+		Kind:        KindHeatmap,
+		Palette:     "heat",
+	},
+	"findings_ageing_bar": {
+		Type:        "findings_ageing_bar",
+		TitleKey:    "chart.title.findings_ageing",
+		SectionType: "findings",
+		Kind:        KindBar,
+	},
+	"findings_burndown_line": {
+		Type:        "findings_burndown_line",
+		TitleKey:    "chart.title.findings_burndown",
+		SectionType: "findings",
+		Kind:        KindLine,
+		DefaultConfig: map[string]any{"days": 30},
+	},
+	"findings_severity_bar": {
+		Type:        "findings_severity_bar",
+		TitleKey:    "chart.title.findings_severity",
+		SectionType: "findings",
+		Kind:        KindBar,
+	},
+	"findings_status_bar": {
+		Type:        "findings_status_bar",
+		TitleKey:    "chart.title.findings_status",
+		SectionType: "findings",
+		Kind:        KindBar,
+	},
+	"assets_criticality_bar": {
+		Type:        "assets_criticality_bar",
+		TitleKey:    "chart.title.assets_criticality",
+		SectionType: "assets",
+		Kind:        KindBar,
+	},
+	"assets_env_bar": {
+		Type:        "assets_env_bar",
+		TitleKey:    "chart.title.assets_env",
+		SectionType: "assets",
+		Kind:        KindBar,
+	},
+	"assets_type_bar": {
+		Type:        "assets_type_bar",
+		TitleKey:    "chart.title.assets_type",
+		SectionType: "assets",
+		Kind:        KindBar,
+	},
+	"software_inventory_bar": {
+		Type:        "software_inventory_bar",
+		TitleKey:    "chart.title.software_inventory",
+		SectionType: "software",
+		Kind:        KindBar,
+		DefaultConfig: map[string]any{"top_n": 8},
+	},
+	"framework_coverage_bar": {
+		Type:        "framework_coverage_bar",
+		TitleKey:    "chart.title.framework_coverage",
+		SectionType: "framework_coverage",
+		Kind:        KindBar,
+	},
+	"effort_bar": {
+		Type:        "effort_bar",
+		TitleKey:    "chart.title.effort",
+		SectionType: "effort",
+		Kind:        KindBar,
+		DefaultConfig: map[string]any{"top_n": 8},
+	},
+	"tasks_flow_cfd_area": {
+		Type:        "tasks_flow_cfd_area",
+		TitleKey:    "chart.title.flow_cfd",
+		SectionType: "task_flow",
+		Kind:        KindArea,
+	},
+	"tasks_flow_cycle_bar": {
+		Type:        "tasks_flow_cycle_bar",
+		TitleKey:    "chart.title.flow_cycle",
+		SectionType: "task_flow",
+		Kind:        KindBar,
+	},
+	"tasks_flow_lead_bar": {
+		Type:        "tasks_flow_lead_bar",
+		TitleKey:    "chart.title.flow_lead",
+		SectionType: "task_flow",
+		Kind:        KindBar,
+	},
+	"tasks_flow_throughput_line": {
+		Type:        "tasks_flow_throughput_line",
+		TitleKey:    "chart.title.flow_throughput",
+		SectionType: "task_flow",
+		Kind:        KindLine,
+		DefaultConfig: map[string]any{"weeks": 8},
+	},
+	"tasks_flow_wip_line": {
+		Type:        "tasks_flow_wip_line",
+		TitleKey:    "chart.title.flow_wip",
+		SectionType: "task_flow",
+		Kind:        KindLine,
+		DefaultConfig: map[string]any{"weeks": 8},
+	},
+	"tasks_flow_ageing_bar": {
+		Type:        "tasks_flow_ageing_bar",
+		TitleKey:    "chart.title.flow_ageing",
+		SectionType: "task_flow",
+		Kind:        KindBar,
+	},
 }
 
 func DefinitionFor(chartType string) (Definition, bool) {
@@ -117,6 +304,12 @@ func DefaultCharts() []store.ReportChart {
 		"monitoring_uptime_bar",
 		"monitoring_downtime_line",
 		"monitoring_tls_bar",
+		"software_eol_bar",
+		"risks_level_bar",
+		"findings_ageing_bar",
+		"findings_burndown_line",
+		"findings_severity_bar",
+		"effort_bar",
 	}
 	out := make([]store.ReportChart, 0, len(order))
 	for _, key := range order {
@@ -149,13 +342,16 @@ func NormalizeConfig(chartType string, cfg map[string]any) map[string]any {
 		out[k] = v
 	}
 	switch chartType {
-	case "controls_domains_bar", "monitoring_uptime_bar":
+	case "controls_domains_bar", "monitoring_uptime_bar", "effort_bar", "software_inventory_bar":
 		out["top_n"] = clampInt(cfg, "top_n", intValue(out["top_n"]), 3, 12)
-	case "incidents_weekly_line", "tasks_weekly_line", "docs_weekly_line":
+	case "incidents_weekly_line", "incidents_mttr_weekly_line", "incidents_severity_weekly_stacked", "incidents_severity_weekly_line", "tasks_weekly_line", "docs_weekly_line", "tasks_flow_throughput_line", "tasks_flow_wip_line":
 		out["weeks"] = clampInt(cfg, "weeks", intValue(out["weeks"]), 4, 16)
-	case "monitoring_downtime_line":
+	case "monitoring_downtime_line", "findings_burndown_line":
 		out["days"] = clampInt(cfg, "days", intValue(out["days"]), 7, 31)
 	}
+	if name, ok := cfg["palette"].(string); ok && paletteFor(name) != nil {
+		out["palette"] = strings.ToLower(strings.TrimSpace(name))
+	}
 	return out
 }
 
@@ -0,0 +1,155 @@
+package charts
+
+import "strings"
+
+// The PNG renderer has no font engine, so text is drawn from this 5x7 bitmap
+// font. Each glyph is eight rows of five bits, most significant bit on the
+// left; row 6 is the baseline and row 7 holds descenders.
+const (
+	glyphWidth   = 5
+	glyphRows    = 8
+	glyphAscent  = 7
+	glyphAdvance = 6
+)
+
+var glyphs = map[rune][glyphRows]uint8{
+	' ':  {},
+	'!':  {0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00000, 0b00100},
+	'"':  {0b01010, 0b01010, 0b01010},
+	'#':  {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+	'$':  {0b00100, 0b01111, 0b10100, 0b01110, 0b00101, 0b11110, 0b00100},
+	'%':  {0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011},
+	'&':  {0b01100, 0b10010, 0b10100, 0b01000, 0b10101, 0b10010, 0b01101},
+	'\'': {0b00100, 0b00100, 0b01000},
+	'(':  {0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010},
+	')':  {0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000},
+	'*':  {0b00000, 0b00100, 0b10101, 0b01110, 0b10101, 0b00100},
+	'+':  {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100},
+	',':  {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	'-':  {0b00000, 0b00000, 0b00000, 0b11111},
+	'.':  {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	'/':  {0b00000, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000},
+	'0':  {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1':  {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3':  {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4':  {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5':  {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6':  {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8':  {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9':  {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	':':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100},
+	';':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b00100, 0b01000},
+	'<':  {0b00010, 0b00100, 0b01000, 0b10000, 0b01000, 0b00100, 0b00010},
+	'=':  {0b00000, 0b00000, 0b11111, 0b00000, 0b11111},
+	'>':  {0b01000, 0b00100, 0b00010, 0b00001, 0b00010, 0b00100, 0b01000},
+	'?':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+	'@':  {0b01110, 0b10001, 0b00001, 0b01101, 0b10101, 0b10101, 0b01110},
+	'A':  {0b01110, 0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001},
+	'B':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C':  {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D':  {0b11100, 0b10010, 0b10001, 0b10001, 0b10001, 0b10010, 0b11100},
+	'E':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G':  {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H':  {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I':  {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J':  {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K':  {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L':  {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M':  {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N':  {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S':  {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T':  {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W':  {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X':  {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y':  {0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100},
+	'Z':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'[':  {0b01110, 0b01000, 0b01000, 0b01000, 0b01000, 0b01000, 0b01110},
+	'\\': {0b00000, 0b10000, 0b01000, 0b00100, 0b00010, 0b00001},
+	']':  {0b01110, 0b00010, 0b00010, 0b00010, 0b00010, 0b00010, 0b01110},
+	'^':  {0b00100, 0b01010, 0b10001},
+	'_':  {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b11111},
+	'`':  {0b01000, 0b00100},
+	'a':  {0b00000, 0b00000, 0b01110, 0b00001, 0b01111, 0b10001, 0b01111},
+	'b':  {0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b11110},
+	'c':  {0b00000, 0b00000, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110},
+	'd':  {0b00001, 0b00001, 0b01101, 0b10011, 0b10001, 0b10001, 0b01111},
+	'e':  {0b00000, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110},
+	'f':  {0b00110, 0b01001, 0b01000, 0b11100, 0b01000, 0b01000, 0b01000},
+	'g':  {0b00000, 0b00000, 0b01111, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},
+	'h':  {0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001},
+	'i':  {0b00100, 0b00000, 0b01100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'j':  {0b00010, 0b00000, 0b00110, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'k':  {0b10000, 0b10000, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010},
+	'l':  {0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'm':  {0b00000, 0b00000, 0b11010, 0b10101, 0b10101, 0b10001, 0b10001},
+	'n':  {0b00000, 0b00000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001},
+	'o':  {0b00000, 0b00000, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110},
+	'p':  {0b00000, 0b00000, 0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000},
+	'q':  {0b00000, 0b00000, 0b01111, 0b10001, 0b10001, 0b01111, 0b00001, 0b00001},
+	'r':  {0b00000, 0b00000, 0b10110, 0b11001, 0b10000, 0b10000, 0b10000},
+	's':  {0b00000, 0b00000, 0b01111, 0b10000, 0b01110, 0b00001, 0b11110},
+	't':  {0b01000, 0b01000, 0b11100, 0b01000, 0b01000, 0b01001, 0b00110},
+	'u':  {0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b10011, 0b01101},
+	'v':  {0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'w':  {0b00000, 0b00000, 0b10001, 0b10001, 0b10101, 0b10101, 0b01010},
+	'x':  {0b00000, 0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001},
+	'y':  {0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},
+	'z':  {0b00000, 0b00000, 0b11111, 0b00010, 0b00100, 0b01000, 0b11111},
+	'{':  {0b00010, 0b00100, 0b00100, 0b01000, 0b00100, 0b00100, 0b00010},
+	'|':  {0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'}':  {0b01000, 0b00100, 0b00100, 0b00010, 0b00100, 0b00100, 0b01000},
+	'~':  {0b00000, 0b00000, 0b01000, 0b10101, 0b00010},
+}
+
+// cyrillicLatin transliterates Russian labels, which the bitmap font cannot draw.
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+var punctuationASCII = map[rune]string{
+	'—': "-", '–': "-", '…': "...", '«': "\"", '»': "\"", '“': "\"", '”': "\"",
+	'‘': "'", '’': "'", '№': "No", '°': "o", '×': "x", '≥': ">=", '≤': "<=",
+}
+
+// fontText reduces label to characters the bitmap font has; anything else
+// becomes "?".
+func fontText(label string) string {
+	var b strings.Builder
+	for _, r := range label {
+		if _, ok := glyphs[r]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		if latin, ok := cyrillicLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if lower := []rune(strings.ToLower(string(r)))[0]; lower != r {
+			if latin, ok := cyrillicLatin[lower]; ok {
+				if latin != "" {
+					b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+				}
+				continue
+			}
+		}
+		if ascii, ok := punctuationASCII[r]; ok {
+			b.WriteString(ascii)
+			continue
+		}
+		b.WriteRune('?')
+	}
+	return b.String()
+}
@@ -4,6 +4,15 @@ var ru = map[string]string{
 	"chart.title.incidents_severity":  "Инциденты по критичности",
 	"chart.title.incidents_status":    "Инциденты по статусам",
 	"chart.title.incidents_weekly":    "Инциденты по неделям",
+	"chart.title.incidents_response":  "Время реагирования на инциденты",
+	"chart.title.mttr_severity":       "MTTR по критичности",
+	"chart.title.mttr_weekly":         "MTTR по неделям",
+	"chart.axis.hours":                "Часы",
+	"chart.axis.severity":             "Критичность",
+	"chart.label.mttd":                "MTTD",
+	"chart.label.mtta":                "MTTA",
+	"chart.label.mttc":                "MTTC",
+	"chart.label.mttr":                "MTTR",
 	"chart.title.tasks_status":        "Выполнение задач",
 	"chart.title.tasks_weekly":        "Динамика выполнения по неделям",
 	"chart.title.docs_approvals":      "Согласования документов",
@@ -13,12 +22,49 @@ var ru = map[string]string{
 	"chart.title.monitoring_uptime":   "Uptime критичных мониторингов",
 	"chart.title.monitoring_downtime": "Падения по дням",
 	"chart.title.monitoring_tls":      "TLS истекает",
+	"chart.title.software_eol":        "ПО с истекающей поддержкой",
+	"chart.title.risks_level":         "Риски по уровню",
+	"chart.title.findings_ageing":     "Возраст открытых замечаний",
+	"chart.title.findings_burndown":   "Динамика открытых замечаний",
+	"chart.title.findings_severity":   "Открытые замечания по критичности",
+	"chart.title.findings_status":     "Замечания по статусам",
+	"chart.title.assets_criticality":  "Активы по критичности",
+	"chart.title.assets_env":          "Активы по окружениям",
+	"chart.title.assets_type":         "Активы по типам",
+	"chart.title.software_inventory":  "ПО по числу установок",
+	"chart.title.framework_coverage":  "Покрытие требований фреймворков",
+	"chart.title.effort":              "Трудозатраты",
+	"chart.title.flow_cfd":            "Накопительная диаграмма потока",
+	"chart.title.flow_cycle":          "Cycle time по процентилям",
+	"chart.title.flow_lead":           "Lead time по процентилям",
+	"chart.title.flow_throughput":     "Пропускная способность по неделям",
+	"chart.title.flow_wip":            "Превышения WIP-лимитов",
+	"chart.title.flow_ageing":         "Открытые задачи по времени в колонке",
+	"chart.title.severity_weekly":     "Инциденты по критичности и неделям",
+	"chart.title.severity_trend":      "Динамика инцидентов по критичности",
+	"chart.title.severity_share":      "Доля инцидентов по критичности",
+	"chart.title.weekday_hour":        "Инциденты по дням недели и часам",
+	"chart.title.controls_share":      "Контроли по статусу внедрения",
+	"chart.title.risks_matrix":        "Матрица рисков",
 	"chart.axis.count":                "Количество",
 	"chart.axis.week":                 "Неделя",
 	"chart.axis.day":                  "День",
 	"chart.axis.uptime":               "Uptime (%)",
 	"chart.axis.domain":               "Домен",
 	"chart.axis.monitor":              "Монитор",
+	"chart.axis.risk_level":           "Уровень риска",
+	"chart.axis.age":                  "Возраст",
+	"chart.axis.days":                 "Дни",
+	"chart.axis.percentile":           "Процентиль",
+	"chart.axis.column_days":          "Колонко-дни",
+	"chart.axis.criticality":          "Критичность",
+	"chart.axis.installations":        "Установки",
+	"chart.axis.framework":            "Фреймворк",
+	"chart.axis.coverage":             "Покрытие (%)",
+	"chart.axis.hour":                 "Час (UTC)",
+	"chart.axis.weekday":              "День недели",
+	"chart.axis.impact":               "Влияние",
+	"chart.axis.likelihood":           "Вероятность",
 	"chart.label.done":                "Выполнено",
 	"chart.label.overdue":             "Просрочено",
 	"chart.label.in_progress":         "В работе",
@@ -31,6 +77,21 @@ var ru = map[string]string{
 	"chart.label.lt7":                 "< 7 дней",
 	"chart.label.lt30":                "< 30 дней",
 	"chart.label.lt90":                "< 90 дней",
+	"chart.label.eol_expired":         "Истекла",
+	"chart.label.eol_later":           "Позже",
+	"chart.label.age_lt7":             "< 7 дней",
+	"chart.label.age_7_30":            "7–30 дней",
+	"chart.label.age_30_90":           "30–90 дней",
+	"chart.label.age_gt90":            "> 90 дней",
+	"chart.label.age_lt1":             "< 1 дня",
+	"chart.label.age_1_3":             "1–3 дня",
+	"chart.label.age_3_7":             "3–7 дней",
+	"chart.label.age_7_14":            "7–14 дней",
+	"chart.label.age_gt14":            "> 14 дней",
+	"chart.risk_level.low":            "Низкий",
+	"chart.risk_level.medium":         "Средний",
+	"chart.risk_level.high":           "Высокий",
+	"chart.risk_level.critical":       "Критический",
 	"chart.severity.critical":         "Критично",
 	"chart.severity.high":             "Высокая",
 	"chart.severity.medium":           "Средняя",
@@ -40,12 +101,32 @@ var ru = map[string]string{
 	"chart.status.resolved":           "Решен",
 	"chart.status.closed":             "Закрыт",
 	"chart.status.draft":              "Черновик",
+	"chart.control.implemented":       "Внедрен",
+	"chart.control.partial":           "Частично",
+	"chart.control.not_implemented":   "Не внедрен",
+	"chart.control.not_applicable":    "Неприменим",
+	"chart.weekday.1":                 "Пн",
+	"chart.weekday.2":                 "Вт",
+	"chart.weekday.3":                 "Ср",
+	"chart.weekday.4":                 "Чт",
+	"chart.weekday.5":                 "Пт",
+	"chart.weekday.6":                 "Сб",
+	"chart.weekday.7":                 "Вс",
 }
 
 var en = map[string]string{
 	"chart.title.incidents_severity":  "Incidents by severity",
 	"chart.title.incidents_status":    "Incidents by status",
 	"chart.title.incidents_weekly":    "Incidents by week",
+	"chart.title.incidents_response":  "Incident response times",
+	"chart.title.mttr_severity":       "MTTR by severity",
+	"chart.title.mttr_weekly":         "MTTR by week",
+	"chart.axis.hours":                "Hours",
+	"chart.axis.severity":             "Severity",
+	"chart.label.mttd":                "MTTD",
+	"chart.label.mtta":                "MTTA",
+	"chart.label.mttc":                "MTTC",
+	"chart.label.mttr":                "MTTR",
 	"chart.title.tasks_status":        "Task completion",
 	"chart.title.tasks_weekly":        "Task completion by week",
 	"chart.title.docs_approvals":      "Document approvals",
@@ -55,12 +136,49 @@ var en = map[string]string{
 	"chart.title.monitoring_uptime":   "Uptime for critical monitors",
 	"chart.title.monitoring_downtime": "Downtime by day",
 	"chart.title.monitoring_tls":      "TLS expiring",
+	"chart.title.software_eol":        "Software end of life",
+	"chart.title.risks_level":         "Risks by level",
+	"chart.title.findings_ageing":     "Open findings by age",
+	"chart.title.findings_burndown":   "Open findings burn-down",
+	"chart.title.findings_severity":   "Open findings by severity",
+	"chart.title.findings_status":     "Findings by status",
+	"chart.title.assets_criticality":  "Assets by criticality",
+	"chart.title.assets_env":          "Assets by environment",
+	"chart.title.assets_type":         "Assets by type",
+	"chart.title.software_inventory":  "Software by installations",
+	"chart.title.framework_coverage":  "Framework coverage",
+	"chart.title.effort":              "Effort",
+	"chart.title.flow_cfd":            "Cumulative flow",
+	"chart.title.flow_cycle":          "Cycle time percentiles",
+	"chart.title.flow_lead":           "Lead time percentiles",
+	"chart.title.flow_throughput":     "Weekly throughput",
+	"chart.title.flow_wip":            "WIP limit breaches",
+	"chart.title.flow_ageing":         "Open tasks by time in column",
+	"chart.title.severity_weekly":     "Incidents by severity per week",
+	"chart.title.severity_trend":      "Incident trend by severity",
+	"chart.title.severity_share":      "Incident share by severity",
+	"chart.title.weekday_hour":        "Incidents by weekday and hour",
+	"chart.title.controls_share":      "Controls by implementation status",
+	"chart.title.risks_matrix":        "Risk matrix",
 	"chart.axis.count":                "Count",
 	"chart.axis.week":                 "Week",
 	"chart.axis.day":                  "Day",
 	"chart.axis.uptime":               "Uptime (%)",
 	"chart.axis.domain":               "Domain",
 	"chart.axis.monitor":              "Monitor",
+	"chart.axis.risk_level":           "Risk level",
+	"chart.axis.age":                  "Age",
+	"chart.axis.days":                 "Days",
+	"chart.axis.percentile":           "Percentile",
+	"chart.axis.column_days":          "Column-days",
+	"chart.axis.criticality":          "Criticality",
+	"chart.axis.installations":        "Installations",
+	"chart.axis.framework":            "Framework",
+	"chart.axis.coverage":             "Coverage (%)",
+	"chart.axis.hour":                 "Hour (UTC)",
+	"chart.axis.weekday":              "Weekday",
+	"chart.axis.impact":               "Impact",
+	"chart.axis.likelihood":           "Likelihood",
 	"chart.label.done":                "Done",
 	"chart.label.overdue":             "Overdue",
 	"chart.label.in_progress":         "In progress",
@@ -73,6 +191,21 @@ var en = map[string]string{
 	"chart.label.lt7":                 "< 7 days",
 	"chart.label.lt30":                "< 30 days",
 	"chart.label.lt90":                "< 90 days",
+	"chart.label.eol_expired":         "Expired",
+	"chart.label.eol_later":           "Later",
+	"chart.label.age_lt7":             "< 7 days",
+	"chart.label.age_7_30":            "7–30 days",
+	"chart.label.age_30_90":           "30–90 days",
+	"chart.label.age_gt90":            "> 90 days",
+	"chart.label.age_lt1":             "< 1 day",
+	"chart.label.age_1_3":             "1–3 days",
+	"chart.label.age_3_7":             "3–7 days",
+	"chart.label.age_7_14":            "7–14 days",
+	"chart.label.age_gt14":            "> 14 days",
+	"chart.risk_level.low":            "Low",
+	"chart.risk_level.medium":         "Medium",
+	"chart.risk_level.high":           "High",
+	"chart.risk_level.critical":       "Critical",
 	"chart.severity.critical":         "Critical",
 	"chart.severity.high":             "High",
 	"chart.severity.medium":           "Medium",
@@ -82,6 +215,17 @@ var en = map[string]string{
 	"chart.status.resolved":           "Resolved",
 	"chart.status.closed":             "Closed",
 	"chart.status.draft":              "Draft",
+	"chart.control.implemented":       "Implemented",
+	"chart.control.partial":           "Partial",
+	"chart.control.not_implemented":   "Not implemented",
+	"chart.control.not_applicable":    "Not applicable",
+	"chart.weekday.1":                 "Mon",
+	"chart.weekday.2":                 "Tue",
+	"chart.weekday.3":                 "Wed",
+	"chart.weekday.4":                 "Thu",
+	"chart.weekday.5":                 "Fri",
+	"chart.weekday.6":                 "Sat",
+	"chart.weekday.7":                 "Sun",
 }
 
 func Localized(lang, key string) string {
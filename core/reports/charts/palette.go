@@ -0,0 +1,81 @@
+package charts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var seriesPalette = []string{"#5d86ff", "#22c55e", "#f59e0b", "#ef4444", "#8b5cf6", "#14b8a6", "#ec4899", "#64748b"}
+
+// palettes are the named colour sets a chart can pick via config "palette".
+// Severity and traffic palettes follow the critical-to-low order used by the
+// severity charts; heat palettes are gradient stops from lowest to highest.
+var palettes = map[string][]string{
+	"default":  seriesPalette,
+	"severity": {"#dc2626", "#f97316", "#facc15", "#22c55e", "#9ca3af"},
+	"traffic":  {"#22c55e", "#facc15", "#ef4444", "#9ca3af"},
+	"mono":     {"#1e3a8a", "#1d4ed8", "#3b82f6", "#93c5fd", "#dbeafe"},
+	"heat":     {"#dcfce7", "#fde68a", "#f97316", "#dc2626"},
+	"blues":    {"#eff6ff", "#93c5fd", "#3b82f6", "#1e3a8a"},
+}
+
+const emptyCellColor = "#f3f4f6"
+
+func paletteFor(name string) []string {
+	if p, ok := palettes[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return p
+	}
+	return nil
+}
+
+// colorAt picks the idx-th colour of the chart palette, falling back to the
+// default series palette.
+func colorAt(data ChartData, idx int) string {
+	p := data.Palette
+	if len(p) == 0 {
+		p = seriesPalette
+	}
+	return p[idx%len(p)]
+}
+
+// heatColor maps ratio (0..1) onto the gradient formed by the palette stops.
+func heatColor(stops []string, ratio float64) string {
+	if len(stops) == 0 {
+		stops = palettes["heat"]
+	}
+	if len(stops) == 1 || ratio <= 0 {
+		return stops[0]
+	}
+	if ratio >= 1 {
+		return stops[len(stops)-1]
+	}
+	pos := ratio * float64(len(stops)-1)
+	idx := int(pos)
+	return mixColors(stops[idx], stops[idx+1], pos-float64(idx))
+}
+
+func mixColors(a, b string, t float64) string {
+	ar, ag, ab := parseHexColor(a)
+	br, bg, bb := parseHexColor(b)
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", mix(ar, br), mix(ag, bg), mix(ab, bb))
+}
+
+// parseHexColor reads "#rrggbb" or "#rgb"; anything else is black.
+func parseHexColor(val string) (uint8, uint8, uint8) {
+	hex := strings.TrimPrefix(strings.TrimSpace(val), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, 0, 0
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return uint8(n >> 16), uint8(n >> 8), uint8(n)
+}
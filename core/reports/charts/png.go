@@ -0,0 +1,182 @@
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+)
+
+// pngScale renders PNGs at twice the SVG size so that text and lines stay
+// sharp when documents shrink the image to the page width.
+const pngScale = 2
+
+// RenderPNG rasterizes the same layout as RenderSVG, for DOCX and PDF exports
+// whose converters cannot embed SVG.
+func RenderPNG(data ChartData) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth*pngScale, chartHeight*pngScale))
+	render(&rasterCanvas{img: img, scale: pngScale}, data)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rasterCanvas paints on an RGBA image without anti-aliasing; coordinates are
+// chart units multiplied by scale.
+type rasterCanvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+func (r *rasterCanvas) rect(x, y, w, h float64, fill string, opacity float64) {
+	s := r.scale
+	r.fillBox(x*s, y*s, (x+w)*s, (y+h)*s, fill, opacity)
+}
+
+func (r *rasterCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	dx, dy := x2-x1, y2-y1
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*width/2, dx/length*width/2
+	r.polygon([]point{
+		{x1 + nx, y1 + ny},
+		{x2 + nx, y2 + ny},
+		{x2 - nx, y2 - ny},
+		{x1 - nx, y1 - ny},
+	}, stroke, 1)
+}
+
+func (r *rasterCanvas) polyline(points []point, stroke string, width float64) {
+	for i := 1; i < len(points); i++ {
+		r.line(points[i-1].x, points[i-1].y, points[i].x, points[i].y, stroke, width)
+		if i < len(points)-1 {
+			r.circle(points[i].x, points[i].y, width/2, stroke)
+		}
+	}
+}
+
+// polygon fills with the even-odd rule, sampling pixel centres, so ring
+// outlines from ringPoints leave the hole empty.
+func (r *rasterCanvas) polygon(points []point, fill string, opacity float64) {
+	if len(points) < 3 {
+		return
+	}
+	s := r.scale
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minY = math.Min(minY, p.y*s)
+		maxY = math.Max(maxY, p.y*s)
+	}
+	src := rgba(fill)
+	var xs []float64
+	for py := int(math.Floor(minY)); py < int(math.Ceil(maxY)); py++ {
+		sy := float64(py) + 0.5
+		xs = xs[:0]
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			ay, by := a.y*s, b.y*s
+			if (ay <= sy && sy < by) || (by <= sy && sy < ay) {
+				xs = append(xs, a.x*s+(sy-ay)/(by-ay)*(b.x-a.x)*s)
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			from := int(math.Ceil(xs[i] - 0.5))
+			to := int(math.Ceil(xs[i+1] - 0.5))
+			for px := from; px < to; px++ {
+				r.blend(px, py, src, opacity)
+			}
+		}
+	}
+}
+
+func (r *rasterCanvas) circle(cx, cy, radius float64, fill string) {
+	s := r.scale
+	cx, cy, radius = cx*s, cy*s, radius*s
+	src := rgba(fill)
+	for py := int(math.Floor(cy - radius)); py <= int(math.Ceil(cy+radius)); py++ {
+		for px := int(math.Floor(cx - radius)); px <= int(math.Ceil(cx+radius)); px++ {
+			if math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy) <= radius {
+				r.blend(px, py, src, 1)
+			}
+		}
+	}
+}
+
+// text draws label with the bitmap font; size is the SVG font size, so a
+// glyph dot is a tenth of it.
+func (r *rasterCanvas) text(x, y float64, label string, size float64, fill, anchor string) {
+	runes := []rune(fontText(label))
+	if len(runes) == 0 {
+		return
+	}
+	dot := size / 10
+	width := float64(len(runes)*glyphAdvance-1) * dot
+	switch anchor {
+	case anchorMiddle:
+		x -= width / 2
+	case anchorEnd:
+		x -= width
+	}
+	top := y - glyphAscent*dot
+	s := r.scale
+	for i, ch := range runes {
+		glyph := glyphs[ch]
+		left := x + float64(i*glyphAdvance)*dot
+		for row := 0; row < glyphRows; row++ {
+			bits := glyph[row]
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				gx := left + float64(col)*dot
+				gy := top + float64(row)*dot
+				r.fillBox(gx*s, gy*s, (gx+dot)*s, (gy+dot)*s, fill, 1)
+			}
+		}
+	}
+}
+
+// fillBox fills the pixels whose centres fall inside the box (pixel coordinates).
+func (r *rasterCanvas) fillBox(x0, y0, x1, y1 float64, fill string, opacity float64) {
+	src := rgba(fill)
+	fromX, toX := int(math.Round(x0)), int(math.Round(x1))
+	fromY, toY := int(math.Round(y0)), int(math.Round(y1))
+	if toX == fromX && x1 > x0 {
+		toX++
+	}
+	if toY == fromY && y1 > y0 {
+		toY++
+	}
+	for py := fromY; py < toY; py++ {
+		for px := fromX; px < toX; px++ {
+			r.blend(px, py, src, opacity)
+		}
+	}
+}
+
+func (r *rasterCanvas) blend(px, py int, src color.RGBA, opacity float64) {
+	if !(image.Point{px, py}.In(r.img.Rect)) {
+		return
+	}
+	if opacity >= 1 {
+		r.img.SetRGBA(px, py, src)
+		return
+	}
+	dst := r.img.RGBAAt(px, py)
+	mix := func(s, d uint8) uint8 {
+		return uint8(float64(s)*opacity + float64(d)*(1-opacity) + 0.5)
+	}
+	r.img.SetRGBA(px, py, color.RGBA{mix(src.R, dst.R), mix(src.G, dst.G), mix(src.B, dst.B), 255})
+}
+
+func rgba(hex string) color.RGBA {
+	red, green, blue := parseHexColor(hex)
+	return color.RGBA{red, green, blue, 255}
+}
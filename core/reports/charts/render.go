@@ -8,62 +8,141 @@ import (
 )
 
 const (
-	chartWidth  = 640
-	chartHeight = 320
-	paddingTop  = 30
-	paddingRight = 16
+	chartWidth    = 640
+	chartHeight   = 320
+	paddingTop    = 30
+	paddingRight  = 16
 	paddingBottom = 52
-	paddingLeft = 52
+	paddingLeft   = 52
 )
 
+const (
+	anchorStart  = "start"
+	anchorMiddle = "middle"
+	anchorEnd    = "end"
+)
+
+type point struct {
+	x, y float64
+}
+
+// canvas is the drawing surface shared by the SVG and PNG renderers, so both
+// formats get the same layout.
+type canvas interface {
+	rect(x, y, w, h float64, fill string, opacity float64)
+	line(x1, y1, x2, y2 float64, stroke string, width float64)
+	polyline(points []point, stroke string, width float64)
+	polygon(points []point, fill string, opacity float64)
+	circle(cx, cy, r float64, fill string)
+	text(x, y float64, label string, size float64, fill, anchor string)
+}
+
 func RenderSVG(data ChartData) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">", chartWidth, chartHeight, chartWidth, chartHeight))
-	buf.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>")
-	buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"18\" font-family=\"Arial, sans-serif\" font-size=\"14\" fill=\"#111827\">%s</text>", paddingLeft, escapeXML(data.Title)))
+	render(&svgCanvas{buf: &buf}, data)
+	buf.WriteString("</svg>")
+	return buf.Bytes(), nil
+}
+
+func render(c canvas, data ChartData) {
+	c.rect(0, 0, chartWidth, chartHeight, "#ffffff", 1)
+	c.text(paddingLeft, 18, data.Title, 14, "#111827", anchorStart)
 	plotW := chartWidth - paddingLeft - paddingRight
 	plotH := chartHeight - paddingTop - paddingBottom
 	if plotW <= 0 || plotH <= 0 {
-		buf.WriteString("</svg>")
-		return buf.Bytes(), nil
+		return
 	}
-	maxVal := maxValue(data.Values)
-	if maxVal <= 0 {
-		maxVal = 1
+	switch data.Kind {
+	case KindPie, KindDonut:
+		drawDonut(c, data)
+		return
+	case KindHeatmap:
+		drawHeatmap(c, data)
+		return
 	}
-	drawAxes(&buf, plotW, plotH, maxVal, data)
+	maxVal := scaleMax(data)
+	drawAxes(c, plotW, plotH, maxVal, data)
+	labels := data.Labels
 	switch data.Kind {
 	case KindLine:
-		drawLineSeries(&buf, plotW, plotH, data.Values)
+		if len(data.Series) == 0 {
+			drawLineSeries(c, plotW, plotH, maxVal, data.Values, colorAt(data, 0))
+			break
+		}
+		for idx, s := range data.Series {
+			drawLineSeries(c, plotW, plotH, maxVal, s.Values, colorAt(data, idx))
+		}
+		drawLegend(c, data)
+		labels = thinLabels(labels, 10)
+	case KindArea:
+		drawStackedArea(c, plotW, plotH, maxVal, data)
+		drawLegend(c, data)
+		labels = thinLabels(labels, 10)
+	case KindStackedBar:
+		drawStackedBars(c, plotW, plotH, maxVal, data)
+		drawLegend(c, data)
+		labels = thinLabels(labels, 12)
 	default:
-		drawBars(&buf, plotW, plotH, data.Values)
+		drawBars(c, plotW, plotH, data)
+	}
+	if data.Kind == KindLine || data.Kind == KindArea {
+		drawLabels(c, plotW, plotH, labels)
+	} else {
+		drawBarLabels(c, plotW, plotH, labels)
 	}
-	drawLabels(&buf, plotW, plotH, data.Labels)
-	buf.WriteString("</svg>")
-	return buf.Bytes(), nil
 }
 
-func drawAxes(buf *bytes.Buffer, plotW, plotH int, maxVal float64, data ChartData) {
-	x0 := paddingLeft
-	y0 := paddingTop + plotH
-	buf.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#d1d5db\" stroke-width=\"1\"/>", x0, y0, x0+plotW, y0))
-	buf.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#d1d5db\" stroke-width=\"1\"/>", x0, paddingTop, x0, y0))
+// scaleMax is the top of the value axis: the largest value, or for
+// multi-series lines the largest point of any series.
+func scaleMax(data ChartData) float64 {
+	maxVal := maxValue(data.Values)
+	if data.Kind == KindLine {
+		for _, s := range data.Series {
+			if v := maxValue(s.Values); v > maxVal {
+				maxVal = v
+			}
+		}
+	}
+	if maxVal <= 0 {
+		maxVal = 1
+	}
+	return maxVal
+}
+
+func drawAxes(c canvas, plotW, plotH int, maxVal float64, data ChartData) {
+	x0 := float64(paddingLeft)
+	y0 := float64(paddingTop + plotH)
+	c.line(x0, y0, x0+float64(plotW), y0, "#d1d5db", 1)
+	c.line(x0, paddingTop, x0, y0, "#d1d5db", 1)
 	steps := 4
 	for i := 0; i <= steps; i++ {
 		val := maxVal * float64(i) / float64(steps)
-		y := y0 - int((val/maxVal)*float64(plotH))
-		buf.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#eef2f7\" stroke-width=\"1\"/>", x0, y, x0+plotW, y))
-		buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-family=\"Arial, sans-serif\" font-size=\"10\" fill=\"#6b7280\" text-anchor=\"end\">%s</text>", x0-6, y+4, formatNumber(val)))
+		y := y0 - float64(int((val/maxVal)*float64(plotH)))
+		c.line(x0, y, x0+float64(plotW), y, "#eef2f7", 1)
+		c.text(x0-6, y+4, formatNumber(val), 10, "#6b7280", anchorEnd)
 	}
 	if strings.TrimSpace(data.YLabel) != "" {
-		buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-family=\"Arial, sans-serif\" font-size=\"11\" fill=\"#6b7280\">%s</text>", x0, paddingTop-8, escapeXML(data.YLabel)))
+		c.text(x0+float64(plotW), paddingTop-8, data.YLabel, 11, "#6b7280", anchorEnd)
 	}
 	if strings.TrimSpace(data.XLabel) != "" {
-		buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-family=\"Arial, sans-serif\" font-size=\"11\" fill=\"#6b7280\" text-anchor=\"end\">%s</text>", x0+plotW, y0+38, escapeXML(data.XLabel)))
+		c.text(x0+float64(plotW), y0+38, data.XLabel, 11, "#6b7280", anchorEnd)
+	}
+}
+
+// barSlots returns the left edge step and width of count bars across the plot.
+func barSlots(plotW, count int) (float64, float64) {
+	barGap := 6.0
+	barW := (float64(plotW) - barGap*float64(count-1)) / float64(count)
+	if barW < 6 {
+		barW = 6
 	}
+	return barW + barGap, barW
 }
 
-func drawBars(buf *bytes.Buffer, plotW, plotH int, values []float64) {
+// drawBars paints a single series; with a palette each bar takes its own colour.
+func drawBars(c canvas, plotW, plotH int, data ChartData) {
+	values := data.Values
 	if len(values) == 0 {
 		return
 	}
@@ -71,47 +150,272 @@ func drawBars(buf *bytes.Buffer, plotW, plotH int, values []float64) {
 	if maxVal <= 0 {
 		maxVal = 1
 	}
-	count := len(values)
-	barGap := 6.0
-	barW := (float64(plotW) - barGap*float64(count-1)) / float64(count)
-	if barW < 6 {
-		barW = 6
-	}
+	step, barW := barSlots(plotW, len(values))
 	for i, v := range values {
 		height := (v / maxVal) * float64(plotH)
-		x := float64(paddingLeft) + float64(i)*(barW+barGap)
+		x := float64(paddingLeft) + float64(i)*step
 		y := float64(paddingTop) + float64(plotH) - height
-		buf.WriteString(fmt.Sprintf("<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"#5d86ff\"/>", x, y, barW, height))
+		color := seriesPalette[0]
+		if len(data.Palette) > 0 {
+			color = colorAt(data, i)
+		}
+		c.rect(x, y, barW, height, color, 1)
 	}
 }
 
-func drawLineSeries(buf *bytes.Buffer, plotW, plotH int, values []float64) {
-	if len(values) == 0 {
+// drawStackedBars stacks the series bottom-up in each bar; maxVal is the highest total.
+func drawStackedBars(c canvas, plotW, plotH int, maxVal float64, data ChartData) {
+	count := len(data.Labels)
+	if count == 0 {
 		return
 	}
-	maxVal := maxValue(values)
-	if maxVal <= 0 {
-		maxVal = 1
+	step, barW := barSlots(plotW, count)
+	for i := 0; i < count; i++ {
+		x := float64(paddingLeft) + float64(i)*step
+		base := float64(paddingTop + plotH)
+		for idx, s := range data.Series {
+			if i >= len(s.Values) || s.Values[i] <= 0 {
+				continue
+			}
+			height := (s.Values[i] / maxVal) * float64(plotH)
+			base -= height
+			c.rect(x, base, barW, height, colorAt(data, idx), 1)
+		}
+	}
+}
+
+// drawLineSeries plots values against the shared axis maximum maxVal.
+func drawLineSeries(c canvas, plotW, plotH int, maxVal float64, values []float64, color string) {
+	if len(values) == 0 {
+		return
 	}
 	step := float64(plotW)
 	if len(values) > 1 {
 		step = float64(plotW) / float64(len(values)-1)
 	}
-	var path strings.Builder
+	points := make([]point, 0, len(values))
 	for i, v := range values {
 		x := float64(paddingLeft) + step*float64(i)
 		y := float64(paddingTop) + float64(plotH) - (v/maxVal)*float64(plotH)
-		if i == 0 {
-			path.WriteString(fmt.Sprintf("M %.1f %.1f", x, y))
-		} else {
-			path.WriteString(fmt.Sprintf(" L %.1f %.1f", x, y))
+		points = append(points, point{x, y})
+		c.circle(x, y, 2.5, color)
+	}
+	c.polyline(points, color, 2)
+}
+
+// drawStackedArea stacks the series bottom-up; maxVal is the highest stack total.
+func drawStackedArea(c canvas, plotW, plotH int, maxVal float64, data ChartData) {
+	count := 0
+	for _, s := range data.Series {
+		if len(s.Values) > count {
+			count = len(s.Values)
 		}
-		buf.WriteString(fmt.Sprintf("<circle cx=\"%.1f\" cy=\"%.1f\" r=\"2.5\" fill=\"#5d86ff\"/>", x, y))
 	}
-	buf.WriteString(fmt.Sprintf("<path d=\"%s\" fill=\"none\" stroke=\"#5d86ff\" stroke-width=\"2\"/>", path.String()))
+	if count == 0 {
+		return
+	}
+	step := float64(plotW)
+	if count > 1 {
+		step = float64(plotW) / float64(count-1)
+	}
+	at := func(i int, v float64) point {
+		return point{float64(paddingLeft) + step*float64(i), float64(paddingTop) + float64(plotH) - (v/maxVal)*float64(plotH)}
+	}
+	lower := make([]float64, count)
+	for idx, s := range data.Series {
+		upper := make([]float64, count)
+		for i := range upper {
+			upper[i] = lower[i]
+			if i < len(s.Values) {
+				upper[i] += s.Values[i]
+			}
+		}
+		points := make([]point, 0, 2*count)
+		for i := 0; i < count; i++ {
+			points = append(points, at(i, upper[i]))
+		}
+		for i := count - 1; i >= 0; i-- {
+			points = append(points, at(i, lower[i]))
+		}
+		color := colorAt(data, idx)
+		c.polygon(points, color, 0.85)
+		c.polyline(points[:count], color, 1)
+		lower = upper
+	}
 }
 
-func drawLabels(buf *bytes.Buffer, plotW, plotH int, labels []string) {
+func drawLegend(c canvas, data ChartData) {
+	x := float64(paddingLeft)
+	y := float64(chartHeight - 12)
+	for idx, s := range data.Series {
+		if x > chartWidth-paddingRight-40 {
+			break
+		}
+		c.rect(x, y-9, 10, 10, colorAt(data, idx), 1)
+		label := trimLabel(s.Name, 14)
+		c.text(x+14, y, label, 10, "#374151", anchorStart)
+		x += float64(14 + len([]rune(label))*6 + 12)
+	}
+}
+
+// drawDonut draws one slice per label with a value/share legend on the right.
+// Pie charts are donuts without the hole.
+func drawDonut(c canvas, data ChartData) {
+	radius := float64(chartHeight-paddingTop-30) / 2
+	cx := float64(paddingLeft) + radius
+	cy := float64(paddingTop+10) + radius
+	inner := 0.0
+	if data.Kind == KindDonut {
+		inner = radius * 0.58
+	}
+	total := 0.0
+	for _, v := range data.Values {
+		if v > 0 {
+			total += v
+		}
+	}
+	if total <= 0 {
+		c.polygon(ringPoints(cx, cy, radius, inner, 0, 2*math.Pi), "#e5e7eb", 1)
+	}
+	angle := 0.0
+	for i, v := range data.Values {
+		if v <= 0 || total <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		c.polygon(ringPoints(cx, cy, radius, inner, angle, angle+sweep), colorAt(data, i), 1)
+		angle += sweep
+	}
+	if data.Kind == KindDonut {
+		c.text(cx, cy+7, formatNumber(total), 20, "#111827", anchorMiddle)
+	}
+	x := cx + radius + 40
+	y := float64(paddingTop + 20)
+	for i, label := range data.Labels {
+		if y > chartHeight-12 {
+			break
+		}
+		v := 0.0
+		if i < len(data.Values) {
+			v = data.Values[i]
+		}
+		share := 0.0
+		if total > 0 {
+			share = v / total * 100
+		}
+		c.rect(x, y-9, 10, 10, colorAt(data, i), 1)
+		c.text(x+16, y, trimLabel(label, 24), 11, "#374151", anchorStart)
+		c.text(chartWidth-paddingRight, y, fmt.Sprintf("%s (%.0f%%)", formatNumber(v), share), 11, "#6b7280", anchorEnd)
+		y += 18
+	}
+}
+
+// ringPoints outlines a ring sector clockwise from 12 o'clock; inner 0 gives
+// a pie slice.
+func ringPoints(cx, cy, outer, inner, from, to float64) []point {
+	steps := int(math.Ceil((to - from) / (math.Pi / 90)))
+	if steps < 2 {
+		steps = 2
+	}
+	at := func(r, a float64) point {
+		return point{cx + r*math.Sin(a), cy - r*math.Cos(a)}
+	}
+	points := make([]point, 0, 2*steps+2)
+	for i := 0; i <= steps; i++ {
+		points = append(points, at(outer, from+(to-from)*float64(i)/float64(steps)))
+	}
+	if inner <= 0 {
+		return append(points, point{cx, cy})
+	}
+	for i := steps; i >= 0; i-- {
+		points = append(points, at(inner, from+(to-from)*float64(i)/float64(steps)))
+	}
+	return points
+}
+
+// drawHeatmap lays Series out as rows (top to bottom) and Labels as columns;
+// cells are shaded along the palette gradient by their share of the maximum.
+func drawHeatmap(c canvas, data ChartData) {
+	rows := len(data.Series)
+	cols := len(data.Labels)
+	if rows == 0 || cols == 0 {
+		return
+	}
+	left := float64(paddingLeft + 44)
+	top := float64(paddingTop + 4)
+	gridW := float64(chartWidth-paddingRight-56) - left
+	gridH := float64(chartHeight-40) - top
+	cellW := gridW / float64(cols)
+	cellH := gridH / float64(rows)
+	maxVal := 0.0
+	for _, s := range data.Series {
+		if v := maxValue(s.Values); v > maxVal {
+			maxVal = v
+		}
+	}
+	stops := data.Palette
+	if len(stops) == 0 {
+		stops = palettes["heat"]
+	}
+	for r, s := range data.Series {
+		y := top + float64(r)*cellH
+		c.text(left-6, y+cellH/2+4, trimLabel(s.Name, 14), 10, "#374151", anchorEnd)
+		for col := 0; col < cols; col++ {
+			v := 0.0
+			if col < len(s.Values) {
+				v = s.Values[col]
+			}
+			x := left + float64(col)*cellW
+			fill := emptyCellColor
+			if v > 0 && maxVal > 0 {
+				fill = heatColor(stops, v/maxVal)
+			}
+			c.rect(x+0.5, y+0.5, cellW-1, cellH-1, fill, 1)
+			if v > 0 && cellW >= 18 && cellH >= 14 {
+				c.text(x+cellW/2, y+cellH/2+4, formatNumber(v), 10, "#111827", anchorMiddle)
+			}
+		}
+	}
+	labels := thinLabels(data.Labels, int(gridW/36))
+	for col, label := range labels {
+		if label == "" {
+			continue
+		}
+		c.text(left+(float64(col)+0.5)*cellW, top+gridH+14, trimLabel(label, 12), 10, "#6b7280", anchorMiddle)
+	}
+	if strings.TrimSpace(data.YLabel) != "" {
+		c.text(left+gridW, paddingTop-8, data.YLabel, 11, "#6b7280", anchorEnd)
+	}
+	if strings.TrimSpace(data.XLabel) != "" {
+		c.text(left+gridW, top+gridH+32, data.XLabel, 11, "#6b7280", anchorEnd)
+	}
+	// colour scale
+	scaleX := float64(chartWidth - paddingRight - 40)
+	const steps = 5
+	stepH := gridH / steps
+	for i := 0; i < steps; i++ {
+		c.rect(scaleX, top+float64(i)*stepH, 12, stepH, heatColor(stops, 1-float64(i)/float64(steps-1)), 1)
+	}
+	c.text(scaleX+16, top+10, formatNumber(maxVal), 10, "#6b7280", anchorStart)
+	c.text(scaleX+16, top+gridH, "0", 10, "#6b7280", anchorStart)
+}
+
+// thinLabels blanks labels so that at most limit of them are drawn.
+func thinLabels(labels []string, limit int) []string {
+	if len(labels) <= limit || limit <= 0 {
+		return labels
+	}
+	every := (len(labels) + limit - 1) / limit
+	out := make([]string, len(labels))
+	for i, label := range labels {
+		if i%every == 0 {
+			out[i] = label
+		}
+	}
+	return out
+}
+
+func drawLabels(c canvas, plotW, plotH int, labels []string) {
 	if len(labels) == 0 {
 		return
 	}
@@ -120,21 +424,35 @@ func drawLabels(buf *bytes.Buffer, plotW, plotH int, labels []string) {
 	if count > 1 {
 		step = float64(plotW) / float64(count-1)
 	}
-	y := paddingTop + plotH + 18
+	y := float64(paddingTop + plotH + 18)
 	for i, label := range labels {
 		x := float64(paddingLeft) + step*float64(i)
-		buf.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%d\" font-family=\"Arial, sans-serif\" font-size=\"10\" fill=\"#6b7280\" text-anchor=\"middle\">%s</text>", x, y, escapeXML(trimLabel(label, 12))))
+		c.text(x, y, trimLabel(label, 12), 10, "#6b7280", anchorMiddle)
+	}
+}
+
+// drawBarLabels centres each label under its bar slot.
+func drawBarLabels(c canvas, plotW, plotH int, labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+	step, barW := barSlots(plotW, len(labels))
+	y := float64(paddingTop + plotH + 18)
+	for i, label := range labels {
+		x := float64(paddingLeft) + float64(i)*step + barW/2
+		c.text(x, y, trimLabel(label, 12), 10, "#6b7280", anchorMiddle)
 	}
 }
 
 func trimLabel(label string, limit int) string {
-	if len(label) <= limit {
+	runes := []rune(label)
+	if len(runes) <= limit {
 		return label
 	}
 	if limit <= 3 {
-		return label[:limit]
+		return string(runes[:limit])
 	}
-	return label[:limit-3] + "..."
+	return string(runes[:limit-3]) + "..."
 }
 
 func maxValue(values []float64) float64 {
@@ -154,6 +472,57 @@ func formatNumber(val float64) string {
 	return fmt.Sprintf("%.1f", val)
 }
 
+type svgCanvas struct {
+	buf *bytes.Buffer
+}
+
+func (s *svgCanvas) rect(x, y, w, h float64, fill string, opacity float64) {
+	fmt.Fprintf(s.buf, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"%s/>", x, y, w, h, fill, svgOpacity(opacity))
+}
+
+func (s *svgCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	fmt.Fprintf(s.buf, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\" stroke-width=\"%s\"/>", x1, y1, x2, y2, stroke, formatNumber(width))
+}
+
+func (s *svgCanvas) polyline(points []point, stroke string, width float64) {
+	fmt.Fprintf(s.buf, "<path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>", svgPath(points), stroke, formatNumber(width))
+}
+
+func (s *svgCanvas) polygon(points []point, fill string, opacity float64) {
+	fmt.Fprintf(s.buf, "<path d=\"%s Z\" fill=\"%s\"%s/>", svgPath(points), fill, svgOpacity(opacity))
+}
+
+func (s *svgCanvas) circle(cx, cy, r float64, fill string) {
+	fmt.Fprintf(s.buf, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%s\" fill=\"%s\"/>", cx, cy, formatNumber(r), fill)
+}
+
+func (s *svgCanvas) text(x, y float64, label string, size float64, fill, anchor string) {
+	extra := ""
+	if anchor != anchorStart {
+		extra = fmt.Sprintf(" text-anchor=\"%s\"", anchor)
+	}
+	fmt.Fprintf(s.buf, "<text x=\"%.1f\" y=\"%.1f\" font-family=\"Arial, sans-serif\" font-size=\"%s\" fill=\"%s\"%s>%s</text>", x, y, formatNumber(size), fill, extra, escapeXML(label))
+}
+
+func svgPath(points []point) string {
+	var path strings.Builder
+	for i, p := range points {
+		if i == 0 {
+			path.WriteString(fmt.Sprintf("M %.1f %.1f", p.x, p.y))
+		} else {
+			path.WriteString(fmt.Sprintf(" L %.1f %.1f", p.x, p.y))
+		}
+	}
+	return path.String()
+}
+
+func svgOpacity(opacity float64) string {
+	if opacity >= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" fill-opacity=\"%g\"", opacity)
+}
+
 func escapeXML(val string) string {
 	replacer := strings.NewReplacer(
 		"&", "&amp;",
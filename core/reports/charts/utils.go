@@ -2,6 +2,7 @@ package charts
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -60,11 +61,17 @@ func getFloat(m map[string]any, key string) float64 {
 	if !ok {
 		return 0
 	}
+	return floatValue(val)
+}
+
+func floatValue(val any) float64 {
 	switch v := val.(type) {
 	case float64:
 		return v
 	case int:
 		return float64(v)
+	case int64:
+		return float64(v)
 	case string:
 		if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
 			return parsed
@@ -88,6 +95,9 @@ func getInt(m map[string]any, key string) *int {
 	case int:
 		iv := v
 		return &iv
+	case int64:
+		iv := int(v)
+		return &iv
 	case string:
 		if parsed, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
 			return &parsed
@@ -113,3 +123,26 @@ func getBool(m map[string]any, key string) bool {
 		return false
 	}
 }
+
+// anyList unpacks a slice of any element type, as stored in snapshot items
+// before (typed) and after (decoded JSON) a round trip.
+func anyList(val any) []any {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+func stringList(val any) []string {
+	items := anyList(val)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, strings.TrimSpace(fmt.Sprintf("%v", item)))
+	}
+	return out
+}
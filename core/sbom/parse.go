@@ -0,0 +1,227 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Supported SBOM formats.
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+var ErrFormatInvalid = errors.New("assets.sbom.formatInvalid")
+
+// Component is a software package listed in an SBOM.
+type Component struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+	Version string `json:"version"`
+	PURL    string `json:"purl,omitempty"`
+	CPE     string `json:"cpe,omitempty"`
+}
+
+type Document struct {
+	Format     string      `json:"format"`
+	Components []Component `json:"components"`
+}
+
+// Parse decodes a CycloneDX or SPDX JSON document; the format is detected from the content.
+func Parse(data []byte) (*Document, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		return parseCycloneDX(data)
+	case strings.HasPrefix(strings.ToUpper(probe.SPDXVersion), "SPDX-"):
+		return parseSPDX(data)
+	}
+	return nil, ErrFormatInvalid
+}
+
+type cdxComponent struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Publisher string `json:"publisher"`
+	Author    string `json:"author"`
+	Supplier  *struct {
+		Name string `json:"name"`
+	} `json:"supplier"`
+	PURL       string         `json:"purl"`
+	CPE        string         `json:"cpe"`
+	Components []cdxComponent `json:"components"`
+}
+
+func parseCycloneDX(data []byte) (*Document, error) {
+	var bom struct {
+		Components []cdxComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	doc := &Document{Format: FormatCycloneDX}
+	var walk func(items []cdxComponent)
+	walk = func(items []cdxComponent) {
+		for _, c := range items {
+			switch strings.ToLower(c.Type) {
+			case "file", "data", "device", "machine-learning-model":
+			default:
+				vendor := c.Publisher
+				if c.Supplier != nil && strings.TrimSpace(c.Supplier.Name) != "" {
+					vendor = c.Supplier.Name
+				}
+				if strings.TrimSpace(vendor) == "" {
+					vendor = firstNonEmpty(cpeVendor(c.CPE), c.Author, c.Group, purlNamespace(c.PURL))
+				}
+				doc.Components = append(doc.Components, Component{Name: c.Name, Vendor: vendor, Version: c.Version, PURL: c.PURL, CPE: c.CPE})
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	doc.Components = cleanComponents(doc.Components)
+	return doc, nil
+}
+
+func parseSPDX(data []byte) (*Document, error) {
+	var spdx struct {
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			Supplier     string `json:"supplier"`
+			Originator   string `json:"originator"`
+			ExternalRefs []struct {
+				Category string `json:"referenceCategory"`
+				Type     string `json:"referenceType"`
+				Locator  string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, ErrFormatInvalid
+	}
+	described := map[string]bool{}
+	if len(spdx.Packages) > 1 {
+		for _, id := range spdx.DocumentDescribes {
+			described[id] = true
+		}
+	}
+	doc := &Document{Format: FormatSPDX}
+	for _, p := range spdx.Packages {
+		if described[p.SPDXID] {
+			continue
+		}
+		c := Component{Name: p.Name, Version: p.VersionInfo}
+		for _, ref := range p.ExternalRefs {
+			switch strings.ToLower(ref.Type) {
+			case "purl":
+				c.PURL = ref.Locator
+			case "cpe23type", "cpe22type":
+				if c.CPE == "" {
+					c.CPE = ref.Locator
+				}
+			}
+		}
+		c.Vendor = firstNonEmpty(spdxParty(p.Supplier), spdxParty(p.Originator), cpeVendor(c.CPE), purlNamespace(c.PURL))
+		doc.Components = append(doc.Components, c)
+	}
+	doc.Components = cleanComponents(doc.Components)
+	return doc, nil
+}
+
+// cleanComponents trims fields and drops duplicates of the same vendor/name/version.
+func cleanComponents(in []Component) []Component {
+	seen := map[string]bool{}
+	out := make([]Component, 0, len(in))
+	for _, c := range in {
+		c.Name = strings.TrimSpace(c.Name)
+		c.Vendor = strings.TrimSpace(c.Vendor)
+		c.Version = strings.TrimSpace(c.Version)
+		c.PURL = strings.TrimSpace(c.PURL)
+		c.CPE = strings.TrimSpace(c.CPE)
+		if c.Name == "" || len(c.Name) > 200 {
+			continue
+		}
+		if len(c.Vendor) > 200 {
+			c.Vendor = ""
+		}
+		key := NormalizeVendor(c.Vendor) + "|" + normalizeName(c.Name) + "|" + strings.ToLower(c.Version)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// spdxParty strips the "Organization:" / "Person:" prefix; NOASSERTION means unknown.
+func spdxParty(raw string) string {
+	v := strings.TrimSpace(raw)
+	if strings.EqualFold(v, "NOASSERTION") {
+		return ""
+	}
+	for _, prefix := range []string{"Organization:", "Person:", "Tool:"} {
+		if strings.HasPrefix(v, prefix) {
+			v = strings.TrimSpace(v[len(prefix):])
+			break
+		}
+	}
+	if idx := strings.Index(v, " ("); idx > 0 {
+		v = v[:idx]
+	}
+	return v
+}
+
+func cpeVendor(cpe string) string {
+	parts := strings.Split(strings.TrimSpace(cpe), ":")
+	switch {
+	case len(parts) > 3 && parts[1] == "2.3":
+		if parts[3] != "*" && parts[3] != "-" {
+			return parts[3]
+		}
+	case len(parts) > 2 && strings.HasPrefix(parts[1], "/"):
+		return parts[2]
+	}
+	return ""
+}
+
+// purlNamespace returns the namespace of a package URL, e.g. "org.apache.logging.log4j"
+// for "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1".
+func purlNamespace(purl string) string {
+	v := strings.TrimSpace(purl)
+	if !strings.HasPrefix(v, "pkg:") {
+		return ""
+	}
+	v = v[len("pkg:"):]
+	if idx := strings.IndexAny(v, "@?#"); idx >= 0 {
+		v = v[:idx]
+	}
+	parts := strings.Split(v, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.Join(parts[1:len(parts)-1], "/")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
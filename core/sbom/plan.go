@@ -0,0 +1,296 @@
+package sbom
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Source is the installation source recorded for SBOM-managed software.
+const Source = "sbom"
+
+// Plan actions.
+const (
+	ActionAdd       = "add"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionArchive   = "archive"
+)
+
+type PlanItem struct {
+	Action          string `json:"action"`
+	Name            string `json:"name"`
+	Vendor          string `json:"vendor"`
+	Version         string `json:"version"`
+	PURL            string `json:"purl,omitempty"`
+	ProductID       int64  `json:"product_id,omitempty"`
+	NewProduct      bool   `json:"new_product"`
+	NewVersion      bool   `json:"new_version"`
+	InstallationID  int64  `json:"installation_id,omitempty"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+
+	installedAt *time.Time
+}
+
+// Plan is the diff between an SBOM and the current software inventory of an asset.
+type Plan struct {
+	Format      string     `json:"format"`
+	Components  int        `json:"components"`
+	Skipped     int        `json:"skipped"`
+	Added       int        `json:"added"`
+	Updated     int        `json:"updated"`
+	Unchanged   int        `json:"unchanged"`
+	Archived    int        `json:"archived"`
+	NewProducts int        `json:"new_products"`
+	NewVersions int        `json:"new_versions"`
+	Applied     bool       `json:"applied"`
+	Items       []PlanItem `json:"items"`
+}
+
+// BuildPlan compares the SBOM components with the asset inventory without changing anything.
+// Products are matched by name and normalized vendor, so "Apache Software Foundation" and
+// "The Apache Software Foundation, Inc." resolve to the same product. Only installations with
+// source "sbom" are updated or archived; manually entered software is left alone.
+func BuildPlan(ctx context.Context, sw store.SoftwareStore, assetID int64, doc *Document) (*Plan, error) {
+	products, err := listAllProducts(ctx, sw)
+	if err != nil {
+		return nil, err
+	}
+	installs, err := sw.ListAssetSoftware(ctx, assetID, false)
+	if err != nil {
+		return nil, err
+	}
+	idx := newProductIndex(products)
+	versions := map[int64]map[string]bool{}
+	versionExists := func(productID int64, version string) (bool, error) {
+		known, ok := versions[productID]
+		if !ok {
+			list, err := sw.ListVersions(ctx, productID, false)
+			if err != nil {
+				return false, err
+			}
+			known = map[string]bool{}
+			for _, v := range list {
+				known[strings.ToLower(strings.TrimSpace(v.Version))] = true
+			}
+			versions[productID] = known
+		}
+		return known[strings.ToLower(version)], nil
+	}
+	byProduct := map[int64][]store.AssetSoftwareInstallation{}
+	for _, inst := range installs {
+		byProduct[inst.ProductID] = append(byProduct[inst.ProductID], inst)
+	}
+	claimed := map[int64]bool{}
+	newProducts := map[string]bool{}
+	newVersions := map[string]bool{}
+
+	plan := &Plan{Format: doc.Format, Components: len(doc.Components), Items: []PlanItem{}}
+	for _, c := range doc.Components {
+		if c.Version == "" {
+			plan.Skipped++
+			continue
+		}
+		item := PlanItem{Name: c.Name, Vendor: idx.canonicalVendor(c.Vendor), Version: c.Version, PURL: c.PURL}
+		if p := idx.find(c.Name, c.Vendor); p != nil {
+			item.ProductID = p.ID
+			item.Name = p.Name
+			item.Vendor = p.Vendor
+			exists, err := versionExists(p.ID, c.Version)
+			if err != nil {
+				return nil, err
+			}
+			item.NewVersion = !exists
+		} else {
+			item.NewProduct = true
+			item.NewVersion = true
+			idx.remember(item.Vendor)
+		}
+		key := productKey(item.Name, item.Vendor)
+		if item.NewProduct && !newProducts[key] {
+			newProducts[key] = true
+			plan.NewProducts++
+		}
+		if item.NewVersion && !newVersions[key+"|"+strings.ToLower(c.Version)] {
+			newVersions[key+"|"+strings.ToLower(c.Version)] = true
+			plan.NewVersions++
+		}
+		item.Action = ActionAdd
+		if item.ProductID > 0 {
+			if inst := pickInstallation(byProduct[item.ProductID], claimed, c.Version); inst != nil {
+				claimed[inst.ID] = true
+				item.InstallationID = inst.ID
+				item.installedAt = inst.InstalledAt
+				if strings.EqualFold(installedVersion(*inst), c.Version) {
+					item.Action = ActionUnchanged
+				} else {
+					item.Action = ActionUpdate
+					item.PreviousVersion = installedVersion(*inst)
+				}
+			}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	for _, inst := range installs {
+		if claimed[inst.ID] || inst.Source != Source {
+			continue
+		}
+		plan.Items = append(plan.Items, PlanItem{
+			Action:          ActionArchive,
+			Name:            inst.ProductName,
+			Vendor:          inst.ProductVendor,
+			Version:         installedVersion(inst),
+			ProductID:       inst.ProductID,
+			InstallationID:  inst.ID,
+			PreviousVersion: installedVersion(inst),
+		})
+	}
+	for _, item := range plan.Items {
+		switch item.Action {
+		case ActionAdd:
+			plan.Added++
+		case ActionUpdate:
+			plan.Updated++
+		case ActionUnchanged:
+			plan.Unchanged++
+		case ActionArchive:
+			plan.Archived++
+		}
+	}
+	return plan, nil
+}
+
+// Apply executes a plan built by BuildPlan for the asset in one transaction: either every item is
+// applied or nothing is, and the plan items only get the new product and installation ids on success.
+func Apply(ctx context.Context, sw store.SoftwareStore, assetID int64, plan *Plan, userID int64) error {
+	items := append([]PlanItem(nil), plan.Items...)
+	err := sw.WithTx(ctx, func(tx store.SoftwareStore) error {
+		return applyItems(ctx, tx, assetID, items, userID)
+	})
+	if err != nil {
+		return err
+	}
+	plan.Items = items
+	plan.Applied = true
+	return nil
+}
+
+func applyItems(ctx context.Context, sw store.SoftwareStore, assetID int64, items []PlanItem, userID int64) error {
+	createdProducts := map[string]int64{}
+	versionIDs := map[int64]map[string]int64{}
+	versionID := func(productID int64, version string) (int64, error) {
+		known, ok := versionIDs[productID]
+		if !ok {
+			list, err := sw.ListVersions(ctx, productID, false)
+			if err != nil {
+				return 0, err
+			}
+			known = map[string]int64{}
+			for _, v := range list {
+				known[strings.ToLower(strings.TrimSpace(v.Version))] = v.ID
+			}
+			versionIDs[productID] = known
+		}
+		if id, ok := known[strings.ToLower(version)]; ok {
+			return id, nil
+		}
+		id, err := sw.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: version, CreatedBy: &userID, UpdatedBy: &userID})
+		if err != nil {
+			return 0, err
+		}
+		known[strings.ToLower(version)] = id
+		return id, nil
+	}
+	for i := range items {
+		item := &items[i]
+		switch item.Action {
+		case ActionArchive:
+			if err := sw.ArchiveAssetSoftware(ctx, item.InstallationID, userID); err != nil {
+				return err
+			}
+			continue
+		case ActionUnchanged:
+			continue
+		}
+		if item.ProductID == 0 {
+			key := productKey(item.Name, item.Vendor)
+			id, ok := createdProducts[key]
+			if !ok {
+				var err error
+				id, err = sw.CreateProduct(ctx, &store.SoftwareProduct{Name: item.Name, Vendor: item.Vendor, CreatedBy: &userID, UpdatedBy: &userID})
+				if err != nil {
+					return err
+				}
+				createdProducts[key] = id
+			}
+			item.ProductID = id
+		}
+		vid, err := versionID(item.ProductID, item.Version)
+		if err != nil {
+			return err
+		}
+		inst := &store.AssetSoftwareInstallation{
+			AssetID:     assetID,
+			ProductID:   item.ProductID,
+			VersionID:   &vid,
+			VersionText: item.Version,
+			Source:      Source,
+			Notes:       item.PURL,
+			UpdatedBy:   &userID,
+		}
+		if item.Action == ActionUpdate {
+			inst.ID = item.InstallationID
+			inst.InstalledAt = item.installedAt
+			if err := sw.UpdateAssetSoftware(ctx, inst); err != nil {
+				return err
+			}
+			continue
+		}
+		inst.CreatedBy = &userID
+		id, err := sw.AddAssetSoftware(ctx, inst)
+		if err != nil {
+			return err
+		}
+		item.InstallationID = id
+	}
+	return nil
+}
+
+// pickInstallation prefers an installation of the exact version, then any other SBOM-managed one.
+func pickInstallation(installs []store.AssetSoftwareInstallation, claimed map[int64]bool, version string) *store.AssetSoftwareInstallation {
+	for i := range installs {
+		if !claimed[installs[i].ID] && strings.EqualFold(installedVersion(installs[i]), version) {
+			return &installs[i]
+		}
+	}
+	for i := range installs {
+		if !claimed[installs[i].ID] && installs[i].Source == Source {
+			return &installs[i]
+		}
+	}
+	return nil
+}
+
+func installedVersion(inst store.AssetSoftwareInstallation) string {
+	if inst.VersionLabel != "" {
+		return inst.VersionLabel
+	}
+	return inst.VersionText
+}
+
+func listAllProducts(ctx context.Context, sw store.SoftwareStore) ([]store.SoftwareProduct, error) {
+	const page = 500
+	var out []store.SoftwareProduct
+	for offset := 0; ; offset += page {
+		items, err := sw.ListProducts(ctx, store.SoftwareFilter{Limit: page, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) < page {
+			return out, nil
+		}
+	}
+}
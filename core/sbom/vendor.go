@@ -0,0 +1,101 @@
+package sbom
+
+import (
+	"strings"
+	"unicode"
+
+	"berkut-scc/core/store"
+)
+
+var corporateSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true, "corp": true,
+	"corporation": true, "co": true, "company": true, "gmbh": true, "ag": true, "sa": true,
+	"bv": true, "plc": true, "oy": true, "ab": true, "srl": true, "ooo": true, "ооо": true, "ао": true,
+}
+
+// NormalizeVendor reduces a vendor name to a comparison key: lowercase words without
+// punctuation, a leading "the" or trailing corporate suffixes ("Microsoft Corporation" -> "microsoft").
+func NormalizeVendor(vendor string) string {
+	words := strings.FieldsFunc(strings.ToLower(vendor), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	for len(words) > 1 && corporateSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func productKey(name, vendor string) string {
+	return NormalizeVendor(vendor) + "|" + normalizeName(name)
+}
+
+type productIndex struct {
+	byKey   map[string]*store.SoftwareProduct
+	byName  map[string][]*store.SoftwareProduct
+	vendors map[string]string
+}
+
+func newProductIndex(products []store.SoftwareProduct) *productIndex {
+	idx := &productIndex{byKey: map[string]*store.SoftwareProduct{}, byName: map[string][]*store.SoftwareProduct{}, vendors: map[string]string{}}
+	for i := range products {
+		p := &products[i]
+		key := productKey(p.Name, p.Vendor)
+		if _, ok := idx.byKey[key]; !ok {
+			idx.byKey[key] = p
+		}
+		idx.byName[normalizeName(p.Name)] = append(idx.byName[normalizeName(p.Name)], p)
+		idx.remember(p.Vendor)
+	}
+	return idx
+}
+
+// find returns the existing product for a component. A missing vendor on either side
+// matches when the name alone is unambiguous.
+func (idx *productIndex) find(name, vendor string) *store.SoftwareProduct {
+	if p, ok := idx.byKey[productKey(name, vendor)]; ok {
+		return p
+	}
+	candidates := idx.byName[normalizeName(name)]
+	if NormalizeVendor(vendor) == "" {
+		if len(candidates) == 1 {
+			return candidates[0]
+		}
+		return nil
+	}
+	var found *store.SoftwareProduct
+	for _, p := range candidates {
+		if NormalizeVendor(p.Vendor) != "" {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = p
+	}
+	return found
+}
+
+// canonicalVendor returns the spelling already used in the catalog for an equivalent vendor.
+func (idx *productIndex) canonicalVendor(vendor string) string {
+	if v, ok := idx.vendors[NormalizeVendor(vendor)]; ok {
+		return v
+	}
+	return strings.TrimSpace(vendor)
+}
+
+func (idx *productIndex) remember(vendor string) {
+	key := NormalizeVendor(vendor)
+	if key == "" {
+		return
+	}
+	if _, ok := idx.vendors[key]; !ok {
+		idx.vendors[key] = strings.TrimSpace(vendor)
+	}
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrCalendarNotFound = errors.New("schedule.error.calendarNotFound")
+
+// DateLayout is the format of holiday dates.
+const DateLayout = "2006-01-02"
+
+// Calendar tells business days from weekends and holidays. A nil calendar treats
+// Saturday and Sunday as the only non-business days.
+type Calendar struct {
+	Weekend  map[time.Weekday]bool
+	Holidays map[string]string
+}
+
+// CalendarSource loads business calendars by id.
+type CalendarSource interface {
+	BusinessCalendar(ctx context.Context, id int64) (*Calendar, error)
+}
+
+// DefaultWeekend is Saturday and Sunday.
+var DefaultWeekend = []time.Weekday{time.Saturday, time.Sunday}
+
+// NewCalendar builds a calendar from weekend days and holidays keyed by DateLayout dates.
+func NewCalendar(weekend []time.Weekday, holidays map[string]string) *Calendar {
+	c := &Calendar{Weekend: map[time.Weekday]bool{}, Holidays: map[string]string{}}
+	for _, wd := range weekend {
+		c.Weekend[wd] = true
+	}
+	for day, name := range holidays {
+		c.Holidays[day] = name
+	}
+	return c
+}
+
+// IsBusinessDay reports whether the date of t, in its own location, is a working day.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if c == nil {
+		return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+	}
+	if c.Weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.Holidays[t.Format(DateLayout)]
+	return !holiday
+}
+
+// Holiday returns the holiday name of the date of t.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	name, ok := c.Holidays[t.Format(DateLayout)]
+	return name, ok
+}
+
+// AddBusinessDays moves t forward by days business days keeping the wall clock time.
+// A start on a non-business day counts from the next business day.
+func (c *Calendar) AddBusinessDays(t time.Time, days int) time.Time {
+	if days <= 0 {
+		return t
+	}
+	cur := t
+	// Calendars without any business day would never finish.
+	for guard := 0; days > 0 && guard < days*7+366; guard++ {
+		cur = cur.AddDate(0, 0, 1)
+		if c.IsBusinessDay(cur) {
+			days--
+		}
+	}
+	return cur
+}
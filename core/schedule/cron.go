@@ -0,0 +1,107 @@
+package schedule
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("schedule.error.cron")
+
+// CronToRRule converts a five-field cron expression (minute hour day-of-month month
+// day-of-week) to an equivalent daily RRULE. Fields accept *, numbers, ranges, lists
+// and steps; day-of-week 7 is Sunday. Cron matches either day field when both are
+// restricted, which a single RRULE cannot express, so such expressions are rejected.
+func CronToRRule(expr string) (string, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return "", ErrInvalidCron
+	}
+	minutes, err := parseCronField(fields[0], 0, 59, true)
+	if err != nil {
+		return "", err
+	}
+	hours, err := parseCronField(fields[1], 0, 23, true)
+	if err != nil {
+		return "", err
+	}
+	monthDays, err := parseCronField(fields[2], 1, 31, false)
+	if err != nil {
+		return "", err
+	}
+	months, err := parseCronField(fields[3], 1, 12, false)
+	if err != nil {
+		return "", err
+	}
+	weekdays, err := parseCronField(fields[4], 0, 7, false)
+	if err != nil {
+		return "", err
+	}
+	if len(monthDays) > 0 && len(weekdays) > 0 {
+		return "", ErrInvalidCron
+	}
+	rule := &RRule{Freq: FreqDaily, Interval: 1, WeekStart: time.Monday, ByMonth: months, ByMonthDay: monthDays, ByHour: hours, ByMinute: minutes}
+	seen := map[int]bool{}
+	for _, wd := range weekdays {
+		wd %= 7
+		if !seen[wd] {
+			seen[wd] = true
+			rule.ByDay = append(rule.ByDay, WeekdayNum{Day: time.Weekday(wd)})
+		}
+	}
+	return rule.String(), nil
+}
+
+// parseCronField returns the sorted values of a cron field; a bare * yields nil
+// unless expand asks for every value.
+func parseCronField(field string, minV, maxV int, expand bool) ([]int, error) {
+	if field == "*" && !expand {
+		return nil, nil
+	}
+	set := make([]bool, maxV+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, ErrInvalidCron
+			}
+			rng, step = part[:i], n
+		}
+		lo, hi := minV, maxV
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil || a > b {
+				return nil, ErrInvalidCron
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return nil, ErrInvalidCron
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = maxV
+			}
+		}
+		if lo < minV || hi > maxV {
+			return nil, ErrInvalidCron
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	var out []int
+	for v := minV; v <= maxV; v++ {
+		if set[v] {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
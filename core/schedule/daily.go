@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/utils"
+)
+
+// Daily runs a job at most once per UTC day. The day is checked every
+// scheduler interval, so a job that fails is retried on the next tick.
+type Daily struct {
+	name   string
+	cfg    config.SchedulerConfig
+	run    func(ctx context.Context, now time.Time) error
+	logger *utils.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	running bool
+	wg      sync.WaitGroup
+	lastDay time.Time
+}
+
+// NewDaily creates a daily job; name is used in error logs.
+func NewDaily(name string, cfg config.SchedulerConfig, run func(ctx context.Context, now time.Time) error, logger *utils.Logger) *Daily {
+	return &Daily{name: name, cfg: cfg, run: run, logger: logger}
+}
+
+func (d *Daily) StartWithContext(ctx context.Context) {
+	if d == nil || d.run == nil || !d.cfg.Enabled {
+		return
+	}
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	d.cancel = cancel
+	d.running = true
+	d.wg.Add(1)
+	d.mu.Unlock()
+
+	interval := time.Duration(d.cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer d.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.RunOnce(runCtx, time.Now().UTC()); err != nil && d.logger != nil {
+					d.logger.Errorf("scheduler %s: %v", d.name, err)
+				}
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (d *Daily) StopWithContext(ctx context.Context) error {
+	if d == nil || !d.cfg.Enabled {
+		return nil
+	}
+	d.mu.Lock()
+	cancel := d.cancel
+	d.cancel = nil
+	wasRunning := d.running
+	d.mu.Unlock()
+	if !wasRunning || cancel == nil {
+		return nil
+	}
+	cancel()
+	waitDone := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+		d.mu.Lock()
+		d.running = false
+		d.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce runs the job when it has not succeeded yet on now's UTC day.
+func (d *Daily) RunOnce(ctx context.Context, now time.Time) error {
+	if d == nil || d.run == nil {
+		return nil
+	}
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	d.mu.Lock()
+	due := !d.lastDay.Equal(day)
+	d.mu.Unlock()
+	if !due {
+		return nil
+	}
+	if err := d.run(ctx, now); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.lastDay = day
+	d.mu.Unlock()
+	return nil
+}
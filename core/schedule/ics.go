@@ -0,0 +1,167 @@
+package schedule
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidICS = errors.New("schedule.error.ics")
+
+const (
+	maxICSLine     = 64 * 1024
+	maxHolidayDays = 366
+)
+
+// Holiday is a named non-business date.
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+type icsEvent struct {
+	start   string
+	end     string
+	summary string
+	rrule   string
+	exdates []string
+}
+
+// ParseICS reads all-day and timed events of an iCalendar file as holidays. Multi-day
+// events cover every date up to DTEND; recurring events are expanded up to until.
+func ParseICS(r io.Reader, until time.Time) ([]Holiday, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []icsEvent
+	var cur *icsEvent
+	calendar := false
+	for _, line := range lines {
+		name, value := splitICSLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			calendar = true
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			cur = &icsEvent{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if cur != nil {
+				events = append(events, *cur)
+			}
+			cur = nil
+		case cur == nil:
+		case name == "DTSTART":
+			cur.start = icsDateValue(value)
+		case name == "DTEND":
+			cur.end = icsDateValue(value)
+		case name == "SUMMARY":
+			cur.summary = unescapeICSText(value)
+		case name == "RRULE":
+			cur.rrule = value
+		case name == "EXDATE":
+			for _, item := range strings.Split(value, ",") {
+				cur.exdates = append(cur.exdates, icsDateValue(item))
+			}
+		}
+	}
+	if !calendar {
+		return nil, ErrInvalidICS
+	}
+	byDate := map[string]string{}
+	for _, ev := range events {
+		days, err := ev.dates(until)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range days {
+			if _, ok := byDate[d]; !ok {
+				byDate[d] = ev.summary
+			}
+		}
+	}
+	out := make([]Holiday, 0, len(byDate))
+	for d, name := range byDate {
+		out = append(out, Holiday{Date: d, Name: name})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out, nil
+}
+
+func (ev icsEvent) dates(until time.Time) ([]string, error) {
+	start, err := time.Parse("20060102", ev.start)
+	if err != nil {
+		return nil, ErrInvalidICS
+	}
+	span := 1
+	if ev.end != "" {
+		end, err := time.Parse("20060102", ev.end)
+		if err != nil {
+			return nil, ErrInvalidICS
+		}
+		span = min(max(int(end.Sub(start).Hours()/24), 1), maxHolidayDays)
+	}
+	starts := []time.Time{start}
+	if ev.rrule != "" {
+		rr, err := ParseRRule(ev.rrule)
+		if err != nil {
+			return nil, ErrInvalidICS
+		}
+		rule := &Rule{Start: start, RRule: rr}
+		for _, ex := range ev.exdates {
+			if d, err := time.Parse("20060102", ex); err == nil {
+				rule.ExDates = append(rule.ExDates, ExDate{At: d, AllDay: true})
+			}
+		}
+		starts = rule.Between(start, until)
+	}
+	var out []string
+	for _, s := range starts {
+		for i := 0; i < span; i++ {
+			out = append(out, s.AddDate(0, 0, i).Format(DateLayout))
+		}
+	}
+	return out, nil
+}
+
+func unfoldICS(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), maxICSLine)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, ErrInvalidICS
+	}
+	return lines, nil
+}
+
+// splitICSLine splits "NAME;PARAM=V:value" into its name and value.
+func splitICSLine(line string) (string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	name, _, _ := strings.Cut(head, ";")
+	return strings.ToUpper(strings.TrimSpace(name)), strings.TrimSpace(value)
+}
+
+// icsDateValue returns the YYYYMMDD date of a DATE or DATE-TIME value.
+func icsDateValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 8 {
+		return value[:8]
+	}
+	return value
+}
+
+func unescapeICSText(val string) string {
+	return strings.TrimSpace(strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(val))
+}
@@ -0,0 +1,638 @@
+package schedule
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies of RFC 5545. SECONDLY is not supported.
+const (
+	FreqYearly   = "YEARLY"
+	FreqMonthly  = "MONTHLY"
+	FreqWeekly   = "WEEKLY"
+	FreqDaily    = "DAILY"
+	FreqHourly   = "HOURLY"
+	FreqMinutely = "MINUTELY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal inside the
+// month or year (1MO is the first Monday, -1FR the last Friday, 0 means every).
+type WeekdayNum struct {
+	Day time.Weekday
+	N   int
+}
+
+// RRule is a parsed RFC 5545 recurrence rule.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByMonth    []int
+	ByWeekNo   []int
+	ByYearDay  []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	// untilFloating marks an UNTIL without a zone, read as wall clock of the rule location.
+	untilFloating bool
+	untilRaw      string
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRRule parses the value of an RRULE property, with or without the "RRULE:" prefix.
+func ParseRRule(raw string) (*RRule, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 6 && strings.EqualFold(raw[:6], "RRULE:") {
+		raw = raw[6:]
+	}
+	if raw == "" {
+		return nil, ErrInvalidRRule
+	}
+	r := &RRule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, ErrInvalidRRule
+		}
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		val := strings.ToUpper(strings.TrimSpace(kv[1]))
+		if seen[key] || val == "" {
+			return nil, ErrInvalidRRule
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			switch val {
+			case FreqYearly, FreqMonthly, FreqWeekly, FreqDaily, FreqHourly, FreqMinutely:
+				r.Freq = val
+			default:
+				return nil, ErrInvalidRRule
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err != nil || r.Interval < 1 {
+				return nil, ErrInvalidRRule
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err != nil || r.Count < 1 {
+				return nil, ErrInvalidRRule
+			}
+		case "UNTIL":
+			if err := r.parseUntil(val); err != nil {
+				return nil, err
+			}
+		case "BYMONTH":
+			r.ByMonth, err = parseIntList(val, 1, 12, false)
+		case "BYWEEKNO":
+			r.ByWeekNo, err = parseIntList(val, 1, 53, true)
+		case "BYYEARDAY":
+			r.ByYearDay, err = parseIntList(val, 1, 366, true)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(val, 1, 31, true)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(val)
+		case "BYHOUR":
+			r.ByHour, err = parseIntList(val, 0, 23, false)
+		case "BYMINUTE":
+			r.ByMinute, err = parseIntList(val, 0, 59, false)
+		case "BYSECOND":
+			r.BySecond, err = parseIntList(val, 0, 59, false)
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(val, 1, 366, true)
+		case "WKST":
+			wd, ok := weekdayCodes[val]
+			if !ok {
+				return nil, ErrInvalidRRule
+			}
+			r.WeekStart = wd
+		default:
+			return nil, ErrInvalidRRule
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RRule) validate() error {
+	if r.Freq == "" {
+		return ErrInvalidRRule
+	}
+	if r.Count > 0 && r.untilRaw != "" {
+		return ErrInvalidRRule
+	}
+	if len(r.ByWeekNo) > 0 && r.Freq != FreqYearly {
+		return ErrInvalidRRule
+	}
+	if len(r.ByYearDay) > 0 && (r.Freq == FreqMonthly || r.Freq == FreqWeekly) {
+		return ErrInvalidRRule
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == FreqWeekly {
+		return ErrInvalidRRule
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != FreqMonthly && r.Freq != FreqYearly {
+			return ErrInvalidRRule
+		}
+		if d.N != 0 && r.Freq == FreqYearly && len(r.ByWeekNo) > 0 {
+			return ErrInvalidRRule
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByMonth)+len(r.ByWeekNo)+len(r.ByYearDay)+len(r.ByMonthDay)+len(r.ByDay)+len(r.ByHour)+len(r.ByMinute)+len(r.BySecond) == 0 {
+		return ErrInvalidRRule
+	}
+	return nil
+}
+
+func (r *RRule) parseUntil(val string) error {
+	r.untilRaw = val
+	layouts := []struct {
+		layout   string
+		floating bool
+		endOfDay bool
+	}{
+		{"20060102T150405Z", false, false},
+		{"20060102T150405", true, false},
+		{"20060102", true, true},
+	}
+	for _, l := range layouts {
+		t, err := time.Parse(l.layout, val)
+		if err != nil {
+			continue
+		}
+		if l.endOfDay {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		r.Until = t
+		r.untilFloating = l.floating
+		return nil
+	}
+	return ErrInvalidRRule
+}
+
+func parseIntList(val string, minV, maxV int, allowNegative bool) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(item), "+"))
+		if err != nil {
+			return nil, ErrInvalidRRule
+		}
+		abs := n
+		if n < 0 {
+			if !allowNegative {
+				return nil, ErrInvalidRRule
+			}
+			abs = -n
+		}
+		if abs < minV || abs > maxV || (n == 0 && minV > 0) {
+			return nil, ErrInvalidRRule
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseByDay(val string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, ErrInvalidRRule
+		}
+		wd, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, ErrInvalidRRule
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, ErrInvalidRRule
+			}
+		}
+		out = append(out, WeekdayNum{Day: wd, N: n})
+	}
+	return out, nil
+}
+
+// String formats the rule in canonical RRULE value form.
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.untilRaw != "" {
+		parts = append(parts, "UNTIL="+r.untilRaw)
+	}
+	addInts := func(key string, vals []int) {
+		if len(vals) == 0 {
+			return
+		}
+		items := make([]string, len(vals))
+		for i, v := range vals {
+			items[i] = strconv.Itoa(v)
+		}
+		parts = append(parts, key+"="+strings.Join(items, ","))
+	}
+	addInts("BYMONTH", r.ByMonth)
+	addInts("BYWEEKNO", r.ByWeekNo)
+	addInts("BYYEARDAY", r.ByYearDay)
+	addInts("BYMONTHDAY", r.ByMonthDay)
+	if len(r.ByDay) > 0 {
+		items := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			items[i] = weekdayNames[d.Day]
+			if d.N != 0 {
+				items[i] = strconv.Itoa(d.N) + items[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+	addInts("BYHOUR", r.ByHour)
+	addInts("BYMINUTE", r.ByMinute)
+	addInts("BYSECOND", r.BySecond)
+	addInts("BYSETPOS", r.BySetPos)
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// until returns the inclusive end of the rule in loc, or zero when unbounded.
+func (r *RRule) until(loc *time.Location) time.Time {
+	if r.Until.IsZero() || !r.untilFloating {
+		return r.Until
+	}
+	u := r.Until
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+}
+
+// periodSet returns the sorted occurrences of period k counted from start, before
+// COUNT, UNTIL and the DTSTART lower bound are applied.
+func (r *RRule) periodSet(start time.Time, k int) []time.Time {
+	loc := start.Location()
+	var set []time.Time
+	switch r.Freq {
+	case FreqHourly, FreqMinutely:
+		step := time.Hour
+		if r.Freq == FreqMinutely {
+			step = time.Minute
+		}
+		t := start.Add(time.Duration(k*r.Interval) * step)
+		if !r.dayAllowed(t, true) || !containsInt(r.ByHour, t.Hour(), true) {
+			return nil
+		}
+		minutes := []int{t.Minute()}
+		if r.Freq == FreqHourly && len(r.ByMinute) > 0 {
+			minutes = r.ByMinute
+		} else if r.Freq == FreqMinutely && !containsInt(r.ByMinute, t.Minute(), true) {
+			return nil
+		}
+		seconds := orDefault(r.BySecond, t.Second())
+		for _, mi := range minutes {
+			for _, s := range seconds {
+				set = append(set, time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), mi, s, 0, loc))
+			}
+		}
+	default:
+		hours := orDefault(r.ByHour, start.Hour())
+		minutes := orDefault(r.ByMinute, start.Minute())
+		seconds := orDefault(r.BySecond, start.Second())
+		for _, day := range r.periodDays(start, k) {
+			for _, h := range hours {
+				for _, mi := range minutes {
+					for _, s := range seconds {
+						set = append(set, time.Date(day.Year(), day.Month(), day.Day(), h, mi, s, 0, loc))
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Before(set[j]) })
+	set = dedupeTimes(set)
+	if len(r.BySetPos) == 0 {
+		return set
+	}
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(set) + pos
+		}
+		if idx >= 0 && idx < len(set) {
+			out = append(out, set[idx])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupeTimes(out)
+}
+
+// periodDays returns the dates (at midnight in the start location) of period k for
+// day-based frequencies.
+func (r *RRule) periodDays(start time.Time, k int) []time.Time {
+	loc := start.Location()
+	var days []time.Time
+	switch r.Freq {
+	case FreqYearly:
+		year := start.Year() + k*r.Interval
+		days = r.yearDays(start, year)
+	case FreqMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(k*r.Interval), 1, 0, 0, 0, 0, loc)
+		if !containsInt(r.ByMonth, int(first.Month()), true) {
+			return nil
+		}
+		days = r.monthDays(start, first.Year(), first.Month())
+	case FreqWeekly:
+		day := midnight(start)
+		offset := (int(day.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := day.AddDate(0, 0, -offset+7*k*r.Interval)
+		for i := 0; i < 7; i++ {
+			d := weekStart.AddDate(0, 0, i)
+			if len(r.ByDay) > 0 {
+				if !weekdayIn(r.ByDay, d.Weekday()) {
+					continue
+				}
+			} else if d.Weekday() != start.Weekday() {
+				continue
+			}
+			if containsInt(r.ByMonth, int(d.Month()), true) {
+				days = append(days, d)
+			}
+		}
+	case FreqDaily:
+		d := midnight(start).AddDate(0, 0, k*r.Interval)
+		if r.dayAllowed(d, true) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+func (r *RRule) yearDays(start time.Time, year int) []time.Time {
+	loc := start.Location()
+	var days []time.Time
+	switch {
+	case len(r.ByWeekNo) > 0:
+		for _, wn := range r.ByWeekNo {
+			first := weekNoStart(year, wn, r.WeekStart, loc)
+			if first.IsZero() {
+				continue
+			}
+			for i := 0; i < 7; i++ {
+				days = append(days, first.AddDate(0, 0, i))
+			}
+		}
+		days = r.filterDays(days, true)
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	case len(r.ByYearDay) > 0:
+		length := daysInYear(year)
+		for _, yd := range r.ByYearDay {
+			if yd < 0 {
+				yd = length + yd + 1
+			}
+			if yd < 1 || yd > length {
+				continue
+			}
+			days = append(days, time.Date(year, 1, yd, 0, 0, 0, 0, loc))
+		}
+		days = r.filterDays(days, true)
+	case len(r.ByMonthDay) > 0 || (len(r.ByDay) > 0 && len(r.ByMonth) > 0):
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(start, year, time.Month(m))...)
+		}
+	case len(r.ByDay) > 0:
+		days = expandByDay(r.ByDay, time.Date(year, 1, 1, 0, 0, 0, 0, loc), daysInYear(year))
+	case len(r.ByMonth) > 0:
+		for _, m := range r.ByMonth {
+			if start.Day() <= daysInMonth(year, time.Month(m)) {
+				days = append(days, time.Date(year, time.Month(m), start.Day(), 0, 0, 0, 0, loc))
+			}
+		}
+	default:
+		if start.Day() <= daysInMonth(year, start.Month()) {
+			days = append(days, time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, loc))
+		}
+	}
+	return days
+}
+
+func (r *RRule) monthDays(start time.Time, year int, month time.Month) []time.Time {
+	loc := start.Location()
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	length := daysInMonth(year, month)
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		var allowed map[int]bool
+		if len(r.ByDay) > 0 {
+			allowed = map[int]bool{}
+			for _, d := range expandByDay(r.ByDay, first, length) {
+				allowed[d.Day()] = true
+			}
+		}
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = length + md + 1
+			}
+			if md < 1 || md > length || (allowed != nil && !allowed[md]) {
+				continue
+			}
+			days = append(days, first.AddDate(0, 0, md-1))
+		}
+	case len(r.ByDay) > 0:
+		days = expandByDay(r.ByDay, first, length)
+	default:
+		if start.Day() <= length {
+			days = append(days, first.AddDate(0, 0, start.Day()-1))
+		}
+	}
+	return days
+}
+
+// filterDays limits expanded days by BYMONTH, BYMONTHDAY and weekday-only BYDAY.
+func (r *RRule) filterDays(days []time.Time, withByDay bool) []time.Time {
+	var out []time.Time
+	for _, d := range days {
+		if !containsInt(r.ByMonth, int(d.Month()), true) || !monthDayMatches(r.ByMonthDay, d) {
+			continue
+		}
+		if withByDay && len(r.ByDay) > 0 && !weekdayIn(r.ByDay, d.Weekday()) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// dayAllowed applies the day-level BY rules as filters to a DAILY or finer period.
+func (r *RRule) dayAllowed(t time.Time, withByDay bool) bool {
+	if !containsInt(r.ByMonth, int(t.Month()), true) || !monthDayMatches(r.ByMonthDay, t) {
+		return false
+	}
+	if len(r.ByYearDay) > 0 {
+		length := daysInYear(t.Year())
+		ok := false
+		for _, yd := range r.ByYearDay {
+			if yd < 0 {
+				yd = length + yd + 1
+			}
+			if yd == t.YearDay() {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return !withByDay || len(r.ByDay) == 0 || weekdayIn(r.ByDay, t.Weekday())
+}
+
+// expandByDay returns the days of the span [first, first+length) matching BYDAY,
+// ordinals counted inside the span.
+func expandByDay(byDay []WeekdayNum, first time.Time, length int) []time.Time {
+	byWeekday := map[time.Weekday][]time.Time{}
+	for i := 0; i < length; i++ {
+		d := first.AddDate(0, 0, i)
+		byWeekday[d.Weekday()] = append(byWeekday[d.Weekday()], d)
+	}
+	var out []time.Time
+	for _, wd := range byDay {
+		list := byWeekday[wd.Day]
+		switch {
+		case wd.N == 0:
+			out = append(out, list...)
+		case wd.N > 0 && wd.N <= len(list):
+			out = append(out, list[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(list):
+			out = append(out, list[len(list)+wd.N])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// weekNoStart returns the first day of week wn of year; week 1 is the first week
+// with at least four days in the year.
+func weekNoStart(year, wn int, wkst time.Weekday, loc *time.Location) time.Time {
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	offset := (int(jan1.Weekday()) - int(wkst) + 7) % 7
+	week1 := jan1.AddDate(0, 0, -offset)
+	if offset > 3 {
+		week1 = week1.AddDate(0, 0, 7)
+	}
+	nextJan1 := time.Date(year+1, 1, 1, 0, 0, 0, 0, loc)
+	nextOffset := (int(nextJan1.Weekday()) - int(wkst) + 7) % 7
+	nextWeek1 := nextJan1.AddDate(0, 0, -nextOffset)
+	if nextOffset > 3 {
+		nextWeek1 = nextWeek1.AddDate(0, 0, 7)
+	}
+	weeks := int(nextWeek1.Sub(week1).Hours()/24+0.5) / 7
+	if wn < 0 {
+		wn = weeks + wn + 1
+	}
+	if wn < 1 || wn > weeks {
+		return time.Time{}
+	}
+	return week1.AddDate(0, 0, 7*(wn-1))
+}
+
+func monthDayMatches(list []int, t time.Time) bool {
+	if len(list) == 0 {
+		return true
+	}
+	length := daysInMonth(t.Year(), t.Month())
+	for _, md := range list {
+		if md < 0 {
+			md = length + md + 1
+		}
+		if md == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func weekdayIn(list []WeekdayNum, wd time.Weekday) bool {
+	for _, d := range list {
+		if d.Day == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, v int, emptyMatches bool) bool {
+	if len(list) == 0 {
+		return emptyMatches
+	}
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(list []int, def int) []int {
+	if len(list) == 0 {
+		return []int{def}
+	}
+	return list
+}
+
+func dedupeTimes(in []time.Time) []time.Time {
+	out := in[:0]
+	for i, t := range in {
+		if i > 0 && t.Equal(in[i-1]) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
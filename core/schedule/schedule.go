@@ -0,0 +1,361 @@
+// Package schedule computes occurrences of recurring schedules: RFC 5545 recurrence
+// rules evaluated in an IANA timezone, excluded dates and shifting of occurrences that
+// fall on weekends or holidays of a business calendar.
+package schedule
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRRule    = errors.New("schedule.error.rrule")
+	ErrInvalidStart    = errors.New("schedule.error.start")
+	ErrInvalidTimezone = errors.New("schedule.error.timezone")
+	ErrInvalidExDate   = errors.New("schedule.error.exdate")
+	ErrInvalidShift    = errors.New("schedule.error.shift")
+)
+
+// Shifts applied to occurrences that fall on non-business days.
+const (
+	ShiftNone                = ""
+	ShiftNextBusinessDay     = "next_business_day"
+	ShiftPreviousBusinessDay = "previous_business_day"
+	ShiftSkip                = "skip"
+)
+
+const (
+	// maxShiftDays bounds the search for a business day when shifting.
+	maxShiftDays = 31
+	// maxPeriods bounds the iteration of rules that rarely or never match.
+	maxPeriods = 200000
+	// MaxPreview is the largest number of occurrences returned by a preview.
+	MaxPreview = 100
+)
+
+// ExDate excludes an exact occurrence or, with AllDay, every occurrence of a date.
+type ExDate struct {
+	At     time.Time
+	AllDay bool
+}
+
+// Rule is a compiled schedule. A nil RRule means a single occurrence at Start.
+type Rule struct {
+	Start    time.Time
+	RRule    *RRule
+	ExDates  []ExDate
+	Shift    string
+	Calendar *Calendar
+}
+
+// ValidShift reports whether shift is a known shift mode.
+func ValidShift(shift string) bool {
+	switch shift {
+	case ShiftNone, ShiftNextBusinessDay, ShiftPreviousBusinessDay, ShiftSkip:
+		return true
+	}
+	return false
+}
+
+// LoadLocation resolves an IANA timezone name; empty means UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// ParseLocalTime reads a wall clock time in loc. RFC 3339 values keep their offset,
+// "2006-01-02T15:04[:05]" and "2006-01-02 15:04" are local, a bare date is midnight.
+func ParseLocalTime(raw string, loc *time.Location) (time.Time, bool, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.In(loc), false, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "20060102T150405"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, ErrInvalidStart
+}
+
+// ParseExDates parses excluded dates; bare dates exclude the whole day.
+func ParseExDates(raw []string, loc *time.Location) ([]ExDate, error) {
+	var out []ExDate
+	for _, item := range raw {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		t, allDay, err := ParseLocalTime(item, loc)
+		if err != nil {
+			return nil, ErrInvalidExDate
+		}
+		out = append(out, ExDate{At: t, AllDay: allDay})
+	}
+	return out, nil
+}
+
+// Next returns the first occurrence strictly after after.
+func (r *Rule) Next(after time.Time) (time.Time, bool) {
+	items := r.Occurrences(after, 1)
+	if len(items) == 0 {
+		return time.Time{}, false
+	}
+	return items[0], true
+}
+
+// Occurrences returns up to n occurrences strictly after after, in the rule location.
+func (r *Rule) Occurrences(after time.Time, n int) []time.Time {
+	if r == nil || n <= 0 || r.Start.IsZero() {
+		return nil
+	}
+	var out []time.Time
+	r.each(after, func(t time.Time) bool {
+		shifted, ok := r.shift(t)
+		if !ok || !shifted.After(after) {
+			return true
+		}
+		if len(out) > 0 && !shifted.After(out[len(out)-1]) {
+			return true
+		}
+		out = append(out, shifted)
+		return len(out) < n
+	})
+	return out
+}
+
+// Between returns the occurrences in [from, to).
+func (r *Rule) Between(from, to time.Time) []time.Time {
+	if r == nil || !to.After(from) || r.Start.IsZero() {
+		return nil
+	}
+	var out []time.Time
+	r.each(from.Add(-time.Nanosecond), func(t time.Time) bool {
+		shifted, ok := r.shift(t)
+		if !ok || shifted.Before(from) {
+			return true
+		}
+		if !shifted.Before(to) {
+			// Shifting keeps the order, nothing later can fall back into the range.
+			return r.Shift == ShiftPreviousBusinessDay && t.Before(to.AddDate(0, 0, maxShiftDays))
+		}
+		if len(out) > 0 && !shifted.After(out[len(out)-1]) {
+			return true
+		}
+		out = append(out, shifted)
+		return true
+	})
+	return out
+}
+
+// each calls fn for the raw occurrences, before shifting, that may shift past after,
+// in ascending order, until fn returns false.
+func (r *Rule) each(after time.Time, fn func(time.Time) bool) {
+	start := r.Start
+	if r.RRule == nil {
+		if !r.excluded(start) {
+			fn(start)
+		}
+		return
+	}
+	loc := start.Location()
+	until := r.RRule.until(loc)
+	k := 0
+	if r.RRule.Count == 0 {
+		k = r.skipPeriods(after.In(loc).AddDate(0, 0, -maxShiftDays-1))
+	}
+	emitted := 0
+	for i := 0; i < maxPeriods; i++ {
+		set := r.RRule.periodSet(start, k+i)
+		if r.periodPast(k+i, until) {
+			return
+		}
+		for _, t := range set {
+			if t.Before(start) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return
+			}
+			emitted++
+			if !r.excluded(t) && !fn(t) {
+				return
+			}
+			if r.RRule.Count > 0 && emitted >= r.RRule.Count {
+				return
+			}
+		}
+	}
+}
+
+// skipPeriods returns how many whole periods lie before target, so iteration of
+// unbounded rules can start near it.
+func (r *Rule) skipPeriods(target time.Time) int {
+	start := r.Start
+	if !target.After(start) {
+		return 0
+	}
+	interval := r.RRule.Interval
+	var periods int
+	switch r.RRule.Freq {
+	case FreqYearly:
+		periods = (target.Year() - start.Year()) / interval
+	case FreqMonthly:
+		months := (target.Year()-start.Year())*12 + int(target.Month()) - int(start.Month())
+		periods = months / interval
+	case FreqWeekly:
+		periods = int(midnightUTC(target).Sub(midnightUTC(start)).Hours()/24) / 7 / interval
+	case FreqDaily:
+		periods = int(midnightUTC(target).Sub(midnightUTC(start)).Hours()/24) / interval
+	case FreqHourly:
+		periods = int(target.Sub(start).Hours()) / interval
+	case FreqMinutely:
+		periods = int(target.Sub(start).Minutes()) / interval
+	}
+	return max(periods-1, 0)
+}
+
+// periodPast reports whether period k starts after until.
+func (r *Rule) periodPast(k int, until time.Time) bool {
+	if until.IsZero() {
+		return false
+	}
+	start := r.Start
+	var periodStart time.Time
+	switch r.RRule.Freq {
+	case FreqYearly:
+		periodStart = time.Date(start.Year()+k*r.RRule.Interval, 1, 1, 0, 0, 0, 0, start.Location())
+	case FreqMonthly:
+		periodStart = time.Date(start.Year(), start.Month()+time.Month(k*r.RRule.Interval), 1, 0, 0, 0, 0, start.Location())
+	case FreqWeekly:
+		periodStart = midnight(start).AddDate(0, 0, 7*k*r.RRule.Interval-7)
+	case FreqDaily:
+		periodStart = midnight(start).AddDate(0, 0, k*r.RRule.Interval)
+	case FreqHourly:
+		periodStart = start.Add(time.Duration(k*r.RRule.Interval) * time.Hour).Truncate(time.Hour)
+	default:
+		periodStart = start.Add(time.Duration(k*r.RRule.Interval) * time.Minute).Truncate(time.Minute)
+	}
+	return periodStart.After(until)
+}
+
+func (r *Rule) excluded(t time.Time) bool {
+	for _, ex := range r.ExDates {
+		if ex.AllDay {
+			at := ex.At.In(t.Location())
+			if at.Year() == t.Year() && at.YearDay() == t.YearDay() {
+				return true
+			}
+			continue
+		}
+		if ex.At.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// shift moves t according to the shift mode; false drops the occurrence.
+func (r *Rule) shift(t time.Time) (time.Time, bool) {
+	if r.Shift == ShiftNone || r.Calendar.IsBusinessDay(t) {
+		return t, true
+	}
+	step := 1
+	switch r.Shift {
+	case ShiftPreviousBusinessDay:
+		step = -1
+	case ShiftNextBusinessDay:
+	default:
+		return time.Time{}, false
+	}
+	for i := 1; i <= maxShiftDays; i++ {
+		d := t.AddDate(0, 0, step*i)
+		if r.Calendar.IsBusinessDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func midnightUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Spec is the stored form of a schedule shared by the modules that recur.
+type Spec struct {
+	Start      string   `json:"start,omitempty"`
+	RRule      string   `json:"rrule,omitempty"`
+	ExDates    []string `json:"exdates,omitempty"`
+	Timezone   string   `json:"timezone,omitempty"`
+	Shift      string   `json:"shift,omitempty"`
+	CalendarID int64    `json:"calendar_id,omitempty"`
+}
+
+// Normalize trims the spec and checks it can be compiled; the RRULE is rewritten in
+// canonical form.
+func (s Spec) Normalize() (Spec, error) {
+	s.Start = strings.TrimSpace(s.Start)
+	s.Timezone = strings.TrimSpace(s.Timezone)
+	s.Shift = strings.ToLower(strings.TrimSpace(s.Shift))
+	if s.Shift == "none" {
+		s.Shift = ShiftNone
+	}
+	if s.CalendarID < 0 {
+		s.CalendarID = 0
+	}
+	if _, err := s.Compile(nil); err != nil {
+		return s, err
+	}
+	if strings.TrimSpace(s.RRule) != "" {
+		rr, _ := ParseRRule(s.RRule)
+		s.RRule = rr.String()
+	}
+	var exdates []string
+	for _, item := range s.ExDates {
+		if item = strings.TrimSpace(item); item != "" {
+			exdates = append(exdates, item)
+		}
+	}
+	sort.Strings(exdates)
+	s.ExDates = exdates
+	return s, nil
+}
+
+// Compile builds the rule; cal is the business calendar used for shifting, nil means
+// weekends only.
+func (s Spec) Compile(cal *Calendar) (*Rule, error) {
+	loc, err := LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	start, _, err := ParseLocalTime(s.Start, loc)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidShift(s.Shift) {
+		return nil, ErrInvalidShift
+	}
+	rule := &Rule{Start: start, Shift: s.Shift, Calendar: cal}
+	if strings.TrimSpace(s.RRule) != "" {
+		if rule.RRule, err = ParseRRule(s.RRule); err != nil {
+			return nil, err
+		}
+	}
+	if rule.ExDates, err = ParseExDates(s.ExDates, loc); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
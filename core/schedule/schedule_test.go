@@ -0,0 +1,229 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/config"
+)
+
+func mustRule(t *testing.T, spec Spec, cal *Calendar) *Rule {
+	t.Helper()
+	rule, err := spec.Compile(cal)
+	if err != nil {
+		t.Fatalf("compile %+v: %v", spec, err)
+	}
+	return rule
+}
+
+func formatAll(items []time.Time, layout string) string {
+	out := make([]string, len(items))
+	for i, t := range items {
+		out[i] = t.Format(layout)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestRRuleExpansion(t *testing.T) {
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		start string
+		rrule string
+		n     int
+		want  string
+	}{
+		{"last friday", "2026-01-01T10:00", "FREQ=MONTHLY;BYDAY=-1FR", 4, "2026-01-30,2026-02-27,2026-03-27,2026-04-24"},
+		{"last workday", "2026-01-01T10:00", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", 3, "2026-01-30,2026-02-27,2026-03-31"},
+		{"second tuesday", "2026-01-01T10:00", "FREQ=MONTHLY;BYDAY=2TU", 2, "2026-01-13,2026-02-10"},
+		{"day 31 skips short months", "2026-01-31T10:00", "FREQ=MONTHLY", 3, "2026-01-31,2026-03-31,2026-05-31"},
+		{"biweekly", "2026-01-05T10:00", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", 4, "2026-01-05,2026-01-08,2026-01-19,2026-01-22"},
+		{"quarter end", "2026-01-01T10:00", "FREQ=YEARLY;BYMONTH=3,6,9,12;BYMONTHDAY=-1", 4, "2026-03-31,2026-06-30,2026-09-30,2026-12-31"},
+		{"count", "2026-01-01T10:00", "FREQ=DAILY;COUNT=2", 5, "2026-01-01,2026-01-02"},
+		{"until", "2026-01-01T10:00", "FREQ=WEEKLY;UNTIL=20260115", 5, "2026-01-01,2026-01-08,2026-01-15"},
+		{"week number", "2026-01-01T10:00", "FREQ=YEARLY;BYWEEKNO=1;BYDAY=MO", 2, "2027-01-04,2028-01-03"},
+		{"leap day", "2024-02-29T10:00", "FREQ=YEARLY", 2, "2028-02-29,2032-02-29"},
+	}
+	for _, tc := range cases {
+		rule := mustRule(t, Spec{Start: tc.start, RRule: tc.rrule}, nil)
+		got := formatAll(rule.Occurrences(after, tc.n), DateLayout)
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRRuleParseErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYWEEKNO=3",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	} {
+		if _, err := ParseRRule(raw); err != ErrInvalidRRule {
+			t.Errorf("%q: expected invalid rrule, got %v", raw, err)
+		}
+	}
+	rr, err := ParseRRule("rrule:freq=monthly;byday=-1fr;interval=1")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if rr.String() != "FREQ=MONTHLY;BYDAY=-1FR" {
+		t.Fatalf("unexpected canonical form %s", rr.String())
+	}
+}
+
+func TestCronToRRule(t *testing.T) {
+	cases := []struct {
+		expr  string
+		rrule string
+		want  string
+	}{
+		{"0 7 1 * *", "FREQ=DAILY;BYMONTHDAY=1;BYHOUR=7;BYMINUTE=0", "2026-02-01T07:00,2026-03-01T07:00"},
+		{"30 8 * * 1-5", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=8;BYMINUTE=30", "2026-01-30T08:30,2026-02-02T08:30"},
+		{"0 */12 * 1,7 7", "FREQ=DAILY;BYMONTH=1,7;BYDAY=SU;BYHOUR=0,12;BYMINUTE=0", "2026-07-05T00:00,2026-07-05T12:00"},
+	}
+	after := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	for _, tc := range cases {
+		got, err := CronToRRule(tc.expr)
+		if err != nil || got != tc.rrule {
+			t.Errorf("%s: got %s (%v), want %s", tc.expr, got, err, tc.rrule)
+			continue
+		}
+		rule := mustRule(t, Spec{Start: "2000-01-01", RRule: got}, nil)
+		if occ := formatAll(rule.Occurrences(after, 2), "2006-01-02T15:04"); occ != tc.want {
+			t.Errorf("%s: occurrences %s, want %s", tc.expr, occ, tc.want)
+		}
+	}
+	for _, expr := range []string{"", "0 7 * *", "60 7 * * *", "0 7 1 * 1", "0 7 */0 * *", "0 7 5-1 * *", "a b c d e"} {
+		if _, err := CronToRRule(expr); err != ErrInvalidCron {
+			t.Errorf("%q: expected invalid cron, got %v", expr, err)
+		}
+	}
+}
+
+func TestTimezoneKeepsWallClockAcrossDST(t *testing.T) {
+	rule := mustRule(t, Spec{Start: "2026-03-27T09:00", RRule: "FREQ=DAILY", Timezone: "Europe/Berlin"}, nil)
+	items := rule.Occurrences(time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC), 3)
+	if got := formatAll(items, "15:04"); got != "09:00,09:00,09:00" {
+		t.Fatalf("wall clock must stay 09:00: %s", got)
+	}
+	if items[0].UTC().Hour() != 8 || items[2].UTC().Hour() != 7 {
+		t.Fatalf("UTC must follow the DST switch: %v", items)
+	}
+	if _, err := (Spec{Start: "2026-01-01", Timezone: "Mars/Olympus"}).Compile(nil); err != ErrInvalidTimezone {
+		t.Fatalf("expected timezone error, got %v", err)
+	}
+}
+
+func TestExDates(t *testing.T) {
+	rule := mustRule(t, Spec{Start: "2026-01-05T10:00", RRule: "FREQ=WEEKLY;COUNT=4", ExDates: []string{"2026-01-12", "2026-01-19T10:00"}}, nil)
+	got := formatAll(rule.Occurrences(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 10), DateLayout)
+	if got != "2026-01-05,2026-01-26" {
+		t.Fatalf("excluded dates must be dropped and still count: %s", got)
+	}
+}
+
+func TestShiftToBusinessDay(t *testing.T) {
+	cal := NewCalendar(DefaultWeekend, map[string]string{"2026-06-01": "Holiday"})
+	// 2026-05-31 is a Sunday, the Monday after it is a holiday.
+	spec := Spec{Start: "2026-01-31T09:00", RRule: "FREQ=MONTHLY;BYMONTHDAY=-1", Shift: ShiftNextBusinessDay}
+	rule := mustRule(t, spec, cal)
+	got := formatAll(rule.Occurrences(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), 2), DateLayout)
+	if got != "2026-06-02,2026-06-30" {
+		t.Fatalf("next business day: %s", got)
+	}
+	spec.Shift = ShiftPreviousBusinessDay
+	rule = mustRule(t, spec, cal)
+	got = formatAll(rule.Occurrences(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), 1), DateLayout)
+	if got != "2026-05-29" {
+		t.Fatalf("previous business day: %s", got)
+	}
+	spec.Shift = ShiftSkip
+	rule = mustRule(t, spec, cal)
+	got = formatAll(rule.Occurrences(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), 1), DateLayout)
+	if got != "2026-06-30" {
+		t.Fatalf("skip: %s", got)
+	}
+	if due := cal.AddBusinessDays(time.Date(2026, 5, 29, 12, 0, 0, 0, time.UTC), 2); due.Format(DateLayout) != "2026-06-03" {
+		t.Fatalf("business days must skip weekend and holiday: %s", due)
+	}
+}
+
+func TestBetweenAndFastForward(t *testing.T) {
+	rule := mustRule(t, Spec{Start: "2000-01-01T06:00", RRule: "FREQ=DAILY"}, nil)
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	items := rule.Between(from, from.AddDate(0, 1, 0))
+	if len(items) != 28 || !items[0].Equal(from.Add(6*time.Hour)) {
+		t.Fatalf("unexpected february occurrences: %d %v", len(items), items)
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	raw := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20260101",
+		"DTEND;VALUE=DATE:20260103",
+		"SUMMARY:New Year\\, holidays",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20250501",
+		"RRULE:FREQ=YEARLY",
+		"EXDATE;VALUE=DATE:20270501",
+		"SUMMARY:Labour",
+		"  Day",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	items, err := ParseICS(strings.NewReader(raw), time.Date(2028, 12, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var got []string
+	for _, h := range items {
+		got = append(got, h.Date+"="+h.Name)
+	}
+	want := "2025-05-01=Labour Day,2026-01-01=New Year, holidays,2026-01-02=New Year, holidays,2026-05-01=Labour Day,2028-05-01=Labour Day"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected holidays:\n%s", strings.Join(got, ","))
+	}
+	if _, err := ParseICS(strings.NewReader("not a calendar"), time.Now()); err != ErrInvalidICS {
+		t.Fatalf("expected ics error, got %v", err)
+	}
+}
+
+func TestDailyRunsOncePerDayAndRetriesFailures(t *testing.T) {
+	calls := 0
+	fail := true
+	d := NewDaily("test", config.SchedulerConfig{Enabled: true}, func(ctx context.Context, now time.Time) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}, nil)
+	morning := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	if err := d.RunOnce(context.Background(), morning); err == nil {
+		t.Fatalf("expected the job error")
+	}
+	fail = false
+	_ = d.RunOnce(context.Background(), morning.Add(time.Minute))
+	_ = d.RunOnce(context.Background(), morning.Add(time.Hour))
+	if calls != 2 {
+		t.Fatalf("expected a retry after failure and no rerun on the same day, got %d calls", calls)
+	}
+	_ = d.RunOnce(context.Background(), morning.Add(24*time.Hour))
+	if calls != 3 {
+		t.Fatalf("expected a run on the next day, got %d calls", calls)
+	}
+}
@@ -0,0 +1,345 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entities tracked by the asset history.
+const (
+	AssetHistoryEntityAsset    = "asset"
+	AssetHistoryEntitySoftware = "software"
+)
+
+// AssetFieldChange holds the previous and the new value of a field. Values are
+// strings, string lists or null; timestamps are RFC 3339 strings.
+type AssetFieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// AssetChange is one history entry of an asset or of one of its software
+// installations.
+type AssetChange struct {
+	ID         int64                       `json:"id"`
+	AssetID    int64                       `json:"asset_id"`
+	EntityType string                      `json:"entity_type"`
+	EntityID   int64                       `json:"entity_id"`
+	Action     string                      `json:"action"`
+	Changes    map[string]AssetFieldChange `json:"changes"`
+	ActorID    *int64                      `json:"actor_id,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+}
+
+type AssetHistoryFilter struct {
+	Since  *time.Time
+	Limit  int
+	Offset int
+}
+
+type AssetHistoryStore interface {
+	ListAssetHistory(ctx context.Context, assetID int64, filter AssetHistoryFilter) ([]AssetChange, error)
+	// AssetAsOf reconstructs the asset and its software installations as they were at
+	// the given time. Nil is returned when the asset did not exist yet.
+	AssetAsOf(ctx context.Context, assetID int64, at time.Time) (*Asset, []AssetSoftwareInstallation, error)
+}
+
+type assetHistoryStore struct {
+	db *sql.DB
+}
+
+func NewAssetHistoryStore(db *sql.DB) AssetHistoryStore {
+	return &assetHistoryStore{db: db}
+}
+
+func (s *assetHistoryStore) ListAssetHistory(ctx context.Context, assetID int64, filter AssetHistoryFilter) ([]AssetChange, error) {
+	query := `
+		SELECT id, asset_id, entity_type, entity_id, action, changes_json, actor_id, created_at
+		FROM asset_history
+		WHERE asset_id=?`
+	args := []any{assetID}
+	if filter.Since != nil {
+		query += ` AND created_at>?`
+		args = append(args, filter.Since.UTC())
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AssetChange
+	for rows.Next() {
+		var item AssetChange
+		var changesJSON string
+		var actor sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.AssetID, &item.EntityType, &item.EntityID, &item.Action, &changesJSON, &actor, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		item.ActorID = nullInt64Ptr(actor)
+		item.Changes = map[string]AssetFieldChange{}
+		_ = json.Unmarshal([]byte(changesJSON), &item.Changes)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+// AssetAsOf starts from the current rows and reverts every change recorded after the
+// requested time, newest first. Changes made before the history was introduced are
+// not known, so such fields keep their current value.
+func (s *assetHistoryStore) AssetAsOf(ctx context.Context, assetID int64, at time.Time) (*Asset, []AssetSoftwareInstallation, error) {
+	asset, err := scanAsset(s.db.QueryRowContext(ctx, assetSelectByID, assetID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	at = at.UTC()
+	if asset.CreatedAt.After(at) {
+		return nil, nil, nil
+	}
+	changes, err := s.ListAssetHistory(ctx, assetID, AssetHistoryFilter{Since: &at})
+	if err != nil {
+		return nil, nil, err
+	}
+	installs, err := (&softwareStore{db: s.db}).ListAssetSoftware(ctx, assetID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	byInstall := map[int64]*AssetSoftwareInstallation{}
+	for i := range installs {
+		byInstall[installs[i].ID] = &installs[i]
+	}
+	for _, change := range changes {
+		switch change.EntityType {
+		case AssetHistoryEntityAsset:
+			revertAssetFields(asset, change.Changes)
+		case AssetHistoryEntitySoftware:
+			if inst := byInstall[change.EntityID]; inst != nil {
+				revertAssetSoftwareFields(inst, change.Changes)
+			}
+		}
+	}
+	for i := range installs {
+		inst := &installs[i]
+		if inst.VersionID == nil || inst.VersionLabel != "" {
+			continue
+		}
+		var label string
+		if err := s.db.QueryRowContext(ctx, `SELECT version FROM software_versions WHERE id=?`, *inst.VersionID).Scan(&label); err == nil {
+			inst.VersionLabel = label
+		}
+	}
+	var software []AssetSoftwareInstallation
+	for _, inst := range installs {
+		if inst.CreatedAt.After(at) || inst.DeletedAt != nil {
+			continue
+		}
+		software = append(software, inst)
+	}
+	sort.SliceStable(software, func(i, j int) bool {
+		return strings.ToLower(software[i].ProductName) < strings.ToLower(software[j].ProductName)
+	})
+	return asset, software, nil
+}
+
+type assetHistoryExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func recordAssetChange(ctx context.Context, exec assetHistoryExecer, change AssetChange) error {
+	if len(change.Changes) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(change.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO asset_history(asset_id, entity_type, entity_id, action, changes_json, actor_id, created_at)
+		VALUES(?,?,?,?,?,?,?)`,
+		change.AssetID, change.EntityType, change.EntityID, change.Action, string(raw), nullableID(change.ActorID), change.CreatedAt)
+	return err
+}
+
+func historyTime(ts *time.Time) any {
+	if ts == nil {
+		return nil
+	}
+	return ts.UTC().Format(time.RFC3339Nano)
+}
+
+func historyID(id *int64) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+func historyList(items []string) any {
+	if len(items) == 0 {
+		return []string{}
+	}
+	return items
+}
+
+func assetHistoryFields(a *Asset) map[string]any {
+	return map[string]any{
+		"name":            a.Name,
+		"type":            a.Type,
+		"description":     a.Description,
+		"commissioned_at": historyTime(a.CommissionedAt),
+		"ip_addresses":    historyList(a.IPAddresses),
+		"criticality":     a.Criticality,
+		"owner":           a.Owner,
+		"administrator":   a.Administrator,
+		"env":             a.Env,
+		"status":          a.Status,
+		"tags":            historyList(a.Tags),
+		"deleted_at":      historyTime(a.DeletedAt),
+	}
+}
+
+func assetSoftwareHistoryFields(inst *AssetSoftwareInstallation) map[string]any {
+	return map[string]any{
+		"product_id":   inst.ProductID,
+		"version_id":   historyID(inst.VersionID),
+		"version_text": inst.VersionText,
+		"installed_at": historyTime(inst.InstalledAt),
+		"source":       inst.Source,
+		"notes":        inst.Notes,
+		"deleted_at":   historyTime(inst.DeletedAt),
+	}
+}
+
+// diffHistoryFields compares two field sets; a nil previous set records every
+// non-empty field of a new entity.
+func diffHistoryFields(prev, next map[string]any) map[string]AssetFieldChange {
+	out := map[string]AssetFieldChange{}
+	for key, val := range next {
+		var old any
+		if prev != nil {
+			old = prev[key]
+		}
+		oldRaw, _ := json.Marshal(old)
+		newRaw, _ := json.Marshal(val)
+		if string(oldRaw) == string(newRaw) {
+			continue
+		}
+		if prev == nil && isEmptyHistoryValue(val) {
+			continue
+		}
+		out[key] = AssetFieldChange{Old: old, New: val}
+	}
+	return out
+}
+
+func isEmptyHistoryValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []string:
+		return len(val) == 0
+	}
+	return false
+}
+
+func revertAssetFields(a *Asset, changes map[string]AssetFieldChange) {
+	for key, change := range changes {
+		switch key {
+		case "name":
+			a.Name = historyString(change.Old)
+		case "type":
+			a.Type = historyString(change.Old)
+		case "description":
+			a.Description = historyString(change.Old)
+		case "commissioned_at":
+			a.CommissionedAt = historyTimePtr(change.Old)
+		case "ip_addresses":
+			a.IPAddresses = historyStrings(change.Old)
+		case "criticality":
+			a.Criticality = historyString(change.Old)
+		case "owner":
+			a.Owner = historyString(change.Old)
+		case "administrator":
+			a.Administrator = historyString(change.Old)
+		case "env":
+			a.Env = historyString(change.Old)
+		case "status":
+			a.Status = historyString(change.Old)
+		case "tags":
+			a.Tags = historyStrings(change.Old)
+		case "deleted_at":
+			a.DeletedAt = historyTimePtr(change.Old)
+		}
+	}
+}
+
+func revertAssetSoftwareFields(inst *AssetSoftwareInstallation, changes map[string]AssetFieldChange) {
+	for key, change := range changes {
+		switch key {
+		case "version_id":
+			inst.VersionID = historyIDPtr(change.Old)
+			inst.VersionLabel = ""
+		case "version_text":
+			inst.VersionText = historyString(change.Old)
+		case "installed_at":
+			inst.InstalledAt = historyTimePtr(change.Old)
+		case "source":
+			inst.Source = historyString(change.Old)
+		case "notes":
+			inst.Notes = historyString(change.Old)
+		case "deleted_at":
+			inst.DeletedAt = historyTimePtr(change.Old)
+		}
+	}
+}
+
+func historyString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func historyStrings(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func historyTimePtr(v any) *time.Time {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func historyIDPtr(v any) *int64 {
+	f, ok := v.(float64)
+	if !ok {
+		return nil
+	}
+	id := int64(f)
+	return &id
+}
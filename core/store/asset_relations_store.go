@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Asset relation types. The source asset depends on the target: a VM "runs_on" its
+// hypervisor, an application "depends_on" its database, a server "backs_up_to" the
+// backup storage. An outage of the target therefore affects the source.
+const (
+	AssetRelationDependsOn = "depends_on"
+	AssetRelationRunsOn    = "runs_on"
+	AssetRelationBacksUpTo = "backs_up_to"
+)
+
+var AssetRelationTypes = []string{AssetRelationDependsOn, AssetRelationRunsOn, AssetRelationBacksUpTo}
+
+type AssetRelation struct {
+	ID           int64     `json:"id"`
+	SourceID     int64     `json:"source_id"`
+	SourceName   string    `json:"source_name"`
+	TargetID     int64     `json:"target_id"`
+	TargetName   string    `json:"target_name"`
+	RelationType string    `json:"relation_type"`
+	Description  string    `json:"description"`
+	CreatedBy    *int64    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AssetRelationsStore interface {
+	ListAssetRelations(ctx context.Context, assetID int64) ([]AssetRelation, error)
+	ListAssetRelationsFor(ctx context.Context, assetIDs []int64) ([]AssetRelation, error)
+	GetAssetRelation(ctx context.Context, id int64) (*AssetRelation, error)
+	AddAssetRelation(ctx context.Context, rel *AssetRelation) (int64, error)
+	DeleteAssetRelation(ctx context.Context, id int64) error
+}
+
+type assetRelationsStore struct {
+	db *sql.DB
+}
+
+func NewAssetRelationsStore(db *sql.DB) AssetRelationsStore {
+	return &assetRelationsStore{db: db}
+}
+
+const assetRelationSelect = `
+		SELECT r.id, r.source_asset_id, s.name, r.target_asset_id, t.name, r.relation_type, r.description, r.created_by, r.created_at
+		FROM asset_relations r
+		JOIN assets s ON s.id=r.source_asset_id
+		JOIN assets t ON t.id=r.target_asset_id`
+
+// ListAssetRelations returns the relations of an asset in both directions. Relations
+// to archived assets are omitted.
+func (s *assetRelationsStore) ListAssetRelations(ctx context.Context, assetID int64) ([]AssetRelation, error) {
+	return s.ListAssetRelationsFor(ctx, []int64{assetID})
+}
+
+// ListAssetRelationsFor returns every relation touching one of the assets, so a graph
+// can be expanded one level per query.
+func (s *assetRelationsStore) ListAssetRelationsFor(ctx context.Context, assetIDs []int64) ([]AssetRelation, error) {
+	ids := normalizeUniqueInt64(assetIDs)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, 0, len(ids)*2)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := s.db.QueryContext(ctx, assetRelationSelect+`
+		WHERE (r.source_asset_id IN (`+placeholders+`) OR r.target_asset_id IN (`+placeholders+`))
+		  AND s.deleted_at IS NULL AND t.deleted_at IS NULL
+		ORDER BY r.relation_type ASC, LOWER(s.name) ASC, LOWER(t.name) ASC, r.id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AssetRelation
+	for rows.Next() {
+		item, err := scanAssetRelation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	return out, rows.Err()
+}
+
+func (s *assetRelationsStore) GetAssetRelation(ctx context.Context, id int64) (*AssetRelation, error) {
+	row := s.db.QueryRowContext(ctx, assetRelationSelect+` WHERE r.id=?`, id)
+	item, err := scanAssetRelation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// AddAssetRelation stores a relation; ErrConflict is returned when the same relation
+// already exists.
+func (s *assetRelationsStore) AddAssetRelation(ctx context.Context, rel *AssetRelation) (int64, error) {
+	relType := strings.ToLower(strings.TrimSpace(rel.RelationType))
+	var existing int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM asset_relations WHERE source_asset_id=? AND target_asset_id=? AND relation_type=?`,
+		rel.SourceID, rel.TargetID, relType).Scan(&existing)
+	if err == nil {
+		return 0, ErrConflict
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO asset_relations(source_asset_id, target_asset_id, relation_type, description, created_by, created_at)
+		VALUES(?,?,?,?,?,?)`,
+		rel.SourceID, rel.TargetID, relType, strings.TrimSpace(rel.Description), nullableID(rel.CreatedBy), now)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	rel.ID = id
+	rel.RelationType = relType
+	rel.CreatedAt = now
+	return id, nil
+}
+
+func (s *assetRelationsStore) DeleteAssetRelation(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM asset_relations WHERE id=?`, id)
+	return err
+}
+
+func scanAssetRelation(row interface{ Scan(dest ...any) error }) (*AssetRelation, error) {
+	var item AssetRelation
+	var createdBy sql.NullInt64
+	if err := row.Scan(&item.ID, &item.SourceID, &item.SourceName, &item.TargetID, &item.TargetName, &item.RelationType, &item.Description, &createdBy, &item.CreatedAt); err != nil {
+		return nil, err
+	}
+	item.CreatedBy = nullInt64Ptr(createdBy)
+	return &item, nil
+}
@@ -54,7 +54,12 @@ func (s *softwareStore) AddAssetSoftware(ctx context.Context, inst *AssetSoftwar
 	}
 	inst.Notes = strings.TrimSpace(inst.Notes)
 	inst.VersionText = strings.TrimSpace(inst.VersionText)
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `
 		INSERT INTO asset_software(asset_id, product_id, version_id, version_text, installed_at, source, notes, created_by, updated_by, created_at, updated_at)
 		VALUES(?,?,?,?,?,?,?,?,?,?,?)
 	`, inst.AssetID, inst.ProductID, nullableID(inst.VersionID), inst.VersionText, nullableTime(inst.InstalledAt), inst.Source, inst.Notes, nullableID(inst.CreatedBy), nullableID(inst.UpdatedBy), now, now)
@@ -62,6 +67,13 @@ func (s *softwareStore) AddAssetSoftware(ctx context.Context, inst *AssetSoftwar
 		return 0, err
 	}
 	id, _ := res.LastInsertId()
+	change := AssetChange{AssetID: inst.AssetID, EntityType: AssetHistoryEntitySoftware, EntityID: id, Action: "create", Changes: diffHistoryFields(nil, assetSoftwareHistoryFields(inst)), ActorID: inst.CreatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
@@ -76,7 +88,16 @@ func (s *softwareStore) UpdateAssetSoftware(ctx context.Context, inst *AssetSoft
 	}
 	inst.Notes = strings.TrimSpace(inst.Notes)
 	inst.VersionText = strings.TrimSpace(inst.VersionText)
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	prev, err := scanAssetSoftwareRow(tx.QueryRowContext(ctx, assetSoftwareSelectByID, inst.ID))
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE asset_software
 		SET version_id=?, version_text=?, installed_at=?, source=?, notes=?, updated_by=?, updated_at=?
 		WHERE id=? AND deleted_at IS NULL
@@ -88,12 +109,31 @@ func (s *softwareStore) UpdateAssetSoftware(ctx context.Context, inst *AssetSoft
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	next := *prev
+	next.VersionID = inst.VersionID
+	next.VersionText = inst.VersionText
+	next.InstalledAt = inst.InstalledAt
+	next.Source = inst.Source
+	next.Notes = inst.Notes
+	change := AssetChange{AssetID: prev.AssetID, EntityType: AssetHistoryEntitySoftware, EntityID: inst.ID, Action: "update", Changes: diffHistoryFields(assetSoftwareHistoryFields(prev), assetSoftwareHistoryFields(&next)), ActorID: inst.UpdatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *softwareStore) ArchiveAssetSoftware(ctx context.Context, id int64, updatedBy int64) error {
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	prev, err := scanAssetSoftwareRow(tx.QueryRowContext(ctx, assetSoftwareSelectByID, id))
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE asset_software SET deleted_at=?, updated_by=?, updated_at=?
 		WHERE id=? AND deleted_at IS NULL
 	`, now, updatedBy, now, id)
@@ -104,12 +144,25 @@ func (s *softwareStore) ArchiveAssetSoftware(ctx context.Context, id int64, upda
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	change := AssetChange{AssetID: prev.AssetID, EntityType: AssetHistoryEntitySoftware, EntityID: id, Action: "archive", Changes: map[string]AssetFieldChange{"deleted_at": {Old: nil, New: historyTime(&now)}}, ActorID: &updatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *softwareStore) RestoreAssetSoftware(ctx context.Context, id int64, updatedBy int64) error {
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	prev, err := scanAssetSoftwareRow(tx.QueryRowContext(ctx, assetSoftwareSelectByID, id))
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE asset_software SET deleted_at=NULL, updated_by=?, updated_at=?
 		WHERE id=? AND deleted_at IS NOT NULL
 	`, updatedBy, now, id)
@@ -120,7 +173,11 @@ func (s *softwareStore) RestoreAssetSoftware(ctx context.Context, id int64, upda
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	change := AssetChange{AssetID: prev.AssetID, EntityType: AssetHistoryEntitySoftware, EntityID: id, Action: "restore", Changes: map[string]AssetFieldChange{"deleted_at": {Old: historyTime(prev.DeletedAt), New: nil}}, ActorID: &updatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *softwareStore) ListProductAssets(ctx context.Context, productID int64, includeDeleted bool) ([]AssetSoftwareInstallation, error) {
@@ -192,6 +249,16 @@ func (s *softwareStore) ListProductAssets(ctx context.Context, productID int64,
 	return out, rows.Err()
 }
 
+const assetSoftwareSelectByID = `
+		SELECT a.id, a.asset_id, a.product_id, a.version_id, a.version_text, a.installed_at, a.source, a.notes,
+		       a.created_by, a.updated_by, a.created_at, a.updated_at, a.deleted_at,
+		       p.name, p.vendor,
+		       v.version
+		FROM asset_software a
+		JOIN software_products p ON p.id=a.product_id
+		LEFT JOIN software_versions v ON v.id=a.version_id
+		WHERE a.id=?`
+
 type assetSoftwareRowScanner interface {
 	Scan(dest ...any) error
 }
@@ -12,24 +12,25 @@ import (
 )
 
 type Asset struct {
-	ID             int64      `json:"id"`
-	Name           string     `json:"name"`
-	Type           string     `json:"type"`
-	Description    string     `json:"description"`
-	CommissionedAt *time.Time `json:"commissioned_at,omitempty"`
-	IPAddresses    []string   `json:"ip_addresses,omitempty"`
-	Criticality    string     `json:"criticality"`
-	Owner          string     `json:"owner"`
-	Administrator  string     `json:"administrator"`
-	Env            string     `json:"env"`
-	Status         string     `json:"status"`
-	Tags           []string   `json:"tags,omitempty"`
-	CreatedBy      *int64     `json:"created_by,omitempty"`
-	UpdatedBy      *int64     `json:"updated_by,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	Version        int        `json:"version"`
-	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	Description    string         `json:"description"`
+	CommissionedAt *time.Time     `json:"commissioned_at,omitempty"`
+	IPAddresses    []string       `json:"ip_addresses,omitempty"`
+	Criticality    string         `json:"criticality"`
+	Owner          string         `json:"owner"`
+	Administrator  string         `json:"administrator"`
+	Env            string         `json:"env"`
+	Status         string         `json:"status"`
+	Tags           []string       `json:"tags,omitempty"`
+	CustomFields   map[string]any `json:"custom_fields,omitempty"`
+	CreatedBy      *int64         `json:"created_by,omitempty"`
+	UpdatedBy      *int64         `json:"updated_by,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Version        int            `json:"version"`
+	DeletedAt      *time.Time     `json:"deleted_at,omitempty"`
 }
 
 type AssetLite struct {
@@ -39,15 +40,17 @@ type AssetLite struct {
 }
 
 type AssetFilter struct {
-	Search        string
-	Type          string
-	Criticality   string
-	Env           string
-	Status        string
-	Tag           string
+	Search         string
+	Type           string
+	Criticality    string
+	Env            string
+	Status         string
+	Tag            string
+	CustomFields   []CustomFieldCondition
+	Query          *QueryClause
 	IncludeDeleted bool
-	Limit         int
-	Offset        int
+	Limit          int
+	Offset         int
 }
 
 type AssetsStore interface {
@@ -102,6 +105,15 @@ func (s *assetsStore) ListAssets(ctx context.Context, filter AssetFilter) ([]Ass
 		clauses = append(clauses, "tags_json LIKE ?")
 		args = append(args, "%"+strings.ToUpper(tag)+"%")
 	}
+	for _, cond := range filter.CustomFields {
+		clause, condArgs := CustomFieldClause(CustomFieldEntityAsset, "id", cond)
+		clauses = append(clauses, clause)
+		args = append(args, condArgs...)
+	}
+	if filter.Query != nil {
+		clauses = append(clauses, "("+filter.Query.SQL+")")
+		args = append(args, filter.Query.Args...)
+	}
 	limit := filter.Limit
 	if limit <= 0 || limit > 500 {
 		limit = 200
@@ -172,12 +184,14 @@ func (s *assetsStore) ListAssetsLite(ctx context.Context, search string, limit i
 	return out, rows.Err()
 }
 
-func (s *assetsStore) GetAsset(ctx context.Context, id int64) (*Asset, error) {
-	row := s.db.QueryRowContext(ctx, `
+const assetSelectByID = `
 		SELECT id, name, type, description, commissioned_at, ip_addresses_json, criticality, owner, administrator, env, status, tags_json,
 		       created_by, updated_by, created_at, updated_at, version, deleted_at
 		FROM assets
-		WHERE id=?`, id)
+		WHERE id=?`
+
+func (s *assetsStore) GetAsset(ctx context.Context, id int64) (*Asset, error) {
+	row := s.db.QueryRowContext(ctx, assetSelectByID, id)
 	return scanAsset(row)
 }
 
@@ -193,7 +207,12 @@ func (s *assetsStore) CreateAsset(ctx context.Context, a *Asset) (int64, error)
 	a.IPAddresses = normalizeIPs(a.IPAddresses)
 	a.Tags = normalizeTags(a.Tags)
 
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `
 		INSERT INTO assets(name, type, description, commissioned_at, ip_addresses_json, criticality, owner, administrator, env, status, tags_json, created_by, updated_by, created_at, updated_at, version)
 		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
 		a.Name, a.Type, a.Description, nullableTime(a.CommissionedAt), ipsToJSON(a.IPAddresses), a.Criticality, a.Owner, a.Administrator, a.Env, a.Status, tagsToJSON(a.Tags), nullableID(a.CreatedBy), nullableID(a.UpdatedBy), now, now, 1)
@@ -201,6 +220,13 @@ func (s *assetsStore) CreateAsset(ctx context.Context, a *Asset) (int64, error)
 		return 0, err
 	}
 	id, _ := res.LastInsertId()
+	change := AssetChange{AssetID: id, EntityType: AssetHistoryEntityAsset, EntityID: id, Action: "create", Changes: diffHistoryFields(nil, assetHistoryFields(a)), ActorID: a.CreatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 	a.ID = id
 	a.CreatedAt = now
 	a.UpdatedAt = now
@@ -220,7 +246,19 @@ func (s *assetsStore) UpdateAsset(ctx context.Context, a *Asset) error {
 	a.IPAddresses = normalizeIPs(a.IPAddresses)
 	a.Tags = normalizeTags(a.Tags)
 
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	prev, err := scanAsset(tx.QueryRowContext(ctx, assetSelectByID, a.ID))
+	if err != nil {
+		return err
+	}
+	if prev.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE assets
 		SET name=?, type=?, description=?, commissioned_at=?, ip_addresses_json=?, criticality=?, owner=?, administrator=?, env=?, status=?, tags_json=?,
 		    updated_by=?, updated_at=?, version=version+1
@@ -234,13 +272,27 @@ func (s *assetsStore) UpdateAsset(ctx context.Context, a *Asset) error {
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
+	next := *a
+	next.DeletedAt = nil
+	change := AssetChange{AssetID: a.ID, EntityType: AssetHistoryEntityAsset, EntityID: a.ID, Action: "update", Changes: diffHistoryFields(assetHistoryFields(prev), assetHistoryFields(&next)), ActorID: a.UpdatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	a.UpdatedAt = now
 	return nil
 }
 
 func (s *assetsStore) ArchiveAsset(ctx context.Context, id int64, updatedBy int64) error {
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `
 		UPDATE assets
 		SET deleted_at=?, updated_by=?, updated_at=?, version=version+1
 		WHERE id=? AND deleted_at IS NULL`, now, updatedBy, now, id)
@@ -251,12 +303,25 @@ func (s *assetsStore) ArchiveAsset(ctx context.Context, id int64, updatedBy int6
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	change := AssetChange{AssetID: id, EntityType: AssetHistoryEntityAsset, EntityID: id, Action: "archive", Changes: map[string]AssetFieldChange{"deleted_at": {Old: nil, New: historyTime(&now)}}, ActorID: &updatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *assetsStore) RestoreAsset(ctx context.Context, id int64, updatedBy int64) error {
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var deletedAt sql.NullTime
+	if err := tx.QueryRowContext(ctx, `SELECT deleted_at FROM assets WHERE id=?`, id).Scan(&deletedAt); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE assets
 		SET deleted_at=NULL, updated_by=?, updated_at=?, version=version+1
 		WHERE id=? AND deleted_at IS NOT NULL`, updatedBy, now, id)
@@ -267,7 +332,11 @@ func (s *assetsStore) RestoreAsset(ctx context.Context, id int64, updatedBy int6
 	if affected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	change := AssetChange{AssetID: id, EntityType: AssetHistoryEntityAsset, EntityID: id, Action: "restore", Changes: map[string]AssetFieldChange{"deleted_at": {Old: historyTime(&deletedAt.Time), New: nil}}, ActorID: &updatedBy, CreatedAt: now}
+	if err := recordAssetChange(ctx, tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func scanAsset(row *sql.Row) (*Asset, error) {
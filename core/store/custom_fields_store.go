@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Entities that support custom fields.
+const (
+	CustomFieldEntityIncident = "incident"
+	CustomFieldEntityAsset    = "asset"
+	CustomFieldEntityFinding  = "finding"
+	CustomFieldEntityTask     = "task"
+)
+
+// Custom field types. Values are stored as text: numbers in decimal form, dates as
+// YYYY-MM-DD, users and assets as ids and multi-select values as a JSON array.
+const (
+	CustomFieldTypeText        = "text"
+	CustomFieldTypeNumber      = "number"
+	CustomFieldTypeDate        = "date"
+	CustomFieldTypeSelect      = "select"
+	CustomFieldTypeMultiSelect = "multiselect"
+	CustomFieldTypeUser        = "user"
+	CustomFieldTypeAsset       = "asset"
+)
+
+var (
+	CustomFieldEntityTypes = []string{CustomFieldEntityIncident, CustomFieldEntityAsset, CustomFieldEntityFinding, CustomFieldEntityTask}
+	CustomFieldTypes       = []string{CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeDate, CustomFieldTypeSelect, CustomFieldTypeMultiSelect, CustomFieldTypeUser, CustomFieldTypeAsset}
+)
+
+// CustomField is an admin-defined field of an entity type. Empty ViewRoles or
+// EditRoles mean that everyone with access to the entity may view or edit it.
+type CustomField struct {
+	ID         int64      `json:"id"`
+	EntityType string     `json:"entity_type"`
+	Key        string     `json:"key"`
+	Label      string     `json:"label"`
+	FieldType  string     `json:"field_type"`
+	Options    []string   `json:"options"`
+	Required   bool       `json:"required"`
+	ViewRoles  []string   `json:"view_roles"`
+	EditRoles  []string   `json:"edit_roles"`
+	Position   int        `json:"position"`
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CustomFieldCondition restricts a list to entities whose field has the value;
+// for multi-select fields the value must be one of the selected options.
+type CustomFieldCondition struct {
+	FieldID int64
+	Value   string
+	Multi   bool
+}
+
+type CustomFieldsStore interface {
+	ListCustomFields(ctx context.Context, entityType string, includeDeleted bool) ([]CustomField, error)
+	GetCustomField(ctx context.Context, id int64) (*CustomField, error)
+	CreateCustomField(ctx context.Context, f *CustomField) (int64, error)
+	UpdateCustomField(ctx context.Context, f *CustomField) error
+	ArchiveCustomField(ctx context.Context, id int64) error
+	// ListCustomFieldValues returns the stored values by entity id and field id.
+	ListCustomFieldValues(ctx context.Context, entityType string, entityIDs []int64) (map[int64]map[int64]string, error)
+	// SetCustomFieldValues writes the given values; a nil value removes the field.
+	SetCustomFieldValues(ctx context.Context, entityType string, entityID int64, values map[int64]*string, updatedBy int64) error
+}
+
+type customFieldsStore struct {
+	db *sql.DB
+}
+
+func NewCustomFieldsStore(db *sql.DB) CustomFieldsStore {
+	return &customFieldsStore{db: db}
+}
+
+const customFieldSelect = `
+		SELECT id, entity_type, field_key, label, field_type, options_json, required, view_roles_json, edit_roles_json, position,
+		       created_by, created_at, updated_at, deleted_at
+		FROM custom_fields`
+
+func (s *customFieldsStore) ListCustomFields(ctx context.Context, entityType string, includeDeleted bool) ([]CustomField, error) {
+	clauses := []string{"1=1"}
+	var args []any
+	if entityType != "" {
+		clauses = append(clauses, "entity_type=?")
+		args = append(args, entityType)
+	}
+	if !includeDeleted {
+		clauses = append(clauses, "deleted_at IS NULL")
+	}
+	rows, err := s.db.QueryContext(ctx, customFieldSelect+`
+		WHERE `+strings.Join(clauses, " AND ")+`
+		ORDER BY entity_type ASC, position ASC, id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CustomField
+	for rows.Next() {
+		item, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	return out, rows.Err()
+}
+
+func (s *customFieldsStore) GetCustomField(ctx context.Context, id int64) (*CustomField, error) {
+	item, err := scanCustomField(s.db.QueryRowContext(ctx, customFieldSelect+` WHERE id=?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateCustomField stores a field definition; ErrConflict is returned when the key is
+// already used by the entity type, including by an archived field.
+func (s *customFieldsStore) CreateCustomField(ctx context.Context, f *CustomField) (int64, error) {
+	var existing int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM custom_fields WHERE entity_type=? AND field_key=?`, f.EntityType, f.Key).Scan(&existing)
+	if err == nil {
+		return 0, ErrConflict
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO custom_fields(entity_type, field_key, label, field_type, options_json, required, view_roles_json, edit_roles_json, position, created_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`,
+		f.EntityType, f.Key, f.Label, f.FieldType, tagsToJSON(f.Options), boolToInt(f.Required), tagsToJSON(f.ViewRoles), tagsToJSON(f.EditRoles), f.Position,
+		nullableID(f.CreatedBy), now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	f.ID = id
+	f.CreatedAt = now
+	f.UpdatedAt = now
+	return id, nil
+}
+
+// UpdateCustomField changes the label, options, flags and permissions; the entity type,
+// key and value type are fixed once values may exist.
+func (s *customFieldsStore) UpdateCustomField(ctx context.Context, f *CustomField) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE custom_fields
+		SET label=?, options_json=?, required=?, view_roles_json=?, edit_roles_json=?, position=?, updated_at=?
+		WHERE id=? AND deleted_at IS NULL`,
+		f.Label, tagsToJSON(f.Options), boolToInt(f.Required), tagsToJSON(f.ViewRoles), tagsToJSON(f.EditRoles), f.Position, now, f.ID)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	f.UpdatedAt = now
+	return nil
+}
+
+// ArchiveCustomField hides the field; stored values are kept.
+func (s *customFieldsStore) ArchiveCustomField(ctx context.Context, id int64) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `UPDATE custom_fields SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, id)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *customFieldsStore) ListCustomFieldValues(ctx context.Context, entityType string, entityIDs []int64) (map[int64]map[int64]string, error) {
+	out := map[int64]map[int64]string{}
+	ids := normalizeUniqueInt64(entityIDs)
+	if len(ids) == 0 {
+		return out, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []any{entityType}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT entity_id, field_id, value_text
+		FROM custom_field_values
+		WHERE entity_type=? AND entity_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entityID, fieldID int64
+		var value string
+		if err := rows.Scan(&entityID, &fieldID, &value); err != nil {
+			return nil, err
+		}
+		if out[entityID] == nil {
+			out[entityID] = map[int64]string{}
+		}
+		out[entityID][fieldID] = value
+	}
+	return out, rows.Err()
+}
+
+func (s *customFieldsStore) SetCustomFieldValues(ctx context.Context, entityType string, entityID int64, values map[int64]*string, updatedBy int64) error {
+	if len(values) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	for fieldID, value := range values {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM custom_field_values WHERE entity_type=? AND entity_id=? AND field_id=?`, entityType, entityID, fieldID); err != nil {
+			return err
+		}
+		if value == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO custom_field_values(entity_type, entity_id, field_id, value_text, updated_by, updated_at)
+			VALUES(?,?,?,?,?,?)`, entityType, entityID, fieldID, *value, nullableID(&updatedBy), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CustomFieldClause returns a WHERE condition matching the entities of entityType,
+// identified by idColumn, that satisfy cond.
+func CustomFieldClause(entityType, idColumn string, cond CustomFieldCondition) (string, []any) {
+	clause := idColumn + ` IN (SELECT entity_id FROM custom_field_values WHERE entity_type=? AND field_id=? AND value_text=?)`
+	value := cond.Value
+	if cond.Multi {
+		clause = idColumn + ` IN (SELECT entity_id FROM custom_field_values WHERE entity_type=? AND field_id=? AND value_text LIKE ? ESCAPE '\')`
+		raw, _ := json.Marshal(cond.Value)
+		value = "%" + EscapeLike(string(raw)) + "%"
+	}
+	return clause, []any{entityType, cond.FieldID, value}
+}
+
+// EscapeLike escapes the LIKE wildcards in value for a pattern used with ESCAPE '\'.
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func scanCustomField(row interface{ Scan(dest ...any) error }) (*CustomField, error) {
+	var item CustomField
+	var optionsRaw, viewRaw, editRaw string
+	var required int
+	var createdBy sql.NullInt64
+	var deletedAt sql.NullTime
+	if err := row.Scan(&item.ID, &item.EntityType, &item.Key, &item.Label, &item.FieldType, &optionsRaw, &required, &viewRaw, &editRaw, &item.Position,
+		&createdBy, &item.CreatedAt, &item.UpdatedAt, &deletedAt); err != nil {
+		return nil, err
+	}
+	item.Required = required != 0
+	item.CreatedBy = nullInt64Ptr(createdBy)
+	if deletedAt.Valid {
+		t := deletedAt.Time
+		item.DeletedAt = &t
+	}
+	_ = json.Unmarshal([]byte(optionsRaw), &item.Options)
+	_ = json.Unmarshal([]byte(viewRaw), &item.ViewRoles)
+	_ = json.Unmarshal([]byte(editRaw), &item.EditRoles)
+	if item.Options == nil {
+		item.Options = []string{}
+	}
+	if item.ViewRoles == nil {
+		item.ViewRoles = []string{}
+	}
+	if item.EditRoles == nil {
+		item.EditRoles = []string{}
+	}
+	return &item, nil
+}
@@ -142,6 +142,7 @@ type DocumentFilter struct {
 	Tags           []string
 	MinLevel       int
 	DocType        string
+	Query          *QueryClause
 	Limit          int
 	Offset         int
 	Sort           string
@@ -473,6 +474,10 @@ func (s *docsStore) ListDocuments(ctx context.Context, filter DocumentFilter) ([
 		clauses = append(clauses, "classification_tags LIKE ?")
 		args = append(args, "%"+strings.ToUpper(strings.TrimSpace(t))+"%")
 	}
+	if filter.Query != nil {
+		clauses = append(clauses, "("+filter.Query.SQL+")")
+		args = append(args, filter.Query.Args...)
+	}
 	baseQuery := `SELECT id, folder_id, title, status, classification_level, classification_tags, reg_number, doc_type, inherit_acl, inherit_classification, created_by, current_version, created_at, updated_at, deleted_at FROM docs`
 	where := ""
 	if len(clauses) > 0 {
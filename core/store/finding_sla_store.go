@@ -0,0 +1,372 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"berkut-scc/core/schedule"
+)
+
+// Finding exception statuses. Only an approved exception pauses the SLA.
+const (
+	FindingExceptionPending  = "pending"
+	FindingExceptionApproved = "approved"
+	FindingExceptionRejected = "rejected"
+	FindingExceptionRevoked  = "revoked"
+	FindingExceptionExpired  = "expired"
+)
+
+var ErrInvalidFindingSLAPolicy = errors.New("invalid finding sla policy")
+
+// FindingSLAPolicy maps severity to remediation days. Zero days means the
+// severity has no SLA.
+type FindingSLAPolicy struct {
+	Days             map[string]int `json:"days"`
+	DueSoonDays      int            `json:"due_soon_days"`
+	EscalationDays   int            `json:"escalation_days"`
+	EscalateTo       []int64        `json:"escalate_to"`
+	MaxExceptionDays int            `json:"max_exception_days"`
+	// CalendarID counts the SLA days as business days of a schedule calendar.
+	CalendarID int64     `json:"calendar_id,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type FindingException struct {
+	ID              int64      `json:"id"`
+	FindingID       int64      `json:"finding_id"`
+	RequestedBy     *int64     `json:"requested_by,omitempty"`
+	ApproverID      *int64     `json:"approver_id,omitempty"`
+	Justification   string     `json:"justification"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	Status          string     `json:"status"`
+	PrevStatus      string     `json:"prev_status,omitempty"`
+	DecisionComment string     `json:"decision_comment"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type FindingSLAStore interface {
+	GetSLAPolicy(ctx context.Context) (*FindingSLAPolicy, error)
+	SaveSLAPolicy(ctx context.Context, p *FindingSLAPolicy) error
+
+	ListOpenFindings(ctx context.Context) ([]Finding, error)
+	SetFindingDueAt(ctx context.Context, id int64, dueAt time.Time) error
+	MarkFindingOverdue(ctx context.Context, id int64, at time.Time) error
+	MarkFindingEscalated(ctx context.Context, id int64, at time.Time) error
+	ClearFindingOverdue(ctx context.Context, id int64) error
+	ClearClosedFindingOverdue(ctx context.Context) (int64, error)
+	PauseFindingSLA(ctx context.Context, id int64, at time.Time) error
+	ResumeFindingSLA(ctx context.Context, id int64, status string, dueAt *time.Time) error
+
+	CreateFindingException(ctx context.Context, e *FindingException) (int64, error)
+	GetFindingException(ctx context.Context, id int64) (*FindingException, error)
+	ListFindingExceptions(ctx context.Context, findingID int64) ([]FindingException, error)
+	ListPendingFindingExceptions(ctx context.Context, approverID int64) ([]FindingException, error)
+	ActiveFindingException(ctx context.Context, findingID int64) (*FindingException, error)
+	ListExpiredFindingExceptions(ctx context.Context, now time.Time) ([]FindingException, error)
+	DecideFindingException(ctx context.Context, id int64, status, prevStatus, comment string, at time.Time) error
+	CloseFindingException(ctx context.Context, id int64, status string, at time.Time) error
+}
+
+type findingSLAStore struct {
+	db *sql.DB
+}
+
+func NewFindingSLAStore(db *sql.DB) FindingSLAStore {
+	return &findingSLAStore{db: db}
+}
+
+// DefaultFindingSLAPolicy follows common vulnerability management practice.
+func DefaultFindingSLAPolicy() FindingSLAPolicy {
+	return FindingSLAPolicy{
+		Days:             map[string]int{"critical": 7, "high": 30, "medium": 90, "low": 180},
+		DueSoonDays:      7,
+		EscalationDays:   7,
+		MaxExceptionDays: 365,
+	}
+}
+
+func (p FindingSLAPolicy) Validate() error {
+	for sev, days := range p.Days {
+		if normalizeFindingSeverity(sev) != sev || days < 0 || days > 3650 {
+			return ErrInvalidFindingSLAPolicy
+		}
+	}
+	if p.DueSoonDays < 0 || p.DueSoonDays > 90 {
+		return ErrInvalidFindingSLAPolicy
+	}
+	if p.EscalationDays < 0 || p.EscalationDays > 365 {
+		return ErrInvalidFindingSLAPolicy
+	}
+	if p.MaxExceptionDays < 1 || p.MaxExceptionDays > 3650 {
+		return ErrInvalidFindingSLAPolicy
+	}
+	if p.CalendarID < 0 {
+		return ErrInvalidFindingSLAPolicy
+	}
+	return nil
+}
+
+// DueAt returns the default due date for a finding of severity opened at from,
+// or nil when the severity has no SLA.
+func (p FindingSLAPolicy) DueAt(severity string, from time.Time) *time.Time {
+	days := p.Days[normalizeFindingSeverity(severity)]
+	if days <= 0 {
+		return nil
+	}
+	due := from.UTC().AddDate(0, 0, days)
+	return &due
+}
+
+// BusinessDueAt is DueAt counting only the business days of cal.
+func (p FindingSLAPolicy) BusinessDueAt(severity string, from time.Time, cal *schedule.Calendar) *time.Time {
+	days := p.Days[normalizeFindingSeverity(severity)]
+	if days <= 0 {
+		return nil
+	}
+	due := cal.AddBusinessDays(from.UTC(), days)
+	return &due
+}
+
+func (s *findingSLAStore) GetSLAPolicy(ctx context.Context) (*FindingSLAPolicy, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT policy_json, updated_at FROM finding_sla_settings ORDER BY id LIMIT 1`)
+	var raw string
+	var updatedAt time.Time
+	if err := row.Scan(&raw, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			p := DefaultFindingSLAPolicy()
+			return &p, nil
+		}
+		return nil, err
+	}
+	p := DefaultFindingSLAPolicy()
+	if err := json.Unmarshal([]byte(raw), &p); err != nil || p.Validate() != nil {
+		p = DefaultFindingSLAPolicy()
+	}
+	p.UpdatedAt = updatedAt
+	return &p, nil
+}
+
+func (s *findingSLAStore) SaveSLAPolicy(ctx context.Context, p *FindingSLAPolicy) error {
+	if p == nil {
+		return errors.New("missing policy")
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	payload, _ := json.Marshal(FindingSLAPolicy{
+		Days:             p.Days,
+		DueSoonDays:      p.DueSoonDays,
+		EscalationDays:   p.EscalationDays,
+		EscalateTo:       p.EscalateTo,
+		MaxExceptionDays: p.MaxExceptionDays,
+		CalendarID:       p.CalendarID,
+	})
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM finding_sla_settings ORDER BY id LIMIT 1`).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = s.db.ExecContext(ctx, `INSERT INTO finding_sla_settings(policy_json, updated_at) VALUES(?,?)`, string(payload), now)
+	case err == nil:
+		_, err = s.db.ExecContext(ctx, `UPDATE finding_sla_settings SET policy_json=?, updated_at=? WHERE id=?`, string(payload), now, id)
+	}
+	if err == nil {
+		p.UpdatedAt = now
+	}
+	return err
+}
+
+// ListOpenFindings returns active findings the SLA applies to.
+func (s *findingSLAStore) ListOpenFindings(ctx context.Context) ([]Finding, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+findingColumns+`
+		FROM findings
+		WHERE deleted_at IS NULL AND status IN ('open', 'in_progress')
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Finding
+	for rows.Next() {
+		f, err := scanFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *f)
+	}
+	return out, rows.Err()
+}
+
+func (s *findingSLAStore) SetFindingDueAt(ctx context.Context, id int64, dueAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE findings SET due_at=? WHERE id=? AND due_at IS NULL`, dueAt.UTC(), id)
+	return err
+}
+
+func (s *findingSLAStore) MarkFindingOverdue(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE findings SET overdue_at=? WHERE id=? AND overdue_at IS NULL`, at.UTC(), id)
+	return err
+}
+
+func (s *findingSLAStore) MarkFindingEscalated(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE findings SET escalated_at=? WHERE id=? AND escalated_at IS NULL`, at.UTC(), id)
+	return err
+}
+
+func (s *findingSLAStore) ClearFindingOverdue(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE findings SET overdue_at=NULL, escalated_at=NULL WHERE id=?`, id)
+	return err
+}
+
+// ClearClosedFindingOverdue drops overdue marks from findings that were closed or archived.
+func (s *findingSLAStore) ClearClosedFindingOverdue(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE findings SET overdue_at=NULL, escalated_at=NULL
+		WHERE overdue_at IS NOT NULL AND (deleted_at IS NOT NULL OR status NOT IN ('open', 'in_progress'))`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *findingSLAStore) PauseFindingSLA(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE findings SET sla_paused_at=?, overdue_at=NULL, escalated_at=NULL, status='accepted_risk', updated_at=?, version=version+1
+		WHERE id=? AND deleted_at IS NULL`, at.UTC(), at.UTC(), id)
+	return err
+}
+
+func (s *findingSLAStore) ResumeFindingSLA(ctx context.Context, id int64, status string, dueAt *time.Time) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE findings SET sla_paused_at=NULL, status=CASE WHEN status='accepted_risk' THEN ? ELSE status END, due_at=?, updated_at=?, version=version+1
+		WHERE id=? AND sla_paused_at IS NOT NULL`, normalizeFindingStatus(status), dueAt, now, id)
+	return err
+}
+
+func (s *findingSLAStore) CreateFindingException(ctx context.Context, e *FindingException) (int64, error) {
+	if e == nil || e.FindingID <= 0 {
+		return 0, errors.New("bad finding")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO finding_exceptions(finding_id, requested_by, approver_id, justification, expires_at, status, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?)`,
+		e.FindingID, nullableID(e.RequestedBy), nullableID(e.ApproverID), strings.TrimSpace(e.Justification), e.ExpiresAt.UTC(), FindingExceptionPending, now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	e.ID = id
+	e.Status = FindingExceptionPending
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	return id, nil
+}
+
+const findingExceptionColumns = `id, finding_id, requested_by, approver_id, justification, expires_at, status, prev_status,
+		       decision_comment, decided_at, closed_at, created_at, updated_at`
+
+func (s *findingSLAStore) GetFindingException(ctx context.Context, id int64) (*FindingException, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+findingExceptionColumns+` FROM finding_exceptions WHERE id=?`, id)
+	e, err := scanFindingException(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+func (s *findingSLAStore) ListFindingExceptions(ctx context.Context, findingID int64) ([]FindingException, error) {
+	return s.listExceptions(ctx, `SELECT `+findingExceptionColumns+` FROM finding_exceptions WHERE finding_id=? ORDER BY created_at DESC, id DESC`, findingID)
+}
+
+func (s *findingSLAStore) ListPendingFindingExceptions(ctx context.Context, approverID int64) ([]FindingException, error) {
+	return s.listExceptions(ctx, `SELECT `+findingExceptionColumns+` FROM finding_exceptions WHERE approver_id=? AND status=? ORDER BY created_at, id`, approverID, FindingExceptionPending)
+}
+
+func (s *findingSLAStore) ActiveFindingException(ctx context.Context, findingID int64) (*FindingException, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+findingExceptionColumns+`
+		FROM finding_exceptions
+		WHERE finding_id=? AND status IN (?, ?)
+		ORDER BY id DESC LIMIT 1`, findingID, FindingExceptionPending, FindingExceptionApproved)
+	e, err := scanFindingException(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+func (s *findingSLAStore) ListExpiredFindingExceptions(ctx context.Context, now time.Time) ([]FindingException, error) {
+	return s.listExceptions(ctx, `SELECT `+findingExceptionColumns+` FROM finding_exceptions WHERE status=? AND expires_at<=? ORDER BY id`, FindingExceptionApproved, now.UTC())
+}
+
+// DecideFindingException approves or rejects a pending exception; ErrConflict
+// means it was already decided.
+func (s *findingSLAStore) DecideFindingException(ctx context.Context, id int64, status, prevStatus, comment string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE finding_exceptions SET status=?, prev_status=?, decision_comment=?, decided_at=?, updated_at=?
+		WHERE id=? AND status=?`, status, prevStatus, strings.TrimSpace(comment), at.UTC(), at.UTC(), id, FindingExceptionPending)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// CloseFindingException ends a pending or approved exception (revoked or expired).
+func (s *findingSLAStore) CloseFindingException(ctx context.Context, id int64, status string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE finding_exceptions SET status=?, closed_at=?, updated_at=?
+		WHERE id=? AND status IN (?, ?)`, status, at.UTC(), at.UTC(), id, FindingExceptionPending, FindingExceptionApproved)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *findingSLAStore) listExceptions(ctx context.Context, query string, args ...any) ([]FindingException, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []FindingException
+	for rows.Next() {
+		e, err := scanFindingException(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func scanFindingException(row interface{ Scan(dest ...any) error }) (*FindingException, error) {
+	var e FindingException
+	var requestedBy, approverID sql.NullInt64
+	var decidedAt, closedAt sql.NullTime
+	if err := row.Scan(&e.ID, &e.FindingID, &requestedBy, &approverID, &e.Justification, &e.ExpiresAt, &e.Status, &e.PrevStatus,
+		&e.DecisionComment, &decidedAt, &closedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	e.RequestedBy = nullInt64Ptr(requestedBy)
+	e.ApproverID = nullInt64Ptr(approverID)
+	e.DecidedAt = nullTimePtr(decidedAt)
+	e.ClosedAt = nullTimePtr(closedAt)
+	return &e, nil
+}
@@ -10,21 +10,26 @@ import (
 )
 
 type Finding struct {
-	ID            int64      `json:"id"`
-	Title         string     `json:"title"`
-	DescriptionMD string     `json:"description_md"`
-	Status        string     `json:"status"`
-	Severity      string     `json:"severity"`
-	FindingType   string     `json:"finding_type"`
-	Owner         string     `json:"owner"`
-	DueAt         *time.Time `json:"due_at,omitempty"`
-	Tags          []string   `json:"tags,omitempty"`
-	CreatedBy     *int64     `json:"created_by,omitempty"`
-	UpdatedBy     *int64     `json:"updated_by,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Version       int        `json:"version"`
-	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	DescriptionMD string         `json:"description_md"`
+	Status        string         `json:"status"`
+	Severity      string         `json:"severity"`
+	FindingType   string         `json:"finding_type"`
+	Owner         string         `json:"owner"`
+	DueAt         *time.Time     `json:"due_at,omitempty"`
+	ResolvedAt    *time.Time     `json:"resolved_at,omitempty"`
+	OverdueAt     *time.Time     `json:"overdue_at,omitempty"`
+	EscalatedAt   *time.Time     `json:"escalated_at,omitempty"`
+	SLAPausedAt   *time.Time     `json:"sla_paused_at,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	CustomFields  map[string]any `json:"custom_fields,omitempty"`
+	CreatedBy     *int64         `json:"created_by,omitempty"`
+	UpdatedBy     *int64         `json:"updated_by,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	Version       int            `json:"version"`
+	DeletedAt     *time.Time     `json:"deleted_at,omitempty"`
 }
 
 type FindingFilter struct {
@@ -33,6 +38,9 @@ type FindingFilter struct {
 	Severity       string
 	Type           string
 	Tag            string
+	Overdue        bool
+	CustomFields   []CustomFieldCondition
+	Query          *QueryClause
 	IncludeDeleted bool
 	Limit          int
 	Offset         int
@@ -86,6 +94,18 @@ func (s *findingsStore) ListFindings(ctx context.Context, filter FindingFilter)
 		clauses = append(clauses, "tags_json LIKE ?")
 		args = append(args, "%"+strings.ToUpper(tag)+"%")
 	}
+	if filter.Overdue {
+		clauses = append(clauses, "overdue_at IS NOT NULL")
+	}
+	for _, cond := range filter.CustomFields {
+		clause, condArgs := CustomFieldClause(CustomFieldEntityFinding, "id", cond)
+		clauses = append(clauses, clause)
+		args = append(args, condArgs...)
+	}
+	if filter.Query != nil {
+		clauses = append(clauses, "("+filter.Query.SQL+")")
+		args = append(args, filter.Query.Args...)
+	}
 	limit := filter.Limit
 	if limit <= 0 || limit > 500 {
 		limit = 200
@@ -96,8 +116,7 @@ func (s *findingsStore) ListFindings(ctx context.Context, filter FindingFilter)
 	}
 
 	query := `
-		SELECT id, title, description_md, status, severity, finding_type, owner, due_at, tags_json,
-		       created_by, updated_by, created_at, updated_at, version, deleted_at
+		SELECT ` + findingColumns + `
 		FROM findings`
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
@@ -112,33 +131,11 @@ func (s *findingsStore) ListFindings(ctx context.Context, filter FindingFilter)
 	defer rows.Close()
 	var out []Finding
 	for rows.Next() {
-		var f Finding
-		var tagsRaw string
-		var due, deleted sql.NullTime
-		var createdBy, updatedBy sql.NullInt64
-		if err := rows.Scan(&f.ID, &f.Title, &f.DescriptionMD, &f.Status, &f.Severity, &f.FindingType, &f.Owner, &due, &tagsRaw, &createdBy, &updatedBy, &f.CreatedAt, &f.UpdatedAt, &f.Version, &deleted); err != nil {
+		f, err := scanFinding(rows)
+		if err != nil {
 			return nil, err
 		}
-		if due.Valid {
-			t := due.Time
-			f.DueAt = &t
-		}
-		if deleted.Valid {
-			t := deleted.Time
-			f.DeletedAt = &t
-		}
-		if createdBy.Valid {
-			v := createdBy.Int64
-			f.CreatedBy = &v
-		}
-		if updatedBy.Valid {
-			v := updatedBy.Int64
-			f.UpdatedBy = &v
-		}
-		if tagsRaw != "" {
-			_ = json.Unmarshal([]byte(tagsRaw), &f.Tags)
-		}
-		out = append(out, f)
+		out = append(out, *f)
 	}
 	return out, rows.Err()
 }
@@ -181,36 +178,39 @@ func (s *findingsStore) GetFinding(ctx context.Context, id int64) (*Finding, err
 		return nil, errors.New("bad id")
 	}
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, title, description_md, status, severity, finding_type, owner, due_at, tags_json,
-		       created_by, updated_by, created_at, updated_at, version, deleted_at
+		SELECT `+findingColumns+`
 		FROM findings
 		WHERE id=?`, id)
-	var f Finding
-	var tagsRaw string
-	var due, deleted sql.NullTime
-	var createdBy, updatedBy sql.NullInt64
-	if err := row.Scan(&f.ID, &f.Title, &f.DescriptionMD, &f.Status, &f.Severity, &f.FindingType, &f.Owner, &due, &tagsRaw, &createdBy, &updatedBy, &f.CreatedAt, &f.UpdatedAt, &f.Version, &deleted); err != nil {
+	f, err := scanFinding(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	if due.Valid {
-		t := due.Time
-		f.DueAt = &t
-	}
-	if deleted.Valid {
-		t := deleted.Time
-		f.DeletedAt = &t
-	}
-	if createdBy.Valid {
-		v := createdBy.Int64
-		f.CreatedBy = &v
-	}
-	if updatedBy.Valid {
-		v := updatedBy.Int64
-		f.UpdatedBy = &v
+	return f, nil
+}
+
+const findingColumns = `id, title, description_md, status, severity, finding_type, owner, due_at, tags_json,
+		       created_by, updated_by, created_at, updated_at, version, deleted_at,
+		       resolved_at, overdue_at, escalated_at, sla_paused_at`
+
+func scanFinding(row interface{ Scan(dest ...any) error }) (*Finding, error) {
+	var f Finding
+	var tagsRaw string
+	var due, deleted, resolved, overdue, escalated, paused sql.NullTime
+	var createdBy, updatedBy sql.NullInt64
+	if err := row.Scan(&f.ID, &f.Title, &f.DescriptionMD, &f.Status, &f.Severity, &f.FindingType, &f.Owner, &due, &tagsRaw, &createdBy, &updatedBy, &f.CreatedAt, &f.UpdatedAt, &f.Version, &deleted, &resolved, &overdue, &escalated, &paused); err != nil {
+		return nil, err
 	}
+	f.DueAt = nullTimePtr(due)
+	f.DeletedAt = nullTimePtr(deleted)
+	f.ResolvedAt = nullTimePtr(resolved)
+	f.OverdueAt = nullTimePtr(overdue)
+	f.EscalatedAt = nullTimePtr(escalated)
+	f.SLAPausedAt = nullTimePtr(paused)
+	f.CreatedBy = nullInt64Ptr(createdBy)
+	f.UpdatedBy = nullInt64Ptr(updatedBy)
 	if tagsRaw != "" {
 		_ = json.Unmarshal([]byte(tagsRaw), &f.Tags)
 	}
@@ -223,11 +223,16 @@ func (s *findingsStore) CreateFinding(ctx context.Context, f *Finding) (int64, e
 	}
 	now := time.Now().UTC()
 	tagsJSON, _ := json.Marshal(normalizeUpperTags(f.Tags))
+	status := normalizeFindingStatus(f.Status)
+	var resolvedAt any
+	if FindingStatusClosed(status) {
+		resolvedAt = now
+	}
 	res, err := s.db.ExecContext(ctx, `
-		INSERT INTO findings(title, description_md, status, severity, finding_type, owner, due_at, tags_json, created_by, updated_by, created_at, updated_at, version)
-		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,1)
-	`, strings.TrimSpace(f.Title), strings.TrimSpace(f.DescriptionMD), normalizeFindingStatus(f.Status), normalizeFindingSeverity(f.Severity), normalizeFindingType(f.FindingType),
-		strings.TrimSpace(f.Owner), f.DueAt, string(tagsJSON), nullableID(f.CreatedBy), nullableID(f.UpdatedBy), now, now)
+		INSERT INTO findings(title, description_md, status, severity, finding_type, owner, due_at, tags_json, created_by, updated_by, created_at, updated_at, version, resolved_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,1,?)
+	`, strings.TrimSpace(f.Title), strings.TrimSpace(f.DescriptionMD), status, normalizeFindingSeverity(f.Severity), normalizeFindingType(f.FindingType),
+		strings.TrimSpace(f.Owner), f.DueAt, string(tagsJSON), nullableID(f.CreatedBy), nullableID(f.UpdatedBy), now, now, resolvedAt)
 	if err != nil {
 		return 0, err
 	}
@@ -241,12 +246,15 @@ func (s *findingsStore) UpdateFinding(ctx context.Context, f *Finding) error {
 	}
 	now := time.Now().UTC()
 	tagsJSON, _ := json.Marshal(normalizeUpperTags(f.Tags))
+	status := normalizeFindingStatus(f.Status)
+	// resolved_at keeps the first close time; reopening clears it.
 	res, err := s.db.ExecContext(ctx, `
 		UPDATE findings
-		SET title=?, description_md=?, status=?, severity=?, finding_type=?, owner=?, due_at=?, tags_json=?, updated_by=?, updated_at=?, version=version+1
+		SET title=?, description_md=?, status=?, severity=?, finding_type=?, owner=?, due_at=?, tags_json=?, updated_by=?, updated_at=?, version=version+1,
+		    resolved_at=CASE WHEN ? THEN COALESCE(resolved_at, ?) ELSE NULL END
 		WHERE id=? AND version=? AND deleted_at IS NULL
-	`, strings.TrimSpace(f.Title), strings.TrimSpace(f.DescriptionMD), normalizeFindingStatus(f.Status), normalizeFindingSeverity(f.Severity), normalizeFindingType(f.FindingType),
-		strings.TrimSpace(f.Owner), f.DueAt, string(tagsJSON), nullableID(f.UpdatedBy), now, f.ID, f.Version)
+	`, strings.TrimSpace(f.Title), strings.TrimSpace(f.DescriptionMD), status, normalizeFindingSeverity(f.Severity), normalizeFindingType(f.FindingType),
+		strings.TrimSpace(f.Owner), f.DueAt, string(tagsJSON), nullableID(f.UpdatedBy), now, FindingStatusClosed(status), now, f.ID, f.Version)
 	if err != nil {
 		return err
 	}
@@ -295,6 +303,15 @@ func (s *findingsStore) RestoreFinding(ctx context.Context, id int64, updatedBy
 	return nil
 }
 
+// FindingStatusClosed reports whether status ends the remediation SLA.
+func FindingStatusClosed(status string) bool {
+	switch status {
+	case "resolved", "false_positive":
+		return true
+	}
+	return false
+}
+
 func normalizeFindingStatus(v string) string {
 	val := strings.ToLower(strings.TrimSpace(v))
 	switch val {
@@ -318,7 +335,7 @@ func normalizeFindingSeverity(v string) string {
 func normalizeFindingType(v string) string {
 	val := strings.ToLower(strings.TrimSpace(v))
 	switch val {
-	case "config", "process", "technical", "compliance", "other":
+	case "config", "process", "technical", "compliance", "vulnerability", "eol", "other":
 		return val
 	default:
 		return "other"
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Incident lifecycle milestones tracked in incident_impact.
+const (
+	IncidentMilestoneDetected     = "detected"
+	IncidentMilestoneAcknowledged = "acknowledged"
+	IncidentMilestoneContained    = "contained"
+	IncidentMilestoneResolved     = "resolved"
+)
+
+var incidentMilestoneColumns = map[string]string{
+	IncidentMilestoneDetected:     "detected_at",
+	IncidentMilestoneAcknowledged: "acknowledged_at",
+	IncidentMilestoneContained:    "contained_at",
+	IncidentMilestoneResolved:     "resolved_at",
+}
+
+type IncidentImpact struct {
+	IncidentID      int64      `json:"incident_id"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	DetectedAt      *time.Time `json:"detected_at,omitempty"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
+	ContainedAt     *time.Time `json:"contained_at,omitempty"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	DowntimeMinutes int        `json:"downtime_minutes"`
+	AffectedUsers   int        `json:"affected_users"`
+	EstimatedCost   float64    `json:"estimated_cost"`
+	CostCurrency    string     `json:"cost_currency"`
+	UpdatedBy       *int64     `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+const incidentImpactColumns = `incident_id, started_at, detected_at, acknowledged_at, contained_at, resolved_at,
+		       downtime_minutes, affected_users, estimated_cost, cost_currency, updated_by, updated_at`
+
+// GetIncidentImpact returns the stored impact record, or an empty one when nothing was recorded yet.
+func (s *incidentsStore) GetIncidentImpact(ctx context.Context, incidentID int64) (*IncidentImpact, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+incidentImpactColumns+` FROM incident_impact WHERE incident_id=?`, incidentID)
+	item, err := scanIncidentImpact(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &IncidentImpact{IncidentID: incidentID}, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *incidentsStore) SaveIncidentImpact(ctx context.Context, impact *IncidentImpact) error {
+	if impact == nil || impact.IncidentID <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	impact.CostCurrency = strings.ToUpper(strings.TrimSpace(impact.CostCurrency))
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO incident_impact(incident_id, started_at, detected_at, acknowledged_at, contained_at, resolved_at, downtime_minutes, affected_users, estimated_cost, cost_currency, updated_by, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(incident_id) DO UPDATE SET
+			started_at=excluded.started_at,
+			detected_at=excluded.detected_at,
+			acknowledged_at=excluded.acknowledged_at,
+			contained_at=excluded.contained_at,
+			resolved_at=excluded.resolved_at,
+			downtime_minutes=excluded.downtime_minutes,
+			affected_users=excluded.affected_users,
+			estimated_cost=excluded.estimated_cost,
+			cost_currency=excluded.cost_currency,
+			updated_by=excluded.updated_by,
+			updated_at=excluded.updated_at`,
+		impact.IncidentID, nullableTime(impact.StartedAt), nullableTime(impact.DetectedAt), nullableTime(impact.AcknowledgedAt),
+		nullableTime(impact.ContainedAt), nullableTime(impact.ResolvedAt), impact.DowntimeMinutes, impact.AffectedUsers,
+		impact.EstimatedCost, impact.CostCurrency, nullableID(impact.UpdatedBy), now)
+	if err != nil {
+		return err
+	}
+	impact.UpdatedAt = &now
+	return nil
+}
+
+// StampIncidentMilestone records the first time an incident reached a milestone.
+// An already recorded (or manually entered) timestamp is never overwritten.
+func (s *incidentsStore) StampIncidentMilestone(ctx context.Context, incidentID int64, milestone string, at time.Time) error {
+	col, ok := incidentMilestoneColumns[milestone]
+	if !ok {
+		return fmt.Errorf("unknown milestone %q", milestone)
+	}
+	now := time.Now().UTC()
+	if at.IsZero() {
+		at = now
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO incident_impact(incident_id, `+col+`, updated_at)
+		VALUES(?,?,?)
+		ON CONFLICT(incident_id) DO UPDATE SET `+col+`=COALESCE(incident_impact.`+col+`, excluded.`+col+`)`,
+		incidentID, at.UTC(), now)
+	return err
+}
+
+func (s *incidentsStore) ListIncidentImpacts(ctx context.Context, incidentIDs []int64) (map[int64]IncidentImpact, error) {
+	out := map[int64]IncidentImpact{}
+	for _, chunk := range chunkIDs(incidentIDs, 500) {
+		args := make([]any, 0, len(chunk))
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		rows, err := s.db.QueryContext(ctx, `SELECT `+incidentImpactColumns+` FROM incident_impact WHERE incident_id IN (`+placeholders(len(chunk))+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			item, err := scanIncidentImpact(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out[item.IncidentID] = *item
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ListIncidentTimelineByTypes loads timeline events of the given types for many incidents at once,
+// oldest first. It backs lifecycle derivation for incidents that predate explicit milestones.
+func (s *incidentsStore) ListIncidentTimelineByTypes(ctx context.Context, incidentIDs []int64, eventTypes []string) ([]IncidentTimelineEvent, error) {
+	if len(eventTypes) == 0 {
+		return nil, nil
+	}
+	var res []IncidentTimelineEvent
+	for _, chunk := range chunkIDs(incidentIDs, 500) {
+		args := make([]any, 0, len(chunk)+len(eventTypes))
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		for _, t := range eventTypes {
+			args = append(args, t)
+		}
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT id, incident_id, event_type, message, meta_json, created_by, created_at, event_at
+			FROM incident_timeline
+			WHERE incident_id IN (`+placeholders(len(chunk))+`) AND event_type IN (`+placeholders(len(eventTypes))+`)
+			ORDER BY COALESCE(event_at, created_at) ASC, id ASC`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var ev IncidentTimelineEvent
+			var eventAt sql.NullTime
+			if err := rows.Scan(&ev.ID, &ev.IncidentID, &ev.EventType, &ev.Message, &ev.MetaJSON, &ev.CreatedBy, &ev.CreatedAt, &eventAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			ev.EventAt = ev.CreatedAt
+			if eventAt.Valid {
+				ev.EventAt = eventAt.Time
+			}
+			res = append(res, ev)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func scanIncidentImpact(row assetRowScanner) (*IncidentImpact, error) {
+	var item IncidentImpact
+	var started, detected, acked, contained, resolved, updatedAt sql.NullTime
+	var updatedBy sql.NullInt64
+	if err := row.Scan(&item.IncidentID, &started, &detected, &acked, &contained, &resolved,
+		&item.DowntimeMinutes, &item.AffectedUsers, &item.EstimatedCost, &item.CostCurrency, &updatedBy, &updatedAt); err != nil {
+		return nil, err
+	}
+	item.StartedAt = nullTimePtr(started)
+	item.DetectedAt = nullTimePtr(detected)
+	item.AcknowledgedAt = nullTimePtr(acked)
+	item.ContainedAt = nullTimePtr(contained)
+	item.ResolvedAt = nullTimePtr(resolved)
+	item.UpdatedAt = nullTimePtr(updatedAt)
+	if updatedBy.Valid {
+		v := updatedBy.Int64
+		item.UpdatedBy = &v
+	}
+	return &item, nil
+}
+
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := v.Time.UTC()
+	return &t
+}
+
+func chunkIDs(ids []int64, size int) [][]int64 {
+	var out [][]int64
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		out = append(out, ids[start:end])
+	}
+	return out
+}
@@ -159,6 +159,8 @@ type IncidentFilter struct {
 	MineUserID      int64
 	AssignedUserID  int64
 	CreatedByUserID int64
+	CustomFields    []CustomFieldCondition
+	Query           *QueryClause
 	IncludeDeleted  bool
 	Limit           int
 	Offset          int
@@ -193,6 +195,7 @@ type IncidentsStore interface {
 	NextStagePosition(ctx context.Context, incidentID int64) (int, error)
 
 	ListIncidentLinks(ctx context.Context, incidentID int64) ([]IncidentLink, error)
+	ListOpenIncidentsByLink(ctx context.Context, entityType string, entityIDs []string) ([]Incident, error)
 	AddIncidentLink(ctx context.Context, link *IncidentLink) (int64, error)
 	DeleteIncidentLink(ctx context.Context, linkID int64) error
 
@@ -208,6 +211,12 @@ type IncidentsStore interface {
 	ListIncidentTimeline(ctx context.Context, incidentID int64, limit int, eventType string) ([]IncidentTimelineEvent, error)
 	AddIncidentTimeline(ctx context.Context, ev *IncidentTimelineEvent) (int64, error)
 	FindOpenIncidentBySource(ctx context.Context, source string, refID int64) (*Incident, error)
+
+	GetIncidentImpact(ctx context.Context, incidentID int64) (*IncidentImpact, error)
+	SaveIncidentImpact(ctx context.Context, impact *IncidentImpact) error
+	StampIncidentMilestone(ctx context.Context, incidentID int64, milestone string, at time.Time) error
+	ListIncidentImpacts(ctx context.Context, incidentIDs []int64) (map[int64]IncidentImpact, error)
+	ListIncidentTimelineByTypes(ctx context.Context, incidentIDs []int64, eventTypes []string) ([]IncidentTimelineEvent, error)
 }
 
 type incidentsStore struct {
@@ -252,6 +261,10 @@ func (s *incidentsStore) CreateIncident(ctx context.Context, incident *Incident,
 	}
 	incidentID, _ := res.LastInsertId()
 	incident.ID = incidentID
+	if err := setIncidentTags(ctx, tx, incidentID, incident.Meta.Tags); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
 	acl = ensureIncidentACLDefaults(incident, participants, acl)
 	if len(acl) > 0 {
 		for _, a := range acl {
@@ -294,21 +307,47 @@ func (s *incidentsStore) CreateIncident(ctx context.Context, incident *Incident,
 
 func (s *incidentsStore) UpdateIncident(ctx context.Context, incident *Incident, expectedVersion int) error {
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx, `
+	meta := NormalizeIncidentMeta(incident.Meta)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `
 		UPDATE incidents SET title=?, description=?, severity=?, status=?, owner_user_id=?, assignee_user_id=?, classification_level=?, classification_tags=?, meta_json=?, updated_by=?, updated_at=?, version=version+1
 		WHERE id=? AND version=?`,
-		incident.Title, incident.Description, incident.Severity, incident.Status, incident.OwnerUserID, nullableID(incident.AssigneeUserID), incident.ClassificationLevel, tagsToJSON(normalizeTags(incident.ClassificationTags)), metaToJSON(NormalizeIncidentMeta(incident.Meta)), incident.UpdatedBy, now, incident.ID, expectedVersion)
+		incident.Title, incident.Description, incident.Severity, incident.Status, incident.OwnerUserID, nullableID(incident.AssigneeUserID), incident.ClassificationLevel, tagsToJSON(normalizeTags(incident.ClassificationTags)), metaToJSON(meta), incident.UpdatedBy, now, incident.ID, expectedVersion)
 	if err != nil {
 		return err
 	}
 	if affected, _ := res.RowsAffected(); affected == 0 {
 		return ErrConflict
 	}
+	if err := setIncidentTags(ctx, tx, incident.ID, meta.Tags); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	incident.Version = expectedVersion + 1
 	incident.UpdatedAt = now
 	return nil
 }
 
+// setIncidentTags mirrors the meta tags into incident_tags, which the query
+// language filters on.
+func setIncidentTags(ctx context.Context, tx *sql.Tx, incidentID int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM incident_tags WHERE incident_id=?`, incidentID); err != nil {
+		return err
+	}
+	for _, tag := range normalizeTags(tags) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO incident_tags(incident_id, tag) VALUES(?,?)`, incidentID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *incidentsStore) CloseIncident(ctx context.Context, incidentID int64, userID int64) (*Incident, error) {
 	now := time.Now().UTC()
 	res, err := s.db.ExecContext(ctx, `
@@ -428,6 +467,15 @@ func (s *incidentsStore) ListIncidents(ctx context.Context, filter IncidentFilte
 		clauses = append(clauses, "created_by=?")
 		args = append(args, filter.CreatedByUserID)
 	}
+	for _, cond := range filter.CustomFields {
+		clause, condArgs := CustomFieldClause(CustomFieldEntityIncident, "id", cond)
+		clauses = append(clauses, clause)
+		args = append(args, condArgs...)
+	}
+	if filter.Query != nil {
+		clauses = append(clauses, "("+filter.Query.SQL+")")
+		args = append(args, filter.Query.Args...)
+	}
 	query := `SELECT id, reg_no, title, description, severity, status, source, source_ref_id, closed_at, closed_by, owner_user_id, assignee_user_id, classification_level, classification_tags, meta_json, created_by, updated_by, created_at, updated_at, version, deleted_at FROM incidents`
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
@@ -910,6 +958,41 @@ func (s *incidentsStore) ListIncidentLinks(ctx context.Context, incidentID int64
 	return res, rows.Err()
 }
 
+// ListOpenIncidentsByLink returns incidents that are neither resolved nor closed and
+// link to one of the entities.
+func (s *incidentsStore) ListOpenIncidentsByLink(ctx context.Context, entityType string, entityIDs []string) ([]Incident, error) {
+	var ids []any
+	for _, raw := range entityIDs {
+		if v := strings.TrimSpace(raw); v != "" {
+			ids = append(ids, v)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimRight(strings.Repeat("?,", len(ids)), ",")
+	args := append([]any{strings.ToLower(strings.TrimSpace(entityType))}, ids...)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, reg_no, title, description, severity, status, source, source_ref_id, closed_at, closed_by, owner_user_id, assignee_user_id, classification_level, classification_tags, meta_json, created_by, updated_by, created_at, updated_at, version, deleted_at
+		FROM incidents
+		WHERE deleted_at IS NULL AND status NOT IN ('resolved','closed')
+		  AND id IN (SELECT incident_id FROM incident_links WHERE entity_type=? AND entity_id IN (`+placeholders+`))
+		ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []Incident
+	for rows.Next() {
+		incident, err := s.scanIncidentRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, incident)
+	}
+	return res, rows.Err()
+}
+
 func (s *incidentsStore) AddIncidentLink(ctx context.Context, link *IncidentLink) (int64, error) {
 	now := time.Now().UTC()
 	res, err := s.db.ExecContext(ctx, `
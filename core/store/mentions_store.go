@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Mention is a resolved @username reference from a comment or note to the mentioned user.
+// SourceType/SourceID identify the text (task_comment, incident_timeline, control_comment),
+// EntityType/EntityID the object it belongs to.
+type Mention struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Username   string    `json:"username,omitempty"`
+	AuthorID   *int64    `json:"author_id,omitempty"`
+	AuthorName string    `json:"author_name,omitempty"`
+	SourceType string    `json:"source_type"`
+	SourceID   string    `json:"source_id"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Title      string    `json:"title"`
+	Excerpt    string    `json:"excerpt"`
+	Link       string    `json:"link"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MentionsStore interface {
+	// SaveMentions replaces the mentions of a source and returns the ones that were not stored before.
+	SaveMentions(ctx context.Context, sourceType, sourceID string, items []Mention) ([]Mention, error)
+	DeleteMentions(ctx context.Context, sourceType, sourceID string) error
+	ListSourceMentions(ctx context.Context, sourceType string, sourceIDs []string) (map[string][]Mention, error)
+	ListUserMentions(ctx context.Context, userID int64, limit, offset int) ([]Mention, error)
+}
+
+type mentionsStore struct {
+	db *sql.DB
+}
+
+func NewMentionsStore(db *sql.DB) MentionsStore {
+	return &mentionsStore{db: db}
+}
+
+func (s *mentionsStore) SaveMentions(ctx context.Context, sourceType, sourceID string, items []Mention) ([]Mention, error) {
+	sourceType = strings.TrimSpace(sourceType)
+	sourceID = strings.TrimSpace(sourceID)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM mentions WHERE source_type=? AND source_id=?`, sourceType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[int64]bool{}
+	for rows.Next() {
+		var uid int64
+		if err := rows.Scan(&uid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		existing[uid] = true
+	}
+	rows.Close()
+	keep := map[int64]bool{}
+	var added []Mention
+	now := time.Now().UTC()
+	for _, m := range items {
+		if m.UserID <= 0 || keep[m.UserID] {
+			continue
+		}
+		keep[m.UserID] = true
+		if existing[m.UserID] {
+			if _, err := tx.ExecContext(ctx, `UPDATE mentions SET title=?, excerpt=? WHERE source_type=? AND source_id=? AND user_id=?`,
+				m.Title, m.Excerpt, sourceType, sourceID, m.UserID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		m.SourceType = sourceType
+		m.SourceID = sourceID
+		m.CreatedAt = now
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO mentions(user_id, author_id, source_type, source_id, entity_type, entity_id, title, excerpt, link, created_at)
+			VALUES(?,?,?,?,?,?,?,?,?,?)`,
+			m.UserID, nullableID(m.AuthorID), sourceType, sourceID, m.EntityType, m.EntityID, m.Title, m.Excerpt, m.Link, now)
+		if err != nil {
+			return nil, err
+		}
+		m.ID, _ = res.LastInsertId()
+		added = append(added, m)
+	}
+	for uid := range existing {
+		if keep[uid] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM mentions WHERE source_type=? AND source_id=? AND user_id=?`, sourceType, sourceID, uid); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+func (s *mentionsStore) DeleteMentions(ctx context.Context, sourceType, sourceID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM mentions WHERE source_type=? AND source_id=?`, strings.TrimSpace(sourceType), strings.TrimSpace(sourceID))
+	return err
+}
+
+func (s *mentionsStore) ListSourceMentions(ctx context.Context, sourceType string, sourceIDs []string) (map[string][]Mention, error) {
+	out := map[string][]Mention{}
+	if len(sourceIDs) == 0 {
+		return out, nil
+	}
+	args := []any{strings.TrimSpace(sourceType)}
+	for _, id := range sourceIDs {
+		args = append(args, id)
+	}
+	items, err := s.query(ctx, `WHERE m.source_type=? AND m.source_id IN (`+placeholders(len(sourceIDs))+`) ORDER BY m.id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range items {
+		out[m.SourceID] = append(out[m.SourceID], m)
+	}
+	return out, nil
+}
+
+func (s *mentionsStore) ListUserMentions(ctx context.Context, userID int64, limit, offset int) ([]Mention, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.query(ctx, `WHERE m.user_id=? ORDER BY m.id DESC LIMIT ? OFFSET ?`, userID, limit, offset)
+}
+
+func (s *mentionsStore) query(ctx context.Context, tail string, args ...any) ([]Mention, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.id, m.user_id, COALESCE(u.username, ''), m.author_id, COALESCE(a.username, ''), m.source_type, m.source_id,
+			m.entity_type, m.entity_id, m.title, m.excerpt, m.link, m.created_at
+		FROM mentions m
+		LEFT JOIN users u ON u.id=m.user_id
+		LEFT JOIN users a ON a.id=m.author_id
+		`+tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Mention
+	for rows.Next() {
+		var m Mention
+		var author sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Username, &author, &m.AuthorName, &m.SourceType, &m.SourceID,
+			&m.EntityType, &m.EntityID, &m.Title, &m.Excerpt, &m.Link, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if author.Valid {
+			v := author.Int64
+			m.AuthorID = &v
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
@@ -952,6 +952,360 @@ var migrations = []string{
 	`CREATE INDEX IF NOT EXISTS idx_monitor_notification_deliveries_created ON monitor_notification_deliveries(created_at);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_notification_deliveries_status ON monitor_notification_deliveries(status, acknowledged_at);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_notifications_monitor ON monitor_notifications(monitor_id);`,
+	`CREATE TABLE IF NOT EXISTS observables (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		obs_type TEXT NOT NULL,
+		value TEXT NOT NULL,
+		tlp TEXT NOT NULL DEFAULT 'amber',
+		description TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT 'manual',
+		first_seen_at TIMESTAMP,
+		last_seen_at TIMESTAMP,
+		created_by INTEGER,
+		updated_by INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE(entity_type, entity_id, obs_type, value)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_observables_entity ON observables(entity_type, entity_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_observables_value ON observables(obs_type, value);`,
+	`CREATE TABLE IF NOT EXISTS incident_impact (
+		incident_id INTEGER PRIMARY KEY,
+		started_at TIMESTAMP,
+		detected_at TIMESTAMP,
+		acknowledged_at TIMESTAMP,
+		contained_at TIMESTAMP,
+		resolved_at TIMESTAMP,
+		downtime_minutes INTEGER NOT NULL DEFAULT 0,
+		affected_users INTEGER NOT NULL DEFAULT 0,
+		estimated_cost REAL NOT NULL DEFAULT 0,
+		cost_currency TEXT NOT NULL DEFAULT '',
+		updated_by INTEGER,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(incident_id) REFERENCES incidents(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_incident_timeline_type ON incident_timeline(event_type, incident_id);`,
+	`CREATE TABLE IF NOT EXISTS vulnerabilities (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		aliases_json TEXT NOT NULL DEFAULT '[]',
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		severity TEXT NOT NULL DEFAULT 'medium',
+		cvss_score REAL NOT NULL DEFAULT 0,
+		cvss_vector TEXT NOT NULL DEFAULT '',
+		references_json TEXT NOT NULL DEFAULT '[]',
+		published_at TIMESTAMP,
+		modified_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE(source, external_id)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerabilities_external_id ON vulnerabilities(external_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerabilities_severity ON vulnerabilities(severity);`,
+	`CREATE TABLE IF NOT EXISTS vulnerability_affected (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		vulnerability_id INTEGER NOT NULL,
+		cpe TEXT NOT NULL DEFAULT '',
+		vendor TEXT NOT NULL DEFAULT '',
+		product TEXT NOT NULL,
+		product_key TEXT NOT NULL DEFAULT '',
+		ecosystem TEXT NOT NULL DEFAULT '',
+		versions_json TEXT NOT NULL DEFAULT '[]',
+		version_start_including TEXT NOT NULL DEFAULT '',
+		version_start_excluding TEXT NOT NULL DEFAULT '',
+		version_end_including TEXT NOT NULL DEFAULT '',
+		version_end_excluding TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY(vulnerability_id) REFERENCES vulnerabilities(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerability_affected_vuln ON vulnerability_affected(vulnerability_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerability_affected_product ON vulnerability_affected(product_key, vendor);`,
+	`CREATE TABLE IF NOT EXISTS software_product_aliases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		product_id INTEGER NOT NULL,
+		vendor TEXT NOT NULL DEFAULT '',
+		product TEXT NOT NULL,
+		cpe TEXT NOT NULL DEFAULT '',
+		created_by INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(product_id, vendor, product),
+		FOREIGN KEY(product_id) REFERENCES software_products(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_software_product_aliases_product ON software_product_aliases(product, vendor);`,
+	`CREATE TABLE IF NOT EXISTS vulnerability_matches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		vulnerability_id INTEGER NOT NULL,
+		installation_id INTEGER NOT NULL,
+		asset_id INTEGER NOT NULL,
+		product_id INTEGER NOT NULL,
+		version_text TEXT NOT NULL DEFAULT '',
+		finding_id INTEGER,
+		status TEXT NOT NULL DEFAULT 'open',
+		first_seen_at TIMESTAMP NOT NULL,
+		last_seen_at TIMESTAMP NOT NULL,
+		closed_at TIMESTAMP,
+		UNIQUE(vulnerability_id, installation_id),
+		FOREIGN KEY(vulnerability_id) REFERENCES vulnerabilities(id) ON DELETE CASCADE,
+		FOREIGN KEY(installation_id) REFERENCES asset_software(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerability_matches_asset ON vulnerability_matches(asset_id, status);`,
+	`CREATE INDEX IF NOT EXISTS idx_vulnerability_matches_finding ON vulnerability_matches(finding_id);`,
+	`CREATE TABLE IF NOT EXISTS software_eol_alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		installation_id INTEGER NOT NULL UNIQUE,
+		asset_id INTEGER NOT NULL,
+		product_id INTEGER NOT NULL,
+		version_text TEXT NOT NULL DEFAULT '',
+		eol_date TIMESTAMP NOT NULL,
+		state TEXT NOT NULL DEFAULT 'approaching',
+		finding_id INTEGER,
+		task_id INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		resolved_at TIMESTAMP,
+		FOREIGN KEY(installation_id) REFERENCES asset_software(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_software_eol_alerts_asset ON software_eol_alerts(asset_id);`,
+	`CREATE TABLE IF NOT EXISTS user_notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL DEFAULT '',
+		title_key TEXT NOT NULL DEFAULT '',
+		body_key TEXT NOT NULL DEFAULT '',
+		params_json TEXT NOT NULL DEFAULT '{}',
+		entity_type TEXT NOT NULL DEFAULT '',
+		entity_id TEXT NOT NULL DEFAULT '',
+		link TEXT NOT NULL DEFAULT '',
+		actor_id INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		read_at TIMESTAMP,
+		dismissed_at TIMESTAMP,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_user_notifications_user ON user_notifications(user_id, dismissed_at, id);`,
+	`CREATE TABLE IF NOT EXISTS user_notification_preferences (
+		user_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		external INTEGER NOT NULL DEFAULT 0,
+		channel_id INTEGER,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (user_id, event_type),
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS mentions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		author_id INTEGER,
+		source_type TEXT NOT NULL,
+		source_id TEXT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		excerpt TEXT NOT NULL DEFAULT '',
+		link TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(source_type, source_id, user_id),
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id, id);`,
+	`CREATE TABLE IF NOT EXISTS risks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		description_md TEXT NOT NULL DEFAULT '',
+		category TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'identified',
+		owner_id INTEGER,
+		threats_json TEXT NOT NULL DEFAULT '[]',
+		vulnerabilities_json TEXT NOT NULL DEFAULT '[]',
+		inherent_likelihood INTEGER NOT NULL DEFAULT 1,
+		inherent_impact INTEGER NOT NULL DEFAULT 1,
+		residual_likelihood INTEGER NOT NULL DEFAULT 1,
+		residual_impact INTEGER NOT NULL DEFAULT 1,
+		treatment TEXT NOT NULL DEFAULT 'mitigate',
+		treatment_plan_md TEXT NOT NULL DEFAULT '',
+		treatment_due_at TIMESTAMP,
+		accepted_by INTEGER,
+		accepted_at TIMESTAMP,
+		acceptance_expires_at TIMESTAMP,
+		acceptance_note TEXT NOT NULL DEFAULT '',
+		review_at TIMESTAMP,
+		tags_json TEXT NOT NULL DEFAULT '[]',
+		created_by INTEGER,
+		updated_by INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
+		deleted_at TIMESTAMP,
+		FOREIGN KEY(owner_id) REFERENCES users(id) ON DELETE SET NULL,
+		FOREIGN KEY(accepted_by) REFERENCES users(id) ON DELETE SET NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_risks_status ON risks(status);`,
+	`CREATE INDEX IF NOT EXISTS idx_risks_owner ON risks(owner_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_risks_deleted_at ON risks(deleted_at);`,
+	`CREATE TABLE IF NOT EXISTS risk_settings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		matrix_json TEXT NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS finding_sla_settings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		policy_json TEXT NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS finding_exceptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		finding_id INTEGER NOT NULL REFERENCES findings(id) ON DELETE CASCADE,
+		requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		approver_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		justification TEXT NOT NULL DEFAULT '',
+		expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		prev_status TEXT NOT NULL DEFAULT '',
+		decision_comment TEXT NOT NULL DEFAULT '',
+		decided_at TIMESTAMP,
+		closed_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_finding_exceptions_finding ON finding_exceptions(finding_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_finding_exceptions_status ON finding_exceptions(status);`,
+	`CREATE INDEX IF NOT EXISTS idx_finding_exceptions_approver ON finding_exceptions(approver_id);`,
+	`CREATE TABLE IF NOT EXISTS asset_relations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+		target_asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+		relation_type TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(source_asset_id, target_asset_id, relation_type)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_asset_relations_source ON asset_relations(source_asset_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_asset_relations_target ON asset_relations(target_asset_id);`,
+	`CREATE TABLE IF NOT EXISTS asset_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		changes_json TEXT NOT NULL DEFAULT '{}',
+		actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_asset_history_asset ON asset_history(asset_id, created_at);`,
+	`CREATE TABLE IF NOT EXISTS custom_fields (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type TEXT NOT NULL,
+		field_key TEXT NOT NULL,
+		label TEXT NOT NULL,
+		field_type TEXT NOT NULL,
+		options_json TEXT NOT NULL DEFAULT '[]',
+		required INTEGER NOT NULL DEFAULT 0,
+		view_roles_json TEXT NOT NULL DEFAULT '[]',
+		edit_roles_json TEXT NOT NULL DEFAULT '[]',
+		position INTEGER NOT NULL DEFAULT 0,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		deleted_at TIMESTAMP,
+		UNIQUE(entity_type, field_key)
+	);`,
+	`CREATE TABLE IF NOT EXISTS custom_field_values (
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		field_id INTEGER NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+		value_text TEXT NOT NULL,
+		updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY(entity_type, entity_id, field_id)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_custom_field_values_field ON custom_field_values(field_id, value_text);`,
+	`CREATE TABLE IF NOT EXISTS saved_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		entity_type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		query TEXT NOT NULL,
+		dashboard INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches(owner_id, entity_type);`,
+	`CREATE TABLE IF NOT EXISTS saved_search_groups (
+		search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+		group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+		PRIMARY KEY(search_id, group_id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS incident_tags (
+		incident_id INTEGER NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		PRIMARY KEY(incident_id, tag)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_incident_tags_tag ON incident_tags(tag);`,
+	`CREATE TABLE IF NOT EXISTS schedule_calendars (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT NOT NULL DEFAULT '',
+		weekend_json TEXT NOT NULL DEFAULT '[0,6]',
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS schedule_calendar_days (
+		calendar_id INTEGER NOT NULL REFERENCES schedule_calendars(id) ON DELETE CASCADE,
+		day TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY(calendar_id, day)
+	);`,
+	`CREATE TABLE IF NOT EXISTS report_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		report_id INTEGER REFERENCES docs(id) ON DELETE SET NULL,
+		template_id INTEGER REFERENCES report_templates(id) ON DELETE SET NULL,
+		period TEXT NOT NULL,
+		cron TEXT NOT NULL DEFAULT '',
+		rrule TEXT NOT NULL,
+		time_of_day TEXT NOT NULL DEFAULT '',
+		timezone TEXT NOT NULL DEFAULT '',
+		format TEXT NOT NULL,
+		language TEXT NOT NULL DEFAULT 'en',
+		recipient_users_json TEXT NOT NULL DEFAULT '[]',
+		recipient_groups_json TEXT NOT NULL DEFAULT '[]',
+		owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		output_doc_id INTEGER REFERENCES docs(id) ON DELETE SET NULL,
+		granted_acl_json TEXT NOT NULL DEFAULT '[]',
+		next_run_at TIMESTAMP,
+		last_run_at TIMESTAMP,
+		last_status TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_report_schedules_next_run ON report_schedules(enabled, next_run_at);`,
+	`CREATE TABLE IF NOT EXISTS report_schedule_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		schedule_id INTEGER NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+		trigger_type TEXT NOT NULL,
+		triggered_by TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		scheduled_for TIMESTAMP,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NOT NULL,
+		period_from TIMESTAMP,
+		period_to TIMESTAMP,
+		report_id INTEGER,
+		snapshot_id INTEGER,
+		output_doc_id INTEGER,
+		output_version INTEGER NOT NULL DEFAULT 0,
+		delivered INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT ''
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_report_schedule_runs_schedule ON report_schedule_runs(schedule_id, started_at);`,
 }
 
 func ApplyMigrations(ctx context.Context, db *sql.DB, logger *utils.Logger) error {
@@ -1005,6 +1359,7 @@ func applySQLiteTestMigrations(ctx context.Context, db *sql.DB, logger *utils.Lo
 		ensureEntityLinksSchema,
 		ensureControlTypes,
 		ensureRuntimeSettingsColumns,
+		ensureFindingSLAColumns,
 	}
 	for _, fn := range post {
 		if err := fn(ctx, db); err != nil {
@@ -1853,6 +2208,7 @@ func ensureTaskColumns(ctx context.Context, db *sql.DB) error {
 		{Name: "business_customer", SQL: "ALTER TABLE tasks ADD COLUMN business_customer TEXT NOT NULL DEFAULT ''"},
 		{Name: "size_estimate", SQL: "ALTER TABLE tasks ADD COLUMN size_estimate INTEGER"},
 		{Name: "subcolumn_id", SQL: "ALTER TABLE tasks ADD COLUMN subcolumn_id INTEGER"},
+		{Name: "start_date", SQL: "ALTER TABLE tasks ADD COLUMN start_date TIMESTAMP"},
 	}
 	for _, c := range cols {
 		exists, err := columnExists(ctx, db, "tasks", c.Name)
@@ -2112,3 +2468,31 @@ func ensureRuntimeSettingsColumns(ctx context.Context, db *sql.DB) error {
 	}
 	return nil
 }
+
+func ensureFindingSLAColumns(ctx context.Context, db *sql.DB) error {
+	type col struct {
+		Name string
+		SQL  string
+	}
+	cols := []col{
+		{Name: "resolved_at", SQL: "ALTER TABLE findings ADD COLUMN resolved_at TIMESTAMP"},
+		{Name: "overdue_at", SQL: "ALTER TABLE findings ADD COLUMN overdue_at TIMESTAMP"},
+		{Name: "escalated_at", SQL: "ALTER TABLE findings ADD COLUMN escalated_at TIMESTAMP"},
+		{Name: "sla_paused_at", SQL: "ALTER TABLE findings ADD COLUMN sla_paused_at TIMESTAMP"},
+	}
+	for _, c := range cols {
+		exists, err := columnExists(ctx, db, "findings", c.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := db.ExecContext(ctx, c.SQL); err != nil {
+				return fmt.Errorf("add column findings.%s: %w", c.Name, err)
+			}
+		}
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_findings_due_at ON findings(due_at)`); err != nil {
+		return fmt.Errorf("index findings.due_at: %w", err)
+	}
+	return nil
+}
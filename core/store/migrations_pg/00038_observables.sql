@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS observables (
+  id BIGSERIAL PRIMARY KEY,
+  entity_type TEXT NOT NULL,
+  entity_id BIGINT NOT NULL,
+  obs_type TEXT NOT NULL,
+  value TEXT NOT NULL,
+  tlp TEXT NOT NULL DEFAULT 'amber',
+  description TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT 'manual',
+  first_seen_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ,
+  created_by BIGINT,
+  updated_by BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(entity_type, entity_id, obs_type, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_observables_entity ON observables(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_observables_value ON observables(obs_type, value);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_observables_value;
+DROP INDEX IF EXISTS idx_observables_entity;
+DROP TABLE IF EXISTS observables;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS incident_impact (
+  incident_id BIGINT PRIMARY KEY REFERENCES incidents(id) ON DELETE CASCADE,
+  started_at TIMESTAMPTZ,
+  detected_at TIMESTAMPTZ,
+  acknowledged_at TIMESTAMPTZ,
+  contained_at TIMESTAMPTZ,
+  resolved_at TIMESTAMPTZ,
+  downtime_minutes INTEGER NOT NULL DEFAULT 0,
+  affected_users INTEGER NOT NULL DEFAULT 0,
+  estimated_cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+  cost_currency TEXT NOT NULL DEFAULT '',
+  updated_by BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_timeline_type ON incident_timeline(event_type, incident_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_incident_timeline_type;
+DROP TABLE IF EXISTS incident_impact;
@@ -0,0 +1,85 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS vulnerabilities (
+  id BIGSERIAL PRIMARY KEY,
+  source TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  aliases_json TEXT NOT NULL DEFAULT '[]',
+  title TEXT NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  severity TEXT NOT NULL DEFAULT 'medium',
+  cvss_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  cvss_vector TEXT NOT NULL DEFAULT '',
+  references_json TEXT NOT NULL DEFAULT '[]',
+  published_at TIMESTAMPTZ,
+  modified_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerabilities_external_id ON vulnerabilities(external_id);
+CREATE INDEX IF NOT EXISTS idx_vulnerabilities_severity ON vulnerabilities(severity);
+
+CREATE TABLE IF NOT EXISTS vulnerability_affected (
+  id BIGSERIAL PRIMARY KEY,
+  vulnerability_id BIGINT NOT NULL REFERENCES vulnerabilities(id) ON DELETE CASCADE,
+  cpe TEXT NOT NULL DEFAULT '',
+  vendor TEXT NOT NULL DEFAULT '',
+  product TEXT NOT NULL,
+  product_key TEXT NOT NULL DEFAULT '',
+  ecosystem TEXT NOT NULL DEFAULT '',
+  versions_json TEXT NOT NULL DEFAULT '[]',
+  version_start_including TEXT NOT NULL DEFAULT '',
+  version_start_excluding TEXT NOT NULL DEFAULT '',
+  version_end_including TEXT NOT NULL DEFAULT '',
+  version_end_excluding TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerability_affected_vuln ON vulnerability_affected(vulnerability_id);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_affected_product ON vulnerability_affected(product_key, vendor);
+
+CREATE TABLE IF NOT EXISTS software_product_aliases (
+  id BIGSERIAL PRIMARY KEY,
+  product_id BIGINT NOT NULL REFERENCES software_products(id) ON DELETE CASCADE,
+  vendor TEXT NOT NULL DEFAULT '',
+  product TEXT NOT NULL,
+  cpe TEXT NOT NULL DEFAULT '',
+  created_by BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(product_id, vendor, product)
+);
+
+CREATE INDEX IF NOT EXISTS idx_software_product_aliases_product ON software_product_aliases(product, vendor);
+
+CREATE TABLE IF NOT EXISTS vulnerability_matches (
+  id BIGSERIAL PRIMARY KEY,
+  vulnerability_id BIGINT NOT NULL REFERENCES vulnerabilities(id) ON DELETE CASCADE,
+  installation_id BIGINT NOT NULL REFERENCES asset_software(id) ON DELETE CASCADE,
+  asset_id BIGINT NOT NULL,
+  product_id BIGINT NOT NULL,
+  version_text TEXT NOT NULL DEFAULT '',
+  finding_id BIGINT,
+  status TEXT NOT NULL DEFAULT 'open',
+  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  closed_at TIMESTAMPTZ,
+  UNIQUE(vulnerability_id, installation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerability_matches_asset ON vulnerability_matches(asset_id, status);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_matches_finding ON vulnerability_matches(finding_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_vulnerability_matches_finding;
+DROP INDEX IF EXISTS idx_vulnerability_matches_asset;
+DROP TABLE IF EXISTS vulnerability_matches;
+DROP INDEX IF EXISTS idx_software_product_aliases_product;
+DROP TABLE IF EXISTS software_product_aliases;
+DROP INDEX IF EXISTS idx_vulnerability_affected_product;
+DROP INDEX IF EXISTS idx_vulnerability_affected_vuln;
+DROP TABLE IF EXISTS vulnerability_affected;
+DROP INDEX IF EXISTS idx_vulnerabilities_severity;
+DROP INDEX IF EXISTS idx_vulnerabilities_external_id;
+DROP TABLE IF EXISTS vulnerabilities;
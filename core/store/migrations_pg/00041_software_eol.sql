@@ -0,0 +1,23 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS software_eol_alerts (
+  id BIGSERIAL PRIMARY KEY,
+  installation_id BIGINT NOT NULL UNIQUE REFERENCES asset_software(id) ON DELETE CASCADE,
+  asset_id BIGINT NOT NULL,
+  product_id BIGINT NOT NULL,
+  version_text TEXT NOT NULL DEFAULT '',
+  eol_date TIMESTAMPTZ NOT NULL,
+  state TEXT NOT NULL DEFAULT 'approaching',
+  finding_id BIGINT,
+  task_id BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_software_eol_alerts_asset ON software_eol_alerts(asset_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_software_eol_alerts_asset;
+DROP TABLE IF EXISTS software_eol_alerts;
@@ -0,0 +1,33 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS user_notifications (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  title TEXT NOT NULL DEFAULT '',
+  body TEXT NOT NULL DEFAULT '',
+  entity_type TEXT NOT NULL DEFAULT '',
+  entity_id TEXT NOT NULL DEFAULT '',
+  link TEXT NOT NULL DEFAULT '',
+  actor_id BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  read_at TIMESTAMPTZ,
+  dismissed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user ON user_notifications(user_id, dismissed_at, id);
+
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  external INTEGER NOT NULL DEFAULT 0,
+  channel_id BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, event_type)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS user_notification_preferences;
+DROP INDEX IF EXISTS idx_user_notifications_user;
+DROP TABLE IF EXISTS user_notifications;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS mentions (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  author_id BIGINT,
+  source_type TEXT NOT NULL,
+  source_id TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  title TEXT NOT NULL DEFAULT '',
+  excerpt TEXT NOT NULL DEFAULT '',
+  link TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (source_type, source_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id, id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_mentions_user;
+DROP TABLE IF EXISTS mentions;
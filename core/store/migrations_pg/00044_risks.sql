@@ -0,0 +1,49 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS risks (
+  id BIGSERIAL PRIMARY KEY,
+  title TEXT NOT NULL,
+  description_md TEXT NOT NULL DEFAULT '',
+  category TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'identified',
+  owner_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  threats_json TEXT NOT NULL DEFAULT '[]',
+  vulnerabilities_json TEXT NOT NULL DEFAULT '[]',
+  inherent_likelihood INTEGER NOT NULL DEFAULT 1,
+  inherent_impact INTEGER NOT NULL DEFAULT 1,
+  residual_likelihood INTEGER NOT NULL DEFAULT 1,
+  residual_impact INTEGER NOT NULL DEFAULT 1,
+  treatment TEXT NOT NULL DEFAULT 'mitigate',
+  treatment_plan_md TEXT NOT NULL DEFAULT '',
+  treatment_due_at TIMESTAMPTZ,
+  accepted_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  accepted_at TIMESTAMPTZ,
+  acceptance_expires_at TIMESTAMPTZ,
+  acceptance_note TEXT NOT NULL DEFAULT '',
+  review_at TIMESTAMPTZ,
+  tags_json TEXT NOT NULL DEFAULT '[]',
+  created_by BIGINT,
+  updated_by BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  version INTEGER NOT NULL DEFAULT 1,
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_risks_status ON risks(status);
+CREATE INDEX IF NOT EXISTS idx_risks_owner ON risks(owner_id);
+CREATE INDEX IF NOT EXISTS idx_risks_deleted_at ON risks(deleted_at);
+
+CREATE TABLE IF NOT EXISTS risk_settings (
+  id BIGSERIAL PRIMARY KEY,
+  matrix_json TEXT NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS risk_settings;
+DROP INDEX IF EXISTS idx_risks_deleted_at;
+DROP INDEX IF EXISTS idx_risks_owner;
+DROP INDEX IF EXISTS idx_risks_status;
+DROP TABLE IF EXISTS risks;
@@ -0,0 +1,48 @@
+-- +goose Up
+
+ALTER TABLE findings ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+ALTER TABLE findings ADD COLUMN IF NOT EXISTS overdue_at TIMESTAMPTZ;
+ALTER TABLE findings ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+ALTER TABLE findings ADD COLUMN IF NOT EXISTS sla_paused_at TIMESTAMPTZ;
+
+UPDATE findings
+SET resolved_at=updated_at
+WHERE resolved_at IS NULL AND status IN ('resolved', 'false_positive');
+
+CREATE INDEX IF NOT EXISTS idx_findings_due_at ON findings(due_at);
+
+CREATE TABLE IF NOT EXISTS finding_sla_settings (
+  id BIGSERIAL PRIMARY KEY,
+  policy_json TEXT NOT NULL DEFAULT '{}',
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS finding_exceptions (
+  id BIGSERIAL PRIMARY KEY,
+  finding_id BIGINT NOT NULL REFERENCES findings(id) ON DELETE CASCADE,
+  requested_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  approver_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  justification TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMPTZ NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  prev_status TEXT NOT NULL DEFAULT '',
+  decision_comment TEXT NOT NULL DEFAULT '',
+  decided_at TIMESTAMPTZ,
+  closed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_finding_exceptions_finding ON finding_exceptions(finding_id);
+CREATE INDEX IF NOT EXISTS idx_finding_exceptions_status ON finding_exceptions(status);
+CREATE INDEX IF NOT EXISTS idx_finding_exceptions_approver ON finding_exceptions(approver_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS finding_exceptions;
+DROP TABLE IF EXISTS finding_sla_settings;
+DROP INDEX IF EXISTS idx_findings_due_at;
+ALTER TABLE findings DROP COLUMN IF EXISTS sla_paused_at;
+ALTER TABLE findings DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE findings DROP COLUMN IF EXISTS overdue_at;
+ALTER TABLE findings DROP COLUMN IF EXISTS resolved_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS asset_relations (
+  id BIGSERIAL PRIMARY KEY,
+  source_asset_id BIGINT NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+  target_asset_id BIGINT NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+  relation_type TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  UNIQUE(source_asset_id, target_asset_id, relation_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_relations_source ON asset_relations(source_asset_id);
+CREATE INDEX IF NOT EXISTS idx_asset_relations_target ON asset_relations(target_asset_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_asset_relations_target;
+DROP INDEX IF EXISTS idx_asset_relations_source;
+DROP TABLE IF EXISTS asset_relations;
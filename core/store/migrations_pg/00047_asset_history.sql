@@ -0,0 +1,19 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS asset_history (
+  id BIGSERIAL PRIMARY KEY,
+  asset_id BIGINT NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+  entity_type TEXT NOT NULL,
+  entity_id BIGINT NOT NULL,
+  action TEXT NOT NULL,
+  changes_json TEXT NOT NULL DEFAULT '{}',
+  actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_asset_history_asset ON asset_history(asset_id, created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_asset_history_asset;
+DROP TABLE IF EXISTS asset_history;
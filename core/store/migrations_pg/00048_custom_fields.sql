@@ -0,0 +1,37 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS custom_fields (
+  id BIGSERIAL PRIMARY KEY,
+  entity_type TEXT NOT NULL,
+  field_key TEXT NOT NULL,
+  label TEXT NOT NULL,
+  field_type TEXT NOT NULL,
+  options_json TEXT NOT NULL DEFAULT '[]',
+  required INTEGER NOT NULL DEFAULT 0,
+  view_roles_json TEXT NOT NULL DEFAULT '[]',
+  edit_roles_json TEXT NOT NULL DEFAULT '[]',
+  position INTEGER NOT NULL DEFAULT 0,
+  created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  deleted_at TIMESTAMPTZ,
+  UNIQUE(entity_type, field_key)
+);
+
+CREATE TABLE IF NOT EXISTS custom_field_values (
+  entity_type TEXT NOT NULL,
+  entity_id BIGINT NOT NULL,
+  field_id BIGINT NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+  value_text TEXT NOT NULL,
+  updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY(entity_type, entity_id, field_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_field_values_field ON custom_field_values(field_id, value_text);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_custom_field_values_field;
+DROP TABLE IF EXISTS custom_field_values;
+DROP TABLE IF EXISTS custom_fields;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id BIGSERIAL PRIMARY KEY,
+  owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  entity_type TEXT NOT NULL,
+  name TEXT NOT NULL,
+  query TEXT NOT NULL,
+  dashboard INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches(owner_id, entity_type);
+
+CREATE TABLE IF NOT EXISTS saved_search_groups (
+  search_id BIGINT NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+  group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+  PRIMARY KEY(search_id, group_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS saved_search_groups;
+DROP INDEX IF EXISTS idx_saved_searches_owner;
+DROP TABLE IF EXISTS saved_searches;
@@ -0,0 +1,40 @@
+-- +goose Up
+
+-- Full-text indexes of the global search. The expressions match searchSources in
+-- search_store.go; PostgreSQL keeps them current on every write. The docs_fts index
+-- also serves the document search of the docs module.
+CREATE INDEX IF NOT EXISTS idx_docs_search_title ON docs USING GIN (to_tsvector('simple', coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS idx_docs_fts_search_content ON docs_fts USING GIN (to_tsvector('simple', content));
+CREATE INDEX IF NOT EXISTS idx_incidents_search_title ON incidents USING GIN (to_tsvector('simple', coalesce(reg_no, '') || ' ' || coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS idx_incidents_search_body ON incidents USING GIN (to_tsvector('simple', coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_tasks_search_title ON tasks USING GIN (to_tsvector('simple', coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS idx_tasks_search_body ON tasks USING GIN (to_tsvector('simple', coalesce(description, '') || ' ' || coalesce(result, '')));
+CREATE INDEX IF NOT EXISTS idx_findings_search_title ON findings USING GIN (to_tsvector('simple', coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS idx_findings_search_body ON findings USING GIN (to_tsvector('simple', coalesce(description_md, '')));
+CREATE INDEX IF NOT EXISTS idx_assets_search_title ON assets USING GIN (to_tsvector('simple', coalesce(name, '')));
+CREATE INDEX IF NOT EXISTS idx_assets_search_body ON assets USING GIN (to_tsvector('simple', coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_software_products_search_title ON software_products USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(vendor, '')));
+CREATE INDEX IF NOT EXISTS idx_software_products_search_body ON software_products USING GIN (to_tsvector('simple', coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_controls_search_title ON controls USING GIN (to_tsvector('simple', coalesce(code, '') || ' ' || coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS idx_controls_search_body ON controls USING GIN (to_tsvector('simple', coalesce(description_md, '')));
+CREATE INDEX IF NOT EXISTS idx_monitors_search_title ON monitors USING GIN (to_tsvector('simple', coalesce(name, '')));
+CREATE INDEX IF NOT EXISTS idx_monitors_search_body ON monitors USING GIN (to_tsvector('simple', coalesce(url, '') || ' ' || coalesce(host, '')));
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_monitors_search_body;
+DROP INDEX IF EXISTS idx_monitors_search_title;
+DROP INDEX IF EXISTS idx_controls_search_body;
+DROP INDEX IF EXISTS idx_controls_search_title;
+DROP INDEX IF EXISTS idx_software_products_search_body;
+DROP INDEX IF EXISTS idx_software_products_search_title;
+DROP INDEX IF EXISTS idx_assets_search_body;
+DROP INDEX IF EXISTS idx_assets_search_title;
+DROP INDEX IF EXISTS idx_findings_search_body;
+DROP INDEX IF EXISTS idx_findings_search_title;
+DROP INDEX IF EXISTS idx_tasks_search_body;
+DROP INDEX IF EXISTS idx_tasks_search_title;
+DROP INDEX IF EXISTS idx_incidents_search_body;
+DROP INDEX IF EXISTS idx_incidents_search_title;
+DROP INDEX IF EXISTS idx_docs_fts_search_content;
+DROP INDEX IF EXISTS idx_docs_search_title;
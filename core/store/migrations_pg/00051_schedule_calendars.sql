@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS schedule_calendars (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  weekend_json TEXT NOT NULL DEFAULT '[0,6]',
+  created_by BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS schedule_calendar_days (
+  calendar_id BIGINT NOT NULL REFERENCES schedule_calendars(id) ON DELETE CASCADE,
+  day TEXT NOT NULL,
+  name TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY(calendar_id, day)
+);
+
+ALTER TABLE backup_plans
+  ADD COLUMN IF NOT EXISTS schedule_timezone TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS schedule_rrule TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS schedule_shift TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS schedule_calendar_id BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+
+ALTER TABLE backup_plans
+  DROP COLUMN IF EXISTS schedule_calendar_id,
+  DROP COLUMN IF EXISTS schedule_shift,
+  DROP COLUMN IF EXISTS schedule_rrule,
+  DROP COLUMN IF EXISTS schedule_timezone;
+
+DROP TABLE IF EXISTS schedule_calendar_days;
+DROP TABLE IF EXISTS schedule_calendars;
@@ -0,0 +1,46 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS task_automation_rules (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  board_id INTEGER NOT NULL REFERENCES task_boards(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  trigger_type TEXT NOT NULL,
+  trigger_column_id INTEGER,
+  conditions_json TEXT NOT NULL DEFAULT '{}',
+  actions_json TEXT NOT NULL DEFAULT '[]',
+  is_active INTEGER NOT NULL DEFAULT 1,
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS task_automation_events (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  board_id INTEGER NOT NULL,
+  task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  trigger_type TEXT NOT NULL,
+  ref_id INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL,
+  processed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS task_automation_runs (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  rule_id INTEGER NOT NULL REFERENCES task_automation_rules(id) ON DELETE CASCADE,
+  task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  event_key TEXT NOT NULL,
+  status TEXT NOT NULL,
+  result TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  UNIQUE(rule_id, task_id, event_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_automation_rules_board ON task_automation_rules(board_id, trigger_type);
+CREATE INDEX IF NOT EXISTS idx_task_automation_events_pending ON task_automation_events(processed_at);
+CREATE INDEX IF NOT EXISTS idx_task_automation_runs_task ON task_automation_runs(task_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS task_automation_runs;
+DROP TABLE IF EXISTS task_automation_events;
+DROP TABLE IF EXISTS task_automation_rules;
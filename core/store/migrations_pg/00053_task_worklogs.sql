@@ -0,0 +1,36 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS task_worklogs (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  work_date TIMESTAMP NOT NULL,
+  minutes INTEGER NOT NULL,
+  comment TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT 'manual',
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS task_timers (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  started_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS task_time_estimates (
+  task_id INTEGER PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+  remaining_minutes INTEGER NOT NULL,
+  updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_worklogs_task ON task_worklogs(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_worklogs_user_date ON task_worklogs(user_id, work_date);
+CREATE INDEX IF NOT EXISTS idx_task_worklogs_date ON task_worklogs(work_date);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS task_time_estimates;
+DROP TABLE IF EXISTS task_timers;
+DROP TABLE IF EXISTS task_worklogs;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS task_activity (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  action TEXT NOT NULL,
+  field TEXT NOT NULL DEFAULT '',
+  old_value TEXT NOT NULL DEFAULT '',
+  new_value TEXT NOT NULL DEFAULT '',
+  details_json TEXT NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_activity_task ON task_activity(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_task_activity_action ON task_activity(action, created_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS task_activity;
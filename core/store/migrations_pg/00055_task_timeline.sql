@@ -0,0 +1,21 @@
+-- +goose Up
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS start_date TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  predecessor_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  successor_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  dep_type TEXT NOT NULL DEFAULT 'fs',
+  lag_days INTEGER NOT NULL DEFAULT 0,
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL,
+  UNIQUE(predecessor_id, successor_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_successor ON task_dependencies(successor_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS task_dependencies;
+ALTER TABLE tasks DROP COLUMN IF EXISTS start_date;
@@ -0,0 +1,52 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  name TEXT NOT NULL,
+  report_id INTEGER REFERENCES docs(id) ON DELETE SET NULL,
+  template_id INTEGER REFERENCES report_templates(id) ON DELETE SET NULL,
+  period TEXT NOT NULL,
+  cron TEXT NOT NULL DEFAULT '',
+  rrule TEXT NOT NULL,
+  time_of_day TEXT NOT NULL DEFAULT '',
+  timezone TEXT NOT NULL DEFAULT '',
+  format TEXT NOT NULL,
+  recipient_users_json TEXT NOT NULL DEFAULT '[]',
+  recipient_groups_json TEXT NOT NULL DEFAULT '[]',
+  owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  enabled INTEGER NOT NULL DEFAULT 1,
+  output_doc_id INTEGER REFERENCES docs(id) ON DELETE SET NULL,
+  next_run_at TIMESTAMP,
+  last_run_at TIMESTAMP,
+  last_status TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_next_run ON report_schedules(enabled, next_run_at);
+
+CREATE TABLE IF NOT EXISTS report_schedule_runs (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  schedule_id INTEGER NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+  trigger_type TEXT NOT NULL,
+  triggered_by TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  scheduled_for TIMESTAMP,
+  started_at TIMESTAMP NOT NULL,
+  finished_at TIMESTAMP NOT NULL,
+  period_from TIMESTAMP,
+  period_to TIMESTAMP,
+  report_id INTEGER,
+  snapshot_id INTEGER,
+  output_doc_id INTEGER,
+  output_version INTEGER NOT NULL DEFAULT 0,
+  delivered INTEGER NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedule_runs_schedule ON report_schedule_runs(schedule_id, started_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS report_schedule_runs;
+DROP TABLE IF EXISTS report_schedules;
@@ -0,0 +1,22 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS incident_tags (
+  incident_id BIGINT NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+  tag TEXT NOT NULL,
+  PRIMARY KEY(incident_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_tags_tag ON incident_tags(tag);
+
+INSERT INTO incident_tags(incident_id, tag)
+SELECT DISTINCT i.id, t.tag
+FROM incidents i
+CROSS JOIN LATERAL jsonb_array_elements_text(
+  CASE WHEN jsonb_typeof(i.meta_json::jsonb -> 'tags') = 'array' THEN i.meta_json::jsonb -> 'tags' ELSE '[]'::jsonb END
+) AS t(tag)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_incident_tags_tag;
+DROP TABLE IF EXISTS incident_tags;
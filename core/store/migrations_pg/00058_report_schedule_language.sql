@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE report_schedules ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT 'en';
+
+-- +goose Down
+
+ALTER TABLE report_schedules DROP COLUMN IF EXISTS language;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+ALTER TABLE user_notifications ADD COLUMN IF NOT EXISTS title_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_notifications ADD COLUMN IF NOT EXISTS body_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_notifications ADD COLUMN IF NOT EXISTS params_json TEXT NOT NULL DEFAULT '{}';
+
+-- +goose Down
+
+ALTER TABLE user_notifications DROP COLUMN IF EXISTS params_json;
+ALTER TABLE user_notifications DROP COLUMN IF EXISTS body_key;
+ALTER TABLE user_notifications DROP COLUMN IF EXISTS title_key;
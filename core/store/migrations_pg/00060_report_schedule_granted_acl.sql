@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE report_schedules ADD COLUMN IF NOT EXISTS granted_acl_json TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+
+ALTER TABLE report_schedules DROP COLUMN IF EXISTS granted_acl_json;
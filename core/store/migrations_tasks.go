@@ -63,6 +63,7 @@ func taskMigrations() []string {
 		checklist TEXT NOT NULL DEFAULT '[]',
 		size_estimate INTEGER,
 		created_by INTEGER,
+		start_date TIMESTAMP,
 		due_date TIMESTAMP,
 		created_at TIMESTAMP NOT NULL,
 		updated_at TIMESTAMP NOT NULL,
@@ -258,6 +259,106 @@ func taskMigrations() []string {
 		FOREIGN KEY(space_id) REFERENCES task_spaces(id) ON DELETE CASCADE
 	);`,
 		`CREATE INDEX IF NOT EXISTS idx_task_board_layouts_space ON task_board_layouts(space_id);`,
+		`CREATE TABLE IF NOT EXISTS task_automation_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		board_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		trigger_type TEXT NOT NULL,
+		trigger_column_id INTEGER,
+		conditions_json TEXT NOT NULL DEFAULT '{}',
+		actions_json TEXT NOT NULL DEFAULT '[]',
+		is_active INTEGER NOT NULL DEFAULT 1,
+		created_by INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(board_id) REFERENCES task_boards(id) ON DELETE CASCADE,
+		FOREIGN KEY(created_by) REFERENCES users(id) ON DELETE SET NULL
+	);`,
+		`CREATE TABLE IF NOT EXISTS task_automation_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		board_id INTEGER NOT NULL,
+		task_id INTEGER NOT NULL,
+		trigger_type TEXT NOT NULL,
+		ref_id INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		processed_at TIMESTAMP,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);`,
+		`CREATE TABLE IF NOT EXISTS task_automation_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rule_id INTEGER NOT NULL,
+		task_id INTEGER NOT NULL,
+		event_key TEXT NOT NULL,
+		status TEXT NOT NULL,
+		result TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(rule_id, task_id, event_key),
+		FOREIGN KEY(rule_id) REFERENCES task_automation_rules(id) ON DELETE CASCADE,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_automation_rules_board ON task_automation_rules(board_id, trigger_type);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_automation_events_pending ON task_automation_events(processed_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_automation_runs_task ON task_automation_runs(task_id);`,
+		`CREATE TABLE IF NOT EXISTS task_worklogs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		work_date TIMESTAMP NOT NULL,
+		minutes INTEGER NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT 'manual',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);`,
+		`CREATE TABLE IF NOT EXISTS task_timers (
+		user_id INTEGER PRIMARY KEY,
+		task_id INTEGER NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);`,
+		`CREATE TABLE IF NOT EXISTS task_time_estimates (
+		task_id INTEGER PRIMARY KEY,
+		remaining_minutes INTEGER NOT NULL,
+		updated_by INTEGER,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(updated_by) REFERENCES users(id) ON DELETE SET NULL
+	);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_worklogs_task ON task_worklogs(task_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_worklogs_user_date ON task_worklogs(user_id, work_date);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_worklogs_date ON task_worklogs(work_date);`,
+		`CREATE TABLE IF NOT EXISTS task_activity (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		actor_id INTEGER,
+		action TEXT NOT NULL,
+		field TEXT NOT NULL DEFAULT '',
+		old_value TEXT NOT NULL DEFAULT '',
+		new_value TEXT NOT NULL DEFAULT '',
+		details_json TEXT NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(actor_id) REFERENCES users(id) ON DELETE SET NULL
+	);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_activity_task ON task_activity(task_id, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_activity_action ON task_activity(action, created_at);`,
+		`CREATE TABLE IF NOT EXISTS task_dependencies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		predecessor_id INTEGER NOT NULL,
+		successor_id INTEGER NOT NULL,
+		dep_type TEXT NOT NULL DEFAULT 'fs',
+		lag_days INTEGER NOT NULL DEFAULT 0,
+		created_by INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(predecessor_id, successor_id),
+		FOREIGN KEY(predecessor_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(successor_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(created_by) REFERENCES users(id) ON DELETE SET NULL
+	);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_dependencies_successor ON task_dependencies(successor_id);`,
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"berkut-scc/core/schedule"
 	"github.com/robfig/cron/v3"
 )
 
@@ -22,8 +23,8 @@ type maintenanceRange struct {
 	End   time.Time
 }
 
-func maintenanceActiveAt(m MonitorMaintenance, now time.Time) bool {
-	ranges := maintenanceWindowsWithin(m, now.Add(-time.Minute), now.Add(time.Minute))
+func maintenanceActiveAt(m MonitorMaintenance, cal *schedule.Calendar, now time.Time) bool {
+	ranges := maintenanceWindowsWithin(m, cal, now.Add(-time.Minute), now.Add(time.Minute))
 	for _, rng := range ranges {
 		if (now.After(rng.Start) || now.Equal(rng.Start)) && now.Before(rng.End) {
 			return true
@@ -32,7 +33,9 @@ func maintenanceActiveAt(m MonitorMaintenance, now time.Time) bool {
 	return false
 }
 
-func maintenanceWindowsWithin(m MonitorMaintenance, since, until time.Time) []maintenanceRange {
+// maintenanceWindowsWithin returns the windows of m overlapping [since, until); cal is
+// the business calendar used to shift RRULE windows.
+func maintenanceWindowsWithin(m MonitorMaintenance, cal *schedule.Calendar, since, until time.Time) []maintenanceRange {
 	if !until.After(since) || !m.IsActive {
 		return nil
 	}
@@ -92,7 +95,7 @@ func maintenanceWindowsWithin(m MonitorMaintenance, since, until time.Time) []ma
 			return day.Day() == last.Day()
 		})
 	case maintenanceStrategyRRule:
-		return windowsForLegacyRRule(m, since.UTC(), until.UTC())
+		return windowsForRRule(m, cal, since.UTC(), until.UTC())
 	default:
 		return overlapSingle(m.StartsAt.UTC(), m.EndsAt.UTC(), since.UTC(), until.UTC())
 	}
@@ -183,30 +186,29 @@ func windowsForDailyStrategy(m MonitorMaintenance, since, until time.Time, accep
 	return mergeMaintenanceRanges(out)
 }
 
-func windowsForLegacyRRule(m MonitorMaintenance, since, until time.Time) []maintenanceRange {
+// windowsForRRule expands an RFC 5545 rule from StartsAt in the maintenance timezone;
+// every occurrence lasts as long as the first window.
+func windowsForRRule(m MonitorMaintenance, cal *schedule.Calendar, since, until time.Time) []maintenanceRange {
 	if !m.IsRecurring {
 		return overlapSingle(m.StartsAt.UTC(), m.EndsAt.UTC(), since.UTC(), until.UTC())
 	}
-	spec, err := parseRRule(m.RRuleText)
+	rr, err := schedule.ParseRRule(m.RRuleText)
 	if err != nil {
 		return nil
 	}
 	loc := maintenanceLocation(m.Timezone)
+	exdates, err := schedule.ParseExDates(m.Schedule.ExDates, loc)
+	if err != nil {
+		return nil
+	}
 	duration := m.EndsAt.Sub(m.StartsAt)
 	if duration <= 0 {
 		duration = time.Hour
 	}
-	startDay := dateOnly(m.StartsAt.In(loc))
-	cursor := dateOnly(since.In(loc).Add(-24 * time.Hour))
-	last := dateOnly(until.In(loc).Add(24 * time.Hour))
+	rule := &schedule.Rule{Start: m.StartsAt.In(loc), RRule: rr, ExDates: exdates, Shift: m.Schedule.Shift, Calendar: cal}
 	var out []maintenanceRange
-	for !cursor.After(last) {
-		if rruleMatchesDay(spec, startDay, cursor) {
-			startLocal := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), m.StartsAt.In(loc).Hour(), m.StartsAt.In(loc).Minute(), m.StartsAt.In(loc).Second(), 0, loc)
-			endLocal := startLocal.Add(duration)
-			out = append(out, overlapSingle(startLocal.UTC(), endLocal.UTC(), since.UTC(), until.UTC())...)
-		}
-		cursor = cursor.Add(24 * time.Hour)
+	for _, start := range rule.Between(since.Add(-duration), until) {
+		out = append(out, overlapSingle(start.UTC(), start.Add(duration).UTC(), since.UTC(), until.UTC())...)
 	}
 	return mergeMaintenanceRanges(out)
 }
@@ -304,6 +306,10 @@ func parseHHMM(raw string) (int, int, bool) {
 	return parsed.Hour(), parsed.Minute(), true
 }
 
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 func scheduleWeekdayToGo(day int) time.Weekday {
 	switch day {
 	case 1:
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"strings"
 	"time"
+
+	"berkut-scc/core/schedule"
 )
 
 func (s *monitoringStore) ListMaintenance(ctx context.Context, filter MaintenanceFilter) ([]MonitorMaintenance, error) {
@@ -135,7 +137,7 @@ func (s *monitoringStore) ActiveMaintenanceFor(ctx context.Context, monitorID in
 		if !maintenanceAppliesToMonitor(*item, monitorID, tags) {
 			continue
 		}
-		if maintenanceActiveAt(*item, now) {
+		if maintenanceActiveAt(*item, s.maintenanceCalendar(ctx, *item), now) {
 			res = append(res, *item)
 		}
 	}
@@ -155,7 +157,7 @@ func (s *monitoringStore) MaintenanceWindowsFor(ctx context.Context, monitorID i
 		if !maintenanceAppliesToMonitor(item, monitorID, tags) {
 			continue
 		}
-		ranges := maintenanceWindowsWithin(item, since, until)
+		ranges := maintenanceWindowsWithin(item, s.maintenanceCalendar(ctx, item), since, until)
 		for _, rng := range ranges {
 			windows = append(windows, MaintenanceWindow{Start: rng.Start, End: rng.End})
 		}
@@ -163,6 +165,19 @@ func (s *monitoringStore) MaintenanceWindowsFor(ctx context.Context, monitorID i
 	return mergeMaintenanceWindows(windows), nil
 }
 
+// maintenanceCalendar loads the business calendar of a maintenance; a missing calendar
+// falls back to weekends only.
+func (s *monitoringStore) maintenanceCalendar(ctx context.Context, m MonitorMaintenance) *schedule.Calendar {
+	if m.Schedule.CalendarID <= 0 || m.Schedule.Shift == schedule.ShiftNone {
+		return nil
+	}
+	cal, err := NewScheduleCalendarsStore(s.db).BusinessCalendar(ctx, m.Schedule.CalendarID)
+	if err != nil {
+		return nil
+	}
+	return cal
+}
+
 func scanMaintenance(row interface {
 	Scan(dest ...any) error
 }) (*MonitorMaintenance, error) {
@@ -164,6 +164,9 @@ type MaintenanceSchedule struct {
 	WindowEnd      string     `json:"window_end,omitempty"`
 	ActiveFrom     *time.Time `json:"active_from,omitempty"`
 	ActiveUntil    *time.Time `json:"active_until,omitempty"`
+	ExDates        []string   `json:"exdates,omitempty"`
+	Shift          string     `json:"shift,omitempty"`
+	CalendarID     int64      `json:"calendar_id,omitempty"`
 }
 
 type MaintenanceWindow struct {
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Notification is an inbox item. TitleKey and BodyKey are GUI i18n keys rendered with Params in
+// the reader's language; Title and Body hold the same text in English for external channels and
+// clients without the keys.
+type Notification struct {
+	ID          int64             `json:"id"`
+	UserID      int64             `json:"user_id"`
+	EventType   string            `json:"event_type"`
+	Title       string            `json:"title"`
+	Body        string            `json:"body"`
+	TitleKey    string            `json:"title_key,omitempty"`
+	BodyKey     string            `json:"body_key,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	EntityType  string            `json:"entity_type"`
+	EntityID    string            `json:"entity_id"`
+	Link        string            `json:"link"`
+	ActorID     *int64            `json:"actor_id,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ReadAt      *time.Time        `json:"read_at,omitempty"`
+	DismissedAt *time.Time        `json:"dismissed_at,omitempty"`
+}
+
+type NotificationFilter struct {
+	UnreadOnly       bool
+	IncludeDismissed bool
+	EventType        string
+	AfterID          int64
+	Limit            int
+	Offset           int
+}
+
+type NotificationPreference struct {
+	EventType string `json:"event_type"`
+	External  bool   `json:"external"`
+	ChannelID *int64 `json:"channel_id,omitempty"`
+}
+
+type NotificationsStore interface {
+	CreateNotification(ctx context.Context, n *Notification) (int64, error)
+	ListNotifications(ctx context.Context, userID int64, filter NotificationFilter) ([]Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int, error)
+	// MarkNotificationsRead and DismissNotifications apply to all of the user's notifications when ids is empty.
+	MarkNotificationsRead(ctx context.Context, userID int64, ids []int64, at time.Time) (int, error)
+	DismissNotifications(ctx context.Context, userID int64, ids []int64, at time.Time) (int, error)
+	ListNotificationPreferences(ctx context.Context, userID int64) ([]NotificationPreference, error)
+	SaveNotificationPreferences(ctx context.Context, userID int64, prefs []NotificationPreference) error
+}
+
+type notificationsStore struct {
+	db *sql.DB
+}
+
+func NewNotificationsStore(db *sql.DB) NotificationsStore {
+	return &notificationsStore{db: db}
+}
+
+func (s *notificationsStore) CreateNotification(ctx context.Context, n *Notification) (int64, error) {
+	if n == nil || n.UserID <= 0 || strings.TrimSpace(n.EventType) == "" {
+		return 0, errors.New("invalid notification")
+	}
+	n.EventType = strings.ToLower(strings.TrimSpace(n.EventType))
+	n.CreatedAt = time.Now().UTC()
+	params := "{}"
+	if len(n.Params) > 0 {
+		raw, err := json.Marshal(n.Params)
+		if err != nil {
+			return 0, err
+		}
+		params = string(raw)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_notifications(user_id, event_type, title, body, title_key, body_key, params_json, entity_type, entity_id, link, actor_id, created_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`,
+		n.UserID, n.EventType, strings.TrimSpace(n.Title), strings.TrimSpace(n.Body), strings.TrimSpace(n.TitleKey), strings.TrimSpace(n.BodyKey), params,
+		strings.TrimSpace(n.EntityType), strings.TrimSpace(n.EntityID), strings.TrimSpace(n.Link), nullableID(n.ActorID), n.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	n.ID = id
+	return id, nil
+}
+
+func (s *notificationsStore) ListNotifications(ctx context.Context, userID int64, filter NotificationFilter) ([]Notification, error) {
+	query := `
+		SELECT id, user_id, event_type, title, body, title_key, body_key, params_json, entity_type, entity_id, link, actor_id, created_at, read_at, dismissed_at
+		FROM user_notifications
+		WHERE user_id=?`
+	args := []any{userID}
+	if !filter.IncludeDismissed {
+		query += " AND dismissed_at IS NULL"
+	}
+	if filter.UnreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.EventType)); v != "" {
+		query += " AND event_type=?"
+		args = append(args, v)
+	}
+	if filter.AfterID > 0 {
+		query += " AND id>?"
+		args = append(args, filter.AfterID)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, filter.Offset)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Notification
+	for rows.Next() {
+		var n Notification
+		var params string
+		var actor sql.NullInt64
+		var readAt, dismissedAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.EventType, &n.Title, &n.Body, &n.TitleKey, &n.BodyKey, &params, &n.EntityType, &n.EntityID, &n.Link, &actor, &n.CreatedAt, &readAt, &dismissedAt); err != nil {
+			return nil, err
+		}
+		if params != "" && params != "{}" {
+			_ = json.Unmarshal([]byte(params), &n.Params)
+		}
+		if actor.Valid {
+			v := actor.Int64
+			n.ActorID = &v
+		}
+		n.ReadAt = nullTimePtr(readAt)
+		n.DismissedAt = nullTimePtr(dismissedAt)
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func (s *notificationsStore) CountUnreadNotifications(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM user_notifications
+		WHERE user_id=? AND read_at IS NULL AND dismissed_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+func (s *notificationsStore) MarkNotificationsRead(ctx context.Context, userID int64, ids []int64, at time.Time) (int, error) {
+	return s.stamp(ctx, "read_at", userID, ids, at)
+}
+
+func (s *notificationsStore) DismissNotifications(ctx context.Context, userID int64, ids []int64, at time.Time) (int, error) {
+	return s.stamp(ctx, "dismissed_at", userID, ids, at)
+}
+
+// stamp sets column (read_at or dismissed_at) on the user's notifications that do not have it yet.
+func (s *notificationsStore) stamp(ctx context.Context, column string, userID int64, ids []int64, at time.Time) (int, error) {
+	query := `UPDATE user_notifications SET ` + column + `=? WHERE user_id=? AND ` + column + ` IS NULL`
+	args := []any{at.UTC(), userID}
+	if len(ids) > 0 {
+		query += ` AND id IN (` + placeholders(len(ids)) + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (s *notificationsStore) ListNotificationPreferences(ctx context.Context, userID int64) ([]NotificationPreference, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT event_type, external, channel_id
+		FROM user_notification_preferences
+		WHERE user_id=?
+		ORDER BY event_type ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []NotificationPreference
+	for rows.Next() {
+		var p NotificationPreference
+		var external int
+		var channel sql.NullInt64
+		if err := rows.Scan(&p.EventType, &external, &channel); err != nil {
+			return nil, err
+		}
+		p.External = external == 1
+		if channel.Valid {
+			v := channel.Int64
+			p.ChannelID = &v
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// SaveNotificationPreferences replaces the user's preferences with prefs.
+func (s *notificationsStore) SaveNotificationPreferences(ctx context.Context, userID int64, prefs []NotificationPreference) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_notification_preferences WHERE user_id=?`, userID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, p := range prefs {
+		eventType := strings.ToLower(strings.TrimSpace(p.EventType))
+		if eventType == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_notification_preferences(user_id, event_type, external, channel_id, updated_at)
+			VALUES(?,?,?,?,?)
+			ON CONFLICT(user_id, event_type) DO UPDATE SET external=excluded.external, channel_id=excluded.channel_id, updated_at=excluded.updated_at`,
+			userID, eventType, boolToInt(p.External), nullableID(p.ChannelID), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Observable struct {
+	ID          int64      `json:"id"`
+	EntityType  string     `json:"entity_type"`
+	EntityID    int64      `json:"entity_id"`
+	Type        string     `json:"type"`
+	Value       string     `json:"value"`
+	TLP         string     `json:"tlp"`
+	Description string     `json:"description"`
+	Source      string     `json:"source"`
+	FirstSeenAt *time.Time `json:"first_seen_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+	CreatedBy   *int64     `json:"created_by,omitempty"`
+	UpdatedBy   *int64     `json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ObservableKey struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type ObservablesStore interface {
+	ListObservables(ctx context.Context, entityType string, entityID int64) ([]Observable, error)
+	GetObservable(ctx context.Context, id int64) (*Observable, error)
+	// UpsertObservable inserts a new observable or merges it into an existing one with the same
+	// entity/type/value, widening the first/last seen window. Returns true when a row was created.
+	UpsertObservable(ctx context.Context, o *Observable) (bool, error)
+	UpdateObservable(ctx context.Context, o *Observable) error
+	DeleteObservable(ctx context.Context, id int64) error
+	FindObservablesByKeys(ctx context.Context, keys []ObservableKey) ([]Observable, error)
+	FindAssetIDsByIP(ctx context.Context, ip string) ([]int64, error)
+}
+
+type observablesStore struct {
+	db *sql.DB
+}
+
+func NewObservablesStore(db *sql.DB) ObservablesStore {
+	return &observablesStore{db: db}
+}
+
+const observableColumns = `id, entity_type, entity_id, obs_type, value, tlp, description, source, first_seen_at, last_seen_at,
+		       created_by, updated_by, created_at, updated_at`
+
+func (s *observablesStore) ListObservables(ctx context.Context, entityType string, entityID int64) ([]Observable, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+observableColumns+`
+		FROM observables
+		WHERE entity_type=? AND entity_id=?
+		ORDER BY obs_type ASC, value ASC, id ASC`,
+		strings.ToLower(strings.TrimSpace(entityType)), entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanObservableRows(rows)
+}
+
+func (s *observablesStore) GetObservable(ctx context.Context, id int64) (*Observable, error) {
+	if id <= 0 {
+		return nil, errors.New("bad id")
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT `+observableColumns+` FROM observables WHERE id=?`, id)
+	o, err := scanObservable(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *observablesStore) UpsertObservable(ctx context.Context, o *Observable) (bool, error) {
+	if o == nil || o.EntityID <= 0 || strings.TrimSpace(o.EntityType) == "" {
+		return false, errors.New("invalid observable")
+	}
+	o.EntityType = strings.ToLower(strings.TrimSpace(o.EntityType))
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	row := tx.QueryRowContext(ctx, `
+		SELECT `+observableColumns+`
+		FROM observables
+		WHERE entity_type=? AND entity_id=? AND obs_type=? AND value=?`,
+		o.EntityType, o.EntityID, o.Type, o.Value)
+	existing, err := scanObservable(row)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	now := time.Now().UTC()
+	if existing != nil {
+		first := earliestTime(existing.FirstSeenAt, o.FirstSeenAt)
+		last := latestTime(existing.LastSeenAt, o.LastSeenAt)
+		tlp := existing.TLP
+		if strings.TrimSpace(o.TLP) != "" {
+			tlp = o.TLP
+		}
+		desc := existing.Description
+		if strings.TrimSpace(o.Description) != "" {
+			desc = strings.TrimSpace(o.Description)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE observables SET tlp=?, description=?, first_seen_at=?, last_seen_at=?, updated_by=?, updated_at=?
+			WHERE id=?`,
+			tlp, desc, nullableTime(first), nullableTime(last), nullableID(o.UpdatedBy), now, existing.ID); err != nil {
+			return false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+		o.ID = existing.ID
+		o.TLP = tlp
+		o.Description = desc
+		o.FirstSeenAt = first
+		o.LastSeenAt = last
+		o.CreatedAt = existing.CreatedAt
+		o.UpdatedAt = now
+		return false, nil
+	}
+	source := strings.ToLower(strings.TrimSpace(o.Source))
+	if source == "" {
+		source = "manual"
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO observables(entity_type, entity_id, obs_type, value, tlp, description, source, first_seen_at, last_seen_at, created_by, updated_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		o.EntityType, o.EntityID, o.Type, o.Value, o.TLP, strings.TrimSpace(o.Description), source,
+		nullableTime(o.FirstSeenAt), nullableTime(o.LastSeenAt), nullableID(o.CreatedBy), nullableID(o.UpdatedBy), now, now)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	id, _ := res.LastInsertId()
+	o.ID = id
+	o.Source = source
+	o.CreatedAt = now
+	o.UpdatedAt = now
+	return true, nil
+}
+
+func (s *observablesStore) UpdateObservable(ctx context.Context, o *Observable) error {
+	if o == nil || o.ID <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE observables SET tlp=?, description=?, first_seen_at=?, last_seen_at=?, updated_by=?, updated_at=?
+		WHERE id=?`,
+		o.TLP, strings.TrimSpace(o.Description), nullableTime(o.FirstSeenAt), nullableTime(o.LastSeenAt), nullableID(o.UpdatedBy), now, o.ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	o.UpdatedAt = now
+	return nil
+}
+
+func (s *observablesStore) DeleteObservable(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM observables WHERE id=?`, id)
+	return err
+}
+
+func (s *observablesStore) FindObservablesByKeys(ctx context.Context, keys []ObservableKey) ([]Observable, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var clauses []string
+	var args []any
+	for _, k := range keys {
+		if strings.TrimSpace(k.Type) == "" || strings.TrimSpace(k.Value) == "" {
+			continue
+		}
+		clauses = append(clauses, "(obs_type=? AND value=?)")
+		args = append(args, k.Type, k.Value)
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+observableColumns+`
+		FROM observables
+		WHERE `+strings.Join(clauses, " OR ")+`
+		ORDER BY obs_type ASC, value ASC, entity_type ASC, entity_id ASC
+		LIMIT 2000`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanObservableRows(rows)
+}
+
+func (s *observablesStore) FindAssetIDsByIP(ctx context.Context, ip string) ([]int64, error) {
+	ip = strings.TrimSpace(ip)
+	if ip == "" {
+		return nil, nil
+	}
+	// ip_addresses_json holds a JSON array of canonical IP strings, so matching the quoted value is exact.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM assets
+		WHERE deleted_at IS NULL AND ip_addresses_json LIKE ?
+		ORDER BY id ASC
+		LIMIT 200`, `%"`+ip+`"%`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+func scanObservableRows(rows *sql.Rows) ([]Observable, error) {
+	var out []Observable
+	for rows.Next() {
+		o, err := scanObservable(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+func scanObservable(row assetRowScanner) (*Observable, error) {
+	var o Observable
+	var first, last sql.NullTime
+	var createdBy, updatedBy sql.NullInt64
+	if err := row.Scan(&o.ID, &o.EntityType, &o.EntityID, &o.Type, &o.Value, &o.TLP, &o.Description, &o.Source, &first, &last,
+		&createdBy, &updatedBy, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if first.Valid {
+		t := first.Time
+		o.FirstSeenAt = &t
+	}
+	if last.Valid {
+		t := last.Time
+		o.LastSeenAt = &t
+	}
+	if createdBy.Valid {
+		v := createdBy.Int64
+		o.CreatedBy = &v
+	}
+	if updatedBy.Valid {
+		v := updatedBy.Int64
+		o.UpdatedBy = &v
+	}
+	return &o, nil
+}
+
+func earliestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.Before(*b) {
+		return a
+	}
+	return b
+}
+
+func latestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.After(*b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,258 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type ReportSchedule struct {
+	ID              int64      `json:"id"`
+	Name            string     `json:"name"`
+	ReportID        *int64     `json:"report_id,omitempty"`
+	TemplateID      *int64     `json:"template_id,omitempty"`
+	Period          string     `json:"period"`
+	Cron            string     `json:"cron,omitempty"`
+	RRule           string     `json:"rrule"`
+	TimeOfDay       string     `json:"time_of_day"`
+	Timezone        string     `json:"timezone"`
+	Format          string     `json:"format"`
+	Language        string     `json:"language"`
+	RecipientUsers  []int64    `json:"recipient_users"`
+	RecipientGroups []int64    `json:"recipient_groups"`
+	OwnerID         int64      `json:"owner_id"`
+	Enabled         bool       `json:"enabled"`
+	OutputDocID     *int64     `json:"output_doc_id,omitempty"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	LastStatus      string     `json:"last_status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	// GrantedACL lists the output document rules the schedule added for its recipients,
+	// so they can be revoked when a recipient is removed.
+	GrantedACL []ACLRule `json:"-"`
+}
+
+type ReportScheduleRun struct {
+	ID            int64      `json:"id"`
+	ScheduleID    int64      `json:"schedule_id"`
+	Trigger       string     `json:"trigger"`
+	TriggeredBy   string     `json:"triggered_by"`
+	Status        string     `json:"status"`
+	ScheduledFor  *time.Time `json:"scheduled_for,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    time.Time  `json:"finished_at"`
+	PeriodFrom    *time.Time `json:"period_from,omitempty"`
+	PeriodTo      *time.Time `json:"period_to,omitempty"`
+	ReportID      *int64     `json:"report_id,omitempty"`
+	SnapshotID    *int64     `json:"snapshot_id,omitempty"`
+	OutputDocID   *int64     `json:"output_doc_id,omitempty"`
+	OutputVersion int        `json:"output_version"`
+	Delivered     int        `json:"delivered"`
+	Error         string     `json:"error,omitempty"`
+}
+
+const reportScheduleColumns = `id, name, report_id, template_id, period, cron, rrule, time_of_day, timezone, format, language,
+	recipient_users_json, recipient_groups_json, owner_id, enabled, output_doc_id, granted_acl_json, next_run_at, last_run_at, last_status, created_at, updated_at`
+
+func (s *reportsStore) ListReportSchedules(ctx context.Context) ([]ReportSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+reportScheduleColumns+` FROM report_schedules ORDER BY name ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReportSchedules(rows)
+}
+
+// ListDueReportSchedules returns enabled schedules whose next run is not after now,
+// oldest first.
+func (s *reportsStore) ListDueReportSchedules(ctx context.Context, now time.Time, limit int) ([]ReportSchedule, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+reportScheduleColumns+` FROM report_schedules
+		WHERE enabled=1 AND next_run_at IS NOT NULL AND next_run_at<=?
+		ORDER BY next_run_at ASC, id ASC LIMIT ?`, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReportSchedules(rows)
+}
+
+func (s *reportsStore) GetReportSchedule(ctx context.Context, id int64) (*ReportSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+reportScheduleColumns+` FROM report_schedules WHERE id=?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := scanReportSchedules(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+func (s *reportsStore) CreateReportSchedule(ctx context.Context, sch *ReportSchedule) (int64, error) {
+	if sch == nil {
+		return 0, errors.New("missing schedule")
+	}
+	now := time.Now().UTC()
+	users, _ := json.Marshal(nonNilIDs(sch.RecipientUsers))
+	groups, _ := json.Marshal(nonNilIDs(sch.RecipientGroups))
+	granted, _ := json.Marshal(nonNilRules(sch.GrantedACL))
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO report_schedules(name, report_id, template_id, period, cron, rrule, time_of_day, timezone, format, language,
+			recipient_users_json, recipient_groups_json, owner_id, enabled, output_doc_id, granted_acl_json, next_run_at, last_run_at, last_status, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		sch.Name, nullableID(sch.ReportID), nullableID(sch.TemplateID), sch.Period, sch.Cron, sch.RRule, sch.TimeOfDay, sch.Timezone, sch.Format, sch.Language,
+		string(users), string(groups), sch.OwnerID, boolToInt(sch.Enabled), nullableID(sch.OutputDocID), string(granted), nullableTime(sch.NextRunAt), nullableTime(sch.LastRunAt), sch.LastStatus, now, now)
+	if err != nil {
+		return 0, err
+	}
+	sch.ID, _ = res.LastInsertId()
+	sch.CreatedAt = now
+	sch.UpdatedAt = now
+	return sch.ID, nil
+}
+
+// UpdateReportSchedule saves the settings of a schedule and its next run; the run
+// state is kept.
+func (s *reportsStore) UpdateReportSchedule(ctx context.Context, sch *ReportSchedule) error {
+	if sch == nil {
+		return errors.New("missing schedule")
+	}
+	sch.UpdatedAt = time.Now().UTC()
+	users, _ := json.Marshal(nonNilIDs(sch.RecipientUsers))
+	groups, _ := json.Marshal(nonNilIDs(sch.RecipientGroups))
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE report_schedules SET name=?, report_id=?, template_id=?, period=?, cron=?, rrule=?, time_of_day=?, timezone=?, format=?,
+			recipient_users_json=?, recipient_groups_json=?, owner_id=?, enabled=?, next_run_at=?, updated_at=?
+		WHERE id=?`,
+		sch.Name, nullableID(sch.ReportID), nullableID(sch.TemplateID), sch.Period, sch.Cron, sch.RRule, sch.TimeOfDay, sch.Timezone, sch.Format,
+		string(users), string(groups), sch.OwnerID, boolToInt(sch.Enabled), nullableTime(sch.NextRunAt), sch.UpdatedAt, sch.ID)
+	return err
+}
+
+// UpdateReportScheduleState records the outcome of a run: the report and output
+// document it produced, the access it granted, when it ran and when it runs next.
+func (s *reportsStore) UpdateReportScheduleState(ctx context.Context, sch *ReportSchedule) error {
+	if sch == nil {
+		return errors.New("missing schedule")
+	}
+	granted, _ := json.Marshal(nonNilRules(sch.GrantedACL))
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE report_schedules SET report_id=?, output_doc_id=?, granted_acl_json=?, next_run_at=?, last_run_at=?, last_status=?
+		WHERE id=?`,
+		nullableID(sch.ReportID), nullableID(sch.OutputDocID), string(granted), nullableTime(sch.NextRunAt), nullableTime(sch.LastRunAt), sch.LastStatus, sch.ID)
+	return err
+}
+
+func (s *reportsStore) DeleteReportSchedule(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM report_schedules WHERE id=?`, id)
+	return err
+}
+
+func (s *reportsStore) CreateReportScheduleRun(ctx context.Context, run *ReportScheduleRun) (int64, error) {
+	if run == nil {
+		return 0, errors.New("missing run")
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO report_schedule_runs(schedule_id, trigger_type, triggered_by, status, scheduled_for, started_at, finished_at,
+			period_from, period_to, report_id, snapshot_id, output_doc_id, output_version, delivered, error)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		run.ScheduleID, run.Trigger, run.TriggeredBy, run.Status, nullableTime(run.ScheduledFor), run.StartedAt, run.FinishedAt,
+		nullableTime(run.PeriodFrom), nullableTime(run.PeriodTo), nullableID(run.ReportID), nullableID(run.SnapshotID), nullableID(run.OutputDocID),
+		run.OutputVersion, run.Delivered, run.Error)
+	if err != nil {
+		return 0, err
+	}
+	run.ID, _ = res.LastInsertId()
+	return run.ID, nil
+}
+
+// ListReportScheduleRuns returns the latest runs of a schedule, newest first.
+func (s *reportsStore) ListReportScheduleRuns(ctx context.Context, scheduleID int64, limit int) ([]ReportScheduleRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, schedule_id, trigger_type, triggered_by, status, scheduled_for, started_at, finished_at,
+			period_from, period_to, report_id, snapshot_id, output_doc_id, output_version, delivered, error
+		FROM report_schedule_runs WHERE schedule_id=? ORDER BY started_at DESC, id DESC LIMIT ?`, scheduleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []ReportScheduleRun
+	for rows.Next() {
+		var run ReportScheduleRun
+		var scheduledFor, periodFrom, periodTo sql.NullTime
+		var reportID, snapshotID, outputDocID sql.NullInt64
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.Trigger, &run.TriggeredBy, &run.Status, &scheduledFor, &run.StartedAt, &run.FinishedAt,
+			&periodFrom, &periodTo, &reportID, &snapshotID, &outputDocID, &run.OutputVersion, &run.Delivered, &run.Error); err != nil {
+			return nil, err
+		}
+		run.ScheduledFor = nullTimePtr(scheduledFor)
+		run.PeriodFrom = nullTimePtr(periodFrom)
+		run.PeriodTo = nullTimePtr(periodTo)
+		run.ReportID = nullIDPtr(reportID)
+		run.SnapshotID = nullIDPtr(snapshotID)
+		run.OutputDocID = nullIDPtr(outputDocID)
+		res = append(res, run)
+	}
+	return res, rows.Err()
+}
+
+func scanReportSchedules(rows *sql.Rows) ([]ReportSchedule, error) {
+	var res []ReportSchedule
+	for rows.Next() {
+		var sch ReportSchedule
+		var reportID, templateID, outputDocID sql.NullInt64
+		var nextRun, lastRun sql.NullTime
+		var users, groups, granted string
+		var enabled int
+		if err := rows.Scan(&sch.ID, &sch.Name, &reportID, &templateID, &sch.Period, &sch.Cron, &sch.RRule, &sch.TimeOfDay, &sch.Timezone, &sch.Format, &sch.Language,
+			&users, &groups, &sch.OwnerID, &enabled, &outputDocID, &granted, &nextRun, &lastRun, &sch.LastStatus, &sch.CreatedAt, &sch.UpdatedAt); err != nil {
+			return nil, err
+		}
+		sch.ReportID = nullIDPtr(reportID)
+		sch.TemplateID = nullIDPtr(templateID)
+		sch.OutputDocID = nullIDPtr(outputDocID)
+		sch.NextRunAt = nullTimePtr(nextRun)
+		sch.LastRunAt = nullTimePtr(lastRun)
+		sch.Enabled = enabled == 1
+		_ = json.Unmarshal([]byte(users), &sch.RecipientUsers)
+		_ = json.Unmarshal([]byte(groups), &sch.RecipientGroups)
+		_ = json.Unmarshal([]byte(granted), &sch.GrantedACL)
+		sch.RecipientUsers = nonNilIDs(sch.RecipientUsers)
+		sch.RecipientGroups = nonNilIDs(sch.RecipientGroups)
+		res = append(res, sch)
+	}
+	return res, rows.Err()
+}
+
+func nullIDPtr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	id := v.Int64
+	return &id
+}
+
+func nonNilRules(rules []ACLRule) []ACLRule {
+	if rules == nil {
+		return []ACLRule{}
+	}
+	return rules
+}
+
+func nonNilIDs(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
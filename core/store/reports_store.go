@@ -78,6 +78,16 @@ type ReportsStore interface {
 	CreateReportSnapshot(ctx context.Context, snapshot *ReportSnapshot, items []ReportSnapshotItem) (int64, error)
 	ListReportSnapshots(ctx context.Context, reportID int64) ([]ReportSnapshot, error)
 	GetReportSnapshot(ctx context.Context, snapshotID int64) (*ReportSnapshot, []ReportSnapshotItem, error)
+
+	ListReportSchedules(ctx context.Context) ([]ReportSchedule, error)
+	ListDueReportSchedules(ctx context.Context, now time.Time, limit int) ([]ReportSchedule, error)
+	GetReportSchedule(ctx context.Context, id int64) (*ReportSchedule, error)
+	CreateReportSchedule(ctx context.Context, sch *ReportSchedule) (int64, error)
+	UpdateReportSchedule(ctx context.Context, sch *ReportSchedule) error
+	UpdateReportScheduleState(ctx context.Context, sch *ReportSchedule) error
+	DeleteReportSchedule(ctx context.Context, id int64) error
+	CreateReportScheduleRun(ctx context.Context, run *ReportScheduleRun) (int64, error)
+	ListReportScheduleRuns(ctx context.Context, scheduleID int64, limit int) ([]ReportScheduleRun, error)
 }
 
 type reportsStore struct {
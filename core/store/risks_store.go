@@ -0,0 +1,509 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Risk struct {
+	ID                  int64      `json:"id"`
+	Title               string     `json:"title"`
+	DescriptionMD       string     `json:"description_md"`
+	Category            string     `json:"category"`
+	Status              string     `json:"status"`
+	OwnerID             *int64     `json:"owner_id,omitempty"`
+	Threats             []string   `json:"threats,omitempty"`
+	Vulnerabilities     []string   `json:"vulnerabilities,omitempty"`
+	InherentLikelihood  int        `json:"inherent_likelihood"`
+	InherentImpact      int        `json:"inherent_impact"`
+	ResidualLikelihood  int        `json:"residual_likelihood"`
+	ResidualImpact      int        `json:"residual_impact"`
+	Treatment           string     `json:"treatment"`
+	TreatmentPlanMD     string     `json:"treatment_plan_md"`
+	TreatmentDueAt      *time.Time `json:"treatment_due_at,omitempty"`
+	AcceptedBy          *int64     `json:"accepted_by,omitempty"`
+	AcceptedAt          *time.Time `json:"accepted_at,omitempty"`
+	AcceptanceExpiresAt *time.Time `json:"acceptance_expires_at,omitempty"`
+	AcceptanceNote      string     `json:"acceptance_note"`
+	ReviewAt            *time.Time `json:"review_at,omitempty"`
+	Tags                []string   `json:"tags,omitempty"`
+	CreatedBy           *int64     `json:"created_by,omitempty"`
+	UpdatedBy           *int64     `json:"updated_by,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Version             int        `json:"version"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+}
+
+type RiskFilter struct {
+	Search         string
+	Status         string
+	Treatment      string
+	Category       string
+	OwnerID        int64
+	Tag            string
+	IncludeDeleted bool
+	Limit          int
+	Offset         int
+}
+
+// RiskLevel is a band on the likelihood x impact matrix. A score falls into
+// the highest level whose MinScore it reaches.
+type RiskLevel struct {
+	Key      string `json:"key"`
+	MinScore int    `json:"min_score"`
+	Color    string `json:"color"`
+}
+
+type RiskMatrix struct {
+	Likelihood []string    `json:"likelihood"`
+	Impact     []string    `json:"impact"`
+	Levels     []RiskLevel `json:"levels"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+const (
+	RiskMatrixMinSize = 3
+	RiskMatrixMaxSize = 10
+)
+
+var ErrInvalidRiskMatrix = errors.New("invalid risk matrix")
+
+type RisksStore interface {
+	ListRisks(ctx context.Context, filter RiskFilter) ([]Risk, error)
+	GetRisk(ctx context.Context, id int64) (*Risk, error)
+	CreateRisk(ctx context.Context, r *Risk) (int64, error)
+	UpdateRisk(ctx context.Context, r *Risk) error
+	ArchiveRisk(ctx context.Context, id int64, updatedBy int64) error
+	RestoreRisk(ctx context.Context, id int64, updatedBy int64) error
+	AcceptRisk(ctx context.Context, id int64, acceptedBy int64, expiresAt *time.Time, note string) error
+	RevokeRiskAcceptance(ctx context.Context, id int64, updatedBy int64) error
+	GetRiskMatrix(ctx context.Context) (*RiskMatrix, error)
+	SaveRiskMatrix(ctx context.Context, m *RiskMatrix) error
+}
+
+type risksStore struct {
+	db *sql.DB
+}
+
+func NewRisksStore(db *sql.DB) RisksStore {
+	return &risksStore{db: db}
+}
+
+const riskColumns = `id, title, description_md, category, status, owner_id, threats_json, vulnerabilities_json,
+		       inherent_likelihood, inherent_impact, residual_likelihood, residual_impact,
+		       treatment, treatment_plan_md, treatment_due_at, accepted_by, accepted_at, acceptance_expires_at, acceptance_note,
+		       review_at, tags_json, created_by, updated_by, created_at, updated_at, version, deleted_at`
+
+func (s *risksStore) ListRisks(ctx context.Context, filter RiskFilter) ([]Risk, error) {
+	clauses := []string{}
+	args := []any{}
+	if !filter.IncludeDeleted {
+		clauses = append(clauses, "deleted_at IS NULL")
+	}
+	if q := strings.TrimSpace(filter.Search); q != "" {
+		clauses = append(clauses, "(LOWER(title) LIKE ? OR LOWER(description_md) LIKE ? OR LOWER(category) LIKE ?)")
+		pattern := "%" + strings.ToLower(q) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Status)); v != "" {
+		clauses = append(clauses, "LOWER(status)=?")
+		args = append(args, v)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Treatment)); v != "" {
+		clauses = append(clauses, "LOWER(treatment)=?")
+		args = append(args, v)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Category)); v != "" {
+		clauses = append(clauses, "LOWER(category)=?")
+		args = append(args, v)
+	}
+	if filter.OwnerID > 0 {
+		clauses = append(clauses, "owner_id=?")
+		args = append(args, filter.OwnerID)
+	}
+	if tag := strings.TrimSpace(filter.Tag); tag != "" {
+		clauses = append(clauses, "tags_json LIKE ?")
+		args = append(args, "%"+strings.ToUpper(tag)+"%")
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	query := `SELECT ` + riskColumns + ` FROM risks`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY deleted_at IS NOT NULL, residual_likelihood*residual_impact DESC, updated_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Risk
+	for rows.Next() {
+		r, err := scanRisk(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *r)
+	}
+	return out, rows.Err()
+}
+
+func (s *risksStore) GetRisk(ctx context.Context, id int64) (*Risk, error) {
+	if id <= 0 {
+		return nil, errors.New("bad id")
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT `+riskColumns+` FROM risks WHERE id=?`, id)
+	r, err := scanRisk(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *risksStore) CreateRisk(ctx context.Context, r *Risk) (int64, error) {
+	if r == nil {
+		return 0, errors.New("nil risk")
+	}
+	now := time.Now().UTC()
+	threatsJSON, _ := json.Marshal(normalizeRiskList(r.Threats))
+	vulnsJSON, _ := json.Marshal(normalizeRiskList(r.Vulnerabilities))
+	tagsJSON, _ := json.Marshal(normalizeUpperTags(r.Tags))
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO risks(title, description_md, category, status, owner_id, threats_json, vulnerabilities_json,
+			inherent_likelihood, inherent_impact, residual_likelihood, residual_impact,
+			treatment, treatment_plan_md, treatment_due_at, review_at, tags_json, created_by, updated_by, created_at, updated_at, version)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,1)
+	`, strings.TrimSpace(r.Title), strings.TrimSpace(r.DescriptionMD), strings.TrimSpace(r.Category), NormalizeRiskStatus(r.Status), nullableID(r.OwnerID),
+		string(threatsJSON), string(vulnsJSON), r.InherentLikelihood, r.InherentImpact, r.ResidualLikelihood, r.ResidualImpact,
+		NormalizeRiskTreatment(r.Treatment), strings.TrimSpace(r.TreatmentPlanMD), nullableTime(r.TreatmentDueAt), nullableTime(r.ReviewAt),
+		string(tagsJSON), nullableID(r.CreatedBy), nullableID(r.UpdatedBy), now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return id, nil
+}
+
+// UpdateRisk writes the editable fields. Acceptance sign-off is managed by
+// AcceptRisk/RevokeRiskAcceptance and is not touched here.
+func (s *risksStore) UpdateRisk(ctx context.Context, r *Risk) error {
+	if r == nil || r.ID <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	threatsJSON, _ := json.Marshal(normalizeRiskList(r.Threats))
+	vulnsJSON, _ := json.Marshal(normalizeRiskList(r.Vulnerabilities))
+	tagsJSON, _ := json.Marshal(normalizeUpperTags(r.Tags))
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE risks
+		SET title=?, description_md=?, category=?, status=?, owner_id=?, threats_json=?, vulnerabilities_json=?,
+			inherent_likelihood=?, inherent_impact=?, residual_likelihood=?, residual_impact=?,
+			treatment=?, treatment_plan_md=?, treatment_due_at=?, review_at=?, tags_json=?, updated_by=?, updated_at=?, version=version+1
+		WHERE id=? AND version=? AND deleted_at IS NULL
+	`, strings.TrimSpace(r.Title), strings.TrimSpace(r.DescriptionMD), strings.TrimSpace(r.Category), NormalizeRiskStatus(r.Status), nullableID(r.OwnerID),
+		string(threatsJSON), string(vulnsJSON), r.InherentLikelihood, r.InherentImpact, r.ResidualLikelihood, r.ResidualImpact,
+		NormalizeRiskTreatment(r.Treatment), strings.TrimSpace(r.TreatmentPlanMD), nullableTime(r.TreatmentDueAt), nullableTime(r.ReviewAt),
+		string(tagsJSON), nullableID(r.UpdatedBy), now, r.ID, r.Version)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *risksStore) ArchiveRisk(ctx context.Context, id int64, updatedBy int64) error {
+	if id <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE risks SET deleted_at=?, updated_by=?, updated_at=?, version=version+1
+		WHERE id=? AND deleted_at IS NULL
+	`, now, updatedBy, now, id)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *risksStore) RestoreRisk(ctx context.Context, id int64, updatedBy int64) error {
+	if id <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE risks SET deleted_at=NULL, updated_by=?, updated_at=?, version=version+1
+		WHERE id=? AND deleted_at IS NOT NULL
+	`, updatedBy, now, id)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *risksStore) AcceptRisk(ctx context.Context, id int64, acceptedBy int64, expiresAt *time.Time, note string) error {
+	if id <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE risks
+		SET treatment='accept', status='accepted', accepted_by=?, accepted_at=?, acceptance_expires_at=?, acceptance_note=?,
+			updated_by=?, updated_at=?, version=version+1
+		WHERE id=? AND deleted_at IS NULL
+	`, acceptedBy, now, nullableTime(expiresAt), strings.TrimSpace(note), acceptedBy, now, id)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *risksStore) RevokeRiskAcceptance(ctx context.Context, id int64, updatedBy int64) error {
+	if id <= 0 {
+		return errors.New("bad id")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE risks
+		SET status='assessed', accepted_by=NULL, accepted_at=NULL, acceptance_expires_at=NULL, acceptance_note='',
+			updated_by=?, updated_at=?, version=version+1
+		WHERE id=? AND deleted_at IS NULL AND accepted_at IS NOT NULL
+	`, updatedBy, now, id)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *risksStore) GetRiskMatrix(ctx context.Context) (*RiskMatrix, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT matrix_json, updated_at FROM risk_settings ORDER BY id LIMIT 1`)
+	var raw string
+	var updatedAt time.Time
+	if err := row.Scan(&raw, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			m := DefaultRiskMatrix()
+			if err := s.SaveRiskMatrix(ctx, &m); err != nil {
+				return nil, err
+			}
+			return &m, nil
+		}
+		return nil, err
+	}
+	var m RiskMatrix
+	if err := json.Unmarshal([]byte(raw), &m); err != nil || m.Validate() != nil {
+		m = DefaultRiskMatrix()
+	}
+	m.UpdatedAt = updatedAt
+	return &m, nil
+}
+
+func (s *risksStore) SaveRiskMatrix(ctx context.Context, m *RiskMatrix) error {
+	if m == nil {
+		return errors.New("missing matrix")
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	payload, _ := json.Marshal(RiskMatrix{Likelihood: m.Likelihood, Impact: m.Impact, Levels: m.Levels})
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM risk_settings ORDER BY id LIMIT 1`).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = s.db.ExecContext(ctx, `INSERT INTO risk_settings(matrix_json, updated_at) VALUES(?,?)`, string(payload), now)
+	case err == nil:
+		_, err = s.db.ExecContext(ctx, `UPDATE risk_settings SET matrix_json=?, updated_at=? WHERE id=?`, string(payload), now, id)
+	}
+	if err == nil {
+		m.UpdatedAt = now
+	}
+	return err
+}
+
+// DefaultRiskMatrix is the classic 5x5 matrix with four levels.
+func DefaultRiskMatrix() RiskMatrix {
+	return RiskMatrix{
+		Likelihood: []string{"rare", "unlikely", "possible", "likely", "almost_certain"},
+		Impact:     []string{"negligible", "minor", "moderate", "major", "severe"},
+		Levels: []RiskLevel{
+			{Key: "low", MinScore: 1, Color: "#4caf50"},
+			{Key: "medium", MinScore: 5, Color: "#ffc107"},
+			{Key: "high", MinScore: 10, Color: "#ff9800"},
+			{Key: "critical", MinScore: 17, Color: "#f44336"},
+		},
+		UpdatedAt: time.Now().UTC(),
+	}
+}
+
+func (m RiskMatrix) Validate() error {
+	if len(m.Likelihood) < RiskMatrixMinSize || len(m.Likelihood) > RiskMatrixMaxSize {
+		return ErrInvalidRiskMatrix
+	}
+	if len(m.Impact) < RiskMatrixMinSize || len(m.Impact) > RiskMatrixMaxSize {
+		return ErrInvalidRiskMatrix
+	}
+	for _, v := range append(append([]string{}, m.Likelihood...), m.Impact...) {
+		if strings.TrimSpace(v) == "" {
+			return ErrInvalidRiskMatrix
+		}
+	}
+	if len(m.Levels) == 0 || m.Levels[0].MinScore != 1 {
+		return ErrInvalidRiskMatrix
+	}
+	seen := map[string]struct{}{}
+	for i, lvl := range m.Levels {
+		key := strings.TrimSpace(lvl.Key)
+		if key == "" {
+			return ErrInvalidRiskMatrix
+		}
+		if _, ok := seen[key]; ok {
+			return ErrInvalidRiskMatrix
+		}
+		seen[key] = struct{}{}
+		if i > 0 && lvl.MinScore <= m.Levels[i-1].MinScore {
+			return ErrInvalidRiskMatrix
+		}
+	}
+	return nil
+}
+
+// InRange reports whether a likelihood/impact pair fits the matrix axes.
+func (m RiskMatrix) InRange(likelihood, impact int) bool {
+	return likelihood >= 1 && likelihood <= len(m.Likelihood) && impact >= 1 && impact <= len(m.Impact)
+}
+
+func (m RiskMatrix) Level(likelihood, impact int) RiskLevel {
+	score := RiskScore(likelihood, impact)
+	var out RiskLevel
+	for _, lvl := range m.Levels {
+		if score >= lvl.MinScore {
+			out = lvl
+		}
+	}
+	return out
+}
+
+func RiskScore(likelihood, impact int) int {
+	if likelihood < 0 || impact < 0 {
+		return 0
+	}
+	return likelihood * impact
+}
+
+// AcceptanceExpired reports whether a signed-off acceptance has lapsed.
+func (r Risk) AcceptanceExpired(now time.Time) bool {
+	return r.AcceptedAt != nil && r.AcceptanceExpiresAt != nil && !r.AcceptanceExpiresAt.After(now)
+}
+
+func NormalizeRiskStatus(v string) string {
+	val := strings.ToLower(strings.TrimSpace(v))
+	switch val {
+	case "identified", "assessed", "treating", "accepted", "closed":
+		return val
+	default:
+		return "identified"
+	}
+}
+
+func NormalizeRiskTreatment(v string) string {
+	val := strings.ToLower(strings.TrimSpace(v))
+	switch val {
+	case "mitigate", "accept", "transfer", "avoid":
+		return val
+	default:
+		return "mitigate"
+	}
+}
+
+func normalizeRiskList(items []string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, item := range items {
+		val := strings.TrimSpace(item)
+		if val == "" {
+			continue
+		}
+		key := strings.ToLower(val)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, val)
+	}
+	return out
+}
+
+func scanRisk(row interface{ Scan(dest ...any) error }) (*Risk, error) {
+	var r Risk
+	var threatsRaw, vulnsRaw, tagsRaw string
+	var owner, acceptedBy, createdBy, updatedBy sql.NullInt64
+	var due, acceptedAt, expires, review, deleted sql.NullTime
+	if err := row.Scan(&r.ID, &r.Title, &r.DescriptionMD, &r.Category, &r.Status, &owner, &threatsRaw, &vulnsRaw,
+		&r.InherentLikelihood, &r.InherentImpact, &r.ResidualLikelihood, &r.ResidualImpact,
+		&r.Treatment, &r.TreatmentPlanMD, &due, &acceptedBy, &acceptedAt, &expires, &r.AcceptanceNote,
+		&review, &tagsRaw, &createdBy, &updatedBy, &r.CreatedAt, &r.UpdatedAt, &r.Version, &deleted); err != nil {
+		return nil, err
+	}
+	r.OwnerID = nullInt64Ptr(owner)
+	r.AcceptedBy = nullInt64Ptr(acceptedBy)
+	r.CreatedBy = nullInt64Ptr(createdBy)
+	r.UpdatedBy = nullInt64Ptr(updatedBy)
+	r.TreatmentDueAt = nullTimePtr(due)
+	r.AcceptedAt = nullTimePtr(acceptedAt)
+	r.AcceptanceExpiresAt = nullTimePtr(expires)
+	r.ReviewAt = nullTimePtr(review)
+	r.DeletedAt = nullTimePtr(deleted)
+	if threatsRaw != "" {
+		_ = json.Unmarshal([]byte(threatsRaw), &r.Threats)
+	}
+	if vulnsRaw != "" {
+		_ = json.Unmarshal([]byte(vulnsRaw), &r.Vulnerabilities)
+	}
+	if tagsRaw != "" {
+		_ = json.Unmarshal([]byte(tagsRaw), &r.Tags)
+	}
+	return &r, nil
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	id := v.Int64
+	return &id
+}
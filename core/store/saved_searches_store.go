@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Entities that support the list query language and saved searches.
+const (
+	SearchEntityIncident = "incident"
+	SearchEntityFinding  = "finding"
+	SearchEntityAsset    = "asset"
+	SearchEntityTask     = "task"
+	SearchEntityDoc      = "doc"
+)
+
+var SearchEntityTypes = []string{SearchEntityIncident, SearchEntityFinding, SearchEntityAsset, SearchEntityTask, SearchEntityDoc}
+
+// QueryClause is a compiled list query: a WHERE condition with ? placeholders that
+// refers to the columns of the listed table without a table alias.
+type QueryClause struct {
+	SQL  string
+	Args []any
+}
+
+// SavedSearch is a named list query. It is private to the owner unless shared with
+// groups; members of those groups may run it but not change it.
+type SavedSearch struct {
+	ID         int64     `json:"id"`
+	OwnerID    int64     `json:"owner_id"`
+	EntityType string    `json:"entity_type"`
+	Name       string    `json:"name"`
+	Query      string    `json:"query"`
+	GroupIDs   []int64   `json:"group_ids"`
+	Dashboard  bool      `json:"dashboard"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SavedSearchesStore interface {
+	// ListSavedSearches returns the searches owned by the user or shared with one of
+	// the user's groups; an empty entityType lists all entity types.
+	ListSavedSearches(ctx context.Context, userID int64, entityType string) ([]SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id int64) (*SavedSearch, error)
+	// GetVisibleSavedSearch returns nil when the search does not exist or is not visible
+	// to the user.
+	GetVisibleSavedSearch(ctx context.Context, id, userID int64) (*SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, s *SavedSearch) (int64, error)
+	UpdateSavedSearch(ctx context.Context, s *SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, id int64) error
+}
+
+type savedSearchesStore struct {
+	db *sql.DB
+}
+
+func NewSavedSearchesStore(db *sql.DB) SavedSearchesStore {
+	return &savedSearchesStore{db: db}
+}
+
+const savedSearchVisible = `(s.owner_id=? OR s.id IN (
+		SELECT g.search_id FROM saved_search_groups g JOIN user_groups ug ON ug.group_id=g.group_id WHERE ug.user_id=?))`
+
+func (s *savedSearchesStore) ListSavedSearches(ctx context.Context, userID int64, entityType string) ([]SavedSearch, error) {
+	clauses := []string{savedSearchVisible}
+	args := []any{userID, userID}
+	if entityType != "" {
+		clauses = append(clauses, "s.entity_type=?")
+		args = append(args, entityType)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.owner_id, s.entity_type, s.name, s.query, s.dashboard, s.created_at, s.updated_at
+		FROM saved_searches s
+		WHERE `+strings.Join(clauses, " AND ")+`
+		ORDER BY s.entity_type ASC, LOWER(s.name) ASC, s.id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []SavedSearch
+	for rows.Next() {
+		item, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if out[i].GroupIDs, err = s.searchGroups(ctx, out[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *savedSearchesStore) GetSavedSearch(ctx context.Context, id int64) (*SavedSearch, error) {
+	return s.getSavedSearch(ctx, `WHERE s.id=?`, id)
+}
+
+func (s *savedSearchesStore) GetVisibleSavedSearch(ctx context.Context, id, userID int64) (*SavedSearch, error) {
+	return s.getSavedSearch(ctx, `WHERE s.id=? AND `+savedSearchVisible, id, userID, userID)
+}
+
+func (s *savedSearchesStore) getSavedSearch(ctx context.Context, where string, args ...any) (*SavedSearch, error) {
+	item, err := scanSavedSearch(s.db.QueryRowContext(ctx, `
+		SELECT s.id, s.owner_id, s.entity_type, s.name, s.query, s.dashboard, s.created_at, s.updated_at
+		FROM saved_searches s `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if item.GroupIDs, err = s.searchGroups(ctx, item.ID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *savedSearchesStore) CreateSavedSearch(ctx context.Context, item *SavedSearch) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO saved_searches(owner_id, entity_type, name, query, dashboard, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?)`,
+		item.OwnerID, item.EntityType, item.Name, item.Query, boolToInt(item.Dashboard), now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := replaceSearchGroupsTx(ctx, tx, id, item.GroupIDs); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	item.ID = id
+	item.GroupIDs = append([]int64{}, normalizeUniqueInt64(item.GroupIDs)...)
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	return id, nil
+}
+
+func (s *savedSearchesStore) UpdateSavedSearch(ctx context.Context, item *SavedSearch) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `UPDATE saved_searches SET name=?, query=?, dashboard=?, updated_at=? WHERE id=?`,
+		item.Name, item.Query, boolToInt(item.Dashboard), now, item.ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	if err := replaceSearchGroupsTx(ctx, tx, item.ID, item.GroupIDs); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	item.GroupIDs = append([]int64{}, normalizeUniqueInt64(item.GroupIDs)...)
+	item.UpdatedAt = now
+	return nil
+}
+
+func (s *savedSearchesStore) DeleteSavedSearch(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM saved_search_groups WHERE search_id=?`, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM saved_searches WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+func (s *savedSearchesStore) searchGroups(ctx context.Context, searchID int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT group_id FROM saved_search_groups WHERE search_id=? ORDER BY group_id`, searchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func replaceSearchGroupsTx(ctx context.Context, tx *sql.Tx, searchID int64, groupIDs []int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM saved_search_groups WHERE search_id=?`, searchID); err != nil {
+		return err
+	}
+	for _, groupID := range normalizeUniqueInt64(groupIDs) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO saved_search_groups(search_id, group_id) VALUES(?,?)`, searchID, groupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanSavedSearch(row interface{ Scan(dest ...any) error }) (*SavedSearch, error) {
+	var item SavedSearch
+	var dashboard int
+	if err := row.Scan(&item.ID, &item.OwnerID, &item.EntityType, &item.Name, &item.Query, &dashboard, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	item.Dashboard = dashboard == 1
+	return &item, nil
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"berkut-scc/core/schedule"
+)
+
+// ScheduleCalendar is a named business calendar: weekend days and holidays used to shift
+// recurring schedules and to count SLA business days.
+type ScheduleCalendar struct {
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Weekend     []int              `json:"weekend"`
+	Holidays    []schedule.Holiday `json:"holidays"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type ScheduleCalendarsStore interface {
+	schedule.CalendarSource
+	// ListScheduleCalendars returns calendars without their holidays.
+	ListScheduleCalendars(ctx context.Context) ([]ScheduleCalendar, error)
+	GetScheduleCalendar(ctx context.Context, id int64) (*ScheduleCalendar, error)
+	// CreateScheduleCalendar returns ErrConflict when the name is taken.
+	CreateScheduleCalendar(ctx context.Context, c *ScheduleCalendar) (int64, error)
+	// UpdateScheduleCalendar replaces the name, description, weekend and holidays.
+	UpdateScheduleCalendar(ctx context.Context, c *ScheduleCalendar) error
+	DeleteScheduleCalendar(ctx context.Context, id int64) error
+}
+
+type scheduleCalendarsStore struct {
+	db *sql.DB
+}
+
+func NewScheduleCalendarsStore(db *sql.DB) ScheduleCalendarsStore {
+	return &scheduleCalendarsStore{db: db}
+}
+
+func (s *scheduleCalendarsStore) ListScheduleCalendars(ctx context.Context) ([]ScheduleCalendar, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, description, weekend_json, created_by, created_at, updated_at
+		FROM schedule_calendars
+		ORDER BY LOWER(name) ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ScheduleCalendar
+	for rows.Next() {
+		item, err := scanScheduleCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *item)
+	}
+	return out, rows.Err()
+}
+
+func (s *scheduleCalendarsStore) GetScheduleCalendar(ctx context.Context, id int64) (*ScheduleCalendar, error) {
+	item, err := scanScheduleCalendar(s.db.QueryRowContext(ctx, `
+		SELECT id, name, description, weekend_json, created_by, created_at, updated_at
+		FROM schedule_calendars WHERE id=?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT day, name FROM schedule_calendar_days WHERE calendar_id=? ORDER BY day ASC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	item.Holidays = []schedule.Holiday{}
+	for rows.Next() {
+		var h schedule.Holiday
+		if err := rows.Scan(&h.Date, &h.Name); err != nil {
+			return nil, err
+		}
+		item.Holidays = append(item.Holidays, h)
+	}
+	return item, rows.Err()
+}
+
+func (s *scheduleCalendarsStore) CreateScheduleCalendar(ctx context.Context, c *ScheduleCalendar) (int64, error) {
+	if err := s.checkNameFree(ctx, c.Name, 0); err != nil {
+		return 0, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO schedule_calendars(name, description, weekend_json, created_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?)`,
+		c.Name, c.Description, weekendJSON(c.Weekend), c.CreatedBy, now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := replaceCalendarDays(ctx, tx, id, c.Holidays); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	c.ID = id
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return id, nil
+}
+
+func (s *scheduleCalendarsStore) UpdateScheduleCalendar(ctx context.Context, c *ScheduleCalendar) error {
+	if err := s.checkNameFree(ctx, c.Name, c.ID); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `
+		UPDATE schedule_calendars SET name=?, description=?, weekend_json=?, updated_at=? WHERE id=?`,
+		c.Name, c.Description, weekendJSON(c.Weekend), now, c.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if err := replaceCalendarDays(ctx, tx, c.ID, c.Holidays); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+func (s *scheduleCalendarsStore) DeleteScheduleCalendar(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedule_calendar_days WHERE calendar_id=?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedule_calendars WHERE id=?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BusinessCalendar implements schedule.CalendarSource.
+func (s *scheduleCalendarsStore) BusinessCalendar(ctx context.Context, id int64) (*schedule.Calendar, error) {
+	item, err := s.GetScheduleCalendar(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, schedule.ErrCalendarNotFound
+	}
+	weekend := make([]time.Weekday, 0, len(item.Weekend))
+	for _, d := range item.Weekend {
+		weekend = append(weekend, time.Weekday(d))
+	}
+	holidays := make(map[string]string, len(item.Holidays))
+	for _, h := range item.Holidays {
+		holidays[h.Date] = h.Name
+	}
+	return schedule.NewCalendar(weekend, holidays), nil
+}
+
+func (s *scheduleCalendarsStore) checkNameFree(ctx context.Context, name string, exceptID int64) error {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM schedule_calendars WHERE LOWER(name)=LOWER(?) AND id<>?`, name, exceptID).Scan(&id)
+	if err == nil {
+		return ErrConflict
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func replaceCalendarDays(ctx context.Context, tx *sql.Tx, calendarID int64, holidays []schedule.Holiday) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedule_calendar_days WHERE calendar_id=?`, calendarID); err != nil {
+		return err
+	}
+	for _, h := range holidays {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schedule_calendar_days(calendar_id, day, name) VALUES(?,?,?)`, calendarID, h.Date, h.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func weekendJSON(days []int) string {
+	sorted := append([]int{}, days...)
+	sort.Ints(sorted)
+	raw, _ := json.Marshal(sorted)
+	return string(raw)
+}
+
+func scanScheduleCalendar(row interface {
+	Scan(dest ...any) error
+}) (*ScheduleCalendar, error) {
+	var c ScheduleCalendar
+	var weekendRaw string
+	if err := row.Scan(&c.ID, &c.Name, &c.Description, &weekendRaw, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	c.Weekend = []int{}
+	_ = json.Unmarshal([]byte(weekendRaw), &c.Weekend)
+	return &c, nil
+}
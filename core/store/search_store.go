@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entity types of the global search besides the ones of saved searches.
+const (
+	SearchEntitySoftware = "software"
+	SearchEntityControl  = "control"
+	SearchEntityMonitor  = "monitor"
+)
+
+// GlobalSearchTypes lists the entity types of the global search in display order.
+var GlobalSearchTypes = []string{
+	SearchEntityDoc, SearchEntityIncident, SearchEntityTask, SearchEntityFinding,
+	SearchEntityAsset, SearchEntitySoftware, SearchEntityControl, SearchEntityMonitor,
+}
+
+// SearchResult is a match of the global search before access checks.
+type SearchResult struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   int64     `json:"id"`
+	Title      string    `json:"title"`
+	Ref        string    `json:"ref,omitempty"`
+	Snippet    string    `json:"snippet"`
+	Rank       float64   `json:"rank"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SearchStore interface {
+	// Search returns up to limit matches of text among entities of one type, best first.
+	Search(ctx context.Context, entityType, text string, limit int) ([]SearchResult, error)
+}
+
+// searchSource describes the searchable text of an entity table. On PostgreSQL the
+// title and body expressions are backed by GIN indexes over to_tsvector('simple', ...),
+// see 00050_global_search.sql; the expressions must stay identical to the indexed ones.
+type searchSource struct {
+	from    string
+	id      string
+	ref     string
+	title   string
+	body    string
+	updated string
+	where   string
+}
+
+var searchSources = map[string]searchSource{
+	SearchEntityDoc: {
+		from:    "docs d LEFT JOIN docs_fts f ON f.doc_id=d.id AND f.version_id=d.current_version",
+		id:      "d.id",
+		ref:     "d.reg_number",
+		title:   "coalesce(d.title, '')",
+		body:    "f.content",
+		updated: "d.updated_at",
+		where:   "d.deleted_at IS NULL AND d.doc_type='document'",
+	},
+	SearchEntityIncident: {
+		from:    "incidents",
+		id:      "id",
+		ref:     "reg_no",
+		title:   "coalesce(reg_no, '') || ' ' || coalesce(title, '')",
+		body:    "coalesce(description, '')",
+		updated: "updated_at",
+		where:   "deleted_at IS NULL",
+	},
+	SearchEntityTask: {
+		from:    "tasks",
+		id:      "id",
+		ref:     "''",
+		title:   "coalesce(title, '')",
+		body:    "coalesce(description, '') || ' ' || coalesce(result, '')",
+		updated: "updated_at",
+		where:   "is_archived=0",
+	},
+	SearchEntityFinding: {
+		from:    "findings",
+		id:      "id",
+		ref:     "''",
+		title:   "coalesce(title, '')",
+		body:    "coalesce(description_md, '')",
+		updated: "updated_at",
+		where:   "deleted_at IS NULL",
+	},
+	SearchEntityAsset: {
+		from:    "assets",
+		id:      "id",
+		ref:     "''",
+		title:   "coalesce(name, '')",
+		body:    "coalesce(description, '')",
+		updated: "updated_at",
+		where:   "deleted_at IS NULL",
+	},
+	SearchEntitySoftware: {
+		from:    "software_products",
+		id:      "id",
+		ref:     "vendor",
+		title:   "coalesce(name, '') || ' ' || coalesce(vendor, '')",
+		body:    "coalesce(description, '')",
+		updated: "updated_at",
+		where:   "deleted_at IS NULL",
+	},
+	SearchEntityControl: {
+		from:    "controls",
+		id:      "id",
+		ref:     "code",
+		title:   "coalesce(code, '') || ' ' || coalesce(title, '')",
+		body:    "coalesce(description_md, '')",
+		updated: "updated_at",
+		where:   "is_active=1",
+	},
+	SearchEntityMonitor: {
+		from:    "monitors",
+		id:      "id",
+		ref:     "type",
+		title:   "coalesce(name, '')",
+		body:    "coalesce(url, '') || ' ' || coalesce(host, '')",
+		updated: "updated_at",
+		where:   "1=1",
+	},
+}
+
+// searchDisplayTitle strips the reference prepended to the indexed title.
+var searchDisplayTitle = map[string]string{
+	SearchEntityIncident: "title",
+	SearchEntitySoftware: "name",
+	SearchEntityControl:  "title",
+}
+
+const (
+	maxSearchTerms     = 8
+	searchSnippetRunes = 160
+)
+
+type searchStore struct {
+	db   *sql.DB
+	once sync.Once
+	isPG bool
+}
+
+func NewSearchStore(db *sql.DB) SearchStore {
+	return &searchStore{db: db}
+}
+
+func (s *searchStore) postgres(ctx context.Context) bool {
+	s.once.Do(func() {
+		s.isPG, _ = isPostgresDB(ctx, s.db)
+	})
+	return s.isPG
+}
+
+func (s *searchStore) Search(ctx context.Context, entityType, text string, limit int) ([]SearchResult, error) {
+	src, ok := searchSources[entityType]
+	text = strings.TrimSpace(text)
+	if !ok || text == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if s.postgres(ctx) {
+		return s.searchPG(ctx, entityType, src, text, limit)
+	}
+	return s.searchLike(ctx, entityType, src, text, limit)
+}
+
+func (s *searchStore) searchPG(ctx context.Context, entityType string, src searchSource, text string, limit int) ([]SearchResult, error) {
+	titleVec := "to_tsvector('simple', " + src.title + ")"
+	bodyVec := "to_tsvector('simple', " + src.body + ")"
+	query := `SELECT ` + src.id + `, ` + s.titleColumn(entityType, src) + `, coalesce(` + src.ref + `, ''),
+		coalesce(ts_headline('simple', ` + src.body + `, q, 'MaxWords=30, MinWords=10, MaxFragments=1'), ''),
+		coalesce(ts_rank(` + titleVec + `, q), 0) * 2 + coalesce(ts_rank(` + bodyVec + `, q), 0) AS rank, ` + src.updated + `
+		FROM ` + src.from + ` CROSS JOIN plainto_tsquery('simple', ?) q
+		WHERE ` + src.where + ` AND (` + titleVec + ` @@ q OR ` + bodyVec + ` @@ q)
+		ORDER BY rank DESC, ` + src.updated + ` DESC
+		LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, query, text, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []SearchResult
+	for rows.Next() {
+		item := SearchResult{EntityType: entityType}
+		if err := rows.Scan(&item.EntityID, &item.Title, &item.Ref, &item.Snippet, &item.Rank, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		item.Snippet = strings.NewReplacer("<b>", "", "</b>", "").Replace(item.Snippet)
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+// searchLike serves databases without full-text search: every term must occur in
+// the title or body, ranking counts the occurrences.
+func (s *searchStore) searchLike(ctx context.Context, entityType string, src searchSource, text string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	where := []string{src.where}
+	var args []any
+	for _, term := range terms {
+		where = append(where, "LOWER("+src.title+" || ' ' || coalesce("+src.body+", '')) LIKE ?")
+		args = append(args, "%"+term+"%")
+	}
+	query := `SELECT ` + src.id + `, ` + s.titleColumn(entityType, src) + `, coalesce(` + src.ref + `, ''), ` + src.title + `, coalesce(` + src.body + `, ''), ` + src.updated + `
+		FROM ` + src.from + `
+		WHERE ` + strings.Join(where, " AND ")
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []SearchResult
+	for rows.Next() {
+		item := SearchResult{EntityType: entityType}
+		var title, body string
+		if err := rows.Scan(&item.EntityID, &item.Title, &item.Ref, &title, &body, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		lowerTitle, lowerBody := strings.ToLower(title), strings.ToLower(body)
+		for _, term := range terms {
+			if strings.Contains(lowerTitle, term) {
+				item.Rank += 0.2
+			}
+			item.Rank += 0.01 * float64(min(strings.Count(lowerBody, term), 10))
+		}
+		item.Snippet = searchSnippet(body, terms)
+		res = append(res, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Rank != res[j].Rank {
+			return res[i].Rank > res[j].Rank
+		}
+		return res[i].UpdatedAt.After(res[j].UpdatedAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (s *searchStore) titleColumn(entityType string, src searchSource) string {
+	if col, ok := searchDisplayTitle[entityType]; ok {
+		return "coalesce(" + col + ", '')"
+	}
+	return src.title
+}
+
+func searchTerms(text string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, f := range strings.Fields(strings.ToLower(text)) {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		terms = append(terms, f)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// searchSnippet cuts the part of body around the first term.
+func searchSnippet(body string, terms []string) string {
+	runes := []rune(strings.Join(strings.Fields(body), " "))
+	if len(runes) == 0 {
+		return ""
+	}
+	lower := []rune(strings.ToLower(string(runes)))
+	start := 0
+	for _, term := range terms {
+		if idx := strings.Index(string(lower), term); idx >= 0 {
+			start = len([]rune(string(lower)[:idx]))
+			break
+		}
+	}
+	from := max(start-searchSnippetRunes/4, 0)
+	to := min(from+searchSnippetRunes, len(runes))
+	snippet := strings.TrimSpace(string(runes[from:to]))
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
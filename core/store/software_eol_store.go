@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// End-of-life alert states.
+const (
+	EOLStateApproaching = "approaching"
+	EOLStateExpired     = "expired"
+)
+
+// SoftwareEOLExposure is an active installation whose version has an end-of-life date.
+// Installations without a registry version are matched to a version by text.
+type SoftwareEOLExposure struct {
+	InstallationID   int64     `json:"installation_id"`
+	AssetID          int64     `json:"asset_id"`
+	AssetName        string    `json:"asset_name"`
+	AssetOwner       string    `json:"asset_owner"`
+	AssetCriticality string    `json:"asset_criticality"`
+	ProductID        int64     `json:"product_id"`
+	ProductName      string    `json:"product_name"`
+	ProductVendor    string    `json:"product_vendor"`
+	VersionID        int64     `json:"version_id"`
+	Version          string    `json:"version"`
+	EOLDate          time.Time `json:"eol_date"`
+}
+
+type SoftwareEOLAlert struct {
+	ID             int64      `json:"id"`
+	InstallationID int64      `json:"installation_id"`
+	AssetID        int64      `json:"asset_id"`
+	ProductID      int64      `json:"product_id"`
+	VersionText    string     `json:"version_text"`
+	EOLDate        time.Time  `json:"eol_date"`
+	State          string     `json:"state"`
+	FindingID      *int64     `json:"finding_id,omitempty"`
+	TaskID         *int64     `json:"task_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+type SoftwareEOLStore interface {
+	ListEOLExposure(ctx context.Context, until time.Time) ([]SoftwareEOLExposure, error)
+	ListEOLAlerts(ctx context.Context, openOnly bool) ([]SoftwareEOLAlert, error)
+	SaveEOLAlert(ctx context.Context, a *SoftwareEOLAlert) error
+	ResolveEOLAlert(ctx context.Context, id int64, at time.Time) error
+}
+
+type softwareEOLStore struct {
+	db *sql.DB
+}
+
+func NewSoftwareEOLStore(db *sql.DB) SoftwareEOLStore {
+	return &softwareEOLStore{db: db}
+}
+
+// ListEOLExposure returns installations on active assets whose version reaches end of life on or before until.
+func (s *softwareEOLStore) ListEOLExposure(ctx context.Context, until time.Time) ([]SoftwareEOLExposure, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.asset_id, s.name, s.owner, s.criticality, a.product_id, p.name, p.vendor, v.id, v.version, v.eol_date
+		FROM asset_software a
+		JOIN software_products p ON p.id=a.product_id
+		JOIN software_versions v ON v.product_id=a.product_id AND (v.id=a.version_id OR (a.version_id IS NULL AND LOWER(v.version)=LOWER(a.version_text)))
+		JOIN assets s ON s.id=a.asset_id
+		WHERE a.deleted_at IS NULL AND p.deleted_at IS NULL AND v.deleted_at IS NULL AND s.deleted_at IS NULL AND v.eol_date IS NOT NULL
+		ORDER BY v.eol_date ASC, a.asset_id ASC, a.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	until = until.UTC()
+	seen := map[int64]bool{}
+	var out []SoftwareEOLExposure
+	for rows.Next() {
+		var e SoftwareEOLExposure
+		if err := rows.Scan(&e.InstallationID, &e.AssetID, &e.AssetName, &e.AssetOwner, &e.AssetCriticality, &e.ProductID, &e.ProductName, &e.ProductVendor,
+			&e.VersionID, &e.Version, &e.EOLDate); err != nil {
+			return nil, err
+		}
+		e.EOLDate = e.EOLDate.UTC()
+		if e.EOLDate.After(until) || seen[e.InstallationID] {
+			continue
+		}
+		seen[e.InstallationID] = true
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (s *softwareEOLStore) ListEOLAlerts(ctx context.Context, openOnly bool) ([]SoftwareEOLAlert, error) {
+	query := `
+		SELECT id, installation_id, asset_id, product_id, version_text, eol_date, state, finding_id, task_id, created_at, updated_at, resolved_at
+		FROM software_eol_alerts`
+	if openOnly {
+		query += ` WHERE resolved_at IS NULL`
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []SoftwareEOLAlert
+	for rows.Next() {
+		var a SoftwareEOLAlert
+		var findingID, taskID sql.NullInt64
+		var resolved sql.NullTime
+		if err := rows.Scan(&a.ID, &a.InstallationID, &a.AssetID, &a.ProductID, &a.VersionText, &a.EOLDate, &a.State, &findingID, &taskID,
+			&a.CreatedAt, &a.UpdatedAt, &resolved); err != nil {
+			return nil, err
+		}
+		if findingID.Valid {
+			v := findingID.Int64
+			a.FindingID = &v
+		}
+		if taskID.Valid {
+			v := taskID.Int64
+			a.TaskID = &v
+		}
+		a.EOLDate = a.EOLDate.UTC()
+		a.ResolvedAt = nullTimePtr(resolved)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// SaveEOLAlert inserts or refreshes the alert of an installation and reopens it when it was resolved.
+func (s *softwareEOLStore) SaveEOLAlert(ctx context.Context, a *SoftwareEOLAlert) error {
+	if a == nil || a.InstallationID <= 0 {
+		return errors.New("bad alert")
+	}
+	now := time.Now().UTC()
+	a.State = strings.ToLower(strings.TrimSpace(a.State))
+	if a.State != EOLStateExpired {
+		a.State = EOLStateApproaching
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO software_eol_alerts(installation_id, asset_id, product_id, version_text, eol_date, state, finding_id, task_id, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(installation_id) DO UPDATE SET
+			asset_id=excluded.asset_id,
+			product_id=excluded.product_id,
+			version_text=excluded.version_text,
+			eol_date=excluded.eol_date,
+			state=excluded.state,
+			finding_id=excluded.finding_id,
+			task_id=excluded.task_id,
+			updated_at=excluded.updated_at,
+			resolved_at=NULL`,
+		a.InstallationID, a.AssetID, a.ProductID, a.VersionText, a.EOLDate.UTC(), a.State, nullableID(a.FindingID), nullableID(a.TaskID), now, now)
+	if err != nil {
+		return err
+	}
+	a.UpdatedAt = now
+	a.ResolvedAt = nil
+	return nil
+}
+
+func (s *softwareEOLStore) ResolveEOLAlert(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE software_eol_alerts SET resolved_at=?, updated_at=? WHERE id=? AND resolved_at IS NULL`, at.UTC(), at.UTC(), id)
+	return err
+}
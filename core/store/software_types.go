@@ -95,12 +95,60 @@ type SoftwareStore interface {
 	SuggestProductNames(ctx context.Context, search string, limit int, includeDeleted bool) ([]string, error)
 	SuggestVendors(ctx context.Context, search string, limit int, includeDeleted bool) ([]string, error)
 	SuggestProductTags(ctx context.Context, search string, limit int, includeDeleted bool) ([]string, error)
+
+	// WithTx runs fn against a store bound to one transaction, committed when fn returns nil.
+	WithTx(ctx context.Context, fn func(SoftwareStore) error) error
+}
+
+type softwareDB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type softwareTx interface {
+	softwareDB
+	Commit() error
+	Rollback() error
 }
 
 type softwareStore struct {
-	db *sql.DB
+	db   softwareDB
+	conn *sql.DB
+	tx   *sql.Tx
 }
 
 func NewSoftwareStore(db *sql.DB) SoftwareStore {
-	return &softwareStore{db: db}
+	return &softwareStore{db: db, conn: db}
+}
+
+func (s *softwareStore) WithTx(ctx context.Context, fn func(SoftwareStore) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(&softwareStore{db: tx, conn: s.conn, tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// begin opens a transaction for a single write, or joins the one the store is bound to; the bound
+// transaction is committed or rolled back by WithTx.
+func (s *softwareStore) begin(ctx context.Context) (softwareTx, error) {
+	if s.tx != nil {
+		return joinedTx{s.tx}, nil
+	}
+	return s.conn.BeginTx(ctx, nil)
 }
+
+type joinedTx struct {
+	*sql.Tx
+}
+
+func (joinedTx) Commit() error   { return nil }
+func (joinedTx) Rollback() error { return nil }
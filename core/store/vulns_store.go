@@ -0,0 +1,583 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Vulnerability match states.
+const (
+	VulnMatchOpen   = "open"
+	VulnMatchClosed = "closed"
+)
+
+type Vulnerability struct {
+	ID          int64                   `json:"id"`
+	Source      string                  `json:"source"`
+	ExternalID  string                  `json:"external_id"`
+	Aliases     []string                `json:"aliases,omitempty"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	Severity    string                  `json:"severity"`
+	CVSSScore   float64                 `json:"cvss_score"`
+	CVSSVector  string                  `json:"cvss_vector"`
+	References  []string                `json:"references,omitempty"`
+	PublishedAt *time.Time              `json:"published_at,omitempty"`
+	ModifiedAt  *time.Time              `json:"modified_at,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	Affected    []VulnerabilityAffected `json:"affected,omitempty"`
+}
+
+// VulnerabilityAffected describes one affected product and version range. Empty bounds are open;
+// when Versions is set the entry only matches those exact versions.
+type VulnerabilityAffected struct {
+	ID                    int64    `json:"id"`
+	VulnerabilityID       int64    `json:"vulnerability_id"`
+	CPE                   string   `json:"cpe,omitempty"`
+	Vendor                string   `json:"vendor"`
+	Product               string   `json:"product"`
+	ProductKey            string   `json:"-"`
+	Ecosystem             string   `json:"ecosystem,omitempty"`
+	Versions              []string `json:"versions,omitempty"`
+	VersionStartIncluding string   `json:"version_start_including,omitempty"`
+	VersionStartExcluding string   `json:"version_start_excluding,omitempty"`
+	VersionEndIncluding   string   `json:"version_end_including,omitempty"`
+	VersionEndExcluding   string   `json:"version_end_excluding,omitempty"`
+}
+
+// SoftwareProductAlias maps a software product to the vendor/product naming used by vulnerability feeds.
+type SoftwareProductAlias struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	Vendor    string    `json:"vendor"`
+	Product   string    `json:"product"`
+	CPE       string    `json:"cpe,omitempty"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type VulnerabilityMatch struct {
+	ID              int64      `json:"id"`
+	VulnerabilityID int64      `json:"vulnerability_id"`
+	InstallationID  int64      `json:"installation_id"`
+	AssetID         int64      `json:"asset_id"`
+	ProductID       int64      `json:"product_id"`
+	VersionText     string     `json:"version_text"`
+	FindingID       *int64     `json:"finding_id,omitempty"`
+	Status          string     `json:"status"`
+	FirstSeenAt     time.Time  `json:"first_seen_at"`
+	LastSeenAt      time.Time  `json:"last_seen_at"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+
+	ExternalID  string  `json:"external_id,omitempty"`
+	Severity    string  `json:"severity,omitempty"`
+	CVSSScore   float64 `json:"cvss_score"`
+	AssetName   string  `json:"asset_name,omitempty"`
+	ProductName string  `json:"product_name,omitempty"`
+}
+
+type VulnerableAsset struct {
+	AssetID     int64   `json:"asset_id"`
+	AssetName   string  `json:"asset_name"`
+	Criticality string  `json:"criticality"`
+	Open        int     `json:"open"`
+	Critical    int     `json:"critical"`
+	High        int     `json:"high"`
+	Medium      int     `json:"medium"`
+	Low         int     `json:"low"`
+	MaxCVSS     float64 `json:"max_cvss"`
+}
+
+type VulnerabilityFilter struct {
+	Search   string
+	Severity string
+	Source   string
+	Limit    int
+	Offset   int
+}
+
+type VulnerabilityMatchFilter struct {
+	VulnerabilityID int64
+	AssetID         int64
+	Status          string
+}
+
+type VulnsStore interface {
+	UpsertVulnerability(ctx context.Context, v *Vulnerability) (bool, error)
+	ListVulnerabilities(ctx context.Context, filter VulnerabilityFilter) ([]Vulnerability, error)
+	GetVulnerability(ctx context.Context, id int64) (*Vulnerability, error)
+	ListAffectedByProducts(ctx context.Context, productKeys []string) ([]VulnerabilityAffected, error)
+
+	ListProductAliases(ctx context.Context, productID int64) ([]SoftwareProductAlias, error)
+	AddProductAlias(ctx context.Context, alias *SoftwareProductAlias) (int64, error)
+	DeleteProductAlias(ctx context.Context, productID, aliasID int64) error
+
+	ListInstallations(ctx context.Context, assetID int64) ([]AssetSoftwareInstallation, error)
+	ListMatches(ctx context.Context, filter VulnerabilityMatchFilter) ([]VulnerabilityMatch, error)
+	SaveMatch(ctx context.Context, m *VulnerabilityMatch) error
+	CloseMatch(ctx context.Context, id int64, at time.Time) error
+	ListVulnerableAssets(ctx context.Context) ([]VulnerableAsset, error)
+}
+
+type vulnsStore struct {
+	db *sql.DB
+}
+
+func NewVulnsStore(db *sql.DB) VulnsStore {
+	return &vulnsStore{db: db}
+}
+
+// UpsertVulnerability inserts or refreshes a vulnerability keyed by (source, external_id) and
+// replaces its affected entries. It reports whether the record was new.
+func (s *vulnsStore) UpsertVulnerability(ctx context.Context, v *Vulnerability) (bool, error) {
+	if v == nil || strings.TrimSpace(v.Source) == "" || strings.TrimSpace(v.ExternalID) == "" {
+		return false, errors.New("bad vulnerability")
+	}
+	now := time.Now().UTC()
+	v.Source = strings.ToLower(strings.TrimSpace(v.Source))
+	v.ExternalID = strings.TrimSpace(v.ExternalID)
+	v.Severity = normalizeFindingSeverity(v.Severity)
+	aliasesJSON, _ := json.Marshal(nonNilStrings(v.Aliases))
+	refsJSON, _ := json.Marshal(nonNilStrings(v.References))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	var id int64
+	created := false
+	err = tx.QueryRowContext(ctx, `SELECT id FROM vulnerabilities WHERE source=? AND external_id=?`, v.Source, v.ExternalID).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO vulnerabilities(source, external_id, aliases_json, title, description, severity, cvss_score, cvss_vector, references_json, published_at, modified_at, created_at, updated_at)
+			VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+			v.Source, v.ExternalID, string(aliasesJSON), strings.TrimSpace(v.Title), strings.TrimSpace(v.Description), v.Severity, v.CVSSScore,
+			strings.TrimSpace(v.CVSSVector), string(refsJSON), nullableTime(v.PublishedAt), nullableTime(v.ModifiedAt), now, now)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		id, _ = res.LastInsertId()
+		created = true
+		v.CreatedAt = now
+	case err != nil:
+		tx.Rollback()
+		return false, err
+	default:
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE vulnerabilities
+			SET aliases_json=?, title=?, description=?, severity=?, cvss_score=?, cvss_vector=?, references_json=?, published_at=?, modified_at=?, updated_at=?
+			WHERE id=?`,
+			string(aliasesJSON), strings.TrimSpace(v.Title), strings.TrimSpace(v.Description), v.Severity, v.CVSSScore, strings.TrimSpace(v.CVSSVector),
+			string(refsJSON), nullableTime(v.PublishedAt), nullableTime(v.ModifiedAt), now, id); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM vulnerability_affected WHERE vulnerability_id=?`, id); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+	for i := range v.Affected {
+		a := &v.Affected[i]
+		versionsJSON, _ := json.Marshal(nonNilStrings(a.Versions))
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO vulnerability_affected(vulnerability_id, cpe, vendor, product, product_key, ecosystem, versions_json, version_start_including, version_start_excluding, version_end_including, version_end_excluding)
+			VALUES(?,?,?,?,?,?,?,?,?,?,?)`,
+			id, a.CPE, a.Vendor, a.Product, a.ProductKey, a.Ecosystem, string(versionsJSON),
+			a.VersionStartIncluding, a.VersionStartExcluding, a.VersionEndIncluding, a.VersionEndExcluding); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		a.VulnerabilityID = id
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	v.ID = id
+	v.UpdatedAt = now
+	return created, nil
+}
+
+const vulnerabilityColumns = `id, source, external_id, aliases_json, title, description, severity, cvss_score, cvss_vector, references_json,
+		       published_at, modified_at, created_at, updated_at`
+
+func (s *vulnsStore) ListVulnerabilities(ctx context.Context, filter VulnerabilityFilter) ([]Vulnerability, error) {
+	clauses := []string{}
+	args := []any{}
+	if q := strings.TrimSpace(filter.Search); q != "" {
+		clauses = append(clauses, "(LOWER(external_id) LIKE ? OR LOWER(title) LIKE ? OR LOWER(aliases_json) LIKE ?)")
+		pattern := "%" + strings.ToLower(q) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Severity)); v != "" {
+		clauses = append(clauses, "severity=?")
+		args = append(args, v)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Source)); v != "" {
+		clauses = append(clauses, "source=?")
+		args = append(args, v)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	query := `SELECT ` + vulnerabilityColumns + ` FROM vulnerabilities`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY cvss_score DESC, external_id ASC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Vulnerability
+	for rows.Next() {
+		v, err := scanVulnerability(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, rows.Err()
+}
+
+func (s *vulnsStore) GetVulnerability(ctx context.Context, id int64) (*Vulnerability, error) {
+	if id <= 0 {
+		return nil, errors.New("bad id")
+	}
+	v, err := scanVulnerability(s.db.QueryRowContext(ctx, `SELECT `+vulnerabilityColumns+` FROM vulnerabilities WHERE id=?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	affected, err := s.listAffected(ctx, `WHERE vulnerability_id=?`, id)
+	if err != nil {
+		return nil, err
+	}
+	v.Affected = affected
+	return v, nil
+}
+
+// ListAffectedByProducts returns the affected entries whose normalized product name is one of productKeys.
+func (s *vulnsStore) ListAffectedByProducts(ctx context.Context, productKeys []string) ([]VulnerabilityAffected, error) {
+	var out []VulnerabilityAffected
+	for start := 0; start < len(productKeys); start += 500 {
+		end := start + 500
+		if end > len(productKeys) {
+			end = len(productKeys)
+		}
+		args := make([]any, 0, end-start)
+		for _, key := range productKeys[start:end] {
+			args = append(args, key)
+		}
+		items, err := s.listAffected(ctx, `WHERE product_key IN (`+placeholders(len(args))+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+func (s *vulnsStore) listAffected(ctx context.Context, where string, args ...any) ([]VulnerabilityAffected, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, vulnerability_id, cpe, vendor, product, product_key, ecosystem, versions_json,
+		       version_start_including, version_start_excluding, version_end_including, version_end_excluding
+		FROM vulnerability_affected `+where+`
+		ORDER BY vulnerability_id ASC, id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []VulnerabilityAffected
+	for rows.Next() {
+		var a VulnerabilityAffected
+		var versionsRaw string
+		if err := rows.Scan(&a.ID, &a.VulnerabilityID, &a.CPE, &a.Vendor, &a.Product, &a.ProductKey, &a.Ecosystem, &versionsRaw,
+			&a.VersionStartIncluding, &a.VersionStartExcluding, &a.VersionEndIncluding, &a.VersionEndExcluding); err != nil {
+			return nil, err
+		}
+		if versionsRaw != "" {
+			_ = json.Unmarshal([]byte(versionsRaw), &a.Versions)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ListProductAliases returns aliases of one product, or of all products when productID is 0.
+func (s *vulnsStore) ListProductAliases(ctx context.Context, productID int64) ([]SoftwareProductAlias, error) {
+	query := `SELECT id, product_id, vendor, product, cpe, created_by, created_at FROM software_product_aliases`
+	args := []any{}
+	if productID > 0 {
+		query += " WHERE product_id=?"
+		args = append(args, productID)
+	}
+	query += " ORDER BY product_id ASC, id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []SoftwareProductAlias
+	for rows.Next() {
+		var a SoftwareProductAlias
+		var createdBy sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.ProductID, &a.Vendor, &a.Product, &a.CPE, &createdBy, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			v := createdBy.Int64
+			a.CreatedBy = &v
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *vulnsStore) AddProductAlias(ctx context.Context, alias *SoftwareProductAlias) (int64, error) {
+	if alias == nil || alias.ProductID <= 0 || strings.TrimSpace(alias.Product) == "" {
+		return 0, errors.New("bad alias")
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO software_product_aliases(product_id, vendor, product, cpe, created_by, created_at)
+		VALUES(?,?,?,?,?,?)`,
+		alias.ProductID, alias.Vendor, alias.Product, strings.TrimSpace(alias.CPE), nullableID(alias.CreatedBy), now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	alias.ID = id
+	alias.CreatedAt = now
+	return id, nil
+}
+
+func (s *vulnsStore) DeleteProductAlias(ctx context.Context, productID, aliasID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM software_product_aliases WHERE id=? AND product_id=?`, aliasID, productID)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListInstallations returns active installations on active assets, optionally limited to one asset.
+func (s *vulnsStore) ListInstallations(ctx context.Context, assetID int64) ([]AssetSoftwareInstallation, error) {
+	clauses := []string{"a.deleted_at IS NULL", "p.deleted_at IS NULL", "s.deleted_at IS NULL"}
+	args := []any{}
+	if assetID > 0 {
+		clauses = append(clauses, "a.asset_id=?")
+		args = append(args, assetID)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.asset_id, a.product_id, a.version_id, a.version_text, p.name, p.vendor, v.version, s.name
+		FROM asset_software a
+		JOIN software_products p ON p.id=a.product_id
+		LEFT JOIN software_versions v ON v.id=a.version_id
+		JOIN assets s ON s.id=a.asset_id
+		WHERE `+strings.Join(clauses, " AND ")+`
+		ORDER BY a.asset_id ASC, a.id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []AssetSoftwareInstallation
+	for rows.Next() {
+		var inst AssetSoftwareInstallation
+		var vid sql.NullInt64
+		var versionLabel sql.NullString
+		if err := rows.Scan(&inst.ID, &inst.AssetID, &inst.ProductID, &vid, &inst.VersionText, &inst.ProductName, &inst.ProductVendor, &versionLabel, &inst.AssetName); err != nil {
+			return nil, err
+		}
+		if vid.Valid {
+			v := vid.Int64
+			inst.VersionID = &v
+		}
+		if versionLabel.Valid {
+			inst.VersionLabel = strings.TrimSpace(versionLabel.String)
+		}
+		out = append(out, inst)
+	}
+	return out, rows.Err()
+}
+
+func (s *vulnsStore) ListMatches(ctx context.Context, filter VulnerabilityMatchFilter) ([]VulnerabilityMatch, error) {
+	clauses := []string{}
+	args := []any{}
+	if filter.VulnerabilityID > 0 {
+		clauses = append(clauses, "m.vulnerability_id=?")
+		args = append(args, filter.VulnerabilityID)
+	}
+	if filter.AssetID > 0 {
+		clauses = append(clauses, "m.asset_id=?")
+		args = append(args, filter.AssetID)
+	}
+	if v := strings.ToLower(strings.TrimSpace(filter.Status)); v != "" {
+		clauses = append(clauses, "m.status=?")
+		args = append(args, v)
+	}
+	query := `
+		SELECT m.id, m.vulnerability_id, m.installation_id, m.asset_id, m.product_id, m.version_text, m.finding_id, m.status,
+		       m.first_seen_at, m.last_seen_at, m.closed_at,
+		       v.external_id, v.severity, v.cvss_score,
+		       COALESCE(s.name, ''), COALESCE(p.name, '')
+		FROM vulnerability_matches m
+		JOIN vulnerabilities v ON v.id=m.vulnerability_id
+		LEFT JOIN assets s ON s.id=m.asset_id
+		LEFT JOIN software_products p ON p.id=m.product_id`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY v.cvss_score DESC, m.asset_id ASC, m.id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []VulnerabilityMatch
+	for rows.Next() {
+		var m VulnerabilityMatch
+		var findingID sql.NullInt64
+		var closed sql.NullTime
+		if err := rows.Scan(&m.ID, &m.VulnerabilityID, &m.InstallationID, &m.AssetID, &m.ProductID, &m.VersionText, &findingID, &m.Status,
+			&m.FirstSeenAt, &m.LastSeenAt, &closed, &m.ExternalID, &m.Severity, &m.CVSSScore, &m.AssetName, &m.ProductName); err != nil {
+			return nil, err
+		}
+		if findingID.Valid {
+			v := findingID.Int64
+			m.FindingID = &v
+		}
+		m.ClosedAt = nullTimePtr(closed)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// SaveMatch records that an installation is (still) affected and reopens a previously closed match.
+func (s *vulnsStore) SaveMatch(ctx context.Context, m *VulnerabilityMatch) error {
+	if m == nil || m.VulnerabilityID <= 0 || m.InstallationID <= 0 {
+		return errors.New("bad match")
+	}
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO vulnerability_matches(vulnerability_id, installation_id, asset_id, product_id, version_text, finding_id, status, first_seen_at, last_seen_at)
+		VALUES(?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(vulnerability_id, installation_id) DO UPDATE SET
+			asset_id=excluded.asset_id,
+			product_id=excluded.product_id,
+			version_text=excluded.version_text,
+			finding_id=excluded.finding_id,
+			status=excluded.status,
+			last_seen_at=excluded.last_seen_at,
+			closed_at=NULL`,
+		m.VulnerabilityID, m.InstallationID, m.AssetID, m.ProductID, m.VersionText, nullableID(m.FindingID), VulnMatchOpen, now, now)
+	if err != nil {
+		return err
+	}
+	m.Status = VulnMatchOpen
+	m.LastSeenAt = now
+	m.ClosedAt = nil
+	return nil
+}
+
+func (s *vulnsStore) CloseMatch(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE vulnerability_matches SET status=?, closed_at=? WHERE id=? AND status=?`, VulnMatchClosed, at.UTC(), id, VulnMatchOpen)
+	return err
+}
+
+func (s *vulnsStore) ListVulnerableAssets(ctx context.Context) ([]VulnerableAsset, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.name, s.criticality, v.severity, v.cvss_score
+		FROM vulnerability_matches m
+		JOIN vulnerabilities v ON v.id=m.vulnerability_id
+		JOIN assets s ON s.id=m.asset_id
+		WHERE m.status=? AND s.deleted_at IS NULL
+		ORDER BY s.id ASC`, VulnMatchOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byAsset := map[int64]*VulnerableAsset{}
+	var order []int64
+	for rows.Next() {
+		var id int64
+		var name, criticality, severity string
+		var score float64
+		if err := rows.Scan(&id, &name, &criticality, &severity, &score); err != nil {
+			return nil, err
+		}
+		item := byAsset[id]
+		if item == nil {
+			item = &VulnerableAsset{AssetID: id, AssetName: name, Criticality: criticality}
+			byAsset[id] = item
+			order = append(order, id)
+		}
+		item.Open++
+		switch severity {
+		case "critical":
+			item.Critical++
+		case "high":
+			item.High++
+		case "medium":
+			item.Medium++
+		default:
+			item.Low++
+		}
+		if score > item.MaxCVSS {
+			item.MaxCVSS = score
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]VulnerableAsset, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byAsset[id])
+	}
+	return out, nil
+}
+
+func scanVulnerability(row assetRowScanner) (*Vulnerability, error) {
+	var v Vulnerability
+	var aliasesRaw, refsRaw string
+	var published, modified sql.NullTime
+	if err := row.Scan(&v.ID, &v.Source, &v.ExternalID, &aliasesRaw, &v.Title, &v.Description, &v.Severity, &v.CVSSScore, &v.CVSSVector, &refsRaw,
+		&published, &modified, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if aliasesRaw != "" {
+		_ = json.Unmarshal([]byte(aliasesRaw), &v.Aliases)
+	}
+	if refsRaw != "" {
+		_ = json.Unmarshal([]byte(refsRaw), &v.References)
+	}
+	v.PublishedAt = nullTimePtr(published)
+	v.ModifiedAt = nullTimePtr(modified)
+	return &v, nil
+}
+
+func nonNilStrings(in []string) []string {
+	if in == nil {
+		return []string{}
+	}
+	return in
+}
@@ -0,0 +1,190 @@
+package vulns
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+type bduVul struct {
+	Identifier  string `xml:"identifier"`
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Software    []struct {
+		Vendor  string `xml:"vendor"`
+		Name    string `xml:"name"`
+		Version string `xml:"version"`
+	} `xml:"vulnerable_software>soft"`
+	IdentifyDate string    `xml:"identify_date"`
+	PublishDate  string    `xml:"publication_date"`
+	LastUpdate   string    `xml:"last_upd_date"`
+	CVSS         bduVector `xml:"cvss>vector"`
+	CVSS3        bduVector `xml:"cvss3>vector"`
+	Severity     string    `xml:"severity"`
+	Sources      string    `xml:"sources"`
+	Identifiers  []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"identifiers>identifier"`
+}
+
+type bduVector struct {
+	Score string `xml:"score,attr"`
+	Value string `xml:",chardata"`
+}
+
+var (
+	bduFrom       = regexp.MustCompile(`(?i)(?:^|\s)(?:от|from)\s+([0-9][^\s,;()]*)`)
+	bduTo         = regexp.MustCompile(`(?i)(?:^|\s)(?:до|to|before)\s+([0-9][^\s,;()]*)(\s+(?:включительно|inclusive))?`)
+	bduAndEarlier = regexp.MustCompile(`(?i)^([0-9][^\s,;()]*)\s+(?:и\s+более\s+ранние|and\s+earlier|и\s+ниже)`)
+)
+
+// ParseBDU reads the FSTEC BDU vulnerability XML export (vulxml), streaming <vul> elements.
+func ParseBDU(r io.Reader) ([]store.Vulnerability, error) {
+	dec := xml.NewDecoder(r)
+	var out []store.Vulnerability
+	seenRoot := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrFeedInvalid
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "vul" {
+			seenRoot = true
+			continue
+		}
+		var item bduVul
+		if err := dec.DecodeElement(&item, &start); err != nil {
+			return nil, ErrFeedInvalid
+		}
+		seenRoot = true
+		if v, ok := bduToVulnerability(item); ok {
+			out = append(out, v)
+		}
+	}
+	if !seenRoot {
+		return nil, ErrFeedInvalid
+	}
+	return out, nil
+}
+
+func bduToVulnerability(item bduVul) (store.Vulnerability, bool) {
+	id := strings.TrimSpace(item.Identifier)
+	if id == "" {
+		return store.Vulnerability{}, false
+	}
+	v := store.Vulnerability{
+		Source:      FormatBDU,
+		ExternalID:  id,
+		Title:       truncate(item.Name, 200),
+		Description: strings.TrimSpace(item.Description),
+		PublishedAt: parseFeedTime(firstNonEmpty(item.PublishDate, item.IdentifyDate)),
+		ModifiedAt:  parseFeedTime(item.LastUpdate),
+	}
+	for _, ident := range item.Identifiers {
+		v.Aliases = appendUnique(v.Aliases, ident.Value)
+	}
+	for _, src := range strings.Fields(item.Sources) {
+		if strings.HasPrefix(src, "http") {
+			v.References = appendUnique(v.References, src)
+		}
+	}
+	if vec := strings.TrimSpace(item.CVSS3.Value); vec != "" {
+		v.CVSSVector = "CVSS:3.0/" + strings.TrimPrefix(vec, "CVSS:3.0/")
+		if score, err := CVSS3BaseScore(v.CVSSVector); err == nil {
+			v.CVSSScore = score
+		} else {
+			v.CVSSScore = parseBDUScore(item.CVSS3.Score)
+		}
+	} else if vec := strings.TrimSpace(item.CVSS.Value); vec != "" {
+		v.CVSSVector = vec
+		v.CVSSScore = parseBDUScore(item.CVSS.Score)
+	}
+	v.Severity = SeverityFromCVSS(v.CVSSScore, bduSeverity(item.Severity))
+	for _, soft := range item.Software {
+		product := strings.TrimSpace(soft.Name)
+		if product == "" {
+			continue
+		}
+		a := bduVersionRange(soft.Version)
+		a.Vendor = strings.TrimSpace(soft.Vendor)
+		a.Product = product
+		v.Affected = append(v.Affected, a)
+	}
+	return v, true
+}
+
+// bduVersionRange interprets the free-text version field ("от 2.0 до 2.4.57 включительно",
+// "до 1.1.1k", "11.0.10 и более ранние", "-").
+func bduVersionRange(raw string) store.VulnerabilityAffected {
+	var a store.VulnerabilityAffected
+	text := strings.TrimSpace(raw)
+	if text == "" || text == "-" || strings.HasPrefix(text, "- ") {
+		return a
+	}
+	if m := bduAndEarlier.FindStringSubmatch(text); m != nil {
+		a.VersionEndIncluding = m[1]
+		return a
+	}
+	from := bduFrom.FindStringSubmatch(text)
+	to := bduTo.FindStringSubmatch(text)
+	if from == nil && to == nil {
+		a.Versions = []string{NormalizeVersion(text)}
+		return a
+	}
+	if from != nil {
+		a.VersionStartIncluding = from[1]
+	}
+	if to != nil {
+		if strings.TrimSpace(to[2]) != "" {
+			a.VersionEndIncluding = to[1]
+		} else {
+			a.VersionEndExcluding = to[1]
+		}
+	}
+	return a
+}
+
+func bduSeverity(raw string) string {
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.Contains(lower, "критическ"):
+		return "critical"
+	case strings.Contains(lower, "высок"):
+		return "high"
+	case strings.Contains(lower, "средн"):
+		return "medium"
+	case strings.Contains(lower, "низк"):
+		return "low"
+	}
+	return ""
+}
+
+func parseBDUScore(raw string) float64 {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(raw), ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
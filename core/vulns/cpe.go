@@ -0,0 +1,91 @@
+package vulns
+
+import (
+	"strings"
+)
+
+// CPE holds the fields of a CPE 2.2/2.3 name used for matching.
+type CPE struct {
+	Part    string
+	Vendor  string
+	Product string
+	Version string
+}
+
+// ParseCPE accepts "cpe:2.3:a:vendor:product:version:..." and the legacy "cpe:/a:vendor:product:version" forms.
+func ParseCPE(raw string) (CPE, bool) {
+	raw = strings.TrimSpace(raw)
+	lower := strings.ToLower(raw)
+	var fields []string
+	switch {
+	case strings.HasPrefix(lower, "cpe:2.3:"):
+		fields = splitCPE23(raw[len("cpe:2.3:"):])
+	case strings.HasPrefix(lower, "cpe:/"):
+		fields = strings.Split(raw[len("cpe:/"):], ":")
+	default:
+		return CPE{}, false
+	}
+	if len(fields) < 3 {
+		return CPE{}, false
+	}
+	c := CPE{Part: strings.ToLower(fields[0]), Vendor: unescapeCPE(fields[1]), Product: unescapeCPE(fields[2])}
+	if len(fields) > 3 {
+		c.Version = unescapeCPE(fields[3])
+	}
+	if c.Product == "" || c.Product == "*" {
+		return CPE{}, false
+	}
+	if c.Vendor == "*" {
+		c.Vendor = ""
+	}
+	if c.Version == "*" || c.Version == "-" {
+		c.Version = ""
+	}
+	return c, true
+}
+
+// NormalizeName folds vendor/product names to the CPE convention: lower case with underscores,
+// so "Apache HTTP Server" and "apache_http_server" compare equal.
+func NormalizeName(raw string) string {
+	var b strings.Builder
+	pendingSep := false
+	for _, r := range strings.ToLower(strings.TrimSpace(raw)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' || r > 127 {
+			if pendingSep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pendingSep = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingSep = true
+	}
+	return b.String()
+}
+
+func splitCPE23(s string) []string {
+	var out []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune('\\')
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			out = append(out, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	out = append(out, cur.String())
+	return out
+}
+
+func unescapeCPE(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "\\", ""))
+}
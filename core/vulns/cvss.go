@@ -0,0 +1,103 @@
+package vulns
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrCVSSVector = errors.New("vulns.cvss.vectorInvalid")
+
+var (
+	cvssAV = map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}
+	cvssAC = map[string]float64{"L": 0.77, "H": 0.44}
+	cvssUI = map[string]float64{"N": 0.85, "R": 0.62}
+	cvssCI = map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+)
+
+// CVSS3BaseScore computes the CVSS v3.0/v3.1 base score from a vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+func CVSS3BaseScore(vector string) (float64, error) {
+	metrics := map[string]string{}
+	for _, part := range strings.Split(strings.TrimSpace(vector), "/") {
+		k, v, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, ErrCVSSVector
+		}
+		metrics[strings.ToUpper(k)] = strings.ToUpper(v)
+	}
+	if ver := metrics["CVSS"]; ver != "" && !strings.HasPrefix(ver, "3.") {
+		return 0, ErrCVSSVector
+	}
+	av, ok1 := cvssAV[metrics["AV"]]
+	ac, ok2 := cvssAC[metrics["AC"]]
+	ui, ok3 := cvssUI[metrics["UI"]]
+	c, ok4 := cvssCI[metrics["C"]]
+	i, ok5 := cvssCI[metrics["I"]]
+	a, ok6 := cvssCI[metrics["A"]]
+	scope := metrics["S"]
+	if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6) || (scope != "U" && scope != "C") {
+		return 0, ErrCVSSVector
+	}
+	changed := scope == "C"
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if changed {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if changed {
+			pr = 0.5
+		}
+	default:
+		return 0, ErrCVSSVector
+	}
+	iss := 1 - (1-c)*(1-i)*(1-a)
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * av * ac * pr * ui
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// SeverityFromCVSS maps a base score to the finding severity scale. Zero scores return fallback.
+func SeverityFromCVSS(score float64, fallback string) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	}
+	switch v := strings.ToLower(strings.TrimSpace(fallback)); v {
+	case "critical", "high", "medium", "low":
+		return v
+	case "moderate":
+		return "medium"
+	}
+	return "medium"
+}
+
+// roundUp implements the CVSS v3.1 Roundup function.
+func roundUp(v float64) float64 {
+	n := int64(math.Round(v * 100000))
+	if n%10000 == 0 {
+		return float64(n) / 100000
+	}
+	return (math.Floor(float64(n)/10000) + 1) / 10
+}
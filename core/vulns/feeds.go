@@ -0,0 +1,131 @@
+package vulns
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Supported offline feed formats.
+const (
+	FormatNVD = "nvd"
+	FormatOSV = "osv"
+	FormatBDU = "bdu"
+)
+
+var (
+	ErrFormatInvalid = errors.New("vulns.import.formatInvalid")
+	ErrFeedInvalid   = errors.New("vulns.import.feedInvalid")
+)
+
+// maxFeedSize bounds decompressed feed files.
+const maxFeedSize = 512 << 20
+
+// ParseFeed decodes a feed file, gzip-compressed or not. An empty format is detected from the content.
+func ParseFeed(format string, data []byte) ([]store.Vulnerability, error) {
+	data, err := Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	switch format {
+	case FormatNVD:
+		return ParseNVD(bytes.NewReader(data))
+	case FormatOSV:
+		return ParseOSV(data)
+	case FormatBDU:
+		return ParseBDU(bytes.NewReader(data))
+	default:
+		return nil, ErrFormatInvalid
+	}
+}
+
+// Decompress unwraps gzip feeds (NVD and BDU are distributed as .gz); other data is returned as is.
+func Decompress(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrFeedInvalid
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxFeedSize))
+	if err != nil {
+		return nil, ErrFeedInvalid
+	}
+	return out, nil
+}
+
+// DetectFormat guesses the feed format from the first bytes of the file.
+func DetectFormat(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return FormatOSV
+	}
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	trimmed := bytes.TrimSpace(head)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatBDU
+	case bytes.Contains(trimmed, []byte(`"vulnerabilities"`)) || bytes.Contains(trimmed, []byte(`"CVE_Items"`)):
+		return FormatNVD
+	case bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")):
+		return FormatOSV
+	}
+	return ""
+}
+
+func parseFeedTime(raw string) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05.000", "2006-01-02T15:04:05", "2006-01-02T15:04Z", "2006-01-02", "02.01.2006"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		dup := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, v) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
@@ -0,0 +1,139 @@
+package vulns
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+type nvdFeed struct {
+	Vulnerabilities []struct {
+		CVE nvdCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdCVE struct {
+	ID           string `json:"id"`
+	Published    string `json:"published"`
+	LastModified string `json:"lastModified"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Metrics struct {
+		V31 []nvdMetric `json:"cvssMetricV31"`
+		V30 []nvdMetric `json:"cvssMetricV30"`
+		V2  []nvdMetric `json:"cvssMetricV2"`
+	} `json:"metrics"`
+	Configurations []struct {
+		Nodes []struct {
+			CPEMatch []struct {
+				Vulnerable            bool   `json:"vulnerable"`
+				Criteria              string `json:"criteria"`
+				VersionStartIncluding string `json:"versionStartIncluding"`
+				VersionStartExcluding string `json:"versionStartExcluding"`
+				VersionEndIncluding   string `json:"versionEndIncluding"`
+				VersionEndExcluding   string `json:"versionEndExcluding"`
+			} `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+type nvdMetric struct {
+	Type     string `json:"type"`
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		VectorString string  `json:"vectorString"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+// ParseNVD reads an NVD CVE API / JSON 2.0 feed file.
+func ParseNVD(r io.Reader) ([]store.Vulnerability, error) {
+	var feed nvdFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, ErrFeedInvalid
+	}
+	out := make([]store.Vulnerability, 0, len(feed.Vulnerabilities))
+	for _, item := range feed.Vulnerabilities {
+		cve := item.CVE
+		if strings.TrimSpace(cve.ID) == "" {
+			continue
+		}
+		v := store.Vulnerability{
+			Source:      FormatNVD,
+			ExternalID:  strings.TrimSpace(cve.ID),
+			PublishedAt: parseFeedTime(cve.Published),
+			ModifiedAt:  parseFeedTime(cve.LastModified),
+		}
+		for _, d := range cve.Descriptions {
+			if strings.EqualFold(d.Lang, "en") || v.Description == "" {
+				v.Description = strings.TrimSpace(d.Value)
+			}
+		}
+		v.Title = truncate(v.Description, 200)
+		if m := preferredNVDMetric(cve); m != nil {
+			v.CVSSScore = m.CVSSData.BaseScore
+			v.CVSSVector = m.CVSSData.VectorString
+			sev := m.CVSSData.BaseSeverity
+			if sev == "" {
+				sev = m.BaseSeverity
+			}
+			v.Severity = SeverityFromCVSS(v.CVSSScore, sev)
+		} else {
+			v.Severity = SeverityFromCVSS(0, "")
+		}
+		for _, ref := range cve.References {
+			v.References = appendUnique(v.References, ref.URL)
+		}
+		for _, cfg := range cve.Configurations {
+			for _, node := range cfg.Nodes {
+				for _, m := range node.CPEMatch {
+					if !m.Vulnerable {
+						continue
+					}
+					cpe, ok := ParseCPE(m.Criteria)
+					if !ok {
+						continue
+					}
+					a := store.VulnerabilityAffected{
+						CPE:                   m.Criteria,
+						Vendor:                cpe.Vendor,
+						Product:               cpe.Product,
+						VersionStartIncluding: m.VersionStartIncluding,
+						VersionStartExcluding: m.VersionStartExcluding,
+						VersionEndIncluding:   m.VersionEndIncluding,
+						VersionEndExcluding:   m.VersionEndExcluding,
+					}
+					if cpe.Version != "" {
+						a.Versions = []string{cpe.Version}
+					}
+					v.Affected = append(v.Affected, a)
+				}
+			}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func preferredNVDMetric(cve nvdCVE) *nvdMetric {
+	for _, list := range [][]nvdMetric{cve.Metrics.V31, cve.Metrics.V30, cve.Metrics.V2} {
+		if len(list) == 0 {
+			continue
+		}
+		for i := range list {
+			if strings.EqualFold(list[i].Type, "Primary") {
+				return &list[i]
+			}
+		}
+		return &list[0]
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package vulns
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+type osvRecord struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Details   string   `json:"details"`
+	Published string   `json:"published"`
+	Modified  string   `json:"modified"`
+	Withdrawn string   `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string              `json:"type"`
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		Versions         []string `json:"versions"`
+		DatabaseSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"database_specific"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+// ParseOSV reads OSV records: a single JSON object, a JSON array or a zip dump of per-record files.
+func ParseOSV(data []byte) ([]store.Vulnerability, error) {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return parseOSVZip(data)
+	}
+	trimmed := bytes.TrimSpace(data)
+	var records []osvRecord
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, ErrFeedInvalid
+		}
+	} else {
+		var rec osvRecord
+		if err := json.Unmarshal(trimmed, &rec); err != nil {
+			return nil, ErrFeedInvalid
+		}
+		records = []osvRecord{rec}
+	}
+	out := make([]store.Vulnerability, 0, len(records))
+	for _, rec := range records {
+		if v, ok := osvToVulnerability(rec); ok {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func parseOSVZip(data []byte) ([]store.Vulnerability, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrFeedInvalid
+	}
+	var out []store.Vulnerability
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, ErrFeedInvalid
+		}
+		raw, err := io.ReadAll(io.LimitReader(rc, 8<<20))
+		rc.Close()
+		if err != nil {
+			return nil, ErrFeedInvalid
+		}
+		var rec osvRecord
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			continue
+		}
+		if v, ok := osvToVulnerability(rec); ok {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func osvToVulnerability(rec osvRecord) (store.Vulnerability, bool) {
+	if strings.TrimSpace(rec.ID) == "" || strings.TrimSpace(rec.Withdrawn) != "" {
+		return store.Vulnerability{}, false
+	}
+	v := store.Vulnerability{
+		Source:      FormatOSV,
+		ExternalID:  strings.TrimSpace(rec.ID),
+		Aliases:     appendUnique(nil, rec.Aliases...),
+		Title:       truncate(rec.Summary, 200),
+		Description: strings.TrimSpace(rec.Details),
+		PublishedAt: parseFeedTime(rec.Published),
+		ModifiedAt:  parseFeedTime(rec.Modified),
+	}
+	if v.Title == "" {
+		v.Title = truncate(v.Description, 200)
+	}
+	for _, sev := range rec.Severity {
+		if !strings.HasPrefix(strings.ToUpper(sev.Type), "CVSS_V3") {
+			continue
+		}
+		if score, err := CVSS3BaseScore(sev.Score); err == nil {
+			v.CVSSScore = score
+			v.CVSSVector = sev.Score
+			break
+		}
+	}
+	fallback := rec.DatabaseSpecific.Severity
+	for _, ref := range rec.References {
+		v.References = appendUnique(v.References, ref.URL)
+	}
+	for _, aff := range rec.Affected {
+		name := strings.TrimSpace(aff.Package.Name)
+		if name == "" {
+			continue
+		}
+		if fallback == "" {
+			fallback = aff.DatabaseSpecific.Severity
+		}
+		base := store.VulnerabilityAffected{Product: strings.ToLower(name), Ecosystem: strings.TrimSpace(aff.Package.Ecosystem)}
+		ranged := false
+		for _, rng := range aff.Ranges {
+			if strings.EqualFold(rng.Type, "GIT") {
+				continue
+			}
+			for _, entry := range osvRanges(rng.Events) {
+				a := base
+				a.VersionStartIncluding = entry.VersionStartIncluding
+				a.VersionEndExcluding = entry.VersionEndExcluding
+				a.VersionEndIncluding = entry.VersionEndIncluding
+				v.Affected = append(v.Affected, a)
+				ranged = true
+			}
+		}
+		if !ranged && len(aff.Versions) > 0 {
+			a := base
+			a.Versions = append([]string(nil), aff.Versions...)
+			v.Affected = append(v.Affected, a)
+		}
+	}
+	v.Severity = SeverityFromCVSS(v.CVSSScore, fallback)
+	return v, true
+}
+
+// osvRanges turns an ordered introduced/fixed/last_affected event list into bounded ranges.
+func osvRanges(events []map[string]string) []store.VulnerabilityAffected {
+	var out []store.VulnerabilityAffected
+	var cur *store.VulnerabilityAffected
+	for _, ev := range events {
+		if intro, ok := ev["introduced"]; ok {
+			if cur != nil {
+				out = append(out, *cur)
+			}
+			cur = &store.VulnerabilityAffected{}
+			if intro != "0" {
+				cur.VersionStartIncluding = intro
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if fixed, ok := ev["fixed"]; ok {
+			cur.VersionEndExcluding = fixed
+			out = append(out, *cur)
+			cur = nil
+		} else if last, ok := ev["last_affected"]; ok {
+			cur.VersionEndIncluding = last
+			out = append(out, *cur)
+			cur = nil
+		}
+	}
+	if cur != nil {
+		out = append(out, *cur)
+	}
+	return out
+}
@@ -0,0 +1,317 @@
+package vulns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// FindingType is the finding_type assigned to findings raised by vulnerability matching.
+const FindingType = "vulnerability"
+
+type Service struct {
+	store    store.VulnsStore
+	findings store.FindingsStore
+	links    store.EntityLinksStore
+	audits   store.AuditStore
+}
+
+func NewService(vs store.VulnsStore, findings store.FindingsStore, links store.EntityLinksStore, audits store.AuditStore) *Service {
+	return &Service{store: vs, findings: findings, links: links, audits: audits}
+}
+
+type ImportResult struct {
+	Format  string      `json:"format"`
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Scan    *ScanResult `json:"scan,omitempty"`
+}
+
+type ScanResult struct {
+	Installations   int `json:"installations"`
+	Matches         int `json:"matches"`
+	FindingsCreated int `json:"findings_created"`
+	Reopened        int `json:"reopened"`
+	Closed          int `json:"closed"`
+}
+
+// Import loads a feed file into the vulnerability catalog and rescans all installations.
+func (s *Service) Import(ctx context.Context, format string, data []byte, username string, userID int64) (*ImportResult, error) {
+	data, err := Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	items, err := ParseFeed(format, data)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{Format: strings.ToLower(format), Total: len(items)}
+	for i := range items {
+		for j := range items[i].Affected {
+			items[i].Affected[j].ProductKey = NormalizeName(items[i].Affected[j].Product)
+		}
+		created, err := s.store.UpsertVulnerability(ctx, &items[i])
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			res.Created++
+		} else {
+			res.Updated++
+		}
+	}
+	s.log(ctx, username, "vulns.import", fmt.Sprintf("%s|total=%d created=%d updated=%d", res.Format, res.Total, res.Created, res.Updated))
+	scan, err := s.Scan(ctx, 0, username, userID)
+	if err != nil {
+		return nil, err
+	}
+	res.Scan = scan
+	return res, nil
+}
+
+type matchKey struct {
+	vulnID int64
+	instID int64
+}
+
+type productKey struct {
+	vendor  string
+	product string
+}
+
+// Scan matches installed software against the catalog (all assets when assetID is 0),
+// raises vulnerability findings for new matches and resolves findings whose installations
+// were upgraded or removed.
+func (s *Service) Scan(ctx context.Context, assetID int64, username string, userID int64) (*ScanResult, error) {
+	installs, err := s.store.ListInstallations(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.store.ListProductAliases(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.store.ListMatches(ctx, store.VulnerabilityMatchFilter{AssetID: assetID})
+	if err != nil {
+		return nil, err
+	}
+
+	keysByProduct := map[int64][]productKey{}
+	for _, a := range aliases {
+		keysByProduct[a.ProductID] = append(keysByProduct[a.ProductID], productKey{vendor: NormalizeName(a.Vendor), product: NormalizeName(a.Product)})
+	}
+	// Only the catalog entries of installed products are loaded, so a rescan
+	// does not pull the whole feed into memory.
+	seen := map[string]bool{}
+	var productKeys []string
+	for _, inst := range installs {
+		keys := append([]productKey{{product: NormalizeName(inst.ProductName)}}, keysByProduct[inst.ProductID]...)
+		for _, key := range keys {
+			if key.product != "" && !seen[key.product] {
+				seen[key.product] = true
+				productKeys = append(productKeys, key.product)
+			}
+		}
+	}
+	affected, err := s.store.ListAffectedByProducts(ctx, productKeys)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := map[string][]store.VulnerabilityAffected{}
+	for _, a := range affected {
+		byProduct[a.ProductKey] = append(byProduct[a.ProductKey], a)
+	}
+
+	matched := map[matchKey]store.AssetSoftwareInstallation{}
+	for _, inst := range installs {
+		version := inst.VersionLabel
+		if version == "" {
+			version = inst.VersionText
+		}
+		keys := append([]productKey{{vendor: NormalizeName(inst.ProductVendor), product: NormalizeName(inst.ProductName)}}, keysByProduct[inst.ProductID]...)
+		for _, key := range keys {
+			for _, a := range byProduct[key.product] {
+				if !vendorMatches(key.vendor, NormalizeName(a.Vendor)) || !AffectsVersion(a, version) {
+					continue
+				}
+				inst.VersionText = version
+				matched[matchKey{vulnID: a.VulnerabilityID, instID: inst.ID}] = inst
+			}
+		}
+	}
+
+	res := &ScanResult{Installations: len(installs), Matches: len(matched)}
+	current := map[matchKey]store.VulnerabilityMatch{}
+	findingFor := map[string]int64{}
+	for _, m := range existing {
+		current[matchKey{vulnID: m.VulnerabilityID, instID: m.InstallationID}] = m
+		if m.FindingID != nil {
+			findingFor[assetVulnKey(m.AssetID, m.VulnerabilityID)] = *m.FindingID
+		}
+	}
+	vulnCache := map[int64]*store.Vulnerability{}
+	now := time.Now().UTC()
+	openFindings := map[int64]bool{}
+	for key, inst := range matched {
+		prev, known := current[key]
+		findingID, ok := findingFor[assetVulnKey(inst.AssetID, key.vulnID)]
+		if !ok {
+			vuln, err := s.vulnerability(ctx, vulnCache, key.vulnID)
+			if err != nil {
+				return nil, err
+			}
+			if vuln == nil {
+				continue
+			}
+			findingID, err = s.createFinding(ctx, vuln, inst, userID)
+			if err != nil {
+				return nil, err
+			}
+			findingFor[assetVulnKey(inst.AssetID, key.vulnID)] = findingID
+			res.FindingsCreated++
+		} else if known && prev.Status == store.VulnMatchClosed && !openFindings[findingID] {
+			if s.setFindingStatus(ctx, findingID, "resolved", "open", userID) {
+				res.Reopened++
+			}
+		}
+		openFindings[findingID] = true
+		fid := findingID
+		m := store.VulnerabilityMatch{
+			VulnerabilityID: key.vulnID,
+			InstallationID:  inst.ID,
+			AssetID:         inst.AssetID,
+			ProductID:       inst.ProductID,
+			VersionText:     inst.VersionText,
+			FindingID:       &fid,
+		}
+		if err := s.store.SaveMatch(ctx, &m); err != nil {
+			return nil, err
+		}
+	}
+	for key, m := range current {
+		if m.Status != store.VulnMatchOpen {
+			continue
+		}
+		if _, still := matched[key]; still {
+			continue
+		}
+		if err := s.store.CloseMatch(ctx, m.ID, now); err != nil {
+			return nil, err
+		}
+		res.Closed++
+		if m.FindingID != nil && !openFindings[*m.FindingID] {
+			s.setFindingStatus(ctx, *m.FindingID, "", "resolved", userID)
+		}
+	}
+	s.log(ctx, username, "vulns.scan", fmt.Sprintf("asset=%d installations=%d matches=%d created=%d reopened=%d closed=%d",
+		assetID, res.Installations, res.Matches, res.FindingsCreated, res.Reopened, res.Closed))
+	return res, nil
+}
+
+func (s *Service) vulnerability(ctx context.Context, cache map[int64]*store.Vulnerability, id int64) (*store.Vulnerability, error) {
+	if v, ok := cache[id]; ok {
+		return v, nil
+	}
+	v, err := s.store.GetVulnerability(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cache[id] = v
+	return v, nil
+}
+
+func (s *Service) createFinding(ctx context.Context, v *store.Vulnerability, inst store.AssetSoftwareInstallation, userID int64) (int64, error) {
+	f := &store.Finding{
+		Title:         truncate(fmt.Sprintf("%s: %s %s", v.ExternalID, inst.ProductName, inst.VersionText), 200),
+		DescriptionMD: findingDescription(v, inst),
+		Status:        "open",
+		Severity:      v.Severity,
+		FindingType:   FindingType,
+		Tags:          []string{v.ExternalID},
+	}
+	if userID > 0 {
+		f.CreatedBy = &userID
+		f.UpdatedBy = &userID
+	}
+	id, err := s.findings.CreateFinding(ctx, f)
+	if err != nil {
+		return 0, err
+	}
+	if s.links != nil {
+		source := strconv.FormatInt(id, 10)
+		_, _ = s.links.Add(ctx, &store.EntityLink{SourceType: "finding", SourceID: source, TargetType: "asset", TargetID: strconv.FormatInt(inst.AssetID, 10), RelationType: "affects"})
+		_, _ = s.links.Add(ctx, &store.EntityLink{SourceType: "finding", SourceID: source, TargetType: "software", TargetID: strconv.FormatInt(inst.ProductID, 10), RelationType: "affects"})
+	}
+	return id, nil
+}
+
+// setFindingStatus moves a vulnerability finding to the target status. When from is set the
+// finding must currently be in that status; otherwise only active findings are resolved, so
+// accepted risks and false positives stay untouched.
+func (s *Service) setFindingStatus(ctx context.Context, id int64, from, to string, userID int64) bool {
+	f, err := s.findings.GetFinding(ctx, id)
+	if err != nil || f == nil || f.DeletedAt != nil || f.FindingType != FindingType {
+		return false
+	}
+	if from != "" && f.Status != from {
+		return false
+	}
+	if from == "" && f.Status != "open" && f.Status != "in_progress" {
+		return false
+	}
+	f.Status = to
+	if userID > 0 {
+		f.UpdatedBy = &userID
+	}
+	return s.findings.UpdateFinding(ctx, f) == nil
+}
+
+func findingDescription(v *store.Vulnerability, inst store.AssetSoftwareInstallation) string {
+	var b strings.Builder
+	b.WriteString(v.Title)
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "- %s: %s\n", strings.ToUpper(v.Source), v.ExternalID)
+	if len(v.Aliases) > 0 {
+		fmt.Fprintf(&b, "- Aliases: %s\n", strings.Join(v.Aliases, ", "))
+	}
+	if v.CVSSScore > 0 {
+		fmt.Fprintf(&b, "- CVSS: %.1f %s\n", v.CVSSScore, v.CVSSVector)
+	}
+	fmt.Fprintf(&b, "- Asset: %s\n", inst.AssetName)
+	fmt.Fprintf(&b, "- Software: %s %s\n", strings.TrimSpace(inst.ProductVendor+" "+inst.ProductName), inst.VersionText)
+	if v.Description != "" && v.Description != v.Title {
+		b.WriteString("\n")
+		b.WriteString(v.Description)
+		b.WriteString("\n")
+	}
+	if len(v.References) > 0 {
+		b.WriteString("\n")
+		for _, ref := range v.References {
+			fmt.Fprintf(&b, "- %s\n", ref)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (s *Service) log(ctx context.Context, username, action, details string) {
+	if s.audits != nil {
+		_ = s.audits.Log(ctx, username, action, details)
+	}
+}
+
+// vendorMatches treats a missing vendor on either side as a wildcard.
+func vendorMatches(installed, feed string) bool {
+	return installed == "" || feed == "" || installed == feed
+}
+
+func assetVulnKey(assetID, vulnID int64) string {
+	return strconv.FormatInt(assetID, 10) + ":" + strconv.FormatInt(vulnID, 10)
+}
@@ -0,0 +1,139 @@
+package vulns
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"berkut-scc/core/store"
+)
+
+// CompareVersions orders version strings segment by segment: numeric segments compare as
+// numbers, others lexically. A trailing alphabetic segment marks a pre-release, so
+// "1.2.0-rc1" < "1.2.0" < "1.2.0.1". Returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	as := versionSegments(a)
+	bs := versionSegments(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		if i >= len(as) {
+			return tailOrder(bs[i], -1)
+		}
+		if i >= len(bs) {
+			return tailOrder(as[i], 1)
+		}
+		if c := compareSegment(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// NormalizeVersion extracts the comparable part of a free-form version label,
+// e.g. "v2.4.57 (Ubuntu)" -> "2.4.57".
+func NormalizeVersion(raw string) string {
+	v := strings.TrimSpace(raw)
+	if idx := strings.IndexAny(v, " \t("); idx > 0 {
+		v = v[:idx]
+	}
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') && v[1] >= '0' && v[1] <= '9' {
+		v = v[1:]
+	}
+	return strings.ToLower(v)
+}
+
+// AffectsVersion reports whether the installed version falls into the affected entry.
+// Entries without versions and bounds cover every version.
+func AffectsVersion(a store.VulnerabilityAffected, version string) bool {
+	version = NormalizeVersion(version)
+	if len(a.Versions) > 0 {
+		for _, v := range a.Versions {
+			if version != "" && CompareVersions(NormalizeVersion(v), version) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	bounded := a.VersionStartIncluding != "" || a.VersionStartExcluding != "" || a.VersionEndIncluding != "" || a.VersionEndExcluding != ""
+	if !bounded {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+	if v := NormalizeVersion(a.VersionStartIncluding); v != "" && CompareVersions(version, v) < 0 {
+		return false
+	}
+	if v := NormalizeVersion(a.VersionStartExcluding); v != "" && CompareVersions(version, v) <= 0 {
+		return false
+	}
+	if v := NormalizeVersion(a.VersionEndIncluding); v != "" && CompareVersions(version, v) > 0 {
+		return false
+	}
+	if v := NormalizeVersion(a.VersionEndExcluding); v != "" && CompareVersions(version, v) >= 0 {
+		return false
+	}
+	return true
+}
+
+func versionSegments(v string) []string {
+	v = NormalizeVersion(v)
+	var out []string
+	var cur strings.Builder
+	kind := 0 // 1 digit, 2 letter
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+		kind = 0
+	}
+	for _, r := range v {
+		switch {
+		case unicode.IsDigit(r):
+			if kind == 2 {
+				flush()
+			}
+			kind = 1
+			cur.WriteRune(r)
+		case unicode.IsLetter(r):
+			if kind == 1 {
+				flush()
+			}
+			kind = 2
+			cur.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+func compareSegment(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an < bn {
+			return -1
+		}
+		if an > bn {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return 1
+	case bErr == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// tailOrder decides the result when one version has extra segments: a numeric tail makes it
+// newer, an alphabetic one (rc, beta...) makes it older.
+func tailOrder(seg string, longerSign int) int {
+	if _, err := strconv.ParseUint(seg, 10, 64); err == nil {
+		return longerSign
+	}
+	return -longerSign
+}
@@ -32,6 +32,7 @@
     { value: 'assets', labelKey: 'nav.assets' },
     { value: 'software', labelKey: 'nav.software' },
     { value: 'findings', labelKey: 'nav.findings' },
+    { value: 'risks', labelKey: 'nav.risks' },
     { value: 'monitoring', labelKey: 'nav.monitoring' },
     { value: 'docs', labelKey: 'nav.docs' },
     { value: 'approvals', labelKey: 'nav.approvals' },
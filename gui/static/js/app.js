@@ -29,6 +29,9 @@
   await loadAppMeta();
   bindProfileShortcut();
   bindNotificationsUI();
+  if (typeof AppSearch !== 'undefined' && AppSearch.init) {
+    AppSearch.init((target) => openNotificationTarget({ target }));
+  }
   bindStepupUI();
 
   document.getElementById('logout-btn').addEventListener('click', async () => {
@@ -123,6 +126,7 @@
     if (base === 'assets') return 'assets';
     if (base === 'software') return 'software';
     if (base === 'findings') return 'findings';
+    if (base === 'risks') return 'risks';
     if (base === 'logs') return 'logs';
     return items.find(i => i.path === base)?.path || null;
   }
@@ -227,7 +231,7 @@
   function renderMenu(items, activePath) {
     const nav = document.getElementById('menu');
     nav.innerHTML = '';
-    const activeKey = ['assets', 'software', 'findings'].includes(activePath) ? 'registry' : activePath;
+    const activeKey = ['assets', 'software', 'findings', 'risks'].includes(activePath) ? 'registry' : activePath;
     sortMenuItems(items).forEach(item => {
       const link = document.createElement('a');
       link.className = 'sidebar-link';
@@ -319,7 +323,7 @@
   }
 
   function setActiveLink(path) {
-    const effective = ['assets', 'software', 'findings'].includes(path) ? 'registry' : path;
+    const effective = ['assets', 'software', 'findings', 'risks'].includes(path) ? 'registry' : path;
     document.querySelectorAll('.sidebar-link').forEach(link => {
       link.classList.toggle('active', link.dataset.path === effective);
     });
@@ -408,6 +412,9 @@
     if (path === 'findings' && typeof FindingsPage !== 'undefined') {
       FindingsPage.init();
     }
+    if (path === 'risks' && typeof RisksPage !== 'undefined') {
+      RisksPage.init();
+    }
     if (path === 'monitoring' && typeof MonitoringPage !== 'undefined') {
       MonitoringPage.init();
     }
@@ -593,6 +600,8 @@
         return BerkutI18n.t('software.subtitle');
       case 'findings':
         return BerkutI18n.t('findings.subtitle');
+      case 'risks':
+        return BerkutI18n.t('risks.subtitle');
       case 'monitoring':
         return BerkutI18n.t('monitoring.subtitle');
       case 'logs':
@@ -688,8 +697,48 @@
     const drop = document.getElementById('notifications-dropdown');
     const list = document.getElementById('notifications-list');
     const clearBtn = document.getElementById('notifications-clear-all');
+    const mentionsBtn = document.getElementById('notifications-mentions');
     const badge = document.getElementById('notifications-btn-badge');
     if (!btn || !drop || !list || !clearBtn || !badge) return;
+    let showMentions = false;
+
+    const renderMentions = async () => {
+      list.innerHTML = '';
+      let mentions = [];
+      try {
+        mentions = window.AppMentions ? await AppMentions.listMine() : [];
+      } catch (_) {
+        mentions = [];
+      }
+      if (!showMentions) return;
+      list.innerHTML = '';
+      if (!mentions.length) {
+        const empty = document.createElement('div');
+        empty.className = 'notifications-empty muted';
+        empty.textContent = BerkutI18n.t('app.notifications.mentionsEmpty');
+        list.appendChild(empty);
+        return;
+      }
+      mentions.forEach((m) => {
+        const row = document.createElement('div');
+        row.className = 'notification-row';
+        const head = document.createElement('div');
+        head.className = 'notification-head';
+        const title = document.createElement('strong');
+        title.textContent = m.title || '-';
+        head.appendChild(title);
+        const message = document.createElement('div');
+        message.className = 'notification-message muted';
+        message.textContent = m.author_name ? `${m.author_name}: ${m.excerpt || ''}` : (m.excerpt || '');
+        row.appendChild(head);
+        row.appendChild(message);
+        row.onclick = async () => {
+          await openNotificationTarget({ target: m.link });
+          drop.hidden = true;
+        };
+        list.appendChild(row);
+      });
+    };
 
     const render = () => {
       const total = (window.AppNotifications && AppNotifications.getTotal) ? AppNotifications.getTotal() : 0;
@@ -700,6 +749,11 @@
         badge.hidden = true;
         badge.textContent = '';
       }
+      if (mentionsBtn) {
+        mentionsBtn.classList.toggle('active', showMentions);
+        clearBtn.hidden = showMentions;
+      }
+      if (showMentions) return;
       const items = (window.AppNotifications && AppNotifications.getItems) ? AppNotifications.getItems() : [];
       list.innerHTML = '';
       if (!items.length) {
@@ -712,6 +766,7 @@
       items.forEach((item) => {
         const row = document.createElement('div');
         row.className = 'notification-row';
+        if (item.unread) row.classList.add('unread');
         const head = document.createElement('div');
         head.className = 'notification-head';
         const title = document.createElement('strong');
@@ -736,6 +791,9 @@
         row.appendChild(head);
         row.appendChild(message);
         row.onclick = async () => {
+          if (window.AppNotifications?.markRead) {
+            AppNotifications.markRead(item.key);
+          }
           await openNotificationTarget(item);
           drop.hidden = true;
         };
@@ -747,8 +805,19 @@
       e.preventDefault();
       e.stopPropagation();
       drop.hidden = !drop.hidden;
-      if (!drop.hidden) render();
+      if (!drop.hidden) {
+        render();
+        if (showMentions) renderMentions();
+      }
     };
+    if (mentionsBtn) {
+      mentionsBtn.onclick = (e) => {
+        e.preventDefault();
+        showMentions = !showMentions;
+        render();
+        if (showMentions) renderMentions();
+      };
+    }
     clearBtn.onclick = (e) => {
       e.preventDefault();
       if (window.AppNotifications?.clearAll) {
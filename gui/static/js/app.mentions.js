@@ -0,0 +1,188 @@
+﻿const AppMentions = (() => {
+  const SUGGEST_DEBOUNCE_MS = 200;
+  const MAX_SUGGESTIONS = 10;
+  // Mirrors core/notify mentionPattern: @name not glued to a word or an e-mail address.
+  const MENTION_RE = /(^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]{0,63})/gu;
+  const TYPING_RE = /(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_.\-]{1,64})$/u;
+  const bgOpts = { headers: { 'X-Berkut-Background': '1' } };
+
+  let popup = null;
+  let activeInput = null;
+  let candidates = [];
+  let selected = 0;
+  let timer = null;
+  let seq = 0;
+
+  function init() {
+    if (typeof document === 'undefined' || window.__appMentionsBound) return;
+    window.__appMentionsBound = true;
+    // Textareas opt in with data-mentions, including ones rendered after page load.
+    document.addEventListener('input', (e) => {
+      const el = e.target;
+      if (!el || !el.matches || !el.matches('textarea[data-mentions]')) return;
+      scheduleSuggest(el);
+    });
+    document.addEventListener('keydown', onKeyDown, true);
+    document.addEventListener('focusout', (e) => {
+      if (e.target === activeInput) setTimeout(hide, 150);
+    });
+  }
+
+  function scheduleSuggest(el) {
+    if (timer) clearTimeout(timer);
+    const query = currentQuery(el);
+    if (!query) {
+      hide();
+      return;
+    }
+    timer = setTimeout(() => suggest(el, query), SUGGEST_DEBOUNCE_MS);
+  }
+
+  function currentQuery(el) {
+    const before = String(el.value || '').slice(0, el.selectionStart || 0);
+    const m = before.match(TYPING_RE);
+    return m ? m[1] : '';
+  }
+
+  async function suggest(el, query) {
+    const my = ++seq;
+    let items = [];
+    try {
+      const res = await Api.get(`/api/notifications/mentions/users?q=${encodeURIComponent(query)}`, bgOpts);
+      items = Array.isArray(res.items) ? res.items.slice(0, MAX_SUGGESTIONS) : [];
+    } catch (_) {
+      items = [];
+    }
+    if (my !== seq || document.activeElement !== el) return;
+    candidates = items;
+    selected = 0;
+    activeInput = el;
+    if (!candidates.length) {
+      hide();
+      return;
+    }
+    show(el);
+  }
+
+  function show(el) {
+    if (!popup) {
+      popup = document.createElement('div');
+      popup.className = 'mention-suggest';
+      popup.addEventListener('mousedown', (e) => {
+        const row = e.target.closest('[data-index]');
+        if (!row) return;
+        e.preventDefault();
+        pick(Number(row.dataset.index));
+      });
+      document.body.appendChild(popup);
+    }
+    popup.innerHTML = '';
+    candidates.forEach((c, idx) => {
+      const row = document.createElement('div');
+      row.className = 'mention-suggest-item';
+      if (idx === selected) row.classList.add('active');
+      row.dataset.index = String(idx);
+      const name = document.createElement('strong');
+      name.textContent = `@${c.username}`;
+      row.appendChild(name);
+      if (c.full_name) {
+        const full = document.createElement('span');
+        full.className = 'muted';
+        full.textContent = c.full_name;
+        row.appendChild(full);
+      }
+      popup.appendChild(row);
+    });
+    const rect = el.getBoundingClientRect();
+    popup.style.left = `${rect.left + window.scrollX}px`;
+    popup.style.top = `${rect.bottom + window.scrollY + 2}px`;
+    popup.style.minWidth = `${Math.min(rect.width, 320)}px`;
+    popup.hidden = false;
+  }
+
+  function hide() {
+    candidates = [];
+    activeInput = null;
+    if (popup) popup.hidden = true;
+  }
+
+  function onKeyDown(e) {
+    if (!popup || popup.hidden || e.target !== activeInput || !candidates.length) return;
+    if (e.key === 'ArrowDown' || e.key === 'ArrowUp') {
+      e.preventDefault();
+      const step = e.key === 'ArrowDown' ? 1 : -1;
+      selected = (selected + step + candidates.length) % candidates.length;
+      show(activeInput);
+    } else if (e.key === 'Enter' || e.key === 'Tab') {
+      e.preventDefault();
+      e.stopPropagation();
+      pick(selected);
+    } else if (e.key === 'Escape') {
+      e.stopPropagation();
+      hide();
+    }
+  }
+
+  function pick(idx) {
+    const el = activeInput;
+    const c = candidates[idx];
+    if (!el || !c) return;
+    const pos = el.selectionStart || 0;
+    const before = el.value.slice(0, pos);
+    const query = currentQuery(el);
+    const start = before.length - query.length;
+    const insert = `${c.username} `;
+    el.value = `${el.value.slice(0, start)}${insert}${el.value.slice(pos)}`;
+    const caret = start + insert.length;
+    el.setSelectionRange(caret, caret);
+    el.dispatchEvent(new Event('change', { bubbles: true }));
+    hide();
+    el.focus();
+  }
+
+  function escapeText(s) {
+    return String(s || '').replace(/[&<>"']/g, (ch) => ({
+      '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;',
+    })[ch]);
+  }
+
+  // html returns escaped text with @mentions wrapped for highlighting.
+  function html(text) {
+    const raw = String(text || '');
+    let out = '';
+    let last = 0;
+    raw.replace(MENTION_RE, (match, lead, name, offset) => {
+      const clean = name.replace(/[.\-]+$/, '');
+      if (!clean) return match;
+      const at = offset + lead.length;
+      out += escapeText(raw.slice(last, at));
+      out += `<span class="mention">@${escapeText(clean)}</span>`;
+      last = at + 1 + clean.length;
+      return match;
+    });
+    out += escapeText(raw.slice(last));
+    return out;
+  }
+
+  function render(el, text) {
+    if (!el) return;
+    el.innerHTML = html(text);
+  }
+
+  async function listMine(limit = 50) {
+    const res = await Api.get(`/api/notifications/mentions?limit=${limit}`, bgOpts);
+    return Array.isArray(res.items) ? res.items : [];
+  }
+
+  return {
+    init,
+    html,
+    render,
+    listMine,
+  };
+})();
+
+if (typeof window !== 'undefined') {
+  window.AppMentions = AppMentions;
+  AppMentions.init();
+}
@@ -1,21 +1,32 @@
 ﻿const AppNotifications = (() => {
-  const POLL_MS = 15000;
   const REFRESH_DEBOUNCE_MS = 350;
-  const MAX_ITEMS_PER_SOURCE = 8;
+  const RECONNECT_MS = 30000;
+  const PAGE_SIZE = 50;
   const STORAGE_DISMISSED = 'app.notifications.dismissed.v1';
   const STORAGE_ERRORS = 'app.notifications.errors.v1';
   const MAX_STORED_ERRORS = 80;
 
+  // Sidebar badges count unread notifications of the events that belong to the section.
+  const EVENT_SECTIONS = {
+    'task.assigned': 'tasks',
+    'approval.requested': 'approvals',
+    'incident.assigned': 'incidents',
+    'monitor.down': 'monitoring',
+  };
+
   let menuPaths = new Set();
-  let timer = null;
   let debounceTimer = null;
+  let reconnectTimer = null;
   let inFlight = false;
   let queued = false;
   let dismissedKeys = new Set();
   let transientItems = [];
+  let serverItems = [];
+  let unread = 0;
+  let lastEventID = 0;
+  let source = null;
 
   const counts = {
-    docs: 0,
     approvals: 0,
     incidents: 0,
     tasks: 0,
@@ -32,17 +43,14 @@
     emitChanged();
     onMenuRendered();
     scheduleRefresh(0);
-    if (timer) clearInterval(timer);
-    timer = setInterval(() => scheduleRefresh(0), POLL_MS);
+    connect();
   }
 
   function bindSignals() {
     if (window.__appNotificationsBound) return;
     window.__appNotificationsBound = true;
-    window.addEventListener('app:data-changed', () => scheduleRefresh(REFRESH_DEBOUNCE_MS));
-    window.addEventListener('focus', () => scheduleRefresh(0));
     document.addEventListener('visibilitychange', () => {
-      if (document.visibilityState === 'visible') scheduleRefresh(0);
+      if (document.visibilityState === 'visible' && !source) connect();
     });
     window.addEventListener('app:toast-notification', (e) => {
       const item = e && e.detail ? e.detail : null;
@@ -51,11 +59,52 @@
       if (dismissedKeys.has(key)) return;
       transientItems = sortItems([item, ...transientItems]).slice(0, 24);
       persistErrorItems();
-      items = dedupeItems(sortItems([...items, item]));
-      emitChanged();
+      rebuild();
     });
   }
 
+  function connect() {
+    if (typeof window === 'undefined' || typeof window.EventSource === 'undefined') return;
+    if (reconnectTimer) {
+      clearTimeout(reconnectTimer);
+      reconnectTimer = null;
+    }
+    if (source) source.close();
+    const url = lastEventID ? `/api/notifications/stream?after=${lastEventID}` : '/api/notifications/stream';
+    source = new EventSource(url, { withCredentials: true });
+    source.addEventListener('notification', (e) => {
+      const ev = parseEvent(e);
+      if (!ev || !ev.notification) return;
+      const n = ev.notification;
+      lastEventID = Math.max(lastEventID, Number(n.id) || 0);
+      serverItems = [n, ...serverItems.filter((it) => it.id !== n.id)].slice(0, PAGE_SIZE);
+      unread = Number(ev.unread || 0);
+      rebuild();
+    });
+    source.addEventListener('sync', (e) => {
+      const ev = parseEvent(e);
+      if (ev && Number(ev.unread || 0) !== unread) scheduleRefresh(REFRESH_DEBOUNCE_MS);
+    });
+    source.onerror = () => {
+      // The browser retries dropped connections itself; a closed stream (expired session,
+      // server restart) is reopened after a pause and the inbox is reloaded.
+      if (!source || source.readyState !== EventSource.CLOSED) return;
+      source = null;
+      reconnectTimer = setTimeout(() => {
+        scheduleRefresh(0);
+        connect();
+      }, RECONNECT_MS);
+    };
+  }
+
+  function parseEvent(e) {
+    try {
+      return JSON.parse(e.data);
+    } catch (_) {
+      return null;
+    }
+  }
+
   function scheduleRefresh(delayMs) {
     if (debounceTimer) clearTimeout(debounceTimer);
     debounceTimer = setTimeout(() => {
@@ -70,19 +119,15 @@
     }
     inFlight = true;
     try {
-      const list = [];
-      const jobs = [];
-      if (menuPaths.has('docs')) jobs.push(loadDocsCount(list));
-      if (menuPaths.has('approvals')) jobs.push(loadApprovalsCount(list));
-      if (menuPaths.has('incidents')) jobs.push(loadIncidentsCount(list));
-      if (menuPaths.has('tasks')) jobs.push(loadTasksCount(list));
-      if (menuPaths.has('monitoring')) jobs.push(loadMonitoringDownCount(list));
-      await Promise.all(jobs);
-      const merged = dedupeItems([...list, ...transientItems]);
-      items = sortItems(merged).filter((it) => !dismissedKeys.has(it.key));
-      transientItems = transientItems.filter((it) => !dismissedKeys.has(it.key));
-      onMenuRendered();
-      emitChanged();
+      const res = await Api.get(`/api/notifications?limit=${PAGE_SIZE}`, bgOpts);
+      serverItems = Array.isArray(res.items) ? res.items : [];
+      unread = Number(res.unread || 0);
+      serverItems.forEach((n) => {
+        lastEventID = Math.max(lastEventID, Number(n.id) || 0);
+      });
+      rebuild();
+    } catch (_) {
+      // keep the last known inbox
     } finally {
       inFlight = false;
       if (queued) {
@@ -92,153 +137,50 @@
     }
   }
 
-  async function loadDocsCount(list) {
-    try {
-      const res = await Api.get('/api/docs?mine=1&status_in=draft,review,returned', bgOpts);
-      const docs = Array.isArray(res.items) ? res.items : [];
-      const mine = docs.filter((d) => {
-        const st = normalizeStatus(d && d.status);
-        return st === 'draft' || st === 'review' || st === 'returned';
-      });
-      counts.docs = mine.length;
-      mine.slice(0, MAX_ITEMS_PER_SOURCE).forEach((doc) => {
-        const title = String(doc.title || `#${doc.id || ''}`).trim();
-        const status = normalizeStatus(doc.status);
-        list.push({
-          key: `docs:${doc.id}:${status}`,
-          section: 'docs',
-          path: '/docs',
-          target: '/docs',
-          title,
-          message: statusText(status),
-          ts: new Date(doc.updated_at || doc.created_at || 0).getTime() || 0,
-        });
-      });
-    } catch (_) {
-      counts.docs = 0;
-    }
-  }
-
-  async function loadApprovalsCount(list) {
-    try {
-      const res = await Api.get('/api/approvals?status=review', bgOpts);
-      const workflow = Array.isArray(res.items) ? res.items : [];
-      const exportItems = Array.isArray(res.export_items) ? res.export_items : [];
-      const exportReview = exportItems.filter((x) => normalizeStatus(x && x.status) === 'review');
-      counts.approvals = workflow.length + exportReview.length;
-
-      workflow.slice(0, MAX_ITEMS_PER_SOURCE).forEach((a) => {
-        list.push({
-          key: `approval:w:${a.id}`,
-          section: 'approvals',
-          path: '/approvals',
-          target: '/approvals',
-          title: i18n('nav.approvals'),
-          message: `#${a.doc_id || ''}`,
-          ts: new Date(a.updated_at || a.created_at || 0).getTime() || 0,
-        });
-      });
-      exportReview.slice(0, MAX_ITEMS_PER_SOURCE).forEach((a) => {
-        list.push({
-          key: `approval:e:${a.id}`,
-          section: 'approvals',
-          path: '/approvals',
-          target: '/approvals',
-          title: i18n('docs.export.approvalLabel'),
-          message: `#${a.doc_id || ''}`,
-          ts: new Date(a.updated_at || a.created_at || 0).getTime() || 0,
-        });
-      });
-    } catch (_) {
-      counts.approvals = 0;
-    }
-  }
-
-  async function loadIncidentsCount(list) {
-    try {
-      const res = await Api.get('/api/incidents?limit=200', bgOpts);
-      const all = Array.isArray(res.items) ? res.items : [];
-      const active = all.filter((it) => isActiveIncidentStatus(it && it.status));
-      counts.incidents = active.length;
-      if (counts.incidents > 0) {
-        list.push({
-          key: 'incidents:open',
-          section: 'incidents',
-          path: '/incidents',
-          target: '/incidents',
-          title: i18n('nav.incidents'),
-          message: `${i18n('incidents.status.open')}: ${counts.incidents}`,
-          ts: Date.now(),
-        });
-      }
-      active.slice(0, MAX_ITEMS_PER_SOURCE).forEach((it) => {
-        const id = Number(it && it.id);
-        if (!id) return;
-        const st = normalizeStatus(it.status);
-        list.push({
-          key: `incident:${id}`,
-          section: 'incidents',
-          path: '/incidents',
-          target: `/incidents?incident=${encodeURIComponent(id)}`,
-          title: String(it.title || `#${id}`),
-          message: i18n(`incidents.status.${st}`) || st,
-          ts: new Date(it.updated_at || it.created_at || 0).getTime() || 0,
-        });
-      });
-    } catch (_) {
-      counts.incidents = 0;
-    }
+  function rebuild() {
+    Object.keys(counts).forEach((k) => { counts[k] = 0; });
+    serverItems.forEach((n) => {
+      const section = EVENT_SECTIONS[n.event_type];
+      if (section && !n.read_at) counts[section] += 1;
+    });
+    const list = serverItems.map(toItem);
+    items = sortItems(dedupeItems([...list, ...transientItems])).filter((it) => !dismissedKeys.has(it.key));
+    onMenuRendered();
+    emitChanged();
   }
 
-  function isActiveTaskStatus(status) {
-    const s = normalizeStatus(status);
-    if (!s) return true;
-    const doneSet = new Set(['done', 'completed', 'closed', 'ready', 'готово', 'завершено', 'закрыто']);
-    const backlogSet = new Set(['backlog', 'беклог', 'бэклог']);
-    return !doneSet.has(s) && !backlogSet.has(s);
+  function toItem(n) {
+    const label = eventLabel(n.event_type);
+    const body = localizedText(n.body_key, n.params, n.body).trim();
+    const title = localizedText(n.title_key, n.params, n.title).trim();
+    return {
+      key: `n:${n.id}`,
+      id: n.id,
+      section: EVENT_SECTIONS[n.event_type] || 'notifications',
+      path: String(n.link || '').replace(/^\/+/, '').split(/[/?]/)[0],
+      target: n.link || '',
+      title: title || label,
+      message: body ? `${label}: ${body}` : label,
+      unread: !n.read_at,
+      ts: new Date(n.created_at || 0).getTime() || 0,
+    };
   }
 
-  async function loadTasksCount(list) {
-    try {
-      const res = await Api.get('/api/tasks?mine=1', bgOpts);
-      const tasks = Array.isArray(res.items) ? res.items : [];
-      const active = tasks.filter((t) => !t.is_archived && !t.closed_at && isActiveTaskStatus(t.status));
-      counts.tasks = active.length;
-      active.slice(0, MAX_ITEMS_PER_SOURCE).forEach((t) => {
-        list.push({
-          key: `task:${t.id}`,
-          section: 'tasks',
-          path: '/tasks',
-          target: `/tasks/task/${encodeURIComponent(t.id)}`,
-          title: String(t.title || `#${t.id || ''}`),
-          message: i18n('tasks.subtitle'),
-          ts: new Date(t.updated_at || t.created_at || 0).getTime() || 0,
-        });
-      });
-    } catch (_) {
-      counts.tasks = 0;
-    }
+  // Server notifications may carry i18n keys with {param} placeholders; the stored text is the
+  // English fallback for keys this GUI does not know.
+  function localizedText(key, params, fallback) {
+    const k = String(key || '').trim();
+    if (!k) return String(fallback || '');
+    const text = i18n(k);
+    if (!text || text === k) return String(fallback || '');
+    const values = params || {};
+    return text.replace(/\{(\w+)\}/g, (m, name) => (Object.prototype.hasOwnProperty.call(values, name) ? String(values[name] ?? '') : m));
   }
 
-  async function loadMonitoringDownCount(list) {
-    try {
-      const res = await Api.get('/api/monitoring/monitors?status=down', bgOpts);
-      const down = Array.isArray(res.items) ? res.items : [];
-      counts.monitoring = down.length;
-      down.slice(0, MAX_ITEMS_PER_SOURCE).forEach((m) => {
-        list.push({
-          key: `monitor:${m.id}`,
-          section: 'monitoring',
-          path: '/monitoring',
-          target: '/monitoring',
-          title: String(m.name || `#${m.id || ''}`),
-          message: i18n('monitoring.status.down'),
-          ts: new Date(m.updated_at || m.last_check_at || 0).getTime() || 0,
-        });
-      });
-    } catch (_) {
-      counts.monitoring = 0;
-    }
+  function eventLabel(type) {
+    const key = `app.notifications.event.${type}`;
+    const label = i18n(key);
+    return label && label !== key ? label : String(type || '');
   }
 
   function sortItems(list) {
@@ -247,30 +189,19 @@
 
   function dedupeItems(list) {
     const seen = new Set();
-    const seenSemantic = new Set();
     return (list || []).filter((it) => {
       if (!it) return false;
       const key = String(it.key || '').trim();
-      const semantic = [
-        String(it.section || ''),
-        String(it.target || ''),
-        String(it.message || '').trim().toLowerCase(),
-      ].join('|');
-      const digest = key || semantic;
-      if (!digest || seen.has(digest)) return false;
-      if (semantic && seenSemantic.has(semantic)) return false;
-      seen.add(digest);
-      if (semantic) seenSemantic.add(semantic);
+      if (!key || seen.has(key)) return false;
+      seen.add(key);
       return true;
     });
   }
 
   function onMenuRendered() {
-    renderBadge('docs', counts.docs);
-    renderBadge('approvals', counts.approvals);
-    renderBadge('incidents', counts.incidents);
-    renderBadge('tasks', counts.tasks);
-    renderBadge('monitoring', counts.monitoring);
+    Object.keys(counts).forEach((path) => {
+      renderBadge(path, menuPaths.size && !menuPaths.has(path) ? 0 : counts[path]);
+    });
   }
 
   function renderBadge(path, value) {
@@ -297,28 +228,56 @@
   }
 
   function getTotal() {
-    // Bell badge must match visible center items to avoid phantom counters.
-    return items.length;
+    // Read notifications stay in the list until dismissed but do not count towards the bell.
+    return unread + items.filter((it) => it.section === 'errors').length;
+  }
+
+  function serverID(key) {
+    const k = String(key || '');
+    return k.startsWith('n:') ? Number(k.slice(2)) || 0 : 0;
+  }
+
+  function markRead(key) {
+    const id = serverID(key);
+    const n = serverItems.find((it) => it.id === id);
+    if (!n || n.read_at) return;
+    n.read_at = new Date().toISOString();
+    unread = Math.max(0, unread - 1);
+    rebuild();
+    Api.post('/api/notifications/read', { ids: [id] }, bgOpts)
+      .then((res) => { unread = Number(res.unread || 0); emitChanged(); })
+      .catch(() => {});
   }
 
   function dismissItem(key) {
     const k = String(key || '').trim();
     if (!k) return;
-    dismissedKeys.add(k);
-    items = items.filter((it) => it.key !== k);
-    transientItems = transientItems.filter((it) => it.key !== k);
-    saveDismissed();
-    persistErrorItems();
-    emitChanged();
+    const id = serverID(k);
+    if (id) {
+      const n = serverItems.find((it) => it.id === id);
+      if (n && !n.read_at) unread = Math.max(0, unread - 1);
+      serverItems = serverItems.filter((it) => it.id !== id);
+      Api.post('/api/notifications/dismiss', { ids: [id] }, bgOpts)
+        .then((res) => { unread = Number(res.unread || 0); emitChanged(); })
+        .catch(() => scheduleRefresh(0));
+    } else {
+      dismissedKeys.add(k);
+      transientItems = transientItems.filter((it) => it.key !== k);
+      saveDismissed();
+      persistErrorItems();
+    }
+    rebuild();
   }
 
   function clearAll() {
-    items.forEach((it) => dismissedKeys.add(it.key));
-    items = [];
+    transientItems.forEach((it) => dismissedKeys.add(it.key));
     transientItems = [];
+    serverItems = [];
+    unread = 0;
     saveDismissed();
     persistErrorItems();
-    emitChanged();
+    rebuild();
+    Api.post('/api/notifications/dismiss', { ids: [] }, bgOpts).catch(() => scheduleRefresh(0));
   }
 
   function emitChanged() {
@@ -332,23 +291,6 @@
     }));
   }
 
-  function normalizeStatus(status) {
-    return String(status || '').trim().toLowerCase();
-  }
-
-  function isActiveIncidentStatus(status) {
-    const s = normalizeStatus(status);
-    if (!s) return true;
-    return !new Set(['closed', 'resolved']).has(s);
-  }
-
-  function statusText(status) {
-    if (status === 'draft') return i18n('docs.status.draft');
-    if (status === 'review') return i18n('docs.status.review');
-    if (status === 'returned') return i18n('docs.status.returned');
-    return status || '-';
-  }
-
   function i18n(key) {
     if (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) {
       return BerkutI18n.t(key);
@@ -357,7 +299,7 @@
   }
 
   function loadState() {
-    dismissedKeys = new Set(readJSON(STORAGE_DISMISSED, []).map((x) => String(x || '').trim()).filter(Boolean));
+    dismissedKeys = new Set(readJSON(STORAGE_DISMISSED, []).map((x) => String(x || '').trim()).filter((x) => x.startsWith('toast:')));
     const rawErrors = readJSON(STORAGE_ERRORS, []);
     transientItems = (Array.isArray(rawErrors) ? rawErrors : [])
       .map((it) => normalizeStoredError(it))
@@ -432,6 +374,7 @@
     refresh: () => scheduleRefresh(0),
     getItems,
     getTotal,
+    markRead,
     dismissItem,
     clearAll,
   };
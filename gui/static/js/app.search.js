@@ -0,0 +1,130 @@
+const AppSearch = (() => {
+  const DEBOUNCE_MS = 300;
+  const MIN_QUERY = 2;
+  const TYPE_ORDER = ['doc', 'incident', 'task', 'finding', 'asset', 'software', 'control', 'monitor'];
+
+  let openTarget = null;
+  let timer = null;
+  let seq = 0;
+  let els = {};
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHtml(str) {
+    return String(str == null ? '' : str)
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  function targetFor(item) {
+    const id = encodeURIComponent(item.id);
+    switch (item.entity_type) {
+      case 'doc': return `/docs/${id}`;
+      case 'incident': return `/incidents?incident=${id}`;
+      case 'task': return `/tasks/task/${id}`;
+      case 'finding': return `/registry/findings?finding=${id}`;
+      case 'asset': return `/assets?asset=${id}`;
+      case 'software': return `/software?software=${id}`;
+      case 'control': return `/registry/controls?control=${id}`;
+      case 'monitor': return `/monitoring?monitor=${id}`;
+      default: return '';
+    }
+  }
+
+  // init binds the sidebar search box; open navigates inside the app to a result link.
+  function init(open) {
+    openTarget = open;
+    els = {
+      box: document.getElementById('global-search'),
+      input: document.getElementById('global-search-input'),
+      results: document.getElementById('global-search-results')
+    };
+    if (!els.box || !els.input || !els.results) return;
+    els.input.addEventListener('input', () => schedule());
+    els.input.addEventListener('focus', () => {
+      if (els.results.innerHTML) els.results.hidden = false;
+    });
+    els.input.addEventListener('keydown', (e) => {
+      if (e.key === 'Escape') {
+        els.results.hidden = true;
+        els.input.blur();
+      } else if (e.key === 'Enter') {
+        e.preventDefault();
+        schedule(0);
+      }
+    });
+    document.addEventListener('click', (e) => {
+      if (!els.results.hidden && !els.box.contains(e.target)) els.results.hidden = true;
+    });
+  }
+
+  function schedule(delay = DEBOUNCE_MS) {
+    if (timer) clearTimeout(timer);
+    timer = setTimeout(run, delay);
+  }
+
+  async function run() {
+    const q = els.input.value.trim();
+    const current = ++seq;
+    if (q.length < MIN_QUERY) {
+      els.results.innerHTML = '';
+      els.results.hidden = true;
+      return;
+    }
+    try {
+      const res = await Api.get(`/api/search?q=${encodeURIComponent(q)}`);
+      if (current !== seq) return;
+      render(Array.isArray(res.items) ? res.items : []);
+    } catch (err) {
+      if (current !== seq) return;
+      const raw = (err && err.message ? err.message : '').trim();
+      els.results.innerHTML = `<div class="global-search-empty muted">${escapeHtml(raw ? t(raw) : t('common.error'))}</div>`;
+      els.results.hidden = false;
+    }
+  }
+
+  function render(items) {
+    if (!items.length) {
+      els.results.innerHTML = `<div class="global-search-empty muted">${escapeHtml(t('search.empty'))}</div>`;
+      els.results.hidden = false;
+      return;
+    }
+    const groups = {};
+    items.forEach(item => {
+      (groups[item.entity_type] = groups[item.entity_type] || []).push(item);
+    });
+    els.results.innerHTML = TYPE_ORDER.filter(type => groups[type]).map(type => `
+      <div class="global-search-group">
+        <div class="global-search-type">${escapeHtml(t(`search.type.${type}`))}</div>
+        ${groups[type].map(item => `
+          <div class="global-search-row" data-target="${escapeHtml(targetFor(item))}">
+            <div class="global-search-title">${item.ref ? `<span class="muted">${escapeHtml(item.ref)}</span> ` : ''}${escapeHtml(item.title || `#${item.id}`)}</div>
+            ${item.snippet ? `<div class="global-search-snippet muted">${escapeHtml(item.snippet)}</div>` : ''}
+          </div>`).join('')}
+      </div>`).join('');
+    els.results.querySelectorAll('.global-search-row').forEach(row => {
+      row.addEventListener('click', async () => {
+        const target = row.getAttribute('data-target');
+        if (!target) return;
+        els.results.hidden = true;
+        if (typeof openTarget === 'function') {
+          await openTarget(target);
+        } else {
+          window.location.href = target;
+        }
+      });
+    });
+    els.results.hidden = false;
+  }
+
+  return { init };
+})();
+
+if (typeof window !== 'undefined') {
+  window.AppSearch = AppSearch;
+}
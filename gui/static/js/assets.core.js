@@ -4,6 +4,7 @@ const AssetsPage = (() => {
     canManage: false,
     initialized: false,
     tagsBound: false,
+    searchBar: null,
   };
 
   function t(key) {
@@ -143,6 +144,8 @@ const AssetsPage = (() => {
     if (f.status) url.searchParams.set('status', f.status);
     if (f.tag) url.searchParams.set('tag', f.tag);
     if (f.includeDeleted) url.searchParams.set('include_deleted', '1');
+    const query = state.searchBar ? state.searchBar.query() : '';
+    if (query) url.searchParams.set('query', query);
     url.searchParams.set('limit', '200');
     return url.pathname + url.search;
   }
@@ -154,7 +157,14 @@ const AssetsPage = (() => {
     if (!tbody) return;
     tbody.innerHTML = '';
 
-    const data = await Api.get(buildListURL());
+    let data = null;
+    try {
+      data = await Api.get(buildListURL());
+      if (state.searchBar) state.searchBar.showError(null);
+    } catch (err) {
+      if (!state.searchBar) throw err;
+      state.searchBar.showError(err);
+    }
     state.items = (data && Array.isArray(data.items)) ? data.items : [];
 
     if (state.items.length === 0) {
@@ -277,10 +287,19 @@ const AssetsPage = (() => {
     });
     const saveBtn = document.getElementById('asset-save');
     if (saveBtn) saveBtn.hidden = readOnly || !state.canManage;
+    if (typeof CustomFieldsForm !== 'undefined') {
+      CustomFieldsForm.render(document.getElementById('asset-custom-fields'), 'asset', item ? item.custom_fields : null, readOnly || !state.canManage);
+    }
 
     if (typeof AssetsSoftware !== 'undefined' && AssetsSoftware.onAssetModalOpened) {
       AssetsSoftware.onAssetModalOpened({ asset: item, readOnly: !!readOnly, canManage: !!state.canManage });
     }
+    if (typeof AssetsRelations !== 'undefined' && AssetsRelations.onAssetModalOpened) {
+      AssetsRelations.onAssetModalOpened({ asset: item, readOnly: !!readOnly, canManage: !!state.canManage });
+    }
+    if (typeof AssetsHistory !== 'undefined' && AssetsHistory.onAssetModalOpened) {
+      AssetsHistory.onAssetModalOpened({ asset: item });
+    }
 
     openModal('#asset-modal');
   }
@@ -309,6 +328,9 @@ const AssetsPage = (() => {
       status: (document.getElementById('asset-status')?.value || '').toString(),
       tags: selectedValues('asset-tags'),
     };
+    if (typeof CustomFieldsForm !== 'undefined') {
+      payload.custom_fields = CustomFieldsForm.collect(document.getElementById('asset-custom-fields'));
+    }
     try {
       if (idRaw) {
         await Api.put(`/api/assets/${encodeURIComponent(idRaw)}`, payload);
@@ -373,9 +395,15 @@ const AssetsPage = (() => {
     if (typeof RegistryReports !== 'undefined' && RegistryReports.bind) {
       RegistryReports.bind('assets-create-report', 'assets');
     }
+    if (typeof RegistryImport !== 'undefined' && RegistryImport.bind) {
+      RegistryImport.bind('assets-import', 'assets', refresh);
+    }
     const includeDeletedField = document.getElementById('assets-include-deleted-field');
     if (includeDeletedField) includeDeletedField.hidden = !state.canManage;
     wireEvents();
+    if (typeof SavedSearchBar !== 'undefined') {
+      state.searchBar = SavedSearchBar.mount(document.getElementById('assets-saved-search'), 'asset', refresh);
+    }
     if (!state.tagsBound) {
       state.tagsBound = true;
       document.addEventListener('tags:changed', () => setAssetTags(selectedValues('asset-tags')));
@@ -385,7 +413,9 @@ const AssetsPage = (() => {
     const pending = window.__pendingAssetOpen;
     if (pending) {
       window.__pendingAssetOpen = null;
-      await openAsset(pending, 'view');
+      const pendingId = typeof pending === 'object' ? pending.id : pending;
+      await openAsset(pendingId, 'view');
+      if (typeof pending === 'object' && pending.asOf) showAsOf(pending.asOf);
       return;
     }
     try {
@@ -393,12 +423,20 @@ const AssetsPage = (() => {
       const assetId = parseInt(url.searchParams.get('asset') || '', 10);
       if (assetId) {
         await openAsset(assetId, 'view');
+        const asOf = url.searchParams.get('as_of') || '';
+        if (asOf) showAsOf(asOf);
       }
     } catch (_) {
       // ignore
     }
   }
 
+  function showAsOf(value) {
+    if (typeof AssetsHistory !== 'undefined' && AssetsHistory.showAsOf) {
+      AssetsHistory.showAsOf(value);
+    }
+  }
+
   async function openAsset(assetId, mode) {
     const id = parseInt(assetId, 10);
     if (!id) return;
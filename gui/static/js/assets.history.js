@@ -0,0 +1,227 @@
+const AssetsHistory = (() => {
+  const PAGE_SIZE = 50;
+  const state = {
+    assetId: 0,
+    items: [],
+    offset: 0,
+    bound: false,
+  };
+
+  const FIELD_LABELS = {
+    name: 'assets.field.name',
+    type: 'assets.field.type',
+    description: 'assets.field.description',
+    commissioned_at: 'assets.field.commissionedAt',
+    ip_addresses: 'assets.field.ip',
+    criticality: 'assets.field.criticality',
+    owner: 'assets.field.owner',
+    administrator: 'assets.field.admin',
+    env: 'assets.field.env',
+    status: 'assets.field.status',
+    tags: 'assets.field.tags',
+    deleted_at: 'assets.history.field.deletedAt',
+    product_id: 'assets.software.field.product',
+    version_id: 'assets.software.field.version',
+    version_text: 'assets.software.field.versionText',
+    installed_at: 'assets.software.field.installedAt',
+    source: 'assets.software.field.source',
+    notes: 'assets.software.field.notes',
+  };
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHTML(str) {
+    return (str || '').toString().replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+  }
+
+  function localizeError(err, fallbackKey) {
+    const raw = String((err && err.message) || '').trim();
+    if (raw && typeof BerkutI18n !== 'undefined' && typeof BerkutI18n.t === 'function') {
+      const translated = BerkutI18n.t(raw);
+      if (translated && translated !== raw) return translated;
+    }
+    if (fallbackKey && typeof BerkutI18n !== 'undefined' && typeof BerkutI18n.t === 'function') {
+      return BerkutI18n.t(fallbackKey);
+    }
+    return raw || fallbackKey || 'Error';
+  }
+
+  function setAlert(el, msg) {
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+  }
+
+  function formatDateTime(raw) {
+    if (!raw) return '-';
+    if (typeof AppTime !== 'undefined' && AppTime.formatDateTime) return AppTime.formatDateTime(raw);
+    return raw;
+  }
+
+  function labelOf(prefix, v, fallback) {
+    const k = `${prefix}.${(v || fallback || '').toString().toLowerCase()}`;
+    const msg = t(k);
+    return msg && msg !== k ? msg : (v || fallback || '');
+  }
+
+  function formatValue(key, v) {
+    if (v === null || v === undefined || v === '') return '-';
+    if (Array.isArray(v)) return v.length ? v.join(', ') : '-';
+    if (key.endsWith('_at')) return formatDateTime(v);
+    if (key === 'criticality') return labelOf('assets.criticality', v);
+    if (key === 'type') return labelOf('assets.type', v);
+    if (key === 'env') return labelOf('assets.env', v);
+    if (key === 'status') return labelOf('assets.status', v);
+    return String(v);
+  }
+
+  function renderChanges(item) {
+    const keys = Object.keys(item.changes || {}).sort();
+    return keys.map(key => {
+      const change = item.changes[key] || {};
+      const label = FIELD_LABELS[key] ? t(FIELD_LABELS[key]) : key;
+      return `<div><strong>${escapeHTML(label)}:</strong> ${escapeHTML(formatValue(key, change.old))} → ${escapeHTML(formatValue(key, change.new))}</div>`;
+    }).join('');
+  }
+
+  function describeAction(item) {
+    const action = labelOf('assets.history.action', item.action);
+    if (item.entity_type !== 'software') return action;
+    return `${t('assets.history.entity.software')}: ${item.product_name || `#${item.entity_id}`} · ${action}`;
+  }
+
+  async function load(append) {
+    const alert = document.getElementById('asset-history-alert');
+    setAlert(alert, '');
+    if (!state.assetId) return;
+    if (!append) {
+      state.items = [];
+      state.offset = 0;
+    }
+    let res;
+    try {
+      res = await Api.get(`/api/assets/${state.assetId}/history?limit=${PAGE_SIZE}&offset=${state.offset}`);
+    } catch (err) {
+      render(false);
+      setAlert(alert, localizeError(err, 'common.error'));
+      return;
+    }
+    const items = Array.isArray(res?.items) ? res.items : [];
+    state.items = state.items.concat(items);
+    state.offset += PAGE_SIZE;
+    render(items.length === PAGE_SIZE);
+  }
+
+  function render(hasMore) {
+    const tbody = document.querySelector('#asset-history-table tbody');
+    const empty = document.getElementById('asset-history-empty');
+    const more = document.getElementById('asset-history-more');
+    if (!tbody) return;
+    tbody.innerHTML = '';
+    if (empty) empty.hidden = state.items.length > 0;
+    if (more) more.hidden = !hasMore;
+    state.items.forEach(item => {
+      const tr = document.createElement('tr');
+      tr.innerHTML = `
+        <td>${escapeHTML(formatDateTime(item.created_at))}</td>
+        <td>${escapeHTML(item.actor_name || '-')}</td>
+        <td>${escapeHTML(describeAction(item))}</td>
+        <td>${renderChanges(item)}</td>
+      `;
+      tbody.appendChild(tr);
+    });
+  }
+
+  function toLocalInput(raw) {
+    const d = new Date(raw);
+    if (Number.isNaN(d.getTime())) return '';
+    const pad = (n) => String(n).padStart(2, '0');
+    return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}T${pad(d.getHours())}:${pad(d.getMinutes())}`;
+  }
+
+  async function loadSnapshot() {
+    const box = document.getElementById('asset-history-snapshot');
+    const alert = document.getElementById('asset-history-alert');
+    const raw = document.getElementById('asset-history-at')?.value || '';
+    setAlert(alert, '');
+    if (!box || !state.assetId) return;
+    box.hidden = true;
+    box.innerHTML = '';
+    const at = new Date(raw);
+    if (!raw || Number.isNaN(at.getTime())) {
+      setAlert(alert, t('assets.history.badTime'));
+      return;
+    }
+    let res;
+    try {
+      res = await Api.get(`/api/assets/${state.assetId}/as-of?at=${encodeURIComponent(at.toISOString())}`);
+    } catch (err) {
+      setAlert(alert, localizeError(err, 'common.error'));
+      return;
+    }
+    box.innerHTML = renderSnapshot(res);
+    box.hidden = false;
+  }
+
+  function renderSnapshot(res) {
+    const asset = res?.asset || {};
+    const software = Array.isArray(res?.software) ? res.software : [];
+    const fields = ['name', 'type', 'criticality', 'env', 'status', 'owner', 'administrator', 'ip_addresses', 'tags', 'commissioned_at', 'description'];
+    const rows = fields.map(key => `
+      <tr>
+        <th>${escapeHTML(t(FIELD_LABELS[key]))}</th>
+        <td>${escapeHTML(formatValue(key, asset[key]))}</td>
+      </tr>`).join('');
+    const softwareItems = software.map(s => `<li>${escapeHTML(s.product_name)} ${escapeHTML(s.version_label || s.version_text || '')}</li>`).join('');
+    return `
+      <h5>${escapeHTML(t('assets.history.snapshotTitle'))} ${escapeHTML(formatDateTime(res?.at))}</h5>
+      ${asset.deleted_at ? `<div class="muted">${escapeHTML(t('assets.history.archivedAtTime'))}</div>` : ''}
+      <table class="data-table">
+        <tbody>${rows}</tbody>
+      </table>
+      <h5>${escapeHTML(t('assets.software.title'))}</h5>
+      ${softwareItems ? `<ul>${softwareItems}</ul>` : `<div class="muted">${escapeHTML(t('assets.software.empty'))}</div>`}
+    `;
+  }
+
+  // showAsOf fills the point-in-time field, e.g. with an incident detection time, and
+  // shows the asset state at that moment.
+  function showAsOf(raw) {
+    const input = document.getElementById('asset-history-at');
+    if (!input || !state.assetId) return;
+    input.value = toLocalInput(raw);
+    loadSnapshot();
+    document.getElementById('asset-history-section')?.scrollIntoView({ block: 'start' });
+  }
+
+  function bindUI() {
+    if (state.bound) return;
+    state.bound = true;
+    document.getElementById('asset-history-refresh')?.addEventListener('click', () => load(false));
+    document.getElementById('asset-history-more')?.addEventListener('click', () => load(true));
+    document.getElementById('asset-history-show')?.addEventListener('click', () => loadSnapshot());
+  }
+
+  async function onAssetModalOpened({ asset }) {
+    bindUI();
+    state.assetId = asset ? (asset.id || 0) : 0;
+    state.items = [];
+    state.offset = 0;
+    const section = document.getElementById('asset-history-section');
+    if (section) section.hidden = !state.assetId;
+    const box = document.getElementById('asset-history-snapshot');
+    if (box) {
+      box.hidden = true;
+      box.innerHTML = '';
+    }
+    const input = document.getElementById('asset-history-at');
+    if (input) input.value = '';
+    setAlert(document.getElementById('asset-history-alert'), '');
+    if (!state.assetId) return;
+    await load(false);
+  }
+
+  return { onAssetModalOpened, showAsOf };
+})();
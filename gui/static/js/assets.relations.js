@@ -0,0 +1,319 @@
+const AssetsRelations = (() => {
+  const state = {
+    assetId: 0,
+    items: [],
+    canManage: false,
+    readOnly: true,
+    bound: false,
+  };
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHTML(str) {
+    return (str || '').toString().replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+  }
+
+  function localizeError(err, fallbackKey) {
+    const raw = String((err && err.message) || '').trim();
+    if (raw && typeof BerkutI18n !== 'undefined' && typeof BerkutI18n.t === 'function') {
+      const translated = BerkutI18n.t(raw);
+      if (translated && translated !== raw) return translated;
+    }
+    if (fallbackKey && typeof BerkutI18n !== 'undefined' && typeof BerkutI18n.t === 'function') {
+      return BerkutI18n.t(fallbackKey);
+    }
+    return raw || fallbackKey || 'Error';
+  }
+
+  function setAlert(el, msg) {
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+  }
+
+  function labelRelation(v) {
+    const k = `assets.relations.type.${(v || '').toString().toLowerCase()}`;
+    const msg = t(k);
+    return msg && msg !== k ? msg : (v || '');
+  }
+
+  function labelCriticality(v) {
+    const k = `assets.criticality.${(v || 'medium').toString().toLowerCase()}`;
+    const msg = t(k);
+    return msg && msg !== k ? msg : (v || 'medium');
+  }
+
+  function editable() {
+    return !!(state.assetId && state.canManage && !state.readOnly);
+  }
+
+  async function load() {
+    const alert = document.getElementById('asset-relations-alert');
+    setAlert(alert, '');
+    if (!state.assetId) return;
+    let res;
+    try {
+      res = await Api.get(`/api/assets/${state.assetId}/relations`);
+    } catch (err) {
+      state.items = [];
+      render();
+      setAlert(alert, localizeError(err, 'common.error'));
+      return;
+    }
+    state.items = Array.isArray(res?.items) ? res.items : [];
+    render();
+    await loadGraph();
+  }
+
+  function render() {
+    const tbody = document.querySelector('#asset-relations-table tbody');
+    const empty = document.getElementById('asset-relations-empty');
+    if (!tbody) return;
+    tbody.innerHTML = '';
+    if (empty) empty.hidden = state.items.length > 0;
+    state.items.forEach(rel => {
+      const tr = document.createElement('tr');
+      tr.innerHTML = `
+        <td>${escapeHTML(rel.source_name)}</td>
+        <td>${escapeHTML(labelRelation(rel.relation_type))}</td>
+        <td>${escapeHTML(rel.target_name)}</td>
+        <td>${escapeHTML(rel.description || '-')}</td>
+        <td></td>
+      `;
+      const cell = tr.querySelector('td:last-child');
+      if (cell && editable()) {
+        const delBtn = document.createElement('button');
+        delBtn.className = 'btn ghost btn-xs danger';
+        delBtn.textContent = t('assets.relations.actions.delete');
+        delBtn.addEventListener('click', () => removeRelation(rel));
+        cell.appendChild(delBtn);
+      }
+      tbody.appendChild(tr);
+    });
+  }
+
+  async function removeRelation(rel) {
+    const msg = t('assets.relations.confirm.delete');
+    const ok = await (window.AppConfirm?.ask
+      ? window.AppConfirm.ask(msg, {
+        title: t('common.confirm'),
+        confirmText: t('assets.relations.actions.delete'),
+        cancelText: t('common.cancel'),
+        danger: true,
+      })
+      : Promise.resolve(confirm(msg)));
+    if (!ok) return;
+    try {
+      await Api.del(`/api/assets/${state.assetId}/relations/${rel.id}`);
+    } catch (err) {
+      setAlert(document.getElementById('asset-relations-alert'), localizeError(err, 'common.error'));
+      return;
+    }
+    await load();
+  }
+
+  async function refreshTargetOptions(search) {
+    const select = document.getElementById('asset-relations-target');
+    if (!select) return;
+    select.innerHTML = '';
+    const placeholder = document.createElement('option');
+    placeholder.value = '';
+    placeholder.textContent = t('assets.relations.field.targetPlaceholder');
+    select.appendChild(placeholder);
+    let res;
+    try {
+      res = await Api.get(`/api/assets/list?limit=50&q=${encodeURIComponent(search || '')}`);
+    } catch (_) {
+      return;
+    }
+    (res.items || []).forEach(a => {
+      if (a.id === state.assetId) return;
+      const opt = document.createElement('option');
+      opt.value = String(a.id);
+      opt.textContent = a.name;
+      select.appendChild(opt);
+    });
+  }
+
+  async function addRelation() {
+    const alert = document.getElementById('asset-relations-alert');
+    setAlert(alert, '');
+    const targetID = parseInt(document.getElementById('asset-relations-target')?.value || '0', 10) || 0;
+    if (!targetID) {
+      setAlert(alert, t('assets.relations.targetRequired'));
+      return;
+    }
+    const payload = {
+      target_id: targetID,
+      relation_type: document.getElementById('asset-relations-type')?.value || '',
+      description: document.getElementById('asset-relations-description')?.value || '',
+    };
+    try {
+      await Api.post(`/api/assets/${state.assetId}/relations`, payload);
+    } catch (err) {
+      setAlert(alert, localizeError(err, 'common.error'));
+      return;
+    }
+    const desc = document.getElementById('asset-relations-description');
+    if (desc) desc.value = '';
+    await load();
+  }
+
+  async function loadGraph() {
+    const box = document.getElementById('asset-graph');
+    if (!box || !state.assetId) return;
+    box.innerHTML = '';
+    const direction = document.getElementById('asset-graph-direction')?.value || 'both';
+    const depth = document.getElementById('asset-graph-depth')?.value || '2';
+    let res;
+    try {
+      res = await Api.get(`/api/assets/${state.assetId}/graph?direction=${encodeURIComponent(direction)}&depth=${encodeURIComponent(depth)}`);
+    } catch (err) {
+      box.textContent = localizeError(err, 'common.error');
+      return;
+    }
+    renderGraph(box, res);
+  }
+
+  // renderGraph lays the nodes out in columns: dependencies on the left, the asset in
+  // the middle and dependents on the right, one column per level.
+  function renderGraph(box, res) {
+    const nodes = Array.isArray(res?.nodes) ? res.nodes : [];
+    const edges = Array.isArray(res?.edges) ? res.edges : [];
+    if (nodes.length <= 1) {
+      box.innerHTML = `<div class="muted">${escapeHTML(t('assets.graph.empty'))}</div>`;
+      return;
+    }
+    const columns = new Map();
+    nodes.forEach(n => {
+      let key = 0;
+      if (n.direction === 'upstream') key = -n.depth;
+      if (n.direction === 'downstream' || n.direction === 'both') key = n.depth;
+      if (!columns.has(key)) columns.set(key, []);
+      columns.get(key).push(n);
+    });
+    const relationsOf = (id) => edges
+      .filter(e => e.source_id === id || e.target_id === id)
+      .map(e => `${e.source_name} → ${labelRelation(e.relation_type)} → ${e.target_name}`)
+      .join('\n');
+    Array.from(columns.keys()).sort((a, b) => a - b).forEach(key => {
+      const col = document.createElement('div');
+      col.className = 'asset-graph-col';
+      const title = document.createElement('div');
+      title.className = 'asset-graph-col-title';
+      if (key < 0) title.textContent = `${t('assets.graph.direction.upstream')} · ${-key}`;
+      else if (key > 0) title.textContent = `${t('assets.graph.direction.downstream')} · ${key}`;
+      else title.textContent = t('assets.graph.root');
+      col.appendChild(title);
+      columns.get(key).forEach(n => {
+        const node = document.createElement('div');
+        node.className = 'asset-graph-node';
+        node.dataset.root = n.direction === 'root' ? '1' : '0';
+        node.title = relationsOf(n.id);
+        node.innerHTML = `${escapeHTML(n.name)}<small>${escapeHTML(labelCriticality(n.criticality))}</small>`;
+        if (n.direction !== 'root' && typeof AssetsPage !== 'undefined' && AssetsPage.openAsset) {
+          node.addEventListener('click', () => AssetsPage.openAsset(n.id, 'view'));
+        }
+        col.appendChild(node);
+      });
+      box.appendChild(col);
+    });
+    if (res.truncated) {
+      const note = document.createElement('div');
+      note.className = 'muted';
+      note.textContent = t('assets.graph.truncated');
+      box.appendChild(note);
+    }
+  }
+
+  async function showBlastRadius() {
+    const box = document.getElementById('asset-blast');
+    if (!box || !state.assetId) return;
+    box.hidden = false;
+    box.innerHTML = '';
+    const depth = document.getElementById('asset-graph-depth')?.value || '2';
+    let res;
+    try {
+      res = await Api.get(`/api/assets/blast-radius?asset_id=${state.assetId}&depth=${encodeURIComponent(depth)}`);
+    } catch (err) {
+      box.textContent = localizeError(err, 'common.error');
+      return;
+    }
+    box.innerHTML = renderBlastRadius(res);
+  }
+
+  function renderBlastRadius(res) {
+    const assets = Array.isArray(res?.assets) ? res.assets : [];
+    const incidents = Array.isArray(res?.incidents) ? res.incidents : [];
+    const controls = Array.isArray(res?.controls) ? res.controls : [];
+    const assetRows = assets.map(a => `
+      <tr>
+        <td>${escapeHTML(a.name)}</td>
+        <td>${escapeHTML(labelCriticality(a.criticality))}</td>
+        <td>${a.depth}</td>
+        <td>${escapeHTML((a.software || []).join(', ') || '-')}</td>
+      </tr>`).join('');
+    const incidentItems = incidents.map(i => `<li>${escapeHTML(i.reg_no)} ${escapeHTML(i.title)}</li>`).join('');
+    const controlItems = controls.map(c => `<li>${escapeHTML(c.code)} ${escapeHTML(c.title)}</li>`).join('');
+    const none = `<div class="muted">${escapeHTML(t('assets.blast.none'))}</div>`;
+    return `
+      <h5>${escapeHTML(t('assets.blast.assets'))}</h5>
+      <div class="table-responsive">
+        <table class="data-table">
+          <thead>
+            <tr>
+              <th>${escapeHTML(t('assets.table.name'))}</th>
+              <th>${escapeHTML(t('assets.table.criticality'))}</th>
+              <th>${escapeHTML(t('assets.graph.depth'))}</th>
+              <th>${escapeHTML(t('assets.software.title'))}</th>
+            </tr>
+          </thead>
+          <tbody>${assetRows}</tbody>
+        </table>
+      </div>
+      <h5>${escapeHTML(t('assets.blast.incidents'))}</h5>
+      ${incidentItems ? `<ul>${incidentItems}</ul>` : none}
+      <h5>${escapeHTML(t('assets.blast.controls'))}</h5>
+      ${controlItems ? `<ul>${controlItems}</ul>` : none}
+      ${res?.truncated ? `<div class="muted">${escapeHTML(t('assets.graph.truncated'))}</div>` : ''}
+    `;
+  }
+
+  function bindUI() {
+    if (state.bound) return;
+    state.bound = true;
+    document.getElementById('asset-relations-refresh')?.addEventListener('click', () => load());
+    document.getElementById('asset-relations-add')?.addEventListener('click', () => addRelation());
+    document.getElementById('asset-relations-blast')?.addEventListener('click', () => showBlastRadius());
+    document.getElementById('asset-relations-target-q')?.addEventListener('input', (e) => refreshTargetOptions(e.target.value || ''));
+    document.getElementById('asset-graph-direction')?.addEventListener('change', () => loadGraph());
+    document.getElementById('asset-graph-depth')?.addEventListener('change', () => loadGraph());
+  }
+
+  async function onAssetModalOpened({ asset, readOnly, canManage }) {
+    bindUI();
+    state.assetId = asset ? (asset.id || 0) : 0;
+    state.readOnly = !!readOnly;
+    state.canManage = !!canManage;
+    state.items = [];
+
+    const section = document.getElementById('asset-relations-section');
+    if (section) section.hidden = !state.assetId;
+    const form = document.getElementById('asset-relations-form');
+    if (form) form.hidden = !editable();
+    const blast = document.getElementById('asset-blast');
+    if (blast) {
+      blast.hidden = true;
+      blast.innerHTML = '';
+    }
+    setAlert(document.getElementById('asset-relations-alert'), '');
+
+    if (!state.assetId) return;
+    if (editable()) await refreshTargetOptions('');
+    await load();
+  }
+
+  return { onAssetModalOpened };
+})();
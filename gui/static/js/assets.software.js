@@ -359,11 +359,50 @@ const AssetsSoftware = (() => {
     }
   }
 
+  async function uploadSBOM(file, preview) {
+    const fd = new FormData();
+    fd.append('file', file);
+    if (preview) fd.append('preview', '1');
+    return Api.upload(`/api/assets/${state.assetId}/software/sbom`, fd);
+  }
+
+  async function importSBOM(file) {
+    if (!file || !state.assetId) return;
+    try {
+      const plan = await uploadSBOM(file, true);
+      const summary = t('assets.sbom.preview')
+        .replace('{added}', plan.added || 0)
+        .replace('{updated}', plan.updated || 0)
+        .replace('{unchanged}', plan.unchanged || 0)
+        .replace('{archived}', plan.archived || 0)
+        .replace('{products}', plan.new_products || 0)
+        .replace('{versions}', plan.new_versions || 0);
+      const ok = await (window.AppConfirm?.ask
+        ? window.AppConfirm.ask(summary, {
+          title: t('assets.sbom.import'),
+          confirmText: t('assets.sbom.apply'),
+          cancelText: t('common.cancel'),
+        })
+        : Promise.resolve(confirm(summary)));
+      if (!ok) return;
+      await uploadSBOM(file, false);
+      await load();
+    } catch (err) {
+      alert(localizeError(err, 'assets.sbom.formatInvalid'));
+    }
+  }
+
   function bindUI() {
     if (state.bound) return;
     state.bound = true;
     document.getElementById('asset-software-refresh')?.addEventListener('click', () => load());
     document.getElementById('asset-software-add')?.addEventListener('click', () => openInstallModal(null, false));
+    document.getElementById('asset-software-sbom')?.addEventListener('click', () => document.getElementById('asset-software-sbom-file')?.click());
+    document.getElementById('asset-software-sbom-file')?.addEventListener('change', async (e) => {
+      const file = e.target.files && e.target.files[0];
+      e.target.value = '';
+      await importSBOM(file);
+    });
     document.getElementById('asset-software-save')?.addEventListener('click', () => saveFromModal());
     document.getElementById('asset-software-product-q')?.addEventListener('input', (e) => refreshProductOptions(e.target.value || ''));
     document.getElementById('asset-software-product-select')?.addEventListener('change', async (e) => {
@@ -390,6 +429,8 @@ const AssetsSoftware = (() => {
     if (filters) filters.hidden = !(state.assetId && state.canManage && !state.readOnly);
     const addBtn = document.getElementById('asset-software-add');
     if (addBtn) addBtn.hidden = !(state.assetId && state.canManage && !state.readOnly);
+    const sbomBtn = document.getElementById('asset-software-sbom');
+    if (sbomBtn) sbomBtn.hidden = !(state.assetId && state.canManage && !state.readOnly);
 
     if (!state.assetId) return;
     await load();
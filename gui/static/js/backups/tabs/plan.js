@@ -17,6 +17,7 @@ const BackupsPlan = (() => {
   async function load(showSpinner = false) {
     toggleBusy(showSpinner);
     try {
+      await loadCalendars();
       const res = await BackupsPage.apiGet('/api/backups/plan');
       render(res.item || {});
     } catch (err) {
@@ -42,6 +43,10 @@ const BackupsPlan = (() => {
         retention_days: parseInt(document.getElementById('backups-plan-retention-days')?.value || '30', 10),
         keep_last_successful: parseInt(document.getElementById('backups-plan-keep-last')?.value || '5', 10),
         include_files: !!document.getElementById('backups-plan-include-files')?.checked,
+        schedule_rrule: (document.getElementById('backups-plan-rrule')?.value || '').trim(),
+        schedule_timezone: (document.getElementById('backups-plan-timezone')?.value || '').trim(),
+        schedule_shift: document.getElementById('backups-plan-shift')?.value || '',
+        schedule_calendar_id: parseInt(document.getElementById('backups-plan-calendar')?.value || '0', 10) || 0,
       };
       const res = await BackupsPage.apiPut('/api/backups/plan', payload);
       render(res.item || {});
@@ -62,6 +67,10 @@ const BackupsPlan = (() => {
     setValue('backups-plan-keep-last', `${item.keep_last_successful || 5}`);
     setChecked('backups-plan-enabled', !!item.enabled);
     setChecked('backups-plan-include-files', !!item.include_files);
+    setValue('backups-plan-rrule', item.schedule_rrule || '');
+    setValue('backups-plan-timezone', item.schedule_timezone || '');
+    setValue('backups-plan-shift', item.schedule_shift || '');
+    setValue('backups-plan-calendar', item.schedule_calendar_id ? `${item.schedule_calendar_id}` : '');
     syncScheduleVisibility();
   }
 
@@ -76,7 +85,7 @@ const BackupsPlan = (() => {
   }
 
   function toggleBusy(disabled) {
-    const ids = ['backups-plan-save', 'backups-plan-refresh', 'backups-plan-enabled', 'backups-plan-schedule-type', 'backups-plan-weekday', 'backups-plan-time', 'backups-plan-retention-days', 'backups-plan-keep-last', 'backups-plan-include-files'];
+    const ids = ['backups-plan-save', 'backups-plan-refresh', 'backups-plan-enabled', 'backups-plan-schedule-type', 'backups-plan-weekday', 'backups-plan-time', 'backups-plan-retention-days', 'backups-plan-keep-last', 'backups-plan-include-files', 'backups-plan-rrule', 'backups-plan-timezone', 'backups-plan-shift', 'backups-plan-calendar'];
     ids.forEach((id) => {
       const el = document.getElementById(id);
       if (el) el.disabled = disabled;
@@ -87,6 +96,25 @@ const BackupsPlan = (() => {
     const type = (document.getElementById('backups-plan-schedule-type')?.value || 'daily').trim();
     const weekdayWrap = document.getElementById('backups-plan-weekday-wrap');
     if (weekdayWrap) weekdayWrap.hidden = type !== 'weekly';
+    const rruleWrap = document.getElementById('backups-plan-rrule-wrap');
+    if (rruleWrap) rruleWrap.hidden = type !== 'rrule';
+  }
+
+  async function loadCalendars() {
+    const select = document.getElementById('backups-plan-calendar');
+    if (!select || select.dataset.loaded) return;
+    try {
+      const res = await BackupsPage.apiGet('/api/schedule/calendars');
+      (res.items || []).forEach((cal) => {
+        const opt = document.createElement('option');
+        opt.value = `${cal.id}`;
+        opt.textContent = cal.name;
+        select.appendChild(opt);
+      });
+      select.dataset.loaded = '1';
+    } catch (_) {
+      // Without calendars the default weekend applies.
+    }
   }
 
   function parseTime() {
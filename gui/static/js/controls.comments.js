@@ -113,7 +113,8 @@
       if (comment.content) {
         const body = document.createElement('div');
         body.className = 'control-comment-body';
-        body.textContent = comment.content;
+        if (window.AppMentions) AppMentions.render(body, comment.content);
+        else body.textContent = comment.content;
         wrapper.appendChild(body);
       }
       if (comment.attachments && comment.attachments.length) {
@@ -33,7 +33,8 @@ const ControlsPage = (() => {
     frameworks: 'controls-tab-frameworks',
     assets: 'controls-tab-assets',
     software: 'controls-tab-software',
-    findings: 'controls-tab-findings'
+    findings: 'controls-tab-findings',
+    risks: 'controls-tab-risks'
   };
 
   function init() {
@@ -224,6 +225,9 @@ const ControlsPage = (() => {
     document.querySelectorAll('#controls-tabs .tab-btn[data-tab="controls-tab-findings"]').forEach(btn => {
       btn.hidden = !hasPerm('findings.view') || !hasMenu('findings');
     });
+    document.querySelectorAll('#controls-tabs .tab-btn[data-tab="controls-tab-risks"]').forEach(btn => {
+      btn.hidden = !hasPerm('risks.view') || !hasMenu('risks');
+    });
   }
 
   function bindTabs() {
@@ -263,7 +267,7 @@ const ControlsPage = (() => {
   }
 
   function isRegistryTab(tabId) {
-    return tabId === 'controls-tab-assets' || tabId === 'controls-tab-software' || tabId === 'controls-tab-findings';
+    return tabId === 'controls-tab-assets' || tabId === 'controls-tab-software' || tabId === 'controls-tab-findings' || tabId === 'controls-tab-risks';
   }
 
   function registryNameForTab(tabId) {
@@ -274,6 +278,8 @@ const ControlsPage = (() => {
         return 'software';
       case 'controls-tab-findings':
         return 'findings';
+      case 'controls-tab-risks':
+        return 'risks';
       default:
         return '';
     }
@@ -309,6 +315,7 @@ const ControlsPage = (() => {
       if (name === 'assets' && typeof AssetsPage !== 'undefined') AssetsPage.init();
       if (name === 'software' && typeof SoftwarePage !== 'undefined') SoftwarePage.init();
       if (name === 'findings' && typeof FindingsPage !== 'undefined') FindingsPage.init();
+      if (name === 'risks' && typeof RisksPage !== 'undefined') RisksPage.init();
       switchTab(tabId, opts);
     } catch (err) {
       if (panel) {
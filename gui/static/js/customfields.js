@@ -0,0 +1,119 @@
+var CustomFieldsForm = (() => {
+  const cache = {};
+  let assetsLoading;
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHtml(str) {
+    return String(str == null ? '' : str)
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  async function load(entity, force = false) {
+    if (cache[entity] && !force) return cache[entity];
+    cache[entity] = Api.get(`/api/custom-fields?entity_type=${encodeURIComponent(entity)}`)
+      .then(res => (Array.isArray(res.items) ? res.items : []))
+      .catch(() => []);
+    return cache[entity];
+  }
+
+  function loadAssets() {
+    if (!assetsLoading) {
+      assetsLoading = Api.get('/api/assets/list?limit=500')
+        .then(res => (Array.isArray(res.items) ? res.items : []))
+        .catch(() => []);
+    }
+    return assetsLoading;
+  }
+
+  function option(value, label, selected) {
+    return `<option value="${escapeHtml(value)}"${selected ? ' selected' : ''}>${escapeHtml(label)}</option>`;
+  }
+
+  function inputHtml(field, value, choices) {
+    const attrs = `data-cf-key="${escapeHtml(field.key)}" data-cf-type="${escapeHtml(field.field_type)}"`;
+    const current = value == null ? '' : value;
+    switch (field.field_type) {
+      case 'number':
+        return `<input type="number" step="any" ${attrs} value="${escapeHtml(current)}">`;
+      case 'date':
+        return `<input type="date" ${attrs} value="${escapeHtml(String(current).slice(0, 10))}">`;
+      case 'select':
+        return `<select class="select" ${attrs}>${option('', '-', !current)}${(field.options || []).map(o => option(o, o, o === current)).join('')}</select>`;
+      case 'multiselect': {
+        const selected = Array.isArray(current) ? current : [];
+        return `<select class="select" multiple ${attrs}>${(field.options || []).map(o => option(o, o, selected.includes(o))).join('')}</select>`;
+      }
+      case 'user':
+      case 'asset':
+        return `<select class="select" ${attrs}>${option('', '-', !current)}${choices.map(c => option(c.id, c.label, String(c.id) === String(current))).join('')}</select>`;
+      default:
+        return `<input ${attrs} value="${escapeHtml(current)}">`;
+    }
+  }
+
+  async function choicesFor(field) {
+    if (field.field_type === 'user') {
+      const dir = (typeof window !== 'undefined' && window.UserDirectory) ? window.UserDirectory : null;
+      if (!dir) return [];
+      await dir.load();
+      return dir.all().map(u => ({ id: u.id, label: u.full_name || u.username }));
+    }
+    if (field.field_type === 'asset') {
+      const assets = await loadAssets();
+      return assets.map(a => ({ id: a.id, label: a.name }));
+    }
+    return [];
+  }
+
+  // render fills the container with the fields of the entity visible to the user.
+  // Fields the user may not edit, or all of them when readOnly, are disabled.
+  async function render(container, entity, values, readOnly) {
+    if (!container) return;
+    const fields = await load(entity);
+    container.innerHTML = '';
+    container.hidden = fields.length === 0;
+    const current = values || {};
+    for (const field of fields) {
+      const choices = await choicesFor(field);
+      const wrap = document.createElement('div');
+      wrap.className = `form-field${field.required ? ' required' : ''}`;
+      wrap.innerHTML = `<label>${escapeHtml(field.label)}</label>${inputHtml(field, current[field.key], choices)}`;
+      const input = wrap.querySelector('[data-cf-key]');
+      if (input) input.disabled = !!readOnly || !field.can_edit;
+      container.appendChild(wrap);
+    }
+  }
+
+  // collect returns the values of editable fields keyed by field key; empty
+  // inputs are sent as null so that cleared values are removed.
+  function collect(container) {
+    const out = {};
+    if (!container) return out;
+    container.querySelectorAll('[data-cf-key]').forEach((el) => {
+      if (el.disabled) return;
+      const key = el.dataset.cfKey;
+      const type = el.dataset.cfType;
+      if (type === 'multiselect') {
+        const items = Array.from(el.selectedOptions || []).map(o => o.value);
+        out[key] = items.length ? items : null;
+        return;
+      }
+      const raw = (el.value || '').toString().trim();
+      out[key] = raw === '' ? null : raw;
+    });
+    return out;
+  }
+
+  return { load, render, collect };
+})();
+
+if (typeof window !== 'undefined') {
+  window.CustomFieldsForm = CustomFieldsForm;
+}
@@ -65,7 +65,7 @@
     order.forEach((id, idx) => {
       if (hidden.has(id)) return;
       const titleKey = frameMap[id] || `dashboard.frame.${id}`;
-      const renderer = FRAME_RENDERERS[id];
+      const renderer = FRAME_RENDERERS[id] || (id.startsWith(SAVED_SEARCH_PREFIX) ? renderSavedSearchFrame : null);
       const frame = renderer ? renderer(id, titleKey) : renderPlaceholderFrame(id, titleKey);
       if (frame) {
         state.grid.appendChild(frame);
@@ -514,6 +514,43 @@
       container.innerHTML = `<div class="muted">${t('dashboard.activity.error')}</div>`;
     }
   }
+  function renderSavedSearchFrame(id, titleKey) {
+    const shell = createFrameShell(id, titleKey);
+    shell.card.classList.add('frame-saved-search');
+    const container = document.createElement('div');
+    container.className = 'dashboard-activity';
+    container.innerHTML = `<div class="muted">${t('dashboard.activity.loading')}</div>`;
+    shell.body.appendChild(container);
+    const frame = (state.frames || []).find(f => f && f.id === id) || {};
+    loadSavedSearch(frame, container);
+    return shell.card;
+  }
+  async function loadSavedSearch(frame, container) {
+    const source = SAVED_SEARCH_SOURCES[frame.entity_type];
+    if (!source || !frame.search_id) {
+      container.innerHTML = `<div class="muted">${t('dashboard.framePlaceholder')}</div>`;
+      return;
+    }
+    try {
+      const res = await Api.get(`${source.url}?saved_search=${encodeURIComponent(frame.search_id)}&limit=${SAVED_SEARCH_LIMIT}`);
+      const items = Array.isArray(res.items) ? res.items.slice(0, SAVED_SEARCH_LIMIT) : [];
+      if (!items.length) {
+        container.innerHTML = `<div class="muted">${t('savedSearches.empty')}</div>`;
+        return;
+      }
+      container.innerHTML = '';
+      items.forEach(item => {
+        const row = document.createElement('div');
+        row.className = 'dashboard-activity-row';
+        row.innerHTML = `
+          <div class="dashboard-activity-title">${escapeHtml(source.title(item))}</div>
+          <div class="dashboard-activity-meta">${escapeHtml(source.meta(item))} - ${formatDate(item.updated_at)}</div>`;
+        container.appendChild(row);
+      });
+    } catch (err) {
+      container.innerHTML = `<div class="muted">${t('savedSearches.loadError')}</div>`;
+    }
+  }
   function translateAction(action) {
     if (globalObj.LogsPage && typeof globalObj.LogsPage.prettyAction === "function") {
       return globalObj.LogsPage.prettyAction(action);
@@ -534,6 +571,15 @@
     const pad = (num) => `${num}`.padStart(2, '0');
     return `${pad(dt.getDate())}.${pad(dt.getMonth() + 1)}.${dt.getFullYear()} ${pad(dt.getHours())}:${pad(dt.getMinutes())}`;
   }
+  const SAVED_SEARCH_PREFIX = 'saved_search.';
+  const SAVED_SEARCH_LIMIT = 10;
+  const SAVED_SEARCH_SOURCES = {
+    incident: { url: '/api/incidents', title: i => `${i.reg_no || ''} ${i.title || ''}`.trim(), meta: i => t(`incidents.status.${i.status}`) },
+    finding: { url: '/api/findings', title: i => i.title || '', meta: i => i.status || '' },
+    asset: { url: '/api/assets', title: i => i.name || '', meta: i => i.criticality || '' },
+    task: { url: '/api/tasks', title: i => i.title || '', meta: i => i.status || '' },
+    doc: { url: '/api/docs', title: i => `${i.reg_number || ''} ${i.title || ''}`.trim(), meta: i => i.status || '' }
+  };
   const FRAME_RENDERERS = {
     summary: renderSummaryFrame,
     tasks: renderTasksFrame,
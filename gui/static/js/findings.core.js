@@ -5,8 +5,14 @@ const FindingsPage = (() => {
     linkOptions: { assets: [], software: [], controls: [], incidents: [], tasks: [], docs: [] },
     linkOptionsLoaded: false,
     permissions: [],
+    userId: null,
+    policy: null,
+    exceptions: [],
+    customFieldValues: null,
+    pendingExceptions: [],
     pendingOpenId: null,
-    tagsBound: false
+    tagsBound: false,
+    searchBar: null
   };
 
   function t(key) {
@@ -30,9 +36,18 @@ const FindingsPage = (() => {
     if (typeof RegistryReports !== 'undefined' && RegistryReports.bind) {
       RegistryReports.bind('findings-create-report', 'findings');
     }
+    if (typeof RegistryImport !== 'undefined' && RegistryImport.bind) {
+      RegistryImport.bind('findings-import', 'findings', load);
+    }
     applyAccessControls();
     bindTagDirectory();
+    if (typeof SavedSearchBar !== 'undefined') {
+      state.searchBar = SavedSearchBar.mount(document.getElementById('findings-saved-search'), 'finding', load);
+    }
+    if (typeof UserDirectory !== 'undefined') await UserDirectory.load();
+    await loadPolicy();
     await load();
+    await loadPendingExceptions();
     if (state.pendingOpenId) {
       const id = state.pendingOpenId;
       state.pendingOpenId = null;
@@ -45,6 +60,7 @@ const FindingsPage = (() => {
       const res = await Api.get('/api/auth/me');
       const me = res.user;
       state.permissions = Array.isArray(me?.permissions) ? me.permissions : [];
+      state.userId = me?.id || null;
     } catch (_) {
       state.permissions = [];
     }
@@ -58,6 +74,10 @@ const FindingsPage = (() => {
     document.getElementById('finding-link-add')?.addEventListener('click', () => addLink());
     document.getElementById('finding-link-target-type')?.addEventListener('change', () => refreshLinkTargets());
     document.getElementById('finding-link-search')?.addEventListener('input', () => refreshLinkTargets());
+    document.getElementById('findings-sla-policy')?.addEventListener('click', () => openPolicyModal());
+    document.getElementById('finding-sla-save')?.addEventListener('click', () => savePolicy());
+    document.getElementById('finding-sla-evaluate')?.addEventListener('click', () => evaluateNow());
+    document.getElementById('finding-exception-request')?.addEventListener('click', () => requestException());
   }
 
   function applyAccessControls() {
@@ -65,6 +85,8 @@ const FindingsPage = (() => {
     if (createBtn) createBtn.hidden = !hasPerm('findings.manage');
     const includeField = document.getElementById('findings-include-deleted-field');
     if (includeField) includeField.hidden = !hasPerm('findings.manage');
+    const slaBtn = document.getElementById('findings-sla-policy');
+    if (slaBtn) slaBtn.hidden = !hasPerm('findings.manage');
   }
 
   function bindTagDirectory() {
@@ -125,8 +147,11 @@ const FindingsPage = (() => {
     if (val('findings-filter-severity')) q.set('severity', val('findings-filter-severity'));
     if (val('findings-filter-type')) q.set('type', val('findings-filter-type'));
     if (val('findings-filter-tag')) q.set('tag', val('findings-filter-tag'));
+    if (val('findings-filter-overdue')) q.set('overdue', val('findings-filter-overdue'));
     const includeDeleted = val('findings-filter-include-deleted');
     if (includeDeleted) q.set('include_deleted', includeDeleted);
+    const query = state.searchBar ? state.searchBar.query() : '';
+    if (query) q.set('query', query);
     q.set('limit', '200');
     return q.toString();
   }
@@ -135,8 +160,10 @@ const FindingsPage = (() => {
     try {
       const res = await Api.get(`/api/findings?${buildFilterQuery()}`);
       state.items = res.items || [];
-    } catch (_) {
+      if (state.searchBar) state.searchBar.showError(null);
+    } catch (err) {
       state.items = [];
+      if (state.searchBar) state.searchBar.showError(err);
     }
     renderTable();
   }
@@ -164,6 +191,7 @@ const FindingsPage = (() => {
         <td>${escapeHtml(typeLabel(item.finding_type))}</td>
         <td>${escapeHtml(item.owner || '-')}</td>
         <td>${escapeHtml(item.due_at ? formatDate(item.due_at) : '-')}</td>
+        <td>${slaBadge(item)}</td>
         <td>${escapeHtml(tags || '-')}</td>
         <td>${escapeHtml(item.updated_at ? formatDateTime(item.updated_at) : '-')}</td>
         <td class="actions"></td>
@@ -198,6 +226,7 @@ const FindingsPage = (() => {
         const item = await Api.get(`/api/findings/${id}`);
         fillForm(item);
         await loadLinks(id);
+        await loadExceptions(id);
       } catch (err) {
         if (alert) {
           alert.textContent = localizeError(err);
@@ -208,8 +237,13 @@ const FindingsPage = (() => {
     } else {
       state.links = [];
       renderLinks();
+      state.exceptions = [];
+      renderExceptions();
     }
     setFormDisabled(viewOnly);
+    if (typeof CustomFieldsForm !== 'undefined') {
+      await CustomFieldsForm.render(document.getElementById('finding-custom-fields'), 'finding', state.customFieldValues, viewOnly);
+    }
     modal.hidden = false;
   }
 
@@ -228,6 +262,7 @@ const FindingsPage = (() => {
     setVal('finding-owner', '');
     setVal('finding-due-at', '');
     setFindingTags([]);
+    state.customFieldValues = null;
   }
 
   function fillForm(item) {
@@ -240,6 +275,7 @@ const FindingsPage = (() => {
     setVal('finding-owner', item.owner || '');
     setVal('finding-due-at', item.due_at ? formatDateInput(item.due_at) : '');
     setFindingTags(Array.isArray(item.tags) ? item.tags : []);
+    state.customFieldValues = item.custom_fields || null;
     document.getElementById('finding-form').dataset.version = String(item.version || 1);
     const archiveBtn = document.getElementById('finding-archive');
     if (archiveBtn) {
@@ -265,6 +301,10 @@ const FindingsPage = (() => {
     if (linkType) linkType.disabled = !!disabled;
     if (linkTarget) linkTarget.disabled = !!disabled;
     if (linkSearch) linkSearch.disabled = !!disabled;
+    const exceptionSection = document.getElementById('finding-exceptions-section');
+    if (exceptionSection) exceptionSection.hidden = !document.getElementById('finding-id')?.value;
+    const exceptionForm = document.getElementById('finding-exception-form');
+    if (exceptionForm) exceptionForm.hidden = !hasPerm('findings.manage');
   }
 
   async function saveFinding() {
@@ -283,6 +323,9 @@ const FindingsPage = (() => {
       tags: selectedValues('finding-tags'),
       version: parseInt(document.getElementById('finding-form')?.dataset.version || '1', 10) || 1
     };
+    if (typeof CustomFieldsForm !== 'undefined') {
+      payload.custom_fields = CustomFieldsForm.collect(document.getElementById('finding-custom-fields'));
+    }
     if (!payload.title) {
       showAlert(alert, t('findings.titleRequired'));
       return;
@@ -508,6 +551,261 @@ const FindingsPage = (() => {
     }
   }
 
+  function slaState(item) {
+    if (!item || item.deleted_at) return 'none';
+    if (item.sla_paused_at) return 'paused';
+    if (!item.due_at || (item.status !== 'open' && item.status !== 'in_progress')) return 'none';
+    const due = new Date(item.due_at).getTime();
+    const now = Date.now();
+    if (due <= now) return 'overdue';
+    const soonDays = state.policy ? state.policy.due_soon_days || 0 : 7;
+    if (soonDays > 0 && due - now <= soonDays * 86400000) return 'due_soon';
+    return 'on_track';
+  }
+
+  function slaBadge(item) {
+    const stateKey = slaState(item);
+    if (stateKey === 'none') return '-';
+    let label = t(`findings.sla.state.${stateKey}`);
+    if (stateKey === 'overdue' && item.escalated_at) label += ` · ${t('findings.sla.escalated')}`;
+    return `<span class="finding-sla-badge" data-state="${stateKey}">${escapeHtml(label)}</span>`;
+  }
+
+  async function loadPolicy() {
+    try {
+      state.policy = await Api.get('/api/findings/sla/policy');
+    } catch (_) {
+      state.policy = null;
+    }
+  }
+
+  function fillUserSelect(select, selected, withEmpty) {
+    if (!select) return;
+    const chosen = new Set((Array.isArray(selected) ? selected : [selected]).filter(Boolean).map(String));
+    select.innerHTML = '';
+    if (withEmpty) {
+      const none = document.createElement('option');
+      none.value = '';
+      none.textContent = '-';
+      select.appendChild(none);
+    }
+    const users = typeof UserDirectory !== 'undefined' ? UserDirectory.all() : [];
+    users
+      .filter(u => u.id !== state.userId || !withEmpty)
+      .slice()
+      .sort((a, b) => (a.full_name || a.username || '').localeCompare(b.full_name || b.username || ''))
+      .forEach((u) => {
+        const opt = document.createElement('option');
+        opt.value = String(u.id);
+        opt.textContent = u.full_name || u.username;
+        if (chosen.has(String(u.id))) opt.selected = true;
+        select.appendChild(opt);
+      });
+  }
+
+  async function openPolicyModal() {
+    const modal = document.getElementById('finding-sla-modal');
+    if (!modal) return;
+    showAlert(document.getElementById('finding-sla-alert'), '');
+    await loadPolicy();
+    const p = state.policy || { days: {} };
+    ['critical', 'high', 'medium', 'low'].forEach((sev) => {
+      setVal(`finding-sla-days-${sev}`, String((p.days || {})[sev] ?? 0));
+    });
+    setVal('finding-sla-due-soon', String(p.due_soon_days ?? 0));
+    setVal('finding-sla-escalation', String(p.escalation_days ?? 0));
+    setVal('finding-sla-max-exception', String(p.max_exception_days ?? 365));
+    fillUserSelect(document.getElementById('finding-sla-escalate-to'), p.escalate_to || [], false);
+    await fillCalendarSelect(document.getElementById('finding-sla-calendar'), p.calendar_id || 0);
+    modal.hidden = false;
+  }
+
+  async function fillCalendarSelect(select, selected) {
+    if (!select) return;
+    if (!select.dataset.loaded) {
+      try {
+        const res = await Api.get('/api/schedule/calendars');
+        (res.items || []).forEach((cal) => {
+          const opt = document.createElement('option');
+          opt.value = `${cal.id}`;
+          opt.textContent = cal.name;
+          select.appendChild(opt);
+        });
+        select.dataset.loaded = '1';
+      } catch (_) {
+        // Without calendars the SLA keeps counting calendar days.
+      }
+    }
+    select.value = selected ? `${selected}` : '';
+  }
+
+  async function savePolicy() {
+    const alert = document.getElementById('finding-sla-alert');
+    const num = (id) => parseInt(document.getElementById(id)?.value || '0', 10) || 0;
+    const payload = {
+      days: {
+        critical: num('finding-sla-days-critical'),
+        high: num('finding-sla-days-high'),
+        medium: num('finding-sla-days-medium'),
+        low: num('finding-sla-days-low')
+      },
+      due_soon_days: num('finding-sla-due-soon'),
+      escalation_days: num('finding-sla-escalation'),
+      escalate_to: selectedValues('finding-sla-escalate-to').map(v => parseInt(v, 10)).filter(Boolean),
+      max_exception_days: num('finding-sla-max-exception'),
+      calendar_id: num('finding-sla-calendar')
+    };
+    try {
+      state.policy = await Api.put('/api/findings/sla/policy', payload);
+      showAlert(alert, t('common.saved'));
+      renderTable();
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function evaluateNow() {
+    const alert = document.getElementById('finding-sla-alert');
+    try {
+      const res = await Api.post('/api/findings/sla/evaluate', {});
+      showAlert(alert, t('findings.sla.evaluated')
+        .replace('{overdue}', res.overdue || 0)
+        .replace('{escalated}', res.escalated || 0)
+        .replace('{expired}', res.exceptions_expired || 0));
+      await load();
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function loadPendingExceptions() {
+    const box = document.getElementById('findings-pending-exceptions');
+    try {
+      const res = await Api.get('/api/findings/exceptions/pending');
+      state.pendingExceptions = res.items || [];
+    } catch (_) {
+      state.pendingExceptions = [];
+    }
+    if (!box) return;
+    box.innerHTML = '';
+    box.hidden = !state.pendingExceptions.length;
+    if (!state.pendingExceptions.length) return;
+    const title = document.createElement('div');
+    title.textContent = t('findings.exceptions.pendingForYou').replace('{count}', state.pendingExceptions.length);
+    box.appendChild(title);
+    state.pendingExceptions.forEach((ex) => {
+      const btn = document.createElement('button');
+      btn.type = 'button';
+      btn.className = 'btn ghost btn-xs';
+      btn.textContent = `#${ex.finding_id}`;
+      btn.addEventListener('click', () => openFinding(ex.finding_id, 'view'));
+      box.appendChild(btn);
+    });
+  }
+
+  async function loadExceptions(id) {
+    state.exceptions = [];
+    try {
+      const res = await Api.get(`/api/findings/${id}/exceptions`);
+      state.exceptions = res.items || [];
+    } catch (_) {
+      state.exceptions = [];
+    }
+    renderExceptions();
+  }
+
+  function renderExceptions() {
+    const tbody = document.querySelector('#finding-exceptions-table tbody');
+    const empty = document.getElementById('finding-exceptions-empty');
+    if (!tbody) return;
+    tbody.innerHTML = '';
+    if (empty) empty.hidden = !!state.exceptions.length;
+    const userName = (id) => (id && typeof UserDirectory !== 'undefined' ? UserDirectory.name(id) : '-');
+    state.exceptions.forEach((ex) => {
+      const tr = document.createElement('tr');
+      const comment = ex.decision_comment ? `<div class="muted">${escapeHtml(ex.decision_comment)}</div>` : '';
+      tr.innerHTML = `
+        <td>${escapeHtml(t(`findings.exceptions.state.${ex.status}`))}</td>
+        <td>${escapeHtml(userName(ex.requested_by))}</td>
+        <td>${escapeHtml(userName(ex.approver_id))}</td>
+        <td>${escapeHtml(formatDate(ex.expires_at))}</td>
+        <td>${escapeHtml(ex.justification || '')}${comment}</td>
+        <td class="actions"></td>
+      `;
+      const actions = tr.querySelector('.actions');
+      const addBtn = (key, handler, danger) => {
+        const btn = document.createElement('button');
+        btn.type = 'button';
+        btn.className = `btn ghost btn-xs${danger ? ' danger' : ''}`;
+        btn.textContent = t(key);
+        btn.addEventListener('click', handler);
+        actions.appendChild(btn);
+      };
+      if (ex.status === 'pending' && ex.approver_id === state.userId) {
+        addBtn('findings.exceptions.approve', () => decideException(ex, true));
+        addBtn('findings.exceptions.reject', () => decideException(ex, false), true);
+      }
+      if ((ex.status === 'pending' || ex.status === 'approved') && hasPerm('findings.manage')) {
+        addBtn('findings.exceptions.revoke', () => revokeException(ex), true);
+      }
+      tbody.appendChild(tr);
+    });
+    fillUserSelect(document.getElementById('finding-exception-approver'), null, true);
+  }
+
+  async function requestException() {
+    const id = (document.getElementById('finding-id')?.value || '').trim();
+    const alert = document.getElementById('finding-modal-alert');
+    if (!id) return;
+    const payload = {
+      approver_id: parseInt(document.getElementById('finding-exception-approver')?.value || '0', 10) || 0,
+      expires_at: (document.getElementById('finding-exception-expires')?.value || '').trim(),
+      justification: (document.getElementById('finding-exception-justification')?.value || '').trim()
+    };
+    try {
+      await Api.post(`/api/findings/${id}/exceptions`, payload);
+      setVal('finding-exception-justification', '');
+      setVal('finding-exception-expires', '');
+      await loadExceptions(id);
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function decideException(ex, approve) {
+    const alert = document.getElementById('finding-modal-alert');
+    const comment = window.prompt(t(approve ? 'findings.exceptions.approveComment' : 'findings.exceptions.rejectComment')) ?? null;
+    if (comment === null) return;
+    try {
+      await Api.post(`/api/findings/exceptions/${ex.id}/${approve ? 'approve' : 'reject'}`, { comment });
+      await load();
+      await loadPendingExceptions();
+      await openFinding(ex.finding_id, 'view');
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function revokeException(ex) {
+    const alert = document.getElementById('finding-modal-alert');
+    const ok = await (window.AppConfirm?.ask
+      ? window.AppConfirm.ask(t('findings.exceptions.revokeConfirm'), {
+        title: t('common.confirm'),
+        confirmText: t('common.confirm'),
+        cancelText: t('common.cancel'),
+        danger: true,
+      })
+      : Promise.resolve(window.confirm(t('findings.exceptions.revokeConfirm'))));
+    if (!ok) return;
+    try {
+      await Api.del(`/api/findings/${ex.finding_id}/exceptions/${ex.id}`);
+      await load();
+      await openFinding(ex.finding_id, 'edit');
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
   function linkHref(type, id) {
     if (!type || !id) return '';
     const tt = String(type).toLowerCase();
@@ -568,6 +866,8 @@ const FindingsPage = (() => {
       config: t('findings.type.config'),
       process: t('findings.type.process'),
       compliance: t('findings.type.compliance'),
+      vulnerability: t('findings.type.vulnerability'),
+      eol: t('findings.type.eol'),
       other: t('findings.type.other')
     };
     return map[val] || val || '-';
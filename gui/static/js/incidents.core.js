@@ -8,6 +8,8 @@
     currentUser: null,
     dashboard: { metrics: { open: 0, in_progress: 0, closed: 0, critical: 0 }, mine: [], attention: [], recent: [] },
     filters: { status: '', severity: '', scope: 'all', period: 'all' },
+    listQuery: '',
+    searchBar: null,
     customIncidentTypes: [],
     customDetectionSources: []
   };
@@ -177,6 +177,7 @@
                   <div class="form-field">
                     <label>${t('incidents.form.affected')}</label>
                     <textarea id="incident-form-affected"></textarea>
+                    <button class="btn ghost btn-xs" id="incident-form-affected-fill">${t('incidents.form.affectedFromAssets')}</button>
                   </div>
                   <div class="form-field">
                     <label>${t('incidents.form.risks')}</label>
@@ -187,6 +188,7 @@
                     <textarea id="incident-form-actions"></textarea>
                   </div>
                 </div>
+                <div class="form-grid two-column" id="incident-form-custom-fields" hidden></div>
               </div>
             </div>
           </form>
@@ -211,6 +213,9 @@
       if (input.type !== 'time') input.inputMode = 'numeric';
     });
     if (IncidentsPage.bindCreateAttachments) IncidentsPage.bindCreateAttachments(tabId);
+    if (typeof CustomFieldsForm !== 'undefined') {
+      CustomFieldsForm.render(panel.querySelector('#incident-form-custom-fields'), 'incident', null, false);
+    }
     const saveBtn = panel.querySelector('#incident-form-save');
     const cancelBtn = panel.querySelector('#incident-form-cancel');
     const generateBtn = panel.querySelector('#incident-title-generate');
@@ -231,6 +236,7 @@
     const whatInput = panel.querySelector('#incident-form-what');
     const detectedInput = panel.querySelector('#incident-form-detected');
     const affectedInput = panel.querySelector('#incident-form-affected');
+    const affectedFillBtn = panel.querySelector('#incident-form-affected-fill');
     const riskInput = panel.querySelector('#incident-form-risk');
     const actionsInput = panel.querySelector('#incident-form-actions');
     const titleInput = panel.querySelector('#incident-form-title');
@@ -307,6 +313,27 @@
         titleInput.dispatchEvent(new Event('input', { bubbles: true }));
       });
     }
+    if (affectedFillBtn && affectedInput) {
+      affectedFillBtn.addEventListener('click', async (e) => {
+        e.preventDefault();
+        const names = (assetsInput?.value || '').split(',').map(v => v.trim()).filter(Boolean);
+        if (!names.length) {
+          showError(new Error(t('incidents.form.affectedNoAssets')), 'incidents.form.affectedNoAssets');
+          return;
+        }
+        try {
+          const affected = await blastRadiusSystems(names);
+          if (!affected.length) {
+            showError(new Error(t('incidents.form.affectedNotFound')), 'incidents.form.affectedNotFound');
+            return;
+          }
+          affectedInput.value = affected.join(', ');
+          affectedInput.dispatchEvent(new Event('input', { bubbles: true }));
+        } catch (err) {
+          showError(err, 'incidents.form.affectedNotFound');
+        }
+      });
+    }
     if (saveBtn) {
       saveBtn.addEventListener('click', async (e) => {
         e.preventDefault();
@@ -342,6 +369,9 @@
           const payload = { title, severity, description, participants, meta };
           if (owner) payload.owner = owner;
           if (assignee) payload.assignee = assignee;
+          if (typeof CustomFieldsForm !== 'undefined') {
+            payload.custom_fields = CustomFieldsForm.collect(panel.querySelector('#incident-form-custom-fields'));
+          }
           const res = await Api.post('/api/incidents', payload);
           const attachments = IncidentsPage.getCreateAttachments ? IncidentsPage.getCreateAttachments(tabId) : [];
           if (attachments.length && IncidentsPage.uploadCreateAttachment) {
@@ -372,6 +402,26 @@
     }
   }
 
+  // blastRadiusSystems resolves the asset names entered in the form and returns the
+  // names of every asset an outage of them affects, the assets themselves first.
+  async function blastRadiusSystems(names) {
+    const out = [];
+    const seen = new Set();
+    for (const name of names) {
+      const res = await Api.get(`/api/assets/list?limit=20&q=${encodeURIComponent(name)}`);
+      const items = Array.isArray(res?.items) ? res.items : [];
+      const match = items.find(a => (a.name || '').toLowerCase() === name.toLowerCase());
+      if (!match) continue;
+      const radius = await Api.get(`/api/assets/blast-radius?asset_id=${match.id}`);
+      (radius?.assets || []).forEach(a => {
+        if (seen.has(a.id)) return;
+        seen.add(a.id);
+        out.push(a.name);
+      });
+    }
+    return out;
+  }
+
   function hasFormData(panel) {
     const fields = panel.querySelectorAll('input, textarea, select');
     return Array.from(fields).some(field => {
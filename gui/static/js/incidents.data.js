@@ -13,11 +13,17 @@
 
   async function loadIncidents() {
     try {
-      const res = await Api.get('/api/incidents');
+      const query = state.listQuery ? `?query=${encodeURIComponent(state.listQuery)}` : '';
+      const res = await Api.get(`/api/incidents${query}`);
       state.incidents = (res.items || []).slice();
+      if (state.searchBar) state.searchBar.showError(null);
       if (IncidentsPage.renderHome) IncidentsPage.renderHome();
       if (IncidentsPage.renderTableRows) IncidentsPage.renderTableRows();
     } catch (err) {
+      if (state.listQuery && state.searchBar) {
+        state.searchBar.showError(err);
+        return;
+      }
       showError(err, 'incidents.forbidden');
     }
   }
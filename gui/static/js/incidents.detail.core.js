@@ -199,6 +199,12 @@
                 <button class="btn ghost incident-classification-save">${t('incidents.classification.save')}</button>
               </div>
             </div>
+            <div class="incident-meta incident-custom-fields" hidden>
+              <div class="form-grid two-column incident-custom-fields-list"></div>
+              <div class="meta-actions">
+                <button class="btn ghost incident-custom-fields-save">${t('customFields.save')}</button>
+              </div>
+            </div>
             <div class="incident-meta incident-overview">
               <div class="meta-field">
                 <label>${t('incidents.form.incidentType')}</label>
@@ -321,6 +327,7 @@
       postmortemBtn.addEventListener('click', () => savePostmortem(incidentId));
     }
     renderIncidentClassification(incidentId);
+    renderIncidentCustomFields(incidentId);
     renderIncidentPeople(incidentId);
     renderIncidentInnerTabs(incidentId);
     renderIncidentInnerContent(incidentId);
@@ -467,7 +474,7 @@
             </div>
             <div class="form-field">
               <label>${t('incidents.stage.blocks.timeline.message')}</label>
-              <textarea class="textarea incident-timeline-message" placeholder="${t('incidents.stage.blocks.timeline.messagePlaceholder')}"></textarea>
+              <textarea class="textarea incident-timeline-message" data-mentions placeholder="${t('incidents.stage.blocks.timeline.messagePlaceholder')}"></textarea>
             </div>
           </div>
           <div class="form-actions form-actions-inline">
@@ -626,6 +633,33 @@
     };
   }
 
+  async function renderIncidentCustomFields(incidentId) {
+    const tabId = `incident-${incidentId}`;
+    const panel = document.querySelector(`#incidents-panels [data-tab="${tabId}"]`);
+    const detail = state.incidentDetails.get(incidentId);
+    if (!panel || !detail || !detail.incident || typeof CustomFieldsForm === 'undefined') return;
+    const wrap = panel.querySelector('.incident-custom-fields');
+    const list = panel.querySelector('.incident-custom-fields-list');
+    const saveBtn = panel.querySelector('.incident-custom-fields-save');
+    if (!wrap || !list || !saveBtn) return;
+    await CustomFieldsForm.render(list, 'incident', detail.incident.custom_fields, detail.readOnly);
+    wrap.hidden = list.hidden;
+    saveBtn.disabled = !!detail.readOnly || !list.querySelector('[data-cf-key]:not([disabled])');
+    saveBtn.onclick = async () => {
+      try {
+        const res = await Api.put(`/api/incidents/${incidentId}`, {
+          custom_fields: CustomFieldsForm.collect(list),
+          version: detail.incident.version
+        });
+        detail.incident = res;
+        syncIncident(res);
+        renderIncidentCustomFields(incidentId);
+      } catch (err) {
+        showError(err, 'customFields.saveFailed');
+      }
+    };
+  }
+
   function buildPeopleState(incident, participants) {
     const ownerUser = incident?.owner_user_id && typeof UserDirectory !== 'undefined'
       ? UserDirectory.get(incident.owner_user_id)
@@ -50,15 +50,19 @@
     navigateToDocs();
   }
 
-  function openAssetInAssets(assetId) {
+  function openAssetInAssets(assetId, asOf) {
     const id = parseInt(assetId, 10);
     if (!id) return;
     if (window.AssetsPage && typeof window.AssetsPage.openAsset === 'function' && document.getElementById('assets-page')) {
-      window.AssetsPage.openAsset(id, 'view');
+      Promise.resolve(window.AssetsPage.openAsset(id, 'view')).then(() => {
+        if (asOf && typeof AssetsHistory !== 'undefined' && AssetsHistory.showAsOf) {
+          AssetsHistory.showAsOf(asOf);
+        }
+      });
     } else {
-      window.__pendingAssetOpen = id;
+      window.__pendingAssetOpen = asOf ? { id, asOf } : id;
     }
-    navigateToAssets(id);
+    navigateToAssets(id, asOf);
   }
 
   function navigateToDocs() {
@@ -67,9 +71,10 @@
     window.dispatchEvent(new PopStateEvent('popstate'));
   }
 
-  function navigateToAssets(assetId) {
+  function navigateToAssets(assetId, asOf) {
     const current = window.location.pathname;
-    const next = assetId ? `/assets?asset=${encodeURIComponent(assetId)}` : '/assets';
+    let next = assetId ? `/assets?asset=${encodeURIComponent(assetId)}` : '/assets';
+    if (assetId && asOf) next += `&as_of=${encodeURIComponent(asOf)}`;
     if (current === next) return;
     window.history.pushState({}, '', next);
     window.dispatchEvent(new PopStateEvent('popstate'));
@@ -161,6 +161,7 @@
       tbody.appendChild(tr);
       return;
     }
+    const detectedAt = detail.incident?.meta?.detected_at || '';
     detail.links.forEach(link => {
       const tr = document.createElement('tr');
       const status = link.unverified ? t('incidents.links.unverified') : t('incidents.links.verified');
@@ -174,6 +175,7 @@
         <td class="actions">
           ${link.entity_type === 'doc' || link.entity_type === 'report' ? `<button class="btn ghost link-open" data-id="${link.entity_id}">${t('incidents.links.openDoc')}</button>` : ''}
           ${link.entity_type === 'asset' ? `<button class="btn ghost link-open-asset" data-id="${link.entity_id}">${t('incidents.links.openAsset')}</button>` : ''}
+          ${link.entity_type === 'asset' && detectedAt ? `<button class="btn ghost link-asset-as-of" data-id="${link.entity_id}">${t('incidents.links.assetAtDetection')}</button>` : ''}
           ${link.entity_type === 'software' ? `<button class="btn ghost link-open-software" data-id="${link.entity_id}">${t('incidents.links.openSoftware')}</button>` : ''}
           <button class="btn ghost link-remove" data-id="${link.id}">${t('incidents.links.remove')}</button>
         </td>`;
@@ -186,6 +188,10 @@
       if (openAssetBtn) {
         openAssetBtn.onclick = () => IncidentsPage.openAssetInAssets(link.entity_id);
       }
+      const asOfBtn = tr.querySelector('.link-asset-as-of');
+      if (asOfBtn) {
+        asOfBtn.onclick = () => IncidentsPage.openAssetInAssets(link.entity_id, detectedAt);
+      }
       const openSoftwareBtn = tr.querySelector('.link-open-software');
       if (openSoftwareBtn) {
         openSoftwareBtn.onclick = () => {
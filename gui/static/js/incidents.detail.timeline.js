@@ -7,6 +7,7 @@
     'severity.change': { type: 'incidents.timeline.event.severity.change', message: 'incidents.timeline.message.severity.change' },
     'assignee.change': { type: 'incidents.timeline.event.assignee.change', message: 'incidents.timeline.message.assignee.change' },
     'classification.change': { type: 'incidents.timeline.event.classification.change', message: 'incidents.timeline.message.classification.change' },
+    'custom_fields.change': { type: 'incidents.timeline.event.custom_fields.change', message: 'incidents.timeline.message.custom_fields.change' },
     'owner.change': { type: 'incidents.timeline.event.owner.change', message: 'incidents.timeline.message.owner.change' },
     'incident.delete': { type: 'incidents.timeline.event.incident.delete', message: 'incidents.timeline.message.incident.delete' },
     'incident.restore': { type: 'incidents.timeline.event.incident.restore', message: 'incidents.timeline.message.incident.restore' },
@@ -192,7 +193,7 @@
         <div class="timeline-time">${escapeHtml(formatDate(ev.created_at))}</div>
         <div class="timeline-body">
           <div class="timeline-type">${escapeHtml(translateTimelineType(ev.event_type))}</div>
-          <div class="timeline-msg">${escapeHtml(translateTimelineMessage(ev.event_type, ev.message))}</div>
+          <div class="timeline-msg">${timelineMessageHtml(ev)}</div>
         </div>`;
       targetLists.forEach(list => list.appendChild(row.cloneNode(true)));
     });
@@ -208,6 +209,11 @@
     return eventType;
   }
 
+  function timelineMessageHtml(ev) {
+    const text = translateTimelineMessage(ev.event_type, ev.message);
+    return window.AppMentions ? AppMentions.html(text) : escapeHtml(text);
+  }
+
   function translateTimelineMessage(eventType, raw) {
     const tplKey = TIMELINE_TEMPLATES[eventType]?.message;
     let detail = cleanDetail(eventType, raw) || '';
@@ -48,6 +48,7 @@
               <option value="30d">${t('incidents.filters.period30d')}</option>
             </select>
           </div>
+          <div id="incident-saved-search"></div>
           <div class="table-responsive">
             <table class="data-table" id="incidents-table">
               <thead>
@@ -71,9 +72,22 @@
     if (createBtn) createBtn.addEventListener('click', () => IncidentsPage.openCreateTab());
     bindFilters();
     syncFilterControls();
+    mountSearchBar();
     renderTableRows();
   }
 
+  function mountSearchBar() {
+    state.searchBar = null;
+    if (typeof SavedSearchBar === 'undefined') return;
+    const bar = SavedSearchBar.mount(document.getElementById('incident-saved-search'), 'incident', () => {
+      state.listQuery = bar.query();
+      IncidentsPage.loadIncidents();
+    });
+    if (!bar) return;
+    bar.setQuery(state.listQuery);
+    state.searchBar = bar;
+  }
+
   function bindFilters() {
     const status = document.getElementById('incident-filter-status');
     const severity = document.getElementById('incident-filter-severity');
@@ -493,11 +493,12 @@
     if (action.startsWith('control.')) return 'controls';
     if (action.startsWith('assets.')) return 'assets';
     if (action.startsWith('finding.')) return 'findings';
+    if (action.startsWith('risk.')) return 'risks';
     if (action.startsWith('software.') || action.startsWith('assets.software.')) return 'software';
     if (action.startsWith('monitoring.')) return 'monitoring';
     if (action.startsWith('reports.') || action.startsWith('report.')) return 'reports';
     if (action.startsWith('backups.')) return 'backups';
-    if (action.startsWith('settings.')) return 'settings';
+    if (action.startsWith('settings.') || action.startsWith('custom_fields.')) return 'settings';
     if (['create_user', 'delete_user', 'copy_user', 'import_users'].includes(action)) return 'accounts';
     return 'other';
   }
@@ -514,6 +515,7 @@
     'tasks',
     'controls',
     'findings',
+    'risks',
     'assets',
     'software',
     'monitoring',
@@ -626,11 +628,17 @@
       'registry.tab.assets.view': 'Реестры: активы',
       'registry.tab.software.view': 'Реестры: ПО',
       'registry.tab.findings.view': 'Реестры: замечания',
+      'registry.tab.risks.view': 'Реестры: риски',
       'assets.create': 'Активы: создание',
       'assets.update': 'Активы: обновление',
       'assets.archive': 'Активы: архивирование',
       'assets.restore': 'Активы: восстановление',
       'assets.export.csv': 'Активы: экспорт CSV',
+      'assets.import.start': 'Активы: загрузка файла импорта',
+      'assets.import.commit': 'Активы: импорт',
+      'assets.import.nmap': 'Активы: импорт отчета Nmap',
+      'assets.relation.add': 'Активы: добавление связи',
+      'assets.relation.delete': 'Активы: удаление связи',
       'assets.software.view': 'ПО на активе: просмотр',
       'assets.software.add': 'ПО на активе: добавление',
       'assets.software.update': 'ПО на активе: обновление',
@@ -645,6 +653,8 @@
       'software.version.archive': 'ПО: архивирование версии',
       'software.version.restore': 'ПО: восстановление версии',
       'software.export.csv': 'ПО: экспорт CSV',
+      'software.import.start': 'ПО: загрузка файла импорта',
+      'software.import.commit': 'ПО: импорт',
       'finding.create': 'Находки: создание',
       'finding.update': 'Находки: обновление',
       'finding.archive': 'Находки: архивирование',
@@ -652,6 +662,25 @@
       'finding.link.add': 'Находки: добавление связи',
       'finding.link.remove': 'Находки: удаление связи',
       'finding.export.csv': 'Находки: экспорт CSV',
+      'finding.import.start': 'Находки: загрузка файла импорта',
+      'finding.import.commit': 'Находки: импорт',
+      'finding.import.scan': 'Находки: импорт отчета сканера',
+      'risk.create': 'Риски: создание',
+      'risk.update': 'Риски: обновление',
+      'risk.archive': 'Риски: архивирование',
+      'risk.restore': 'Риски: восстановление',
+      'risk.accept': 'Риски: принятие риска',
+      'risk.accept.revoke': 'Риски: отзыв принятия',
+      'risk.matrix.update': 'Риски: изменение матрицы',
+      'risk.link.add': 'Риски: добавление связи',
+      'risk.link.remove': 'Риски: удаление связи',
+      'risk.task.create': 'Риски: задача по обработке',
+      'custom_fields.create': 'Настройки: создание дополнительного поля',
+      'custom_fields.update': 'Настройки: изменение дополнительного поля',
+      'custom_fields.archive': 'Настройки: архивирование дополнительного поля',
+      'saved_searches.create': 'Поиск: создание сохранённого поиска',
+      'saved_searches.update': 'Поиск: изменение сохранённого поиска',
+      'saved_searches.delete': 'Поиск: удаление сохранённого поиска',
       'incident.create': 'Инциденты: создание',
       'incident.view': 'Инциденты: просмотр',
       'incident.update': 'Инциденты: обновление',
@@ -953,11 +982,17 @@
       'registry.tab.assets.view': 'Registries: assets',
       'registry.tab.software.view': 'Registries: software',
       'registry.tab.findings.view': 'Registries: remarks',
+      'registry.tab.risks.view': 'Registries: risks',
       'assets.create': 'Assets: create',
       'assets.update': 'Assets: update',
       'assets.archive': 'Assets: archive',
       'assets.restore': 'Assets: restore',
       'assets.export.csv': 'Assets: export CSV',
+      'assets.import.start': 'Assets: import file uploaded',
+      'assets.import.commit': 'Assets: import',
+      'assets.import.nmap': 'Assets: Nmap report import',
+      'assets.relation.add': 'Assets: add relation',
+      'assets.relation.delete': 'Assets: delete relation',
       'assets.software.view': 'Asset software: view',
       'assets.software.add': 'Asset software: add',
       'assets.software.update': 'Asset software: update',
@@ -972,6 +1007,8 @@
       'software.version.archive': 'Software: archive version',
       'software.version.restore': 'Software: restore version',
       'software.export.csv': 'Software: export CSV',
+      'software.import.start': 'Software: import file uploaded',
+      'software.import.commit': 'Software: import',
       'finding.create': 'Findings: create',
       'finding.update': 'Findings: update',
       'finding.archive': 'Findings: archive',
@@ -979,6 +1016,25 @@
       'finding.link.add': 'Findings: link added',
       'finding.link.remove': 'Findings: link removed',
       'finding.export.csv': 'Findings: export CSV',
+      'finding.import.start': 'Findings: import file uploaded',
+      'finding.import.commit': 'Findings: import',
+      'finding.import.scan': 'Findings: scanner report import',
+      'risk.create': 'Risks: create',
+      'risk.update': 'Risks: update',
+      'risk.archive': 'Risks: archive',
+      'risk.restore': 'Risks: restore',
+      'risk.accept': 'Risks: acceptance signed off',
+      'risk.accept.revoke': 'Risks: acceptance revoked',
+      'risk.matrix.update': 'Risks: matrix updated',
+      'risk.link.add': 'Risks: link added',
+      'risk.link.remove': 'Risks: link removed',
+      'risk.task.create': 'Risks: treatment task created',
+      'custom_fields.create': 'Settings: custom field created',
+      'custom_fields.update': 'Settings: custom field updated',
+      'custom_fields.archive': 'Settings: custom field archived',
+      'saved_searches.create': 'Search: saved search created',
+      'saved_searches.update': 'Search: saved search updated',
+      'saved_searches.delete': 'Search: saved search deleted',
       'incident.create': 'Incidents: create',
       'incident.view': 'Incidents: view',
       'incident.update': 'Incidents: update',
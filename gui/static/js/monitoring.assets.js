@@ -30,6 +30,11 @@
     els.search = document.getElementById('monitor-assets-search');
     els.optionsList = document.getElementById('monitor-assets-list');
     els.save = document.getElementById('monitor-assets-save');
+    els.blast = document.getElementById('monitor-assets-blast');
+    els.blastResult = document.getElementById('monitor-assets-blast-result');
+    if (els.blast) {
+      els.blast.addEventListener('click', () => showBlastRadius());
+    }
     if (els.edit) {
       els.edit.addEventListener('click', () => openModal());
     }
@@ -77,12 +82,59 @@
       els.edit.hidden = !canManage;
       els.edit.disabled = !canManage;
     }
+    if (els.blast) els.blast.hidden = !(state.linked || []).length;
+    if (els.blastResult) {
+      els.blastResult.hidden = true;
+      els.blastResult.innerHTML = '';
+    }
   }
 
   function hide() {
     if (els.wrap) els.wrap.hidden = true;
   }
 
+  // showBlastRadius lists the assets affected when the monitor is down: its own assets
+  // and every asset depending on them, with the open incidents and covering controls.
+  async function showBlastRadius() {
+    if (!els.blastResult || !state.monitorId) return;
+    els.blastResult.hidden = false;
+    els.blastResult.innerHTML = '';
+    let res;
+    try {
+      res = await Api.get(`/api/assets/blast-radius?monitor_id=${encodeURIComponent(state.monitorId)}&depth=3`);
+    } catch (err) {
+      els.blastResult.textContent = t((err && err.message ? err.message : '').trim() || 'common.error');
+      return;
+    }
+    const section = (titleKey, items, label) => {
+      const wrap = document.createElement('div');
+      const title = document.createElement('div');
+      title.className = 'muted';
+      title.textContent = t(titleKey);
+      wrap.appendChild(title);
+      const list = document.createElement('div');
+      list.className = 'monitor-detail-tags';
+      if (!items.length) {
+        const none = document.createElement('span');
+        none.className = 'muted';
+        none.textContent = t('assets.blast.none');
+        list.appendChild(none);
+      }
+      items.forEach((item) => {
+        const chip = document.createElement('span');
+        chip.className = 'monitor-item-tag';
+        chip.textContent = label(item);
+        chip.title = chip.textContent;
+        list.appendChild(chip);
+      });
+      wrap.appendChild(list);
+      els.blastResult.appendChild(wrap);
+    };
+    section('assets.blast.assets', res.assets || [], (a) => a.name || `#${a.id}`);
+    section('assets.blast.incidents', res.incidents || [], (i) => `${i.reg_no} ${i.title}`.trim());
+    section('assets.blast.controls', res.controls || [], (c) => `${c.code} ${c.title}`.trim());
+  }
+
   function renderLinked() {
     if (!els.list || !els.empty) return;
     els.list.innerHTML = '';
@@ -67,6 +67,10 @@
     els.weekdays = document.getElementById('maintenance-weekdays');
     els.monthdays = document.getElementById('maintenance-monthdays');
     els.lastDay = document.getElementById('maintenance-last-day');
+    els.rrule = document.getElementById('maintenance-rrule');
+    els.exdates = document.getElementById('maintenance-exdates');
+    els.shift = document.getElementById('maintenance-shift');
+    els.calendar = document.getElementById('maintenance-calendar');
   }
 
   async function loadMaintenance() {
@@ -154,7 +158,15 @@
       U?.markDays(els.weekdays, []);
       U?.markDays(els.monthdays, []);
       if (els.lastDay) els.lastDay.checked = false;
+      if (els.rrule) els.rrule.value = '';
+      if (els.exdates) els.exdates.value = '';
+      if (els.shift) els.shift.value = '';
+      if (els.calendar) {
+        els.calendar.dataset.value = '';
+        els.calendar.value = '';
+      }
     }
+    loadCalendars();
     renderMonitorHint();
     toggleStrategyFields();
     els.modal.hidden = false;
@@ -182,6 +194,34 @@
     U?.markDays(els.weekdays, schedule.weekdays || []);
     U?.markDays(els.monthdays, schedule.month_days || []);
     if (els.lastDay) els.lastDay.checked = !!schedule.use_last_day;
+    if (els.rrule) els.rrule.value = item.rrule_text || '';
+    if (els.exdates) els.exdates.value = (schedule.exdates || []).join(', ');
+    if (els.shift) els.shift.value = schedule.shift || '';
+    if (els.calendar) {
+      els.calendar.dataset.value = schedule.calendar_id ? `${schedule.calendar_id}` : '';
+      els.calendar.value = els.calendar.dataset.value;
+    }
+    if (els.strategy.value === 'rrule') {
+      els.startsAt.value = U?.toInputValue(item.starts_at) || '';
+      els.endsAt.value = U?.toInputValue(item.ends_at) || '';
+    }
+  }
+
+  async function loadCalendars() {
+    if (!els.calendar || els.calendar.dataset.loaded) return;
+    try {
+      const res = await Api.get('/api/schedule/calendars');
+      (res.items || []).forEach((cal) => {
+        const opt = document.createElement('option');
+        opt.value = `${cal.id}`;
+        opt.textContent = cal.name;
+        els.calendar.appendChild(opt);
+      });
+      els.calendar.dataset.loaded = '1';
+      els.calendar.value = els.calendar.dataset.value || '';
+    } catch (_) {
+      // Without calendars only the weekend counts as non-business days.
+    }
   }
 
   async function submitForm() {
@@ -223,6 +263,17 @@
       payload.ends_at = rangeEnd;
       return payload;
     }
+    if (strategy === 'rrule') {
+      payload.starts_at = rangeStart;
+      payload.ends_at = rangeEnd;
+      payload.is_recurring = true;
+      payload.rrule_text = (els.rrule?.value || '').trim();
+      if (!payload.rrule_text) return modalError('monitoring.error.invalidRRule');
+      payload.schedule.exdates = (els.exdates?.value || '').split(',').map((v) => v.trim()).filter(Boolean);
+      payload.schedule.shift = els.shift?.value || '';
+      payload.schedule.calendar_id = parseInt(els.calendar?.value || '0', 10) || 0;
+      return payload;
+    }
     payload.schedule.active_from = rangeStart;
     payload.schedule.active_until = rangeEnd;
     if (strategy === 'cron') {
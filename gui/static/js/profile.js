@@ -16,6 +16,9 @@ const ProfilePage = (() => {
     if (window.SettingsPasskeys?.bind) {
       window.SettingsPasskeys.bind(alertBox);
     }
+    if (window.SettingsNotifications?.bind) {
+      window.SettingsNotifications.bind(alertBox);
+    }
   }
 
   function bindPasswordChange(alertBox) {
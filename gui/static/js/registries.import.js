@@ -0,0 +1,401 @@
+const RegistryImport = (() => {
+  const MODULES = {
+    assets: {
+      permission: 'assets.manage',
+      title: 'assets.import.title',
+      base: '/api/assets/import',
+      fields: [
+        { key: 'name', label: 'assets.field.name', required: true, match: ['name', 'hostname', 'host', 'asset'] },
+        { key: 'type', label: 'assets.field.type', match: ['type', 'asset_type'] },
+        { key: 'description', label: 'assets.field.description', match: ['description', 'notes', 'comment'] },
+        { key: 'ip_addresses', label: 'assets.field.ip', match: ['ip_addresses', 'ip', 'ips', 'address', 'addresses'] },
+        { key: 'criticality', label: 'assets.field.criticality', match: ['criticality', 'priority'] },
+        { key: 'owner', label: 'assets.field.owner', match: ['owner'] },
+        { key: 'administrator', label: 'assets.field.admin', match: ['administrator', 'admin'] },
+        { key: 'env', label: 'assets.field.env', match: ['env', 'environment'] },
+        { key: 'status', label: 'assets.field.status', match: ['status', 'state'] },
+        { key: 'tags', label: 'assets.field.tags', match: ['tags', 'tag'] },
+        { key: 'commissioned_at', label: 'assets.field.commissionedAt', match: ['commissioned_at', 'commissioned'] }
+      ],
+      scan: { url: '/api/assets/import/nmap', label: 'import.scan.nmap', accept: '.xml' }
+    },
+    findings: {
+      permission: 'findings.manage',
+      title: 'findings.import.title',
+      base: '/api/findings/import',
+      fields: [
+        { key: 'title', label: 'findings.field.title', required: true, match: ['title', 'name', 'finding'] },
+        { key: 'description_md', label: 'findings.field.description', match: ['description_md', 'description', 'details'] },
+        { key: 'status', label: 'findings.field.status', match: ['status', 'state'] },
+        { key: 'severity', label: 'findings.field.severity', match: ['severity', 'risk'] },
+        { key: 'type', label: 'findings.field.type', match: ['type', 'finding_type', 'category'] },
+        { key: 'owner', label: 'findings.field.owner', match: ['owner', 'assignee'] },
+        { key: 'due_at', label: 'findings.field.dueAt', match: ['due_at', 'due', 'deadline'] },
+        { key: 'tags', label: 'findings.field.tags', match: ['tags', 'tag'] },
+        { key: 'asset', label: 'import.field.asset', match: ['asset', 'host', 'hostname', 'ip'] }
+      ],
+      scan: { url: '/api/findings/import/scan', label: 'import.scan.vuln', accept: '.nessus,.xml' }
+    },
+    software: {
+      permission: 'software.manage',
+      title: 'software.import.title',
+      base: '/api/software/import',
+      fields: [
+        { key: 'name', label: 'software.field.name', required: true, match: ['name', 'product', 'software'] },
+        { key: 'vendor', label: 'software.field.vendor', match: ['vendor', 'publisher', 'manufacturer'] },
+        { key: 'description', label: 'software.field.description', match: ['description', 'notes'] },
+        { key: 'tags', label: 'software.field.tags', match: ['tags', 'tag'] },
+        { key: 'versions', label: 'software.versions.title', match: ['versions', 'version'] }
+      ]
+    }
+  };
+
+  let state = { module: null, importId: '', headers: [], scanFile: null, onDone: null };
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHtml(str) {
+    return String(str ?? '')
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  async function loadPerms() {
+    try {
+      const me = await Api.get('/api/auth/me');
+      return Array.isArray(me?.user?.permissions) ? me.user.permissions : [];
+    } catch (_) {
+      return [];
+    }
+  }
+
+  function hasPerm(perms, perm) {
+    if (!perm) return true;
+    if (!Array.isArray(perms) || !perms.length) return true;
+    return perms.includes(perm);
+  }
+
+  function showAlert(msg) {
+    const el = document.getElementById('registry-import-alert');
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+  }
+
+  function errorText(err) {
+    return (err && err.message) ? err.message : t('common.error');
+  }
+
+  function ensureModal() {
+    let modal = document.getElementById('registry-import-modal');
+    if (modal) return modal;
+    modal = document.createElement('div');
+    modal.className = 'modal';
+    modal.id = 'registry-import-modal';
+    modal.hidden = true;
+    modal.innerHTML = `
+      <div class="modal-backdrop"></div>
+      <div class="modal-body wide">
+        <div class="modal-header">
+          <h3 id="registry-import-title"></h3>
+          <button class="btn ghost" data-close="#registry-import-modal" aria-label="Close">x</button>
+        </div>
+        <div class="modal-content">
+          <div class="alert" id="registry-import-alert" hidden></div>
+          <div class="form-grid">
+            <div class="form-field">
+              <label>${escapeHtml(t('import.file'))}</label>
+              <input type="file" id="registry-import-file" class="input" accept=".csv,.xlsx,.txt">
+              <div class="muted">${escapeHtml(t('import.fileHint'))}</div>
+            </div>
+            <div class="form-field" id="registry-import-scan-field" hidden>
+              <label id="registry-import-scan-label"></label>
+              <input type="file" id="registry-import-scan-file" class="input">
+            </div>
+          </div>
+          <div id="registry-import-mapping" hidden>
+            <h4>${escapeHtml(t('import.mapping'))}</h4>
+            <div class="form-grid" id="registry-import-mapping-fields"></div>
+            <div id="registry-import-preview"></div>
+          </div>
+          <div id="registry-import-result" hidden></div>
+          <div class="modal-actions">
+            <button class="btn ghost" id="registry-import-check" hidden>${escapeHtml(t('import.check'))}</button>
+            <button class="btn primary" id="registry-import-commit" hidden>${escapeHtml(t('import.commit'))}</button>
+            <button class="btn ghost" data-close="#registry-import-modal">${escapeHtml(t('common.close'))}</button>
+          </div>
+        </div>
+      </div>
+    `;
+    document.body.appendChild(modal);
+    document.getElementById('registry-import-file')?.addEventListener('change', (e) => uploadTable(e.target.files));
+    document.getElementById('registry-import-scan-file')?.addEventListener('change', (e) => previewScan(e.target.files));
+    document.getElementById('registry-import-check')?.addEventListener('click', () => submit(true));
+    document.getElementById('registry-import-commit')?.addEventListener('click', () => submit(false));
+    return modal;
+  }
+
+  function reset(moduleKey) {
+    const cfg = MODULES[moduleKey];
+    state = { module: moduleKey, importId: '', headers: [], scanFile: null, onDone: state.onDone };
+    const titleEl = document.getElementById('registry-import-title');
+    if (titleEl) titleEl.textContent = t(cfg.title);
+    ['registry-import-file', 'registry-import-scan-file'].forEach(id => {
+      const el = document.getElementById(id);
+      if (el) el.value = '';
+    });
+    const scanField = document.getElementById('registry-import-scan-field');
+    if (scanField) scanField.hidden = !cfg.scan;
+    if (cfg.scan) {
+      const label = document.getElementById('registry-import-scan-label');
+      if (label) label.textContent = t(cfg.scan.label);
+      const input = document.getElementById('registry-import-scan-file');
+      if (input) input.accept = cfg.scan.accept;
+    }
+    document.getElementById('registry-import-mapping').hidden = true;
+    document.getElementById('registry-import-result').hidden = true;
+    document.getElementById('registry-import-check').hidden = true;
+    document.getElementById('registry-import-commit').hidden = true;
+    showAlert('');
+  }
+
+  function autoSelectHeader(headers, candidates) {
+    const normalized = (headers || []).map(h => h.toLowerCase());
+    for (const cand of candidates) {
+      const cleanCand = cand.replace(/[^a-z0-9]/g, '');
+      const idx = normalized.findIndex(h => h === cand || h.replace(/[^a-z0-9]/g, '') === cleanCand);
+      if (idx >= 0) return headers[idx];
+    }
+    return '';
+  }
+
+  function renderMapping(headers) {
+    const cfg = MODULES[state.module];
+    const box = document.getElementById('registry-import-mapping-fields');
+    if (!box) return;
+    const options = [`<option value="">${escapeHtml(t('import.notMapped'))}</option>`]
+      .concat(headers.map(h => `<option value="${escapeHtml(h)}">${escapeHtml(h)}</option>`))
+      .join('');
+    box.innerHTML = cfg.fields.map(f => `
+      <div class="form-field">
+        <label>${escapeHtml(t(f.label))}${f.required ? ' *' : ''}</label>
+        <select class="select" data-import-field="${escapeHtml(f.key)}">${options}</select>
+      </div>
+    `).join('');
+    cfg.fields.forEach(f => {
+      const el = box.querySelector(`[data-import-field="${f.key}"]`);
+      const auto = autoSelectHeader(headers, f.match);
+      if (el && auto) el.value = auto;
+    });
+  }
+
+  function renderPreview(headers, rows, total) {
+    const container = document.getElementById('registry-import-preview');
+    if (!container) return;
+    let html = `<h4>${escapeHtml(t('import.preview'))} (${total})</h4><div class="table-responsive"><table class="data-table"><thead><tr>`;
+    headers.forEach(h => { html += `<th>${escapeHtml(h)}</th>`; });
+    html += '</tr></thead><tbody>';
+    (rows || []).forEach(r => {
+      html += '<tr>' + headers.map((_, idx) => `<td>${escapeHtml((r && r[idx]) || '')}</td>`).join('') + '</tr>';
+    });
+    if (!rows || !rows.length) {
+      html += `<tr><td colspan="${headers.length}">${escapeHtml(t('common.notAvailable'))}</td></tr>`;
+    }
+    html += '</tbody></table></div>';
+    container.innerHTML = html;
+  }
+
+  function collectMapping() {
+    const mapping = {};
+    document.querySelectorAll('#registry-import-mapping-fields [data-import-field]').forEach(el => {
+      mapping[el.dataset.importField] = el.value || '';
+    });
+    return mapping;
+  }
+
+  async function uploadTable(files) {
+    const file = files && files[0];
+    if (!file) return;
+    const cfg = MODULES[state.module];
+    const fd = new FormData();
+    fd.append('file', file);
+    showAlert('');
+    try {
+      const res = await Api.upload(`${cfg.base}/upload`, fd);
+      state.importId = res.import_id;
+      state.headers = res.detected_headers || [];
+      state.scanFile = null;
+      renderMapping(state.headers);
+      renderPreview(state.headers, res.preview_rows || [], res.total_rows || 0);
+      document.getElementById('registry-import-mapping').hidden = false;
+      document.getElementById('registry-import-result').hidden = true;
+      document.getElementById('registry-import-check').hidden = false;
+      document.getElementById('registry-import-commit').hidden = false;
+    } catch (err) {
+      showAlert(errorText(err));
+    }
+  }
+
+  async function submit(dryRun) {
+    if (state.scanFile) {
+      await applyScan();
+      return;
+    }
+    const cfg = MODULES[state.module];
+    if (!state.importId) {
+      showAlert(t('import.fileRequired'));
+      return;
+    }
+    const mapping = collectMapping();
+    const missing = cfg.fields.filter(f => f.required && !mapping[f.key]);
+    if (missing.length) {
+      showAlert(t('import.mappingRequired'));
+      return;
+    }
+    showAlert('');
+    try {
+      const res = await Api.post(`${cfg.base}/commit`, { import_id: state.importId, mapping, dry_run: dryRun });
+      renderReport(res);
+      if (!dryRun) {
+        state.importId = '';
+        document.getElementById('registry-import-check').hidden = true;
+        document.getElementById('registry-import-commit').hidden = true;
+        if (typeof state.onDone === 'function') await state.onDone();
+      }
+    } catch (err) {
+      showAlert(errorText(err));
+    }
+  }
+
+  function reasonLabel(failure) {
+    const key = `import.reason.${failure.reason || 'invalid_value'}`;
+    let text = t(key);
+    if (text === key) text = failure.reason || '';
+    if (failure.detail) text += ` (${failure.detail})`;
+    return text;
+  }
+
+  function renderReport(res) {
+    const box = document.getElementById('registry-import-result');
+    if (!box) return;
+    const title = res.dry_run ? t('import.result.dryRun') : t('import.result.title');
+    let html = `<h4>${escapeHtml(title)}</h4><div class="muted">`
+      + `${escapeHtml(t('import.result.total'))}: ${res.total_rows || 0} · `
+      + `${escapeHtml(t('import.result.created'))}: ${res.created_count || 0} · `
+      + `${escapeHtml(t('import.result.updated'))}: ${res.updated_count || 0} · `
+      + `${escapeHtml(t('import.result.failed'))}: ${res.failed_count || 0}</div>`;
+    const failures = Array.isArray(res.failures) ? res.failures : [];
+    if (failures.length) {
+      html += `<div class="table-responsive"><table class="data-table"><thead><tr><th>${escapeHtml(t('import.result.row'))}</th><th>${escapeHtml(t('import.result.reason'))}</th></tr></thead><tbody>`;
+      failures.forEach(f => {
+        html += `<tr><td>${escapeHtml(f.row_number || '')}</td><td>${escapeHtml(reasonLabel(f))}</td></tr>`;
+      });
+      html += '</tbody></table></div>';
+    }
+    box.innerHTML = html;
+    box.hidden = false;
+  }
+
+  async function previewScan(files) {
+    const file = files && files[0];
+    if (!file) return;
+    const cfg = MODULES[state.module];
+    const fd = new FormData();
+    fd.append('file', file);
+    fd.append('preview', '1');
+    showAlert('');
+    try {
+      const plan = await Api.upload(cfg.scan.url, fd);
+      state.scanFile = file;
+      state.importId = '';
+      document.getElementById('registry-import-mapping').hidden = true;
+      document.getElementById('registry-import-check').hidden = true;
+      document.getElementById('registry-import-commit').hidden = false;
+      renderPlan(plan);
+    } catch (err) {
+      showAlert(errorText(err));
+    }
+  }
+
+  async function applyScan() {
+    const cfg = MODULES[state.module];
+    const fd = new FormData();
+    fd.append('file', state.scanFile);
+    showAlert('');
+    try {
+      const plan = await Api.upload(cfg.scan.url, fd);
+      state.scanFile = null;
+      document.getElementById('registry-import-commit').hidden = true;
+      renderPlan(plan);
+      if (typeof state.onDone === 'function') await state.onDone();
+    } catch (err) {
+      showAlert(errorText(err));
+    }
+  }
+
+  function renderPlan(plan) {
+    const box = document.getElementById('registry-import-result');
+    if (!box) return;
+    const items = Array.isArray(plan?.items) ? plan.items : [];
+    const title = plan?.applied ? t('import.result.title') : t('import.result.plan');
+    let html = `<h4>${escapeHtml(title)}</h4><div class="muted">`
+      + `${escapeHtml(t('import.result.created'))}: ${plan?.added || 0} · `
+      + `${escapeHtml(t('import.result.updated'))}: ${plan?.updated || 0}`;
+    if (plan?.unchanged != null) html += ` · ${escapeHtml(t('import.result.unchanged'))}: ${plan.unchanged || 0}`;
+    if (plan?.new_assets) html += ` · ${escapeHtml(t('import.result.newAssets'))}: ${plan.new_assets}`;
+    if (plan?.informational) html += ` · ${escapeHtml(t('import.result.informational'))}: ${plan.informational}`;
+    html += '</div>';
+    if (items.length) {
+      const isFindings = state.module === 'findings';
+      html += '<div class="table-responsive"><table class="data-table"><thead><tr>'
+        + `<th>${escapeHtml(t('import.result.action'))}</th>`
+        + `<th>${escapeHtml(isFindings ? t('findings.field.title') : t('assets.field.name'))}</th>`
+        + (isFindings ? `<th>${escapeHtml(t('findings.field.severity'))}</th><th>${escapeHtml(t('import.field.asset'))}</th>` : `<th>${escapeHtml(t('assets.field.ip'))}</th>`)
+        + `<th>${escapeHtml(t('import.result.ports'))}</th></tr></thead><tbody>`;
+      items.forEach(item => {
+        const action = t(`import.plan.${item.action}`);
+        const ports = (Array.isArray(item.ports) ? item.ports : [])
+          .map(p => (typeof p === 'string' ? p : `${p.port}/${p.protocol}${p.service ? ' ' + p.service : ''}`))
+          .join(', ');
+        html += `<tr><td>${escapeHtml(action)}</td>`;
+        if (isFindings) {
+          html += `<td>${escapeHtml(item.title)}</td><td>${escapeHtml(t(`findings.severity.${item.severity}`))}</td><td>${escapeHtml(item.asset_name)}</td>`;
+        } else {
+          html += `<td>${escapeHtml(item.name)}</td><td>${escapeHtml((item.addresses || []).join(', '))}</td>`;
+        }
+        html += `<td>${escapeHtml(ports)}</td></tr>`;
+      });
+      html += '</tbody></table></div>';
+    }
+    box.innerHTML = html;
+    box.hidden = false;
+  }
+
+  async function bind(buttonId, moduleKey, onDone) {
+    const btn = document.getElementById(buttonId);
+    const cfg = MODULES[moduleKey];
+    if (!btn || !cfg) return;
+    const perms = await loadPerms();
+    const allowed = hasPerm(perms, cfg.permission);
+    btn.hidden = !allowed;
+    btn.disabled = !allowed;
+    if (!allowed) return;
+    btn.onclick = () => {
+      const modal = ensureModal();
+      state.onDone = onDone;
+      reset(moduleKey);
+      modal.hidden = false;
+    };
+  }
+
+  return { bind };
+})();
+
+if (typeof window !== 'undefined') {
+  window.RegistryImport = RegistryImport;
+}
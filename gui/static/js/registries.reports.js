@@ -187,6 +187,42 @@ const RegistryReports = (() => {
     return lines.join('\n');
   }
 
+  async function buildRisksMarkdown(items, filters) {
+    const title = titleFor('risks', t('risks.title'));
+    const subtitle = t('risks.subtitle');
+    const lines = [
+      `# ${escapeMd(title)}`,
+      subtitle ? `_${escapeMd(subtitle)}_` : '',
+      filters ? `_${escapeMd(filters)}_` : '',
+      ''
+    ].filter(Boolean);
+    if (!Array.isArray(items) || !items.length) {
+      lines.push(escapeMd(t('risks.empty') || '-'));
+      return lines.join('\n');
+    }
+    if (typeof UserDirectory !== 'undefined' && UserDirectory.load) await UserDirectory.load();
+    const headers = [
+      t('risks.table.title'),
+      t('risks.table.owner'),
+      t('risks.table.inherent'),
+      t('risks.table.residual'),
+      t('risks.table.treatment'),
+      t('risks.table.status'),
+      t('risks.table.acceptance')
+    ];
+    const rows = items.map(r => ([
+      r?.title || '',
+      r?.owner_id && typeof UserDirectory !== 'undefined' ? UserDirectory.name(r.owner_id) : '-',
+      `${r?.inherent_score ?? '-'} (${r?.inherent_level || '-'})`,
+      `${r?.residual_score ?? '-'} (${r?.residual_level || '-'})`,
+      t(`risks.treatment.${(r?.treatment || 'mitigate').toString().toLowerCase()}`),
+      t(`risks.status.${(r?.status || 'identified').toString().toLowerCase()}`),
+      r?.acceptance_expires_at ? (typeof AppTime !== 'undefined' && AppTime.formatDate ? AppTime.formatDate(r.acceptance_expires_at) : r.acceptance_expires_at) : '-'
+    ]));
+    lines.push(mdTable(headers, rows));
+    return lines.join('\n');
+  }
+
   function filtersSummary(pairs) {
     const items = (pairs || []).filter(p => p && p[0] && p[1]);
     if (!items.length) return '';
@@ -275,6 +311,32 @@ const RegistryReports = (() => {
     await createReportDoc(titleFor('findings', t('findings.title')), content);
   }
 
+  async function createRisksReport() {
+    const perms = await loadPerms();
+    if (!requireReportPerms(perms)) return;
+    const canManage = hasPerm(perms, 'risks.manage');
+    const val = (id) => (document.getElementById(id)?.value || '').toString().trim();
+    const includeDeleted = canManage && val('risks-filter-include-deleted') === '1';
+    const url = new URL('/api/risks', window.location.origin);
+    if (val('risks-filter-q')) url.searchParams.set('q', val('risks-filter-q'));
+    if (val('risks-filter-status')) url.searchParams.set('status', val('risks-filter-status'));
+    if (val('risks-filter-treatment')) url.searchParams.set('treatment', val('risks-filter-treatment'));
+    if (val('risks-filter-level')) url.searchParams.set('level', val('risks-filter-level'));
+    if (includeDeleted) url.searchParams.set('include_deleted', '1');
+    url.searchParams.set('limit', '200');
+    const data = await Api.get(url.pathname + url.search);
+    const items = Array.isArray(data?.items) ? data.items : [];
+    const filters = filtersSummary([
+      [t('risks.filter.search'), val('risks-filter-q')],
+      [t('risks.filter.status'), val('risks-filter-status')],
+      [t('risks.filter.treatment'), val('risks-filter-treatment')],
+      [t('risks.filter.level'), val('risks-filter-level')],
+      [t('risks.filter.archived'), includeDeleted ? t('risks.filter.includeArchived') : '']
+    ]);
+    const content = await buildRisksMarkdown(items, filters);
+    await createReportDoc(titleFor('risks', t('risks.title')), content);
+  }
+
   async function bind(buttonId, moduleKey) {
     const btn = document.getElementById(buttonId);
     if (!btn) return;
@@ -289,6 +351,7 @@ const RegistryReports = (() => {
         if (moduleKey === 'assets') await createAssetsReport();
         if (moduleKey === 'software') await createSoftwareReport();
         if (moduleKey === 'findings') await createFindingsReport();
+        if (moduleKey === 'risks') await createRisksReport();
       } catch (err) {
         alert((err && err.message) ? err.message : t('common.error'));
       } finally {
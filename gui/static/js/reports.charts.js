@@ -4,16 +4,44 @@
     { type: 'incidents_severity_bar', section: 'incidents', titleKey: 'reports.charts.incidentsSeverity' },
     { type: 'incidents_status_bar', section: 'incidents', titleKey: 'reports.charts.incidentsStatus' },
     { type: 'incidents_weekly_line', section: 'incidents', titleKey: 'reports.charts.incidentsWeekly', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'incidents_response_bar', section: 'incidents', titleKey: 'reports.charts.incidentsResponse' },
+    { type: 'incidents_mttr_severity_bar', section: 'incidents', titleKey: 'reports.charts.incidentsMTTRSeverity' },
+    { type: 'incidents_mttr_weekly_line', section: 'incidents', titleKey: 'reports.charts.incidentsMTTRWeekly', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'incidents_severity_weekly_stacked', section: 'incidents', titleKey: 'reports.charts.incidentsSeverityWeekly', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'incidents_severity_weekly_line', section: 'incidents', titleKey: 'reports.charts.incidentsSeverityTrend', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'incidents_severity_pie', section: 'incidents', titleKey: 'reports.charts.incidentsSeverityShare' },
+    { type: 'incidents_weekday_hour_heatmap', section: 'incidents', titleKey: 'reports.charts.incidentsWeekdayHour' },
     { type: 'tasks_status_bar', section: 'tasks', titleKey: 'reports.charts.tasksStatus' },
     { type: 'tasks_weekly_line', section: 'tasks', titleKey: 'reports.charts.tasksWeekly', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
     { type: 'docs_approvals_bar', section: 'docs', titleKey: 'reports.charts.docsApprovals' },
     { type: 'docs_weekly_line', section: 'docs', titleKey: 'reports.charts.docsWeekly', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
     { type: 'controls_status_bar', section: 'controls', titleKey: 'reports.charts.controlsStatus' },
+    { type: 'controls_status_donut', section: 'controls', titleKey: 'reports.charts.controlsStatusShare' },
     { type: 'controls_domains_bar', section: 'controls', titleKey: 'reports.charts.controlsDomains', config: { key: 'top_n', labelKey: 'reports.charts.config.topN', min: 3, max: 12 } },
     { type: 'monitoring_uptime_bar', section: 'monitoring', titleKey: 'reports.charts.monitoringUptime', config: { key: 'top_n', labelKey: 'reports.charts.config.topN', min: 3, max: 12 } },
     { type: 'monitoring_downtime_line', section: 'monitoring', titleKey: 'reports.charts.monitoringDowntime', config: { key: 'days', labelKey: 'reports.charts.config.days', min: 7, max: 31 } },
-    { type: 'monitoring_tls_bar', section: 'monitoring', titleKey: 'reports.charts.monitoringTLS' }
+    { type: 'monitoring_tls_bar', section: 'monitoring', titleKey: 'reports.charts.monitoringTLS' },
+    { type: 'software_eol_bar', section: 'software_eol', titleKey: 'reports.charts.softwareEol' },
+    { type: 'risks_level_bar', section: 'risks', titleKey: 'reports.charts.risksLevel' },
+    { type: 'risks_matrix_heatmap', section: 'risks', titleKey: 'reports.charts.risksMatrix' },
+    { type: 'findings_ageing_bar', section: 'findings', titleKey: 'reports.charts.findingsAgeing' },
+    { type: 'findings_burndown_line', section: 'findings', titleKey: 'reports.charts.findingsBurndown', config: { key: 'days', labelKey: 'reports.charts.config.days', min: 7, max: 31 } },
+    { type: 'findings_severity_bar', section: 'findings', titleKey: 'reports.charts.findingsSeverity' },
+    { type: 'findings_status_bar', section: 'findings', titleKey: 'reports.charts.findingsStatus' },
+    { type: 'assets_criticality_bar', section: 'assets', titleKey: 'reports.charts.assetsCriticality' },
+    { type: 'assets_env_bar', section: 'assets', titleKey: 'reports.charts.assetsEnv' },
+    { type: 'assets_type_bar', section: 'assets', titleKey: 'reports.charts.assetsType' },
+    { type: 'software_inventory_bar', section: 'software', titleKey: 'reports.charts.softwareInventory', config: { key: 'top_n', labelKey: 'reports.charts.config.topN', min: 3, max: 12 } },
+    { type: 'framework_coverage_bar', section: 'framework_coverage', titleKey: 'reports.charts.frameworkCoverage' },
+    { type: 'effort_bar', section: 'effort', titleKey: 'reports.charts.effort', config: { key: 'top_n', labelKey: 'reports.charts.config.topN', min: 3, max: 12 } },
+    { type: 'tasks_flow_cfd_area', section: 'task_flow', titleKey: 'reports.charts.flowCfd' },
+    { type: 'tasks_flow_cycle_bar', section: 'task_flow', titleKey: 'reports.charts.flowCycle' },
+    { type: 'tasks_flow_lead_bar', section: 'task_flow', titleKey: 'reports.charts.flowLead' },
+    { type: 'tasks_flow_throughput_line', section: 'task_flow', titleKey: 'reports.charts.flowThroughput', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'tasks_flow_wip_line', section: 'task_flow', titleKey: 'reports.charts.flowWip', config: { key: 'weeks', labelKey: 'reports.charts.config.weeks', min: 4, max: 16 } },
+    { type: 'tasks_flow_ageing_bar', section: 'task_flow', titleKey: 'reports.charts.flowAgeing' }
   ];
+  const CHART_PALETTES = ['default', 'severity', 'traffic', 'mono', 'heat', 'blues'];
 
   function bindCharts() {
     const saveBtn = document.getElementById('report-charts-save');
@@ -53,6 +81,15 @@
           <label>${t(def.config.labelKey)}</label>
           <input type="number" class="input" data-config="${def.config.key}" min="${def.config.min}" max="${def.config.max}" value="${escapeAttr(ch.config?.[def.config.key] ?? '')}">
         </div>` : '';
+      const palette = ch.config?.palette || '';
+      const paletteSelect = `
+        <div class="form-field">
+          <label>${t('reports.charts.palette')}</label>
+          <select class="select" data-palette>
+            <option value="">${t('reports.charts.palette.auto')}</option>
+            ${CHART_PALETTES.map(p => `<option value="${p}" ${p === palette ? 'selected' : ''}>${t(`reports.charts.palette.${p}`)}</option>`).join('')}
+          </select>
+        </div>`;
       const card = document.createElement('div');
       card.className = 'report-chart-card';
       card.dataset.type = ch.chart_type;
@@ -70,6 +107,7 @@
           </div>
         </div>
         ${configInput}
+        ${paletteSelect}
       `;
       card.querySelectorAll('button[data-action]').forEach(btn => {
         btn.onclick = () => moveChart(card, btn.dataset.action);
@@ -101,7 +139,7 @@
       const card = document.createElement('div');
       card.className = 'report-chart-preview-card';
       card.innerHTML = `
-        <div class="muted">${escapeHtml(title)}</div>
+        <div class="muted">${escapeHtml(title)} · <a href="/api/reports/${reportId}/charts/${ch.id}/render?format=png" download="chart_${ch.id}.png">${t('reports.charts.downloadPng')}</a></div>
         <img src="/api/reports/${reportId}/charts/${ch.id}/render?ts=${Date.now()}" alt="${escapeAttr(title)}">`;
       preview.appendChild(card);
     });
@@ -115,7 +153,14 @@
         out.push(existing);
       }
     });
-    return out.length ? out : list;
+    if (!out.length) return list;
+    // chart types added after the report was created are offered disabled
+    CHART_DEFS.forEach(def => {
+      if (!list.some(c => c.chart_type === def.type)) {
+        out.push({ chart_type: def.type, section_type: def.section, config: {}, is_enabled: false });
+      }
+    });
+    return out;
   }
 
   function moveChart(card, dir) {
@@ -155,6 +200,10 @@
           config[key] = val;
         }
       });
+      const palette = card.querySelector('[data-palette]')?.value || '';
+      if (palette) {
+        config.palette = palette;
+      }
       const def = CHART_DEFS.find(d => d.type === type);
       charts.push({
         id,
@@ -52,6 +52,7 @@ const ReportsPage = (() => {
     if (ReportsPage.bindSections) ReportsPage.bindSections();
     if (ReportsPage.bindCharts) ReportsPage.bindCharts();
     if (ReportsPage.bindTemplates) ReportsPage.bindTemplates();
+    if (ReportsPage.bindSchedules) ReportsPage.bindSchedules();
     if (ReportsPage.bindSettings) ReportsPage.bindSettings();
     bindModalClose();
     document.querySelectorAll('#reports-page input[type="date"]').forEach(input => {
@@ -66,6 +67,7 @@ const ReportsPage = (() => {
     await ReportsPage.loadTemplates();
     await ReportsPage.loadSettings();
     await ReportsPage.loadReports();
+    if (ReportsPage.loadSchedules) await ReportsPage.loadSchedules();
     await handleReportsRoute();
     if (window.__pendingReportOpen) {
       const id = window.__pendingReportOpen;
@@ -88,6 +90,7 @@ const ReportsPage = (() => {
 
   function applyAccessControls() {
     toggleTab('reports-tab-templates', ReportsPage.hasPermission('reports.templates.view'));
+    toggleTab('reports-tab-schedules', ReportsPage.hasPermission('reports.edit'));
     toggleTab('reports-tab-settings', ReportsPage.hasPermission('reports.templates.manage'));
     const newBtn = document.getElementById('reports-new-btn');
     if (newBtn) newBtn.hidden = !ReportsPage.hasPermission('reports.create');
@@ -123,6 +126,7 @@ const ReportsPage = (() => {
     } else {
       const tabMap = {
         'reports-tab-templates': 'templates',
+        'reports-tab-schedules': 'schedules',
         'reports-tab-settings': 'settings',
         'reports-tab-home': ''
       };
@@ -140,6 +144,7 @@ const ReportsPage = (() => {
     if (!parts[1]) return { tab: 'reports-tab-home' };
     if (parts[1] === 'builder') return { create: true };
     if (parts[1] === 'templates') return { tab: 'reports-tab-templates' };
+    if (parts[1] === 'schedules') return { tab: 'reports-tab-schedules' };
     if (parts[1] === 'settings') return { tab: 'reports-tab-settings' };
     const id = parseInt(parts[1], 10);
     if (Number.isFinite(id)) {
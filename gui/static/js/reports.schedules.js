@@ -0,0 +1,238 @@
+(() => {
+  const state = ReportsPage.state;
+  state.schedules = [];
+  state.scheduleGroups = [];
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function localizeError(err) {
+    const raw = (err && err.message ? err.message : '').trim();
+    return raw ? t(raw) : t('common.error');
+  }
+
+  function bindSchedules() {
+    const newBtn = document.getElementById('reports-schedule-new');
+    if (newBtn) newBtn.onclick = () => openScheduleForm();
+    const form = document.getElementById('reports-schedule-form');
+    if (form) {
+      form.onsubmit = async (e) => {
+        e.preventDefault();
+        await saveSchedule();
+      };
+    }
+    const delBtn = document.getElementById('reports-schedule-delete');
+    if (delBtn) delBtn.onclick = () => deleteSchedule();
+  }
+
+  async function loadSchedules() {
+    if (!ReportsPage.hasPermission('reports.edit')) return;
+    try {
+      const res = await Api.get('/api/reports/schedules');
+      state.schedules = res.items || [];
+      renderSchedules();
+    } catch (err) {
+      console.warn('load schedules', err);
+    }
+  }
+
+  async function loadGroups() {
+    if (state.scheduleGroups.length) return;
+    try {
+      const res = await Api.get('/api/accounts/groups');
+      state.scheduleGroups = res.groups || [];
+    } catch (_) {
+      state.scheduleGroups = [];
+    }
+  }
+
+  function renderSchedules() {
+    const tbody = document.querySelector('#reports-schedules-table tbody');
+    if (!tbody) return;
+    tbody.innerHTML = '';
+    if (!state.schedules.length) {
+      const tr = document.createElement('tr');
+      tr.innerHTML = `<td colspan="6" class="muted">${escapeHtml(t('reports.schedules.empty'))}</td>`;
+      tbody.appendChild(tr);
+      return;
+    }
+    state.schedules.forEach(sch => {
+      const tr = document.createElement('tr');
+      const status = sch.last_status ? t(`reports.schedules.status.${sch.last_status}`) : '-';
+      tr.innerHTML = `
+        <td>${escapeHtml(sch.name)}${sch.enabled ? '' : ` <span class="muted">(${escapeHtml(t('reports.schedules.disabled'))})</span>`}</td>
+        <td>${escapeHtml(t(`reports.schedules.period.${sch.period}`))}</td>
+        <td><code>${escapeHtml(sch.cron || sch.rrule)}</code></td>
+        <td>${sch.next_run_at ? escapeHtml(ReportsPage.formatDate(sch.next_run_at)) : '-'}</td>
+        <td>${sch.last_run_at ? `${escapeHtml(ReportsPage.formatDate(sch.last_run_at))} · ` : ''}${escapeHtml(status)}</td>
+        <td class="actions">
+          <button class="btn ghost" data-action="run">${escapeHtml(t('reports.schedules.runNow'))}</button>
+          <button class="btn ghost" data-action="runs">${escapeHtml(t('reports.schedules.runs'))}</button>
+          <button class="btn secondary" data-action="edit">${escapeHtml(t('common.edit'))}</button>
+        </td>
+      `;
+      tr.querySelector('[data-action="run"]').onclick = () => runSchedule(sch);
+      tr.querySelector('[data-action="runs"]').onclick = () => showRuns(sch);
+      tr.querySelector('[data-action="edit"]').onclick = () => openScheduleForm(sch);
+      tbody.appendChild(tr);
+    });
+  }
+
+  function fillSelect(sel, items, selected, withEmpty) {
+    if (!sel) return;
+    sel.innerHTML = '';
+    if (withEmpty) {
+      const empty = document.createElement('option');
+      empty.value = '';
+      empty.textContent = t('common.none');
+      sel.appendChild(empty);
+    }
+    const chosen = new Set((selected || []).map(String));
+    items.forEach(item => {
+      const opt = document.createElement('option');
+      opt.value = item.id;
+      opt.textContent = item.label;
+      opt.selected = chosen.has(String(item.id));
+      sel.appendChild(opt);
+    });
+  }
+
+  async function openScheduleForm(sch) {
+    const form = document.getElementById('reports-schedule-form');
+    if (!form) return;
+    await loadGroups();
+    form.hidden = false;
+    document.getElementById('reports-schedule-id').value = sch?.id || '';
+    document.getElementById('reports-schedule-name').value = sch?.name || '';
+    document.getElementById('reports-schedule-enabled').checked = sch ? !!sch.enabled : true;
+    const reports = (state.reports || []).map(r => r.document || {}).filter(d => d.id).map(d => ({ id: d.id, label: d.title }));
+    const templates = (state.templates || []).map(tpl => ({ id: tpl.id, label: tpl.name }));
+    const reportId = sch?.template_id ? null : sch?.report_id;
+    fillSelect(document.getElementById('reports-schedule-report'), reports, reportId ? [reportId] : [], true);
+    fillSelect(document.getElementById('reports-schedule-template'), templates, sch?.template_id ? [sch.template_id] : [], true);
+    document.getElementById('reports-schedule-period').value = sch?.period || 'last_month';
+    document.getElementById('reports-schedule-format').value = sch?.format || 'pdf';
+    document.getElementById('reports-schedule-cron').value = sch?.cron || '';
+    document.getElementById('reports-schedule-rrule').value = sch && !sch.cron ? sch.rrule : '';
+    document.getElementById('reports-schedule-time').value = sch?.time_of_day || '';
+    document.getElementById('reports-schedule-timezone').value = sch?.timezone || '';
+    const users = (typeof UserDirectory !== 'undefined' && UserDirectory.all ? UserDirectory.all() : [])
+      .map(u => ({ id: u.id, label: u.full_name || u.username }));
+    fillSelect(document.getElementById('reports-schedule-users'), users, sch?.recipient_users, false);
+    fillSelect(document.getElementById('reports-schedule-groups'), state.scheduleGroups.map(g => ({ id: g.id, label: g.name })), sch?.recipient_groups, false);
+    const delBtn = document.getElementById('reports-schedule-delete');
+    if (delBtn) delBtn.hidden = !sch?.id;
+    form.scrollIntoView({ behavior: 'smooth', block: 'start' });
+  }
+
+  function selectedIDs(id) {
+    return Array.from(document.getElementById(id)?.selectedOptions || [])
+      .map(o => parseInt(o.value, 10))
+      .filter(Boolean);
+  }
+
+  async function saveSchedule() {
+    const id = parseInt(document.getElementById('reports-schedule-id').value || '0', 10);
+    const reportId = parseInt(document.getElementById('reports-schedule-report').value || '0', 10);
+    const templateId = parseInt(document.getElementById('reports-schedule-template').value || '0', 10);
+    const payload = {
+      name: document.getElementById('reports-schedule-name').value.trim(),
+      report_id: reportId || null,
+      template_id: templateId || null,
+      period: document.getElementById('reports-schedule-period').value,
+      format: document.getElementById('reports-schedule-format').value,
+      cron: document.getElementById('reports-schedule-cron').value.trim(),
+      rrule: document.getElementById('reports-schedule-rrule').value.trim(),
+      time_of_day: document.getElementById('reports-schedule-time').value,
+      timezone: document.getElementById('reports-schedule-timezone').value.trim(),
+      recipient_users: selectedIDs('reports-schedule-users'),
+      recipient_groups: selectedIDs('reports-schedule-groups'),
+      enabled: document.getElementById('reports-schedule-enabled').checked
+    };
+    try {
+      if (id) {
+        await Api.put(`/api/reports/schedules/${id}`, payload);
+      } else {
+        await Api.post('/api/reports/schedules', payload);
+      }
+      ReportsPage.showAlert('reports-schedules-alert', t('common.saved'), true);
+      document.getElementById('reports-schedule-form').hidden = true;
+      await loadSchedules();
+    } catch (err) {
+      ReportsPage.showAlert('reports-schedules-alert', localizeError(err));
+    }
+  }
+
+  async function deleteSchedule() {
+    const id = parseInt(document.getElementById('reports-schedule-id').value || '0', 10);
+    if (!id) return;
+    const ok = await (window.AppConfirm?.ask
+      ? window.AppConfirm.ask(t('reports.schedules.deleteConfirm'), {
+        title: t('common.confirm'),
+        confirmText: t('common.delete'),
+        cancelText: t('common.cancel'),
+        danger: true,
+      })
+      : Promise.resolve(window.confirm(t('reports.schedules.deleteConfirm'))));
+    if (!ok) return;
+    try {
+      await Api.del(`/api/reports/schedules/${id}`);
+      document.getElementById('reports-schedule-form').hidden = true;
+      await loadSchedules();
+    } catch (err) {
+      ReportsPage.showAlert('reports-schedules-alert', localizeError(err));
+    }
+  }
+
+  async function runSchedule(sch) {
+    try {
+      const run = await Api.post(`/api/reports/schedules/${sch.id}/run`, {});
+      if (run.status === 'success') {
+        ReportsPage.showAlert('reports-schedules-alert', t('reports.schedules.runDone'), true);
+      } else {
+        ReportsPage.showAlert('reports-schedules-alert', t(run.error || 'common.error'));
+      }
+      await loadSchedules();
+      await showRuns(sch);
+    } catch (err) {
+      ReportsPage.showAlert('reports-schedules-alert', localizeError(err));
+    }
+  }
+
+  async function showRuns(sch) {
+    const box = document.getElementById('reports-schedule-runs');
+    const tbody = box?.querySelector('tbody');
+    if (!tbody) return;
+    try {
+      const res = await Api.get(`/api/reports/schedules/${sch.id}/runs`);
+      tbody.innerHTML = '';
+      (res.items || []).forEach(run => {
+        const tr = document.createElement('tr');
+        const output = run.output_doc_id
+          ? `<a href="/docs/${run.output_doc_id}">#${run.output_doc_id} v${run.output_version}</a>`
+          : '-';
+        const status = t(`reports.schedules.status.${run.status}`) + (run.error ? `: ${t(run.error)}` : '');
+        tr.innerHTML = `
+          <td>${escapeHtml(ReportsPage.formatDate(run.started_at))}</td>
+          <td>${escapeHtml(t(`reports.schedules.trigger.${run.trigger}`))}${run.triggered_by ? ` · ${escapeHtml(run.triggered_by)}` : ''}</td>
+          <td>${escapeHtml(ReportsPage.formatPeriod(run))}</td>
+          <td>${escapeHtml(status)}</td>
+          <td>${output}</td>
+          <td>${run.delivered || 0}</td>
+        `;
+        tbody.appendChild(tr);
+      });
+      box.hidden = false;
+    } catch (err) {
+      ReportsPage.showAlert('reports-schedules-alert', localizeError(err));
+    }
+  }
+
+  function escapeHtml(str) {
+    return (str || '').toString().replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+  }
+
+  ReportsPage.bindSchedules = bindSchedules;
+  ReportsPage.loadSchedules = loadSchedules;
+})();
@@ -8,6 +8,14 @@
     { type: 'controls', titleKey: 'reports.sections.controls' },
     { type: 'monitoring', titleKey: 'reports.sections.monitoring' },
     { type: 'sla_summary', titleKey: 'reports.sections.slaSummary' },
+    { type: 'software_eol', titleKey: 'reports.sections.softwareEol' },
+    { type: 'risks', titleKey: 'reports.sections.risks' },
+    { type: 'findings', titleKey: 'reports.sections.findings' },
+    { type: 'assets', titleKey: 'reports.sections.assets' },
+    { type: 'software', titleKey: 'reports.sections.software' },
+    { type: 'framework_coverage', titleKey: 'reports.sections.frameworkCoverage' },
+    { type: 'effort', titleKey: 'reports.sections.effort' },
+    { type: 'task_flow', titleKey: 'reports.sections.taskFlow' },
     { type: 'audit', titleKey: 'reports.sections.audit' },
     { type: 'custom_md', titleKey: 'reports.sections.custom' }
   ];
@@ -36,6 +44,9 @@
     if (!reportId) return;
     try {
       const res = await Api.get(`/api/reports/${reportId}/sections`);
+      state.savedSearches = await Api.get('/api/saved-searches')
+        .then(r => (Array.isArray(r.items) ? r.items : []))
+        .catch(() => []);
       state.sections = res.sections || [];
       state.sectionsMeta = res.meta || {};
       renderSections();
@@ -101,7 +112,8 @@
           </div>
           <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
             <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
-          </div>`;
+          </div>
+          ${queryFields(cfg, 'incident')}`;
       case 'tasks':
         return `
           <div class="form-field"><label>${t('reports.sections.filters.status')}</label>
@@ -121,7 +133,8 @@
           </div>
           <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
             <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
-          </div>`;
+          </div>
+          ${queryFields(cfg, 'task')}`;
       case 'docs':
         return `
           <div class="form-field"><label>${t('reports.sections.filters.status')}</label>
@@ -135,7 +148,8 @@
           </div>
           <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
             <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
-          </div>`;
+          </div>
+          ${queryFields(cfg, 'doc')}`;
       case 'controls':
         return `
           <div class="form-field"><label>${t('reports.sections.filters.status')}</label>
@@ -198,6 +212,126 @@
           <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
             <input type="number" class="input" data-field="limit" value="${cfg.limit || 50}">
           </div>`;
+      case 'software_eol':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.eolHorizon')}</label>
+            <input type="number" class="input" data-field="horizon_days" value="${cfg.horizon_days || ''}">
+          </div>
+          <div class="form-field">
+            <label class="checkbox"><input type="checkbox" data-field="only_expired" ${cfg.only_expired ? 'checked' : ''}>
+            <span>${t('reports.sections.filters.onlyExpired')}</span></label>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 50}">
+          </div>`;
+      case 'risks':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.riskScore')}</label>
+            <select class="select" data-field="score">
+              <option value="residual" ${cfg.score !== 'inherent' ? 'selected' : ''}>${t('risks.heatmap.residual')}</option>
+              <option value="inherent" ${cfg.score === 'inherent' ? 'selected' : ''}>${t('risks.heatmap.inherent')}</option>
+            </select>
+          </div>
+          <div class="form-field">
+            <label class="checkbox"><input type="checkbox" data-field="include_closed" ${cfg.include_closed ? 'checked' : ''}>
+            <span>${t('reports.sections.filters.includeClosed')}</span></label>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>`;
+      case 'findings':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.severity')}</label>
+            <input class="input" data-field="severity" value="${escapeAttr(listValue(cfg.severity))}" placeholder="critical, high">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.status')}</label>
+            <input class="input" data-field="status" value="${escapeAttr(listValue(cfg.status))}" placeholder="open, in_progress">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.minAgeDays')}</label>
+            <input type="number" class="input" data-field="min_age_days" value="${cfg.min_age_days || ''}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>
+          ${queryFields(cfg, 'finding')}`;
+      case 'assets':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.criticality')}</label>
+            <select class="select" data-field="criticality">${optionList(['critical', 'high', 'medium', 'low'], cfg.criticality, 'assets.criticality')}</select>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.env')}</label>
+            <select class="select" data-field="env">${optionList(['prod', 'stage', 'dev', 'test', 'other'], cfg.env, 'assets.env')}</select>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.type')}</label>
+            <select class="select" data-field="type">${optionList(['host', 'service', 'application', 'network', 'other'], cfg.type, 'assets.type')}</select>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.status')}</label>
+            <select class="select" data-field="status">${optionList(['active', 'decommissioned'], cfg.status, 'assets.status')}</select>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.tag')}</label>
+            <input class="input" data-field="tag" value="${escapeAttr(cfg.tag || '')}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>
+          ${queryFields(cfg, 'asset')}`;
+      case 'software':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.vendor')}</label>
+            <input class="input" data-field="vendor" value="${escapeAttr(cfg.vendor || '')}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.tag')}</label>
+            <input class="input" data-field="tag" value="${escapeAttr(cfg.tag || '')}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.eolHorizon')}</label>
+            <input type="number" class="input" data-field="horizon_days" value="${cfg.horizon_days || ''}">
+          </div>
+          <div class="form-field">
+            <label class="checkbox"><input type="checkbox" data-field="only_eol" ${cfg.only_eol ? 'checked' : ''}>
+            <span>${t('reports.sections.filters.onlyEol')}</span></label>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>`;
+      case 'framework_coverage':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.frameworkId')}</label>
+            <input type="number" class="input" data-field="framework_id" value="${cfg.framework_id || ''}">
+          </div>
+          <div class="form-field">
+            <label class="checkbox"><input type="checkbox" data-field="include_inactive" ${cfg.include_inactive ? 'checked' : ''}>
+            <span>${t('reports.sections.filters.includeInactive')}</span></label>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.gapsLimit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>`;
+      case 'effort':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.effortGroup')}</label>
+            <select class="select" data-field="group_by">
+              ${['user', 'board', 'space', 'customer'].map(val => `<option value="${val}" ${(cfg.group_by || 'user') === val ? 'selected' : ''}>${t(`reports.sections.effortGroups.${val}`)}</option>`).join('')}
+            </select>
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.board')}</label>
+            <input type="number" class="input" data-field="board_id" value="${cfg.board_id || ''}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.space')}</label>
+            <input type="number" class="input" data-field="space_id" value="${cfg.space_id || ''}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.customer')}</label>
+            <input class="input" data-field="customer" value="${escapeAttr(cfg.customer || '')}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.limit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 20}">
+          </div>`;
+      case 'task_flow':
+        return `
+          <div class="form-field"><label>${t('reports.sections.filters.board')}</label>
+            <input type="number" class="input" data-field="board_id" value="${cfg.board_id || ''}">
+          </div>
+          <div class="form-field"><label>${t('reports.sections.filters.ageingLimit')}</label>
+            <input type="number" class="input" data-field="limit" value="${cfg.limit || 10}">
+          </div>`;
       case 'custom_md':
         return `
           <div class="form-field"><label>${t('reports.sections.filters.customKey')}</label>
@@ -360,6 +494,32 @@
     return out;
   }
 
+  // queryFields renders the query and saved search settings of a list section.
+  function queryFields(cfg, entity) {
+    const searches = (state.savedSearches || []).filter(s => s.entity_type === entity);
+    const options = [`<option value="">-</option>`].concat(searches.map(s =>
+      `<option value="${s.id}" ${String(cfg.saved_search) === String(s.id) ? 'selected' : ''}>${escapeHtml(s.name)}</option>`));
+    return `
+          <div class="form-field"><label>${t('savedSearches.title')}</label>
+            <select class="select" data-field="saved_search">${options.join('')}</select>
+          </div>
+          <div class="form-field"><label>${t('savedSearches.query')}</label>
+            <input class="input" data-field="query" value="${escapeAttr(cfg.query || '')}" placeholder="${escapeAttr(t('savedSearches.queryPlaceholder'))}">
+          </div>`;
+  }
+
+  function optionList(values, selected, labelPrefix) {
+    let html = `<option value="">${t('common.all')}</option>`;
+    values.forEach(val => {
+      html += `<option value="${val}" ${selected === val ? 'selected' : ''}>${escapeHtml(t(`${labelPrefix}.${val}`))}</option>`;
+    });
+    return html;
+  }
+
+  function listValue(val) {
+    return Array.isArray(val) ? val.join(', ') : (val || '');
+  }
+
   function userOptions(selected) {
     const users = UserDirectory?.all ? UserDirectory.all() : [];
     let html = `<option value="">${t('common.all')}</option>`;
@@ -0,0 +1,959 @@
+const RisksPage = (() => {
+  const state = {
+    items: [],
+    links: [],
+    matrix: null,
+    linkOptions: { assets: [], controls: [], findings: [], vulnerabilities: [], tasks: [] },
+    linkOptionsLoaded: false,
+    boards: [],
+    permissions: [],
+    current: null,
+    pendingOpenId: null,
+    tagsBound: false
+  };
+
+  function t(key) {
+    const val = BerkutI18n.t(key);
+    return val === key ? key : val;
+  }
+
+  function tOr(key, fallback) {
+    const val = BerkutI18n.t(key);
+    return !val || val === key ? fallback : val;
+  }
+
+  function hasPerm(perm) {
+    if (!perm) return true;
+    const perms = Array.isArray(state.permissions) ? state.permissions : [];
+    if (!perms.length) return true;
+    return perms.includes(perm);
+  }
+
+  async function init() {
+    const page = document.getElementById('risks-page');
+    if (!page) return;
+    state.pendingOpenId = parseInt(new URLSearchParams(window.location.search).get('risk') || '', 10) || null;
+    await loadCurrentUser();
+    bindUI();
+    if (typeof RegistryReports !== 'undefined' && RegistryReports.bind) {
+      RegistryReports.bind('risks-create-report', 'risks');
+    }
+    applyAccessControls();
+    bindTagDirectory();
+    if (typeof UserDirectory !== 'undefined') await UserDirectory.load();
+    await loadMatrix();
+    await Promise.all([load(), loadHeatmap()]);
+    if (state.pendingOpenId) {
+      const id = state.pendingOpenId;
+      state.pendingOpenId = null;
+      openRisk(id, 'view');
+    }
+  }
+
+  async function loadCurrentUser() {
+    try {
+      const res = await Api.get('/api/auth/me');
+      const me = res.user;
+      state.permissions = Array.isArray(me?.permissions) ? me.permissions : [];
+    } catch (_) {
+      state.permissions = [];
+    }
+  }
+
+  function bindUI() {
+    document.getElementById('risks-apply')?.addEventListener('click', () => load());
+    document.getElementById('risks-create')?.addEventListener('click', () => openRisk(null, 'create'));
+    document.getElementById('risks-matrix-open')?.addEventListener('click', () => openMatrix());
+    document.getElementById('risk-save')?.addEventListener('click', () => saveRisk());
+    document.getElementById('risk-archive')?.addEventListener('click', () => archiveOrRestore());
+    document.getElementById('risk-accept')?.addEventListener('click', () => acceptRisk());
+    document.getElementById('risk-accept-revoke')?.addEventListener('click', () => revokeAcceptance());
+    document.getElementById('risk-link-add')?.addEventListener('click', () => addLink());
+    document.getElementById('risk-link-target-type')?.addEventListener('change', () => refreshLinkTargets());
+    document.getElementById('risk-link-search')?.addEventListener('input', () => refreshLinkTargets());
+    document.getElementById('risk-task-board')?.addEventListener('change', () => loadTaskColumns());
+    document.getElementById('risk-task-create')?.addEventListener('click', () => createTask());
+    document.getElementById('risk-matrix-level-add')?.addEventListener('click', () => addLevelRow({ key: '', min_score: '', color: '#9e9e9e' }));
+    document.getElementById('risk-matrix-reset')?.addEventListener('click', () => fillMatrixForm(defaultMatrix()));
+    document.getElementById('risk-matrix-save')?.addEventListener('click', () => saveMatrix());
+    ['risk-inherent-likelihood', 'risk-inherent-impact', 'risk-residual-likelihood', 'risk-residual-impact'].forEach((id) => {
+      document.getElementById(id)?.addEventListener('change', () => renderScoreHint());
+    });
+  }
+
+  function applyAccessControls() {
+    const createBtn = document.getElementById('risks-create');
+    if (createBtn) createBtn.hidden = !hasPerm('risks.manage');
+    const matrixBtn = document.getElementById('risks-matrix-open');
+    if (matrixBtn) matrixBtn.hidden = !hasPerm('risks.manage');
+    const includeField = document.getElementById('risks-include-deleted-field');
+    if (includeField) includeField.hidden = !hasPerm('risks.manage');
+  }
+
+  function bindTagDirectory() {
+    setRiskTags(selectedValues('risk-tags'));
+    if (state.tagsBound) return;
+    state.tagsBound = true;
+    document.addEventListener('tags:changed', () => setRiskTags(selectedValues('risk-tags')));
+  }
+
+  function selectedValues(selectId) {
+    const el = document.getElementById(selectId);
+    return Array.from(el?.selectedOptions || []).map(o => o.value);
+  }
+
+  function setRiskTags(selected) {
+    const select = document.getElementById('risk-tags');
+    if (!select) return;
+    const available = (typeof DocUI !== 'undefined' && DocUI.availableTags) ? DocUI.availableTags() : [];
+    const selectedSet = new Set((selected || []).map(v => (v || '').toString().trim().toUpperCase()).filter(Boolean));
+    select.innerHTML = '';
+    const addOpt = (code) => {
+      const normalized = (code || '').toString().trim().toUpperCase();
+      if (!normalized) return;
+      const opt = document.createElement('option');
+      opt.value = normalized;
+      const label = (typeof DocUI !== 'undefined' && DocUI.tagLabel) ? DocUI.tagLabel(normalized) : normalized;
+      opt.textContent = label;
+      opt.dataset.label = label;
+      if (selectedSet.has(normalized)) opt.selected = true;
+      select.appendChild(opt);
+    };
+    available.forEach(tag => addOpt(tag.code || tag));
+    Array.from(selectedSet.values()).forEach(code => {
+      if (!Array.from(select.options).some(o => o.value === code)) addOpt(code);
+    });
+    if (typeof DocsPage !== 'undefined' && DocsPage.enhanceMultiSelects) {
+      DocsPage.enhanceMultiSelects([select.id]);
+    } else {
+      select.multiple = true;
+      select.setAttribute('multiple', 'multiple');
+      if (!select.size || select.size < 2) select.size = 6;
+    }
+    const hint = document.querySelector('[data-tag-hint="risk-tags"]');
+    if (typeof DocUI !== 'undefined' && DocUI.bindTagHint) {
+      DocUI.bindTagHint(select, hint);
+    }
+  }
+
+  // --- matrix ---
+
+  function defaultMatrix() {
+    return {
+      likelihood: ['rare', 'unlikely', 'possible', 'likely', 'almost_certain'],
+      impact: ['negligible', 'minor', 'moderate', 'major', 'severe'],
+      levels: [
+        { key: 'low', min_score: 1, color: '#4caf50' },
+        { key: 'medium', min_score: 5, color: '#ffc107' },
+        { key: 'high', min_score: 10, color: '#ff9800' },
+        { key: 'critical', min_score: 17, color: '#f44336' }
+      ]
+    };
+  }
+
+  async function loadMatrix() {
+    try {
+      state.matrix = await Api.get('/api/risks/matrix');
+    } catch (_) {
+      state.matrix = defaultMatrix();
+    }
+    fillAxisSelects();
+    fillLevelFilter();
+  }
+
+  function axisLabel(axis, value) {
+    return tOr(`risks.${axis}.${value}`, value);
+  }
+
+  function levelLabel(key) {
+    if (!key) return '-';
+    return tOr(`risks.level.${key}`, key);
+  }
+
+  function levelFor(score) {
+    const levels = state.matrix?.levels || [];
+    let out = null;
+    levels.forEach((lvl) => {
+      if (score >= lvl.min_score) out = lvl;
+    });
+    return out;
+  }
+
+  function fillAxisSelects() {
+    const m = state.matrix || defaultMatrix();
+    document.querySelectorAll('#risk-form select[data-axis]').forEach((select) => {
+      const axis = select.dataset.axis;
+      const labels = axis === 'impact' ? m.impact : m.likelihood;
+      const prev = select.value;
+      select.innerHTML = '';
+      (labels || []).forEach((label, idx) => {
+        const opt = document.createElement('option');
+        opt.value = String(idx + 1);
+        opt.textContent = `${idx + 1} - ${axisLabel(axis, label)}`;
+        select.appendChild(opt);
+      });
+      if (prev && Array.from(select.options).some(o => o.value === prev)) select.value = prev;
+    });
+  }
+
+  function fillLevelFilter() {
+    const select = document.getElementById('risks-filter-level');
+    if (!select) return;
+    const prev = select.value;
+    Array.from(select.options).slice(1).forEach(o => o.remove());
+    (state.matrix?.levels || []).forEach((lvl) => {
+      const opt = document.createElement('option');
+      opt.value = lvl.key;
+      opt.textContent = levelLabel(lvl.key);
+      select.appendChild(opt);
+    });
+    select.value = prev;
+  }
+
+  function openMatrix() {
+    if (!hasPerm('risks.manage')) return;
+    showAlert(document.getElementById('risk-matrix-alert'), '');
+    fillMatrixForm(state.matrix || defaultMatrix());
+    const modal = document.getElementById('risk-matrix-modal');
+    if (modal) modal.hidden = false;
+  }
+
+  function fillMatrixForm(m) {
+    setVal('risk-matrix-likelihood', (m.likelihood || []).join('\n'));
+    setVal('risk-matrix-impact', (m.impact || []).join('\n'));
+    const tbody = document.querySelector('#risk-matrix-levels tbody');
+    if (tbody) tbody.innerHTML = '';
+    (m.levels || []).forEach(addLevelRow);
+  }
+
+  function addLevelRow(level) {
+    const tbody = document.querySelector('#risk-matrix-levels tbody');
+    if (!tbody) return;
+    const tr = document.createElement('tr');
+    tr.innerHTML = `
+      <td><input class="input" data-field="key" value="${escapeHtml(level.key || '')}"></td>
+      <td><input class="input" type="number" min="1" data-field="min_score" value="${escapeHtml(String(level.min_score ?? ''))}"></td>
+      <td><input type="color" data-field="color" value="${escapeHtml(level.color || '#9e9e9e')}"></td>
+      <td class="actions"></td>
+    `;
+    const del = document.createElement('button');
+    del.type = 'button';
+    del.className = 'btn ghost btn-xs';
+    del.textContent = t('common.delete');
+    del.addEventListener('click', () => tr.remove());
+    tr.querySelector('.actions').appendChild(del);
+    tbody.appendChild(tr);
+  }
+
+  async function saveMatrix() {
+    const alert = document.getElementById('risk-matrix-alert');
+    const lines = (id) => (document.getElementById(id)?.value || '').split('\n').map(x => x.trim()).filter(Boolean);
+    const levels = Array.from(document.querySelectorAll('#risk-matrix-levels tbody tr')).map((tr) => ({
+      key: (tr.querySelector('[data-field="key"]')?.value || '').trim().toLowerCase(),
+      min_score: parseInt(tr.querySelector('[data-field="min_score"]')?.value || '0', 10) || 0,
+      color: tr.querySelector('[data-field="color"]')?.value || ''
+    }));
+    const payload = { likelihood: lines('risk-matrix-likelihood'), impact: lines('risk-matrix-impact'), levels };
+    try {
+      state.matrix = await Api.put('/api/risks/matrix', payload);
+      fillAxisSelects();
+      fillLevelFilter();
+      document.getElementById('risk-matrix-modal').hidden = true;
+      await Promise.all([load(), loadHeatmap()]);
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  // --- heat map ---
+
+  async function loadHeatmap() {
+    try {
+      const res = await Api.get('/api/risks/heatmap');
+      if (res.matrix) state.matrix = res.matrix;
+      renderHeatmap('risks-heatmap-inherent', res.inherent || []);
+      renderHeatmap('risks-heatmap-residual', res.residual || []);
+    } catch (_) {
+      renderHeatmap('risks-heatmap-inherent', []);
+      renderHeatmap('risks-heatmap-residual', []);
+    }
+  }
+
+  // Rows are drawn from the highest likelihood down so the critical corner
+  // sits top-right, as on a paper matrix.
+  function renderHeatmap(containerId, grid) {
+    const el = document.getElementById(containerId);
+    if (!el) return;
+    const m = state.matrix || defaultMatrix();
+    const cols = m.impact.length;
+    let html = `<div class="risk-heatmap-grid" style="grid-template-columns: auto repeat(${cols}, 1fr)">`;
+    for (let l = m.likelihood.length; l >= 1; l -= 1) {
+      html += `<div class="risk-heatmap-axis">${escapeHtml(axisLabel('likelihood', m.likelihood[l - 1]))}</div>`;
+      for (let i = 1; i <= cols; i += 1) {
+        const count = grid[l - 1]?.[i - 1] || 0;
+        const lvl = levelFor(l * i);
+        const color = lvl?.color || 'transparent';
+        const title = `${l} x ${i} = ${l * i} (${levelLabel(lvl?.key)})`;
+        html += `<div class="risk-heatmap-cell${count ? ' has-items' : ''}" style="background:${escapeHtml(color)}" title="${escapeHtml(title)}">${count || ''}</div>`;
+      }
+    }
+    html += '<div></div>';
+    for (let i = 1; i <= cols; i += 1) {
+      html += `<div class="risk-heatmap-axis">${escapeHtml(axisLabel('impact', m.impact[i - 1]))}</div>`;
+    }
+    html += '</div>';
+    el.innerHTML = html;
+  }
+
+  // --- list ---
+
+  function buildFilterQuery() {
+    const q = new URLSearchParams();
+    const val = (id) => (document.getElementById(id)?.value || '').trim();
+    if (val('risks-filter-q')) q.set('q', val('risks-filter-q'));
+    if (val('risks-filter-status')) q.set('status', val('risks-filter-status'));
+    if (val('risks-filter-treatment')) q.set('treatment', val('risks-filter-treatment'));
+    if (val('risks-filter-level')) q.set('level', val('risks-filter-level'));
+    const includeDeleted = val('risks-filter-include-deleted');
+    if (includeDeleted) q.set('include_deleted', includeDeleted);
+    q.set('limit', '200');
+    return q.toString();
+  }
+
+  async function load() {
+    try {
+      const res = await Api.get(`/api/risks?${buildFilterQuery()}`);
+      state.items = res.items || [];
+    } catch (_) {
+      state.items = [];
+    }
+    renderTable();
+  }
+
+  function levelBadge(score, key) {
+    const lvl = (state.matrix?.levels || []).find(l => l.key === key);
+    const color = lvl?.color || 'transparent';
+    return `<span class="risk-level-badge" style="background:${escapeHtml(color)}">${escapeHtml(String(score))} · ${escapeHtml(levelLabel(key))}</span>`;
+  }
+
+  function acceptanceText(item) {
+    if (!item.accepted_at) return '-';
+    const until = item.acceptance_expires_at ? formatDate(item.acceptance_expires_at) : '-';
+    if (item.acceptance_expired) return `${t('risks.acceptance.expired')} (${until})`;
+    return `${t('risks.acceptance.until')} ${until}`;
+  }
+
+  function renderTable() {
+    const tbody = document.querySelector('#risks-table tbody');
+    const empty = document.getElementById('risks-empty');
+    if (!tbody) return;
+    tbody.innerHTML = '';
+    if (!state.items.length) {
+      if (empty) empty.hidden = false;
+      return;
+    }
+    if (empty) empty.hidden = true;
+    state.items.forEach((item) => {
+      const tr = document.createElement('tr');
+      const owner = item.owner_id && typeof UserDirectory !== 'undefined' ? UserDirectory.name(item.owner_id) : '-';
+      tr.innerHTML = `
+        <td>${escapeHtml(item.title || '')}${item.deleted_at ? ` <span class="muted">(${escapeHtml(t('common.archived'))})</span>` : ''}</td>
+        <td>${escapeHtml(owner)}</td>
+        <td>${levelBadge(item.inherent_score, item.inherent_level)}</td>
+        <td>${levelBadge(item.residual_score, item.residual_level)}</td>
+        <td>${escapeHtml(t(`risks.treatment.${item.treatment || 'mitigate'}`))}</td>
+        <td>${escapeHtml(t(`risks.status.${item.status || 'identified'}`))}</td>
+        <td${item.acceptance_expired ? ' class="danger"' : ''}>${escapeHtml(acceptanceText(item))}</td>
+        <td>${escapeHtml(item.updated_at ? formatDateTime(item.updated_at) : '-')}</td>
+        <td class="actions"></td>
+      `;
+      const openBtn = document.createElement('button');
+      openBtn.type = 'button';
+      openBtn.className = 'btn ghost btn-xs';
+      openBtn.textContent = t('common.open');
+      openBtn.addEventListener('click', () => openRisk(item.id, 'view'));
+      tr.querySelector('.actions').appendChild(openBtn);
+      tbody.appendChild(tr);
+    });
+  }
+
+  // --- modal ---
+
+  function fillOwnerSelect(selected) {
+    const select = document.getElementById('risk-owner');
+    if (!select) return;
+    select.innerHTML = '';
+    const none = document.createElement('option');
+    none.value = '';
+    none.textContent = '-';
+    select.appendChild(none);
+    const users = typeof UserDirectory !== 'undefined' ? UserDirectory.all() : [];
+    users
+      .slice()
+      .sort((a, b) => (a.full_name || a.username || '').localeCompare(b.full_name || b.username || ''))
+      .forEach((u) => {
+        const opt = document.createElement('option');
+        opt.value = String(u.id);
+        opt.textContent = u.full_name || u.username;
+        select.appendChild(opt);
+      });
+    select.value = selected ? String(selected) : '';
+  }
+
+  async function openRisk(id, mode) {
+    const modal = document.getElementById('risk-modal');
+    const alert = document.getElementById('risk-modal-alert');
+    if (alert) alert.hidden = true;
+    if (!modal) return;
+    const canManage = hasPerm('risks.manage');
+    const isCreate = !id;
+    const viewOnly = !canManage || mode === 'view';
+    const titleEl = document.getElementById('risk-modal-title');
+    if (titleEl) titleEl.textContent = isCreate ? t('risks.modal.createTitle') : t(viewOnly ? 'risks.modal.viewTitle' : 'risks.modal.editTitle');
+    const archiveBtn = document.getElementById('risk-archive');
+    if (archiveBtn) archiveBtn.hidden = isCreate || !canManage;
+
+    resetForm();
+    state.current = null;
+    if (!isCreate) {
+      try {
+        const item = await Api.get(`/api/risks/${id}`);
+        state.current = item;
+        fillForm(item);
+        await loadLinks(id);
+      } catch (err) {
+        if (alert) {
+          alert.textContent = localizeError(err);
+          alert.hidden = false;
+        }
+        return;
+      }
+    } else {
+      state.links = [];
+      renderLinks();
+    }
+    setFormDisabled(viewOnly && !isCreate);
+    renderAcceptance();
+    await prepareTaskSection();
+    modal.hidden = false;
+  }
+
+  function closeRiskModal() {
+    const modal = document.getElementById('risk-modal');
+    if (modal) modal.hidden = true;
+  }
+
+  function resetForm() {
+    fillAxisSelects();
+    setVal('risk-id', '');
+    setVal('risk-title', '');
+    setVal('risk-category', '');
+    setVal('risk-description', '');
+    setVal('risk-status', 'identified');
+    setVal('risk-treatment', 'mitigate');
+    setVal('risk-inherent-likelihood', '1');
+    setVal('risk-inherent-impact', '1');
+    setVal('risk-residual-likelihood', '1');
+    setVal('risk-residual-impact', '1');
+    setVal('risk-treatment-due', '');
+    setVal('risk-review-at', '');
+    setVal('risk-threats', '');
+    setVal('risk-vulnerabilities', '');
+    setVal('risk-treatment-plan', '');
+    setVal('risk-acceptance-expires', '');
+    setVal('risk-acceptance-note', '');
+    fillOwnerSelect(null);
+    setRiskTags([]);
+    renderScoreHint();
+  }
+
+  function fillForm(item) {
+    setVal('risk-id', item.id || '');
+    setVal('risk-title', item.title || '');
+    setVal('risk-category', item.category || '');
+    setVal('risk-description', item.description_md || '');
+    setVal('risk-status', item.status || 'identified');
+    setVal('risk-treatment', item.treatment || 'mitigate');
+    setVal('risk-inherent-likelihood', String(item.inherent_likelihood || 1));
+    setVal('risk-inherent-impact', String(item.inherent_impact || 1));
+    setVal('risk-residual-likelihood', String(item.residual_likelihood || 1));
+    setVal('risk-residual-impact', String(item.residual_impact || 1));
+    setVal('risk-treatment-due', item.treatment_due_at ? formatDateInput(item.treatment_due_at) : '');
+    setVal('risk-review-at', item.review_at ? formatDateInput(item.review_at) : '');
+    setVal('risk-threats', (item.threats || []).join('\n'));
+    setVal('risk-vulnerabilities', (item.vulnerabilities || []).join('\n'));
+    setVal('risk-treatment-plan', item.treatment_plan_md || '');
+    fillOwnerSelect(item.owner_id);
+    setRiskTags(Array.isArray(item.tags) ? item.tags : []);
+    document.getElementById('risk-form').dataset.version = String(item.version || 1);
+    const archiveBtn = document.getElementById('risk-archive');
+    if (archiveBtn) {
+      archiveBtn.textContent = item.deleted_at ? t('risks.actions.restore') : t('risks.actions.archive');
+      archiveBtn.dataset.archived = item.deleted_at ? '1' : '0';
+    }
+    renderScoreHint();
+  }
+
+  function renderScoreHint() {
+    const el = document.getElementById('risk-score-hint');
+    if (!el) return;
+    const num = (id) => parseInt(document.getElementById(id)?.value || '0', 10) || 0;
+    const inherent = num('risk-inherent-likelihood') * num('risk-inherent-impact');
+    const residual = num('risk-residual-likelihood') * num('risk-residual-impact');
+    el.innerHTML = `${escapeHtml(t('risks.table.inherent'))}: ${levelBadge(inherent, levelFor(inherent)?.key)} &nbsp; ${escapeHtml(t('risks.table.residual'))}: ${levelBadge(residual, levelFor(residual)?.key)}`;
+  }
+
+  function setFormDisabled(disabled) {
+    document.querySelectorAll('#risk-form input, #risk-form select, #risk-form textarea').forEach((el) => {
+      el.disabled = !!disabled;
+    });
+    const saveBtn = document.getElementById('risk-save');
+    if (saveBtn) saveBtn.hidden = !!disabled;
+    const hasId = !!document.getElementById('risk-id')?.value;
+    const linkSection = document.getElementById('risk-links-section');
+    if (linkSection) linkSection.hidden = !hasId;
+    ['risk-link-add', 'risk-link-target-type', 'risk-link-target-id', 'risk-link-search', 'risk-link-relation'].forEach((id) => {
+      const el = document.getElementById(id);
+      if (el) el.disabled = !hasPerm('risks.manage');
+    });
+  }
+
+  function linesOf(id) {
+    return (document.getElementById(id)?.value || '').split('\n').map(x => x.trim()).filter(Boolean);
+  }
+
+  async function saveRisk() {
+    if (!hasPerm('risks.manage')) return;
+    const alert = document.getElementById('risk-modal-alert');
+    if (alert) alert.hidden = true;
+    const id = (document.getElementById('risk-id')?.value || '').trim();
+    const num = (elId) => parseInt(document.getElementById(elId)?.value || '0', 10) || 0;
+    const ownerRaw = document.getElementById('risk-owner')?.value || '';
+    const payload = {
+      title: (document.getElementById('risk-title')?.value || '').trim(),
+      category: (document.getElementById('risk-category')?.value || '').trim(),
+      description_md: (document.getElementById('risk-description')?.value || '').trim(),
+      status: document.getElementById('risk-status')?.value || 'identified',
+      treatment: document.getElementById('risk-treatment')?.value || 'mitigate',
+      owner_id: ownerRaw ? parseInt(ownerRaw, 10) : null,
+      inherent_likelihood: num('risk-inherent-likelihood'),
+      inherent_impact: num('risk-inherent-impact'),
+      residual_likelihood: num('risk-residual-likelihood'),
+      residual_impact: num('risk-residual-impact'),
+      treatment_due_at: dateToISO(document.getElementById('risk-treatment-due')?.value || ''),
+      review_at: dateToISO(document.getElementById('risk-review-at')?.value || ''),
+      threats: linesOf('risk-threats'),
+      vulnerabilities: linesOf('risk-vulnerabilities'),
+      treatment_plan_md: (document.getElementById('risk-treatment-plan')?.value || '').trim(),
+      tags: selectedValues('risk-tags'),
+      version: parseInt(document.getElementById('risk-form')?.dataset.version || '1', 10) || 1
+    };
+    if (!payload.title) {
+      showAlert(alert, t('risks.titleRequired'));
+      return;
+    }
+    try {
+      if (id) {
+        await Api.put(`/api/risks/${id}`, payload);
+      } else {
+        await Api.post('/api/risks', payload);
+      }
+      await Promise.all([load(), loadHeatmap()]);
+      closeRiskModal();
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function archiveOrRestore() {
+    if (!hasPerm('risks.manage')) return;
+    const id = (document.getElementById('risk-id')?.value || '').trim();
+    if (!id) return;
+    const archived = document.getElementById('risk-archive')?.dataset.archived === '1';
+    if (!await confirmAction()) return;
+    try {
+      if (archived) {
+        await Api.post(`/api/risks/${id}/restore`, {});
+      } else {
+        await Api.del(`/api/risks/${id}`);
+      }
+      await Promise.all([load(), loadHeatmap()]);
+      openRisk(parseInt(id, 10), 'edit');
+    } catch (err) {
+      showAlert(document.getElementById('risk-modal-alert'), localizeError(err));
+    }
+  }
+
+  // --- acceptance ---
+
+  function renderAcceptance() {
+    const section = document.getElementById('risk-acceptance-section');
+    const stateEl = document.getElementById('risk-acceptance-state');
+    const item = state.current;
+    if (!section) return;
+    section.hidden = !item;
+    if (!item) return;
+    if (stateEl) {
+      if (item.accepted_at) {
+        const who = item.accepted_by && typeof UserDirectory !== 'undefined' ? UserDirectory.name(item.accepted_by) : '-';
+        const parts = [
+          `${t('risks.acceptance.signedBy')} ${who}, ${formatDate(item.accepted_at)}`,
+          acceptanceText(item)
+        ];
+        if (item.acceptance_note) parts.push(item.acceptance_note);
+        stateEl.textContent = parts.join(' · ');
+        stateEl.classList.toggle('danger', !!item.acceptance_expired);
+      } else {
+        stateEl.textContent = t('risks.acceptance.none');
+        stateEl.classList.remove('danger');
+      }
+    }
+    const canAccept = hasPerm('risks.accept') && !item.deleted_at;
+    const form = document.getElementById('risk-acceptance-form');
+    if (form) form.hidden = !canAccept;
+    const revoke = document.getElementById('risk-accept-revoke');
+    if (revoke) revoke.hidden = !item.accepted_at;
+  }
+
+  async function acceptRisk() {
+    const item = state.current;
+    if (!item || !hasPerm('risks.accept')) return;
+    const alert = document.getElementById('risk-modal-alert');
+    const expires = dateToISO(document.getElementById('risk-acceptance-expires')?.value || '');
+    if (!expires) {
+      showAlert(alert, t('risks.acceptance.expiryRequired'));
+      return;
+    }
+    try {
+      state.current = await Api.post(`/api/risks/${item.id}/accept`, {
+        expires_at: expires,
+        note: (document.getElementById('risk-acceptance-note')?.value || '').trim()
+      });
+      fillForm(state.current);
+      renderAcceptance();
+      await load();
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function revokeAcceptance() {
+    const item = state.current;
+    if (!item || !hasPerm('risks.accept')) return;
+    if (!await confirmAction()) return;
+    try {
+      state.current = await Api.del(`/api/risks/${item.id}/accept`);
+      fillForm(state.current);
+      renderAcceptance();
+      await load();
+    } catch (err) {
+      showAlert(document.getElementById('risk-modal-alert'), localizeError(err));
+    }
+  }
+
+  // --- treatment task ---
+
+  async function prepareTaskSection() {
+    const section = document.getElementById('risk-task-section');
+    const item = state.current;
+    const allowed = !!item && !item.deleted_at && hasPerm('risks.manage') && hasPerm('tasks.create');
+    if (section) section.hidden = !allowed;
+    if (!allowed) return;
+    if (!state.boards.length) {
+      try {
+        const res = await Api.get('/api/tasks/boards');
+        state.boards = res.items || [];
+      } catch (_) {
+        state.boards = [];
+      }
+    }
+    const select = document.getElementById('risk-task-board');
+    if (!select) return;
+    select.innerHTML = '';
+    state.boards.forEach((b) => {
+      const opt = document.createElement('option');
+      opt.value = String(b.id);
+      opt.textContent = b.name || `#${b.id}`;
+      select.appendChild(opt);
+    });
+    await loadTaskColumns();
+  }
+
+  async function loadTaskColumns() {
+    const boardId = document.getElementById('risk-task-board')?.value || '';
+    const select = document.getElementById('risk-task-column');
+    if (!select) return;
+    select.innerHTML = '';
+    if (!boardId) return;
+    try {
+      const res = await Api.get(`/api/tasks/boards/${boardId}/columns`);
+      (res.items || []).filter(c => c.is_active !== false && !c.is_final).forEach((c) => {
+        const opt = document.createElement('option');
+        opt.value = String(c.id);
+        opt.textContent = c.name || `#${c.id}`;
+        select.appendChild(opt);
+      });
+    } catch (_) {
+      // board may be hidden from the user; leave the list empty
+    }
+  }
+
+  async function createTask() {
+    const item = state.current;
+    if (!item) return;
+    const alert = document.getElementById('risk-modal-alert');
+    const columnId = parseInt(document.getElementById('risk-task-column')?.value || '0', 10) || 0;
+    if (!columnId) {
+      showAlert(alert, t('tasks.columnRequired'));
+      return;
+    }
+    try {
+      await Api.post(`/api/risks/${item.id}/tasks`, {
+        column_id: columnId,
+        assign_owner: !!document.getElementById('risk-task-assign-owner')?.checked
+      });
+      await loadLinks(item.id);
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  // --- links ---
+
+  async function loadLinks(id) {
+    state.links = [];
+    try {
+      const res = await Api.get(`/api/risks/${id}/links`);
+      state.links = res.items || [];
+    } catch (_) {
+      state.links = [];
+    }
+    renderLinks();
+    refreshLinkTargets();
+  }
+
+  function renderLinks() {
+    const list = document.getElementById('risk-links-list');
+    const empty = document.getElementById('risk-links-empty');
+    if (!list) return;
+    list.innerHTML = '';
+    if (!state.links.length) {
+      if (empty) empty.hidden = false;
+      return;
+    }
+    if (empty) empty.hidden = true;
+    state.links.forEach((l) => {
+      const row = document.createElement('div');
+      row.className = 'link-item';
+      const label = `${linkTypeLabel(l.target_type)} #${l.target_id}`;
+      const title = l.target_title ? ` - ${l.target_title}` : '';
+      row.innerHTML = `<span>${escapeHtml(label)}${title ? ` <span class="muted">${escapeHtml(title)}</span>` : ''}</span><span class="muted">${escapeHtml(relationLabel(l.relation_type))}</span>`;
+      const actions = document.createElement('div');
+      actions.className = 'table-actions';
+      const openHref = linkHref(l.target_type, l.target_id);
+      if (openHref) {
+        const open = document.createElement('a');
+        open.className = 'btn ghost btn-xs';
+        open.href = openHref;
+        open.textContent = t('controls.links.open');
+        actions.appendChild(open);
+      }
+      if (hasPerm('risks.manage')) {
+        const del = document.createElement('button');
+        del.type = 'button';
+        del.className = 'btn ghost btn-xs';
+        del.textContent = t('risks.actions.removeLink');
+        del.addEventListener('click', () => deleteLink(l.id));
+        actions.appendChild(del);
+      }
+      row.appendChild(actions);
+      list.appendChild(row);
+    });
+  }
+
+  async function ensureLinkOptions() {
+    if (state.linkOptionsLoaded) return;
+    try {
+      const [assetsRes, controlsRes, findingsRes, vulnsRes, tasksRes] = await Promise.all([
+        Api.get('/api/assets/list?limit=200').catch(() => ({ items: [] })),
+        Api.get('/api/controls?limit=200').catch(() => ({ items: [] })),
+        Api.get('/api/findings/list?limit=200').catch(() => ({ items: [] })),
+        Api.get('/api/vulnerabilities?limit=200').catch(() => ({ items: [] })),
+        Api.get('/api/tasks?limit=200').catch(() => ({ items: [] })),
+      ]);
+      state.linkOptions = {
+        assets: assetsRes.items || [],
+        controls: controlsRes.items || [],
+        findings: findingsRes.items || [],
+        vulnerabilities: vulnsRes.items || [],
+        tasks: tasksRes.items || []
+      };
+    } finally {
+      state.linkOptionsLoaded = true;
+    }
+  }
+
+  function refreshLinkTargets() {
+    const type = document.getElementById('risk-link-target-type')?.value || '';
+    const select = document.getElementById('risk-link-target-id');
+    const search = (document.getElementById('risk-link-search')?.value || '').toLowerCase().trim();
+    if (!select) return;
+    select.innerHTML = '';
+    const placeholder = document.createElement('option');
+    placeholder.value = '';
+    placeholder.textContent = t('risks.links.targetPlaceholder');
+    select.appendChild(placeholder);
+    if (!type) return;
+    ensureLinkOptions().then(() => {
+      const source = {
+        asset: state.linkOptions.assets,
+        control: state.linkOptions.controls,
+        finding: state.linkOptions.findings,
+        vulnerability: state.linkOptions.vulnerabilities,
+        task: state.linkOptions.tasks
+      }[type] || [];
+      source
+        .filter(item => linkOptionLabel(type, item).toLowerCase().includes(search))
+        .forEach(item => {
+          const opt = document.createElement('option');
+          opt.value = String(item.id || '');
+          opt.textContent = linkOptionLabel(type, item);
+          select.appendChild(opt);
+        });
+    });
+  }
+
+  function linkOptionLabel(type, item) {
+    if (!item) return '';
+    if (type === 'asset') return `#${item.id} ${item.name || ''}`.trim();
+    if (type === 'control') {
+      const code = item.code ? `${item.code} - ` : '';
+      return `${code}${item.title || ''}`.trim();
+    }
+    if (type === 'vulnerability') return `${item.external_id || `#${item.id}`} ${item.title || ''}`.trim();
+    return `#${item.id} ${item.title || ''}`.trim();
+  }
+
+  async function addLink() {
+    const riskId = document.getElementById('risk-id')?.value || '';
+    if (!riskId || !hasPerm('risks.manage')) return;
+    const targetType = document.getElementById('risk-link-target-type')?.value || '';
+    const targetId = document.getElementById('risk-link-target-id')?.value || '';
+    const relationType = document.getElementById('risk-link-relation')?.value || '';
+    const alert = document.getElementById('risk-modal-alert');
+    if (!targetType || !targetId) {
+      showAlert(alert, t('risks.links.required'));
+      return;
+    }
+    try {
+      await Api.post(`/api/risks/${riskId}/links`, {
+        target_type: targetType,
+        target_id: targetId.trim(),
+        relation_type: relationType
+      });
+      document.getElementById('risk-link-target-id').value = '';
+      document.getElementById('risk-link-search').value = '';
+      await loadLinks(riskId);
+    } catch (err) {
+      showAlert(alert, localizeError(err));
+    }
+  }
+
+  async function deleteLink(linkId) {
+    const riskId = document.getElementById('risk-id')?.value || '';
+    if (!riskId || !linkId) return;
+    if (!await confirmAction()) return;
+    try {
+      await Api.del(`/api/risks/${riskId}/links/${linkId}`);
+      await loadLinks(riskId);
+    } catch (err) {
+      showAlert(document.getElementById('risk-modal-alert'), localizeError(err));
+    }
+  }
+
+  function linkHref(type, id) {
+    if (!type || !id) return '';
+    const tt = String(type).toLowerCase();
+    if (tt === 'asset') return `/assets?asset=${encodeURIComponent(id)}`;
+    if (tt === 'control') return `/registry/controls?control=${encodeURIComponent(id)}`;
+    if (tt === 'finding') return `/registry/findings?finding=${encodeURIComponent(id)}`;
+    if (tt === 'task') return `/tasks/task/${encodeURIComponent(id)}`;
+    return '';
+  }
+
+  function linkTypeLabel(val) {
+    return tOr(`risks.links.type.${val}`, val || '-');
+  }
+
+  function relationLabel(val) {
+    return tOr(`risks.links.relation.${val}`, val || '-');
+  }
+
+  // --- helpers ---
+
+  function confirmAction() {
+    return window.AppConfirm?.ask
+      ? window.AppConfirm.ask(t('common.confirm'), {
+        title: t('common.confirm'),
+        confirmText: t('common.confirm'),
+        cancelText: t('common.cancel'),
+        danger: true,
+      })
+      : Promise.resolve(window.confirm(t('common.confirm')));
+  }
+
+  function localizeError(err) {
+    const raw = (err && err.message ? err.message : '').trim();
+    const msg = raw ? t(raw) : t('common.error');
+    return msg || raw || 'error';
+  }
+
+  function showAlert(el, msg) {
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+  }
+
+  function escapeHtml(str) {
+    return (str || '').toString().replace(/[&<>"']/g, (c) => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+  }
+
+  function setVal(id, val) {
+    const el = document.getElementById(id);
+    if (el) el.value = val;
+  }
+
+  function formatDateInput(raw) {
+    try {
+      const dt = new Date(raw);
+      const pad = (n) => String(n).padStart(2, '0');
+      return `${dt.getFullYear()}-${pad(dt.getMonth() + 1)}-${pad(dt.getDate())}`;
+    } catch (_) {
+      return '';
+    }
+  }
+
+  function dateToISO(val) {
+    const s = String(val || '').trim();
+    if (!s) return null;
+    try {
+      return new Date(`${s}T00:00:00Z`).toISOString();
+    } catch (_) {
+      return null;
+    }
+  }
+
+  function formatDate(raw) {
+    if (!raw) return '-';
+    if (typeof AppTime !== 'undefined' && AppTime.formatDate) return AppTime.formatDate(raw);
+    return raw;
+  }
+
+  function formatDateTime(raw) {
+    if (!raw) return '-';
+    if (typeof AppTime !== 'undefined' && AppTime.formatDateTime) return AppTime.formatDateTime(raw);
+    return raw;
+  }
+
+  return { init, openRisk };
+})();
@@ -0,0 +1,202 @@
+var SavedSearchBar = (() => {
+  let groupsLoading;
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function escapeHtml(str) {
+    return String(str == null ? '' : str)
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  function errorText(err) {
+    const raw = (err && err.message ? err.message : '').trim();
+    return raw ? t(raw) : t('common.error');
+  }
+
+  function loadGroups() {
+    if (!groupsLoading) {
+      groupsLoading = Api.get('/api/saved-searches/groups')
+        .then(res => (Array.isArray(res.items) ? res.items : []))
+        .catch(() => []);
+    }
+    return groupsLoading;
+  }
+
+  // mount renders the query input and the saved search controls of a list page into
+  // container. onApply is called whenever the query changes; the returned bar exposes
+  // the current query and reports list errors.
+  function mount(container, entity, onApply) {
+    if (!container) return null;
+    const state = { items: [], selectedId: '' };
+    container.classList.add('saved-search-bar');
+    container.innerHTML = `
+      <div class="form-grid three-column">
+        <div class="form-field saved-search-query">
+          <label>${escapeHtml(t('savedSearches.query'))}</label>
+          <input type="search" data-role="query" placeholder="${escapeHtml(t('savedSearches.queryPlaceholder'))}">
+        </div>
+        <div class="form-field">
+          <label>${escapeHtml(t('savedSearches.title'))}</label>
+          <select class="select" data-role="select"></select>
+        </div>
+        <div class="form-actions-inline">
+          <button type="button" class="btn ghost" data-role="apply">${escapeHtml(t('common.apply'))}</button>
+          <button type="button" class="btn ghost" data-role="save">${escapeHtml(t('savedSearches.save'))}</button>
+          <button type="button" class="btn ghost danger" data-role="delete" hidden>${escapeHtml(t('savedSearches.delete'))}</button>
+        </div>
+      </div>
+      <div class="saved-search-form" data-role="form" hidden>
+        <div class="form-grid three-column">
+          <div class="form-field required">
+            <label>${escapeHtml(t('savedSearches.name'))}</label>
+            <input class="input" data-role="name" maxlength="200">
+          </div>
+          <div class="form-field">
+            <label>${escapeHtml(t('savedSearches.groups'))}</label>
+            <select class="select" multiple data-role="groups"></select>
+          </div>
+          <div class="form-field form-field-inline">
+            <label class="checkbox switch">
+              <input type="checkbox" data-role="dashboard">
+              <span>${escapeHtml(t('savedSearches.dashboard'))}</span>
+            </label>
+          </div>
+        </div>
+        <div class="form-actions-inline">
+          <button type="button" class="btn primary" data-role="confirm">${escapeHtml(t('common.save'))}</button>
+          <button type="button" class="btn ghost" data-role="saveAs" hidden>${escapeHtml(t('savedSearches.saveAsNew'))}</button>
+          <button type="button" class="btn ghost" data-role="cancel">${escapeHtml(t('common.cancel'))}</button>
+        </div>
+      </div>
+      <div class="alert" data-role="error" hidden></div>`;
+    const el = (role) => container.querySelector(`[data-role="${role}"]`);
+
+    function selected() {
+      return state.items.find(item => String(item.id) === String(state.selectedId)) || null;
+    }
+
+    function showError(err) {
+      const box = el('error');
+      if (!box) return;
+      box.textContent = err ? errorText(err) : '';
+      box.hidden = !err;
+    }
+
+    function renderSelect() {
+      const select = el('select');
+      select.innerHTML = `<option value="">${escapeHtml(t('savedSearches.none'))}</option>`
+        + state.items.map(item => `<option value="${escapeHtml(item.id)}">${escapeHtml(item.name)}</option>`).join('');
+      select.value = state.selectedId;
+      const current = selected();
+      el('delete').hidden = !(current && current.can_edit);
+    }
+
+    async function loadItems() {
+      try {
+        const res = await Api.get(`/api/saved-searches?entity_type=${encodeURIComponent(entity)}`);
+        state.items = Array.isArray(res.items) ? res.items : [];
+      } catch (_) {
+        state.items = [];
+      }
+      if (!selected()) state.selectedId = '';
+      renderSelect();
+    }
+
+    function apply() {
+      showError(null);
+      if (typeof onApply === 'function') onApply();
+    }
+
+    async function openForm() {
+      const current = selected();
+      const groups = await loadGroups();
+      const chosen = (current && current.can_edit && Array.isArray(current.group_ids)) ? current.group_ids.map(String) : [];
+      el('groups').innerHTML = groups
+        .map(g => `<option value="${escapeHtml(g.id)}"${chosen.includes(String(g.id)) ? ' selected' : ''}>${escapeHtml(g.name)}</option>`)
+        .join('');
+      el('name').value = (current && current.can_edit) ? current.name : '';
+      el('dashboard').checked = !!(current && current.can_edit && current.dashboard);
+      el('saveAs').hidden = !(current && current.can_edit);
+      el('form').hidden = false;
+      el('name').focus();
+    }
+
+    async function save(asNew) {
+      const payload = {
+        entity_type: entity,
+        name: el('name').value.trim(),
+        query: el('query').value.trim(),
+        group_ids: Array.from(el('groups').selectedOptions).map(o => parseInt(o.value, 10)).filter(Boolean),
+        dashboard: el('dashboard').checked
+      };
+      const current = selected();
+      try {
+        let saved;
+        if (current && current.can_edit && !asNew) {
+          saved = await Api.put(`/api/saved-searches/${current.id}`, payload);
+        } else {
+          saved = await Api.post('/api/saved-searches', payload);
+        }
+        state.selectedId = saved && saved.id ? String(saved.id) : '';
+        el('form').hidden = true;
+        showError(null);
+        await loadItems();
+      } catch (err) {
+        showError(err);
+      }
+    }
+
+    async function remove() {
+      const current = selected();
+      if (!current || !current.can_edit) return;
+      if (!window.confirm(t('savedSearches.deleteConfirm'))) return;
+      try {
+        await Api.del(`/api/saved-searches/${current.id}`);
+        state.selectedId = '';
+        await loadItems();
+      } catch (err) {
+        showError(err);
+      }
+    }
+
+    el('query').addEventListener('keydown', (e) => {
+      if (e.key !== 'Enter') return;
+      e.preventDefault();
+      apply();
+    });
+    el('apply').addEventListener('click', () => apply());
+    el('select').addEventListener('change', () => {
+      state.selectedId = el('select').value;
+      const current = selected();
+      el('query').value = current ? current.query : '';
+      el('form').hidden = true;
+      renderSelect();
+      apply();
+    });
+    el('save').addEventListener('click', () => openForm());
+    el('confirm').addEventListener('click', () => save(false));
+    el('saveAs').addEventListener('click', () => save(true));
+    el('cancel').addEventListener('click', () => { el('form').hidden = true; });
+    el('delete').addEventListener('click', () => remove());
+    loadItems();
+
+    return {
+      query: () => el('query').value.trim(),
+      setQuery: (value) => { el('query').value = value || ''; },
+      showError,
+      reload: loadItems
+    };
+  }
+
+  return { mount };
+})();
+
+if (typeof window !== 'undefined') {
+  window.SavedSearchBar = SavedSearchBar;
+}
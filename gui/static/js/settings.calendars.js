@@ -0,0 +1,231 @@
+(() => {
+  if (typeof window === 'undefined') return;
+  if (window.SettingsCalendars && window.SettingsCalendars.bind) return;
+
+  const WEEKDAYS = [1, 2, 3, 4, 5, 6, 0];
+  let items = [];
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function localizeError(err) {
+    const raw = (err && err.message ? err.message : '').trim();
+    const msg = raw ? t(raw) : t('common.error');
+    return msg || raw || 'error';
+  }
+
+  function showAlert(msg, success) {
+    const el = document.getElementById('calendars-alert');
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+    if (success) el.classList.add('success'); else el.classList.remove('success');
+  }
+
+  function escapeHtml(str) {
+    return String(str || '')
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  function weekdayLabel(d) {
+    return t(['common.sun', 'common.mon', 'common.tue', 'common.wed', 'common.thu', 'common.fri', 'common.sat'][d]);
+  }
+
+  function formatHolidays(list) {
+    return (list || []).map(h => (h.name ? `${h.date} ${h.name}` : h.date)).join('\n');
+  }
+
+  function parseHolidays(value) {
+    return (value || '')
+      .split('\n')
+      .map(line => line.trim())
+      .filter(Boolean)
+      .map((line) => {
+        const idx = line.search(/\s/);
+        if (idx < 0) return { date: line, name: '' };
+        return { date: line.slice(0, idx), name: line.slice(idx).trim() };
+      });
+  }
+
+  async function refresh() {
+    const res = await Api.get('/api/schedule/calendars');
+    items = Array.isArray(res.items) ? res.items : [];
+    render();
+  }
+
+  function render() {
+    const table = document.getElementById('calendars-table');
+    const empty = document.getElementById('calendars-empty');
+    if (!table) return;
+    const tbody = table.querySelector('tbody');
+    tbody.innerHTML = items.map(c => `<tr>
+        <td>${escapeHtml(c.name)}${c.description ? `<div class="muted">${escapeHtml(c.description)}</div>` : ''}</td>
+        <td>${escapeHtml((c.weekend || []).map(weekdayLabel).join(', '))}</td>
+        <td>${c.holiday_count || 0}</td>
+        <td class="actions">
+          <button type="button" class="btn ghost btn-sm" data-action="edit" data-id="${c.id}">${escapeHtml(t('common.edit'))}</button>
+          <button type="button" class="btn ghost btn-sm" data-action="delete" data-id="${c.id}">${escapeHtml(t('common.delete'))}</button>
+        </td>
+      </tr>`).join('');
+    if (empty) empty.hidden = items.length > 0;
+  }
+
+  function formEl() {
+    return document.getElementById('calendars-form');
+  }
+
+  function renderWeekend(selected) {
+    const box = document.getElementById('calendar-weekend');
+    if (!box) return;
+    const set = new Set(selected || []);
+    box.innerHTML = WEEKDAYS.map(d => `<label class="checkbox">
+        <input type="checkbox" value="${d}"${set.has(d) ? ' checked' : ''}>
+        <span>${escapeHtml(weekdayLabel(d))}</span>
+      </label>`).join('');
+  }
+
+  function selectedWeekend() {
+    return Array.from(document.querySelectorAll('#calendar-weekend input:checked'))
+      .map(el => parseInt(el.value, 10));
+  }
+
+  function setImportEnabled(enabled) {
+    const btn = document.getElementById('calendars-import');
+    if (btn) btn.disabled = !enabled;
+  }
+
+  function resetForm() {
+    const form = formEl();
+    if (!form) return;
+    form.reset();
+    form.elements.id.value = '';
+    renderWeekend([0, 6]);
+    setImportEnabled(false);
+    const title = document.getElementById('calendars-form-title');
+    if (title) title.textContent = t('schedule.calendars.add');
+  }
+
+  async function edit(id) {
+    const form = formEl();
+    if (!form) return;
+    try {
+      const cal = await Api.get(`/api/schedule/calendars/${id}`);
+      form.elements.id.value = cal.id;
+      form.elements.name.value = cal.name || '';
+      form.elements.description.value = cal.description || '';
+      form.elements.holidays.value = formatHolidays(cal.holidays);
+      renderWeekend(cal.weekend);
+      setImportEnabled(true);
+      const title = document.getElementById('calendars-form-title');
+      if (title) title.textContent = t('schedule.calendars.edit');
+      form.scrollIntoView({ behavior: 'smooth', block: 'nearest' });
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  async function save() {
+    const form = formEl();
+    if (!form) return;
+    const id = parseInt(form.elements.id.value, 10);
+    const payload = {
+      name: form.elements.name.value.trim(),
+      description: form.elements.description.value.trim(),
+      weekend: selectedWeekend(),
+      holidays: parseHolidays(form.elements.holidays.value),
+    };
+    try {
+      if (id) {
+        await Api.put(`/api/schedule/calendars/${id}`, payload);
+      } else {
+        await Api.post('/api/schedule/calendars', payload);
+      }
+      showAlert(t('common.saved'), true);
+      resetForm();
+      await refresh();
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  async function importICS() {
+    const form = formEl();
+    const input = document.getElementById('calendar-import-file');
+    const id = form ? parseInt(form.elements.id.value, 10) : 0;
+    if (!id || !input || !input.files || !input.files[0]) {
+      showAlert(t('import.fileRequired'));
+      return;
+    }
+    const fd = new FormData();
+    fd.append('file', input.files[0]);
+    if ((document.getElementById('calendar-import-replace') || {}).checked) fd.append('replace', '1');
+    try {
+      const cal = await Api.upload(`/api/schedule/calendars/${id}/import`, fd);
+      form.elements.holidays.value = formatHolidays(cal.holidays);
+      input.value = '';
+      showAlert(t('schedule.calendars.imported'), true);
+      await refresh();
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  async function remove(id) {
+    if (!window.confirm(t('schedule.calendars.deleteConfirm'))) return;
+    try {
+      await Api.del(`/api/schedule/calendars/${id}`);
+      showAlert('');
+      resetForm();
+      await refresh();
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  function bind() {
+    const table = document.getElementById('calendars-table');
+    if (!table || table.dataset.bound) return;
+    table.dataset.bound = '1';
+    table.addEventListener('click', (e) => {
+      const btn = e.target.closest('button[data-action]');
+      if (!btn) return;
+      const id = parseInt(btn.dataset.id, 10);
+      if (btn.dataset.action === 'edit') {
+        edit(id);
+      } else if (btn.dataset.action === 'delete') {
+        remove(id);
+      }
+    });
+    const saveBtn = document.getElementById('calendars-save');
+    if (saveBtn) {
+      saveBtn.addEventListener('click', (e) => {
+        e.preventDefault();
+        save();
+      });
+    }
+    const importBtn = document.getElementById('calendars-import');
+    if (importBtn) {
+      importBtn.addEventListener('click', (e) => {
+        e.preventDefault();
+        importICS();
+      });
+    }
+    const resetBtn = document.getElementById('calendars-reset');
+    if (resetBtn) {
+      resetBtn.addEventListener('click', (e) => {
+        e.preventDefault();
+        resetForm();
+        showAlert('');
+      });
+    }
+    resetForm();
+    refresh().catch(err => showAlert(localizeError(err)));
+  }
+
+  window.SettingsCalendars = { bind };
+})();
@@ -0,0 +1,186 @@
+(() => {
+  if (typeof window === 'undefined') return;
+  if (window.SettingsCustomFields && window.SettingsCustomFields.bind) return;
+
+  let items = [];
+
+  function t(key) {
+    return (typeof BerkutI18n !== 'undefined' && BerkutI18n.t) ? BerkutI18n.t(key) : key;
+  }
+
+  function localizeError(err) {
+    const raw = (err && err.message ? err.message : '').trim();
+    const msg = raw ? t(raw) : t('common.error');
+    return msg || raw || 'error';
+  }
+
+  function showAlert(msg, success) {
+    const el = document.getElementById('custom-fields-alert');
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+    if (success) el.classList.add('success'); else el.classList.remove('success');
+  }
+
+  function escapeHtml(str) {
+    return String(str || '')
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  function splitList(value, sep) {
+    return (value || '')
+      .split(sep)
+      .map(s => s.trim())
+      .filter(Boolean);
+  }
+
+  async function refresh() {
+    const res = await Api.get('/api/custom-fields/all');
+    items = Array.isArray(res.items) ? res.items : [];
+    render();
+  }
+
+  function render() {
+    const table = document.getElementById('custom-fields-table');
+    const empty = document.getElementById('custom-fields-empty');
+    if (!table) return;
+    const tbody = table.querySelector('tbody');
+    const entity = (document.getElementById('custom-fields-entity-filter') || {}).value || '';
+    const showArchived = !!(document.getElementById('custom-fields-show-archived') || {}).checked;
+    const rows = items.filter(f => (!entity || f.entity_type === entity) && (showArchived || !f.deleted_at));
+    tbody.innerHTML = rows.map((f) => {
+      const archived = !!f.deleted_at;
+      const actions = archived
+        ? `<span class="muted">${escapeHtml(t('common.archived'))}</span>`
+        : `<button type="button" class="btn ghost btn-sm" data-action="edit" data-id="${f.id}">${escapeHtml(t('common.edit'))}</button>
+           <button type="button" class="btn ghost btn-sm" data-action="archive" data-id="${f.id}">${escapeHtml(t('customFields.settings.archive'))}</button>`;
+      return `<tr${archived ? ' class="muted"' : ''}>
+        <td>${escapeHtml(t(`customFields.entity.${f.entity_type}`))}</td>
+        <td><code>${escapeHtml(f.key)}</code></td>
+        <td>${escapeHtml(f.label)}</td>
+        <td>${escapeHtml(t(`customFields.type.${f.field_type}`))}</td>
+        <td>${f.required ? escapeHtml(t('common.yes')) : ''}</td>
+        <td>${escapeHtml((f.view_roles || []).join(', '))}</td>
+        <td>${escapeHtml((f.edit_roles || []).join(', '))}</td>
+        <td class="actions">${actions}</td>
+      </tr>`;
+    }).join('');
+    if (empty) empty.hidden = rows.length > 0;
+  }
+
+  function formEl() {
+    return document.getElementById('custom-fields-form');
+  }
+
+  function resetForm() {
+    const form = formEl();
+    if (!form) return;
+    form.reset();
+    form.elements.id.value = '';
+    form.elements.position.value = '0';
+    ['entity_type', 'field_type', 'key'].forEach((name) => { form.elements[name].disabled = false; });
+    const title = document.getElementById('custom-fields-form-title');
+    if (title) title.textContent = t('customFields.settings.add');
+  }
+
+  function fillForm(field) {
+    const form = formEl();
+    if (!form || !field) return;
+    form.elements.id.value = field.id;
+    form.elements.entity_type.value = field.entity_type;
+    form.elements.field_type.value = field.field_type;
+    form.elements.key.value = field.key;
+    form.elements.label.value = field.label || '';
+    form.elements.options.value = (field.options || []).join('\n');
+    form.elements.position.value = field.position || 0;
+    form.elements.view_roles.value = (field.view_roles || []).join(', ');
+    form.elements.edit_roles.value = (field.edit_roles || []).join(', ');
+    form.elements.required.checked = !!field.required;
+    ['entity_type', 'field_type', 'key'].forEach((name) => { form.elements[name].disabled = true; });
+    const title = document.getElementById('custom-fields-form-title');
+    if (title) title.textContent = t('customFields.settings.edit');
+    form.scrollIntoView({ behavior: 'smooth', block: 'nearest' });
+  }
+
+  async function save() {
+    const form = formEl();
+    if (!form) return;
+    const id = parseInt(form.elements.id.value, 10);
+    const payload = {
+      entity_type: form.elements.entity_type.value,
+      field_type: form.elements.field_type.value,
+      key: form.elements.key.value.trim(),
+      label: form.elements.label.value.trim(),
+      options: splitList(form.elements.options.value, '\n'),
+      position: parseInt(form.elements.position.value, 10) || 0,
+      view_roles: splitList(form.elements.view_roles.value, ','),
+      edit_roles: splitList(form.elements.edit_roles.value, ','),
+      required: form.elements.required.checked,
+    };
+    try {
+      if (id) {
+        await Api.put(`/api/custom-fields/${id}`, payload);
+      } else {
+        await Api.post('/api/custom-fields', payload);
+      }
+      showAlert(t('common.saved'), true);
+      resetForm();
+      await refresh();
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  async function archive(id) {
+    if (!window.confirm(t('customFields.settings.archiveConfirm'))) return;
+    try {
+      await Api.del(`/api/custom-fields/${id}`);
+      showAlert('');
+      await refresh();
+    } catch (err) {
+      showAlert(localizeError(err));
+    }
+  }
+
+  function bind() {
+    const table = document.getElementById('custom-fields-table');
+    if (!table || table.dataset.bound) return;
+    table.dataset.bound = '1';
+    table.addEventListener('click', (e) => {
+      const btn = e.target.closest('button[data-action]');
+      if (!btn) return;
+      const id = parseInt(btn.dataset.id, 10);
+      if (btn.dataset.action === 'edit') {
+        fillForm(items.find(f => f.id === id));
+      } else if (btn.dataset.action === 'archive') {
+        archive(id);
+      }
+    });
+    const filter = document.getElementById('custom-fields-entity-filter');
+    if (filter) filter.addEventListener('change', render);
+    const archived = document.getElementById('custom-fields-show-archived');
+    if (archived) archived.addEventListener('change', render);
+    const saveBtn = document.getElementById('custom-fields-save');
+    if (saveBtn) {
+      saveBtn.addEventListener('click', (e) => {
+        e.preventDefault();
+        save();
+      });
+    }
+    const resetBtn = document.getElementById('custom-fields-reset');
+    if (resetBtn) {
+      resetBtn.addEventListener('click', (e) => {
+        e.preventDefault();
+        resetForm();
+        showAlert('');
+      });
+    }
+    refresh().catch(err => showAlert(localizeError(err)));
+  }
+
+  window.SettingsCustomFields = { bind };
+})();
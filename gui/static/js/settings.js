@@ -244,6 +244,8 @@ const SettingsPage = (() => {
     'settings-incidents': 'settings.incident_options',
     'settings-controls': 'settings.controls',
     'settings-sources': 'settings.detection_sources',
+    'settings-custom-fields': 'settings.custom_fields',
+    'settings-calendars': 'settings.calendars',
   };
   const CLEANUP_TARGETS = {
     monitoring: { keys: [], prefixes: ['monitoring.'], remoteCleanup: cleanupMonitoringRemote },
@@ -337,6 +339,12 @@ const SettingsPage = (() => {
       if (canViewTab('settings-controls')) {
         bindControlsSettings(alertBox);
       }
+      if (canViewTab('settings-custom-fields') && window.SettingsCustomFields && typeof window.SettingsCustomFields.bind === 'function') {
+        window.SettingsCustomFields.bind();
+      }
+      if (canViewTab('settings-calendars') && window.SettingsCalendars && typeof window.SettingsCalendars.bind === 'function') {
+        window.SettingsCalendars.bind();
+      }
       const target = tabFromPath();
       const initialTab = (target && canViewTab(target)) ? target : firstAllowedTab() || activeTab;
       switchTab(initialTab);
@@ -0,0 +1,96 @@
+(() => {
+  if (typeof window === 'undefined') return;
+  if (window.SettingsNotifications && window.SettingsNotifications.bind) return;
+
+  let state = { eventTypes: [], preferences: {}, channels: [] };
+
+  function showAlert(el, msg, success) {
+    if (!el) return;
+    el.textContent = msg || '';
+    el.hidden = !msg;
+    if (success) el.classList.add('success'); else el.classList.remove('success');
+  }
+
+  function escapeHtml(str) {
+    return String(str || '')
+      .replace(/&/g, '&amp;')
+      .replace(/</g, '&lt;')
+      .replace(/>/g, '&gt;')
+      .replace(/"/g, '&quot;')
+      .replace(/'/g, '&#39;');
+  }
+
+  function eventLabel(type) {
+    const key = `app.notifications.event.${type}`;
+    const label = BerkutI18n.t(key);
+    return label && label !== key ? label : type;
+  }
+
+  async function refresh() {
+    const res = await Api.get('/api/notifications/preferences');
+    const prefs = {};
+    (Array.isArray(res.preferences) ? res.preferences : []).forEach((p) => {
+      if (p && p.event_type) prefs[p.event_type] = p;
+    });
+    state = {
+      eventTypes: Array.isArray(res.event_types) ? res.event_types : [],
+      preferences: prefs,
+      channels: Array.isArray(res.channels) ? res.channels : [],
+    };
+    render();
+  }
+
+  function render() {
+    const body = document.getElementById('notify-prefs-body');
+    const empty = document.getElementById('notify-prefs-no-channels');
+    if (!body) return;
+    if (empty) empty.hidden = state.channels.length > 0;
+    body.innerHTML = '';
+    state.eventTypes.forEach((type) => {
+      const pref = state.preferences[type] || {};
+      const options = [`<option value="">${escapeHtml(BerkutI18n.t('app.notifications.prefs.inAppOnly'))}</option>`]
+        .concat(state.channels.map((ch) => {
+          const selected = pref.external && Number(pref.channel_id) === Number(ch.id) ? ' selected' : '';
+          return `<option value="${escapeHtml(ch.id)}"${selected}>${escapeHtml(ch.name)}</option>`;
+        }));
+      const tr = document.createElement('tr');
+      tr.innerHTML = `
+        <td>${escapeHtml(eventLabel(type))}</td>
+        <td><select class="select select-compact" data-event-type="${escapeHtml(type)}"${state.channels.length ? '' : ' disabled'}>${options.join('')}</select></td>
+      `;
+      body.appendChild(tr);
+    });
+  }
+
+  async function save(alertBox) {
+    const selects = document.querySelectorAll('#notify-prefs-body select[data-event-type]');
+    const preferences = [];
+    selects.forEach((sel) => {
+      const channelID = parseInt(sel.value, 10);
+      preferences.push({
+        event_type: sel.dataset.eventType,
+        external: !!channelID,
+        channel_id: channelID || null,
+      });
+    });
+    try {
+      await Api.put('/api/notifications/preferences', { preferences });
+      showAlert(alertBox, BerkutI18n.t('common.saved'), true);
+      await refresh();
+    } catch (err) {
+      showAlert(alertBox, err.message || BerkutI18n.t('common.error'));
+    }
+  }
+
+  function bind(alertBox) {
+    const saveBtn = document.getElementById('notify-prefs-save');
+    if (!saveBtn) return;
+    saveBtn.addEventListener('click', async (e) => {
+      e.preventDefault();
+      await save(alertBox);
+    });
+    refresh().catch(() => {});
+  }
+
+  window.SettingsNotifications = { bind };
+})();
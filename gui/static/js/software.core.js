@@ -362,6 +362,9 @@ const SoftwarePage = (() => {
     if (typeof RegistryReports !== 'undefined' && RegistryReports.bind) {
       RegistryReports.bind('software-create-report', 'software');
     }
+    if (typeof RegistryImport !== 'undefined' && RegistryImport.bind) {
+      RegistryImport.bind('software-import', 'software', refresh);
+    }
     const includeField = document.getElementById('software-include-deleted-field');
     if (includeField) includeField.hidden = !state.canManage;
 
@@ -260,6 +260,28 @@ const SoftwareDetail = (() => {
     }
   }
 
+  async function importEOL(file) {
+    const productID = state.ctx?.getOpenProductId ? state.ctx.getOpenProductId() : 0;
+    if (!file || !productID) return;
+    const createVersions = await confirmAction(t('software.eol.createVersionsConfirm'), {
+      title: t('software.eol.import'),
+      confirmText: t('common.yes'),
+      cancelText: t('common.no'),
+    });
+    const fd = new FormData();
+    fd.append('file', file);
+    try {
+      const res = await Api.upload(`/api/software/${productID}/eol/import?create_versions=${createVersions ? '1' : '0'}`, fd);
+      alert(t('software.eol.importDone')
+        .replace('{updated}', res.updated || 0)
+        .replace('{created}', res.versions_created || 0)
+        .replace('{unmatched}', res.unmatched || 0));
+      await loadVersions(productID, { canManage: true, readOnly: false });
+    } catch (err) {
+      alert(localizeError(err, 'software.eol.feedInvalid'));
+    }
+  }
+
   function bindUI(ctx) {
     state.ctx = ctx || null;
     if (state.bound) return;
@@ -271,6 +293,15 @@ const SoftwareDetail = (() => {
       openVersionModal({ productID, version: null, readOnly: false });
     });
     document.getElementById('software-version-save')?.addEventListener('click', () => saveVersionFromModal());
+    document.getElementById('software-eol-import')?.addEventListener('click', () => {
+      if (!state.ctx?.canManage?.()) return;
+      document.getElementById('software-eol-import-file')?.click();
+    });
+    document.getElementById('software-eol-import-file')?.addEventListener('change', async (e) => {
+      const file = e.target.files && e.target.files[0];
+      e.target.value = '';
+      await importEOL(file);
+    });
   }
 
   return { bindUI, loadForProduct, openVersionModal };
@@ -0,0 +1,258 @@
+(() => {
+  const state = TasksPage.state;
+  const { t, hasPermission, showAlert, hideAlert, openModal, closeModal, resolveErrorMessage, formatDateTime, confirmAction, escapeHtml } = TasksPage;
+
+  let currentBoardId = null;
+  let currentRuleId = null;
+  let rules = [];
+
+  function initAutomation() {
+    const closeBtn = document.getElementById('tasks-automation-close');
+    const newBtn = document.getElementById('tasks-automation-new');
+    const form = document.getElementById('tasks-automation-form');
+    const trigger = document.getElementById('tasks-automation-trigger');
+    const dryBtn = document.getElementById('tasks-automation-dry-run');
+
+    if (closeBtn) closeBtn.addEventListener('click', () => closeModal('tasks-automation-modal'));
+    if (newBtn) newBtn.addEventListener('click', () => editRule(null));
+    if (trigger) trigger.addEventListener('change', toggleColumnField);
+    if (dryBtn) dryBtn.addEventListener('click', dryRun);
+    if (form) {
+      form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        await saveRule();
+      });
+    }
+    renderPriorityOptions();
+  }
+
+  async function openAutomation(board) {
+    if (!board || !hasPermission('tasks.manage')) return;
+    currentBoardId = board.id;
+    hideAlert('tasks-automation-alert');
+    const title = document.getElementById('tasks-automation-title');
+    if (title) title.textContent = `${t('tasks.automation.title')}: ${board.name}`;
+    renderColumnOptions();
+    try {
+      await loadRules();
+      editRule(null);
+      openModal('tasks-automation-modal');
+    } catch (err) {
+      showAlert('tasks-automation-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function loadRules() {
+    const res = await Api.get(`/api/tasks/boards/${currentBoardId}/automation`);
+    rules = res.items || [];
+    renderRules();
+  }
+
+  function renderRules() {
+    const list = document.getElementById('tasks-automation-list');
+    if (!list) return;
+    list.innerHTML = '';
+    if (!rules.length) {
+      list.textContent = t('tasks.automation.empty');
+      return;
+    }
+    rules.forEach(rule => {
+      const row = document.createElement('div');
+      row.className = 'tasks-automation-rule';
+      const last = (rule.runs || [])[0];
+      const lastText = last ? `${t('tasks.automation.lastRun')}: ${formatDateTime(last.created_at)} (${t(`tasks.automation.status.${last.status}`)})` : '';
+      row.innerHTML = `
+        <div class="tasks-automation-rule-info">
+          <strong>${escapeHtml(rule.name)}</strong>
+          <span class="muted">${escapeHtml(t(`tasks.automation.triggers.${rule.trigger}`))}${rule.is_active ? '' : ` · ${escapeHtml(t('tasks.automation.inactive'))}`}</span>
+          <span class="muted">${escapeHtml(lastText)}</span>
+        </div>`;
+      const actions = document.createElement('div');
+      actions.className = 'tasks-automation-rule-actions';
+      const editBtn = document.createElement('button');
+      editBtn.type = 'button';
+      editBtn.className = 'btn ghost';
+      editBtn.textContent = t('common.edit');
+      editBtn.addEventListener('click', () => editRule(rule.id));
+      const toggleBtn = document.createElement('button');
+      toggleBtn.type = 'button';
+      toggleBtn.className = 'btn ghost';
+      toggleBtn.textContent = rule.is_active ? t('tasks.automation.disable') : t('tasks.automation.enable');
+      toggleBtn.addEventListener('click', () => toggleRule(rule));
+      const deleteBtn = document.createElement('button');
+      deleteBtn.type = 'button';
+      deleteBtn.className = 'btn ghost danger';
+      deleteBtn.textContent = t('common.delete');
+      deleteBtn.addEventListener('click', () => deleteRule(rule));
+      actions.append(editBtn, toggleBtn, deleteBtn);
+      row.appendChild(actions);
+      list.appendChild(row);
+    });
+  }
+
+  function renderPriorityOptions() {
+    const select = document.getElementById('tasks-automation-priorities');
+    if (!select) return;
+    select.innerHTML = '';
+    ['low', 'medium', 'high', 'critical'].forEach(val => {
+      const opt = document.createElement('option');
+      opt.value = val;
+      opt.textContent = t(`tasks.priority.${val}`);
+      select.appendChild(opt);
+    });
+  }
+
+  function renderColumnOptions() {
+    const select = document.getElementById('tasks-automation-column');
+    if (!select) return;
+    select.innerHTML = '';
+    const any = document.createElement('option');
+    any.value = '';
+    any.textContent = t('tasks.automation.anyColumn');
+    select.appendChild(any);
+    (state.columnsByBoard[currentBoardId] || []).forEach(col => {
+      const opt = document.createElement('option');
+      opt.value = `${col.id}`;
+      opt.textContent = col.name;
+      select.appendChild(opt);
+    });
+  }
+
+  function toggleColumnField() {
+    const trigger = document.getElementById('tasks-automation-trigger');
+    const field = document.getElementById('tasks-automation-column-field');
+    if (field) field.hidden = !trigger || trigger.value !== 'task_moved';
+  }
+
+  function editRule(id) {
+    const rule = id ? rules.find(r => r.id === id) : null;
+    currentRuleId = rule ? rule.id : null;
+    setValue('tasks-automation-name', rule ? rule.name : '');
+    setValue('tasks-automation-trigger', rule ? rule.trigger : 'task_created');
+    setValue('tasks-automation-column', rule && rule.trigger_column_id ? `${rule.trigger_column_id}` : '');
+    const conditions = (rule && rule.conditions) || {};
+    const priorities = new Set(conditions.priorities || []);
+    const prioritySelect = document.getElementById('tasks-automation-priorities');
+    if (prioritySelect) {
+      Array.from(prioritySelect.options).forEach(opt => { opt.selected = priorities.has(opt.value); });
+    }
+    setValue('tasks-automation-tags', (conditions.tags || []).join(', '));
+    setValue('tasks-automation-actions', rule ? JSON.stringify(rule.actions || [], null, 2) : '[]');
+    const active = document.getElementById('tasks-automation-active');
+    if (active) active.checked = rule ? !!rule.is_active : true;
+    const result = document.getElementById('tasks-automation-dry-result');
+    if (result) result.hidden = true;
+    toggleColumnField();
+  }
+
+  function collectRule() {
+    const trigger = document.getElementById('tasks-automation-trigger')?.value || '';
+    const columnId = parseInt(document.getElementById('tasks-automation-column')?.value || '', 10);
+    const prioritySelect = document.getElementById('tasks-automation-priorities');
+    const priorities = prioritySelect ? Array.from(prioritySelect.selectedOptions).map(opt => opt.value) : [];
+    const tags = (document.getElementById('tasks-automation-tags')?.value || '')
+      .split(',')
+      .map(tag => tag.trim())
+      .filter(Boolean);
+    let actions;
+    try {
+      actions = JSON.parse(document.getElementById('tasks-automation-actions')?.value || '[]');
+    } catch (err) {
+      throw new Error('tasks.automation.actionsJSONInvalid');
+    }
+    if (!Array.isArray(actions)) {
+      throw new Error('tasks.automation.actionsJSONInvalid');
+    }
+    const existing = currentRuleId ? rules.find(r => r.id === currentRuleId) : null;
+    const conditions = Object.assign({}, existing?.conditions || {}, { priorities, tags });
+    return {
+      name: document.getElementById('tasks-automation-name')?.value || '',
+      trigger,
+      trigger_column_id: trigger === 'task_moved' && columnId > 0 ? columnId : 0,
+      conditions,
+      actions,
+      is_active: !!document.getElementById('tasks-automation-active')?.checked
+    };
+  }
+
+  async function saveRule() {
+    hideAlert('tasks-automation-alert');
+    try {
+      const payload = collectRule();
+      if (currentRuleId) {
+        await Api.put(`/api/tasks/automation/${currentRuleId}`, payload);
+      } else {
+        const created = await Api.post(`/api/tasks/boards/${currentBoardId}/automation`, payload);
+        currentRuleId = created?.id || null;
+      }
+      await loadRules();
+      editRule(currentRuleId);
+    } catch (err) {
+      showAlert('tasks-automation-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function toggleRule(rule) {
+    hideAlert('tasks-automation-alert');
+    try {
+      await Api.put(`/api/tasks/automation/${rule.id}`, { is_active: !rule.is_active });
+      await loadRules();
+    } catch (err) {
+      showAlert('tasks-automation-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function deleteRule(rule) {
+    const ok = await confirmAction({ message: t('tasks.automation.deleteConfirm') });
+    if (!ok) return;
+    hideAlert('tasks-automation-alert');
+    try {
+      await Api.del(`/api/tasks/automation/${rule.id}`);
+      if (currentRuleId === rule.id) currentRuleId = null;
+      await loadRules();
+      editRule(currentRuleId);
+    } catch (err) {
+      showAlert('tasks-automation-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function dryRun() {
+    hideAlert('tasks-automation-alert');
+    const result = document.getElementById('tasks-automation-dry-result');
+    const taskId = parseInt(document.getElementById('tasks-automation-dry-task')?.value || '', 10);
+    if (!taskId) {
+      showAlert('tasks-automation-alert', t('tasks.automation.taskRequired'));
+      return;
+    }
+    try {
+      const rule = collectRule();
+      const plan = await Api.post(`/api/tasks/boards/${currentBoardId}/automation/dry-run`, { rule, task_id: taskId });
+      if (result) {
+        result.textContent = describePlan(plan);
+        result.hidden = false;
+      }
+    } catch (err) {
+      showAlert('tasks-automation-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  function describePlan(plan) {
+    const lines = [];
+    lines.push(plan.matched ? t('tasks.automation.planMatched') : t('tasks.automation.planNotMatched'));
+    (plan.conditions || []).forEach(c => {
+      lines.push(`${c.matched ? '+' : '-'} ${t(`tasks.automation.conditions.${c.condition}`)}`);
+    });
+    (plan.actions || []).forEach(a => {
+      lines.push(`> ${t(`tasks.automation.actionTypes.${a.type}`)}`);
+    });
+    return lines.join('\n');
+  }
+
+  function setValue(id, val) {
+    const el = document.getElementById(id);
+    if (el !== null && el !== undefined) el.value = val;
+  }
+
+  TasksPage.initAutomation = initAutomation;
+  TasksPage.openAutomation = openAutomation;
+})();
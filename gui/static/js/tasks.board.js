@@ -38,6 +38,9 @@
     if (TasksPage.initTemplates) TasksPage.initTemplates();
     if (TasksPage.initTemplatesHome) TasksPage.initTemplatesHome();
     if (TasksPage.initTemplatePicker) TasksPage.initTemplatePicker();
+    if (TasksPage.initAutomation) TasksPage.initAutomation();
+    if (TasksPage.initImport) TasksPage.initImport();
+    if (TasksPage.initWorklog) TasksPage.initWorklog();
     loadData();
   }
 
@@ -778,6 +781,12 @@
         actions.push({ label: t('tasks.defaultTemplate'), children: boardTemplateMenu });
       }
       actions.push({ label: t('tasks.boards.rename'), handler: () => openBoardModal('rename', board) });
+      if (TasksPage.openAutomation) {
+        actions.push({ label: t('tasks.automation.title'), handler: () => TasksPage.openAutomation(board) });
+      }
+      if (TasksPage.openImport && hasPermission('tasks.create')) {
+        actions.push({ label: t('tasks.import.title'), handler: () => TasksPage.openImport(board) });
+      }
       actions.push({ label: t('tasks.boards.delete'), danger: true, handler: () => deleteBoard(board) });
       actions.push({ label: t('tasks.columns.add'), handler: () => openColumnModal('add', board.id) });
       actions.push({ label: t('tasks.actions.createBoard'), handler: () => openBoardModal('create', null, board.space_id) });
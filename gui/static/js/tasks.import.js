@@ -0,0 +1,105 @@
+(() => {
+  const state = TasksPage.state;
+  const { t, hasPermission, showAlert, hideAlert, openModal, closeModal, resolveErrorMessage } = TasksPage;
+
+  let currentBoardId = null;
+
+  function initImport() {
+    const closeBtn = document.getElementById('tasks-import-close');
+    const previewBtn = document.getElementById('tasks-import-preview');
+    const form = document.getElementById('tasks-import-form');
+    if (closeBtn) closeBtn.addEventListener('click', () => closeModal('tasks-import-modal'));
+    if (previewBtn) previewBtn.addEventListener('click', () => runImport(true));
+    if (form) {
+      form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        await runImport(false);
+      });
+    }
+  }
+
+  function openImport(board) {
+    if (!board || !hasPermission('tasks.create')) return;
+    currentBoardId = board.id;
+    hideAlert('tasks-import-alert');
+    const title = document.getElementById('tasks-import-title');
+    if (title) title.textContent = `${t('tasks.import.title')}: ${board.name}`;
+    const form = document.getElementById('tasks-import-form');
+    if (form) form.reset();
+    const result = document.getElementById('tasks-import-result');
+    if (result) result.hidden = true;
+    renderColumnOptions();
+    openModal('tasks-import-modal');
+  }
+
+  function renderColumnOptions() {
+    const select = document.getElementById('tasks-import-column');
+    if (!select) return;
+    select.innerHTML = '';
+    const first = document.createElement('option');
+    first.value = '';
+    first.textContent = t('tasks.import.firstColumn');
+    select.appendChild(first);
+    (state.columnsByBoard[currentBoardId] || []).forEach(col => {
+      const opt = document.createElement('option');
+      opt.value = `${col.id}`;
+      opt.textContent = col.name;
+      select.appendChild(opt);
+    });
+  }
+
+  async function runImport(preview) {
+    hideAlert('tasks-import-alert');
+    const fileInput = document.getElementById('tasks-import-file');
+    const file = fileInput?.files?.[0];
+    if (!file) {
+      showAlert('tasks-import-alert', t('tasks.import.fileRequired'));
+      return;
+    }
+    const form = new FormData();
+    form.append('file', file);
+    form.append('source', document.getElementById('tasks-import-source')?.value || '');
+    const column = document.getElementById('tasks-import-column')?.value || '';
+    if (column) form.append('default_column_id', column);
+    const statusMap = (document.getElementById('tasks-import-status-map')?.value || '').trim();
+    if (statusMap) form.append('status_map', statusMap);
+    Array.from(document.getElementById('tasks-import-attachments')?.files || []).forEach(f => form.append('attachments', f));
+    if (preview) form.append('preview', '1');
+    try {
+      const plan = await Api.upload(`/api/tasks/boards/${currentBoardId}/import`, form);
+      renderPlan(plan);
+      if (!preview) {
+        await TasksPage.loadTasks(currentBoardId);
+        TasksPage.renderBoard();
+      }
+    } catch (err) {
+      showAlert('tasks-import-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  function renderPlan(plan) {
+    const result = document.getElementById('tasks-import-result');
+    if (!result) return;
+    const lines = [];
+    lines.push(plan.applied ? t('tasks.import.applied') : t('tasks.import.previewed'));
+    lines.push(`${t('tasks.import.created')}: ${plan.created || 0}`);
+    lines.push(`${t('tasks.import.updated')}: ${plan.updated || 0}`);
+    lines.push(`${t('tasks.import.unchanged')}: ${plan.unchanged || 0}`);
+    lines.push(`${t('tasks.import.skipped')}: ${plan.skipped || 0}`);
+    const report = plan.report || [];
+    if (report.length) {
+      lines.push('');
+      lines.push(t('tasks.import.report'));
+      report.forEach(entry => {
+        const ref = entry.external_id || `#${entry.row}`;
+        const detail = entry.detail ? `: ${entry.detail}` : '';
+        lines.push(`- ${ref} ${t(`tasks.import.reasons.${entry.reason}`)}${detail}`);
+      });
+    }
+    result.textContent = lines.join('\n');
+    result.hidden = false;
+  }
+
+  TasksPage.initImport = initImport;
+  TasksPage.openImport = openImport;
+})();
@@ -10,6 +10,7 @@
     const form = document.getElementById('task-recurring-form');
     const typeSelect = document.getElementById('task-recurring-type');
     const runBtn = document.getElementById('task-recurring-run');
+    const previewBtn = document.getElementById('task-recurring-preview-btn');
 
     if (closeBtn) closeBtn.addEventListener('click', () => closeModal('task-recurring-modal'));
     if (newBtn) newBtn.addEventListener('click', () => editRule(null));
@@ -18,6 +19,7 @@
       runBtn.hidden = !hasPermission('tasks.recurring.run');
       runBtn.addEventListener('click', runNow);
     }
+    if (previewBtn) previewBtn.addEventListener('click', previewSchedule);
     if (form) {
       form.addEventListener('submit', async (e) => {
         e.preventDefault();
@@ -34,6 +36,7 @@
     try {
       if (hasPermission('tasks.recurring.manage')) {
         await ensureTemplates();
+        await ensureCalendars();
       }
       const res = await Api.get('/api/tasks/recurring?include_inactive=1');
       state.recurringRules = res.items || [];
@@ -53,6 +56,24 @@
     populateTemplateSelect();
   }
 
+  async function ensureCalendars() {
+    const select = document.getElementById('task-recurring-calendar');
+    if (!select || select.dataset.loaded) return;
+    try {
+      const res = await Api.get('/api/schedule/calendars');
+      select.innerHTML = `<option value="">${t('schedule.calendar.default')}</option>`;
+      (res.items || []).forEach(cal => {
+        const opt = document.createElement('option');
+        opt.value = cal.id;
+        opt.textContent = cal.name;
+        select.appendChild(opt);
+      });
+      select.dataset.loaded = '1';
+    } catch (_) {
+      // Calendars are optional; the default weekend is used without them.
+    }
+  }
+
   function renderRecurringList() {
     const tbody = document.getElementById('task-recurring-list');
     if (!tbody) return;
@@ -146,6 +167,13 @@
     setValue('task-recurring-time', '');
     setValue('task-recurring-day', '');
     setValue('task-recurring-month-day', '');
+    setValue('task-recurring-rrule', '');
+    setValue('task-recurring-start', '');
+    setValue('task-recurring-exdates', '');
+    setValue('task-recurring-timezone', '');
+    setValue('task-recurring-shift', '');
+    setValue('task-recurring-calendar', '');
+    clearPreview();
     document.getElementById('task-recurring-active').checked = true;
     clearWeekdays();
     toggleRecurringFields('daily');
@@ -197,11 +225,17 @@
     if (monthly) monthly.hidden = type !== 'monthly';
     if (monthField) monthField.hidden = !['quarterly', 'semiannual', 'annual'].includes(type);
     if (monthDay) monthDay.hidden = !['quarterly', 'semiannual', 'annual'].includes(type);
+    const rrule = document.getElementById('task-recurring-rrule-field');
+    if (rrule) rrule.hidden = type !== 'rrule';
   }
 
   function applyScheduleConfig(rule) {
     const config = parseConfig(rule.schedule_config);
     clearWeekdays();
+    clearPreview();
+    setValue('task-recurring-timezone', config.timezone || '');
+    setValue('task-recurring-shift', config.shift || '');
+    setValue('task-recurring-calendar', config.calendar_id || '');
     if (rule.schedule_type === 'weekly') {
       const days = Array.isArray(config.weekdays) ? config.weekdays : [];
       days.forEach(d => {
@@ -213,6 +247,10 @@
     } else if (['quarterly', 'semiannual', 'annual'].includes(rule.schedule_type)) {
       setValue('task-recurring-month', config.month || '');
       setValue('task-recurring-month-day', config.day || '');
+    } else if (rule.schedule_type === 'rrule') {
+      setValue('task-recurring-rrule', config.rrule || '');
+      setValue('task-recurring-start', config.start || '');
+      setValue('task-recurring-exdates', (config.exdates || []).join('\n'));
     }
   }
 
@@ -223,22 +261,78 @@
   }
 
   function buildScheduleConfig(type) {
+    const options = buildScheduleOptions();
     if (type === 'weekly') {
       const days = Array.from(document.querySelectorAll('#task-recurring-weekdays input:checked'))
         .map(cb => parseInt(cb.value, 10))
         .filter(n => !Number.isNaN(n));
-      return { weekdays: days };
+      return { weekdays: days, ...options };
     }
     if (type === 'monthly') {
       const day = parseInt(document.getElementById('task-recurring-day')?.value || '0', 10);
-      return { day };
+      return { day, ...options };
     }
     if (['quarterly', 'semiannual', 'annual'].includes(type)) {
       const month = parseInt(document.getElementById('task-recurring-month')?.value || '0', 10);
       const day = parseInt(document.getElementById('task-recurring-month-day')?.value || '0', 10);
-      return { month, day };
+      return { month, day, ...options };
+    }
+    if (type === 'rrule') {
+      return {
+        rrule: (document.getElementById('task-recurring-rrule')?.value || '').trim(),
+        start: (document.getElementById('task-recurring-start')?.value || '').trim(),
+        exdates: (document.getElementById('task-recurring-exdates')?.value || '')
+          .split(/[\n,]/).map(s => s.trim()).filter(Boolean),
+        ...options
+      };
+    }
+    return options;
+  }
+
+  function buildScheduleOptions() {
+    const options = {};
+    const timezone = (document.getElementById('task-recurring-timezone')?.value || '').trim();
+    const shift = document.getElementById('task-recurring-shift')?.value || '';
+    const calendarId = parseInt(document.getElementById('task-recurring-calendar')?.value || '0', 10);
+    if (timezone) options.timezone = timezone;
+    if (shift) options.shift = shift;
+    if (calendarId) options.calendar_id = calendarId;
+    return options;
+  }
+
+  function clearPreview() {
+    const list = document.getElementById('task-recurring-preview');
+    if (list) list.innerHTML = '';
+  }
+
+  async function previewSchedule() {
+    hideAlert('task-recurring-manage-alert');
+    const list = document.getElementById('task-recurring-preview');
+    if (!list) return;
+    const type = document.getElementById('task-recurring-type')?.value || 'daily';
+    const timeOfDay = (document.getElementById('task-recurring-time')?.value || '').trim();
+    try {
+      const res = await Api.post('/api/tasks/recurring/preview', {
+        schedule_type: type,
+        schedule_config: buildScheduleConfig(type),
+        time_of_day: timeOfDay,
+        count: 10
+      });
+      list.innerHTML = '';
+      (res.items || []).forEach(item => {
+        const li = document.createElement('li');
+        li.textContent = item.holiday ? `${item.local} (${item.holiday})` : item.local;
+        list.appendChild(li);
+      });
+      if (!list.children.length) {
+        const li = document.createElement('li');
+        li.className = 'muted';
+        li.textContent = t('tasks.recurring.noNextRun');
+        list.appendChild(li);
+      }
+    } catch (err) {
+      showAlert('task-recurring-manage-alert', resolveErrorMessage(err, 'common.error'));
     }
-    return {};
   }
 
   function describeSchedule(rule) {
@@ -256,6 +350,9 @@
     if (['quarterly', 'semiannual', 'annual'].includes(type)) {
       return `${label}: ${config.month || ''}/${config.day || ''}`;
     }
+    if (type === 'rrule') {
+      return `${label}: ${config.rrule || ''}`;
+    }
     return label;
   }
 
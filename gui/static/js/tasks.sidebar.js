@@ -24,6 +24,7 @@
   let blockTitles = {};
   let taskFiles = [];
   let comments = [];
+  let activity = { items: [], users: {}, columns: {}, flow: null };
   let editingCommentId = null;
   const taskTitleCache = {};
   const taskTitlePending = new Set();
@@ -54,6 +55,7 @@
   ];
   const singleInstanceBlocks = new Set(['tags', 'relations_child', 'business_customer', 'size']);
   const blockOrderStorageKey = 'tasks.blockOrder';
+  const showActivityStorageKey = 'tasks.showActivity';
   const inlineFieldConfig = {
     external_link: {
       inputId: 'task-modal-external-link',
@@ -227,6 +229,14 @@
       if (commentFile) commentFile.value = '';
     });
     commentSubmit?.addEventListener('click', submitComment);
+    document.getElementById('task-comments-show-activity')?.addEventListener('change', (e) => {
+      try {
+        localStorage.setItem(showActivityStorageKey, e.target.checked ? '1' : '0');
+      } catch (_) {
+        // ignore
+      }
+      renderComments();
+    });
     commentInput?.addEventListener('keydown', (e) => {
       if ((e.ctrlKey || e.metaKey) && e.key === 'Enter') {
         e.preventDefault();
@@ -275,6 +285,13 @@
       updateTask({ priority: prioritySel.value }).catch(() => {});
     });
 
+    const startInput = document.getElementById('task-modal-start');
+    startInput?.addEventListener('change', () => {
+      if (startInput.disabled || suppressAutoSave) return;
+      const value = startInput.value ? toISODate(startInput.value) : '';
+      updateTask({ start_date: value }).catch(() => {});
+    });
+
     const dueInput = document.getElementById('task-modal-due');
     dueInput?.addEventListener('change', () => {
       if (dueInput.disabled || suppressAutoSave) return;
@@ -298,6 +315,7 @@
       resetCommentForm();
       openModal('task-modal');
       await Promise.all([loadLinks(taskId), loadControlLinks(taskId), loadBlocks(taskId), loadFiles(taskId), loadComments(taskId)]);
+      if (TasksPage.loadWorklog) await TasksPage.loadWorklog(taskId);
     } catch (err) {
       showError(err, 'common.error');
     }
@@ -495,6 +513,13 @@
       sizeInput.disabled = !canEdit;
     }
 
+    const startInput = document.getElementById('task-modal-start');
+    if (startInput) {
+      startInput.value = task.start_date ? toInputDate(task.start_date) : '';
+      startInput.lang = 'ru';
+      startInput.disabled = !canEdit;
+    }
+
     const dueInput = document.getElementById('task-modal-due');
     if (dueInput) {
       dueInput.value = task.due_date ? toInputDate(task.due_date) : '';
@@ -515,6 +540,7 @@
     }
 
     renderAssignees(task, canEdit && canAssign);
+    renderCustomFields(task, canEdit);
     renderTextView('description', task.description, t('tasks.fields.descriptionHint'));
     renderTextView('result', task.result, t('tasks.fields.resultHint'));
     resetForcedBlockMarkers();
@@ -1908,20 +1934,127 @@
   }
 
   async function loadComments(taskId) {
+    const [commentsRes, activityRes] = await Promise.all([
+      Api.get(`/api/tasks/${taskId}/comments`).catch(() => null),
+      Api.get(`/api/tasks/${taskId}/activity`).catch(() => null)
+    ]);
+    comments = commentsRes?.items || [];
+    activity = activityRes || { items: [], users: {}, columns: {}, flow: null };
+    renderFlow();
+    renderComments();
+  }
+
+  function showActivityEnabled() {
     try {
-      const res = await Api.get(`/api/tasks/${taskId}/comments`);
-      comments = res.items || [];
-    } catch (err) {
-      comments = [];
+      return localStorage.getItem(showActivityStorageKey) !== '0';
+    } catch (_) {
+      return true;
     }
-    renderComments();
+  }
+
+  function renderFlow() {
+    const el = document.getElementById('task-modal-flow');
+    if (!el) return;
+    const flow = activity?.flow;
+    const parts = [];
+    if (flow && flow.lead_time_seconds !== undefined && flow.lead_time_seconds !== null) {
+      parts.push(`${t('tasks.activity.leadTime')}: ${formatDuration(flow.lead_time_seconds)}`);
+    }
+    if (flow && flow.cycle_time_seconds !== undefined && flow.cycle_time_seconds !== null) {
+      parts.push(`${t('tasks.activity.cycleTime')}: ${formatDuration(flow.cycle_time_seconds)}`);
+    }
+    (flow?.columns || []).forEach(col => {
+      const name = activity.columns?.[col.column_id] || `#${col.column_id}`;
+      parts.push(`${name}: ${formatDuration(col.seconds)}`);
+    });
+    el.textContent = parts.join(' · ');
+    el.hidden = !parts.length;
+  }
+
+  function formatDuration(seconds) {
+    const total = Math.max(0, Math.floor((seconds || 0) / 60));
+    const days = Math.floor(total / 1440);
+    const hours = Math.floor((total % 1440) / 60);
+    const minutes = total % 60;
+    if (days > 0) return `${days}${t('tasks.activity.daysShort')} ${hours}${t('tasks.activity.hoursShort')}`;
+    if (hours > 0) return `${hours}${t('tasks.activity.hoursShort')} ${minutes}${t('tasks.activity.minutesShort')}`;
+    return `${minutes}${t('tasks.activity.minutesShort')}`;
+  }
+
+  function activityValue(field, value) {
+    if (!value) return '-';
+    if (field === 'due_date' || field === 'start_date') return formatDateTime(value);
+    if (field === 'priority') return t(`tasks.priority.${value}`);
+    return value;
+  }
+
+  function describeActivity(item, dir) {
+    const userName = (id) => {
+      const u = dir?.get ? dir.get(id) : null;
+      return u?.full_name || u?.username || activity.users?.[id] || `#${id}`;
+    };
+    const details = item.details || {};
+    switch (item.action) {
+      case 'field_changed': {
+        const label = t(`tasks.activity.fields.${item.field}`);
+        if (item.field === 'description' || item.field === 'result') {
+          return `${t('tasks.activity.changed')} ${label}`;
+        }
+        return `${t('tasks.activity.changed')} ${label}: ${activityValue(item.field, item.old_value)} → ${activityValue(item.field, item.new_value)}`;
+      }
+      case 'moved': {
+        const from = details.from_column || activity.columns?.[item.old_value] || `#${item.old_value}`;
+        const to = details.to_column || activity.columns?.[item.new_value] || `#${item.new_value}`;
+        return `${t('tasks.activity.moved')}: ${from} → ${to}`;
+      }
+      case 'assignees_changed': {
+        const added = (details.added || []).map(id => `+${userName(id)}`);
+        const removed = (details.removed || []).map(id => `-${userName(id)}`);
+        return `${t('tasks.activity.assignees')}: ${added.concat(removed).join(', ')}`;
+      }
+      case 'tags_changed': {
+        const added = (details.added || []).map(tag => `+${tag}`);
+        const removed = (details.removed || []).map(tag => `-${tag}`);
+        return `${t('tasks.activity.tags')}: ${added.concat(removed).join(', ')}`;
+      }
+      case 'checklist_toggled':
+        return `${item.new_value === 'true' ? t('tasks.activity.checked') : t('tasks.activity.unchecked')}: ${item.field}`;
+      case 'link_added':
+      case 'link_removed':
+        return `${t(`tasks.activity.actions.${item.action}`)}: ${t(`tasks.activity.linkTypes.${item.field}`)} ${item.new_value}`;
+      case 'block_added':
+      case 'block_resolved':
+      case 'dependency_added':
+      case 'dependency_removed':
+      case 'file_added':
+        return `${t(`tasks.activity.actions.${item.action}`)}${item.new_value ? `: ${item.new_value}` : ''}`;
+      case 'file_removed':
+        return `${t('tasks.activity.actions.file_removed')}: ${item.old_value}`;
+      default:
+        return t(`tasks.activity.actions.${item.action}`);
+    }
+  }
+
+  function renderActivityRow(item, dir) {
+    const row = document.createElement('div');
+    row.className = 'task-activity-row';
+    const actorId = item.actor_id;
+    const actor = actorId ? (dir?.get ? dir.get(actorId) : null) : null;
+    const actorName = actorId ? (actor?.full_name || actor?.username || activity.users?.[actorId] || `#${actorId}`) : t('tasks.activity.system');
+    const created = item.created_at ? formatDateTime(item.created_at) : '';
+    row.innerHTML = `<span class="task-comment-author">${escapeHtml(actorName)}</span> <span>${escapeHtml(describeActivity(item, dir))}</span> <span class="task-comment-time">${escapeHtml(created)}</span>`;
+    return row;
   }
 
   function renderComments() {
     const list = document.getElementById('task-modal-comments-list');
     if (!list) return;
     list.innerHTML = '';
-    if (!comments.length) {
+    const toggle = document.getElementById('task-comments-show-activity');
+    const withActivity = showActivityEnabled();
+    if (toggle) toggle.checked = withActivity;
+    const history = withActivity ? (activity?.items || []) : [];
+    if (!comments.length && !history.length) {
       const empty = document.createElement('div');
       empty.className = 'muted';
       empty.textContent = t('tasks.comments.empty') || t('tasks.empty.noSelection') || '-';
@@ -1931,7 +2064,15 @@
     const dir = (typeof window !== 'undefined' && window.UserDirectory)
       ? window.UserDirectory
       : (typeof UserDirectory !== 'undefined' ? UserDirectory : null);
-    comments.forEach(comment => {
+    const timeline = comments.map(comment => ({ at: comment.created_at, comment }))
+      .concat(history.map(item => ({ at: item.created_at, item })))
+      .sort((a, b) => new Date(a.at || 0) - new Date(b.at || 0));
+    timeline.forEach(entry => {
+      if (!entry.comment) {
+        list.appendChild(renderActivityRow(entry.item, dir));
+        return;
+      }
+      const comment = entry.comment;
       const row = document.createElement('div');
       row.className = 'task-comment';
       const author = dir?.get ? dir.get(comment.author_id) : null;
@@ -1969,6 +2110,7 @@
         const textarea = document.createElement('textarea');
         textarea.className = 'textarea';
         textarea.rows = 3;
+        textarea.dataset.mentions = '1';
         textarea.value = comment.content || '';
         const actions = document.createElement('div');
         actions.className = 'task-comment-editor-actions';
@@ -2003,7 +2145,8 @@
       } else {
         const body = document.createElement('div');
         body.className = 'task-comment-body';
-        body.textContent = comment.content || '';
+        if (window.AppMentions) AppMentions.render(body, comment.content || '');
+        else body.textContent = comment.content || '';
         row.appendChild(body);
       }
 
@@ -2308,6 +2451,7 @@
     const title = document.getElementById('task-modal-title-input')?.value || '';
     const sizeRaw = document.getElementById('task-modal-size')?.value || '';
     const priority = document.getElementById('task-modal-priority')?.value || '';
+    const startRaw = document.getElementById('task-modal-start')?.value || '';
     const dueRaw = document.getElementById('task-modal-due')?.value || '';
     const assignees = Array.from(document.getElementById('task-modal-assignees')?.selectedOptions || []).map(o => o.value);
     const externalInput = document.getElementById('task-modal-external-link');
@@ -2317,6 +2461,7 @@
     const payload = {
       title,
       priority,
+      start_date: startRaw ? toISODate(startRaw) : '',
       due_date: dueRaw ? toISODate(dueRaw) : '',
       assigned_to: assignees,
       tags: state.card.tags || [],
@@ -2336,6 +2481,25 @@
     }
   }
 
+  async function renderCustomFields(task, canEdit) {
+    const field = document.getElementById('task-modal-custom-fields-field');
+    const list = document.getElementById('task-modal-custom-fields');
+    const saveBtn = document.getElementById('task-modal-custom-fields-save');
+    if (!field || !list || typeof CustomFieldsForm === 'undefined') return;
+    await CustomFieldsForm.render(list, 'task', task.custom_fields, !canEdit);
+    field.hidden = list.hidden;
+    if (saveBtn) {
+      saveBtn.hidden = !list.querySelector('[data-cf-key]:not([disabled])');
+      saveBtn.onclick = async () => {
+        try {
+          await updateTask({ custom_fields: CustomFieldsForm.collect(list) });
+        } catch (_) {
+          // handled in updateTask
+        }
+      };
+    }
+  }
+
   async function updateTask(payload, onSuccess) {
     if (!state.card.taskId) return;
     try {
@@ -2346,6 +2510,7 @@
       await refreshBoards(updated.board_id);
       if (onSuccess) onSuccess();
       hideAlert('task-modal-alert');
+      loadComments(state.card.taskId);
       if (payload && Object.prototype.hasOwnProperty.call(payload, 'tags')) {
         refreshTagSuggestions();
       }
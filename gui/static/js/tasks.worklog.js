@@ -0,0 +1,233 @@
+(() => {
+  const state = TasksPage.state;
+  const { t, hasPermission, showAlert, hideAlert, resolveErrorMessage, formatDateTime, confirmAction, escapeHtml } = TasksPage;
+
+  let taskId = null;
+  let items = [];
+  let users = {};
+  let summary = null;
+  let timer = null;
+  let timerTick = null;
+
+  function initWorklog() {
+    const form = document.getElementById('task-worklog-form');
+    const timerBtn = document.getElementById('task-worklog-timer-toggle');
+    const remainingBtn = document.getElementById('task-worklog-remaining-save');
+    if (form) {
+      form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        await addWorklog();
+      });
+    }
+    if (timerBtn) timerBtn.addEventListener('click', toggleTimer);
+    if (remainingBtn) remainingBtn.addEventListener('click', saveRemaining);
+  }
+
+  async function loadWorklog(id) {
+    taskId = id;
+    const field = document.getElementById('task-modal-worklog-field');
+    if (!field) return;
+    try {
+      const res = await Api.get(`/api/tasks/${id}/worklogs`);
+      items = res.items || [];
+      users = res.users || {};
+      summary = res.summary || null;
+      timer = res.timer || null;
+      field.hidden = false;
+    } catch (_) {
+      items = [];
+      summary = null;
+      timer = null;
+      field.hidden = true;
+      return;
+    }
+    resetForm();
+    render();
+  }
+
+  function render() {
+    renderSummary();
+    renderTimer();
+    renderList();
+    const canLog = hasPermission('tasks.comment') && !state.card.original?.is_archived;
+    const form = document.getElementById('task-worklog-form');
+    if (form) form.hidden = !canLog;
+    const estimate = document.getElementById('task-worklog-estimate');
+    if (estimate) estimate.hidden = !hasPermission('tasks.edit');
+  }
+
+  function renderSummary() {
+    const el = document.getElementById('task-modal-worklog-summary');
+    if (!el) return;
+    const spent = formatHours(summary?.spent_minutes || 0);
+    const hasRemaining = summary && summary.remaining_minutes !== undefined && summary.remaining_minutes !== null;
+    const remaining = hasRemaining ? formatHours(summary.remaining_minutes) : '-';
+    el.textContent = `${t('tasks.worklog.spent')}: ${spent} · ${t('tasks.worklog.remaining')}: ${remaining}`;
+    const input = document.getElementById('task-worklog-remaining');
+    if (input) input.value = hasRemaining ? `${round(summary.remaining_minutes / 60)}` : '';
+  }
+
+  function renderTimer() {
+    const btn = document.getElementById('task-worklog-timer-toggle');
+    const info = document.getElementById('task-worklog-timer-info');
+    if (timerTick) {
+      clearInterval(timerTick);
+      timerTick = null;
+    }
+    if (!btn) return;
+    const closed = !!state.card.original?.closed_at || !!state.card.original?.is_archived;
+    const runningHere = timer && timer.task_id === taskId;
+    btn.hidden = !hasPermission('tasks.comment') || (!runningHere && closed);
+    btn.textContent = runningHere ? t('tasks.worklog.stopTimer') : t('tasks.worklog.startTimer');
+    if (!info) return;
+    if (!timer) {
+      info.textContent = '';
+      return;
+    }
+    if (!runningHere) {
+      info.textContent = `${t('tasks.worklog.timerElsewhere')} #${timer.task_id}`;
+      return;
+    }
+    const update = () => {
+      const elapsed = Math.max(0, Date.now() - new Date(timer.started_at).getTime());
+      info.textContent = `${t('tasks.worklog.timerRunningSince')} ${formatDateTime(timer.started_at)} (${formatElapsed(elapsed)})`;
+    };
+    update();
+    timerTick = setInterval(() => {
+      const field = document.getElementById('task-modal-worklog-field');
+      if (!field || field.hidden || !field.isConnected) {
+        clearInterval(timerTick);
+        timerTick = null;
+        return;
+      }
+      update();
+    }, 30000);
+  }
+
+  function renderList() {
+    const list = document.getElementById('task-modal-worklog-list');
+    if (!list) return;
+    list.innerHTML = '';
+    if (!items.length) {
+      list.textContent = t('tasks.worklog.empty');
+      return;
+    }
+    const meId = state.currentUser?.id;
+    const canManage = hasPermission('tasks.manage');
+    items.forEach(item => {
+      const row = document.createElement('div');
+      row.className = 'task-worklog-row';
+      const author = users[item.user_id] || `#${item.user_id}`;
+      const source = item.source === 'timer' ? ` · ${t('tasks.worklog.sourceTimer')}` : '';
+      row.innerHTML = `
+        <div class="task-worklog-info">
+          <strong>${escapeHtml(formatHours(item.minutes))}</strong>
+          <span class="muted">${escapeHtml(formatDay(item.work_date))} · ${escapeHtml(author)}${escapeHtml(source)}</span>
+          ${item.comment ? `<span>${escapeHtml(item.comment)}</span>` : ''}
+        </div>`;
+      if (item.user_id === meId || canManage) {
+        const del = document.createElement('button');
+        del.type = 'button';
+        del.className = 'btn ghost btn-xs danger';
+        del.textContent = t('common.delete');
+        del.addEventListener('click', () => deleteWorklog(item));
+        row.appendChild(del);
+      }
+      list.appendChild(row);
+    });
+  }
+
+  function resetForm() {
+    setValue('task-worklog-hours', '');
+    setValue('task-worklog-comment', '');
+    setValue('task-worklog-date', new Date().toISOString().slice(0, 10));
+  }
+
+  async function addWorklog() {
+    if (!taskId) return;
+    hideAlert('task-modal-alert');
+    const hours = parseFloat(document.getElementById('task-worklog-hours')?.value || '');
+    const payload = {
+      hours: Number.isFinite(hours) ? hours : 0,
+      work_date: document.getElementById('task-worklog-date')?.value || '',
+      comment: document.getElementById('task-worklog-comment')?.value || ''
+    };
+    try {
+      await Api.post(`/api/tasks/${taskId}/worklogs`, payload);
+      await loadWorklog(taskId);
+    } catch (err) {
+      showAlert('task-modal-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function deleteWorklog(item) {
+    const ok = await confirmAction({ message: t('tasks.worklog.deleteConfirm') });
+    if (!ok) return;
+    hideAlert('task-modal-alert');
+    try {
+      await Api.del(`/api/tasks/${taskId}/worklogs/${item.id}`);
+      await loadWorklog(taskId);
+    } catch (err) {
+      showAlert('task-modal-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function saveRemaining() {
+    if (!taskId) return;
+    hideAlert('task-modal-alert');
+    const raw = (document.getElementById('task-worklog-remaining')?.value || '').trim();
+    const hours = parseFloat(raw);
+    const payload = { remaining_minutes: raw === '' ? null : Math.round((Number.isFinite(hours) ? hours : -1) * 60) };
+    try {
+      summary = await Api.put(`/api/tasks/${taskId}/estimate`, payload);
+      renderSummary();
+    } catch (err) {
+      showAlert('task-modal-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  async function toggleTimer() {
+    if (!taskId) return;
+    hideAlert('task-modal-alert');
+    try {
+      if (timer && timer.task_id === taskId) {
+        const comment = document.getElementById('task-worklog-comment')?.value || '';
+        await Api.post('/api/tasks/timer/stop', { comment });
+        await loadWorklog(taskId);
+        return;
+      }
+      const res = await Api.post(`/api/tasks/${taskId}/timer/start`, {});
+      timer = res.timer || null;
+      renderTimer();
+    } catch (err) {
+      showAlert('task-modal-alert', resolveErrorMessage(err, 'common.error'));
+    }
+  }
+
+  function formatHours(minutes) {
+    return `${round((minutes || 0) / 60)} ${t('tasks.worklog.hoursShort')}`;
+  }
+
+  function formatElapsed(ms) {
+    const total = Math.floor(ms / 60000);
+    const h = Math.floor(total / 60);
+    const m = total % 60;
+    return `${h}:${String(m).padStart(2, '0')}`;
+  }
+
+  function formatDay(val) {
+    return val ? `${val}`.slice(0, 10) : '-';
+  }
+
+  function round(val) {
+    return Math.round(val * 100) / 100;
+  }
+
+  function setValue(id, val) {
+    const el = document.getElementById(id);
+    if (el !== null && el !== undefined) el.value = val;
+  }
+
+  TasksPage.initWorklog = initWorklog;
+  TasksPage.loadWorklog = loadWorklog;
+})();
@@ -0,0 +1,291 @@
+package tasks
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ActivityCreated           = "created"
+	ActivityFieldChanged      = "field_changed"
+	ActivityMoved             = "moved"
+	ActivityAssigneesChanged  = "assignees_changed"
+	ActivityTagsChanged       = "tags_changed"
+	ActivityChecklistToggled  = "checklist_toggled"
+	ActivityBlockAdded        = "block_added"
+	ActivityBlockResolved     = "block_resolved"
+	ActivityDependencyAdded   = "dependency_added"
+	ActivityDependencyRemoved = "dependency_removed"
+	ActivityLinkAdded         = "link_added"
+	ActivityLinkRemoved       = "link_removed"
+	ActivityFileAdded         = "file_added"
+	ActivityFileRemoved       = "file_removed"
+	ActivityClosed            = "closed"
+	ActivityArchived          = "archived"
+	ActivityRestored          = "restored"
+
+	maxActivityValueLen = 2000
+)
+
+// TaskActivity is one entry of the task history. Field changes carry the old and
+// new value; moves carry the column ids so flow metrics can be derived from them.
+type TaskActivity struct {
+	ID        int64          `json:"id"`
+	TaskID    int64          `json:"task_id"`
+	ActorID   *int64         `json:"actor_id,omitempty"`
+	Action    string         `json:"action"`
+	Field     string         `json:"field,omitempty"`
+	OldValue  string         `json:"old_value,omitempty"`
+	NewValue  string         `json:"new_value,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ColumnTime is how long a task stayed in a column, over all its visits.
+type ColumnTime struct {
+	ColumnID int64 `json:"column_id"`
+	Seconds  int64 `json:"seconds"`
+	Visits   int   `json:"visits"`
+}
+
+// TaskFlow holds the flow metrics of one task. Lead time runs from creation and
+// cycle time from the first move out of the initial column, both until the task is
+// done; they are nil while it is not.
+type TaskFlow struct {
+	LeadTimeSeconds  *int64       `json:"lead_time_seconds,omitempty"`
+	CycleTimeSeconds *int64       `json:"cycle_time_seconds,omitempty"`
+	DoneAt           *time.Time   `json:"done_at,omitempty"`
+	Columns          []ColumnTime `json:"columns"`
+}
+
+// DiffTask returns a field change per edited scalar field and a toggle per
+// checklist item whose state changed.
+func DiffTask(before, after *Task) []TaskActivity {
+	var out []TaskActivity
+	add := func(field, oldVal, newVal string) {
+		if oldVal != newVal {
+			out = append(out, TaskActivity{TaskID: after.ID, Action: ActivityFieldChanged, Field: field, OldValue: clipActivity(oldVal), NewValue: clipActivity(newVal)})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("description", before.Description, after.Description)
+	add("result", before.Result, after.Result)
+	add("priority", before.Priority, after.Priority)
+	add("start_date", activityTime(before.StartDate), activityTime(after.StartDate))
+	add("due_date", activityTime(before.DueDate), activityTime(after.DueDate))
+	add("external_link", before.ExternalLink, after.ExternalLink)
+	add("business_customer", before.BusinessCustomer, after.BusinessCustomer)
+	add("size_estimate", activityInt(before.SizeEstimate), activityInt(after.SizeEstimate))
+	return append(out, diffChecklist(after.ID, before.Checklist, after.Checklist)...)
+}
+
+func diffChecklist(taskID int64, before, after []TaskChecklistItem) []TaskActivity {
+	var out []TaskActivity
+	if len(before) != len(after) {
+		out = append(out, TaskActivity{TaskID: taskID, Action: ActivityFieldChanged, Field: "checklist",
+			OldValue: strconv.Itoa(len(before)), NewValue: strconv.Itoa(len(after))})
+	}
+	for i, item := range after {
+		if i >= len(before) || before[i].Text != item.Text || before[i].Done == item.Done {
+			continue
+		}
+		out = append(out, TaskActivity{TaskID: taskID, Action: ActivityChecklistToggled, Field: clipActivity(item.Text),
+			OldValue: strconv.FormatBool(before[i].Done), NewValue: strconv.FormatBool(item.Done)})
+	}
+	return out
+}
+
+// DiffAssignees returns nil when the set of assignees did not change.
+func DiffAssignees(taskID int64, before, after []int64) *TaskActivity {
+	added, removed := diffSet(before, after, func(a, b int64) bool { return a == b })
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return &TaskActivity{TaskID: taskID, Action: ActivityAssigneesChanged, Field: "assigned_to",
+		OldValue: joinIDs(before), NewValue: joinIDs(after),
+		Details: map[string]any{"added": added, "removed": removed}}
+}
+
+// DiffTags compares tags case-insensitively and returns nil when nothing changed.
+func DiffTags(taskID int64, before, after []string) *TaskActivity {
+	added, removed := diffSet(before, after, strings.EqualFold)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return &TaskActivity{TaskID: taskID, Action: ActivityTagsChanged, Field: "tags",
+		OldValue: strings.Join(before, ", "), NewValue: strings.Join(after, ", "),
+		Details: map[string]any{"added": added, "removed": removed}}
+}
+
+// MoveActivity records a column change; it returns nil for a reorder inside the
+// same column.
+func MoveActivity(before, after *Task, fromName, toName string) *TaskActivity {
+	if before.ColumnID == after.ColumnID && before.BoardID == after.BoardID {
+		return nil
+	}
+	details := map[string]any{"from_column": fromName, "to_column": toName}
+	if before.BoardID != after.BoardID {
+		details["from_board_id"] = before.BoardID
+		details["to_board_id"] = after.BoardID
+	}
+	return &TaskActivity{TaskID: after.ID, Action: ActivityMoved, Field: "column",
+		OldValue: strconv.FormatInt(before.ColumnID, 10), NewValue: strconv.FormatInt(after.ColumnID, 10), Details: details}
+}
+
+// ComputeTaskFlow derives lead, cycle and per-column times from the moves of a
+// task. finalColumns marks done columns: an open task counts as done from the
+// moment it entered one. Moves must be in chronological order.
+func ComputeTaskFlow(task *Task, moves []TaskActivity, finalColumns map[int64]bool, now time.Time) TaskFlow {
+	flow := TaskFlow{Columns: []ColumnTime{}}
+	if task == nil {
+		return flow
+	}
+	var transitions []TaskActivity
+	for _, m := range moves {
+		if m.Action == ActivityMoved && m.Field == "column" {
+			transitions = append(transitions, m)
+		}
+	}
+	current := task.ColumnID
+	if len(transitions) > 0 {
+		if id, err := strconv.ParseInt(transitions[0].OldValue, 10, 64); err == nil {
+			current = id
+		}
+	}
+	since := task.CreatedAt
+	var started *time.Time
+	var enteredFinal *time.Time
+	if finalColumns[current] {
+		enteredFinal = &since
+	}
+	index := map[int64]int{}
+	spend := func(columnID int64, from, to time.Time) {
+		i, ok := index[columnID]
+		if !ok {
+			i = len(flow.Columns)
+			index[columnID] = i
+			flow.Columns = append(flow.Columns, ColumnTime{ColumnID: columnID})
+		}
+		if to.After(from) {
+			flow.Columns[i].Seconds += int64(to.Sub(from) / time.Second)
+		}
+		flow.Columns[i].Visits++
+	}
+	for _, m := range transitions {
+		next, err := strconv.ParseInt(m.NewValue, 10, 64)
+		if err != nil {
+			continue
+		}
+		spend(current, since, m.CreatedAt)
+		if started == nil {
+			at := m.CreatedAt
+			started = &at
+		}
+		current = next
+		since = m.CreatedAt
+		if finalColumns[next] {
+			at := m.CreatedAt
+			enteredFinal = &at
+		} else {
+			enteredFinal = nil
+		}
+	}
+	end := now
+	switch {
+	case task.ClosedAt != nil:
+		end = *task.ClosedAt
+		flow.DoneAt = task.ClosedAt
+	case enteredFinal != nil:
+		flow.DoneAt = enteredFinal
+	}
+	spend(current, since, end)
+	if flow.DoneAt != nil {
+		lead := int64(flow.DoneAt.Sub(task.CreatedAt) / time.Second)
+		flow.LeadTimeSeconds = &lead
+		if started != nil && !started.After(*flow.DoneAt) {
+			cycle := int64(flow.DoneAt.Sub(*started) / time.Second)
+			flow.CycleTimeSeconds = &cycle
+		}
+	}
+	return flow
+}
+
+// MergeActivity orders entries chronologically, keeping insertion order for ties.
+func MergeActivity(items []TaskActivity) []TaskActivity {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items
+}
+
+func diffSet[T any](before, after []T, eq func(a, b T) bool) ([]T, []T) {
+	added := []T{}
+	removed := []T{}
+	for _, v := range after {
+		if !slices.ContainsFunc(before, func(b T) bool { return eq(b, v) }) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range before {
+		if !slices.ContainsFunc(after, func(a T) bool { return eq(a, v) }) {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func activityTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func activityInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func clipActivity(val string) string {
+	runes := []rune(val)
+	if len(runes) <= maxActivityValueLen {
+		return val
+	}
+	return string(runes[:maxActivityValueLen])
+}
+
+// BlockActivity records a block being added or resolved; auto marks blocks
+// resolved because the blocking task was closed.
+func BlockActivity(action string, block *TaskBlock, auto bool) TaskActivity {
+	details := map[string]any{"block_id": block.ID, "block_type": block.BlockType}
+	value := ""
+	if block.Reason != nil {
+		value = clipActivity(*block.Reason)
+	}
+	if block.BlockerTaskID != nil {
+		details["blocker_task_id"] = *block.BlockerTaskID
+		value = "#" + strconv.FormatInt(*block.BlockerTaskID, 10)
+	}
+	if auto {
+		details["auto"] = true
+	}
+	return TaskActivity{TaskID: block.TaskID, Action: action, Field: "block", NewValue: value, Details: details}
+}
+
+// DependencyActivity is recorded on the successor of a dependency.
+func DependencyActivity(action string, dep *TaskDependency) TaskActivity {
+	details := map[string]any{"dependency_id": dep.ID, "predecessor_id": dep.PredecessorID, "type": dep.Type, "lag_days": dep.LagDays}
+	value := "#" + strconv.FormatInt(dep.PredecessorID, 10)
+	return TaskActivity{TaskID: dep.SuccessorID, Action: action, Field: "dependency", NewValue: value, Details: details}
+}
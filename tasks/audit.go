@@ -60,6 +60,22 @@ const (
 	AuditRecurringToggle            = "task.recurring.toggle"
 	AuditRecurringRunNow            = "task.recurring.run_now"
 	AuditTaskRecurringCreate        = "task.recurring.task_create"
+	AuditAutomationCreate           = "task.automation.create"
+	AuditAutomationUpdate           = "task.automation.update"
+	AuditAutomationDelete           = "task.automation.delete"
+	AuditAutomationDryRun           = "task.automation.dry_run"
+	AuditAutomationRun              = "task.automation.run"
+	AuditWorklogAdd                 = "task.worklog.add"
+	AuditWorklogUpdate              = "task.worklog.update"
+	AuditWorklogDelete              = "task.worklog.delete"
+	AuditWorklogExport              = "task.worklog.export"
+	AuditTimerStart                 = "task.timer.start"
+	AuditTimerStop                  = "task.timer.stop"
+	AuditEstimateUpdate             = "task.estimate.update"
+	AuditTaskImport                 = "task.import"
+	AuditDependencyAdd              = "task.dependency.add"
+	AuditDependencyDelete           = "task.dependency.delete"
+	AuditDependencyShift            = "task.dependency.shift"
 )
 
 func Log(audits store.AuditStore, ctx context.Context, username, action, details string) {
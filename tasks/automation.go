@@ -0,0 +1,336 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	TriggerTaskCreated        = "task_created"
+	TriggerTaskMoved          = "task_moved"
+	TriggerDuePassed          = "due_passed"
+	TriggerBlockResolved      = "block_resolved"
+	TriggerChecklistCompleted = "checklist_completed"
+
+	ActionAssign             = "assign"
+	ActionMove               = "move"
+	ActionAddTag             = "add_tag"
+	ActionSetDue             = "set_due"
+	ActionCreateSubtask      = "create_subtask"
+	ActionComment            = "comment"
+	ActionCloseIncidentStage = "close_incident_stage"
+
+	AutomationRunRunning = "running"
+	AutomationRunDone    = "done"
+	AutomationRunFailed  = "failed"
+
+	maxAutomationActions = 10
+	maxAutomationNameLen = 200
+	maxAutomationTextLen = 4000
+)
+
+// AutomationRule fires its actions for a task of the board when the trigger happens
+// and every non-empty condition group matches.
+type AutomationRule struct {
+	ID              int64                `json:"id"`
+	BoardID         int64                `json:"board_id"`
+	Name            string               `json:"name"`
+	Trigger         string               `json:"trigger"`
+	TriggerColumnID *int64               `json:"trigger_column_id,omitempty"`
+	Conditions      AutomationConditions `json:"conditions"`
+	Actions         []AutomationAction   `json:"actions"`
+	IsActive        bool                 `json:"is_active"`
+	CreatedBy       *int64               `json:"created_by,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// AutomationConditions are ANDed; values inside one group are ORed.
+type AutomationConditions struct {
+	Priorities  []string `json:"priorities,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	AssigneeIDs []int64  `json:"assignee_ids,omitempty"`
+	TemplateIDs []int64  `json:"template_ids,omitempty"`
+}
+
+type AutomationAction struct {
+	Type       string  `json:"type"`
+	UserIDs    []int64 `json:"user_ids,omitempty"`
+	ColumnID   int64   `json:"column_id,omitempty"`
+	Tag        string  `json:"tag,omitempty"`
+	DueDays    int     `json:"due_days,omitempty"`
+	Title      string  `json:"title,omitempty"`
+	Comment    string  `json:"comment,omitempty"`
+	StageTitle string  `json:"stage_title,omitempty"`
+}
+
+type AutomationRuleFilter struct {
+	BoardID         int64
+	Trigger         string
+	IncludeInactive bool
+}
+
+// AutomationEvent is queued by the task handlers and consumed by the automation worker.
+type AutomationEvent struct {
+	ID          int64      `json:"id"`
+	BoardID     int64      `json:"board_id"`
+	TaskID      int64      `json:"task_id"`
+	Trigger     string     `json:"trigger"`
+	RefID       int64      `json:"ref_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// AutomationRun records one execution of a rule for a task. EventKey identifies the
+// occurrence of the trigger, so a rule never runs twice for the same occurrence.
+type AutomationRun struct {
+	ID        int64     `json:"id"`
+	RuleID    int64     `json:"rule_id"`
+	TaskID    int64     `json:"task_id"`
+	EventKey  string    `json:"event_key"`
+	Status    string    `json:"status"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AutomationSubject is the state of a task that conditions are checked against.
+type AutomationSubject struct {
+	Task        Task
+	Tags        []string
+	AssigneeIDs []int64
+}
+
+type AutomationConditionResult struct {
+	Condition string `json:"condition"`
+	Matched   bool   `json:"matched"`
+}
+
+// AutomationPlan is the outcome of evaluating a rule without executing it.
+type AutomationPlan struct {
+	RuleID         int64                       `json:"rule_id,omitempty"`
+	TaskID         int64                       `json:"task_id"`
+	TriggerMatched bool                        `json:"trigger_matched"`
+	Matched        bool                        `json:"matched"`
+	Conditions     []AutomationConditionResult `json:"conditions"`
+	Actions        []AutomationAction          `json:"actions"`
+}
+
+func IsAutomationTrigger(val string) bool {
+	switch val {
+	case TriggerTaskCreated, TriggerTaskMoved, TriggerDuePassed, TriggerBlockResolved, TriggerChecklistCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeAutomationRule trims and validates a rule. Column references are checked
+// against the board by the caller.
+func NormalizeAutomationRule(rule *AutomationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || len([]rune(rule.Name)) > maxAutomationNameLen {
+		return errors.New("tasks.automation.nameRequired")
+	}
+	rule.Trigger = strings.ToLower(strings.TrimSpace(rule.Trigger))
+	if !IsAutomationTrigger(rule.Trigger) {
+		return errors.New("tasks.automation.triggerInvalid")
+	}
+	if rule.Trigger != TriggerTaskMoved || (rule.TriggerColumnID != nil && *rule.TriggerColumnID <= 0) {
+		rule.TriggerColumnID = nil
+	}
+	cond := &rule.Conditions
+	priorities := []string{}
+	for _, p := range cond.Priorities {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch p {
+		case PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical:
+		default:
+			return errors.New("tasks.priorityInvalid")
+		}
+		if !slices.Contains(priorities, p) {
+			priorities = append(priorities, p)
+		}
+	}
+	cond.Priorities = priorities
+	cond.Tags = uniqueTags(cond.Tags)
+	cond.AssigneeIDs = uniqueIDs(cond.AssigneeIDs)
+	cond.TemplateIDs = uniqueIDs(cond.TemplateIDs)
+	if len(rule.Actions) == 0 {
+		return errors.New("tasks.automation.actionsRequired")
+	}
+	if len(rule.Actions) > maxAutomationActions {
+		return errors.New("tasks.automation.tooManyActions")
+	}
+	for i := range rule.Actions {
+		if err := normalizeAutomationAction(&rule.Actions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeAutomationAction(a *AutomationAction) error {
+	a.Type = strings.ToLower(strings.TrimSpace(a.Type))
+	a.Tag = strings.TrimSpace(a.Tag)
+	a.Title = strings.TrimSpace(a.Title)
+	a.Comment = strings.TrimSpace(a.Comment)
+	a.StageTitle = strings.TrimSpace(a.StageTitle)
+	a.UserIDs = uniqueIDs(a.UserIDs)
+	keep := AutomationAction{Type: a.Type}
+	switch a.Type {
+	case ActionAssign:
+		if len(a.UserIDs) == 0 {
+			return errors.New("tasks.automation.usersRequired")
+		}
+		keep.UserIDs = a.UserIDs
+	case ActionMove:
+		if a.ColumnID <= 0 {
+			return errors.New("tasks.columnRequired")
+		}
+		keep.ColumnID = a.ColumnID
+	case ActionAddTag:
+		if a.Tag == "" {
+			return errors.New("tasks.automation.tagRequired")
+		}
+		keep.Tag = a.Tag
+	case ActionSetDue:
+		if a.DueDays < 0 || a.DueDays > 3650 {
+			return errors.New("tasks.automation.dueDaysInvalid")
+		}
+		keep.DueDays = a.DueDays
+	case ActionCreateSubtask:
+		if a.Title == "" || len([]rune(a.Title)) > maxAutomationNameLen {
+			return errors.New("tasks.titleRequired")
+		}
+		keep.Title = a.Title
+		if a.ColumnID > 0 {
+			keep.ColumnID = a.ColumnID
+		}
+		keep.UserIDs = a.UserIDs
+	case ActionComment:
+		if a.Comment == "" || len([]rune(a.Comment)) > maxAutomationTextLen {
+			return errors.New("tasks.automation.commentRequired")
+		}
+		keep.Comment = a.Comment
+	case ActionCloseIncidentStage:
+		keep.StageTitle = a.StageTitle
+	default:
+		return errors.New("tasks.automation.actionInvalid")
+	}
+	*a = keep
+	return nil
+}
+
+// AutomationColumnIDs lists the columns a rule refers to, for board checks.
+func AutomationColumnIDs(rule *AutomationRule) []int64 {
+	var ids []int64
+	if rule.TriggerColumnID != nil {
+		ids = append(ids, *rule.TriggerColumnID)
+	}
+	for _, a := range rule.Actions {
+		if a.ColumnID > 0 {
+			ids = append(ids, a.ColumnID)
+		}
+	}
+	return ids
+}
+
+// MatchesTrigger reports whether an event of the given trigger fires the rule.
+// refID is the target column for task_moved events.
+func (rule *AutomationRule) MatchesTrigger(trigger string, refID int64) bool {
+	if rule.Trigger != trigger {
+		return false
+	}
+	if rule.Trigger == TriggerTaskMoved && rule.TriggerColumnID != nil {
+		return *rule.TriggerColumnID == refID
+	}
+	return true
+}
+
+// EvaluateAutomation checks the conditions of a rule against a task and returns the
+// actions that would run. It never changes anything, so it backs both the worker and
+// the dry-run endpoint.
+func EvaluateAutomation(rule *AutomationRule, subject AutomationSubject) AutomationPlan {
+	plan := AutomationPlan{RuleID: rule.ID, TaskID: subject.Task.ID, TriggerMatched: true, Matched: true, Conditions: []AutomationConditionResult{}}
+	add := func(name string, ok bool) {
+		plan.Conditions = append(plan.Conditions, AutomationConditionResult{Condition: name, Matched: ok})
+		plan.Matched = plan.Matched && ok
+	}
+	cond := rule.Conditions
+	if len(cond.Priorities) > 0 {
+		add("priority", slices.Contains(cond.Priorities, strings.ToLower(subject.Task.Priority)))
+	}
+	if len(cond.Tags) > 0 {
+		ok := false
+		for _, tag := range subject.Tags {
+			for _, want := range cond.Tags {
+				ok = ok || strings.EqualFold(tag, want)
+			}
+		}
+		add("tag", ok)
+	}
+	if len(cond.AssigneeIDs) > 0 {
+		ok := false
+		for _, id := range subject.AssigneeIDs {
+			ok = ok || slices.Contains(cond.AssigneeIDs, id)
+		}
+		add("assignee", ok)
+	}
+	if len(cond.TemplateIDs) > 0 {
+		add("template", subject.Task.TemplateID != nil && slices.Contains(cond.TemplateIDs, *subject.Task.TemplateID))
+	}
+	if plan.Matched {
+		plan.Actions = append([]AutomationAction{}, rule.Actions...)
+	} else {
+		plan.Actions = []AutomationAction{}
+	}
+	return plan
+}
+
+// ChecklistCompleted reports whether a non-empty checklist is fully done.
+func ChecklistCompleted(items []TaskChecklistItem) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !item.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// DueEventKey identifies one due date of a task, so moving the due date re-arms
+// due_passed rules.
+func DueEventKey(due time.Time) string {
+	return "due:" + due.UTC().Format(time.RFC3339)
+}
+
+func AutomationEventKey(eventID int64) string {
+	return fmt.Sprintf("event:%d", eventID)
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	out := []int64{}
+	for _, id := range ids {
+		if id > 0 && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func uniqueTags(tags []string) []string {
+	out := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.ContainsFunc(out, func(v string) bool { return strings.EqualFold(v, tag) }) {
+			continue
+		}
+		out = append(out, tag)
+	}
+	return out
+}
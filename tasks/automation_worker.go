@@ -0,0 +1,446 @@
+package tasks
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/config"
+	cstore "berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+// automationActor is the audit username of changes made by automation rules.
+const automationActor = "automation"
+
+// AutomationWorker consumes queued task events, scans overdue tasks and runs the
+// matching board automation rules. Every run is claimed in task_automation_runs first,
+// so a rule fires at most once per task and trigger occurrence. Changes made by rules
+// go straight to the store and never queue new events, which keeps rules from
+// triggering each other in a loop.
+type AutomationWorker struct {
+	cfg       config.SchedulerConfig
+	store     Store
+	incidents cstore.IncidentsStore
+	audits    cstore.AuditStore
+	logger    *utils.Logger
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	running bool
+	wg      sync.WaitGroup
+}
+
+func NewAutomationWorker(cfg config.SchedulerConfig, store Store, incidents cstore.IncidentsStore, audits cstore.AuditStore, logger *utils.Logger) *AutomationWorker {
+	return &AutomationWorker{
+		cfg:       cfg,
+		store:     store,
+		incidents: incidents,
+		audits:    audits,
+		logger:    logger,
+	}
+}
+
+func (w *AutomationWorker) Start() {
+	w.StartWithContext(context.Background())
+}
+
+func (w *AutomationWorker) StartWithContext(ctx context.Context) {
+	if w == nil || w.store == nil || !w.cfg.Enabled {
+		return
+	}
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.running = true
+	w.wg.Add(1)
+	w.mu.Unlock()
+
+	interval := time.Duration(w.cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 60 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer w.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = w.RunOnce(runCtx, time.Now().UTC())
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (w *AutomationWorker) Stop() {
+	_ = w.StopWithContext(context.Background())
+}
+
+func (w *AutomationWorker) StopWithContext(ctx context.Context) error {
+	if w == nil || !w.cfg.Enabled {
+		return nil
+	}
+	w.mu.Lock()
+	if w.cancel == nil || !w.running {
+		w.mu.Unlock()
+		return nil
+	}
+	cancel := w.cancel
+	w.cancel = nil
+	w.mu.Unlock()
+	cancel()
+	waitDone := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+		w.mu.Lock()
+		w.running = false
+		w.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *AutomationWorker) RunOnce(ctx context.Context, now time.Time) error {
+	if w == nil || w.store == nil || !w.cfg.Enabled {
+		return nil
+	}
+	limit := w.cfg.MaxJobsPerTick
+	if limit <= 0 {
+		limit = 20
+	}
+	events, err := w.store.ListPendingAutomationEvents(ctx, limit)
+	if err != nil {
+		w.logError("automation.events", err)
+		return err
+	}
+	for _, ev := range events {
+		w.processEvent(ctx, ev, now)
+		if err := w.store.MarkAutomationEventProcessed(ctx, ev.ID, now); err != nil {
+			w.logError("automation.event_done", err)
+		}
+	}
+	rules, err := w.store.ListAutomationRules(ctx, AutomationRuleFilter{Trigger: TriggerDuePassed})
+	if err != nil {
+		w.logError("automation.rules", err)
+		return err
+	}
+	for _, rule := range rules {
+		overdue, err := w.store.ListOverdueAutomationTasks(ctx, rule.ID, rule.BoardID, now, limit)
+		if err != nil {
+			w.logError("automation.overdue", err)
+			continue
+		}
+		for i := range overdue {
+			task := overdue[i]
+			w.apply(ctx, rule, &task, DueEventKey(*task.DueDate), now)
+		}
+	}
+	return nil
+}
+
+func (w *AutomationWorker) processEvent(ctx context.Context, ev AutomationEvent, now time.Time) {
+	task, err := w.store.GetTask(ctx, ev.TaskID)
+	if err != nil || task == nil || task.IsArchived || task.BoardID != ev.BoardID {
+		return
+	}
+	rules, err := w.store.ListAutomationRules(ctx, AutomationRuleFilter{BoardID: ev.BoardID, Trigger: ev.Trigger})
+	if err != nil {
+		w.logError("automation.rules", err)
+		return
+	}
+	for _, rule := range rules {
+		if !rule.MatchesTrigger(ev.Trigger, ev.RefID) {
+			continue
+		}
+		w.apply(ctx, rule, task, AutomationEventKey(ev.ID), now)
+	}
+}
+
+// LoadAutomationSubject loads the tags and assignees conditions are checked against.
+func LoadAutomationSubject(ctx context.Context, store Store, task *Task) (AutomationSubject, error) {
+	subject := AutomationSubject{Task: *task}
+	tags, err := store.ListTaskTagsForTasks(ctx, []int64{task.ID})
+	if err != nil {
+		return subject, err
+	}
+	subject.Tags = tags[task.ID]
+	assignments, err := store.ListTaskAssignments(ctx, task.ID)
+	if err != nil {
+		return subject, err
+	}
+	for _, a := range assignments {
+		subject.AssigneeIDs = append(subject.AssigneeIDs, a.UserID)
+	}
+	return subject, nil
+}
+
+func (w *AutomationWorker) apply(ctx context.Context, rule AutomationRule, task *Task, eventKey string, now time.Time) {
+	subject, err := LoadAutomationSubject(ctx, w.store, task)
+	if err != nil {
+		w.logError("automation.subject", err)
+		return
+	}
+	plan := EvaluateAutomation(&rule, subject)
+	if !plan.Matched {
+		return
+	}
+	run := &AutomationRun{RuleID: rule.ID, TaskID: task.ID, EventKey: eventKey}
+	claimed, err := w.store.ClaimAutomationRun(ctx, run)
+	if err != nil {
+		w.logError("automation.claim", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	status := AutomationRunDone
+	var done []string
+	for _, action := range plan.Actions {
+		if err := w.execute(ctx, &rule, task, action, now); err != nil {
+			status = AutomationRunFailed
+			done = append(done, action.Type+": "+err.Error())
+			break
+		}
+		done = append(done, action.Type)
+	}
+	if err := w.store.FinishAutomationRun(ctx, run.ID, status, strings.Join(done, "; ")); err != nil {
+		w.logError("automation.finish", err)
+	}
+	Log(w.audits, ctx, automationActor, AuditAutomationRun, fmt.Sprintf("%d|%d|%s|%s", rule.ID, task.ID, eventKey, status))
+}
+
+func (w *AutomationWorker) execute(ctx context.Context, rule *AutomationRule, task *Task, action AutomationAction, now time.Time) error {
+	if rule.CreatedBy == nil {
+		return errors.New("rule has no author")
+	}
+	actor := *rule.CreatedBy
+	switch action.Type {
+	case ActionAssign:
+		current, err := w.store.ListTaskAssignments(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		ids := []int64{}
+		for _, a := range current {
+			ids = append(ids, a.UserID)
+		}
+		merged := uniqueIDs(append(ids, action.UserIDs...))
+		if len(merged) == len(ids) {
+			return nil
+		}
+		if err := w.store.SetTaskAssignments(ctx, task.ID, merged, actor); err != nil {
+			return err
+		}
+		Log(w.audits, ctx, automationActor, AuditTaskAssign, fmt.Sprintf("%d", task.ID))
+		if entry := DiffAssignees(task.ID, ids, merged); entry != nil {
+			w.recordActivity(ctx, rule, *entry)
+		}
+	case ActionMove:
+		return w.move(ctx, rule, task, action.ColumnID, actor)
+	case ActionAddTag:
+		tags, err := w.store.ListTaskTagsForTasks(ctx, []int64{task.ID})
+		if err != nil {
+			return err
+		}
+		next := append(append([]string{}, tags[task.ID]...), action.Tag)
+		if err := w.store.SetTaskTags(ctx, task.ID, next); err != nil {
+			return err
+		}
+		Log(w.audits, ctx, automationActor, AuditTaskUpdate, fmt.Sprintf("%d", task.ID))
+		if entry := DiffTags(task.ID, tags[task.ID], next); entry != nil {
+			w.recordActivity(ctx, rule, *entry)
+		}
+	case ActionSetDue:
+		before := *task
+		due := now.UTC().AddDate(0, 0, action.DueDays)
+		task.DueDate = &due
+		if err := w.store.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		Log(w.audits, ctx, automationActor, AuditTaskUpdate, fmt.Sprintf("%d", task.ID))
+		w.recordActivity(ctx, rule, DiffTask(&before, task)...)
+	case ActionCreateSubtask:
+		return w.createSubtask(ctx, task, action, actor)
+	case ActionComment:
+		if _, err := w.store.AddTaskComment(ctx, &Comment{TaskID: task.ID, AuthorID: actor, Content: action.Comment}); err != nil {
+			return err
+		}
+		Log(w.audits, ctx, automationActor, AuditCommentAdd, fmt.Sprintf("%d", task.ID))
+	case ActionCloseIncidentStage:
+		return w.closeIncidentStage(ctx, task, action.StageTitle, actor)
+	default:
+		return errors.New("unknown action")
+	}
+	return nil
+}
+
+func (w *AutomationWorker) move(ctx context.Context, rule *AutomationRule, task *Task, columnID, actor int64) error {
+	if task.ColumnID == columnID {
+		return nil
+	}
+	column, err := w.store.GetColumn(ctx, columnID)
+	if err != nil {
+		return err
+	}
+	if column == nil || !column.IsActive || column.BoardID != task.BoardID {
+		return errors.New("column not found")
+	}
+	if column.IsFinal {
+		blocks, err := w.store.ListActiveTaskBlocksForTasks(ctx, []int64{task.ID})
+		if err != nil {
+			return err
+		}
+		if len(blocks[task.ID]) > 0 {
+			Log(w.audits, ctx, automationActor, AuditTaskMoveDeniedBlockedFinal, fmt.Sprintf("%d|%d", task.ID, columnID))
+			return errors.New("task is blocked")
+		}
+	}
+	moved, err := w.store.MoveTask(ctx, task.ID, columnID, nil, 0)
+	if err != nil {
+		return err
+	}
+	fromName := ""
+	if from, err := w.store.GetColumn(ctx, task.ColumnID); err == nil && from != nil {
+		fromName = from.Name
+	}
+	if entry := MoveActivity(task, moved, fromName, column.Name); entry != nil {
+		w.recordActivity(ctx, rule, *entry)
+	}
+	*task = *moved
+	Log(w.audits, ctx, automationActor, AuditTaskMove, fmt.Sprintf("%d", task.ID))
+	if column.IsFinal {
+		resolved, err := w.store.ResolveTaskBlocksByBlocker(ctx, task.ID, actor)
+		if err != nil {
+			return err
+		}
+		for _, block := range resolved {
+			Log(w.audits, ctx, automationActor, AuditTaskBlockResolveAuto, fmt.Sprintf("%d|%d", block.TaskID, block.ID))
+			w.recordActivity(ctx, rule, BlockActivity(ActivityBlockResolved, &block, true))
+		}
+	}
+	return nil
+}
+
+func (w *AutomationWorker) createSubtask(ctx context.Context, parent *Task, action AutomationAction, actor int64) error {
+	columnID := action.ColumnID
+	if columnID == 0 {
+		columnID = parent.ColumnID
+	}
+	sub := &Task{
+		BoardID:   parent.BoardID,
+		ColumnID:  columnID,
+		Title:     action.Title,
+		Priority:  parent.Priority,
+		CreatedBy: &actor,
+	}
+	parentID := strconv.FormatInt(parent.ID, 10)
+	links := []Link{{TargetType: "task_parent", TargetID: parentID}}
+	if _, err := w.store.CreateTaskWithLinks(ctx, sub, action.UserIDs, links); err != nil {
+		return err
+	}
+	pair := &Link{SourceType: "task", SourceID: parentID, TargetType: "task_child", TargetID: strconv.FormatInt(sub.ID, 10)}
+	if _, err := w.store.AddEntityLink(ctx, pair); err != nil {
+		return err
+	}
+	Log(w.audits, ctx, automationActor, AuditTaskCreate, fmt.Sprintf("%d", sub.ID))
+	Log(w.audits, ctx, automationActor, AuditLinkPairAdd, fmt.Sprintf("%s|task_child|%d", parentID, sub.ID))
+	return nil
+}
+
+// closeIncidentStage completes, in every incident linked to the task, the first open
+// stage with the given title, or the first open stage when the title is empty.
+func (w *AutomationWorker) closeIncidentStage(ctx context.Context, task *Task, title string, actor int64) error {
+	if w.incidents == nil {
+		return errors.New("incidents are not available")
+	}
+	links, err := w.store.ListEntityLinks(ctx, "task", strconv.FormatInt(task.ID, 10))
+	if err != nil {
+		return err
+	}
+	closed := 0
+	for _, link := range links {
+		if link.TargetType != "incident" {
+			continue
+		}
+		incidentID, err := strconv.ParseInt(link.TargetID, 10, 64)
+		if err != nil || incidentID == 0 {
+			continue
+		}
+		incident, err := w.incidents.GetIncident(ctx, incidentID)
+		if err != nil || incident == nil || incident.DeletedAt != nil {
+			continue
+		}
+		stages, err := w.incidents.ListIncidentStages(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+		slices.SortStableFunc(stages, func(a, b cstore.IncidentStage) int { return cmp.Compare(a.Position, b.Position) })
+		for _, stage := range stages {
+			if strings.EqualFold(stage.Status, "done") || (title != "" && !strings.EqualFold(strings.TrimSpace(stage.Title), title)) {
+				continue
+			}
+			if _, err := w.incidents.CompleteIncidentStage(ctx, stage.ID, actor); err != nil {
+				if errors.Is(err, cstore.ErrConflict) {
+					continue
+				}
+				return err
+			}
+			_, _ = w.incidents.AddIncidentTimeline(ctx, &cstore.IncidentTimelineEvent{
+				IncidentID: incidentID,
+				EventType:  "stage.completed",
+				Message:    fmt.Sprintf("stage completed: %s", stage.Title),
+				CreatedBy:  actor,
+			})
+			Log(w.audits, ctx, automationActor, "incidents.stage_completed", fmt.Sprintf("%s|%d", incident.RegNo, stage.ID))
+			closed++
+			break
+		}
+	}
+	if closed == 0 {
+		return errors.New("no open incident stage")
+	}
+	return nil
+}
+
+// recordActivity adds history entries on behalf of the rule author, marked with
+// the rule that produced them.
+func (w *AutomationWorker) recordActivity(ctx context.Context, rule *AutomationRule, items ...TaskActivity) {
+	if len(items) == 0 {
+		return
+	}
+	for i := range items {
+		items[i].ActorID = rule.CreatedBy
+		if items[i].Details == nil {
+			items[i].Details = map[string]any{}
+		}
+		items[i].Details["rule_id"] = rule.ID
+	}
+	if err := w.store.AddTaskActivity(ctx, items); err != nil {
+		w.logError("automation.activity", err)
+	}
+}
+
+func (w *AutomationWorker) logError(scope string, err error) {
+	if w.logger == nil || err == nil {
+		return
+	}
+	w.logger.Errorf("automation %s: %v", scope, err)
+}
@@ -0,0 +1,325 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MaxFlowDays bounds the period of board flow analytics.
+const MaxFlowDays = 366
+
+// FlowColumn is a board column as seen by flow analytics.
+type FlowColumn struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	IsFinal  bool   `json:"is_final"`
+	WIPLimit *int   `json:"wip_limit,omitempty"`
+}
+
+// FlowDay is the number of tasks in each column at the end of a day; it is one
+// row of the cumulative flow diagram.
+type FlowDay struct {
+	Date   time.Time     `json:"date"`
+	Counts map[int64]int `json:"counts"`
+}
+
+// DurationStats summarizes lead or cycle times in seconds using nearest-rank
+// percentiles.
+type DurationStats struct {
+	Count int   `json:"count"`
+	Mean  int64 `json:"mean_seconds"`
+	P50   int64 `json:"p50_seconds"`
+	P75   int64 `json:"p75_seconds"`
+	P85   int64 `json:"p85_seconds"`
+	P95   int64 `json:"p95_seconds"`
+}
+
+type ThroughputWeek struct {
+	WeekStart time.Time `json:"week_start"`
+	Done      int       `json:"done"`
+}
+
+// WIPViolation is a day on which a column held more tasks than its WIP limit.
+type WIPViolation struct {
+	Date     time.Time `json:"date"`
+	ColumnID int64     `json:"column_id"`
+	Count    int       `json:"count"`
+	Limit    int       `json:"limit"`
+}
+
+// AgeingTask is an open task with the time it has spent in its current column.
+type AgeingTask struct {
+	TaskID     int64     `json:"task_id"`
+	Title      string    `json:"title"`
+	ColumnID   int64     `json:"column_id"`
+	Since      time.Time `json:"since"`
+	AgeSeconds int64     `json:"age_seconds"`
+}
+
+// CompletedTask is a task done within the analyzed period.
+type CompletedTask struct {
+	TaskID           int64     `json:"task_id"`
+	Title            string    `json:"title"`
+	DoneAt           time.Time `json:"done_at"`
+	LeadTimeSeconds  int64     `json:"lead_time_seconds"`
+	CycleTimeSeconds *int64    `json:"cycle_time_seconds,omitempty"`
+}
+
+// BoardFlow holds the flow analytics of a board for a period of whole days.
+type BoardFlow struct {
+	BoardID       int64            `json:"board_id"`
+	From          time.Time        `json:"from"`
+	To            time.Time        `json:"to"`
+	Columns       []FlowColumn     `json:"columns"`
+	Cumulative    []FlowDay        `json:"cumulative"`
+	LeadTime      DurationStats    `json:"lead_time"`
+	CycleTime     DurationStats    `json:"cycle_time"`
+	Throughput    []ThroughputWeek `json:"throughput"`
+	WIPViolations []WIPViolation   `json:"wip_violations"`
+	Ageing        []AgeingTask     `json:"ageing"`
+	Completed     []CompletedTask  `json:"completed"`
+}
+
+// LoadBoardFlow reads the columns, tasks and column moves of a board and
+// analyzes them. Archived tasks are included until they were archived.
+func LoadBoardFlow(ctx context.Context, st Store, boardID int64, from, to, now time.Time) (BoardFlow, error) {
+	columns, err := st.ListColumns(ctx, boardID, true)
+	if err != nil {
+		return BoardFlow{}, err
+	}
+	list, err := st.ListTasks(ctx, TaskFilter{BoardID: boardID, IncludeArchived: true})
+	if err != nil {
+		return BoardFlow{}, err
+	}
+	ids := make([]int64, 0, len(list))
+	for _, t := range list {
+		ids = append(ids, t.ID)
+	}
+	history, err := st.ListTaskActivityForTasks(ctx, ids, []string{ActivityMoved, ActivityArchived, ActivityRestored})
+	if err != nil {
+		return BoardFlow{}, err
+	}
+	flow := AnalyzeBoardFlow(columns, list, history, from, to, now)
+	flow.BoardID = boardID
+	return flow, nil
+}
+
+// AnalyzeBoardFlow computes flow analytics from the tasks of a board and their
+// history. from and to are days (inclusive); the end of the period is capped at
+// now.
+func AnalyzeBoardFlow(columns []Column, list []Task, history map[int64][]TaskActivity, from, to, now time.Time) BoardFlow {
+	from, to = WorkDay(from), WorkDay(to)
+	if today := WorkDay(now); to.After(today) {
+		to = today
+	}
+	flow := BoardFlow{
+		From:          from,
+		To:            to,
+		Columns:       []FlowColumn{},
+		Cumulative:    []FlowDay{},
+		Throughput:    []ThroughputWeek{},
+		WIPViolations: []WIPViolation{},
+		Ageing:        []AgeingTask{},
+		Completed:     []CompletedTask{},
+	}
+	sorted := append([]Column(nil), columns...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+	onBoard := map[int64]bool{}
+	finalColumns := map[int64]bool{}
+	for _, c := range sorted {
+		flow.Columns = append(flow.Columns, FlowColumn{ID: c.ID, Name: c.Name, Position: c.Position, IsFinal: c.IsFinal, WIPLimit: c.WIPLimit})
+		onBoard[c.ID] = true
+		finalColumns[c.ID] = c.IsFinal
+	}
+
+	periodEnd := to.AddDate(0, 0, 1)
+	timelines := make([]taskTimeline, 0, len(list))
+	var leads, cycles []int64
+	for i := range list {
+		task := &list[i]
+		tl := newTaskTimeline(task, history[task.ID])
+		timelines = append(timelines, tl)
+		metrics := ComputeTaskFlow(task, history[task.ID], finalColumns, now)
+		if metrics.DoneAt != nil && !metrics.DoneAt.Before(from) && metrics.DoneAt.Before(periodEnd) && metrics.LeadTimeSeconds != nil {
+			flow.Completed = append(flow.Completed, CompletedTask{
+				TaskID:           task.ID,
+				Title:            task.Title,
+				DoneAt:           *metrics.DoneAt,
+				LeadTimeSeconds:  *metrics.LeadTimeSeconds,
+				CycleTimeSeconds: metrics.CycleTimeSeconds,
+			})
+			leads = append(leads, *metrics.LeadTimeSeconds)
+			if metrics.CycleTimeSeconds != nil {
+				cycles = append(cycles, *metrics.CycleTimeSeconds)
+			}
+		}
+		if task.IsArchived || task.ClosedAt != nil || metrics.DoneAt != nil || !onBoard[task.ColumnID] {
+			continue
+		}
+		since := tl.spans[len(tl.spans)-1].from
+		flow.Ageing = append(flow.Ageing, AgeingTask{
+			TaskID:     task.ID,
+			Title:      task.Title,
+			ColumnID:   task.ColumnID,
+			Since:      since,
+			AgeSeconds: max(0, int64(now.Sub(since)/time.Second)),
+		})
+	}
+	sort.SliceStable(flow.Completed, func(i, j int) bool { return flow.Completed[i].DoneAt.Before(flow.Completed[j].DoneAt) })
+	sort.SliceStable(flow.Ageing, func(i, j int) bool { return flow.Ageing[i].AgeSeconds > flow.Ageing[j].AgeSeconds })
+	flow.LeadTime = durationStats(leads)
+	flow.CycleTime = durationStats(cycles)
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		at := day.AddDate(0, 0, 1)
+		if at.After(now) {
+			at = now
+		}
+		counts := map[int64]int{}
+		for _, c := range sorted {
+			counts[c.ID] = 0
+		}
+		for _, tl := range timelines {
+			if col := tl.columnAt(at); onBoard[col] {
+				counts[col]++
+			}
+		}
+		flow.Cumulative = append(flow.Cumulative, FlowDay{Date: day, Counts: counts})
+		for _, c := range sorted {
+			if c.WIPLimit != nil && *c.WIPLimit > 0 && counts[c.ID] > *c.WIPLimit {
+				flow.WIPViolations = append(flow.WIPViolations, WIPViolation{Date: day, ColumnID: c.ID, Count: counts[c.ID], Limit: *c.WIPLimit})
+			}
+		}
+	}
+
+	for week := flowWeekStart(from); !week.After(to); week = week.AddDate(0, 0, 7) {
+		next := week.AddDate(0, 0, 7)
+		done := 0
+		for _, c := range flow.Completed {
+			if !c.DoneAt.Before(week) && c.DoneAt.Before(next) {
+				done++
+			}
+		}
+		flow.Throughput = append(flow.Throughput, ThroughputWeek{WeekStart: week, Done: done})
+	}
+	return flow
+}
+
+type flowSpan struct {
+	columnID int64
+	from     time.Time
+}
+
+type flowGap struct {
+	from time.Time
+	to   *time.Time
+}
+
+// taskTimeline is the column history of a task; gaps are the periods it spent
+// in the archive.
+type taskTimeline struct {
+	created time.Time
+	spans   []flowSpan
+	gaps    []flowGap
+}
+
+func newTaskTimeline(task *Task, history []TaskActivity) taskTimeline {
+	tl := taskTimeline{created: task.CreatedAt}
+	initial := task.ColumnID
+	first := true
+	var moves []flowSpan
+	for _, h := range history {
+		switch h.Action {
+		case ActivityMoved:
+			if h.Field != "column" {
+				continue
+			}
+			next, err := strconv.ParseInt(h.NewValue, 10, 64)
+			if err != nil {
+				continue
+			}
+			if first {
+				if id, err := strconv.ParseInt(h.OldValue, 10, 64); err == nil {
+					initial = id
+				}
+				first = false
+			}
+			moves = append(moves, flowSpan{columnID: next, from: h.CreatedAt})
+		case ActivityArchived:
+			tl.gaps = append(tl.gaps, flowGap{from: h.CreatedAt})
+		case ActivityRestored:
+			if n := len(tl.gaps); n > 0 && tl.gaps[n-1].to == nil {
+				at := h.CreatedAt
+				tl.gaps[n-1].to = &at
+			}
+		}
+	}
+	tl.spans = append([]flowSpan{{columnID: initial, from: task.CreatedAt}}, moves...)
+	archivedOpen := len(tl.gaps) > 0 && tl.gaps[len(tl.gaps)-1].to == nil
+	if task.IsArchived && !archivedOpen {
+		// Archived without a recorded entry (e.g. before history existed).
+		tl.gaps = append(tl.gaps, flowGap{from: task.UpdatedAt})
+	}
+	return tl
+}
+
+// columnAt returns the column a task was in at the given moment, or 0 when it
+// did not exist yet or was archived.
+func (tl taskTimeline) columnAt(at time.Time) int64 {
+	if at.Before(tl.created) {
+		return 0
+	}
+	for _, g := range tl.gaps {
+		if !at.Before(g.from) && (g.to == nil || at.Before(*g.to)) {
+			return 0
+		}
+	}
+	col := int64(0)
+	for _, s := range tl.spans {
+		if s.from.After(at) {
+			break
+		}
+		col = s.columnID
+	}
+	return col
+}
+
+func durationStats(values []int64) DurationStats {
+	if len(values) == 0 {
+		return DurationStats{}
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum int64
+	for _, v := range sorted {
+		sum += v
+	}
+	return DurationStats{
+		Count: len(sorted),
+		Mean:  sum / int64(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P75:   percentile(sorted, 75),
+		P85:   percentile(sorted, 85),
+		P95:   percentile(sorted, 95),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func flowWeekStart(t time.Time) time.Time {
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return WorkDay(t.AddDate(0, 0, -(weekday - 1)))
+}
@@ -0,0 +1,52 @@
+package taskshttp
+
+import (
+	"errors"
+	"net/http"
+
+	"berkut-scc/core/customfields"
+	cstore "berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+// SetCustomFields enables admin-defined fields on tasks.
+func (h *Handler) SetCustomFields(svc *customfields.Service) {
+	if h == nil {
+		return
+	}
+	h.fields = svc
+}
+
+// attachCustomFields fills the custom field values visible to the roles.
+func (h *Handler) attachCustomFields(r *http.Request, roles []string, items []tasks.TaskDTO) {
+	if h.fields == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	values, err := h.fields.Values(r.Context(), cstore.CustomFieldEntityTask, roles, ids)
+	if err != nil {
+		return
+	}
+	for i := range items {
+		items[i].CustomFields = values[items[i].ID]
+	}
+}
+
+func (h *Handler) respondTask(w http.ResponseWriter, r *http.Request, roles []string, code int, dto tasks.TaskDTO) {
+	items := []tasks.TaskDTO{dto}
+	h.attachCustomFields(r, roles, items)
+	respondJSON(w, code, items[0])
+}
+
+// respondCustomFieldError writes an error for an invalid custom field value or filter.
+func respondCustomFieldError(w http.ResponseWriter, err error) {
+	var fieldErr *customfields.FieldError
+	if errors.As(err, &fieldErr) {
+		respondError(w, http.StatusBadRequest, fieldErr.Error())
+		return
+	}
+	respondError(w, http.StatusInternalServerError, "server error")
+}
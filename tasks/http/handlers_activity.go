@@ -0,0 +1,121 @@
+package taskshttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+// TaskActivity returns the change history of a task together with its flow
+// metrics. The creation entry is derived from the task itself.
+func (h *Handler) TaskActivity(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	history, err := h.svc.Store().ListTaskActivity(r.Context(), task.ID, nil)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	created := tasks.TaskActivity{
+		TaskID:    task.ID,
+		ActorID:   task.CreatedBy,
+		Action:    tasks.ActivityCreated,
+		NewValue:  strconv.FormatInt(task.ColumnID, 10),
+		CreatedAt: task.CreatedAt,
+	}
+	items := tasks.MergeActivity(append([]tasks.TaskActivity{created}, history...))
+
+	columnNames := map[int64]string{}
+	finalColumns := map[int64]bool{}
+	columnIDs := []int64{task.ColumnID}
+	actorIDs := []int64{}
+	for _, item := range items {
+		if item.ActorID != nil {
+			actorIDs = append(actorIDs, *item.ActorID)
+		}
+		if item.Action != tasks.ActivityMoved {
+			continue
+		}
+		for _, raw := range []string{item.OldValue, item.NewValue} {
+			if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				columnIDs = append(columnIDs, id)
+			}
+		}
+	}
+	for _, id := range columnIDs {
+		if _, seen := columnNames[id]; seen {
+			continue
+		}
+		columnNames[id] = ""
+		if column, err := h.svc.Store().GetColumn(r.Context(), id); err == nil && column != nil {
+			columnNames[id] = column.Name
+			finalColumns[id] = column.IsFinal
+		}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"items":   items,
+		"users":   h.userNames(r.Context(), actorIDs),
+		"columns": columnNames,
+		"flow":    tasks.ComputeTaskFlow(task, items, finalColumns, time.Now().UTC()),
+	})
+}
+
+// recordActivity stores history entries on behalf of the actor. The history is
+// best-effort: a failure never fails the change that produced it.
+func (h *Handler) recordActivity(ctx context.Context, actorID int64, items ...tasks.TaskActivity) {
+	if len(items) == 0 {
+		return
+	}
+	for i := range items {
+		if actorID > 0 {
+			id := actorID
+			items[i].ActorID = &id
+		}
+	}
+	_ = h.svc.Store().AddTaskActivity(ctx, items)
+}
+
+// recordMove stores a column change of a task with the names of both columns.
+func (h *Handler) recordMove(ctx context.Context, actorID int64, before, after *tasks.Task) {
+	if before == nil || after == nil {
+		return
+	}
+	fromName, toName := "", ""
+	if column, err := h.svc.Store().GetColumn(ctx, before.ColumnID); err == nil && column != nil {
+		fromName = column.Name
+	}
+	if column, err := h.svc.Store().GetColumn(ctx, after.ColumnID); err == nil && column != nil {
+		toName = column.Name
+	}
+	if entry := tasks.MoveActivity(before, after, fromName, toName); entry != nil {
+		h.recordActivity(ctx, actorID, *entry)
+	}
+}
+
+func (h *Handler) userNames(ctx context.Context, ids []int64) map[int64]string {
+	names := map[int64]string{}
+	for _, id := range ids {
+		if _, ok := names[id]; ok || id <= 0 {
+			continue
+		}
+		names[id] = "#" + strconv.FormatInt(id, 10)
+		if u, _, err := h.users.Get(ctx, id); err == nil && u != nil {
+			names[id] = u.Username
+			if strings.TrimSpace(u.FullName) != "" {
+				names[id] = u.FullName
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,306 @@
+package taskshttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	cstore "berkut-scc/core/store"
+	"berkut-scc/tasks"
+	"github.com/go-chi/chi/v5"
+)
+
+type automationRuleDTO struct {
+	tasks.AutomationRule
+	Runs []tasks.AutomationRun `json:"runs,omitempty"`
+}
+
+const automationRecentRuns = 10
+
+func (h *Handler) ListAutomationRules(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	board, ok := h.automationBoard(w, r, user, roles, groups, parseInt64Default(chi.URLParam(r, "board_id"), 0))
+	if !ok {
+		return
+	}
+	rules, err := h.svc.Store().ListAutomationRules(r.Context(), tasks.AutomationRuleFilter{BoardID: board.ID, IncludeInactive: true})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	res := []automationRuleDTO{}
+	for _, rule := range rules {
+		runs, _ := h.svc.Store().ListAutomationRuns(r.Context(), rule.ID, automationRecentRuns)
+		res = append(res, automationRuleDTO{AutomationRule: rule, Runs: runs})
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"items": res})
+}
+
+func (h *Handler) CreateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	board, ok := h.automationBoard(w, r, user, roles, groups, parseInt64Default(chi.URLParam(r, "board_id"), 0))
+	if !ok {
+		return
+	}
+	var payload tasks.AutomationRule
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	rule := &tasks.AutomationRule{
+		BoardID:         board.ID,
+		Name:            payload.Name,
+		Trigger:         payload.Trigger,
+		TriggerColumnID: payload.TriggerColumnID,
+		Conditions:      payload.Conditions,
+		Actions:         payload.Actions,
+		IsActive:        true,
+		CreatedBy:       &user.ID,
+	}
+	if err := h.validateAutomationRule(r.Context(), rule); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := h.svc.Store().CreateAutomationRule(r.Context(), rule); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditAutomationCreate, fmt.Sprintf("%d|%d|%s", rule.ID, board.ID, rule.Trigger))
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+func (h *Handler) UpdateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	rule, ok := h.automationRule(w, r, user, roles, groups)
+	if !ok {
+		return
+	}
+	var payload struct {
+		Name            *string                     `json:"name"`
+		Trigger         *string                     `json:"trigger"`
+		TriggerColumnID *int64                      `json:"trigger_column_id"`
+		Conditions      *tasks.AutomationConditions `json:"conditions"`
+		Actions         []tasks.AutomationAction    `json:"actions"`
+		IsActive        *bool                       `json:"is_active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	if payload.Name != nil {
+		rule.Name = *payload.Name
+	}
+	if payload.Trigger != nil {
+		rule.Trigger = *payload.Trigger
+		rule.TriggerColumnID = payload.TriggerColumnID
+	} else if payload.TriggerColumnID != nil {
+		rule.TriggerColumnID = payload.TriggerColumnID
+	}
+	if payload.Conditions != nil {
+		rule.Conditions = *payload.Conditions
+	}
+	if payload.Actions != nil {
+		rule.Actions = payload.Actions
+	}
+	if payload.IsActive != nil {
+		rule.IsActive = *payload.IsActive
+	}
+	if err := h.validateAutomationRule(r.Context(), rule); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.svc.Store().UpdateAutomationRule(r.Context(), rule); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditAutomationUpdate, fmt.Sprintf("%d|%d|%t", rule.ID, rule.BoardID, rule.IsActive))
+	respondJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) DeleteAutomationRule(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	rule, ok := h.automationRule(w, r, user, roles, groups)
+	if !ok {
+		return
+	}
+	if err := h.svc.Store().DeleteAutomationRule(r.Context(), rule.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditAutomationDelete, fmt.Sprintf("%d|%d", rule.ID, rule.BoardID))
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// DryRunAutomation evaluates a saved rule (rule_id) or an unsaved one (rule) against a
+// task of the board and reports which conditions match and which actions would run.
+// Nothing is changed. With trigger set, the trigger of the rule is checked as well.
+func (h *Handler) DryRunAutomation(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	board, ok := h.automationBoard(w, r, user, roles, groups, parseInt64Default(chi.URLParam(r, "board_id"), 0))
+	if !ok {
+		return
+	}
+	var payload struct {
+		RuleID   int64                 `json:"rule_id"`
+		Rule     *tasks.AutomationRule `json:"rule"`
+		TaskID   int64                 `json:"task_id"`
+		Trigger  string                `json:"trigger"`
+		ColumnID int64                 `json:"column_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	var rule *tasks.AutomationRule
+	switch {
+	case payload.RuleID > 0:
+		rule, err = h.svc.Store().GetAutomationRule(r.Context(), payload.RuleID)
+		if err != nil || rule == nil || rule.BoardID != board.ID {
+			respondError(w, http.StatusNotFound, "tasks.automation.notFound")
+			return
+		}
+	case payload.Rule != nil:
+		rule = payload.Rule
+		rule.ID = 0
+		rule.BoardID = board.ID
+		if err := h.validateAutomationRule(r.Context(), rule); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		respondError(w, http.StatusBadRequest, "tasks.automation.ruleRequired")
+		return
+	}
+	task, err := h.svc.Store().GetTask(r.Context(), payload.TaskID)
+	if err != nil || task == nil || task.BoardID != board.ID {
+		respondError(w, http.StatusNotFound, "tasks.notFound")
+		return
+	}
+	subject, err := tasks.LoadAutomationSubject(r.Context(), h.svc.Store(), task)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	plan := tasks.EvaluateAutomation(rule, subject)
+	if payload.Trigger != "" {
+		plan.TriggerMatched = rule.MatchesTrigger(payload.Trigger, payload.ColumnID)
+		if !plan.TriggerMatched {
+			plan.Matched = false
+			plan.Actions = []tasks.AutomationAction{}
+		}
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditAutomationDryRun, fmt.Sprintf("%d|%d|%t", rule.ID, task.ID, plan.Matched))
+	respondJSON(w, http.StatusOK, plan)
+}
+
+func (h *Handler) automationBoard(w http.ResponseWriter, r *http.Request, user *cstore.User, roles []string, groups []cstore.Group, boardID int64) (*tasks.Board, bool) {
+	if !tasks.Allowed(h.policy, roles, tasks.PermManage) {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return nil, false
+	}
+	if boardID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return nil, false
+	}
+	board, err := h.svc.Store().GetBoard(r.Context(), boardID)
+	if err != nil || board == nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return nil, false
+	}
+	spaceACL := []tasks.ACLRule{}
+	if board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(r.Context(), board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(r.Context(), board.ID)
+	if !boardAllowed(user, roles, groups, spaceACL, boardACL, "manage") {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return nil, false
+	}
+	return board, true
+}
+
+func (h *Handler) automationRule(w http.ResponseWriter, r *http.Request, user *cstore.User, roles []string, groups []cstore.Group) (*tasks.AutomationRule, bool) {
+	ruleID := parseInt64Default(chi.URLParam(r, "id"), 0)
+	if ruleID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return nil, false
+	}
+	rule, err := h.svc.Store().GetAutomationRule(r.Context(), ruleID)
+	if err != nil || rule == nil {
+		respondError(w, http.StatusNotFound, "tasks.automation.notFound")
+		return nil, false
+	}
+	if _, ok := h.automationBoard(w, r, user, roles, groups, rule.BoardID); !ok {
+		return nil, false
+	}
+	return rule, true
+}
+
+// validateAutomationRule normalizes the rule and checks that the columns, templates
+// and users it refers to exist and that the columns belong to the rule's board.
+func (h *Handler) validateAutomationRule(ctx context.Context, rule *tasks.AutomationRule) error {
+	if err := tasks.NormalizeAutomationRule(rule); err != nil {
+		return err
+	}
+	for _, columnID := range tasks.AutomationColumnIDs(rule) {
+		column, err := h.svc.Store().GetColumn(ctx, columnID)
+		if err != nil || column == nil || column.BoardID != rule.BoardID {
+			return errors.New("tasks.columnNotFound")
+		}
+	}
+	for _, templateID := range rule.Conditions.TemplateIDs {
+		tpl, err := h.svc.Store().GetTaskTemplate(ctx, templateID)
+		if err != nil || tpl == nil {
+			return errors.New("tasks.templateNotFound")
+		}
+	}
+	userIDs := append([]int64{}, rule.Conditions.AssigneeIDs...)
+	for _, action := range rule.Actions {
+		userIDs = append(userIDs, action.UserIDs...)
+	}
+	for _, id := range userIDs {
+		if u, _, err := h.users.Get(ctx, id); err != nil || u == nil {
+			return errors.New("tasks.userNotFound")
+		}
+	}
+	return nil
+}
+
+// enqueueAutomation queues a trigger occurrence for the automation worker. Failures
+// are not reported to the client: the user's change itself has succeeded.
+func (h *Handler) enqueueAutomation(ctx context.Context, task *tasks.Task, trigger string, refID int64) {
+	if task == nil {
+		return
+	}
+	_ = h.svc.Store().EnqueueAutomationEvent(ctx, &tasks.AutomationEvent{BoardID: task.BoardID, TaskID: task.ID, Trigger: trigger, RefID: refID})
+}
+
+func (h *Handler) enqueueBlockResolved(ctx context.Context, taskID int64) {
+	task, err := h.svc.Store().GetTask(ctx, taskID)
+	if err != nil || task == nil {
+		return
+	}
+	h.enqueueAutomation(ctx, task, tasks.TriggerBlockResolved, 0)
+}
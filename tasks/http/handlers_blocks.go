@@ -1,7 +1,6 @@
 package taskshttp
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -121,6 +120,7 @@ func (h *Handler) AddTextBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockCreateText, fmt.Sprintf("%d|%d", task.ID, block.ID))
+	h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockAdded, block, false))
 	respondJSON(w, http.StatusCreated, block)
 }
 
@@ -172,7 +172,7 @@ func (h *Handler) AddTaskBlock(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "tasks.blocks.blockerNotFound")
 		return
 	}
-	cycle, err := h.blockCycleExists(r.Context(), payload.BlockerTaskID, task.ID)
+	cycle, err := tasks.DependencyCreatesCycle(r.Context(), h.svc.Store(), payload.BlockerTaskID, task.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "server error")
 		return
@@ -193,6 +193,7 @@ func (h *Handler) AddTaskBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockCreateTask, fmt.Sprintf("%d|%d", task.ID, block.ID))
+	h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockAdded, block, false))
 	respondJSON(w, http.StatusCreated, block)
 }
 
@@ -234,35 +235,7 @@ func (h *Handler) ResolveBlock(w http.ResponseWriter, r *http.Request) {
 		details = fmt.Sprintf("%s|%s", details, comment)
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockResolveManual, details)
+	h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockResolved, block, false))
+	h.enqueueAutomation(r.Context(), task, tasks.TriggerBlockResolved, 0)
 	respondJSON(w, http.StatusOK, block)
 }
-
-func (h *Handler) blockCycleExists(ctx context.Context, blockerTaskID int64, taskID int64) (bool, error) {
-	visited := map[int64]struct{}{}
-	stack := []int64{taskID}
-	for len(stack) > 0 {
-		idx := len(stack) - 1
-		current := stack[idx]
-		stack = stack[:idx]
-		if current == blockerTaskID {
-			return true, nil
-		}
-		if _, ok := visited[current]; ok {
-			continue
-		}
-		visited[current] = struct{}{}
-		blocks, err := h.svc.Store().ListActiveBlocksByBlocker(ctx, current)
-		if err != nil {
-			return false, err
-		}
-		for _, block := range blocks {
-			if block.TaskID == blockerTaskID {
-				return true, nil
-			}
-			if _, ok := visited[block.TaskID]; !ok {
-				stack = append(stack, block.TaskID)
-			}
-		}
-	}
-	return false, nil
-}
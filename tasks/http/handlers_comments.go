@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"berkut-scc/core/notify"
 	"berkut-scc/core/utils"
 	"berkut-scc/tasks"
 	"github.com/go-chi/chi/v5"
@@ -95,6 +96,7 @@ func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditCommentAdd, fmt.Sprintf("%d", task.ID))
+	h.recordCommentMentions(r.Context(), task, comment, user)
 	prepareCommentAttachments(task.ID, comment)
 	respondJSON(w, http.StatusCreated, comment)
 }
@@ -145,6 +147,7 @@ func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditCommentUpdate, fmt.Sprintf("%d|%d", task.ID, comment.ID))
+	h.recordCommentMentions(r.Context(), task, comment, user)
 	prepareCommentAttachments(task.ID, comment)
 	respondJSON(w, http.StatusOK, comment)
 }
@@ -187,6 +190,7 @@ func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditCommentDelete, fmt.Sprintf("%d|%d", task.ID, comment.ID))
+	h.svc.Notifier().ClearMentions(r.Context(), notify.SourceTaskComment, fmt.Sprintf("%d", comment.ID))
 	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -70,6 +70,7 @@ func (h *Handler) AddFile(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskFileAdd, fmt.Sprintf("%d|%d", task.ID, files[i].ID))
+		h.recordActivity(r.Context(), user.ID, tasks.TaskActivity{TaskID: task.ID, Action: tasks.ActivityFileAdded, Field: "file", NewValue: files[i].Name})
 		prepareTaskFile(task.ID, &files[i])
 		created = append(created, files[i])
 	}
@@ -133,6 +134,7 @@ func (h *Handler) DeleteFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskFileDelete, fmt.Sprintf("%d|%d", task.ID, fileID))
+	h.recordActivity(r.Context(), user.ID, tasks.TaskActivity{TaskID: task.ID, Action: tasks.ActivityFileRemoved, Field: "file", OldValue: file.Name})
 	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -0,0 +1,67 @@
+package taskshttp
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/tasks"
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultFlowDays = 30
+
+// BoardFlow returns cumulative flow, lead and cycle time percentiles, weekly
+// throughput, WIP limit violations and ageing of open tasks for a board.
+func (h *Handler) BoardFlow(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	boardID := parseInt64Default(chi.URLParam(r, "board_id"), 0)
+	if boardID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	board, err := h.svc.Store().GetBoard(r.Context(), boardID)
+	if err != nil || board == nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	spaceACL := []tasks.ACLRule{}
+	if board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(r.Context(), board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(r.Context(), board.ID)
+	if !boardAllowed(user, roles, groups, spaceACL, boardACL, "view") {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	now := time.Now().UTC()
+	to := tasks.WorkDay(now)
+	from := to.AddDate(0, 0, -(defaultFlowDays - 1))
+	q := r.URL.Query()
+	for key, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := strings.TrimSpace(q.Get(key))
+		if raw == "" {
+			continue
+		}
+		day, err := parseWorkDate(raw)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "tasks.flow.periodInvalid")
+			return
+		}
+		*target = tasks.WorkDay(day)
+	}
+	if to.Before(from) || to.Sub(from) >= tasks.MaxFlowDays*24*time.Hour {
+		respondError(w, http.StatusBadRequest, "tasks.flow.periodInvalid")
+		return
+	}
+	flow, err := tasks.LoadBoardFlow(r.Context(), h.svc.Store(), board.ID, from, to, now)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	respondJSON(w, http.StatusOK, flow)
+}
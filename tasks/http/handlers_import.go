@@ -0,0 +1,264 @@
+package taskshttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/tasks"
+	"github.com/go-chi/chi/v5"
+)
+
+const maxImportUpload = 64 << 20
+
+// ImportTasks imports a Jira CSV/XML, YouTrack CSV, Trello JSON or generic CSV
+// export (multipart field "file") into a board. Files referenced by the export can
+// be uploaded alongside as "attachments". With preview=1 only the plan is returned.
+func (h *Handler) ImportTasks(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	boardID := parseInt64Default(chi.URLParam(r, "board_id"), 0)
+	if boardID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	board, err := h.svc.Store().GetBoard(r.Context(), boardID)
+	if err != nil || board == nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	spaceACL := []tasks.ACLRule{}
+	if board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(r.Context(), board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(r.Context(), board.ID)
+	if !boardAllowed(user, roles, groups, spaceACL, boardACL, "manage") {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	if err := parseMultipartFormLimited(w, r, maxImportUpload); err != nil {
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "tasks.import.fileRequired")
+		return
+	}
+	data, err := io.ReadAll(file)
+	_ = file.Close()
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "tasks.import.formatInvalid")
+		return
+	}
+	export, err := importer.ParseTaskExport(strings.TrimSpace(r.FormValue("source")), header.Filename, data)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "tasks.import.formatInvalid")
+		return
+	}
+	if len(export.Tasks) > tasks.MaxImportTasks {
+		respondError(w, http.StatusBadRequest, "tasks.import.tooMany")
+		return
+	}
+	opts := tasks.ImportOptions{DefaultColumnID: parseInt64Default(r.FormValue("default_column_id"), 0)}
+	if raw := strings.TrimSpace(r.FormValue("status_map")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts.StatusMap); err != nil {
+			respondError(w, http.StatusBadRequest, "tasks.import.statusMapInvalid")
+			return
+		}
+	}
+	files := map[string]*multipart.FileHeader{}
+	for _, fh := range r.MultipartForm.File["attachments"] {
+		name := filepath.Base(fh.Filename)
+		files[strings.ToLower(name)] = fh
+		opts.Attachments = append(opts.Attachments, name)
+	}
+	resolve, err := h.importUserResolver(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	opts.ResolveUser = resolve
+	plan, err := tasks.PlanImport(r.Context(), h.svc.Store(), board.ID, export, opts)
+	if errors.Is(err, tasks.ErrInvalidInput) {
+		respondError(w, http.StatusBadRequest, "tasks.import.statusMapInvalid")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if r.FormValue("preview") == "1" {
+		respondJSON(w, http.StatusOK, plan)
+		return
+	}
+	for i := range plan.Items {
+		if err := h.applyImportItem(r.Context(), user.ID, board.ID, export.System, &plan.Items[i], files, resolve); err != nil {
+			respondError(w, http.StatusInternalServerError, "server error")
+			return
+		}
+	}
+	plan.Applied = true
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskImport, fmt.Sprintf("%d|%s|created=%d updated=%d unchanged=%d skipped=%d",
+		board.ID, plan.Format, plan.Created, plan.Updated, plan.Unchanged, plan.Skipped))
+	respondJSON(w, http.StatusOK, plan)
+}
+
+// applyImportItem creates or updates one task of an import plan. Imports do not
+// notify assignees or fire automation rules.
+func (h *Handler) applyImportItem(ctx context.Context, userID, boardID int64, system string, item *tasks.ImportPlanItem, files map[string]*multipart.FileHeader, resolve func(string) int64) error {
+	now := time.Now().UTC()
+	st := h.svc.Store()
+	if item.Action == tasks.ImportUnchanged {
+		return nil
+	}
+	if item.Action == tasks.ImportUpdate {
+		task, err := st.GetTask(ctx, item.TaskID)
+		if err != nil || task == nil {
+			return err
+		}
+		before := *task
+		task.Title = item.Title
+		if desc := strings.TrimSpace(item.Source.Description); desc != "" {
+			task.Description = desc
+		}
+		task.Priority = item.Priority
+		if item.Source.DueDate != nil {
+			task.DueDate = item.Source.DueDate
+		}
+		if len(item.Checklist) > 0 {
+			task.Checklist = normalizeChecklist(item.Checklist, task.Checklist, userID, now)
+		}
+		if err := st.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		changes := tasks.DiffTask(&before, task)
+		previous, _ := st.ListTaskAssignments(ctx, task.ID)
+		previousIDs := make([]int64, 0, len(previous))
+		for _, a := range previous {
+			previousIDs = append(previousIDs, a.UserID)
+		}
+		if entry := tasks.DiffAssignees(task.ID, previousIDs, item.Assignees); entry != nil {
+			if err := st.SetTaskAssignments(ctx, task.ID, item.Assignees, userID); err != nil {
+				return err
+			}
+			changes = append(changes, *entry)
+		}
+		previousTags, _ := st.ListTaskTagsForTasks(ctx, []int64{task.ID})
+		if entry := tasks.DiffTags(task.ID, previousTags[task.ID], item.Tags); entry != nil {
+			if err := st.SetTaskTags(ctx, task.ID, item.Tags); err != nil {
+				return err
+			}
+			changes = append(changes, *entry)
+		}
+		h.recordActivity(ctx, userID, changes...)
+		if task.ColumnID != item.ColumnID {
+			moved, err := st.MoveTask(ctx, task.ID, item.ColumnID, nil, 0)
+			if err != nil {
+				return err
+			}
+			h.recordMove(ctx, userID, &before, moved)
+		}
+		return nil
+	}
+
+	task := &tasks.Task{
+		BoardID:      boardID,
+		ColumnID:     item.ColumnID,
+		Title:        item.Title,
+		Description:  strings.TrimSpace(item.Source.Description),
+		ExternalLink: item.ExternalLink,
+		Priority:     item.Priority,
+		Checklist:    normalizeChecklist(item.Checklist, nil, userID, now),
+		CreatedBy:    &userID,
+		DueDate:      item.Source.DueDate,
+	}
+	if _, err := st.CreateTask(ctx, task, item.Assignees); err != nil {
+		return err
+	}
+	item.TaskID = task.ID
+	if len(item.Tags) > 0 {
+		if err := st.SetTaskTags(ctx, task.ID, item.Tags); err != nil {
+			return err
+		}
+	}
+	for _, c := range item.Source.Comments {
+		if strings.TrimSpace(c.Body) == "" {
+			continue
+		}
+		// Comments are posted by the importing user; attributing them to the
+		// matched local account would let an export speak for someone else.
+		comment := &tasks.Comment{TaskID: task.ID, AuthorID: userID, Content: c.Body}
+		if c.Author != "" {
+			comment.Content = fmt.Sprintf("_%s (%s):_\n\n%s", c.Author, system, c.Body)
+		}
+		if c.CreatedAt != nil {
+			comment.CreatedAt = *c.CreatedAt
+		}
+		if _, err := st.AddTaskComment(ctx, comment); err != nil {
+			return err
+		}
+	}
+	var uploads []*multipart.FileHeader
+	for _, name := range item.Attachments {
+		if fh := files[strings.ToLower(name)]; fh != nil {
+			uploads = append(uploads, fh)
+		}
+	}
+	saved, err := saveTaskFiles(task.ID, uploads)
+	if err != nil {
+		return err
+	}
+	for i := range saved {
+		saved[i].TaskID = task.ID
+		saved[i].UploadedBy = &userID
+		if _, err := st.AddTaskFile(ctx, &saved[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importUserResolver matches the users named by an export by login or e-mail, and
+// by full name when exactly one user has it.
+func (h *Handler) importUserResolver(ctx context.Context) (func(string) int64, error) {
+	users, err := h.users.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byLogin := map[string]int64{}
+	byName := map[string]int64{}
+	for _, u := range users {
+		if !u.Active {
+			continue
+		}
+		byLogin[strings.ToLower(u.Username)] = u.ID
+		if email := strings.ToLower(strings.TrimSpace(u.Email)); email != "" {
+			byLogin[email] = u.ID
+		}
+		if name := strings.ToLower(strings.TrimSpace(u.FullName)); name != "" {
+			if _, dup := byName[name]; dup {
+				byName[name] = 0
+			} else {
+				byName[name] = u.ID
+			}
+		}
+	}
+	return func(ref string) int64 {
+		key := strings.ToLower(strings.TrimSpace(ref))
+		if id := byLogin[key]; id > 0 {
+			return id
+		}
+		return byName[key]
+	}, nil
+}
@@ -213,6 +213,7 @@ func (h *Handler) AddLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditLinkAdd, fmt.Sprintf("%d|%s|%s", task.ID, targetType, targetID))
+	h.recordActivity(r.Context(), user.ID, linkActivity(tasks.ActivityLinkAdded, task.ID, targetType, targetID))
 	if targetType == "task_parent" || targetType == "task_child" {
 		pairType := "task_parent"
 		if targetType == "task_parent" {
@@ -226,6 +227,9 @@ func (h *Handler) AddLink(w http.ResponseWriter, r *http.Request) {
 		}
 		if _, err := h.svc.Store().AddEntityLink(r.Context(), pair); err == nil {
 			tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditLinkPairAdd, fmt.Sprintf("%s|%s|%d", targetID, pairType, task.ID))
+			if pairTaskID := parseInt64Default(targetID, 0); pairTaskID > 0 {
+				h.recordActivity(r.Context(), user.ID, linkActivity(tasks.ActivityLinkAdded, pairTaskID, pairType, fmt.Sprintf("%d", task.ID)))
+			}
 		}
 	}
 	respondJSON(w, http.StatusCreated, link)
@@ -271,6 +275,7 @@ func (h *Handler) DeleteLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditLinkRemove, fmt.Sprintf("%d|%s|%s", task.ID, target.TargetType, target.TargetID))
+	h.recordActivity(r.Context(), user.ID, linkActivity(tasks.ActivityLinkRemoved, task.ID, target.TargetType, target.TargetID))
 	if target.TargetType == "task_parent" || target.TargetType == "task_child" {
 		pairType := "task_parent"
 		if target.TargetType == "task_parent" {
@@ -282,6 +287,9 @@ func (h *Handler) DeleteLink(w http.ResponseWriter, r *http.Request) {
 				if pl.TargetType == pairType && pl.TargetID == fmt.Sprintf("%d", task.ID) {
 					_ = h.svc.Store().DeleteEntityLink(r.Context(), pl.ID)
 					tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditLinkPairRemove, fmt.Sprintf("%s|%s|%d", target.TargetID, pairType, task.ID))
+					if pairTaskID := parseInt64Default(target.TargetID, 0); pairTaskID > 0 {
+						h.recordActivity(r.Context(), user.ID, linkActivity(tasks.ActivityLinkRemoved, pairTaskID, pairType, fmt.Sprintf("%d", task.ID)))
+					}
 					break
 				}
 			}
@@ -301,3 +309,12 @@ func allowedByMenuPermissions(menu []string, key string) bool {
 	}
 	return false
 }
+
+func linkActivity(action string, taskID int64, targetType, targetID string) tasks.TaskActivity {
+	return tasks.TaskActivity{
+		TaskID:   taskID,
+		Action:   action,
+		Field:    targetType,
+		NewValue: targetID,
+	}
+}
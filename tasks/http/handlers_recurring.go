@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"berkut-scc/core/schedule"
 	"berkut-scc/tasks"
 	"github.com/go-chi/chi/v5"
 )
@@ -19,6 +20,14 @@ type recurringRuleDTO struct {
 	TemplateActive bool   `json:"template_active"`
 }
 
+// SetCalendars sets the business calendars used to shift recurring runs.
+func (h *Handler) SetCalendars(calendars schedule.CalendarSource) {
+	if h == nil {
+		return
+	}
+	h.calendars = calendars
+}
+
 func (h *Handler) ListRecurringRules(w http.ResponseWriter, r *http.Request) {
 	user, roles, groups, _, err := h.currentUser(r)
 	if err != nil || user == nil {
@@ -90,6 +99,46 @@ func (h *Handler) ListRecurringRules(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]any{"items": res})
 }
 
+// PreviewRecurringSchedule lists the next runs of an unsaved schedule.
+func (h *Handler) PreviewRecurringSchedule(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ScheduleType   string          `json:"schedule_type"`
+		ScheduleConfig json.RawMessage `json:"schedule_config"`
+		TimeOfDay      string          `json:"time_of_day"`
+		Count          int             `json:"count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	normalized, err := tasks.NormalizeScheduleConfig(payload.ScheduleType, payload.ScheduleConfig)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rule, err := tasks.CompileSchedule(r.Context(), h.calendars, payload.ScheduleType, normalized, payload.TimeOfDay)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	count := payload.Count
+	if count <= 0 {
+		count = 10
+	}
+	type occurrence struct {
+		At      time.Time `json:"at"`
+		Local   string    `json:"local"`
+		Holiday string    `json:"holiday,omitempty"`
+	}
+	items := []occurrence{}
+	for _, at := range rule.Occurrences(time.Now().UTC(), min(count, schedule.MaxPreview)) {
+		item := occurrence{At: at.UTC(), Local: at.Format("2006-01-02 15:04 Mon")}
+		item.Holiday, _ = rule.Calendar.Holiday(at)
+		items = append(items, item)
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
 func (h *Handler) CreateRecurringRule(w http.ResponseWriter, r *http.Request) {
 	user, roles, groups, _, err := h.currentUser(r)
 	if err != nil || user == nil {
@@ -145,7 +194,7 @@ func (h *Handler) CreateRecurringRule(w http.ResponseWriter, r *http.Request) {
 	}
 	var nextRun *time.Time
 	if active {
-		next, err := tasks.ComputeNextRunAt(time.Now().UTC(), payload.ScheduleType, normalized, payload.TimeOfDay)
+		next, err := tasks.ComputeNextRunAtWith(r.Context(), h.calendars, time.Now().UTC(), payload.ScheduleType, normalized, payload.TimeOfDay)
 		if err != nil {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
@@ -233,7 +282,7 @@ func (h *Handler) UpdateRecurringRule(w http.ResponseWriter, r *http.Request) {
 	}
 	rule.ScheduleConfig = normalized
 	if rule.IsActive {
-		next, err := tasks.ComputeNextRunAt(time.Now().UTC(), rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
+		next, err := tasks.ComputeNextRunAtWith(r.Context(), h.calendars, time.Now().UTC(), rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
 		if err != nil {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
@@ -294,7 +343,7 @@ func (h *Handler) ToggleRecurringRule(w http.ResponseWriter, r *http.Request) {
 	}
 	rule.IsActive = payload.IsActive
 	if rule.IsActive {
-		next, err := tasks.ComputeNextRunAt(time.Now().UTC(), rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
+		next, err := tasks.ComputeNextRunAtWith(r.Context(), h.calendars, time.Now().UTC(), rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
 		if err != nil {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
@@ -356,13 +405,14 @@ func (h *Handler) RunRecurringRuleNow(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "server error")
 		return
 	}
-	next, err := tasks.ComputeNextRunAt(now, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
+	next, err := tasks.ComputeNextRunAtWith(r.Context(), h.calendars, now, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
 	if err == nil {
 		_ = h.svc.Store().UpdateRecurringRuleRun(r.Context(), rule.ID, now, next)
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditRecurringRunNow, fmt.Sprintf("%d", rule.ID))
 	if created && task != nil {
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskRecurringCreate, fmt.Sprintf("%d", task.ID))
+		h.enqueueAutomation(r.Context(), task, tasks.TriggerTaskCreated, 0)
 		respondJSON(w, http.StatusCreated, buildTaskDTO(*task, nil, tpl.DefaultAssignees, nil, nil, true))
 		return
 	}
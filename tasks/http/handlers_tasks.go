@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	cstore "berkut-scc/core/store"
 	"berkut-scc/tasks"
 	"github.com/go-chi/chi/v5"
 )
@@ -39,6 +40,16 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Query().Get("mine") == "1" || strings.ToLower(r.URL.Query().Get("mine")) == "true" {
 		filter.MineUserID = user.ID
 	}
+	filter.CustomFields, err = h.fields.Conditions(r.Context(), cstore.CustomFieldEntityTask, roles, r.URL.Query())
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+	filter.Query, err = h.queries.FromRequest(r.Context(), cstore.SearchEntityTask, r.URL.Query(), user.ID, roles)
+	if err != nil {
+		respondQueryError(w, err)
+		return
+	}
 	items, err := h.svc.Store().ListTasks(r.Context(), filter)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "server error")
@@ -82,6 +93,7 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		allowDetails := h.canViewBlockDetails(user.ID, roles, &t, taskAssignments)
 		res = append(res, buildTaskDTO(t, taskAssignments, nil, blocksByTask[t.ID], tagsByTask[t.ID], allowDetails))
 	}
+	h.attachCustomFields(r, roles, res)
 	respondJSON(w, http.StatusOK, map[string]any{"items": res})
 }
 
@@ -96,20 +108,22 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var payload struct {
-		BoardID          int64    `json:"board_id"`
-		ColumnID         int64    `json:"column_id"`
-		SubColumnID      *int64   `json:"subcolumn_id"`
-		Title            string   `json:"title"`
-		Description      string   `json:"description"`
-		Result           string   `json:"result"`
-		ExternalLink     string   `json:"external_link"`
-		BusinessCustomer string   `json:"business_customer"`
-		SizeEstimate     *int     `json:"size_estimate"`
-		Priority         string   `json:"priority"`
-		AssignedTo       []string `json:"assigned_to"`
-		Tags             []string `json:"tags"`
-		DueDate          *string  `json:"due_date"`
-		Position         int      `json:"position"`
+		BoardID          int64          `json:"board_id"`
+		ColumnID         int64          `json:"column_id"`
+		SubColumnID      *int64         `json:"subcolumn_id"`
+		Title            string         `json:"title"`
+		Description      string         `json:"description"`
+		Result           string         `json:"result"`
+		ExternalLink     string         `json:"external_link"`
+		BusinessCustomer string         `json:"business_customer"`
+		SizeEstimate     *int           `json:"size_estimate"`
+		Priority         string         `json:"priority"`
+		AssignedTo       []string       `json:"assigned_to"`
+		Tags             []string       `json:"tags"`
+		StartDate        *string        `json:"start_date"`
+		DueDate          *string        `json:"due_date"`
+		Position         int            `json:"position"`
+		CustomFields     map[string]any `json:"custom_fields"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		respondError(w, http.StatusBadRequest, "bad request")
@@ -172,11 +186,25 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "tasks.dueDateInvalid")
 		return
 	}
+	start, err := parseDueDate(payload.StartDate)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "tasks.startDateInvalid")
+		return
+	}
+	if start != nil && due != nil && due.Before(*start) {
+		respondError(w, http.StatusBadRequest, "tasks.startAfterDue")
+		return
+	}
 	assignIDs, err := h.resolveUserIDs(r.Context(), payload.AssignedTo)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "tasks.userNotFound")
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), cstore.CustomFieldEntityTask, roles, payload.CustomFields, true)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
 	task := &tasks.Task{
 		BoardID:          boardID,
 		ColumnID:         payload.ColumnID,
@@ -189,6 +217,7 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		SizeEstimate:     payload.SizeEstimate,
 		Priority:         priority,
 		CreatedBy:        &user.ID,
+		StartDate:        start,
 		DueDate:          due,
 		Position:         payload.Position,
 		IsArchived:       false,
@@ -200,11 +229,17 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	if payload.Tags != nil {
 		_ = h.svc.Store().SetTaskTags(r.Context(), task.ID, payload.Tags)
 	}
+	if err := h.fields.Save(r.Context(), cstore.CustomFieldEntityTask, task.ID, values, user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskCreate, fmt.Sprintf("%d", task.ID))
 	if len(assignIDs) > 0 {
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskAssign, fmt.Sprintf("%d", task.ID))
+		h.notifyAssigned(r.Context(), task, user, assignIDs, nil)
 	}
-	respondJSON(w, http.StatusCreated, buildTaskDTO(*task, nil, assignIDs, nil, payload.Tags, true))
+	h.enqueueAutomation(r.Context(), task, tasks.TriggerTaskCreated, 0)
+	h.respondTask(w, r, roles, http.StatusCreated, buildTaskDTO(*task, nil, assignIDs, nil, payload.Tags, true))
 }
 
 func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -247,7 +282,7 @@ func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
 	blocksByTask, _ := h.svc.Store().ListActiveTaskBlocksForTasks(r.Context(), []int64{task.ID})
 	allowDetails := h.canViewBlockDetails(user.ID, roles, task, assignments)
 	tags, _ := h.svc.Store().ListTaskTagsForTasks(r.Context(), []int64{task.ID})
-	respondJSON(w, http.StatusOK, buildTaskDTO(*task, assignments, nil, blocksByTask[task.ID], tags[task.ID], allowDetails))
+	h.respondTask(w, r, roles, http.StatusOK, buildTaskDTO(*task, assignments, nil, blocksByTask[task.ID], tags[task.ID], allowDetails))
 }
 
 func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
@@ -292,10 +327,13 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		BusinessCustomer *string                    `json:"business_customer"`
 		SizeEstimate     *int                       `json:"size_estimate"`
 		Priority         *string                    `json:"priority"`
+		StartDate        *string                    `json:"start_date"`
 		DueDate          *string                    `json:"due_date"`
 		AssignedTo       []string                   `json:"assigned_to"`
 		Tags             []string                   `json:"tags"`
 		Checklist        *[]tasks.TaskChecklistItem `json:"checklist"`
+		CustomFields     map[string]any             `json:"custom_fields"`
+		ShiftDependents  bool                       `json:"shift_dependents"`
 	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -311,6 +349,7 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if payloadRaw == nil {
 		payloadRaw = map[string]json.RawMessage{}
 	}
+	before := *task
 	if payload.Title != nil {
 		task.Title = strings.TrimSpace(*payload.Title)
 	}
@@ -365,6 +404,23 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 			task.DueDate = &parsed
 		}
 	}
+	if payload.StartDate != nil {
+		if strings.TrimSpace(*payload.StartDate) == "" {
+			task.StartDate = nil
+		} else {
+			parsed, err := parseISOTime(*payload.StartDate)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "tasks.startDateInvalid")
+				return
+			}
+			task.StartDate = &parsed
+		}
+	}
+	if task.StartDate != nil && task.DueDate != nil && task.DueDate.Before(*task.StartDate) {
+		respondError(w, http.StatusBadRequest, "tasks.startAfterDue")
+		return
+	}
+	checklistWasCompleted := tasks.ChecklistCompleted(task.Checklist)
 	if _, ok := payloadRaw["checklist"]; ok {
 		list := []tasks.TaskChecklistItem{}
 		if payload.Checklist != nil {
@@ -376,10 +432,20 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "tasks.titleRequired")
 		return
 	}
+	values, err := h.fields.Prepare(r.Context(), cstore.CustomFieldEntityTask, roles, payload.CustomFields, false)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
 	if err := h.svc.Store().UpdateTask(r.Context(), task); err != nil {
 		respondError(w, http.StatusInternalServerError, "server error")
 		return
 	}
+	if err := h.fields.Save(r.Context(), cstore.CustomFieldEntityTask, task.ID, values, user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	changes := tasks.DiffTask(&before, task)
 	if payload.AssignedTo != nil {
 		if !tasks.Allowed(h.policy, roles, tasks.PermAssign) {
 			respondError(w, http.StatusForbidden, "forbidden")
@@ -390,21 +456,44 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusBadRequest, "tasks.userNotFound")
 			return
 		}
+		previous, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
 		if err := h.svc.Store().SetTaskAssignments(r.Context(), task.ID, assignIDs, user.ID); err != nil {
 			respondError(w, http.StatusInternalServerError, "server error")
 			return
 		}
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskAssign, fmt.Sprintf("%d", task.ID))
+		h.notifyAssigned(r.Context(), task, user, assignIDs, previous)
+		previousIDs := make([]int64, 0, len(previous))
+		for _, a := range previous {
+			previousIDs = append(previousIDs, a.UserID)
+		}
+		if entry := tasks.DiffAssignees(task.ID, previousIDs, assignIDs); entry != nil {
+			changes = append(changes, *entry)
+		}
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskUpdate, fmt.Sprintf("%d", task.ID))
+	if !checklistWasCompleted && tasks.ChecklistCompleted(task.Checklist) {
+		h.enqueueAutomation(r.Context(), task, tasks.TriggerChecklistCompleted, 0)
+	}
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
 	blocksByTask, _ := h.svc.Store().ListActiveTaskBlocksForTasks(r.Context(), []int64{task.ID})
 	allowDetails := h.canViewBlockDetails(user.ID, roles, task, assignments)
+	var previousTags map[int64][]string
 	if payload.Tags != nil {
+		previousTags, _ = h.svc.Store().ListTaskTagsForTasks(r.Context(), []int64{task.ID})
 		_ = h.svc.Store().SetTaskTags(r.Context(), task.ID, payload.Tags)
 	}
 	tags, _ := h.svc.Store().ListTaskTagsForTasks(r.Context(), []int64{task.ID})
-	respondJSON(w, http.StatusOK, buildTaskDTO(*task, assignments, nil, blocksByTask[task.ID], tags[task.ID], allowDetails))
+	if payload.Tags != nil {
+		if entry := tasks.DiffTags(task.ID, previousTags[task.ID], tags[task.ID]); entry != nil {
+			changes = append(changes, *entry)
+		}
+	}
+	h.recordActivity(r.Context(), user.ID, changes...)
+	if payload.ShiftDependents && (!sameTime(before.StartDate, task.StartDate) || !sameTime(before.DueDate, task.DueDate)) {
+		h.shiftDependents(r.Context(), user, roles, groups, task)
+	}
+	h.respondTask(w, r, roles, http.StatusOK, buildTaskDTO(*task, assignments, nil, blocksByTask[task.ID], tags[task.ID], allowDetails))
 }
 
 func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request) {
@@ -492,6 +581,10 @@ func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskMove, fmt.Sprintf("%d", task.ID))
+	h.recordMove(r.Context(), user.ID, task, moved)
+	if task.ColumnID != moved.ColumnID {
+		h.enqueueAutomation(r.Context(), moved, tasks.TriggerTaskMoved, moved.ColumnID)
+	}
 	if column.IsFinal {
 		autoBlocks, err := h.svc.Store().ResolveTaskBlocksByBlocker(r.Context(), task.ID, user.ID)
 		if err != nil {
@@ -500,6 +593,8 @@ func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request) {
 		}
 		for _, block := range autoBlocks {
 			tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockResolveAuto, fmt.Sprintf("%d|%d", block.TaskID, block.ID))
+			h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockResolved, &block, true))
+			h.enqueueBlockResolved(r.Context(), block.TaskID)
 		}
 	}
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
@@ -605,6 +700,10 @@ func (h *Handler) RelocateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskRelocate, fmt.Sprintf("%d|%d|%d", task.ID, targetBoardID, payload.ColumnID))
+	h.recordMove(r.Context(), user.ID, task, moved)
+	if task.ColumnID != moved.ColumnID {
+		h.enqueueAutomation(r.Context(), moved, tasks.TriggerTaskMoved, moved.ColumnID)
+	}
 	if column.IsFinal {
 		autoBlocks, err := h.svc.Store().ResolveTaskBlocksByBlocker(r.Context(), task.ID, user.ID)
 		if err != nil {
@@ -613,6 +712,8 @@ func (h *Handler) RelocateTask(w http.ResponseWriter, r *http.Request) {
 		}
 		for _, block := range autoBlocks {
 			tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockResolveAuto, fmt.Sprintf("%d|%d", block.TaskID, block.ID))
+			h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockResolved, &block, true))
+			h.enqueueBlockResolved(r.Context(), block.TaskID)
 		}
 	}
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
@@ -719,6 +820,7 @@ func (h *Handler) CloneTask(w http.ResponseWriter, r *http.Request) {
 		Priority:         task.Priority,
 		Checklist:        append([]tasks.TaskChecklistItem{}, task.Checklist...),
 		CreatedBy:        &user.ID,
+		StartDate:        task.StartDate,
 		DueDate:          task.DueDate,
 		Position:         payload.Position,
 		IsArchived:       false,
@@ -735,6 +837,7 @@ func (h *Handler) CloneTask(w http.ResponseWriter, r *http.Request) {
 	if len(assignIDs) > 0 {
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskAssign, fmt.Sprintf("%d", newTask.ID))
 	}
+	h.enqueueAutomation(r.Context(), newTask, tasks.TriggerTaskCreated, 0)
 	blocksByTask := map[int64][]tasks.TaskBlock{}
 	respondJSON(w, http.StatusCreated, buildTaskDTO(*newTask, nil, assignIDs, blocksByTask[newTask.ID], tags[task.ID], true))
 }
@@ -789,6 +892,7 @@ func (h *Handler) CloseTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskClose, fmt.Sprintf("%d", task.ID))
+	h.recordActivity(r.Context(), user.ID, tasks.TaskActivity{TaskID: task.ID, Action: tasks.ActivityClosed})
 	autoBlocks, err := h.svc.Store().ResolveTaskBlocksByBlocker(r.Context(), task.ID, user.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "server error")
@@ -796,6 +900,8 @@ func (h *Handler) CloseTask(w http.ResponseWriter, r *http.Request) {
 	}
 	for _, block := range autoBlocks {
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskBlockResolveAuto, fmt.Sprintf("%d|%d", block.TaskID, block.ID))
+		h.recordActivity(r.Context(), user.ID, tasks.BlockActivity(tasks.ActivityBlockResolved, &block, true))
+		h.enqueueBlockResolved(r.Context(), block.TaskID)
 	}
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
 	blocksByTask, _ := h.svc.Store().ListActiveTaskBlocksForTasks(r.Context(), []int64{task.ID})
@@ -844,6 +950,7 @@ func (h *Handler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskArchive, fmt.Sprintf("%d", task.ID))
+	h.recordActivity(r.Context(), user.ID, tasks.TaskActivity{TaskID: task.ID, Action: tasks.ActivityArchived})
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), task.ID)
 	blocksByTask, _ := h.svc.Store().ListActiveTaskBlocksForTasks(r.Context(), []int64{task.ID})
 	allowDetails := h.canViewBlockDetails(user.ID, roles, archived, assignments)
@@ -994,6 +1101,8 @@ func (h *Handler) RestoreTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskRestore, fmt.Sprintf("%d|%d|%d", taskID, restored.BoardID, restored.ColumnID))
+	h.recordActivity(r.Context(), user.ID, tasks.TaskActivity{TaskID: taskID, Action: tasks.ActivityRestored})
+	h.recordMove(r.Context(), user.ID, task, restored)
 	assignments, _ := h.svc.Store().ListTaskAssignments(r.Context(), taskID)
 	blocksByTask, _ := h.svc.Store().ListActiveTaskBlocksForTasks(r.Context(), []int64{taskID})
 	allowDetails := h.canViewBlockDetails(user.ID, roles, restored, assignments)
@@ -455,6 +455,7 @@ func (h *Handler) CreateTaskFromTemplate(w http.ResponseWriter, r *http.Request)
 	if len(tpl.DefaultAssignees) > 0 {
 		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTaskAssign, fmt.Sprintf("%d", task.ID))
 	}
+	h.enqueueAutomation(r.Context(), task, tasks.TriggerTaskCreated, 0)
 	respondJSON(w, http.StatusCreated, buildTaskDTO(*task, nil, tpl.DefaultAssignees, nil, nil, true))
 }
 
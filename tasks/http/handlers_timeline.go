@@ -0,0 +1,296 @@
+package taskshttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	cstore "berkut-scc/core/store"
+	"berkut-scc/tasks"
+	"github.com/go-chi/chi/v5"
+)
+
+// BoardTimeline returns the schedule of a board: tasks with their earliest and
+// latest dates, dependencies, the critical path and dependency cycles.
+func (h *Handler) BoardTimeline(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	boardID := parseInt64Default(chi.URLParam(r, "board_id"), 0)
+	if boardID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	board, err := h.svc.Store().GetBoard(r.Context(), boardID)
+	if err != nil || board == nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	spaceACL := []tasks.ACLRule{}
+	if board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(r.Context(), board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(r.Context(), board.ID)
+	if !boardAllowed(user, roles, groups, spaceACL, boardACL, "view") {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	timeline, err := tasks.LoadTimeline(r.Context(), h.svc.Store(), []int64{board.ID}, time.Now().UTC())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	respondJSON(w, http.StatusOK, timeline)
+}
+
+// SpaceTimeline returns the schedule of the boards of a space the user can view,
+// so dependencies between those boards are taken into account.
+func (h *Handler) SpaceTimeline(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	spaceID := parseInt64Default(chi.URLParam(r, "id"), 0)
+	if spaceID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	space, err := h.svc.Store().GetSpace(r.Context(), spaceID)
+	if err != nil || space == nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	spaceACL, _ := h.svc.Store().GetSpaceACL(r.Context(), space.ID)
+	if !aclAllowed(user, roles, groups, spaceACL, "view") {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	boards, err := h.svc.Store().ListBoards(r.Context(), tasks.BoardFilter{SpaceID: space.ID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	var boardIDs []int64
+	for _, b := range boards {
+		boardACL, _ := h.svc.Store().GetBoardACL(r.Context(), b.ID)
+		if boardAllowed(user, roles, groups, spaceACL, boardACL, "view") {
+			boardIDs = append(boardIDs, b.ID)
+		}
+	}
+	timeline, err := tasks.LoadTimeline(r.Context(), h.svc.Store(), boardIDs, time.Now().UTC())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	respondJSON(w, http.StatusOK, timeline)
+}
+
+// ListDependencies returns the stored dependencies of a task in both directions
+// with the titles of the linked tasks the user can see.
+func (h *Handler) ListDependencies(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	deps, err := h.svc.Store().ListTaskDependencies(r.Context(), []int64{task.ID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if deps == nil {
+		deps = []tasks.TaskDependency{}
+	}
+	titles := map[int64]string{}
+	for _, d := range deps {
+		other := d.PredecessorID
+		if other == task.ID {
+			other = d.SuccessorID
+		}
+		if ref, _ := h.svc.Store().GetTask(r.Context(), other); ref != nil && h.taskVisible(r.Context(), user, roles, groups, ref) {
+			titles[other] = ref.Title
+		}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"items": deps, "titles": titles})
+}
+
+// AddDependency makes another task a predecessor of the task in the URL.
+func (h *Handler) AddDependency(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "manage")
+	if !ok {
+		return
+	}
+	if task.ClosedAt != nil || task.IsArchived {
+		respondError(w, http.StatusConflict, "tasks.closedReadOnly")
+		return
+	}
+	var payload struct {
+		PredecessorID int64  `json:"predecessor_id"`
+		Type          string `json:"type"`
+		LagDays       int    `json:"lag_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	depType := strings.ToLower(strings.TrimSpace(payload.Type))
+	if depType == "" {
+		depType = tasks.DependencyFinishStart
+	}
+	if !tasks.ValidDependencyType(depType) {
+		respondError(w, http.StatusBadRequest, "tasks.dependencies.typeInvalid")
+		return
+	}
+	if payload.LagDays < -tasks.MaxDependencyLagDays || payload.LagDays > tasks.MaxDependencyLagDays {
+		respondError(w, http.StatusBadRequest, "tasks.dependencies.lagInvalid")
+		return
+	}
+	if payload.PredecessorID == 0 {
+		respondError(w, http.StatusBadRequest, "tasks.dependencies.predecessorRequired")
+		return
+	}
+	if payload.PredecessorID == task.ID {
+		respondError(w, http.StatusBadRequest, "tasks.dependencies.self")
+		return
+	}
+	predecessor, err := h.svc.Store().GetTask(r.Context(), payload.PredecessorID)
+	if err != nil || predecessor == nil || !h.taskVisible(r.Context(), user, roles, groups, predecessor) {
+		respondError(w, http.StatusNotFound, "tasks.dependencies.predecessorNotFound")
+		return
+	}
+	cycle, err := tasks.DependencyCreatesCycle(r.Context(), h.svc.Store(), predecessor.ID, task.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if cycle {
+		respondError(w, http.StatusConflict, "tasks.dependencies.cycle")
+		return
+	}
+	dep := &tasks.TaskDependency{
+		PredecessorID: predecessor.ID,
+		SuccessorID:   task.ID,
+		Type:          depType,
+		LagDays:       payload.LagDays,
+		CreatedBy:     &user.ID,
+	}
+	if _, err := h.svc.Store().AddTaskDependency(r.Context(), dep); err != nil {
+		if errors.Is(err, tasks.ErrConflict) {
+			respondError(w, http.StatusConflict, "tasks.dependencies.exists")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditDependencyAdd, fmt.Sprintf("%d|%d|%s|%d", predecessor.ID, task.ID, dep.Type, dep.LagDays))
+	h.recordActivity(r.Context(), user.ID, tasks.DependencyActivity(tasks.ActivityDependencyAdded, dep))
+	respondJSON(w, http.StatusCreated, dep)
+}
+
+// DeleteDependency removes a dependency of the task in the URL, in either
+// direction.
+func (h *Handler) DeleteDependency(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "manage")
+	if !ok {
+		return
+	}
+	depID := parseInt64Default(chi.URLParam(r, "dependency_id"), 0)
+	if depID == 0 {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	dep, err := h.svc.Store().GetTaskDependency(r.Context(), depID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if dep == nil || (dep.PredecessorID != task.ID && dep.SuccessorID != task.ID) {
+		respondError(w, http.StatusNotFound, "tasks.dependencies.notFound")
+		return
+	}
+	if err := h.svc.Store().DeleteTaskDependency(r.Context(), dep.ID); err != nil {
+		if errors.Is(err, tasks.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "tasks.dependencies.notFound")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditDependencyDelete, fmt.Sprintf("%d|%d", dep.PredecessorID, dep.SuccessorID))
+	h.recordActivity(r.Context(), user.ID, tasks.DependencyActivity(tasks.ActivityDependencyRemoved, dep))
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// shiftDependents moves the dependents of a task whose dates changed, on boards
+// the user can manage.
+func (h *Handler) shiftDependents(ctx context.Context, user *cstore.User, roles []string, groups []cstore.Group, task *tasks.Task) {
+	manage := map[int64]bool{}
+	canShift := func(t *tasks.Task) bool {
+		allowed, ok := manage[t.BoardID]
+		if !ok {
+			board, _ := h.svc.Store().GetBoard(ctx, t.BoardID)
+			spaceACL := []tasks.ACLRule{}
+			if board != nil && board.SpaceID > 0 {
+				spaceACL, _ = h.svc.Store().GetSpaceACL(ctx, board.SpaceID)
+			}
+			boardACL, _ := h.svc.Store().GetBoardACL(ctx, t.BoardID)
+			allowed = boardAllowed(user, roles, groups, spaceACL, boardACL, "manage")
+			manage[t.BoardID] = allowed
+		}
+		return allowed
+	}
+	shifted, err := tasks.ShiftDependents(ctx, h.svc.Store(), task, canShift)
+	if err != nil {
+		return
+	}
+	for i := range shifted {
+		next := &shifted[i]
+		before, err := h.svc.Store().GetTask(ctx, next.ID)
+		if err != nil || before == nil {
+			continue
+		}
+		if err := h.svc.Store().UpdateTask(ctx, next); err != nil {
+			continue
+		}
+		h.recordActivity(ctx, user.ID, tasks.DiffTask(before, next)...)
+		tasks.Log(h.audits, ctx, user.Username, tasks.AuditDependencyShift, fmt.Sprintf("%d|%d", task.ID, next.ID))
+	}
+}
+
+func (h *Handler) taskVisible(ctx context.Context, user *cstore.User, roles []string, groups []cstore.Group, task *tasks.Task) bool {
+	board, _ := h.svc.Store().GetBoard(ctx, task.BoardID)
+	spaceACL := []tasks.ACLRule{}
+	if board != nil && board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(ctx, board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(ctx, task.BoardID)
+	return boardAllowed(user, roles, groups, spaceACL, boardACL, "view")
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,501 @@
+package taskshttp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	cstore "berkut-scc/core/store"
+	"berkut-scc/tasks"
+	"github.com/go-chi/chi/v5"
+)
+
+const maxWorklogExportRows = 20000
+
+type worklogPayload struct {
+	Minutes          int     `json:"minutes"`
+	Hours            float64 `json:"hours"`
+	WorkDate         string  `json:"work_date"`
+	Comment          string  `json:"comment"`
+	RemainingMinutes *int    `json:"remaining_minutes"`
+	ClearRemaining   bool    `json:"clear_remaining"`
+}
+
+func (h *Handler) ListWorklogs(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	items, err := h.svc.Store().ListWorklogs(r.Context(), tasks.WorklogFilter{TaskID: task.ID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	summary, err := h.svc.Store().GetTaskTimeSummary(r.Context(), task.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	timer, _ := h.svc.Store().GetTaskTimer(r.Context(), user.ID)
+	if items == nil {
+		items = []tasks.WorklogEntry{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"items":   items,
+		"users":   h.worklogUserNames(r.Context(), items),
+		"summary": summary,
+		"timer":   timer,
+	})
+}
+
+func (h *Handler) AddWorklog(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	if task.IsArchived {
+		respondError(w, http.StatusConflict, "tasks.closedReadOnly")
+		return
+	}
+	var payload worklogPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	entry := &tasks.Worklog{TaskID: task.ID, UserID: user.ID, Minutes: payloadMinutes(payload), Comment: payload.Comment}
+	if strings.TrimSpace(payload.WorkDate) != "" {
+		day, err := parseWorkDate(payload.WorkDate)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "tasks.worklog.dateInvalid")
+			return
+		}
+		entry.WorkDate = day
+	}
+	if err := tasks.NormalizeWorklog(entry); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if payload.RemainingMinutes != nil && *payload.RemainingMinutes < 0 {
+		respondError(w, http.StatusBadRequest, "tasks.worklog.remainingInvalid")
+		return
+	}
+	if _, err := h.svc.Store().AddWorklog(r.Context(), entry); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if err := h.adjustRemaining(r.Context(), task.ID, entry.Minutes, payload, user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditWorklogAdd, fmt.Sprintf("%d|%d|%d", task.ID, entry.ID, entry.Minutes))
+	respondJSON(w, http.StatusCreated, entry)
+}
+
+func (h *Handler) UpdateWorklog(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	entry, ok := h.worklogForTask(w, r, task, user.ID, roles)
+	if !ok {
+		return
+	}
+	var payload worklogPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	previous := entry.Minutes
+	if minutes := payloadMinutes(payload); minutes != 0 {
+		entry.Minutes = minutes
+	}
+	if strings.TrimSpace(payload.WorkDate) != "" {
+		day, err := parseWorkDate(payload.WorkDate)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "tasks.worklog.dateInvalid")
+			return
+		}
+		entry.WorkDate = day
+	}
+	entry.Comment = payload.Comment
+	if err := tasks.NormalizeWorklog(entry); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if payload.RemainingMinutes != nil && *payload.RemainingMinutes < 0 {
+		respondError(w, http.StatusBadRequest, "tasks.worklog.remainingInvalid")
+		return
+	}
+	if err := h.svc.Store().UpdateWorklog(r.Context(), entry); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if err := h.adjustRemaining(r.Context(), task.ID, entry.Minutes-previous, payload, user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditWorklogUpdate, fmt.Sprintf("%d|%d|%d", task.ID, entry.ID, entry.Minutes))
+	respondJSON(w, http.StatusOK, entry)
+}
+
+func (h *Handler) DeleteWorklog(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	entry, ok := h.worklogForTask(w, r, task, user.ID, roles)
+	if !ok {
+		return
+	}
+	if err := h.svc.Store().DeleteWorklog(r.Context(), entry.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditWorklogDelete, fmt.Sprintf("%d|%d|%d", task.ID, entry.ID, entry.Minutes))
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) UpdateTaskEstimate(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	var payload struct {
+		RemainingMinutes *int `json:"remaining_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	if payload.RemainingMinutes != nil && *payload.RemainingMinutes < 0 {
+		respondError(w, http.StatusBadRequest, "tasks.worklog.remainingInvalid")
+		return
+	}
+	if err := h.svc.Store().SetTaskRemainingEstimate(r.Context(), task.ID, payload.RemainingMinutes, user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	summary, err := h.svc.Store().GetTaskTimeSummary(r.Context(), task.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	details := "-"
+	if payload.RemainingMinutes != nil {
+		details = strconv.Itoa(*payload.RemainingMinutes)
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditEstimateUpdate, fmt.Sprintf("%d|%s", task.ID, details))
+	respondJSON(w, http.StatusOK, summary)
+}
+
+func (h *Handler) GetTimer(w http.ResponseWriter, r *http.Request) {
+	user, _, _, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	timer, err := h.svc.Store().GetTaskTimer(r.Context(), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"timer": timer})
+}
+
+func (h *Handler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	task, ok := h.getTaskWithBoardAccess(w, r, user, roles, groups, "view")
+	if !ok {
+		return
+	}
+	if task.IsArchived || task.ClosedAt != nil {
+		respondError(w, http.StatusConflict, "tasks.closedReadOnly")
+		return
+	}
+	timer := &tasks.TaskTimer{UserID: user.ID, TaskID: task.ID, StartedAt: time.Now().UTC()}
+	started, err := h.svc.Store().StartTaskTimer(r.Context(), timer)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if !started {
+		running, _ := h.svc.Store().GetTaskTimer(r.Context(), user.ID)
+		if running != nil && running.TaskID == task.ID {
+			respondJSON(w, http.StatusOK, map[string]any{"timer": running})
+			return
+		}
+		respondError(w, http.StatusConflict, "tasks.worklog.timerRunning")
+		return
+	}
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTimerStart, fmt.Sprintf("%d", task.ID))
+	respondJSON(w, http.StatusCreated, map[string]any{"timer": timer})
+}
+
+// StopTimer logs the running timer of the current user; a discard flag drops it
+// without logging.
+func (h *Handler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	user, _, _, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var payload struct {
+		Comment string `json:"comment"`
+		Discard bool   `json:"discard"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respondError(w, http.StatusBadRequest, "bad request")
+			return
+		}
+	}
+	running, err := h.svc.Store().GetTaskTimer(r.Context(), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if running == nil {
+		respondError(w, http.StatusNotFound, "tasks.worklog.timerNotRunning")
+		return
+	}
+	if payload.Discard {
+		if err := h.svc.Store().DiscardTaskTimer(r.Context(), user.ID); err != nil {
+			respondError(w, http.StatusInternalServerError, "server error")
+			return
+		}
+		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTimerStop, fmt.Sprintf("%d|discard", running.TaskID))
+		respondJSON(w, http.StatusOK, map[string]any{"worklog": nil})
+		return
+	}
+	entry, err := h.svc.Store().StopTaskTimer(r.Context(), user.ID, time.Now().UTC(), payload.Comment)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if entry != nil {
+		if err := h.adjustRemaining(r.Context(), entry.TaskID, entry.Minutes, worklogPayload{}, user.ID); err != nil {
+			respondError(w, http.StatusInternalServerError, "server error")
+			return
+		}
+		tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditTimerStop, fmt.Sprintf("%d|%d|%d", entry.TaskID, entry.ID, entry.Minutes))
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"worklog": entry})
+}
+
+func (h *Handler) WorklogSummary(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	groupBy := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("group_by")))
+	if groupBy == "" {
+		groupBy = tasks.EffortByUser
+	}
+	if !tasks.IsEffortGroup(groupBy) {
+		respondError(w, http.StatusBadRequest, "tasks.worklog.groupInvalid")
+		return
+	}
+	filter, ok := worklogFilterFromQuery(w, r.URL.Query())
+	if !ok {
+		return
+	}
+	entries, err := h.visibleWorklogs(r.Context(), user, roles, groups, filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	names := h.worklogUserNames(r.Context(), entries)
+	items := tasks.AggregateWorklogs(entries, groupBy, func(id int64) string { return names[id] })
+	total := 0
+	for _, item := range items {
+		total += item.Minutes
+	}
+	if items == nil {
+		items = []tasks.EffortAggregate{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"group_by": groupBy, "items": items, "total_minutes": total})
+}
+
+func (h *Handler) ExportWorklogs(w http.ResponseWriter, r *http.Request) {
+	user, roles, groups, _, err := h.currentUser(r)
+	if err != nil || user == nil {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	filter, ok := worklogFilterFromQuery(w, r.URL.Query())
+	if !ok {
+		return
+	}
+	filter.Limit = maxWorklogExportRows
+	entries, err := h.visibleWorklogs(r.Context(), user, roles, groups, filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	names := h.worklogUserNames(r.Context(), entries)
+	filename := fmt.Sprintf("worklogs_%s.csv", time.Now().UTC().Format("20060102_150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", attachmentDisposition(filename))
+	w.WriteHeader(http.StatusOK)
+	tasks.Log(h.audits, r.Context(), user.Username, tasks.AuditWorklogExport, strconv.Itoa(len(entries)))
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"id", "work_date", "user", "task_id", "task", "board", "space", "business_customer", "minutes", "hours", "source", "comment"})
+	for _, e := range entries {
+		_ = writer.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.WorkDate.UTC().Format("2006-01-02"),
+			names[e.UserID],
+			strconv.FormatInt(e.TaskID, 10),
+			e.TaskTitle,
+			e.BoardName,
+			e.SpaceName,
+			e.BusinessCustomer,
+			strconv.Itoa(e.Minutes),
+			strconv.FormatFloat(float64(e.Minutes)/60, 'f', 2, 64),
+			e.Source,
+			e.Comment,
+		})
+	}
+	writer.Flush()
+}
+
+func (h *Handler) worklogForTask(w http.ResponseWriter, r *http.Request, task *tasks.Task, userID int64, roles []string) (*tasks.Worklog, bool) {
+	id := parseInt64Default(chi.URLParam(r, "worklog_id"), 0)
+	entry, err := h.svc.Store().GetWorklog(r.Context(), id)
+	if err != nil || entry == nil || entry.TaskID != task.ID {
+		respondError(w, http.StatusNotFound, "tasks.worklog.notFound")
+		return nil, false
+	}
+	if entry.UserID != userID && !isAdminRole(roles) && !tasks.Allowed(h.policy, roles, tasks.PermManage) {
+		respondError(w, http.StatusForbidden, "forbidden")
+		return nil, false
+	}
+	return entry, true
+}
+
+// adjustRemaining applies an explicit remaining estimate from the payload, or
+// reduces the stored one by the newly logged minutes (a negative delta restores it).
+func (h *Handler) adjustRemaining(ctx context.Context, taskID int64, logged int, payload worklogPayload, userID int64) error {
+	if payload.ClearRemaining {
+		return h.svc.Store().SetTaskRemainingEstimate(ctx, taskID, nil, userID)
+	}
+	if payload.RemainingMinutes != nil {
+		return h.svc.Store().SetTaskRemainingEstimate(ctx, taskID, payload.RemainingMinutes, userID)
+	}
+	if logged == 0 {
+		return nil
+	}
+	summary, err := h.svc.Store().GetTaskTimeSummary(ctx, taskID)
+	if err != nil || summary.RemainingMinutes == nil {
+		return err
+	}
+	return h.svc.Store().SetTaskRemainingEstimate(ctx, taskID, tasks.RemainingAfter(summary.RemainingMinutes, logged), userID)
+}
+
+// visibleWorklogs drops worklogs of boards the user cannot view.
+func (h *Handler) visibleWorklogs(ctx context.Context, user *cstore.User, roles []string, groups []cstore.Group, filter tasks.WorklogFilter) ([]tasks.WorklogEntry, error) {
+	entries, err := h.svc.Store().ListWorklogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[int64]bool{}
+	spaceACL := map[int64][]tasks.ACLRule{}
+	var out []tasks.WorklogEntry
+	for _, e := range entries {
+		ok, seen := allowed[e.BoardID]
+		if !seen {
+			if _, loaded := spaceACL[e.SpaceID]; !loaded && e.SpaceID > 0 {
+				acl, _ := h.svc.Store().GetSpaceACL(ctx, e.SpaceID)
+				spaceACL[e.SpaceID] = acl
+			}
+			boardACL, _ := h.svc.Store().GetBoardACL(ctx, e.BoardID)
+			ok = boardAllowed(user, roles, groups, spaceACL[e.SpaceID], boardACL, "view")
+			allowed[e.BoardID] = ok
+		}
+		if ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (h *Handler) worklogUserNames(ctx context.Context, entries []tasks.WorklogEntry) map[int64]string {
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.UserID)
+	}
+	return h.userNames(ctx, ids)
+}
+
+func worklogFilterFromQuery(w http.ResponseWriter, q url.Values) (tasks.WorklogFilter, bool) {
+	filter := tasks.WorklogFilter{
+		UserID:   parseInt64Default(q.Get("user_id"), 0),
+		BoardID:  parseInt64Default(q.Get("board_id"), 0),
+		SpaceID:  parseInt64Default(q.Get("space_id"), 0),
+		Customer: strings.TrimSpace(q.Get("customer")),
+	}
+	for key, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		raw := strings.TrimSpace(q.Get(key))
+		if raw == "" {
+			continue
+		}
+		day, err := parseWorkDate(raw)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "tasks.worklog.dateInvalid")
+			return filter, false
+		}
+		*target = &day
+	}
+	return filter, true
+}
+
+func parseWorkDate(raw string) (time.Time, error) {
+	clean := strings.TrimSpace(raw)
+	if day, err := time.Parse("2006-01-02", clean); err == nil {
+		return day, nil
+	}
+	return parseISOTime(clean)
+}
+
+func payloadMinutes(payload worklogPayload) int {
+	if payload.Minutes != 0 || payload.Hours == 0 {
+		return payload.Minutes
+	}
+	return int(payload.Hours*60 + 0.5)
+}
@@ -11,9 +11,12 @@ import (
 
 	"berkut-scc/config"
 	"berkut-scc/core/auth"
+	"berkut-scc/core/customfields"
 	"berkut-scc/core/docs"
 	"berkut-scc/core/incidents"
+	"berkut-scc/core/query"
 	"berkut-scc/core/rbac"
+	"berkut-scc/core/schedule"
 	cstore "berkut-scc/core/store"
 	"berkut-scc/tasks"
 	"github.com/go-chi/chi/v5"
@@ -33,6 +36,9 @@ type Handler struct {
 	entityLinks    cstore.EntityLinksStore
 	policy         *rbac.Policy
 	audits         cstore.AuditStore
+	fields         *customfields.Service
+	queries        *query.Service
+	calendars      schedule.CalendarSource
 }
 
 func NewHandler(cfg *config.AppConfig, svc *tasks.Service, users cstore.UsersStore, docsStore cstore.DocsStore, docsSvc *docs.Service, incidentsStore cstore.IncidentsStore, incidentsSvc *incidents.Service, controlsStore cstore.ControlsStore, assetsStore cstore.AssetsStore, softwareStore cstore.SoftwareStore, entityLinks cstore.EntityLinksStore, policy *rbac.Policy, audits cstore.AuditStore) *Handler {
@@ -0,0 +1,74 @@
+package taskshttp
+
+import (
+	"context"
+	"fmt"
+
+	"berkut-scc/core/auth"
+	"berkut-scc/core/notify"
+	cstore "berkut-scc/core/store"
+	"berkut-scc/tasks"
+)
+
+// notifyAssigned tells users newly added to the task that it is theirs now; previous lists
+// the assignees before the change so re-saving a task does not notify everybody again.
+func (h *Handler) notifyAssigned(ctx context.Context, task *tasks.Task, actor *cstore.User, assignIDs []int64, previous []tasks.Assignment) {
+	n := h.svc.Notifier()
+	if n == nil || task == nil || len(assignIDs) == 0 {
+		return
+	}
+	had := map[int64]bool{}
+	for _, a := range previous {
+		had[a.UserID] = true
+	}
+	var added []int64
+	for _, id := range assignIDs {
+		if !had[id] {
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	item := cstore.Notification{
+		EventType:  notify.EventTaskAssigned,
+		Title:      fmt.Sprintf("#%d %s", task.ID, task.Title),
+		EntityType: "task",
+		EntityID:   fmt.Sprintf("%d", task.ID),
+		Link:       fmt.Sprintf("/tasks/task/%d", task.ID),
+	}
+	if actor != nil {
+		item.ActorID = &actor.ID
+		item.Body = "Assigned by " + actor.Username
+		item.BodyKey = "notifications.task.assignedBy"
+		item.Params = map[string]string{"user": actor.Username}
+	}
+	n.Notify(ctx, item, added...)
+}
+
+// recordCommentMentions stores @mentions of the comment for users who can open the task's board.
+func (h *Handler) recordCommentMentions(ctx context.Context, task *tasks.Task, comment *tasks.Comment, author *cstore.User) {
+	n := h.svc.Notifier()
+	if n == nil || task == nil || comment == nil {
+		return
+	}
+	board, _ := h.svc.Store().GetBoard(ctx, task.BoardID)
+	spaceACL := []tasks.ACLRule{}
+	if board != nil && board.SpaceID > 0 {
+		spaceACL, _ = h.svc.Store().GetSpaceACL(ctx, board.SpaceID)
+	}
+	boardACL, _ := h.svc.Store().GetBoardACL(ctx, task.BoardID)
+	src := notify.MentionSource{
+		SourceType: notify.SourceTaskComment,
+		SourceID:   fmt.Sprintf("%d", comment.ID),
+		EntityType: "task",
+		EntityID:   fmt.Sprintf("%d", task.ID),
+		Title:      fmt.Sprintf("#%d %s", task.ID, task.Title),
+		Link:       fmt.Sprintf("/tasks/task/%d", task.ID),
+	}
+	n.RecordMentions(ctx, src, author, comment.Content, func(ctx context.Context, u *cstore.User, roles []string) bool {
+		groups, _ := h.users.UserGroups(ctx, u.ID)
+		eff := auth.CalculateEffectiveAccess(u, roles, groups, h.policy)
+		return tasks.Allowed(h.policy, eff.Roles, tasks.PermView) && boardAllowed(u, eff.Roles, groups, spaceACL, boardACL, "view")
+	})
+}
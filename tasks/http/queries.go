@@ -0,0 +1,36 @@
+package taskshttp
+
+import (
+	"errors"
+	"net/http"
+
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/query"
+)
+
+// SetQueries enables the query and saved_search filters of the task list.
+func (h *Handler) SetQueries(svc *query.Service) {
+	if h == nil {
+		return
+	}
+	h.queries = svc
+}
+
+// respondQueryError writes an error for an invalid query or an unknown saved search.
+func respondQueryError(w http.ResponseWriter, err error) {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		respondError(w, http.StatusBadRequest, queryErr.Error())
+		return
+	}
+	if errors.Is(err, query.ErrSearchNotFound) {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	var fieldErr *customfields.FieldError
+	if errors.As(err, &fieldErr) {
+		respondError(w, http.StatusBadRequest, fieldErr.Error())
+		return
+	}
+	respondError(w, http.StatusInternalServerError, "server error")
+}
@@ -27,6 +27,7 @@ func RegisterRoutes(deps RouteDeps) http.Handler {
 	r.Delete("/tasks/spaces/{id}", withSession(require(tasks.PermManage)(h.DeleteSpace)))
 	r.Get("/tasks/spaces/{id}/layout", withSession(require(tasks.PermView)(h.GetBoardLayout)))
 	r.Post("/tasks/spaces/{id}/layout", withSession(require(tasks.PermView)(h.SaveBoardLayout)))
+	r.Get("/tasks/spaces/{id}/timeline", withSession(require(tasks.PermView)(h.SpaceTimeline)))
 
 	r.Get("/tasks/boards", withSession(require(tasks.PermView)(h.ListBoards)))
 	r.Post("/tasks/boards", withSession(require(tasks.PermManage)(h.CreateBoard)))
@@ -36,6 +37,14 @@ func RegisterRoutes(deps RouteDeps) http.Handler {
 	r.Get("/tasks/boards/{board_id}/columns", withSession(require(tasks.PermView)(h.ListColumns)))
 	r.Post("/tasks/boards/{board_id}/columns", withSession(require(tasks.PermManage)(h.CreateColumn)))
 	r.Get("/tasks/boards/{board_id}/subcolumns", withSession(require(tasks.PermView)(h.ListSubColumnsByBoard)))
+	r.Get("/tasks/boards/{board_id}/flow", withSession(require(tasks.PermView)(h.BoardFlow)))
+	r.Post("/tasks/boards/{board_id}/import", withSession(require(tasks.PermCreate)(h.ImportTasks)))
+	r.Get("/tasks/boards/{board_id}/timeline", withSession(require(tasks.PermView)(h.BoardTimeline)))
+	r.Get("/tasks/boards/{board_id}/automation", withSession(require(tasks.PermManage)(h.ListAutomationRules)))
+	r.Post("/tasks/boards/{board_id}/automation", withSession(require(tasks.PermManage)(h.CreateAutomationRule)))
+	r.Post("/tasks/boards/{board_id}/automation/dry-run", withSession(require(tasks.PermManage)(h.DryRunAutomation)))
+	r.Put("/tasks/automation/{id}", withSession(require(tasks.PermManage)(h.UpdateAutomationRule)))
+	r.Delete("/tasks/automation/{id}", withSession(require(tasks.PermManage)(h.DeleteAutomationRule)))
 	r.Put("/tasks/columns/{id}", withSession(require(tasks.PermManage)(h.UpdateColumn)))
 	r.Delete("/tasks/columns/{id}", withSession(require(tasks.PermManage)(h.DeleteColumn)))
 	r.Post("/tasks/columns/{id}/move", withSession(require(tasks.PermManage)(h.MoveColumn)))
@@ -55,11 +64,16 @@ func RegisterRoutes(deps RouteDeps) http.Handler {
 	r.Post("/tasks/templates/{id}/create-task", withSession(require(tasks.PermCreate)(h.CreateTaskFromTemplate)))
 	r.Get("/tasks/recurring", withSession(require(tasks.PermRecurringView)(h.ListRecurringRules)))
 	r.Post("/tasks/recurring", withSession(require(tasks.PermRecurringManage)(h.CreateRecurringRule)))
+	r.Post("/tasks/recurring/preview", withSession(require(tasks.PermRecurringView)(h.PreviewRecurringSchedule)))
 	r.Put("/tasks/recurring/{id}", withSession(require(tasks.PermRecurringManage)(h.UpdateRecurringRule)))
 	r.Post("/tasks/recurring/{id}/toggle", withSession(require(tasks.PermRecurringManage)(h.ToggleRecurringRule)))
 	r.Post("/tasks/recurring/{id}/run-now", withSession(require(tasks.PermRecurringRun)(h.RunRecurringRuleNow)))
 	r.Get("/tasks/archive", withSession(require(tasks.PermArchive)(h.ListArchivedTasks)))
 	r.Post("/tasks/archive/{id}/restore", withSession(require(tasks.PermArchive)(h.RestoreTask)))
+	r.Get("/tasks/worklogs/summary", withSession(require(tasks.PermView)(h.WorklogSummary)))
+	r.Get("/tasks/worklogs/export", withSession(require(tasks.PermView)(h.ExportWorklogs)))
+	r.Get("/tasks/timer", withSession(require(tasks.PermView)(h.GetTimer)))
+	r.Post("/tasks/timer/stop", withSession(require(tasks.PermComment)(h.StopTimer)))
 	r.Get("/tasks/{id}", withSession(require(tasks.PermView)(h.GetTask)))
 	r.Put("/tasks/{id}", withSession(require(tasks.PermEdit)(h.UpdateTask)))
 	r.Delete("/tasks/{id}", withSession(require(tasks.PermArchive)(h.DeleteTask)))
@@ -68,12 +82,19 @@ func RegisterRoutes(deps RouteDeps) http.Handler {
 	r.Post("/tasks/{id}/clone", withSession(require(tasks.PermCreate)(h.CloneTask)))
 	r.Post("/tasks/{id}/close", withSession(require(tasks.PermClose)(h.CloseTask)))
 	r.Post("/tasks/{id}/archive", withSession(require(tasks.PermArchive)(h.ArchiveTask)))
+	r.Get("/tasks/{id}/activity", withSession(require(tasks.PermView)(h.TaskActivity)))
 	r.Get("/tasks/{id}/comments", withSession(require(tasks.PermView)(h.ListComments)))
 	r.Post("/tasks/{id}/comments", withSession(require(tasks.PermComment)(h.AddComment)))
 	r.Put("/tasks/{id}/comments/{comment_id}", withSession(require(tasks.PermComment)(h.UpdateComment)))
 	r.Delete("/tasks/{id}/comments/{comment_id}", withSession(require(tasks.PermComment)(h.DeleteComment)))
 	r.Get("/tasks/{id}/comments/{comment_id}/files/{file_id}", withSession(require(tasks.PermView)(h.DownloadCommentFile)))
 	r.Delete("/tasks/{id}/comments/{comment_id}/files/{file_id}", withSession(require(tasks.PermComment)(h.DeleteCommentFile)))
+	r.Get("/tasks/{id}/worklogs", withSession(require(tasks.PermView)(h.ListWorklogs)))
+	r.Post("/tasks/{id}/worklogs", withSession(require(tasks.PermComment)(h.AddWorklog)))
+	r.Put("/tasks/{id}/worklogs/{worklog_id}", withSession(require(tasks.PermComment)(h.UpdateWorklog)))
+	r.Delete("/tasks/{id}/worklogs/{worklog_id}", withSession(require(tasks.PermComment)(h.DeleteWorklog)))
+	r.Put("/tasks/{id}/estimate", withSession(require(tasks.PermEdit)(h.UpdateTaskEstimate)))
+	r.Post("/tasks/{id}/timer/start", withSession(require(tasks.PermComment)(h.StartTimer)))
 	r.Get("/tasks/{id}/files", withSession(require(tasks.PermView)(h.ListFiles)))
 	r.Post("/tasks/{id}/files", withSession(require(tasks.PermEdit)(h.AddFile)))
 	r.Get("/tasks/{id}/files/{file_id}", withSession(require(tasks.PermView)(h.DownloadFile)))
@@ -86,5 +107,8 @@ func RegisterRoutes(deps RouteDeps) http.Handler {
 	r.Post("/tasks/{id}/blocks/text", withSession(require(tasks.PermBlockCreate)(h.AddTextBlock)))
 	r.Post("/tasks/{id}/blocks/task", withSession(require(tasks.PermBlockCreate)(h.AddTaskBlock)))
 	r.Post("/tasks/{id}/blocks/{block_id}/resolve", withSession(require(tasks.PermBlockResolve)(h.ResolveBlock)))
+	r.Get("/tasks/{id}/dependencies", withSession(require(tasks.PermView)(h.ListDependencies)))
+	r.Post("/tasks/{id}/dependencies", withSession(require(tasks.PermEdit)(h.AddDependency)))
+	r.Delete("/tasks/{id}/dependencies/{dependency_id}", withSession(require(tasks.PermEdit)(h.DeleteDependency)))
 	return r
 }
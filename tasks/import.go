@@ -0,0 +1,354 @@
+package tasks
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"berkut-scc/core/importer"
+)
+
+// Import plan actions.
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+
+	// MaxImportTasks bounds the number of tasks in one import file.
+	MaxImportTasks = 5000
+)
+
+// Import report reasons. The first group leaves a task out of the import; the
+// second imports it without the named part or with a fallback value.
+const (
+	ImportSkipTitleMissing      = "title_missing"
+	ImportSkipExternalIDMissing = "external_id_missing"
+	ImportSkipDuplicate         = "duplicate"
+	ImportSkipArchivedInSource  = "archived_in_source"
+	ImportSkipTaskReadOnly      = "task_read_only"
+
+	ImportSkipUserNotFound      = "user_not_found"
+	ImportSkipStatusUnmapped    = "status_unmapped"
+	ImportSkipPriorityUnknown   = "priority_unknown"
+	ImportSkipAttachmentMissing = "attachment_missing"
+)
+
+// importPriorities maps the priority names of Jira, YouTrack and common CSV exports.
+var importPriorities = map[string]string{
+	"blocker":      PriorityCritical,
+	"show-stopper": PriorityCritical,
+	"highest":      PriorityCritical,
+	"urgent":       PriorityCritical,
+	"critical":     PriorityCritical,
+	"major":        PriorityHigh,
+	"high":         PriorityHigh,
+	"normal":       PriorityMedium,
+	"medium":       PriorityMedium,
+	"minor":        PriorityLow,
+	"low":          PriorityLow,
+	"lowest":       PriorityLow,
+	"trivial":      PriorityLow,
+}
+
+// ImportOptions controls how an export lands on a board. StatusMap keys are matched
+// case-insensitively; statuses it does not cover go to the column with the same name,
+// then to DefaultColumnID, then to the first column. ResolveUser returns 0 for users
+// that do not exist.
+type ImportOptions struct {
+	StatusMap       map[string]int64
+	DefaultColumnID int64
+	Attachments     []string
+	ResolveUser     func(ref string) int64
+}
+
+// ImportPlanItem is a task the import creates or updates. Source carries the parsed
+// task for comments and attachments, which are only added to new tasks.
+type ImportPlanItem struct {
+	Row          int                   `json:"row"`
+	ExternalLink string                `json:"external_link"`
+	Title        string                `json:"title"`
+	Action       string                `json:"action"`
+	TaskID       int64                 `json:"task_id,omitempty"`
+	Status       string                `json:"status,omitempty"`
+	ColumnID     int64                 `json:"column_id"`
+	Priority     string                `json:"priority"`
+	Assignees    []int64               `json:"assignees"`
+	Tags         []string              `json:"tags"`
+	Checklist    []TaskChecklistItem   `json:"checklist,omitempty"`
+	Comments     int                   `json:"comments"`
+	Attachments  []string              `json:"attachments,omitempty"`
+	Changes      []string              `json:"changes,omitempty"`
+	Source       importer.ExternalTask `json:"-"`
+}
+
+// ImportSkip is an entry of the import report: a task left out, or a part of a task
+// that could not be imported.
+type ImportSkip struct {
+	Row        int    `json:"row,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	Reason     string `json:"reason"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// ImportPlan lists what importing an export into a board does.
+type ImportPlan struct {
+	Format    string           `json:"format"`
+	BoardID   int64            `json:"board_id"`
+	Total     int              `json:"total"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Skipped   int              `json:"skipped"`
+	Applied   bool             `json:"applied"`
+	Items     []ImportPlanItem `json:"items"`
+	Report    []ImportSkip     `json:"report"`
+}
+
+// PlanImport matches the tasks of an export against the board. A task whose
+// external key (system and id, stored in ExternalLink) is already on the board,
+// archived or not, is updated instead of created, so re-running an import is safe.
+// It returns ErrInvalidInput when the status map or default column points outside
+// the board.
+func PlanImport(ctx context.Context, st Store, boardID int64, export *importer.TaskExport, opts ImportOptions) (*ImportPlan, error) {
+	columns, err := st.ListColumns(ctx, boardID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, ErrInvalidInput
+	}
+	onBoard := map[int64]bool{}
+	byName := map[string]int64{}
+	for _, c := range columns {
+		onBoard[c.ID] = true
+		if key := strings.ToLower(strings.TrimSpace(c.Name)); byName[key] == 0 {
+			byName[key] = c.ID
+		}
+	}
+	statusMap := map[string]int64{}
+	for status, columnID := range opts.StatusMap {
+		if !onBoard[columnID] {
+			return nil, ErrInvalidInput
+		}
+		statusMap[strings.ToLower(strings.TrimSpace(status))] = columnID
+	}
+	fallback := columns[0].ID
+	if opts.DefaultColumnID > 0 {
+		if !onBoard[opts.DefaultColumnID] {
+			return nil, ErrInvalidInput
+		}
+		fallback = opts.DefaultColumnID
+	}
+	resolve := opts.ResolveUser
+	if resolve == nil {
+		resolve = func(string) int64 { return 0 }
+	}
+	uploaded := map[string]string{}
+	for _, name := range opts.Attachments {
+		uploaded[strings.ToLower(name)] = name
+	}
+
+	existing, err := st.ListTasks(ctx, TaskFilter{BoardID: boardID, IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+	byLink := map[string]*Task{}
+	for i := range existing {
+		if link := existing[i].ExternalLink; link != "" && byLink[link] == nil {
+			byLink[link] = &existing[i]
+		}
+	}
+
+	plan := &ImportPlan{Format: export.Format, BoardID: boardID, Total: len(export.Tasks), Items: []ImportPlanItem{}, Report: []ImportSkip{}}
+	report := func(row int, id, reason, detail string) {
+		plan.Report = append(plan.Report, ImportSkip{Row: row, ExternalID: id, Reason: reason, Detail: detail})
+	}
+	seen := map[string]bool{}
+	for i, src := range export.Tasks {
+		row := i + 1
+		id := strings.TrimSpace(src.ExternalID)
+		skip := func(reason, detail string) {
+			report(row, id, reason, detail)
+			plan.Skipped++
+		}
+		link := export.ExternalKey(src)
+		switch {
+		case strings.TrimSpace(src.Title) == "":
+			skip(ImportSkipTitleMissing, "")
+			continue
+		case link == "":
+			skip(ImportSkipExternalIDMissing, src.Title)
+			continue
+		case seen[link]:
+			skip(ImportSkipDuplicate, src.Title)
+			continue
+		case src.Closed:
+			skip(ImportSkipArchivedInSource, src.Title)
+			continue
+		}
+		seen[link] = true
+		item := ImportPlanItem{
+			Row:          row,
+			ExternalLink: link,
+			Title:        strings.TrimSpace(src.Title),
+			Status:       src.Status,
+			Comments:     len(src.Comments),
+			Assignees:    []int64{},
+			Tags:         []string{},
+			Source:       src,
+		}
+
+		status := strings.ToLower(strings.TrimSpace(src.Status))
+		switch {
+		case statusMap[status] > 0:
+			item.ColumnID = statusMap[status]
+		case byName[status] > 0:
+			item.ColumnID = byName[status]
+		default:
+			item.ColumnID = fallback
+			if status != "" {
+				report(row, id, ImportSkipStatusUnmapped, src.Status)
+			}
+		}
+
+		labels := src.Labels
+		priority, known := "", false
+		if raw := strings.ToLower(strings.TrimSpace(src.Priority)); raw != "" {
+			if priority, known = importPriorities[raw]; !known {
+				report(row, id, ImportSkipPriorityUnknown, src.Priority)
+			}
+		} else {
+			// Trello has no priorities; a label named like one is taken as the priority.
+			for j, label := range labels {
+				if p, ok := importPriorities[strings.ToLower(label)]; ok {
+					priority, known = p, true
+					labels = append(labels[:j:j], labels[j+1:]...)
+					break
+				}
+			}
+		}
+		if known {
+			item.Priority = priority
+		}
+
+		for _, ref := range src.Assignees {
+			if userID := resolve(ref); userID > 0 {
+				if !slices.Contains(item.Assignees, userID) {
+					item.Assignees = append(item.Assignees, userID)
+				}
+			} else {
+				report(row, id, ImportSkipUserNotFound, ref)
+			}
+		}
+		for _, label := range labels {
+			if label = strings.TrimSpace(label); label != "" && !containsFold(item.Tags, label) {
+				item.Tags = append(item.Tags, label)
+			}
+		}
+		for _, c := range src.Checklist {
+			item.Checklist = append(item.Checklist, TaskChecklistItem{Text: c.Text, Done: c.Done})
+		}
+		for _, a := range src.Attachments {
+			if name, ok := uploaded[strings.ToLower(a.Name)]; ok {
+				item.Attachments = append(item.Attachments, name)
+			}
+		}
+
+		task := byLink[link]
+		if task == nil {
+			item.Action = ImportCreate
+			if item.Priority == "" {
+				item.Priority = PriorityMedium
+			}
+			for _, a := range src.Attachments {
+				if _, ok := uploaded[strings.ToLower(a.Name)]; !ok {
+					report(row, id, ImportSkipAttachmentMissing, a.Name)
+				}
+			}
+			plan.Created++
+			plan.Items = append(plan.Items, item)
+			continue
+		}
+		if task.IsArchived || task.ClosedAt != nil {
+			skip(ImportSkipTaskReadOnly, item.Title)
+			continue
+		}
+		item.TaskID = task.ID
+		item.Changes = importChanges(ctx, st, task, &item)
+		item.Action = ImportUnchanged
+		if len(item.Changes) > 0 {
+			item.Action = ImportUpdate
+			plan.Updated++
+		} else {
+			plan.Unchanged++
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	return plan, nil
+}
+
+// importChanges lists the fields an import changes on an existing task. Tags and
+// assignees are merged with the current ones and empty imported values keep what
+// the task has, so local edits survive a re-run.
+func importChanges(ctx context.Context, st Store, task *Task, item *ImportPlanItem) []string {
+	src := item.Source
+	var changes []string
+	if task.Title != item.Title {
+		changes = append(changes, "title")
+	}
+	if desc := strings.TrimSpace(src.Description); desc != "" && desc != task.Description {
+		changes = append(changes, "description")
+	}
+	if item.Priority == "" {
+		item.Priority = task.Priority
+	}
+	if item.Priority != task.Priority {
+		changes = append(changes, "priority")
+	}
+	if src.DueDate != nil && (task.DueDate == nil || !task.DueDate.Equal(*src.DueDate)) {
+		changes = append(changes, "due_date")
+	}
+	if len(item.Checklist) > 0 && !sameChecklist(task.Checklist, item.Checklist) {
+		changes = append(changes, "checklist")
+	}
+	if item.ColumnID != task.ColumnID {
+		changes = append(changes, "column")
+	}
+	tags, _ := st.ListTaskTagsForTasks(ctx, []int64{task.ID})
+	merged := append([]string{}, tags[task.ID]...)
+	for _, tag := range item.Tags {
+		if !containsFold(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	if len(merged) != len(tags[task.ID]) {
+		changes = append(changes, "tags")
+	}
+	item.Tags = merged
+	assignments, _ := st.ListTaskAssignments(ctx, task.ID)
+	current := make([]int64, 0, len(assignments))
+	for _, a := range assignments {
+		current = append(current, a.UserID)
+	}
+	assignees := append([]int64{}, current...)
+	for _, id := range item.Assignees {
+		if !slices.Contains(assignees, id) {
+			assignees = append(assignees, id)
+		}
+	}
+	if len(assignees) != len(current) {
+		changes = append(changes, "assignees")
+	}
+	item.Assignees = assignees
+	return changes
+}
+
+func sameChecklist(a, b []TaskChecklistItem) bool {
+	return slices.EqualFunc(a, b, func(x, y TaskChecklistItem) bool {
+		return strings.TrimSpace(x.Text) == strings.TrimSpace(y.Text) && x.Done == y.Done
+	})
+}
+
+func containsFold(list []string, val string) bool {
+	return slices.ContainsFunc(list, func(v string) bool { return strings.EqualFold(v, val) })
+}
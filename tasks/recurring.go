@@ -1,12 +1,16 @@
 package tasks
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"berkut-scc/core/schedule"
 )
 
 const (
@@ -16,25 +20,62 @@ const (
 	ScheduleQuarterly  = "quarterly"
 	ScheduleSemiAnnual = "semiannual"
 	ScheduleAnnual     = "annual"
+	ScheduleRRule      = "rrule"
 )
 
+// ErrNoNextRun is returned for rules whose COUNT or UNTIL is exhausted.
+var ErrNoNextRun = errors.New("tasks.recurring.noNextRun")
+
+// ScheduleOptions are accepted by every schedule type: the timezone of time_of_day and
+// the shifting of runs that fall on weekends or holidays of a business calendar.
+type ScheduleOptions struct {
+	Timezone   string `json:"timezone,omitempty"`
+	Shift      string `json:"shift,omitempty"`
+	CalendarID int64  `json:"calendar_id,omitempty"`
+}
+
+type DailyScheduleConfig struct {
+	ScheduleOptions
+}
+
 type WeeklyScheduleConfig struct {
 	Weekdays []int `json:"weekdays"`
+	ScheduleOptions
 }
 
 type MonthlyScheduleConfig struct {
 	Day int `json:"day"`
+	ScheduleOptions
 }
 
 type MonthDayScheduleConfig struct {
 	Month int `json:"month"`
 	Day   int `json:"day"`
+	ScheduleOptions
+}
+
+// RRuleScheduleConfig runs on an RFC 5545 recurrence rule counted from the start date.
+type RRuleScheduleConfig struct {
+	RRule   string   `json:"rrule"`
+	Start   string   `json:"start,omitempty"`
+	ExDates []string `json:"exdates,omitempty"`
+	ScheduleOptions
 }
 
 func NormalizeScheduleConfig(scheduleType string, raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		raw = json.RawMessage(`{}`)
+	}
+	var opts ScheduleOptions
+	_ = json.Unmarshal(raw, &opts)
+	opts, err := normalizeScheduleOptions(opts)
+	if err != nil {
+		return nil, err
+	}
 	switch strings.ToLower(strings.TrimSpace(scheduleType)) {
 	case ScheduleDaily:
-		return json.RawMessage(`{}`), nil
+		out, _ := json.Marshal(DailyScheduleConfig{ScheduleOptions: opts})
+		return out, nil
 	case ScheduleWeekly:
 		var cfg WeeklyScheduleConfig
 		if err := json.Unmarshal(raw, &cfg); err != nil {
@@ -55,6 +96,7 @@ func NormalizeScheduleConfig(scheduleType string, raw json.RawMessage) (json.Raw
 			cfg.Weekdays = append(cfg.Weekdays, d)
 		}
 		sort.Ints(cfg.Weekdays)
+		cfg.ScheduleOptions = opts
 		out, _ := json.Marshal(cfg)
 		return out, nil
 	case ScheduleMonthly:
@@ -65,6 +107,7 @@ func NormalizeScheduleConfig(scheduleType string, raw json.RawMessage) (json.Raw
 		if cfg.Day < 1 || cfg.Day > 31 {
 			return nil, errors.New("tasks.recurring.dayRequired")
 		}
+		cfg.ScheduleOptions = opts
 		out, _ := json.Marshal(cfg)
 		return out, nil
 	case ScheduleQuarterly, ScheduleSemiAnnual, ScheduleAnnual:
@@ -75,6 +118,29 @@ func NormalizeScheduleConfig(scheduleType string, raw json.RawMessage) (json.Raw
 		if cfg.Month < 1 || cfg.Month > 12 || cfg.Day < 1 || cfg.Day > 31 {
 			return nil, errors.New("tasks.recurring.monthDayRequired")
 		}
+		cfg.ScheduleOptions = opts
+		out, _ := json.Marshal(cfg)
+		return out, nil
+	case ScheduleRRule:
+		var cfg RRuleScheduleConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil || strings.TrimSpace(cfg.RRule) == "" {
+			return nil, errors.New("tasks.recurring.rruleRequired")
+		}
+		loc, _ := schedule.LoadLocation(opts.Timezone)
+		cfg.Start = strings.TrimSpace(cfg.Start)
+		if cfg.Start == "" {
+			cfg.Start = time.Now().In(loc).Format(schedule.DateLayout)
+		}
+		if _, err := time.ParseInLocation(schedule.DateLayout, cfg.Start, loc); err != nil {
+			return nil, schedule.ErrInvalidStart
+		}
+		spec, err := schedule.Spec{Start: cfg.Start, RRule: cfg.RRule, ExDates: cfg.ExDates, Timezone: opts.Timezone}.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		cfg.RRule = spec.RRule
+		cfg.ExDates = spec.ExDates
+		cfg.ScheduleOptions = opts
 		out, _ := json.Marshal(cfg)
 		return out, nil
 	default:
@@ -82,128 +148,139 @@ func NormalizeScheduleConfig(scheduleType string, raw json.RawMessage) (json.Raw
 	}
 }
 
-func ComputeNextRunAt(base time.Time, scheduleType string, raw json.RawMessage, timeOfDay string) (time.Time, error) {
+func normalizeScheduleOptions(opts ScheduleOptions) (ScheduleOptions, error) {
+	spec, err := schedule.Spec{Start: "2000-01-01", Timezone: opts.Timezone, Shift: opts.Shift, CalendarID: opts.CalendarID}.Normalize()
+	if err != nil {
+		return opts, err
+	}
+	return ScheduleOptions{Timezone: spec.Timezone, Shift: spec.Shift, CalendarID: spec.CalendarID}, nil
+}
+
+// ScheduleSpec converts a recurring rule schedule to the shared schedule form. The
+// legacy types become RRULEs anchored at time_of_day in the rule timezone; days past
+// the end of a month fall on its last day.
+func ScheduleSpec(scheduleType string, raw json.RawMessage, timeOfDay string) (schedule.Spec, error) {
 	hour, min, err := parseTimeOfDay(timeOfDay)
 	if err != nil {
-		return time.Time{}, err
+		return schedule.Spec{}, err
 	}
-	base = base.UTC()
+	if len(raw) == 0 {
+		raw = json.RawMessage(`{}`)
+	}
+	var opts ScheduleOptions
+	_ = json.Unmarshal(raw, &opts)
+	clock := fmt.Sprintf("T%02d:%02d", hour, min)
+	spec := schedule.Spec{Start: "2000-01-01" + clock, Timezone: opts.Timezone, Shift: opts.Shift, CalendarID: opts.CalendarID}
 	switch strings.ToLower(strings.TrimSpace(scheduleType)) {
 	case ScheduleDaily:
-		return nextDaily(base, hour, min), nil
+		spec.RRule = "FREQ=DAILY"
 	case ScheduleWeekly:
 		var cfg WeeklyScheduleConfig
-		if err := json.Unmarshal(raw, &cfg); err != nil {
-			return time.Time{}, errors.New("tasks.recurring.weekdaysRequired")
+		if err := json.Unmarshal(raw, &cfg); err != nil || len(cfg.Weekdays) == 0 {
+			return spec, errors.New("tasks.recurring.weekdaysRequired")
 		}
-		return nextWeekly(base, cfg.Weekdays, hour, min)
+		days := make([]string, 0, len(cfg.Weekdays))
+		for _, d := range cfg.Weekdays {
+			if d < 0 || d > 6 {
+				return spec, errors.New("tasks.recurring.weekdaysRequired")
+			}
+			days = append(days, strings.ToUpper(time.Weekday(d).String()[:2]))
+		}
+		spec.RRule = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
 	case ScheduleMonthly:
 		var cfg MonthlyScheduleConfig
-		if err := json.Unmarshal(raw, &cfg); err != nil {
-			return time.Time{}, errors.New("tasks.recurring.dayRequired")
-		}
-		return nextMonthly(base, cfg.Day, hour, min), nil
-	case ScheduleQuarterly:
-		return nextByInterval(base, raw, hour, min, 3)
-	case ScheduleSemiAnnual:
-		return nextByInterval(base, raw, hour, min, 6)
-	case ScheduleAnnual:
-		return nextByInterval(base, raw, hour, min, 12)
+		if err := json.Unmarshal(raw, &cfg); err != nil || cfg.Day < 1 || cfg.Day > 31 {
+			return spec, errors.New("tasks.recurring.dayRequired")
+		}
+		spec.RRule = "FREQ=MONTHLY;" + clampedMonthDay(cfg.Day)
+	case ScheduleQuarterly, ScheduleSemiAnnual, ScheduleAnnual:
+		var cfg MonthDayScheduleConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil || cfg.Month < 1 || cfg.Month > 12 || cfg.Day < 1 || cfg.Day > 31 {
+			return spec, errors.New("tasks.recurring.monthDayRequired")
+		}
+		step := map[string]int{ScheduleQuarterly: 3, ScheduleSemiAnnual: 6, ScheduleAnnual: 12}[strings.ToLower(strings.TrimSpace(scheduleType))]
+		var months []string
+		for m := (cfg.Month-1)%step + 1; m <= 12; m += step {
+			months = append(months, strconv.Itoa(m))
+		}
+		spec.RRule = "FREQ=MONTHLY;BYMONTH=" + strings.Join(months, ",") + ";" + clampedMonthDay(cfg.Day)
+	case ScheduleRRule:
+		var cfg RRuleScheduleConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil || strings.TrimSpace(cfg.RRule) == "" {
+			return spec, errors.New("tasks.recurring.rruleRequired")
+		}
+		if strings.TrimSpace(cfg.Start) != "" {
+			spec.Start = strings.TrimSpace(cfg.Start) + clock
+		}
+		spec.RRule = cfg.RRule
+		spec.ExDates = cfg.ExDates
 	default:
-		return time.Time{}, errors.New("tasks.recurring.typeInvalid")
+		return spec, errors.New("tasks.recurring.typeInvalid")
 	}
+	return spec, nil
 }
 
-func parseTimeOfDay(val string) (int, int, error) {
-	clean := strings.TrimSpace(val)
-	if clean == "" {
-		return 0, 0, errors.New("tasks.recurring.timeRequired")
-	}
-	parts := strings.Split(clean, ":")
-	if len(parts) != 2 {
-		return 0, 0, errors.New("tasks.recurring.timeRequired")
+// clampedMonthDay selects the day of month, or the last day of shorter months.
+func clampedMonthDay(day int) string {
+	if day <= 28 {
+		return "BYMONTHDAY=" + strconv.Itoa(day)
 	}
-	var hour, min int
-	if _, err := fmt.Sscanf(clean, "%d:%d", &hour, &min); err != nil {
-		return 0, 0, errors.New("tasks.recurring.timeRequired")
-	}
-	if hour < 0 || hour > 23 || min < 0 || min > 59 {
-		return 0, 0, errors.New("tasks.recurring.timeRequired")
+	days := make([]string, 0, day-27)
+	for d := 28; d <= day; d++ {
+		days = append(days, strconv.Itoa(d))
 	}
-	return hour, min, nil
+	return "BYMONTHDAY=" + strings.Join(days, ",") + ";BYSETPOS=-1"
 }
 
-func nextDaily(base time.Time, hour, min int) time.Time {
-	candidate := time.Date(base.Year(), base.Month(), base.Day(), hour, min, 0, 0, base.Location())
-	if !candidate.After(base) {
-		candidate = candidate.AddDate(0, 0, 1)
-	}
-	return candidate
+func ComputeNextRunAt(base time.Time, scheduleType string, raw json.RawMessage, timeOfDay string) (time.Time, error) {
+	return ComputeNextRunAtWith(context.Background(), nil, base, scheduleType, raw, timeOfDay)
 }
 
-func nextWeekly(base time.Time, weekdays []int, hour, min int) (time.Time, error) {
-	if len(weekdays) == 0 {
-		return time.Time{}, errors.New("tasks.recurring.weekdaysRequired")
-	}
-	allowed := map[time.Weekday]struct{}{}
-	for _, d := range weekdays {
-		if d < 0 || d > 6 {
-			return time.Time{}, errors.New("tasks.recurring.weekdaysRequired")
-		}
-		allowed[time.Weekday(d)] = struct{}{}
+// ComputeNextRunAtWith returns the next run after base in UTC, loading the business
+// calendar of the rule from calendars; without a source only weekends are non-business days.
+func ComputeNextRunAtWith(ctx context.Context, calendars schedule.CalendarSource, base time.Time, scheduleType string, raw json.RawMessage, timeOfDay string) (time.Time, error) {
+	rule, err := CompileSchedule(ctx, calendars, scheduleType, raw, timeOfDay)
+	if err != nil {
+		return time.Time{}, err
 	}
-	for i := 0; i < 7; i++ {
-		date := base.AddDate(0, 0, i)
-		if _, ok := allowed[date.Weekday()]; !ok {
-			continue
-		}
-		candidate := time.Date(date.Year(), date.Month(), date.Day(), hour, min, 0, 0, base.Location())
-		if candidate.After(base) {
-			return candidate, nil
-		}
+	next, ok := rule.Next(base)
+	if !ok {
+		return time.Time{}, ErrNoNextRun
 	}
-	return time.Time{}, errors.New("tasks.recurring.weekdaysRequired")
+	return next.UTC(), nil
 }
 
-func nextMonthly(base time.Time, day, hour, min int) time.Time {
-	if day < 1 {
-		day = 1
+// CompileSchedule builds the engine rule of a recurring schedule. Occurrences are in the
+// schedule timezone.
+func CompileSchedule(ctx context.Context, calendars schedule.CalendarSource, scheduleType string, raw json.RawMessage, timeOfDay string) (*schedule.Rule, error) {
+	spec, err := ScheduleSpec(scheduleType, raw, timeOfDay)
+	if err != nil {
+		return nil, err
 	}
-	candidate := time.Date(base.Year(), base.Month(), clampDay(base.Year(), base.Month(), day), hour, min, 0, 0, base.Location())
-	if !candidate.After(base) {
-		candidate = addMonths(candidate, 1, day, hour, min)
+	var cal *schedule.Calendar
+	if spec.CalendarID > 0 && calendars != nil {
+		if cal, err = calendars.BusinessCalendar(ctx, spec.CalendarID); err != nil {
+			return nil, err
+		}
 	}
-	return candidate
+	return spec.Compile(cal)
 }
 
-func nextByInterval(base time.Time, raw json.RawMessage, hour, min, interval int) (time.Time, error) {
-	var cfg MonthDayScheduleConfig
-	if err := json.Unmarshal(raw, &cfg); err != nil {
-		return time.Time{}, errors.New("tasks.recurring.monthDayRequired")
-	}
-	if cfg.Month < 1 || cfg.Month > 12 || cfg.Day < 1 || cfg.Day > 31 {
-		return time.Time{}, errors.New("tasks.recurring.monthDayRequired")
+func parseTimeOfDay(val string) (int, int, error) {
+	clean := strings.TrimSpace(val)
+	if clean == "" {
+		return 0, 0, errors.New("tasks.recurring.timeRequired")
 	}
-	year := base.Year()
-	candidate := time.Date(year, time.Month(cfg.Month), clampDay(year, time.Month(cfg.Month), cfg.Day), hour, min, 0, 0, base.Location())
-	for !candidate.After(base) {
-		candidate = addMonths(candidate, interval, cfg.Day, hour, min)
+	parts := strings.Split(clean, ":")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("tasks.recurring.timeRequired")
 	}
-	return candidate, nil
-}
-
-func addMonths(base time.Time, months, day, hour, min int) time.Time {
-	next := base.AddDate(0, months, 0)
-	return time.Date(next.Year(), next.Month(), clampDay(next.Year(), next.Month(), day), hour, min, 0, 0, base.Location())
-}
-
-func clampDay(year int, month time.Month, day int) int {
-	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
-	if day > last {
-		return last
+	var hour, min int
+	if _, err := fmt.Sscanf(clean, "%d:%d", &hour, &min); err != nil {
+		return 0, 0, errors.New("tasks.recurring.timeRequired")
 	}
-	if day < 1 {
-		return 1
+	if hour < 0 || hour > 23 || min < 0 || min > 59 {
+		return 0, 0, errors.New("tasks.recurring.timeRequired")
 	}
-	return day
+	return hour, min, nil
 }
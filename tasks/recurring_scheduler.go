@@ -2,11 +2,13 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"berkut-scc/config"
+	"berkut-scc/core/schedule"
 	cstore "berkut-scc/core/store"
 	"berkut-scc/core/utils"
 )
@@ -16,6 +18,8 @@ type RecurringScheduler struct {
 	store  Store
 	audits cstore.AuditStore
 	logger *utils.Logger
+	// calendars resolves the business calendars used to shift runs.
+	calendars schedule.CalendarSource
 
 	mu      sync.Mutex
 	cancel  context.CancelFunc
@@ -34,6 +38,13 @@ func NewRecurringScheduler(cfg config.SchedulerConfig, store Store, audits cstor
 	}
 }
 
+func (s *RecurringScheduler) SetCalendars(calendars schedule.CalendarSource) {
+	if s == nil {
+		return
+	}
+	s.calendars = calendars
+}
+
 func (s *RecurringScheduler) Start() {
 	s.StartWithContext(context.Background())
 }
@@ -130,33 +141,51 @@ func (s *RecurringScheduler) RunOnce(ctx context.Context, now time.Time) error {
 			continue
 		}
 		if !tpl.IsActive {
-			next, err := ComputeNextRunAt(*rule.NextRunAt, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
+			next, err := ComputeNextRunAtWith(ctx, s.calendars, *rule.NextRunAt, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
 			if err == nil {
 				_ = s.store.UpdateRecurringRuleRun(ctx, rule.ID, *rule.NextRunAt, next)
+			} else if errors.Is(err, ErrNoNextRun) {
+				s.finishRule(ctx, rule, *rule.NextRunAt)
 			}
 			continue
 		}
 		scheduledFor := rule.NextRunAt.UTC()
-		nextRun, err := ComputeNextRunAt(scheduledFor, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
-		if err != nil {
+		nextRun, err := ComputeNextRunAtWith(ctx, s.calendars, scheduledFor, rule.ScheduleType, rule.ScheduleConfig, rule.TimeOfDay)
+		if err != nil && !errors.Is(err, ErrNoNextRun) {
 			s.logError("recurring.next_run", err)
 			continue
 		}
+		finished := err != nil
 		task, created, err := s.store.CreateRecurringInstanceTask(ctx, &rule, tpl, scheduledFor)
 		if err != nil {
 			s.logError("recurring.create", err)
 			continue
 		}
-		if err := s.store.UpdateRecurringRuleRun(ctx, rule.ID, scheduledFor, nextRun); err != nil {
+		if finished {
+			s.finishRule(ctx, rule, scheduledFor)
+		} else if err := s.store.UpdateRecurringRuleRun(ctx, rule.ID, scheduledFor, nextRun); err != nil {
 			s.logError("recurring.update", err)
 		}
 		if created && task != nil {
 			Log(s.audits, ctx, "scheduler", AuditTaskRecurringCreate, fmt.Sprintf("%d", task.ID))
+			if err := s.store.EnqueueAutomationEvent(ctx, &AutomationEvent{BoardID: task.BoardID, TaskID: task.ID, Trigger: TriggerTaskCreated}); err != nil {
+				s.logError("recurring.automation", err)
+			}
 		}
 	}
 	return nil
 }
 
+// finishRule deactivates a rule after its last occurrence.
+func (s *RecurringScheduler) finishRule(ctx context.Context, rule TaskRecurringRule, lastRun time.Time) {
+	rule.LastRunAt = &lastRun
+	rule.NextRunAt = nil
+	rule.IsActive = false
+	if err := s.store.UpdateTaskRecurringRule(ctx, &rule); err != nil {
+		s.logError("recurring.finish", err)
+	}
+}
+
 func (s *RecurringScheduler) logError(scope string, err error) {
 	if s.logger == nil || err == nil {
 		return
@@ -3,6 +3,8 @@ package tasks
 import (
 	"context"
 	"time"
+
+	"berkut-scc/core/notify"
 )
 
 type Store interface {
@@ -73,6 +75,33 @@ type Store interface {
 	UpdateRecurringRuleRun(ctx context.Context, ruleID int64, lastRunAt, nextRunAt time.Time) error
 	CreateRecurringInstanceTask(ctx context.Context, rule *TaskRecurringRule, template *TaskTemplate, scheduledFor time.Time) (*Task, bool, error)
 
+	CreateAutomationRule(ctx context.Context, rule *AutomationRule) (int64, error)
+	UpdateAutomationRule(ctx context.Context, rule *AutomationRule) error
+	DeleteAutomationRule(ctx context.Context, id int64) error
+	GetAutomationRule(ctx context.Context, id int64) (*AutomationRule, error)
+	ListAutomationRules(ctx context.Context, filter AutomationRuleFilter) ([]AutomationRule, error)
+	EnqueueAutomationEvent(ctx context.Context, ev *AutomationEvent) error
+	ListPendingAutomationEvents(ctx context.Context, limit int) ([]AutomationEvent, error)
+	MarkAutomationEventProcessed(ctx context.Context, eventID int64, processedAt time.Time) error
+	ListOverdueAutomationTasks(ctx context.Context, ruleID, boardID int64, now time.Time, limit int) ([]Task, error)
+	ClaimAutomationRun(ctx context.Context, run *AutomationRun) (bool, error)
+	FinishAutomationRun(ctx context.Context, runID int64, status, result string) error
+	ListAutomationRuns(ctx context.Context, ruleID int64, limit int) ([]AutomationRun, error)
+	AddWorklog(ctx context.Context, w *Worklog) (int64, error)
+	UpdateWorklog(ctx context.Context, w *Worklog) error
+	DeleteWorklog(ctx context.Context, id int64) error
+	GetWorklog(ctx context.Context, id int64) (*Worklog, error)
+	ListWorklogs(ctx context.Context, filter WorklogFilter) ([]WorklogEntry, error)
+	GetTaskTimeSummary(ctx context.Context, taskID int64) (TaskTimeSummary, error)
+	SetTaskRemainingEstimate(ctx context.Context, taskID int64, remaining *int, userID int64) error
+	GetTaskTimer(ctx context.Context, userID int64) (*TaskTimer, error)
+	StartTaskTimer(ctx context.Context, timer *TaskTimer) (bool, error)
+	StopTaskTimer(ctx context.Context, userID int64, stoppedAt time.Time, comment string) (*Worklog, error)
+	DiscardTaskTimer(ctx context.Context, userID int64) error
+	AddTaskActivity(ctx context.Context, items []TaskActivity) error
+	ListTaskActivity(ctx context.Context, taskID int64, actions []string) ([]TaskActivity, error)
+	ListTaskActivityForTasks(ctx context.Context, taskIDs []int64, actions []string) (map[int64][]TaskActivity, error)
+
 	SetTaskAssignments(ctx context.Context, taskID int64, userIDs []int64, assignedBy int64) error
 	ListTaskAssignments(ctx context.Context, taskID int64) ([]Assignment, error)
 	ListTaskAssignmentsForTasks(ctx context.Context, taskIDs []int64) (map[int64][]Assignment, error)
@@ -94,6 +123,10 @@ type Store interface {
 	ListActiveTaskBlocksForTasks(ctx context.Context, taskIDs []int64) (map[int64][]TaskBlock, error)
 	ListActiveBlocksByBlocker(ctx context.Context, blockerTaskID int64) ([]TaskBlock, error)
 	ResolveTaskBlocksByBlocker(ctx context.Context, blockerTaskID int64, resolvedBy int64) ([]TaskBlock, error)
+	AddTaskDependency(ctx context.Context, dep *TaskDependency) (int64, error)
+	GetTaskDependency(ctx context.Context, id int64) (*TaskDependency, error)
+	DeleteTaskDependency(ctx context.Context, id int64) error
+	ListTaskDependencies(ctx context.Context, taskIDs []int64) ([]TaskDependency, error)
 
 	ListEntityLinks(ctx context.Context, sourceType, sourceID string) ([]Link, error)
 	AddEntityLink(ctx context.Context, link *Link) (int64, error)
@@ -105,7 +138,8 @@ type Store interface {
 }
 
 type Service struct {
-	store Store
+	store    Store
+	notifier *notify.Service
 }
 
 func NewService(store Store) *Service {
@@ -115,3 +149,15 @@ func NewService(store Store) *Service {
 func (s *Service) Store() Store {
 	return s.store
 }
+
+func (s *Service) SetNotifier(n *notify.Service) {
+	s.notifier = n
+}
+
+// Notifier returns the in-app notification service; nil when notifications are not wired.
+func (s *Service) Notifier() *notify.Service {
+	if s == nil {
+		return nil
+	}
+	return s.notifier
+}
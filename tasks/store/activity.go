@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+// AddTaskActivity stores history entries in one transaction; entries without a
+// timestamp get the current time.
+func (s *SQLStore) AddTaskActivity(ctx context.Context, items []tasks.TaskActivity) error {
+	if len(items) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for i := range items {
+			item := &items[i]
+			if item.CreatedAt.IsZero() {
+				item.CreatedAt = now
+			}
+			details := "{}"
+			if len(item.Details) > 0 {
+				details = marshalJSON(item.Details)
+			}
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO task_activity(task_id, actor_id, action, field, old_value, new_value, details_json, created_at)
+				VALUES(?,?,?,?,?,?,?,?)`,
+				item.TaskID, nullableID(item.ActorID), item.Action, item.Field, item.OldValue, item.NewValue, details, item.CreatedAt.UTC())
+			if err != nil {
+				return err
+			}
+			item.ID, _ = res.LastInsertId()
+		}
+		return nil
+	})
+}
+
+// ListTaskActivity returns the history of a task, oldest first. Empty actions
+// return every entry.
+func (s *SQLStore) ListTaskActivity(ctx context.Context, taskID int64, actions []string) ([]tasks.TaskActivity, error) {
+	query := `
+		SELECT id, task_id, actor_id, action, field, old_value, new_value, details_json, created_at
+		FROM task_activity WHERE task_id=?`
+	args := []any{taskID}
+	if len(actions) > 0 {
+		query += " AND action IN (" + placeholders(len(actions)) + ")"
+		for _, a := range actions {
+			args = append(args, a)
+		}
+	}
+	query += " ORDER BY created_at ASC, id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.TaskActivity
+	for rows.Next() {
+		item, err := scanTaskActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+// ListTaskActivityForTasks returns the history of several tasks keyed by task,
+// oldest first.
+func (s *SQLStore) ListTaskActivityForTasks(ctx context.Context, taskIDs []int64, actions []string) (map[int64][]tasks.TaskActivity, error) {
+	out := map[int64][]tasks.TaskActivity{}
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	query := `
+		SELECT id, task_id, actor_id, action, field, old_value, new_value, details_json, created_at
+		FROM task_activity WHERE task_id IN (` + placeholders(len(taskIDs)) + `)`
+	args := toAny(taskIDs)
+	if len(actions) > 0 {
+		query += " AND action IN (" + placeholders(len(actions)) + ")"
+		for _, a := range actions {
+			args = append(args, a)
+		}
+	}
+	query += " ORDER BY created_at ASC, id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := scanTaskActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[item.TaskID] = append(out[item.TaskID], item)
+	}
+	return out, rows.Err()
+}
+
+func scanTaskActivity(rows *sql.Rows) (tasks.TaskActivity, error) {
+	var item tasks.TaskActivity
+	var actor sql.NullInt64
+	var details string
+	if err := rows.Scan(&item.ID, &item.TaskID, &actor, &item.Action, &item.Field, &item.OldValue, &item.NewValue, &details, &item.CreatedAt); err != nil {
+		return item, err
+	}
+	if actor.Valid {
+		id := actor.Int64
+		item.ActorID = &id
+	}
+	unmarshalJSON(details, &item.Details)
+	return item, nil
+}
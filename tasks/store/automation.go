@@ -0,0 +1,256 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+const automationRuleColumns = `id, board_id, name, trigger_type, trigger_column_id, conditions_json, actions_json, is_active, created_by, created_at, updated_at`
+
+func (s *SQLStore) CreateAutomationRule(ctx context.Context, rule *tasks.AutomationRule) (int64, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_automation_rules(board_id, name, trigger_type, trigger_column_id, conditions_json, actions_json, is_active, created_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?)`,
+		rule.BoardID, rule.Name, rule.Trigger, nullableID(rule.TriggerColumnID), marshalJSON(rule.Conditions), marshalJSON(rule.Actions),
+		boolToInt(rule.IsActive), nullableID(rule.CreatedBy), now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	rule.ID = id
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return id, nil
+}
+
+func (s *SQLStore) UpdateAutomationRule(ctx context.Context, rule *tasks.AutomationRule) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE task_automation_rules SET name=?, trigger_type=?, trigger_column_id=?, conditions_json=?, actions_json=?, is_active=?, updated_at=? WHERE id=?`,
+		rule.Name, rule.Trigger, nullableID(rule.TriggerColumnID), marshalJSON(rule.Conditions), marshalJSON(rule.Actions),
+		boolToInt(rule.IsActive), now, rule.ID)
+	if err == nil {
+		rule.UpdatedAt = now
+	}
+	return err
+}
+
+func (s *SQLStore) DeleteAutomationRule(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_automation_rules WHERE id=?`, id)
+	return err
+}
+
+func (s *SQLStore) GetAutomationRule(ctx context.Context, id int64) (*tasks.AutomationRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+automationRuleColumns+` FROM task_automation_rules WHERE id=?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	rule, err := scanAutomationRuleRow(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *SQLStore) ListAutomationRules(ctx context.Context, filter tasks.AutomationRuleFilter) ([]tasks.AutomationRule, error) {
+	clauses := []string{}
+	args := []any{}
+	if filter.BoardID > 0 {
+		clauses = append(clauses, "board_id=?")
+		args = append(args, filter.BoardID)
+	}
+	if filter.Trigger != "" {
+		clauses = append(clauses, "trigger_type=?")
+		args = append(args, filter.Trigger)
+	}
+	if !filter.IncludeInactive {
+		clauses = append(clauses, "is_active=1")
+	}
+	query := `SELECT ` + automationRuleColumns + ` FROM task_automation_rules`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY id ASC"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.AutomationRule
+	for rows.Next() {
+		rule, err := scanAutomationRuleRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rule)
+	}
+	return res, rows.Err()
+}
+
+// EnqueueAutomationEvent queues the event only when the board has an active rule for
+// its trigger, so boards without automation do not grow the queue.
+func (s *SQLStore) EnqueueAutomationEvent(ctx context.Context, ev *tasks.AutomationEvent) error {
+	var exists int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT 1 FROM task_automation_rules WHERE board_id=? AND trigger_type=? AND is_active=1 LIMIT 1`,
+		ev.BoardID, ev.Trigger).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_automation_events(board_id, task_id, trigger_type, ref_id, created_at)
+		VALUES(?,?,?,?,?)`,
+		ev.BoardID, ev.TaskID, ev.Trigger, ev.RefID, now)
+	if err != nil {
+		return err
+	}
+	ev.ID, _ = res.LastInsertId()
+	ev.CreatedAt = now
+	return nil
+}
+
+func (s *SQLStore) ListPendingAutomationEvents(ctx context.Context, limit int) ([]tasks.AutomationEvent, error) {
+	query := `
+		SELECT id, board_id, task_id, trigger_type, ref_id, created_at
+		FROM task_automation_events
+		WHERE processed_at IS NULL
+		ORDER BY id ASC`
+	if limit > 0 {
+		query += " LIMIT " + fmt.Sprintf("%d", limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.AutomationEvent
+	for rows.Next() {
+		var ev tasks.AutomationEvent
+		if err := rows.Scan(&ev.ID, &ev.BoardID, &ev.TaskID, &ev.Trigger, &ev.RefID, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, ev)
+	}
+	return res, rows.Err()
+}
+
+func (s *SQLStore) MarkAutomationEventProcessed(ctx context.Context, eventID int64, processedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE task_automation_events SET processed_at=? WHERE id=?`, processedAt.UTC(), eventID)
+	return err
+}
+
+// ListOverdueAutomationTasks returns open tasks of the board whose due date has passed
+// and that the rule has not handled since that due date.
+func (s *SQLStore) ListOverdueAutomationTasks(ctx context.Context, ruleID, boardID int64, now time.Time, limit int) ([]tasks.Task, error) {
+	query := `
+		SELECT t.id, t.board_id, t.column_id, t.subcolumn_id, t.title, t.description, t.result, t.external_link, t.business_customer, t.size_estimate, t.status, t.priority, t.template_id, t.recurring_rule_id, t.checklist, t.created_by, t.start_date, t.due_date, t.created_at, t.updated_at, t.closed_at, t.is_archived, t.position
+		FROM tasks t
+		WHERE t.board_id=? AND t.is_archived=0 AND t.closed_at IS NULL AND t.due_date IS NOT NULL AND t.due_date<=?
+			AND NOT EXISTS (
+				SELECT 1 FROM task_automation_runs r
+				WHERE r.rule_id=? AND r.task_id=t.id AND r.event_key LIKE 'due:%' AND r.created_at>=t.due_date
+			)
+		ORDER BY t.due_date ASC, t.id ASC`
+	if limit > 0 {
+		query += " LIMIT " + fmt.Sprintf("%d", limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, boardID, now.UTC(), ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.Task
+	for rows.Next() {
+		task, err := s.scanTaskRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, task)
+	}
+	return res, rows.Err()
+}
+
+// ClaimAutomationRun records the run before its actions execute. It returns false when
+// the rule already ran for this task and event key.
+func (s *SQLStore) ClaimAutomationRun(ctx context.Context, run *tasks.AutomationRun) (bool, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_automation_runs(rule_id, task_id, event_key, status, result, created_at)
+		VALUES(?,?,?,?,?,?)`,
+		run.RuleID, run.TaskID, run.EventKey, tasks.AutomationRunRunning, "", now)
+	if err != nil {
+		if isUniqueConstraint(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	run.ID, _ = res.LastInsertId()
+	run.Status = tasks.AutomationRunRunning
+	run.CreatedAt = now
+	return true, nil
+}
+
+func (s *SQLStore) FinishAutomationRun(ctx context.Context, runID int64, status, result string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE task_automation_runs SET status=?, result=? WHERE id=?`, status, result, runID)
+	return err
+}
+
+func (s *SQLStore) ListAutomationRuns(ctx context.Context, ruleID int64, limit int) ([]tasks.AutomationRun, error) {
+	query := `
+		SELECT id, rule_id, task_id, event_key, status, result, created_at
+		FROM task_automation_runs WHERE rule_id=?
+		ORDER BY id DESC`
+	if limit > 0 {
+		query += " LIMIT " + fmt.Sprintf("%d", limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.AutomationRun
+	for rows.Next() {
+		var run tasks.AutomationRun
+		if err := rows.Scan(&run.ID, &run.RuleID, &run.TaskID, &run.EventKey, &run.Status, &run.Result, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, run)
+	}
+	return res, rows.Err()
+}
+
+func scanAutomationRuleRow(rows *sql.Rows) (tasks.AutomationRule, error) {
+	var rule tasks.AutomationRule
+	var triggerColumn sql.NullInt64
+	var conditionsRaw, actionsRaw sql.NullString
+	var createdBy sql.NullInt64
+	var active int
+	if err := rows.Scan(&rule.ID, &rule.BoardID, &rule.Name, &rule.Trigger, &triggerColumn, &conditionsRaw, &actionsRaw, &active, &createdBy, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return rule, err
+	}
+	if triggerColumn.Valid {
+		rule.TriggerColumnID = &triggerColumn.Int64
+	}
+	unmarshalJSON(conditionsRaw.String, &rule.Conditions)
+	unmarshalJSON(actionsRaw.String, &rule.Actions)
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.Int64
+	}
+	rule.IsActive = active == 1
+	return rule, nil
+}
@@ -9,8 +9,13 @@ import (
 	"berkut-scc/tasks"
 )
 
+// AddTaskComment keeps a preset CreatedAt (imported comments carry their original
+// date); otherwise the comment is dated now.
 func (s *SQLStore) AddTaskComment(ctx context.Context, comment *tasks.Comment) (int64, error) {
 	now := time.Now().UTC()
+	if !comment.CreatedAt.IsZero() {
+		now = comment.CreatedAt.UTC()
+	}
 	attachments := "[]"
 	if comment.Attachments != nil {
 		if raw, err := json.Marshal(comment.Attachments); err == nil {
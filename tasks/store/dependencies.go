@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+// AddTaskDependency stores a dependency; it returns tasks.ErrConflict when the two
+// tasks are already linked.
+func (s *SQLStore) AddTaskDependency(ctx context.Context, dep *tasks.TaskDependency) (int64, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_dependencies(predecessor_id, successor_id, dep_type, lag_days, created_by, created_at)
+		VALUES(?,?,?,?,?,?)`,
+		dep.PredecessorID, dep.SuccessorID, dep.Type, dep.LagDays, nullableID(dep.CreatedBy), now)
+	if err != nil {
+		if isUniqueConstraint(err) {
+			return 0, tasks.ErrConflict
+		}
+		return 0, err
+	}
+	dep.ID, _ = res.LastInsertId()
+	dep.CreatedAt = now
+	return dep.ID, nil
+}
+
+func (s *SQLStore) GetTaskDependency(ctx context.Context, id int64) (*tasks.TaskDependency, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, predecessor_id, successor_id, dep_type, lag_days, created_by, created_at
+		FROM task_dependencies WHERE id=?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	dep, err := scanTaskDependency(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+func (s *SQLStore) DeleteTaskDependency(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM task_dependencies WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return tasks.ErrNotFound
+	}
+	return nil
+}
+
+// ListTaskDependencies returns the dependencies that have any of the tasks as
+// predecessor or successor.
+func (s *SQLStore) ListTaskDependencies(ctx context.Context, taskIDs []int64) ([]tasks.TaskDependency, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+	args := append(toAny(taskIDs), toAny(taskIDs)...)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, predecessor_id, successor_id, dep_type, lag_days, created_by, created_at
+		FROM task_dependencies
+		WHERE predecessor_id IN (`+placeholders(len(taskIDs))+`) OR successor_id IN (`+placeholders(len(taskIDs))+`)
+		ORDER BY id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.TaskDependency
+	for rows.Next() {
+		dep, err := scanTaskDependency(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, dep)
+	}
+	return res, rows.Err()
+}
+
+func scanTaskDependency(rows *sql.Rows) (tasks.TaskDependency, error) {
+	var dep tasks.TaskDependency
+	var createdBy sql.NullInt64
+	if err := rows.Scan(&dep.ID, &dep.PredecessorID, &dep.SuccessorID, &dep.Type, &dep.LagDays, &createdBy, &dep.CreatedAt); err != nil {
+		return dep, err
+	}
+	if createdBy.Valid {
+		dep.CreatedBy = &createdBy.Int64
+	}
+	return dep, nil
+}
@@ -49,11 +49,11 @@ func (s *SQLStore) createTaskTx(ctx context.Context, tx *sql.Tx, task *tasks.Tas
 	}
 	now := time.Now().UTC()
 	res, err := tx.ExecContext(ctx, `
-		INSERT INTO tasks(board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, due_date, created_at, updated_at, closed_at, is_archived, position)
-		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		INSERT INTO tasks(board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, start_date, due_date, created_at, updated_at, closed_at, is_archived, position)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
 		task.BoardID, task.ColumnID, nullableID(task.SubColumnID), task.Title, task.Description, task.Result, task.ExternalLink, task.BusinessCustomer, nullableInt(task.SizeEstimate), task.Status, task.Priority,
 		nullableID(task.TemplateID), nullableID(task.RecurringRuleID), marshalJSON(task.Checklist),
-		nullableID(task.CreatedBy), nullableTime(task.DueDate), now, now, nullableTime(task.ClosedAt), boolToInt(task.IsArchived), task.Position)
+		nullableID(task.CreatedBy), nullableTime(task.StartDate), nullableTime(task.DueDate), now, now, nullableTime(task.ClosedAt), boolToInt(task.IsArchived), task.Position)
 	if err != nil {
 		return 0, err
 	}
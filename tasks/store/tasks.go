@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	cstore "berkut-scc/core/store"
 	"berkut-scc/tasks"
 )
 
@@ -29,8 +30,8 @@ func (s *SQLStore) CreateTask(ctx context.Context, task *tasks.Task, assignments
 
 func (s *SQLStore) UpdateTask(ctx context.Context, task *tasks.Task) error {
 	_, err := s.db.ExecContext(ctx, `
-		UPDATE tasks SET title=?, description=?, result=?, external_link=?, business_customer=?, size_estimate=?, priority=?, checklist=?, due_date=?, updated_at=? WHERE id=?`,
-		task.Title, task.Description, task.Result, task.ExternalLink, task.BusinessCustomer, nullableInt(task.SizeEstimate), task.Priority, marshalJSON(task.Checklist), nullableTime(task.DueDate), time.Now().UTC(), task.ID)
+		UPDATE tasks SET title=?, description=?, result=?, external_link=?, business_customer=?, size_estimate=?, priority=?, checklist=?, start_date=?, due_date=?, updated_at=? WHERE id=?`,
+		task.Title, task.Description, task.Result, task.ExternalLink, task.BusinessCustomer, nullableInt(task.SizeEstimate), task.Priority, marshalJSON(task.Checklist), nullableTime(task.StartDate), nullableTime(task.DueDate), time.Now().UTC(), task.ID)
 	return err
 }
 
@@ -439,7 +440,7 @@ func (s *SQLStore) ListArchivedTasks(ctx context.Context, filter tasks.TaskFilte
 	selectPrefix := `
 		SELECT
 			t.id, t.board_id, t.column_id, t.subcolumn_id, t.title, t.description, t.result, t.external_link, t.business_customer, t.size_estimate,
-			t.status, t.priority, t.template_id, t.recurring_rule_id, t.checklist, t.created_by, t.due_date, t.created_at, t.updated_at, t.closed_at,
+			t.status, t.priority, t.template_id, t.recurring_rule_id, t.checklist, t.created_by, t.start_date, t.due_date, t.created_at, t.updated_at, t.closed_at,
 			t.is_archived, t.position,
 			a.archived_at, a.archived_by, a.archived_board_id, a.archived_column_id, a.archived_subcolumn_id, a.original_position, a.restored_at
 	`
@@ -495,7 +496,7 @@ func (s *SQLStore) DeleteTask(ctx context.Context, taskID int64) error {
 
 func (s *SQLStore) GetTask(ctx context.Context, taskID int64) (*tasks.Task, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, due_date, created_at, updated_at, closed_at, is_archived, position
+		SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, start_date, due_date, created_at, updated_at, closed_at, is_archived, position
 		FROM tasks WHERE id=?`, taskID)
 	return s.scanTask(row)
 }
@@ -504,10 +505,10 @@ func (s *SQLStore) ListTasks(ctx context.Context, filter tasks.TaskFilter) ([]ta
 	clauses := []string{}
 	args := []any{}
 	base := "tasks"
-	selectPrefix := "SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, due_date, created_at, updated_at, closed_at, is_archived, position"
+	selectPrefix := "SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, start_date, due_date, created_at, updated_at, closed_at, is_archived, position"
 	if filter.SpaceID > 0 {
 		base = "tasks t JOIN task_boards b ON t.board_id=b.id"
-		selectPrefix = "SELECT t.id, t.board_id, t.column_id, t.subcolumn_id, t.title, t.description, t.result, t.external_link, t.business_customer, t.size_estimate, t.status, t.priority, t.template_id, t.recurring_rule_id, t.checklist, t.created_by, t.due_date, t.created_at, t.updated_at, t.closed_at, t.is_archived, t.position"
+		selectPrefix = "SELECT t.id, t.board_id, t.column_id, t.subcolumn_id, t.title, t.description, t.result, t.external_link, t.business_customer, t.size_estimate, t.status, t.priority, t.template_id, t.recurring_rule_id, t.checklist, t.created_by, t.start_date, t.due_date, t.created_at, t.updated_at, t.closed_at, t.is_archived, t.position"
 		clauses = append(clauses, "b.space_id=?")
 		args = append(args, filter.SpaceID)
 	}
@@ -540,6 +541,21 @@ func (s *SQLStore) ListTasks(ctx context.Context, filter tasks.TaskFilter) ([]ta
 		clauses = append(clauses, "id IN (SELECT task_id FROM task_assignments WHERE user_id=?)")
 		args = append(args, filter.MineUserID)
 	}
+	idColumn := "id"
+	if filter.SpaceID > 0 {
+		idColumn = "t.id"
+	}
+	for _, cond := range filter.CustomFields {
+		clause, condArgs := cstore.CustomFieldClause(cstore.CustomFieldEntityTask, idColumn, cond)
+		clauses = append(clauses, clause)
+		args = append(args, condArgs...)
+	}
+	if filter.Query != nil {
+		// The query refers to unaliased task columns, so it is applied by id when
+		// tasks are joined with boards.
+		clauses = append(clauses, idColumn+" IN (SELECT id FROM tasks WHERE "+filter.Query.SQL+")")
+		args = append(args, filter.Query.Args...)
+	}
 	query := fmt.Sprintf("%s FROM %s", selectPrefix, base)
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
@@ -598,12 +614,13 @@ func (s *SQLStore) scanTask(row *sql.Row) (*tasks.Task, error) {
 	var templateID sql.NullInt64
 	var recurringID sql.NullInt64
 	var checklistRaw sql.NullString
+	var start sql.NullTime
 	var due sql.NullTime
 	var closed sql.NullTime
 	var archived int
 	var sizeEstimate sql.NullInt64
 	var subcolumnID sql.NullInt64
-	if err := row.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
+	if err := row.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &start, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -628,6 +645,9 @@ func (s *SQLStore) scanTask(row *sql.Row) (*tasks.Task, error) {
 	if createdBy.Valid {
 		t.CreatedBy = &createdBy.Int64
 	}
+	if start.Valid {
+		t.StartDate = &start.Time
+	}
 	if due.Valid {
 		t.DueDate = &due.Time
 	}
@@ -644,12 +664,13 @@ func (s *SQLStore) scanTaskRow(rows *sql.Rows) (tasks.Task, error) {
 	var templateID sql.NullInt64
 	var recurringID sql.NullInt64
 	var checklistRaw sql.NullString
+	var start sql.NullTime
 	var due sql.NullTime
 	var closed sql.NullTime
 	var archived int
 	var sizeEstimate sql.NullInt64
 	var subcolumnID sql.NullInt64
-	if err := rows.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
+	if err := rows.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &start, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
 		return t, err
 	}
 	if templateID.Valid {
@@ -671,6 +692,9 @@ func (s *SQLStore) scanTaskRow(rows *sql.Rows) (tasks.Task, error) {
 	if createdBy.Valid {
 		t.CreatedBy = &createdBy.Int64
 	}
+	if start.Valid {
+		t.StartDate = &start.Time
+	}
 	if due.Valid {
 		t.DueDate = &due.Time
 	}
@@ -683,19 +707,20 @@ func (s *SQLStore) scanTaskRow(rows *sql.Rows) (tasks.Task, error) {
 
 func (s *SQLStore) getTaskTx(ctx context.Context, tx *sql.Tx, taskID int64) (*tasks.Task, error) {
 	row := tx.QueryRowContext(ctx, `
-		SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, due_date, created_at, updated_at, closed_at, is_archived, position
+		SELECT id, board_id, column_id, subcolumn_id, title, description, result, external_link, business_customer, size_estimate, status, priority, template_id, recurring_rule_id, checklist, created_by, start_date, due_date, created_at, updated_at, closed_at, is_archived, position
 		FROM tasks WHERE id=?`, taskID)
 	var t tasks.Task
 	var createdBy sql.NullInt64
 	var templateID sql.NullInt64
 	var recurringID sql.NullInt64
 	var checklistRaw sql.NullString
+	var start sql.NullTime
 	var due sql.NullTime
 	var closed sql.NullTime
 	var archived int
 	var sizeEstimate sql.NullInt64
 	var subcolumnID sql.NullInt64
-	if err := row.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
+	if err := row.Scan(&t.ID, &t.BoardID, &t.ColumnID, &subcolumnID, &t.Title, &t.Description, &t.Result, &t.ExternalLink, &t.BusinessCustomer, &sizeEstimate, &t.Status, &t.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &start, &due, &t.CreatedAt, &t.UpdatedAt, &closed, &archived, &t.Position); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -720,6 +745,9 @@ func (s *SQLStore) getTaskTx(ctx context.Context, tx *sql.Tx, taskID int64) (*ta
 	if createdBy.Valid {
 		t.CreatedBy = &createdBy.Int64
 	}
+	if start.Valid {
+		t.StartDate = &start.Time
+	}
 	if due.Valid {
 		t.DueDate = &due.Time
 	}
@@ -771,6 +799,7 @@ func scanArchivedTaskRow(rows *sql.Rows) (tasks.TaskArchiveEntry, error) {
 	var templateID sql.NullInt64
 	var recurringID sql.NullInt64
 	var checklistRaw sql.NullString
+	var start sql.NullTime
 	var due sql.NullTime
 	var closed sql.NullTime
 	var archived int
@@ -781,7 +810,7 @@ func scanArchivedTaskRow(rows *sql.Rows) (tasks.TaskArchiveEntry, error) {
 	var restoredAt sql.NullTime
 	if err := rows.Scan(
 		&entry.ID, &entry.BoardID, &entry.ColumnID, &subcolumnID, &entry.Title, &entry.Description, &entry.Result, &entry.ExternalLink, &entry.BusinessCustomer, &sizeEstimate,
-		&entry.Status, &entry.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &due, &entry.CreatedAt, &entry.UpdatedAt, &closed,
+		&entry.Status, &entry.Priority, &templateID, &recurringID, &checklistRaw, &createdBy, &start, &due, &entry.CreatedAt, &entry.UpdatedAt, &closed,
 		&archived, &entry.Position,
 		&entry.ArchivedAt, &archivedBy, &entry.ArchivedBoardID, &entry.ArchivedColumnID, &archivedSubColumnID, &entry.OriginalPosition, &restoredAt,
 	); err != nil {
@@ -806,6 +835,9 @@ func scanArchivedTaskRow(rows *sql.Rows) (tasks.TaskArchiveEntry, error) {
 	if createdBy.Valid {
 		entry.CreatedBy = &createdBy.Int64
 	}
+	if start.Valid {
+		entry.StartDate = &start.Time
+	}
 	if due.Valid {
 		entry.DueDate = &due.Time
 	}
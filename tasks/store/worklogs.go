@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+func (s *SQLStore) AddWorklog(ctx context.Context, w *tasks.Worklog) (int64, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_worklogs(task_id, user_id, work_date, minutes, comment, source, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?)`,
+		w.TaskID, w.UserID, w.WorkDate.UTC(), w.Minutes, w.Comment, w.Source, now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	w.ID = id
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	return id, nil
+}
+
+func (s *SQLStore) UpdateWorklog(ctx context.Context, w *tasks.Worklog) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE task_worklogs SET work_date=?, minutes=?, comment=?, updated_at=? WHERE id=?`,
+		w.WorkDate.UTC(), w.Minutes, w.Comment, now, w.ID)
+	if err == nil {
+		w.UpdatedAt = now
+	}
+	return err
+}
+
+func (s *SQLStore) DeleteWorklog(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_worklogs WHERE id=?`, id)
+	return err
+}
+
+func (s *SQLStore) GetWorklog(ctx context.Context, id int64) (*tasks.Worklog, error) {
+	var w tasks.Worklog
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, task_id, user_id, work_date, minutes, comment, source, created_at, updated_at
+		FROM task_worklogs WHERE id=?`, id).
+		Scan(&w.ID, &w.TaskID, &w.UserID, &w.WorkDate, &w.Minutes, &w.Comment, &w.Source, &w.CreatedAt, &w.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWorklogs returns worklogs with their task, board and space, newest work first.
+// From and To bound the work date inclusively.
+func (s *SQLStore) ListWorklogs(ctx context.Context, filter tasks.WorklogFilter) ([]tasks.WorklogEntry, error) {
+	clauses := []string{}
+	args := []any{}
+	if filter.TaskID > 0 {
+		clauses = append(clauses, "w.task_id=?")
+		args = append(args, filter.TaskID)
+	}
+	if filter.UserID > 0 {
+		clauses = append(clauses, "w.user_id=?")
+		args = append(args, filter.UserID)
+	}
+	if filter.BoardID > 0 {
+		clauses = append(clauses, "t.board_id=?")
+		args = append(args, filter.BoardID)
+	}
+	if filter.SpaceID > 0 {
+		clauses = append(clauses, "b.space_id=?")
+		args = append(args, filter.SpaceID)
+	}
+	if v := strings.TrimSpace(filter.Customer); v != "" {
+		clauses = append(clauses, "LOWER(t.business_customer)=?")
+		args = append(args, strings.ToLower(v))
+	}
+	if filter.From != nil {
+		clauses = append(clauses, "w.work_date>=?")
+		args = append(args, tasks.WorkDay(*filter.From))
+	}
+	if filter.To != nil {
+		clauses = append(clauses, "w.work_date<=?")
+		args = append(args, tasks.WorkDay(*filter.To))
+	}
+	query := `
+		SELECT w.id, w.task_id, w.user_id, w.work_date, w.minutes, w.comment, w.source, w.created_at, w.updated_at,
+			t.title, t.board_id, b.name, b.space_id, COALESCE(sp.name, ''), COALESCE(t.business_customer, '')
+		FROM task_worklogs w
+		JOIN tasks t ON t.id=w.task_id
+		JOIN task_boards b ON b.id=t.board_id
+		LEFT JOIN task_spaces sp ON sp.id=b.space_id`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY w.work_date DESC, w.id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + fmt.Sprintf("%d", filter.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []tasks.WorklogEntry
+	for rows.Next() {
+		var e tasks.WorklogEntry
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.UserID, &e.WorkDate, &e.Minutes, &e.Comment, &e.Source, &e.CreatedAt, &e.UpdatedAt,
+			&e.TaskTitle, &e.BoardID, &e.BoardName, &e.SpaceID, &e.SpaceName, &e.BusinessCustomer); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func (s *SQLStore) GetTaskTimeSummary(ctx context.Context, taskID int64) (tasks.TaskTimeSummary, error) {
+	var summary tasks.TaskTimeSummary
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(minutes), 0) FROM task_worklogs WHERE task_id=?`, taskID).Scan(&summary.SpentMinutes); err != nil {
+		return summary, err
+	}
+	var remaining int
+	err := s.db.QueryRowContext(ctx, `SELECT remaining_minutes FROM task_time_estimates WHERE task_id=?`, taskID).Scan(&remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return summary, nil
+	}
+	if err != nil {
+		return summary, err
+	}
+	summary.RemainingMinutes = &remaining
+	return summary, nil
+}
+
+// SetTaskRemainingEstimate stores the remaining estimate; nil clears it.
+func (s *SQLStore) SetTaskRemainingEstimate(ctx context.Context, taskID int64, remaining *int, userID int64) error {
+	if remaining == nil {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM task_time_estimates WHERE task_id=?`, taskID)
+		return err
+	}
+	var updatedBy *int64
+	if userID > 0 {
+		updatedBy = &userID
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_time_estimates(task_id, remaining_minutes, updated_by, updated_at)
+		VALUES(?,?,?,?)
+		ON CONFLICT(task_id)
+		DO UPDATE SET remaining_minutes=excluded.remaining_minutes, updated_by=excluded.updated_by, updated_at=excluded.updated_at`,
+		taskID, *remaining, nullableID(updatedBy), time.Now().UTC())
+	return err
+}
+
+func (s *SQLStore) GetTaskTimer(ctx context.Context, userID int64) (*tasks.TaskTimer, error) {
+	var timer tasks.TaskTimer
+	err := s.db.QueryRowContext(ctx, `SELECT user_id, task_id, started_at FROM task_timers WHERE user_id=?`, userID).
+		Scan(&timer.UserID, &timer.TaskID, &timer.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// StartTaskTimer returns false when the user already runs a timer.
+func (s *SQLStore) StartTaskTimer(ctx context.Context, timer *tasks.TaskTimer) (bool, error) {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO task_timers(user_id, task_id, started_at) VALUES(?,?,?)`,
+		timer.UserID, timer.TaskID, timer.StartedAt.UTC())
+	if err != nil {
+		if isUniqueConstraint(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// StopTaskTimer removes the running timer of the user and turns it into a worklog
+// in one transaction. It returns nil when no timer was running.
+func (s *SQLStore) StopTaskTimer(ctx context.Context, userID int64, stoppedAt time.Time, comment string) (*tasks.Worklog, error) {
+	var logged *tasks.Worklog
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var timer tasks.TaskTimer
+		err := tx.QueryRowContext(ctx, `SELECT user_id, task_id, started_at FROM task_timers WHERE user_id=?`, userID).
+			Scan(&timer.UserID, &timer.TaskID, &timer.StartedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_timers WHERE user_id=?`, userID); err != nil {
+			return err
+		}
+		minutes := tasks.TimerMinutes(timer.StartedAt, stoppedAt)
+		if minutes <= 0 {
+			return nil
+		}
+		w := &tasks.Worklog{
+			TaskID:   timer.TaskID,
+			UserID:   userID,
+			WorkDate: tasks.WorkDay(timer.StartedAt),
+			Minutes:  minutes,
+			Comment:  strings.TrimSpace(comment),
+			Source:   tasks.WorklogSourceTimer,
+		}
+		now := time.Now().UTC()
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO task_worklogs(task_id, user_id, work_date, minutes, comment, source, created_at, updated_at)
+			VALUES(?,?,?,?,?,?,?,?)`,
+			w.TaskID, w.UserID, w.WorkDate, w.Minutes, w.Comment, w.Source, now, now)
+		if err != nil {
+			return err
+		}
+		w.ID, _ = res.LastInsertId()
+		w.CreatedAt = now
+		w.UpdatedAt = now
+		logged = w
+		return nil
+	})
+	return logged, err
+}
+
+func (s *SQLStore) DiscardTaskTimer(ctx context.Context, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_timers WHERE user_id=?`, userID)
+	return err
+}
@@ -0,0 +1,531 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Dependency types. A finish-to-start successor starts the day after its
+// predecessor finishes, a start-to-start one no earlier than its predecessor
+// starts; the lag moves that date by whole days.
+const (
+	DependencyFinishStart = "fs"
+	DependencyStartStart  = "ss"
+
+	// MaxDependencyLagDays bounds the lag of a dependency in either direction.
+	MaxDependencyLagDays = 365
+)
+
+// TaskDependency orders two tasks on the timeline. FromBlock marks the
+// finish-to-start dependency implied by an active task block; it is not stored.
+type TaskDependency struct {
+	ID            int64     `json:"id"`
+	PredecessorID int64     `json:"predecessor_id"`
+	SuccessorID   int64     `json:"successor_id"`
+	Type          string    `json:"type"`
+	LagDays       int       `json:"lag_days"`
+	CreatedBy     *int64    `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	FromBlock     bool      `json:"from_block,omitempty"`
+}
+
+// TimelineTask is a task with its computed schedule. Dates are days; finishes are
+// inclusive. Tasks without a start or due date are placed at today and are not
+// Scheduled. Late is set when the earliest finish falls after the due date.
+type TimelineTask struct {
+	TaskID         int64      `json:"task_id"`
+	BoardID        int64      `json:"board_id"`
+	ColumnID       int64      `json:"column_id"`
+	Title          string     `json:"title"`
+	Priority       string     `json:"priority"`
+	StartDate      *time.Time `json:"start_date,omitempty"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	Done           bool       `json:"done"`
+	Scheduled      bool       `json:"scheduled"`
+	DurationDays   int        `json:"duration_days"`
+	EarliestStart  time.Time  `json:"earliest_start"`
+	EarliestFinish time.Time  `json:"earliest_finish"`
+	LatestStart    time.Time  `json:"latest_start"`
+	LatestFinish   time.Time  `json:"latest_finish"`
+	SlackDays      int        `json:"slack_days"`
+	Critical       bool       `json:"critical"`
+	Late           bool       `json:"late"`
+	InCycle        bool       `json:"in_cycle,omitempty"`
+}
+
+// Timeline is the schedule of a board or space. CriticalPath lists the tasks
+// that drive the finish date, first to last. Cycles lists groups of tasks whose
+// dependencies form a loop; dependencies inside a group are ignored.
+type Timeline struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Tasks        []TimelineTask   `json:"tasks"`
+	Dependencies []TaskDependency `json:"dependencies"`
+	CriticalPath []int64          `json:"critical_path"`
+	Cycles       [][]int64        `json:"cycles"`
+}
+
+func ValidDependencyType(depType string) bool {
+	return depType == DependencyFinishStart || depType == DependencyStartStart
+}
+
+// LoadTimeline schedules the open and closed, not archived, tasks of the boards.
+// Dependencies to tasks outside the boards are left out.
+func LoadTimeline(ctx context.Context, st Store, boardIDs []int64, now time.Time) (*Timeline, error) {
+	var list []Task
+	done := map[int64]bool{}
+	for _, boardID := range boardIDs {
+		columns, err := st.ListColumns(ctx, boardID, true)
+		if err != nil {
+			return nil, err
+		}
+		final := map[int64]bool{}
+		for _, c := range columns {
+			final[c.ID] = c.IsFinal
+		}
+		boardTasks, err := st.ListTasks(ctx, TaskFilter{BoardID: boardID})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range boardTasks {
+			done[t.ID] = t.ClosedAt != nil || final[t.ColumnID]
+		}
+		list = append(list, boardTasks...)
+	}
+	ids := make([]int64, 0, len(list))
+	for _, t := range list {
+		ids = append(ids, t.ID)
+	}
+	deps, err := timelineDependencies(ctx, st, ids)
+	if err != nil {
+		return nil, err
+	}
+	return BuildTimeline(list, done, deps, now), nil
+}
+
+// timelineDependencies returns the stored dependencies among the tasks and a
+// finish-to-start dependency per active task block that has no stored one.
+func timelineDependencies(ctx context.Context, st Store, ids []int64) ([]TaskDependency, error) {
+	inScope := map[int64]bool{}
+	for _, id := range ids {
+		inScope[id] = true
+	}
+	stored, err := st.ListTaskDependencies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	deps := []TaskDependency{}
+	pairs := map[[2]int64]bool{}
+	for _, d := range stored {
+		if inScope[d.PredecessorID] && inScope[d.SuccessorID] {
+			deps = append(deps, d)
+			pairs[[2]int64{d.PredecessorID, d.SuccessorID}] = true
+		}
+	}
+	blocks, err := st.ListActiveTaskBlocksForTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		for _, b := range blocks[id] {
+			if b.BlockType != BlockTypeTask || b.BlockerTaskID == nil || !inScope[*b.BlockerTaskID] {
+				continue
+			}
+			pair := [2]int64{*b.BlockerTaskID, b.TaskID}
+			if pairs[pair] {
+				continue
+			}
+			pairs[pair] = true
+			deps = append(deps, TaskDependency{PredecessorID: pair[0], SuccessorID: pair[1], Type: DependencyFinishStart, CreatedAt: b.CreatedAt, FromBlock: true})
+		}
+	}
+	return deps, nil
+}
+
+// BuildTimeline computes earliest dates with a forward pass over the dependencies
+// and latest dates with a backward pass from the finish of the whole timeline.
+// A task never starts before its own start date: dependencies can only push it
+// later. Tasks with no slack are critical.
+func BuildTimeline(list []Task, done map[int64]bool, deps []TaskDependency, now time.Time) *Timeline {
+	today := dayNumber(now)
+	tl := &Timeline{Tasks: []TimelineTask{}, Dependencies: []TaskDependency{}, CriticalPath: []int64{}, Cycles: [][]int64{}}
+	index := map[int64]int{}
+	planned := make([]int, len(list))
+	duration := make([]int, len(list))
+	for i, t := range list {
+		index[t.ID] = i
+		start, finish, ok := taskDays(&t)
+		if !ok {
+			start, finish = today, today
+		}
+		planned[i] = start
+		duration[i] = finish - start + 1
+		tl.Tasks = append(tl.Tasks, TimelineTask{
+			TaskID:       t.ID,
+			BoardID:      t.BoardID,
+			ColumnID:     t.ColumnID,
+			Title:        t.Title,
+			Priority:     t.Priority,
+			StartDate:    t.StartDate,
+			DueDate:      t.DueDate,
+			Done:         done[t.ID],
+			Scheduled:    ok,
+			DurationDays: duration[i],
+		})
+	}
+	if len(list) == 0 {
+		tl.From, tl.To = dayTime(today), dayTime(today)
+		return tl
+	}
+
+	out := make([][]TaskDependency, len(list))
+	in := make([][]TaskDependency, len(list))
+	for _, d := range deps {
+		p, okP := index[d.PredecessorID]
+		s, okS := index[d.SuccessorID]
+		if !okP || !okS || p == s {
+			continue
+		}
+		tl.Dependencies = append(tl.Dependencies, d)
+		out[p] = append(out[p], d)
+	}
+	component := make([]int, len(list))
+	for _, group := range stronglyConnected(len(list), func(i int) []int {
+		next := make([]int, 0, len(out[i]))
+		for _, d := range out[i] {
+			next = append(next, index[d.SuccessorID])
+		}
+		return next
+	}) {
+		ids := make([]int64, 0, len(group))
+		for _, i := range group {
+			component[i] = len(tl.Cycles) + 1
+			tl.Tasks[i].InCycle = true
+			ids = append(ids, list[i].ID)
+		}
+		sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+		tl.Cycles = append(tl.Cycles, ids)
+	}
+	for p := range out {
+		kept := out[p][:0]
+		for _, d := range out[p] {
+			s := index[d.SuccessorID]
+			if component[p] != 0 && component[p] == component[s] {
+				continue
+			}
+			kept = append(kept, d)
+			in[s] = append(in[s], d)
+		}
+		out[p] = kept
+	}
+
+	order := topologicalOrder(len(list), func(i int) []int {
+		next := make([]int, 0, len(out[i]))
+		for _, d := range out[i] {
+			next = append(next, index[d.SuccessorID])
+		}
+		return next
+	})
+	es := make([]int, len(list))
+	ef := make([]int, len(list))
+	for _, i := range order {
+		es[i] = planned[i]
+		for _, d := range in[i] {
+			if req := requiredStart(d, es[index[d.PredecessorID]], ef[index[d.PredecessorID]]); req > es[i] {
+				es[i] = req
+			}
+		}
+		ef[i] = es[i] + duration[i] - 1
+	}
+	finish, first := ef[0], es[0]
+	for i := range list {
+		finish = max(finish, ef[i])
+		first = min(first, es[i])
+	}
+	ls := make([]int, len(list))
+	lf := make([]int, len(list))
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		lf[i] = finish
+		for _, d := range out[i] {
+			s := index[d.SuccessorID]
+			limit := ls[s] - 1 - d.LagDays
+			if d.Type == DependencyStartStart {
+				limit = ls[s] - d.LagDays + duration[i] - 1
+			}
+			lf[i] = min(lf[i], limit)
+		}
+		ls[i] = lf[i] - duration[i] + 1
+	}
+
+	for i := range list {
+		item := &tl.Tasks[i]
+		item.EarliestStart = dayTime(es[i])
+		item.EarliestFinish = dayTime(ef[i])
+		item.LatestStart = dayTime(ls[i])
+		item.LatestFinish = dayTime(lf[i])
+		item.SlackDays = ls[i] - es[i]
+		item.Critical = item.SlackDays == 0
+		item.Late = list[i].DueDate != nil && ef[i] > dayNumber(*list[i].DueDate)
+	}
+	tl.From, tl.To = dayTime(first), dayTime(finish)
+
+	// Walk back from the task that finishes last through the predecessors that
+	// set each start date.
+	end := -1
+	for _, i := range order {
+		if ef[i] == finish && tl.Tasks[i].Critical {
+			end = i
+		}
+	}
+	for cur := end; cur >= 0; {
+		tl.CriticalPath = append([]int64{list[cur].ID}, tl.CriticalPath...)
+		next := -1
+		for _, d := range in[cur] {
+			p := index[d.PredecessorID]
+			if tl.Tasks[p].Critical && requiredStart(d, es[p], ef[p]) == es[cur] {
+				next = p
+				break
+			}
+		}
+		cur = next
+	}
+	return tl
+}
+
+// ShiftDependents moves the successors of a task whose dates no longer satisfy
+// their dependencies, and their successors in turn, keeping each task's duration.
+// Dependents only move later; tasks without dates and tasks canShift rejects stay
+// put. It returns the moved tasks with their new dates, unsaved.
+func ShiftDependents(ctx context.Context, st Store, moved *Task, canShift func(*Task) bool) ([]Task, error) {
+	current := map[int64]*Task{moved.ID: moved}
+	var shifted []*Task
+	queue := []int64{moved.ID}
+	visits := map[int64]int{}
+	for len(queue) > 0 {
+		predID := queue[0]
+		queue = queue[1:]
+		pred := current[predID]
+		predStart, predFinish, ok := taskDays(pred)
+		if !ok {
+			continue
+		}
+		deps, err := successorDependencies(ctx, st, predID)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deps {
+			succ := current[d.SuccessorID]
+			if succ == nil {
+				succ, err = st.GetTask(ctx, d.SuccessorID)
+				if err != nil {
+					return nil, err
+				}
+				if succ == nil {
+					continue
+				}
+				current[succ.ID] = succ
+			}
+			if succ.ID == moved.ID || succ.ClosedAt != nil || succ.IsArchived || !canShift(succ) {
+				continue
+			}
+			start, _, ok := taskDays(succ)
+			if !ok {
+				continue
+			}
+			delta := requiredStart(d, predStart, predFinish) - start
+			if delta <= 0 {
+				continue
+			}
+			// A loop of dependencies would push its tasks forever.
+			if visits[succ.ID]++; visits[succ.ID] > len(current) {
+				continue
+			}
+			if visits[succ.ID] == 1 {
+				shifted = append(shifted, succ)
+			}
+			if succ.StartDate != nil {
+				val := succ.StartDate.AddDate(0, 0, delta)
+				succ.StartDate = &val
+			}
+			if succ.DueDate != nil {
+				val := succ.DueDate.AddDate(0, 0, delta)
+				succ.DueDate = &val
+			}
+			queue = append(queue, succ.ID)
+		}
+	}
+	res := make([]Task, 0, len(shifted))
+	for _, t := range shifted {
+		res = append(res, *t)
+	}
+	return res, nil
+}
+
+// DependencyCreatesCycle reports whether making predecessorID a predecessor of
+// successorID closes a loop through dependencies or task blocks.
+func DependencyCreatesCycle(ctx context.Context, st Store, predecessorID, successorID int64) (bool, error) {
+	if predecessorID == successorID {
+		return true, nil
+	}
+	visited := map[int64]bool{}
+	stack := []int64{successorID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == predecessorID {
+			return true, nil
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		deps, err := successorDependencies(ctx, st, current)
+		if err != nil {
+			return false, err
+		}
+		for _, d := range deps {
+			if !visited[d.SuccessorID] {
+				stack = append(stack, d.SuccessorID)
+			}
+		}
+	}
+	return false, nil
+}
+
+// successorDependencies returns the stored dependencies of the task's successors
+// and the finish-to-start ones implied by the tasks it blocks.
+func successorDependencies(ctx context.Context, st Store, taskID int64) ([]TaskDependency, error) {
+	stored, err := st.ListTaskDependencies(ctx, []int64{taskID})
+	if err != nil {
+		return nil, err
+	}
+	var deps []TaskDependency
+	seen := map[int64]bool{}
+	for _, d := range stored {
+		if d.PredecessorID == taskID {
+			deps = append(deps, d)
+			seen[d.SuccessorID] = true
+		}
+	}
+	blocks, err := st.ListActiveBlocksByBlocker(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if !seen[b.TaskID] {
+			seen[b.TaskID] = true
+			deps = append(deps, TaskDependency{PredecessorID: taskID, SuccessorID: b.TaskID, Type: DependencyFinishStart, FromBlock: true})
+		}
+	}
+	return deps, nil
+}
+
+// taskDays returns the first and last day of a task. A task with one of its
+// dates lasts that day; a due date before the start date is ignored.
+func taskDays(t *Task) (int, int, bool) {
+	switch {
+	case t.StartDate != nil && t.DueDate != nil:
+		start, finish := dayNumber(*t.StartDate), dayNumber(*t.DueDate)
+		return start, max(start, finish), true
+	case t.StartDate != nil:
+		start := dayNumber(*t.StartDate)
+		return start, start, true
+	case t.DueDate != nil:
+		finish := dayNumber(*t.DueDate)
+		return finish, finish, true
+	}
+	return 0, 0, false
+}
+
+func requiredStart(d TaskDependency, predStart, predFinish int) int {
+	if d.Type == DependencyStartStart {
+		return predStart + d.LagDays
+	}
+	return predFinish + 1 + d.LagDays
+}
+
+func dayNumber(t time.Time) int {
+	return int(WorkDay(t).Unix() / 86400)
+}
+
+func dayTime(day int) time.Time {
+	return time.Unix(int64(day)*86400, 0).UTC()
+}
+
+// topologicalOrder orders the nodes of an acyclic graph so that every edge points
+// forward, keeping the input order among independent nodes.
+func topologicalOrder(n int, next func(int) []int) []int {
+	indegree := make([]int, n)
+	for i := 0; i < n; i++ {
+		for _, j := range next(i) {
+			indegree[j]++
+		}
+	}
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if indegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, j := range next(order[k]) {
+			if indegree[j]--; indegree[j] == 0 {
+				order = append(order, j)
+			}
+		}
+	}
+	return order
+}
+
+// stronglyConnected returns the groups of more than one node that reach each
+// other (Tarjan's algorithm).
+func stronglyConnected(n int, next func(int) []int) [][]int {
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var groups [][]int
+	counter := 0
+	var visit func(int)
+	visit = func(v int) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range next(v) {
+			if index[w] < 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var group []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			group = append(group, w)
+			if w == v {
+				break
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	return groups
+}
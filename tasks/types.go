@@ -3,6 +3,8 @@ package tasks
 import (
 	"encoding/json"
 	"time"
+
+	cstore "berkut-scc/core/store"
 )
 
 const (
@@ -78,6 +80,7 @@ type Task struct {
 	RecurringRuleID  *int64              `json:"recurring_rule_id,omitempty"`
 	Checklist        []TaskChecklistItem `json:"checklist,omitempty"`
 	CreatedBy        *int64              `json:"created_by,omitempty"`
+	StartDate        *time.Time          `json:"start_date,omitempty"`
 	DueDate          *time.Time          `json:"due_date,omitempty"`
 	CreatedAt        time.Time           `json:"created_at"`
 	UpdatedAt        time.Time           `json:"updated_at"`
@@ -240,6 +243,8 @@ type TaskFilter struct {
 	Status          string
 	IncludeArchived bool
 	Search          string
+	CustomFields    []cstore.CustomFieldCondition
+	Query           *cstore.QueryClause
 	Limit           int
 	Offset          int
 }
@@ -251,6 +256,7 @@ type TaskDTO struct {
 	Blocks         []TaskBlockInfo `json:"blocks,omitempty"`
 	BlockedByTasks []int64         `json:"blocked_by_tasks,omitempty"`
 	Tags           []string        `json:"tags,omitempty"`
+	CustomFields   map[string]any  `json:"custom_fields,omitempty"`
 }
 
 type ACLRule struct {
@@ -0,0 +1,177 @@
+package tasks
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WorklogSourceManual = "manual"
+	WorklogSourceTimer  = "timer"
+
+	EffortByUser     = "user"
+	EffortByBoard    = "board"
+	EffortBySpace    = "space"
+	EffortByCustomer = "customer"
+
+	maxWorklogMinutes    = 24 * 60
+	maxWorklogCommentLen = 2000
+)
+
+// Worklog is time a user spent on a task. WorkDate is the day the work was done,
+// stored at midnight UTC.
+type Worklog struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	UserID    int64     `json:"user_id"`
+	WorkDate  time.Time `json:"work_date"`
+	Minutes   int       `json:"minutes"`
+	Comment   string    `json:"comment"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorklogEntry is a worklog with the task, board and space it belongs to, for
+// aggregates and exports.
+type WorklogEntry struct {
+	Worklog
+	TaskTitle        string `json:"task_title"`
+	BoardID          int64  `json:"board_id"`
+	BoardName        string `json:"board_name"`
+	SpaceID          int64  `json:"space_id"`
+	SpaceName        string `json:"space_name"`
+	BusinessCustomer string `json:"business_customer"`
+}
+
+type WorklogFilter struct {
+	TaskID   int64
+	UserID   int64
+	BoardID  int64
+	SpaceID  int64
+	Customer string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
+
+// TaskTimer is the running timer of a user. A user runs at most one timer.
+type TaskTimer struct {
+	UserID    int64     `json:"user_id"`
+	TaskID    int64     `json:"task_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// TaskTimeSummary is the time tracking state of one task.
+type TaskTimeSummary struct {
+	SpentMinutes     int  `json:"spent_minutes"`
+	RemainingMinutes *int `json:"remaining_minutes,omitempty"`
+}
+
+type EffortAggregate struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Minutes int    `json:"minutes"`
+	Entries int    `json:"entries"`
+}
+
+func IsEffortGroup(val string) bool {
+	switch val {
+	case EffortByUser, EffortByBoard, EffortBySpace, EffortByCustomer:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeWorklog validates a worklog and truncates its date to the day.
+func NormalizeWorklog(w *Worklog) error {
+	if w.Minutes <= 0 || w.Minutes > maxWorklogMinutes {
+		return errors.New("tasks.worklog.minutesInvalid")
+	}
+	w.Comment = strings.TrimSpace(w.Comment)
+	if len([]rune(w.Comment)) > maxWorklogCommentLen {
+		return errors.New("tasks.worklog.commentTooLong")
+	}
+	if w.WorkDate.IsZero() {
+		w.WorkDate = time.Now().UTC()
+	}
+	w.WorkDate = WorkDay(w.WorkDate)
+	if w.WorkDate.After(WorkDay(time.Now().UTC())) {
+		return errors.New("tasks.worklog.dateInFuture")
+	}
+	if w.Source != WorklogSourceTimer {
+		w.Source = WorklogSourceManual
+	}
+	return nil
+}
+
+func WorkDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TimerMinutes rounds the elapsed time of a timer up to whole minutes.
+func TimerMinutes(startedAt, stoppedAt time.Time) int {
+	elapsed := stoppedAt.Sub(startedAt)
+	if elapsed <= 0 {
+		return 0
+	}
+	minutes := int((elapsed + time.Minute - 1) / time.Minute)
+	return min(minutes, maxWorklogMinutes)
+}
+
+// RemainingAfter reduces the remaining estimate by the logged minutes, never below zero.
+func RemainingAfter(remaining *int, logged int) *int {
+	if remaining == nil {
+		return nil
+	}
+	left := max(*remaining-logged, 0)
+	return &left
+}
+
+// AggregateWorklogs sums worklog minutes by the group key. label resolves user names
+// for the user grouping; other groups use the names carried by the entries.
+func AggregateWorklogs(entries []WorklogEntry, groupBy string, label func(userID int64) string) []EffortAggregate {
+	index := map[string]int{}
+	var out []EffortAggregate
+	for _, e := range entries {
+		key, name := effortKey(e, groupBy, label)
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, EffortAggregate{Key: key, Label: name})
+		}
+		out[i].Minutes += e.Minutes
+		out[i].Entries++
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Minutes != out[j].Minutes {
+			return out[i].Minutes > out[j].Minutes
+		}
+		return out[i].Label < out[j].Label
+	})
+	return out
+}
+
+func effortKey(e WorklogEntry, groupBy string, label func(int64) string) (string, string) {
+	switch groupBy {
+	case EffortByBoard:
+		return strconv.FormatInt(e.BoardID, 10), e.BoardName
+	case EffortBySpace:
+		return strconv.FormatInt(e.SpaceID, 10), e.SpaceName
+	case EffortByCustomer:
+		customer := strings.TrimSpace(e.BusinessCustomer)
+		return strings.ToLower(customer), customer
+	default:
+		name := ""
+		if label != nil {
+			name = label(e.UserID)
+		}
+		return strconv.FormatInt(e.UserID, 10), name
+	}
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+type assetGraphEnv struct {
+	cfg       *config.AppConfig
+	handler   *handlers.AssetsHandler
+	assets    store.AssetsStore
+	incidents store.IncidentsStore
+	controls  store.ControlsStore
+	links     store.EntityLinksStore
+	users     store.UsersStore
+	admin     *store.User
+}
+
+func newAssetGraphEnv(t *testing.T) *assetGraphEnv {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.AppConfig{
+		DBPath:    filepath.Join(dir, "test.db"),
+		Incidents: config.IncidentsConfig{RegNoFormat: "INC-{year}-{seq:05}", StorageDir: filepath.Join(dir, "incidents")},
+		Docs:      config.DocsConfig{EncryptionKey: "0123456789abcdef0123456789abcdef"},
+	}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	audits := store.NewAuditStore(db)
+	incSvc, err := incidents.NewService(cfg, audits)
+	if err != nil {
+		t.Fatalf("incidents service: %v", err)
+	}
+	env := &assetGraphEnv{
+		cfg:       cfg,
+		assets:    store.NewAssetsStore(db),
+		incidents: store.NewIncidentsStore(db),
+		controls:  store.NewControlsStore(db),
+		links:     store.NewEntityLinksStore(db),
+		users:     store.NewUsersStore(db),
+	}
+	env.handler = handlers.NewAssetsHandler(env.assets, store.NewSoftwareStore(db), nil, nil, env.users, audits, rbac.NewPolicy(rbac.DefaultRoles()))
+	env.handler.SetGraph(cfg, store.NewAssetRelationsStore(db), store.NewMonitoringStore(db), env.incidents, incSvc, env.links, env.controls)
+	env.admin = createObservablesUser(t, env.users, "graph-admin", []string{"admin"})
+	return env
+}
+
+func (e *assetGraphEnv) createAsset(t *testing.T, name string) int64 {
+	t.Helper()
+	id, err := e.assets.CreateAsset(context.Background(), &store.Asset{Name: name, Type: "host", Criticality: "high", Env: "prod", Status: "active"})
+	if err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+	return id
+}
+
+func (e *assetGraphEnv) request(method, target string, params map[string]string, body any, user *store.User, roles []string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	if params != nil {
+		req = withURLParams(req, params)
+	}
+	sess := &store.SessionRecord{UserID: user.ID, Username: user.Username, Roles: roles}
+	return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, sess))
+}
+
+func (e *assetGraphEnv) relate(t *testing.T, source, target int64, relType string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	payload := map[string]any{"target_id": target, "relation_type": relType}
+	e.handler.AddRelation(rr, e.request("POST", "/api/assets/"+strconv.FormatInt(source, 10)+"/relations", map[string]string{"id": strconv.FormatInt(source, 10)}, payload, e.admin, []string{"admin"}))
+	return rr
+}
+
+func TestAssetRelationsAndGraph(t *testing.T) {
+	env := newAssetGraphEnv(t)
+	hv := env.createAsset(t, "hypervisor-1")
+	vm := env.createAsset(t, "vm-app")
+	db := env.createAsset(t, "db-main")
+	web := env.createAsset(t, "web-portal")
+
+	if rr := env.relate(t, vm, hv, "runs_on"); rr.Code != http.StatusCreated {
+		t.Fatalf("add relation: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := env.relate(t, vm, db, "depends_on"); rr.Code != http.StatusCreated {
+		t.Fatalf("add relation: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := env.relate(t, web, vm, "depends_on"); rr.Code != http.StatusCreated {
+		t.Fatalf("add relation: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := env.relate(t, vm, hv, "RUNS_ON"); rr.Code != http.StatusConflict {
+		t.Fatalf("expected duplicate conflict, got %d", rr.Code)
+	}
+	if rr := env.relate(t, vm, vm, "depends_on"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected self relation rejected, got %d", rr.Code)
+	}
+	if rr := env.relate(t, vm, hv, "hosts"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid type rejected, got %d", rr.Code)
+	}
+
+	graph := func(id int64, query string) map[string]any {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		env.handler.Graph(rr, env.request("GET", "/api/assets/"+strconv.FormatInt(id, 10)+"/graph?"+query, map[string]string{"id": strconv.FormatInt(id, 10)}, nil, env.admin, []string{"admin"}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("graph: %d %s", rr.Code, rr.Body.String())
+		}
+		var resp map[string]any
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp
+	}
+	nodeDirections := func(resp map[string]any) map[string]string {
+		out := map[string]string{}
+		for _, raw := range resp["nodes"].([]any) {
+			n := raw.(map[string]any)
+			out[n["name"].(string)] = n["direction"].(string)
+		}
+		return out
+	}
+
+	both := nodeDirections(graph(vm, ""))
+	if both["vm-app"] != "root" || both["hypervisor-1"] != "upstream" || both["db-main"] != "upstream" || both["web-portal"] != "downstream" {
+		t.Fatalf("unexpected graph nodes: %v", both)
+	}
+	upstream := nodeDirections(graph(web, "direction=upstream&depth=1"))
+	if len(upstream) != 2 || upstream["vm-app"] != "upstream" {
+		t.Fatalf("depth 1 upstream should stop at vm-app: %v", upstream)
+	}
+	deep := nodeDirections(graph(web, "direction=upstream&depth=2"))
+	if deep["hypervisor-1"] != "upstream" || deep["db-main"] != "upstream" {
+		t.Fatalf("depth 2 upstream should reach hypervisor and db: %v", deep)
+	}
+	rr := httptest.NewRecorder()
+	env.handler.Graph(rr, env.request("GET", "/api/assets/1/graph?depth=9", map[string]string{"id": strconv.FormatInt(vm, 10)}, nil, env.admin, []string{"admin"}))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid depth rejected, got %d", rr.Code)
+	}
+}
+
+func TestAssetBlastRadius(t *testing.T) {
+	env := newAssetGraphEnv(t)
+	ctx := context.Background()
+	hv := env.createAsset(t, "hypervisor-2")
+	vm := env.createAsset(t, "vm-billing")
+	app := env.createAsset(t, "billing-app")
+	other := env.createAsset(t, "unrelated")
+	env.relate(t, vm, hv, "runs_on")
+	env.relate(t, app, vm, "depends_on")
+
+	visible := &store.Incident{Title: "Billing down", Severity: "high", Status: "open", OwnerUserID: env.admin.ID, CreatedBy: env.admin.ID, UpdatedBy: env.admin.ID, Version: 1}
+	acl := []store.ACLRule{{SubjectType: "user", SubjectID: strconv.FormatInt(env.admin.ID, 10), Permission: "manage"}}
+	if _, err := env.incidents.CreateIncident(ctx, visible, nil, acl, env.cfg.Incidents.RegNoFormat); err != nil {
+		t.Fatalf("create incident: %v", err)
+	}
+	outsider := createObservablesUser(t, env.users, "graph-outsider", []string{"analyst"})
+	hidden := &store.Incident{Title: "Other team", Severity: "low", Status: "open", OwnerUserID: outsider.ID, CreatedBy: outsider.ID, UpdatedBy: outsider.ID, Version: 1}
+	hiddenACL := []store.ACLRule{{SubjectType: "user", SubjectID: strconv.FormatInt(outsider.ID, 10), Permission: "manage"}}
+	if _, err := env.incidents.CreateIncident(ctx, hidden, nil, hiddenACL, env.cfg.Incidents.RegNoFormat); err != nil {
+		t.Fatalf("create incident: %v", err)
+	}
+	closed := &store.Incident{Title: "Old outage", Severity: "low", Status: "closed", OwnerUserID: env.admin.ID, CreatedBy: env.admin.ID, UpdatedBy: env.admin.ID, Version: 1}
+	if _, err := env.incidents.CreateIncident(ctx, closed, nil, acl, env.cfg.Incidents.RegNoFormat); err != nil {
+		t.Fatalf("create incident: %v", err)
+	}
+	for _, inc := range []*store.Incident{visible, hidden, closed} {
+		if _, err := env.incidents.AddIncidentLink(ctx, &store.IncidentLink{IncidentID: inc.ID, EntityType: "asset", EntityID: strconv.FormatInt(app, 10), CreatedBy: env.admin.ID}); err != nil {
+			t.Fatalf("link incident: %v", err)
+		}
+	}
+	control := &store.Control{Code: "BK-01", Title: "Backups", ControlType: "preventive", Domain: "ops", ReviewFrequency: "monthly", Status: "active", RiskLevel: "medium", IsActive: true}
+	if _, err := env.controls.CreateControl(ctx, control); err != nil {
+		t.Fatalf("create control: %v", err)
+	}
+	for _, target := range []int64{vm, other} {
+		if _, err := env.links.Add(ctx, &store.EntityLink{SourceType: "control", SourceID: strconv.FormatInt(control.ID, 10), TargetType: "asset", TargetID: strconv.FormatInt(target, 10), RelationType: "implements"}); err != nil {
+			t.Fatalf("link control: %v", err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	env.handler.BlastRadius(rr, env.request("GET", "/api/assets/blast-radius?asset_id="+strconv.FormatInt(hv, 10)+"&depth=3", nil, nil, env.admin, []string{"admin"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("blast radius: %d %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Assets []struct {
+			ID    int64  `json:"id"`
+			Name  string `json:"name"`
+			Depth int    `json:"depth"`
+		} `json:"assets"`
+		Incidents []struct {
+			ID       int64   `json:"id"`
+			AssetIDs []int64 `json:"asset_ids"`
+		} `json:"incidents"`
+		Controls []struct {
+			Code     string  `json:"code"`
+			AssetIDs []int64 `json:"asset_ids"`
+		} `json:"controls"`
+		AffectedSystems string `json:"affected_systems"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Assets) != 3 || resp.Assets[0].ID != hv || resp.Assets[2].Name != "billing-app" || resp.Assets[2].Depth != 2 {
+		t.Fatalf("unexpected affected assets: %+v", resp.Assets)
+	}
+	if resp.AffectedSystems != "hypervisor-2, vm-billing, billing-app" {
+		t.Fatalf("unexpected affected systems: %q", resp.AffectedSystems)
+	}
+	if len(resp.Incidents) != 1 || resp.Incidents[0].ID != visible.ID || len(resp.Incidents[0].AssetIDs) != 1 || resp.Incidents[0].AssetIDs[0] != app {
+		t.Fatalf("expected only the visible open incident: %+v", resp.Incidents)
+	}
+	if len(resp.Controls) != 1 || resp.Controls[0].Code != "BK-01" || len(resp.Controls[0].AssetIDs) != 1 || resp.Controls[0].AssetIDs[0] != vm {
+		t.Fatalf("unexpected controls: %+v", resp.Controls)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.BlastRadius(rr, env.request("GET", "/api/assets/blast-radius?asset_id="+strconv.FormatInt(hv, 10)+"&depth=3", nil, nil, env.admin, []string{"manager"}))
+	resp.Controls = nil
+	if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &resp) != nil {
+		t.Fatalf("blast radius without controls.view: %d %s", rr.Code, rr.Body.String())
+	}
+	if len(resp.Controls) != 0 {
+		t.Fatalf("expected controls hidden without controls.view: %+v", resp.Controls)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.BlastRadius(rr, env.request("GET", "/api/assets/blast-radius", nil, nil, env.admin, []string{"admin"}))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing source rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestAssetHistoryAndAsOf(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+	if err := store.ApplyMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	software := store.NewSoftwareStore(db)
+	history := store.NewAssetHistoryStore(db)
+	h := handlers.NewAssetsHandler(assets, software, nil, nil, users, store.NewAuditStore(db), rbac.NewPolicy(rbac.DefaultRoles()))
+	h.SetHistory(history)
+	env := &assetGraphEnv{handler: h}
+	admin := createObservablesUser(t, users, "history-admin", []string{"admin"})
+
+	asset := &store.Asset{Name: "db-01", Type: "host", Criticality: "medium", Env: "prod", Status: "active", Owner: "ops", CreatedBy: &admin.ID, UpdatedBy: &admin.ID}
+	assetID, err := assets.CreateAsset(ctx, asset)
+	if err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+	productID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "PostgreSQL", Vendor: "PGDG"})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	instID, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: productID, VersionText: "13.4", CreatedBy: &admin.ID, UpdatedBy: &admin.ID})
+	if err != nil {
+		t.Fatalf("add software: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	before := time.Now().UTC()
+	time.Sleep(20 * time.Millisecond)
+
+	asset.Criticality = "critical"
+	asset.Owner = "dba"
+	asset.Tags = []string{"pci"}
+	if err := assets.UpdateAsset(ctx, asset); err != nil {
+		t.Fatalf("update asset: %v", err)
+	}
+	if err := software.UpdateAssetSoftware(ctx, &store.AssetSoftwareInstallation{ID: instID, VersionText: "15.2", UpdatedBy: &admin.ID}); err != nil {
+		t.Fatalf("update software: %v", err)
+	}
+	if err := assets.ArchiveAsset(ctx, assetID, admin.ID); err != nil {
+		t.Fatalf("archive asset: %v", err)
+	}
+	if err := assets.RestoreAsset(ctx, assetID, admin.ID); err != nil {
+		t.Fatalf("restore asset: %v", err)
+	}
+
+	changes, err := history.ListAssetHistory(ctx, assetID, store.AssetHistoryFilter{})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(changes) != 6 {
+		t.Fatalf("expected 6 history entries, got %d", len(changes))
+	}
+	update := changes[3]
+	if update.Action != "update" || update.EntityType != store.AssetHistoryEntityAsset {
+		t.Fatalf("unexpected entry order: %+v", update)
+	}
+	if c := update.Changes["criticality"]; c.Old != "medium" || c.New != "critical" {
+		t.Fatalf("unexpected criticality diff: %+v", c)
+	}
+	if _, ok := update.Changes["name"]; ok {
+		t.Fatalf("unchanged field recorded: %+v", update.Changes)
+	}
+	if update.ActorID == nil || *update.ActorID != admin.ID {
+		t.Fatalf("expected actor %d, got %v", admin.ID, update.ActorID)
+	}
+
+	past, pastSoftware, err := history.AssetAsOf(ctx, assetID, before)
+	if err != nil || past == nil {
+		t.Fatalf("as of: %v %v", past, err)
+	}
+	if past.Criticality != "medium" || past.Owner != "ops" || len(past.Tags) != 0 || past.DeletedAt != nil {
+		t.Fatalf("unexpected past state: %+v", past)
+	}
+	if len(pastSoftware) != 1 || pastSoftware[0].VersionText != "13.4" {
+		t.Fatalf("unexpected past software: %+v", pastSoftware)
+	}
+	current, _, err := history.AssetAsOf(ctx, assetID, time.Now().UTC())
+	if err != nil || current == nil || current.Criticality != "critical" || current.DeletedAt != nil {
+		t.Fatalf("unexpected current state: %+v %v", current, err)
+	}
+	if none, _, err := history.AssetAsOf(ctx, assetID, before.Add(-time.Hour)); err != nil || none != nil {
+		t.Fatalf("expected no asset before creation, got %+v %v", none, err)
+	}
+
+	params := map[string]string{"id": strconv.FormatInt(assetID, 10)}
+	rr := httptest.NewRecorder()
+	h.History(rr, env.request(http.MethodGet, "/api/assets/"+params["id"]+"/history", params, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("history status %d: %s", rr.Code, rr.Body.String())
+	}
+	var listResp struct {
+		Items []struct {
+			Action      string `json:"action"`
+			ActorName   string `json:"actor_name"`
+			ProductName string `json:"product_name"`
+			EntityType  string `json:"entity_type"`
+		} `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &listResp)
+	if len(listResp.Items) != 6 || listResp.Items[0].ActorName != "history-admin" {
+		t.Fatalf("unexpected history response: %s", rr.Body.String())
+	}
+	if listResp.Items[2].EntityType != store.AssetHistoryEntitySoftware || listResp.Items[2].ProductName != "PostgreSQL" {
+		t.Fatalf("expected software entry with product name: %+v", listResp.Items[2])
+	}
+
+	rr = httptest.NewRecorder()
+	h.AsOf(rr, env.request(http.MethodGet, "/api/assets/"+params["id"]+"/as-of?at="+before.Format(time.RFC3339Nano), params, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("as-of status %d: %s", rr.Code, rr.Body.String())
+	}
+	var asOfResp struct {
+		Asset    store.Asset                       `json:"asset"`
+		Software []store.AssetSoftwareInstallation `json:"software"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &asOfResp)
+	if asOfResp.Asset.Criticality != "medium" || len(asOfResp.Software) != 1 {
+		t.Fatalf("unexpected as-of response: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.AsOf(rr, env.request(http.MethodGet, "/api/assets/"+params["id"]+"/as-of?at=2000-01-01", params, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before creation, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	h.AsOf(rr, env.request(http.MethodGet, "/api/assets/"+params["id"]+"/as-of?at=yesterday", params, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad time, got %d", rr.Code)
+	}
+}
@@ -32,7 +32,7 @@ func TestAssetsAutocompleteRejectsInvalidField(t *testing.T) {
 		t.Fatalf("create user: %v", err)
 	}
 	policy := rbac.NewPolicy(rbac.DefaultRoles())
-	h := handlers.NewAssetsHandler(store.NewAssetsStore(db), store.NewSoftwareStore(db), users, store.NewAuditStore(db), policy)
+	h := handlers.NewAssetsHandler(store.NewAssetsStore(db), store.NewSoftwareStore(db), nil, nil, users, store.NewAuditStore(db), policy)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/assets/autocomplete?field=bad", nil)
 	req = makeSessionContext(req, "u1", 1, []string{"superadmin"})
@@ -0,0 +1,404 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/importer"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+const nmapReport = `<?xml version="1.0"?>
+<nmaprun scanner="nmap">
+  <host><status state="up"/>
+    <address addr="10.0.0.5" addrtype="ipv4"/><address addr="00:11:22:33:44:55" addrtype="mac"/>
+    <hostnames><hostname name="web-01.corp" type="PTR"/></hostnames>
+    <ports>
+      <port protocol="tcp" portid="22"><state state="open"/><service name="ssh" product="OpenSSH" version="8.9p1"/></port>
+      <port protocol="tcp" portid="443"><state state="open"/><service name="https" product="nginx"/></port>
+      <port protocol="tcp" portid="25"><state state="closed"/><service name="smtp"/></port>
+    </ports>
+    <os><osmatch name="Linux 5.x" accuracy="95"/></os>
+  </host>
+  <host><status state="up"/>
+    <address addr="10.0.0.9" addrtype="ipv4"/>
+    <ports><port protocol="udp" portid="161"><state state="open"/><service name="snmp"/></port></ports>
+  </host>
+  <host><status state="down"/><address addr="10.0.0.10" addrtype="ipv4"/></host>
+</nmaprun>`
+
+const nessusReport = `<?xml version="1.0" ?>
+<NessusClientData_v2>
+  <Report name="weekly">
+    <ReportHost name="10.0.0.5">
+      <HostProperties><tag name="host-ip">10.0.0.5</tag><tag name="host-fqdn">web-01.corp</tag></HostProperties>
+      <ReportItem port="443" svc_name="www" protocol="tcp" severity="3" pluginID="10001" pluginName="OpenSSL Outdated">
+        <synopsis>Outdated OpenSSL.</synopsis><solution>Upgrade OpenSSL.</solution>
+        <cvss3_base_score>7.5</cvss3_base_score><cve>CVE-2022-0778</cve>
+      </ReportItem>
+      <ReportItem port="8443" svc_name="www" protocol="tcp" severity="4" pluginID="10001" pluginName="OpenSSL Outdated">
+        <cvss3_base_score>9.8</cvss3_base_score><cve>cve-2022-3602</cve>
+      </ReportItem>
+      <ReportItem port="0" svc_name="general" protocol="tcp" severity="0" pluginID="19506" pluginName="Nessus Scan Information"/>
+    </ReportHost>
+    <ReportHost name="10.0.0.77">
+      <HostProperties><tag name="host-ip">10.0.0.77</tag></HostProperties>
+      <ReportItem port="22" svc_name="ssh" protocol="tcp" severity="2" pluginID="20002" pluginName="SSH Weak Algorithms"/>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`
+
+const openVASReport = `<report id="r1"><report><results>
+  <result id="1"><name>TLS 1.0 enabled</name><host>10.0.0.9<hostname>snmp-01</hostname></host><port>443/tcp</port>
+    <nvt oid="1.3.6.1.4.1.25623.1.0.117274"><name>TLS 1.0 enabled</name><cvss_base>4.3</cvss_base>
+      <tags>summary=Deprecated TLS.|solution=Disable TLS 1.0.</tags><refs><ref type="cve" id="CVE-2011-3389"/></refs></nvt>
+    <threat>Medium</threat><severity>4.3</severity><description>TLSv1.0 accepted</description></result>
+  <result id="2"><name>OS Detection</name><host>10.0.0.9</host><port>general/tcp</port>
+    <nvt oid="1.3.6.1.4.1.25623.1.0.105937"><name>OS Detection</name></nvt><threat>Log</threat><severity>0.0</severity></result>
+</results></report></report>`
+
+func TestReadTableCSVAndXLSX(t *testing.T) {
+	csv := "\ufeffName;IP;Tags\nweb-01;10.0.0.5, 10.0.0.6;prod|dmz\n;;\ndb-01;10.0.0.7;\n"
+	table, err := importer.ReadTable("assets.csv", []byte(csv))
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if strings.Join(table.Headers, ",") != "Name,IP,Tags" || len(table.Rows) != 2 {
+		t.Fatalf("unexpected csv table: %+v", table)
+	}
+	if ips := importer.SplitList(table.Rows[0][1]); len(ips) != 2 || ips[1] != "10.0.0.6" {
+		t.Fatalf("unexpected ip list: %v", ips)
+	}
+
+	table, err = importer.ReadTable("assets.xlsx", buildXLSX(t, [][]string{{"Name", "Commissioned"}, {"web-01", "45000"}, {"", ""}, {"db-01", "2024-03-01"}}))
+	if err != nil {
+		t.Fatalf("xlsx: %v", err)
+	}
+	if strings.Join(table.Headers, ",") != "Name,Commissioned" || len(table.Rows) != 2 || table.Rows[1][0] != "db-01" {
+		t.Fatalf("unexpected xlsx table: %+v", table)
+	}
+	d, err := importer.ParseDate(table.Rows[0][1])
+	if err != nil || d.Format("2006-01-02") != "2023-03-15" {
+		t.Fatalf("excel serial date: %v %v", d, err)
+	}
+	if _, err := importer.ReadTable("empty.csv", []byte("Name\n")); err != importer.ErrEmpty {
+		t.Fatalf("expected empty error, got %v", err)
+	}
+}
+
+func TestAssetsImportUpsertByNameAndIP(t *testing.T) {
+	env := newBulkImportEnv(t)
+	ctx := context.Background()
+	h := handlers.NewAssetsHandler(env.assets, nil, nil, nil, env.users, env.audits, rbac.NewPolicy(rbac.DefaultRoles()))
+	byName, _ := env.assets.CreateAsset(ctx, &store.Asset{Name: "web-01", Type: "host", IPAddresses: []string{"10.0.0.5"}, Tags: []string{"PROD"}})
+	byIP, _ := env.assets.CreateAsset(ctx, &store.Asset{Name: "legacy-db", Type: "host", IPAddresses: []string{"10.0.0.7"}})
+
+	csv := "Host,Addresses,Owner,Tags\n" +
+		"web-01,10.0.0.6,alice,DMZ\n" +
+		"db-01,10.0.0.7,bob,\n" +
+		"new-01,10.0.0.8,carol,\n" +
+		"bad-01,10.0.0.300,,\n"
+	mapping := map[string]string{"name": "Host", "ip_addresses": "Addresses", "owner": "Owner", "tags": "Tags"}
+
+	upload := env.uploadTable(t, h.ImportUpload, "assets.csv", csv)
+	if upload.TotalRows != 4 || len(upload.PreviewRows) != 4 {
+		t.Fatalf("unexpected upload: %+v", upload)
+	}
+	dry := env.commitTable(t, h.ImportCommit, upload.ImportID, mapping, true)
+	if dry.CreatedCount != 1 || dry.UpdatedCount != 2 || dry.FailedCount != 1 || dry.Failures[0].RowNumber != 5 {
+		t.Fatalf("unexpected dry run: %+v", dry)
+	}
+	if items, _ := env.assets.ListAssets(ctx, store.AssetFilter{}); len(items) != 2 {
+		t.Fatalf("dry run must not write, got %d assets", len(items))
+	}
+	res := env.commitTable(t, h.ImportCommit, upload.ImportID, mapping, false)
+	if res.CreatedCount != 1 || res.UpdatedCount != 2 || res.FailedCount != 1 {
+		t.Fatalf("unexpected commit: %+v", res)
+	}
+
+	a, _ := env.assets.GetAsset(ctx, byName)
+	if a.Owner != "alice" || len(a.IPAddresses) != 2 || len(a.Tags) != 2 {
+		t.Fatalf("asset matched by name not merged: %+v", a)
+	}
+	a, _ = env.assets.GetAsset(ctx, byIP)
+	if a.Name != "legacy-db" || a.Owner != "bob" {
+		t.Fatalf("asset matched by ip not updated: %+v", a)
+	}
+	if items, _ := env.assets.ListAssets(ctx, store.AssetFilter{}); len(items) != 3 {
+		t.Fatalf("expected 3 assets, got %d", len(items))
+	}
+	if rr := env.post(h.ImportCommit, map[string]any{"import_id": upload.ImportID, "mapping": mapping}); rr.Code != http.StatusNotFound {
+		t.Fatalf("session must be consumed, got %d", rr.Code)
+	}
+}
+
+func TestNmapImportPlanAndApply(t *testing.T) {
+	env := newBulkImportEnv(t)
+	ctx := context.Background()
+	existing, _ := env.assets.CreateAsset(ctx, &store.Asset{Name: "web-01.corp", Type: "host", Description: "Frontend"})
+
+	hosts, err := importer.ParseNmap([]byte(nmapReport))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "web-01.corp" || len(hosts[0].Ports) != 2 || hosts[0].OS != "Linux 5.x" {
+		t.Fatalf("unexpected hosts: %+v", hosts)
+	}
+	plan, err := importer.BuildHostPlan(ctx, env.assets, hosts)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Added != 1 || plan.Updated != 1 || plan.Items[0].AssetID != existing {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if err := importer.ApplyHostPlan(ctx, env.assets, plan, env.user.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	a, _ := env.assets.GetAsset(ctx, existing)
+	if !strings.HasPrefix(a.Description, "Frontend") || !strings.Contains(a.Description, "443/tcp https (nginx)") || len(a.IPAddresses) != 1 {
+		t.Fatalf("existing asset not updated: %+v", a)
+	}
+
+	// A second scan replaces the port block instead of appending another one.
+	plan, _ = importer.BuildHostPlan(ctx, env.assets, hosts)
+	_ = importer.ApplyHostPlan(ctx, env.assets, plan, env.user.ID, time.Now().UTC())
+	a, _ = env.assets.GetAsset(ctx, existing)
+	if strings.Count(a.Description, "Open ports") != 1 {
+		t.Fatalf("port notes duplicated: %q", a.Description)
+	}
+	items, _ := env.assets.ListAssets(ctx, store.AssetFilter{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 assets, got %d", len(items))
+	}
+}
+
+func TestVulnReportImportLinksFindings(t *testing.T) {
+	env := newBulkImportEnv(t)
+	ctx := context.Background()
+	st := importer.FindingStores{Assets: env.assets, Findings: env.findings, Links: env.links}
+	web, _ := env.assets.CreateAsset(ctx, &store.Asset{Name: "web-01", Type: "host", IPAddresses: []string{"10.0.0.5"}})
+
+	report, err := importer.ParseVulnReport([]byte(nessusReport))
+	if err != nil || report.Format != importer.FormatNessus {
+		t.Fatalf("nessus: %v %+v", err, report)
+	}
+	if len(report.Issues) != 3 || report.Informational != 1 {
+		t.Fatalf("unexpected nessus issues: %+v", report)
+	}
+	plan, err := importer.BuildFindingPlan(ctx, st, report)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Added != 2 || plan.NewAssets != 1 || len(plan.Items) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	merged := plan.Items[0]
+	if merged.AssetID != web || merged.Severity != "critical" || len(merged.Ports) != 2 || len(merged.CVEs) != 2 {
+		t.Fatalf("issues of one plugin not merged: %+v", merged)
+	}
+	if err := importer.ApplyFindingPlan(ctx, st, plan, env.user.ID, time.Now().UTC(), nil); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	linked, err := importer.LinkedFindings(ctx, st, web)
+	if err != nil || len(linked) != 1 || linked[0].FindingType != "vulnerability" || !strings.Contains(linked[0].DescriptionMD, "CVE-2022-3602") {
+		t.Fatalf("finding not linked to asset: %v %+v", err, linked)
+	}
+
+	// Re-importing the same report is a no-op; a resolved finding is reopened.
+	plan, _ = importer.BuildFindingPlan(ctx, st, report)
+	if plan.Unchanged != 2 || plan.NewAssets != 0 {
+		t.Fatalf("expected unchanged plan, got %+v", plan)
+	}
+	f := linked[0]
+	f.Status = "resolved"
+	if err := env.findings.UpdateFinding(ctx, &f); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	plan, _ = importer.BuildFindingPlan(ctx, st, report)
+	if plan.Updated != 1 || !plan.Items[0].Reopened {
+		t.Fatalf("expected reopen, got %+v", plan)
+	}
+	_ = importer.ApplyFindingPlan(ctx, st, plan, env.user.ID, time.Now().UTC(), nil)
+	if got, _ := env.findings.GetFinding(ctx, f.ID); got.Status != "open" {
+		t.Fatalf("finding not reopened: %s", got.Status)
+	}
+
+	report, err = importer.ParseVulnReport([]byte(openVASReport))
+	if err != nil || report.Format != importer.FormatOpenVAS || len(report.Issues) != 1 || report.Informational != 1 {
+		t.Fatalf("openvas: %v %+v", err, report)
+	}
+	issue := report.Issues[0]
+	if issue.Severity != "medium" || issue.Port != 443 || issue.Solution != "Disable TLS 1.0." || issue.CVEs[0] != "CVE-2011-3389" {
+		t.Fatalf("unexpected openvas issue: %+v", issue)
+	}
+	if _, err := importer.ParseVulnReport([]byte(`<foo/>`)); err != importer.ErrFormatInvalid {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
+
+type bulkImportEnv struct {
+	assets   store.AssetsStore
+	findings store.FindingsStore
+	links    store.EntityLinksStore
+	users    store.UsersStore
+	audits   store.AuditStore
+	user     *store.User
+}
+
+type tableUploadResponse struct {
+	ImportID    string     `json:"import_id"`
+	PreviewRows [][]string `json:"preview_rows"`
+	TotalRows   int        `json:"total_rows"`
+}
+
+type tableCommitResponse struct {
+	TotalRows    int  `json:"total_rows"`
+	CreatedCount int  `json:"created_count"`
+	UpdatedCount int  `json:"updated_count"`
+	FailedCount  int  `json:"failed_count"`
+	DryRun       bool `json:"dry_run"`
+	Failures     []struct {
+		RowNumber int    `json:"row_number"`
+		Reason    string `json:"reason"`
+	} `json:"failures"`
+}
+
+func newBulkImportEnv(t *testing.T) *bulkImportEnv {
+	t.Helper()
+	cfg := &config.AppConfig{DBPath: filepath.Join(t.TempDir(), "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	env := &bulkImportEnv{
+		assets:   store.NewAssetsStore(db),
+		findings: store.NewFindingsStore(db),
+		links:    store.NewEntityLinksStore(db),
+		users:    store.NewUsersStore(db),
+		audits:   store.NewAuditStore(db),
+	}
+	env.user = createObservablesUser(t, env.users, "import-admin", []string{"admin"})
+	return env
+}
+
+func (e *bulkImportEnv) withSession(req *http.Request) *http.Request {
+	sess := &store.SessionRecord{UserID: e.user.ID, Username: e.user.Username, Roles: []string{"admin"}}
+	return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, sess))
+}
+
+func (e *bulkImportEnv) uploadTable(t *testing.T, handler http.HandlerFunc, filename, content string) tableUploadResponse {
+	t.Helper()
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("file", filename)
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+	req := httptest.NewRequest("POST", "/import/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	handler(rr, e.withSession(req))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upload status %d: %s", rr.Code, rr.Body.String())
+	}
+	var res tableUploadResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode upload: %v", err)
+	}
+	return res
+}
+
+func (e *bulkImportEnv) post(handler http.HandlerFunc, payload any) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/import/commit", bytes.NewReader(raw))
+	rr := httptest.NewRecorder()
+	handler(rr, e.withSession(req))
+	return rr
+}
+
+func (e *bulkImportEnv) commitTable(t *testing.T, handler http.HandlerFunc, importID string, mapping map[string]string, dryRun bool) tableCommitResponse {
+	t.Helper()
+	rr := e.post(handler, map[string]any{"import_id": importID, "mapping": mapping, "dry_run": dryRun})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("commit status %d: %s", rr.Code, rr.Body.String())
+	}
+	var res tableCommitResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode commit: %v", err)
+	}
+	return res
+}
+
+// buildXLSX writes a minimal workbook: the first column as shared strings, the rest
+// as numbers or inline strings.
+func buildXLSX(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+	var shared []string
+	var sheet strings.Builder
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		sheet.WriteString(`<row r="` + strconv.Itoa(i+1) + `">`)
+		for j, val := range row {
+			if val == "" {
+				continue
+			}
+			ref := string(rune('A'+j)) + strconv.Itoa(i+1)
+			switch {
+			case j == 0:
+				shared = append(shared, val)
+				sheet.WriteString(`<c r="` + ref + `" t="s"><v>` + strconv.Itoa(len(shared)-1) + `</v></c>`)
+			case strings.Trim(val, "0123456789") == "":
+				sheet.WriteString(`<c r="` + ref + `"><v>` + val + `</v></c>`)
+			default:
+				sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t>` + val + `</t></is></c>`)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	var sst strings.Builder
+	sst.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, s := range shared {
+		sst.WriteString(`<si><t>` + s + `</t></si>`)
+	}
+	sst.WriteString(`</sst>`)
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Data" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId3" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/worksheets/data.xml":     sheet.String(),
+		"xl/sharedStrings.xml":       sst.String(),
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/customfields"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestCustomFieldsAssets(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+	if err := store.ApplyMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	audits := store.NewAuditStore(db)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	cf := store.NewCustomFieldsStore(db)
+	fieldsHandler := handlers.NewCustomFieldsHandler(cf, audits, policy)
+	assetsHandler := handlers.NewAssetsHandler(assets, store.NewSoftwareStore(db), nil, nil, users, audits, policy)
+	assetsHandler.SetCustomFields(customfields.NewService(cf, users, assets))
+	env := &assetGraphEnv{handler: assetsHandler}
+	admin := createObservablesUser(t, users, "cf-admin", []string{"admin"})
+	analyst := createObservablesUser(t, users, "cf-analyst", []string{"analyst"})
+
+	createField := func(user *store.User, roles []string, payload map[string]any) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		fieldsHandler.Create(rr, env.request(http.MethodPost, "/api/custom-fields", nil, payload, user, roles))
+		return rr
+	}
+	unit := map[string]any{"entity_type": "asset", "key": "business_unit", "label": "Business unit", "field_type": "select", "options": []string{"retail", "corp"}, "required": true}
+	if rr := createField(analyst, []string{"analyst"}, unit); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without settings.custom_fields, got %d", rr.Code)
+	}
+	rr := createField(admin, []string{"admin"}, unit)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create field status %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := createField(admin, []string{"admin"}, unit); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate key, got %d", rr.Code)
+	}
+	bad := map[string]any{"entity_type": "asset", "key": "1bad", "label": "Bad", "field_type": "text"}
+	if rr := createField(admin, []string{"admin"}, bad); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "customFields.error.key") {
+		t.Fatalf("expected invalid key error, got %d %s", rr.Code, rr.Body.String())
+	}
+	secret := map[string]any{"entity_type": "asset", "key": "secret_note", "label": "Secret", "field_type": "text", "view_roles": []string{"admin"}, "edit_roles": []string{"admin"}}
+	rr = createField(admin, []string{"admin"}, secret)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create secret field status %d: %s", rr.Code, rr.Body.String())
+	}
+	var secretField store.CustomField
+	_ = json.Unmarshal(rr.Body.Bytes(), &secretField)
+
+	createAsset := func(user *store.User, roles []string, name string, values map[string]any) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		payload := map[string]any{"name": name, "type": "host", "criticality": "medium", "env": "prod", "status": "active", "custom_fields": values}
+		assetsHandler.Create(rr, env.request(http.MethodPost, "/api/assets", nil, payload, user, roles))
+		return rr
+	}
+	if rr := createAsset(admin, []string{"admin"}, "srv-0", nil); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "customFields.error.required") {
+		t.Fatalf("expected required error, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := createAsset(admin, []string{"admin"}, "srv-0", map[string]any{"business_unit": "unknown"}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "customFields.error.invalid") {
+		t.Fatalf("expected invalid option error, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := createAsset(analyst, []string{"analyst"}, "srv-0", map[string]any{"business_unit": "corp", "secret_note": "x"}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "customFields.error.unknown") {
+		t.Fatalf("expected hidden field to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+	rr = createAsset(admin, []string{"admin"}, "srv-1", map[string]any{"business_unit": "retail", "secret_note": "vault"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create asset status %d: %s", rr.Code, rr.Body.String())
+	}
+	var created store.Asset
+	_ = json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.CustomFields["business_unit"] != "retail" || created.CustomFields["secret_note"] != "vault" {
+		t.Fatalf("unexpected custom field values: %+v", created.CustomFields)
+	}
+	if rr := createAsset(admin, []string{"admin"}, "srv-2", map[string]any{"business_unit": "corp"}); rr.Code != http.StatusCreated {
+		t.Fatalf("create second asset status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	list := func(user *store.User, roles []string, query string) ([]store.Asset, int) {
+		rr := httptest.NewRecorder()
+		assetsHandler.List(rr, env.request(http.MethodGet, "/api/assets?"+query, nil, nil, user, roles))
+		var resp struct {
+			Items []store.Asset `json:"items"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Items, rr.Code
+	}
+	items, code := list(analyst, []string{"analyst"}, "cf.business_unit=retail")
+	if code != http.StatusOK || len(items) != 1 || items[0].ID != created.ID {
+		t.Fatalf("unexpected filtered list: %d %+v", code, items)
+	}
+	if _, ok := items[0].CustomFields["secret_note"]; ok {
+		t.Fatalf("hidden field returned to analyst: %+v", items[0].CustomFields)
+	}
+	if _, code := list(analyst, []string{"analyst"}, "cf.secret_note=vault"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 filtering by a hidden field, got %d", code)
+	}
+
+	rr = httptest.NewRecorder()
+	assetsHandler.ExportCSV(rr, env.request(http.MethodGet, "/api/assets/export.csv?cf.business_unit=retail", nil, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export status %d: %s", rr.Code, rr.Body.String())
+	}
+	csv := rr.Body.String()
+	if !strings.Contains(csv, "cf.business_unit") || !strings.Contains(csv, "retail") || strings.Contains(csv, "srv-2") {
+		t.Fatalf("unexpected export: %s", csv)
+	}
+
+	params := map[string]string{"id": strconv.FormatInt(secretField.ID, 10)}
+	rr = httptest.NewRecorder()
+	fieldsHandler.Archive(rr, env.request(http.MethodDelete, "/api/custom-fields/"+params["id"], params, nil, admin, []string{"admin"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("archive status %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	fieldsHandler.List(rr, env.request(http.MethodGet, "/api/custom-fields?entity_type=asset", nil, nil, admin, []string{"admin"}))
+	var active struct {
+		Items []store.CustomField `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &active)
+	if len(active.Items) != 1 || active.Items[0].Key != "business_unit" {
+		t.Fatalf("unexpected active fields: %s", rr.Body.String())
+	}
+
+	// LIKE wildcards in a multi-select filter value must match literally.
+	teams := map[string]any{"entity_type": "asset", "key": "teams", "label": "Teams", "field_type": "multiselect", "options": []string{"team_a", "teamxa"}}
+	if rr := createField(admin, []string{"admin"}, teams); rr.Code != http.StatusCreated {
+		t.Fatalf("create multiselect field status %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = createAsset(admin, []string{"admin"}, "srv-3", map[string]any{"business_unit": "corp", "teams": []string{"teamxa"}})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create multiselect asset status %d: %s", rr.Code, rr.Body.String())
+	}
+	if items, code := list(admin, []string{"admin"}, "cf.teams=team_a"); code != http.StatusOK || len(items) != 0 {
+		t.Fatalf("wildcard matched another option: %d %+v", code, items)
+	}
+	if items, code := list(admin, []string{"admin"}, "cf.teams=teamxa"); code != http.StatusOK || len(items) != 1 {
+		t.Fatalf("unexpected multiselect filter: %d %+v", code, items)
+	}
+}
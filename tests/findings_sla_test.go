@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/findings"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+type findingsSLAEnv struct {
+	findings      store.FindingsStore
+	sla           store.FindingSLAStore
+	notifications store.NotificationsStore
+	svc           *findings.Service
+	handler       *handlers.FindingsHandler
+	admin         *store.User
+	analyst       *store.User
+}
+
+func setupFindingsSLA(t *testing.T) *findingsSLAEnv {
+	t.Helper()
+	cfg := &config.AppConfig{DBPath: filepath.Join(t.TempDir(), "findings_sla.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	fs := store.NewFindingsStore(db)
+	sla := store.NewFindingSLAStore(db)
+	audits := store.NewAuditStore(db)
+	ns := store.NewNotificationsStore(db)
+	notifier := notify.NewService(ns, logger)
+	svc := findings.NewService(fs, sla, users, audits)
+	svc.SetNotifier(notifier)
+	h := handlers.NewFindingsHandler(fs, nil, users, nil, nil, nil, nil, audits, rbac.NewPolicy(rbac.DefaultRoles()))
+	h.SetSLA(svc, sla)
+	admin := createObservablesUser(t, users, "sla-admin", []string{"admin"})
+	analyst := createObservablesUser(t, users, "sla-analyst", []string{"analyst"})
+	t.Cleanup(notifier.Wait)
+	return &findingsSLAEnv{findings: fs, sla: sla, notifications: ns, svc: svc, handler: h, admin: admin, analyst: analyst}
+}
+
+func (env *findingsSLAEnv) createFinding(t *testing.T, f store.Finding) *store.Finding {
+	t.Helper()
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now().UTC()
+	}
+	f.UpdatedAt = f.CreatedAt
+	f.CreatedBy = &env.admin.ID
+	f.Version = 1
+	id, err := env.findings.CreateFinding(context.Background(), &f)
+	if err != nil {
+		t.Fatalf("create finding: %v", err)
+	}
+	out, _ := env.findings.GetFinding(context.Background(), id)
+	return out
+}
+
+func (env *findingsSLAEnv) notificationsOf(t *testing.T, u *store.User, event string) []store.Notification {
+	t.Helper()
+	items, err := env.notifications.ListNotifications(context.Background(), u.ID, store.NotificationFilter{EventType: event})
+	if err != nil {
+		t.Fatalf("notifications: %v", err)
+	}
+	return items
+}
+
+func TestFindingSLADefaultDueDate(t *testing.T) {
+	env := setupFindingsSLA(t)
+	rr := httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/findings", map[string]any{"title": "Weak TLS", "severity": "critical"}, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rr.Code, rr.Body.String())
+	}
+	var created store.Finding
+	_ = json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.DueAt == nil {
+		t.Fatalf("expected default due date")
+	}
+	if days := created.DueAt.Sub(created.CreatedAt).Hours() / 24; days < 6.99 || days > 7.01 {
+		t.Fatalf("expected 7 day SLA for critical, got %.2f", days)
+	}
+
+	policy := store.DefaultFindingSLAPolicy()
+	policy.Days["low"] = 0
+	if err := env.sla.SaveSLAPolicy(context.Background(), &policy); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/findings", map[string]any{"title": "Banner", "severity": "low"}, env.admin, []string{"admin"}, nil))
+	var low store.Finding
+	_ = json.Unmarshal(rr.Body.Bytes(), &low)
+	if low.DueAt != nil {
+		t.Fatalf("expected no due date when the severity has no SLA, got %v", low.DueAt)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.UpdateSLAPolicy(rr, riskRequest(http.MethodPut, "/api/findings/sla/policy", map[string]any{"days": map[string]int{"urgent": 1}, "max_exception_days": 30}, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown severity to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestFindingSLAOverdueEscalation(t *testing.T) {
+	env := setupFindingsSLA(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	past := now.AddDate(0, 0, -10)
+	late := env.createFinding(t, store.Finding{Title: "Unpatched host", Status: "open", Severity: "high", Owner: env.analyst.Username, DueAt: &past})
+	recent := now.AddDate(0, 0, -2)
+	fresh := env.createFinding(t, store.Finding{Title: "Missing header", Status: "in_progress", Severity: "medium", Owner: env.analyst.Username, DueAt: &recent})
+	noDue := env.createFinding(t, store.Finding{Title: "Imported", Status: "open", Severity: "critical", CreatedAt: now.AddDate(0, 0, -1)})
+
+	res, err := env.svc.Evaluate(ctx, now, "tester")
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if res.NewlyOverdue != 2 || res.Escalated != 1 || res.DueDatesSet != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	got, _ := env.findings.GetFinding(ctx, late.ID)
+	if got.OverdueAt == nil || got.EscalatedAt == nil {
+		t.Fatalf("expected overdue and escalated marks: %+v", got)
+	}
+	got, _ = env.findings.GetFinding(ctx, fresh.ID)
+	if got.OverdueAt == nil || got.EscalatedAt != nil {
+		t.Fatalf("expected overdue without escalation: %+v", got)
+	}
+	got, _ = env.findings.GetFinding(ctx, noDue.ID)
+	if got.DueAt == nil || got.OverdueAt != nil {
+		t.Fatalf("expected backfilled due date: %+v", got)
+	}
+	if n := len(env.notificationsOf(t, env.analyst, notify.EventFindingOverdue)); n != 2 {
+		t.Fatalf("expected 2 owner notifications, got %d", n)
+	}
+	escalated := env.notificationsOf(t, env.admin, notify.EventFindingOverdue)
+	if len(escalated) != 1 {
+		t.Fatalf("expected escalation to the creator, got %d", len(escalated))
+	}
+	if n := escalated[0]; n.TitleKey != "notifications.finding.escalated" || n.BodyKey != "notifications.finding.overdueSince" ||
+		n.Params["title"] != "Unpatched host" || n.Params["severity"] != "high" || n.Title != "Overdue finding escalated: Unpatched host" {
+		t.Fatalf("expected i18n key with params and English fallback: %+v", n)
+	}
+
+	res, err = env.svc.Evaluate(ctx, now.Add(time.Hour), "tester")
+	if err != nil {
+		t.Fatalf("evaluate again: %v", err)
+	}
+	if res.NewlyOverdue != 0 || res.Escalated != 0 || res.Overdue != 2 {
+		t.Fatalf("expected no repeated alerts: %+v", res)
+	}
+
+	rr := httptest.NewRecorder()
+	env.handler.List(rr, riskRequest(http.MethodGet, "/api/findings?overdue=1", nil, env.admin, []string{"admin"}, nil))
+	var list struct {
+		Items []store.Finding `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Items) != 2 {
+		t.Fatalf("expected 2 overdue findings, got %d", len(list.Items))
+	}
+
+	extended := now.AddDate(0, 0, 14)
+	got, _ = env.findings.GetFinding(ctx, fresh.ID)
+	got.DueAt = &extended
+	if err := env.findings.UpdateFinding(ctx, got); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	got, _ = env.findings.GetFinding(ctx, late.ID)
+	got.Status = "resolved"
+	if err := env.findings.UpdateFinding(ctx, got); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	res, err = env.svc.Evaluate(ctx, now.Add(2*time.Hour), "tester")
+	if err != nil {
+		t.Fatalf("evaluate after changes: %v", err)
+	}
+	if res.Overdue != 0 || res.Cleared != 2 {
+		t.Fatalf("expected marks to be cleared: %+v", res)
+	}
+	got, _ = env.findings.GetFinding(ctx, late.ID)
+	if got.OverdueAt != nil || got.ResolvedAt == nil {
+		t.Fatalf("expected resolved finding without overdue mark: %+v", got)
+	}
+}
+
+func TestFindingExceptionWorkflow(t *testing.T) {
+	env := setupFindingsSLA(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	due := now.AddDate(0, 0, 10)
+	f := env.createFinding(t, store.Finding{Title: "Legacy cipher", Status: "in_progress", Severity: "high", Owner: env.analyst.Username, DueAt: &due})
+	params := map[string]string{"id": strconv.FormatInt(f.ID, 10)}
+	request := func(body map[string]any) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		env.handler.RequestException(rr, riskRequest(http.MethodPost, "/api/findings/"+params["id"]+"/exceptions", body, env.admin, []string{"admin"}, params))
+		return rr
+	}
+	expires := now.AddDate(0, 0, 30).Format("2006-01-02")
+	if rr := request(map[string]any{"approver_id": env.admin.ID, "justification": "vendor fix pending", "expires_at": expires}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected self approval to be rejected, got %d", rr.Code)
+	}
+	if rr := request(map[string]any{"approver_id": env.analyst.ID, "justification": "vendor fix pending", "expires_at": now.AddDate(2, 0, 0).Format("2006-01-02")}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected expiry beyond policy to be rejected, got %d", rr.Code)
+	}
+	if rr := request(map[string]any{"approver_id": env.analyst.ID, "justification": " ", "expires_at": expires}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing justification to be rejected, got %d", rr.Code)
+	}
+	rr := request(map[string]any{"approver_id": env.analyst.ID, "justification": "vendor fix pending", "expires_at": expires})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("request exception: %d %s", rr.Code, rr.Body.String())
+	}
+	var ex store.FindingException
+	_ = json.Unmarshal(rr.Body.Bytes(), &ex)
+	if rr := request(map[string]any{"approver_id": env.analyst.ID, "justification": "again", "expires_at": expires}); rr.Code != http.StatusConflict {
+		t.Fatalf("expected duplicate request to conflict, got %d", rr.Code)
+	}
+	if n := len(env.notificationsOf(t, env.analyst, notify.EventFindingException)); n != 1 {
+		t.Fatalf("expected approver notification, got %d", n)
+	}
+
+	exParams := map[string]string{"exception_id": strconv.FormatInt(ex.ID, 10)}
+	rr = httptest.NewRecorder()
+	env.handler.ApproveException(rr, riskRequest(http.MethodPost, "/api/findings/exceptions/x/approve", map[string]any{}, env.admin, []string{"admin"}, exParams))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected non-approver to be refused, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.ApproveException(rr, riskRequest(http.MethodPost, "/api/findings/exceptions/x/approve", map[string]any{"comment": "ok until patch"}, env.analyst, []string{"analyst"}, exParams))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", rr.Code, rr.Body.String())
+	}
+	paused, _ := env.findings.GetFinding(ctx, f.ID)
+	if paused.Status != "accepted_risk" || paused.SLAPausedAt == nil {
+		t.Fatalf("expected paused accepted risk: %+v", paused)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.RejectException(rr, riskRequest(http.MethodPost, "/api/findings/exceptions/x/reject", nil, env.analyst, []string{"analyst"}, exParams))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected decided exception to conflict, got %d", rr.Code)
+	}
+
+	// Expiry resumes the SLA with the remaining time preserved.
+	later := now.AddDate(0, 0, 31)
+	res, err := env.svc.Evaluate(ctx, later, "tester")
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if res.ExceptionsExpired != 1 || res.Overdue != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	resumed, _ := env.findings.GetFinding(ctx, f.ID)
+	if resumed.Status != "in_progress" || resumed.SLAPausedAt != nil || resumed.DueAt == nil {
+		t.Fatalf("expected resumed finding: %+v", resumed)
+	}
+	if left := resumed.DueAt.Sub(later).Hours() / 24; left < 9.9 || left > 10.1 {
+		t.Fatalf("expected ~10 days left after resume, got %.2f", left)
+	}
+	stored, _ := env.sla.GetFindingException(ctx, ex.ID)
+	if stored.Status != store.FindingExceptionExpired {
+		t.Fatalf("expected expired exception, got %s", stored.Status)
+	}
+
+	// A pending request can be revoked.
+	rr = request(map[string]any{"approver_id": env.analyst.ID, "justification": "second try", "expires_at": now.AddDate(0, 0, 60).Format("2006-01-02")})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("second request: %d %s", rr.Code, rr.Body.String())
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &ex)
+	rr = httptest.NewRecorder()
+	env.handler.RevokeException(rr, riskRequest(http.MethodDelete, "/api/findings/x/exceptions/y", nil, env.admin, []string{"admin"}, map[string]string{"id": params["id"], "exception_id": strconv.FormatInt(ex.ID, 10)}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", rr.Code, rr.Body.String())
+	}
+	pending, _ := env.sla.ListPendingFindingExceptions(ctx, env.analyst.ID)
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending exceptions, got %d", len(pending))
+	}
+}
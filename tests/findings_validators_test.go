@@ -105,6 +105,6 @@ func setupFindingsHandlerWithStore(t *testing.T) (*handlers.FindingsHandler, sto
 	}
 	policy := rbac.NewPolicy(rbac.DefaultRoles())
 	fs := store.NewFindingsStore(db)
-	h := handlers.NewFindingsHandler(fs, nil, nil, nil, nil, nil, store.NewAuditStore(db), policy)
+	h := handlers.NewFindingsHandler(fs, nil, nil, nil, nil, nil, nil, store.NewAuditStore(db), policy)
 	return h, fs, func() { _ = db.Close() }
 }
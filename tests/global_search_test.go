@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+type globalSearchEnv struct {
+	*assetGraphEnv
+	findings store.FindingsStore
+	handler  *handlers.SearchHandler
+}
+
+func newGlobalSearchEnv(t *testing.T) *globalSearchEnv {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.AppConfig{
+		DBPath:    filepath.Join(dir, "test.db"),
+		Incidents: config.IncidentsConfig{RegNoFormat: "INC-{year}-{seq:05}", StorageDir: filepath.Join(dir, "incidents")},
+		Docs:      config.DocsConfig{EncryptionKey: "0123456789abcdef0123456789abcdef"},
+	}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	incSvc, err := incidents.NewService(cfg, store.NewAuditStore(db))
+	if err != nil {
+		t.Fatalf("incidents service: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	incStore := store.NewIncidentsStore(db)
+	return &globalSearchEnv{
+		assetGraphEnv: &assetGraphEnv{cfg: cfg, users: users, incidents: incStore},
+		findings:      store.NewFindingsStore(db),
+		handler:       handlers.NewSearchHandler(cfg, store.NewSearchStore(db), users, nil, nil, incStore, incSvc, nil, rbac.NewPolicy(rbac.DefaultRoles())),
+	}
+}
+
+func (e *globalSearchEnv) createFinding(t *testing.T, title, description string) int64 {
+	t.Helper()
+	now := time.Now().UTC()
+	id, err := e.findings.CreateFinding(context.Background(), &store.Finding{Title: title, DescriptionMD: description, Severity: "medium", Status: "open", FindingType: "technical", CreatedAt: now, UpdatedAt: now, Version: 1})
+	if err != nil {
+		t.Fatalf("create finding: %v", err)
+	}
+	return id
+}
+
+func (e *globalSearchEnv) createIncident(t *testing.T, title string, owner *store.User) int64 {
+	t.Helper()
+	inc := &store.Incident{Title: title, Severity: "high", Status: "open", OwnerUserID: owner.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID, Version: 1}
+	acl := []store.ACLRule{{SubjectType: "user", SubjectID: strconv.FormatInt(owner.ID, 10), Permission: "manage"}}
+	id, err := e.incidents.CreateIncident(context.Background(), inc, nil, acl, e.cfg.Incidents.RegNoFormat)
+	if err != nil {
+		t.Fatalf("create incident: %v", err)
+	}
+	return id
+}
+
+func (e *globalSearchEnv) search(t *testing.T, user *store.User, roles []string, params url.Values) ([]store.SearchResult, *httptest.ResponseRecorder) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	e.handler.Search(rr, e.request("GET", "/api/search?"+params.Encode(), nil, nil, user, roles))
+	if rr.Code != http.StatusOK {
+		return nil, rr
+	}
+	var res struct {
+		Items []store.SearchResult `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return res.Items, rr
+}
+
+func TestGlobalSearchRankingAndSnippet(t *testing.T) {
+	env := newGlobalSearchEnv(t)
+	analyst := createObservablesUser(t, env.users, "search-analyst", []string{"analyst"})
+	inBody := env.createFinding(t, "Weak TLS settings", "The gateway still accepts legacy ciphers and an expired certificate on the portal.")
+	inTitle := env.createFinding(t, "Expired certificate on mail relay", "Renewal was missed.")
+	env.createFinding(t, "Open SMB share", "Anonymous access is allowed.")
+
+	items, rr := env.search(t, analyst, []string{"analyst"}, url.Values{"q": {"expired certificate"}, "types": {"finding"}})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("search: %d %s", rr.Code, rr.Body.String())
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", items)
+	}
+	if items[0].EntityID != inTitle || items[1].EntityID != inBody {
+		t.Fatalf("title match must rank first: %+v", items)
+	}
+	if !strings.Contains(strings.ToLower(items[1].Snippet), "expired certificate") {
+		t.Fatalf("snippet must show the match: %q", items[1].Snippet)
+	}
+}
+
+func TestGlobalSearchAccessChecks(t *testing.T) {
+	env := newGlobalSearchEnv(t)
+	owner := createObservablesUser(t, env.users, "search-owner", []string{"analyst"})
+	other := createObservablesUser(t, env.users, "search-other", []string{"analyst"})
+	viewer := createObservablesUser(t, env.users, "search-soc", []string{"soc_viewer"})
+	own := env.createIncident(t, "Phishing wave against finance", owner)
+	env.createIncident(t, "Phishing on partner portal", other)
+	env.createFinding(t, "Phishing simulation gaps", "")
+
+	items, _ := env.search(t, owner, []string{"analyst"}, url.Values{"q": {"phishing"}})
+	var incidentIDs []int64
+	findings := 0
+	for _, item := range items {
+		switch item.EntityType {
+		case store.SearchEntityIncident:
+			incidentIDs = append(incidentIDs, item.EntityID)
+		case store.SearchEntityFinding:
+			findings++
+		}
+	}
+	if len(incidentIDs) != 1 || incidentIDs[0] != own || findings != 1 {
+		t.Fatalf("owner must see own incident and the finding only: %+v", items)
+	}
+
+	items, _ = env.search(t, viewer, []string{"soc_viewer"}, url.Values{"q": {"phishing"}})
+	for _, item := range items {
+		if item.EntityType == store.SearchEntityFinding {
+			t.Fatalf("findings must be hidden without findings.view: %+v", items)
+		}
+	}
+
+	if _, rr := env.search(t, owner, []string{"analyst"}, url.Values{"q": {"p"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("short query must be rejected: %d", rr.Code)
+	}
+	if _, rr := env.search(t, owner, []string{"analyst"}, url.Values{"q": {"phishing"}, "types": {"secrets"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("unknown type must be rejected: %d", rr.Code)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestDeriveLifecycleFromTimeline(t *testing.T) {
+	created := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	inc := store.Incident{ID: 1, CreatedAt: created}
+	events := []store.IncidentTimelineEvent{
+		{EventType: "status.change", Message: "open -> in_progress", EventAt: created.Add(30 * time.Minute)},
+		{EventType: "status.change", Message: "in_progress -> contained", EventAt: created.Add(2 * time.Hour)},
+		{EventType: "status.change", Message: "contained -> resolved", EventAt: created.Add(5 * time.Hour)},
+	}
+	lc := incidents.DeriveLifecycle(inc, nil, events)
+	if lc.DetectedAt == nil || !lc.DetectedAt.Equal(created) {
+		t.Fatalf("detected should default to created_at, got %v", lc.DetectedAt)
+	}
+	if lc.AcknowledgedAt == nil || !lc.AcknowledgedAt.Equal(created.Add(30*time.Minute)) {
+		t.Fatalf("unexpected acknowledged: %v", lc.AcknowledgedAt)
+	}
+	if lc.ContainedAt == nil || !lc.ContainedAt.Equal(created.Add(2*time.Hour)) {
+		t.Fatalf("unexpected contained: %v", lc.ContainedAt)
+	}
+	if lc.ResolvedAt == nil || !lc.ResolvedAt.Equal(created.Add(5*time.Hour)) {
+		t.Fatalf("unexpected resolved: %v", lc.ResolvedAt)
+	}
+
+	explicit := created.Add(-time.Hour)
+	lc = incidents.DeriveLifecycle(inc, &store.IncidentImpact{DetectedAt: &explicit}, nil)
+	if !lc.DetectedAt.Equal(explicit) {
+		t.Fatalf("explicit detected_at must win")
+	}
+}
+
+func TestComputeIncidentMetricsBySeverity(t *testing.T) {
+	base := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	at := func(min int) *time.Time {
+		v := base.Add(time.Duration(min) * time.Minute)
+		return &v
+	}
+	records := []incidents.MetricsRecord{
+		{Incident: store.Incident{Severity: "high"}, Lifecycle: incidents.Lifecycle{StartedAt: at(-60), DetectedAt: at(0), AcknowledgedAt: at(10), ResolvedAt: at(120)}, Impact: store.IncidentImpact{DowntimeMinutes: 30, EstimatedCost: 100, CostCurrency: "USD"}},
+		{Incident: store.Incident{Severity: "high"}, Lifecycle: incidents.Lifecycle{DetectedAt: at(0), AcknowledgedAt: at(30), ResolvedAt: at(240)}, Impact: store.IncidentImpact{DowntimeMinutes: 15, EstimatedCost: 50, CostCurrency: "USD"}},
+		{Incident: store.Incident{Severity: "critical"}, Lifecycle: incidents.Lifecycle{DetectedAt: at(0)}},
+	}
+	report := incidents.ComputeMetrics(records, "severity", "")
+	if report.Overall.Incidents != 3 || report.Overall.MTTD.Count != 1 || report.Overall.MTTD.Mean != 60 {
+		t.Fatalf("unexpected overall: %+v", report.Overall)
+	}
+	if len(report.Groups) != 2 || report.Groups[0].Key != "critical" || report.Groups[1].Key != "high" {
+		t.Fatalf("unexpected groups: %+v", report.Groups)
+	}
+	high := report.Groups[1]
+	if high.MTTA.Mean != 20 || high.MTTR.Mean != 180 || high.MTTR.Count != 2 {
+		t.Fatalf("unexpected high bucket: %+v", high)
+	}
+	if high.DowntimeMinutes != 45 || high.EstimatedCost["USD"] != 150 {
+		t.Fatalf("unexpected impact totals: %+v", high)
+	}
+	if report.Groups[0].MTTR.Count != 0 {
+		t.Fatalf("unresolved incident must not count toward MTTR")
+	}
+}
+
+func TestIncidentStatusChangeStampsMilestones(t *testing.T) {
+	ctx, cfg, user, is, _, us, svc, _, cleanup := setupIncidents(t)
+	defer cleanup()
+	incident := createIncident(t, ctx, is, cfg, user)
+	h := handlers.NewIncidentsHandler(cfg, is, nil, nil, nil, nil, nil, nil, us, nil, rbac.NewPolicy(rbac.DefaultRoles()), svc, nil, nil, utils.NewLogger())
+
+	update := func(status string, version int) {
+		body, _ := json.Marshal(map[string]any{"status": status, "version": version})
+		req := observablesRequest("PUT", "/api/incidents/"+strconv.FormatInt(incident.ID, 10), incident.ID, body, user)
+		rr := httptest.NewRecorder()
+		h.Update(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("update %s: %d %s", status, rr.Code, rr.Body.String())
+		}
+	}
+	update("contained", 1)
+	impact, err := is.GetIncidentImpact(context.Background(), incident.ID)
+	if err != nil {
+		t.Fatalf("impact: %v", err)
+	}
+	if impact.AcknowledgedAt == nil || impact.ContainedAt == nil || impact.ResolvedAt != nil {
+		t.Fatalf("expected acknowledged+contained stamped, got %+v", impact)
+	}
+	firstContained := *impact.ContainedAt
+	update("in_progress", 2)
+	update("contained", 3)
+	impact, _ = is.GetIncidentImpact(context.Background(), incident.ID)
+	if !impact.ContainedAt.Equal(firstContained) {
+		t.Fatalf("milestone must not be overwritten")
+	}
+}
+
+func TestIncidentImpactUpdateAndMetricsAPI(t *testing.T) {
+	ctx, cfg, user, is, _, us, svc, _, cleanup := setupIncidents(t)
+	defer cleanup()
+	incident := createIncident(t, ctx, is, cfg, user)
+	h := handlers.NewIncidentsHandler(cfg, is, nil, nil, nil, nil, nil, nil, us, nil, rbac.NewPolicy(rbac.DefaultRoles()), svc, nil, nil, utils.NewLogger())
+
+	detected := time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Second)
+	bad, _ := json.Marshal(map[string]any{"detected_at": detected, "resolved_at": detected.Add(-time.Hour)})
+	rr := httptest.NewRecorder()
+	h.UpdateImpact(rr, observablesRequest("PUT", "/api/incidents/x/impact", incident.ID, bad, user))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected order validation, got %d", rr.Code)
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"detected_at":      detected,
+		"acknowledged_at":  detected.Add(15 * time.Minute),
+		"resolved_at":      detected.Add(2 * time.Hour),
+		"downtime_minutes": 40,
+		"affected_users":   120,
+		"estimated_cost":   2500.5,
+		"cost_currency":    "eur",
+	})
+	rr = httptest.NewRecorder()
+	h.UpdateImpact(rr, observablesRequest("PUT", "/api/incidents/x/impact", incident.ID, payload, user))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update impact: %d %s", rr.Code, rr.Body.String())
+	}
+
+	req := observablesRequest("GET", "/api/incidents/metrics?group_by=severity", incident.ID, nil, user)
+	rr = httptest.NewRecorder()
+	h.Metrics(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("metrics: %d %s", rr.Code, rr.Body.String())
+	}
+	var report incidents.MetricsReport
+	if err := json.NewDecoder(bytes.NewReader(rr.Body.Bytes())).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Overall.Incidents != 1 || report.Overall.MTTA.Mean != 15 || report.Overall.MTTR.Mean != 120 {
+		t.Fatalf("unexpected metrics: %+v", report.Overall)
+	}
+	if report.Overall.AffectedUsers != 120 || report.Overall.EstimatedCost["EUR"] != 2500.5 {
+		t.Fatalf("unexpected impact totals: %+v", report.Overall)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Metrics(rr, observablesRequest("GET", "/api/incidents/metrics?from=2000-01-01&to=2000-01-31", incident.ID, nil, user))
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil || report.Overall.Incidents != 0 {
+		t.Fatalf("period filter should exclude the incident: %v %+v", err, report.Overall)
+	}
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/docs"
+	"berkut-scc/core/incidents"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func setupObservables(t *testing.T) (*sql.DB, *config.AppConfig, *handlers.IncidentsHandler, store.IncidentsStore, store.UsersStore, store.ObservablesStore) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.AppConfig{
+		DBPath: filepath.Join(dir, "test.db"),
+		Incidents: config.IncidentsConfig{
+			RegNoFormat: "INC-{year}-{seq:05}",
+			StorageDir:  filepath.Join(dir, "incidents"),
+		},
+		Docs: config.DocsConfig{EncryptionKey: "0123456789abcdef0123456789abcdef"},
+	}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	audits := store.NewAuditStore(db)
+	svc, err := incidents.NewService(cfg, audits)
+	if err != nil {
+		t.Fatalf("incidents service: %v", err)
+	}
+	is := store.NewIncidentsStore(db)
+	us := store.NewUsersStore(db)
+	obs := store.NewObservablesStore(db)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	h := handlers.NewIncidentsHandler(cfg, is, nil, nil, store.NewAssetsStore(db), nil, store.NewFindingsStore(db), obs, us, nil, policy, svc, nil, audits, logger)
+	return db, cfg, h, is, us, obs
+}
+
+func createObservablesUser(t *testing.T, us store.UsersStore, username string, roles []string) *store.User {
+	t.Helper()
+	u := &store.User{Username: username, FullName: username, ClearanceLevel: int(docs.ClassificationInternal), PasswordHash: "hash", Salt: "salt", PasswordSet: true, Active: true}
+	id, err := us.Create(context.Background(), u, roles)
+	if err != nil {
+		t.Fatalf("user create: %v", err)
+	}
+	u.ID = id
+	return u
+}
+
+func observablesRequest(method, target string, incidentID int64, body []byte, user *store.User) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req = withURLParams(req, map[string]string{"id": strconv.FormatInt(incidentID, 10)})
+	return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: user.ID, Username: user.Username}))
+}
+
+func TestNormalizeObservable(t *testing.T) {
+	cases := []struct {
+		typ, in, want string
+		ok            bool
+	}{
+		{"ip", " 10.0.0.1 ", "10.0.0.1", true},
+		{"ip", "10[.]0[.]0[.]1", "10.0.0.1", true},
+		{"ip", "2001:DB8::0:1", "2001:db8::1", true},
+		{"ip", "10.0.0.256", "", false},
+		{"domain", "Evil[.]Example.COM.", "evil.example.com", true},
+		{"domain", "not a domain", "", false},
+		{"url", "hxxps://Evil.example.com/path?q=1", "https://evil.example.com/path?q=1", true},
+		{"url", "evil.example.com/path", "", false},
+		{"md5", "D41D8CD98F00B204E9800998ECF8427E", "d41d8cd98f00b204e9800998ecf8427e", true},
+		{"sha1", "d41d8cd98f00b204e9800998ecf8427e", "", false},
+		{"email", "Bad.Actor@Example.com", "bad.actor@example.com", true},
+		{"email", "Bad Actor <bad@example.com>", "", false},
+		{"cve", "cve-2021-44228", "CVE-2021-44228", true},
+		{"cve", "CVE-21-1", "", false},
+		{"username", "DOMAIN\\Admin", "domain\\admin", true},
+	}
+	for _, tc := range cases {
+		_, got, err := incidents.NormalizeObservable(tc.typ, tc.in)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Fatalf("%s %q: got %q err %v, want %q", tc.typ, tc.in, got, err, tc.want)
+		}
+		if !tc.ok && err == nil {
+			t.Fatalf("%s %q: expected error, got %q", tc.typ, tc.in, got)
+		}
+	}
+	if _, _, err := incidents.NormalizeObservable("mutex", "x"); err != incidents.ErrObservableType {
+		t.Fatalf("expected type error, got %v", err)
+	}
+	if lvl, err := incidents.NormalizeTLP("TLP:WHITE"); err != nil || lvl != "clear" {
+		t.Fatalf("tlp white: %q %v", lvl, err)
+	}
+	if _, err := incidents.NormalizeTLP("purple"); err == nil {
+		t.Fatalf("expected tlp error")
+	}
+}
+
+func TestObservableUpsertWidensSeenWindow(t *testing.T) {
+	_, cfg, _, is, us, obs := setupObservables(t)
+	ctx := context.Background()
+	owner := createObservablesUser(t, us, "owner", []string{"security_officer"})
+	incident := createIncident(t, ctx, is, cfg, owner)
+	t1 := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created, err := obs.UpsertObservable(ctx, &store.Observable{EntityType: "incident", EntityID: incident.ID, Type: "ip", Value: "10.0.0.1", TLP: "amber", FirstSeenAt: &t1, LastSeenAt: &t1})
+	if err != nil || !created {
+		t.Fatalf("first upsert: %v %v", created, err)
+	}
+	created, err = obs.UpsertObservable(ctx, &store.Observable{EntityType: "incident", EntityID: incident.ID, Type: "ip", Value: "10.0.0.1", TLP: "red", FirstSeenAt: &t0, LastSeenAt: &t2})
+	if err != nil || created {
+		t.Fatalf("second upsert should merge: %v %v", created, err)
+	}
+	items, _ := obs.ListObservables(ctx, "incident", incident.ID)
+	if len(items) != 1 {
+		t.Fatalf("expected single observable, got %d", len(items))
+	}
+	got := items[0]
+	if got.TLP != "red" || !got.FirstSeenAt.Equal(t0) || !got.LastSeenAt.Equal(t2) {
+		t.Fatalf("unexpected merge result: %+v", got)
+	}
+}
+
+func TestObservableCorrelationRespectsACL(t *testing.T) {
+	db, cfg, h, is, us, obs := setupObservables(t)
+	ctx := context.Background()
+	analyst := createObservablesUser(t, us, "analyst1", []string{"analyst"})
+	stranger := createObservablesUser(t, us, "stranger", []string{"security_officer"})
+	mine := createIncident(t, ctx, is, cfg, analyst)
+	other := createIncident(t, ctx, is, cfg, analyst)
+	hidden := createIncident(t, ctx, is, cfg, stranger)
+
+	fs := store.NewFindingsStore(db)
+	findingID, err := fs.CreateFinding(ctx, &store.Finding{Title: "Open RDP", Status: "open", Severity: "high", FindingType: "technical"})
+	if err != nil {
+		t.Fatalf("finding: %v", err)
+	}
+	assetID, err := store.NewAssetsStore(db).CreateAsset(ctx, &store.Asset{Name: "srv-01", Type: "host", Criticality: "high", Env: "prod", Status: "active", IPAddresses: []string{"10.0.0.1"}})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	add := func(entityType string, id int64) {
+		if _, err := obs.UpsertObservable(ctx, &store.Observable{EntityType: entityType, EntityID: id, Type: "ip", Value: "10.0.0.1", TLP: "amber"}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+	add("incident", mine.ID)
+	add("incident", other.ID)
+	add("incident", hidden.ID)
+	add("finding", findingID)
+
+	rr := httptest.NewRecorder()
+	h.CorrelateObservables(rr, observablesRequest("GET", "/api/incidents/x/observables/correlations", mine.ID, nil, analyst))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("correlations status: %d %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Items []struct {
+			Incidents []struct {
+				ID int64 `json:"id"`
+			} `json:"incidents"`
+			Findings []struct {
+				ID int64 `json:"id"`
+			} `json:"findings"`
+			Assets []struct {
+				ID        int64  `json:"id"`
+				MatchedBy string `json:"matched_by"`
+			} `json:"assets"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected one observable, got %d", len(resp.Items))
+	}
+	item := resp.Items[0]
+	if len(item.Incidents) != 1 || item.Incidents[0].ID != other.ID {
+		t.Fatalf("expected only the visible incident, got %+v", item.Incidents)
+	}
+	if len(item.Findings) != 1 || item.Findings[0].ID != findingID {
+		t.Fatalf("expected finding correlation, got %+v", item.Findings)
+	}
+	if len(item.Assets) != 1 || item.Assets[0].ID != assetID || item.Assets[0].MatchedBy != "ip_address" {
+		t.Fatalf("expected asset matched by ip, got %+v", item.Assets)
+	}
+}
+
+func TestObservablesCSVAndSTIXRoundTrip(t *testing.T) {
+	seen := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []store.Observable{
+		{Type: "ip", Value: "10.0.0.1", TLP: "red", FirstSeenAt: &seen},
+		{Type: "domain", Value: "evil.example.com", TLP: "green", Description: "C2"},
+		{Type: "sha256", Value: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", TLP: "amber"},
+		{Type: "cve", Value: "CVE-2021-44228", TLP: "clear"},
+		{Type: "email", Value: "bad@example.com", TLP: "amber"},
+	}
+	var buf bytes.Buffer
+	if err := incidents.WriteObservablesCSV(&buf, items); err != nil {
+		t.Fatalf("csv write: %v", err)
+	}
+	parsed, failures, err := incidents.ParseObservablesCSV(append(buf.Bytes(), []byte("ip,999.1.1.1,amber,,,\n")...))
+	if err != nil {
+		t.Fatalf("csv parse: %v", err)
+	}
+	if len(parsed) != len(items) || len(failures) != 1 || failures[0].Row != len(items)+2 {
+		t.Fatalf("csv round trip: parsed=%d failures=%+v", len(parsed), failures)
+	}
+	if parsed[0].FirstSeenAt == nil || !parsed[0].FirstSeenAt.Equal(seen) || parsed[1].Description != "C2" {
+		t.Fatalf("csv fields lost: %+v", parsed[:2])
+	}
+
+	raw, err := incidents.ExportSTIXBundle(items, seen)
+	if err != nil {
+		t.Fatalf("stix export: %v", err)
+	}
+	var bundle struct {
+		Type    string           `json:"type"`
+		Objects []map[string]any `json:"objects"`
+	}
+	if err := json.Unmarshal(raw, &bundle); err != nil || bundle.Type != "bundle" {
+		t.Fatalf("stix bundle: %v %s", err, bundle.Type)
+	}
+	back, failures, err := incidents.ParseSTIXBundle(raw)
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("stix parse: %v %+v", err, failures)
+	}
+	got := map[string]store.Observable{}
+	for _, o := range back {
+		got[o.Type+"|"+o.Value] = o
+	}
+	for _, o := range items {
+		b, ok := got[o.Type+"|"+o.Value]
+		if !ok {
+			t.Fatalf("stix lost %s %s", o.Type, o.Value)
+		}
+		if b.TLP != o.TLP {
+			t.Fatalf("stix tlp for %s: got %s want %s", o.Value, b.TLP, o.TLP)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"berkut-scc/core/notify"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+	"berkut-scc/tasks"
+	taskhttp "berkut-scc/tasks/http"
+)
+
+func TestParseMentions(t *testing.T) {
+	cases := map[string][]string{
+		"@Alice please check":                 {"alice"},
+		"cc @bob, @carol. and @bob again":     {"bob", "carol"},
+		"mail admin@example.com or @ops-team": {"ops-team"},
+		"(@dave) @":                           {"dave"},
+		"no mentions here":                    nil,
+	}
+	for text, want := range cases {
+		if got := notify.ParseMentions(text); !reflect.DeepEqual(got, want) {
+			t.Fatalf("ParseMentions(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestTaskCommentMentionsRespectBoardACL(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	logger := utils.NewLogger()
+	db := env.tasksStore.DB()
+	outsider := createObservablesUser(t, env.users, "mention-outsider", []string{"analyst"})
+
+	ns := store.NewNotificationsStore(db)
+	notifier := notify.NewService(ns, logger)
+	notifier.SetMentionStore(store.NewMentionsStore(db), env.users)
+	svc := tasks.NewService(env.tasksStore)
+	svc.SetNotifier(notifier)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	handler := taskhttp.NewHandler(env.cfg, svc, env.users, nil, nil, nil, nil, nil, nil, nil, nil, policy, store.NewAuditStore(db))
+	task := createTask(t, env, "Mentioned task")
+	taskID := strconv.FormatInt(task.ID, 10)
+
+	payload, _ := json.Marshal(map[string]string{"content": "@analyst1 and @mention-outsider, please look"})
+	req := withURLParams(authedRequest("POST", "/api/tasks/"+taskID+"/comments", payload, env.admin), map[string]string{"id": taskID})
+	rr := httptest.NewRecorder()
+	handler.AddComment(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("add comment: %d %s", rr.Code, rr.Body.String())
+	}
+	var comment tasks.Comment
+	_ = json.Unmarshal(rr.Body.Bytes(), &comment)
+	notifier.Wait()
+
+	mine, _ := notifier.Mentions().ListUserMentions(env.ctx, env.analyst.ID, 10, 0)
+	if len(mine) != 1 || mine[0].Link != "/tasks/task/"+taskID || mine[0].AuthorName != env.admin.Username {
+		t.Fatalf("unexpected mentions of board member: %+v", mine)
+	}
+	if list, _ := ns.ListNotifications(env.ctx, env.analyst.ID, store.NotificationFilter{EventType: notify.EventMention}); len(list) != 1 {
+		t.Fatalf("board member must be notified once: %+v", list)
+	}
+	if other, _ := notifier.Mentions().ListUserMentions(env.ctx, outsider.ID, 10, 0); len(other) != 0 {
+		t.Fatalf("user without board access must not be mentioned: %+v", other)
+	}
+	if list, _ := ns.ListNotifications(env.ctx, outsider.ID, store.NotificationFilter{}); len(list) != 0 {
+		t.Fatalf("user without board access must not be notified: %+v", list)
+	}
+
+	update := func(content string) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"content": content})
+		commentID := strconv.FormatInt(comment.ID, 10)
+		req := withURLParams(authedRequest("PUT", "/api/tasks/"+taskID+"/comments/"+commentID, body, env.admin), map[string]string{"id": taskID, "comment_id": commentID})
+		rr := httptest.NewRecorder()
+		handler.UpdateComment(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("update comment: %d %s", rr.Code, rr.Body.String())
+		}
+		notifier.Wait()
+	}
+	update("@analyst1 please look, updated")
+	if list, _ := ns.ListNotifications(env.ctx, env.analyst.ID, store.NotificationFilter{EventType: notify.EventMention}); len(list) != 1 {
+		t.Fatalf("re-saving an existing mention must not notify again: %+v", list)
+	}
+	update("nobody is mentioned now")
+	if mine, _ := notifier.Mentions().ListUserMentions(env.ctx, env.analyst.ID, 10, 0); len(mine) != 0 {
+		t.Fatalf("edit must remove dropped mentions: %+v", mine)
+	}
+}
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+type fakeExternalSender struct {
+	mu    sync.Mutex
+	sent  []string
+	chans []int64
+}
+
+func (f *fakeExternalSender) SendChannelMessage(ctx context.Context, channelID int64, eventType, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, eventType+"|"+text)
+	f.chans = append(f.chans, channelID)
+	return nil
+}
+
+func TestNotifyServiceDeliveryAndState(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	actor := createObservablesUser(t, users, "notify-actor", []string{"admin"})
+	target := createObservablesUser(t, users, "notify-target", []string{"analyst"})
+
+	ns := store.NewNotificationsStore(db)
+	svc := notify.NewService(ns, logger)
+	sender := &fakeExternalSender{}
+	svc.SetExternalSender(sender)
+	channelID := int64(7)
+	if err := ns.SaveNotificationPreferences(ctx, target.ID, []store.NotificationPreference{{EventType: notify.EventTaskAssigned, External: true, ChannelID: &channelID}}); err != nil {
+		t.Fatalf("prefs: %v", err)
+	}
+
+	events, cancel := svc.Subscribe(target.ID)
+	defer cancel()
+	sent := svc.Notify(ctx, store.Notification{EventType: notify.EventTaskAssigned, Title: "#1 Patch", Link: "/tasks/task/1", ActorID: &actor.ID}, actor.ID, target.ID, target.ID)
+	if sent != 1 {
+		t.Fatalf("expected one delivery (actor skipped, duplicates merged), got %d", sent)
+	}
+	select {
+	case ev := <-events:
+		if ev.Kind != notify.KindNotification || ev.Notification == nil || ev.Notification.UserID != target.ID || ev.Unread != 1 {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no stream event")
+	}
+	svc.Wait()
+	if len(sender.sent) != 1 || sender.chans[0] != channelID || sender.sent[0] != "user.task.assigned|#1 Patch" {
+		t.Fatalf("unexpected external delivery: %+v %+v", sender.sent, sender.chans)
+	}
+	svc.Notify(ctx, store.Notification{EventType: notify.EventIncidentAssigned, Title: "INC-1", Body: "Severity: high",
+		BodyKey: "notifications.incident.severity", Params: map[string]string{"severity": "high"}}, target.ID)
+	svc.Wait()
+	if len(sender.sent) != 1 {
+		t.Fatalf("event without external preference must stay in-app: %+v", sender.sent)
+	}
+	if list, _ := ns.ListNotifications(ctx, actor.ID, store.NotificationFilter{}); len(list) != 0 {
+		t.Fatalf("actor must not be notified: %+v", list)
+	}
+
+	list, _ := ns.ListNotifications(ctx, target.ID, store.NotificationFilter{})
+	if len(list) != 2 || list[0].EventType != notify.EventIncidentAssigned {
+		t.Fatalf("unexpected inbox: %+v", list)
+	}
+	if list[0].BodyKey != "notifications.incident.severity" || list[0].Params["severity"] != "high" || list[1].BodyKey != "" || list[1].Params != nil {
+		t.Fatalf("i18n key and params must round-trip: %+v", list)
+	}
+	if n, _ := ns.MarkNotificationsRead(ctx, target.ID, []int64{list[1].ID}, time.Now()); n != 1 {
+		t.Fatalf("mark read: %d", n)
+	}
+	if unread, _ := ns.CountUnreadNotifications(ctx, target.ID); unread != 1 {
+		t.Fatalf("expected 1 unread, got %d", unread)
+	}
+	if n, _ := ns.DismissNotifications(ctx, actor.ID, nil, time.Now()); n != 0 {
+		t.Fatalf("dismiss must be scoped to the user, changed %d", n)
+	}
+	if n, _ := ns.DismissNotifications(ctx, target.ID, nil, time.Now()); n != 2 {
+		t.Fatalf("dismiss all: %d", n)
+	}
+	if list, _ := ns.ListNotifications(ctx, target.ID, store.NotificationFilter{}); len(list) != 0 {
+		t.Fatalf("dismissed items must leave the inbox: %+v", list)
+	}
+}
+
+func TestNotificationsHandlers(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	user := createObservablesUser(t, users, "notify-user", []string{"analyst"})
+	monitoring := store.NewMonitoringStore(db)
+	chID, err := monitoring.CreateNotificationChannel(ctx, &store.NotificationChannel{Type: "telegram", Name: "SOC", TelegramBotTokenEnc: []byte("enc"), TelegramChatID: "1", IsActive: true, CreatedBy: user.ID})
+	if err != nil {
+		t.Fatalf("channel: %v", err)
+	}
+	svc := notify.NewService(store.NewNotificationsStore(db), logger)
+	h := handlers.NewNotificationsHandler(svc, monitoring, users, store.NewAuditStore(db), rbac.NewPolicy(rbac.DefaultRoles()))
+	svc.Notify(ctx, store.Notification{EventType: notify.EventMention, Title: "first"}, user.ID)
+	svc.Notify(ctx, store.Notification{EventType: notify.EventMention, Title: "second"}, user.ID)
+
+	roles := []string{"analyst"}
+	call := func(fn http.HandlerFunc, method, target string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, target, &buf)
+		req = req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: user.ID, Username: user.Username, Roles: roles}))
+		rr := httptest.NewRecorder()
+		fn(rr, req)
+		return rr
+	}
+
+	rr := call(h.List, "GET", "/api/notifications", nil)
+	var listResp struct {
+		Items  []store.Notification `json:"items"`
+		Unread int                  `json:"unread"`
+	}
+	if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &listResp) != nil || len(listResp.Items) != 2 || listResp.Unread != 2 {
+		t.Fatalf("list: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = call(h.MarkRead, "POST", "/api/notifications/read", map[string]any{"ids": []int64{listResp.Items[0].ID}})
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"unread":1`)) {
+		t.Fatalf("mark read: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = call(h.List, "GET", "/api/notifications?unread=1", nil)
+	if json.Unmarshal(rr.Body.Bytes(), &listResp) != nil || len(listResp.Items) != 1 || listResp.Items[0].Title != "first" {
+		t.Fatalf("unread list: %s", rr.Body.String())
+	}
+
+	rr = call(h.UpdatePreferences, "PUT", "/api/notifications/preferences", map[string]any{"preferences": []map[string]any{{"event_type": "bogus"}}})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown event to be rejected, got %d", rr.Code)
+	}
+	rr = call(h.UpdatePreferences, "PUT", "/api/notifications/preferences", map[string]any{"preferences": []map[string]any{{"event_type": notify.EventMention, "external": true, "channel_id": chID + 100}}})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown channel to be rejected, got %d", rr.Code)
+	}
+	rr = call(h.UpdatePreferences, "PUT", "/api/notifications/preferences", map[string]any{"preferences": []map[string]any{{"event_type": notify.EventMention, "external": true, "channel_id": chID}}})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected shared channel to be rejected without notification management, got %d", rr.Code)
+	}
+	roles = []string{"soc_operator"}
+	rr = call(h.UpdatePreferences, "PUT", "/api/notifications/preferences", map[string]any{"preferences": []map[string]any{{"event_type": notify.EventMention, "external": true, "channel_id": chID}}})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("save prefs: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = call(h.GetPreferences, "GET", "/api/notifications/preferences", nil)
+	var prefsResp struct {
+		Preferences []store.NotificationPreference `json:"preferences"`
+		Channels    []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"channels"`
+	}
+	if json.Unmarshal(rr.Body.Bytes(), &prefsResp) != nil || len(prefsResp.Preferences) != 1 || len(prefsResp.Channels) != 1 || prefsResp.Channels[0].Name != "SOC" {
+		t.Fatalf("get prefs: %s", rr.Body.String())
+	}
+
+	rr = call(h.Dismiss, "POST", "/api/notifications/dismiss", map[string]any{"ids": []int64{}})
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"changed":2`)) {
+		t.Fatalf("dismiss all: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestNotificationsStreamReplaysMissed(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	user := createObservablesUser(t, store.NewUsersStore(db), "notify-stream", []string{"analyst"})
+	ns := store.NewNotificationsStore(db)
+	svc := notify.NewService(ns, logger)
+	h := handlers.NewNotificationsHandler(svc, nil, nil, nil, nil)
+	svc.Notify(ctx, store.Notification{EventType: notify.EventMention, Title: "seen"}, user.ID)
+	seen, _ := ns.ListNotifications(ctx, user.ID, store.NotificationFilter{})
+	svc.Notify(ctx, store.Notification{EventType: notify.EventMention, Title: "missed"}, user.ID)
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	req := httptest.NewRequest("GET", "/api/notifications/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	req = req.WithContext(context.WithValue(reqCtx, auth.SessionContextKey, &store.SessionRecord{UserID: user.ID, Username: user.Username}))
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		h.Stream(rr, req)
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+	body := rr.Body.String()
+	if rr.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", rr.Header().Get("Content-Type"))
+	}
+	if len(seen) != 1 || bytes.Contains([]byte(body), []byte(`"title":"seen"`)) || !bytes.Contains([]byte(body), []byte(`"title":"missed"`)) || !bytes.Contains([]byte(body), []byte("event: sync")) {
+		t.Fatalf("unexpected stream body: %s", body)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -35,6 +36,7 @@ type reportEnv struct {
 	reports    store.ReportsStore
 	monitoring store.MonitoringStore
 	incidents  store.IncidentsStore
+	db         *sql.DB
 	cleanup    func()
 }
 
@@ -103,7 +105,7 @@ func setupReportsBuilder(t *testing.T) reportEnv {
 		t.Fatalf("inc svc: %v", err)
 	}
 	taskSvc := tasks.NewService(taskstore.NewStore(db))
-	handler := handlers.NewReportsHandler(cfg, docsStore, reportsStore, users, policy, docsSvc, incStore, incSvc, ctrlStore, monStore, taskSvc, audits, logger)
+	handler := handlers.NewReportsHandler(cfg, docsStore, reportsStore, users, policy, docsSvc, incStore, incSvc, ctrlStore, monStore, taskSvc, nil, nil, nil, audits, logger)
 	return reportEnv{
 		cfg:        cfg,
 		user:       u,
@@ -114,6 +116,7 @@ func setupReportsBuilder(t *testing.T) reportEnv {
 		reports:    reportsStore,
 		monitoring: monStore,
 		incidents:  incStore,
+		db:         db,
 		cleanup:    func() { db.Close() },
 	}
 }
@@ -8,11 +8,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"berkut-scc/api/handlers"
 	"berkut-scc/core/auth"
 	"berkut-scc/core/docs"
+	"berkut-scc/core/rbac"
 	"berkut-scc/core/reports/charts"
 	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
 )
 
 func TestReportChartsUpdateRequiresEdit(t *testing.T) {
@@ -67,3 +71,72 @@ func TestReportChartsUpdateRequiresEdit(t *testing.T) {
 		t.Fatalf("expected charts update to be denied")
 	}
 }
+
+func TestReportRiskMatrixChartRendersPNG(t *testing.T) {
+	env := setupReportsBuilder(t)
+	defer env.cleanup()
+	ctx := context.Background()
+	risks := store.NewRisksStore(env.db)
+	handler := handlers.NewReportsHandler(env.cfg, env.docs, env.reports, env.users, rbac.NewPolicy(rbac.DefaultRoles()), env.docsSvc, env.incidents, nil, nil, env.monitoring, nil, nil, risks, nil, store.NewAuditStore(env.db), utils.NewLogger())
+	for _, r := range []store.Risk{
+		{Title: "Outage", Status: "open", ResidualLikelihood: 1, ResidualImpact: 1},
+		{Title: "Leak", Status: "open", ResidualLikelihood: 3, ResidualImpact: 2},
+		{Title: "Fraud", Status: "open", ResidualLikelihood: 3, ResidualImpact: 2},
+	} {
+		r := r
+		if _, err := risks.CreateRisk(ctx, &r); err != nil {
+			t.Fatalf("risk: %v", err)
+		}
+	}
+	report := createScheduledReport(t, env)
+	from, to := time.Now().UTC().AddDate(0, -1, 0), time.Now().UTC()
+	if err := env.reports.UpsertReportMeta(ctx, &store.ReportMeta{DocID: report.ID, Status: "draft", PeriodFrom: &from, PeriodTo: &to}); err != nil {
+		t.Fatalf("report meta: %v", err)
+	}
+	// Only one risk is listed, the matrix still counts all of them.
+	sections := []store.ReportSection{{SectionType: "risks", Title: "Risks", IsEnabled: true, Config: map[string]any{"limit": 1}}}
+	if err := env.reports.ReplaceReportSections(ctx, report.ID, sections); err != nil {
+		t.Fatalf("sections: %v", err)
+	}
+	if err := env.reports.ReplaceReportCharts(ctx, report.ID, []store.ReportChart{{ChartType: "risks_matrix_heatmap", SectionType: "risks", IsEnabled: true}}); err != nil {
+		t.Fatalf("charts: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	handler.Build(rr, scheduleRequest(env, http.MethodPost, "/api/reports/1/build", report.ID, map[string]any{"reason": "build", "mode": "replace"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("build: %d %s", rr.Code, rr.Body.String())
+	}
+	snaps, _ := env.reports.ListReportSnapshots(ctx, report.ID)
+	if len(snaps) == 0 {
+		t.Fatalf("expected snapshot")
+	}
+	snap, items, err := env.reports.GetReportSnapshot(ctx, snaps[0].ID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	data, err := charts.BuildChart(store.ReportChart{ChartType: "risks_matrix_heatmap"}, snap, items, "en")
+	if err != nil || len(data.Series) == 0 {
+		t.Fatalf("unexpected matrix chart: %+v %v", data, err)
+	}
+	total := 0.0
+	for _, s := range data.Series {
+		for _, v := range s.Values {
+			total += v
+		}
+	}
+	if total != 3 {
+		t.Fatalf("expected all risks in matrix, got %.0f: %+v", total, data.Series)
+	}
+
+	list, _ := env.reports.ListReportCharts(ctx, report.ID)
+	if len(list) != 1 {
+		t.Fatalf("expected chart")
+	}
+	req := scheduleRequest(env, http.MethodGet, "/api/reports/1/charts/1/render?format=png", 0, nil)
+	req = withURLParams(req, map[string]string{"id": fmt.Sprintf("%d", report.ID), "chart_id": fmt.Sprintf("%d", list[0].ID)})
+	rr = httptest.NewRecorder()
+	handler.RenderChart(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "image/png" || !bytes.HasPrefix(rr.Body.Bytes(), []byte("\x89PNG")) {
+		t.Fatalf("expected png render: %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/reports/charts"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestReportBuildInventorySections(t *testing.T) {
+	env := setupReportsBuilder(t)
+	ctx := context.Background()
+	assets := store.NewAssetsStore(env.db)
+	software := store.NewSoftwareStore(env.db)
+	controls := store.NewControlsStore(env.db)
+	findings := store.NewFindingsStore(env.db)
+	handler := handlers.NewReportsHandler(env.cfg, env.docs, env.reports, env.users, rbac.NewPolicy(rbac.DefaultRoles()), env.docsSvc, env.incidents, nil, controls, env.monitoring, nil, nil, nil, findings, store.NewAuditStore(env.db), utils.NewLogger())
+	handler.SetInventory(assets, software)
+
+	var assetIDs []int64
+	for _, a := range []store.Asset{
+		{Name: "db-1", Type: "host", Criticality: "critical", Env: "prod", Status: "active"},
+		{Name: "web-1", Type: "service", Criticality: "high", Env: "prod", Status: "active"},
+		{Name: "dev-1", Type: "host", Criticality: "low", Env: "dev", Status: "active"},
+	} {
+		a := a
+		id, err := assets.CreateAsset(ctx, &a)
+		if err != nil {
+			t.Fatalf("asset: %v", err)
+		}
+		assetIDs = append(assetIDs, id)
+	}
+	nginxID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "nginx", Vendor: "F5"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	psqlID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "PostgreSQL"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	for _, inst := range []store.AssetSoftwareInstallation{
+		{AssetID: assetIDs[0], ProductID: psqlID, VersionText: "15"},
+		{AssetID: assetIDs[1], ProductID: nginxID, VersionText: "1.24"},
+		{AssetID: assetIDs[2], ProductID: nginxID, VersionText: "1.25"},
+	} {
+		inst := inst
+		if _, err := software.AddAssetSoftware(ctx, &inst); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	}
+
+	fwID, err := controls.CreateFramework(ctx, &store.ControlFramework{Name: "ISO 27001", Version: "2022", IsActive: true})
+	if err != nil {
+		t.Fatalf("framework: %v", err)
+	}
+	var reqIDs []int64
+	for _, code := range []string{"A.5.1", "A.5.2", "A.5.3", "A.5.4"} {
+		id, err := controls.CreateFrameworkItem(ctx, &store.ControlFrameworkItem{FrameworkID: fwID, Code: code, Title: "Requirement " + code})
+		if err != nil {
+			t.Fatalf("framework item: %v", err)
+		}
+		reqIDs = append(reqIDs, id)
+	}
+	for i, status := range []string{"implemented", "partial", "not_applicable"} {
+		c := &store.Control{Code: fmt.Sprintf("C-%d", i+1), Title: "Control", ControlType: "technical", Status: status, RiskLevel: "medium", CreatedBy: env.user.ID, IsActive: true}
+		if _, err := controls.CreateControl(ctx, c); err != nil {
+			t.Fatalf("control: %v", err)
+		}
+		if _, err := controls.AddFrameworkMap(ctx, &store.ControlFrameworkMap{FrameworkItemID: reqIDs[i], ControlID: c.ID}); err != nil {
+			t.Fatalf("map: %v", err)
+		}
+	}
+
+	for _, f := range []store.Finding{
+		{Title: "Old critical", Severity: "critical", Status: "open"},
+		{Title: "New critical", Severity: "critical", Status: "open"},
+		{Title: "Old low", Severity: "low", Status: "open"},
+	} {
+		f := f
+		id, err := findings.CreateFinding(ctx, &f)
+		if err != nil {
+			t.Fatalf("finding: %v", err)
+		}
+		if strings.HasPrefix(f.Title, "Old") {
+			if _, err := env.db.ExecContext(ctx, `UPDATE findings SET created_at=? WHERE id=?`, time.Now().UTC().AddDate(0, 0, -40), id); err != nil {
+				t.Fatalf("age finding: %v", err)
+			}
+		}
+	}
+
+	report := createScheduledReport(t, env)
+	from, to := time.Now().UTC().AddDate(0, -1, 0), time.Now().UTC()
+	if err := env.reports.UpsertReportMeta(ctx, &store.ReportMeta{DocID: report.ID, Status: "draft", PeriodFrom: &from, PeriodTo: &to}); err != nil {
+		t.Fatalf("report meta: %v", err)
+	}
+	sections := []store.ReportSection{
+		{SectionType: "findings", Title: "Findings", IsEnabled: true, Config: map[string]any{"severity": "critical, high", "min_age_days": 30}},
+		{SectionType: "assets", Title: "Assets", IsEnabled: true, Config: map[string]any{"env": "prod"}},
+		{SectionType: "software", Title: "Software", IsEnabled: true},
+		{SectionType: "framework_coverage", Title: "Coverage", IsEnabled: true},
+	}
+	if err := env.reports.ReplaceReportSections(ctx, report.ID, sections); err != nil {
+		t.Fatalf("sections: %v", err)
+	}
+	req := scheduleRequest(env, http.MethodPost, "/api/reports/1/build", report.ID, map[string]any{"reason": "build", "mode": "replace"})
+	rr := httptest.NewRecorder()
+	handler.Build(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("build: %d %s", rr.Code, rr.Body.String())
+	}
+	snaps, _ := env.reports.ListReportSnapshots(ctx, report.ID)
+	if len(snaps) == 0 {
+		t.Fatalf("expected snapshot")
+	}
+	snap, items, err := env.reports.GetReportSnapshot(ctx, snaps[0].ID)
+	if err != nil || snap == nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	byType := map[string][]store.ReportSnapshotItem{}
+	for _, it := range items {
+		byType[it.EntityType] = append(byType[it.EntityType], it)
+	}
+	if got := byType["finding"]; len(got) != 1 || got[0].Entity["title"] != "Old critical" {
+		t.Fatalf("unexpected findings: %+v", got)
+	}
+	if got := byType["asset"]; len(got) != 2 {
+		t.Fatalf("unexpected assets: %+v", got)
+	}
+	if got := byType["software"]; len(got) != 2 || got[0].Entity["name"] != "nginx" || got[0].Entity["installations"] != float64(2) || got[0].Entity["assets"] != float64(2) {
+		t.Fatalf("unexpected software: %+v", got)
+	}
+	fw := byType["framework"]
+	if len(fw) != 1 || fw[0].Entity["covered"] != float64(1) || fw[0].Entity["partial"] != float64(1) || fw[0].Entity["unmapped"] != float64(1) || fw[0].Entity["not_applicable"] != float64(1) {
+		t.Fatalf("unexpected framework coverage: %+v", fw)
+	}
+	if fw[0].Entity["coverage_pct"] != 33.3 {
+		t.Fatalf("unexpected coverage pct: %v", fw[0].Entity["coverage_pct"])
+	}
+	if got := byType["framework_gap"]; len(got) != 2 || got[0].Entity["code"] != "A.5.2" || got[0].Entity["state"] != "partial" {
+		t.Fatalf("unexpected gaps: %+v", got)
+	}
+
+	// Charts are rebuilt from the stored snapshot items.
+	data, err := charts.BuildChart(store.ReportChart{ChartType: "assets_criticality_bar"}, snap, items, "en")
+	if err != nil || len(data.Values) != 4 || data.Values[0] != 1 || data.Values[1] != 1 || data.Values[3] != 0 {
+		t.Fatalf("unexpected assets chart: %+v %v", data, err)
+	}
+	data, err = charts.BuildChart(store.ReportChart{ChartType: "framework_coverage_bar"}, snap, items, "en")
+	if err != nil || len(data.Labels) != 1 || data.Labels[0] != "ISO 27001 2022" || data.Values[0] != 33.3 {
+		t.Fatalf("unexpected coverage chart: %+v %v", data, err)
+	}
+}
@@ -0,0 +1,347 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/docs"
+	"berkut-scc/core/notify"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func createScheduledReport(t *testing.T, env reportEnv) *store.Document {
+	t.Helper()
+	ctx := context.Background()
+	doc := &store.Document{
+		Title:               "Monthly Security Report",
+		Status:              docs.StatusDraft,
+		ClassificationLevel: int(docs.ClassificationInternal),
+		DocType:             "report",
+		CreatedBy:           env.user.ID,
+	}
+	acl := []store.ACLRule{
+		{SubjectType: "user", SubjectID: env.user.Username, Permission: "view"},
+		{SubjectType: "user", SubjectID: env.user.Username, Permission: "edit"},
+	}
+	id, err := env.docs.CreateDocument(ctx, doc, acl, env.cfg.Docs.RegTemplate, env.cfg.Docs.PerFolderSequence)
+	if err != nil {
+		t.Fatalf("create doc: %v", err)
+	}
+	doc.ID = id
+	if err := env.reports.UpsertReportMeta(ctx, &store.ReportMeta{DocID: id, Status: "draft"}); err != nil {
+		t.Fatalf("report meta: %v", err)
+	}
+	sections := []store.ReportSection{
+		{SectionType: "custom_md", Title: "Summary", IsEnabled: true, Config: map[string]any{"key": "summary", "markdown": "Monthly summary"}},
+	}
+	if err := env.reports.ReplaceReportSections(ctx, id, sections); err != nil {
+		t.Fatalf("sections: %v", err)
+	}
+	v, err := env.docsSvc.SaveVersion(ctx, docs.SaveRequest{Doc: doc, Author: env.user, Format: docs.FormatMarkdown, Content: []byte("initial"), Reason: "init"})
+	if err != nil {
+		t.Fatalf("save init: %v", err)
+	}
+	doc.CurrentVersion = v.Version
+	_ = env.docs.UpdateDocument(ctx, doc)
+	return doc
+}
+
+func scheduleRequest(env reportEnv, method, target string, id int64, payload any) *http.Request {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if id > 0 {
+		req = withURLParams(req, map[string]string{"id": fmt.Sprintf("%d", id)})
+	}
+	return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: env.user.ID, Username: env.user.Username}))
+}
+
+func TestReportScheduleRunDeliversReport(t *testing.T) {
+	env := setupReportsBuilder(t)
+	ctx := context.Background()
+	env.docsSvc.SetNotifier(notify.NewService(store.NewNotificationsStore(env.db), utils.NewLogger()))
+	analyst := createObservablesUser(t, env.users, "schedule-analyst", []string{"analyst"})
+	report := createScheduledReport(t, env)
+
+	rr := httptest.NewRecorder()
+	env.handler.CreateSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules", 0, map[string]any{
+		"name":            "Monthly security",
+		"report_id":       report.ID,
+		"period":          "last_month",
+		"cron":            "0 7 1 * *",
+		"format":          "md",
+		"recipient_users": []int64{analyst.ID},
+	}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create schedule: %d %s", rr.Code, rr.Body.String())
+	}
+	var sch store.ReportSchedule
+	_ = json.Unmarshal(rr.Body.Bytes(), &sch)
+	if sch.RRule == "" || sch.NextRunAt == nil || sch.NextRunAt.Day() != 1 || sch.NextRunAt.Hour() != 7 || sch.Language != "en" {
+		t.Fatalf("unexpected schedule: %+v", sch)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.RunSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules/1/run", sch.ID, nil))
+	var run store.ReportScheduleRun
+	_ = json.Unmarshal(rr.Body.Bytes(), &run)
+	if rr.Code != http.StatusOK || run.Status != "success" {
+		t.Fatalf("run: %d %s", rr.Code, rr.Body.String())
+	}
+	now := time.Now().UTC()
+	wantFrom := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	if run.PeriodFrom == nil || !run.PeriodFrom.Equal(wantFrom) || run.PeriodTo == nil || !run.PeriodTo.Equal(wantFrom.AddDate(0, 1, -1)) {
+		t.Fatalf("unexpected period: %v - %v", run.PeriodFrom, run.PeriodTo)
+	}
+	if run.SnapshotID == nil || run.OutputDocID == nil || run.OutputVersion != 1 || run.Delivered != 1 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	meta, _ := env.reports.GetReportMeta(ctx, report.ID)
+	if meta == nil || meta.PeriodFrom == nil || !meta.PeriodFrom.Equal(wantFrom) {
+		t.Fatalf("report period not updated: %+v", meta)
+	}
+	out, _ := env.docs.GetDocument(ctx, *run.OutputDocID)
+	if out == nil || out.CurrentVersion != 1 {
+		t.Fatalf("output doc: %+v", out)
+	}
+	ver, err := env.docs.GetVersion(ctx, out.ID, 1)
+	if err != nil || ver == nil || ver.Format != docs.FormatMarkdown {
+		t.Fatalf("output version: %+v %v", ver, err)
+	}
+	outACL, _ := env.docs.GetDocACL(ctx, out.ID)
+	if !env.docsSvc.CheckACL(analyst, []string{"analyst"}, out, outACL, nil, "export") {
+		t.Fatalf("recipient cannot export output")
+	}
+	items, _ := store.NewNotificationsStore(env.db).ListNotifications(ctx, analyst.ID, store.NotificationFilter{})
+	if len(items) != 1 || items[0].EventType != notify.EventReportDelivered || items[0].Link != fmt.Sprintf("/docs/%d", out.ID) {
+		t.Fatalf("unexpected notifications: %+v", items)
+	}
+	if items[0].TitleKey != "notifications.report.delivered" || items[0].Params["version"] != "1" || items[0].Params["name"] == "" {
+		t.Fatalf("expected i18n key with params: %+v", items[0])
+	}
+
+	// A second run adds a version to the same output document and keeps the
+	// access granted by hand in the meantime.
+	manual := store.ACLRule{SubjectType: "role", SubjectID: "auditor", Permission: "view"}
+	outACL = append(outACL, manual)
+	if err := env.docs.SetDocACL(ctx, out.ID, outACL); err != nil {
+		t.Fatalf("set acl: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.RunSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules/1/run", sch.ID, nil))
+	_ = json.Unmarshal(rr.Body.Bytes(), &run)
+	if run.Status != "success" || run.OutputDocID == nil || *run.OutputDocID != out.ID || run.OutputVersion != 2 {
+		t.Fatalf("second run: %+v", run)
+	}
+	if rerun, _ := env.docs.GetDocACL(ctx, out.ID); !slices.Contains(rerun, manual) || len(rerun) != len(outACL) {
+		t.Fatalf("rerun must keep the acl and not duplicate rules: %+v", rerun)
+	}
+
+	runs, _ := env.reports.ListReportScheduleRuns(ctx, sch.ID, 10)
+	if len(runs) != 2 || runs[0].Trigger != "manual" || runs[0].TriggeredBy != env.user.Username {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+}
+
+func TestReportScheduleRevokesRemovedRecipients(t *testing.T) {
+	env := setupReportsBuilder(t)
+	ctx := context.Background()
+	analyst := createObservablesUser(t, env.users, "schedule-former", []string{"analyst"})
+	extra := createObservablesUser(t, env.users, "schedule-extra", []string{"analyst"})
+	report := createScheduledReport(t, env)
+
+	rr := httptest.NewRecorder()
+	env.handler.CreateSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules", 0, map[string]any{
+		"name":            "Weekly exposure",
+		"report_id":       report.ID,
+		"period":          "last_week",
+		"rrule":           "FREQ=WEEKLY;BYDAY=MO",
+		"format":          "md",
+		"recipient_users": []int64{analyst.ID},
+	}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create schedule: %d %s", rr.Code, rr.Body.String())
+	}
+	var created store.ReportSchedule
+	_ = json.Unmarshal(rr.Body.Bytes(), &created)
+	run := func(recipients []int64) []store.ACLRule {
+		t.Helper()
+		sch, _ := env.reports.GetReportSchedule(ctx, created.ID)
+		sch.RecipientUsers = recipients
+		if err := env.reports.UpdateReportSchedule(ctx, sch); err != nil {
+			t.Fatalf("update schedule: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		env.handler.RunSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules/1/run", sch.ID, nil))
+		var res store.ReportScheduleRun
+		_ = json.Unmarshal(rr.Body.Bytes(), &res)
+		if res.Status != "success" || res.OutputDocID == nil {
+			t.Fatalf("run: %d %s", rr.Code, rr.Body.String())
+		}
+		acl, _ := env.docs.GetDocACL(ctx, *res.OutputDocID)
+		return acl
+	}
+	rule := func(u *store.User, perm string) store.ACLRule {
+		return store.ACLRule{SubjectType: "user", SubjectID: u.Username, Permission: perm}
+	}
+
+	acl := run([]int64{analyst.ID})
+	if !slices.Contains(acl, rule(analyst, "view")) || !slices.Contains(acl, rule(analyst, "export")) {
+		t.Fatalf("recipient not granted: %+v", acl)
+	}
+	// Access granted by hand before the user becomes a recipient stays theirs.
+	sch, _ := env.reports.GetReportSchedule(ctx, created.ID)
+	manual := []store.ACLRule{rule(extra, "view"), {SubjectType: "role", SubjectID: "auditor", Permission: "view"}}
+	if err := env.docs.SetDocACL(ctx, *sch.OutputDocID, append(acl, manual...)); err != nil {
+		t.Fatalf("set acl: %v", err)
+	}
+
+	acl = run([]int64{extra.ID})
+	if slices.Contains(acl, rule(analyst, "view")) || slices.Contains(acl, rule(analyst, "export")) {
+		t.Fatalf("removed recipient keeps access: %+v", acl)
+	}
+	if !slices.Contains(acl, rule(extra, "view")) || !slices.Contains(acl, rule(extra, "export")) || !slices.Contains(acl, manual[1]) {
+		t.Fatalf("unexpected acl after recipient change: %+v", acl)
+	}
+
+	acl = run(nil)
+	if slices.Contains(acl, rule(extra, "export")) {
+		t.Fatalf("granted export must be revoked: %+v", acl)
+	}
+	if !slices.Contains(acl, rule(extra, "view")) || !slices.Contains(acl, manual[1]) || !slices.Contains(acl, rule(env.user, "manage")) {
+		t.Fatalf("manual and owner rules must be kept: %+v", acl)
+	}
+}
+
+func TestReportScheduleFailedRunIsLogged(t *testing.T) {
+	env := setupReportsBuilder(t)
+	ctx := context.Background()
+	report := createScheduledReport(t, env)
+	rr := httptest.NewRecorder()
+	env.handler.CreateSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules", 0, map[string]any{
+		"name":      "Weekly PDF",
+		"report_id": report.ID,
+		"period":    "last_week",
+		"rrule":     "FREQ=WEEKLY;BYDAY=MO",
+		"format":    "pdf",
+	}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create schedule: %d %s", rr.Code, rr.Body.String())
+	}
+	var sch store.ReportSchedule
+	_ = json.Unmarshal(rr.Body.Bytes(), &sch)
+
+	// Make the schedule due and let the scheduler pick it up.
+	past := time.Now().UTC().Add(-time.Hour)
+	sch.NextRunAt = &past
+	if err := env.reports.UpdateReportScheduleState(ctx, &sch); err != nil {
+		t.Fatalf("state: %v", err)
+	}
+	worker := handlers.NewReportScheduler(config.SchedulerConfig{Enabled: true}, env.handler, utils.NewLogger())
+	now := time.Now().UTC()
+	if err := worker.RunOnce(ctx, now); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	runs, _ := env.reports.ListReportScheduleRuns(ctx, sch.ID, 10)
+	if len(runs) != 1 || runs[0].Status != "failed" || runs[0].Error != "reports.error.exportConverterMissing" || runs[0].Trigger != "schedule" {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+	if runs[0].ScheduledFor == nil || runs[0].ScheduledFor.Sub(past).Abs() > time.Second {
+		t.Fatalf("unexpected scheduled_for: %v", runs[0].ScheduledFor)
+	}
+	stored, _ := env.reports.GetReportSchedule(ctx, sch.ID)
+	if stored == nil || stored.LastStatus != "failed" || stored.NextRunAt == nil || !stored.NextRunAt.After(now) || stored.NextRunAt.Weekday() != time.Monday {
+		t.Fatalf("unexpected schedule state: %+v", stored)
+	}
+	if err := worker.RunOnce(ctx, now); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if runs, _ := env.reports.ListReportScheduleRuns(ctx, sch.ID, 10); len(runs) != 1 {
+		t.Fatalf("schedule ran again before it was due: %d", len(runs))
+	}
+}
+
+func TestReportScheduleValidation(t *testing.T) {
+	env := setupReportsBuilder(t)
+	report := createScheduledReport(t, env)
+	cases := []struct {
+		payload map[string]any
+		want    string
+	}{
+		{map[string]any{"report_id": report.ID, "period": "last_month", "cron": "0 7 1 * *"}, "reports.schedules.error.name"},
+		{map[string]any{"name": "x", "period": "last_month", "cron": "0 7 1 * *"}, "reports.schedules.error.target"},
+		{map[string]any{"name": "x", "report_id": report.ID, "period": "yesterday", "cron": "0 7 1 * *"}, "reports.schedules.error.period"},
+		{map[string]any{"name": "x", "report_id": report.ID, "period": "last_month"}, "reports.schedules.error.recurrence"},
+		{map[string]any{"name": "x", "report_id": report.ID, "period": "last_month", "cron": "0 7 1 * 1"}, "schedule.error.cron"},
+		{map[string]any{"name": "x", "report_id": report.ID, "period": "last_month", "cron": "0 7 1 * *", "format": "xlsx"}, "reports.schedules.error.format"},
+		{map[string]any{"name": "x", "report_id": report.ID, "period": "last_month", "cron": "0 7 1 * *", "recipient_users": []int64{9999}}, "reports.schedules.error.recipients"},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		env.handler.CreateSchedule(rr, scheduleRequest(env, http.MethodPost, "/api/reports/schedules", 0, tc.payload))
+		if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte(tc.want)) {
+			t.Fatalf("payload %v: %d %s, want %s", tc.payload, rr.Code, rr.Body.String(), tc.want)
+		}
+	}
+}
+
+func TestReportScheduleRendersInCreatorLanguage(t *testing.T) {
+	env := setupReportsBuilder(t)
+	ctx := context.Background()
+	report := createScheduledReport(t, env)
+	if err := env.reports.ReplaceReportCharts(ctx, report.ID, []store.ReportChart{{ChartType: "incidents_severity_pie", SectionType: "incidents", IsEnabled: true}}); err != nil {
+		t.Fatalf("charts: %v", err)
+	}
+	req := scheduleRequest(env, http.MethodPost, "/api/reports/schedules", 0, map[string]any{
+		"name":      "Ежемесячный отчёт",
+		"report_id": report.ID,
+		"period":    "last_month",
+		"cron":      "0 7 1 * *",
+		"format":    "md",
+	})
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9")
+	rr := httptest.NewRecorder()
+	env.handler.CreateSchedule(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create schedule: %d %s", rr.Code, rr.Body.String())
+	}
+	var sch store.ReportSchedule
+	_ = json.Unmarshal(rr.Body.Bytes(), &sch)
+	if sch.Language != "ru" {
+		t.Fatalf("language not captured: %+v", sch)
+	}
+
+	// Scheduled runs have no request, so the stored language must be used.
+	past := time.Now().UTC().Add(-time.Hour)
+	sch.NextRunAt = &past
+	if err := env.reports.UpdateReportScheduleState(ctx, &sch); err != nil {
+		t.Fatalf("state: %v", err)
+	}
+	worker := handlers.NewReportScheduler(config.SchedulerConfig{Enabled: true}, env.handler, utils.NewLogger())
+	if err := worker.RunOnce(ctx, time.Now().UTC()); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	runs, _ := env.reports.ListReportScheduleRuns(ctx, sch.ID, 10)
+	if len(runs) != 1 || runs[0].Status != "success" || runs[0].OutputDocID == nil {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+	ver, err := env.docs.GetVersion(ctx, *runs[0].OutputDocID, runs[0].OutputVersion)
+	if err != nil || ver == nil {
+		t.Fatalf("output version: %v", err)
+	}
+	content, err := env.docsSvc.LoadContent(ctx, ver)
+	if err != nil || !strings.Contains(string(content), "## Графики") {
+		t.Fatalf("output not rendered in the creator language: %v %s", err, content)
+	}
+}
@@ -0,0 +1,302 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+	"berkut-scc/tasks"
+	taskstore "berkut-scc/tasks/store"
+)
+
+type risksEnv struct {
+	risks   store.RisksStore
+	links   store.EntityLinksStore
+	assets  store.AssetsStore
+	tasks   *taskstore.SQLStore
+	handler *handlers.RisksHandler
+	admin   *store.User
+	analyst *store.User
+	column  *tasks.Column
+}
+
+func setupRisks(t *testing.T) *risksEnv {
+	t.Helper()
+	cfg := &config.AppConfig{DBPath: filepath.Join(t.TempDir(), "risks.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	rs := store.NewRisksStore(db)
+	links := store.NewEntityLinksStore(db)
+	assets := store.NewAssetsStore(db)
+	ts := taskstore.NewStore(db)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	h := handlers.NewRisksHandler(rs, links, users, assets, store.NewControlsStore(db), store.NewFindingsStore(db), store.NewVulnsStore(db), ts, store.NewAuditStore(db), policy)
+	admin := createObservablesUser(t, users, "risk-admin", []string{"admin"})
+	analyst := createObservablesUser(t, users, "risk-analyst", []string{"analyst"})
+
+	ctx := context.Background()
+	spaceID, err := ts.CreateSpace(ctx, &tasks.Space{Name: "Space", IsActive: true, CreatedBy: &admin.ID}, nil)
+	if err != nil {
+		t.Fatalf("space: %v", err)
+	}
+	board := &tasks.Board{SpaceID: spaceID, Name: "Treatment", IsActive: true, CreatedBy: &admin.ID}
+	if _, err := ts.CreateBoard(ctx, board, []tasks.ACLRule{{SubjectType: "user", SubjectID: admin.Username, Permission: "manage"}}); err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	column := &tasks.Column{BoardID: board.ID, Name: "Todo", Position: 1, IsActive: true}
+	if _, err := ts.CreateColumn(ctx, column); err != nil {
+		t.Fatalf("column: %v", err)
+	}
+	return &risksEnv{risks: rs, links: links, assets: assets, tasks: ts, handler: h, admin: admin, analyst: analyst, column: column}
+}
+
+func riskRequest(method, target string, body any, u *store.User, roles []string, params map[string]string) *http.Request {
+	var raw []byte
+	if body != nil {
+		raw, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(raw))
+	if params != nil {
+		req = withURLParams(req, params)
+	}
+	return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, sessionFor(u, roles)))
+}
+
+func createRiskViaAPI(t *testing.T, env *risksEnv, payload map[string]any) map[string]any {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/risks", payload, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create risk: %d %s", rr.Code, rr.Body.String())
+	}
+	var out map[string]any
+	_ = json.Unmarshal(rr.Body.Bytes(), &out)
+	return out
+}
+
+func TestRiskMatrixScoringAndValidation(t *testing.T) {
+	m := store.DefaultRiskMatrix()
+	if err := m.Validate(); err != nil {
+		t.Fatalf("default matrix invalid: %v", err)
+	}
+	cases := map[[2]int]string{{1, 1}: "low", {2, 2}: "low", {1, 5}: "medium", {2, 5}: "high", {4, 4}: "high", {5, 4}: "critical", {5, 5}: "critical"}
+	for in, want := range cases {
+		if got := m.Level(in[0], in[1]).Key; got != want {
+			t.Fatalf("level(%d,%d): expected %s, got %s", in[0], in[1], want, got)
+		}
+	}
+	if m.InRange(6, 1) || m.InRange(0, 3) {
+		t.Fatalf("out of range scores accepted")
+	}
+	bad := store.DefaultRiskMatrix()
+	bad.Levels[2].MinScore = bad.Levels[1].MinScore
+	if err := bad.Validate(); err == nil {
+		t.Fatalf("expected non-increasing thresholds to be rejected")
+	}
+	small := store.DefaultRiskMatrix()
+	small.Impact = small.Impact[:2]
+	if err := small.Validate(); err == nil {
+		t.Fatalf("expected 2-column matrix to be rejected")
+	}
+}
+
+func TestRiskCreateValidation(t *testing.T) {
+	env := setupRisks(t)
+	rr := httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/risks", map[string]any{"title": " "}, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte("risks.titleRequired")) {
+		t.Fatalf("expected title validation, got %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/risks", map[string]any{"title": "Data leak", "inherent_likelihood": 6, "inherent_impact": 2}, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte("risks.scoreInvalid")) {
+		t.Fatalf("expected score validation, got %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	env.handler.Create(rr, riskRequest(http.MethodPost, "/api/risks", map[string]any{"title": "Data leak"}, env.analyst, []string{"analyst"}, nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("analyst must not create risks, got %d", rr.Code)
+	}
+
+	created := createRiskViaAPI(t, env, map[string]any{"title": "Data leak", "inherent_likelihood": 4, "inherent_impact": 5})
+	if created["inherent_score"].(float64) != 20 || created["inherent_level"] != "critical" {
+		t.Fatalf("unexpected inherent scoring: %+v", created)
+	}
+	if created["residual_score"].(float64) != 20 {
+		t.Fatalf("residual should default to inherent: %+v", created)
+	}
+	id := strconv.FormatInt(int64(created["id"].(float64)), 10)
+	update := map[string]any{"title": "Data leak", "inherent_likelihood": 4, "inherent_impact": 5, "residual_likelihood": 2, "residual_impact": 2, "version": 1}
+	rr = httptest.NewRecorder()
+	env.handler.Update(rr, riskRequest(http.MethodPut, "/api/risks/"+id, update, env.admin, []string{"admin"}, map[string]string{"id": id}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	env.handler.Update(rr, riskRequest(http.MethodPut, "/api/risks/"+id, update, env.admin, []string{"admin"}, map[string]string{"id": id}))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("stale version must conflict, got %d", rr.Code)
+	}
+}
+
+func TestRiskAcceptanceRequiresExpiry(t *testing.T) {
+	env := setupRisks(t)
+	created := createRiskViaAPI(t, env, map[string]any{"title": "Legacy VPN", "inherent_likelihood": 3, "inherent_impact": 3})
+	id := strconv.FormatInt(int64(created["id"].(float64)), 10)
+	params := map[string]string{"id": id}
+
+	rr := httptest.NewRecorder()
+	env.handler.Accept(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/accept", map[string]any{"note": "ok"}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte("risks.acceptance.expiryRequired")) {
+		t.Fatalf("expected expiry required, got %d %s", rr.Code, rr.Body.String())
+	}
+	past := time.Now().UTC().Add(-24 * time.Hour)
+	rr = httptest.NewRecorder()
+	env.handler.Accept(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/accept", map[string]any{"expires_at": past}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected past expiry to be rejected, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.RevokeAcceptance(rr, riskRequest(http.MethodDelete, "/api/risks/"+id+"/accept", nil, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("revoke without acceptance must conflict, got %d", rr.Code)
+	}
+
+	future := time.Now().UTC().Add(90 * 24 * time.Hour)
+	rr = httptest.NewRecorder()
+	env.handler.Accept(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/accept", map[string]any{"expires_at": future, "note": "budget"}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("accept: %d %s", rr.Code, rr.Body.String())
+	}
+	risk, _ := env.risks.GetRisk(context.Background(), int64(created["id"].(float64)))
+	if risk.Status != "accepted" || risk.Treatment != "accept" || risk.AcceptedBy == nil || *risk.AcceptedBy != env.admin.ID {
+		t.Fatalf("unexpected accepted risk: %+v", risk)
+	}
+	if risk.AcceptanceExpired(time.Now().UTC()) || !risk.AcceptanceExpired(future.Add(time.Hour)) {
+		t.Fatalf("unexpected acceptance expiry evaluation")
+	}
+	rr = httptest.NewRecorder()
+	env.handler.RevokeAcceptance(rr, riskRequest(http.MethodDelete, "/api/risks/"+id+"/accept", nil, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", rr.Code, rr.Body.String())
+	}
+	risk, _ = env.risks.GetRisk(context.Background(), risk.ID)
+	if risk.AcceptedAt != nil || risk.Status != "assessed" {
+		t.Fatalf("acceptance not cleared: %+v", risk)
+	}
+}
+
+func TestRiskMatrixShrinkBlockedWhenInUse(t *testing.T) {
+	env := setupRisks(t)
+	createRiskViaAPI(t, env, map[string]any{"title": "Ransomware", "inherent_likelihood": 5, "inherent_impact": 5, "residual_likelihood": 2, "residual_impact": 3})
+	matrix := store.DefaultRiskMatrix()
+	matrix.Likelihood = matrix.Likelihood[:4]
+	rr := httptest.NewRecorder()
+	env.handler.UpdateMatrix(rr, riskRequest(http.MethodPut, "/api/risks/matrix", matrix, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected matrix in use conflict, got %d %s", rr.Code, rr.Body.String())
+	}
+	matrix = store.DefaultRiskMatrix()
+	matrix.Levels[0].Key = "minor"
+	rr = httptest.NewRecorder()
+	env.handler.UpdateMatrix(rr, riskRequest(http.MethodPut, "/api/risks/matrix", matrix, env.admin, []string{"admin"}, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("matrix update: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	env.handler.Heatmap(rr, riskRequest(http.MethodGet, "/api/risks/heatmap", nil, env.analyst, []string{"analyst"}, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("heatmap: %d", rr.Code)
+	}
+	var hm struct {
+		Inherent [][]int `json:"inherent"`
+		Residual [][]int `json:"residual"`
+		Total    int     `json:"total"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &hm)
+	if hm.Total != 1 || hm.Inherent[4][4] != 1 || hm.Residual[1][2] != 1 {
+		t.Fatalf("unexpected heatmap: %+v", hm)
+	}
+}
+
+func TestRiskLinksAndTreatmentTask(t *testing.T) {
+	env := setupRisks(t)
+	ctx := context.Background()
+	created := createRiskViaAPI(t, env, map[string]any{"title": "Unpatched CRM", "inherent_likelihood": 3, "inherent_impact": 4, "treatment_plan_md": "Patch CRM"})
+	riskID := int64(created["id"].(float64))
+	id := strconv.FormatInt(riskID, 10)
+	params := map[string]string{"id": id}
+	assetID, err := env.assets.CreateAsset(ctx, &store.Asset{Name: "crm-01", Type: "server", Criticality: "high"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	env.handler.AddLink(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/links", map[string]any{"target_type": "asset", "target_id": strconv.FormatInt(assetID, 10)}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("add link: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	env.handler.AddLink(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/links", map[string]any{"target_type": "asset", "target_id": strconv.FormatInt(assetID, 10)}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("duplicate link must conflict, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	env.handler.AddLink(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/links", map[string]any{"target_type": "asset", "target_id": "999"}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("missing target must be rejected, got %d", rr.Code)
+	}
+	links, _ := env.links.ListBySource(ctx, "risk", id)
+	if len(links) != 1 || links[0].RelationType != "affects" {
+		t.Fatalf("unexpected links: %+v", links)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.CreateTask(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/tasks", map[string]any{"column_id": env.column.ID}, env.admin, []string{"admin"}, params))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create task: %d %s", rr.Code, rr.Body.String())
+	}
+	var out struct {
+		TaskID int64 `json:"task_id"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &out)
+	task, err := env.tasks.GetTask(ctx, out.TaskID)
+	if err != nil || task == nil || task.Title != "Unpatched CRM" || task.Description != "Patch CRM" {
+		t.Fatalf("unexpected task: %+v %v", task, err)
+	}
+	taskLinks, _ := env.tasks.ListEntityLinks(ctx, "task", strconv.FormatInt(out.TaskID, 10))
+	if len(taskLinks) != 1 || taskLinks[0].TargetType != "risk" || taskLinks[0].TargetID != id {
+		t.Fatalf("task must link back to risk: %+v", taskLinks)
+	}
+	links, _ = env.links.ListBySource(ctx, "risk", id)
+	if len(links) != 2 {
+		t.Fatalf("risk must link to task: %+v", links)
+	}
+
+	// Role permissions alone are not enough: the board ACL only lists the admin.
+	rr = httptest.NewRecorder()
+	env.handler.CreateTask(rr, riskRequest(http.MethodPost, "/api/risks/"+id+"/tasks", map[string]any{"column_id": env.column.ID}, env.analyst, []string{"admin"}, params))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden outside board ACL, got %d %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,343 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/query"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestQueryParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"status:":                      "query.error.value",
+		"(status:open":                 "query.error.syntax",
+		"status:open OR":               "query.error.syntax",
+		"title~(a,b)":                  "query.error.operator",
+		"status:open)":                 "query.error.syntax",
+		`title:"open`:                  "query.error.syntax",
+		strings.Repeat("a", 2001):      "query.error.tooLong",
+		"status:open AND NOT tag:test": "",
+		`title~"vpn gateway" severity:(high,critical)`: "",
+	}
+	for input, code := range cases {
+		_, err := query.Parse(input)
+		if code == "" {
+			if err != nil {
+				t.Fatalf("parse %q: %v", input, err)
+			}
+			continue
+		}
+		var qe *query.Error
+		if !errors.As(err, &qe) || qe.Code != code {
+			t.Fatalf("parse %q: expected %s, got %v", input, code, err)
+		}
+	}
+}
+
+func TestQueryCompile(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	compile := func(entity, input string) (*store.QueryClause, error) {
+		node, err := query.Parse(input)
+		if err != nil {
+			return nil, err
+		}
+		return query.Compile(entity, node, query.Env{UserID: 7, Now: now})
+	}
+	clause, err := compile(store.SearchEntityIncident, "severity:(High,critical) assignee:me OR NOT status:closed")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !strings.Contains(clause.SQL, " OR ") || !strings.Contains(clause.SQL, "NOT ") || len(clause.Args) != 4 {
+		t.Fatalf("unexpected clause: %s %v", clause.SQL, clause.Args)
+	}
+	if clause.Args[0] != "high" || clause.Args[2] != int64(7) {
+		t.Fatalf("unexpected args: %v", clause.Args)
+	}
+	clause, err = compile(store.SearchEntityFinding, "created:2026-03-01 updated>=today-7d")
+	if err != nil {
+		t.Fatalf("compile dates: %v", err)
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	if len(clause.Args) != 3 || clause.Args[0] != day || clause.Args[1] != day.AddDate(0, 0, 1) || clause.Args[2] != week {
+		t.Fatalf("unexpected date args: %v", clause.Args)
+	}
+	clause, err = query.Compile(store.SearchEntityIncident, query.Term{Field: "status", Op: query.OpContains, Values: []string{"open", "Contain"}}, query.Env{UserID: 7, Now: now})
+	if err != nil {
+		t.Fatalf("compile contains list: %v", err)
+	}
+	if strings.Count(clause.SQL, "LIKE") != 2 || len(clause.Args) != 2 || clause.Args[1] != "%contain%" {
+		t.Fatalf("expected every value of a contains list to match: %s %v", clause.SQL, clause.Args)
+	}
+	errorCases := map[string]string{
+		"unknown:x":   "query.error.field",
+		"severity>1":  "query.error.operator",
+		"level:high":  "query.error.value",
+		"created:abc": "query.error.value",
+		"cf.unit:x":   "query.error.field",
+	}
+	for input, code := range errorCases {
+		_, err := compile(store.SearchEntityIncident, input)
+		var qe *query.Error
+		if !errors.As(err, &qe) || qe.Code != code {
+			t.Fatalf("compile %q: expected %s, got %v", input, code, err)
+		}
+	}
+}
+
+type savedSearchesEnv struct {
+	*assetGraphEnv
+	findings  store.FindingsStore
+	groups    store.GroupsStore
+	searches  *handlers.SavedSearchesHandler
+	findingsH *handlers.FindingsHandler
+}
+
+func newSavedSearchesEnv(t *testing.T) *savedSearchesEnv {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	audits := store.NewAuditStore(db)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	fs := store.NewFindingsStore(db)
+	ss := store.NewSavedSearchesStore(db)
+	queries := query.NewService(ss, nil)
+	findingsH := handlers.NewFindingsHandler(fs, nil, users, nil, nil, nil, nil, audits, policy)
+	findingsH.SetQueries(queries)
+	return &savedSearchesEnv{
+		assetGraphEnv: &assetGraphEnv{cfg: cfg, users: users},
+		findings:      fs,
+		groups:        store.NewGroupsStore(db),
+		searches:      handlers.NewSavedSearchesHandler(ss, queries, users, audits, policy),
+		findingsH:     findingsH,
+	}
+}
+
+func (e *savedSearchesEnv) createFinding(t *testing.T, title, severity, status string, tags []string) int64 {
+	t.Helper()
+	now := time.Now().UTC()
+	id, err := e.findings.CreateFinding(context.Background(), &store.Finding{Title: title, Severity: severity, Status: status, FindingType: "technical", Tags: tags, CreatedAt: now, UpdatedAt: now, Version: 1})
+	if err != nil {
+		t.Fatalf("create finding: %v", err)
+	}
+	return id
+}
+
+func (e *savedSearchesEnv) listFindings(t *testing.T, user *store.User, params string) ([]store.Finding, *httptest.ResponseRecorder) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	e.findingsH.List(rr, e.request(http.MethodGet, "/api/findings?"+params, nil, nil, user, []string{"analyst"}))
+	var resp struct {
+		Items []store.Finding `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+	return resp.Items, rr
+}
+
+func (e *savedSearchesEnv) listSearches(t *testing.T, user *store.User) []map[string]any {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	e.searches.List(rr, e.request(http.MethodGet, "/api/saved-searches?entity_type=finding", nil, nil, user, []string{"analyst"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list searches status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Items []map[string]any `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+	return resp.Items
+}
+
+func TestFindingsListQuery(t *testing.T) {
+	env := newSavedSearchesEnv(t)
+	user := createObservablesUser(t, env.users, "ss-reader", []string{"analyst"})
+	critical := env.createFinding(t, "Open RDP on gateway", "critical", "open", []string{"network"})
+	env.createFinding(t, "Weak TLS ciphers", "medium", "open", []string{"tls"})
+	env.createFinding(t, "Old kernel", "high", "resolved", nil)
+
+	items, rr := env.listFindings(t, user, "query="+url.QueryEscape("severity:(high,critical) AND status!=resolved"))
+	if rr.Code != http.StatusOK || len(items) != 1 || items[0].ID != critical {
+		t.Fatalf("unexpected query result: %d %s", rr.Code, rr.Body.String())
+	}
+	items, _ = env.listFindings(t, user, "query="+url.QueryEscape("tag:network OR title~tls"))
+	if len(items) != 2 {
+		t.Fatalf("expected 2 findings for OR query, got %d", len(items))
+	}
+	items, _ = env.listFindings(t, user, "status=open&query="+url.QueryEscape("NOT tag:network"))
+	if len(items) != 1 || items[0].Title != "Weak TLS ciphers" {
+		t.Fatalf("query must combine with list filters: %+v", items)
+	}
+	if _, rr := env.listFindings(t, user, "query="+url.QueryEscape("owner>x")); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "query.error.operator") {
+		t.Fatalf("expected operator error, got %d %s", rr.Code, rr.Body.String())
+	}
+	if _, rr := env.listFindings(t, user, "saved_search=999"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown saved search, got %d", rr.Code)
+	}
+
+	// LIKE wildcards in query values match literally.
+	env.createFinding(t, "Disk 100% full", "low", "resolved", []string{"netxa"})
+	for input, want := range map[string]int{`title~"100%"`: 1, `title~"1_0"`: 0, "tag:net_a": 0, "tag~t_a": 0, "tag~txa": 1} {
+		if items, rr := env.listFindings(t, user, "query="+url.QueryEscape(input)); rr.Code != http.StatusOK || len(items) != want {
+			t.Fatalf("query %q: expected %d findings, got %d %s", input, want, len(items), rr.Body.String())
+		}
+	}
+}
+
+func TestIncidentsTagQuery(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+	if err := store.ApplyMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	incidents := store.NewIncidentsStore(db)
+	owner := createObservablesUser(t, users, "tag-owner", []string{"analyst"})
+	create := func(title string, meta store.IncidentMeta) *store.Incident {
+		inc := &store.Incident{Title: title, Severity: "high", Status: "open", OwnerUserID: owner.ID, CreatedBy: owner.ID, UpdatedBy: owner.ID, Version: 1, Meta: meta}
+		if _, err := incidents.CreateIncident(ctx, inc, nil, nil, "INC-{year}-{seq:05}"); err != nil {
+			t.Fatalf("create incident: %v", err)
+		}
+		return inc
+	}
+	tagged := create("Mailbox compromise", store.IncidentMeta{Tags: []string{"phishing", "email"}})
+	// The tag value only occurs in the free-text part of the meta.
+	create("Suspicious link", store.IncidentMeta{WhatHappened: "phishing"})
+	list := func(input string) []store.Incident {
+		t.Helper()
+		node, err := query.Parse(input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", input, err)
+		}
+		clause, err := query.Compile(store.SearchEntityIncident, node, query.Env{UserID: owner.ID})
+		if err != nil {
+			t.Fatalf("compile %q: %v", input, err)
+		}
+		items, err := incidents.ListIncidents(ctx, store.IncidentFilter{Query: clause})
+		if err != nil {
+			t.Fatalf("list %q: %v", input, err)
+		}
+		return items
+	}
+	if items := list("tag:Phishing"); len(items) != 1 || items[0].ID != tagged.ID {
+		t.Fatalf("tag must match only the tags array: %+v", items)
+	}
+	if items := list("tag~phish"); len(items) != 1 || items[0].ID != tagged.ID {
+		t.Fatalf("tag contains must match only the tags array: %+v", items)
+	}
+	tagged.Meta.Tags = []string{"email"}
+	if err := incidents.UpdateIncident(ctx, tagged, tagged.Version); err != nil {
+		t.Fatalf("update incident: %v", err)
+	}
+	if items := list("tag:phishing"); len(items) != 0 {
+		t.Fatalf("tags must follow incident updates: %+v", items)
+	}
+}
+
+func TestSavedSearchesSharing(t *testing.T) {
+	env := newSavedSearchesEnv(t)
+	ctx := context.Background()
+	owner := createObservablesUser(t, env.users, "ss-owner", []string{"analyst"})
+	member := createObservablesUser(t, env.users, "ss-member", []string{"analyst"})
+	outsider := createObservablesUser(t, env.users, "ss-outsider", []string{"analyst"})
+	teamID, err := env.groups.Create(ctx, &store.Group{Name: "soc"}, nil, []int64{owner.ID, member.ID})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	otherID, err := env.groups.Create(ctx, &store.Group{Name: "audit"}, nil, []int64{outsider.ID})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	critical := env.createFinding(t, "Open RDP on gateway", "critical", "open", nil)
+	env.createFinding(t, "Weak TLS ciphers", "medium", "open", nil)
+
+	create := func(payload map[string]any) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		env.searches.Create(rr, env.request(http.MethodPost, "/api/saved-searches", nil, payload, owner, []string{"analyst"}))
+		return rr
+	}
+	payload := map[string]any{"entity_type": "finding", "name": "Critical", "query": "severity:critical", "group_ids": []int64{otherID}}
+	if rr := create(payload); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "savedSearches.error.group") {
+		t.Fatalf("expected group error, got %d %s", rr.Code, rr.Body.String())
+	}
+	payload["group_ids"] = []int64{teamID}
+	payload["query"] = "(severity:critical"
+	if rr := create(payload); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "query.error.syntax") {
+		t.Fatalf("expected syntax error, got %d %s", rr.Code, rr.Body.String())
+	}
+	payload["query"] = "severity:critical"
+	rr := create(payload)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", rr.Code, rr.Body.String())
+	}
+	var created store.SavedSearch
+	_ = json.Unmarshal(rr.Body.Bytes(), &created)
+
+	if items := env.listSearches(t, member); len(items) != 1 || items[0]["can_edit"] != false {
+		t.Fatalf("member must see the shared search read-only: %+v", items)
+	}
+	if items := env.listSearches(t, outsider); len(items) != 0 {
+		t.Fatalf("outsider must not see the search: %+v", items)
+	}
+	param := "saved_search=" + strconv.FormatInt(created.ID, 10)
+	if items, rr := env.listFindings(t, member, param); rr.Code != http.StatusOK || len(items) != 1 || items[0].ID != critical {
+		t.Fatalf("unexpected saved search result: %d %s", rr.Code, rr.Body.String())
+	}
+	if _, rr := env.listFindings(t, outsider, param); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a search that is not shared, got %d", rr.Code)
+	}
+
+	params := map[string]string{"id": strconv.FormatInt(created.ID, 10)}
+	update := map[string]any{"name": "Critical", "query": "severity:medium"}
+	rr = httptest.NewRecorder()
+	env.searches.Update(rr, env.request(http.MethodPut, "/api/saved-searches/"+params["id"], params, update, member, []string{"analyst"}))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-owner update, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	env.searches.Update(rr, env.request(http.MethodPut, "/api/saved-searches/"+params["id"], params, update, owner, []string{"analyst"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update status %d: %s", rr.Code, rr.Body.String())
+	}
+	if items := env.listSearches(t, member); len(items) != 0 {
+		t.Fatalf("update without groups must unshare the search: %+v", items)
+	}
+	rr = httptest.NewRecorder()
+	env.searches.Delete(rr, env.request(http.MethodDelete, "/api/saved-searches/"+params["id"], params, nil, owner, []string{"analyst"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete status %d: %s", rr.Code, rr.Body.String())
+	}
+	if items := env.listSearches(t, owner); len(items) != 0 {
+		t.Fatalf("deleted search still listed: %+v", items)
+	}
+}
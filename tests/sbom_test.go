@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/sbom"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+const cycloneDXInitial = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"type": "application", "name": "billing", "version": "1.0.0"}},
+  "components": [
+    {"type": "library", "name": "log4j-core", "version": "2.14.1", "publisher": "The Apache Software Foundation, Inc.",
+     "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+    {"type": "library", "name": "jackson-databind", "group": "com.fasterxml.jackson.core", "version": "2.13.0",
+     "components": [{"type": "library", "name": "jackson-core", "group": "com.fasterxml.jackson.core", "version": "2.13.0"}]},
+    {"type": "library", "name": "no-version"}
+  ]
+}`
+
+const spdxUpdated = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentDescribes": ["SPDXRef-root"],
+  "packages": [
+    {"SPDXID": "SPDXRef-root", "name": "billing", "versionInfo": "1.0.1"},
+    {"SPDXID": "SPDXRef-1", "name": "log4j-core", "versionInfo": "2.17.1", "supplier": "Organization: Apache Software Foundation",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1"}]},
+    {"SPDXID": "SPDXRef-2", "name": "jackson-databind", "versionInfo": "2.13.0", "supplier": "NOASSERTION",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.13.0"}]}
+  ]
+}`
+
+func TestParseSBOMFormats(t *testing.T) {
+	doc, err := sbom.Parse([]byte(cycloneDXInitial))
+	if err != nil || doc.Format != sbom.FormatCycloneDX {
+		t.Fatalf("cyclonedx: %v %+v", err, doc)
+	}
+	if len(doc.Components) != 4 || doc.Components[2].Name != "jackson-core" || doc.Components[1].Vendor != "com.fasterxml.jackson.core" {
+		t.Fatalf("unexpected cyclonedx components: %+v", doc.Components)
+	}
+	doc, err = sbom.Parse([]byte(spdxUpdated))
+	if err != nil || doc.Format != sbom.FormatSPDX {
+		t.Fatalf("spdx: %v %+v", err, doc)
+	}
+	if len(doc.Components) != 2 || doc.Components[0].Vendor != "Apache Software Foundation" || doc.Components[1].Vendor != "com.fasterxml.jackson.core" {
+		t.Fatalf("unexpected spdx components: %+v", doc.Components)
+	}
+	if _, err := sbom.Parse([]byte(`{"foo": 1}`)); err != sbom.ErrFormatInvalid {
+		t.Fatalf("expected format error, got %v", err)
+	}
+	if sbom.NormalizeVendor("The Apache Software Foundation, Inc.") != sbom.NormalizeVendor("apache software foundation") {
+		t.Fatalf("vendor normalization mismatch")
+	}
+}
+
+func TestSBOMImportPreviewApplyAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	software := store.NewSoftwareStore(db)
+	audits := store.NewAuditStore(db)
+	user := createObservablesUser(t, users, "sbom-admin", []string{"admin"})
+	h := handlers.NewAssetsHandler(assets, software, nil, nil, users, audits, rbac.NewPolicy(rbac.DefaultRoles()))
+
+	assetID, err := assets.CreateAsset(ctx, &store.Asset{Name: "billing-01", Type: "host"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	log4jID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "log4j-core", Vendor: "Apache Software Foundation"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	openssl, _ := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "OpenSSL", Vendor: "OpenSSL"})
+	manualID, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: openssl, VersionText: "3.0.2"})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	upload := func(doc string, preview bool) sbom.Plan {
+		t.Helper()
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("file", "bom.json")
+		_, _ = fw.Write([]byte(doc))
+		if preview {
+			_ = mw.WriteField("preview", "1")
+		}
+		_ = mw.Close()
+		req := httptest.NewRequest("POST", "/api/assets/x/software/sbom", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req = withURLParams(req, map[string]string{"id": strconv.FormatInt(assetID, 10)})
+		req = req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: user.ID, Username: user.Username, Roles: []string{"admin"}}))
+		rr := httptest.NewRecorder()
+		h.ImportSBOM(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("sbom upload: %d %s", rr.Code, rr.Body.String())
+		}
+		var plan sbom.Plan
+		if err := json.Unmarshal(rr.Body.Bytes(), &plan); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return plan
+	}
+
+	plan := upload(cycloneDXInitial, true)
+	if plan.Applied || plan.Added != 3 || plan.Skipped != 1 || plan.NewProducts != 2 || plan.NewVersions != 3 {
+		t.Fatalf("unexpected preview: %+v", plan)
+	}
+	if installs, _ := software.ListAssetSoftware(ctx, assetID, false); len(installs) != 1 {
+		t.Fatalf("preview must not change inventory: %+v", installs)
+	}
+
+	plan = upload(cycloneDXInitial, false)
+	if !plan.Applied || plan.Added != 3 {
+		t.Fatalf("unexpected apply: %+v", plan)
+	}
+	installs, _ := software.ListAssetSoftware(ctx, assetID, false)
+	if len(installs) != 4 {
+		t.Fatalf("expected 4 installations, got %+v", installs)
+	}
+	var log4jInst store.AssetSoftwareInstallation
+	for _, inst := range installs {
+		if inst.ProductID == log4jID {
+			log4jInst = inst
+		}
+	}
+	if log4jInst.ID == 0 || log4jInst.Source != sbom.Source || log4jInst.VersionID == nil || log4jInst.VersionLabel != "2.14.1" {
+		t.Fatalf("log4j should reuse the existing product with an sbom installation: %+v", log4jInst)
+	}
+	if products, _ := software.ListProducts(ctx, store.SoftwareFilter{Search: "jackson"}); len(products) != 2 {
+		t.Fatalf("expected jackson products to be created: %+v", products)
+	}
+
+	plan = upload(spdxUpdated, false)
+	if plan.Updated != 1 || plan.Unchanged != 1 || plan.Archived != 1 || plan.NewProducts != 0 {
+		t.Fatalf("unexpected re-upload diff: %+v", plan)
+	}
+	installs, _ = software.ListAssetSoftware(ctx, assetID, false)
+	if len(installs) != 3 {
+		t.Fatalf("jackson-core should be archived: %+v", installs)
+	}
+	for _, inst := range installs {
+		if inst.ID == log4jInst.ID && inst.VersionLabel != "2.17.1" {
+			t.Fatalf("log4j should be upgraded in place: %+v", inst)
+		}
+	}
+	found := false
+	for _, inst := range installs {
+		if inst.ID == manualID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("manual installation must be kept")
+	}
+
+	otherID, err := assets.CreateAsset(ctx, &store.Asset{Name: "billing-02", Type: "host"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	doc, err := sbom.Parse([]byte(cycloneDXInitial))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	partial, err := sbom.BuildPlan(ctx, software, otherID, doc)
+	if err != nil || partial.Added != 3 {
+		t.Fatalf("plan: %v %+v", err, partial)
+	}
+	partial.Items = append(partial.Items, sbom.PlanItem{Action: sbom.ActionUpdate, Name: "gone", Version: "1.0", ProductID: log4jID, InstallationID: 999999})
+	if err := sbom.Apply(ctx, software, otherID, partial, user.ID); err == nil {
+		t.Fatalf("expected apply to fail on a missing installation")
+	}
+	if installs, _ := software.ListAssetSoftware(ctx, otherID, false); len(installs) != 0 || partial.Applied || partial.Items[0].InstallationID != 0 {
+		t.Fatalf("failed apply must roll back the whole plan: %+v %+v", installs, partial)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+	"berkut-scc/tasks"
+)
+
+func TestScheduleCalendarsAndPreview(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+	if err := store.ApplyMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	users := store.NewUsersStore(db)
+	calendars := store.NewScheduleCalendarsStore(db)
+	h := handlers.NewScheduleHandler(calendars, store.NewAuditStore(db))
+	env := &assetGraphEnv{}
+	admin := createObservablesUser(t, users, "sched-admin", []string{"admin"})
+	roles := []string{"admin"}
+
+	rr := httptest.NewRecorder()
+	h.CreateCalendar(rr, env.request(http.MethodPost, "/api/schedule/calendars", nil, map[string]any{
+		"name":     "Office",
+		"weekend":  []int{6, 0, 0},
+		"holidays": []map[string]string{{"date": "2026-06-01", "name": "Holiday"}},
+	}, admin, roles))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create calendar status %d: %s", rr.Code, rr.Body.String())
+	}
+	var cal store.ScheduleCalendar
+	_ = json.Unmarshal(rr.Body.Bytes(), &cal)
+	if len(cal.Weekend) != 2 || len(cal.Holidays) != 1 {
+		t.Fatalf("unexpected calendar: %+v", cal)
+	}
+	idParam := map[string]string{"id": strconv.FormatInt(cal.ID, 10)}
+
+	rr = httptest.NewRecorder()
+	h.CreateCalendar(rr, env.request(http.MethodPost, "/api/schedule/calendars", nil, map[string]any{"name": "office"}, admin, roles))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate name, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	h.CreateCalendar(rr, env.request(http.MethodPost, "/api/schedule/calendars", nil, map[string]any{
+		"name": "Broken", "holidays": []map[string]string{{"date": "01.01.2026"}},
+	}, admin, roles))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "schedule.error.holidayDate") {
+		t.Fatalf("expected holiday date error, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260504\r\nSUMMARY:Spring\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("file", "holidays.ics")
+	_, _ = fw.Write([]byte(ics))
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/schedule/calendars/"+idParam["id"]+"/import", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req = withURLParams(req, idParam)
+	req = req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: admin.ID, Username: admin.Username, Roles: roles}))
+	rr = httptest.NewRecorder()
+	h.ImportCalendar(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", rr.Code, rr.Body.String())
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &cal)
+	if len(cal.Holidays) != 2 || cal.Holidays[0].Date != "2026-05-04" {
+		t.Fatalf("imported holidays not merged: %+v", cal.Holidays)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Preview(rr, env.request(http.MethodPost, "/api/schedule/preview", nil, map[string]any{
+		"start":       "2026-01-31T09:00",
+		"rrule":       "FREQ=MONTHLY;BYMONTHDAY=-1",
+		"timezone":    "Europe/Moscow",
+		"shift":       "next_business_day",
+		"calendar_id": cal.ID,
+		"after":       "2026-05-01T00:00",
+		"count":       2,
+	}, admin, roles))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("preview status %d: %s", rr.Code, rr.Body.String())
+	}
+	var preview struct {
+		Items []struct {
+			At    time.Time `json:"at"`
+			Local string    `json:"local"`
+		} `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &preview)
+	if len(preview.Items) != 2 || !strings.HasPrefix(preview.Items[0].Local, "2026-06-02 09:00") || preview.Items[0].At.Hour() != 6 {
+		t.Fatalf("unexpected preview: %+v", preview.Items)
+	}
+	rr = httptest.NewRecorder()
+	h.Preview(rr, env.request(http.MethodPost, "/api/schedule/preview", nil, map[string]any{"start": "2026-01-01", "rrule": "FREQ=SOMETIMES"}, admin, roles))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "schedule.error.rrule") {
+		t.Fatalf("expected rrule error, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	config := json.RawMessage(`{"day":31,"shift":"next_business_day","calendar_id":` + idParam["id"] + `}`)
+	next, err := tasks.ComputeNextRunAtWith(ctx, calendars, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), tasks.ScheduleMonthly, config, "09:00")
+	if err != nil {
+		t.Fatalf("next run: %v", err)
+	}
+	if next.Format("2006-01-02") != "2026-06-02" {
+		t.Fatalf("recurring task must skip weekend and holiday: %s", next)
+	}
+
+	rr = httptest.NewRecorder()
+	h.DeleteCalendar(rr, env.request(http.MethodDelete, "/api/schedule/calendars/"+idParam["id"], idParam, nil, admin, roles))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete status %d", rr.Code)
+	}
+	if _, err := calendars.BusinessCalendar(ctx, cal.ID); err == nil {
+		t.Fatalf("deleted calendar still resolves")
+	}
+}
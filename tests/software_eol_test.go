@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/eol"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+	"berkut-scc/tasks"
+	taskstore "berkut-scc/tasks/store"
+)
+
+const endOfLifeFeed = `[
+  {"cycle": "3.2", "releaseDate": "2025-03-01", "eol": false, "latest": "3.2.1"},
+  {"cycle": "3.1", "releaseDate": "2024-01-10", "eol": "2025-07-15", "latest": "3.1.4"},
+  {"cycle": "3.0", "releaseDate": "2023-01-10", "eol": "2025-05-01", "latest": "3.0.9"},
+  {"cycle": "2", "eol": true}
+]`
+
+func TestEndOfLifeFeedMatching(t *testing.T) {
+	cycles, err := eol.ParseEndOfLife([]byte(endOfLifeFeed))
+	if err != nil || len(cycles) != 4 {
+		t.Fatalf("parse: %v %+v", err, cycles)
+	}
+	if cycles[0].EOL != nil || cycles[3].EOL != nil || !cycles[3].EOLReached {
+		t.Fatalf("unexpected eol flags: %+v", cycles)
+	}
+	for version, want := range map[string]string{"3.1.4": "3.1", "v3.0": "3.0", "2.7.1": "2", "3.1-beta": "3.1"} {
+		c, ok := eol.MatchCycle(cycles, version)
+		if !ok || c.Cycle != want {
+			t.Fatalf("%s: expected cycle %s, got %+v", version, want, c)
+		}
+	}
+	if _, ok := eol.MatchCycle(cycles, "3.10"); ok {
+		t.Fatalf("3.10 must not match 3.1")
+	}
+	if _, err := eol.ParseEndOfLife([]byte(`{"cycle": "1"}`)); err != eol.ErrFeedInvalid {
+		t.Fatalf("expected feed error, got %v", err)
+	}
+}
+
+func TestSoftwareEOLImportAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	software := store.NewSoftwareStore(db)
+	findings := store.NewFindingsStore(db)
+	user := createObservablesUser(t, users, "eol-admin", []string{"admin"})
+	svc := eol.NewService(config.SoftwareConfig{EOLHorizonDays: 90, EOLAlertMode: eol.ModeFinding}, store.NewSoftwareEOLStore(db), software, findings, store.NewEntityLinksStore(db), users, store.NewAuditStore(db))
+
+	productID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "Widget Server", Vendor: "Widgets"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	v31, _ := software.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: "3.1.4"})
+	v32, _ := software.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: "3.2.1"})
+	if _, err := software.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: "9.9"}); err != nil {
+		t.Fatalf("version: %v", err)
+	}
+
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	res, err := svc.ImportEndOfLife(ctx, productID, []byte(endOfLifeFeed), true, now, user.Username, user.ID)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.Matched != 2 || res.Updated != 1 || res.Unmatched != 1 || res.VersionsCreated != 2 {
+		t.Fatalf("unexpected import result: %+v", res)
+	}
+	versions, _ := software.ListVersions(ctx, productID, false)
+	for _, v := range versions {
+		switch v.Version {
+		case "3.1.4":
+			if v.EOLDate == nil || v.EOLDate.Format("2006-01-02") != "2025-07-15" {
+				t.Fatalf("3.1.4 eol not set: %+v", v)
+			}
+		case "3.2.1":
+			if v.EOLDate != nil {
+				t.Fatalf("3.2.1 must stay without eol: %+v", v)
+			}
+		case "2":
+			if v.EOLDate == nil || v.EOLDate.After(now) {
+				t.Fatalf("reached cycle should get the import date: %+v", v)
+			}
+		}
+	}
+
+	assetID, err := assets.CreateAsset(ctx, &store.Asset{Name: "app-01", Type: "host", Owner: "eol-admin"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	instID, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: productID, VersionID: &v31})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if _, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: productID, VersionText: "3.0"}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	check, err := svc.Check(ctx, now, user.Username, user.ID)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if check.Exposed != 2 || check.Expired != 1 || check.Approaching != 1 || check.Raised != 2 {
+		t.Fatalf("unexpected first check: %+v", check)
+	}
+	list, _ := findings.ListFindings(ctx, store.FindingFilter{Type: eol.FindingType})
+	if len(list) != 2 {
+		t.Fatalf("expected 2 eol findings, got %+v", list)
+	}
+	for _, f := range list {
+		if f.Owner != "eol-admin" || (f.Severity != "high" && f.Severity != "medium") {
+			t.Fatalf("unexpected finding: %+v", f)
+		}
+	}
+
+	check, _ = svc.Check(ctx, now, user.Username, user.ID)
+	if check.Raised != 0 || check.Escalated != 0 {
+		t.Fatalf("repeated check must be idempotent: %+v", check)
+	}
+
+	check, _ = svc.Check(ctx, time.Date(2025, 7, 20, 8, 0, 0, 0, time.UTC), user.Username, user.ID)
+	if check.Expired != 2 || check.Escalated != 1 {
+		t.Fatalf("expected escalation after eol date: %+v", check)
+	}
+
+	installs, _ := software.ListAssetSoftware(ctx, assetID, false)
+	var inst store.AssetSoftwareInstallation
+	for _, it := range installs {
+		if it.ID == instID {
+			inst = it
+		}
+	}
+	inst.VersionID = &v32
+	if err := software.UpdateAssetSoftware(ctx, &inst); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	check, _ = svc.Check(ctx, time.Date(2025, 7, 20, 8, 0, 0, 0, time.UTC), user.Username, user.ID)
+	if check.Resolved != 1 || check.Exposed != 1 {
+		t.Fatalf("upgrade should resolve the alert: %+v", check)
+	}
+	resolved := 0
+	list, _ = findings.ListFindings(ctx, store.FindingFilter{Type: eol.FindingType})
+	for _, f := range list {
+		if f.Status == "resolved" {
+			resolved++
+		}
+	}
+	if resolved != 1 {
+		t.Fatalf("expected one resolved finding: %+v", list)
+	}
+}
+
+func TestSoftwareEOLTaskDestinationAndClose(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	software := store.NewSoftwareStore(db)
+	ts := taskstore.NewStore(db)
+	user := createObservablesUser(t, users, "eol-tasks", []string{"admin"})
+
+	spaceID, err := ts.CreateSpace(ctx, &tasks.Space{Name: "Space", IsActive: true, CreatedBy: &user.ID}, nil)
+	if err != nil {
+		t.Fatalf("space: %v", err)
+	}
+	var boards []*tasks.Board
+	for _, name := range []string{"General", "Patching"} {
+		board := &tasks.Board{SpaceID: spaceID, Name: name, IsActive: true, CreatedBy: &user.ID}
+		if _, err := ts.CreateBoard(ctx, board, []tasks.ACLRule{{SubjectType: "user", SubjectID: user.Username, Permission: "manage"}}); err != nil {
+			t.Fatalf("board: %v", err)
+		}
+		boards = append(boards, board)
+	}
+	backlog := &tasks.Column{BoardID: boards[1].ID, Name: "Backlog", Position: 1, IsActive: true}
+	upgrades := &tasks.Column{BoardID: boards[1].ID, Name: "Upgrades", Position: 2, IsActive: true}
+	for _, col := range []*tasks.Column{{BoardID: boards[0].ID, Name: "Todo", Position: 1, IsActive: true}, backlog, upgrades} {
+		if _, err := ts.CreateColumn(ctx, col); err != nil {
+			t.Fatalf("column: %v", err)
+		}
+	}
+
+	svc := eol.NewService(config.SoftwareConfig{EOLHorizonDays: 90, EOLAlertMode: eol.ModeTask, EOLTaskBoardID: boards[1].ID, EOLTaskColumnID: upgrades.ID}, store.NewSoftwareEOLStore(db), software, store.NewFindingsStore(db), store.NewEntityLinksStore(db), users, store.NewAuditStore(db))
+	svc.SetTaskStore(ts)
+
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	eolDate := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	productID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "Gateway", Vendor: "Widgets"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	oldID, _ := software.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: "1.0", EOLDate: &eolDate})
+	newID, _ := software.CreateVersion(ctx, &store.SoftwareVersion{ProductID: productID, Version: "2.0"})
+	assetID, err := assets.CreateAsset(ctx, &store.Asset{Name: "gw-01", Type: "host"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	instID, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: productID, VersionID: &oldID})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	check, err := svc.Check(ctx, now, user.Username, user.ID)
+	if err != nil || check.TasksCreated != 1 {
+		t.Fatalf("check: %v %+v", err, check)
+	}
+	alerts, _ := store.NewSoftwareEOLStore(db).ListEOLAlerts(ctx, false)
+	if len(alerts) != 1 || alerts[0].TaskID == nil {
+		t.Fatalf("expected an alert with a task: %+v", alerts)
+	}
+	task, err := ts.GetTask(ctx, *alerts[0].TaskID)
+	if err != nil || task == nil || task.BoardID != boards[1].ID || task.ColumnID != upgrades.ID {
+		t.Fatalf("expected task in the configured column: %v %+v", err, task)
+	}
+
+	installs, _ := software.ListAssetSoftware(ctx, assetID, false)
+	for _, inst := range installs {
+		if inst.ID != instID {
+			continue
+		}
+		inst.VersionID = &newID
+		if err := software.UpdateAssetSoftware(ctx, &inst); err != nil {
+			t.Fatalf("upgrade: %v", err)
+		}
+	}
+	check, _ = svc.Check(ctx, now, user.Username, user.ID)
+	if check.Resolved != 1 {
+		t.Fatalf("upgrade should resolve the alert: %+v", check)
+	}
+	task, _ = ts.GetTask(ctx, task.ID)
+	if task == nil || task.ClosedAt == nil {
+		t.Fatalf("expected the eol task to be closed: %+v", task)
+	}
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+type activityResponse struct {
+	Items   []tasks.TaskActivity `json:"items"`
+	Columns map[string]string    `json:"columns"`
+	Flow    tasks.TaskFlow       `json:"flow"`
+}
+
+func fetchActivity(t *testing.T, env *taskEnv, taskID int64) activityResponse {
+	t.Helper()
+	id := strconv.FormatInt(taskID, 10)
+	rr := httptest.NewRecorder()
+	env.handler.TaskActivity(rr, withURLParams(authedRequest("GET", "/api/tasks/"+id+"/activity", nil, env.admin), map[string]string{"id": id}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("activity status %d: %s", rr.Code, rr.Body.String())
+	}
+	var res activityResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode activity: %v", err)
+	}
+	return res
+}
+
+func TestTaskActivityRecordsChanges(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	task := createTask(t, env, "Rotate keys")
+	task.Checklist = []tasks.TaskChecklistItem{{Text: "Revoke old key"}, {Text: "Publish new key"}}
+	if err := env.tasksStore.UpdateTask(env.ctx, task); err != nil {
+		t.Fatalf("seed checklist: %v", err)
+	}
+	id := strconv.FormatInt(task.ID, 10)
+	params := map[string]string{"id": id}
+
+	body, _ := json.Marshal(map[string]any{
+		"title":       "Rotate signing keys",
+		"priority":    tasks.PriorityHigh,
+		"tags":        []string{"crypto"},
+		"assigned_to": []string{env.analyst.Username},
+		"checklist": []map[string]any{
+			{"text": "Revoke old key", "done": true},
+			{"text": "Publish new key", "done": false},
+		},
+	})
+	rr := httptest.NewRecorder()
+	env.handler.UpdateTask(rr, withURLParams(authedRequest("PUT", "/api/tasks/"+id, body, env.admin), params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	body, _ = json.Marshal(map[string]any{"column_id": env.done.ID, "position": 1})
+	rr = httptest.NewRecorder()
+	env.handler.MoveTask(rr, withURLParams(authedRequest("POST", "/api/tasks/"+id+"/move", body, env.admin), params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("move status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	res := fetchActivity(t, env, task.ID)
+	if len(res.Items) == 0 || res.Items[0].Action != tasks.ActivityCreated {
+		t.Fatalf("expected history to start with creation, got %+v", res.Items)
+	}
+	seen := map[string]tasks.TaskActivity{}
+	for _, item := range res.Items {
+		seen[item.Action+":"+item.Field] = item
+		if item.Action != tasks.ActivityCreated && (item.ActorID == nil || *item.ActorID != env.admin.ID) {
+			t.Fatalf("expected admin as actor, got %+v", item)
+		}
+	}
+	if title := seen[tasks.ActivityFieldChanged+":title"]; title.OldValue != "Rotate keys" || title.NewValue != "Rotate signing keys" {
+		t.Fatalf("unexpected title diff: %+v", title)
+	}
+	if prio := seen[tasks.ActivityFieldChanged+":priority"]; prio.OldValue != tasks.PriorityMedium || prio.NewValue != tasks.PriorityHigh {
+		t.Fatalf("unexpected priority diff: %+v", prio)
+	}
+	if toggle, ok := seen[tasks.ActivityChecklistToggled+":Revoke old key"]; !ok || toggle.NewValue != "true" {
+		t.Fatalf("expected checklist toggle, got %+v", res.Items)
+	}
+	if _, ok := seen[tasks.ActivityChecklistToggled+":Publish new key"]; ok {
+		t.Fatalf("unchanged checklist item must not be recorded")
+	}
+	if _, ok := seen[tasks.ActivityTagsChanged+":tags"]; !ok {
+		t.Fatalf("expected tags change, got %+v", res.Items)
+	}
+	if assign := seen[tasks.ActivityAssigneesChanged+":assigned_to"]; assign.NewValue != strconv.FormatInt(env.analyst.ID, 10) {
+		t.Fatalf("unexpected assignees diff: %+v", assign)
+	}
+	move, ok := seen[tasks.ActivityMoved+":column"]
+	if !ok || move.OldValue != strconv.FormatInt(env.todo.ID, 10) || move.NewValue != strconv.FormatInt(env.done.ID, 10) {
+		t.Fatalf("unexpected move: %+v", move)
+	}
+	if res.Columns[strconv.FormatInt(env.done.ID, 10)] != env.done.Name {
+		t.Fatalf("expected column names, got %+v", res.Columns)
+	}
+	if res.Flow.DoneAt == nil || res.Flow.LeadTimeSeconds == nil {
+		t.Fatalf("task in final column should have lead time: %+v", res.Flow)
+	}
+}
+
+func TestTaskFlowFromColumnMoves(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &tasks.Task{
+		ID:        1,
+		ColumnID:  env.done.ID,
+		CreatedAt: created,
+	}
+	todo, done := strconv.FormatInt(env.todo.ID, 10), strconv.FormatInt(env.done.ID, 10)
+	moves := []tasks.TaskActivity{
+		{Action: tasks.ActivityMoved, Field: "column", OldValue: todo, NewValue: done, CreatedAt: created.Add(2 * time.Hour)},
+		{Action: tasks.ActivityMoved, Field: "column", OldValue: done, NewValue: todo, CreatedAt: created.Add(3 * time.Hour)},
+		{Action: tasks.ActivityMoved, Field: "column", OldValue: todo, NewValue: done, CreatedAt: created.Add(7 * time.Hour)},
+	}
+	flow := tasks.ComputeTaskFlow(task, moves, map[int64]bool{env.done.ID: true}, created.Add(10*time.Hour))
+	if flow.DoneAt == nil || !flow.DoneAt.Equal(created.Add(7*time.Hour)) {
+		t.Fatalf("done should be the last arrival in the final column: %+v", flow.DoneAt)
+	}
+	if flow.LeadTimeSeconds == nil || *flow.LeadTimeSeconds != 7*3600 {
+		t.Fatalf("unexpected lead time: %v", flow.LeadTimeSeconds)
+	}
+	if flow.CycleTimeSeconds == nil || *flow.CycleTimeSeconds != 5*3600 {
+		t.Fatalf("unexpected cycle time: %v", flow.CycleTimeSeconds)
+	}
+	if len(flow.Columns) != 2 || flow.Columns[0].ColumnID != env.todo.ID || flow.Columns[0].Seconds != 6*3600 || flow.Columns[0].Visits != 2 {
+		t.Fatalf("unexpected column times: %+v", flow.Columns)
+	}
+	if flow.Columns[1].Seconds != 4*3600 {
+		t.Fatalf("unexpected time in final column: %+v", flow.Columns[1])
+	}
+
+	task.ColumnID = env.todo.ID
+	open := tasks.ComputeTaskFlow(task, moves[:2], map[int64]bool{env.done.ID: true}, created.Add(10*time.Hour))
+	if open.DoneAt != nil || open.LeadTimeSeconds != nil || open.CycleTimeSeconds != nil {
+		t.Fatalf("reopened task must not have lead time: %+v", open)
+	}
+}
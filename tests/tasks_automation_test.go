@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/tasks"
+)
+
+func TestTaskAutomationRules(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	boardParam := map[string]string{"board_id": strconv.FormatInt(env.board.ID, 10)}
+	createRule := func(rule map[string]any) (*httptest.ResponseRecorder, tasks.AutomationRule) {
+		t.Helper()
+		body, _ := json.Marshal(rule)
+		req := withURLParams(authedRequest("POST", "/api/tasks/boards/"+boardParam["board_id"]+"/automation", body, env.admin), boardParam)
+		rr := httptest.NewRecorder()
+		env.handler.CreateAutomationRule(rr, req)
+		var created tasks.AutomationRule
+		_ = json.Unmarshal(rr.Body.Bytes(), &created)
+		return rr, created
+	}
+
+	rr, triage := createRule(map[string]any{
+		"name":       "Triage high",
+		"trigger":    tasks.TriggerTaskCreated,
+		"conditions": map[string]any{"priorities": []string{"high"}},
+		"actions": []map[string]any{
+			{"type": tasks.ActionAddTag, "tag": "triage"},
+			{"type": tasks.ActionAssign, "user_ids": []int64{env.analyst.ID}},
+			{"type": tasks.ActionComment, "comment": "Picked up by automation"},
+		},
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create rule status %d: %s", rr.Code, rr.Body.String())
+	}
+	rr, _ = createRule(map[string]any{
+		"name":    "Broken",
+		"trigger": tasks.TriggerTaskCreated,
+		"actions": []map[string]any{{"type": tasks.ActionMove, "column_id": 9999}},
+	})
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "tasks.columnNotFound") {
+		t.Fatalf("expected column validation error, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr, _ = createRule(map[string]any{
+		"name":    "Finish checklist",
+		"trigger": tasks.TriggerChecklistCompleted,
+		"actions": []map[string]any{{"type": tasks.ActionMove, "column_id": env.done.ID}},
+	}); rr.Code != http.StatusCreated {
+		t.Fatalf("create checklist rule status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	createViaAPI := func(title, priority string) *tasks.Task {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"column_id": env.todo.ID, "title": title, "priority": priority})
+		rr := httptest.NewRecorder()
+		env.handler.CreateTask(rr, authedRequest("POST", "/api/tasks", body, env.admin))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("create task status %d: %s", rr.Code, rr.Body.String())
+		}
+		var task tasks.Task
+		_ = json.Unmarshal(rr.Body.Bytes(), &task)
+		return &task
+	}
+	high := createViaAPI("Urgent", tasks.PriorityHigh)
+	low := createViaAPI("Later", tasks.PriorityLow)
+
+	body, _ := json.Marshal(map[string]any{"rule_id": triage.ID, "task_id": low.ID})
+	rr = httptest.NewRecorder()
+	env.handler.DryRunAutomation(rr, withURLParams(authedRequest("POST", "/api/tasks/boards/"+boardParam["board_id"]+"/automation/dry-run", body, env.admin), boardParam))
+	var plan tasks.AutomationPlan
+	_ = json.Unmarshal(rr.Body.Bytes(), &plan)
+	if rr.Code != http.StatusOK || plan.Matched || len(plan.Conditions) != 1 || plan.Conditions[0].Matched {
+		t.Fatalf("unexpected dry run for low task: %d %s", rr.Code, rr.Body.String())
+	}
+	body, _ = json.Marshal(map[string]any{"rule_id": triage.ID, "task_id": high.ID})
+	rr = httptest.NewRecorder()
+	env.handler.DryRunAutomation(rr, withURLParams(authedRequest("POST", "/api/tasks/boards/"+boardParam["board_id"]+"/automation/dry-run", body, env.admin), boardParam))
+	_ = json.Unmarshal(rr.Body.Bytes(), &plan)
+	if !plan.Matched || len(plan.Actions) != 3 {
+		t.Fatalf("unexpected dry run for high task: %s", rr.Body.String())
+	}
+	if tags, _ := env.tasksStore.ListTaskTagsForTasks(env.ctx, []int64{high.ID}); len(tags[high.ID]) != 0 {
+		t.Fatalf("dry run must not change the task: %v", tags[high.ID])
+	}
+
+	worker := tasks.NewAutomationWorker(config.SchedulerConfig{Enabled: true, MaxJobsPerTick: 10}, env.tasksStore, nil, nil, nil)
+	now := time.Now().UTC()
+	for i := 0; i < 2; i++ {
+		if err := worker.RunOnce(env.ctx, now); err != nil {
+			t.Fatalf("run once: %v", err)
+		}
+	}
+	tags, _ := env.tasksStore.ListTaskTagsForTasks(env.ctx, []int64{high.ID, low.ID})
+	if len(tags[high.ID]) != 1 || tags[high.ID][0] != "triage" || len(tags[low.ID]) != 0 {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	assignments, _ := env.tasksStore.ListTaskAssignments(env.ctx, high.ID)
+	if len(assignments) != 1 || assignments[0].UserID != env.analyst.ID {
+		t.Fatalf("unexpected assignments: %+v", assignments)
+	}
+	comments, _ := env.tasksStore.ListTaskComments(env.ctx, high.ID)
+	if len(comments) != 1 {
+		t.Fatalf("rule must run once per event, got %d comments", len(comments))
+	}
+	runs, _ := env.tasksStore.ListAutomationRuns(env.ctx, triage.ID, 10)
+	if len(runs) != 1 || runs[0].Status != tasks.AutomationRunDone {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+
+	taskParam := map[string]string{"id": strconv.FormatInt(high.ID, 10)}
+	body, _ = json.Marshal(map[string]any{"checklist": []map[string]any{{"text": "Check", "done": true}}})
+	rr = httptest.NewRecorder()
+	env.handler.UpdateTask(rr, withURLParams(authedRequest("PUT", "/api/tasks/"+taskParam["id"], body, env.admin), taskParam))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update task status %d: %s", rr.Code, rr.Body.String())
+	}
+	if err := worker.RunOnce(env.ctx, now); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if moved, _ := env.tasksStore.GetTask(env.ctx, high.ID); moved.ColumnID != env.done.ID {
+		t.Fatalf("completed checklist must move the task to done, column %d", moved.ColumnID)
+	}
+
+	if rr, _ = createRule(map[string]any{
+		"name":    "Overdue follow-up",
+		"trigger": tasks.TriggerDuePassed,
+		"actions": []map[string]any{{"type": tasks.ActionCreateSubtask, "title": "Follow up"}},
+	}); rr.Code != http.StatusCreated {
+		t.Fatalf("create due rule status %d: %s", rr.Code, rr.Body.String())
+	}
+	past := now.Add(-time.Hour)
+	low.DueDate = &past
+	if err := env.tasksStore.UpdateTask(env.ctx, low); err != nil {
+		t.Fatalf("update due: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := worker.RunOnce(env.ctx, now.Add(time.Minute)); err != nil {
+			t.Fatalf("run once: %v", err)
+		}
+	}
+	links, _ := env.tasksStore.ListEntityLinks(env.ctx, "task", strconv.FormatInt(low.ID, 10))
+	if len(links) != 1 || links[0].TargetType != "task_child" {
+		t.Fatalf("expected one subtask link, got %+v", links)
+	}
+}
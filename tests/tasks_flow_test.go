@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+func TestBoardFlowEndpoint(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	limit := 1
+	env.todo.WIPLimit = &limit
+	if err := env.tasksStore.UpdateColumn(env.ctx, env.todo); err != nil {
+		t.Fatalf("wip limit: %v", err)
+	}
+	first := createTask(t, env, "Patch servers")
+	createTask(t, env, "Review firewall")
+	createTask(t, env, "Renew certificates")
+
+	id := strconv.FormatInt(first.ID, 10)
+	body, _ := json.Marshal(map[string]any{"column_id": env.done.ID, "position": 1})
+	rr := httptest.NewRecorder()
+	env.handler.MoveTask(rr, withURLParams(authedRequest("POST", "/api/tasks/"+id+"/move", body, env.admin), map[string]string{"id": id}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("move status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	boardID := strconv.FormatInt(env.board.ID, 10)
+	get := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := authedRequest("GET", "/api/tasks/boards/"+boardID+"/flow"+query, nil, env.admin)
+		env.handler.BoardFlow(rr, withURLParams(req, map[string]string{"board_id": boardID}))
+		return rr
+	}
+	rr = get("")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("flow status %d: %s", rr.Code, rr.Body.String())
+	}
+	var flow tasks.BoardFlow
+	if err := json.Unmarshal(rr.Body.Bytes(), &flow); err != nil {
+		t.Fatalf("decode flow: %v", err)
+	}
+	if len(flow.Cumulative) != 30 || len(flow.Columns) != 2 {
+		t.Fatalf("expected 30 days over 2 columns, got %d days %d columns", len(flow.Cumulative), len(flow.Columns))
+	}
+	today := flow.Cumulative[len(flow.Cumulative)-1]
+	if today.Counts[env.todo.ID] != 2 || today.Counts[env.done.ID] != 1 {
+		t.Fatalf("unexpected counts for today: %+v", today.Counts)
+	}
+	if len(flow.Completed) != 1 || flow.Completed[0].TaskID != first.ID || flow.LeadTime.Count != 1 {
+		t.Fatalf("expected the moved task to be completed: %+v", flow.Completed)
+	}
+	if len(flow.WIPViolations) != 1 || flow.WIPViolations[0].ColumnID != env.todo.ID || flow.WIPViolations[0].Count != 2 {
+		t.Fatalf("expected todo over its WIP limit today: %+v", flow.WIPViolations)
+	}
+	if len(flow.Ageing) != 2 {
+		t.Fatalf("expected two open tasks, got %+v", flow.Ageing)
+	}
+
+	if rr = get("?from=2026-01-10&to=2026-01-01"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected reversed period to fail, got %d", rr.Code)
+	}
+	if rr = get("?from=2024-01-01&to=2026-01-01"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected too long period to fail, got %d", rr.Code)
+	}
+}
+
+func TestAnalyzeBoardFlowMetrics(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	limit := 1
+	columns := []tasks.Column{
+		{ID: 3, Name: "Done", Position: 3, IsFinal: true},
+		{ID: 1, Name: "Backlog", Position: 1},
+		{ID: 2, Name: "Doing", Position: 2, WIPLimit: &limit},
+	}
+	move := func(taskID, from, to int64, at time.Time) tasks.TaskActivity {
+		return tasks.TaskActivity{TaskID: taskID, Action: tasks.ActivityMoved, Field: "column",
+			OldValue: strconv.FormatInt(from, 10), NewValue: strconv.FormatInt(to, 10), CreatedAt: at}
+	}
+	list := []tasks.Task{
+		{ID: 10, Title: "A", ColumnID: 3, CreatedAt: day(1, 9)},
+		{ID: 11, Title: "B", ColumnID: 2, CreatedAt: day(1, 9)},
+		{ID: 12, Title: "C", ColumnID: 1, CreatedAt: day(3, 9), IsArchived: true, UpdatedAt: day(4, 9)},
+	}
+	history := map[int64][]tasks.TaskActivity{
+		10: {move(10, 1, 2, day(2, 9)), move(10, 2, 3, day(4, 9))},
+		11: {move(11, 1, 2, day(3, 9))},
+	}
+	flow := tasks.AnalyzeBoardFlow(columns, list, history, day(1, 0), day(5, 0), day(5, 12))
+
+	if flow.Columns[0].ID != 1 || flow.Columns[2].ID != 3 {
+		t.Fatalf("columns should be ordered by position: %+v", flow.Columns)
+	}
+	want := []map[int64]int{
+		{1: 2, 2: 0, 3: 0},
+		{1: 1, 2: 1, 3: 0},
+		{1: 1, 2: 2, 3: 0},
+		{1: 0, 2: 1, 3: 1},
+		{1: 0, 2: 1, 3: 1},
+	}
+	if len(flow.Cumulative) != len(want) {
+		t.Fatalf("expected %d days, got %d", len(want), len(flow.Cumulative))
+	}
+	for i, counts := range want {
+		for col, n := range counts {
+			if flow.Cumulative[i].Counts[col] != n {
+				t.Fatalf("day %d column %d: expected %d, got %+v", i+1, col, n, flow.Cumulative[i].Counts)
+			}
+		}
+	}
+	if len(flow.WIPViolations) != 1 || !flow.WIPViolations[0].Date.Equal(day(3, 0)) || flow.WIPViolations[0].Count != 2 {
+		t.Fatalf("expected one WIP breach on day 3: %+v", flow.WIPViolations)
+	}
+	if flow.LeadTime.Count != 1 || flow.LeadTime.P50 != 3*86400 || flow.CycleTime.P95 != 2*86400 {
+		t.Fatalf("unexpected lead/cycle stats: %+v %+v", flow.LeadTime, flow.CycleTime)
+	}
+	if len(flow.Throughput) != 2 || flow.Throughput[0].Done != 0 || flow.Throughput[1].Done != 1 || !flow.Throughput[1].WeekStart.Equal(day(2, 0)) {
+		t.Fatalf("unexpected throughput: %+v", flow.Throughput)
+	}
+	if len(flow.Ageing) != 1 || flow.Ageing[0].TaskID != 11 || flow.Ageing[0].AgeSeconds != int64(day(5, 12).Sub(day(3, 9))/time.Second) {
+		t.Fatalf("unexpected ageing: %+v", flow.Ageing)
+	}
+}
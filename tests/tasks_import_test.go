@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/importer"
+	"berkut-scc/tasks"
+)
+
+const trelloExport = `{
+  "name": "Ops",
+  "lists": [{"id": "l1", "name": "Todo"}, {"id": "l2", "name": "Doing"}, {"id": "l3", "name": "Done"}],
+  "members": [{"id": "m1", "username": "analyst1"}, {"id": "m2", "username": "ghost"}],
+  "cards": [
+    {"id": "c1", "name": "Rotate keys", "desc": "Yearly rotation", "idList": "l1", "due": "2026-04-01T00:00:00.000Z",
+     "labels": [{"name": "High", "color": "red"}, {"name": "infra", "color": "blue"}], "idMembers": ["m1", "m2"],
+     "attachments": [{"name": "plan.pdf", "url": "https://trello.example/plan.pdf"}]},
+    {"id": "c2", "name": "Audit firewall", "idList": "l3"},
+    {"id": "c3", "name": "Old card", "idList": "l1", "closed": true},
+    {"id": "c4", "name": "Patch servers", "idList": "l2"}
+  ],
+  "checklists": [{"idCard": "c1", "checkItems": [
+    {"name": "Publish new key", "state": "incomplete", "pos": 2},
+    {"name": "Revoke old key", "state": "complete", "pos": 1}
+  ]}],
+  "actions": [
+    {"type": "commentCard", "date": "2026-03-02T10:00:00.000Z", "data": {"text": "Second", "card": {"id": "c1"}}, "memberCreator": {"username": "ghost"}},
+    {"type": "commentCard", "date": "2026-03-01T10:00:00.000Z", "data": {"text": "First", "card": {"id": "c1"}}, "memberCreator": {"username": "analyst1"}}
+  ]
+}`
+
+func importTasks(t *testing.T, env *taskEnv, doc string, preview bool) tasks.ImportPlan {
+	t.Helper()
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("file", "board.json")
+	_, _ = fw.Write([]byte(doc))
+	if preview {
+		_ = mw.WriteField("preview", "1")
+	}
+	_ = mw.Close()
+	boardID := strconv.FormatInt(env.board.ID, 10)
+	req := authedRequest("POST", "/api/tasks/boards/"+boardID+"/import", body.Bytes(), env.admin)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	env.handler.ImportTasks(rr, withURLParams(req, map[string]string{"board_id": boardID}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", rr.Code, rr.Body.String())
+	}
+	var plan tasks.ImportPlan
+	if err := json.Unmarshal(rr.Body.Bytes(), &plan); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	return plan
+}
+
+func TestImportTrelloBoard(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+
+	plan := importTasks(t, env, trelloExport, true)
+	if plan.Applied || plan.Created != 3 || plan.Skipped != 1 {
+		t.Fatalf("unexpected preview: %+v", plan)
+	}
+	reasons := map[string]string{}
+	for _, r := range plan.Report {
+		reasons[r.Reason] = r.ExternalID + ":" + r.Detail
+	}
+	for reason, want := range map[string]string{
+		tasks.ImportSkipArchivedInSource:  "c3:Old card",
+		tasks.ImportSkipStatusUnmapped:    "c4:Doing",
+		tasks.ImportSkipUserNotFound:      "c1:ghost",
+		tasks.ImportSkipAttachmentMissing: "c1:plan.pdf",
+	} {
+		if reasons[reason] != want {
+			t.Fatalf("expected %s for %s, got %+v", want, reason, plan.Report)
+		}
+	}
+	if list, _ := env.tasksStore.ListTasks(env.ctx, tasks.TaskFilter{BoardID: env.board.ID}); len(list) != 0 {
+		t.Fatalf("preview must not create tasks, got %d", len(list))
+	}
+
+	plan = importTasks(t, env, trelloExport, false)
+	if !plan.Applied || plan.Created != 3 {
+		t.Fatalf("unexpected import: %+v", plan)
+	}
+	list, _ := env.tasksStore.ListTasks(env.ctx, tasks.TaskFilter{BoardID: env.board.ID})
+	byLink := map[string]tasks.Task{}
+	for _, task := range list {
+		byLink[task.ExternalLink] = task
+	}
+	keys := byLink["trello:c1"]
+	if keys.ID == 0 || keys.Priority != tasks.PriorityHigh || keys.ColumnID != env.todo.ID || keys.DueDate == nil {
+		t.Fatalf("unexpected imported card: %+v", keys)
+	}
+	if len(keys.Checklist) != 2 || keys.Checklist[0].Text != "Revoke old key" || !keys.Checklist[0].Done || keys.Checklist[1].Done {
+		t.Fatalf("unexpected checklist: %+v", keys.Checklist)
+	}
+	if byLink["trello:c2"].ColumnID != env.done.ID || byLink["trello:c4"].ColumnID != env.todo.ID {
+		t.Fatalf("statuses should map to columns by name: %+v", byLink)
+	}
+	tags, _ := env.tasksStore.ListTaskTagsForTasks(env.ctx, []int64{keys.ID})
+	if len(tags[keys.ID]) != 1 || tags[keys.ID][0] != "infra" {
+		t.Fatalf("priority label must not become a tag: %+v", tags)
+	}
+	assignments, _ := env.tasksStore.ListTaskAssignments(env.ctx, keys.ID)
+	if len(assignments) != 1 || assignments[0].UserID != env.analyst.ID {
+		t.Fatalf("unexpected assignees: %+v", assignments)
+	}
+	comments, _ := env.tasksStore.ListTaskComments(env.ctx, keys.ID)
+	if len(comments) != 2 || comments[0].AuthorID != env.admin.ID || !strings.Contains(comments[0].Content, "analyst1") || !strings.HasSuffix(comments[0].Content, "First") || comments[0].CreatedAt.Day() != 1 {
+		t.Fatalf("comments must be posted by the importing user: %+v", comments)
+	}
+	if comments[1].AuthorID != env.admin.ID || !strings.Contains(comments[1].Content, "ghost") {
+		t.Fatalf("comment of unknown author should name them: %+v", comments[1])
+	}
+
+	plan = importTasks(t, env, trelloExport, false)
+	if plan.Created != 0 || plan.Updated != 0 || plan.Unchanged != 3 {
+		t.Fatalf("re-run should be idempotent: %+v", plan)
+	}
+	plan = importTasks(t, env, strings.Replace(trelloExport, `"idList": "l3"`, `"idList": "l1"`, 1), false)
+	if plan.Updated != 1 || plan.Items[1].Changes[0] != "column" {
+		t.Fatalf("expected the moved card to be updated: %+v", plan)
+	}
+	if moved, _ := env.tasksStore.GetTask(env.ctx, byLink["trello:c2"].ID); moved.ColumnID != env.todo.ID {
+		t.Fatalf("card should follow its list: %+v", moved)
+	}
+}
+
+func TestParseJiraExports(t *testing.T) {
+	csv := "Summary,Issue key,Status,Priority,Assignee,Labels,Labels,Comment,Due date\n" +
+		"Fix login,SEC-1,In Progress,Blocker,\"Doe, John\",web,auth,\"02/Mar/26 10:15 AM;jdoe;Looks good\",15/Mar/26 12:00 AM\n"
+	export, err := importer.ParseTaskExport("", "issues.csv", []byte(csv))
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	task := export.Tasks[0]
+	if export.Format != importer.FormatJiraCSV || export.ExternalKey(task) != "jira:SEC-1" {
+		t.Fatalf("unexpected export: %+v", export)
+	}
+	if len(task.Assignees) != 1 || task.Assignees[0] != "Doe, John" || len(task.Labels) != 2 || task.DueDate == nil || task.DueDate.Day() != 15 {
+		t.Fatalf("unexpected jira row: %+v", task)
+	}
+	if len(task.Comments) != 1 || task.Comments[0].Author != "jdoe" || task.Comments[0].Body != "Looks good" || task.Comments[0].CreatedAt == nil {
+		t.Fatalf("unexpected jira comment: %+v", task.Comments)
+	}
+
+	xml := `<rss version="0.92"><channel><item>
+<title>[SEC-2] Rotate certs</title><key id="10002">SEC-2</key><summary>Rotate certs</summary>
+<description>&lt;p&gt;Renew &amp;amp; deploy&lt;/p&gt;</description>
+<status>Done</status><priority>Minor</priority><assignee username="jdoe">John Doe</assignee>
+<labels><label>pki</label></labels>
+<comments><comment author="jdoe" created="Mon, 2 Mar 2026 10:00:00 +0000">&lt;p&gt;Done&lt;/p&gt;</comment></comments>
+<attachments><attachment id="1" name="certs.zip"/></attachments>
+</item></channel></rss>`
+	export, err = importer.ParseTaskExport("", "issues.xml", []byte(xml))
+	if err != nil {
+		t.Fatalf("parse xml: %v", err)
+	}
+	task = export.Tasks[0]
+	if export.ExternalKey(task) != "jira:SEC-2" || task.Description != "Renew & deploy" || task.Assignees[0] != "jdoe" {
+		t.Fatalf("unexpected jira item: %+v", task)
+	}
+	if len(task.Comments) != 1 || task.Comments[0].Body != "Done" || len(task.Attachments) != 1 || task.Attachments[0].Name != "certs.zip" {
+		t.Fatalf("unexpected jira comments or attachments: %+v", task)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+func addDependency(t *testing.T, env *taskEnv, successor, predecessor int64, depType string, lag int) int {
+	t.Helper()
+	id := strconv.FormatInt(successor, 10)
+	payload, _ := json.Marshal(map[string]any{"predecessor_id": predecessor, "type": depType, "lag_days": lag})
+	req := authedRequest("POST", "/api/tasks/"+id+"/dependencies", payload, env.admin)
+	rr := httptest.NewRecorder()
+	env.handler.AddDependency(rr, withURLParams(req, map[string]string{"id": id}))
+	return rr.Code
+}
+
+func TestTimelineCriticalPathAndShift(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	base := tasks.WorkDay(time.Now()).AddDate(0, 0, 10)
+	day := func(n int) *time.Time {
+		d := base.AddDate(0, 0, n)
+		return &d
+	}
+	dated := func(title string, start, due *time.Time) *tasks.Task {
+		task := createTask(t, env, title)
+		task.StartDate, task.DueDate = start, due
+		if err := env.tasksStore.UpdateTask(env.ctx, task); err != nil {
+			t.Fatalf("update task: %v", err)
+		}
+		return task
+	}
+	design := dated("Design", day(0), day(2))
+	build := dated("Build", day(0), day(0))
+	review := dated("Review", day(1), day(1))
+	release := createTask(t, env, "Release")
+
+	if code := addDependency(t, env, build.ID, design.ID, tasks.DependencyFinishStart, 0); code != http.StatusCreated {
+		t.Fatalf("add fs dependency: %d", code)
+	}
+	if code := addDependency(t, env, review.ID, design.ID, tasks.DependencyStartStart, 1); code != http.StatusCreated {
+		t.Fatalf("add ss dependency: %d", code)
+	}
+	if _, err := env.tasksStore.CreateTaskBlock(env.ctx, &tasks.TaskBlock{TaskID: release.ID, BlockType: tasks.BlockTypeTask, BlockerTaskID: &build.ID, CreatedBy: &env.admin.ID}); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if code := addDependency(t, env, design.ID, release.ID, "", 0); code != http.StatusConflict {
+		t.Fatalf("expected a cycle through the block to be rejected, got %d", code)
+	}
+	if code := addDependency(t, env, build.ID, design.ID, tasks.DependencyStartStart, 0); code != http.StatusConflict {
+		t.Fatalf("expected a duplicate dependency to be rejected, got %d", code)
+	}
+
+	boardID := strconv.FormatInt(env.board.ID, 10)
+	req := authedRequest("GET", "/api/tasks/boards/"+boardID+"/timeline", nil, env.analyst)
+	rr := httptest.NewRecorder()
+	env.handler.BoardTimeline(rr, withURLParams(req, map[string]string{"board_id": boardID}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("timeline status %d: %s", rr.Code, rr.Body.String())
+	}
+	var timeline tasks.Timeline
+	if err := json.Unmarshal(rr.Body.Bytes(), &timeline); err != nil {
+		t.Fatalf("decode timeline: %v", err)
+	}
+	byID := map[int64]tasks.TimelineTask{}
+	for _, item := range timeline.Tasks {
+		byID[item.TaskID] = item
+	}
+	if !byID[build.ID].EarliestStart.Equal(*day(3)) || !byID[review.ID].EarliestStart.Equal(*day(1)) {
+		t.Fatalf("unexpected earliest starts: %+v", timeline.Tasks)
+	}
+	if got := byID[release.ID]; got.Scheduled || !got.EarliestStart.Equal(*day(4)) || !timeline.To.Equal(*day(4)) {
+		t.Fatalf("blocked task should follow its blocker: %+v", got)
+	}
+	if got := byID[review.ID]; got.Critical || got.SlackDays != 3 || !got.LatestFinish.Equal(*day(4)) {
+		t.Fatalf("unexpected review slack: %+v", got)
+	}
+	if !byID[build.ID].Late || byID[design.ID].Late {
+		t.Fatalf("only the pushed task should be late: %+v", timeline.Tasks)
+	}
+	if !slices.Equal(timeline.CriticalPath, []int64{design.ID, build.ID, release.ID}) || len(timeline.Cycles) != 0 {
+		t.Fatalf("unexpected critical path: %+v", timeline)
+	}
+
+	id := strconv.FormatInt(design.ID, 10)
+	payload, _ := json.Marshal(map[string]any{"due_date": day(4).Format(time.RFC3339), "shift_dependents": true})
+	req = authedRequest("PUT", "/api/tasks/"+id, payload, env.admin)
+	rr = httptest.NewRecorder()
+	env.handler.UpdateTask(rr, withURLParams(req, map[string]string{"id": id}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update status %d: %s", rr.Code, rr.Body.String())
+	}
+	moved, _ := env.tasksStore.GetTask(env.ctx, build.ID)
+	if !moved.StartDate.Equal(*day(5)) || !moved.DueDate.Equal(*day(5)) {
+		t.Fatalf("dependent should be shifted after the predecessor: %+v", moved)
+	}
+	if kept, _ := env.tasksStore.GetTask(env.ctx, review.ID); !kept.StartDate.Equal(*day(1)) {
+		t.Fatalf("satisfied dependent should stay: %+v", kept)
+	}
+
+	payload, _ = json.Marshal(map[string]any{"start_date": day(6).Format(time.RFC3339)})
+	req = authedRequest("PUT", "/api/tasks/"+id, payload, env.admin)
+	rr = httptest.NewRecorder()
+	env.handler.UpdateTask(rr, withURLParams(req, map[string]string{"id": id}))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected start after due to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestBuildTimelineReportsCycles(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	list := []tasks.Task{{ID: 1, Title: "A"}, {ID: 2, Title: "B"}, {ID: 3, Title: "C"}}
+	deps := []tasks.TaskDependency{
+		{PredecessorID: 1, SuccessorID: 2, Type: tasks.DependencyFinishStart},
+		{PredecessorID: 2, SuccessorID: 1, Type: tasks.DependencyFinishStart},
+		{PredecessorID: 2, SuccessorID: 3, Type: tasks.DependencyFinishStart},
+	}
+	timeline := tasks.BuildTimeline(list, nil, deps, now)
+	if len(timeline.Cycles) != 1 || !slices.Equal(timeline.Cycles[0], []int64{1, 2}) {
+		t.Fatalf("unexpected cycles: %+v", timeline.Cycles)
+	}
+	last := timeline.Tasks[2]
+	if last.InCycle || !last.EarliestStart.Equal(tasks.WorkDay(now).AddDate(0, 0, 1)) {
+		t.Fatalf("task after a cycle should still be scheduled: %+v", last)
+	}
+}
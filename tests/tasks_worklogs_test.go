@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/tasks"
+)
+
+func TestTaskWorklogsAndRemainingEstimate(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	task := createTask(t, env, "Audit prep")
+	taskID := strconv.FormatInt(task.ID, 10)
+	params := map[string]string{"id": taskID}
+
+	body, _ := json.Marshal(map[string]any{"remaining_minutes": 240})
+	rr := httptest.NewRecorder()
+	env.handler.UpdateTaskEstimate(rr, withURLParams(authedRequest("PUT", "/api/tasks/"+taskID+"/estimate", body, env.admin), params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("estimate status %d: %s", rr.Code, rr.Body.String())
+	}
+
+	addWorklog := func(payload map[string]any) (*httptest.ResponseRecorder, tasks.Worklog) {
+		t.Helper()
+		body, _ := json.Marshal(payload)
+		rr := httptest.NewRecorder()
+		env.handler.AddWorklog(rr, withURLParams(authedRequest("POST", "/api/tasks/"+taskID+"/worklogs", body, env.admin), params))
+		var entry tasks.Worklog
+		_ = json.Unmarshal(rr.Body.Bytes(), &entry)
+		return rr, entry
+	}
+	rr, first := addWorklog(map[string]any{"hours": 1.5, "comment": "Collected evidence"})
+	if rr.Code != http.StatusCreated || first.Minutes != 90 || first.Source != tasks.WorklogSourceManual {
+		t.Fatalf("unexpected worklog %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr, _ = addWorklog(map[string]any{"minutes": 0}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "tasks.worklog.minutesInvalid") {
+		t.Fatalf("expected minutes validation, got %d %s", rr.Code, rr.Body.String())
+	}
+	future := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	if rr, _ = addWorklog(map[string]any{"minutes": 10, "work_date": future}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "tasks.worklog.dateInFuture") {
+		t.Fatalf("expected future date validation, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	summary, err := env.tasksStore.GetTaskTimeSummary(env.ctx, task.ID)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.SpentMinutes != 90 || summary.RemainingMinutes == nil || *summary.RemainingMinutes != 150 {
+		t.Fatalf("unexpected summary after add: %+v", summary)
+	}
+
+	body, _ = json.Marshal(map[string]any{"minutes": 60, "comment": "Collected evidence"})
+	rr = httptest.NewRecorder()
+	updateParams := map[string]string{"id": taskID, "worklog_id": strconv.FormatInt(first.ID, 10)}
+	env.handler.UpdateWorklog(rr, withURLParams(authedRequest("PUT", "/api/tasks/"+taskID+"/worklogs/"+updateParams["worklog_id"], body, env.admin), updateParams))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update worklog status %d: %s", rr.Code, rr.Body.String())
+	}
+	summary, _ = env.tasksStore.GetTaskTimeSummary(env.ctx, task.ID)
+	if summary.SpentMinutes != 60 || *summary.RemainingMinutes != 180 {
+		t.Fatalf("unexpected summary after update: %+v", summary)
+	}
+
+	rr = httptest.NewRecorder()
+	env.handler.UpdateWorklog(rr, withURLParams(authedRequest("PUT", "/api/tasks/"+taskID+"/worklogs/"+updateParams["worklog_id"], body, env.analyst), updateParams))
+	if rr.Code == http.StatusOK {
+		t.Fatalf("expected analyst to be denied editing a foreign worklog")
+	}
+}
+
+func TestTaskTimerLifecycle(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	first := createTask(t, env, "First")
+	second := createTask(t, env, "Second")
+	start := func(task *tasks.Task) *httptest.ResponseRecorder {
+		t.Helper()
+		id := strconv.FormatInt(task.ID, 10)
+		rr := httptest.NewRecorder()
+		env.handler.StartTimer(rr, withURLParams(authedRequest("POST", "/api/tasks/"+id+"/timer/start", nil, env.admin), map[string]string{"id": id}))
+		return rr
+	}
+	if rr := start(first); rr.Code != http.StatusCreated {
+		t.Fatalf("start timer status %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := start(first); rr.Code != http.StatusOK {
+		t.Fatalf("restart on same task should return running timer, got %d", rr.Code)
+	}
+	if rr := start(second); rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "tasks.worklog.timerRunning") {
+		t.Fatalf("expected timer conflict, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if err := env.tasksStore.DiscardTaskTimer(env.ctx, env.admin.ID); err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	startedAt := time.Now().UTC().Add(-30 * time.Minute)
+	if ok, err := env.tasksStore.StartTaskTimer(env.ctx, &tasks.TaskTimer{UserID: env.admin.ID, TaskID: second.ID, StartedAt: startedAt}); err != nil || !ok {
+		t.Fatalf("start timer in store: %v %v", ok, err)
+	}
+	body, _ := json.Marshal(map[string]any{"comment": "Call with vendor"})
+	rr := httptest.NewRecorder()
+	env.handler.StopTimer(rr, authedRequest("POST", "/api/tasks/timer/stop", body, env.admin))
+	var stopped struct {
+		Worklog *tasks.Worklog `json:"worklog"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &stopped)
+	if rr.Code != http.StatusOK || stopped.Worklog == nil {
+		t.Fatalf("stop timer status %d: %s", rr.Code, rr.Body.String())
+	}
+	if stopped.Worklog.TaskID != second.ID || stopped.Worklog.Source != tasks.WorklogSourceTimer || stopped.Worklog.Minutes < 30 || stopped.Worklog.Minutes > 31 {
+		t.Fatalf("unexpected timer worklog: %+v", stopped.Worklog)
+	}
+	if timer, _ := env.tasksStore.GetTaskTimer(env.ctx, env.admin.ID); timer != nil {
+		t.Fatalf("timer should be removed after stop")
+	}
+	rr = httptest.NewRecorder()
+	env.handler.StopTimer(rr, authedRequest("POST", "/api/tasks/timer/stop", nil, env.admin))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected no running timer, got %d", rr.Code)
+	}
+}
+
+func TestWorklogSummaryAndExport(t *testing.T) {
+	env := setupTasksEnv(t)
+	defer env.cleanup()
+	newTask := func(title, customer string) *tasks.Task {
+		t.Helper()
+		task := &tasks.Task{
+			BoardID:          env.board.ID,
+			ColumnID:         env.todo.ID,
+			Title:            title,
+			Priority:         tasks.PriorityMedium,
+			BusinessCustomer: customer,
+			CreatedBy:        &env.admin.ID,
+		}
+		if _, err := env.tasksStore.CreateTask(env.ctx, task, nil); err != nil {
+			t.Fatalf("task create: %v", err)
+		}
+		return task
+	}
+	finance := newTask("Finance review", "Finance")
+	sales := newTask("Sales review", "Sales")
+	day := tasks.WorkDay(time.Now().UTC())
+	for _, w := range []tasks.Worklog{
+		{TaskID: finance.ID, UserID: env.admin.ID, WorkDate: day, Minutes: 120, Source: tasks.WorklogSourceManual},
+		{TaskID: finance.ID, UserID: env.analyst.ID, WorkDate: day, Minutes: 30, Source: tasks.WorklogSourceManual},
+		{TaskID: sales.ID, UserID: env.admin.ID, WorkDate: day.AddDate(0, 0, -10), Minutes: 45, Source: tasks.WorklogSourceTimer},
+	} {
+		entry := w
+		if _, err := env.tasksStore.AddWorklog(env.ctx, &entry); err != nil {
+			t.Fatalf("add worklog: %v", err)
+		}
+	}
+
+	summary := func(query string) (int, []tasks.EffortAggregate, int) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		env.handler.WorklogSummary(rr, authedRequest("GET", "/api/tasks/worklogs/summary?"+query, nil, env.admin))
+		var res struct {
+			Items        []tasks.EffortAggregate `json:"items"`
+			TotalMinutes int                     `json:"total_minutes"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code, res.Items, res.TotalMinutes
+	}
+	code, items, total := summary("group_by=customer")
+	if code != http.StatusOK || total != 195 || len(items) != 2 || items[0].Label != "Finance" || items[0].Minutes != 150 || items[0].Entries != 2 {
+		t.Fatalf("unexpected customer summary %d: %+v total=%d", code, items, total)
+	}
+	code, items, total = summary("group_by=user&customer=finance")
+	if code != http.StatusOK || total != 150 || len(items) != 2 || items[0].Key != strconv.FormatInt(env.admin.ID, 10) {
+		t.Fatalf("unexpected user summary %d: %+v total=%d", code, items, total)
+	}
+	code, _, total = summary("group_by=board&from=" + day.AddDate(0, 0, -1).Format("2006-01-02"))
+	if code != http.StatusOK || total != 150 {
+		t.Fatalf("expected period filter to drop old worklog, got %d total=%d", code, total)
+	}
+	if code, _, _ = summary("group_by=priority"); code != http.StatusBadRequest {
+		t.Fatalf("expected invalid group to fail, got %d", code)
+	}
+
+	rr := httptest.NewRecorder()
+	env.handler.ExportWorklogs(rr, authedRequest("GET", "/api/tasks/worklogs/export?customer=Sales", nil, env.admin))
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("export status %d: %s", rr.Code, rr.Body.String())
+	}
+	records, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(records) != 2 || records[1][4] != "Sales review" || records[1][8] != "45" || records[1][10] != tasks.WorklogSourceTimer {
+		t.Fatalf("unexpected export: %v", records)
+	}
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"berkut-scc/api/handlers"
+	"berkut-scc/config"
+	"berkut-scc/core/auth"
+	"berkut-scc/core/rbac"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+	"berkut-scc/core/vulns"
+)
+
+const nvdLog4jFeed = `{
+  "format": "NVD_CVE",
+  "version": "2.0",
+  "vulnerabilities": [{
+    "cve": {
+      "id": "CVE-2021-44228",
+      "published": "2021-12-10T10:15:09.143",
+      "lastModified": "2023-11-07T03:39:36.747",
+      "descriptions": [{"lang": "en", "value": "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints."}],
+      "metrics": {"cvssMetricV31": [{"type": "Primary", "cvssData": {"baseScore": 10.0, "baseSeverity": "CRITICAL", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}}]},
+      "configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+        {"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding": "2.0.1", "versionEndExcluding": "2.12.2"},
+        {"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding": "2.13.0", "versionEndExcluding": "2.15.0"},
+        {"vulnerable": false, "criteria": "cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"}
+      ]}]}],
+      "references": [{"url": "https://logging.apache.org/log4j/2.x/security.html"}]
+    }
+  }]
+}`
+
+const osvSample = `{
+  "id": "GHSA-jfh8-c2jp-5v3q",
+  "aliases": ["CVE-2021-44228"],
+  "summary": "Remote code injection in Log4j",
+  "published": "2021-12-10T00:40:56Z",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.13.0"}, {"fixed": "2.15.0"}, {"introduced": "0"}, {"fixed": "2.3.1"}]}]
+  }]
+}`
+
+const bduSample = `<?xml version="1.0" encoding="UTF-8"?>
+<vulnerabilities>
+  <vul>
+    <identifier>BDU:2021-05969</identifier>
+    <name>Уязвимость библиотеки журналирования Log4j</name>
+    <description>Уязвимость позволяет нарушителю выполнить произвольный код</description>
+    <vulnerable_software>
+      <soft><vendor>Apache Software Foundation</vendor><name>Log4j</name><version>от 2.0 до 2.14.1 включительно</version></soft>
+    </vulnerable_software>
+    <identify_date>09.12.2021</identify_date>
+    <cvss3><vector score="10">AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H</vector></cvss3>
+    <severity>Критический уровень опасности</severity>
+    <sources>https://logging.apache.org/log4j/2.x/security.html</sources>
+    <identifiers><identifier type="CVE" link="https://nvd.nist.gov/vuln/detail/CVE-2021-44228">CVE-2021-44228</identifier></identifiers>
+  </vul>
+</vulnerabilities>`
+
+func TestCVSS3BaseScore(t *testing.T) {
+	cases := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10.0,
+		"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.1/AV:N/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N": 2.0,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	}
+	for vector, want := range cases {
+		got, err := vulns.CVSS3BaseScore(vector)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %v %v, want %v", vector, got, err, want)
+		}
+	}
+	if _, err := vulns.CVSS3BaseScore("AV:N/AC:L/Au:N/C:P/I:P/A:P"); err == nil {
+		t.Fatalf("cvss v2 vector must be rejected")
+	}
+	if sev := vulns.SeverityFromCVSS(7.5, ""); sev != "high" {
+		t.Fatalf("unexpected severity %s", sev)
+	}
+}
+
+func TestVersionRanges(t *testing.T) {
+	ordered := []string{"1.0.0-rc1", "1.0.0", "1.0.0.1", "1.2", "1.10", "2.0.0-beta", "2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		if vulns.CompareVersions(ordered[i-1], ordered[i]) >= 0 {
+			t.Fatalf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+	if vulns.CompareVersions("v2.4.57 (Ubuntu)", "2.4.57") != 0 {
+		t.Fatalf("labels should normalize")
+	}
+	a := store.VulnerabilityAffected{VersionStartIncluding: "2.13.0", VersionEndExcluding: "2.15.0"}
+	if !vulns.AffectsVersion(a, "2.14.1") || vulns.AffectsVersion(a, "2.15.0") || vulns.AffectsVersion(a, "2.12.9") {
+		t.Fatalf("range check failed")
+	}
+	if !vulns.AffectsVersion(store.VulnerabilityAffected{Versions: []string{"1.1.1k"}}, "1.1.1K") {
+		t.Fatalf("exact version should match")
+	}
+	if cpe, ok := vulns.ParseCPE(`cpe:2.3:a:apache:http\_server:2.4.57:*:*:*:*:*:*:*`); !ok || cpe.Product != "http_server" || cpe.Version != "2.4.57" {
+		t.Fatalf("unexpected cpe %+v", cpe)
+	}
+}
+
+func TestParseVulnerabilityFeeds(t *testing.T) {
+	nvd, err := vulns.ParseFeed("", []byte(nvdLog4jFeed))
+	if err != nil || len(nvd) != 1 {
+		t.Fatalf("nvd: %v %d", err, len(nvd))
+	}
+	if v := nvd[0]; v.Source != "nvd" || v.Severity != "critical" || v.CVSSScore != 10 || len(v.Affected) != 2 || v.Affected[0].Vendor != "apache" {
+		t.Fatalf("unexpected nvd record: %+v", v)
+	}
+	osv, err := vulns.ParseFeed("", []byte(osvSample))
+	if err != nil || len(osv) != 1 {
+		t.Fatalf("osv: %v", err)
+	}
+	if v := osv[0]; v.CVSSScore != 10 || len(v.Affected) != 2 || v.Affected[1].VersionEndExcluding != "2.3.1" || v.Affected[1].VersionStartIncluding != "" {
+		t.Fatalf("unexpected osv record: %+v", v)
+	}
+	bdu, err := vulns.ParseFeed("", []byte(bduSample))
+	if err != nil || len(bdu) != 1 {
+		t.Fatalf("bdu: %v", err)
+	}
+	if v := bdu[0]; v.ExternalID != "BDU:2021-05969" || v.Severity != "critical" || len(v.Aliases) != 1 ||
+		v.Affected[0].VersionStartIncluding != "2.0" || v.Affected[0].VersionEndIncluding != "2.14.1" {
+		t.Fatalf("unexpected bdu record: %+v", v)
+	}
+	if _, err := vulns.ParseFeed("csv", []byte("a,b")); err != vulns.ErrFormatInvalid {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
+
+func TestVulnerabilityImportMatchesAndClosesOnUpgrade(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AppConfig{DBPath: filepath.Join(dir, "test.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	users := store.NewUsersStore(db)
+	assets := store.NewAssetsStore(db)
+	software := store.NewSoftwareStore(db)
+	findings := store.NewFindingsStore(db)
+	links := store.NewEntityLinksStore(db)
+	audits := store.NewAuditStore(db)
+	vs := store.NewVulnsStore(db)
+	svc := vulns.NewService(vs, findings, links, audits)
+	policy := rbac.NewPolicy(rbac.DefaultRoles())
+	user := createObservablesUser(t, users, "vuln-admin", []string{"admin"})
+
+	assetID, err := assets.CreateAsset(ctx, &store.Asset{Name: "app-01", Type: "host"})
+	if err != nil {
+		t.Fatalf("asset: %v", err)
+	}
+	productID, err := software.CreateProduct(ctx, &store.SoftwareProduct{Name: "Log4j", Vendor: "Apache Software Foundation"})
+	if err != nil {
+		t.Fatalf("product: %v", err)
+	}
+	instID, err := software.AddAssetSoftware(ctx, &store.AssetSoftwareInstallation{AssetID: assetID, ProductID: productID, VersionText: "2.14.1"})
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	vh := handlers.NewVulnsHandler(vs, software, svc, users, audits, policy)
+	session := func(req *http.Request, params map[string]string) *http.Request {
+		req = withURLParams(req, params)
+		return req.WithContext(context.WithValue(req.Context(), auth.SessionContextKey, &store.SessionRecord{UserID: user.ID, Username: user.Username, Roles: []string{"admin"}}))
+	}
+
+	// The vendor differs from the CPE naming, so nothing matches until an alias is added.
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("file", "nvdcve-2.0-2021.json")
+	_, _ = fw.Write([]byte(nvdLog4jFeed))
+	_ = mw.Close()
+	req := httptest.NewRequest("POST", "/api/vulnerabilities/import", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	vh.Import(rr, session(req, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", rr.Code, rr.Body.String())
+	}
+	var imported vulns.ImportResult
+	_ = json.Unmarshal(rr.Body.Bytes(), &imported)
+	if imported.Created != 1 || imported.Scan == nil || imported.Scan.Matches != 0 {
+		t.Fatalf("unexpected import result: %+v", imported)
+	}
+
+	aliasBody, _ := json.Marshal(map[string]string{"cpe": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*"})
+	rr = httptest.NewRecorder()
+	vh.AddAlias(rr, session(httptest.NewRequest("POST", "/api/software/x/aliases", bytes.NewReader(aliasBody)), map[string]string{"id": strconv.FormatInt(productID, 10)}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("alias: %d %s", rr.Code, rr.Body.String())
+	}
+	scan, err := svc.Scan(ctx, 0, user.Username, user.ID)
+	if err != nil || scan.Matches != 1 || scan.FindingsCreated != 1 {
+		t.Fatalf("scan: %v %+v", err, scan)
+	}
+	matches, _ := vs.ListMatches(ctx, store.VulnerabilityMatchFilter{AssetID: assetID})
+	if len(matches) != 1 || matches[0].FindingID == nil {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	findingID := *matches[0].FindingID
+	f, _ := findings.GetFinding(ctx, findingID)
+	if f.FindingType != "vulnerability" || f.Severity != "critical" || f.Status != "open" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	linked, _ := links.ListBySource(ctx, "finding", strconv.FormatInt(findingID, 10))
+	if len(linked) != 2 {
+		t.Fatalf("finding should link asset and software, got %+v", linked)
+	}
+	if again, _ := svc.Scan(ctx, 0, user.Username, user.ID); again.FindingsCreated != 0 {
+		t.Fatalf("rescan must not duplicate findings: %+v", again)
+	}
+
+	rr = httptest.NewRecorder()
+	vh.ListAssets(rr, session(httptest.NewRequest("GET", "/api/vulnerabilities/assets", nil), nil))
+	var view struct {
+		Items []store.VulnerableAsset `json:"items"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &view)
+	if len(view.Items) != 1 || view.Items[0].Critical != 1 || view.Items[0].MaxCVSS != 10 {
+		t.Fatalf("unexpected vulnerable assets: %s", rr.Body.String())
+	}
+
+	ah := handlers.NewAssetsHandler(assets, software, nil, svc, users, audits, policy)
+	upgrade, _ := json.Marshal(map[string]any{"version_text": "2.17.1"})
+	rr = httptest.NewRecorder()
+	ah.UpdateSoftware(rr, session(httptest.NewRequest("PUT", "/api/assets/x/software/y", bytes.NewReader(upgrade)),
+		map[string]string{"id": strconv.FormatInt(assetID, 10), "inst_id": strconv.FormatInt(instID, 10)}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upgrade: %d %s", rr.Code, rr.Body.String())
+	}
+	f, _ = findings.GetFinding(ctx, findingID)
+	if f.Status != "resolved" {
+		t.Fatalf("finding should be resolved after upgrade, got %s", f.Status)
+	}
+	if open, _ := vs.ListVulnerableAssets(ctx); len(open) != 0 {
+		t.Fatalf("asset should no longer be vulnerable: %+v", open)
+	}
+
+	downgrade, _ := json.Marshal(map[string]any{"version_text": "2.14.0"})
+	rr = httptest.NewRecorder()
+	ah.UpdateSoftware(rr, session(httptest.NewRequest("PUT", "/api/assets/x/software/y", bytes.NewReader(downgrade)),
+		map[string]string{"id": strconv.FormatInt(assetID, 10), "inst_id": strconv.FormatInt(instID, 10)}))
+	f, _ = findings.GetFinding(ctx, findingID)
+	if f.Status != "open" {
+		t.Fatalf("finding should reopen after downgrade, got %s", f.Status)
+	}
+}